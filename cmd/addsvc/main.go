@@ -1,157 +1,47 @@
 package main
 
 import (
-	"fmt"
-	"net"
 	"net/http"
-	"os"
-	"os/signal"
-	"syscall"
 
 	"github.com/go-kit/kit/log"
-	"github.com/go-kit/kit/log/level"
-	kitgrpc "github.com/go-kit/kit/transport/grpc"
-	stdopentracing "github.com/opentracing/opentracing-go"
-	"github.com/openzipkin/zipkin-go"
-	zipkinhttp "github.com/openzipkin/zipkin-go/reporter/http"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/health"
-	healthgrpc "google.golang.org/grpc/health/grpc_health_v1"
-	"google.golang.org/grpc/reflection"
 
 	pb "github.com/miki-tnt/sa5g-go-usvc-k8s/pb/addsvc"
 	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/addsvc/endpoints"
 	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/addsvc/service"
 	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/addsvc/transports"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/bootstrap"
 )
 
-const (
-	defZipkinV2URL string = ""
-	defNameSpace   string = "sa5g-go-usvc-k8s"
-	defServiceName string = "addsvc"
-	defLogLevel    string = "error"
-	defServiceHost string = "localhost"
-	defHTTPPort    string = "8180"
-	defGRPCPort    string = "8181"
-	envZipkinV2URL string = "QS_ZIPKIN_V2_URL"
-	envNameSpace   string = "QS_ADDSVC_NAMESPACE"
-	envServiceName string = "QS_ADDSVC_SERVICE_NAME"
-	envLogLevel    string = "QS_ADDSVC_LOG_LEVEL"
-	envServiceHost string = "QS_ADDSVC_SERVICE_HOST"
-	envHTTPPort    string = "QS_ADDSVC_HTTP_PORT"
-	envGRPCPort    string = "QS_ADDSVC_GRPC_PORT"
-)
-
-type config struct {
-	nameSpace   string
-	serviceName string
-	logLevel    string
-	serviceHost string
-	httpPort    string
-	grpcPort    string
-	zipkinV2URL string
-}
-
-// Env reads specified environment variable. If no value has been found,
-// fallback is returned.
-func env(key string, fallback string) string {
-	if v := os.Getenv(key); v != "" {
-		return v
-	}
-	return fallback
+// spec is addsvc as bootstrapped, the environment variables of its own
+// being prefixed with QS_ADDSVC_.
+var spec = bootstrap.Spec{
+	Name:        "addsvc",
+	HTTPPort:    "8180",
+	GRPCPort:    "8181",
+	Capture:     true,
+	Limits:      true,
+	Idempotency: true,
 }
 
 func main() {
-	var logger log.Logger
-	{
-		logger = log.NewLogfmtLogger(os.Stderr)
-		logger = level.NewFilter(logger, level.AllowInfo())
-		logger = log.With(logger, "ts", log.DefaultTimestampUTC)
-		logger = log.With(logger, "caller", log.DefaultCaller)
-	}
-	cfg := loadConfig(logger)
-	logger = log.With(logger, "service", cfg.serviceName)
-
-	tracer := initOpentracing()
-	zipkinTracer := initZipkin(cfg.serviceName, cfg.httpPort, cfg.zipkinV2URL, logger)
-	service := NewServer(logger)
-	endpoints := endpoints.New(service, logger, tracer, zipkinTracer)
+	nf := bootstrap.New(spec)
+	cfg := nf.Config
+	logger := nf.Logger
 
-	errs := make(chan error, 2)
-	hs := health.NewServer()
-	hs.SetServingStatus(cfg.serviceName, healthgrpc.HealthCheckResponse_SERVING)
-	go startHTTPServer(endpoints, tracer, zipkinTracer, cfg.httpPort, logger, errs)
-	go startGRPCServer(endpoints, tracer, zipkinTracer, cfg.grpcPort, hs, logger, errs)
+	service := NewServer(nf.RequestLogger())
+	endpoints := endpoints.New(service, nf.Middleware())
 
-	go func() {
-		c := make(chan os.Signal)
-		signal.Notify(c, syscall.SIGINT)
-		errs <- fmt.Errorf("%s", <-c)
-	}()
-
-	err := <-errs
-	level.Info(logger).Log("serviceName", cfg.serviceName, "terminated", err)
-}
-
-func loadConfig(logger log.Logger) (cfg config) {
-	cfg.nameSpace = env(envNameSpace, defNameSpace)
-	cfg.serviceName = env(envServiceName, defServiceName)
-	cfg.logLevel = env(envLogLevel, defLogLevel)
-	cfg.serviceHost = env(envServiceHost, defServiceHost)
-	cfg.httpPort = env(envHTTPPort, defHTTPPort)
-	cfg.grpcPort = env(envGRPCPort, defGRPCPort)
-	cfg.zipkinV2URL = env(envZipkinV2URL, defZipkinV2URL)
-	return cfg
+	nf.HTTP(cfg.HTTPPort, func(m *http.ServeMux) {
+		m.Handle("/", transports.NewHTTPHandler(endpoints, nf.Tracer, nf.ZipkinTracer, logger))
+	})
+	nf.GRPC(cfg.GRPCPort, func(s *grpc.Server) {
+		pb.RegisterAddsvcServer(s, transports.MakeGRPCServer(endpoints, nf.Tracer, nf.ZipkinTracer, logger))
+	})
+	nf.Run()
 }
 
-func NewServer(logger log.Logger) (service.AddsvcService) {
+func NewServer(logger log.Logger) service.AddsvcService {
 	service := service.New(logger)
 	return service
 }
-
-func initOpentracing() (tracer stdopentracing.Tracer) {
-	return stdopentracing.GlobalTracer()
-}
-
-func initZipkin(serviceName, httpPort, zipkinV2URL string, logger log.Logger) (zipkinTracer *zipkin.Tracer) {
-	var (
-		err           error
-		hostPort      = fmt.Sprintf("localhost:%s", httpPort)
-		useNoopTracer = (zipkinV2URL == "")
-		reporter      = zipkinhttp.NewReporter(zipkinV2URL)
-	)
-	zEP, _ := zipkin.NewEndpoint(serviceName, hostPort)
-	zipkinTracer, err = zipkin.NewTracer(reporter, zipkin.WithLocalEndpoint(zEP), zipkin.WithNoopTracer(useNoopTracer))
-	if err != nil {
-		logger.Log("err", err)
-		os.Exit(1)
-	}
-	if !useNoopTracer {
-		logger.Log("tracer", "Zipkin", "type", "Native", "URL", zipkinV2URL)
-	}
-
-	return
-}
-
-func startHTTPServer(endpoints endpoints.Endpoints, tracer stdopentracing.Tracer, zipkinTracer *zipkin.Tracer, port string, logger log.Logger, errs chan error) {
-	p := fmt.Sprintf(":%s", port)
-	level.Info(logger).Log("protocol", "HTTP", "exposed", port)
-	errs <- http.ListenAndServe(p, transports.NewHTTPHandler(endpoints, tracer, zipkinTracer, logger))
-}
-
-func startGRPCServer(endpoints endpoints.Endpoints, tracer stdopentracing.Tracer, zipkinTracer *zipkin.Tracer, port string, hs *health.Server, logger log.Logger, errs chan error) {
-	p := fmt.Sprintf(":%s", port)
-	listener, err := net.Listen("tcp", p)
-	if err != nil {
-		level.Error(logger).Log("protocol", "GRPC", "listen", port, "err", err)
-		os.Exit(1)
-	}
-
-	var server *grpc.Server
-	level.Info(logger).Log("protocol", "GRPC", "protocol", "GRPC", "exposed", port)
-	server = grpc.NewServer(grpc.UnaryInterceptor(kitgrpc.Interceptor))
-	pb.RegisterAddsvcServer(server, transports.MakeGRPCServer(endpoints, tracer, zipkinTracer, logger))
-	healthgrpc.RegisterHealthServer(server, hs)
-	reflection.Register(server)
-	errs <- server.Serve(listener)
-}
@@ -0,0 +1,292 @@
+// Command alertgen writes the alerting rules of the NFs, a PrometheusRule of
+// the Prometheus operator, from their SLOs:
+//
+//	alertgen [-name sa5g-alerts] [-namespace monitoring] slos.yaml
+//
+// The rules are derived from the series every NF registers on the admin
+// port through pkg/bootstrap (see package autoscale), and select the NFs by
+// their nf label: an NF without SLOs of its own is held to the default
+// ones, so a new NF is alerted on as soon as it is scraped. It is alerted
+// on
+//
+//   - the ratio of its procedures that fail, over its SLO,
+//   - the 99th percentile of the seconds its procedures take, over its SLO,
+//   - its circuit breakers opening,
+//   - its links with other nodes going down: those of the gNB-DU with the
+//     gNB-CU and the near-RT RIC, the F1 and the E2, which would run over
+//     SCTP associations. The N2 of the N3IWF is in process and has none,
+//   - the error budget of one of its methods burning fast, or slowly, as
+//     tracked against the objectives of its QS_<NF>_SLOS (see package slo).
+//
+// See deployments/alerting/slos.yaml for the SLOs.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/autoscale"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/slo"
+)
+
+// SLOs are the SLOs of the NFs: the defaults and those of the NFs, by name,
+// which override them.
+type SLOs struct {
+	Defaults SLO            `yaml:"defaults"`
+	NFs      map[string]SLO `yaml:"nfs"`
+}
+
+// SLO is the service level objective of an NF. A zero field takes the
+// default.
+type SLO struct {
+	// ErrorRatio is the ratio of its procedures that may fail, such as
+	// 0.01.
+	ErrorRatio float64 `yaml:"error_ratio"`
+	// P99 is the 99th percentile of the seconds its procedures may take.
+	P99 time.Duration `yaml:"p99"`
+	// Window is the window of the rates, For how long an objective is
+	// missed before it alerts.
+	Window time.Duration `yaml:"window"`
+	For    time.Duration `yaml:"for"`
+}
+
+// or returns slo with its zero fields taken from def.
+func (slo SLO) or(def SLO) SLO {
+	if slo.ErrorRatio == 0 {
+		slo.ErrorRatio = def.ErrorRatio
+	}
+	if slo.P99 == 0 {
+		slo.P99 = def.P99
+	}
+	if slo.Window == 0 {
+		slo.Window = def.Window
+	}
+	if slo.For == 0 {
+		slo.For = def.For
+	}
+	return slo
+}
+
+// The defaults of the defaults.
+var defaultSLO = SLO{ErrorRatio: 0.01, P99: 500 * time.Millisecond, Window: 5 * time.Minute, For: 5 * time.Minute}
+
+// A breaker alerts once open within breakerWindow, a link once down for
+// linkFor.
+const (
+	breakerWindow = 5 * time.Minute
+	linkFor       = time.Minute
+)
+
+// An error budget burns fast when its burn rate is over fastBurn over the
+// short and the long windows of slo.DefaultWindows, slowly when over
+// slowBurn over the long and the longest ones: 2% and 5% of a budget of 30
+// days spent in an hour and in 6 hours.
+const (
+	fastBurn = 14.4
+	slowBurn = 6
+)
+
+// PrometheusRule is the resource of the rules (monitoring.coreos.com/v1).
+type PrometheusRule struct {
+	APIVersion string   `yaml:"apiVersion"`
+	Kind       string   `yaml:"kind"`
+	Metadata   Metadata `yaml:"metadata"`
+	Spec       RuleSpec `yaml:"spec"`
+}
+
+// Metadata is the metadata of the resource.
+type Metadata struct {
+	Name      string            `yaml:"name"`
+	Namespace string            `yaml:"namespace,omitempty"`
+	Labels    map[string]string `yaml:"labels,omitempty"`
+}
+
+// RuleSpec is the specification of the rules.
+type RuleSpec struct {
+	Groups []Group `yaml:"groups"`
+}
+
+// Group is a group of rules.
+type Group struct {
+	Name  string `yaml:"name"`
+	Rules []Rule `yaml:"rules"`
+}
+
+// Rule is an alerting rule.
+type Rule struct {
+	Alert       string            `yaml:"alert"`
+	Expr        string            `yaml:"expr"`
+	For         string            `yaml:"for,omitempty"`
+	Labels      map[string]string `yaml:"labels,omitempty"`
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+}
+
+func main() {
+	fs := flag.NewFlagSet("alertgen", flag.ExitOnError)
+	name := fs.String("name", "sa5g-alerts", "name of the PrometheusRule")
+	namespace := fs.String("namespace", "monitoring", "namespace of the PrometheusRule")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: alertgen [flags] slos.yaml\n\nflags:\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(os.Args[1:])
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	b, err := run(fs.Arg(0), *name, *namespace)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "alertgen: %v\n", err)
+		os.Exit(1)
+	}
+	os.Stdout.Write(b)
+}
+
+func run(sloFile, name, namespace string) ([]byte, error) {
+	data, err := ioutil.ReadFile(sloFile)
+	if err != nil {
+		return nil, err
+	}
+	var slos SLOs
+	if err := yaml.UnmarshalStrict(data, &slos); err != nil {
+		return nil, fmt.Errorf("%s: %v", sloFile, err)
+	}
+	slos.Defaults = slos.Defaults.or(defaultSLO)
+	all := map[string]SLO{"the defaults": slos.Defaults}
+	for nf, slo := range slos.NFs {
+		all[nf] = slo.or(slos.Defaults)
+	}
+	for nf, slo := range all {
+		if slo.ErrorRatio <= 0 || slo.ErrorRatio >= 1 {
+			return nil, fmt.Errorf("%s: error ratio %v of %s out of (0, 1)", sloFile, slo.ErrorRatio, nf)
+		}
+	}
+
+	pr := PrometheusRule{
+		APIVersion: "monitoring.coreos.com/v1",
+		Kind:       "PrometheusRule",
+		Metadata: Metadata{
+			Name:      name,
+			Namespace: namespace,
+			// those the rule selector of kube-prometheus matches
+			Labels: map[string]string{"prometheus": "kube-prometheus", "role": "alert-rules"},
+		},
+		Spec: RuleSpec{Groups: rules(slos)},
+	}
+	b, err := yaml.Marshal(pr)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# Code generated by alertgen from %s. DO NOT EDIT.\n", sloFile)
+	buf.Write(b)
+	return buf.Bytes(), nil
+}
+
+// rules returns the groups of rules of slos: those of the SLOs, for each NF
+// having its own and for the others, then those of the breakers and links.
+func rules(slos SLOs) []Group {
+	nfs := make([]string, 0, len(slos.NFs))
+	for nf := range slos.NFs {
+		nfs = append(nfs, nf)
+	}
+	sort.Strings(nfs)
+
+	var errors, latency []Rule
+	add := func(selector string, o SLO) {
+		errors = append(errors, Rule{
+			Alert: "SA5GProcedureErrorRatio",
+			Expr: fmt.Sprintf("sum by (namespace, nf) (rate(%s{%s}[%s])) / sum by (namespace, nf) (rate(%s{%s}[%s])) > %s",
+				autoscale.Failures, selector, slo.Label(o.Window), autoscale.Procedures, selector, slo.Label(o.Window), float(o.ErrorRatio)),
+			For:    slo.Label(o.For),
+			Labels: map[string]string{"severity": "critical"},
+			Annotations: map[string]string{
+				"summary":     "{{ $labels.nf }} of {{ $labels.namespace }} fails {{ $value | humanizePercentage }} of its procedures",
+				"description": fmt.Sprintf("The procedures of {{ $labels.nf }} fail over its SLO of %s%% for %s.", percent(o.ErrorRatio), slo.Label(o.For)),
+			},
+		})
+		latency = append(latency, Rule{
+			Alert: "SA5GProcedureLatencyP99",
+			Expr: fmt.Sprintf("histogram_quantile(0.99, sum by (namespace, nf, le) (rate(%s_bucket{%s}[%s]))) > %s",
+				autoscale.Duration, selector, slo.Label(o.Window), float(o.P99.Seconds())),
+			For:    slo.Label(o.For),
+			Labels: map[string]string{"severity": "warning"},
+			Annotations: map[string]string{
+				"summary":     "the slowest 1% of the procedures of {{ $labels.nf }} of {{ $labels.namespace }} take over {{ $value | humanizeDuration }}",
+				"description": fmt.Sprintf("The 99th percentile of the procedures of {{ $labels.nf }} is over its SLO of %s for %s.", o.P99, slo.Label(o.For)),
+			},
+		})
+	}
+	for _, nf := range nfs {
+		add(fmt.Sprintf("nf=%q", nf), slos.NFs[nf].or(slos.Defaults))
+	}
+	others := ""
+	if len(nfs) != 0 {
+		others = fmt.Sprintf("nf!~%q", strings.Join(nfs, "|"))
+	}
+	add(others, slos.Defaults)
+
+	return []Group{
+		{Name: "sa5g-errors", Rules: errors},
+		{Name: "sa5g-latency", Rules: latency},
+		{Name: "sa5g-breakers", Rules: []Rule{{
+			Alert:  "SA5GBreakerOpen",
+			Expr:   fmt.Sprintf("max by (namespace, pod, nf, breaker) (max_over_time(%s[%s])) > 0", autoscale.BreakerOpen, slo.Label(breakerWindow)),
+			Labels: map[string]string{"severity": "warning"},
+			Annotations: map[string]string{
+				"summary":     "the circuit breaker {{ $labels.breaker }} of {{ $labels.pod }} opened",
+				"description": fmt.Sprintf("The circuit breaker {{ $labels.breaker }} of {{ $labels.nf }} was open within the last %s: its calls failed.", slo.Label(breakerWindow)),
+			},
+		}}},
+		{Name: "sa5g-links", Rules: []Rule{{
+			Alert:  "SA5GLinkDown",
+			Expr:   fmt.Sprintf("min by (namespace, pod, nf, link) (%s) == 0", autoscale.LinkUp),
+			For:    slo.Label(linkFor),
+			Labels: map[string]string{"severity": "critical"},
+			Annotations: map[string]string{
+				"summary":     "the {{ $labels.link }} of {{ $labels.pod }} is down",
+				"description": fmt.Sprintf("The {{ $labels.link }} of {{ $labels.nf }} has been down for %s.", slo.Label(linkFor)),
+			},
+		}}},
+		{Name: "sa5g-error-budgets", Rules: []Rule{
+			burn("SA5GErrorBudgetFastBurn", fastBurn, slo.DefaultWindows[0], slo.DefaultWindows[1], "critical"),
+			burn("SA5GErrorBudgetSlowBurn", slowBurn, slo.DefaultWindows[1], slo.DefaultWindows[2], "warning"),
+		}},
+	}
+}
+
+// burn returns the rule alert, on the error budgets burning at over rate
+// over both the windows short and long.
+func burn(alert string, rate float64, short, long time.Duration, severity string) Rule {
+	series := func(window time.Duration) string {
+		return fmt.Sprintf("max by (namespace, nf, method) (%s{window=%q})", autoscale.BurnRate, slo.Label(window))
+	}
+	return Rule{
+		Alert:  alert,
+		Expr:   fmt.Sprintf("%s > %s and %s > %s", series(long), float(rate), series(short), float(rate)),
+		Labels: map[string]string{"severity": severity},
+		Annotations: map[string]string{
+			"summary":     "the error budget of {{ $labels.method }} of {{ $labels.nf }} of {{ $labels.namespace }} burns {{ $value | humanize }} times too fast",
+			"description": fmt.Sprintf("The error budget of {{ $labels.method }} of {{ $labels.nf }} burns at over %s times its sustainable rate over %s and %s.", float(rate), slo.Label(long), slo.Label(short)),
+		},
+	}
+}
+
+func float(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// percent formats the ratio f as a percentage, rounded to 6 digits.
+func percent(f float64) string {
+	return strconv.FormatFloat(f*100, 'g', 6, 64)
+}
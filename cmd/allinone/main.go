@@ -0,0 +1,146 @@
+// Command allinone runs the network functions of the stack in one process,
+// for a laptop or a CI job that wants to try them without a cluster:
+//
+//	allinone [-profile all]
+//	allinone -profile core
+//	allinone -profile udm,ausf,gnbcu
+//
+// -profile selects the NFs embedded: all of them, the core (the UDM and the
+// AUSF), the RAN (the gNB-CU and a gNB-DU), or a comma-separated list of
+// udm, ausf, gnbcu and gnbdu. The tree has no AMF, SMF, UPF or NRF to
+// embed. The embedded NFs call each other through the in-process transport
+// of pkg/kitx/inproc, the others over gRPC as their own binaries do. Each
+// serves its APIs on the ports of its own binary, read from the same
+// environment variables, so that cmd/scenario and the other clients work
+// unchanged. They keep their state in memory.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/go-kit/kit/log/level"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/bootstrap"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/gnodeb/f1"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/kitx/inproc"
+	udmservice "github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/udm/service"
+)
+
+const (
+	defProfile string = "all"
+	envProfile string = "QS_ALLINONE_PROFILE"
+)
+
+// spec is the process as bootstrapped: its logging, tracing and admin port,
+// the environment variables of its own being prefixed with QS_ALLINONE_.
+var spec = bootstrap.Spec{
+	Name:    "allinone",
+	Capture: true,
+}
+
+// The NFs that can be embedded, as their binaries are bootstrapped.
+var (
+	udmSpec   = bootstrap.Spec{Name: "udm", HTTPPort: "8380", GRPCPort: "8381"}
+	ausfSpec  = bootstrap.Spec{Name: "ausf", HTTPPort: "8480", GRPCPort: "8481"}
+	gnbcuSpec = bootstrap.Spec{Name: "gnbcu", HTTPPort: "8580", GRPCPort: "8581"}
+	gnbduSpec = bootstrap.Spec{Name: "gnbdu", HTTPPort: "8680", GRPCPort: "8681"}
+)
+
+// nfs are the names of the NFs that can be embedded, in the order they
+// start.
+var nfs = []string{udmSpec.Name, ausfSpec.Name, gnbcuSpec.Name, gnbduSpec.Name}
+
+// profiles are the sets of NFs -profile selects by name.
+var profiles = map[string][]string{
+	"all":  nfs,
+	"core": {udmSpec.Name, ausfSpec.Name},
+	"ran":  {gnbcuSpec.Name, gnbduSpec.Name},
+}
+
+func main() {
+	nf := bootstrap.New(spec)
+	profile := flag.String("profile", nf.String(envProfile, defProfile), "the NFs to embed: "+profileNames()+", or a comma-separated list of "+strings.Join(nfs, ", "))
+	flag.Parse()
+
+	embed, err := parseProfile(*profile)
+	if err != nil {
+		level.Error(nf.Logger).Log("profile", *profile, "err", err)
+		os.Exit(1)
+	}
+	level.Info(nf.Logger).Log("profile", *profile, "embedded", strings.Join(embed.list(), ","))
+
+	// the NFs are started in the order of nfs, those called before their
+	// callers
+	var udm udmservice.UdmService
+	if embed[udmSpec.Name] {
+		udm = startUDM(nf)
+	}
+	if embed[ausfSpec.Name] {
+		startAUSF(nf, udm)
+	}
+	// the gNB-CU dials the gNB-DUs embedded through the in-process
+	// transport, by the F1 address they announce
+	dus := map[string]*inproc.Server{}
+	var cu f1.CU
+	if embed[gnbcuSpec.Name] {
+		cu = startGNBCU(nf, dus)
+	}
+	if embed[gnbduSpec.Name] {
+		startGNBDU(nf, cu, dus)
+	}
+	nf.Run()
+}
+
+// profile is a set of NF names.
+type profile map[string]bool
+
+// list returns the NFs of p in the order of nfs.
+func (p profile) list() []string {
+	var names []string
+	for _, name := range nfs {
+		if p[name] {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// parseProfile returns the NFs of the profile s: the name of one of
+// profiles, or a comma-separated list of NFs.
+func parseProfile(s string) (profile, error) {
+	names, ok := profiles[s]
+	if !ok {
+		names = strings.Split(s, ",")
+	}
+	p := profile{}
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if !known(name) {
+			return nil, fmt.Errorf("no NF %q to embed, the NFs are %s", name, strings.Join(nfs, ", "))
+		}
+		p[name] = true
+	}
+	return p, nil
+}
+
+func known(name string) bool {
+	for _, nf := range nfs {
+		if nf == name {
+			return true
+		}
+	}
+	return false
+}
+
+func profileNames() string {
+	var names []string
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
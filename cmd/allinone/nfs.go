@@ -0,0 +1,328 @@
+package main
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"google.golang.org/grpc"
+	healthgrpc "google.golang.org/grpc/health/grpc_health_v1"
+
+	ausfpb "github.com/miki-tnt/sa5g-go-usvc-k8s/pb/ausf"
+	gnodebpb "github.com/miki-tnt/sa5g-go-usvc-k8s/pb/gnodeb"
+	udmpb "github.com/miki-tnt/sa5g-go-usvc-k8s/pb/udm"
+	udmpbv2 "github.com/miki-tnt/sa5g-go-usvc-k8s/pb/udm/v2"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/admin"
+	ausfendpoints "github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/ausf/endpoints"
+	ausfservice "github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/ausf/service"
+	ausftransports "github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/ausf/transports"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/autoscale"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/bootstrap"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/flags"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/gnodeb/cell"
+	cuendpoints "github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/gnodeb/cu/endpoints"
+	cuservice "github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/gnodeb/cu/service"
+	cutransports "github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/gnodeb/cu/transports"
+	duendpoints "github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/gnodeb/du/endpoints"
+	duservice "github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/gnodeb/du/service"
+	dutransports "github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/gnodeb/du/transports"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/gnodeb/f1"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/history"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/identity"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/kitx/chain"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/kitx/inproc"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/store"
+	udmendpoints "github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/udm/endpoints"
+	udmservice "github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/udm/service"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/udm/subscriber"
+	udmtransports "github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/udm/transports"
+)
+
+// The environment variables of the embedded NFs read by their binaries,
+// which keep their meaning here.
+const (
+	defSubscribersFile string = ""
+	defAuthTTL         string = "5m"
+	defUdmURL          string = "localhost:8381"
+	defGNBDUID         string = "1"
+	defCUURL           string = "localhost:8581"
+	defF1Address       string = ""
+	defF1SetupRetry    string = "5s"
+	defCellsFile       string = ""
+	defAccessPRBs      string = "6"
+	defCapMaxSize      string = "65536"
+	defCapCompressSize string = "1024"
+	defCapTTL          string = "24h"
+	defInactivity      string = "10s"
+
+	envSubscribersFile string = "QS_UDM_SUBSCRIBERS_FILE"
+	envAuthTTL         string = "QS_AUSF_AUTH_TTL"
+	envUdmURL          string = "QS_UDM_URL"
+	envGNBDUID         string = "QS_GNBDU_ID"
+	envCUURL           string = "QS_GNBDU_CU_URL"
+	envF1Address       string = "QS_GNBDU_F1_ADDRESS"
+	envF1SetupRetry    string = "QS_GNBDU_F1_SETUP_RETRY"
+	envCellsFile       string = "QS_GNBDU_CELLS_FILE"
+	envAccessPRBs      string = "QS_GNBDU_ACCESS_PRBS"
+	envGNBCUGNBID      string = "QS_GNBCU_GNB_ID"
+	envGNBDUGNBID      string = "QS_GNBDU_GNB_ID"
+	envCapMaxSize      string = "QS_GNBCU_UE_CAPABILITY_MAX_SIZE"
+	envCapCompressSize string = "QS_GNBCU_UE_CAPABILITY_COMPRESS_SIZE"
+	envCapTTL          string = "QS_GNBCU_UE_CAPABILITY_TTL"
+	envInactivity      string = "QS_GNBCU_INACTIVITY_TIMER"
+)
+
+// embedded is an NF embedded in the process.
+type embedded struct {
+	nf   *bootstrap.NF
+	spec bootstrap.Spec
+	// id is the identity of the NF, an instance of its own within that of
+	// the process.
+	id     identity.NF
+	logger log.Logger
+}
+
+// embed returns the NF of spec, reported as serving to the health checks
+// of the gRPC servers, its error budgets exported apart.
+func embed(nf *bootstrap.NF, spec bootstrap.Spec) embedded {
+	nf.Health.SetServingStatus(spec.Name, healthgrpc.HealthCheckResponse_SERVING)
+	nf.SLO.NF(spec.Name).Export(nf.Load.NF(spec.Name))
+	id := nf.Identity
+	id.Type = spec.NFType()
+	id.Name = nf.Identity.Name + "/" + spec.Name
+	id.InstanceID = identity.NameBased(spec.Name + "." + nf.Identity.InstanceID.String())
+	return embedded{nf: nf, spec: spec, id: id, logger: log.With(nf.Logger, "nf", spec.Name)}
+}
+
+// gnbID returns the gNB ID of the NF in the environment variable env, if
+// any. It exits when the gNB ID is malformed.
+func (e embedded) gnbID(env string) identity.GNBID {
+	s := e.nf.String(env, "")
+	if s == "" {
+		return identity.GNBID{}
+	}
+	id, err := identity.ParseGNBID(s)
+	if err != nil {
+		level.Error(e.logger).Log("env", env, "error", err)
+		os.Exit(1)
+	}
+	return id
+}
+
+// middleware returns the middleware of the endpoints of the NF: that of the
+// process, logging for the NF, counting its load and tracking its error
+// budgets apart and identifying it to the NFs it calls.
+func (e embedded) middleware() chain.MiddlewareConfig {
+	mw := e.nf.Middleware()
+	mw.Logger = log.With(e.nf.RequestLogger(), "nf", e.spec.Name)
+	mw.Load = e.nf.Load.NF(e.spec.Name)
+	mw.SLO = e.nf.SLO.NF(e.spec.Name)
+	mw.NFInstanceID = e.id.InstanceID.String()
+	return mw
+}
+
+// ports returns the ports of the APIs of the NF, from the environment
+// variables of its binary.
+func (e embedded) ports() (httpPort, grpcPort string) {
+	return e.nf.String(e.spec.Var("HTTP_PORT"), e.spec.HTTPPort), e.nf.String(e.spec.Var("GRPC_PORT"), e.spec.GRPCPort)
+}
+
+// startUDM starts a UDM serving every PLMN with its feature flags off, and
+// returns its in-process client.
+func startUDM(nf *bootstrap.NF) udmservice.UdmService {
+	e := embed(nf, udmSpec)
+	st := store.NewMemory()
+	subscribers := subscriber.NewRepository(st)
+	if file := nf.String(envSubscribersFile, defSubscribersFile); file != "" {
+		loadSubscribers(subscribers, file, e.logger)
+	}
+	service := udmservice.New(subscribers, history.New(st), nil, flags.Static(nil), e.logger)
+	endpoints := udmendpoints.New(service, e.middleware())
+
+	httpPort, grpcPort := e.ports()
+	nf.HTTP(httpPort, func(m *http.ServeMux) {
+		m.Handle("/", udmtransports.NewHTTPHandler(endpoints, nf.Tracer, nf.ZipkinTracer, e.logger))
+	})
+	nf.GRPC(grpcPort, func(s *grpc.Server) {
+		udmpb.RegisterUdmServer(s, udmtransports.MakeGRPCServer(endpoints, nf.Tracer, nf.ZipkinTracer, e.logger))
+		udmpbv2.RegisterUdmServer(s, udmtransports.MakeGRPCServerV2(endpoints, nf.Tracer, nf.ZipkinTracer, e.logger))
+	})
+	is := udmtransports.MakeInprocServer(endpoints, e.logger)
+	go is.Serve()
+	return udmtransports.NewInprocClient(is)
+}
+
+// startAUSF starts an AUSF serving every PLMN, which authenticates the
+// subscribers of udm, or of the UDM at QS_UDM_URL when udm is nil.
+func startAUSF(nf *bootstrap.NF, udm udmservice.UdmService) {
+	e := embed(nf, ausfSpec)
+	if udm == nil {
+		url := nf.String(envUdmURL, defUdmURL)
+		conn, err := grpc.Dial(url, nf.DialOptions()...)
+		if err != nil {
+			level.Error(e.logger).Log("envUdmURL", envUdmURL, "error", err)
+			os.Exit(1)
+		}
+		udm = udmtransports.NewGRPCClient(conn, nf.Tracer, nf.ZipkinTracer, e.logger)
+	}
+	service := ausfservice.New(udm, store.NewMemory(), nf.Duration(envAuthTTL, defAuthTTL), nil, e.logger)
+	endpoints := ausfendpoints.New(service, e.middleware())
+
+	httpPort, grpcPort := e.ports()
+	nf.HTTP(httpPort, func(m *http.ServeMux) {
+		m.Handle("/", ausftransports.NewHTTPHandler(endpoints, nf.Tracer, nf.ZipkinTracer, e.logger))
+	})
+	nf.GRPC(grpcPort, func(s *grpc.Server) {
+		ausfpb.RegisterAusfServer(s, ausftransports.MakeGRPCServer(endpoints, nf.Tracer, nf.ZipkinTracer, e.logger))
+	})
+}
+
+// startGNBCU starts a gNB-CU, and returns its in-process client. It
+// connects to the gNB-DUs of dus through the in-process transport, to the
+// others over gRPC.
+func startGNBCU(nf *bootstrap.NF, dus map[string]*inproc.Server) f1.CU {
+	e := embed(nf, gnbcuSpec)
+	reg := cuservice.NewRegistry()
+	nf.Admin(admin.Pool("f1", func() interface{} { return reg.Stats() }))
+	nf.Load.NF(e.spec.Name).Gauge(autoscale.ActiveUEs, func() float64 { return float64(reg.ActiveUEs()) })
+	dial := func(address string) (f1.DU, io.Closer, error) {
+		if is, ok := dus[address]; ok {
+			return dutransports.NewInprocClient(is), nopCloser{}, nil
+		}
+		conn, err := grpc.Dial(address, nf.DialOptions()...)
+		if err != nil {
+			return nil, nil, err
+		}
+		return dutransports.NewGRPCClient(conn, nf.Tracer, nf.ZipkinTracer, e.logger), conn, nil
+	}
+	caps := cuservice.NewCapabilities(nf.Store,
+		cuservice.CapabilityMaxSize(nf.Int(envCapMaxSize, defCapMaxSize)),
+		cuservice.CapabilityCompressSize(nf.Int(envCapCompressSize, defCapCompressSize)),
+		cuservice.CapabilityTTL(nf.Duration(envCapTTL, defCapTTL)),
+		cuservice.CapabilityLogger(e.logger))
+	nf.Admin(admin.Pool("ue_capabilities", func() interface{} { return caps.Stats() }))
+	service := cuservice.New(e.id.Name, reg, dial, e.logger, cuservice.GNBID(e.gnbID(envGNBCUGNBID)), cuservice.UECapabilities(caps),
+		cuservice.Inactivity(nf.Duration(envInactivity, defInactivity)))
+	endpoints := cuendpoints.New(service, e.middleware())
+
+	// the gNB-CU has no HTTP API
+	_, grpcPort := e.ports()
+	nf.GRPC(grpcPort, func(s *grpc.Server) {
+		gnodebpb.RegisterF1CUServer(s, cutransports.MakeGRPCServer(endpoints, nf.Tracer, nf.ZipkinTracer, e.logger))
+	})
+	is := cutransports.MakeInprocServer(endpoints, e.logger)
+	go is.Serve()
+	return cutransports.NewInprocClient(is)
+}
+
+// startGNBDU starts a gNB-DU, which sets up the F1 with cu, or with the
+// gNB-CU at QS_GNBDU_CU_URL when cu is nil, and adds its in-process server
+// to dus under its F1 address.
+func startGNBDU(nf *bootstrap.NF, cu f1.CU, dus map[string]*inproc.Server) {
+	e := embed(nf, gnbduSpec)
+	if cu == nil {
+		url := nf.String(envCUURL, defCUURL)
+		conn, err := grpc.Dial(url, nf.DialOptions()...)
+		if err != nil {
+			level.Error(e.logger).Log("envCUURL", envCUURL, "error", err)
+			os.Exit(1)
+		}
+		cu = cutransports.NewGRPCClient(conn, nf.Tracer, nf.ZipkinTracer, e.logger)
+	}
+	cells := cell.NewManager()
+	if file := nf.String(envCellsFile, defCellsFile); file != "" {
+		loadCells(cells, file, e.gnbID(envGNBDUGNBID), e.logger)
+	}
+	nf.Admin(admin.Pool("cells", func() interface{} { return cells.List() }))
+	httpPort, grpcPort := e.ports()
+	address := nf.String(envF1Address, defF1Address)
+	if address == "" {
+		address = net.JoinHostPort(nf.String(e.spec.Var("SERVICE_HOST"), "localhost"), grpcPort)
+	}
+	service := duservice.New(nf.Uint(envGNBDUID, defGNBDUID), e.id.Name, address, cu, cells, nf.Int(envAccessPRBs, defAccessPRBs), e.logger)
+	nf.Load.NF(e.spec.Name).Gauge(autoscale.ActiveUEs, func() float64 {
+		ues, _ := service.ActiveUEs(context.Background())
+		var n int
+		for _, u := range ues {
+			n += u
+		}
+		return float64(n)
+	})
+	var f1Up int32
+	nf.Load.NF(e.spec.Name).Gauge(autoscale.LinkUp, func() float64 { return float64(atomic.LoadInt32(&f1Up)) }, "link", "f1")
+	endpoints := duendpoints.New(service, e.middleware())
+
+	nf.HTTP(httpPort, func(m *http.ServeMux) {
+		m.Handle("/", dutransports.NewHTTPHandler(endpoints, nf.Tracer, nf.ZipkinTracer, e.logger))
+	})
+	nf.GRPC(grpcPort, func(s *grpc.Server) {
+		gnodebpb.RegisterF1DUServer(s, dutransports.MakeGRPCServer(endpoints, nf.Tracer, nf.ZipkinTracer, e.logger))
+	})
+	is := dutransports.MakeInprocServer(endpoints, e.logger)
+	go is.Serve()
+	dus[address] = is
+	go setupF1(service, nf.Duration(envF1SetupRetry, defF1SetupRetry), &f1Up, e.logger)
+}
+
+// nopCloser closes the in-process clients, which hold nothing to close.
+type nopCloser struct{}
+
+func (nopCloser) Close() error { return nil }
+
+func loadSubscribers(subscribers subscriber.Repository, file string, logger log.Logger) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		level.Error(logger).Log("subscribers", file, "err", err)
+		os.Exit(1)
+	}
+	n, err := subscriber.Load(context.Background(), subscribers, data)
+	if err != nil {
+		level.Error(logger).Log("subscribers", file, "err", err)
+		os.Exit(1)
+	}
+	level.Info(logger).Log("subscribers", file, "loaded", n)
+}
+
+// loadCells loads the cells of file, which must be cells of gnbID if it is
+// set.
+func loadCells(cells *cell.Manager, file string, gnbID identity.GNBID, logger log.Logger) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		level.Error(logger).Log("cells", file, "err", err)
+		os.Exit(1)
+	}
+	n, err := cells.Load(data)
+	if err != nil {
+		level.Error(logger).Log("cells", file, "err", err)
+		os.Exit(1)
+	}
+	for _, s := range cells.List() {
+		if !gnbID.IsZero() && !gnbID.Serves(s.NCI) {
+			level.Error(logger).Log("cells", file, "nci", s.NCI, "err", "not a cell of gNB "+gnbID.String())
+			os.Exit(1)
+		}
+	}
+	level.Info(logger).Log("cells", file, "loaded", n)
+}
+
+// setupF1 sets up the F1 with the gNB-CU, every retry until it succeeds,
+// then sets up to 1.
+func setupF1(svc duservice.GnbduService, retry time.Duration, up *int32, logger log.Logger) {
+	for {
+		rs, err := svc.F1Setup(context.Background())
+		if err == nil {
+			level.Info(logger).Log("f1", "setup", "gnb_cu_name", rs.CUName, "activated", len(rs.Activated))
+			atomic.StoreInt32(up, 1)
+			return
+		}
+		level.Warn(logger).Log("f1", "setup", "retry", retry, "err", err)
+		time.Sleep(retry)
+	}
+}
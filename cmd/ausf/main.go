@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/go-kit/kit/metrics/expvar"
+	"google.golang.org/grpc"
+
+	pb "github.com/miki-tnt/sa5g-go-usvc-k8s/pb/ausf"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/admin"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/ausf/endpoints"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/ausf/service"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/ausf/transports"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/bootstrap"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/caller"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/grpcpool"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/hedge"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/loglevel"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/plmn"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/schema"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/shadow"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/store"
+	udmendpoints "github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/udm/endpoints"
+	udmservice "github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/udm/service"
+	udmtransports "github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/udm/transports"
+)
+
+const (
+	defAuthTTL       string = "5m"
+	defUdmURL        string = "localhost:8381"
+	defUdmName       string = "udm"
+	defGRPCPool      string = "4"
+	defGRPCMaxEject  string = "50"
+	defUdmHedgeRatio string = "0.1"
+	defUdmHedgePct   string = "0.95"
+	defUdmShadowURL  string = ""
+	defUdmShadowRate string = "0.01"
+
+	envAuthTTL       string = "QS_AUSF_AUTH_TTL"
+	envUdmURL        string = "QS_UDM_URL"
+	envUdmName       string = "QS_UDM_SERVICE_NAME"
+	envGRPCPool      string = "QS_AUSF_GRPC_POOL_SIZE"
+	envGRPCMaxEject  string = "QS_AUSF_GRPC_MAX_EJECTION_PERCENT"
+	envUdmHedgeRatio string = "QS_AUSF_UDM_HEDGE_RATIO"
+	envUdmHedgePct   string = "QS_AUSF_UDM_HEDGE_PERCENTILE"
+	envUdmShadowURL  string = "QS_AUSF_UDM_SHADOW_URL"
+	envUdmShadowRate string = "QS_AUSF_UDM_SHADOW_RATIO"
+)
+
+// spec is the AUSF as bootstrapped, the environment variables of its own
+// being prefixed with QS_AUSF_.
+var spec = bootstrap.Spec{
+	Name:      "ausf",
+	HTTPPort:  "8480",
+	GRPCPort:  "8481",
+	Capture:   true,
+	Limits:    true,
+	Admission: true,
+	PLMNs:     true,
+	Redis:     true,
+	Postgres:  true,
+	Etcd:      true,
+	Schemas:   []*schema.Schema{service.AuthContextSchema},
+}
+
+type config struct {
+	bootstrap.Config
+	authTTL       time.Duration
+	udmURL        string
+	udmName       string
+	grpcPool      int
+	grpcMaxEject  int
+	udmHedgeRatio float64
+	udmHedgePct   float64
+	udmShadowURL  string
+	udmShadowRate float64
+}
+
+func main() {
+	nf := bootstrap.New(spec)
+	cfg := loadConfig(nf)
+	nf.Report(cfg)
+	logger := nf.Logger
+
+	// udm grpc connection pool
+	outliers := grpcpool.DefaultOutliers
+	outliers.MaxEjectedPercent = cfg.grpcMaxEject
+	pool, err := grpcpool.New(
+		context.Background(),
+		cfg.udmURL,
+		cfg.grpcPool,
+		grpcpool.HealthService(cfg.udmName),
+		grpcpool.OutlierDetection(outliers),
+		grpcpool.EjectionCounter(expvar.NewCounter("udm_ejections")),
+		grpcpool.DialOptions(append(nf.DialOptions(), grpc.WithChainUnaryInterceptor(caller.UnaryClientInterceptor(cfg.NFInstanceID.String())))...),
+		grpcpool.Logger(log.With(logger, loglevel.ComponentKey, "grpcpool", "pool", cfg.udmName)),
+	)
+	if err != nil {
+		level.Error(logger).Log("serviceName", cfg.udmURL, "error", err)
+		os.Exit(1)
+	}
+	defer pool.Close()
+	nf.Admin(admin.Pool(cfg.udmName, func() interface{} { return pool.Stats() }))
+
+	// the reads of the UDM are hedged, up to udmHedgeRatio of them
+	hedging := []hedge.Option{
+		hedge.MaxRatio(cfg.udmHedgeRatio),
+		hedge.Percentile(cfg.udmHedgePct),
+		hedge.Counter(expvar.NewCounter("udm_hedges")),
+	}
+	var udm udmservice.UdmService = udmtransports.NewGRPCPoolClientV2(pool, nf.Tracer, nf.ZipkinTracer, logger, hedging...)
+
+	// udmShadowRate of the calls of the UDM are mirrored to the shadow UDM,
+	// a canary build usually, its responses compared and dropped
+	if cfg.udmShadowURL != "" {
+		shadowPool, err := grpcpool.New(
+			context.Background(),
+			cfg.udmShadowURL,
+			1,
+			grpcpool.HealthService(cfg.udmName),
+			grpcpool.DialOptions(append(nf.DialOptions(), grpc.WithChainUnaryInterceptor(caller.UnaryClientInterceptor(cfg.NFInstanceID.String())))...),
+			grpcpool.Logger(log.With(logger, loglevel.ComponentKey, "grpcpool", "pool", cfg.udmName+"_shadow")),
+		)
+		if err != nil {
+			level.Error(logger).Log("serviceName", cfg.udmShadowURL, "error", err)
+			os.Exit(1)
+		}
+		defer shadowPool.Close()
+		mirror := shadow.New(
+			shadow.Ratio(cfg.udmShadowRate),
+			shadow.Counter(expvar.NewCounter("udm_shadow_calls")),
+			shadow.Logger(log.With(logger, loglevel.ComponentKey, "shadow")),
+		)
+		canary := udmtransports.NewGRPCPoolClientV2(shadowPool, nf.Tracer, nf.ZipkinTracer, logger)
+		udm = udmendpoints.Mirrored(udm, canary, mirror)
+		nf.Admin(admin.Pool(cfg.udmName+"_shadow", func() interface{} { return mirror.Stats() }))
+	}
+	service := NewServer(udm, nf.Store, cfg.authTTL, cfg.ServedPLMNs, nf.RequestLogger())
+	// the emergency requests skip the rate limits
+	mw := nf.Middleware()
+	mw.EmergencyBypass = true
+	endpoints := endpoints.New(service, mw)
+
+	nf.HTTP(cfg.HTTPPort, func(m *http.ServeMux) {
+		m.Handle("/", transports.NewHTTPHandler(endpoints, nf.Tracer, nf.ZipkinTracer, logger))
+	})
+	nf.GRPC(cfg.GRPCPort, func(s *grpc.Server) {
+		pb.RegisterAusfServer(s, transports.MakeGRPCServer(endpoints, nf.Tracer, nf.ZipkinTracer, logger))
+	})
+	nf.Run()
+}
+
+func loadConfig(nf *bootstrap.NF) (cfg config) {
+	cfg.Config = nf.Config
+	cfg.authTTL = nf.Duration(envAuthTTL, defAuthTTL)
+	cfg.udmURL = nf.String(envUdmURL, defUdmURL)
+	cfg.udmName = nf.String(envUdmName, defUdmName)
+	cfg.grpcPool = nf.Int(envGRPCPool, defGRPCPool)
+	cfg.grpcMaxEject = nf.Int(envGRPCMaxEject, defGRPCMaxEject)
+	cfg.udmHedgeRatio = nf.Float(envUdmHedgeRatio, defUdmHedgeRatio)
+	cfg.udmHedgePct = nf.Float(envUdmHedgePct, defUdmHedgePct)
+	cfg.udmShadowURL = nf.String(envUdmShadowURL, defUdmShadowURL)
+	cfg.udmShadowRate = nf.Float(envUdmShadowRate, defUdmShadowRate)
+	return cfg
+}
+
+func NewServer(udm udmservice.UdmService, st store.Store, authTTL time.Duration, served plmn.List, logger log.Logger) service.AusfService {
+	service := service.New(udm, st, authTTL, served, logger)
+	return service
+}
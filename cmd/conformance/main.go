@@ -0,0 +1,158 @@
+// Command conformance runs the conformance cases of pkg/conformance against
+// a deployed stack and reports which passed, failed or were skipped. It exits
+// with status 1 when a case failed. The RRC cases go through the gNB-DU of
+// -gnbdu, on its cell -nci, and are skipped without it. The expiry of the
+// authentication contexts is checked when -auth-ttl gives the
+// QS_AUSF_AUTH_TTL of the AUSF, the case waiting it out. -run selects the
+// cases by a regular expression on their names, and -json writes the report
+// in JSON.
+//
+//	conformance [-ausf localhost:8481] [-udm localhost:8381]
+//	conformance -gnbdu http://localhost:8680 -nci 4096
+//	conformance -auth-ttl 2s -run 'aka/(sync|context)'
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc"
+
+	ausfpb "github.com/miki-tnt/sa5g-go-usvc-k8s/pb/ausf"
+	udmpb "github.com/miki-tnt/sa5g-go-usvc-k8s/pb/udm"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/conformance"
+)
+
+const (
+	defAUSFAddr string = "localhost:8481"
+	defUDMAddr  string = "localhost:8381"
+	// the subscribers of the cases are provisioned from imsi-208930000009001,
+	// with the K and OPc of the test set 1 of TS 35.208
+	defSUPI               string = "imsi-208930000009001"
+	defK                  string = "465b5ce8b199b49faa5f0a2ee238a6bc"
+	defOPc                string = "cd63cb71954a9f4e48a5994e37a02baf"
+	defServingNetworkName string = "5G:mnc093.mcc208.3gppnetwork.org"
+	envAUSFAddr           string = "QS_CONFORMANCE_AUSF_ADDR"
+	envUDMAddr            string = "QS_CONFORMANCE_UDM_ADDR"
+	envGNBDU              string = "QS_CONFORMANCE_GNBDU"
+)
+
+// Env reads specified environment variable. If no value has been found,
+// fallback is returned.
+func env(key string, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+func main() {
+	fs := flag.NewFlagSet("conformance", flag.ExitOnError)
+	ausfAddr := fs.String("ausf", env(envAUSFAddr, defAUSFAddr), "gRPC address of the AUSF")
+	udmAddr := fs.String("udm", env(envUDMAddr, defUDMAddr), "gRPC address of the UDM")
+	gnbdu := fs.String("gnbdu", os.Getenv(envGNBDU), "HTTP URL of the gNB-DU the RRC cases go through, such as http://localhost:8680, none by default")
+	nci := fs.Uint64("nci", 4096, "NCI of an active cell of the gNB-DU")
+	authTTL := fs.Duration("auth-ttl", 0, "authentication context TTL of the AUSF, its QS_AUSF_AUTH_TTL; the expiry case is skipped when 0")
+	supi := fs.String("supi", defSUPI, "SUPI of the subscriber of the first case, the others following")
+	k := fs.String("k", defK, "K of the subscribers, in hex")
+	opc := fs.String("opc", defOPc, "OPc of the subscribers, in hex")
+	snn := fs.String("snn", defServingNetworkName, "serving network name")
+	run := fs.String("run", "", "regular expression selecting the cases by name, all of them by default")
+	timeout := fs.Duration("timeout", 5*time.Second, "timeout of every case")
+	asJSON := fs.Bool("json", false, "write the report in JSON")
+	list := fs.Bool("list", false, "list the cases and exit")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: conformance [flags]\n\nflags:\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(os.Args[1:])
+	if fs.NArg() != 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	if *list {
+		for _, c := range conformance.Cases {
+			fmt.Printf("%s\t%s\n", c.Name, c.Clause)
+		}
+		return
+	}
+
+	var match func(string) bool
+	if *run != "" {
+		re, err := regexp.Compile(*run)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "conformance: -run: %v\n", err)
+			os.Exit(2)
+		}
+		match = re.MatchString
+	}
+	if !strings.HasPrefix(*supi, "imsi-") {
+		fmt.Fprintf(os.Stderr, "conformance: -supi %q is not an IMSI based SUPI\n", *supi)
+		os.Exit(2)
+	}
+	sub := conformance.Subscriber{SUPI: *supi, ServingNetworkName: *snn}
+	var err error
+	if sub.K, err = hex.DecodeString(*k); err != nil {
+		fmt.Fprintf(os.Stderr, "conformance: -k: %v\n", err)
+		os.Exit(2)
+	}
+	if sub.OPc, err = hex.DecodeString(*opc); err != nil {
+		fmt.Fprintf(os.Stderr, "conformance: -opc: %v\n", err)
+		os.Exit(2)
+	}
+
+	// the connections are made lazily, an unreachable service shows up as
+	// failed cases
+	ausfConn, err := grpc.Dial(*ausfAddr, grpc.WithInsecure())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "conformance: %v\n", err)
+		os.Exit(1)
+	}
+	defer ausfConn.Close()
+	udmConn, err := grpc.Dial(*udmAddr, grpc.WithInsecure())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "conformance: %v\n", err)
+		os.Exit(1)
+	}
+	defer udmConn.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		c := make(chan os.Signal, 1)
+		signal.Notify(c, syscall.SIGINT, syscall.SIGTERM)
+		<-c
+		cancel()
+	}()
+
+	t := conformance.Targets{
+		AUSF:       ausfpb.NewAusfClient(ausfConn),
+		UDM:        udmpb.NewUdmClient(udmConn),
+		Subscriber: sub,
+		AuthTTL:    *authTTL,
+		NCI:        *nci,
+		Timeout:    *timeout,
+	}
+	if *gnbdu != "" {
+		t.GNBDU = conformance.NewGNBDU(*gnbdu)
+	}
+	rep := conformance.Run(ctx, conformance.Cases, t, match)
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(rep)
+	} else {
+		rep.Write(os.Stdout)
+	}
+	if len(rep.Failures()) != 0 {
+		os.Exit(1)
+	}
+}
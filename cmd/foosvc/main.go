@@ -1,179 +1,118 @@
 package main
 
 import (
-	"fmt"
-	"net"
+	"context"
 	"net/http"
 	"os"
-	"os/signal"
-	"syscall"
 
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
-	kitgrpc "github.com/go-kit/kit/transport/grpc"
+	"github.com/go-kit/kit/metrics/expvar"
 	stdopentracing "github.com/opentracing/opentracing-go"
-	"github.com/openzipkin/zipkin-go"
 	stdzipkin "github.com/openzipkin/zipkin-go"
-	zipkinhttp "github.com/openzipkin/zipkin-go/reporter/http"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/health"
-	healthgrpc "google.golang.org/grpc/health/grpc_health_v1"
-	"google.golang.org/grpc/reflection"
 
 	pb "github.com/miki-tnt/sa5g-go-usvc-k8s/pb/foosvc"
 	addsvctransports "github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/addsvc/transports"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/admin"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/bootstrap"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/caller"
 	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/foosvc/endpoints"
 	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/foosvc/service"
 	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/foosvc/transports"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/grpcpool"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/loglevel"
 )
 
 const (
-	defZipkinV2URL string = ""
-	defNameSpace   string = "sa5g-go-usvc-k8s"
-	defServiceName string = "foosvc"
-	defLogLevel    string = "error"
-	defServiceHost string = "localhost"
-	defHTTPPort    string = "8180"
-	defGRPCPort    string = "8181"
-	defAddsvcURL   string = ""
-
-	envZipkinV2URL string = "QS_ZIPKIN_V2_URL"
-	envNameSpace   string = "QS_FOOSVC_NAMESPACE"
-	envServiceName string = "QS_FOOSVC_SERVICE_NAME"
-	envLogLevel    string = "QS_FOOSVC_LOG_LEVEL"
-	envServiceHost string = "QS_FOOSVC_SERVICE_HOST"
-	envHTTPPort    string = "QS_FOOSVC_HTTP_PORT"
-	envGRPCPort    string = "QS_FOOSVC_GRPC_PORT"
-	envAddsvcURL   string = "QS_ADDSVC_URL"
+	defAddsvcURL    string = ""
+	defAddsvcName   string = "addsvc"
+	defGRPCPool     string = "4"
+	defGRPCMaxEject string = "50"
+
+	envAddsvcURL    string = "QS_ADDSVC_URL"
+	envAddsvcName   string = "QS_ADDSVC_SERVICE_NAME"
+	envGRPCPool     string = "QS_FOOSVC_GRPC_POOL_SIZE"
+	envGRPCMaxEject string = "QS_FOOSVC_GRPC_MAX_EJECTION_PERCENT"
 )
 
-type config struct {
-	nameSpace   string
-	serviceName string
-	logLevel    string
-	serviceHost string
-	httpPort    string
-	grpcPort    string
-	zipkinV2URL string
-	addsvcURL   string
+// spec is foosvc as bootstrapped, the environment variables of its own
+// being prefixed with QS_FOOSVC_.
+var spec = bootstrap.Spec{
+	Name:        "foosvc",
+	HTTPPort:    "8180",
+	GRPCPort:    "8181",
+	Capture:     true,
+	Limits:      true,
+	Idempotency: true,
 }
 
-// Env reads specified environment variable. If no value has been found,
-// fallback is returned.
-func env(key string, fallback string) string {
-	if v := os.Getenv(key); v != "" {
-		return v
-	}
-	return fallback
+type config struct {
+	bootstrap.Config
+	addsvcURL    string
+	addsvcName   string
+	grpcPool     int
+	grpcMaxEject int
 }
 
 func main() {
-	var logger log.Logger
-	{
-		logger = log.NewLogfmtLogger(os.Stderr)
-		logger = level.NewFilter(logger, level.AllowInfo())
-		logger = log.With(logger, "ts", log.DefaultTimestampUTC)
-		logger = log.With(logger, "caller", log.DefaultCaller)
-	}
-	cfg := loadConfig(logger)
-	logger = log.With(logger, "service", cfg.serviceName)
+	nf := bootstrap.New(spec)
+	cfg := loadConfig(nf)
+	nf.Report(cfg)
+	logger := nf.Logger
 
-	// addsvc grpc connection
-	var conn *grpc.ClientConn
+	// addsvc grpc connection pool
+	var pool *grpcpool.Pool
 	{
 		var err error
 		if cfg.addsvcURL != "" {
-			conn, err = grpc.Dial(cfg.addsvcURL, grpc.WithInsecure())
+			outliers := grpcpool.DefaultOutliers
+			outliers.MaxEjectedPercent = cfg.grpcMaxEject
+			pool, err = grpcpool.New(
+				context.Background(),
+				cfg.addsvcURL,
+				cfg.grpcPool,
+				grpcpool.HealthService(cfg.addsvcName),
+				grpcpool.OutlierDetection(outliers),
+				grpcpool.EjectionCounter(expvar.NewCounter("addsvc_ejections")),
+				grpcpool.DialOptions(grpc.WithInsecure(), grpc.WithUnaryInterceptor(caller.UnaryClientInterceptor(cfg.NFInstanceID.String()))),
+				grpcpool.Logger(log.With(logger, loglevel.ComponentKey, "grpcpool", "pool", cfg.addsvcName)),
+			)
 			if err != nil {
 				level.Error(logger).Log("serviceName", cfg.addsvcURL, "error", err)
 				os.Exit(1)
 			}
+			nf.Admin(admin.Pool(cfg.addsvcName, func() interface{} { return pool.Stats() }))
 		}
 	}
 
-	tracer := initOpentracing()
-	zipkinTracer := initZipkin(cfg.serviceName, cfg.httpPort, cfg.zipkinV2URL, logger)
-
-	service := NewServer(conn, tracer, zipkinTracer, logger)
-	endpoints := endpoints.New(service, logger, tracer, zipkinTracer)
-
-	errs := make(chan error, 2)
-	hs := health.NewServer()
-	hs.SetServingStatus(cfg.serviceName, healthgrpc.HealthCheckResponse_SERVING)
-	go startHTTPServer(endpoints, tracer, zipkinTracer, cfg.httpPort, logger, errs)
-	go startGRPCServer(endpoints, tracer, zipkinTracer, cfg.grpcPort, hs, logger, errs)
-
-	go func() {
-		c := make(chan os.Signal)
-		signal.Notify(c, syscall.SIGINT)
-		errs <- fmt.Errorf("%s", <-c)
-	}()
-
-	err := <-errs
-	level.Info(logger).Log("serviceName", cfg.serviceName, "terminated", err)
+	service := NewServer(pool, nf.Tracer, nf.ZipkinTracer, nf.RequestLogger())
+	// the latencies carry the traces of their calls as exemplars, served
+	// with them on the admin port, and pushed over OTLP if configured
+	mw := nf.Middleware()
+	mw.Duration = nf.RequestDuration()
+	endpoints := endpoints.New(service, mw)
+
+	nf.HTTP(cfg.HTTPPort, func(m *http.ServeMux) {
+		m.Handle("/", transports.NewHTTPHandler(endpoints, nf.Tracer, nf.ZipkinTracer, logger))
+	})
+	nf.GRPC(cfg.GRPCPort, func(s *grpc.Server) {
+		pb.RegisterFoosvcServer(s, transports.MakeGRPCServer(endpoints, nf.Tracer, nf.ZipkinTracer, logger))
+	})
+	nf.Run()
 }
 
-func loadConfig(logger log.Logger) (cfg config) {
-	cfg.nameSpace = env(envNameSpace, defNameSpace)
-	cfg.serviceName = env(envServiceName, defServiceName)
-	cfg.logLevel = env(envLogLevel, defLogLevel)
-	cfg.serviceHost = env(envServiceHost, defServiceHost)
-	cfg.httpPort = env(envHTTPPort, defHTTPPort)
-	cfg.grpcPort = env(envGRPCPort, defGRPCPort)
-	cfg.zipkinV2URL = env(envZipkinV2URL, defZipkinV2URL)
-	cfg.addsvcURL = env(envAddsvcURL, defAddsvcURL)
+func loadConfig(nf *bootstrap.NF) (cfg config) {
+	cfg.Config = nf.Config
+	cfg.addsvcURL = nf.String(envAddsvcURL, defAddsvcURL)
+	cfg.addsvcName = nf.String(envAddsvcName, defAddsvcName)
+	cfg.grpcPool = nf.Int(envGRPCPool, defGRPCPool)
+	cfg.grpcMaxEject = nf.Int(envGRPCMaxEject, defGRPCMaxEject)
 	return cfg
 }
 
-func NewServer(conn *grpc.ClientConn, tracer stdopentracing.Tracer, zipkinTracer *stdzipkin.Tracer, logger log.Logger) (service.FoosvcService) {
-	addsvcservice := addsvctransports.NewGRPCClient(conn, tracer, zipkinTracer, logger)
+func NewServer(pool *grpcpool.Pool, tracer stdopentracing.Tracer, zipkinTracer *stdzipkin.Tracer, logger log.Logger) service.FoosvcService {
+	addsvcservice := addsvctransports.NewGRPCPoolClient(pool, tracer, zipkinTracer, logger)
 	service := service.New(addsvcservice, logger)
 	return service
 }
-
-func initOpentracing() (tracer stdopentracing.Tracer) {
-	return stdopentracing.GlobalTracer()
-}
-
-func initZipkin(serviceName, httpPort, zipkinV2URL string, logger log.Logger) (zipkinTracer *zipkin.Tracer) {
-	var (
-		err           error
-		hostPort      = fmt.Sprintf("localhost:%s", httpPort)
-		useNoopTracer = (zipkinV2URL == "")
-		reporter      = zipkinhttp.NewReporter(zipkinV2URL)
-	)
-	zEP, _ := zipkin.NewEndpoint(serviceName, hostPort)
-	zipkinTracer, err = zipkin.NewTracer(reporter, zipkin.WithLocalEndpoint(zEP), zipkin.WithNoopTracer(useNoopTracer))
-	if err != nil {
-		logger.Log("err", err)
-		os.Exit(1)
-	}
-	if !useNoopTracer {
-		logger.Log("tracer", "Zipkin", "type", "Native", "URL", zipkinV2URL)
-	}
-
-	return
-}
-
-func startHTTPServer(endpoints endpoints.Endpoints, tracer stdopentracing.Tracer, zipkinTracer *zipkin.Tracer, port string, logger log.Logger, errs chan error) {
-	p := fmt.Sprintf(":%s", port)
-	level.Info(logger).Log("protocol", "HTTP", "exposed", port)
-	errs <- http.ListenAndServe(p, transports.NewHTTPHandler(endpoints, tracer, zipkinTracer, logger))
-}
-
-func startGRPCServer(endpoints endpoints.Endpoints, tracer stdopentracing.Tracer, zipkinTracer *zipkin.Tracer, port string, hs *health.Server, logger log.Logger, errs chan error) {
-	p := fmt.Sprintf(":%s", port)
-	listener, err := net.Listen("tcp", p)
-	if err != nil {
-		level.Error(logger).Log("protocol", "GRPC", "listen", port, "err", err)
-		os.Exit(1)
-	}
-
-	var server *grpc.Server
-	level.Info(logger).Log("protocol", "GRPC", "protocol", "GRPC", "exposed", port)
-	server = grpc.NewServer(grpc.UnaryInterceptor(kitgrpc.Interceptor))
-	pb.RegisterFoosvcServer(server, transports.MakeGRPCServer(endpoints, tracer, zipkinTracer, logger))
-	healthgrpc.RegisterHealthServer(server, hs)
-	reflection.Register(server)
-	errs <- server.Serve(listener)
-}
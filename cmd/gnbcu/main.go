@@ -0,0 +1,121 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	stdopentracing "github.com/opentracing/opentracing-go"
+	"github.com/openzipkin/zipkin-go"
+	"google.golang.org/grpc"
+
+	pb "github.com/miki-tnt/sa5g-go-usvc-k8s/pb/gnodeb"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/admin"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/autoscale"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/bootstrap"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/gnodeb/cu/endpoints"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/gnodeb/cu/service"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/gnodeb/cu/transports"
+	dutransports "github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/gnodeb/du/transports"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/gnodeb/f1"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/gnodeb/xn"
+)
+
+const (
+	defCapabilityMaxSize      string = "65536"
+	defCapabilityCompressSize string = "1024"
+	defCapabilityTTL          string = "24h"
+	defInactivityTimer        string = "10s"
+	defXnNeighbours           string = ""
+
+	envCapabilityMaxSize      string = "QS_GNBCU_UE_CAPABILITY_MAX_SIZE"
+	envCapabilityCompressSize string = "QS_GNBCU_UE_CAPABILITY_COMPRESS_SIZE"
+	envCapabilityTTL          string = "QS_GNBCU_UE_CAPABILITY_TTL"
+	envInactivityTimer        string = "QS_GNBCU_INACTIVITY_TIMER"
+	envXnNeighbours           string = "QS_GNBCU_XN_NEIGHBOURS"
+)
+
+// spec is the gNB-CU as bootstrapped, the environment variables of its own
+// being prefixed with QS_GNBCU_.
+var spec = bootstrap.Spec{
+	Name:        "gnbcu",
+	HTTPPort:    "8580",
+	GRPCPort:    "8581",
+	Compression: true,
+	GNB:         true,
+}
+
+func main() {
+	nf := bootstrap.New(spec)
+	cfg := nf.Config
+	logger := nf.Logger
+
+	// the gNB-DUs announce where they serve their end of the F1 in the F1
+	// Setup, and the gNB-CU connects back to them
+	reg := service.NewRegistry()
+	nf.Admin(admin.Pool("f1", func() interface{} { return reg.Stats() }))
+	nf.Load.Gauge(autoscale.ActiveUEs, func() float64 { return float64(reg.ActiveUEs()) })
+	// the gNB-DUs drain first, their last RRC messages being served
+	nf.DrainAfter(reg.Addresses)
+	// the radio capabilities of the UEs outlive their connections in the
+	// store, shared with the other gNB-CUs when it is Redis
+	caps := service.NewCapabilities(nf.Store,
+		service.CapabilityMaxSize(nf.Int(envCapabilityMaxSize, defCapabilityMaxSize)),
+		service.CapabilityCompressSize(nf.Int(envCapabilityCompressSize, defCapabilityCompressSize)),
+		service.CapabilityTTL(nf.Duration(envCapabilityTTL, defCapabilityTTL)),
+		service.CapabilityLogger(logger))
+	nf.Admin(admin.Pool("ue_capabilities", func() interface{} { return caps.Stats() }))
+	// the RRC connections of the UEs without activity are released, the
+	// UEs going RRC idle
+	inactivity := nf.Duration(envInactivityTimer, defInactivityTimer)
+	// the UEs are handed over to the neighbour gNBs over the Xn, for the
+	// cells of their gNB IDs
+	neighbours, err := xn.ParseNeighbours(nf.String(envXnNeighbours, defXnNeighbours))
+	if err != nil {
+		level.Error(logger).Log("env", envXnNeighbours, "error", err)
+		os.Exit(1)
+	}
+	var options []service.Option
+	for _, n := range neighbours {
+		conn, err := grpc.Dial(n.Address, nf.DialOptions()...)
+		if err != nil {
+			level.Error(logger).Log("env", envXnNeighbours, "gnb_id", n.GNBID, "error", err)
+			os.Exit(1)
+		}
+		defer conn.Close()
+		options = append(options, service.Neighbour(n.GNBID, transports.NewXnGRPCClient(conn, nf.Tracer, nf.ZipkinTracer, logger)))
+	}
+	service := NewServer(cfg, reg, caps, inactivity, dialDU(nf.DialOptions(), nf.Tracer, nf.ZipkinTracer, logger), nf.RequestLogger(), options...)
+	// the F1 and the Xn are the gNB's own: their calls are neither limited
+	// nor broken
+	endpoints := endpoints.New(service, nf.Middleware())
+
+	// the gNB-CU has no HTTP API, only the log levels
+	nf.HTTP(cfg.HTTPPort, func(m *http.ServeMux) {})
+	nf.GRPC(cfg.GRPCPort, func(s *grpc.Server) {
+		pb.RegisterF1CUServer(s, transports.MakeGRPCServer(endpoints, nf.Tracer, nf.ZipkinTracer, logger))
+		pb.RegisterXnAPServer(s, transports.MakeXnGRPCServer(endpoints, nf.Tracer, nf.ZipkinTracer, logger))
+	})
+	nf.Run()
+}
+
+func NewServer(cfg bootstrap.Config, reg *service.Registry, caps *service.Capabilities, inactivity time.Duration, dial service.Dialer, logger log.Logger, options ...service.Option) service.GnbcuService {
+	options = append([]service.Option{service.GNBID(cfg.GNBID), service.UECapabilities(caps), service.Inactivity(inactivity)}, options...)
+	service := service.New(cfg.InstanceID, reg, dial, logger, options...)
+	return service
+}
+
+// dialDU returns the dialer of the gNB-CU: a gRPC connection to the F1 of
+// each gNB-DU, dialed with opts.
+func dialDU(opts []grpc.DialOption, tracer stdopentracing.Tracer, zipkinTracer *zipkin.Tracer, logger log.Logger) service.Dialer {
+	return func(address string) (f1.DU, io.Closer, error) {
+		conn, err := grpc.Dial(address, opts...)
+		if err != nil {
+			return nil, nil, err
+		}
+		return dutransports.NewGRPCClient(conn, tracer, zipkinTracer, logger), conn, nil
+	}
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"google.golang.org/grpc"
+
+	pb "github.com/miki-tnt/sa5g-go-usvc-k8s/pb/gnodeb"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/admin"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/autoscale"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/bootstrap"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/gnodeb/cell"
+	cutransports "github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/gnodeb/cu/transports"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/gnodeb/du/endpoints"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/gnodeb/du/service"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/gnodeb/du/transports"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/gnodeb/e2"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/gnodeb/f1"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/identity"
+)
+
+const (
+	defID           string = "1"
+	defCUURL        string = "localhost:8581"
+	defF1Address    string = ""
+	defF1SetupRetry string = "5s"
+	defCellsFile    string = ""
+	defAccessPRBs   string = "6"
+	defRICURL       string = ""
+	defE2Interval   string = "10s"
+
+	envID           string = "QS_GNBDU_ID"
+	envCUURL        string = "QS_GNBDU_CU_URL"
+	envF1Address    string = "QS_GNBDU_F1_ADDRESS"
+	envF1SetupRetry string = "QS_GNBDU_F1_SETUP_RETRY"
+	envCellsFile    string = "QS_GNBDU_CELLS_FILE"
+	envAccessPRBs   string = "QS_GNBDU_ACCESS_PRBS"
+	envRICURL       string = "QS_GNBDU_RIC_URL"
+	envE2Interval   string = "QS_GNBDU_E2_INTERVAL"
+)
+
+// spec is the gNB-DU as bootstrapped, the environment variables of its own
+// being prefixed with QS_GNBDU_.
+var spec = bootstrap.Spec{
+	Name:        "gnbdu",
+	HTTPPort:    "8680",
+	GRPCPort:    "8681",
+	Compression: true,
+	GNB:         true,
+}
+
+type config struct {
+	bootstrap.Config
+	id           uint64
+	cuURL        string
+	f1Address    string
+	f1SetupRetry time.Duration
+	cellsFile    string
+	accessPRBs   int
+	ricURL       string
+	e2Interval   time.Duration
+}
+
+func main() {
+	nf := bootstrap.New(spec)
+	cfg := loadConfig(nf)
+	nf.Report(cfg)
+	logger := nf.Logger
+
+	// the admission thresholds are the policies of the near-RT RIC
+	thresholds := e2.NewThresholds()
+	cells := cell.NewManager(cell.AdmissionHook(thresholds.Hook()))
+	if cfg.cellsFile != "" {
+		loadCells(cells, cfg.cellsFile, cfg.GNBID, logger)
+	}
+	nf.Admin(admin.Pool("cells", func() interface{} { return cells.List() }))
+	conn, err := grpc.Dial(cfg.cuURL, nf.DialOptions()...)
+	if err != nil {
+		level.Error(logger).Log("envCUURL", envCUURL, "error", err)
+		os.Exit(1)
+	}
+	defer conn.Close()
+	cu := cutransports.NewGRPCClient(conn, nf.Tracer, nf.ZipkinTracer, logger)
+	service := NewServer(cfg, cu, cells, nf.RequestLogger())
+	nf.Load.Gauge(autoscale.ActiveUEs, activeUEs(service))
+	// the F1 and the E2 would run over SCTP associations: the links the
+	// gNB-DU is alerted on
+	var f1Up int32
+	nf.Load.Gauge(autoscale.LinkUp, func() float64 { return float64(atomic.LoadInt32(&f1Up)) }, "link", "f1")
+	// the F1 is the gNB's own: its calls are neither limited nor broken
+	endpoints := endpoints.New(service, nf.Middleware())
+
+	if cfg.ricURL != "" {
+		agent := e2.NewAgent(cfg.InstanceID, cells, service.ActiveUEs, thresholds, logger, e2.Interval(cfg.e2Interval))
+		go startE2Agent(agent, cfg.ricURL, logger)
+		nf.Admin(admin.Pool("e2", func() interface{} { return agent.Status() }))
+		nf.Load.Gauge(autoscale.LinkUp, func() float64 {
+			if agent.Status().Connected {
+				return 1
+			}
+			return 0
+		}, "link", "e2")
+	}
+	nf.HTTP(cfg.HTTPPort, func(m *http.ServeMux) {
+		m.Handle("/", transports.NewHTTPHandler(endpoints, nf.Tracer, nf.ZipkinTracer, logger))
+	})
+	nf.GRPC(cfg.GRPCPort, func(s *grpc.Server) {
+		pb.RegisterF1DUServer(s, transports.MakeGRPCServer(endpoints, nf.Tracer, nf.ZipkinTracer, logger))
+	})
+	go setupF1(service, cfg.f1SetupRetry, &f1Up, logger)
+	nf.Run()
+}
+
+func loadConfig(nf *bootstrap.NF) (cfg config) {
+	cfg.Config = nf.Config
+	cfg.id = nf.Uint(envID, defID)
+	cfg.cuURL = nf.String(envCUURL, defCUURL)
+	cfg.f1Address = nf.String(envF1Address, defF1Address)
+	if cfg.f1Address == "" {
+		cfg.f1Address = net.JoinHostPort(cfg.ServiceHost, cfg.GRPCPort)
+	}
+	cfg.f1SetupRetry = nf.Duration(envF1SetupRetry, defF1SetupRetry)
+	cfg.cellsFile = nf.String(envCellsFile, defCellsFile)
+	cfg.accessPRBs = nf.Int(envAccessPRBs, defAccessPRBs)
+	cfg.ricURL = nf.String(envRICURL, defRICURL)
+	cfg.e2Interval = nf.Duration(envE2Interval, defE2Interval)
+	return cfg
+}
+
+// loadCells loads the cells of file, which must be cells of gnbID if it is
+// set.
+func loadCells(cells *cell.Manager, file string, gnbID identity.GNBID, logger log.Logger) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		level.Error(logger).Log("cells", file, "err", err)
+		os.Exit(1)
+	}
+	n, err := cells.Load(data)
+	if err != nil {
+		level.Error(logger).Log("cells", file, "err", err)
+		os.Exit(1)
+	}
+	for _, s := range cells.List() {
+		if !gnbID.IsZero() && !gnbID.Serves(s.NCI) {
+			level.Error(logger).Log("cells", file, "nci", s.NCI, "err", "not a cell of gNB "+gnbID.String())
+			os.Exit(1)
+		}
+	}
+	level.Info(logger).Log("cells", file, "loaded", n)
+}
+
+// activeUEs returns the number of UE contexts of the cells of svc.
+func activeUEs(svc service.GnbduService) func() float64 {
+	return func() float64 {
+		ues, _ := svc.ActiveUEs(context.Background())
+		var n int
+		for _, u := range ues {
+			n += u
+		}
+		return float64(n)
+	}
+}
+
+func NewServer(cfg config, cu f1.CU, cells *cell.Manager, logger log.Logger) service.GnbduService {
+	service := service.New(cfg.id, cfg.InstanceID, cfg.f1Address, cu, cells, cfg.accessPRBs, logger)
+	return service
+}
+
+// setupF1 sets up the F1 with the gNB-CU, every retry until it succeeds,
+// then sets up to 1.
+func setupF1(svc service.GnbduService, retry time.Duration, up *int32, logger log.Logger) {
+	for {
+		rs, err := svc.F1Setup(context.Background())
+		if err == nil {
+			level.Info(logger).Log("f1", "setup", "gnb_cu_name", rs.CUName, "activated", len(rs.Activated))
+			atomic.StoreInt32(up, 1)
+			return
+		}
+		level.Warn(logger).Log("f1", "setup", "retry", retry, "err", err)
+		time.Sleep(retry)
+	}
+}
+
+// startE2Agent connects the E2 node to the near-RT RIC at url.
+func startE2Agent(agent *e2.Agent, url string, logger log.Logger) {
+	conn, err := grpc.Dial(url, grpc.WithInsecure())
+	if err != nil {
+		level.Error(logger).Log("envRICURL", envRICURL, "error", err)
+		os.Exit(1)
+	}
+	defer conn.Close()
+	agent.Run(context.Background(), pb.NewE2Client(conn))
+}
@@ -0,0 +1,211 @@
+// Command migrate upgrades the records an NF keeps in Redis to the current
+// versions of their schemas (see package schema), offline, before or while
+// a release changing them rolls out:
+//
+//	migrate [-redis localhost:6379] [-prefix udm/] status udm
+//	migrate [-redis localhost:6379] [-prefix udm/] [-dry-run] up udm
+//
+// status counts the records of every schema of the NF by version, and lists
+// the migrations of the schemas. up rewrites the records of older versions,
+// keeping their time to live, and reports those failing their migrations,
+// which it leaves as they are; it exits with 1 when any does. A record is
+// rewritten only if unchanged since it was read, so that up may run while
+// the NF serves, the NF upgrading the records it reads by itself: migrate
+// spares it the upgrades on the hot path, and finds the records that would
+// fail them before it does.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/go-redis/redis"
+
+	ausfservice "github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/ausf/service"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/history"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/idpool"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/schema"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/udm/subscriber"
+)
+
+// schemas are the schemas of the NFs, by name, as in the Spec of their
+// main.
+var schemas = map[string][]*schema.Schema{
+	"udm":   {subscriber.Schema, history.Schema},
+	"ausf":  {ausfservice.AuthContextSchema},
+	"n3iwf": {idpool.Schema},
+}
+
+// attempts is the number of times a record changed by the NF while being
+// rewritten is read again.
+const attempts = 3
+
+func main() {
+	fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+	addr := fs.String("redis", "localhost:6379", "address of the Redis of the NF")
+	prefix := fs.String("prefix", "", "prefix of the keys of the NF, its name and a slash by default")
+	dryRun := fs.Bool("dry-run", false, "upgrade the records without rewriting them")
+	fs.Usage = func() {
+		nfs := make([]string, 0, len(schemas))
+		for nf := range schemas {
+			nfs = append(nfs, nf)
+		}
+		sort.Strings(nfs)
+		fmt.Fprintf(fs.Output(), "usage: migrate [flags] status|up <%s>\n\nflags:\n", strings.Join(nfs, "|"))
+		fs.PrintDefaults()
+	}
+	fs.Parse(os.Args[1:])
+	if fs.NArg() != 2 || (fs.Arg(0) != "status" && fs.Arg(0) != "up") || schemas[fs.Arg(1)] == nil {
+		fs.Usage()
+		os.Exit(2)
+	}
+	nf, up := fs.Arg(1), fs.Arg(0) == "up"
+	if *prefix == "" {
+		*prefix = nf + "/"
+	}
+
+	client := redis.NewClient(&redis.Options{Addr: *addr})
+	defer client.Close()
+	reports := make([]report, 0, len(schemas[nf]))
+	failed := false
+	for _, s := range schemas[nf] {
+		r, err := migrate(client, *prefix, s, up && !*dryRun, os.Stderr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "migrate: %s: %v\n", s.Name, err)
+			os.Exit(1)
+		}
+		reports = append(reports, r)
+		failed = failed || r.failed > 0
+	}
+	printReports(os.Stdout, reports, up)
+	if up && failed {
+		os.Exit(1)
+	}
+}
+
+// report is what migrate found of the records of a schema.
+type report struct {
+	schema *schema.Schema
+	// versions counts the records by the version they had.
+	versions map[int]int
+	// upgrading counts the records of older versions, upgraded those
+	// rewritten, failed those failing their migrations.
+	upgrading, upgraded, failed int
+}
+
+// migrate reports on the records of s under prefix, upgrading them with
+// up. The records failing their migrations are reported to errs.
+func migrate(client *redis.Client, prefix string, s *schema.Schema, up bool, errs io.Writer) (report, error) {
+	r := report{schema: s, versions: make(map[int]int)}
+	var cursor uint64
+	for {
+		keys, next, err := client.Scan(cursor, prefix+s.Prefix+"*", 1000).Result()
+		if err != nil {
+			return r, err
+		}
+		for _, key := range keys {
+			var err error
+			for i := 0; i < attempts; i++ {
+				if err = client.Watch(func(tx *redis.Tx) error { return r.record(tx, key, up, errs) }, key); err != redis.TxFailedErr {
+					break
+				}
+			}
+			if err != nil {
+				return r, fmt.Errorf("%s: %v", key, err)
+			}
+		}
+		if cursor = next; cursor == 0 {
+			return r, nil
+		}
+	}
+}
+
+// record reports on the record of key, read in tx, and rewrites it
+// upgraded with up, only if it is unchanged since.
+func (r *report) record(tx *redis.Tx, key string, up bool, errs io.Writer) error {
+	data, err := tx.Get(key).Bytes()
+	if err == redis.Nil {
+		// expired since the scan
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	from := schema.VersionOf(data)
+	if from >= r.schema.Version() {
+		r.versions[from]++
+		return nil
+	}
+	upgraded, _, err := r.schema.Upgrade(data)
+	if err != nil {
+		r.versions[from]++
+		r.upgrading++
+		r.failed++
+		fmt.Fprintf(errs, "%s: %v\n", key, err)
+		return nil
+	}
+	if up {
+		// a time to live of -1ms is none, of -2ms that of a key expired
+		// since it was read
+		ttl, err := tx.PTTL(key).Result()
+		if err != nil {
+			return err
+		}
+		if ttl == -2*time.Millisecond {
+			return nil
+		}
+		if ttl < 0 {
+			ttl = 0
+		}
+		if _, err := tx.Pipelined(func(p redis.Pipeliner) error {
+			return p.Set(key, upgraded, ttl).Err()
+		}); err != nil {
+			return err
+		}
+		r.upgraded++
+	}
+	r.versions[from]++
+	r.upgrading++
+	return nil
+}
+
+// printReports prints the reports, with the migrations of their schemas.
+func printReports(out io.Writer, reports []report, up bool) {
+	tw := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "SCHEMA\tPREFIX\tVERSION\tRECORDS\tOLDER")
+	if up {
+		fmt.Fprintf(tw, "\tUPGRADED\tFAILED")
+	}
+	fmt.Fprintln(tw)
+	for _, r := range reports {
+		versions := make([]int, 0, len(r.versions))
+		for v := range r.versions {
+			versions = append(versions, v)
+		}
+		sort.Ints(versions)
+		counts := make([]string, 0, len(versions))
+		for _, v := range versions {
+			counts = append(counts, fmt.Sprintf("v%d:%d", v, r.versions[v]))
+		}
+		if len(counts) == 0 {
+			counts = append(counts, "-")
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%d", r.schema.Name, r.schema.Prefix, r.schema.Version(), strings.Join(counts, " "), r.upgrading)
+		if up {
+			fmt.Fprintf(tw, "\t%d\t%d", r.upgraded, r.failed)
+		}
+		fmt.Fprintln(tw)
+	}
+	tw.Flush()
+	for _, r := range reports {
+		for i, m := range r.schema.Migrations {
+			fmt.Fprintf(out, "%s v%d to v%d: %s\n", r.schema.Name, i+1, i+2, m.Description)
+		}
+	}
+}
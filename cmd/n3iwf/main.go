@@ -0,0 +1,307 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"google.golang.org/grpc"
+
+	amfpb "github.com/miki-tnt/sa5g-go-usvc-k8s/pb/amf"
+	pb "github.com/miki-tnt/sa5g-go-usvc-k8s/pb/n3iwf"
+	replicationpb "github.com/miki-tnt/sa5g-go-usvc-k8s/pb/replication"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/admin"
+	ausftransports "github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/ausf/transports"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/autoscale"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/bootstrap"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/caller"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/identity"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/idpool"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/loglevel"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/n3iwf/amf"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/n3iwf/endpoints"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/n3iwf/n2"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/n3iwf/nwu"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/n3iwf/service"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/n3iwf/transports"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/postgres"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/replication"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/schema"
+)
+
+const (
+	defAusfURL     string = "localhost:8481"
+	defNWuPort     string = nwu.DefaultPort
+	defNWuTimeout  string = "10s"
+	defInnerPrefix string = "10.60.0.0/16"
+	defAMFGUAMI    string = "208-93-cafe00"
+	defAMFNSSAI    string = "1"
+	defInactivity  string = "10s"
+	defT3513       string = "6s"
+	defT3512       string = "54m"
+	defImplicit    string = "58m"
+	defTAI         string = "208-93-000001"
+	defRegion      string = ""
+	defPeer        string = ""
+	defDedupWindow string = "5s"
+
+	defOverloadStart     string = "0"
+	defOverloadAction    string = n2.OverloadRejectSignalling
+	defOverloadReduction string = "50"
+
+	envAusfURL     string = "QS_AUSF_URL"
+	envNWuPort     string = "QS_N3IWF_NWU_PORT"
+	envNWuCert     string = "QS_N3IWF_NWU_CERT_FILE"
+	envNWuKey      string = "QS_N3IWF_NWU_KEY_FILE"
+	envNWuTimeout  string = "QS_N3IWF_NWU_TIMEOUT"
+	envInnerPrefix string = "QS_N3IWF_INNER_PREFIX"
+	envAMFGUAMI    string = "QS_N3IWF_AMF_GUAMI"
+	envAMFNSSAI    string = "QS_N3IWF_AMF_NSSAI"
+	envInactivity  string = "QS_N3IWF_INACTIVITY_TIMER"
+	envT3513       string = "QS_N3IWF_AMF_T3513"
+	envT3512       string = "QS_N3IWF_AMF_T3512"
+	envImplicit    string = "QS_N3IWF_AMF_IMPLICIT_DEREGISTRATION"
+	envTAI         string = "QS_N3IWF_TAI"
+	envRegion      string = "QS_N3IWF_REGION"
+	envPeer        string = "QS_N3IWF_REPLICATION_PEER"
+	envDedupWindow string = "QS_N3IWF_N2_DEDUP_WINDOW"
+
+	envOverloadStart     string = "QS_N3IWF_AMF_OVERLOAD_START"
+	envOverloadStop      string = "QS_N3IWF_AMF_OVERLOAD_STOP"
+	envOverloadAction    string = "QS_N3IWF_AMF_OVERLOAD_ACTION"
+	envOverloadReduction string = "QS_N3IWF_AMF_OVERLOAD_REDUCTION"
+)
+
+// spec is the N3IWF as bootstrapped, the environment variables of its own
+// being prefixed with QS_N3IWF_.
+var spec = bootstrap.Spec{
+	Name:        "n3iwf",
+	HTTPPort:    "8980",
+	GRPCPort:    "8981",
+	Capture:     true,
+	Limits:      true,
+	Idempotency: true,
+	Postgres:    true,
+	Schemas:     []*schema.Schema{idpool.Schema},
+}
+
+type config struct {
+	bootstrap.Config
+	ausfURL     string
+	nwuPort     string
+	nwuCert     string
+	nwuKey      string
+	nwuTimeout  time.Duration
+	innerPrefix string
+	amfGUAMI    identity.GUAMI
+	amfNSSAI    identity.NSSAI
+	inactivity  time.Duration
+	t3513       time.Duration
+	t3512       time.Duration
+	implicit    time.Duration
+	tai         identity.TAI
+	region      string
+	peer        string
+	dedupWindow time.Duration
+	// overloadStart and overloadStop are the thresholds of the overload
+	// control of the AMF stand-in, in NAS messages in process, overload
+	// the Overload Start it sends.
+	overloadStart int
+	overloadStop  int
+	overload      n2.Overload
+}
+
+func main() {
+	nf := bootstrap.New(spec)
+	cfg := loadConfig(nf)
+	nf.Report(cfg)
+	logger := nf.Logger
+
+	// the tree has no AMF: the N3IWF relays the NAS of its UEs to a
+	// stand-in, which authenticates them with the AUSF
+	conn, err := grpc.Dial(cfg.ausfURL, append(nf.DialOptions(), grpc.WithChainUnaryInterceptor(caller.UnaryClientInterceptor(cfg.NFInstanceID.String())))...)
+	if err != nil {
+		level.Error(logger).Log("serviceName", cfg.ausfURL, "error", err)
+		os.Exit(1)
+	}
+	defer conn.Close()
+	ausf := ausftransports.NewGRPCClient(conn, nf.Tracer, nf.ZipkinTracer, logger)
+	amfOptions := []amf.Option{amf.Logger(log.With(logger, loglevel.ComponentKey, "amf")), amf.Trace(nf.UETrace), amf.Slices(cfg.amfNSSAI), amf.T3513(cfg.t3513), amf.T3512(cfg.t3512), amf.ImplicitDeregistration(cfg.implicit), amf.Overload(cfg.overloadStart, cfg.overloadStop, cfg.overload)}
+	// in a region, the contexts of the registered UEs are replicated to the
+	// N3IWF of the other region following them, and those of the peer
+	// followed here
+	var replicas *replication.Table
+	if cfg.region != "" {
+		replicas = replication.New(cfg.region, replication.Logger(log.With(logger, loglevel.ComponentKey, "replication")), replication.Pseudonymize(nf.Privacy.Pseudonym))
+		amfOptions = append(amfOptions, amf.Replicate(replicas))
+		nf.Admin(admin.Pool("replication", func() interface{} { return replicas.Status() }))
+		if cfg.peer != "" {
+			peer, err := grpc.Dial(cfg.peer, nf.DialOptions()...)
+			if err != nil {
+				level.Error(logger).Log("env", envPeer, "error", err)
+				os.Exit(1)
+			}
+			defer peer.Close()
+			go replicas.Follow(context.Background(), replicationpb.NewReplicationClient(peer))
+		}
+	}
+	// with PostgreSQL, the UE contexts of the AMF and the sessions of the
+	// UEs are kept in tables of their own, and recovered on restart
+	if nf.Postgres != nil {
+		amfOptions = append(amfOptions, amf.Persist(postgres.NewUEContexts(nf.Postgres, cfg.amfGUAMI.String())))
+	}
+	amfStandIn := amf.New(ausf, cfg.amfGUAMI, amfOptions...)
+	if nf.Postgres != nil {
+		n, err := amfStandIn.Restore(context.Background())
+		if err != nil {
+			level.Error(logger).Log("restore", "failed", "error", err)
+			os.Exit(1)
+		}
+		logger.Log("ue_contexts", "restored", "count", n)
+	}
+	nf.Admin(admin.Pool("amf", func() interface{} { return amfStandIn.Stats() }))
+	nf.Admin(admin.Pool("smsf", func() interface{} { return amfStandIn.SMSStats() }))
+	nf.Admin(admin.Pool("cm", func() interface{} { return amfStandIn.CMStats() }))
+	nf.Admin(admin.Pool("slices", func() interface{} { return amfStandIn.SliceStats() }))
+	nf.Admin(admin.Pool("registration", func() interface{} { return amfStandIn.RegistrationStats() }))
+
+	pool, err := idpool.NewIPPool(context.Background(), nf.Store, "n3iwf-inner", cfg.innerPrefix, idpool.Logger(logger))
+	if err != nil {
+		level.Error(logger).Log("env", envInnerPrefix, "error", err)
+		os.Exit(1)
+	}
+	nf.Admin(admin.Pool("inner", func() interface{} { return pool.Stats() }))
+	// the UEs without activity go CM-IDLE, paged for their downlink
+	sessionsOptions := []service.SessionsOption{service.Inactivity(cfg.inactivity), service.TAI(cfg.tai)}
+	if nf.Postgres != nil {
+		sessionsOptions = append(sessionsOptions, service.Persist(postgres.NewSessions(nf.Postgres, cfg.NFInstanceID.String())))
+	}
+	// the NAS messages the N2 delivers twice are answered once
+	var n2AMF n2.AMF = amfStandIn
+	if cfg.dedupWindow > 0 {
+		n2AMF = n2.Dedup(nf.Store, cfg.dedupWindow, cfg.NFInstanceID.String())(n2AMF)
+	}
+	sessions := service.NewSessions(n2AMF, pool, cfg.nwuTimeout, log.With(logger, loglevel.ComponentKey, "nwu"), sessionsOptions...)
+	if nf.Postgres != nil {
+		n, err := sessions.Recover(context.Background())
+		if err != nil {
+			level.Error(logger).Log("recover", "failed", "error", err)
+			os.Exit(1)
+		}
+		logger.Log("sessions", "recovered", "count", n)
+	}
+	nf.Admin(admin.Pool("overload", func() interface{} {
+		return map[string]map[string]int{"amf": amfStandIn.OverloadStats(), "n3iwf": sessions.OverloadStats()}
+	}))
+	nf.Load.Gauge(autoscale.ActiveUEs, func() float64 { return float64(sessions.Active()) })
+
+	service := NewServer(sessions, nf.RequestLogger())
+	// the latencies carry the traces of their calls as exemplars, served
+	// with them on the admin port, and pushed over OTLP if configured
+	mw := nf.Middleware()
+	mw.Duration = nf.RequestDuration()
+	endpoints := endpoints.New(service, mw)
+
+	tlsConfig, err := nwuTLS(cfg, logger)
+	if err != nil {
+		level.Error(logger).Log("env", envNWuCert, "error", err)
+		os.Exit(1)
+	}
+	nf.Listen("NWu", cfg.nwuPort, func(l net.Listener) error {
+		return sessions.Serve(tls.NewListener(l, tlsConfig))
+	})
+	nf.HTTP(cfg.HTTPPort, func(m *http.ServeMux) {
+		m.Handle("/", transports.NewHTTPHandler(endpoints, nf.Tracer, nf.ZipkinTracer, logger))
+	})
+	nf.GRPC(cfg.GRPCPort, func(s *grpc.Server) {
+		pb.RegisterN3IwfServer(s, transports.MakeGRPCServer(endpoints, nf.Tracer, nf.ZipkinTracer, logger))
+		amfpb.RegisterUEContextsServer(s, amf.NewGRPCServer(amfStandIn))
+		if replicas != nil {
+			replicationpb.RegisterReplicationServer(s, replication.NewGRPCServer(replicas))
+		}
+	})
+	nf.Run()
+}
+
+func NewServer(sessions *service.Sessions, logger log.Logger) service.N3iwfService {
+	service := service.New(sessions, logger)
+	return service
+}
+
+func loadConfig(nf *bootstrap.NF) (cfg config) {
+	cfg.Config = nf.Config
+	cfg.ausfURL = nf.String(envAusfURL, defAusfURL)
+	cfg.nwuPort = nf.String(envNWuPort, defNWuPort)
+	cfg.nwuCert = nf.String(envNWuCert, "")
+	cfg.nwuKey = nf.String(envNWuKey, "")
+	cfg.nwuTimeout = nf.Duration(envNWuTimeout, defNWuTimeout)
+	cfg.innerPrefix = nf.String(envInnerPrefix, defInnerPrefix)
+	cfg.inactivity = nf.Duration(envInactivity, defInactivity)
+	cfg.t3513 = nf.Duration(envT3513, defT3513)
+	cfg.t3512 = nf.Duration(envT3512, defT3512)
+	cfg.implicit = nf.Duration(envImplicit, defImplicit)
+	cfg.region = nf.String(envRegion, defRegion)
+	cfg.peer = nf.String(envPeer, defPeer)
+	cfg.dedupWindow = nf.Duration(envDedupWindow, defDedupWindow)
+	guami, err := identity.ParseGUAMI(nf.String(envAMFGUAMI, defAMFGUAMI))
+	if err != nil {
+		level.Error(nf.Logger).Log("env", envAMFGUAMI, "error", err)
+		guami, _ = identity.ParseGUAMI(defAMFGUAMI)
+	}
+	cfg.amfGUAMI = guami
+	nssai, err := identity.ParseNSSAI(nf.String(envAMFNSSAI, defAMFNSSAI))
+	if err == nil && len(nssai) == 0 {
+		err = identity.ErrInvalidSNSSAI
+	}
+	if err != nil {
+		level.Error(nf.Logger).Log("env", envAMFNSSAI, "error", err)
+		nssai, _ = identity.ParseNSSAI(defAMFNSSAI)
+	}
+	cfg.amfNSSAI = nssai
+	tai, err := identity.ParseTAI(nf.String(envTAI, defTAI))
+	if err != nil {
+		level.Error(nf.Logger).Log("env", envTAI, "error", err)
+		tai, _ = identity.ParseTAI(defTAI)
+	}
+	cfg.tai = tai
+	// the overload control stops at 3/4 of its start by default
+	cfg.overloadStart = nf.Int(envOverloadStart, defOverloadStart)
+	cfg.overloadStop = nf.Int(envOverloadStop, strconv.Itoa(cfg.overloadStart*3/4))
+	cfg.overload.Action = nf.String(envOverloadAction, defOverloadAction)
+	if !n2.ValidOverloadAction(cfg.overload.Action) {
+		level.Error(nf.Logger).Log("env", envOverloadAction, "error", "unknown overload action "+cfg.overload.Action)
+		cfg.overload.Action = defOverloadAction
+	}
+	cfg.overload.TrafficLoadReduction = nf.Int(envOverloadReduction, defOverloadReduction)
+	if r := cfg.overload.TrafficLoadReduction; r < 0 || r > 100 {
+		level.Error(nf.Logger).Log("env", envOverloadReduction, "error", "traffic load reduction out of 0-100")
+		cfg.overload.TrafficLoadReduction, _ = strconv.Atoi(defOverloadReduction)
+	}
+	return cfg
+}
+
+// nwuTLS returns the TLS configuration of the NWu, with the certificate
+// and key of the files configured or, without them, a self-signed
+// certificate.
+func nwuTLS(cfg config, logger log.Logger) (*tls.Config, error) {
+	if cfg.nwuCert != "" || cfg.nwuKey != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.nwuCert, cfg.nwuKey)
+		if err != nil {
+			return nil, err
+		}
+		return &tls.Config{Certificates: []tls.Certificate{cert}}, nil
+	}
+	host, _ := os.Hostname()
+	cert, err := nwu.SelfSigned(host, "localhost", "127.0.0.1")
+	if err != nil {
+		return nil, err
+	}
+	level.Warn(logger).Log("protocol", "NWu", "certificate", "self-signed")
+	return &tls.Config{Certificates: []tls.Certificate{cert}}, nil
+}
@@ -0,0 +1,162 @@
+// Command operator reconciles the CoreNetwork, GNodeB and Slice resources
+// of the cluster it runs in into the Deployments, Services and ConfigMaps
+// of the NFs (see package operator). It reaches the API server as its
+// service account, or at QS_OPERATOR_KUBE_API_URL, such as the
+// http://localhost:8001 of kubectl proxy, out of the cluster.
+//
+// -render prints the objects of the resources of a file instead, at their
+// desired version, as a List kubectl applies:
+//
+//	operator -render deployments/operator/example.yaml | kubectl apply -f -
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/go-kit/kit/log/level"
+	"gopkg.in/yaml.v2"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/admin"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/bootstrap"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/operator"
+)
+
+const (
+	defWatchNamespace string = ""
+	defResync         string = "10s"
+	defKubeAPIURL     string = ""
+
+	envWatchNamespace string = "QS_OPERATOR_WATCH_NAMESPACE"
+	envResync         string = "QS_OPERATOR_RESYNC"
+	envKubeAPIURL     string = "QS_OPERATOR_KUBE_API_URL"
+)
+
+// spec is the operator as bootstrapped, the environment variables of its
+// own being prefixed with QS_OPERATOR_.
+var spec = bootstrap.Spec{
+	Name:     "operator",
+	HTTPPort: "8780",
+}
+
+type config struct {
+	bootstrap.Config
+	// watchNamespace is the namespace of the resources reconciled, every
+	// namespace when empty.
+	watchNamespace string
+	resync         time.Duration
+	kubeAPIURL     string
+}
+
+func main() {
+	fs := flag.NewFlagSet("operator", flag.ExitOnError)
+	render := fs.String("render", "", "print the objects of the resources of a YAML file, - for the standard input, and exit")
+	fs.Parse(os.Args[1:])
+	if *render != "" {
+		if err := renderFile(os.Stdout, *render); err != nil {
+			fmt.Fprintf(os.Stderr, "operator: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	nf := bootstrap.New(spec)
+	cfg := loadConfig(nf)
+	nf.Report(cfg)
+	logger := nf.Logger
+
+	var client *operator.Client
+	if cfg.kubeAPIURL != "" {
+		client = operator.NewClient(cfg.kubeAPIURL)
+	} else {
+		var err error
+		if client, err = operator.InClusterClient(); err != nil {
+			level.Error(logger).Log("env", envKubeAPIURL, "error", err)
+			os.Exit(1)
+		}
+	}
+	op := operator.New(client, logger, operator.Namespace(cfg.watchNamespace))
+	nf.Admin(admin.Pool("operator", op.Stats))
+
+	// the operator has no HTTP API, only the log levels
+	nf.HTTP(cfg.HTTPPort, func(m *http.ServeMux) {})
+	go op.Run(context.Background(), cfg.resync)
+	nf.Run()
+}
+
+func loadConfig(nf *bootstrap.NF) (cfg config) {
+	cfg.Config = nf.Config
+	cfg.watchNamespace = nf.String(envWatchNamespace, defWatchNamespace)
+	cfg.resync = nf.Duration(envResync, defResync)
+	cfg.kubeAPIURL = nf.String(envKubeAPIURL, defKubeAPIURL)
+	return cfg
+}
+
+// renderFile writes the objects of the resources of the YAML documents of
+// file to w.
+func renderFile(w io.Writer, file string) error {
+	var data []byte
+	var err error
+	if file == "-" {
+		data, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		data, err = ioutil.ReadFile(file)
+	}
+	if err != nil {
+		return err
+	}
+	var r operator.Resources
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var doc interface{}
+		if err := dec.Decode(&doc); err == io.EOF {
+			break
+		} else if err != nil {
+			return fmt.Errorf("%s: %v", file, err)
+		}
+		if doc == nil {
+			continue
+		}
+		b, err := json.Marshal(jsonValue(doc))
+		if err != nil {
+			return fmt.Errorf("%s: %v", file, err)
+		}
+		if err := r.Add(b); err != nil {
+			return fmt.Errorf("%s: %v", file, err)
+		}
+	}
+	list := struct {
+		APIVersion string            `json:"apiVersion"`
+		Kind       string            `json:"kind"`
+		Items      []operator.Object `json:"items"`
+	}{"v1", "List", r.Render()}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(list)
+}
+
+// jsonValue returns v, as decoded from YAML, with the keys of its maps
+// made strings for JSON.
+func jsonValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			m[fmt.Sprint(k)] = jsonValue(e)
+		}
+		return m
+	case []interface{}:
+		for i, e := range v {
+			v[i] = jsonValue(e)
+		}
+	}
+	return v
+}
@@ -1,157 +1,167 @@
 package main
 
 import (
-	"fmt"
-	"net"
+	"context"
+	"io/ioutil"
 	"net/http"
 	"os"
-	"os/signal"
-	"syscall"
+	"time"
 
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
-	kitgrpc "github.com/go-kit/kit/transport/grpc"
-	stdopentracing "github.com/opentracing/opentracing-go"
-	"github.com/openzipkin/zipkin-go"
-	zipkinhttp "github.com/openzipkin/zipkin-go/reporter/http"
+	"github.com/go-kit/kit/metrics/dogstatsd"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/health"
-	healthgrpc "google.golang.org/grpc/health/grpc_health_v1"
-	"google.golang.org/grpc/reflection"
 
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pb/gnodeb"
 	pb "github.com/miki-tnt/sa5g-go-usvc-k8s/pb/preamblesvc"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/admin"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/bootstrap"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/gnodeb/cell"
 	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/preamblesvc/endpoints"
 	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/preamblesvc/service"
 	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/preamblesvc/transports"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/runtime/pool"
 )
 
 const (
-	defZipkinV2URL string = ""
-	defNameSpace   string = "sa5g-go-usvc-k8s"
-	defServiceName string = "preamblesvc"
-	defLogLevel    string = "error"
-	defServiceHost string = "localhost"
-	defHTTPPort    string = "8280"
-	defGRPCPort    string = "8281"
-	envZipkinV2URL string = "QS_ZIPKIN_V2_URL"
-	envNameSpace   string = "QS_PREAMBLESVC_NAMESPACE"
-	envServiceName string = "QS_PREAMBLESVC_SERVICE_NAME"
-	envLogLevel    string = "QS_PREAMBLESVC_LOG_LEVEL"
-	envServiceHost string = "QS_PREAMBLESVC_SERVICE_HOST"
-	envHTTPPort    string = "QS_PREAMBLESVC_HTTP_PORT"
-	envGRPCPort    string = "QS_PREAMBLESVC_GRPC_PORT"
+	defStatsdAddr   string = ""
+	defWorkers      string = "16"
+	defQueueSize    string = "256"
+	defCellsFile    string = ""
+	defPreamblePRBs string = "6"
+	defMaxPRBUtil   string = "1"
+
+	envStatsdAddr   string = "QS_STATSD_ADDR"
+	envWorkers      string = "QS_PREAMBLESVC_WORKERS"
+	envQueueSize    string = "QS_PREAMBLESVC_QUEUE_SIZE"
+	envCellsFile    string = "QS_PREAMBLESVC_CELLS_FILE"
+	envPreamblePRBs string = "QS_PREAMBLESVC_PREAMBLE_PRBS"
+	envMaxPRBUtil   string = "QS_PREAMBLESVC_MAX_PRB_UTILIZATION"
 )
 
-type config struct {
-	nameSpace   string
-	serviceName string
-	logLevel    string
-	serviceHost string
-	httpPort    string
-	grpcPort    string
-	zipkinV2URL string
+// spec is preamblesvc as bootstrapped, the environment variables of its own
+// being prefixed with QS_PREAMBLESVC_.
+var spec = bootstrap.Spec{
+	Name:        "preamblesvc",
+	HTTPPort:    "8280",
+	GRPCPort:    "8281",
+	Capture:     true,
+	Limits:      true,
+	Idempotency: true,
 }
 
-// Env reads specified environment variable. If no value has been found,
-// fallback is returned.
-func env(key string, fallback string) string {
-	if v := os.Getenv(key); v != "" {
-		return v
-	}
-	return fallback
+type config struct {
+	bootstrap.Config
+	statsdAddr   string
+	workers      int
+	queueSize    int
+	cellsFile    string
+	preamblePRBs int
+	maxPRBUtil   float64
 }
 
 func main() {
-	var logger log.Logger
-	{
-		logger = log.NewLogfmtLogger(os.Stderr)
-		logger = level.NewFilter(logger, level.AllowInfo())
-		logger = log.With(logger, "ts", log.DefaultTimestampUTC)
-		logger = log.With(logger, "caller", log.DefaultCaller)
+	nf := bootstrap.New(spec)
+	cfg := loadConfig(nf)
+	nf.Report(cfg)
+	logger := nf.Logger
+
+	statsd := initStatsd(cfg.ServiceName, cfg.statsdAddr, logger)
+	workers := pool.New(cfg.workers, cfg.queueSize, poolOptions(statsd)...)
+	defer workers.Close()
+	// the preambles received on a configured cell are admitted against its
+	// PRBs, the cells being changed at runtime through the cells API
+	cells := cell.NewManager(cellOptions(cfg.maxPRBUtil, statsd)...)
+	if cfg.cellsFile != "" {
+		loadCells(cells, cfg.cellsFile, logger)
 	}
-	cfg := loadConfig(logger)
-	logger = log.With(logger, "service", cfg.serviceName)
-
-	tracer := initOpentracing()
-	zipkinTracer := initZipkin(cfg.serviceName, cfg.httpPort, cfg.zipkinV2URL, logger)
-	service := NewServer(logger)
-	endpoints := endpoints.New(service, logger, tracer, zipkinTracer)
-
-	errs := make(chan error, 2)
-	hs := health.NewServer()
-	hs.SetServingStatus(cfg.serviceName, healthgrpc.HealthCheckResponse_SERVING)
-	go startHTTPServer(endpoints, tracer, zipkinTracer, cfg.httpPort, logger, errs)
-	go startGRPCServer(endpoints, tracer, zipkinTracer, cfg.grpcPort, hs, logger, errs)
-
-	go func() {
-		c := make(chan os.Signal)
-		signal.Notify(c, syscall.SIGINT)
-		errs <- fmt.Errorf("%s", <-c)
-	}()
-
-	err := <-errs
-	level.Info(logger).Log("serviceName", cfg.serviceName, "terminated", err)
+	nf.Admin(
+		admin.Pool("workers", func() interface{} { return workers.Stats() }),
+		admin.Pool("cells", func() interface{} { return cells.List() }),
+	)
+	service := NewServer(workers, cells, cfg.preamblePRBs, nf.RequestLogger())
+	cellEndpoints := endpoints.NewCellEndpoints(cells, logger)
+	endpoints := endpoints.New(service, nf.Middleware())
+
+	nf.HTTP(cfg.HTTPPort, func(m *http.ServeMux) {
+		m.Handle("/", transports.NewHTTPHandler(endpoints, nf.Tracer, nf.ZipkinTracer, logger))
+		m.Handle(transports.CellsPath, transports.NewCellsHTTPHandler(cellEndpoints, logger))
+	})
+	nf.GRPC(cfg.GRPCPort, func(s *grpc.Server) {
+		pb.RegisterPreamblesvcServer(s, transports.MakeGRPCServer(endpoints, nf.Tracer, nf.ZipkinTracer, logger))
+		gnodeb.RegisterCellsServer(s, transports.MakeCellsGRPCServer(cellEndpoints, logger))
+	})
+	nf.Run()
 }
 
-func loadConfig(logger log.Logger) (cfg config) {
-	cfg.nameSpace = env(envNameSpace, defNameSpace)
-	cfg.serviceName = env(envServiceName, defServiceName)
-	cfg.logLevel = env(envLogLevel, defLogLevel)
-	cfg.serviceHost = env(envServiceHost, defServiceHost)
-	cfg.httpPort = env(envHTTPPort, defHTTPPort)
-	cfg.grpcPort = env(envGRPCPort, defGRPCPort)
-	cfg.zipkinV2URL = env(envZipkinV2URL, defZipkinV2URL)
+func loadConfig(nf *bootstrap.NF) (cfg config) {
+	cfg.Config = nf.Config
+	cfg.statsdAddr = nf.String(envStatsdAddr, defStatsdAddr)
+	cfg.workers = nf.Int(envWorkers, defWorkers)
+	cfg.queueSize = nf.Int(envQueueSize, defQueueSize)
+	cfg.cellsFile = nf.String(envCellsFile, defCellsFile)
+	cfg.preamblePRBs = nf.Int(envPreamblePRBs, defPreamblePRBs)
+	cfg.maxPRBUtil = nf.Float(envMaxPRBUtil, defMaxPRBUtil)
 	return cfg
 }
 
-func NewServer(logger log.Logger) service.PreamblesvcService {
-	service := service.New(logger)
-	return service
-}
-
-func initOpentracing() (tracer stdopentracing.Tracer) {
-	return stdopentracing.GlobalTracer()
-}
-
-func initZipkin(serviceName, httpPort, zipkinV2URL string, logger log.Logger) (zipkinTracer *zipkin.Tracer) {
-	var (
-		err           error
-		hostPort      = fmt.Sprintf("localhost:%s", httpPort)
-		useNoopTracer = (zipkinV2URL == "")
-		reporter      = zipkinhttp.NewReporter(zipkinV2URL)
-	)
-	zEP, _ := zipkin.NewEndpoint(serviceName, hostPort)
-	zipkinTracer, err = zipkin.NewTracer(reporter, zipkin.WithLocalEndpoint(zEP), zipkin.WithNoopTracer(useNoopTracer))
+func loadCells(cells *cell.Manager, file string, logger log.Logger) {
+	data, err := ioutil.ReadFile(file)
 	if err != nil {
-		logger.Log("err", err)
+		level.Error(logger).Log("cells", file, "err", err)
 		os.Exit(1)
 	}
-	if !useNoopTracer {
-		logger.Log("tracer", "Zipkin", "type", "Native", "URL", zipkinV2URL)
+	n, err := cells.Load(data)
+	if err != nil {
+		level.Error(logger).Log("cells", file, "err", err)
+		os.Exit(1)
 	}
+	level.Info(logger).Log("cells", file, "loaded", n)
+}
 
-	return
+func NewServer(workers *pool.Pool, cells *cell.Manager, preamblePRBs int, logger log.Logger) service.PreamblesvcService {
+	service := service.New(logger, workers, cells, preamblePRBs)
+	return service
 }
 
-func startHTTPServer(endpoints endpoints.Endpoints, tracer stdopentracing.Tracer, zipkinTracer *zipkin.Tracer, port string, logger log.Logger, errs chan error) {
-	p := fmt.Sprintf(":%s", port)
-	level.Info(logger).Log("protocol", "HTTP", "exposed", port)
-	errs <- http.ListenAndServe(p, transports.NewHTTPHandler(endpoints, tracer, zipkinTracer, logger))
+// initStatsd returns a DogStatsD sink flushing to statsdAddr, or nil when no
+// address is configured.
+func initStatsd(serviceName, statsdAddr string, logger log.Logger) (statsd *dogstatsd.Dogstatsd) {
+	if statsdAddr == "" {
+		return nil
+	}
+	statsd = dogstatsd.New(serviceName+".", logger)
+	go statsd.SendLoop(context.Background(), time.NewTicker(5*time.Second).C, "udp", statsdAddr)
+	logger.Log("metrics", "DogStatsD", "addr", statsdAddr)
+	return
 }
 
-func startGRPCServer(endpoints endpoints.Endpoints, tracer stdopentracing.Tracer, zipkinTracer *zipkin.Tracer, port string, hs *health.Server, logger log.Logger, errs chan error) {
-	p := fmt.Sprintf(":%s", port)
-	listener, err := net.Listen("tcp", p)
-	if err != nil {
-		level.Error(logger).Log("protocol", "GRPC", "listen", port, "err", err)
-		os.Exit(1)
+func poolOptions(statsd *dogstatsd.Dogstatsd) []pool.Option {
+	if statsd == nil {
+		return nil
+	}
+	return []pool.Option{
+		pool.QueueDepthGauge(statsd.NewGauge("pool_queue_depth")),
+		pool.BusyGauge(statsd.NewGauge("pool_busy_workers")),
+		pool.ThrottledCounter(statsd.NewCounter("pool_throttled_total", 1)),
+		pool.WaitHistogram(statsd.NewHistogram("pool_wait_seconds", 1)),
+		pool.ExpiredCounter(statsd.NewCounter("pool_expired_total", 1)),
 	}
+}
 
-	var server *grpc.Server
-	level.Info(logger).Log("protocol", "GRPC", "protocol", "GRPC", "exposed", port)
-	server = grpc.NewServer(grpc.UnaryInterceptor(kitgrpc.Interceptor))
-	pb.RegisterPreamblesvcServer(server, transports.MakeGRPCServer(endpoints, tracer, zipkinTracer, logger))
-	healthgrpc.RegisterHealthServer(server, hs)
-	reflection.Register(server)
-	errs <- server.Serve(listener)
+// cellOptions returns the options of the cell manager: the admissions are
+// held under maxPRBUtil of the PRBs of a cell when it is below 1, and the
+// utilization and admissions of the cells go to statsd when it is set.
+func cellOptions(maxPRBUtil float64, statsd *dogstatsd.Dogstatsd) []cell.Option {
+	var options []cell.Option
+	if maxPRBUtil > 0 && maxPRBUtil < 1 {
+		options = append(options, cell.AdmissionHook(cell.MaxUtilization(maxPRBUtil)))
+	}
+	if statsd != nil {
+		options = append(options,
+			cell.UtilizationGauge(statsd.NewGauge("cell_prb_utilization")),
+			cell.AdmissionCounter(statsd.NewCounter("cell_admissions_total", 1)),
+		)
+	}
+	return options
 }
@@ -0,0 +1,223 @@
+// Command replay sends the calls captured from a service (see pkg/capture)
+// again to an instance of it, over its HTTP JSON transport, and reports the
+// calls whose outcome differs from the one captured.
+//
+//	replay [-addr http://localhost:8180] [-method m] [-trace id] [-speed 0] [-v] [file]
+//	replay -from http://localhost:9180 [-addr ...]
+//	replay -corpus dir [-method m] [file]
+//
+// The records are read from file, QS_CAPTURE_FILE of the service, from the
+// standard input when file is "-" or missing, or from the admin port of the
+// service with -from. With -speed 1 the calls are spaced as captured, 0 sends
+// them back to back.
+//
+// With -corpus, the requests are not sent but written to dir, as the seeds
+// of the corpus of the FuzzHTTP harness of the service (see pkg/kitx/fuzz).
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/capture"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/kitx/fuzz"
+)
+
+const (
+	defAddr string = "http://localhost:8180"
+	envAddr string = "QS_REPLAY_ADDR"
+)
+
+// Env reads specified environment variable. If no value has been found,
+// fallback is returned.
+func env(key string, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+type outcome int
+
+const (
+	same outcome = iota
+	different
+	failed
+)
+
+func main() {
+	fs := flag.NewFlagSet("replay", flag.ExitOnError)
+	addr := fs.String("addr", env(envAddr, defAddr), "base URL of the service HTTP port")
+	from := fs.String("from", "", "base URL of the admin port to read the captured calls from")
+	method := fs.String("method", "", "replay the calls of this method only")
+	trace := fs.String("trace", "", "replay the calls of this trace ID only")
+	speed := fs.Float64("speed", 0, "pace of the calls relative to the capture, 0 for back to back")
+	timeout := fs.Duration("timeout", 5*time.Second, "request timeout")
+	verbose := fs.Bool("v", false, "print the captured and replayed outcomes of the calls that differ")
+	corpus := fs.String("corpus", "", "write the requests to this directory as fuzzing seeds instead of sending them")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: replay [flags] [file]\n\nflags:\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(os.Args[1:])
+
+	records, err := read(*from, fs.Arg(0), *timeout)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "replay: %v\n", err)
+		os.Exit(1)
+	}
+	if *corpus != "" {
+		n, err := seed(*corpus, records, *method, *trace)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "replay: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("wrote %d seeds to %s\n", n, *corpus)
+		return
+	}
+	c := &http.Client{Timeout: *timeout}
+	base := strings.TrimSuffix(*addr, "/")
+	var counts [3]int
+	var prev time.Time
+	for _, r := range records {
+		if (*method != "" && r.Method != *method) || (*trace != "" && r.TraceID != *trace) {
+			continue
+		}
+		if *speed > 0 && !prev.IsZero() {
+			time.Sleep(time.Duration(float64(r.Time.Sub(prev)) / *speed))
+		}
+		prev = r.Time
+		got, o, err := replay(c, base, r)
+		counts[o]++
+		traceID := r.TraceID
+		if traceID == "" {
+			traceID = "-"
+		}
+		switch o {
+		case same:
+			fmt.Printf("%s %s %s same\n", r.Time.Format(time.RFC3339Nano), r.Method, traceID)
+		case different:
+			fmt.Printf("%s %s %s different\n", r.Time.Format(time.RFC3339Nano), r.Method, traceID)
+			if *verbose {
+				fmt.Printf("\trequest:  %s\n\tcaptured: %s\n\treplayed: %s\n", r.Request, outcomeOf(r), got)
+			}
+		case failed:
+			fmt.Printf("%s %s %s failed: %v\n", r.Time.Format(time.RFC3339Nano), r.Method, traceID, err)
+		}
+	}
+	fmt.Printf("replayed %d: %d same, %d different, %d failed\n", counts[same]+counts[different]+counts[failed], counts[same], counts[different], counts[failed])
+	if counts[different]+counts[failed] > 0 {
+		os.Exit(1)
+	}
+}
+
+// seed writes the requests of records, of method and trace when set, to
+// dir as the inputs of an HTTP fuzzing harness. The requests with the same
+// body are written once.
+func seed(dir string, records []capture.Record, method, trace string) (n int, err error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return 0, err
+	}
+	for _, r := range records {
+		if (method != "" && r.Method != method) || (trace != "" && r.TraceID != trace) {
+			continue
+		}
+		data := fuzz.HTTPSeed("/"+r.Method, "application/json", r.Request)
+		name := filepath.Join(dir, fuzz.Name(data))
+		if _, err := os.Stat(name); err == nil {
+			continue
+		}
+		if err := ioutil.WriteFile(name, data, 0644); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+// read returns the records of the admin port from, of file otherwise.
+func read(from, file string, timeout time.Duration) ([]capture.Record, error) {
+	var in io.Reader
+	switch {
+	case from != "":
+		c := &http.Client{Timeout: timeout}
+		resp, err := c.Get(strings.TrimSuffix(from, "/") + capture.Path)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, errors.New(resp.Status)
+		}
+		in = resp.Body
+	case file == "" || file == "-":
+		in = os.Stdin
+	default:
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		in = f
+	}
+	return capture.Read(in)
+}
+
+// replay sends the request of r to the path of its method and compares the
+// outcome, returned as JSON, with the one captured.
+func replay(c *http.Client, base string, r capture.Record) (json.RawMessage, outcome, error) {
+	resp, err := c.Post(base+"/"+r.Method, "application/json", bytes.NewReader(r.Request))
+	if err != nil {
+		return nil, failed, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, failed, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var w struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(body, &w) != nil {
+			return nil, failed, errors.New(resp.Status)
+		}
+		got, _ := json.Marshal(map[string]string{"err": w.Error})
+		if w.Error != r.Err || r.Err == "" {
+			return got, different, nil
+		}
+		return got, same, nil
+	}
+	if r.Err != "" || !equalJSON(body, r.Response) {
+		return bytes.TrimSpace(body), different, nil
+	}
+	return body, same, nil
+}
+
+// outcomeOf returns the outcome captured in r, as JSON.
+func outcomeOf(r capture.Record) json.RawMessage {
+	if r.Err != "" {
+		b, _ := json.Marshal(map[string]string{"err": r.Err})
+		return b
+	}
+	return r.Response
+}
+
+func equalJSON(a, b []byte) bool {
+	var va, vb interface{}
+	if json.Unmarshal(a, &va) != nil || json.Unmarshal(b, &vb) != nil {
+		return false
+	}
+	return reflect.DeepEqual(va, vb)
+}
@@ -11,10 +11,11 @@ import (
 	"strconv"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
-	kitgrpc "github.com/go-kit/kit/transport/grpc"
+	"github.com/go-kit/kit/metrics/dogstatsd"
 	"github.com/mwitkow/grpc-proxy/proxy"
 	stdopentracing "github.com/opentracing/opentracing-go"
 	"github.com/openzipkin/zipkin-go"
@@ -24,33 +25,63 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
-	"google.golang.org/grpc/reflection"
 
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/admin"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/grpcpool"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/grpcserver"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/logging"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/loglevel"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/recovery"
 	routertransport "github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/router/transport"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/sampling"
 )
 
 const grpcRouterReg = `([a-zA-Z]+)/`
 
 const (
 	defZipkinV2URL   = ""
+	defTraceRate     = "1"
+	defTraceLimit    = "0"
+	defTraceMethods  = ""
+	defTraceKeep     = ""
+	defStatsdAddr    = ""
 	defServiceName   = "router"
 	defLogLevel      = "error"
+	defLogFormat     = "logfmt"
+	defLogRedact     = "true"
 	defHTTPPort      = ""
 	defGRPCPort      = ""
+	defAdminPort     = ""
 	defRretryTimeout = "500" // time.Millisecond
 	defRretryMax     = "3"
 	defAddsvcURL     = ""
 	defFoosvcURL     = ""
+	defGRPCPoolSize  = "4"
+	defGRPCMaxEject  = "50"
+	defGRPCKeepalive = "0s"
+	defGRPCMaxMsg    = "4194304"
 
-	envZipkinV2URL  = "QS_ZIPKIN_V2_URL"
-	envServiceName  = "QS_ROUTER_SERVICE_NAME"
-	envLogLevel     = "QS_ROUTER_LOG_LEVEL"
-	envHTTPPort     = "QS_ROUTER_HTTP_PORT"
-	envGRPCPort     = "QS_ROUTER_GRPC_PORT"
-	envRetryMax     = "QS_ROUTER_RETRY_MAX"
-	envRetryTimeout = "QS_ROUTER_RETRY_TIMEOUT"
-	envAddsvcURL    = "QS_ADDSVC_URL"
-	envFoosvcURL    = "QS_FOOSVC_URL"
+	envZipkinV2URL   = "QS_ZIPKIN_V2_URL"
+	envTraceRate     = "QS_TRACE_SAMPLE_RATE"
+	envTraceLimit    = "QS_TRACE_RATE_LIMIT"
+	envTraceMethods  = "QS_TRACE_SAMPLE_METHODS"
+	envTraceKeep     = "QS_TRACE_KEEP"
+	envStatsdAddr    = "QS_STATSD_ADDR"
+	envServiceName   = "QS_ROUTER_SERVICE_NAME"
+	envLogLevel      = "QS_ROUTER_LOG_LEVEL"
+	envLogFormat     = "QS_LOG_FORMAT"
+	envLogRedact     = "QS_LOG_REDACT"
+	envHTTPPort      = "QS_ROUTER_HTTP_PORT"
+	envGRPCPort      = "QS_ROUTER_GRPC_PORT"
+	envAdminPort     = "QS_ROUTER_ADMIN_PORT"
+	envRetryMax      = "QS_ROUTER_RETRY_MAX"
+	envRetryTimeout  = "QS_ROUTER_RETRY_TIMEOUT"
+	envAddsvcURL     = "QS_ADDSVC_URL"
+	envFoosvcURL     = "QS_FOOSVC_URL"
+	envGRPCPoolSize  = "QS_ROUTER_GRPC_POOL_SIZE"
+	envGRPCMaxEject  = "QS_ROUTER_GRPC_MAX_EJECTION_PERCENT"
+	envGRPCKeepalive = "QS_GRPC_KEEPALIVE_TIME"
+	envGRPCMaxMsg    = "QS_GRPC_MAX_MSG_SIZE"
 )
 
 const (
@@ -68,45 +99,68 @@ func env(key string, fallback string) (s0 string) {
 }
 
 type config struct {
-	nameSpace    string
-	serviceName  string
-	logLevel     string
-	serviceHost  string
-	httpPort     string
-	grpcPort     string
-	zipkinV2URL  string
-	retryMax     int64
-	retryTimeout int64
-	addsvcURL    string
-	foosvcURL    string
-	routerMap    map[string]string
+	nameSpace     string
+	serviceName   string
+	logLevel      string
+	serviceHost   string
+	httpPort      string
+	grpcPort      string
+	adminPort     string
+	zipkinV2URL   string
+	traceSampling sampling.Config
+	statsdAddr    string
+	retryMax      int64
+	retryTimeout  int64
+	addsvcURL     string
+	foosvcURL     string
+	grpcPoolSize  int64
+	grpcMaxEject  int
+	grpcKeepalive time.Duration
+	grpcMaxMsg    int
+	routerMap     map[string]string
 }
 
 func main() {
+	levels := loglevel.New(loglevel.Info)
 	var logger log.Logger
 	{
-		logger = log.NewLogfmtLogger(os.Stderr)
-		logger = level.NewFilter(logger, level.AllowInfo())
+		logger = logging.NewLogger(os.Stderr, env(envLogFormat, defLogFormat))
+		logger = levels.NewFilter(logger)
+		if redact, _ := strconv.ParseBool(env(envLogRedact, defLogRedact)); redact {
+			logger = logging.Redact(logger, logging.DefaultRedactedKeys...)
+		}
 		logger = log.With(logger, "ts", log.DefaultTimestampUTC)
 		logger = log.With(logger, "caller", log.DefaultCaller)
 	}
 	cfg := loadConfig(logger)
 	logger = log.With(logger, "service", cfg.serviceName)
+	if l, err := loglevel.Parse(cfg.logLevel); err != nil {
+		level.Error(logger).Log("envLogLevel", envLogLevel, "error", err)
+	} else {
+		levels.SetDefault(l)
+	}
 
 	tracer := initOpentracing()
-	zipkinTracer := initZipkin(cfg.serviceName, cfg.httpPort, cfg.zipkinV2URL, logger)
+	zipkinTracer := initZipkin(cfg.serviceName, cfg.httpPort, cfg.zipkinV2URL, cfg.traceSampling, logger)
+	statsd := initStatsd(cfg.serviceName, cfg.statsdAddr, logger)
 	ctx := context.Background()
 
+	poolSize := int(cfg.grpcPoolSize)
 	hb := routertransport.NewHandlerBuilder()
-	hb.AddHandler(routerAddsvc, routertransport.MakeAddSvcHandler(ctx, cfg.addsvcURL, tracer, zipkinTracer, logger))
-	hb.AddHandler(routerFoosvc, routertransport.MakeFooSvcHandler(ctx, cfg.foosvcURL, tracer, zipkinTracer, logger))
+	hb.AddHandler(routerAddsvc, routertransport.MakeAddSvcHandler(ctx, cfg.addsvcURL, poolSize, poolOptions(routerAddsvc, cfg, statsd, logger), tracer, zipkinTracer, logger))
+	hb.AddHandler(routerFoosvc, routertransport.MakeFooSvcHandler(ctx, cfg.foosvcURL, poolSize, poolOptions(routerFoosvc, cfg, statsd, logger), tracer, zipkinTracer, logger))
 
 	errs := make(chan error, 1)
 	go startHTTPServer(hb.Router, cfg.httpPort, logger, errs)
-	go startGRPCServer(zipkinTracer, cfg.grpcPort, cfg.routerMap, logger, errs)
+	go startAdminServer(admin.New(levels, admin.Config(cfg)), cfg.adminPort, logger, errs)
+	gb := grpcserver.NewServerBuilder(nil).
+		Recovery(logger).
+		Keepalive(cfg.grpcKeepalive, 0).
+		MaxMsgSize(cfg.grpcMaxMsg, cfg.grpcMaxMsg)
+	go startGRPCServer(zipkinTracer, cfg.grpcPort, cfg.routerMap, gb, logger, errs)
 
 	go func() {
-		c := make(chan os.Signal)
+		c := make(chan os.Signal, 1)
 		signal.Notify(c, syscall.SIGINT)
 		errs <- fmt.Errorf("%s", <-c)
 	}()
@@ -126,15 +180,61 @@ func loadConfig(logger log.Logger) (cfg config) {
 		level.Error(logger).Log("envRetryTimeout", envRetryTimeout, "error", err)
 	}
 
+	grpcPoolSize, err := strconv.ParseInt(env(envGRPCPoolSize, defGRPCPoolSize), 10, 0)
+	if err != nil {
+		level.Error(logger).Log("envGRPCPoolSize", envGRPCPoolSize, "error", err)
+	}
+
+	grpcMaxEject, err := strconv.Atoi(env(envGRPCMaxEject, defGRPCMaxEject))
+	if err != nil {
+		level.Error(logger).Log("envGRPCMaxEject", envGRPCMaxEject, "error", err)
+	}
+
+	grpcKeepalive, err := time.ParseDuration(env(envGRPCKeepalive, defGRPCKeepalive))
+	if err != nil {
+		level.Error(logger).Log("envGRPCKeepalive", envGRPCKeepalive, "error", err)
+	}
+
+	grpcMaxMsg, err := strconv.Atoi(env(envGRPCMaxMsg, defGRPCMaxMsg))
+	if err != nil {
+		level.Error(logger).Log("envGRPCMaxMsg", envGRPCMaxMsg, "error", err)
+	}
+
 	cfg.serviceName = env(envServiceName, defServiceName)
 	cfg.logLevel = env(envLogLevel, defLogLevel)
 	cfg.httpPort = env(envHTTPPort, defHTTPPort)
 	cfg.grpcPort = env(envGRPCPort, defGRPCPort)
+	cfg.adminPort = env(envAdminPort, defAdminPort)
 	cfg.zipkinV2URL = env(envZipkinV2URL, defZipkinV2URL)
+	traceRate, err := strconv.ParseFloat(env(envTraceRate, defTraceRate), 64)
+	if err != nil {
+		level.Error(logger).Log("envTraceRate", envTraceRate, "error", err)
+	}
+	cfg.traceSampling.Rate = traceRate
+	traceLimit, err := strconv.ParseFloat(env(envTraceLimit, defTraceLimit), 64)
+	if err != nil {
+		level.Error(logger).Log("envTraceLimit", envTraceLimit, "error", err)
+	}
+	cfg.traceSampling.RateLimit = traceLimit
+	traceMethods, err := sampling.ParseMethods(env(envTraceMethods, defTraceMethods))
+	if err != nil {
+		level.Error(logger).Log("envTraceMethods", envTraceMethods, "error", err)
+	}
+	cfg.traceSampling.Methods = traceMethods
+	cfg.traceSampling.Tail = env(envTraceKeep, defTraceKeep)
+	if _, err := sampling.ParseTail(cfg.traceSampling.Tail); err != nil {
+		level.Error(logger).Log("envTraceKeep", envTraceKeep, "error", err)
+		cfg.traceSampling.Tail = defTraceKeep
+	}
+	cfg.statsdAddr = env(envStatsdAddr, defStatsdAddr)
 	cfg.retryMax = retryMax
 	cfg.retryTimeout = retryTimeout
 	cfg.addsvcURL = env(envAddsvcURL, defAddsvcURL)
 	cfg.foosvcURL = env(envFoosvcURL, defFoosvcURL)
+	cfg.grpcPoolSize = grpcPoolSize
+	cfg.grpcMaxEject = grpcMaxEject
+	cfg.grpcKeepalive = grpcKeepalive
+	cfg.grpcMaxMsg = grpcMaxMsg
 
 	cfg.routerMap = map[string]string{}
 	cfg.routerMap[routerAddsvc] = cfg.addsvcURL
@@ -146,12 +246,18 @@ func initOpentracing() (tracer stdopentracing.Tracer) {
 	return stdopentracing.GlobalTracer()
 }
 
-func initZipkin(serviceName, httpPort, zipkinV2URL string, logger log.Logger) (zipkinTracer *zipkin.Tracer) {
+// initZipkin returns the Zipkin tracer of the service, which exports the
+// traces traceSampling keeps.
+func initZipkin(serviceName, httpPort, zipkinV2URL string, traceSampling sampling.Config, logger log.Logger) (zipkinTracer *zipkin.Tracer) {
+	samplingOptions, err := traceSampling.Options()
+	if err != nil {
+		logger.Log("err", err)
+		os.Exit(1)
+	}
 	var (
-		err           error
 		hostPort      = fmt.Sprintf("localhost:%s", httpPort)
 		useNoopTracer = (zipkinV2URL == "")
-		reporter      = zipkinhttp.NewReporter(zipkinV2URL)
+		reporter      = sampling.NewReporter(zipkinhttp.NewReporter(zipkinV2URL), samplingOptions...)
 	)
 	zEP, _ := zipkin.NewEndpoint(serviceName, hostPort)
 	zipkinTracer, err = zipkin.NewTracer(reporter, zipkin.WithLocalEndpoint(zEP), zipkin.WithNoopTracer(useNoopTracer))
@@ -166,16 +272,56 @@ func initZipkin(serviceName, httpPort, zipkinV2URL string, logger log.Logger) (z
 	return
 }
 
+// initStatsd returns a DogStatsD sink flushing to statsdAddr, or nil when no
+// address is configured.
+func initStatsd(serviceName, statsdAddr string, logger log.Logger) (statsd *dogstatsd.Dogstatsd) {
+	if statsdAddr == "" {
+		return nil
+	}
+	statsd = dogstatsd.New(serviceName+".", logger)
+	go statsd.SendLoop(context.Background(), time.NewTicker(5*time.Second).C, "udp", statsdAddr)
+	logger.Log("metrics", "DogStatsD", "addr", statsdAddr)
+	return
+}
+
+func poolOptions(target string, cfg config, statsd *dogstatsd.Dogstatsd, logger log.Logger) []grpcpool.Option {
+	outliers := grpcpool.DefaultOutliers
+	outliers.MaxEjectedPercent = cfg.grpcMaxEject
+	options := []grpcpool.Option{
+		grpcpool.Logger(log.With(logger, loglevel.ComponentKey, "grpcpool", "pool", target)),
+		grpcpool.OutlierDetection(outliers),
+	}
+	if statsd != nil {
+		options = append(options,
+			grpcpool.InflightGauge(statsd.NewGauge("grpcpool_inflight").With("target", target)),
+			grpcpool.SaturationGauge(statsd.NewGauge("grpcpool_saturation").With("target", target)),
+			grpcpool.ReconnectCounter(statsd.NewCounter("grpcpool_reconnects_total", 1).With("target", target)),
+			grpcpool.EjectionCounter(statsd.NewCounter("grpcpool_ejections_total", 1).With("target", target)),
+		)
+	}
+	return options
+}
+
 func startHTTPServer(handler http.Handler, port string, logger log.Logger, errs chan error) {
 	if port == "" {
 		return
 	}
 	p := fmt.Sprintf(":%s", port)
 	level.Info(logger).Log("protocol", "HTTP", "exposed", port)
+	errs <- http.ListenAndServe(p, recovery.Handler(handler, logger))
+}
+
+// startAdminServer serves the admin handler on port, unless port is empty.
+func startAdminServer(handler http.Handler, port string, logger log.Logger, errs chan error) {
+	if port == "" {
+		return
+	}
+	p := fmt.Sprintf(":%s", port)
+	level.Info(logger).Log("protocol", "HTTP", "admin", port)
 	errs <- http.ListenAndServe(p, handler)
 }
 
-func startGRPCServer(zipkinTracer *opzipkin.Tracer, port string, routerMap map[string]string, logger log.Logger, errs chan error) {
+func startGRPCServer(zipkinTracer *opzipkin.Tracer, port string, routerMap map[string]string, gb *grpcserver.ServerBuilder, logger log.Logger, errs chan error) {
 	if port == "" {
 		return
 	}
@@ -200,8 +346,7 @@ func startGRPCServer(zipkinTracer *opzipkin.Tracer, port string, routerMap map[s
 
 		md, ok := metadata.FromIncomingContext(ctx)
 		// Copy the inbound metadata explicitly.
-		outCtx, _ := context.WithCancel(ctx)
-		outCtx = metadata.NewOutgoingContext(outCtx, md.Copy())
+		outCtx := metadata.NewOutgoingContext(ctx, md.Copy())
 
 		if ok {
 			conn, err := grpc.DialContext(
@@ -218,12 +363,10 @@ func startGRPCServer(zipkinTracer *opzipkin.Tracer, port string, routerMap map[s
 
 	var server *grpc.Server
 	level.Info(logger).Log("GRPC", "proxy", "exposed", port)
-	server = grpc.NewServer(
+	server = gb.Option(
 		grpc.CustomCodec(proxy.Codec()),
 		grpc.UnknownServiceHandler(proxy.TransparentHandler(director)),
-		grpc.UnaryInterceptor(kitgrpc.Interceptor),
 		grpc.StatsHandler(zipkingrpc.NewServerHandler(zipkinTracer)),
-	)
-	reflection.Register(server)
+	).Build()
 	errs <- server.Serve(listener)
 }
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/admin"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/breakers"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/privacy"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/slo"
+)
+
+// The admin commands talk to the admin port of a service, see
+// QS_<SERVICE>_ADMIN_PORT.
+
+func runConfig(c *client, args []string) error {
+	if len(args) != 0 {
+		return errors.New("usage: config")
+	}
+	var cfg map[string]string
+	if err := c.do(http.MethodGet, admin.ConfigPath, nil, &cfg); err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(cfg))
+	for k := range cfg {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	tw := tabwriter.NewWriter(c.out, 0, 4, 2, ' ', 0)
+	for _, k := range keys {
+		fmt.Fprintf(tw, "%s\t%s\n", k, cfg[k])
+	}
+	return tw.Flush()
+}
+
+func runBreakers(c *client, args []string) error {
+	if len(args) != 0 {
+		return errors.New("usage: breakers")
+	}
+	var states []breakers.State
+	if err := c.do(http.MethodGet, admin.BreakersPath, nil, &states); err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(c.out, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "NAME\tSTATE\tSINCE\n")
+	for _, s := range states {
+		since := "-"
+		if !s.Since.IsZero() {
+			since = time.Since(s.Since).Round(time.Second).String()
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", s.Name, s.State, since)
+	}
+	return tw.Flush()
+}
+
+func runPools(c *client, args []string) error {
+	if len(args) != 0 {
+		return errors.New("usage: pools")
+	}
+	var pools []struct {
+		Name  string          `json:"name"`
+		Stats json.RawMessage `json:"stats"`
+	}
+	if err := c.do(http.MethodGet, admin.PoolsPath, nil, &pools); err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(c.out, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "NAME\tSTATS\n")
+	for _, p := range pools {
+		var stats bytes.Buffer
+		if err := json.Compact(&stats, p.Stats); err != nil {
+			return err
+		}
+		fmt.Fprintf(tw, "%s\t%s\n", p.Name, stats.String())
+	}
+	return tw.Flush()
+}
+
+func runSLO(c *client, args []string) error {
+	if len(args) > 1 {
+		return errors.New("usage: slo [method]")
+	}
+	path := slo.Path
+	if len(args) == 1 {
+		path += "?method=" + url.QueryEscape(args[0])
+	}
+	var statuses []slo.Status
+	if err := c.do(http.MethodGet, path, nil, &statuses); err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(c.out, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "NF\tMETHOD\tOBJECTIVE\tGOOD\tBAD")
+	if len(statuses) != 0 {
+		for _, w := range statuses[0].Windows {
+			fmt.Fprintf(tw, "\tBURN %s", w.Window)
+		}
+	}
+	fmt.Fprintf(tw, "\tBUDGET\n")
+	for _, s := range statuses {
+		fmt.Fprintf(tw, "%s\t%s\t%g%% in %s\t%d\t%d", s.NF, s.Method, s.Objective, s.Latency, s.Good, s.Bad)
+		for _, w := range s.Windows {
+			fmt.Fprintf(tw, "\t%.2f", w.BurnRate)
+		}
+		fmt.Fprintf(tw, "\t%.1f%%\n", s.BudgetRemaining*100)
+	}
+	return tw.Flush()
+}
+
+func runPrivacy(c *client, args []string) error {
+	if len(args) == 0 || args[0] == "status" {
+		var s privacy.Status
+		if err := c.do(http.MethodGet, privacy.Path, nil, &s); err != nil {
+			return err
+		}
+		current := s.Current
+		if current == "" {
+			current = "(unkeyed)"
+		}
+		tw := tabwriter.NewWriter(c.out, 0, 4, 2, ' ', 0)
+		fmt.Fprintf(tw, "CURRENT KEY\tKEYS\tRECENT\tREVEAL\n")
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%t\n", current, len(s.Keys), s.Recent, s.RevealEnabled)
+		return tw.Flush()
+	}
+	if args[0] != "reveal" {
+		return fmt.Errorf("unknown subcommand %q", args[0])
+	}
+	fs := flag.NewFlagSet("reveal", flag.ContinueOnError)
+	reason := fs.String("reason", "", "why the pseudonym is revealed, logged by the service")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 || *reason == "" {
+		return errors.New("usage: reveal -reason r <pseudonym>")
+	}
+	body, err := json.Marshal(privacy.RevealRequest{Pseudonym: fs.Arg(0), Reason: *reason})
+	if err != nil {
+		return err
+	}
+	var rr privacy.RevealResponse
+	if err := c.do(http.MethodPost, privacy.RevealPath, bytes.NewReader(body), &rr); err != nil {
+		return err
+	}
+	fmt.Fprintln(c.out, rr.ID)
+	return nil
+}
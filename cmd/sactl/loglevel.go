@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"text/tabwriter"
+	"time"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/loglevel"
+)
+
+func runLoglevel(c *client, args []string) error {
+	if len(args) == 0 {
+		return errors.New("missing subcommand: list, set or reset")
+	}
+	var state loglevel.State
+	switch args[0] {
+	case "list":
+		if err := c.do(http.MethodGet, loglevel.Path, nil, &state); err != nil {
+			return err
+		}
+	case "set":
+		fs := flag.NewFlagSet("set", flag.ContinueOnError)
+		ttl := fs.Duration("ttl", loglevel.DefaultTTL, "time after which the level resets")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if fs.NArg() != 2 {
+			return errors.New("usage: set [-ttl d] <component> <level>")
+		}
+		body, err := json.Marshal(loglevel.SetRequest{Component: fs.Arg(0), Level: fs.Arg(1), TTL: ttl.String()})
+		if err != nil {
+			return err
+		}
+		if err := c.do(http.MethodPut, loglevel.Path, bytes.NewReader(body), &state); err != nil {
+			return err
+		}
+	case "reset":
+		if len(args) != 2 {
+			return errors.New("usage: reset <component>")
+		}
+		path := loglevel.Path + "?component=" + url.QueryEscape(args[1])
+		if err := c.do(http.MethodDelete, path, nil, &state); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown subcommand %q", args[0])
+	}
+
+	tw := tabwriter.NewWriter(c.out, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "COMPONENT\tLEVEL\tEXPIRES IN\n")
+	fmt.Fprintf(tw, "(default)\t%s\t-\n", state.Default)
+	for _, o := range state.Overrides {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", o.Component, o.Level, time.Until(o.ExpiresAt).Round(time.Second))
+	}
+	return tw.Flush()
+}
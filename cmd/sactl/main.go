@@ -0,0 +1,120 @@
+// Command sactl talks to the admin API of the services, on their admin
+// port.
+//
+//	sactl [-addr http://localhost:9180] loglevel list
+//	sactl loglevel set [-ttl 10m] <component> <level>
+//	sactl loglevel reset <component>
+//	sactl uetrace list | activate [-ttl 1h] [-ref r] <ue> | deactivate <ue>
+//	sactl config | breakers | pools | slo [method]
+//	sactl privacy [status | reveal -reason r <pseudonym>]
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	defAddr string = "http://localhost:9180"
+	envAddr string = "QS_SACTL_ADDR"
+)
+
+type command struct {
+	usage string
+	run   func(c *client, args []string) error
+}
+
+var commands = map[string]command{
+	"loglevel": {"list | set [-ttl d] <component> <level> | reset <component>", runLoglevel},
+	"uetrace":  {"list | activate [-ttl d] [-ref r] <ue> | deactivate <ue>", runUETrace},
+	"config":   {"configuration of the service", runConfig},
+	"breakers": {"state of the circuit breakers", runBreakers},
+	"pools":    {"state of the connection and worker pools", runPools},
+	"slo":      {"[method] error budgets of the methods", runSLO},
+	"privacy":  {"[status | reveal -reason r <pseudonym>] keys of the pseudonyms, reveals", runPrivacy},
+}
+
+type client struct {
+	addr string
+	http *http.Client
+	out  io.Writer
+}
+
+// Env reads specified environment variable. If no value has been found,
+// fallback is returned.
+func env(key string, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+func main() {
+	fs := flag.NewFlagSet("sactl", flag.ExitOnError)
+	addr := fs.String("addr", env(envAddr, defAddr), "base URL of the service admin port")
+	timeout := fs.Duration("timeout", 5*time.Second, "request timeout")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: sactl [flags] <command> [args]\n\ncommands:\n")
+		names := make([]string, 0, len(commands))
+		for name := range commands {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(fs.Output(), "  %s %s\n", name, commands[name].usage)
+		}
+		fmt.Fprintf(fs.Output(), "\nflags:\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(os.Args[1:])
+
+	cmd, ok := commands[fs.Arg(0)]
+	if !ok {
+		fs.Usage()
+		os.Exit(2)
+	}
+	c := &client{
+		addr: strings.TrimSuffix(*addr, "/"),
+		http: &http.Client{Timeout: *timeout},
+		out:  os.Stdout,
+	}
+	if err := cmd.run(c, fs.Args()[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "sactl %s: %v\n", fs.Arg(0), err)
+		os.Exit(1)
+	}
+}
+
+// do sends a request with an optional JSON body and decodes the JSON reply
+// into v. Error replies carry {"error": "..."}.
+func (c *client) do(method, path string, body io.Reader, v interface{}) error {
+	req, err := http.NewRequest(method, c.addr+path, body)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		var w struct {
+			Error string `json:"error"`
+		}
+		if json.NewDecoder(resp.Body).Decode(&w) != nil || w.Error == "" {
+			return errors.New(resp.Status)
+		}
+		return errors.New(w.Error)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
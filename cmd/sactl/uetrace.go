@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"text/tabwriter"
+	"time"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/uetrace"
+)
+
+func runUETrace(c *client, args []string) error {
+	if len(args) == 0 {
+		return errors.New("missing subcommand: list, activate or deactivate")
+	}
+	var activations []uetrace.Activation
+	switch args[0] {
+	case "list":
+		if err := c.do(http.MethodGet, uetrace.Path, nil, &activations); err != nil {
+			return err
+		}
+	case "activate":
+		fs := flag.NewFlagSet("activate", flag.ContinueOnError)
+		ttl := fs.Duration("ttl", uetrace.DefaultTTL, "time after which the trace deactivates")
+		ref := fs.String("ref", "", "trace reference, random when empty")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if fs.NArg() != 1 {
+			return errors.New("usage: activate [-ttl d] [-ref r] <ue>")
+		}
+		body, err := json.Marshal(uetrace.ActivateRequest{UEID: fs.Arg(0), TTL: ttl.String(), Reference: *ref})
+		if err != nil {
+			return err
+		}
+		if err := c.do(http.MethodPut, uetrace.Path, bytes.NewReader(body), &activations); err != nil {
+			return err
+		}
+	case "deactivate":
+		if len(args) != 2 {
+			return errors.New("usage: deactivate <ue>")
+		}
+		path := uetrace.Path + "?ue_id=" + url.QueryEscape(args[1])
+		if err := c.do(http.MethodDelete, path, nil, &activations); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown subcommand %q", args[0])
+	}
+
+	tw := tabwriter.NewWriter(c.out, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "UE\tTRACE REFERENCE\tEXPIRES IN\n")
+	for _, a := range activations {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", a.UEID, a.Reference, time.Until(a.ExpiresAt).Round(time.Second))
+	}
+	return tw.Flush()
+}
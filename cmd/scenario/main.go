@@ -0,0 +1,193 @@
+// Command scenario runs an end-to-end scenario against the AUSF and the UDM
+// and reports the outcome and latencies of every step. It exits with status
+// 1 when the run does not meet the expectations of the scenario. With -push
+// the report is also sent to a Prometheus Pushgateway. The UEs of a scenario
+// with mobility go through the gNB-DUs of -gnbdus, by name. With -speed the
+// scenario runs faster than real time, its latencies being measured in real
+// time still. The UEs registering over WiFi go through the N3IWF of -n3iwf,
+// whose certificate is checked against the CA of -n3iwf-ca when set.
+//
+//	scenario [-ausf localhost:8481] [-udm localhost:8381] deployments/scenario/register.yaml
+//	scenario -push http://localhost:9091 deployments/scenario/load.yaml
+//	scenario -gnbdus du-1=http://localhost:8680,du-2=http://localhost:8690 deployments/scenario/mobility.yaml
+//	scenario -speed 10 deployments/scenario/load.yaml
+//	scenario -n3iwf localhost:4500 deployments/scenario/wifi.yaml
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"google.golang.org/grpc"
+
+	ausfpb "github.com/miki-tnt/sa5g-go-usvc-k8s/pb/ausf"
+	udmpb "github.com/miki-tnt/sa5g-go-usvc-k8s/pb/udm"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/clock"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/scenario"
+)
+
+const (
+	defAUSFAddr string = "localhost:8481"
+	defUDMAddr  string = "localhost:8381"
+	envAUSFAddr string = "QS_SCENARIO_AUSF_ADDR"
+	envUDMAddr  string = "QS_SCENARIO_UDM_ADDR"
+	envPushURL  string = "QS_SCENARIO_PUSHGATEWAY_URL"
+	envGNBDUs   string = "QS_SCENARIO_GNBDUS"
+	envN3IWF    string = "QS_SCENARIO_N3IWF_ADDR"
+)
+
+// Env reads specified environment variable. If no value has been found,
+// fallback is returned.
+func env(key string, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+func main() {
+	fs := flag.NewFlagSet("scenario", flag.ExitOnError)
+	ausfAddr := fs.String("ausf", env(envAUSFAddr, defAUSFAddr), "gRPC address of the AUSF")
+	udmAddr := fs.String("udm", env(envUDMAddr, defUDMAddr), "gRPC address of the UDM")
+	pushURL := fs.String("push", os.Getenv(envPushURL), "Prometheus Pushgateway URL the report is pushed to, none by default")
+	job := fs.String("job", "scenario", "Pushgateway job name")
+	gnbdus := fs.String("gnbdus", os.Getenv(envGNBDUs), "HTTP URLs of the gNB-DUs by name, such as du-1=http://localhost:8680,du-2=http://localhost:8690")
+	n3iwfAddr := fs.String("n3iwf", os.Getenv(envN3IWF), "NWu address of the N3IWF the UEs register through over WiFi, none by default")
+	n3iwfCA := fs.String("n3iwf-ca", "", "PEM file of the CA of the certificate of the N3IWF, not checked by default")
+	speed := fs.Float64("speed", 1, "speed of the scenario relative to real time: its arrivals, pauses, waits and moves run this many times faster")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: scenario [flags] <scenario.yaml>\n\nflags:\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(os.Args[1:])
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	if *speed <= 0 {
+		fmt.Fprintf(os.Stderr, "scenario: -speed %v is not positive\n", *speed)
+		os.Exit(2)
+	}
+
+	data, err := ioutil.ReadFile(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "scenario: %v\n", err)
+		os.Exit(2)
+	}
+	sc, err := scenario.Parse(data)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "scenario %s: %v\n", fs.Arg(0), err)
+		os.Exit(2)
+	}
+	dus, err := parseGNBDUs(*gnbdus)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "scenario: -gnbdus: %v\n", err)
+		os.Exit(2)
+	}
+	if sc.Mobility != nil {
+		for _, c := range sc.Mobility.Cells {
+			if _, ok := dus[c.GNBDU]; !ok {
+				fmt.Fprintf(os.Stderr, "scenario %s: cell %d: gNB-DU %q not in -gnbdus\n", fs.Arg(0), c.NCI, c.GNBDU)
+				os.Exit(2)
+			}
+		}
+	}
+	var n3iwf *scenario.N3IWF
+	if *n3iwfAddr != "" {
+		config, err := n3iwfTLS(*n3iwfCA)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "scenario: -n3iwf-ca: %v\n", err)
+			os.Exit(2)
+		}
+		n3iwf = scenario.NewN3IWF(*n3iwfAddr, config)
+	}
+	for i, st := range sc.Steps {
+		if st.Action == scenario.ActionRegisterWiFi && n3iwf == nil {
+			fmt.Fprintf(os.Stderr, "scenario %s: step %d: %s needs -n3iwf\n", fs.Arg(0), i+1, st.Action)
+			os.Exit(2)
+		}
+	}
+
+	// the connections are made lazily, an unreachable service shows up as
+	// failed steps
+	ausfConn, err := grpc.Dial(*ausfAddr, grpc.WithInsecure())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "scenario: %v\n", err)
+		os.Exit(1)
+	}
+	defer ausfConn.Close()
+	udmConn, err := grpc.Dial(*udmAddr, grpc.WithInsecure())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "scenario: %v\n", err)
+		os.Exit(1)
+	}
+	defer udmConn.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		c := make(chan os.Signal, 1)
+		signal.Notify(c, syscall.SIGINT, syscall.SIGTERM)
+		<-c
+		cancel()
+	}()
+
+	rep := scenario.Run(ctx, sc, scenario.Targets{
+		AUSF:   ausfpb.NewAusfClient(ausfConn),
+		UDM:    udmpb.NewUdmClient(udmConn),
+		N3IWF:  n3iwf,
+		GNBDUs: dus,
+		Clock:  clock.Scaled(*speed),
+	})
+	rep.Write(os.Stdout)
+	if *pushURL != "" {
+		if err := scenario.Push(*pushURL, *job, rep); err != nil {
+			fmt.Fprintf(os.Stderr, "scenario: push: %v\n", err)
+		}
+	}
+	if len(rep.Failures()) != 0 {
+		os.Exit(1)
+	}
+}
+
+// parseGNBDUs parses the name=url list of the gNB-DUs.
+func parseGNBDUs(s string) (map[string]*scenario.GNBDU, error) {
+	dus := make(map[string]*scenario.GNBDU)
+	if s == "" {
+		return dus, nil
+	}
+	for _, kv := range strings.Split(s, ",") {
+		i := strings.Index(kv, "=")
+		if i <= 0 || i == len(kv)-1 {
+			return nil, fmt.Errorf("%q is not name=url", kv)
+		}
+		dus[kv[:i]] = scenario.NewGNBDU(kv[i+1:])
+	}
+	return dus, nil
+}
+
+// n3iwfTLS returns the TLS configuration of the NWu, trusting the CA of the
+// PEM file ca or, without it, any certificate: that of an N3IWF that has
+// none is self-signed.
+func n3iwfTLS(ca string) (*tls.Config, error) {
+	if ca == "" {
+		return &tls.Config{InsecureSkipVerify: true}, nil
+	}
+	pem, err := ioutil.ReadFile(ca)
+	if err != nil {
+		return nil, err
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(pem) {
+		return nil, errors.New("no certificate in " + ca)
+	}
+	return &tls.Config{RootCAs: roots}, nil
+}
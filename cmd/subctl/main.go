@@ -0,0 +1,323 @@
+// Command subctl provisions the test subscribers of the UDM.
+//
+//	subctl [-addr http://localhost:8380] [-plmn 208-93] create -supi imsi-208930000000001 -k <hex> -opc <hex> [-sst 1 -sd 010203] [-ambr-ul "1 Gbps" -ambr-dl "2 Gbps"]
+//	subctl update -supi imsi-208930000000001 [-sqn <hex>] [...]
+//	subctl delete <supi>
+//	subctl list [-page-size n] [-page-token t]
+//	subctl load <subscribers.json>
+//	subctl history [-since 2h | -since 2020-06-01T08:00:00Z] [-until t] [-page-size n] [-page-token t] <supi>
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	stdopentracing "github.com/opentracing/opentracing-go"
+	"github.com/openzipkin/zipkin-go"
+	"google.golang.org/grpc/status"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/plmn"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/udm/service"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/udm/subscriber"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/udm/transports"
+)
+
+const (
+	defAddr string = "http://localhost:8380"
+	envAddr string = "QS_SUBCTL_ADDR"
+)
+
+type command struct {
+	usage string
+	run   func(ctx context.Context, udm service.UdmService, out io.Writer, args []string) error
+}
+
+var commands = map[string]command{
+	"create":  {"-supi s -k hex (-opc hex | -op hex) [-amf hex] [-sqn hex] [-sst n [-sd hex]] [-ambr-ul r -ambr-dl r]", runCreate},
+	"update":  {"-supi s [same flags as create]", runUpdate},
+	"delete":  {"<supi>", runDelete},
+	"list":    {"[-page-size n] [-page-token t]", runList},
+	"load":    {"<subscribers.json>", runLoad},
+	"history": {"[-since t] [-until t] [-page-size n] [-page-token t] <supi>", runHistory},
+}
+
+// Env reads specified environment variable. If no value has been found,
+// fallback is returned.
+func env(key string, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+func main() {
+	fs := flag.NewFlagSet("subctl", flag.ExitOnError)
+	addr := fs.String("addr", env(envAddr, defAddr), "base URL of the UDM HTTP port")
+	timeout := fs.Duration("timeout", 10*time.Second, "timeout of the whole command")
+	plmnID := fs.String("plmn", "", "PLMN (MCC-MNC) the requests are made for, none by default")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: subctl [flags] <command> [args]\n\ncommands:\n")
+		names := make([]string, 0, len(commands))
+		for name := range commands {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(fs.Output(), "  %s %s\n", name, commands[name].usage)
+		}
+		fmt.Fprintf(fs.Output(), "\nflags:\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(os.Args[1:])
+
+	cmd, ok := commands[fs.Arg(0)]
+	if !ok {
+		fs.Usage()
+		os.Exit(2)
+	}
+	zipkinTracer, _ := zipkin.NewTracer(nil, zipkin.WithNoopTracer(true))
+	udm, err := transports.NewHTTPClient(*addr, stdopentracing.GlobalTracer(), zipkinTracer, log.NewNopLogger())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "subctl: %v\n", err)
+		os.Exit(1)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	if *plmnID != "" {
+		id, err := plmn.Parse(*plmnID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "subctl: %v\n", err)
+			os.Exit(2)
+		}
+		ctx = plmn.NewContext(ctx, id)
+	}
+	err = cmd.run(ctx, udm, os.Stdout, fs.Args()[1:])
+	cancel()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "subctl %s: %s\n", fs.Arg(0), status.Convert(err).Message())
+		os.Exit(1)
+	}
+}
+
+// hexFlag is a flag.Value holding a hex encoded byte string.
+type hexFlag struct {
+	b *subscriber.Hex
+}
+
+func (f hexFlag) String() string {
+	if f.b == nil {
+		return ""
+	}
+	return hex.EncodeToString(*f.b)
+}
+
+func (f hexFlag) Set(s string) error {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return err
+	}
+	*f.b = b
+	return nil
+}
+
+// parseSubscriber parses the subscriber flags of create and update.
+func parseSubscriber(name string, args []string) (sub subscriber.Subscriber, err error) {
+	var (
+		fs     = flag.NewFlagSet(name, flag.ContinueOnError)
+		sst    = fs.Int("sst", -1, "slice/service type of the default S-NSSAI")
+		sd     = fs.String("sd", "", "slice differentiator of the default S-NSSAI, 6 hex digits")
+		ambrUL = fs.String("ambr-ul", "", "uplink subscribed UE-AMBR, e.g. \"1 Gbps\"")
+		ambrDL = fs.String("ambr-dl", "", "downlink subscribed UE-AMBR")
+	)
+	fs.StringVar(&sub.SUPI, "supi", "", "SUPI, e.g. imsi-208930000000001")
+	fs.Var(hexFlag{&sub.K}, "k", "permanent key K")
+	fs.Var(hexFlag{&sub.OP}, "op", "operator variant OP, OPc is derived from it")
+	fs.Var(hexFlag{&sub.OPc}, "opc", "operator variant OPc")
+	fs.Var(hexFlag{&sub.AMF}, "amf", "authentication management field")
+	fs.Var(hexFlag{&sub.SQN}, "sqn", "sequence number")
+	if err := fs.Parse(args); err != nil {
+		return sub, err
+	}
+	if fs.NArg() != 0 {
+		return sub, fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+	if *sst > 255 {
+		return sub, errors.New("sst must be between 0 and 255")
+	}
+	if *sst >= 0 {
+		sub.DefaultSNSSAI = &subscriber.SNSSAI{SST: uint8(*sst), SD: *sd}
+	} else if *sd != "" {
+		return sub, errors.New("sd requires sst")
+	}
+	if *ambrUL != "" || *ambrDL != "" {
+		sub.AMBR = &subscriber.AMBR{Uplink: *ambrUL, Downlink: *ambrDL}
+	}
+	return sub, nil
+}
+
+func runCreate(ctx context.Context, udm service.UdmService, out io.Writer, args []string) error {
+	sub, err := parseSubscriber("create", args)
+	if err != nil {
+		return err
+	}
+	if sub, err = udm.CreateSubscriber(ctx, sub); err != nil {
+		return err
+	}
+	printSubscribers(out, sub)
+	return nil
+}
+
+func runUpdate(ctx context.Context, udm service.UdmService, out io.Writer, args []string) error {
+	sub, err := parseSubscriber("update", args)
+	if err != nil {
+		return err
+	}
+	if sub, err = udm.UpdateSubscriber(ctx, sub); err != nil {
+		return err
+	}
+	printSubscribers(out, sub)
+	return nil
+}
+
+func runDelete(ctx context.Context, udm service.UdmService, out io.Writer, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: delete <supi>")
+	}
+	return udm.DeleteSubscriber(ctx, args[0])
+}
+
+func runList(ctx context.Context, udm service.UdmService, out io.Writer, args []string) error {
+	fs := flag.NewFlagSet("list", flag.ContinueOnError)
+	pageSize := fs.Int("page-size", 0, "subscribers per page, 0 for the server default")
+	pageToken := fs.String("page-token", "", "token of the page to list, from a previous list")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	subs, next, err := udm.ListSubscribers(ctx, *pageSize, *pageToken)
+	if err != nil {
+		return err
+	}
+	printSubscribers(out, subs...)
+	if next != "" {
+		fmt.Fprintf(out, "\nnext page: -page-token %s\n", next)
+	}
+	return nil
+}
+
+// runLoad creates the subscribers of a JSON array in the format of
+// deployments/udm/subscribers.json. It goes on after a failure and reports
+// every subscriber it could not create.
+func runLoad(ctx context.Context, udm service.UdmService, out io.Writer, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: load <subscribers.json>")
+	}
+	data, err := ioutil.ReadFile(args[0])
+	if err != nil {
+		return err
+	}
+	var subs []subscriber.Subscriber
+	if err := json.Unmarshal(data, &subs); err != nil {
+		return err
+	}
+	var failed int
+	for _, sub := range subs {
+		if _, err := udm.CreateSubscriber(ctx, sub); err != nil {
+			fmt.Fprintf(out, "%s: %s\n", sub.SUPI, status.Convert(err).Message())
+			failed++
+		}
+	}
+	fmt.Fprintf(out, "loaded %d of %d subscribers\n", len(subs)-failed, len(subs))
+	if failed > 0 {
+		return fmt.Errorf("%d subscribers not loaded", failed)
+	}
+	return nil
+}
+
+func runHistory(ctx context.Context, udm service.UdmService, out io.Writer, args []string) error {
+	fs := flag.NewFlagSet("history", flag.ContinueOnError)
+	since := fs.String("since", "", "start of the time range, RFC 3339 or a duration before now")
+	until := fs.String("until", "", "end of the time range, excluded, RFC 3339 or a duration before now")
+	pageSize := fs.Int("page-size", 0, "events per page, 0 for the server default")
+	pageToken := fs.String("page-token", "", "token of the page to show, from a previous history")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("usage: history [flags] <supi>")
+	}
+	now := time.Now()
+	from, err := parseTime(*since, now)
+	if err != nil {
+		return err
+	}
+	to, err := parseTime(*until, now)
+	if err != nil {
+		return err
+	}
+	events, next, err := udm.GetUEHistory(ctx, fs.Arg(0), from, to, *pageSize, *pageToken)
+	if err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "TIME\tTYPE\tOUTCOME\tSOURCE\tDETAIL\n")
+	for _, e := range events {
+		keys := make([]string, 0, len(e.Detail))
+		for k := range e.Detail {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		detail := make([]string, len(keys))
+		for i, k := range keys {
+			detail[i] = k + "=" + e.Detail[k]
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", e.Time.Format(time.RFC3339Nano), e.Type, e.Outcome, e.Source, strings.Join(detail, " "))
+	}
+	tw.Flush()
+	if next != "" {
+		fmt.Fprintf(out, "\nnext page: -page-token %s\n", next)
+	}
+	return nil
+}
+
+// parseTime parses an RFC 3339 time, or a duration counted back from now.
+// The empty string gives the zero time.
+func parseTime(s string, now time.Time) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+func printSubscribers(out io.Writer, subs ...subscriber.Subscriber) {
+	tw := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "SUPI\tAMF\tSQN\tS-NSSAI\tAMBR UL/DL\n")
+	for _, s := range subs {
+		snssai, ambr := "-", "-"
+		if s.DefaultSNSSAI != nil {
+			snssai = fmt.Sprintf("%d", s.DefaultSNSSAI.SST)
+			if s.DefaultSNSSAI.SD != "" {
+				snssai += "/" + s.DefaultSNSSAI.SD
+			}
+		}
+		if s.AMBR != nil {
+			ambr = s.AMBR.Uplink + " / " + s.AMBR.Downlink
+		}
+		fmt.Fprintf(tw, "%s\t%x\t%x\t%s\t%s\n", s.SUPI, []byte(s.AMF), []byte(s.SQN), snssai, ambr)
+	}
+	tw.Flush()
+}
@@ -0,0 +1,390 @@
+// Command traceviz draws the sequence diagram of a procedure (see pkg/meta)
+// from its traces, as exported by the services to Zipkin: every call between
+// two services becomes a message, and its outcome and duration the reply.
+//
+//	traceviz [-zipkin http://localhost:9411] [-lookback 1h] [-format mermaid] procedure-id
+//	traceviz -file traces.json [-format plantuml] procedure-id
+//
+// The traces are fetched from the Zipkin API by their procedure_id tag, or
+// read from file, a JSON list of traces or of spans as the Zipkin API and
+// UI export them ("-" for the standard input). The diagram is written in
+// Mermaid or PlantUML to the standard output.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/openzipkin/zipkin-go/model"
+)
+
+const (
+	defZipkin string = "http://localhost:9411"
+	envZipkin string = "QS_TRACEVIZ_ZIPKIN"
+)
+
+// The output formats.
+const (
+	formatMermaid  = "mermaid"
+	formatPlantUML = "plantuml"
+)
+
+// client is the caller of the calls whose caller was not traced, unknown
+// the service of the spans that do not name it.
+const (
+	client  = "client"
+	unknown = "unknown"
+)
+
+// Env reads specified environment variable. If no value has been found,
+// fallback is returned.
+func env(key string, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+func main() {
+	fs := flag.NewFlagSet("traceviz", flag.ExitOnError)
+	zipkinURL := fs.String("zipkin", env(envZipkin, defZipkin), "base URL of the Zipkin API to fetch the traces from")
+	file := fs.String("file", "", "read the traces from this file instead, - for the standard input")
+	lookback := fs.Duration("lookback", time.Hour, "how far back to look for the traces in Zipkin")
+	limit := fs.Int("limit", 10, "maximum number of traces fetched from Zipkin")
+	format := fs.String("format", formatMermaid, "diagram format: mermaid or plantuml")
+	timeout := fs.Duration("timeout", 5*time.Second, "request timeout")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: traceviz [flags] procedure-id\n\nflags:\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(os.Args[1:])
+	if fs.NArg() != 1 || (*format != formatMermaid && *format != formatPlantUML) {
+		fs.Usage()
+		os.Exit(2)
+	}
+	procedure := fs.Arg(0)
+
+	var traces [][]model.SpanModel
+	var err error
+	if *file != "" {
+		traces, err = readFile(*file)
+	} else {
+		traces, err = fetch(*zipkinURL, procedure, *lookback, *limit, *timeout)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "traceviz: %v\n", err)
+		os.Exit(1)
+	}
+	var spans []model.SpanModel
+	for _, t := range traces {
+		if hasProcedure(t, procedure) {
+			spans = append(spans, t...)
+		}
+	}
+	if len(spans) == 0 {
+		fmt.Fprintf(os.Stderr, "traceviz: no trace of procedure %s\n", procedure)
+		os.Exit(1)
+	}
+	d := newDiagram(spans)
+	if *format == formatPlantUML {
+		d.plantUML(os.Stdout, procedure)
+	} else {
+		d.mermaid(os.Stdout, procedure)
+	}
+}
+
+// fetch returns the traces of the procedure from the Zipkin API at base.
+func fetch(base, procedure string, lookback time.Duration, limit int, timeout time.Duration) ([][]model.SpanModel, error) {
+	q := url.Values{}
+	q.Set("annotationQuery", "procedure_id="+procedure)
+	q.Set("endTs", strconv.FormatInt(time.Now().UnixNano()/int64(time.Millisecond), 10))
+	q.Set("lookback", strconv.FormatInt(int64(lookback/time.Millisecond), 10))
+	q.Set("limit", strconv.Itoa(limit))
+	c := &http.Client{Timeout: timeout}
+	resp, err := c.Get(strings.TrimSuffix(base, "/") + "/api/v2/traces?" + q.Encode())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("zipkin answered %s", resp.Status)
+	}
+	var traces [][]model.SpanModel
+	err = json.NewDecoder(resp.Body).Decode(&traces)
+	return traces, err
+}
+
+// readFile reads the traces of file, a list of traces or a list of spans.
+func readFile(file string) ([][]model.SpanModel, error) {
+	var r io.Reader = os.Stdin
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var traces [][]model.SpanModel
+	if err := json.Unmarshal(b, &traces); err == nil {
+		return traces, nil
+	}
+	var spans []model.SpanModel
+	if err := json.Unmarshal(b, &spans); err != nil {
+		return nil, errors.New("not a JSON list of Zipkin traces or spans")
+	}
+	// a flat list of spans, of one or more traces
+	byTrace := make(map[model.TraceID]int)
+	for _, s := range spans {
+		i, ok := byTrace[s.TraceID]
+		if !ok {
+			i = len(traces)
+			byTrace[s.TraceID] = i
+			traces = append(traces, nil)
+		}
+		traces[i] = append(traces[i], s)
+	}
+	return traces, nil
+}
+
+func hasProcedure(trace []model.SpanModel, procedure string) bool {
+	for _, s := range trace {
+		if s.Tags["procedure_id"] == procedure {
+			return true
+		}
+	}
+	return false
+}
+
+// call is a call from a service to another.
+type call struct {
+	from, to   string
+	name       string
+	start, end time.Time
+	outcome    string
+	// depth is the number of calls it is nested in.
+	depth int
+}
+
+// diagram is the sequence of the calls of a procedure.
+type diagram struct {
+	participants []string
+	calls        []call
+}
+
+// newDiagram returns the diagram of the calls of spans. A call is the
+// server span of the callee, or the client span of the caller when the
+// callee was not traced. The server span of a gRPC call shares the ID of
+// its client span, that of an HTTP call is its child.
+func newDiagram(spans []model.SpanModel) *diagram {
+	// the server spans shadow the client spans sharing their ID
+	byID := make(map[model.ID]*model.SpanModel, len(spans))
+	clients := make(map[model.ID]*model.SpanModel)
+	for i := range spans {
+		s := &spans[i]
+		if s.Kind == model.Client {
+			clients[s.ID] = s
+		}
+		if byID[s.ID] == nil || s.Kind == model.Server {
+			byID[s.ID] = s
+		}
+	}
+	// caller returns the client span of the server span s, nil when its
+	// caller was not traced.
+	caller := func(s *model.SpanModel) *model.SpanModel {
+		if c := clients[s.ID]; c != nil {
+			return c
+		}
+		if s.ParentID != nil {
+			return clients[*s.ParentID]
+		}
+		return nil
+	}
+	served := make(map[model.ID]bool)
+	for i := range spans {
+		if s := &spans[i]; s.Kind == model.Server {
+			if c := caller(s); c != nil {
+				served[c.ID] = true
+			}
+		}
+	}
+	var d diagram
+	for i := range spans {
+		s := &spans[i]
+		c := call{name: spanName(s), start: s.Timestamp, end: s.Timestamp.Add(s.Duration), outcome: outcome(s), depth: depth(s, byID)}
+		switch {
+		case s.Kind == model.Server:
+			c.from, c.to = client, service(s.LocalEndpoint)
+			if cs := caller(s); cs != nil {
+				c.from = service(cs.LocalEndpoint)
+			} else if name := service(s.RemoteEndpoint); name != "" {
+				c.from = name
+			}
+		case s.Kind == model.Client && !served[s.ID]:
+			c.from, c.to = service(s.LocalEndpoint), service(s.RemoteEndpoint)
+		default:
+			continue
+		}
+		if c.from == "" {
+			c.from = unknown
+		}
+		if c.to == "" {
+			c.to = unknown
+		}
+		d.calls = append(d.calls, c)
+	}
+	sort.SliceStable(d.calls, func(i, j int) bool { return d.calls[i].start.Before(d.calls[j].start) })
+	seen := make(map[string]bool)
+	for _, c := range d.calls {
+		for _, p := range []string{c.from, c.to} {
+			if !seen[p] {
+				seen[p] = true
+				d.participants = append(d.participants, p)
+			}
+		}
+	}
+	return &d
+}
+
+// message is an arrow of the diagram: a request, or the reply to one.
+type message struct {
+	at    time.Time
+	reply bool
+	c     *call
+}
+
+// messages returns the requests and replies of d in their order: by time,
+// a reply before a request sent at the same time, the innermost reply and
+// the outermost request first.
+func (d *diagram) messages() []message {
+	ms := make([]message, 0, 2*len(d.calls))
+	for i := range d.calls {
+		c := &d.calls[i]
+		ms = append(ms, message{at: c.start, c: c}, message{at: c.end, reply: true, c: c})
+	}
+	sort.SliceStable(ms, func(i, j int) bool {
+		a, b := ms[i], ms[j]
+		switch {
+		case !a.at.Equal(b.at):
+			return a.at.Before(b.at)
+		case a.reply != b.reply:
+			return a.reply
+		case a.reply:
+			return a.c.depth > b.c.depth
+		default:
+			return a.c.depth < b.c.depth
+		}
+	})
+	return ms
+}
+
+func (d *diagram) mermaid(w io.Writer, procedure string) {
+	fmt.Fprintln(w, "sequenceDiagram")
+	fmt.Fprintf(w, "    title procedure %s\n", procedure)
+	for _, p := range d.participants {
+		fmt.Fprintf(w, "    participant %s\n", p)
+	}
+	for _, m := range d.messages() {
+		if m.reply {
+			fmt.Fprintf(w, "    %s-->>%s: %s\n", m.c.to, m.c.from, label(reply(m.c)))
+		} else {
+			fmt.Fprintf(w, "    %s->>%s: %s\n", m.c.from, m.c.to, label(m.c.name))
+		}
+	}
+}
+
+func (d *diagram) plantUML(w io.Writer, procedure string) {
+	fmt.Fprintln(w, "@startuml")
+	fmt.Fprintf(w, "title procedure %s\n", procedure)
+	for _, p := range d.participants {
+		fmt.Fprintf(w, "participant %q\n", p)
+	}
+	for _, m := range d.messages() {
+		if m.reply {
+			fmt.Fprintf(w, "%q --> %q: %s\n", m.c.to, m.c.from, label(reply(m.c)))
+		} else {
+			fmt.Fprintf(w, "%q -> %q: %s\n", m.c.from, m.c.to, label(m.c.name))
+		}
+	}
+	fmt.Fprintln(w, "@enduml")
+}
+
+func reply(c *call) string {
+	return fmt.Sprintf("%s (%s)", c.outcome, c.end.Sub(c.start).Round(10*time.Microsecond))
+}
+
+// label returns s fit for the text of an arrow, in either format.
+func label(s string) string {
+	return strings.NewReplacer("\n", " ", ";", ",", "#", "").Replace(s)
+}
+
+// spanName returns the name of the method of s, the gRPC ones being named
+// after their full path and the HTTP ones after their verb.
+func spanName(s *model.SpanModel) string {
+	name := s.Name
+	if path, ok := s.Tags["http.path"]; ok {
+		name = strings.TrimSuffix(path, "/")
+	}
+	if i := strings.LastIndex(name, "/"); i >= 0 && i < len(name)-1 {
+		name = name[i+1:]
+	}
+	return name
+}
+
+// outcome returns "ok", or the error s was tagged with.
+func outcome(s *model.SpanModel) string {
+	if err, ok := s.Tags["error"]; ok {
+		if err == "" || err == "true" {
+			return "error"
+		}
+		return "error: " + err
+	}
+	return "ok"
+}
+
+// depth returns the number of calls s is nested in, counting its server
+// ancestors.
+func depth(s *model.SpanModel, byID map[model.ID]*model.SpanModel) int {
+	n := 0
+	// bounded, in case of a loop of malformed parents
+	for i := 0; s.ParentID != nil && i < len(byID); i++ {
+		p := byID[*s.ParentID]
+		if p == nil {
+			break
+		}
+		if p.Kind == model.Server {
+			n++
+		}
+		s = p
+	}
+	return n
+}
+
+// service returns the service name of e, a name Mermaid and PlantUML take
+// as is, "" when e has none.
+func service(e *model.Endpoint) string {
+	if e == nil {
+		return ""
+	}
+	return strings.Map(func(r rune) rune {
+		if ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9') {
+			return r
+		}
+		return '_'
+	}, e.ServiceName)
+}
@@ -0,0 +1,426 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/go-kit/kit/metrics/expvar"
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
+
+	pb "github.com/miki-tnt/sa5g-go-usvc-k8s/pb/udm"
+	pbv2 "github.com/miki-tnt/sa5g-go-usvc-k8s/pb/udm/v2"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/admin"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/backup"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/bootstrap"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/etcd"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/events"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/exposure"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/flags"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/history"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/kitx/chain"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/loglevel"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/plmn"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/postgres"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/schema"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/store"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/udm/endpoints"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/udm/service"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/udm/subscriber"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/udm/transports"
+)
+
+const (
+	defFlagsFile       string = ""
+	defOAMHTTPPort     string = ""
+	defOAMGRPCPort     string = ""
+	defOAMRate         string = "1"
+	defOAMBurst        string = "100"
+	defOAMConcurrent   string = "0"
+	defOAMQueue        string = "100"
+	defSubscribersFile string = ""
+	defHistoryTTL      string = "720h"
+	defBackupEndpoint  string = ""
+	defBackupRegion    string = "us-east-1"
+	defBackupBucket    string = ""
+	defBackupAccessKey string = ""
+	defBackupSecretKey string = ""
+	defBackupKey       string = ""
+	defBackupInterval  string = "0s"
+
+	envFlagsFile       string = "QS_FLAGS_FILE"
+	envOAMHTTPPort     string = "QS_UDM_OAM_HTTP_PORT"
+	envOAMGRPCPort     string = "QS_UDM_OAM_GRPC_PORT"
+	envOAMRate         string = "QS_UDM_OAM_RATE"
+	envOAMBurst        string = "QS_UDM_OAM_BURST"
+	envOAMConcurrent   string = "QS_UDM_OAM_MAX_CONCURRENT"
+	envOAMQueue        string = "QS_UDM_OAM_ADMISSION_QUEUE"
+	envSubscribersFile string = "QS_UDM_SUBSCRIBERS_FILE"
+	envHistoryTTL      string = "QS_UDM_HISTORY_TTL"
+	envBackupEndpoint  string = "QS_BACKUP_ENDPOINT"
+	envBackupRegion    string = "QS_BACKUP_REGION"
+	envBackupBucket    string = "QS_BACKUP_BUCKET"
+	envBackupAccessKey string = "QS_BACKUP_ACCESS_KEY"
+	envBackupSecretKey string = "QS_BACKUP_SECRET_KEY"
+	envBackupKey       string = "QS_BACKUP_KEY"
+	envBackupInterval  string = "QS_UDM_BACKUP_INTERVAL"
+)
+
+// spec is the UDM as bootstrapped, the environment variables of its own
+// being prefixed with QS_UDM_.
+var spec = bootstrap.Spec{
+	Name:      "udm",
+	HTTPPort:  "8380",
+	GRPCPort:  "8381",
+	Capture:   true,
+	Limits:    true,
+	Admission: true,
+	PLMNs:     true,
+	Redis:     true,
+	Postgres:  true,
+	Etcd:      true,
+	Schemas:   []*schema.Schema{subscriber.Schema, history.Schema},
+}
+
+type config struct {
+	bootstrap.Config
+	flagsFile       string
+	oamHTTPPort     string
+	oamGRPCPort     string
+	oamRate         float64
+	oamBurst        int
+	oamConcurrent   int
+	oamQueue        int
+	subscribersFile string
+	historyTTL      time.Duration
+	backupEndpoint  string
+	backupRegion    string
+	backupBucket    string
+	backupAccessKey string
+	backupInterval  time.Duration
+}
+
+func main() {
+	restore := flag.String("restore", "", "restore the state from this snapshot of the backup bucket, or from the latest one, before serving")
+	flag.Parse()
+
+	nf := bootstrap.New(spec)
+	cfg := loadConfig(nf)
+	nf.Report(cfg)
+	logger := nf.Logger
+	st := nf.Store
+
+	// with PostgreSQL, the subscribers have a table of their own; with
+	// etcd, they are updated by comparing and swapping
+	subscribers := subscriber.NewRepository(st)
+	if nf.Postgres != nil {
+		subscribers = postgres.NewSubscribers(nf.Postgres)
+	} else if nf.Etcd != nil {
+		subscribers = etcd.NewSubscribers(nf.Etcd, spec.Name+"/")
+	}
+	if cfg.subscribersFile != "" {
+		loadSubscribers(subscribers, cfg.subscribersFile, logger)
+	}
+	// the pseudonyms of the logs and captures are revealed among the
+	// subscribers
+	nf.Privacy.SetCandidates(func(ctx context.Context) ([]string, error) { return supis(ctx, subscribers) })
+	// the events of the UEs are kept for historyTTL, forever when it is zero
+	// and streamed as they happen to the dashboards watching events.Path
+	broker := events.NewBroker(
+		events.PublishedCounter(expvar.NewCounter("events_published")),
+		events.DroppedCounter(expvar.NewCounter("events_dropped")),
+		events.SubscribersGauge(expvar.NewGauge("events_subscribers")),
+	)
+	bk := initBackup(nf, cfg, st, log.With(logger, loglevel.ComponentKey, "backup"))
+	if *restore != "" {
+		restoreBackup(bk, *restore, logger)
+	}
+	// they are also posted to the webhooks subscribed on exposure.Path, until
+	// acknowledged
+	exp := exposure.New(st, log.With(logger, loglevel.ComponentKey, "exposure"),
+		exposure.DeliveredCounter(expvar.NewCounter("exposure_delivered")),
+		exposure.FailedCounter(expvar.NewCounter("exposure_failed")),
+	)
+	hist := history.New(st, history.Retention(cfg.historyTTL), history.Notify(func(supi string, e history.Event) {
+		broker.Publish(ueEvent(supi, e))
+		exp.Publish(ueEvent(supi, e))
+	}))
+	// with etcd, the changes of the provisioned data of the subscribers,
+	// made by any UDM or by a provisioning system writing etcd, are
+	// published too
+	if nf.Etcd != nil && nf.Postgres == nil {
+		prefix := spec.Name + "/" + subscriber.KeyPrefix
+		go nf.Etcd.Watch(context.Background(), prefix, func(e etcd.Event) {
+			if ev, ok := subscriberEvent(prefix, e); ok {
+				broker.Publish(ev)
+				exp.Publish(ev)
+			}
+		})
+	}
+
+	fl, flagsFile := initFlags(cfg.flagsFile, log.With(logger, loglevel.ComponentKey, "flags"))
+	if flagsFile != nil {
+		nf.Admin(admin.Handle(flags.Path, flags.Handler(flagsFile)))
+	}
+	service := NewServer(subscribers, hist, cfg.ServedPLMNs, fl, nf.RequestLogger())
+	// the requests over the maximum of concurrent ones wait by priority;
+	// the operation and maintenance ones have limits of their own, and the
+	// emergency ones skip the rate limits
+	mw := nf.Middleware()
+	mw.EmergencyBypass = true
+	mw.OAM = &chain.Limits{Rate: rate.Limit(cfg.oamRate), Burst: cfg.oamBurst, Admission: nf.NewAdmission("oam_admission", cfg.oamConcurrent, cfg.oamQueue)}
+	endpoints := endpoints.New(service, mw)
+
+	// with ports of their own, the operation and maintenance APIs leave the
+	// signalling ones; the log levels and the trace activations are on the
+	// admin port only
+	signalling := endpoints
+	if cfg.oamHTTPPort != "" {
+		nf.HTTP(cfg.oamHTTPPort, httpRoutes(nf, endpoints, broker, exp))
+		nf.HTTP(cfg.HTTPPort, httpRoutes(nf, endpoints.Signalling(), nil, nil))
+	} else {
+		nf.HTTP(cfg.HTTPPort, httpRoutes(nf, endpoints, broker, exp))
+	}
+	if cfg.oamGRPCPort != "" {
+		signalling = endpoints.Signalling()
+		nf.GRPC(cfg.oamGRPCPort, grpcServices(nf, endpoints))
+	}
+	nf.GRPC(cfg.GRPCPort, grpcServices(nf, signalling))
+	if bk != nil && cfg.backupInterval > 0 {
+		go bk.Run(context.Background(), cfg.backupInterval)
+	}
+	go exp.Run(context.Background(), exposure.DefaultInterval)
+	nf.Run()
+}
+
+func loadConfig(nf *bootstrap.NF) (cfg config) {
+	cfg.Config = nf.Config
+	cfg.flagsFile = nf.String(envFlagsFile, defFlagsFile)
+	cfg.oamHTTPPort = nf.String(envOAMHTTPPort, defOAMHTTPPort)
+	cfg.oamGRPCPort = nf.String(envOAMGRPCPort, defOAMGRPCPort)
+	cfg.oamRate = nf.Float(envOAMRate, defOAMRate)
+	cfg.oamBurst = nf.Int(envOAMBurst, defOAMBurst)
+	cfg.oamConcurrent = nf.Int(envOAMConcurrent, defOAMConcurrent)
+	cfg.oamQueue = nf.Int(envOAMQueue, defOAMQueue)
+	cfg.subscribersFile = nf.String(envSubscribersFile, defSubscribersFile)
+	cfg.historyTTL = nf.Duration(envHistoryTTL, defHistoryTTL)
+	cfg.backupEndpoint = nf.String(envBackupEndpoint, defBackupEndpoint)
+	cfg.backupRegion = nf.String(envBackupRegion, defBackupRegion)
+	cfg.backupBucket = nf.String(envBackupBucket, defBackupBucket)
+	cfg.backupAccessKey = nf.String(envBackupAccessKey, defBackupAccessKey)
+	cfg.backupInterval = nf.Duration(envBackupInterval, defBackupInterval)
+	return cfg
+}
+
+// initBackup returns the backup of the subscribers, of st or of the database
+// of nf, and the histories of st to the bucket configured, or nil when none
+// is. The snapshots are encrypted when
+// a key is configured. The secrets are read here rather than in loadConfig so
+// that the admin port does not report them.
+func initBackup(nf *bootstrap.NF, cfg config, st store.Store, logger log.Logger) *backup.Backup {
+	if cfg.backupEndpoint == "" {
+		return nil
+	}
+	bucket, err := backup.NewS3(cfg.backupEndpoint, cfg.backupRegion, cfg.backupBucket, cfg.backupAccessKey, nf.String(envBackupSecretKey, defBackupSecretKey))
+	if err != nil {
+		level.Error(logger).Log("backup", cfg.backupEndpoint, "err", err)
+		os.Exit(1)
+	}
+	options := []backup.Option{backup.Logger(logger)}
+	if key := nf.String(envBackupKey, defBackupKey); key != "" {
+		b, err := base64.StdEncoding.DecodeString(key)
+		if err != nil {
+			level.Error(logger).Log("envBackupKey", envBackupKey, "error", err)
+			os.Exit(1)
+		}
+		options = append(options, backup.Encrypt(b))
+	}
+	subscribers := backup.StoreSource("subscribers", st, backup.Prefix{Prefix: subscriber.KeyPrefix})
+	if nf.Postgres != nil {
+		subscribers = postgres.SubscriberSource(nf.Postgres)
+	}
+	bk, err := backup.New(bucket, cfg.ServiceName+"/", []backup.Source{
+		subscribers,
+		backup.StoreSource("history", st, backup.Prefix{Prefix: history.KeyPrefix, TTL: cfg.historyTTL}),
+	}, options...)
+	if err != nil {
+		level.Error(logger).Log("backup", cfg.backupEndpoint, "err", err)
+		os.Exit(1)
+	}
+	logger.Log("backup", cfg.backupEndpoint, "bucket", cfg.backupBucket, "interval", cfg.backupInterval)
+	return bk
+}
+
+// restoreBackup restores the state from the snapshot name of bk, exiting
+// when it fails or no backup is configured.
+func restoreBackup(bk *backup.Backup, name string, logger log.Logger) {
+	if bk == nil {
+		level.Error(logger).Log("restore", name, "err", "no backup configured, see "+envBackupEndpoint)
+		os.Exit(1)
+	}
+	restored, err := bk.Restore(context.Background(), name)
+	if err != nil {
+		level.Error(logger).Log("restore", name, "err", err)
+		os.Exit(1)
+	}
+	level.Info(logger).Log("restore", restored)
+}
+
+func loadSubscribers(subscribers subscriber.Repository, file string, logger log.Logger) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		level.Error(logger).Log("subscribers", file, "err", err)
+		os.Exit(1)
+	}
+	n, err := subscriber.Load(context.Background(), subscribers, data)
+	if err != nil {
+		level.Error(logger).Log("subscribers", file, "err", err)
+		os.Exit(1)
+	}
+	level.Info(logger).Log("subscribers", file, "loaded", n)
+}
+
+// initFlags returns the feature flags of the file, reloaded as it changes,
+// and the file, or flags all off and no file when none is configured.
+func initFlags(file string, logger log.Logger) (flags.Client, *flags.File) {
+	if file == "" {
+		return flags.Static(nil), nil
+	}
+	f, err := flags.NewFile(file, logger)
+	if err != nil {
+		level.Error(logger).Log("flags", file, "err", err)
+		os.Exit(1)
+	}
+	go f.Run(context.Background(), flags.DefaultInterval)
+	logger.Log("flags", file)
+	return f, f
+}
+
+func NewServer(subscribers subscriber.Repository, hist *history.Log, served plmn.List, fl flags.Client, logger log.Logger) service.UdmService {
+	service := service.New(subscribers, hist, served, fl, logger)
+	return service
+}
+
+// httpRoutes returns the routes of the HTTP API of endpoints, with those of
+// the events of the UEs, pseudonymized with the keys of the pseudonyms, and
+// their exposure, unless nil.
+func httpRoutes(nf *bootstrap.NF, endpoints endpoints.Endpoints, broker *events.Broker, exp *exposure.Exposure) func(*http.ServeMux) {
+	return func(m *http.ServeMux) {
+		m.Handle("/", transports.NewHTTPHandler(endpoints, nf.Tracer, nf.ZipkinTracer, nf.Logger))
+		if broker != nil {
+			var options []events.HandlerOption
+			if nf.Config.PrivacyKeys != "" {
+				options = append(options, events.HandlerPseudonymize(nf.Privacy.Pseudonym))
+			}
+			m.Handle(events.Path, events.Handler(broker, log.With(nf.Logger, loglevel.ComponentKey, "events"), options...))
+		}
+		if exp != nil {
+			m.Handle(exposure.Path, exposure.Handler(exp))
+			m.Handle(exposure.Path+"/", exposure.Handler(exp))
+		}
+	}
+}
+
+// grpcServices returns the registration of both versions of the gRPC API
+// of endpoints.
+func grpcServices(nf *bootstrap.NF, endpoints endpoints.Endpoints) func(*grpc.Server) {
+	return func(s *grpc.Server) {
+		pb.RegisterUdmServer(s, transports.MakeGRPCServer(endpoints, nf.Tracer, nf.ZipkinTracer, nf.Logger))
+		pbv2.RegisterUdmServer(s, transports.MakeGRPCServerV2(endpoints, nf.Tracer, nf.ZipkinTracer, nf.Logger))
+	}
+}
+
+// supis returns the SUPIs of the subscribers.
+func supis(ctx context.Context, subscribers subscriber.Repository) ([]string, error) {
+	var ids []string
+	token := ""
+	for {
+		page, next, err := subscribers.List(ctx, 1000, token)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range page {
+			ids = append(ids, s.SUPI)
+		}
+		if next == "" {
+			return ids, nil
+		}
+		token = next
+	}
+}
+
+// ueEvent returns the event of the history of the UE supi as published to
+// the dashboards and the webhooks.
+func ueEvent(supi string, e history.Event) events.Event {
+	return events.Event{
+		Time:    e.Time,
+		Topic:   events.TopicUE,
+		Type:    string(e.Type),
+		Key:     supi,
+		Source:  e.Source,
+		Outcome: e.Outcome,
+		Data:    e.Detail,
+	}
+}
+
+// The types of the events of the subscribers changed.
+const (
+	subscriberCreated = "subscriber_created"
+	subscriberUpdated = "subscriber_updated"
+	subscriberDeleted = "subscriber_deleted"
+)
+
+// subscriberEvent returns the event of the UE of the change e of a
+// subscriber under prefix, and false when there is none to publish: that of
+// a watch reset, and those of the authentications, which only move the SQN
+// of the subscriber.
+func subscriberEvent(prefix string, e etcd.Event) (events.Event, bool) {
+	ev := events.Event{
+		Time:  time.Now(),
+		Topic: events.TopicUE,
+		Key:   strings.TrimPrefix(e.Key, prefix),
+		Data:  map[string]string{"revision": strconv.FormatInt(e.Revision, 10)},
+	}
+	switch {
+	case e.Type == etcd.Reset:
+		return ev, false
+	case e.Type == etcd.Delete:
+		ev.Type = subscriberDeleted
+	case e.PrevValue == nil:
+		ev.Type = subscriberCreated
+	default:
+		prev, ok := provisioned(e.PrevValue)
+		cur, _ := provisioned(e.Value)
+		if ok && prev == cur {
+			return ev, false
+		}
+		ev.Type = subscriberUpdated
+	}
+	return ev, true
+}
+
+// provisioned returns the provisioned data of the subscriber of the stored
+// record data, the subscriber without its SQN, in JSON.
+func provisioned(data []byte) (string, bool) {
+	data, _, err := subscriber.Schema.Upgrade(data)
+	if err != nil {
+		return "", false
+	}
+	var s subscriber.Subscriber
+	if err := json.Unmarshal(data, &s); err != nil {
+		return "", false
+	}
+	s.SQN = nil
+	b, err := json.Marshal(s)
+	return string(b), err == nil
+}
@@ -0,0 +1,492 @@
+// Command usvcgen scaffolds a new service in the layout of this repository
+// from a YAML description of its methods:
+//
+//	usvcgen [-root .] [-force] service.yaml
+//
+// It writes the service, its endpoints with the middleware stack of
+// pkg/kitx/chain and its gRPC and HTTP transports under pkg/<name>, its main
+// under cmd/<name>, its protobuf description under pb/<name> and its
+// Kubernetes manifest under deployments/k8s, and adds it to the makefile and
+// skaffold.yaml, which build it with the shared Dockerfile. The methods of
+// the service answer Unimplemented until their business logic is written.
+// See deployments/usvcgen/echosvc.yaml for a description.
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Spec is the description of a service.
+type Spec struct {
+	// Name is the name of the service, its package and its binary.
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	// HTTPPort and GRPCPort are the default ports, GRPCPort being HTTPPort+1
+	// when zero.
+	HTTPPort int `yaml:"http_port"`
+	GRPCPort int `yaml:"grpc_port"`
+	// K8sPort is the gRPC port of the service in the cluster, the HTTP one
+	// being the port below and the admin one the port above.
+	K8sPort int          `yaml:"k8s_port"`
+	Methods []MethodSpec `yaml:"methods"`
+}
+
+// MethodSpec is the description of a method of the service.
+type MethodSpec struct {
+	Name string `yaml:"name"`
+	// Summary completes the name in a sentence, e.g. "echoes s back".
+	Summary string `yaml:"summary"`
+	// Idempotent replays the first response to the retries of a request
+	// carrying the same idempotency key.
+	Idempotent bool        `yaml:"idempotent"`
+	Request    []FieldSpec `yaml:"request"`
+	Response   []FieldSpec `yaml:"response"`
+}
+
+// FieldSpec is a parameter of a request or a value of a response. Its name
+// is in snake case, its type one of the keys of types, optionally repeated
+// with a [] prefix.
+type FieldSpec struct {
+	Name string `yaml:"name"`
+	Type string `yaml:"type"`
+}
+
+// types maps the types of the fields to Go and protobuf.
+var types = map[string][2]string{
+	"string":  {"string", "string"},
+	"bool":    {"bool", "bool"},
+	"int32":   {"int32", "int32"},
+	"int64":   {"int64", "int64"},
+	"uint32":  {"uint32", "uint32"},
+	"uint64":  {"uint64", "uint64"},
+	"float64": {"float64", "double"},
+	"bytes":   {"[]byte", "bytes"},
+}
+
+// reserved are the names the generated code uses next to the fields.
+var reserved = map[string]bool{
+	"ctx": true, "err": true, "e": true, "lm": true, "svc": true,
+	"req": true, "request": true, "resp": true, "response": true,
+}
+
+var (
+	nameRE   = regexp.MustCompile(`^[a-z][a-z0-9]*$`)
+	methodRE = regexp.MustCompile(`^[A-Z][A-Za-z0-9]*$`)
+	fieldRE  = regexp.MustCompile(`^[a-z][a-z0-9]*(_[a-z0-9]+)*$`)
+)
+
+// initialisms are written upper case in the Go names of the fields.
+var initialisms = map[string]bool{
+	"api": true, "http": true, "id": true, "ip": true, "json": true,
+	"nci": true, "rrc": true, "ue": true, "url": true,
+}
+
+type service struct {
+	Module       string
+	Name         string // echosvc
+	Type         string // Echosvc
+	PBType       string // the Go name protoc-gen-go gives Type, N3Iwf for N3iwf
+	Env          string // ECHOSVC
+	Description  string
+	Image        string
+	HTTPPort     int
+	GRPCPort     int
+	K8sGRPCPort  int
+	K8sHTTPPort  int
+	K8sAdminPort int
+	Methods      []method
+}
+
+type method struct {
+	Name       string // CreateSession
+	Path       string // createSession
+	Summary    string
+	Idempotent bool
+	Request    []field
+	Response   []field
+	ErrNumber  int
+	Params     string // , supi string, pduSessionID int32
+	Args       string // , supi, pduSessionID
+	Results    string // ip string, err error
+	ResultVars string // ip,
+}
+
+type field struct {
+	Name      string // pdu_session_id
+	GoName    string // PduSessionID
+	PBName    string // PduSessionId
+	Param     string // pduSessionID
+	GoType    string
+	ProtoType string
+	Number    int
+}
+
+// file is a file generated from the template of the same name.
+type file struct {
+	path     string
+	template string
+	mode     os.FileMode
+}
+
+func main() {
+	fs := flag.NewFlagSet("usvcgen", flag.ExitOnError)
+	root := fs.String("root", ".", "root of the repository")
+	force := fs.Bool("force", false, "overwrite the files of an existing service")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: usvcgen [flags] service.yaml\n\nflags:\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(os.Args[1:])
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	if err := run(*root, fs.Arg(0), *force); err != nil {
+		fmt.Fprintf(os.Stderr, "usvcgen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(root, specFile string, force bool) error {
+	data, err := ioutil.ReadFile(specFile)
+	if err != nil {
+		return err
+	}
+	var spec Spec
+	if err := yaml.UnmarshalStrict(data, &spec); err != nil {
+		return fmt.Errorf("%s: %v", specFile, err)
+	}
+	module, err := modulePath(root)
+	if err != nil {
+		return err
+	}
+	svc, err := newService(spec, module)
+	if err != nil {
+		return fmt.Errorf("%s: %v", specFile, err)
+	}
+
+	n := svc.Name
+	files := []file{
+		{filepath.Join("pb", n, n+".proto"), "proto", 0644},
+		{filepath.Join("pb", n, "compile.sh"), "compile", 0755},
+		{filepath.Join("pkg", n, "service", "service.go"), "service", 0644},
+		{filepath.Join("pkg", n, "service", "logging.go"), "logging", 0644},
+		{filepath.Join("pkg", n, "endpoints", "endpoints.go"), "endpoints", 0644},
+		{filepath.Join("pkg", n, "endpoints", "requests.go"), "requests", 0644},
+		{filepath.Join("pkg", n, "endpoints", "responses.go"), "responses", 0644},
+		{filepath.Join("pkg", n, "endpoints", "middleware.go"), "middleware", 0644},
+		{filepath.Join("pkg", n, "transports", "grpc.go"), "grpc", 0644},
+		{filepath.Join("pkg", n, "transports", "http.go"), "http", 0644},
+		{filepath.Join("pkg", n, "transports", "errors.go"), "errors", 0644},
+		{filepath.Join("pkg", n, "transports", "openapi.go"), "openapi", 0644},
+		{filepath.Join("cmd", n, "main.go"), "main", 0644},
+		{filepath.Join("deployments", "k8s", n+".yaml"), "k8s", 0644},
+	}
+	if !force {
+		for _, f := range files {
+			if _, err := os.Stat(filepath.Join(root, f.path)); err == nil {
+				return fmt.Errorf("%s exists, use -force to overwrite it", f.path)
+			}
+		}
+	}
+
+	// every file is rendered before any is written
+	out := make([][]byte, len(files))
+	for i, f := range files {
+		var buf bytes.Buffer
+		if err := templates.ExecuteTemplate(&buf, f.template, svc); err != nil {
+			return err
+		}
+		out[i] = buf.Bytes()
+		if strings.HasSuffix(f.path, ".go") {
+			if out[i], err = format.Source(out[i]); err != nil {
+				return fmt.Errorf("%s: %v", f.path, err)
+			}
+		}
+	}
+	for i, f := range files {
+		path := filepath.Join(root, f.path)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(path, out[i], f.mode); err != nil {
+			return err
+		}
+		fmt.Println(f.path)
+	}
+	if err := addToMakefile(root, n); err != nil {
+		return err
+	}
+	if err := addToSkaffold(root, svc); err != nil {
+		return err
+	}
+
+	fmt.Printf("\nnext:\n\t(cd pb/%s && ./compile.sh)\n\tgo build ./cmd/%s\n\timplement the methods in pkg/%s/service/service.go\n", n, n, n)
+	return nil
+}
+
+// modulePath returns the path of the module at root.
+func modulePath(root string) (string, error) {
+	data, err := ioutil.ReadFile(filepath.Join(root, "go.mod"))
+	if err != nil {
+		return "", err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if f := strings.Fields(line); len(f) == 2 && f[0] == "module" {
+			return f[1], nil
+		}
+	}
+	return "", errors.New("go.mod: no module path")
+}
+
+// newService checks spec and derives the names used by the templates.
+func newService(spec Spec, module string) (service, error) {
+	if !nameRE.MatchString(spec.Name) {
+		return service{}, fmt.Errorf("name %q: want lower case letters and digits", spec.Name)
+	}
+	if spec.HTTPPort <= 0 || spec.K8sPort <= 1 {
+		return service{}, errors.New("http_port and k8s_port are required")
+	}
+	if len(spec.Methods) == 0 {
+		return service{}, errors.New("no methods")
+	}
+	svc := service{
+		Module:       module,
+		Name:         spec.Name,
+		Type:         strings.ToUpper(spec.Name[:1]) + spec.Name[1:],
+		PBType:       pbName(spec.Name),
+		Env:          strings.ToUpper(spec.Name),
+		Description:  strings.TrimSuffix(strings.TrimSpace(spec.Description), "."),
+		Image:        strings.TrimPrefix(module, "github.com/") + "-" + spec.Name,
+		HTTPPort:     spec.HTTPPort,
+		GRPCPort:     spec.GRPCPort,
+		K8sGRPCPort:  spec.K8sPort,
+		K8sHTTPPort:  spec.K8sPort - 1,
+		K8sAdminPort: spec.K8sPort + 1,
+	}
+	if svc.GRPCPort == 0 {
+		svc.GRPCPort = svc.HTTPPort + 1
+	}
+	if svc.Description == "" {
+		svc.Description = "the " + svc.Name + " service"
+	}
+	seen := make(map[string]bool)
+	for _, ms := range spec.Methods {
+		if !methodRE.MatchString(ms.Name) {
+			return service{}, fmt.Errorf("method %q: want an exported Go name", ms.Name)
+		}
+		if seen[ms.Name] {
+			return service{}, fmt.Errorf("method %s: defined twice", ms.Name)
+		}
+		seen[ms.Name] = true
+		if strings.TrimSpace(ms.Summary) == "" {
+			return service{}, fmt.Errorf("method %s: no summary", ms.Name)
+		}
+		m := method{
+			Name:       ms.Name,
+			Path:       lowerCamel(ms.Name),
+			Summary:    strings.TrimSuffix(strings.TrimSpace(ms.Summary), "."),
+			Idempotent: ms.Idempotent,
+		}
+		if isKeyword(m.Path) {
+			return service{}, fmt.Errorf("method %s: %s is a Go keyword", ms.Name, m.Path)
+		}
+		var err error
+		if m.Request, err = fields(ms.Name, ms.Request); err != nil {
+			return service{}, err
+		}
+		if m.Response, err = fields(ms.Name, ms.Response); err != nil {
+			return service{}, err
+		}
+		m.ErrNumber = len(m.Response) + 1
+		for _, f := range m.Request {
+			m.Params += ", " + f.Param + " " + f.GoType
+			m.Args += ", " + f.Param
+		}
+		for _, f := range m.Response {
+			m.Results += f.Param + " " + f.GoType + ", "
+			m.ResultVars += f.Param + ", "
+		}
+		m.Results += "err error"
+		svc.Methods = append(svc.Methods, m)
+	}
+	return svc, nil
+}
+
+func fields(method string, specs []FieldSpec) ([]field, error) {
+	var fs []field
+	seen := make(map[string]bool)
+	for i, fsp := range specs {
+		if !fieldRE.MatchString(fsp.Name) {
+			return nil, fmt.Errorf("method %s: field %q: want snake case", method, fsp.Name)
+		}
+		if seen[fsp.Name] {
+			return nil, fmt.Errorf("method %s: field %s: defined twice", method, fsp.Name)
+		}
+		seen[fsp.Name] = true
+		t := strings.TrimPrefix(fsp.Type, "[]")
+		repeated := t != fsp.Type
+		ts, ok := types[t]
+		if !ok || (repeated && t == "bytes") {
+			return nil, fmt.Errorf("method %s: field %s: unknown type %q", method, fsp.Name, fsp.Type)
+		}
+		f := field{
+			Name:      fsp.Name,
+			GoName:    goName(fsp.Name),
+			PBName:    pbName(fsp.Name),
+			GoType:    ts[0],
+			ProtoType: ts[1],
+			Number:    i + 1,
+		}
+		f.Param = paramName(fsp.Name)
+		if reserved[f.Param] || isKeyword(f.Param) {
+			return nil, fmt.Errorf("method %s: field %s: %s is reserved", method, fsp.Name, f.Param)
+		}
+		if repeated {
+			f.GoType = "[]" + f.GoType
+			f.ProtoType = "repeated " + f.ProtoType
+		}
+		fs = append(fs, f)
+	}
+	return fs, nil
+}
+
+// goName returns the Go name of the snake case name s.
+func goName(s string) string {
+	var b strings.Builder
+	for _, p := range strings.Split(s, "_") {
+		if initialisms[p] {
+			b.WriteString(strings.ToUpper(p))
+		} else {
+			b.WriteString(strings.ToUpper(p[:1]) + p[1:])
+		}
+	}
+	return b.String()
+}
+
+// paramName returns the Go name of the parameter of the snake case name s:
+// "ue_id" is "ueID".
+func paramName(s string) string {
+	if i := strings.Index(s, "_"); i > 0 {
+		return s[:i] + goName(s[i+1:])
+	}
+	return s
+}
+
+// pbName returns the name protoc-gen-go gives the field or service s: the
+// letter after an underscore or a digit is upper case, the underscore
+// dropped.
+func pbName(s string) string {
+	var b []byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '_' && i+1 < len(s) && isLower(s[i+1]) {
+			continue
+		}
+		if c >= '0' && c <= '9' {
+			b = append(b, c)
+			continue
+		}
+		if isLower(c) {
+			c -= 'a' - 'A'
+		}
+		b = append(b, c)
+		for i+1 < len(s) && isLower(s[i+1]) {
+			i++
+			b = append(b, s[i])
+		}
+	}
+	return string(b)
+}
+
+func isLower(c byte) bool {
+	return c >= 'a' && c <= 'z'
+}
+
+// lowerCamel lowers the leading upper case letters of s, but the one starting
+// the next word: "UEContext" is "ueContext".
+func lowerCamel(s string) string {
+	b := []byte(s)
+	for i := range b {
+		if b[i] < 'A' || b[i] > 'Z' {
+			break
+		}
+		if i > 0 && i+1 < len(b) && isLower(b[i+1]) {
+			break
+		}
+		b[i] += 'a' - 'A'
+	}
+	return string(b)
+}
+
+func isKeyword(s string) bool {
+	switch s {
+	case "break", "case", "chan", "const", "continue", "default", "defer",
+		"else", "fallthrough", "for", "func", "go", "goto", "if", "import",
+		"interface", "map", "package", "range", "return", "select", "struct",
+		"switch", "type", "var":
+		return true
+	}
+	return false
+}
+
+// addToMakefile adds the service to the SERVICES of the makefile.
+func addToMakefile(root, name string) error {
+	path := filepath.Join(root, "makefile")
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	lines := strings.Split(string(data), "\n")
+	for i, line := range lines {
+		if !strings.HasPrefix(line, "SERVICES =") {
+			continue
+		}
+		for _, s := range strings.Fields(line)[2:] {
+			if s == name {
+				return nil
+			}
+		}
+		lines[i] = line + " " + name
+		fmt.Println("makefile")
+		return ioutil.WriteFile(path, []byte(strings.Join(lines, "\n")), 0644)
+	}
+	return errors.New("makefile: no SERVICES")
+}
+
+// addToSkaffold adds the image of the service to the artifacts of
+// skaffold.yaml, before the router's.
+func addToSkaffold(root string, svc service) error {
+	path := filepath.Join(root, "skaffold.yaml")
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if bytes.Contains(data, []byte("image: "+svc.Image+"\n")) {
+		return nil
+	}
+	router := []byte("    - image: " + strings.TrimSuffix(svc.Image, svc.Name) + "router\n")
+	i := bytes.Index(data, router)
+	if i < 0 {
+		return errors.New("skaffold.yaml: no router artifact to add the service before")
+	}
+	var buf bytes.Buffer
+	if err := templates.ExecuteTemplate(&buf, "skaffold", svc); err != nil {
+		return err
+	}
+	out := append(append(append([]byte(nil), data[:i]...), buf.Bytes()...), data[i:]...)
+	fmt.Println("skaffold.yaml")
+	return ioutil.WriteFile(path, out, 0644)
+}
@@ -0,0 +1,959 @@
+package main
+
+import (
+	"text/template"
+)
+
+// templates are those of the files of a service, from foosvc and
+// preamblesvc. Raw strings cannot hold the back quotes of the struct tags,
+// written by tag.
+var templates = template.New("usvcgen").Funcs(template.FuncMap{
+	"tag": func(name string) string { return "`json:\"" + name + "\"`" },
+})
+
+func init() {
+	for name, text := range map[string]string{
+		"proto":      protoTemplate,
+		"compile":    compileTemplate,
+		"service":    serviceTemplate,
+		"logging":    loggingTemplate,
+		"endpoints":  endpointsTemplate,
+		"requests":   requestsTemplate,
+		"responses":  responsesTemplate,
+		"middleware": middlewareTemplate,
+		"grpc":       grpcTemplate,
+		"http":       httpTemplate,
+		"errors":     errorsTemplate,
+		"openapi":    openapiTemplate,
+		"main":       mainTemplate,
+		"k8s":        k8sTemplate,
+		"skaffold":   skaffoldTemplate,
+	} {
+		template.Must(templates.New(name).Parse(text))
+	}
+}
+
+const protoTemplate = `syntax = "proto3";
+
+package pb;
+
+// The {{.Type}} service definition.
+service {{.Type}} {
+{{- range .Methods}}
+
+    // {{.Name}} {{.Summary}}.
+    rpc {{.Name}} ({{.Name}}Request) returns ({{.Name}}Reply) {
+    }
+{{- end}}
+}
+{{- range .Methods}}
+
+message {{.Name}}Request {
+{{- range .Request}}
+    {{.ProtoType}} {{.Name}} = {{.Number}};
+{{- end}}
+}
+
+message {{.Name}}Reply {
+{{- range .Response}}
+    {{.ProtoType}} {{.Name}} = {{.Number}};
+{{- end}}
+    string err = {{.ErrNumber}};
+}
+{{- end}}
+`
+
+const compileTemplate = `#!/usr/bin/env sh
+
+# Install proto3 from source macOS only.
+#  brew install autoconf automake libtool
+#  git clone https://github.com/google/protobuf
+#  ./autogen.sh ; ./configure ; make ; make install
+#
+# Update protoc Go bindings via
+#  go get -u github.com/golang/protobuf/{proto,protoc-gen-go}
+#
+# See also
+#  https://github.com/grpc/grpc-go/tree/master/examples
+
+protoc {{.Name}}.proto --go_out=plugins=grpc:.
+`
+
+const serviceTemplate = `package service
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Middleware describes a service (as opposed to endpoint) middleware.
+type Middleware func({{.Type}}Service) {{.Type}}Service
+
+// {{.Type}}Service describes {{.Description}}.
+type {{.Type}}Service interface {
+{{- range .Methods}}
+	// {{.Name}} {{.Summary}}.
+	{{.Name}}(ctx context.Context{{.Params}}) ({{.Results}})
+{{- end}}
+}
+
+// the concrete implementation of service interface
+type stub{{.Type}}Service struct {
+	logger log.Logger
+}
+
+// New return a new instance of the service.
+// If you want to add service middleware this is the place to put them.
+func New(logger log.Logger) (s {{.Type}}Service) {
+	var svc {{.Type}}Service
+	{
+		svc = &stub{{.Type}}Service{logger: logger}
+		svc = LoggingMiddleware(logger)(svc)
+	}
+	return svc
+}
+{{- range .Methods}}
+
+// Implement the business logic of {{.Name}}
+func (svc *stub{{$.Type}}Service) {{.Name}}(ctx context.Context{{.Params}}) ({{.Results}}) {
+	return {{.ResultVars}}status.Error(codes.Unimplemented, "{{.Path}}: not implemented")
+}
+{{- end}}
+`
+
+const loggingTemplate = `package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+
+	"{{.Module}}/pkg/logging"
+)
+
+type loggingMiddleware struct {
+	logger log.Logger {{tag ""}}
+	next   {{.Type}}Service {{tag ""}}
+}
+
+// LoggingMiddleware takes a logger as a dependency
+// and returns a ServiceMiddleware.
+func LoggingMiddleware(logger log.Logger) Middleware {
+	return func(next {{.Type}}Service) {{.Type}}Service {
+		return loggingMiddleware{level.Info(logger), next}
+	}
+}
+{{- range .Methods}}
+
+func (lm loggingMiddleware) {{.Name}}(ctx context.Context{{.Params}}) ({{.Results}}) {
+	defer func(begin time.Time) {
+		log.With(lm.logger, logging.ContextKeyvals(ctx)...).Log("method", "{{.Name}}"{{range .Request}}, "{{.Name}}", {{.Param}}{{end}}, "err", err)
+	}(time.Now())
+
+	return lm.next.{{.Name}}(ctx{{.Args}})
+}
+{{- end}}
+`
+
+const endpointsTemplate = `package endpoints
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+
+	"{{.Module}}/pkg/kitx/chain"
+	"{{.Module}}/pkg/{{.Name}}/service"
+)
+
+// Endpoints collects all of the endpoints that compose the {{.Name}} service. It's
+// meant to be used as a helper struct, to collect all of the endpoints into a
+// single parameter.
+type Endpoints struct {
+{{- range .Methods}}
+	{{.Name}}Endpoint endpoint.Endpoint {{tag ""}}
+{{- end}}
+}
+
+// New return a new instance of the endpoint that wraps the provided service.
+// The endpoints run in the middleware stack of mw.
+func New(svc service.{{.Type}}Service, mw chain.MiddlewareConfig) (ep Endpoints) {
+	mw.Logging = LoggingMiddleware
+	mw.Instrumenting = InstrumentingMiddleware
+	c := chain.New(mw)
+{{- range .Methods}}
+	ep.{{.Name}}Endpoint = c.Build("{{.Path}}", Make{{.Name}}Endpoint(svc){{if .Idempotent}}, chain.Idempotent({{.Name}}Response{}){{end}})
+{{- end}}
+	return ep
+}
+{{- range .Methods}}
+
+// Make{{.Name}}Endpoint returns an endpoint that invokes {{.Name}} on the service.
+// Primarily useful in a server.
+func Make{{.Name}}Endpoint(svc service.{{$.Type}}Service) (ep endpoint.Endpoint) {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.({{.Name}}Request)
+		if err := req.validate(); err != nil {
+			return {{.Name}}Response{}, err
+		}
+		{{.ResultVars}}err := svc.{{.Name}}(ctx{{range .Request}}, req.{{.GoName}}{{end}})
+		return {{.Name}}Response{ {{- range $i, $f := .Response}}{{if $i}}, {{end}}{{.GoName}}: {{.Param}}{{end -}} }, err
+	}
+}
+
+// {{.Name}} implements the service interface, so Endpoints may be used as a service.
+// This is primarily useful in the context of a client library.
+func (e Endpoints) {{.Name}}(ctx context.Context{{.Params}}) ({{.Results}}) {
+{{- if .Response}}
+	resp, err := e.{{.Name}}Endpoint(ctx, {{.Name}}Request{ {{- range $i, $f := .Request}}{{if $i}}, {{end}}{{.GoName}}: {{.Param}}{{end -}} })
+	if err != nil {
+		return
+	}
+	response := resp.({{.Name}}Response)
+	return {{range .Response}}response.{{.GoName}}, {{end}}nil
+{{- else}}
+	_, err = e.{{.Name}}Endpoint(ctx, {{.Name}}Request{ {{- range $i, $f := .Request}}{{if $i}}, {{end}}{{.GoName}}: {{.Param}}{{end -}} })
+	return err
+{{- end}}
+}
+{{- end}}
+`
+
+const requestsTemplate = `package endpoints
+
+type Request interface {
+	validate() error
+}
+{{- range .Methods}}
+
+// {{.Name}}Request collects the request parameters for the {{.Name}} method.
+type {{.Name}}Request struct {
+{{- range .Request}}
+	{{.GoName}} {{.GoType}} {{tag .Name}}
+{{- end}}
+}
+
+func (r {{.Name}}Request) validate() error {
+	return nil // TBA
+}
+{{- end}}
+`
+
+const responsesTemplate = `package endpoints
+
+import (
+	"net/http"
+
+	httptransport "github.com/go-kit/kit/transport/http"
+)
+
+var (
+{{- range .Methods}}
+	_ httptransport.Headerer = (*{{.Name}}Response)(nil)
+
+	_ httptransport.StatusCoder = (*{{.Name}}Response)(nil)
+{{- end}}
+)
+{{- range .Methods}}
+
+// {{.Name}}Response collects the response values for the {{.Name}} method.
+type {{.Name}}Response struct {
+{{- range .Response}}
+	{{.GoName}} {{.GoType}} {{tag .Name}}
+{{- end}}
+	Err error {{tag "err"}}
+}
+
+func (r {{.Name}}Response) StatusCode() int {
+	return http.StatusOK // TBA
+}
+
+func (r {{.Name}}Response) Headers() http.Header {
+	return http.Header{}
+}
+{{- end}}
+`
+
+const middlewareTemplate = `package endpoints
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/go-kit/kit/metrics"
+
+	"{{.Module}}/pkg/clock"
+	"{{.Module}}/pkg/exemplar"
+	"{{.Module}}/pkg/logging"
+)
+
+// InstrumentingMiddleware returns an endpoint middleware that records
+// the duration of each invocation to the passed histogram. The middleware adds
+// a single field: "success", which is "true" if no error is returned, and
+// "false" otherwise. The trace of the call is the exemplar of its duration
+// when the histogram takes exemplars.
+func InstrumentingMiddleware(duration metrics.Histogram) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+			defer func(begin time.Time) {
+				exemplar.Observe(ctx, duration.With("success", fmt.Sprint(err == nil)), time.Since(begin).Seconds())
+			}(time.Now())
+			return next(ctx, request)
+		}
+	}
+}
+
+// LoggingMiddleware returns an endpoint middleware that logs the
+// duration of each invocation, timed by clk, and the resulting error, if any. Records carry
+// the trace and span IDs of the call when it is traced, and the UE of the
+// request, which is passed on in the context.
+func LoggingMiddleware(logger log.Logger, clk clock.Clock) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+			if r, ok := request.(logging.UERequest); ok {
+				ctx = logging.NewUEContext(ctx, r.UEID())
+			}
+			defer func(begin time.Time) {
+				logger := log.With(logger, logging.ContextKeyvals(ctx)...)
+				if err == nil {
+					level.Info(logger).Log("transport_error", err, "took", clk.Since(begin))
+				} else {
+					level.Error(logger).Log("transport_error", err, "took", clk.Since(begin))
+				}
+			}(clk.Now())
+			return next(ctx, request)
+		}
+	}
+}
+`
+
+const grpcTemplate = `package transports
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/kit/circuitbreaker"
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/ratelimit"
+	"github.com/go-kit/kit/tracing/opentracing"
+	"github.com/go-kit/kit/tracing/zipkin"
+	grpctransport "github.com/go-kit/kit/transport/grpc"
+	stdopentracing "github.com/opentracing/opentracing-go"
+	stdzipkin "github.com/openzipkin/zipkin-go"
+	"github.com/sony/gobreaker"
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "{{.Module}}/pb/{{.Name}}"
+	"{{.Module}}/pkg/breakers"
+	"{{.Module}}/pkg/caller"
+	"{{.Module}}/pkg/grpcpool"
+	"{{.Module}}/pkg/idempotency"
+	"{{.Module}}/pkg/logging"
+	"{{.Module}}/pkg/meta"
+	"{{.Module}}/pkg/{{.Name}}/endpoints"
+	"{{.Module}}/pkg/{{.Name}}/service"
+)
+
+type grpcServer struct {
+{{- range .Methods}}
+	{{.Path}} grpctransport.Handler {{tag ""}}
+{{- end}}
+}
+{{- range .Methods}}
+
+func (s *grpcServer) {{.Name}}(ctx context.Context, req *pb.{{.Name}}Request) (rep *pb.{{.Name}}Reply, err error) {
+	_, rp, err := s.{{.Path}}.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcEncodeError(err)
+	}
+	rep = rp.(*pb.{{.Name}}Reply)
+	return rep, nil
+}
+{{- end}}
+
+// MakeGRPCServer makes a set of endpoints available as a gRPC server.
+func MakeGRPCServer(endpoints endpoints.Endpoints, otTracer stdopentracing.Tracer, zipkinTracer *stdzipkin.Tracer, logger log.Logger) (req pb.{{.PBType}}Server) {
+	// A global Zipkin tracing service is fed to each Go kit gRPC server, the
+	// operation name being the gRPC method path.
+	zipkinServer := zipkin.GRPCServerTrace(zipkinTracer)
+
+	options := []grpctransport.ServerOption{
+		grpctransport.ServerErrorHandler(logging.ErrorHandler(logger)),
+		grpctransport.ServerBefore(idempotency.GRPCToContext()),
+		grpctransport.ServerBefore(caller.GRPCToContext()),
+		grpctransport.ServerBefore(meta.GRPCToContext()),
+		zipkinServer,
+	}
+
+	return &grpcServer{
+{{- range .Methods}}
+		{{.Path}}: grpctransport.NewServer(
+			endpoints.{{.Name}}Endpoint,
+			decodeGRPC{{.Name}}Request,
+			encodeGRPC{{.Name}}Response,
+			append(options, grpctransport.ServerBefore(opentracing.GRPCToContext(otTracer, "{{.Name}}", logger)))...,
+		),
+{{- end}}
+	}
+}
+{{- range .Methods}}
+
+// decodeGRPC{{.Name}}Request is a transport/grpc.DecodeRequestFunc that converts a
+// gRPC request to a user-domain request. Primarily useful in a server.
+func decodeGRPC{{.Name}}Request(_ context.Context, grpcReq interface{}) (interface{}, error) {
+{{- if .Request}}
+	req := grpcReq.(*pb.{{.Name}}Request)
+	return endpoints.{{.Name}}Request{ {{- range $i, $f := .Request}}{{if $i}}, {{end}}{{.GoName}}: req.{{.PBName}}{{end -}} }, nil
+{{- else}}
+	return endpoints.{{.Name}}Request{}, nil
+{{- end}}
+}
+
+// encodeGRPC{{.Name}}Response is a transport/grpc.EncodeResponseFunc that converts a
+// user-domain response to a gRPC reply. Primarily useful in a server.
+func encodeGRPC{{.Name}}Response(_ context.Context, grpcReply interface{}) (res interface{}, err error) {
+	reply := grpcReply.(endpoints.{{.Name}}Response)
+	return &pb.{{.Name}}Reply{ {{- range $i, $f := .Response}}{{if $i}}, {{end}}{{.PBName}}: reply.{{.GoName}}{{end -}} }, grpcEncodeError(reply.Err)
+}
+{{- end}}
+
+// NewGRPCClient returns a {{.Type}}Service backed by a gRPC server at the other end
+// of the conn. The caller is responsible for constructing the conn, and
+// eventually closing the underlying transport. We bake-in certain middlewares,
+// implementing the client library pattern.
+func NewGRPCClient(conn *grpc.ClientConn, otTracer stdopentracing.Tracer, zipkinTracer *stdzipkin.Tracer, logger log.Logger) service.{{.Type}}Service {
+	return NewGRPCPoolClient(grpcpool.FromConn(conn), otTracer, zipkinTracer, logger)
+}
+
+// NewGRPCPoolClient is like NewGRPCClient, but spreads the calls over the
+// connections of the pool. The caller is responsible for closing the pool.
+func NewGRPCPoolClient(pool *grpcpool.Pool, otTracer stdopentracing.Tracer, zipkinTracer *stdzipkin.Tracer, logger log.Logger) service.{{.Type}}Service {
+	// We construct a single ratelimiter middleware, to limit the total outgoing
+	// QPS from this client to all methods on the remote instance, and
+	// per-endpoint circuitbreaker middlewares.
+	limiter := ratelimit.NewErroringLimiter(rate.NewLimiter(rate.Every(time.Second), 100))
+
+	// A global Zipkin tracing client is fed to each Go kit client, the
+	// operation name being the gRPC method path.
+	zipkinClient := zipkin.GRPCClientTrace(zipkinTracer)
+
+	// global client middlewares
+	options := []grpctransport.ClientOption{
+		grpctransport.ClientBefore(idempotency.ContextToGRPC()),
+		grpctransport.ClientBefore(meta.ContextToGRPC()),
+		zipkinClient,
+	}
+{{- range .Methods}}
+
+	var {{.Path}}Endpoint endpoint.Endpoint
+	{
+		{{.Path}}Endpoint = pool.Endpoint(func(conn *grpc.ClientConn) endpoint.Endpoint {
+			return grpctransport.NewClient(
+				conn,
+				"pb.{{$.Type}}",
+				"{{.Name}}",
+				encodeGRPC{{.Name}}Request,
+				decodeGRPC{{.Name}}Response,
+				pb.{{.Name}}Reply{},
+				append(options, grpctransport.ClientBefore(opentracing.ContextToGRPC(otTracer, logger)))...,
+			).Endpoint()
+		})
+		{{.Path}}Endpoint = opentracing.TraceClient(otTracer, "{{.Name}}")({{.Path}}Endpoint)
+		{{.Path}}Endpoint = limiter({{.Path}}Endpoint)
+		{{.Path}}Endpoint = circuitbreaker.Gobreaker(breakers.New(gobreaker.Settings{
+			Name:    "{{.Name}}",
+			Timeout: 30 * time.Second,
+		}))({{.Path}}Endpoint)
+	}
+{{- end}}
+
+	return endpoints.Endpoints{
+{{- range .Methods}}
+		{{.Name}}Endpoint: {{.Path}}Endpoint,
+{{- end}}
+	}
+}
+{{- range .Methods}}
+
+// encodeGRPC{{.Name}}Request is a transport/grpc.EncodeRequestFunc that converts a
+// user-domain {{.Name}} request to a gRPC {{.Name}} request. Primarily useful in a client.
+func encodeGRPC{{.Name}}Request(_ context.Context, request interface{}) (interface{}, error) {
+{{- if .Request}}
+	req := request.(endpoints.{{.Name}}Request)
+	return &pb.{{.Name}}Request{ {{- range $i, $f := .Request}}{{if $i}}, {{end}}{{.PBName}}: req.{{.GoName}}{{end -}} }, nil
+{{- else}}
+	return &pb.{{.Name}}Request{}, nil
+{{- end}}
+}
+
+// decodeGRPC{{.Name}}Response is a transport/grpc.DecodeResponseFunc that converts a
+// gRPC {{.Name}} reply to a user-domain {{.Name}} response. Primarily useful in a client.
+func decodeGRPC{{.Name}}Response(_ context.Context, grpcReply interface{}) (interface{}, error) {
+{{- if .Response}}
+	reply := grpcReply.(*pb.{{.Name}}Reply)
+	return endpoints.{{.Name}}Response{ {{- range $i, $f := .Response}}{{if $i}}, {{end}}{{.GoName}}: reply.{{.PBName}}{{end -}} }, nil
+{{- else}}
+	return endpoints.{{.Name}}Response{}, nil
+{{- end}}
+}
+{{- end}}
+
+func grpcEncodeError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	st, ok := status.FromError(err)
+	if ok {
+		return status.Error(st.Code(), st.Message())
+	}
+	switch err {
+	default:
+		return status.Error(codes.Internal, "internal server error")
+	}
+}
+`
+
+const httpTemplate = `package transports
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-kit/kit/circuitbreaker"
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/ratelimit"
+	"github.com/go-kit/kit/sd/lb"
+	"github.com/go-kit/kit/tracing/opentracing"
+	"github.com/go-kit/kit/tracing/zipkin"
+	httptransport "github.com/go-kit/kit/transport/http"
+	"github.com/golang/protobuf/proto"
+	stdopentracing "github.com/opentracing/opentracing-go"
+	stdzipkin "github.com/openzipkin/zipkin-go"
+	"github.com/sony/gobreaker"
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc/status"
+
+	pb "{{.Module}}/pb/{{.Name}}"
+	"{{.Module}}/pkg/breakers"
+	"{{.Module}}/pkg/caller"
+	"{{.Module}}/pkg/codec"
+	"{{.Module}}/pkg/idempotency"
+	"{{.Module}}/pkg/logging"
+	"{{.Module}}/pkg/meta"
+	"{{.Module}}/pkg/openapi"
+	"{{.Module}}/pkg/{{.Name}}/endpoints"
+	"{{.Module}}/pkg/{{.Name}}/service"
+)
+
+type errorWrapper struct {
+	Error string {{tag "error"}}
+}
+
+func JSONErrorDecoder(r *http.Response) error {
+	contentType := r.Header.Get("Content-Type")
+	if !strings.Contains(contentType, "application/json") {
+		return fmt.Errorf("expected JSON formatted error, got Content-Type %s", contentType)
+	}
+	var w errorWrapper
+	if err := json.NewDecoder(r.Body).Decode(&w); err != nil {
+		return err
+	}
+	return errors.New(w.Error)
+}
+
+// NewHTTPHandler returns a handler that makes a set of endpoints available on
+// predefined paths.
+func NewHTTPHandler(endpoints endpoints.Endpoints, otTracer stdopentracing.Tracer, zipkinTracer *stdzipkin.Tracer, logger log.Logger) http.Handler {
+	// A global Zipkin tracing service is fed to each Go kit endpoint, the
+	// operation name being the HTTP method.
+	zipkinServer := zipkin.HTTPServerTrace(zipkinTracer)
+
+	options := []httptransport.ServerOption{
+		httptransport.ServerErrorEncoder(httpEncodeError),
+		httptransport.ServerErrorHandler(logging.ErrorHandler(logger)),
+		httptransport.ServerBefore(idempotency.HTTPToContext()),
+		httptransport.ServerBefore(caller.HTTPToContext()),
+		httptransport.ServerBefore(meta.HTTPToContext()),
+		httptransport.ServerBefore(codec.Default.HTTPToContext()),
+		zipkinServer,
+	}
+
+	m := http.NewServeMux()
+{{- range .Methods}}
+	m.Handle("/{{.Path}}", httptransport.NewServer(
+		endpoints.{{.Name}}Endpoint,
+		codec.DecodeRequest({{.Path}}Binding),
+		codec.EncodeResponse({{.Path}}Binding),
+		append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "{{.Name}}", logger)))...,
+	))
+{{- end}}
+	m.Handle(openapi.Path, openapi.Handler(OpenAPI()))
+	return m
+}
+{{- range .Methods}}
+
+var {{.Path}}Binding = codec.Binding{
+	Request:   func() interface{} { return &endpoints.{{.Name}}Request{} },
+	PBRequest: func() proto.Message { return &pb.{{.Name}}Request{} },
+	FromPB:    decodeGRPC{{.Name}}Request,
+	ToPB:      encodeGRPC{{.Name}}Response,
+}
+{{- end}}
+
+// NewHTTPClient returns a {{.Type}}Service backed by an HTTP server living at the
+// remote instance. We expect instance to come from a service discovery system,
+// so likely of the form "host:port". We bake-in certain middlewares,
+// implementing the client library pattern.
+func NewHTTPClient(instance string, otTracer stdopentracing.Tracer, zipkinTracer *stdzipkin.Tracer, logger log.Logger) (service.{{.Type}}Service, error) {
+	// Quickly sanitize the instance string.
+	if !strings.HasPrefix(instance, "http") {
+		instance = "http://" + instance
+	}
+	u, err := url.Parse(instance)
+	if err != nil {
+		return nil, err
+	}
+
+	// We construct a single ratelimiter middleware, to limit the total outgoing
+	// QPS from this client to all methods on the remote instance, and
+	// per-endpoint circuitbreaker middlewares.
+	limiter := ratelimit.NewErroringLimiter(rate.NewLimiter(rate.Every(time.Second), 100))
+
+	// A global Zipkin tracing client is fed to each Go kit endpoint, the
+	// operation name being the HTTP method.
+	zipkinClient := zipkin.HTTPClientTrace(zipkinTracer)
+
+	// global client middlewares
+	options := []httptransport.ClientOption{
+		httptransport.ClientBefore(idempotency.ContextToHTTP()),
+		httptransport.ClientBefore(meta.ContextToHTTP()),
+		zipkinClient,
+	}
+
+	e := endpoints.Endpoints{}
+
+	// Each individual endpoint is an http/transport.Client (which implements
+	// endpoint.Endpoint) that gets wrapped with various middlewares.
+{{- range .Methods}}
+	var {{.Path}}Endpoint endpoint.Endpoint
+	{
+		{{.Path}}Endpoint = httptransport.NewClient(
+			"POST",
+			copyURL(u, "/{{.Path}}"),
+			encodeHTTP{{.Name}}Request,
+			decodeHTTP{{.Name}}Response,
+			append(options, httptransport.ClientBefore(opentracing.ContextToHTTP(otTracer, logger)))...,
+		).Endpoint()
+		{{.Path}}Endpoint = opentracing.TraceClient(otTracer, "{{.Name}}")({{.Path}}Endpoint)
+		{{.Path}}Endpoint = zipkin.TraceEndpoint(zipkinTracer, "{{.Name}}")({{.Path}}Endpoint)
+		{{.Path}}Endpoint = limiter({{.Path}}Endpoint)
+		{{.Path}}Endpoint = circuitbreaker.Gobreaker(breakers.New(gobreaker.Settings{
+			Name:    "{{.Name}}",
+			Timeout: 30 * time.Second,
+		}))({{.Path}}Endpoint)
+		e.{{.Name}}Endpoint = {{.Path}}Endpoint
+	}
+{{- end}}
+
+	// Returning the endpoint.Set as a service.Service relies on the
+	// endpoint.Set implementing the Service methods. That's just a simple bit
+	// of glue code.
+	return e, nil
+}
+
+func copyURL(base *url.URL, path string) *url.URL {
+	next := *base
+	next.Path = path
+	return &next
+}
+{{- range .Methods}}
+
+// encodeHTTP{{.Name}}Request is a transport/http.EncodeRequestFunc that
+// JSON-encodes any request to the request body. Primarily useful in a client.
+func encodeHTTP{{.Name}}Request(_ context.Context, r *http.Request, request interface{}) (err error) {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(request); err != nil {
+		return err
+	}
+	r.Body = ioutil.NopCloser(&buf)
+	return nil
+}
+
+// decodeHTTP{{.Name}}Response is a transport/http.DecodeResponseFunc that decodes a
+// JSON-encoded {{.Name}} response from the HTTP response body. If the response has a
+// non-200 status code, we will interpret that as an error and attempt to decode
+// the specific error message from the response body. Primarily useful in a client.
+func decodeHTTP{{.Name}}Response(_ context.Context, r *http.Response) (interface{}, error) {
+	if r.StatusCode != http.StatusOK {
+		return nil, JSONErrorDecoder(r)
+	}
+	var resp endpoints.{{.Name}}Response
+	err := json.NewDecoder(r.Body).Decode(&resp)
+	return resp, err
+}
+{{- end}}
+
+func httpEncodeError(_ context.Context, err error, w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if lberr, ok := err.(lb.RetryError); ok {
+		st, _ := status.FromError(lberr.Final)
+		w.WriteHeader(HTTPStatusFromCode(st.Code()))
+		json.NewEncoder(w).Encode(errorWrapper{Error: st.Message()})
+	} else {
+		st, ok := status.FromError(err)
+		if ok {
+			w.WriteHeader(HTTPStatusFromCode(st.Code()))
+			json.NewEncoder(w).Encode(errorWrapper{Error: st.Message()})
+		} else {
+			switch err {
+			case io.ErrUnexpectedEOF:
+				w.WriteHeader(http.StatusBadRequest)
+			case io.EOF:
+				w.WriteHeader(http.StatusBadRequest)
+			default:
+				switch err.(type) {
+				case *json.SyntaxError:
+					w.WriteHeader(http.StatusBadRequest)
+				case *json.UnmarshalTypeError:
+					w.WriteHeader(http.StatusBadRequest)
+				default:
+					w.WriteHeader(http.StatusInternalServerError)
+				}
+			}
+			json.NewEncoder(w).Encode(errorWrapper{Error: err.Error()})
+		}
+	}
+}
+`
+
+const errorsTemplate = `package transports
+
+import (
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+)
+
+// HTTPStatusFromCode converts a gRPC error code into the corresponding HTTP response status.
+// See: https://github.com/googleapis/googleapis/blob/master/google/rpc/code.proto
+func HTTPStatusFromCode(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		return http.StatusRequestTimeout
+	case codes.Unknown:
+		return http.StatusInternalServerError
+	case codes.InvalidArgument:
+		return http.StatusBadRequest
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.FailedPrecondition:
+		return http.StatusPreconditionFailed
+	case codes.Aborted:
+		return http.StatusConflict
+	case codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Internal:
+		return http.StatusInternalServerError
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	case codes.DataLoss:
+		return http.StatusInternalServerError
+	}
+
+	return http.StatusInternalServerError
+}
+`
+
+const openapiTemplate = `package transports
+
+import (
+	"{{.Module}}/pkg/openapi"
+	"{{.Module}}/pkg/{{.Name}}/endpoints"
+)
+
+// OpenAPI returns the description of the HTTP transport, served by
+// NewHTTPHandler on openapi.Path.
+func OpenAPI() *openapi.Document {
+	return openapi.New("{{.Name}}", "v1",
+{{- range .Methods}}
+		openapi.Operation{
+			Path:     "/{{.Path}}",
+			ID:       "{{.Name}}",
+			Summary:  "{{.Name}} {{.Summary}}.",
+			Request:  endpoints.{{.Name}}Request{},
+			Response: endpoints.{{.Name}}Response{},
+		},
+{{- end}}
+	)
+}
+`
+
+const mainTemplate = `package main
+
+import (
+	"net/http"
+
+	"github.com/go-kit/kit/log"
+	"google.golang.org/grpc"
+
+	pb "{{.Module}}/pb/{{.Name}}"
+	"{{.Module}}/pkg/bootstrap"
+	"{{.Module}}/pkg/{{.Name}}/endpoints"
+	"{{.Module}}/pkg/{{.Name}}/service"
+	"{{.Module}}/pkg/{{.Name}}/transports"
+)
+
+// spec is {{.Name}} as bootstrapped, the environment variables of its own
+// being prefixed with QS_{{.Env}}_.
+var spec = bootstrap.Spec{
+	Name:        "{{.Name}}",
+	HTTPPort:    "{{.HTTPPort}}",
+	GRPCPort:    "{{.GRPCPort}}",
+	Capture:     true,
+	Limits:      true,
+	Idempotency: true,
+}
+
+func main() {
+	nf := bootstrap.New(spec)
+	cfg := nf.Config
+	logger := nf.Logger
+
+	service := NewServer(nf.RequestLogger())
+	// the latencies carry the traces of their calls as exemplars, served
+	// with them on the admin port, and pushed over OTLP if configured
+	mw := nf.Middleware()
+	mw.Duration = nf.RequestDuration()
+	endpoints := endpoints.New(service, mw)
+
+	nf.HTTP(cfg.HTTPPort, func(m *http.ServeMux) {
+		m.Handle("/", transports.NewHTTPHandler(endpoints, nf.Tracer, nf.ZipkinTracer, logger))
+	})
+	nf.GRPC(cfg.GRPCPort, func(s *grpc.Server) {
+		pb.Register{{.PBType}}Server(s, transports.MakeGRPCServer(endpoints, nf.Tracer, nf.ZipkinTracer, logger))
+	})
+	nf.Run()
+}
+
+func NewServer(logger log.Logger) service.{{.Type}}Service {
+	service := service.New(logger)
+	return service
+}
+`
+
+const k8sTemplate = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    app: {{.Name}}
+  name: {{.Name}}
+spec:
+  replicas: 1
+  strategy: {}
+  selector:
+    matchLabels:
+      app: {{.Name}}
+  template:
+    metadata:
+      creationTimestamp: null
+      labels:
+        app: {{.Name}}
+      annotations:
+        "consul.hashicorp.com/connect-inject": "true"
+        "consul.hashicorp.com/connect-service": "{{.Name}}"
+        "consul.hashicorp.com/connect-service-port": "{{.K8sGRPCPort}}"
+        "consul.hashicorp.com/connect-service-protocol": "grpc"
+        "consul.hashicorp.com/connect-service-upstreams": "zipkin:9411"
+    spec:
+      containers:
+        - env:
+            - name: QS_{{.Env}}_GRPC_PORT
+              value: "{{.K8sGRPCPort}}"
+            - name: QS_{{.Env}}_HTTP_PORT
+              value: "{{.K8sHTTPPort}}"
+            - name: QS_{{.Env}}_ADMIN_PORT
+              value: "{{.K8sAdminPort}}"
+            - name: QS_{{.Env}}_LOG_LEVEL
+              value: info
+            - name: QS_ZIPKIN_V2_URL
+              value: http://localhost:9411/api/v2/spans
+          image: {{.Image}}
+          name: {{.Name}}
+          lifecycle:
+            preStop:
+              httpGet:
+                path: /admin/drain
+                port: {{.K8sAdminPort}}
+        - name: prometheus-statsd
+          image: "prom/statsd-exporter:latest"
+          ports:
+          - name: metrics
+            containerPort: 9102
+          - name: statsd
+            containerPort: 9125
+      restartPolicy: Always
+---
+apiVersion: v1
+kind: Service
+metadata:
+  labels:
+    app: {{.Name}}
+    tag: gokitconsul
+  name: {{.Name}}
+spec:
+  ports:
+  - name: metrics
+    port: 9090
+    targetPort: 9102
+  selector:
+    app: {{.Name}}
+`
+
+const skaffoldTemplate = `    - image: {{.Image}}
+      custom:
+        buildCommand: make dev_docker_{{.Name}}
+        dependencies:
+          paths:
+            - cmd/{{.Name}}/main.go
+            - pkg/{{.Name}}
+`
@@ -4,14 +4,19 @@ require (
 	github.com/VividCortex/gohistogram v1.0.0 // indirect
 	github.com/afex/hystrix-go v0.0.0-20180502004556-fa1af6a1f4f5 // indirect
 	github.com/codeskyblue/gohttpserver v0.0.0-20190302135655-85b2bd5dc484 // indirect
+	github.com/fxamacker/cbor/v2 v2.2.0
 	github.com/go-kit/kit v0.9.0
+	github.com/go-redis/redis v6.14.1+incompatible
+	github.com/golang/mock v1.3.1-0.20190508161146-9fa652df1129
 	github.com/golang/protobuf v1.4.2
 	github.com/gorilla/mux v1.7.3
+	github.com/gorilla/websocket v1.4.2
 	github.com/grpc-ecosystem/go-grpc-middleware v1.0.0 // indirect
 	github.com/grpc-ecosystem/grpc-opentracing v0.0.0-20180507213350-8e809c8a8645 // indirect
 	github.com/hashicorp/consul v1.6.0 // indirect
 	github.com/hashicorp/go-hclog v0.9.2 // indirect
 	github.com/hashicorp/golang-lru v0.5.1 // indirect
+	github.com/lib/pq v1.10.9
 	github.com/mwitkow/grpc-proxy v0.0.0-20181017164139-0f1106ef9c76
 	github.com/nicholasjackson/grpc-consul-resolver v0.2.0 // indirect
 	github.com/opentracing/opentracing-go v1.1.0
@@ -27,6 +32,7 @@ require (
 	google.golang.org/genproto v0.0.0-20200602104108-2bb8d6132df6 // indirect
 	google.golang.org/grpc v1.29.1
 	google.golang.org/protobuf v1.24.0
+	gopkg.in/yaml.v2 v2.4.0
 )
 
 go 1.13
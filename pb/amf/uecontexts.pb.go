@@ -0,0 +1,945 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.24.0
+// 	protoc        v3.12.2
+// source: amf/uecontexts.proto
+
+// The bulk export and import of the UE contexts of an AMF, to drain it or
+// to migrate its UEs to another deployment.
+
+package amf
+
+import (
+	context "context"
+	proto "github.com/golang/protobuf/proto"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// This is a compile-time assertion that a sufficiently up-to-date version
+// of the legacy proto package is being used.
+const _ = proto.ProtoPackageIsVersion4
+
+// What becomes of a context imported for a SUPI the AMF already holds.
+type Conflict int32
+
+const (
+	// The import fails, and imports nothing.
+	Conflict_REJECT Conflict = 0
+	// The context is not imported.
+	Conflict_SKIP Conflict = 1
+	// The context imported replaces the one held.
+	Conflict_REPLACE Conflict = 2
+)
+
+// Enum value maps for Conflict.
+var (
+	Conflict_name = map[int32]string{
+		0: "REJECT",
+		1: "SKIP",
+		2: "REPLACE",
+	}
+	Conflict_value = map[string]int32{
+		"REJECT":  0,
+		"SKIP":    1,
+		"REPLACE": 2,
+	}
+)
+
+func (x Conflict) Enum() *Conflict {
+	p := new(Conflict)
+	*p = x
+	return p
+}
+
+func (x Conflict) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Conflict) Descriptor() protoreflect.EnumDescriptor {
+	return file_amf_uecontexts_proto_enumTypes[0].Descriptor()
+}
+
+func (Conflict) Type() protoreflect.EnumType {
+	return &file_amf_uecontexts_proto_enumTypes[0]
+}
+
+func (x Conflict) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Conflict.Descriptor instead.
+func (Conflict) EnumDescriptor() ([]byte, []int) {
+	return file_amf_uecontexts_proto_rawDescGZIP(), []int{0}
+}
+
+type ExportRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The slice the UEs are allowed, written SST or SST-SD, and their
+	// CM state, CM-IDLE or CM-CONNECTED. Empty selects them all.
+	Snssai  string `protobuf:"bytes,1,opt,name=snssai,proto3" json:"snssai,omitempty"`
+	CmState string `protobuf:"bytes,2,opt,name=cm_state,json=cmState,proto3" json:"cm_state,omitempty"`
+	// The TAC of the last TAI of the UEs, 6 hexadecimal digits. Empty
+	// selects them all.
+	Tac string `protobuf:"bytes,3,opt,name=tac,proto3" json:"tac,omitempty"`
+}
+
+func (x *ExportRequest) Reset() {
+	*x = ExportRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_amf_uecontexts_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ExportRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportRequest) ProtoMessage() {}
+
+func (x *ExportRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_amf_uecontexts_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportRequest.ProtoReflect.Descriptor instead.
+func (*ExportRequest) Descriptor() ([]byte, []int) {
+	return file_amf_uecontexts_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ExportRequest) GetSnssai() string {
+	if x != nil {
+		return x.Snssai
+	}
+	return ""
+}
+
+func (x *ExportRequest) GetCmState() string {
+	if x != nil {
+		return x.CmState
+	}
+	return ""
+}
+
+func (x *ExportRequest) GetTac() string {
+	if x != nil {
+		return x.Tac
+	}
+	return ""
+}
+
+type UEContext struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Supi string `protobuf:"bytes,1,opt,name=supi,proto3" json:"supi,omitempty"`
+	// The context, as JSON, and its CRC-32C (Castagnoli).
+	Context []byte `protobuf:"bytes,2,opt,name=context,proto3" json:"context,omitempty"`
+	Crc32C  uint32 `protobuf:"fixed32,3,opt,name=crc32c,proto3" json:"crc32c,omitempty"`
+}
+
+func (x *UEContext) Reset() {
+	*x = UEContext{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_amf_uecontexts_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UEContext) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UEContext) ProtoMessage() {}
+
+func (x *UEContext) ProtoReflect() protoreflect.Message {
+	mi := &file_amf_uecontexts_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UEContext.ProtoReflect.Descriptor instead.
+func (*UEContext) Descriptor() ([]byte, []int) {
+	return file_amf_uecontexts_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *UEContext) GetSupi() string {
+	if x != nil {
+		return x.Supi
+	}
+	return ""
+}
+
+func (x *UEContext) GetContext() []byte {
+	if x != nil {
+		return x.Context
+	}
+	return nil
+}
+
+func (x *UEContext) GetCrc32C() uint32 {
+	if x != nil {
+		return x.Crc32C
+	}
+	return 0
+}
+
+type ImportRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The conflict policy of the import, read from the first message.
+	Conflict Conflict   `protobuf:"varint,1,opt,name=conflict,proto3,enum=amf.Conflict" json:"conflict,omitempty"`
+	Context  *UEContext `protobuf:"bytes,2,opt,name=context,proto3" json:"context,omitempty"`
+}
+
+func (x *ImportRequest) Reset() {
+	*x = ImportRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_amf_uecontexts_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ImportRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportRequest) ProtoMessage() {}
+
+func (x *ImportRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_amf_uecontexts_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportRequest.ProtoReflect.Descriptor instead.
+func (*ImportRequest) Descriptor() ([]byte, []int) {
+	return file_amf_uecontexts_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ImportRequest) GetConflict() Conflict {
+	if x != nil {
+		return x.Conflict
+	}
+	return Conflict_REJECT
+}
+
+func (x *ImportRequest) GetContext() *UEContext {
+	if x != nil {
+		return x.Context
+	}
+	return nil
+}
+
+type ImportReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Imported uint32 `protobuf:"varint,1,opt,name=imported,proto3" json:"imported,omitempty"`
+	Skipped  uint32 `protobuf:"varint,2,opt,name=skipped,proto3" json:"skipped,omitempty"`
+	Replaced uint32 `protobuf:"varint,3,opt,name=replaced,proto3" json:"replaced,omitempty"`
+}
+
+func (x *ImportReply) Reset() {
+	*x = ImportReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_amf_uecontexts_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ImportReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportReply) ProtoMessage() {}
+
+func (x *ImportReply) ProtoReflect() protoreflect.Message {
+	mi := &file_amf_uecontexts_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportReply.ProtoReflect.Descriptor instead.
+func (*ImportReply) Descriptor() ([]byte, []int) {
+	return file_amf_uecontexts_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ImportReply) GetImported() uint32 {
+	if x != nil {
+		return x.Imported
+	}
+	return 0
+}
+
+func (x *ImportReply) GetSkipped() uint32 {
+	if x != nil {
+		return x.Skipped
+	}
+	return 0
+}
+
+func (x *ImportReply) GetReplaced() uint32 {
+	if x != nil {
+		return x.Replaced
+	}
+	return 0
+}
+
+type GetUELocationRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Supi string `protobuf:"bytes,1,opt,name=supi,proto3" json:"supi,omitempty"`
+}
+
+func (x *GetUELocationRequest) Reset() {
+	*x = GetUELocationRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_amf_uecontexts_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetUELocationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUELocationRequest) ProtoMessage() {}
+
+func (x *GetUELocationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_amf_uecontexts_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUELocationRequest.ProtoReflect.Descriptor instead.
+func (*GetUELocationRequest) Descriptor() ([]byte, []int) {
+	return file_amf_uecontexts_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetUELocationRequest) GetSupi() string {
+	if x != nil {
+		return x.Supi
+	}
+	return ""
+}
+
+type Location struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The TAI, written MCC-MNC-TAC, and the outer address of the UE.
+	Tai       string `protobuf:"bytes,1,opt,name=tai,proto3" json:"tai,omitempty"`
+	UeAddress string `protobuf:"bytes,2,opt,name=ue_address,json=ueAddress,proto3" json:"ue_address,omitempty"`
+	Since     int64  `protobuf:"varint,3,opt,name=since,proto3" json:"since,omitempty"` // Unix time in milliseconds
+}
+
+func (x *Location) Reset() {
+	*x = Location{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_amf_uecontexts_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Location) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Location) ProtoMessage() {}
+
+func (x *Location) ProtoReflect() protoreflect.Message {
+	mi := &file_amf_uecontexts_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Location.ProtoReflect.Descriptor instead.
+func (*Location) Descriptor() ([]byte, []int) {
+	return file_amf_uecontexts_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Location) GetTai() string {
+	if x != nil {
+		return x.Tai
+	}
+	return ""
+}
+
+func (x *Location) GetUeAddress() string {
+	if x != nil {
+		return x.UeAddress
+	}
+	return ""
+}
+
+func (x *Location) GetSince() int64 {
+	if x != nil {
+		return x.Since
+	}
+	return 0
+}
+
+type UELocation struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Location *Location `protobuf:"bytes,1,opt,name=location,proto3" json:"location,omitempty"`
+	// The earlier locations, the last one first.
+	History []*Location `protobuf:"bytes,2,rep,name=history,proto3" json:"history,omitempty"`
+}
+
+func (x *UELocation) Reset() {
+	*x = UELocation{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_amf_uecontexts_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UELocation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UELocation) ProtoMessage() {}
+
+func (x *UELocation) ProtoReflect() protoreflect.Message {
+	mi := &file_amf_uecontexts_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UELocation.ProtoReflect.Descriptor instead.
+func (*UELocation) Descriptor() ([]byte, []int) {
+	return file_amf_uecontexts_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *UELocation) GetLocation() *Location {
+	if x != nil {
+		return x.Location
+	}
+	return nil
+}
+
+func (x *UELocation) GetHistory() []*Location {
+	if x != nil {
+		return x.History
+	}
+	return nil
+}
+
+var File_amf_uecontexts_proto protoreflect.FileDescriptor
+
+var file_amf_uecontexts_proto_rawDesc = []byte{
+	0x0a, 0x14, 0x61, 0x6d, 0x66, 0x2f, 0x75, 0x65, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x73,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x03, 0x61, 0x6d, 0x66, 0x22, 0x54, 0x0a, 0x0d, 0x45,
+	0x78, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06,
+	0x73, 0x6e, 0x73, 0x73, 0x61, 0x69, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x6e,
+	0x73, 0x73, 0x61, 0x69, 0x12, 0x19, 0x0a, 0x08, 0x63, 0x6d, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x65,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x63, 0x6d, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12,
+	0x10, 0x0a, 0x03, 0x74, 0x61, 0x63, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x74, 0x61,
+	0x63, 0x22, 0x51, 0x0a, 0x09, 0x55, 0x45, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x12, 0x12,
+	0x0a, 0x04, 0x73, 0x75, 0x70, 0x69, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x73, 0x75,
+	0x70, 0x69, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0c, 0x52, 0x07, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x12, 0x16, 0x0a, 0x06,
+	0x63, 0x72, 0x63, 0x33, 0x32, 0x63, 0x18, 0x03, 0x20, 0x01, 0x28, 0x07, 0x52, 0x06, 0x63, 0x72,
+	0x63, 0x33, 0x32, 0x63, 0x22, 0x64, 0x0a, 0x0d, 0x49, 0x6d, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x29, 0x0a, 0x08, 0x63, 0x6f, 0x6e, 0x66, 0x6c, 0x69, 0x63,
+	0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0d, 0x2e, 0x61, 0x6d, 0x66, 0x2e, 0x43, 0x6f,
+	0x6e, 0x66, 0x6c, 0x69, 0x63, 0x74, 0x52, 0x08, 0x63, 0x6f, 0x6e, 0x66, 0x6c, 0x69, 0x63, 0x74,
+	0x12, 0x28, 0x0a, 0x07, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x0e, 0x2e, 0x61, 0x6d, 0x66, 0x2e, 0x55, 0x45, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x78,
+	0x74, 0x52, 0x07, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x22, 0x5f, 0x0a, 0x0b, 0x49, 0x6d,
+	0x70, 0x6f, 0x72, 0x74, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x1a, 0x0a, 0x08, 0x69, 0x6d, 0x70,
+	0x6f, 0x72, 0x74, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x69, 0x6d, 0x70,
+	0x6f, 0x72, 0x74, 0x65, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x6b, 0x69, 0x70, 0x70, 0x65, 0x64,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x73, 0x6b, 0x69, 0x70, 0x70, 0x65, 0x64, 0x12,
+	0x1a, 0x0a, 0x08, 0x72, 0x65, 0x70, 0x6c, 0x61, 0x63, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x0d, 0x52, 0x08, 0x72, 0x65, 0x70, 0x6c, 0x61, 0x63, 0x65, 0x64, 0x22, 0x2a, 0x0a, 0x14, 0x47,
+	0x65, 0x74, 0x55, 0x45, 0x4c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x75, 0x70, 0x69, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x04, 0x73, 0x75, 0x70, 0x69, 0x22, 0x51, 0x0a, 0x08, 0x4c, 0x6f, 0x63, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x12, 0x10, 0x0a, 0x03, 0x74, 0x61, 0x69, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x03, 0x74, 0x61, 0x69, 0x12, 0x1d, 0x0a, 0x0a, 0x75, 0x65, 0x5f, 0x61, 0x64, 0x64, 0x72,
+	0x65, 0x73, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x75, 0x65, 0x41, 0x64, 0x64,
+	0x72, 0x65, 0x73, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x69, 0x6e, 0x63, 0x65, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x05, 0x73, 0x69, 0x6e, 0x63, 0x65, 0x22, 0x60, 0x0a, 0x0a, 0x55, 0x45,
+	0x4c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x29, 0x0a, 0x08, 0x6c, 0x6f, 0x63, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x61, 0x6d, 0x66,
+	0x2e, 0x4c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x08, 0x6c, 0x6f, 0x63, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x12, 0x27, 0x0a, 0x07, 0x68, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x18, 0x02,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x61, 0x6d, 0x66, 0x2e, 0x4c, 0x6f, 0x63, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x52, 0x07, 0x68, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x2a, 0x2d, 0x0a, 0x08,
+	0x43, 0x6f, 0x6e, 0x66, 0x6c, 0x69, 0x63, 0x74, 0x12, 0x0a, 0x0a, 0x06, 0x52, 0x45, 0x4a, 0x45,
+	0x43, 0x54, 0x10, 0x00, 0x12, 0x08, 0x0a, 0x04, 0x53, 0x4b, 0x49, 0x50, 0x10, 0x01, 0x12, 0x0b,
+	0x0a, 0x07, 0x52, 0x45, 0x50, 0x4c, 0x41, 0x43, 0x45, 0x10, 0x02, 0x32, 0xb1, 0x01, 0x0a, 0x0a,
+	0x55, 0x45, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x73, 0x12, 0x30, 0x0a, 0x06, 0x45, 0x78,
+	0x70, 0x6f, 0x72, 0x74, 0x12, 0x12, 0x2e, 0x61, 0x6d, 0x66, 0x2e, 0x45, 0x78, 0x70, 0x6f, 0x72,
+	0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0e, 0x2e, 0x61, 0x6d, 0x66, 0x2e, 0x55,
+	0x45, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x22, 0x00, 0x30, 0x01, 0x12, 0x32, 0x0a, 0x06,
+	0x49, 0x6d, 0x70, 0x6f, 0x72, 0x74, 0x12, 0x12, 0x2e, 0x61, 0x6d, 0x66, 0x2e, 0x49, 0x6d, 0x70,
+	0x6f, 0x72, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x10, 0x2e, 0x61, 0x6d, 0x66,
+	0x2e, 0x49, 0x6d, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x28, 0x01,
+	0x12, 0x3d, 0x0a, 0x0d, 0x47, 0x65, 0x74, 0x55, 0x45, 0x4c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x12, 0x19, 0x2e, 0x61, 0x6d, 0x66, 0x2e, 0x47, 0x65, 0x74, 0x55, 0x45, 0x4c, 0x6f, 0x63,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0f, 0x2e, 0x61,
+	0x6d, 0x66, 0x2e, 0x55, 0x45, 0x4c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x00, 0x42,
+	0x31, 0x5a, 0x2f, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6d, 0x69,
+	0x6b, 0x69, 0x2d, 0x74, 0x6e, 0x74, 0x2f, 0x73, 0x61, 0x35, 0x67, 0x2d, 0x67, 0x6f, 0x2d, 0x75,
+	0x73, 0x76, 0x63, 0x2d, 0x6b, 0x38, 0x73, 0x2f, 0x70, 0x62, 0x2f, 0x61, 0x6d, 0x66, 0x3b, 0x61,
+	0x6d, 0x66, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_amf_uecontexts_proto_rawDescOnce sync.Once
+	file_amf_uecontexts_proto_rawDescData = file_amf_uecontexts_proto_rawDesc
+)
+
+func file_amf_uecontexts_proto_rawDescGZIP() []byte {
+	file_amf_uecontexts_proto_rawDescOnce.Do(func() {
+		file_amf_uecontexts_proto_rawDescData = protoimpl.X.CompressGZIP(file_amf_uecontexts_proto_rawDescData)
+	})
+	return file_amf_uecontexts_proto_rawDescData
+}
+
+var file_amf_uecontexts_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_amf_uecontexts_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_amf_uecontexts_proto_goTypes = []interface{}{
+	(Conflict)(0),                // 0: amf.Conflict
+	(*ExportRequest)(nil),        // 1: amf.ExportRequest
+	(*UEContext)(nil),            // 2: amf.UEContext
+	(*ImportRequest)(nil),        // 3: amf.ImportRequest
+	(*ImportReply)(nil),          // 4: amf.ImportReply
+	(*GetUELocationRequest)(nil), // 5: amf.GetUELocationRequest
+	(*Location)(nil),             // 6: amf.Location
+	(*UELocation)(nil),           // 7: amf.UELocation
+}
+var file_amf_uecontexts_proto_depIdxs = []int32{
+	0, // 0: amf.ImportRequest.conflict:type_name -> amf.Conflict
+	2, // 1: amf.ImportRequest.context:type_name -> amf.UEContext
+	6, // 2: amf.UELocation.location:type_name -> amf.Location
+	6, // 3: amf.UELocation.history:type_name -> amf.Location
+	1, // 4: amf.UEContexts.Export:input_type -> amf.ExportRequest
+	3, // 5: amf.UEContexts.Import:input_type -> amf.ImportRequest
+	5, // 6: amf.UEContexts.GetUELocation:input_type -> amf.GetUELocationRequest
+	2, // 7: amf.UEContexts.Export:output_type -> amf.UEContext
+	4, // 8: amf.UEContexts.Import:output_type -> amf.ImportReply
+	7, // 9: amf.UEContexts.GetUELocation:output_type -> amf.UELocation
+	7, // [7:10] is the sub-list for method output_type
+	4, // [4:7] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_amf_uecontexts_proto_init() }
+func file_amf_uecontexts_proto_init() {
+	if File_amf_uecontexts_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_amf_uecontexts_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ExportRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_amf_uecontexts_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UEContext); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_amf_uecontexts_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ImportRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_amf_uecontexts_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ImportReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_amf_uecontexts_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetUELocationRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_amf_uecontexts_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Location); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_amf_uecontexts_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UELocation); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_amf_uecontexts_proto_rawDesc,
+			NumEnums:      1,
+			NumMessages:   7,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_amf_uecontexts_proto_goTypes,
+		DependencyIndexes: file_amf_uecontexts_proto_depIdxs,
+		EnumInfos:         file_amf_uecontexts_proto_enumTypes,
+		MessageInfos:      file_amf_uecontexts_proto_msgTypes,
+	}.Build()
+	File_amf_uecontexts_proto = out.File
+	file_amf_uecontexts_proto_rawDesc = nil
+	file_amf_uecontexts_proto_goTypes = nil
+	file_amf_uecontexts_proto_depIdxs = nil
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConnInterface
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion6
+
+// UEContextsClient is the client API for UEContexts service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type UEContextsClient interface {
+	// Export sends the contexts of the UEs the AMF holds, by SUPI.
+	Export(ctx context.Context, in *ExportRequest, opts ...grpc.CallOption) (UEContexts_ExportClient, error)
+	// Import stores the contexts of the stream, the UEs keeping them when
+	// they register again. The contexts are checked first, and none is
+	// imported unless all are valid.
+	Import(ctx context.Context, opts ...grpc.CallOption) (UEContexts_ImportClient, error)
+	// GetUELocation returns the location of a registered UE, and its
+	// earlier locations.
+	GetUELocation(ctx context.Context, in *GetUELocationRequest, opts ...grpc.CallOption) (*UELocation, error)
+}
+
+type uEContextsClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewUEContextsClient(cc grpc.ClientConnInterface) UEContextsClient {
+	return &uEContextsClient{cc}
+}
+
+func (c *uEContextsClient) Export(ctx context.Context, in *ExportRequest, opts ...grpc.CallOption) (UEContexts_ExportClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_UEContexts_serviceDesc.Streams[0], "/amf.UEContexts/Export", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &uEContextsExportClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type UEContexts_ExportClient interface {
+	Recv() (*UEContext, error)
+	grpc.ClientStream
+}
+
+type uEContextsExportClient struct {
+	grpc.ClientStream
+}
+
+func (x *uEContextsExportClient) Recv() (*UEContext, error) {
+	m := new(UEContext)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *uEContextsClient) Import(ctx context.Context, opts ...grpc.CallOption) (UEContexts_ImportClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_UEContexts_serviceDesc.Streams[1], "/amf.UEContexts/Import", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &uEContextsImportClient{stream}
+	return x, nil
+}
+
+type UEContexts_ImportClient interface {
+	Send(*ImportRequest) error
+	CloseAndRecv() (*ImportReply, error)
+	grpc.ClientStream
+}
+
+type uEContextsImportClient struct {
+	grpc.ClientStream
+}
+
+func (x *uEContextsImportClient) Send(m *ImportRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *uEContextsImportClient) CloseAndRecv() (*ImportReply, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(ImportReply)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *uEContextsClient) GetUELocation(ctx context.Context, in *GetUELocationRequest, opts ...grpc.CallOption) (*UELocation, error) {
+	out := new(UELocation)
+	err := c.cc.Invoke(ctx, "/amf.UEContexts/GetUELocation", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UEContextsServer is the server API for UEContexts service.
+type UEContextsServer interface {
+	// Export sends the contexts of the UEs the AMF holds, by SUPI.
+	Export(*ExportRequest, UEContexts_ExportServer) error
+	// Import stores the contexts of the stream, the UEs keeping them when
+	// they register again. The contexts are checked first, and none is
+	// imported unless all are valid.
+	Import(UEContexts_ImportServer) error
+	// GetUELocation returns the location of a registered UE, and its
+	// earlier locations.
+	GetUELocation(context.Context, *GetUELocationRequest) (*UELocation, error)
+}
+
+// UnimplementedUEContextsServer can be embedded to have forward compatible implementations.
+type UnimplementedUEContextsServer struct {
+}
+
+func (*UnimplementedUEContextsServer) Export(*ExportRequest, UEContexts_ExportServer) error {
+	return status.Errorf(codes.Unimplemented, "method Export not implemented")
+}
+func (*UnimplementedUEContextsServer) Import(UEContexts_ImportServer) error {
+	return status.Errorf(codes.Unimplemented, "method Import not implemented")
+}
+func (*UnimplementedUEContextsServer) GetUELocation(context.Context, *GetUELocationRequest) (*UELocation, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUELocation not implemented")
+}
+
+func RegisterUEContextsServer(s *grpc.Server, srv UEContextsServer) {
+	s.RegisterService(&_UEContexts_serviceDesc, srv)
+}
+
+func _UEContexts_Export_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(UEContextsServer).Export(m, &uEContextsExportServer{stream})
+}
+
+type UEContexts_ExportServer interface {
+	Send(*UEContext) error
+	grpc.ServerStream
+}
+
+type uEContextsExportServer struct {
+	grpc.ServerStream
+}
+
+func (x *uEContextsExportServer) Send(m *UEContext) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _UEContexts_Import_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(UEContextsServer).Import(&uEContextsImportServer{stream})
+}
+
+type UEContexts_ImportServer interface {
+	SendAndClose(*ImportReply) error
+	Recv() (*ImportRequest, error)
+	grpc.ServerStream
+}
+
+type uEContextsImportServer struct {
+	grpc.ServerStream
+}
+
+func (x *uEContextsImportServer) SendAndClose(m *ImportReply) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *uEContextsImportServer) Recv() (*ImportRequest, error) {
+	m := new(ImportRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _UEContexts_GetUELocation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUELocationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UEContextsServer).GetUELocation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/amf.UEContexts/GetUELocation",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UEContextsServer).GetUELocation(ctx, req.(*GetUELocationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _UEContexts_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "amf.UEContexts",
+	HandlerType: (*UEContextsServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetUELocation",
+			Handler:    _UEContexts_GetUELocation_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Export",
+			Handler:       _UEContexts_Export_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Import",
+			Handler:       _UEContexts_Import_Handler,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "amf/uecontexts.proto",
+}
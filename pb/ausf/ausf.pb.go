@@ -0,0 +1,566 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.24.0
+// 	protoc        v3.12.2
+// source: ausf.proto
+
+package pb
+
+import (
+	context "context"
+	proto "github.com/golang/protobuf/proto"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// This is a compile-time assertion that a sufficiently up-to-date version
+// of the legacy proto package is being used.
+const _ = proto.ProtoPackageIsVersion4
+
+type AuthenticateRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	SupiOrSuci         string `protobuf:"bytes,1,opt,name=supi_or_suci,json=supiOrSuci,proto3" json:"supi_or_suci,omitempty"`
+	ServingNetworkName string `protobuf:"bytes,2,opt,name=serving_network_name,json=servingNetworkName,proto3" json:"serving_network_name,omitempty"`
+	// Set after a synchronisation failure reported by the UE.
+	ResyncRand []byte `protobuf:"bytes,3,opt,name=resync_rand,json=resyncRand,proto3" json:"resync_rand,omitempty"`
+	ResyncAuts []byte `protobuf:"bytes,4,opt,name=resync_auts,json=resyncAuts,proto3" json:"resync_auts,omitempty"`
+}
+
+func (x *AuthenticateRequest) Reset() {
+	*x = AuthenticateRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ausf_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AuthenticateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AuthenticateRequest) ProtoMessage() {}
+
+func (x *AuthenticateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ausf_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AuthenticateRequest.ProtoReflect.Descriptor instead.
+func (*AuthenticateRequest) Descriptor() ([]byte, []int) {
+	return file_ausf_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *AuthenticateRequest) GetSupiOrSuci() string {
+	if x != nil {
+		return x.SupiOrSuci
+	}
+	return ""
+}
+
+func (x *AuthenticateRequest) GetServingNetworkName() string {
+	if x != nil {
+		return x.ServingNetworkName
+	}
+	return ""
+}
+
+func (x *AuthenticateRequest) GetResyncRand() []byte {
+	if x != nil {
+		return x.ResyncRand
+	}
+	return nil
+}
+
+func (x *AuthenticateRequest) GetResyncAuts() []byte {
+	if x != nil {
+		return x.ResyncAuts
+	}
+	return nil
+}
+
+type AuthenticateReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	AuthCtxId string `protobuf:"bytes,1,opt,name=auth_ctx_id,json=authCtxId,proto3" json:"auth_ctx_id,omitempty"`
+	Rand      []byte `protobuf:"bytes,2,opt,name=rand,proto3" json:"rand,omitempty"`
+	Autn      []byte `protobuf:"bytes,3,opt,name=autn,proto3" json:"autn,omitempty"`
+	HxresStar []byte `protobuf:"bytes,4,opt,name=hxres_star,json=hxresStar,proto3" json:"hxres_star,omitempty"`
+	Err       string `protobuf:"bytes,5,opt,name=err,proto3" json:"err,omitempty"`
+}
+
+func (x *AuthenticateReply) Reset() {
+	*x = AuthenticateReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ausf_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AuthenticateReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AuthenticateReply) ProtoMessage() {}
+
+func (x *AuthenticateReply) ProtoReflect() protoreflect.Message {
+	mi := &file_ausf_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AuthenticateReply.ProtoReflect.Descriptor instead.
+func (*AuthenticateReply) Descriptor() ([]byte, []int) {
+	return file_ausf_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *AuthenticateReply) GetAuthCtxId() string {
+	if x != nil {
+		return x.AuthCtxId
+	}
+	return ""
+}
+
+func (x *AuthenticateReply) GetRand() []byte {
+	if x != nil {
+		return x.Rand
+	}
+	return nil
+}
+
+func (x *AuthenticateReply) GetAutn() []byte {
+	if x != nil {
+		return x.Autn
+	}
+	return nil
+}
+
+func (x *AuthenticateReply) GetHxresStar() []byte {
+	if x != nil {
+		return x.HxresStar
+	}
+	return nil
+}
+
+func (x *AuthenticateReply) GetErr() string {
+	if x != nil {
+		return x.Err
+	}
+	return ""
+}
+
+type ConfirmAuthRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	AuthCtxId string `protobuf:"bytes,1,opt,name=auth_ctx_id,json=authCtxId,proto3" json:"auth_ctx_id,omitempty"`
+	ResStar   []byte `protobuf:"bytes,2,opt,name=res_star,json=resStar,proto3" json:"res_star,omitempty"`
+}
+
+func (x *ConfirmAuthRequest) Reset() {
+	*x = ConfirmAuthRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ausf_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ConfirmAuthRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConfirmAuthRequest) ProtoMessage() {}
+
+func (x *ConfirmAuthRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ausf_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConfirmAuthRequest.ProtoReflect.Descriptor instead.
+func (*ConfirmAuthRequest) Descriptor() ([]byte, []int) {
+	return file_ausf_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ConfirmAuthRequest) GetAuthCtxId() string {
+	if x != nil {
+		return x.AuthCtxId
+	}
+	return ""
+}
+
+func (x *ConfirmAuthRequest) GetResStar() []byte {
+	if x != nil {
+		return x.ResStar
+	}
+	return nil
+}
+
+type ConfirmAuthReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Supi  string `protobuf:"bytes,1,opt,name=supi,proto3" json:"supi,omitempty"`
+	Kseaf []byte `protobuf:"bytes,2,opt,name=kseaf,proto3" json:"kseaf,omitempty"`
+	Err   string `protobuf:"bytes,3,opt,name=err,proto3" json:"err,omitempty"`
+}
+
+func (x *ConfirmAuthReply) Reset() {
+	*x = ConfirmAuthReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_ausf_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ConfirmAuthReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConfirmAuthReply) ProtoMessage() {}
+
+func (x *ConfirmAuthReply) ProtoReflect() protoreflect.Message {
+	mi := &file_ausf_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConfirmAuthReply.ProtoReflect.Descriptor instead.
+func (*ConfirmAuthReply) Descriptor() ([]byte, []int) {
+	return file_ausf_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ConfirmAuthReply) GetSupi() string {
+	if x != nil {
+		return x.Supi
+	}
+	return ""
+}
+
+func (x *ConfirmAuthReply) GetKseaf() []byte {
+	if x != nil {
+		return x.Kseaf
+	}
+	return nil
+}
+
+func (x *ConfirmAuthReply) GetErr() string {
+	if x != nil {
+		return x.Err
+	}
+	return ""
+}
+
+var File_ausf_proto protoreflect.FileDescriptor
+
+var file_ausf_proto_rawDesc = []byte{
+	0x0a, 0x0a, 0x61, 0x75, 0x73, 0x66, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x02, 0x70, 0x62,
+	0x22, 0xab, 0x01, 0x0a, 0x13, 0x41, 0x75, 0x74, 0x68, 0x65, 0x6e, 0x74, 0x69, 0x63, 0x61, 0x74,
+	0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x20, 0x0a, 0x0c, 0x73, 0x75, 0x70, 0x69,
+	0x5f, 0x6f, 0x72, 0x5f, 0x73, 0x75, 0x63, 0x69, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a,
+	0x73, 0x75, 0x70, 0x69, 0x4f, 0x72, 0x53, 0x75, 0x63, 0x69, 0x12, 0x30, 0x0a, 0x14, 0x73, 0x65,
+	0x72, 0x76, 0x69, 0x6e, 0x67, 0x5f, 0x6e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x5f, 0x6e, 0x61,
+	0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x12, 0x73, 0x65, 0x72, 0x76, 0x69, 0x6e,
+	0x67, 0x4e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x1f, 0x0a, 0x0b,
+	0x72, 0x65, 0x73, 0x79, 0x6e, 0x63, 0x5f, 0x72, 0x61, 0x6e, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x0c, 0x52, 0x0a, 0x72, 0x65, 0x73, 0x79, 0x6e, 0x63, 0x52, 0x61, 0x6e, 0x64, 0x12, 0x1f, 0x0a,
+	0x0b, 0x72, 0x65, 0x73, 0x79, 0x6e, 0x63, 0x5f, 0x61, 0x75, 0x74, 0x73, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x0c, 0x52, 0x0a, 0x72, 0x65, 0x73, 0x79, 0x6e, 0x63, 0x41, 0x75, 0x74, 0x73, 0x22, 0x8c,
+	0x01, 0x0a, 0x11, 0x41, 0x75, 0x74, 0x68, 0x65, 0x6e, 0x74, 0x69, 0x63, 0x61, 0x74, 0x65, 0x52,
+	0x65, 0x70, 0x6c, 0x79, 0x12, 0x1e, 0x0a, 0x0b, 0x61, 0x75, 0x74, 0x68, 0x5f, 0x63, 0x74, 0x78,
+	0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x61, 0x75, 0x74, 0x68, 0x43,
+	0x74, 0x78, 0x49, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x72, 0x61, 0x6e, 0x64, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0c, 0x52, 0x04, 0x72, 0x61, 0x6e, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x61, 0x75, 0x74, 0x6e,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x61, 0x75, 0x74, 0x6e, 0x12, 0x1d, 0x0a, 0x0a,
+	0x68, 0x78, 0x72, 0x65, 0x73, 0x5f, 0x73, 0x74, 0x61, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0c,
+	0x52, 0x09, 0x68, 0x78, 0x72, 0x65, 0x73, 0x53, 0x74, 0x61, 0x72, 0x12, 0x10, 0x0a, 0x03, 0x65,
+	0x72, 0x72, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x65, 0x72, 0x72, 0x22, 0x4f, 0x0a,
+	0x12, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x72, 0x6d, 0x41, 0x75, 0x74, 0x68, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x1e, 0x0a, 0x0b, 0x61, 0x75, 0x74, 0x68, 0x5f, 0x63, 0x74, 0x78, 0x5f,
+	0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x61, 0x75, 0x74, 0x68, 0x43, 0x74,
+	0x78, 0x49, 0x64, 0x12, 0x19, 0x0a, 0x08, 0x72, 0x65, 0x73, 0x5f, 0x73, 0x74, 0x61, 0x72, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x72, 0x65, 0x73, 0x53, 0x74, 0x61, 0x72, 0x22, 0x4e,
+	0x0a, 0x10, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x72, 0x6d, 0x41, 0x75, 0x74, 0x68, 0x52, 0x65, 0x70,
+	0x6c, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x75, 0x70, 0x69, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x04, 0x73, 0x75, 0x70, 0x69, 0x12, 0x14, 0x0a, 0x05, 0x6b, 0x73, 0x65, 0x61, 0x66, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x05, 0x6b, 0x73, 0x65, 0x61, 0x66, 0x12, 0x10, 0x0a, 0x03,
+	0x65, 0x72, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x65, 0x72, 0x72, 0x32, 0x87,
+	0x01, 0x0a, 0x04, 0x41, 0x75, 0x73, 0x66, 0x12, 0x40, 0x0a, 0x0c, 0x41, 0x75, 0x74, 0x68, 0x65,
+	0x6e, 0x74, 0x69, 0x63, 0x61, 0x74, 0x65, 0x12, 0x17, 0x2e, 0x70, 0x62, 0x2e, 0x41, 0x75, 0x74,
+	0x68, 0x65, 0x6e, 0x74, 0x69, 0x63, 0x61, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x15, 0x2e, 0x70, 0x62, 0x2e, 0x41, 0x75, 0x74, 0x68, 0x65, 0x6e, 0x74, 0x69, 0x63, 0x61,
+	0x74, 0x65, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x3d, 0x0a, 0x0b, 0x43, 0x6f, 0x6e,
+	0x66, 0x69, 0x72, 0x6d, 0x41, 0x75, 0x74, 0x68, 0x12, 0x16, 0x2e, 0x70, 0x62, 0x2e, 0x43, 0x6f,
+	0x6e, 0x66, 0x69, 0x72, 0x6d, 0x41, 0x75, 0x74, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x14, 0x2e, 0x70, 0x62, 0x2e, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x72, 0x6d, 0x41, 0x75, 0x74,
+	0x68, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_ausf_proto_rawDescOnce sync.Once
+	file_ausf_proto_rawDescData = file_ausf_proto_rawDesc
+)
+
+func file_ausf_proto_rawDescGZIP() []byte {
+	file_ausf_proto_rawDescOnce.Do(func() {
+		file_ausf_proto_rawDescData = protoimpl.X.CompressGZIP(file_ausf_proto_rawDescData)
+	})
+	return file_ausf_proto_rawDescData
+}
+
+var file_ausf_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_ausf_proto_goTypes = []interface{}{
+	(*AuthenticateRequest)(nil), // 0: pb.AuthenticateRequest
+	(*AuthenticateReply)(nil),   // 1: pb.AuthenticateReply
+	(*ConfirmAuthRequest)(nil),  // 2: pb.ConfirmAuthRequest
+	(*ConfirmAuthReply)(nil),    // 3: pb.ConfirmAuthReply
+}
+var file_ausf_proto_depIdxs = []int32{
+	0, // 0: pb.Ausf.Authenticate:input_type -> pb.AuthenticateRequest
+	2, // 1: pb.Ausf.ConfirmAuth:input_type -> pb.ConfirmAuthRequest
+	1, // 2: pb.Ausf.Authenticate:output_type -> pb.AuthenticateReply
+	3, // 3: pb.Ausf.ConfirmAuth:output_type -> pb.ConfirmAuthReply
+	2, // [2:4] is the sub-list for method output_type
+	0, // [0:2] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_ausf_proto_init() }
+func file_ausf_proto_init() {
+	if File_ausf_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_ausf_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AuthenticateRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ausf_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AuthenticateReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ausf_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ConfirmAuthRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_ausf_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ConfirmAuthReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_ausf_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   4,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_ausf_proto_goTypes,
+		DependencyIndexes: file_ausf_proto_depIdxs,
+		MessageInfos:      file_ausf_proto_msgTypes,
+	}.Build()
+	File_ausf_proto = out.File
+	file_ausf_proto_rawDesc = nil
+	file_ausf_proto_goTypes = nil
+	file_ausf_proto_depIdxs = nil
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConnInterface
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion6
+
+// AusfClient is the client API for Ausf service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type AusfClient interface {
+	Authenticate(ctx context.Context, in *AuthenticateRequest, opts ...grpc.CallOption) (*AuthenticateReply, error)
+	ConfirmAuth(ctx context.Context, in *ConfirmAuthRequest, opts ...grpc.CallOption) (*ConfirmAuthReply, error)
+}
+
+type ausfClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAusfClient(cc grpc.ClientConnInterface) AusfClient {
+	return &ausfClient{cc}
+}
+
+func (c *ausfClient) Authenticate(ctx context.Context, in *AuthenticateRequest, opts ...grpc.CallOption) (*AuthenticateReply, error) {
+	out := new(AuthenticateReply)
+	err := c.cc.Invoke(ctx, "/pb.Ausf/Authenticate", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ausfClient) ConfirmAuth(ctx context.Context, in *ConfirmAuthRequest, opts ...grpc.CallOption) (*ConfirmAuthReply, error) {
+	out := new(ConfirmAuthReply)
+	err := c.cc.Invoke(ctx, "/pb.Ausf/ConfirmAuth", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AusfServer is the server API for Ausf service.
+type AusfServer interface {
+	Authenticate(context.Context, *AuthenticateRequest) (*AuthenticateReply, error)
+	ConfirmAuth(context.Context, *ConfirmAuthRequest) (*ConfirmAuthReply, error)
+}
+
+// UnimplementedAusfServer can be embedded to have forward compatible implementations.
+type UnimplementedAusfServer struct {
+}
+
+func (*UnimplementedAusfServer) Authenticate(context.Context, *AuthenticateRequest) (*AuthenticateReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Authenticate not implemented")
+}
+func (*UnimplementedAusfServer) ConfirmAuth(context.Context, *ConfirmAuthRequest) (*ConfirmAuthReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ConfirmAuth not implemented")
+}
+
+func RegisterAusfServer(s *grpc.Server, srv AusfServer) {
+	s.RegisterService(&_Ausf_serviceDesc, srv)
+}
+
+func _Ausf_Authenticate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AuthenticateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AusfServer).Authenticate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Ausf/Authenticate",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AusfServer).Authenticate(ctx, req.(*AuthenticateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Ausf_ConfirmAuth_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConfirmAuthRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AusfServer).ConfirmAuth(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Ausf/ConfirmAuth",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AusfServer).ConfirmAuth(ctx, req.(*ConfirmAuthRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Ausf_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "pb.Ausf",
+	HandlerType: (*AusfServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Authenticate",
+			Handler:    _Ausf_Authenticate_Handler,
+		},
+		{
+			MethodName: "ConfirmAuth",
+			Handler:    _Ausf_ConfirmAuth_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "ausf.proto",
+}
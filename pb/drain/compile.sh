@@ -0,0 +1,6 @@
+#!/usr/bin/env sh
+
+# See ../preamblesvc/compile.sh for the tools. The file is compiled from pb/
+# so that its name is drain/drain.proto in the registry.
+
+cd .. && protoc drain/drain.proto --go_out=plugins=grpc,paths=source_relative:.
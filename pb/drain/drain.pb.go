@@ -0,0 +1,551 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.24.0
+// 	protoc        v3.12.2
+// source: drain/drain.proto
+
+// The drain of a network function before it stops, served next to the
+// health service on every gRPC server.
+
+package drain
+
+import (
+	context "context"
+	proto "github.com/golang/protobuf/proto"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// This is a compile-time assertion that a sufficiently up-to-date version
+// of the legacy proto package is being used.
+const _ = proto.ProtoPackageIsVersion4
+
+type State int32
+
+const (
+	// The NF serves.
+	State_SERVING State = 0
+	// The NF serves, waiting for the NFs calling it to be drained.
+	State_WAITING State = 1
+	// The NF refuses the new calls and finishes those in flight.
+	State_DRAINING State = 2
+	// The NF has no call left, and can stop.
+	State_DRAINED State = 3
+)
+
+// Enum value maps for State.
+var (
+	State_name = map[int32]string{
+		0: "SERVING",
+		1: "WAITING",
+		2: "DRAINING",
+		3: "DRAINED",
+	}
+	State_value = map[string]int32{
+		"SERVING":  0,
+		"WAITING":  1,
+		"DRAINING": 2,
+		"DRAINED":  3,
+	}
+)
+
+func (x State) Enum() *State {
+	p := new(State)
+	*p = x
+	return p
+}
+
+func (x State) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (State) Descriptor() protoreflect.EnumDescriptor {
+	return file_drain_drain_proto_enumTypes[0].Descriptor()
+}
+
+func (State) Type() protoreflect.EnumType {
+	return &file_drain_drain_proto_enumTypes[0]
+}
+
+func (x State) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use State.Descriptor instead.
+func (State) EnumDescriptor() ([]byte, []int) {
+	return file_drain_drain_proto_rawDescGZIP(), []int{0}
+}
+
+type DrainRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *DrainRequest) Reset() {
+	*x = DrainRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_drain_drain_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DrainRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DrainRequest) ProtoMessage() {}
+
+func (x *DrainRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_drain_drain_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DrainRequest.ProtoReflect.Descriptor instead.
+func (*DrainRequest) Descriptor() ([]byte, []int) {
+	return file_drain_drain_proto_rawDescGZIP(), []int{0}
+}
+
+type DrainReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	State State `protobuf:"varint,1,opt,name=state,proto3,enum=drain.State" json:"state,omitempty"`
+}
+
+func (x *DrainReply) Reset() {
+	*x = DrainReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_drain_drain_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DrainReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DrainReply) ProtoMessage() {}
+
+func (x *DrainReply) ProtoReflect() protoreflect.Message {
+	mi := &file_drain_drain_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DrainReply.ProtoReflect.Descriptor instead.
+func (*DrainReply) Descriptor() ([]byte, []int) {
+	return file_drain_drain_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *DrainReply) GetState() State {
+	if x != nil {
+		return x.State
+	}
+	return State_SERVING
+}
+
+type StatusRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *StatusRequest) Reset() {
+	*x = StatusRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_drain_drain_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatusRequest) ProtoMessage() {}
+
+func (x *StatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_drain_drain_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatusRequest.ProtoReflect.Descriptor instead.
+func (*StatusRequest) Descriptor() ([]byte, []int) {
+	return file_drain_drain_proto_rawDescGZIP(), []int{2}
+}
+
+type StatusReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	State State `protobuf:"varint,1,opt,name=state,proto3,enum=drain.State" json:"state,omitempty"`
+	// The calls in flight.
+	Inflight int64 `protobuf:"varint,2,opt,name=inflight,proto3" json:"inflight,omitempty"`
+	// The NFs waited for, by gRPC address, and their last state known.
+	After map[string]State `protobuf:"bytes,3,rep,name=after,proto3" json:"after,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3,enum=drain.State"`
+}
+
+func (x *StatusReply) Reset() {
+	*x = StatusReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_drain_drain_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StatusReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatusReply) ProtoMessage() {}
+
+func (x *StatusReply) ProtoReflect() protoreflect.Message {
+	mi := &file_drain_drain_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatusReply.ProtoReflect.Descriptor instead.
+func (*StatusReply) Descriptor() ([]byte, []int) {
+	return file_drain_drain_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *StatusReply) GetState() State {
+	if x != nil {
+		return x.State
+	}
+	return State_SERVING
+}
+
+func (x *StatusReply) GetInflight() int64 {
+	if x != nil {
+		return x.Inflight
+	}
+	return 0
+}
+
+func (x *StatusReply) GetAfter() map[string]State {
+	if x != nil {
+		return x.After
+	}
+	return nil
+}
+
+var File_drain_drain_proto protoreflect.FileDescriptor
+
+var file_drain_drain_proto_rawDesc = []byte{
+	0x0a, 0x11, 0x64, 0x72, 0x61, 0x69, 0x6e, 0x2f, 0x64, 0x72, 0x61, 0x69, 0x6e, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x12, 0x05, 0x64, 0x72, 0x61, 0x69, 0x6e, 0x22, 0x0e, 0x0a, 0x0c, 0x44, 0x72,
+	0x61, 0x69, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x30, 0x0a, 0x0a, 0x44, 0x72,
+	0x61, 0x69, 0x6e, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x22, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x74,
+	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0c, 0x2e, 0x64, 0x72, 0x61, 0x69, 0x6e, 0x2e,
+	0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x22, 0x0f, 0x0a, 0x0d,
+	0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0xca, 0x01,
+	0x0a, 0x0b, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x22, 0x0a,
+	0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0c, 0x2e, 0x64,
+	0x72, 0x61, 0x69, 0x6e, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x05, 0x73, 0x74, 0x61, 0x74,
+	0x65, 0x12, 0x1a, 0x0a, 0x08, 0x69, 0x6e, 0x66, 0x6c, 0x69, 0x67, 0x68, 0x74, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x08, 0x69, 0x6e, 0x66, 0x6c, 0x69, 0x67, 0x68, 0x74, 0x12, 0x33, 0x0a,
+	0x05, 0x61, 0x66, 0x74, 0x65, 0x72, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x64,
+	0x72, 0x61, 0x69, 0x6e, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79,
+	0x2e, 0x41, 0x66, 0x74, 0x65, 0x72, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x05, 0x61, 0x66, 0x74,
+	0x65, 0x72, 0x1a, 0x46, 0x0a, 0x0a, 0x41, 0x66, 0x74, 0x65, 0x72, 0x45, 0x6e, 0x74, 0x72, 0x79,
+	0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b,
+	0x65, 0x79, 0x12, 0x22, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0e, 0x32, 0x0c, 0x2e, 0x64, 0x72, 0x61, 0x69, 0x6e, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52,
+	0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x2a, 0x3c, 0x0a, 0x05, 0x53, 0x74,
+	0x61, 0x74, 0x65, 0x12, 0x0b, 0x0a, 0x07, 0x53, 0x45, 0x52, 0x56, 0x49, 0x4e, 0x47, 0x10, 0x00,
+	0x12, 0x0b, 0x0a, 0x07, 0x57, 0x41, 0x49, 0x54, 0x49, 0x4e, 0x47, 0x10, 0x01, 0x12, 0x0c, 0x0a,
+	0x08, 0x44, 0x52, 0x41, 0x49, 0x4e, 0x49, 0x4e, 0x47, 0x10, 0x02, 0x12, 0x0b, 0x0a, 0x07, 0x44,
+	0x52, 0x41, 0x49, 0x4e, 0x45, 0x44, 0x10, 0x03, 0x32, 0x70, 0x0a, 0x05, 0x44, 0x72, 0x61, 0x69,
+	0x6e, 0x12, 0x31, 0x0a, 0x05, 0x44, 0x72, 0x61, 0x69, 0x6e, 0x12, 0x13, 0x2e, 0x64, 0x72, 0x61,
+	0x69, 0x6e, 0x2e, 0x44, 0x72, 0x61, 0x69, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x11, 0x2e, 0x64, 0x72, 0x61, 0x69, 0x6e, 0x2e, 0x44, 0x72, 0x61, 0x69, 0x6e, 0x52, 0x65, 0x70,
+	0x6c, 0x79, 0x22, 0x00, 0x12, 0x34, 0x0a, 0x06, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x14,
+	0x2e, 0x64, 0x72, 0x61, 0x69, 0x6e, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x12, 0x2e, 0x64, 0x72, 0x61, 0x69, 0x6e, 0x2e, 0x53, 0x74, 0x61,
+	0x74, 0x75, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x42, 0x35, 0x5a, 0x33, 0x67, 0x69,
+	0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6d, 0x69, 0x6b, 0x69, 0x2d, 0x74, 0x6e,
+	0x74, 0x2f, 0x73, 0x61, 0x35, 0x67, 0x2d, 0x67, 0x6f, 0x2d, 0x75, 0x73, 0x76, 0x63, 0x2d, 0x6b,
+	0x38, 0x73, 0x2f, 0x70, 0x62, 0x2f, 0x64, 0x72, 0x61, 0x69, 0x6e, 0x3b, 0x64, 0x72, 0x61, 0x69,
+	0x6e, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_drain_drain_proto_rawDescOnce sync.Once
+	file_drain_drain_proto_rawDescData = file_drain_drain_proto_rawDesc
+)
+
+func file_drain_drain_proto_rawDescGZIP() []byte {
+	file_drain_drain_proto_rawDescOnce.Do(func() {
+		file_drain_drain_proto_rawDescData = protoimpl.X.CompressGZIP(file_drain_drain_proto_rawDescData)
+	})
+	return file_drain_drain_proto_rawDescData
+}
+
+var file_drain_drain_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_drain_drain_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_drain_drain_proto_goTypes = []interface{}{
+	(State)(0),            // 0: drain.State
+	(*DrainRequest)(nil),  // 1: drain.DrainRequest
+	(*DrainReply)(nil),    // 2: drain.DrainReply
+	(*StatusRequest)(nil), // 3: drain.StatusRequest
+	(*StatusReply)(nil),   // 4: drain.StatusReply
+	nil,                   // 5: drain.StatusReply.AfterEntry
+}
+var file_drain_drain_proto_depIdxs = []int32{
+	0, // 0: drain.DrainReply.state:type_name -> drain.State
+	0, // 1: drain.StatusReply.state:type_name -> drain.State
+	5, // 2: drain.StatusReply.after:type_name -> drain.StatusReply.AfterEntry
+	0, // 3: drain.StatusReply.AfterEntry.value:type_name -> drain.State
+	1, // 4: drain.Drain.Drain:input_type -> drain.DrainRequest
+	3, // 5: drain.Drain.Status:input_type -> drain.StatusRequest
+	2, // 6: drain.Drain.Drain:output_type -> drain.DrainReply
+	4, // 7: drain.Drain.Status:output_type -> drain.StatusReply
+	6, // [6:8] is the sub-list for method output_type
+	4, // [4:6] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_drain_drain_proto_init() }
+func file_drain_drain_proto_init() {
+	if File_drain_drain_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_drain_drain_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DrainRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_drain_drain_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DrainReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_drain_drain_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StatusRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_drain_drain_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StatusReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_drain_drain_proto_rawDesc,
+			NumEnums:      1,
+			NumMessages:   5,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_drain_drain_proto_goTypes,
+		DependencyIndexes: file_drain_drain_proto_depIdxs,
+		EnumInfos:         file_drain_drain_proto_enumTypes,
+		MessageInfos:      file_drain_drain_proto_msgTypes,
+	}.Build()
+	File_drain_drain_proto = out.File
+	file_drain_drain_proto_rawDesc = nil
+	file_drain_drain_proto_goTypes = nil
+	file_drain_drain_proto_depIdxs = nil
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConnInterface
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion6
+
+// DrainClient is the client API for Drain service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type DrainClient interface {
+	// Drain starts the drain and returns once the NF is drained, or the
+	// drain timed out.
+	Drain(ctx context.Context, in *DrainRequest, opts ...grpc.CallOption) (*DrainReply, error)
+	Status(ctx context.Context, in *StatusRequest, opts ...grpc.CallOption) (*StatusReply, error)
+}
+
+type drainClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewDrainClient(cc grpc.ClientConnInterface) DrainClient {
+	return &drainClient{cc}
+}
+
+func (c *drainClient) Drain(ctx context.Context, in *DrainRequest, opts ...grpc.CallOption) (*DrainReply, error) {
+	out := new(DrainReply)
+	err := c.cc.Invoke(ctx, "/drain.Drain/Drain", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *drainClient) Status(ctx context.Context, in *StatusRequest, opts ...grpc.CallOption) (*StatusReply, error) {
+	out := new(StatusReply)
+	err := c.cc.Invoke(ctx, "/drain.Drain/Status", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DrainServer is the server API for Drain service.
+type DrainServer interface {
+	// Drain starts the drain and returns once the NF is drained, or the
+	// drain timed out.
+	Drain(context.Context, *DrainRequest) (*DrainReply, error)
+	Status(context.Context, *StatusRequest) (*StatusReply, error)
+}
+
+// UnimplementedDrainServer can be embedded to have forward compatible implementations.
+type UnimplementedDrainServer struct {
+}
+
+func (*UnimplementedDrainServer) Drain(context.Context, *DrainRequest) (*DrainReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Drain not implemented")
+}
+func (*UnimplementedDrainServer) Status(context.Context, *StatusRequest) (*StatusReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Status not implemented")
+}
+
+func RegisterDrainServer(s *grpc.Server, srv DrainServer) {
+	s.RegisterService(&_Drain_serviceDesc, srv)
+}
+
+func _Drain_Drain_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DrainRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DrainServer).Drain(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/drain.Drain/Drain",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DrainServer).Drain(ctx, req.(*DrainRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Drain_Status_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DrainServer).Status(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/drain.Drain/Status",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DrainServer).Status(ctx, req.(*StatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Drain_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "drain.Drain",
+	HandlerType: (*DrainServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Drain",
+			Handler:    _Drain_Drain_Handler,
+		},
+		{
+			MethodName: "Status",
+			Handler:    _Drain_Status_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "drain/drain.proto",
+}
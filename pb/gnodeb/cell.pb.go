@@ -0,0 +1,1233 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.24.0
+// 	protoc        v3.12.2
+// source: gnodeb/cell.proto
+
+// The API of the cells of a gNB, served next to the Preamblesvc service.
+
+package gnodeb
+
+import (
+	context "context"
+	proto "github.com/golang/protobuf/proto"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// This is a compile-time assertion that a sufficiently up-to-date version
+// of the legacy proto package is being used.
+const _ = proto.ProtoPackageIsVersion4
+
+type Cell struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Nci          uint64 `protobuf:"varint,1,opt,name=nci,proto3" json:"nci,omitempty"`
+	Pci          uint32 `protobuf:"varint,2,opt,name=pci,proto3" json:"pci,omitempty"`
+	Tac          uint32 `protobuf:"varint,3,opt,name=tac,proto3" json:"tac,omitempty"`
+	Band         uint32 `protobuf:"varint,4,opt,name=band,proto3" json:"band,omitempty"`
+	BandwidthMhz uint32 `protobuf:"varint,5,opt,name=bandwidth_mhz,json=bandwidthMhz,proto3" json:"bandwidth_mhz,omitempty"`
+	ScsKhz       uint32 `protobuf:"varint,6,opt,name=scs_khz,json=scsKhz,proto3" json:"scs_khz,omitempty"`
+	Enabled      bool   `protobuf:"varint,7,opt,name=enabled,proto3" json:"enabled,omitempty"`
+}
+
+func (x *Cell) Reset() {
+	*x = Cell{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gnodeb_cell_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Cell) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Cell) ProtoMessage() {}
+
+func (x *Cell) ProtoReflect() protoreflect.Message {
+	mi := &file_gnodeb_cell_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Cell.ProtoReflect.Descriptor instead.
+func (*Cell) Descriptor() ([]byte, []int) {
+	return file_gnodeb_cell_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Cell) GetNci() uint64 {
+	if x != nil {
+		return x.Nci
+	}
+	return 0
+}
+
+func (x *Cell) GetPci() uint32 {
+	if x != nil {
+		return x.Pci
+	}
+	return 0
+}
+
+func (x *Cell) GetTac() uint32 {
+	if x != nil {
+		return x.Tac
+	}
+	return 0
+}
+
+func (x *Cell) GetBand() uint32 {
+	if x != nil {
+		return x.Band
+	}
+	return 0
+}
+
+func (x *Cell) GetBandwidthMhz() uint32 {
+	if x != nil {
+		return x.BandwidthMhz
+	}
+	return 0
+}
+
+func (x *Cell) GetScsKhz() uint32 {
+	if x != nil {
+		return x.ScsKhz
+	}
+	return 0
+}
+
+func (x *Cell) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+// The use of the physical resource blocks of a cell.
+type Usage struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Prbs     int32 `protobuf:"varint,1,opt,name=prbs,proto3" json:"prbs,omitempty"`
+	Used     int32 `protobuf:"varint,2,opt,name=used,proto3" json:"used,omitempty"`
+	Peak     int32 `protobuf:"varint,3,opt,name=peak,proto3" json:"peak,omitempty"`
+	Admitted int64 `protobuf:"varint,4,opt,name=admitted,proto3" json:"admitted,omitempty"`
+	Rejected int64 `protobuf:"varint,5,opt,name=rejected,proto3" json:"rejected,omitempty"`
+}
+
+func (x *Usage) Reset() {
+	*x = Usage{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gnodeb_cell_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Usage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Usage) ProtoMessage() {}
+
+func (x *Usage) ProtoReflect() protoreflect.Message {
+	mi := &file_gnodeb_cell_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Usage.ProtoReflect.Descriptor instead.
+func (*Usage) Descriptor() ([]byte, []int) {
+	return file_gnodeb_cell_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Usage) GetPrbs() int32 {
+	if x != nil {
+		return x.Prbs
+	}
+	return 0
+}
+
+func (x *Usage) GetUsed() int32 {
+	if x != nil {
+		return x.Used
+	}
+	return 0
+}
+
+func (x *Usage) GetPeak() int32 {
+	if x != nil {
+		return x.Peak
+	}
+	return 0
+}
+
+func (x *Usage) GetAdmitted() int64 {
+	if x != nil {
+		return x.Admitted
+	}
+	return 0
+}
+
+func (x *Usage) GetRejected() int64 {
+	if x != nil {
+		return x.Rejected
+	}
+	return 0
+}
+
+type CellStatus struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Cell  *Cell  `protobuf:"bytes,1,opt,name=cell,proto3" json:"cell,omitempty"`
+	Usage *Usage `protobuf:"bytes,2,opt,name=usage,proto3" json:"usage,omitempty"`
+}
+
+func (x *CellStatus) Reset() {
+	*x = CellStatus{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gnodeb_cell_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CellStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CellStatus) ProtoMessage() {}
+
+func (x *CellStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_gnodeb_cell_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CellStatus.ProtoReflect.Descriptor instead.
+func (*CellStatus) Descriptor() ([]byte, []int) {
+	return file_gnodeb_cell_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *CellStatus) GetCell() *Cell {
+	if x != nil {
+		return x.Cell
+	}
+	return nil
+}
+
+func (x *CellStatus) GetUsage() *Usage {
+	if x != nil {
+		return x.Usage
+	}
+	return nil
+}
+
+type AddCellRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Cell *Cell `protobuf:"bytes,1,opt,name=cell,proto3" json:"cell,omitempty"`
+}
+
+func (x *AddCellRequest) Reset() {
+	*x = AddCellRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gnodeb_cell_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AddCellRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddCellRequest) ProtoMessage() {}
+
+func (x *AddCellRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gnodeb_cell_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddCellRequest.ProtoReflect.Descriptor instead.
+func (*AddCellRequest) Descriptor() ([]byte, []int) {
+	return file_gnodeb_cell_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *AddCellRequest) GetCell() *Cell {
+	if x != nil {
+		return x.Cell
+	}
+	return nil
+}
+
+type AddCellReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *AddCellReply) Reset() {
+	*x = AddCellReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gnodeb_cell_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AddCellReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddCellReply) ProtoMessage() {}
+
+func (x *AddCellReply) ProtoReflect() protoreflect.Message {
+	mi := &file_gnodeb_cell_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddCellReply.ProtoReflect.Descriptor instead.
+func (*AddCellReply) Descriptor() ([]byte, []int) {
+	return file_gnodeb_cell_proto_rawDescGZIP(), []int{4}
+}
+
+type UpdateCellRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Cell *Cell `protobuf:"bytes,1,opt,name=cell,proto3" json:"cell,omitempty"`
+}
+
+func (x *UpdateCellRequest) Reset() {
+	*x = UpdateCellRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gnodeb_cell_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UpdateCellRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateCellRequest) ProtoMessage() {}
+
+func (x *UpdateCellRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gnodeb_cell_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateCellRequest.ProtoReflect.Descriptor instead.
+func (*UpdateCellRequest) Descriptor() ([]byte, []int) {
+	return file_gnodeb_cell_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *UpdateCellRequest) GetCell() *Cell {
+	if x != nil {
+		return x.Cell
+	}
+	return nil
+}
+
+type UpdateCellReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *UpdateCellReply) Reset() {
+	*x = UpdateCellReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gnodeb_cell_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UpdateCellReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateCellReply) ProtoMessage() {}
+
+func (x *UpdateCellReply) ProtoReflect() protoreflect.Message {
+	mi := &file_gnodeb_cell_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateCellReply.ProtoReflect.Descriptor instead.
+func (*UpdateCellReply) Descriptor() ([]byte, []int) {
+	return file_gnodeb_cell_proto_rawDescGZIP(), []int{6}
+}
+
+type SetCellEnabledRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Nci     uint64 `protobuf:"varint,1,opt,name=nci,proto3" json:"nci,omitempty"`
+	Enabled bool   `protobuf:"varint,2,opt,name=enabled,proto3" json:"enabled,omitempty"`
+}
+
+func (x *SetCellEnabledRequest) Reset() {
+	*x = SetCellEnabledRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gnodeb_cell_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SetCellEnabledRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetCellEnabledRequest) ProtoMessage() {}
+
+func (x *SetCellEnabledRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gnodeb_cell_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetCellEnabledRequest.ProtoReflect.Descriptor instead.
+func (*SetCellEnabledRequest) Descriptor() ([]byte, []int) {
+	return file_gnodeb_cell_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *SetCellEnabledRequest) GetNci() uint64 {
+	if x != nil {
+		return x.Nci
+	}
+	return 0
+}
+
+func (x *SetCellEnabledRequest) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+type SetCellEnabledReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *SetCellEnabledReply) Reset() {
+	*x = SetCellEnabledReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gnodeb_cell_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SetCellEnabledReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetCellEnabledReply) ProtoMessage() {}
+
+func (x *SetCellEnabledReply) ProtoReflect() protoreflect.Message {
+	mi := &file_gnodeb_cell_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetCellEnabledReply.ProtoReflect.Descriptor instead.
+func (*SetCellEnabledReply) Descriptor() ([]byte, []int) {
+	return file_gnodeb_cell_proto_rawDescGZIP(), []int{8}
+}
+
+type RemoveCellRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Nci uint64 `protobuf:"varint,1,opt,name=nci,proto3" json:"nci,omitempty"`
+}
+
+func (x *RemoveCellRequest) Reset() {
+	*x = RemoveCellRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gnodeb_cell_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RemoveCellRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveCellRequest) ProtoMessage() {}
+
+func (x *RemoveCellRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gnodeb_cell_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveCellRequest.ProtoReflect.Descriptor instead.
+func (*RemoveCellRequest) Descriptor() ([]byte, []int) {
+	return file_gnodeb_cell_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *RemoveCellRequest) GetNci() uint64 {
+	if x != nil {
+		return x.Nci
+	}
+	return 0
+}
+
+type RemoveCellReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *RemoveCellReply) Reset() {
+	*x = RemoveCellReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gnodeb_cell_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RemoveCellReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveCellReply) ProtoMessage() {}
+
+func (x *RemoveCellReply) ProtoReflect() protoreflect.Message {
+	mi := &file_gnodeb_cell_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveCellReply.ProtoReflect.Descriptor instead.
+func (*RemoveCellReply) Descriptor() ([]byte, []int) {
+	return file_gnodeb_cell_proto_rawDescGZIP(), []int{10}
+}
+
+type ListCellsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *ListCellsRequest) Reset() {
+	*x = ListCellsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gnodeb_cell_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListCellsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCellsRequest) ProtoMessage() {}
+
+func (x *ListCellsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gnodeb_cell_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListCellsRequest.ProtoReflect.Descriptor instead.
+func (*ListCellsRequest) Descriptor() ([]byte, []int) {
+	return file_gnodeb_cell_proto_rawDescGZIP(), []int{11}
+}
+
+type ListCellsReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Cells []*CellStatus `protobuf:"bytes,1,rep,name=cells,proto3" json:"cells,omitempty"`
+}
+
+func (x *ListCellsReply) Reset() {
+	*x = ListCellsReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gnodeb_cell_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListCellsReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCellsReply) ProtoMessage() {}
+
+func (x *ListCellsReply) ProtoReflect() protoreflect.Message {
+	mi := &file_gnodeb_cell_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListCellsReply.ProtoReflect.Descriptor instead.
+func (*ListCellsReply) Descriptor() ([]byte, []int) {
+	return file_gnodeb_cell_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ListCellsReply) GetCells() []*CellStatus {
+	if x != nil {
+		return x.Cells
+	}
+	return nil
+}
+
+var File_gnodeb_cell_proto protoreflect.FileDescriptor
+
+var file_gnodeb_cell_proto_rawDesc = []byte{
+	0x0a, 0x11, 0x67, 0x6e, 0x6f, 0x64, 0x65, 0x62, 0x2f, 0x63, 0x65, 0x6c, 0x6c, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x12, 0x06, 0x67, 0x6e, 0x6f, 0x64, 0x65, 0x62, 0x22, 0xa8, 0x01, 0x0a, 0x04,
+	0x43, 0x65, 0x6c, 0x6c, 0x12, 0x10, 0x0a, 0x03, 0x6e, 0x63, 0x69, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x03, 0x6e, 0x63, 0x69, 0x12, 0x10, 0x0a, 0x03, 0x70, 0x63, 0x69, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x03, 0x70, 0x63, 0x69, 0x12, 0x10, 0x0a, 0x03, 0x74, 0x61, 0x63, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x03, 0x74, 0x61, 0x63, 0x12, 0x12, 0x0a, 0x04, 0x62, 0x61,
+	0x6e, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x04, 0x62, 0x61, 0x6e, 0x64, 0x12, 0x23,
+	0x0a, 0x0d, 0x62, 0x61, 0x6e, 0x64, 0x77, 0x69, 0x64, 0x74, 0x68, 0x5f, 0x6d, 0x68, 0x7a, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0c, 0x62, 0x61, 0x6e, 0x64, 0x77, 0x69, 0x64, 0x74, 0x68,
+	0x4d, 0x68, 0x7a, 0x12, 0x17, 0x0a, 0x07, 0x73, 0x63, 0x73, 0x5f, 0x6b, 0x68, 0x7a, 0x18, 0x06,
+	0x20, 0x01, 0x28, 0x0d, 0x52, 0x06, 0x73, 0x63, 0x73, 0x4b, 0x68, 0x7a, 0x12, 0x18, 0x0a, 0x07,
+	0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x64, 0x18, 0x07, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x65,
+	0x6e, 0x61, 0x62, 0x6c, 0x65, 0x64, 0x22, 0x7b, 0x0a, 0x05, 0x55, 0x73, 0x61, 0x67, 0x65, 0x12,
+	0x12, 0x0a, 0x04, 0x70, 0x72, 0x62, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x70,
+	0x72, 0x62, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x75, 0x73, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x04, 0x75, 0x73, 0x65, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x65, 0x61, 0x6b, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x70, 0x65, 0x61, 0x6b, 0x12, 0x1a, 0x0a, 0x08, 0x61,
+	0x64, 0x6d, 0x69, 0x74, 0x74, 0x65, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x61,
+	0x64, 0x6d, 0x69, 0x74, 0x74, 0x65, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x6a, 0x65, 0x63,
+	0x74, 0x65, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x72, 0x65, 0x6a, 0x65, 0x63,
+	0x74, 0x65, 0x64, 0x22, 0x53, 0x0a, 0x0a, 0x43, 0x65, 0x6c, 0x6c, 0x53, 0x74, 0x61, 0x74, 0x75,
+	0x73, 0x12, 0x20, 0x0a, 0x04, 0x63, 0x65, 0x6c, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x0c, 0x2e, 0x67, 0x6e, 0x6f, 0x64, 0x65, 0x62, 0x2e, 0x43, 0x65, 0x6c, 0x6c, 0x52, 0x04, 0x63,
+	0x65, 0x6c, 0x6c, 0x12, 0x23, 0x0a, 0x05, 0x75, 0x73, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x67, 0x6e, 0x6f, 0x64, 0x65, 0x62, 0x2e, 0x55, 0x73, 0x61, 0x67,
+	0x65, 0x52, 0x05, 0x75, 0x73, 0x61, 0x67, 0x65, 0x22, 0x32, 0x0a, 0x0e, 0x41, 0x64, 0x64, 0x43,
+	0x65, 0x6c, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x20, 0x0a, 0x04, 0x63, 0x65,
+	0x6c, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x67, 0x6e, 0x6f, 0x64, 0x65,
+	0x62, 0x2e, 0x43, 0x65, 0x6c, 0x6c, 0x52, 0x04, 0x63, 0x65, 0x6c, 0x6c, 0x22, 0x0e, 0x0a, 0x0c,
+	0x41, 0x64, 0x64, 0x43, 0x65, 0x6c, 0x6c, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x35, 0x0a, 0x11,
+	0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x43, 0x65, 0x6c, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x20, 0x0a, 0x04, 0x63, 0x65, 0x6c, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x0c, 0x2e, 0x67, 0x6e, 0x6f, 0x64, 0x65, 0x62, 0x2e, 0x43, 0x65, 0x6c, 0x6c, 0x52, 0x04, 0x63,
+	0x65, 0x6c, 0x6c, 0x22, 0x11, 0x0a, 0x0f, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x43, 0x65, 0x6c,
+	0x6c, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x43, 0x0a, 0x15, 0x53, 0x65, 0x74, 0x43, 0x65, 0x6c,
+	0x6c, 0x45, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x10, 0x0a, 0x03, 0x6e, 0x63, 0x69, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x03, 0x6e, 0x63,
+	0x69, 0x12, 0x18, 0x0a, 0x07, 0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x07, 0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x64, 0x22, 0x15, 0x0a, 0x13, 0x53,
+	0x65, 0x74, 0x43, 0x65, 0x6c, 0x6c, 0x45, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x64, 0x52, 0x65, 0x70,
+	0x6c, 0x79, 0x22, 0x25, 0x0a, 0x11, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x43, 0x65, 0x6c, 0x6c,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x6e, 0x63, 0x69, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x04, 0x52, 0x03, 0x6e, 0x63, 0x69, 0x22, 0x11, 0x0a, 0x0f, 0x52, 0x65, 0x6d,
+	0x6f, 0x76, 0x65, 0x43, 0x65, 0x6c, 0x6c, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x12, 0x0a, 0x10,
+	0x4c, 0x69, 0x73, 0x74, 0x43, 0x65, 0x6c, 0x6c, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x22, 0x3a, 0x0a, 0x0e, 0x4c, 0x69, 0x73, 0x74, 0x43, 0x65, 0x6c, 0x6c, 0x73, 0x52, 0x65, 0x70,
+	0x6c, 0x79, 0x12, 0x28, 0x0a, 0x05, 0x63, 0x65, 0x6c, 0x6c, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x12, 0x2e, 0x67, 0x6e, 0x6f, 0x64, 0x65, 0x62, 0x2e, 0x43, 0x65, 0x6c, 0x6c, 0x53,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x05, 0x63, 0x65, 0x6c, 0x6c, 0x73, 0x32, 0xdb, 0x02, 0x0a,
+	0x05, 0x43, 0x65, 0x6c, 0x6c, 0x73, 0x12, 0x39, 0x0a, 0x07, 0x41, 0x64, 0x64, 0x43, 0x65, 0x6c,
+	0x6c, 0x12, 0x16, 0x2e, 0x67, 0x6e, 0x6f, 0x64, 0x65, 0x62, 0x2e, 0x41, 0x64, 0x64, 0x43, 0x65,
+	0x6c, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x14, 0x2e, 0x67, 0x6e, 0x6f, 0x64,
+	0x65, 0x62, 0x2e, 0x41, 0x64, 0x64, 0x43, 0x65, 0x6c, 0x6c, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22,
+	0x00, 0x12, 0x42, 0x0a, 0x0a, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x43, 0x65, 0x6c, 0x6c, 0x12,
+	0x19, 0x2e, 0x67, 0x6e, 0x6f, 0x64, 0x65, 0x62, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x43,
+	0x65, 0x6c, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x17, 0x2e, 0x67, 0x6e, 0x6f,
+	0x64, 0x65, 0x62, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x43, 0x65, 0x6c, 0x6c, 0x52, 0x65,
+	0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x4e, 0x0a, 0x0e, 0x53, 0x65, 0x74, 0x43, 0x65, 0x6c, 0x6c,
+	0x45, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x64, 0x12, 0x1d, 0x2e, 0x67, 0x6e, 0x6f, 0x64, 0x65, 0x62,
+	0x2e, 0x53, 0x65, 0x74, 0x43, 0x65, 0x6c, 0x6c, 0x45, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x64, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x67, 0x6e, 0x6f, 0x64, 0x65, 0x62, 0x2e,
+	0x53, 0x65, 0x74, 0x43, 0x65, 0x6c, 0x6c, 0x45, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x64, 0x52, 0x65,
+	0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x42, 0x0a, 0x0a, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x43,
+	0x65, 0x6c, 0x6c, 0x12, 0x19, 0x2e, 0x67, 0x6e, 0x6f, 0x64, 0x65, 0x62, 0x2e, 0x52, 0x65, 0x6d,
+	0x6f, 0x76, 0x65, 0x43, 0x65, 0x6c, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x17,
+	0x2e, 0x67, 0x6e, 0x6f, 0x64, 0x65, 0x62, 0x2e, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x43, 0x65,
+	0x6c, 0x6c, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x3f, 0x0a, 0x09, 0x4c, 0x69, 0x73,
+	0x74, 0x43, 0x65, 0x6c, 0x6c, 0x73, 0x12, 0x18, 0x2e, 0x67, 0x6e, 0x6f, 0x64, 0x65, 0x62, 0x2e,
+	0x4c, 0x69, 0x73, 0x74, 0x43, 0x65, 0x6c, 0x6c, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x16, 0x2e, 0x67, 0x6e, 0x6f, 0x64, 0x65, 0x62, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x43, 0x65,
+	0x6c, 0x6c, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x42, 0x37, 0x5a, 0x35, 0x67, 0x69,
+	0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6d, 0x69, 0x6b, 0x69, 0x2d, 0x74, 0x6e,
+	0x74, 0x2f, 0x73, 0x61, 0x35, 0x67, 0x2d, 0x67, 0x6f, 0x2d, 0x75, 0x73, 0x76, 0x63, 0x2d, 0x6b,
+	0x38, 0x73, 0x2f, 0x70, 0x62, 0x2f, 0x67, 0x6e, 0x6f, 0x64, 0x65, 0x62, 0x3b, 0x67, 0x6e, 0x6f,
+	0x64, 0x65, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_gnodeb_cell_proto_rawDescOnce sync.Once
+	file_gnodeb_cell_proto_rawDescData = file_gnodeb_cell_proto_rawDesc
+)
+
+func file_gnodeb_cell_proto_rawDescGZIP() []byte {
+	file_gnodeb_cell_proto_rawDescOnce.Do(func() {
+		file_gnodeb_cell_proto_rawDescData = protoimpl.X.CompressGZIP(file_gnodeb_cell_proto_rawDescData)
+	})
+	return file_gnodeb_cell_proto_rawDescData
+}
+
+var file_gnodeb_cell_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
+var file_gnodeb_cell_proto_goTypes = []interface{}{
+	(*Cell)(nil),                  // 0: gnodeb.Cell
+	(*Usage)(nil),                 // 1: gnodeb.Usage
+	(*CellStatus)(nil),            // 2: gnodeb.CellStatus
+	(*AddCellRequest)(nil),        // 3: gnodeb.AddCellRequest
+	(*AddCellReply)(nil),          // 4: gnodeb.AddCellReply
+	(*UpdateCellRequest)(nil),     // 5: gnodeb.UpdateCellRequest
+	(*UpdateCellReply)(nil),       // 6: gnodeb.UpdateCellReply
+	(*SetCellEnabledRequest)(nil), // 7: gnodeb.SetCellEnabledRequest
+	(*SetCellEnabledReply)(nil),   // 8: gnodeb.SetCellEnabledReply
+	(*RemoveCellRequest)(nil),     // 9: gnodeb.RemoveCellRequest
+	(*RemoveCellReply)(nil),       // 10: gnodeb.RemoveCellReply
+	(*ListCellsRequest)(nil),      // 11: gnodeb.ListCellsRequest
+	(*ListCellsReply)(nil),        // 12: gnodeb.ListCellsReply
+}
+var file_gnodeb_cell_proto_depIdxs = []int32{
+	0,  // 0: gnodeb.CellStatus.cell:type_name -> gnodeb.Cell
+	1,  // 1: gnodeb.CellStatus.usage:type_name -> gnodeb.Usage
+	0,  // 2: gnodeb.AddCellRequest.cell:type_name -> gnodeb.Cell
+	0,  // 3: gnodeb.UpdateCellRequest.cell:type_name -> gnodeb.Cell
+	2,  // 4: gnodeb.ListCellsReply.cells:type_name -> gnodeb.CellStatus
+	3,  // 5: gnodeb.Cells.AddCell:input_type -> gnodeb.AddCellRequest
+	5,  // 6: gnodeb.Cells.UpdateCell:input_type -> gnodeb.UpdateCellRequest
+	7,  // 7: gnodeb.Cells.SetCellEnabled:input_type -> gnodeb.SetCellEnabledRequest
+	9,  // 8: gnodeb.Cells.RemoveCell:input_type -> gnodeb.RemoveCellRequest
+	11, // 9: gnodeb.Cells.ListCells:input_type -> gnodeb.ListCellsRequest
+	4,  // 10: gnodeb.Cells.AddCell:output_type -> gnodeb.AddCellReply
+	6,  // 11: gnodeb.Cells.UpdateCell:output_type -> gnodeb.UpdateCellReply
+	8,  // 12: gnodeb.Cells.SetCellEnabled:output_type -> gnodeb.SetCellEnabledReply
+	10, // 13: gnodeb.Cells.RemoveCell:output_type -> gnodeb.RemoveCellReply
+	12, // 14: gnodeb.Cells.ListCells:output_type -> gnodeb.ListCellsReply
+	10, // [10:15] is the sub-list for method output_type
+	5,  // [5:10] is the sub-list for method input_type
+	5,  // [5:5] is the sub-list for extension type_name
+	5,  // [5:5] is the sub-list for extension extendee
+	0,  // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_gnodeb_cell_proto_init() }
+func file_gnodeb_cell_proto_init() {
+	if File_gnodeb_cell_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_gnodeb_cell_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Cell); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gnodeb_cell_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Usage); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gnodeb_cell_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CellStatus); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gnodeb_cell_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AddCellRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gnodeb_cell_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AddCellReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gnodeb_cell_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UpdateCellRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gnodeb_cell_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UpdateCellReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gnodeb_cell_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SetCellEnabledRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gnodeb_cell_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SetCellEnabledReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gnodeb_cell_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RemoveCellRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gnodeb_cell_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RemoveCellReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gnodeb_cell_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListCellsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gnodeb_cell_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListCellsReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_gnodeb_cell_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   13,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_gnodeb_cell_proto_goTypes,
+		DependencyIndexes: file_gnodeb_cell_proto_depIdxs,
+		MessageInfos:      file_gnodeb_cell_proto_msgTypes,
+	}.Build()
+	File_gnodeb_cell_proto = out.File
+	file_gnodeb_cell_proto_rawDesc = nil
+	file_gnodeb_cell_proto_goTypes = nil
+	file_gnodeb_cell_proto_depIdxs = nil
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConnInterface
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion6
+
+// CellsClient is the client API for Cells service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type CellsClient interface {
+	AddCell(ctx context.Context, in *AddCellRequest, opts ...grpc.CallOption) (*AddCellReply, error)
+	UpdateCell(ctx context.Context, in *UpdateCellRequest, opts ...grpc.CallOption) (*UpdateCellReply, error)
+	SetCellEnabled(ctx context.Context, in *SetCellEnabledRequest, opts ...grpc.CallOption) (*SetCellEnabledReply, error)
+	RemoveCell(ctx context.Context, in *RemoveCellRequest, opts ...grpc.CallOption) (*RemoveCellReply, error)
+	ListCells(ctx context.Context, in *ListCellsRequest, opts ...grpc.CallOption) (*ListCellsReply, error)
+}
+
+type cellsClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCellsClient(cc grpc.ClientConnInterface) CellsClient {
+	return &cellsClient{cc}
+}
+
+func (c *cellsClient) AddCell(ctx context.Context, in *AddCellRequest, opts ...grpc.CallOption) (*AddCellReply, error) {
+	out := new(AddCellReply)
+	err := c.cc.Invoke(ctx, "/gnodeb.Cells/AddCell", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cellsClient) UpdateCell(ctx context.Context, in *UpdateCellRequest, opts ...grpc.CallOption) (*UpdateCellReply, error) {
+	out := new(UpdateCellReply)
+	err := c.cc.Invoke(ctx, "/gnodeb.Cells/UpdateCell", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cellsClient) SetCellEnabled(ctx context.Context, in *SetCellEnabledRequest, opts ...grpc.CallOption) (*SetCellEnabledReply, error) {
+	out := new(SetCellEnabledReply)
+	err := c.cc.Invoke(ctx, "/gnodeb.Cells/SetCellEnabled", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cellsClient) RemoveCell(ctx context.Context, in *RemoveCellRequest, opts ...grpc.CallOption) (*RemoveCellReply, error) {
+	out := new(RemoveCellReply)
+	err := c.cc.Invoke(ctx, "/gnodeb.Cells/RemoveCell", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *cellsClient) ListCells(ctx context.Context, in *ListCellsRequest, opts ...grpc.CallOption) (*ListCellsReply, error) {
+	out := new(ListCellsReply)
+	err := c.cc.Invoke(ctx, "/gnodeb.Cells/ListCells", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CellsServer is the server API for Cells service.
+type CellsServer interface {
+	AddCell(context.Context, *AddCellRequest) (*AddCellReply, error)
+	UpdateCell(context.Context, *UpdateCellRequest) (*UpdateCellReply, error)
+	SetCellEnabled(context.Context, *SetCellEnabledRequest) (*SetCellEnabledReply, error)
+	RemoveCell(context.Context, *RemoveCellRequest) (*RemoveCellReply, error)
+	ListCells(context.Context, *ListCellsRequest) (*ListCellsReply, error)
+}
+
+// UnimplementedCellsServer can be embedded to have forward compatible implementations.
+type UnimplementedCellsServer struct {
+}
+
+func (*UnimplementedCellsServer) AddCell(context.Context, *AddCellRequest) (*AddCellReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddCell not implemented")
+}
+func (*UnimplementedCellsServer) UpdateCell(context.Context, *UpdateCellRequest) (*UpdateCellReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateCell not implemented")
+}
+func (*UnimplementedCellsServer) SetCellEnabled(context.Context, *SetCellEnabledRequest) (*SetCellEnabledReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetCellEnabled not implemented")
+}
+func (*UnimplementedCellsServer) RemoveCell(context.Context, *RemoveCellRequest) (*RemoveCellReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveCell not implemented")
+}
+func (*UnimplementedCellsServer) ListCells(context.Context, *ListCellsRequest) (*ListCellsReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListCells not implemented")
+}
+
+func RegisterCellsServer(s *grpc.Server, srv CellsServer) {
+	s.RegisterService(&_Cells_serviceDesc, srv)
+}
+
+func _Cells_AddCell_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddCellRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CellsServer).AddCell(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gnodeb.Cells/AddCell",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CellsServer).AddCell(ctx, req.(*AddCellRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Cells_UpdateCell_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateCellRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CellsServer).UpdateCell(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gnodeb.Cells/UpdateCell",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CellsServer).UpdateCell(ctx, req.(*UpdateCellRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Cells_SetCellEnabled_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetCellEnabledRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CellsServer).SetCellEnabled(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gnodeb.Cells/SetCellEnabled",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CellsServer).SetCellEnabled(ctx, req.(*SetCellEnabledRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Cells_RemoveCell_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveCellRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CellsServer).RemoveCell(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gnodeb.Cells/RemoveCell",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CellsServer).RemoveCell(ctx, req.(*RemoveCellRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Cells_ListCells_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListCellsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CellsServer).ListCells(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gnodeb.Cells/ListCells",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CellsServer).ListCells(ctx, req.(*ListCellsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Cells_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "gnodeb.Cells",
+	HandlerType: (*CellsServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "AddCell",
+			Handler:    _Cells_AddCell_Handler,
+		},
+		{
+			MethodName: "UpdateCell",
+			Handler:    _Cells_UpdateCell_Handler,
+		},
+		{
+			MethodName: "SetCellEnabled",
+			Handler:    _Cells_SetCellEnabled_Handler,
+		},
+		{
+			MethodName: "RemoveCell",
+			Handler:    _Cells_RemoveCell_Handler,
+		},
+		{
+			MethodName: "ListCells",
+			Handler:    _Cells_ListCells_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "gnodeb/cell.proto",
+}
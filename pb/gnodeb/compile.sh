@@ -0,0 +1,7 @@
+#!/usr/bin/env sh
+
+# See ../preamblesvc/compile.sh for the tools. The files are compiled from pb/
+# so that their names are gnodeb/cell.proto, gnodeb/f1ap.proto,
+# gnodeb/xnap.proto and gnodeb/e2.proto in the registry.
+
+cd .. && protoc gnodeb/cell.proto gnodeb/f1ap.proto gnodeb/xnap.proto gnodeb/e2.proto --go_out=plugins=grpc,paths=source_relative:.
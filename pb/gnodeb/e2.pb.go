@@ -0,0 +1,872 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.24.0
+// 	protoc        v3.12.2
+// source: gnodeb/e2.proto
+
+// An E2-like interface (O-RAN E2AP, E2SM-KPM) between an E2 node of the gNB
+// and a near-RT RIC, rendered over gRPC.
+
+package gnodeb
+
+import (
+	context "context"
+	proto "github.com/golang/protobuf/proto"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// This is a compile-time assertion that a sufficiently up-to-date version
+// of the legacy proto package is being used.
+const _ = proto.ProtoPackageIsVersion4
+
+type E2NodeMessage struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Message:
+	//	*E2NodeMessage_Setup
+	//	*E2NodeMessage_Indication
+	//	*E2NodeMessage_PolicyResult
+	Message isE2NodeMessage_Message `protobuf_oneof:"message"`
+}
+
+func (x *E2NodeMessage) Reset() {
+	*x = E2NodeMessage{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gnodeb_e2_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *E2NodeMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*E2NodeMessage) ProtoMessage() {}
+
+func (x *E2NodeMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_gnodeb_e2_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use E2NodeMessage.ProtoReflect.Descriptor instead.
+func (*E2NodeMessage) Descriptor() ([]byte, []int) {
+	return file_gnodeb_e2_proto_rawDescGZIP(), []int{0}
+}
+
+func (m *E2NodeMessage) GetMessage() isE2NodeMessage_Message {
+	if m != nil {
+		return m.Message
+	}
+	return nil
+}
+
+func (x *E2NodeMessage) GetSetup() *E2SetupRequest {
+	if x, ok := x.GetMessage().(*E2NodeMessage_Setup); ok {
+		return x.Setup
+	}
+	return nil
+}
+
+func (x *E2NodeMessage) GetIndication() *KPMIndication {
+	if x, ok := x.GetMessage().(*E2NodeMessage_Indication); ok {
+		return x.Indication
+	}
+	return nil
+}
+
+func (x *E2NodeMessage) GetPolicyResult() *PolicyResult {
+	if x, ok := x.GetMessage().(*E2NodeMessage_PolicyResult); ok {
+		return x.PolicyResult
+	}
+	return nil
+}
+
+type isE2NodeMessage_Message interface {
+	isE2NodeMessage_Message()
+}
+
+type E2NodeMessage_Setup struct {
+	Setup *E2SetupRequest `protobuf:"bytes,1,opt,name=setup,proto3,oneof"`
+}
+
+type E2NodeMessage_Indication struct {
+	Indication *KPMIndication `protobuf:"bytes,2,opt,name=indication,proto3,oneof"`
+}
+
+type E2NodeMessage_PolicyResult struct {
+	PolicyResult *PolicyResult `protobuf:"bytes,3,opt,name=policy_result,json=policyResult,proto3,oneof"`
+}
+
+func (*E2NodeMessage_Setup) isE2NodeMessage_Message() {}
+
+func (*E2NodeMessage_Indication) isE2NodeMessage_Message() {}
+
+func (*E2NodeMessage_PolicyResult) isE2NodeMessage_Message() {}
+
+type RICMessage struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Message:
+	//	*RICMessage_Policy
+	Message isRICMessage_Message `protobuf_oneof:"message"`
+}
+
+func (x *RICMessage) Reset() {
+	*x = RICMessage{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gnodeb_e2_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RICMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RICMessage) ProtoMessage() {}
+
+func (x *RICMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_gnodeb_e2_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RICMessage.ProtoReflect.Descriptor instead.
+func (*RICMessage) Descriptor() ([]byte, []int) {
+	return file_gnodeb_e2_proto_rawDescGZIP(), []int{1}
+}
+
+func (m *RICMessage) GetMessage() isRICMessage_Message {
+	if m != nil {
+		return m.Message
+	}
+	return nil
+}
+
+func (x *RICMessage) GetPolicy() *Policy {
+	if x, ok := x.GetMessage().(*RICMessage_Policy); ok {
+		return x.Policy
+	}
+	return nil
+}
+
+type isRICMessage_Message interface {
+	isRICMessage_Message()
+}
+
+type RICMessage_Policy struct {
+	Policy *Policy `protobuf:"bytes,1,opt,name=policy,proto3,oneof"`
+}
+
+func (*RICMessage_Policy) isRICMessage_Message() {}
+
+type E2SetupRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	NodeId string   `protobuf:"bytes,1,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"`
+	Cells  []uint64 `protobuf:"varint,2,rep,packed,name=cells,proto3" json:"cells,omitempty"`
+}
+
+func (x *E2SetupRequest) Reset() {
+	*x = E2SetupRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gnodeb_e2_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *E2SetupRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*E2SetupRequest) ProtoMessage() {}
+
+func (x *E2SetupRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gnodeb_e2_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use E2SetupRequest.ProtoReflect.Descriptor instead.
+func (*E2SetupRequest) Descriptor() ([]byte, []int) {
+	return file_gnodeb_e2_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *E2SetupRequest) GetNodeId() string {
+	if x != nil {
+		return x.NodeId
+	}
+	return ""
+}
+
+func (x *E2SetupRequest) GetCells() []uint64 {
+	if x != nil {
+		return x.Cells
+	}
+	return nil
+}
+
+// KPMIndication reports the cells of an E2 node over the last period.
+type KPMIndication struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	NodeId    string     `protobuf:"bytes,1,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"`
+	Timestamp int64      `protobuf:"varint,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"` // Unix time in milliseconds
+	PeriodMs  int64      `protobuf:"varint,3,opt,name=period_ms,json=periodMs,proto3" json:"period_ms,omitempty"`
+	Cells     []*CellKPM `protobuf:"bytes,4,rep,name=cells,proto3" json:"cells,omitempty"`
+}
+
+func (x *KPMIndication) Reset() {
+	*x = KPMIndication{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gnodeb_e2_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *KPMIndication) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*KPMIndication) ProtoMessage() {}
+
+func (x *KPMIndication) ProtoReflect() protoreflect.Message {
+	mi := &file_gnodeb_e2_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use KPMIndication.ProtoReflect.Descriptor instead.
+func (*KPMIndication) Descriptor() ([]byte, []int) {
+	return file_gnodeb_e2_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *KPMIndication) GetNodeId() string {
+	if x != nil {
+		return x.NodeId
+	}
+	return ""
+}
+
+func (x *KPMIndication) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *KPMIndication) GetPeriodMs() int64 {
+	if x != nil {
+		return x.PeriodMs
+	}
+	return 0
+}
+
+func (x *KPMIndication) GetCells() []*CellKPM {
+	if x != nil {
+		return x.Cells
+	}
+	return nil
+}
+
+type CellKPM struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Nci            uint64  `protobuf:"varint,1,opt,name=nci,proto3" json:"nci,omitempty"`
+	Prbs           int32   `protobuf:"varint,2,opt,name=prbs,proto3" json:"prbs,omitempty"`
+	PrbsUsed       int32   `protobuf:"varint,3,opt,name=prbs_used,json=prbsUsed,proto3" json:"prbs_used,omitempty"`
+	PrbUtilization float64 `protobuf:"fixed64,4,opt,name=prb_utilization,json=prbUtilization,proto3" json:"prb_utilization,omitempty"`
+	ActiveUes      int32   `protobuf:"varint,5,opt,name=active_ues,json=activeUes,proto3" json:"active_ues,omitempty"`
+	RachAttempts   int64   `protobuf:"varint,6,opt,name=rach_attempts,json=rachAttempts,proto3" json:"rach_attempts,omitempty"`
+	RachFailures   int64   `protobuf:"varint,7,opt,name=rach_failures,json=rachFailures,proto3" json:"rach_failures,omitempty"`
+}
+
+func (x *CellKPM) Reset() {
+	*x = CellKPM{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gnodeb_e2_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CellKPM) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CellKPM) ProtoMessage() {}
+
+func (x *CellKPM) ProtoReflect() protoreflect.Message {
+	mi := &file_gnodeb_e2_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CellKPM.ProtoReflect.Descriptor instead.
+func (*CellKPM) Descriptor() ([]byte, []int) {
+	return file_gnodeb_e2_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CellKPM) GetNci() uint64 {
+	if x != nil {
+		return x.Nci
+	}
+	return 0
+}
+
+func (x *CellKPM) GetPrbs() int32 {
+	if x != nil {
+		return x.Prbs
+	}
+	return 0
+}
+
+func (x *CellKPM) GetPrbsUsed() int32 {
+	if x != nil {
+		return x.PrbsUsed
+	}
+	return 0
+}
+
+func (x *CellKPM) GetPrbUtilization() float64 {
+	if x != nil {
+		return x.PrbUtilization
+	}
+	return 0
+}
+
+func (x *CellKPM) GetActiveUes() int32 {
+	if x != nil {
+		return x.ActiveUes
+	}
+	return 0
+}
+
+func (x *CellKPM) GetRachAttempts() int64 {
+	if x != nil {
+		return x.RachAttempts
+	}
+	return 0
+}
+
+func (x *CellKPM) GetRachFailures() int64 {
+	if x != nil {
+		return x.RachFailures
+	}
+	return 0
+}
+
+// Policy sets the admission threshold of a cell, of every cell without its
+// own when nci is zero. A zero max_prb_utilization removes the threshold.
+type Policy struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	PolicyId          uint64  `protobuf:"varint,1,opt,name=policy_id,json=policyId,proto3" json:"policy_id,omitempty"`
+	Nci               uint64  `protobuf:"varint,2,opt,name=nci,proto3" json:"nci,omitempty"`
+	MaxPrbUtilization float64 `protobuf:"fixed64,3,opt,name=max_prb_utilization,json=maxPrbUtilization,proto3" json:"max_prb_utilization,omitempty"`
+}
+
+func (x *Policy) Reset() {
+	*x = Policy{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gnodeb_e2_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Policy) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Policy) ProtoMessage() {}
+
+func (x *Policy) ProtoReflect() protoreflect.Message {
+	mi := &file_gnodeb_e2_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Policy.ProtoReflect.Descriptor instead.
+func (*Policy) Descriptor() ([]byte, []int) {
+	return file_gnodeb_e2_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Policy) GetPolicyId() uint64 {
+	if x != nil {
+		return x.PolicyId
+	}
+	return 0
+}
+
+func (x *Policy) GetNci() uint64 {
+	if x != nil {
+		return x.Nci
+	}
+	return 0
+}
+
+func (x *Policy) GetMaxPrbUtilization() float64 {
+	if x != nil {
+		return x.MaxPrbUtilization
+	}
+	return 0
+}
+
+type PolicyResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	PolicyId uint64 `protobuf:"varint,1,opt,name=policy_id,json=policyId,proto3" json:"policy_id,omitempty"`
+	Err      string `protobuf:"bytes,2,opt,name=err,proto3" json:"err,omitempty"`
+}
+
+func (x *PolicyResult) Reset() {
+	*x = PolicyResult{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gnodeb_e2_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PolicyResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PolicyResult) ProtoMessage() {}
+
+func (x *PolicyResult) ProtoReflect() protoreflect.Message {
+	mi := &file_gnodeb_e2_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PolicyResult.ProtoReflect.Descriptor instead.
+func (*PolicyResult) Descriptor() ([]byte, []int) {
+	return file_gnodeb_e2_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *PolicyResult) GetPolicyId() uint64 {
+	if x != nil {
+		return x.PolicyId
+	}
+	return 0
+}
+
+func (x *PolicyResult) GetErr() string {
+	if x != nil {
+		return x.Err
+	}
+	return ""
+}
+
+var File_gnodeb_e2_proto protoreflect.FileDescriptor
+
+var file_gnodeb_e2_proto_rawDesc = []byte{
+	0x0a, 0x0f, 0x67, 0x6e, 0x6f, 0x64, 0x65, 0x62, 0x2f, 0x65, 0x32, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x12, 0x06, 0x67, 0x6e, 0x6f, 0x64, 0x65, 0x62, 0x22, 0xc0, 0x01, 0x0a, 0x0d, 0x45, 0x32,
+	0x4e, 0x6f, 0x64, 0x65, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x2e, 0x0a, 0x05, 0x73,
+	0x65, 0x74, 0x75, 0x70, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x67, 0x6e, 0x6f,
+	0x64, 0x65, 0x62, 0x2e, 0x45, 0x32, 0x53, 0x65, 0x74, 0x75, 0x70, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x48, 0x00, 0x52, 0x05, 0x73, 0x65, 0x74, 0x75, 0x70, 0x12, 0x37, 0x0a, 0x0a, 0x69,
+	0x6e, 0x64, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x15, 0x2e, 0x67, 0x6e, 0x6f, 0x64, 0x65, 0x62, 0x2e, 0x4b, 0x50, 0x4d, 0x49, 0x6e, 0x64, 0x69,
+	0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x48, 0x00, 0x52, 0x0a, 0x69, 0x6e, 0x64, 0x69, 0x63, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x12, 0x3b, 0x0a, 0x0d, 0x70, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x5f, 0x72,
+	0x65, 0x73, 0x75, 0x6c, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x67, 0x6e,
+	0x6f, 0x64, 0x65, 0x62, 0x2e, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x52, 0x65, 0x73, 0x75, 0x6c,
+	0x74, 0x48, 0x00, 0x52, 0x0c, 0x70, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x52, 0x65, 0x73, 0x75, 0x6c,
+	0x74, 0x42, 0x09, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x22, 0x41, 0x0a, 0x0a,
+	0x52, 0x49, 0x43, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x28, 0x0a, 0x06, 0x70, 0x6f,
+	0x6c, 0x69, 0x63, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x67, 0x6e, 0x6f,
+	0x64, 0x65, 0x62, 0x2e, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x48, 0x00, 0x52, 0x06, 0x70, 0x6f,
+	0x6c, 0x69, 0x63, 0x79, 0x42, 0x09, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x22,
+	0x3f, 0x0a, 0x0e, 0x45, 0x32, 0x53, 0x65, 0x74, 0x75, 0x70, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x17, 0x0a, 0x07, 0x6e, 0x6f, 0x64, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x06, 0x6e, 0x6f, 0x64, 0x65, 0x49, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x65,
+	0x6c, 0x6c, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x04, 0x52, 0x05, 0x63, 0x65, 0x6c, 0x6c, 0x73,
+	0x22, 0x8a, 0x01, 0x0a, 0x0d, 0x4b, 0x50, 0x4d, 0x49, 0x6e, 0x64, 0x69, 0x63, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x12, 0x17, 0x0a, 0x07, 0x6e, 0x6f, 0x64, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x06, 0x6e, 0x6f, 0x64, 0x65, 0x49, 0x64, 0x12, 0x1c, 0x0a, 0x09, 0x74,
+	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09,
+	0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x12, 0x1b, 0x0a, 0x09, 0x70, 0x65, 0x72,
+	0x69, 0x6f, 0x64, 0x5f, 0x6d, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x70, 0x65,
+	0x72, 0x69, 0x6f, 0x64, 0x4d, 0x73, 0x12, 0x25, 0x0a, 0x05, 0x63, 0x65, 0x6c, 0x6c, 0x73, 0x18,
+	0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x67, 0x6e, 0x6f, 0x64, 0x65, 0x62, 0x2e, 0x43,
+	0x65, 0x6c, 0x6c, 0x4b, 0x50, 0x4d, 0x52, 0x05, 0x63, 0x65, 0x6c, 0x6c, 0x73, 0x22, 0xde, 0x01,
+	0x0a, 0x07, 0x43, 0x65, 0x6c, 0x6c, 0x4b, 0x50, 0x4d, 0x12, 0x10, 0x0a, 0x03, 0x6e, 0x63, 0x69,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x03, 0x6e, 0x63, 0x69, 0x12, 0x12, 0x0a, 0x04, 0x70,
+	0x72, 0x62, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x70, 0x72, 0x62, 0x73, 0x12,
+	0x1b, 0x0a, 0x09, 0x70, 0x72, 0x62, 0x73, 0x5f, 0x75, 0x73, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x08, 0x70, 0x72, 0x62, 0x73, 0x55, 0x73, 0x65, 0x64, 0x12, 0x27, 0x0a, 0x0f,
+	0x70, 0x72, 0x62, 0x5f, 0x75, 0x74, 0x69, 0x6c, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0e, 0x70, 0x72, 0x62, 0x55, 0x74, 0x69, 0x6c, 0x69, 0x7a,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1d, 0x0a, 0x0a, 0x61, 0x63, 0x74, 0x69, 0x76, 0x65, 0x5f,
+	0x75, 0x65, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x09, 0x61, 0x63, 0x74, 0x69, 0x76,
+	0x65, 0x55, 0x65, 0x73, 0x12, 0x23, 0x0a, 0x0d, 0x72, 0x61, 0x63, 0x68, 0x5f, 0x61, 0x74, 0x74,
+	0x65, 0x6d, 0x70, 0x74, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0c, 0x72, 0x61, 0x63,
+	0x68, 0x41, 0x74, 0x74, 0x65, 0x6d, 0x70, 0x74, 0x73, 0x12, 0x23, 0x0a, 0x0d, 0x72, 0x61, 0x63,
+	0x68, 0x5f, 0x66, 0x61, 0x69, 0x6c, 0x75, 0x72, 0x65, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x0c, 0x72, 0x61, 0x63, 0x68, 0x46, 0x61, 0x69, 0x6c, 0x75, 0x72, 0x65, 0x73, 0x22, 0x67,
+	0x0a, 0x06, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x12, 0x1b, 0x0a, 0x09, 0x70, 0x6f, 0x6c, 0x69,
+	0x63, 0x79, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x70, 0x6f, 0x6c,
+	0x69, 0x63, 0x79, 0x49, 0x64, 0x12, 0x10, 0x0a, 0x03, 0x6e, 0x63, 0x69, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x04, 0x52, 0x03, 0x6e, 0x63, 0x69, 0x12, 0x2e, 0x0a, 0x13, 0x6d, 0x61, 0x78, 0x5f, 0x70,
+	0x72, 0x62, 0x5f, 0x75, 0x74, 0x69, 0x6c, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x01, 0x52, 0x11, 0x6d, 0x61, 0x78, 0x50, 0x72, 0x62, 0x55, 0x74, 0x69, 0x6c,
+	0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x3d, 0x0a, 0x0c, 0x50, 0x6f, 0x6c, 0x69, 0x63,
+	0x79, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x1b, 0x0a, 0x09, 0x70, 0x6f, 0x6c, 0x69, 0x63,
+	0x79, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x70, 0x6f, 0x6c, 0x69,
+	0x63, 0x79, 0x49, 0x64, 0x12, 0x10, 0x0a, 0x03, 0x65, 0x72, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x03, 0x65, 0x72, 0x72, 0x32, 0x40, 0x0a, 0x02, 0x45, 0x32, 0x12, 0x3a, 0x0a, 0x07,
+	0x43, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x12, 0x15, 0x2e, 0x67, 0x6e, 0x6f, 0x64, 0x65, 0x62,
+	0x2e, 0x45, 0x32, 0x4e, 0x6f, 0x64, 0x65, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x1a, 0x12,
+	0x2e, 0x67, 0x6e, 0x6f, 0x64, 0x65, 0x62, 0x2e, 0x52, 0x49, 0x43, 0x4d, 0x65, 0x73, 0x73, 0x61,
+	0x67, 0x65, 0x22, 0x00, 0x28, 0x01, 0x30, 0x01, 0x42, 0x37, 0x5a, 0x35, 0x67, 0x69, 0x74, 0x68,
+	0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6d, 0x69, 0x6b, 0x69, 0x2d, 0x74, 0x6e, 0x74, 0x2f,
+	0x73, 0x61, 0x35, 0x67, 0x2d, 0x67, 0x6f, 0x2d, 0x75, 0x73, 0x76, 0x63, 0x2d, 0x6b, 0x38, 0x73,
+	0x2f, 0x70, 0x62, 0x2f, 0x67, 0x6e, 0x6f, 0x64, 0x65, 0x62, 0x3b, 0x67, 0x6e, 0x6f, 0x64, 0x65,
+	0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_gnodeb_e2_proto_rawDescOnce sync.Once
+	file_gnodeb_e2_proto_rawDescData = file_gnodeb_e2_proto_rawDesc
+)
+
+func file_gnodeb_e2_proto_rawDescGZIP() []byte {
+	file_gnodeb_e2_proto_rawDescOnce.Do(func() {
+		file_gnodeb_e2_proto_rawDescData = protoimpl.X.CompressGZIP(file_gnodeb_e2_proto_rawDescData)
+	})
+	return file_gnodeb_e2_proto_rawDescData
+}
+
+var file_gnodeb_e2_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_gnodeb_e2_proto_goTypes = []interface{}{
+	(*E2NodeMessage)(nil),  // 0: gnodeb.E2NodeMessage
+	(*RICMessage)(nil),     // 1: gnodeb.RICMessage
+	(*E2SetupRequest)(nil), // 2: gnodeb.E2SetupRequest
+	(*KPMIndication)(nil),  // 3: gnodeb.KPMIndication
+	(*CellKPM)(nil),        // 4: gnodeb.CellKPM
+	(*Policy)(nil),         // 5: gnodeb.Policy
+	(*PolicyResult)(nil),   // 6: gnodeb.PolicyResult
+}
+var file_gnodeb_e2_proto_depIdxs = []int32{
+	2, // 0: gnodeb.E2NodeMessage.setup:type_name -> gnodeb.E2SetupRequest
+	3, // 1: gnodeb.E2NodeMessage.indication:type_name -> gnodeb.KPMIndication
+	6, // 2: gnodeb.E2NodeMessage.policy_result:type_name -> gnodeb.PolicyResult
+	5, // 3: gnodeb.RICMessage.policy:type_name -> gnodeb.Policy
+	4, // 4: gnodeb.KPMIndication.cells:type_name -> gnodeb.CellKPM
+	0, // 5: gnodeb.E2.Connect:input_type -> gnodeb.E2NodeMessage
+	1, // 6: gnodeb.E2.Connect:output_type -> gnodeb.RICMessage
+	6, // [6:7] is the sub-list for method output_type
+	5, // [5:6] is the sub-list for method input_type
+	5, // [5:5] is the sub-list for extension type_name
+	5, // [5:5] is the sub-list for extension extendee
+	0, // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_gnodeb_e2_proto_init() }
+func file_gnodeb_e2_proto_init() {
+	if File_gnodeb_e2_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_gnodeb_e2_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*E2NodeMessage); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gnodeb_e2_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RICMessage); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gnodeb_e2_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*E2SetupRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gnodeb_e2_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*KPMIndication); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gnodeb_e2_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CellKPM); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gnodeb_e2_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Policy); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gnodeb_e2_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PolicyResult); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_gnodeb_e2_proto_msgTypes[0].OneofWrappers = []interface{}{
+		(*E2NodeMessage_Setup)(nil),
+		(*E2NodeMessage_Indication)(nil),
+		(*E2NodeMessage_PolicyResult)(nil),
+	}
+	file_gnodeb_e2_proto_msgTypes[1].OneofWrappers = []interface{}{
+		(*RICMessage_Policy)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_gnodeb_e2_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   7,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_gnodeb_e2_proto_goTypes,
+		DependencyIndexes: file_gnodeb_e2_proto_depIdxs,
+		MessageInfos:      file_gnodeb_e2_proto_msgTypes,
+	}.Build()
+	File_gnodeb_e2_proto = out.File
+	file_gnodeb_e2_proto_rawDesc = nil
+	file_gnodeb_e2_proto_goTypes = nil
+	file_gnodeb_e2_proto_depIdxs = nil
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConnInterface
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion6
+
+// E2Client is the client API for E2 service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type E2Client interface {
+	Connect(ctx context.Context, opts ...grpc.CallOption) (E2_ConnectClient, error)
+}
+
+type e2Client struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewE2Client(cc grpc.ClientConnInterface) E2Client {
+	return &e2Client{cc}
+}
+
+func (c *e2Client) Connect(ctx context.Context, opts ...grpc.CallOption) (E2_ConnectClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_E2_serviceDesc.Streams[0], "/gnodeb.E2/Connect", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &e2ConnectClient{stream}
+	return x, nil
+}
+
+type E2_ConnectClient interface {
+	Send(*E2NodeMessage) error
+	Recv() (*RICMessage, error)
+	grpc.ClientStream
+}
+
+type e2ConnectClient struct {
+	grpc.ClientStream
+}
+
+func (x *e2ConnectClient) Send(m *E2NodeMessage) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *e2ConnectClient) Recv() (*RICMessage, error) {
+	m := new(RICMessage)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// E2Server is the server API for E2 service.
+type E2Server interface {
+	Connect(E2_ConnectServer) error
+}
+
+// UnimplementedE2Server can be embedded to have forward compatible implementations.
+type UnimplementedE2Server struct {
+}
+
+func (*UnimplementedE2Server) Connect(E2_ConnectServer) error {
+	return status.Errorf(codes.Unimplemented, "method Connect not implemented")
+}
+
+func RegisterE2Server(s *grpc.Server, srv E2Server) {
+	s.RegisterService(&_E2_serviceDesc, srv)
+}
+
+func _E2_Connect_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(E2Server).Connect(&e2ConnectServer{stream})
+}
+
+type E2_ConnectServer interface {
+	Send(*RICMessage) error
+	Recv() (*E2NodeMessage, error)
+	grpc.ServerStream
+}
+
+type e2ConnectServer struct {
+	grpc.ServerStream
+}
+
+func (x *e2ConnectServer) Send(m *RICMessage) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *e2ConnectServer) Recv() (*E2NodeMessage, error) {
+	m := new(E2NodeMessage)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+var _E2_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "gnodeb.E2",
+	HandlerType: (*E2Server)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Connect",
+			Handler:       _E2_Connect_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "gnodeb/e2.proto",
+}
@@ -0,0 +1,1429 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.24.0
+// 	protoc        v3.12.2
+// source: gnodeb/f1ap.proto
+
+// F1AP (TS 38.473) rendered over gRPC, between the gNB-CU and the gNB-DU of
+// a split gNB.
+
+package gnodeb
+
+import (
+	context "context"
+	proto "github.com/golang/protobuf/proto"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// This is a compile-time assertion that a sufficiently up-to-date version
+// of the legacy proto package is being used.
+const _ = proto.ProtoPackageIsVersion4
+
+type F1SetupRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	GnbDuId   uint64 `protobuf:"varint,1,opt,name=gnb_du_id,json=gnbDuId,proto3" json:"gnb_du_id,omitempty"`
+	GnbDuName string `protobuf:"bytes,2,opt,name=gnb_du_name,json=gnbDuName,proto3" json:"gnb_du_name,omitempty"`
+	// The address the F1DU service of the gNB-DU is served on.
+	Address     string  `protobuf:"bytes,3,opt,name=address,proto3" json:"address,omitempty"`
+	ServedCells []*Cell `protobuf:"bytes,4,rep,name=served_cells,json=servedCells,proto3" json:"served_cells,omitempty"`
+}
+
+func (x *F1SetupRequest) Reset() {
+	*x = F1SetupRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gnodeb_f1ap_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *F1SetupRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*F1SetupRequest) ProtoMessage() {}
+
+func (x *F1SetupRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gnodeb_f1ap_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use F1SetupRequest.ProtoReflect.Descriptor instead.
+func (*F1SetupRequest) Descriptor() ([]byte, []int) {
+	return file_gnodeb_f1ap_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *F1SetupRequest) GetGnbDuId() uint64 {
+	if x != nil {
+		return x.GnbDuId
+	}
+	return 0
+}
+
+func (x *F1SetupRequest) GetGnbDuName() string {
+	if x != nil {
+		return x.GnbDuName
+	}
+	return ""
+}
+
+func (x *F1SetupRequest) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *F1SetupRequest) GetServedCells() []*Cell {
+	if x != nil {
+		return x.ServedCells
+	}
+	return nil
+}
+
+type F1SetupResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	GnbCuName      string   `protobuf:"bytes,1,opt,name=gnb_cu_name,json=gnbCuName,proto3" json:"gnb_cu_name,omitempty"`
+	ActivatedCells []uint64 `protobuf:"varint,2,rep,packed,name=activated_cells,json=activatedCells,proto3" json:"activated_cells,omitempty"`
+}
+
+func (x *F1SetupResponse) Reset() {
+	*x = F1SetupResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gnodeb_f1ap_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *F1SetupResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*F1SetupResponse) ProtoMessage() {}
+
+func (x *F1SetupResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gnodeb_f1ap_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use F1SetupResponse.ProtoReflect.Descriptor instead.
+func (*F1SetupResponse) Descriptor() ([]byte, []int) {
+	return file_gnodeb_f1ap_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *F1SetupResponse) GetGnbCuName() string {
+	if x != nil {
+		return x.GnbCuName
+	}
+	return ""
+}
+
+func (x *F1SetupResponse) GetActivatedCells() []uint64 {
+	if x != nil {
+		return x.ActivatedCells
+	}
+	return nil
+}
+
+type InitialULRRCMessageTransferRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	GnbDuId       uint64 `protobuf:"varint,1,opt,name=gnb_du_id,json=gnbDuId,proto3" json:"gnb_du_id,omitempty"`
+	GnbDuUeF1ApId uint32 `protobuf:"varint,2,opt,name=gnb_du_ue_f1ap_id,json=gnbDuUeF1apId,proto3" json:"gnb_du_ue_f1ap_id,omitempty"`
+	Nci           uint64 `protobuf:"varint,3,opt,name=nci,proto3" json:"nci,omitempty"`
+	CRnti         uint32 `protobuf:"varint,4,opt,name=c_rnti,json=cRnti,proto3" json:"c_rnti,omitempty"`
+	RrcContainer  []byte `protobuf:"bytes,5,opt,name=rrc_container,json=rrcContainer,proto3" json:"rrc_container,omitempty"`
+}
+
+func (x *InitialULRRCMessageTransferRequest) Reset() {
+	*x = InitialULRRCMessageTransferRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gnodeb_f1ap_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *InitialULRRCMessageTransferRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InitialULRRCMessageTransferRequest) ProtoMessage() {}
+
+func (x *InitialULRRCMessageTransferRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gnodeb_f1ap_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InitialULRRCMessageTransferRequest.ProtoReflect.Descriptor instead.
+func (*InitialULRRCMessageTransferRequest) Descriptor() ([]byte, []int) {
+	return file_gnodeb_f1ap_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *InitialULRRCMessageTransferRequest) GetGnbDuId() uint64 {
+	if x != nil {
+		return x.GnbDuId
+	}
+	return 0
+}
+
+func (x *InitialULRRCMessageTransferRequest) GetGnbDuUeF1ApId() uint32 {
+	if x != nil {
+		return x.GnbDuUeF1ApId
+	}
+	return 0
+}
+
+func (x *InitialULRRCMessageTransferRequest) GetNci() uint64 {
+	if x != nil {
+		return x.Nci
+	}
+	return 0
+}
+
+func (x *InitialULRRCMessageTransferRequest) GetCRnti() uint32 {
+	if x != nil {
+		return x.CRnti
+	}
+	return 0
+}
+
+func (x *InitialULRRCMessageTransferRequest) GetRrcContainer() []byte {
+	if x != nil {
+		return x.RrcContainer
+	}
+	return nil
+}
+
+type InitialULRRCMessageTransferReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	GnbCuUeF1ApId uint32 `protobuf:"varint,1,opt,name=gnb_cu_ue_f1ap_id,json=gnbCuUeF1apId,proto3" json:"gnb_cu_ue_f1ap_id,omitempty"`
+}
+
+func (x *InitialULRRCMessageTransferReply) Reset() {
+	*x = InitialULRRCMessageTransferReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gnodeb_f1ap_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *InitialULRRCMessageTransferReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InitialULRRCMessageTransferReply) ProtoMessage() {}
+
+func (x *InitialULRRCMessageTransferReply) ProtoReflect() protoreflect.Message {
+	mi := &file_gnodeb_f1ap_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InitialULRRCMessageTransferReply.ProtoReflect.Descriptor instead.
+func (*InitialULRRCMessageTransferReply) Descriptor() ([]byte, []int) {
+	return file_gnodeb_f1ap_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *InitialULRRCMessageTransferReply) GetGnbCuUeF1ApId() uint32 {
+	if x != nil {
+		return x.GnbCuUeF1ApId
+	}
+	return 0
+}
+
+type RRCMessageTransferRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	GnbCuUeF1ApId uint32 `protobuf:"varint,1,opt,name=gnb_cu_ue_f1ap_id,json=gnbCuUeF1apId,proto3" json:"gnb_cu_ue_f1ap_id,omitempty"`
+	GnbDuUeF1ApId uint32 `protobuf:"varint,2,opt,name=gnb_du_ue_f1ap_id,json=gnbDuUeF1apId,proto3" json:"gnb_du_ue_f1ap_id,omitempty"`
+	SrbId         uint32 `protobuf:"varint,3,opt,name=srb_id,json=srbId,proto3" json:"srb_id,omitempty"`
+	RrcContainer  []byte `protobuf:"bytes,4,opt,name=rrc_container,json=rrcContainer,proto3" json:"rrc_container,omitempty"`
+}
+
+func (x *RRCMessageTransferRequest) Reset() {
+	*x = RRCMessageTransferRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gnodeb_f1ap_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RRCMessageTransferRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RRCMessageTransferRequest) ProtoMessage() {}
+
+func (x *RRCMessageTransferRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gnodeb_f1ap_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RRCMessageTransferRequest.ProtoReflect.Descriptor instead.
+func (*RRCMessageTransferRequest) Descriptor() ([]byte, []int) {
+	return file_gnodeb_f1ap_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *RRCMessageTransferRequest) GetGnbCuUeF1ApId() uint32 {
+	if x != nil {
+		return x.GnbCuUeF1ApId
+	}
+	return 0
+}
+
+func (x *RRCMessageTransferRequest) GetGnbDuUeF1ApId() uint32 {
+	if x != nil {
+		return x.GnbDuUeF1ApId
+	}
+	return 0
+}
+
+func (x *RRCMessageTransferRequest) GetSrbId() uint32 {
+	if x != nil {
+		return x.SrbId
+	}
+	return 0
+}
+
+func (x *RRCMessageTransferRequest) GetRrcContainer() []byte {
+	if x != nil {
+		return x.RrcContainer
+	}
+	return nil
+}
+
+type RRCMessageTransferReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *RRCMessageTransferReply) Reset() {
+	*x = RRCMessageTransferReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gnodeb_f1ap_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RRCMessageTransferReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RRCMessageTransferReply) ProtoMessage() {}
+
+func (x *RRCMessageTransferReply) ProtoReflect() protoreflect.Message {
+	mi := &file_gnodeb_f1ap_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RRCMessageTransferReply.ProtoReflect.Descriptor instead.
+func (*RRCMessageTransferReply) Descriptor() ([]byte, []int) {
+	return file_gnodeb_f1ap_proto_rawDescGZIP(), []int{5}
+}
+
+type UERadioCapabilityRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	GnbCuUeF1ApId uint32 `protobuf:"varint,1,opt,name=gnb_cu_ue_f1ap_id,json=gnbCuUeF1apId,proto3" json:"gnb_cu_ue_f1ap_id,omitempty"`
+	GnbDuUeF1ApId uint32 `protobuf:"varint,2,opt,name=gnb_du_ue_f1ap_id,json=gnbDuUeF1apId,proto3" json:"gnb_du_ue_f1ap_id,omitempty"`
+}
+
+func (x *UERadioCapabilityRequest) Reset() {
+	*x = UERadioCapabilityRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gnodeb_f1ap_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UERadioCapabilityRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UERadioCapabilityRequest) ProtoMessage() {}
+
+func (x *UERadioCapabilityRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gnodeb_f1ap_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UERadioCapabilityRequest.ProtoReflect.Descriptor instead.
+func (*UERadioCapabilityRequest) Descriptor() ([]byte, []int) {
+	return file_gnodeb_f1ap_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *UERadioCapabilityRequest) GetGnbCuUeF1ApId() uint32 {
+	if x != nil {
+		return x.GnbCuUeF1ApId
+	}
+	return 0
+}
+
+func (x *UERadioCapabilityRequest) GetGnbDuUeF1ApId() uint32 {
+	if x != nil {
+		return x.GnbDuUeF1ApId
+	}
+	return 0
+}
+
+type UERadioCapabilityReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	UeCapabilityRatContainerList []byte `protobuf:"bytes,1,opt,name=ue_capability_rat_container_list,json=ueCapabilityRatContainerList,proto3" json:"ue_capability_rat_container_list,omitempty"`
+}
+
+func (x *UERadioCapabilityReply) Reset() {
+	*x = UERadioCapabilityReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gnodeb_f1ap_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UERadioCapabilityReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UERadioCapabilityReply) ProtoMessage() {}
+
+func (x *UERadioCapabilityReply) ProtoReflect() protoreflect.Message {
+	mi := &file_gnodeb_f1ap_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UERadioCapabilityReply.ProtoReflect.Descriptor instead.
+func (*UERadioCapabilityReply) Descriptor() ([]byte, []int) {
+	return file_gnodeb_f1ap_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *UERadioCapabilityReply) GetUeCapabilityRatContainerList() []byte {
+	if x != nil {
+		return x.UeCapabilityRatContainerList
+	}
+	return nil
+}
+
+type UEContextSetupRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	GnbCuUeF1ApId uint32   `protobuf:"varint,1,opt,name=gnb_cu_ue_f1ap_id,json=gnbCuUeF1apId,proto3" json:"gnb_cu_ue_f1ap_id,omitempty"`
+	GnbDuUeF1ApId uint32   `protobuf:"varint,2,opt,name=gnb_du_ue_f1ap_id,json=gnbDuUeF1apId,proto3" json:"gnb_du_ue_f1ap_id,omitempty"`
+	Nci           uint64   `protobuf:"varint,3,opt,name=nci,proto3" json:"nci,omitempty"`
+	DrbIds        []uint32 `protobuf:"varint,4,rep,packed,name=drb_ids,json=drbIds,proto3" json:"drb_ids,omitempty"`
+	RrcContainer  []byte   `protobuf:"bytes,5,opt,name=rrc_container,json=rrcContainer,proto3" json:"rrc_container,omitempty"`
+}
+
+func (x *UEContextSetupRequest) Reset() {
+	*x = UEContextSetupRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gnodeb_f1ap_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UEContextSetupRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UEContextSetupRequest) ProtoMessage() {}
+
+func (x *UEContextSetupRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gnodeb_f1ap_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UEContextSetupRequest.ProtoReflect.Descriptor instead.
+func (*UEContextSetupRequest) Descriptor() ([]byte, []int) {
+	return file_gnodeb_f1ap_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *UEContextSetupRequest) GetGnbCuUeF1ApId() uint32 {
+	if x != nil {
+		return x.GnbCuUeF1ApId
+	}
+	return 0
+}
+
+func (x *UEContextSetupRequest) GetGnbDuUeF1ApId() uint32 {
+	if x != nil {
+		return x.GnbDuUeF1ApId
+	}
+	return 0
+}
+
+func (x *UEContextSetupRequest) GetNci() uint64 {
+	if x != nil {
+		return x.Nci
+	}
+	return 0
+}
+
+func (x *UEContextSetupRequest) GetDrbIds() []uint32 {
+	if x != nil {
+		return x.DrbIds
+	}
+	return nil
+}
+
+func (x *UEContextSetupRequest) GetRrcContainer() []byte {
+	if x != nil {
+		return x.RrcContainer
+	}
+	return nil
+}
+
+type UEContextSetupResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	GnbDuUeF1ApId uint32   `protobuf:"varint,1,opt,name=gnb_du_ue_f1ap_id,json=gnbDuUeF1apId,proto3" json:"gnb_du_ue_f1ap_id,omitempty"`
+	DrbIds        []uint32 `protobuf:"varint,2,rep,packed,name=drb_ids,json=drbIds,proto3" json:"drb_ids,omitempty"`
+	CRnti         uint32   `protobuf:"varint,3,opt,name=c_rnti,json=cRnti,proto3" json:"c_rnti,omitempty"`
+}
+
+func (x *UEContextSetupResponse) Reset() {
+	*x = UEContextSetupResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gnodeb_f1ap_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UEContextSetupResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UEContextSetupResponse) ProtoMessage() {}
+
+func (x *UEContextSetupResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gnodeb_f1ap_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UEContextSetupResponse.ProtoReflect.Descriptor instead.
+func (*UEContextSetupResponse) Descriptor() ([]byte, []int) {
+	return file_gnodeb_f1ap_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *UEContextSetupResponse) GetGnbDuUeF1ApId() uint32 {
+	if x != nil {
+		return x.GnbDuUeF1ApId
+	}
+	return 0
+}
+
+func (x *UEContextSetupResponse) GetDrbIds() []uint32 {
+	if x != nil {
+		return x.DrbIds
+	}
+	return nil
+}
+
+func (x *UEContextSetupResponse) GetCRnti() uint32 {
+	if x != nil {
+		return x.CRnti
+	}
+	return 0
+}
+
+type UEContextReleaseCommand struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	GnbCuUeF1ApId uint32 `protobuf:"varint,1,opt,name=gnb_cu_ue_f1ap_id,json=gnbCuUeF1apId,proto3" json:"gnb_cu_ue_f1ap_id,omitempty"`
+	GnbDuUeF1ApId uint32 `protobuf:"varint,2,opt,name=gnb_du_ue_f1ap_id,json=gnbDuUeF1apId,proto3" json:"gnb_du_ue_f1ap_id,omitempty"`
+}
+
+func (x *UEContextReleaseCommand) Reset() {
+	*x = UEContextReleaseCommand{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gnodeb_f1ap_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UEContextReleaseCommand) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UEContextReleaseCommand) ProtoMessage() {}
+
+func (x *UEContextReleaseCommand) ProtoReflect() protoreflect.Message {
+	mi := &file_gnodeb_f1ap_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UEContextReleaseCommand.ProtoReflect.Descriptor instead.
+func (*UEContextReleaseCommand) Descriptor() ([]byte, []int) {
+	return file_gnodeb_f1ap_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *UEContextReleaseCommand) GetGnbCuUeF1ApId() uint32 {
+	if x != nil {
+		return x.GnbCuUeF1ApId
+	}
+	return 0
+}
+
+func (x *UEContextReleaseCommand) GetGnbDuUeF1ApId() uint32 {
+	if x != nil {
+		return x.GnbDuUeF1ApId
+	}
+	return 0
+}
+
+type UEContextReleaseComplete struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *UEContextReleaseComplete) Reset() {
+	*x = UEContextReleaseComplete{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gnodeb_f1ap_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UEContextReleaseComplete) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UEContextReleaseComplete) ProtoMessage() {}
+
+func (x *UEContextReleaseComplete) ProtoReflect() protoreflect.Message {
+	mi := &file_gnodeb_f1ap_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UEContextReleaseComplete.ProtoReflect.Descriptor instead.
+func (*UEContextReleaseComplete) Descriptor() ([]byte, []int) {
+	return file_gnodeb_f1ap_proto_rawDescGZIP(), []int{11}
+}
+
+var File_gnodeb_f1ap_proto protoreflect.FileDescriptor
+
+var file_gnodeb_f1ap_proto_rawDesc = []byte{
+	0x0a, 0x11, 0x67, 0x6e, 0x6f, 0x64, 0x65, 0x62, 0x2f, 0x66, 0x31, 0x61, 0x70, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x12, 0x06, 0x67, 0x6e, 0x6f, 0x64, 0x65, 0x62, 0x1a, 0x11, 0x67, 0x6e, 0x6f,
+	0x64, 0x65, 0x62, 0x2f, 0x63, 0x65, 0x6c, 0x6c, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x97,
+	0x01, 0x0a, 0x0e, 0x46, 0x31, 0x53, 0x65, 0x74, 0x75, 0x70, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x1a, 0x0a, 0x09, 0x67, 0x6e, 0x62, 0x5f, 0x64, 0x75, 0x5f, 0x69, 0x64, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x67, 0x6e, 0x62, 0x44, 0x75, 0x49, 0x64, 0x12, 0x1e, 0x0a,
+	0x0b, 0x67, 0x6e, 0x62, 0x5f, 0x64, 0x75, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x09, 0x67, 0x6e, 0x62, 0x44, 0x75, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x18, 0x0a,
+	0x07, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07,
+	0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x12, 0x2f, 0x0a, 0x0c, 0x73, 0x65, 0x72, 0x76, 0x65,
+	0x64, 0x5f, 0x63, 0x65, 0x6c, 0x6c, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0c, 0x2e,
+	0x67, 0x6e, 0x6f, 0x64, 0x65, 0x62, 0x2e, 0x43, 0x65, 0x6c, 0x6c, 0x52, 0x0b, 0x73, 0x65, 0x72,
+	0x76, 0x65, 0x64, 0x43, 0x65, 0x6c, 0x6c, 0x73, 0x22, 0x5a, 0x0a, 0x0f, 0x46, 0x31, 0x53, 0x65,
+	0x74, 0x75, 0x70, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1e, 0x0a, 0x0b, 0x67,
+	0x6e, 0x62, 0x5f, 0x63, 0x75, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x09, 0x67, 0x6e, 0x62, 0x43, 0x75, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x27, 0x0a, 0x0f, 0x61,
+	0x63, 0x74, 0x69, 0x76, 0x61, 0x74, 0x65, 0x64, 0x5f, 0x63, 0x65, 0x6c, 0x6c, 0x73, 0x18, 0x02,
+	0x20, 0x03, 0x28, 0x04, 0x52, 0x0e, 0x61, 0x63, 0x74, 0x69, 0x76, 0x61, 0x74, 0x65, 0x64, 0x43,
+	0x65, 0x6c, 0x6c, 0x73, 0x22, 0xb8, 0x01, 0x0a, 0x22, 0x49, 0x6e, 0x69, 0x74, 0x69, 0x61, 0x6c,
+	0x55, 0x4c, 0x52, 0x52, 0x43, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x54, 0x72, 0x61, 0x6e,
+	0x73, 0x66, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1a, 0x0a, 0x09, 0x67,
+	0x6e, 0x62, 0x5f, 0x64, 0x75, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07,
+	0x67, 0x6e, 0x62, 0x44, 0x75, 0x49, 0x64, 0x12, 0x28, 0x0a, 0x11, 0x67, 0x6e, 0x62, 0x5f, 0x64,
+	0x75, 0x5f, 0x75, 0x65, 0x5f, 0x66, 0x31, 0x61, 0x70, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0d, 0x52, 0x0d, 0x67, 0x6e, 0x62, 0x44, 0x75, 0x55, 0x65, 0x46, 0x31, 0x61, 0x70, 0x49,
+	0x64, 0x12, 0x10, 0x0a, 0x03, 0x6e, 0x63, 0x69, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x03,
+	0x6e, 0x63, 0x69, 0x12, 0x15, 0x0a, 0x06, 0x63, 0x5f, 0x72, 0x6e, 0x74, 0x69, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x05, 0x63, 0x52, 0x6e, 0x74, 0x69, 0x12, 0x23, 0x0a, 0x0d, 0x72, 0x72,
+	0x63, 0x5f, 0x63, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x0c, 0x52, 0x0c, 0x72, 0x72, 0x63, 0x43, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x22,
+	0x4c, 0x0a, 0x20, 0x49, 0x6e, 0x69, 0x74, 0x69, 0x61, 0x6c, 0x55, 0x4c, 0x52, 0x52, 0x43, 0x4d,
+	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x52, 0x65,
+	0x70, 0x6c, 0x79, 0x12, 0x28, 0x0a, 0x11, 0x67, 0x6e, 0x62, 0x5f, 0x63, 0x75, 0x5f, 0x75, 0x65,
+	0x5f, 0x66, 0x31, 0x61, 0x70, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0d,
+	0x67, 0x6e, 0x62, 0x43, 0x75, 0x55, 0x65, 0x46, 0x31, 0x61, 0x70, 0x49, 0x64, 0x22, 0xab, 0x01,
+	0x0a, 0x19, 0x52, 0x52, 0x43, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x54, 0x72, 0x61, 0x6e,
+	0x73, 0x66, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x28, 0x0a, 0x11, 0x67,
+	0x6e, 0x62, 0x5f, 0x63, 0x75, 0x5f, 0x75, 0x65, 0x5f, 0x66, 0x31, 0x61, 0x70, 0x5f, 0x69, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0d, 0x67, 0x6e, 0x62, 0x43, 0x75, 0x55, 0x65, 0x46,
+	0x31, 0x61, 0x70, 0x49, 0x64, 0x12, 0x28, 0x0a, 0x11, 0x67, 0x6e, 0x62, 0x5f, 0x64, 0x75, 0x5f,
+	0x75, 0x65, 0x5f, 0x66, 0x31, 0x61, 0x70, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x0d, 0x67, 0x6e, 0x62, 0x44, 0x75, 0x55, 0x65, 0x46, 0x31, 0x61, 0x70, 0x49, 0x64, 0x12,
+	0x15, 0x0a, 0x06, 0x73, 0x72, 0x62, 0x5f, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52,
+	0x05, 0x73, 0x72, 0x62, 0x49, 0x64, 0x12, 0x23, 0x0a, 0x0d, 0x72, 0x72, 0x63, 0x5f, 0x63, 0x6f,
+	0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0c, 0x72,
+	0x72, 0x63, 0x43, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x22, 0x19, 0x0a, 0x17, 0x52,
+	0x52, 0x43, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65,
+	0x72, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x6e, 0x0a, 0x18, 0x55, 0x45, 0x52, 0x61, 0x64, 0x69,
+	0x6f, 0x43, 0x61, 0x70, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x28, 0x0a, 0x11, 0x67, 0x6e, 0x62, 0x5f, 0x63, 0x75, 0x5f, 0x75, 0x65, 0x5f,
+	0x66, 0x31, 0x61, 0x70, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0d, 0x67,
+	0x6e, 0x62, 0x43, 0x75, 0x55, 0x65, 0x46, 0x31, 0x61, 0x70, 0x49, 0x64, 0x12, 0x28, 0x0a, 0x11,
+	0x67, 0x6e, 0x62, 0x5f, 0x64, 0x75, 0x5f, 0x75, 0x65, 0x5f, 0x66, 0x31, 0x61, 0x70, 0x5f, 0x69,
+	0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0d, 0x67, 0x6e, 0x62, 0x44, 0x75, 0x55, 0x65,
+	0x46, 0x31, 0x61, 0x70, 0x49, 0x64, 0x22, 0x60, 0x0a, 0x16, 0x55, 0x45, 0x52, 0x61, 0x64, 0x69,
+	0x6f, 0x43, 0x61, 0x70, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x52, 0x65, 0x70, 0x6c, 0x79,
+	0x12, 0x46, 0x0a, 0x20, 0x75, 0x65, 0x5f, 0x63, 0x61, 0x70, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74,
+	0x79, 0x5f, 0x72, 0x61, 0x74, 0x5f, 0x63, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x5f,
+	0x6c, 0x69, 0x73, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x1c, 0x75, 0x65, 0x43, 0x61,
+	0x70, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x52, 0x61, 0x74, 0x43, 0x6f, 0x6e, 0x74, 0x61,
+	0x69, 0x6e, 0x65, 0x72, 0x4c, 0x69, 0x73, 0x74, 0x22, 0xbb, 0x01, 0x0a, 0x15, 0x55, 0x45, 0x43,
+	0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x53, 0x65, 0x74, 0x75, 0x70, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x28, 0x0a, 0x11, 0x67, 0x6e, 0x62, 0x5f, 0x63, 0x75, 0x5f, 0x75, 0x65, 0x5f,
+	0x66, 0x31, 0x61, 0x70, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0d, 0x67,
+	0x6e, 0x62, 0x43, 0x75, 0x55, 0x65, 0x46, 0x31, 0x61, 0x70, 0x49, 0x64, 0x12, 0x28, 0x0a, 0x11,
+	0x67, 0x6e, 0x62, 0x5f, 0x64, 0x75, 0x5f, 0x75, 0x65, 0x5f, 0x66, 0x31, 0x61, 0x70, 0x5f, 0x69,
+	0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0d, 0x67, 0x6e, 0x62, 0x44, 0x75, 0x55, 0x65,
+	0x46, 0x31, 0x61, 0x70, 0x49, 0x64, 0x12, 0x10, 0x0a, 0x03, 0x6e, 0x63, 0x69, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x03, 0x6e, 0x63, 0x69, 0x12, 0x17, 0x0a, 0x07, 0x64, 0x72, 0x62, 0x5f,
+	0x69, 0x64, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0d, 0x52, 0x06, 0x64, 0x72, 0x62, 0x49, 0x64,
+	0x73, 0x12, 0x23, 0x0a, 0x0d, 0x72, 0x72, 0x63, 0x5f, 0x63, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e,
+	0x65, 0x72, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0c, 0x72, 0x72, 0x63, 0x43, 0x6f, 0x6e,
+	0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x22, 0x72, 0x0a, 0x16, 0x55, 0x45, 0x43, 0x6f, 0x6e, 0x74,
+	0x65, 0x78, 0x74, 0x53, 0x65, 0x74, 0x75, 0x70, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x28, 0x0a, 0x11, 0x67, 0x6e, 0x62, 0x5f, 0x64, 0x75, 0x5f, 0x75, 0x65, 0x5f, 0x66, 0x31,
+	0x61, 0x70, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0d, 0x67, 0x6e, 0x62,
+	0x44, 0x75, 0x55, 0x65, 0x46, 0x31, 0x61, 0x70, 0x49, 0x64, 0x12, 0x17, 0x0a, 0x07, 0x64, 0x72,
+	0x62, 0x5f, 0x69, 0x64, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0d, 0x52, 0x06, 0x64, 0x72, 0x62,
+	0x49, 0x64, 0x73, 0x12, 0x15, 0x0a, 0x06, 0x63, 0x5f, 0x72, 0x6e, 0x74, 0x69, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x05, 0x63, 0x52, 0x6e, 0x74, 0x69, 0x22, 0x6d, 0x0a, 0x17, 0x55, 0x45,
+	0x43, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x52, 0x65, 0x6c, 0x65, 0x61, 0x73, 0x65, 0x43, 0x6f,
+	0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x28, 0x0a, 0x11, 0x67, 0x6e, 0x62, 0x5f, 0x63, 0x75, 0x5f,
+	0x75, 0x65, 0x5f, 0x66, 0x31, 0x61, 0x70, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x0d, 0x67, 0x6e, 0x62, 0x43, 0x75, 0x55, 0x65, 0x46, 0x31, 0x61, 0x70, 0x49, 0x64, 0x12,
+	0x28, 0x0a, 0x11, 0x67, 0x6e, 0x62, 0x5f, 0x64, 0x75, 0x5f, 0x75, 0x65, 0x5f, 0x66, 0x31, 0x61,
+	0x70, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0d, 0x67, 0x6e, 0x62, 0x44,
+	0x75, 0x55, 0x65, 0x46, 0x31, 0x61, 0x70, 0x49, 0x64, 0x22, 0x1a, 0x0a, 0x18, 0x55, 0x45, 0x43,
+	0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x52, 0x65, 0x6c, 0x65, 0x61, 0x73, 0x65, 0x43, 0x6f, 0x6d,
+	0x70, 0x6c, 0x65, 0x74, 0x65, 0x32, 0xf2, 0x02, 0x0a, 0x04, 0x46, 0x31, 0x43, 0x55, 0x12, 0x3c,
+	0x0a, 0x07, 0x46, 0x31, 0x53, 0x65, 0x74, 0x75, 0x70, 0x12, 0x16, 0x2e, 0x67, 0x6e, 0x6f, 0x64,
+	0x65, 0x62, 0x2e, 0x46, 0x31, 0x53, 0x65, 0x74, 0x75, 0x70, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x17, 0x2e, 0x67, 0x6e, 0x6f, 0x64, 0x65, 0x62, 0x2e, 0x46, 0x31, 0x53, 0x65, 0x74,
+	0x75, 0x70, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x75, 0x0a, 0x1b,
+	0x49, 0x6e, 0x69, 0x74, 0x69, 0x61, 0x6c, 0x55, 0x4c, 0x52, 0x52, 0x43, 0x4d, 0x65, 0x73, 0x73,
+	0x61, 0x67, 0x65, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x12, 0x2a, 0x2e, 0x67, 0x6e,
+	0x6f, 0x64, 0x65, 0x62, 0x2e, 0x49, 0x6e, 0x69, 0x74, 0x69, 0x61, 0x6c, 0x55, 0x4c, 0x52, 0x52,
+	0x43, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x28, 0x2e, 0x67, 0x6e, 0x6f, 0x64, 0x65, 0x62,
+	0x2e, 0x49, 0x6e, 0x69, 0x74, 0x69, 0x61, 0x6c, 0x55, 0x4c, 0x52, 0x52, 0x43, 0x4d, 0x65, 0x73,
+	0x73, 0x61, 0x67, 0x65, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x52, 0x65, 0x70, 0x6c,
+	0x79, 0x22, 0x00, 0x12, 0x5c, 0x0a, 0x14, 0x55, 0x4c, 0x52, 0x52, 0x43, 0x4d, 0x65, 0x73, 0x73,
+	0x61, 0x67, 0x65, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x12, 0x21, 0x2e, 0x67, 0x6e,
+	0x6f, 0x64, 0x65, 0x62, 0x2e, 0x52, 0x52, 0x43, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x54,
+	0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f,
+	0x2e, 0x67, 0x6e, 0x6f, 0x64, 0x65, 0x62, 0x2e, 0x52, 0x52, 0x43, 0x4d, 0x65, 0x73, 0x73, 0x61,
+	0x67, 0x65, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22,
+	0x00, 0x12, 0x57, 0x0a, 0x11, 0x55, 0x45, 0x52, 0x61, 0x64, 0x69, 0x6f, 0x43, 0x61, 0x70, 0x61,
+	0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x12, 0x20, 0x2e, 0x67, 0x6e, 0x6f, 0x64, 0x65, 0x62, 0x2e,
+	0x55, 0x45, 0x52, 0x61, 0x64, 0x69, 0x6f, 0x43, 0x61, 0x70, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74,
+	0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1e, 0x2e, 0x67, 0x6e, 0x6f, 0x64, 0x65,
+	0x62, 0x2e, 0x55, 0x45, 0x52, 0x61, 0x64, 0x69, 0x6f, 0x43, 0x61, 0x70, 0x61, 0x62, 0x69, 0x6c,
+	0x69, 0x74, 0x79, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x32, 0x90, 0x02, 0x0a, 0x04, 0x46,
+	0x31, 0x44, 0x55, 0x12, 0x51, 0x0a, 0x0e, 0x55, 0x45, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74,
+	0x53, 0x65, 0x74, 0x75, 0x70, 0x12, 0x1d, 0x2e, 0x67, 0x6e, 0x6f, 0x64, 0x65, 0x62, 0x2e, 0x55,
+	0x45, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x53, 0x65, 0x74, 0x75, 0x70, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x1e, 0x2e, 0x67, 0x6e, 0x6f, 0x64, 0x65, 0x62, 0x2e, 0x55, 0x45,
+	0x43, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x53, 0x65, 0x74, 0x75, 0x70, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x5c, 0x0a, 0x14, 0x44, 0x4c, 0x52, 0x52, 0x43, 0x4d,
+	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x12, 0x21,
+	0x2e, 0x67, 0x6e, 0x6f, 0x64, 0x65, 0x62, 0x2e, 0x52, 0x52, 0x43, 0x4d, 0x65, 0x73, 0x73, 0x61,
+	0x67, 0x65, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x1f, 0x2e, 0x67, 0x6e, 0x6f, 0x64, 0x65, 0x62, 0x2e, 0x52, 0x52, 0x43, 0x4d, 0x65,
+	0x73, 0x73, 0x61, 0x67, 0x65, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x52, 0x65, 0x70,
+	0x6c, 0x79, 0x22, 0x00, 0x12, 0x57, 0x0a, 0x10, 0x55, 0x45, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x78,
+	0x74, 0x52, 0x65, 0x6c, 0x65, 0x61, 0x73, 0x65, 0x12, 0x1f, 0x2e, 0x67, 0x6e, 0x6f, 0x64, 0x65,
+	0x62, 0x2e, 0x55, 0x45, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x52, 0x65, 0x6c, 0x65, 0x61,
+	0x73, 0x65, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x1a, 0x20, 0x2e, 0x67, 0x6e, 0x6f, 0x64,
+	0x65, 0x62, 0x2e, 0x55, 0x45, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x52, 0x65, 0x6c, 0x65,
+	0x61, 0x73, 0x65, 0x43, 0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x74, 0x65, 0x22, 0x00, 0x42, 0x37, 0x5a,
+	0x35, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6d, 0x69, 0x6b, 0x69,
+	0x2d, 0x74, 0x6e, 0x74, 0x2f, 0x73, 0x61, 0x35, 0x67, 0x2d, 0x67, 0x6f, 0x2d, 0x75, 0x73, 0x76,
+	0x63, 0x2d, 0x6b, 0x38, 0x73, 0x2f, 0x70, 0x62, 0x2f, 0x67, 0x6e, 0x6f, 0x64, 0x65, 0x62, 0x3b,
+	0x67, 0x6e, 0x6f, 0x64, 0x65, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_gnodeb_f1ap_proto_rawDescOnce sync.Once
+	file_gnodeb_f1ap_proto_rawDescData = file_gnodeb_f1ap_proto_rawDesc
+)
+
+func file_gnodeb_f1ap_proto_rawDescGZIP() []byte {
+	file_gnodeb_f1ap_proto_rawDescOnce.Do(func() {
+		file_gnodeb_f1ap_proto_rawDescData = protoimpl.X.CompressGZIP(file_gnodeb_f1ap_proto_rawDescData)
+	})
+	return file_gnodeb_f1ap_proto_rawDescData
+}
+
+var file_gnodeb_f1ap_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_gnodeb_f1ap_proto_goTypes = []interface{}{
+	(*F1SetupRequest)(nil),                     // 0: gnodeb.F1SetupRequest
+	(*F1SetupResponse)(nil),                    // 1: gnodeb.F1SetupResponse
+	(*InitialULRRCMessageTransferRequest)(nil), // 2: gnodeb.InitialULRRCMessageTransferRequest
+	(*InitialULRRCMessageTransferReply)(nil),   // 3: gnodeb.InitialULRRCMessageTransferReply
+	(*RRCMessageTransferRequest)(nil),          // 4: gnodeb.RRCMessageTransferRequest
+	(*RRCMessageTransferReply)(nil),            // 5: gnodeb.RRCMessageTransferReply
+	(*UERadioCapabilityRequest)(nil),           // 6: gnodeb.UERadioCapabilityRequest
+	(*UERadioCapabilityReply)(nil),             // 7: gnodeb.UERadioCapabilityReply
+	(*UEContextSetupRequest)(nil),              // 8: gnodeb.UEContextSetupRequest
+	(*UEContextSetupResponse)(nil),             // 9: gnodeb.UEContextSetupResponse
+	(*UEContextReleaseCommand)(nil),            // 10: gnodeb.UEContextReleaseCommand
+	(*UEContextReleaseComplete)(nil),           // 11: gnodeb.UEContextReleaseComplete
+	(*Cell)(nil),                               // 12: gnodeb.Cell
+}
+var file_gnodeb_f1ap_proto_depIdxs = []int32{
+	12, // 0: gnodeb.F1SetupRequest.served_cells:type_name -> gnodeb.Cell
+	0,  // 1: gnodeb.F1CU.F1Setup:input_type -> gnodeb.F1SetupRequest
+	2,  // 2: gnodeb.F1CU.InitialULRRCMessageTransfer:input_type -> gnodeb.InitialULRRCMessageTransferRequest
+	4,  // 3: gnodeb.F1CU.ULRRCMessageTransfer:input_type -> gnodeb.RRCMessageTransferRequest
+	6,  // 4: gnodeb.F1CU.UERadioCapability:input_type -> gnodeb.UERadioCapabilityRequest
+	8,  // 5: gnodeb.F1DU.UEContextSetup:input_type -> gnodeb.UEContextSetupRequest
+	4,  // 6: gnodeb.F1DU.DLRRCMessageTransfer:input_type -> gnodeb.RRCMessageTransferRequest
+	10, // 7: gnodeb.F1DU.UEContextRelease:input_type -> gnodeb.UEContextReleaseCommand
+	1,  // 8: gnodeb.F1CU.F1Setup:output_type -> gnodeb.F1SetupResponse
+	3,  // 9: gnodeb.F1CU.InitialULRRCMessageTransfer:output_type -> gnodeb.InitialULRRCMessageTransferReply
+	5,  // 10: gnodeb.F1CU.ULRRCMessageTransfer:output_type -> gnodeb.RRCMessageTransferReply
+	7,  // 11: gnodeb.F1CU.UERadioCapability:output_type -> gnodeb.UERadioCapabilityReply
+	9,  // 12: gnodeb.F1DU.UEContextSetup:output_type -> gnodeb.UEContextSetupResponse
+	5,  // 13: gnodeb.F1DU.DLRRCMessageTransfer:output_type -> gnodeb.RRCMessageTransferReply
+	11, // 14: gnodeb.F1DU.UEContextRelease:output_type -> gnodeb.UEContextReleaseComplete
+	8,  // [8:15] is the sub-list for method output_type
+	1,  // [1:8] is the sub-list for method input_type
+	1,  // [1:1] is the sub-list for extension type_name
+	1,  // [1:1] is the sub-list for extension extendee
+	0,  // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_gnodeb_f1ap_proto_init() }
+func file_gnodeb_f1ap_proto_init() {
+	if File_gnodeb_f1ap_proto != nil {
+		return
+	}
+	file_gnodeb_cell_proto_init()
+	if !protoimpl.UnsafeEnabled {
+		file_gnodeb_f1ap_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*F1SetupRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gnodeb_f1ap_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*F1SetupResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gnodeb_f1ap_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*InitialULRRCMessageTransferRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gnodeb_f1ap_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*InitialULRRCMessageTransferReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gnodeb_f1ap_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RRCMessageTransferRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gnodeb_f1ap_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RRCMessageTransferReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gnodeb_f1ap_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UERadioCapabilityRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gnodeb_f1ap_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UERadioCapabilityReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gnodeb_f1ap_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UEContextSetupRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gnodeb_f1ap_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UEContextSetupResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gnodeb_f1ap_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UEContextReleaseCommand); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gnodeb_f1ap_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UEContextReleaseComplete); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_gnodeb_f1ap_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   12,
+			NumExtensions: 0,
+			NumServices:   2,
+		},
+		GoTypes:           file_gnodeb_f1ap_proto_goTypes,
+		DependencyIndexes: file_gnodeb_f1ap_proto_depIdxs,
+		MessageInfos:      file_gnodeb_f1ap_proto_msgTypes,
+	}.Build()
+	File_gnodeb_f1ap_proto = out.File
+	file_gnodeb_f1ap_proto_rawDesc = nil
+	file_gnodeb_f1ap_proto_goTypes = nil
+	file_gnodeb_f1ap_proto_depIdxs = nil
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConnInterface
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion6
+
+// F1CUClient is the client API for F1CU service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type F1CUClient interface {
+	F1Setup(ctx context.Context, in *F1SetupRequest, opts ...grpc.CallOption) (*F1SetupResponse, error)
+	InitialULRRCMessageTransfer(ctx context.Context, in *InitialULRRCMessageTransferRequest, opts ...grpc.CallOption) (*InitialULRRCMessageTransferReply, error)
+	ULRRCMessageTransfer(ctx context.Context, in *RRCMessageTransferRequest, opts ...grpc.CallOption) (*RRCMessageTransferReply, error)
+	// UERadioCapability is fetched by the gNB-DU when it needs the radio
+	// capability of a UE, which the gNB-CU keeps.
+	UERadioCapability(ctx context.Context, in *UERadioCapabilityRequest, opts ...grpc.CallOption) (*UERadioCapabilityReply, error)
+}
+
+type f1CUClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewF1CUClient(cc grpc.ClientConnInterface) F1CUClient {
+	return &f1CUClient{cc}
+}
+
+func (c *f1CUClient) F1Setup(ctx context.Context, in *F1SetupRequest, opts ...grpc.CallOption) (*F1SetupResponse, error) {
+	out := new(F1SetupResponse)
+	err := c.cc.Invoke(ctx, "/gnodeb.F1CU/F1Setup", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *f1CUClient) InitialULRRCMessageTransfer(ctx context.Context, in *InitialULRRCMessageTransferRequest, opts ...grpc.CallOption) (*InitialULRRCMessageTransferReply, error) {
+	out := new(InitialULRRCMessageTransferReply)
+	err := c.cc.Invoke(ctx, "/gnodeb.F1CU/InitialULRRCMessageTransfer", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *f1CUClient) ULRRCMessageTransfer(ctx context.Context, in *RRCMessageTransferRequest, opts ...grpc.CallOption) (*RRCMessageTransferReply, error) {
+	out := new(RRCMessageTransferReply)
+	err := c.cc.Invoke(ctx, "/gnodeb.F1CU/ULRRCMessageTransfer", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *f1CUClient) UERadioCapability(ctx context.Context, in *UERadioCapabilityRequest, opts ...grpc.CallOption) (*UERadioCapabilityReply, error) {
+	out := new(UERadioCapabilityReply)
+	err := c.cc.Invoke(ctx, "/gnodeb.F1CU/UERadioCapability", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// F1CUServer is the server API for F1CU service.
+type F1CUServer interface {
+	F1Setup(context.Context, *F1SetupRequest) (*F1SetupResponse, error)
+	InitialULRRCMessageTransfer(context.Context, *InitialULRRCMessageTransferRequest) (*InitialULRRCMessageTransferReply, error)
+	ULRRCMessageTransfer(context.Context, *RRCMessageTransferRequest) (*RRCMessageTransferReply, error)
+	// UERadioCapability is fetched by the gNB-DU when it needs the radio
+	// capability of a UE, which the gNB-CU keeps.
+	UERadioCapability(context.Context, *UERadioCapabilityRequest) (*UERadioCapabilityReply, error)
+}
+
+// UnimplementedF1CUServer can be embedded to have forward compatible implementations.
+type UnimplementedF1CUServer struct {
+}
+
+func (*UnimplementedF1CUServer) F1Setup(context.Context, *F1SetupRequest) (*F1SetupResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method F1Setup not implemented")
+}
+func (*UnimplementedF1CUServer) InitialULRRCMessageTransfer(context.Context, *InitialULRRCMessageTransferRequest) (*InitialULRRCMessageTransferReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method InitialULRRCMessageTransfer not implemented")
+}
+func (*UnimplementedF1CUServer) ULRRCMessageTransfer(context.Context, *RRCMessageTransferRequest) (*RRCMessageTransferReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ULRRCMessageTransfer not implemented")
+}
+func (*UnimplementedF1CUServer) UERadioCapability(context.Context, *UERadioCapabilityRequest) (*UERadioCapabilityReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UERadioCapability not implemented")
+}
+
+func RegisterF1CUServer(s *grpc.Server, srv F1CUServer) {
+	s.RegisterService(&_F1CU_serviceDesc, srv)
+}
+
+func _F1CU_F1Setup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(F1SetupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(F1CUServer).F1Setup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gnodeb.F1CU/F1Setup",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(F1CUServer).F1Setup(ctx, req.(*F1SetupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _F1CU_InitialULRRCMessageTransfer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InitialULRRCMessageTransferRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(F1CUServer).InitialULRRCMessageTransfer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gnodeb.F1CU/InitialULRRCMessageTransfer",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(F1CUServer).InitialULRRCMessageTransfer(ctx, req.(*InitialULRRCMessageTransferRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _F1CU_ULRRCMessageTransfer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RRCMessageTransferRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(F1CUServer).ULRRCMessageTransfer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gnodeb.F1CU/ULRRCMessageTransfer",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(F1CUServer).ULRRCMessageTransfer(ctx, req.(*RRCMessageTransferRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _F1CU_UERadioCapability_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UERadioCapabilityRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(F1CUServer).UERadioCapability(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gnodeb.F1CU/UERadioCapability",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(F1CUServer).UERadioCapability(ctx, req.(*UERadioCapabilityRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _F1CU_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "gnodeb.F1CU",
+	HandlerType: (*F1CUServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "F1Setup",
+			Handler:    _F1CU_F1Setup_Handler,
+		},
+		{
+			MethodName: "InitialULRRCMessageTransfer",
+			Handler:    _F1CU_InitialULRRCMessageTransfer_Handler,
+		},
+		{
+			MethodName: "ULRRCMessageTransfer",
+			Handler:    _F1CU_ULRRCMessageTransfer_Handler,
+		},
+		{
+			MethodName: "UERadioCapability",
+			Handler:    _F1CU_UERadioCapability_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "gnodeb/f1ap.proto",
+}
+
+// F1DUClient is the client API for F1DU service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type F1DUClient interface {
+	UEContextSetup(ctx context.Context, in *UEContextSetupRequest, opts ...grpc.CallOption) (*UEContextSetupResponse, error)
+	DLRRCMessageTransfer(ctx context.Context, in *RRCMessageTransferRequest, opts ...grpc.CallOption) (*RRCMessageTransferReply, error)
+	UEContextRelease(ctx context.Context, in *UEContextReleaseCommand, opts ...grpc.CallOption) (*UEContextReleaseComplete, error)
+}
+
+type f1DUClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewF1DUClient(cc grpc.ClientConnInterface) F1DUClient {
+	return &f1DUClient{cc}
+}
+
+func (c *f1DUClient) UEContextSetup(ctx context.Context, in *UEContextSetupRequest, opts ...grpc.CallOption) (*UEContextSetupResponse, error) {
+	out := new(UEContextSetupResponse)
+	err := c.cc.Invoke(ctx, "/gnodeb.F1DU/UEContextSetup", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *f1DUClient) DLRRCMessageTransfer(ctx context.Context, in *RRCMessageTransferRequest, opts ...grpc.CallOption) (*RRCMessageTransferReply, error) {
+	out := new(RRCMessageTransferReply)
+	err := c.cc.Invoke(ctx, "/gnodeb.F1DU/DLRRCMessageTransfer", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *f1DUClient) UEContextRelease(ctx context.Context, in *UEContextReleaseCommand, opts ...grpc.CallOption) (*UEContextReleaseComplete, error) {
+	out := new(UEContextReleaseComplete)
+	err := c.cc.Invoke(ctx, "/gnodeb.F1DU/UEContextRelease", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// F1DUServer is the server API for F1DU service.
+type F1DUServer interface {
+	UEContextSetup(context.Context, *UEContextSetupRequest) (*UEContextSetupResponse, error)
+	DLRRCMessageTransfer(context.Context, *RRCMessageTransferRequest) (*RRCMessageTransferReply, error)
+	UEContextRelease(context.Context, *UEContextReleaseCommand) (*UEContextReleaseComplete, error)
+}
+
+// UnimplementedF1DUServer can be embedded to have forward compatible implementations.
+type UnimplementedF1DUServer struct {
+}
+
+func (*UnimplementedF1DUServer) UEContextSetup(context.Context, *UEContextSetupRequest) (*UEContextSetupResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UEContextSetup not implemented")
+}
+func (*UnimplementedF1DUServer) DLRRCMessageTransfer(context.Context, *RRCMessageTransferRequest) (*RRCMessageTransferReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DLRRCMessageTransfer not implemented")
+}
+func (*UnimplementedF1DUServer) UEContextRelease(context.Context, *UEContextReleaseCommand) (*UEContextReleaseComplete, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UEContextRelease not implemented")
+}
+
+func RegisterF1DUServer(s *grpc.Server, srv F1DUServer) {
+	s.RegisterService(&_F1DU_serviceDesc, srv)
+}
+
+func _F1DU_UEContextSetup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UEContextSetupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(F1DUServer).UEContextSetup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gnodeb.F1DU/UEContextSetup",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(F1DUServer).UEContextSetup(ctx, req.(*UEContextSetupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _F1DU_DLRRCMessageTransfer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RRCMessageTransferRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(F1DUServer).DLRRCMessageTransfer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gnodeb.F1DU/DLRRCMessageTransfer",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(F1DUServer).DLRRCMessageTransfer(ctx, req.(*RRCMessageTransferRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _F1DU_UEContextRelease_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UEContextReleaseCommand)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(F1DUServer).UEContextRelease(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gnodeb.F1DU/UEContextRelease",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(F1DUServer).UEContextRelease(ctx, req.(*UEContextReleaseCommand))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _F1DU_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "gnodeb.F1DU",
+	HandlerType: (*F1DUServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "UEContextSetup",
+			Handler:    _F1DU_UEContextSetup_Handler,
+		},
+		{
+			MethodName: "DLRRCMessageTransfer",
+			Handler:    _F1DU_DLRRCMessageTransfer_Handler,
+		},
+		{
+			MethodName: "UEContextRelease",
+			Handler:    _F1DU_UEContextRelease_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "gnodeb/f1ap.proto",
+}
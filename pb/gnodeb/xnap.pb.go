@@ -0,0 +1,688 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.24.0
+// 	protoc        v3.12.2
+// source: gnodeb/xnap.proto
+
+// XnAP (TS 38.423) rendered over gRPC, between the gNB-CUs of neighbour gNBs,
+// for the handovers of their UEs.
+
+package gnodeb
+
+import (
+	context "context"
+	proto "github.com/golang/protobuf/proto"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// This is a compile-time assertion that a sufficiently up-to-date version
+// of the legacy proto package is being used.
+const _ = proto.ProtoPackageIsVersion4
+
+type XnHandoverRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The gNB ID of the source, as ID/LENGTH.
+	SourceGnbId                  string   `protobuf:"bytes,1,opt,name=source_gnb_id,json=sourceGnbId,proto3" json:"source_gnb_id,omitempty"`
+	SourceNgRanNodeUeXnapId      uint32   `protobuf:"varint,2,opt,name=source_ng_ran_node_ue_xnap_id,json=sourceNgRanNodeUeXnapId,proto3" json:"source_ng_ran_node_ue_xnap_id,omitempty"`
+	TargetNci                    uint64   `protobuf:"varint,3,opt,name=target_nci,json=targetNci,proto3" json:"target_nci,omitempty"`
+	Ng_5GSTmsi                   string   `protobuf:"bytes,4,opt,name=ng_5g_s_tmsi,json=ng5gSTmsi,proto3" json:"ng_5g_s_tmsi,omitempty"`
+	UeCapabilityRatContainerList []byte   `protobuf:"bytes,5,opt,name=ue_capability_rat_container_list,json=ueCapabilityRatContainerList,proto3" json:"ue_capability_rat_container_list,omitempty"`
+	DrbIds                       []uint32 `protobuf:"varint,6,rep,packed,name=drb_ids,json=drbIds,proto3" json:"drb_ids,omitempty"`
+}
+
+func (x *XnHandoverRequest) Reset() {
+	*x = XnHandoverRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gnodeb_xnap_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *XnHandoverRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*XnHandoverRequest) ProtoMessage() {}
+
+func (x *XnHandoverRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gnodeb_xnap_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use XnHandoverRequest.ProtoReflect.Descriptor instead.
+func (*XnHandoverRequest) Descriptor() ([]byte, []int) {
+	return file_gnodeb_xnap_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *XnHandoverRequest) GetSourceGnbId() string {
+	if x != nil {
+		return x.SourceGnbId
+	}
+	return ""
+}
+
+func (x *XnHandoverRequest) GetSourceNgRanNodeUeXnapId() uint32 {
+	if x != nil {
+		return x.SourceNgRanNodeUeXnapId
+	}
+	return 0
+}
+
+func (x *XnHandoverRequest) GetTargetNci() uint64 {
+	if x != nil {
+		return x.TargetNci
+	}
+	return 0
+}
+
+func (x *XnHandoverRequest) GetNg_5GSTmsi() string {
+	if x != nil {
+		return x.Ng_5GSTmsi
+	}
+	return ""
+}
+
+func (x *XnHandoverRequest) GetUeCapabilityRatContainerList() []byte {
+	if x != nil {
+		return x.UeCapabilityRatContainerList
+	}
+	return nil
+}
+
+func (x *XnHandoverRequest) GetDrbIds() []uint32 {
+	if x != nil {
+		return x.DrbIds
+	}
+	return nil
+}
+
+type XnHandoverRequestAcknowledge struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	TargetNgRanNodeUeXnapId uint32   `protobuf:"varint,1,opt,name=target_ng_ran_node_ue_xnap_id,json=targetNgRanNodeUeXnapId,proto3" json:"target_ng_ran_node_ue_xnap_id,omitempty"`
+	DrbIds                  []uint32 `protobuf:"varint,2,rep,packed,name=drb_ids,json=drbIds,proto3" json:"drb_ids,omitempty"`
+	RrcContainer            []byte   `protobuf:"bytes,3,opt,name=rrc_container,json=rrcContainer,proto3" json:"rrc_container,omitempty"`
+}
+
+func (x *XnHandoverRequestAcknowledge) Reset() {
+	*x = XnHandoverRequestAcknowledge{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gnodeb_xnap_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *XnHandoverRequestAcknowledge) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*XnHandoverRequestAcknowledge) ProtoMessage() {}
+
+func (x *XnHandoverRequestAcknowledge) ProtoReflect() protoreflect.Message {
+	mi := &file_gnodeb_xnap_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use XnHandoverRequestAcknowledge.ProtoReflect.Descriptor instead.
+func (*XnHandoverRequestAcknowledge) Descriptor() ([]byte, []int) {
+	return file_gnodeb_xnap_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *XnHandoverRequestAcknowledge) GetTargetNgRanNodeUeXnapId() uint32 {
+	if x != nil {
+		return x.TargetNgRanNodeUeXnapId
+	}
+	return 0
+}
+
+func (x *XnHandoverRequestAcknowledge) GetDrbIds() []uint32 {
+	if x != nil {
+		return x.DrbIds
+	}
+	return nil
+}
+
+func (x *XnHandoverRequestAcknowledge) GetRrcContainer() []byte {
+	if x != nil {
+		return x.RrcContainer
+	}
+	return nil
+}
+
+type XnHandoverCancel struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	SourceNgRanNodeUeXnapId uint32 `protobuf:"varint,1,opt,name=source_ng_ran_node_ue_xnap_id,json=sourceNgRanNodeUeXnapId,proto3" json:"source_ng_ran_node_ue_xnap_id,omitempty"`
+	TargetNgRanNodeUeXnapId uint32 `protobuf:"varint,2,opt,name=target_ng_ran_node_ue_xnap_id,json=targetNgRanNodeUeXnapId,proto3" json:"target_ng_ran_node_ue_xnap_id,omitempty"`
+}
+
+func (x *XnHandoverCancel) Reset() {
+	*x = XnHandoverCancel{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gnodeb_xnap_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *XnHandoverCancel) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*XnHandoverCancel) ProtoMessage() {}
+
+func (x *XnHandoverCancel) ProtoReflect() protoreflect.Message {
+	mi := &file_gnodeb_xnap_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use XnHandoverCancel.ProtoReflect.Descriptor instead.
+func (*XnHandoverCancel) Descriptor() ([]byte, []int) {
+	return file_gnodeb_xnap_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *XnHandoverCancel) GetSourceNgRanNodeUeXnapId() uint32 {
+	if x != nil {
+		return x.SourceNgRanNodeUeXnapId
+	}
+	return 0
+}
+
+func (x *XnHandoverCancel) GetTargetNgRanNodeUeXnapId() uint32 {
+	if x != nil {
+		return x.TargetNgRanNodeUeXnapId
+	}
+	return 0
+}
+
+type XnUEContextRelease struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	SourceNgRanNodeUeXnapId uint32 `protobuf:"varint,1,opt,name=source_ng_ran_node_ue_xnap_id,json=sourceNgRanNodeUeXnapId,proto3" json:"source_ng_ran_node_ue_xnap_id,omitempty"`
+	TargetNgRanNodeUeXnapId uint32 `protobuf:"varint,2,opt,name=target_ng_ran_node_ue_xnap_id,json=targetNgRanNodeUeXnapId,proto3" json:"target_ng_ran_node_ue_xnap_id,omitempty"`
+}
+
+func (x *XnUEContextRelease) Reset() {
+	*x = XnUEContextRelease{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gnodeb_xnap_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *XnUEContextRelease) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*XnUEContextRelease) ProtoMessage() {}
+
+func (x *XnUEContextRelease) ProtoReflect() protoreflect.Message {
+	mi := &file_gnodeb_xnap_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use XnUEContextRelease.ProtoReflect.Descriptor instead.
+func (*XnUEContextRelease) Descriptor() ([]byte, []int) {
+	return file_gnodeb_xnap_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *XnUEContextRelease) GetSourceNgRanNodeUeXnapId() uint32 {
+	if x != nil {
+		return x.SourceNgRanNodeUeXnapId
+	}
+	return 0
+}
+
+func (x *XnUEContextRelease) GetTargetNgRanNodeUeXnapId() uint32 {
+	if x != nil {
+		return x.TargetNgRanNodeUeXnapId
+	}
+	return 0
+}
+
+type XnReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *XnReply) Reset() {
+	*x = XnReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gnodeb_xnap_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *XnReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*XnReply) ProtoMessage() {}
+
+func (x *XnReply) ProtoReflect() protoreflect.Message {
+	mi := &file_gnodeb_xnap_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use XnReply.ProtoReflect.Descriptor instead.
+func (*XnReply) Descriptor() ([]byte, []int) {
+	return file_gnodeb_xnap_proto_rawDescGZIP(), []int{4}
+}
+
+var File_gnodeb_xnap_proto protoreflect.FileDescriptor
+
+var file_gnodeb_xnap_proto_rawDesc = []byte{
+	0x0a, 0x11, 0x67, 0x6e, 0x6f, 0x64, 0x65, 0x62, 0x2f, 0x78, 0x6e, 0x61, 0x70, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x12, 0x06, 0x67, 0x6e, 0x6f, 0x64, 0x65, 0x62, 0x22, 0x98, 0x02, 0x0a, 0x11,
+	0x58, 0x6e, 0x48, 0x61, 0x6e, 0x64, 0x6f, 0x76, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x22, 0x0a, 0x0d, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x5f, 0x67, 0x6e, 0x62, 0x5f,
+	0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65,
+	0x47, 0x6e, 0x62, 0x49, 0x64, 0x12, 0x3e, 0x0a, 0x1d, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x5f,
+	0x6e, 0x67, 0x5f, 0x72, 0x61, 0x6e, 0x5f, 0x6e, 0x6f, 0x64, 0x65, 0x5f, 0x75, 0x65, 0x5f, 0x78,
+	0x6e, 0x61, 0x70, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x17, 0x73, 0x6f,
+	0x75, 0x72, 0x63, 0x65, 0x4e, 0x67, 0x52, 0x61, 0x6e, 0x4e, 0x6f, 0x64, 0x65, 0x55, 0x65, 0x58,
+	0x6e, 0x61, 0x70, 0x49, 0x64, 0x12, 0x1d, 0x0a, 0x0a, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x5f,
+	0x6e, 0x63, 0x69, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x74, 0x61, 0x72, 0x67, 0x65,
+	0x74, 0x4e, 0x63, 0x69, 0x12, 0x1f, 0x0a, 0x0c, 0x6e, 0x67, 0x5f, 0x35, 0x67, 0x5f, 0x73, 0x5f,
+	0x74, 0x6d, 0x73, 0x69, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6e, 0x67, 0x35, 0x67,
+	0x53, 0x54, 0x6d, 0x73, 0x69, 0x12, 0x46, 0x0a, 0x20, 0x75, 0x65, 0x5f, 0x63, 0x61, 0x70, 0x61,
+	0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x5f, 0x72, 0x61, 0x74, 0x5f, 0x63, 0x6f, 0x6e, 0x74, 0x61,
+	0x69, 0x6e, 0x65, 0x72, 0x5f, 0x6c, 0x69, 0x73, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0c, 0x52,
+	0x1c, 0x75, 0x65, 0x43, 0x61, 0x70, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x52, 0x61, 0x74,
+	0x43, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x17, 0x0a,
+	0x07, 0x64, 0x72, 0x62, 0x5f, 0x69, 0x64, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0d, 0x52, 0x06,
+	0x64, 0x72, 0x62, 0x49, 0x64, 0x73, 0x22, 0x9c, 0x01, 0x0a, 0x1c, 0x58, 0x6e, 0x48, 0x61, 0x6e,
+	0x64, 0x6f, 0x76, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x41, 0x63, 0x6b, 0x6e,
+	0x6f, 0x77, 0x6c, 0x65, 0x64, 0x67, 0x65, 0x12, 0x3e, 0x0a, 0x1d, 0x74, 0x61, 0x72, 0x67, 0x65,
+	0x74, 0x5f, 0x6e, 0x67, 0x5f, 0x72, 0x61, 0x6e, 0x5f, 0x6e, 0x6f, 0x64, 0x65, 0x5f, 0x75, 0x65,
+	0x5f, 0x78, 0x6e, 0x61, 0x70, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x17,
+	0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x4e, 0x67, 0x52, 0x61, 0x6e, 0x4e, 0x6f, 0x64, 0x65, 0x55,
+	0x65, 0x58, 0x6e, 0x61, 0x70, 0x49, 0x64, 0x12, 0x17, 0x0a, 0x07, 0x64, 0x72, 0x62, 0x5f, 0x69,
+	0x64, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0d, 0x52, 0x06, 0x64, 0x72, 0x62, 0x49, 0x64, 0x73,
+	0x12, 0x23, 0x0a, 0x0d, 0x72, 0x72, 0x63, 0x5f, 0x63, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65,
+	0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0c, 0x72, 0x72, 0x63, 0x43, 0x6f, 0x6e, 0x74,
+	0x61, 0x69, 0x6e, 0x65, 0x72, 0x22, 0x92, 0x01, 0x0a, 0x10, 0x58, 0x6e, 0x48, 0x61, 0x6e, 0x64,
+	0x6f, 0x76, 0x65, 0x72, 0x43, 0x61, 0x6e, 0x63, 0x65, 0x6c, 0x12, 0x3e, 0x0a, 0x1d, 0x73, 0x6f,
+	0x75, 0x72, 0x63, 0x65, 0x5f, 0x6e, 0x67, 0x5f, 0x72, 0x61, 0x6e, 0x5f, 0x6e, 0x6f, 0x64, 0x65,
+	0x5f, 0x75, 0x65, 0x5f, 0x78, 0x6e, 0x61, 0x70, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0d, 0x52, 0x17, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x4e, 0x67, 0x52, 0x61, 0x6e, 0x4e, 0x6f,
+	0x64, 0x65, 0x55, 0x65, 0x58, 0x6e, 0x61, 0x70, 0x49, 0x64, 0x12, 0x3e, 0x0a, 0x1d, 0x74, 0x61,
+	0x72, 0x67, 0x65, 0x74, 0x5f, 0x6e, 0x67, 0x5f, 0x72, 0x61, 0x6e, 0x5f, 0x6e, 0x6f, 0x64, 0x65,
+	0x5f, 0x75, 0x65, 0x5f, 0x78, 0x6e, 0x61, 0x70, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0d, 0x52, 0x17, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x4e, 0x67, 0x52, 0x61, 0x6e, 0x4e, 0x6f,
+	0x64, 0x65, 0x55, 0x65, 0x58, 0x6e, 0x61, 0x70, 0x49, 0x64, 0x22, 0x94, 0x01, 0x0a, 0x12, 0x58,
+	0x6e, 0x55, 0x45, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x52, 0x65, 0x6c, 0x65, 0x61, 0x73,
+	0x65, 0x12, 0x3e, 0x0a, 0x1d, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x5f, 0x6e, 0x67, 0x5f, 0x72,
+	0x61, 0x6e, 0x5f, 0x6e, 0x6f, 0x64, 0x65, 0x5f, 0x75, 0x65, 0x5f, 0x78, 0x6e, 0x61, 0x70, 0x5f,
+	0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x17, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65,
+	0x4e, 0x67, 0x52, 0x61, 0x6e, 0x4e, 0x6f, 0x64, 0x65, 0x55, 0x65, 0x58, 0x6e, 0x61, 0x70, 0x49,
+	0x64, 0x12, 0x3e, 0x0a, 0x1d, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x5f, 0x6e, 0x67, 0x5f, 0x72,
+	0x61, 0x6e, 0x5f, 0x6e, 0x6f, 0x64, 0x65, 0x5f, 0x75, 0x65, 0x5f, 0x78, 0x6e, 0x61, 0x70, 0x5f,
+	0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x17, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74,
+	0x4e, 0x67, 0x52, 0x61, 0x6e, 0x4e, 0x6f, 0x64, 0x65, 0x55, 0x65, 0x58, 0x6e, 0x61, 0x70, 0x49,
+	0x64, 0x22, 0x09, 0x0a, 0x07, 0x58, 0x6e, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x32, 0xe2, 0x01, 0x0a,
+	0x04, 0x58, 0x6e, 0x41, 0x50, 0x12, 0x58, 0x0a, 0x13, 0x48, 0x61, 0x6e, 0x64, 0x6f, 0x76, 0x65,
+	0x72, 0x50, 0x72, 0x65, 0x70, 0x61, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x19, 0x2e, 0x67,
+	0x6e, 0x6f, 0x64, 0x65, 0x62, 0x2e, 0x58, 0x6e, 0x48, 0x61, 0x6e, 0x64, 0x6f, 0x76, 0x65, 0x72,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x24, 0x2e, 0x67, 0x6e, 0x6f, 0x64, 0x65, 0x62,
+	0x2e, 0x58, 0x6e, 0x48, 0x61, 0x6e, 0x64, 0x6f, 0x76, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x41, 0x63, 0x6b, 0x6e, 0x6f, 0x77, 0x6c, 0x65, 0x64, 0x67, 0x65, 0x22, 0x00, 0x12,
+	0x3d, 0x0a, 0x0e, 0x48, 0x61, 0x6e, 0x64, 0x6f, 0x76, 0x65, 0x72, 0x43, 0x61, 0x6e, 0x63, 0x65,
+	0x6c, 0x12, 0x18, 0x2e, 0x67, 0x6e, 0x6f, 0x64, 0x65, 0x62, 0x2e, 0x58, 0x6e, 0x48, 0x61, 0x6e,
+	0x64, 0x6f, 0x76, 0x65, 0x72, 0x43, 0x61, 0x6e, 0x63, 0x65, 0x6c, 0x1a, 0x0f, 0x2e, 0x67, 0x6e,
+	0x6f, 0x64, 0x65, 0x62, 0x2e, 0x58, 0x6e, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x41,
+	0x0a, 0x10, 0x55, 0x45, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x52, 0x65, 0x6c, 0x65, 0x61,
+	0x73, 0x65, 0x12, 0x1a, 0x2e, 0x67, 0x6e, 0x6f, 0x64, 0x65, 0x62, 0x2e, 0x58, 0x6e, 0x55, 0x45,
+	0x43, 0x6f, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x52, 0x65, 0x6c, 0x65, 0x61, 0x73, 0x65, 0x1a, 0x0f,
+	0x2e, 0x67, 0x6e, 0x6f, 0x64, 0x65, 0x62, 0x2e, 0x58, 0x6e, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22,
+	0x00, 0x42, 0x37, 0x5a, 0x35, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f,
+	0x6d, 0x69, 0x6b, 0x69, 0x2d, 0x74, 0x6e, 0x74, 0x2f, 0x73, 0x61, 0x35, 0x67, 0x2d, 0x67, 0x6f,
+	0x2d, 0x75, 0x73, 0x76, 0x63, 0x2d, 0x6b, 0x38, 0x73, 0x2f, 0x70, 0x62, 0x2f, 0x67, 0x6e, 0x6f,
+	0x64, 0x65, 0x62, 0x3b, 0x67, 0x6e, 0x6f, 0x64, 0x65, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x33,
+}
+
+var (
+	file_gnodeb_xnap_proto_rawDescOnce sync.Once
+	file_gnodeb_xnap_proto_rawDescData = file_gnodeb_xnap_proto_rawDesc
+)
+
+func file_gnodeb_xnap_proto_rawDescGZIP() []byte {
+	file_gnodeb_xnap_proto_rawDescOnce.Do(func() {
+		file_gnodeb_xnap_proto_rawDescData = protoimpl.X.CompressGZIP(file_gnodeb_xnap_proto_rawDescData)
+	})
+	return file_gnodeb_xnap_proto_rawDescData
+}
+
+var file_gnodeb_xnap_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_gnodeb_xnap_proto_goTypes = []interface{}{
+	(*XnHandoverRequest)(nil),            // 0: gnodeb.XnHandoverRequest
+	(*XnHandoverRequestAcknowledge)(nil), // 1: gnodeb.XnHandoverRequestAcknowledge
+	(*XnHandoverCancel)(nil),             // 2: gnodeb.XnHandoverCancel
+	(*XnUEContextRelease)(nil),           // 3: gnodeb.XnUEContextRelease
+	(*XnReply)(nil),                      // 4: gnodeb.XnReply
+}
+var file_gnodeb_xnap_proto_depIdxs = []int32{
+	0, // 0: gnodeb.XnAP.HandoverPreparation:input_type -> gnodeb.XnHandoverRequest
+	2, // 1: gnodeb.XnAP.HandoverCancel:input_type -> gnodeb.XnHandoverCancel
+	3, // 2: gnodeb.XnAP.UEContextRelease:input_type -> gnodeb.XnUEContextRelease
+	1, // 3: gnodeb.XnAP.HandoverPreparation:output_type -> gnodeb.XnHandoverRequestAcknowledge
+	4, // 4: gnodeb.XnAP.HandoverCancel:output_type -> gnodeb.XnReply
+	4, // 5: gnodeb.XnAP.UEContextRelease:output_type -> gnodeb.XnReply
+	3, // [3:6] is the sub-list for method output_type
+	0, // [0:3] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_gnodeb_xnap_proto_init() }
+func file_gnodeb_xnap_proto_init() {
+	if File_gnodeb_xnap_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_gnodeb_xnap_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*XnHandoverRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gnodeb_xnap_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*XnHandoverRequestAcknowledge); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gnodeb_xnap_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*XnHandoverCancel); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gnodeb_xnap_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*XnUEContextRelease); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gnodeb_xnap_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*XnReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_gnodeb_xnap_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   5,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_gnodeb_xnap_proto_goTypes,
+		DependencyIndexes: file_gnodeb_xnap_proto_depIdxs,
+		MessageInfos:      file_gnodeb_xnap_proto_msgTypes,
+	}.Build()
+	File_gnodeb_xnap_proto = out.File
+	file_gnodeb_xnap_proto_rawDesc = nil
+	file_gnodeb_xnap_proto_goTypes = nil
+	file_gnodeb_xnap_proto_depIdxs = nil
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConnInterface
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion6
+
+// XnAPClient is the client API for XnAP service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type XnAPClient interface {
+	// HandoverPreparation is called by the source gNB-CU on the gNB-CU of
+	// the target cell.
+	HandoverPreparation(ctx context.Context, in *XnHandoverRequest, opts ...grpc.CallOption) (*XnHandoverRequestAcknowledge, error)
+	// HandoverCancel is called by the source gNB-CU on the target.
+	HandoverCancel(ctx context.Context, in *XnHandoverCancel, opts ...grpc.CallOption) (*XnReply, error)
+	// UEContextRelease is called by the target gNB-CU on the source, once
+	// the UE reached the target cell.
+	UEContextRelease(ctx context.Context, in *XnUEContextRelease, opts ...grpc.CallOption) (*XnReply, error)
+}
+
+type xnAPClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewXnAPClient(cc grpc.ClientConnInterface) XnAPClient {
+	return &xnAPClient{cc}
+}
+
+func (c *xnAPClient) HandoverPreparation(ctx context.Context, in *XnHandoverRequest, opts ...grpc.CallOption) (*XnHandoverRequestAcknowledge, error) {
+	out := new(XnHandoverRequestAcknowledge)
+	err := c.cc.Invoke(ctx, "/gnodeb.XnAP/HandoverPreparation", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *xnAPClient) HandoverCancel(ctx context.Context, in *XnHandoverCancel, opts ...grpc.CallOption) (*XnReply, error) {
+	out := new(XnReply)
+	err := c.cc.Invoke(ctx, "/gnodeb.XnAP/HandoverCancel", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *xnAPClient) UEContextRelease(ctx context.Context, in *XnUEContextRelease, opts ...grpc.CallOption) (*XnReply, error) {
+	out := new(XnReply)
+	err := c.cc.Invoke(ctx, "/gnodeb.XnAP/UEContextRelease", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// XnAPServer is the server API for XnAP service.
+type XnAPServer interface {
+	// HandoverPreparation is called by the source gNB-CU on the gNB-CU of
+	// the target cell.
+	HandoverPreparation(context.Context, *XnHandoverRequest) (*XnHandoverRequestAcknowledge, error)
+	// HandoverCancel is called by the source gNB-CU on the target.
+	HandoverCancel(context.Context, *XnHandoverCancel) (*XnReply, error)
+	// UEContextRelease is called by the target gNB-CU on the source, once
+	// the UE reached the target cell.
+	UEContextRelease(context.Context, *XnUEContextRelease) (*XnReply, error)
+}
+
+// UnimplementedXnAPServer can be embedded to have forward compatible implementations.
+type UnimplementedXnAPServer struct {
+}
+
+func (*UnimplementedXnAPServer) HandoverPreparation(context.Context, *XnHandoverRequest) (*XnHandoverRequestAcknowledge, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method HandoverPreparation not implemented")
+}
+func (*UnimplementedXnAPServer) HandoverCancel(context.Context, *XnHandoverCancel) (*XnReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method HandoverCancel not implemented")
+}
+func (*UnimplementedXnAPServer) UEContextRelease(context.Context, *XnUEContextRelease) (*XnReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UEContextRelease not implemented")
+}
+
+func RegisterXnAPServer(s *grpc.Server, srv XnAPServer) {
+	s.RegisterService(&_XnAP_serviceDesc, srv)
+}
+
+func _XnAP_HandoverPreparation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(XnHandoverRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(XnAPServer).HandoverPreparation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gnodeb.XnAP/HandoverPreparation",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(XnAPServer).HandoverPreparation(ctx, req.(*XnHandoverRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _XnAP_HandoverCancel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(XnHandoverCancel)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(XnAPServer).HandoverCancel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gnodeb.XnAP/HandoverCancel",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(XnAPServer).HandoverCancel(ctx, req.(*XnHandoverCancel))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _XnAP_UEContextRelease_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(XnUEContextRelease)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(XnAPServer).UEContextRelease(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/gnodeb.XnAP/UEContextRelease",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(XnAPServer).UEContextRelease(ctx, req.(*XnUEContextRelease))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _XnAP_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "gnodeb.XnAP",
+	HandlerType: (*XnAPServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "HandoverPreparation",
+			Handler:    _XnAP_HandoverPreparation_Handler,
+		},
+		{
+			MethodName: "HandoverCancel",
+			Handler:    _XnAP_HandoverCancel_Handler,
+		},
+		{
+			MethodName: "UEContextRelease",
+			Handler:    _XnAP_UEContextRelease_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "gnodeb/xnap.proto",
+}
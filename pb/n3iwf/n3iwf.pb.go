@@ -0,0 +1,574 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.24.0
+// 	protoc        v3.12.2
+// source: n3iwf.proto
+
+package pb
+
+import (
+	context "context"
+	proto "github.com/golang/protobuf/proto"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// This is a compile-time assertion that a sufficiently up-to-date version
+// of the legacy proto package is being used.
+const _ = proto.ProtoPackageIsVersion4
+
+type ListUEsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *ListUEsRequest) Reset() {
+	*x = ListUEsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_n3iwf_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListUEsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListUEsRequest) ProtoMessage() {}
+
+func (x *ListUEsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_n3iwf_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListUEsRequest.ProtoReflect.Descriptor instead.
+func (*ListUEsRequest) Descriptor() ([]byte, []int) {
+	return file_n3iwf_proto_rawDescGZIP(), []int{0}
+}
+
+// UE is a UE connected through the NWu: its NGAP IDs, the inner address
+// of its IKE SA and the address it connects from on the untrusted access.
+type UE struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RanUeNgapId   uint64 `protobuf:"varint,1,opt,name=ran_ue_ngap_id,json=ranUeNgapId,proto3" json:"ran_ue_ngap_id,omitempty"`
+	AmfUeNgapId   uint64 `protobuf:"varint,2,opt,name=amf_ue_ngap_id,json=amfUeNgapId,proto3" json:"amf_ue_ngap_id,omitempty"`
+	State         string `protobuf:"bytes,3,opt,name=state,proto3" json:"state,omitempty"`
+	InnerIp       string `protobuf:"bytes,4,opt,name=inner_ip,json=innerIp,proto3" json:"inner_ip,omitempty"`
+	RemoteAddress string `protobuf:"bytes,5,opt,name=remote_address,json=remoteAddress,proto3" json:"remote_address,omitempty"`
+}
+
+func (x *UE) Reset() {
+	*x = UE{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_n3iwf_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UE) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UE) ProtoMessage() {}
+
+func (x *UE) ProtoReflect() protoreflect.Message {
+	mi := &file_n3iwf_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UE.ProtoReflect.Descriptor instead.
+func (*UE) Descriptor() ([]byte, []int) {
+	return file_n3iwf_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *UE) GetRanUeNgapId() uint64 {
+	if x != nil {
+		return x.RanUeNgapId
+	}
+	return 0
+}
+
+func (x *UE) GetAmfUeNgapId() uint64 {
+	if x != nil {
+		return x.AmfUeNgapId
+	}
+	return 0
+}
+
+func (x *UE) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *UE) GetInnerIp() string {
+	if x != nil {
+		return x.InnerIp
+	}
+	return ""
+}
+
+func (x *UE) GetRemoteAddress() string {
+	if x != nil {
+		return x.RemoteAddress
+	}
+	return ""
+}
+
+type ListUEsReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Ues []*UE  `protobuf:"bytes,1,rep,name=ues,proto3" json:"ues,omitempty"`
+	Err string `protobuf:"bytes,2,opt,name=err,proto3" json:"err,omitempty"`
+}
+
+func (x *ListUEsReply) Reset() {
+	*x = ListUEsReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_n3iwf_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListUEsReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListUEsReply) ProtoMessage() {}
+
+func (x *ListUEsReply) ProtoReflect() protoreflect.Message {
+	mi := &file_n3iwf_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListUEsReply.ProtoReflect.Descriptor instead.
+func (*ListUEsReply) Descriptor() ([]byte, []int) {
+	return file_n3iwf_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ListUEsReply) GetUes() []*UE {
+	if x != nil {
+		return x.Ues
+	}
+	return nil
+}
+
+func (x *ListUEsReply) GetErr() string {
+	if x != nil {
+		return x.Err
+	}
+	return ""
+}
+
+type ReleaseUERequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RanUeNgapId uint64 `protobuf:"varint,1,opt,name=ran_ue_ngap_id,json=ranUeNgapId,proto3" json:"ran_ue_ngap_id,omitempty"`
+}
+
+func (x *ReleaseUERequest) Reset() {
+	*x = ReleaseUERequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_n3iwf_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ReleaseUERequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReleaseUERequest) ProtoMessage() {}
+
+func (x *ReleaseUERequest) ProtoReflect() protoreflect.Message {
+	mi := &file_n3iwf_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReleaseUERequest.ProtoReflect.Descriptor instead.
+func (*ReleaseUERequest) Descriptor() ([]byte, []int) {
+	return file_n3iwf_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ReleaseUERequest) GetRanUeNgapId() uint64 {
+	if x != nil {
+		return x.RanUeNgapId
+	}
+	return 0
+}
+
+type ReleaseUEReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Err string `protobuf:"bytes,1,opt,name=err,proto3" json:"err,omitempty"`
+}
+
+func (x *ReleaseUEReply) Reset() {
+	*x = ReleaseUEReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_n3iwf_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ReleaseUEReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReleaseUEReply) ProtoMessage() {}
+
+func (x *ReleaseUEReply) ProtoReflect() protoreflect.Message {
+	mi := &file_n3iwf_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReleaseUEReply.ProtoReflect.Descriptor instead.
+func (*ReleaseUEReply) Descriptor() ([]byte, []int) {
+	return file_n3iwf_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ReleaseUEReply) GetErr() string {
+	if x != nil {
+		return x.Err
+	}
+	return ""
+}
+
+var File_n3iwf_proto protoreflect.FileDescriptor
+
+var file_n3iwf_proto_rawDesc = []byte{
+	0x0a, 0x0b, 0x6e, 0x33, 0x69, 0x77, 0x66, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x02, 0x70,
+	0x62, 0x22, 0x10, 0x0a, 0x0e, 0x4c, 0x69, 0x73, 0x74, 0x55, 0x45, 0x73, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x22, 0xa6, 0x01, 0x0a, 0x02, 0x55, 0x45, 0x12, 0x23, 0x0a, 0x0e, 0x72, 0x61,
+	0x6e, 0x5f, 0x75, 0x65, 0x5f, 0x6e, 0x67, 0x61, 0x70, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x04, 0x52, 0x0b, 0x72, 0x61, 0x6e, 0x55, 0x65, 0x4e, 0x67, 0x61, 0x70, 0x49, 0x64, 0x12,
+	0x23, 0x0a, 0x0e, 0x61, 0x6d, 0x66, 0x5f, 0x75, 0x65, 0x5f, 0x6e, 0x67, 0x61, 0x70, 0x5f, 0x69,
+	0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0b, 0x61, 0x6d, 0x66, 0x55, 0x65, 0x4e, 0x67,
+	0x61, 0x70, 0x49, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x12, 0x19, 0x0a, 0x08, 0x69, 0x6e,
+	0x6e, 0x65, 0x72, 0x5f, 0x69, 0x70, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x69, 0x6e,
+	0x6e, 0x65, 0x72, 0x49, 0x70, 0x12, 0x25, 0x0a, 0x0e, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f,
+	0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x72,
+	0x65, 0x6d, 0x6f, 0x74, 0x65, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x22, 0x3a, 0x0a, 0x0c,
+	0x4c, 0x69, 0x73, 0x74, 0x55, 0x45, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x18, 0x0a, 0x03,
+	0x75, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x06, 0x2e, 0x70, 0x62, 0x2e, 0x55,
+	0x45, 0x52, 0x03, 0x75, 0x65, 0x73, 0x12, 0x10, 0x0a, 0x03, 0x65, 0x72, 0x72, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x03, 0x65, 0x72, 0x72, 0x22, 0x37, 0x0a, 0x10, 0x52, 0x65, 0x6c, 0x65,
+	0x61, 0x73, 0x65, 0x55, 0x45, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x23, 0x0a, 0x0e,
+	0x72, 0x61, 0x6e, 0x5f, 0x75, 0x65, 0x5f, 0x6e, 0x67, 0x61, 0x70, 0x5f, 0x69, 0x64, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x04, 0x52, 0x0b, 0x72, 0x61, 0x6e, 0x55, 0x65, 0x4e, 0x67, 0x61, 0x70, 0x49,
+	0x64, 0x22, 0x22, 0x0a, 0x0e, 0x52, 0x65, 0x6c, 0x65, 0x61, 0x73, 0x65, 0x55, 0x45, 0x52, 0x65,
+	0x70, 0x6c, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x65, 0x72, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x03, 0x65, 0x72, 0x72, 0x32, 0x73, 0x0a, 0x05, 0x4e, 0x33, 0x69, 0x77, 0x66, 0x12, 0x31,
+	0x0a, 0x07, 0x4c, 0x69, 0x73, 0x74, 0x55, 0x45, 0x73, 0x12, 0x12, 0x2e, 0x70, 0x62, 0x2e, 0x4c,
+	0x69, 0x73, 0x74, 0x55, 0x45, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x10, 0x2e,
+	0x70, 0x62, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x55, 0x45, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22,
+	0x00, 0x12, 0x37, 0x0a, 0x09, 0x52, 0x65, 0x6c, 0x65, 0x61, 0x73, 0x65, 0x55, 0x45, 0x12, 0x14,
+	0x2e, 0x70, 0x62, 0x2e, 0x52, 0x65, 0x6c, 0x65, 0x61, 0x73, 0x65, 0x55, 0x45, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x12, 0x2e, 0x70, 0x62, 0x2e, 0x52, 0x65, 0x6c, 0x65, 0x61, 0x73,
+	0x65, 0x55, 0x45, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x33,
+}
+
+var (
+	file_n3iwf_proto_rawDescOnce sync.Once
+	file_n3iwf_proto_rawDescData = file_n3iwf_proto_rawDesc
+)
+
+func file_n3iwf_proto_rawDescGZIP() []byte {
+	file_n3iwf_proto_rawDescOnce.Do(func() {
+		file_n3iwf_proto_rawDescData = protoimpl.X.CompressGZIP(file_n3iwf_proto_rawDescData)
+	})
+	return file_n3iwf_proto_rawDescData
+}
+
+var file_n3iwf_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_n3iwf_proto_goTypes = []interface{}{
+	(*ListUEsRequest)(nil),   // 0: pb.ListUEsRequest
+	(*UE)(nil),               // 1: pb.UE
+	(*ListUEsReply)(nil),     // 2: pb.ListUEsReply
+	(*ReleaseUERequest)(nil), // 3: pb.ReleaseUERequest
+	(*ReleaseUEReply)(nil),   // 4: pb.ReleaseUEReply
+}
+var file_n3iwf_proto_depIdxs = []int32{
+	1, // 0: pb.ListUEsReply.ues:type_name -> pb.UE
+	0, // 1: pb.N3iwf.ListUEs:input_type -> pb.ListUEsRequest
+	3, // 2: pb.N3iwf.ReleaseUE:input_type -> pb.ReleaseUERequest
+	2, // 3: pb.N3iwf.ListUEs:output_type -> pb.ListUEsReply
+	4, // 4: pb.N3iwf.ReleaseUE:output_type -> pb.ReleaseUEReply
+	3, // [3:5] is the sub-list for method output_type
+	1, // [1:3] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_n3iwf_proto_init() }
+func file_n3iwf_proto_init() {
+	if File_n3iwf_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_n3iwf_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListUEsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_n3iwf_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UE); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_n3iwf_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListUEsReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_n3iwf_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ReleaseUERequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_n3iwf_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ReleaseUEReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_n3iwf_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   5,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_n3iwf_proto_goTypes,
+		DependencyIndexes: file_n3iwf_proto_depIdxs,
+		MessageInfos:      file_n3iwf_proto_msgTypes,
+	}.Build()
+	File_n3iwf_proto = out.File
+	file_n3iwf_proto_rawDesc = nil
+	file_n3iwf_proto_goTypes = nil
+	file_n3iwf_proto_depIdxs = nil
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConnInterface
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion6
+
+// N3IwfClient is the client API for N3Iwf service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type N3IwfClient interface {
+	// ListUEs lists the UEs connected through the NWu.
+	ListUEs(ctx context.Context, in *ListUEsRequest, opts ...grpc.CallOption) (*ListUEsReply, error)
+	// ReleaseUE releases the UE of ran_ue_ngap_id, deleting its IKE SA.
+	ReleaseUE(ctx context.Context, in *ReleaseUERequest, opts ...grpc.CallOption) (*ReleaseUEReply, error)
+}
+
+type n3IwfClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewN3IwfClient(cc grpc.ClientConnInterface) N3IwfClient {
+	return &n3IwfClient{cc}
+}
+
+func (c *n3IwfClient) ListUEs(ctx context.Context, in *ListUEsRequest, opts ...grpc.CallOption) (*ListUEsReply, error) {
+	out := new(ListUEsReply)
+	err := c.cc.Invoke(ctx, "/pb.N3iwf/ListUEs", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *n3IwfClient) ReleaseUE(ctx context.Context, in *ReleaseUERequest, opts ...grpc.CallOption) (*ReleaseUEReply, error) {
+	out := new(ReleaseUEReply)
+	err := c.cc.Invoke(ctx, "/pb.N3iwf/ReleaseUE", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// N3IwfServer is the server API for N3Iwf service.
+type N3IwfServer interface {
+	// ListUEs lists the UEs connected through the NWu.
+	ListUEs(context.Context, *ListUEsRequest) (*ListUEsReply, error)
+	// ReleaseUE releases the UE of ran_ue_ngap_id, deleting its IKE SA.
+	ReleaseUE(context.Context, *ReleaseUERequest) (*ReleaseUEReply, error)
+}
+
+// UnimplementedN3IwfServer can be embedded to have forward compatible implementations.
+type UnimplementedN3IwfServer struct {
+}
+
+func (*UnimplementedN3IwfServer) ListUEs(context.Context, *ListUEsRequest) (*ListUEsReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListUEs not implemented")
+}
+func (*UnimplementedN3IwfServer) ReleaseUE(context.Context, *ReleaseUERequest) (*ReleaseUEReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReleaseUE not implemented")
+}
+
+func RegisterN3IwfServer(s *grpc.Server, srv N3IwfServer) {
+	s.RegisterService(&_N3Iwf_serviceDesc, srv)
+}
+
+func _N3Iwf_ListUEs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListUEsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(N3IwfServer).ListUEs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.N3iwf/ListUEs",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(N3IwfServer).ListUEs(ctx, req.(*ListUEsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _N3Iwf_ReleaseUE_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReleaseUERequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(N3IwfServer).ReleaseUE(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.N3iwf/ReleaseUE",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(N3IwfServer).ReleaseUE(ctx, req.(*ReleaseUERequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _N3Iwf_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "pb.N3iwf",
+	HandlerType: (*N3IwfServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListUEs",
+			Handler:    _N3Iwf_ListUEs_Handler,
+		},
+		{
+			MethodName: "ReleaseUE",
+			Handler:    _N3Iwf_ReleaseUE_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "n3iwf.proto",
+}
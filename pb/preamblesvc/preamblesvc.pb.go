@@ -35,6 +35,8 @@ type PreambleRequest struct {
 	unknownFields protoimpl.UnknownFields
 
 	Msg int64 `protobuf:"varint,1,opt,name=msg,proto3" json:"msg,omitempty"`
+	// The NR cell identity of the cell the preamble was received on, or zero.
+	Nci uint64 `protobuf:"varint,2,opt,name=nci,proto3" json:"nci,omitempty"`
 }
 
 func (x *PreambleRequest) Reset() {
@@ -76,6 +78,13 @@ func (x *PreambleRequest) GetMsg() int64 {
 	return 0
 }
 
+func (x *PreambleRequest) GetNci() uint64 {
+	if x != nil {
+		return x.Nci
+	}
+	return 0
+}
+
 type PreambleReply struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -131,21 +140,139 @@ func (x *PreambleReply) GetErr() string {
 	return ""
 }
 
+type PreambleBatchRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Preambles []*PreambleRequest `protobuf:"bytes,1,rep,name=preambles,proto3" json:"preambles,omitempty"`
+}
+
+func (x *PreambleBatchRequest) Reset() {
+	*x = PreambleBatchRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_preamblesvc_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PreambleBatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PreambleBatchRequest) ProtoMessage() {}
+
+func (x *PreambleBatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_preamblesvc_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PreambleBatchRequest.ProtoReflect.Descriptor instead.
+func (*PreambleBatchRequest) Descriptor() ([]byte, []int) {
+	return file_preamblesvc_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *PreambleBatchRequest) GetPreambles() []*PreambleRequest {
+	if x != nil {
+		return x.Preambles
+	}
+	return nil
+}
+
+type PreambleBatchReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Results []*PreambleReply `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	Err     string           `protobuf:"bytes,2,opt,name=err,proto3" json:"err,omitempty"`
+}
+
+func (x *PreambleBatchReply) Reset() {
+	*x = PreambleBatchReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_preamblesvc_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PreambleBatchReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PreambleBatchReply) ProtoMessage() {}
+
+func (x *PreambleBatchReply) ProtoReflect() protoreflect.Message {
+	mi := &file_preamblesvc_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PreambleBatchReply.ProtoReflect.Descriptor instead.
+func (*PreambleBatchReply) Descriptor() ([]byte, []int) {
+	return file_preamblesvc_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *PreambleBatchReply) GetResults() []*PreambleReply {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+func (x *PreambleBatchReply) GetErr() string {
+	if x != nil {
+		return x.Err
+	}
+	return ""
+}
+
 var File_preamblesvc_proto protoreflect.FileDescriptor
 
 var file_preamblesvc_proto_rawDesc = []byte{
 	0x0a, 0x11, 0x70, 0x72, 0x65, 0x61, 0x6d, 0x62, 0x6c, 0x65, 0x73, 0x76, 0x63, 0x2e, 0x70, 0x72,
-	0x6f, 0x74, 0x6f, 0x12, 0x02, 0x70, 0x62, 0x22, 0x23, 0x0a, 0x0f, 0x50, 0x72, 0x65, 0x61, 0x6d,
+	0x6f, 0x74, 0x6f, 0x12, 0x02, 0x70, 0x62, 0x22, 0x35, 0x0a, 0x0f, 0x50, 0x72, 0x65, 0x61, 0x6d,
 	0x62, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x6d, 0x73,
-	0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x03, 0x6d, 0x73, 0x67, 0x22, 0x31, 0x0a, 0x0d,
-	0x50, 0x72, 0x65, 0x61, 0x6d, 0x62, 0x6c, 0x65, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x0e, 0x0a,
-	0x02, 0x72, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x02, 0x72, 0x73, 0x12, 0x10, 0x0a,
-	0x03, 0x65, 0x72, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x65, 0x72, 0x72, 0x32,
-	0x43, 0x0a, 0x0b, 0x50, 0x72, 0x65, 0x61, 0x6d, 0x62, 0x6c, 0x65, 0x73, 0x76, 0x63, 0x12, 0x34,
-	0x0a, 0x08, 0x50, 0x72, 0x65, 0x61, 0x6d, 0x62, 0x6c, 0x65, 0x12, 0x13, 0x2e, 0x70, 0x62, 0x2e,
-	0x50, 0x72, 0x65, 0x61, 0x6d, 0x62, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
-	0x11, 0x2e, 0x70, 0x62, 0x2e, 0x50, 0x72, 0x65, 0x61, 0x6d, 0x62, 0x6c, 0x65, 0x52, 0x65, 0x70,
-	0x6c, 0x79, 0x22, 0x00, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x03, 0x6d, 0x73, 0x67, 0x12, 0x10, 0x0a, 0x03,
+	0x6e, 0x63, 0x69, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x03, 0x6e, 0x63, 0x69, 0x22, 0x31,
+	0x0a, 0x0d, 0x50, 0x72, 0x65, 0x61, 0x6d, 0x62, 0x6c, 0x65, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12,
+	0x0e, 0x0a, 0x02, 0x72, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x02, 0x72, 0x73, 0x12,
+	0x10, 0x0a, 0x03, 0x65, 0x72, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x65, 0x72,
+	0x72, 0x22, 0x49, 0x0a, 0x14, 0x50, 0x72, 0x65, 0x61, 0x6d, 0x62, 0x6c, 0x65, 0x42, 0x61, 0x74,
+	0x63, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x31, 0x0a, 0x09, 0x70, 0x72, 0x65,
+	0x61, 0x6d, 0x62, 0x6c, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x70,
+	0x62, 0x2e, 0x50, 0x72, 0x65, 0x61, 0x6d, 0x62, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x52, 0x09, 0x70, 0x72, 0x65, 0x61, 0x6d, 0x62, 0x6c, 0x65, 0x73, 0x22, 0x53, 0x0a, 0x12,
+	0x50, 0x72, 0x65, 0x61, 0x6d, 0x62, 0x6c, 0x65, 0x42, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65, 0x70,
+	0x6c, 0x79, 0x12, 0x2b, 0x0a, 0x07, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x18, 0x01, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x70, 0x62, 0x2e, 0x50, 0x72, 0x65, 0x61, 0x6d, 0x62, 0x6c,
+	0x65, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x52, 0x07, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x12,
+	0x10, 0x0a, 0x03, 0x65, 0x72, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x65, 0x72,
+	0x72, 0x32, 0x88, 0x01, 0x0a, 0x0b, 0x50, 0x72, 0x65, 0x61, 0x6d, 0x62, 0x6c, 0x65, 0x73, 0x76,
+	0x63, 0x12, 0x34, 0x0a, 0x08, 0x50, 0x72, 0x65, 0x61, 0x6d, 0x62, 0x6c, 0x65, 0x12, 0x13, 0x2e,
+	0x70, 0x62, 0x2e, 0x50, 0x72, 0x65, 0x61, 0x6d, 0x62, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x11, 0x2e, 0x70, 0x62, 0x2e, 0x50, 0x72, 0x65, 0x61, 0x6d, 0x62, 0x6c, 0x65,
+	0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x43, 0x0a, 0x0d, 0x50, 0x72, 0x65, 0x61, 0x6d,
+	0x62, 0x6c, 0x65, 0x42, 0x61, 0x74, 0x63, 0x68, 0x12, 0x18, 0x2e, 0x70, 0x62, 0x2e, 0x50, 0x72,
+	0x65, 0x61, 0x6d, 0x62, 0x6c, 0x65, 0x42, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x16, 0x2e, 0x70, 0x62, 0x2e, 0x50, 0x72, 0x65, 0x61, 0x6d, 0x62, 0x6c, 0x65,
+	0x42, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x62, 0x06, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -160,19 +287,25 @@ func file_preamblesvc_proto_rawDescGZIP() []byte {
 	return file_preamblesvc_proto_rawDescData
 }
 
-var file_preamblesvc_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_preamblesvc_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
 var file_preamblesvc_proto_goTypes = []interface{}{
-	(*PreambleRequest)(nil), // 0: pb.PreambleRequest
-	(*PreambleReply)(nil),   // 1: pb.PreambleReply
+	(*PreambleRequest)(nil),      // 0: pb.PreambleRequest
+	(*PreambleReply)(nil),        // 1: pb.PreambleReply
+	(*PreambleBatchRequest)(nil), // 2: pb.PreambleBatchRequest
+	(*PreambleBatchReply)(nil),   // 3: pb.PreambleBatchReply
 }
 var file_preamblesvc_proto_depIdxs = []int32{
-	0, // 0: pb.Preamblesvc.Preamble:input_type -> pb.PreambleRequest
-	1, // 1: pb.Preamblesvc.Preamble:output_type -> pb.PreambleReply
-	1, // [1:2] is the sub-list for method output_type
-	0, // [0:1] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	0, // 0: pb.PreambleBatchRequest.preambles:type_name -> pb.PreambleRequest
+	1, // 1: pb.PreambleBatchReply.results:type_name -> pb.PreambleReply
+	0, // 2: pb.Preamblesvc.Preamble:input_type -> pb.PreambleRequest
+	2, // 3: pb.Preamblesvc.PreambleBatch:input_type -> pb.PreambleBatchRequest
+	1, // 4: pb.Preamblesvc.Preamble:output_type -> pb.PreambleReply
+	3, // 5: pb.Preamblesvc.PreambleBatch:output_type -> pb.PreambleBatchReply
+	4, // [4:6] is the sub-list for method output_type
+	2, // [2:4] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
 }
 
 func init() { file_preamblesvc_proto_init() }
@@ -205,6 +338,30 @@ func file_preamblesvc_proto_init() {
 				return nil
 			}
 		}
+		file_preamblesvc_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PreambleBatchRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_preamblesvc_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PreambleBatchReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -212,7 +369,7 @@ func file_preamblesvc_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_preamblesvc_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   2,
+			NumMessages:   4,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -239,6 +396,7 @@ const _ = grpc.SupportPackageIsVersion6
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
 type PreamblesvcClient interface {
 	Preamble(ctx context.Context, in *PreambleRequest, opts ...grpc.CallOption) (*PreambleReply, error)
+	PreambleBatch(ctx context.Context, in *PreambleBatchRequest, opts ...grpc.CallOption) (*PreambleBatchReply, error)
 }
 
 type preamblesvcClient struct {
@@ -258,9 +416,19 @@ func (c *preamblesvcClient) Preamble(ctx context.Context, in *PreambleRequest, o
 	return out, nil
 }
 
+func (c *preamblesvcClient) PreambleBatch(ctx context.Context, in *PreambleBatchRequest, opts ...grpc.CallOption) (*PreambleBatchReply, error) {
+	out := new(PreambleBatchReply)
+	err := c.cc.Invoke(ctx, "/pb.Preamblesvc/PreambleBatch", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // PreamblesvcServer is the server API for Preamblesvc service.
 type PreamblesvcServer interface {
 	Preamble(context.Context, *PreambleRequest) (*PreambleReply, error)
+	PreambleBatch(context.Context, *PreambleBatchRequest) (*PreambleBatchReply, error)
 }
 
 // UnimplementedPreamblesvcServer can be embedded to have forward compatible implementations.
@@ -270,6 +438,9 @@ type UnimplementedPreamblesvcServer struct {
 func (*UnimplementedPreamblesvcServer) Preamble(context.Context, *PreambleRequest) (*PreambleReply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Preamble not implemented")
 }
+func (*UnimplementedPreamblesvcServer) PreambleBatch(context.Context, *PreambleBatchRequest) (*PreambleBatchReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PreambleBatch not implemented")
+}
 
 func RegisterPreamblesvcServer(s *grpc.Server, srv PreamblesvcServer) {
 	s.RegisterService(&_Preamblesvc_serviceDesc, srv)
@@ -293,6 +464,24 @@ func _Preamblesvc_Preamble_Handler(srv interface{}, ctx context.Context, dec fun
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Preamblesvc_PreambleBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PreambleBatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PreamblesvcServer).PreambleBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Preamblesvc/PreambleBatch",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PreamblesvcServer).PreambleBatch(ctx, req.(*PreambleBatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _Preamblesvc_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "pb.Preamblesvc",
 	HandlerType: (*PreamblesvcServer)(nil),
@@ -301,6 +490,10 @@ var _Preamblesvc_serviceDesc = grpc.ServiceDesc{
 			MethodName: "Preamble",
 			Handler:    _Preamblesvc_Preamble_Handler,
 		},
+		{
+			MethodName: "PreambleBatch",
+			Handler:    _Preamblesvc_PreambleBatch_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "preamblesvc.proto",
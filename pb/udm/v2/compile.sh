@@ -0,0 +1,6 @@
+#!/usr/bin/env sh
+
+# See ../compile.sh for the tools. The file is compiled from pb/ so that its
+# name, udm/v2/udm.proto, does not clash with version 1 in the registry.
+
+cd ../.. && protoc udm/v2/udm.proto --go_out=plugins=grpc,paths=source_relative:.
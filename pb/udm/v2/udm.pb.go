@@ -0,0 +1,1996 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.24.0
+// 	protoc        v3.12.2
+// source: udm/v2/udm.proto
+
+// Version 2 of the Udm service. It is served next to version 1 (package pb),
+// on the same port, so that the clients move to it at their own pace and
+// keep working against the replicas not upgraded yet.
+//
+// Fields evolve by these rules, so that the messages of a version decode with
+// the same meaning in the next one; cmd/protocompat checks the first and the
+// last:
+//  - a field number is never reused, a removed field is reserved by number
+//    and name;
+//  - a field is not removed while a served version still uses it: it is
+//    first marked deprecated, the servers keep honouring it, and it goes in
+//    the next version;
+//  - a field never changes its type, a new field replaces it.
+
+package udmv2
+
+import (
+	context "context"
+	proto "github.com/golang/protobuf/proto"
+	timestamp "github.com/golang/protobuf/ptypes/timestamp"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// This is a compile-time assertion that a sufficiently up-to-date version
+// of the legacy proto package is being used.
+const _ = proto.ProtoPackageIsVersion4
+
+type ResynchronizationInfo struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Rand []byte `protobuf:"bytes,1,opt,name=rand,proto3" json:"rand,omitempty"`
+	Auts []byte `protobuf:"bytes,2,opt,name=auts,proto3" json:"auts,omitempty"`
+}
+
+func (x *ResynchronizationInfo) Reset() {
+	*x = ResynchronizationInfo{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_udm_v2_udm_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ResynchronizationInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResynchronizationInfo) ProtoMessage() {}
+
+func (x *ResynchronizationInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_udm_v2_udm_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResynchronizationInfo.ProtoReflect.Descriptor instead.
+func (*ResynchronizationInfo) Descriptor() ([]byte, []int) {
+	return file_udm_v2_udm_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ResynchronizationInfo) GetRand() []byte {
+	if x != nil {
+		return x.Rand
+	}
+	return nil
+}
+
+func (x *ResynchronizationInfo) GetAuts() []byte {
+	if x != nil {
+		return x.Auts
+	}
+	return nil
+}
+
+type GenerateAuthDataRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Supi               string                 `protobuf:"bytes,1,opt,name=supi,proto3" json:"supi,omitempty"`
+	ServingNetworkName string                 `protobuf:"bytes,2,opt,name=serving_network_name,json=servingNetworkName,proto3" json:"serving_network_name,omitempty"`
+	Resync             *ResynchronizationInfo `protobuf:"bytes,3,opt,name=resync,proto3" json:"resync,omitempty"`
+}
+
+func (x *GenerateAuthDataRequest) Reset() {
+	*x = GenerateAuthDataRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_udm_v2_udm_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GenerateAuthDataRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GenerateAuthDataRequest) ProtoMessage() {}
+
+func (x *GenerateAuthDataRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_udm_v2_udm_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GenerateAuthDataRequest.ProtoReflect.Descriptor instead.
+func (*GenerateAuthDataRequest) Descriptor() ([]byte, []int) {
+	return file_udm_v2_udm_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GenerateAuthDataRequest) GetSupi() string {
+	if x != nil {
+		return x.Supi
+	}
+	return ""
+}
+
+func (x *GenerateAuthDataRequest) GetServingNetworkName() string {
+	if x != nil {
+		return x.ServingNetworkName
+	}
+	return ""
+}
+
+func (x *GenerateAuthDataRequest) GetResync() *ResynchronizationInfo {
+	if x != nil {
+		return x.Resync
+	}
+	return nil
+}
+
+// The errors are returned as gRPC status, the err fields of version 1 were
+// never set.
+type GenerateAuthDataReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Rand     []byte `protobuf:"bytes,1,opt,name=rand,proto3" json:"rand,omitempty"`
+	Autn     []byte `protobuf:"bytes,2,opt,name=autn,proto3" json:"autn,omitempty"`
+	XresStar []byte `protobuf:"bytes,3,opt,name=xres_star,json=xresStar,proto3" json:"xres_star,omitempty"`
+	Kausf    []byte `protobuf:"bytes,4,opt,name=kausf,proto3" json:"kausf,omitempty"`
+}
+
+func (x *GenerateAuthDataReply) Reset() {
+	*x = GenerateAuthDataReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_udm_v2_udm_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GenerateAuthDataReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GenerateAuthDataReply) ProtoMessage() {}
+
+func (x *GenerateAuthDataReply) ProtoReflect() protoreflect.Message {
+	mi := &file_udm_v2_udm_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GenerateAuthDataReply.ProtoReflect.Descriptor instead.
+func (*GenerateAuthDataReply) Descriptor() ([]byte, []int) {
+	return file_udm_v2_udm_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GenerateAuthDataReply) GetRand() []byte {
+	if x != nil {
+		return x.Rand
+	}
+	return nil
+}
+
+func (x *GenerateAuthDataReply) GetAutn() []byte {
+	if x != nil {
+		return x.Autn
+	}
+	return nil
+}
+
+func (x *GenerateAuthDataReply) GetXresStar() []byte {
+	if x != nil {
+		return x.XresStar
+	}
+	return nil
+}
+
+func (x *GenerateAuthDataReply) GetKausf() []byte {
+	if x != nil {
+		return x.Kausf
+	}
+	return nil
+}
+
+type Snssai struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Sst uint32 `protobuf:"varint,1,opt,name=sst,proto3" json:"sst,omitempty"`
+	Sd  string `protobuf:"bytes,2,opt,name=sd,proto3" json:"sd,omitempty"`
+}
+
+func (x *Snssai) Reset() {
+	*x = Snssai{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_udm_v2_udm_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Snssai) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Snssai) ProtoMessage() {}
+
+func (x *Snssai) ProtoReflect() protoreflect.Message {
+	mi := &file_udm_v2_udm_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Snssai.ProtoReflect.Descriptor instead.
+func (*Snssai) Descriptor() ([]byte, []int) {
+	return file_udm_v2_udm_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Snssai) GetSst() uint32 {
+	if x != nil {
+		return x.Sst
+	}
+	return 0
+}
+
+func (x *Snssai) GetSd() string {
+	if x != nil {
+		return x.Sd
+	}
+	return ""
+}
+
+type Ambr struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Uplink   string `protobuf:"bytes,1,opt,name=uplink,proto3" json:"uplink,omitempty"`
+	Downlink string `protobuf:"bytes,2,opt,name=downlink,proto3" json:"downlink,omitempty"`
+}
+
+func (x *Ambr) Reset() {
+	*x = Ambr{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_udm_v2_udm_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Ambr) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Ambr) ProtoMessage() {}
+
+func (x *Ambr) ProtoReflect() protoreflect.Message {
+	mi := &file_udm_v2_udm_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Ambr.ProtoReflect.Descriptor instead.
+func (*Ambr) Descriptor() ([]byte, []int) {
+	return file_udm_v2_udm_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Ambr) GetUplink() string {
+	if x != nil {
+		return x.Uplink
+	}
+	return ""
+}
+
+func (x *Ambr) GetDownlink() string {
+	if x != nil {
+		return x.Downlink
+	}
+	return ""
+}
+
+// Subscriber is the provisioned subscription of one UE. k and opc are never
+// returned.
+type Subscriber struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Supi string `protobuf:"bytes,1,opt,name=supi,proto3" json:"supi,omitempty"`
+	K    []byte `protobuf:"bytes,2,opt,name=k,proto3" json:"k,omitempty"`
+	// op is only used to derive opc, which provisioning systems should
+	// compute themselves.
+	//
+	// Deprecated: Do not use.
+	Op            []byte  `protobuf:"bytes,3,opt,name=op,proto3" json:"op,omitempty"`
+	Opc           []byte  `protobuf:"bytes,4,opt,name=opc,proto3" json:"opc,omitempty"`
+	Amf           []byte  `protobuf:"bytes,5,opt,name=amf,proto3" json:"amf,omitempty"`
+	Sqn           []byte  `protobuf:"bytes,6,opt,name=sqn,proto3" json:"sqn,omitempty"`
+	DefaultSnssai *Snssai `protobuf:"bytes,7,opt,name=default_snssai,json=defaultSnssai,proto3" json:"default_snssai,omitempty"`
+	Ambr          *Ambr   `protobuf:"bytes,8,opt,name=ambr,proto3" json:"ambr,omitempty"`
+}
+
+func (x *Subscriber) Reset() {
+	*x = Subscriber{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_udm_v2_udm_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Subscriber) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Subscriber) ProtoMessage() {}
+
+func (x *Subscriber) ProtoReflect() protoreflect.Message {
+	mi := &file_udm_v2_udm_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Subscriber.ProtoReflect.Descriptor instead.
+func (*Subscriber) Descriptor() ([]byte, []int) {
+	return file_udm_v2_udm_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Subscriber) GetSupi() string {
+	if x != nil {
+		return x.Supi
+	}
+	return ""
+}
+
+func (x *Subscriber) GetK() []byte {
+	if x != nil {
+		return x.K
+	}
+	return nil
+}
+
+// Deprecated: Do not use.
+func (x *Subscriber) GetOp() []byte {
+	if x != nil {
+		return x.Op
+	}
+	return nil
+}
+
+func (x *Subscriber) GetOpc() []byte {
+	if x != nil {
+		return x.Opc
+	}
+	return nil
+}
+
+func (x *Subscriber) GetAmf() []byte {
+	if x != nil {
+		return x.Amf
+	}
+	return nil
+}
+
+func (x *Subscriber) GetSqn() []byte {
+	if x != nil {
+		return x.Sqn
+	}
+	return nil
+}
+
+func (x *Subscriber) GetDefaultSnssai() *Snssai {
+	if x != nil {
+		return x.DefaultSnssai
+	}
+	return nil
+}
+
+func (x *Subscriber) GetAmbr() *Ambr {
+	if x != nil {
+		return x.Ambr
+	}
+	return nil
+}
+
+type CreateSubscriberRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Subscriber *Subscriber `protobuf:"bytes,1,opt,name=subscriber,proto3" json:"subscriber,omitempty"`
+}
+
+func (x *CreateSubscriberRequest) Reset() {
+	*x = CreateSubscriberRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_udm_v2_udm_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CreateSubscriberRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateSubscriberRequest) ProtoMessage() {}
+
+func (x *CreateSubscriberRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_udm_v2_udm_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateSubscriberRequest.ProtoReflect.Descriptor instead.
+func (*CreateSubscriberRequest) Descriptor() ([]byte, []int) {
+	return file_udm_v2_udm_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *CreateSubscriberRequest) GetSubscriber() *Subscriber {
+	if x != nil {
+		return x.Subscriber
+	}
+	return nil
+}
+
+type CreateSubscriberReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Subscriber *Subscriber `protobuf:"bytes,1,opt,name=subscriber,proto3" json:"subscriber,omitempty"`
+}
+
+func (x *CreateSubscriberReply) Reset() {
+	*x = CreateSubscriberReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_udm_v2_udm_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CreateSubscriberReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateSubscriberReply) ProtoMessage() {}
+
+func (x *CreateSubscriberReply) ProtoReflect() protoreflect.Message {
+	mi := &file_udm_v2_udm_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateSubscriberReply.ProtoReflect.Descriptor instead.
+func (*CreateSubscriberReply) Descriptor() ([]byte, []int) {
+	return file_udm_v2_udm_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *CreateSubscriberReply) GetSubscriber() *Subscriber {
+	if x != nil {
+		return x.Subscriber
+	}
+	return nil
+}
+
+type UpdateSubscriberRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Subscriber *Subscriber `protobuf:"bytes,1,opt,name=subscriber,proto3" json:"subscriber,omitempty"`
+}
+
+func (x *UpdateSubscriberRequest) Reset() {
+	*x = UpdateSubscriberRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_udm_v2_udm_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UpdateSubscriberRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateSubscriberRequest) ProtoMessage() {}
+
+func (x *UpdateSubscriberRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_udm_v2_udm_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateSubscriberRequest.ProtoReflect.Descriptor instead.
+func (*UpdateSubscriberRequest) Descriptor() ([]byte, []int) {
+	return file_udm_v2_udm_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *UpdateSubscriberRequest) GetSubscriber() *Subscriber {
+	if x != nil {
+		return x.Subscriber
+	}
+	return nil
+}
+
+type UpdateSubscriberReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Subscriber *Subscriber `protobuf:"bytes,1,opt,name=subscriber,proto3" json:"subscriber,omitempty"`
+}
+
+func (x *UpdateSubscriberReply) Reset() {
+	*x = UpdateSubscriberReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_udm_v2_udm_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UpdateSubscriberReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateSubscriberReply) ProtoMessage() {}
+
+func (x *UpdateSubscriberReply) ProtoReflect() protoreflect.Message {
+	mi := &file_udm_v2_udm_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateSubscriberReply.ProtoReflect.Descriptor instead.
+func (*UpdateSubscriberReply) Descriptor() ([]byte, []int) {
+	return file_udm_v2_udm_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *UpdateSubscriberReply) GetSubscriber() *Subscriber {
+	if x != nil {
+		return x.Subscriber
+	}
+	return nil
+}
+
+type DeleteSubscriberRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Supi string `protobuf:"bytes,1,opt,name=supi,proto3" json:"supi,omitempty"`
+}
+
+func (x *DeleteSubscriberRequest) Reset() {
+	*x = DeleteSubscriberRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_udm_v2_udm_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DeleteSubscriberRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteSubscriberRequest) ProtoMessage() {}
+
+func (x *DeleteSubscriberRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_udm_v2_udm_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteSubscriberRequest.ProtoReflect.Descriptor instead.
+func (*DeleteSubscriberRequest) Descriptor() ([]byte, []int) {
+	return file_udm_v2_udm_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *DeleteSubscriberRequest) GetSupi() string {
+	if x != nil {
+		return x.Supi
+	}
+	return ""
+}
+
+type DeleteSubscriberReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *DeleteSubscriberReply) Reset() {
+	*x = DeleteSubscriberReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_udm_v2_udm_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DeleteSubscriberReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteSubscriberReply) ProtoMessage() {}
+
+func (x *DeleteSubscriberReply) ProtoReflect() protoreflect.Message {
+	mi := &file_udm_v2_udm_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteSubscriberReply.ProtoReflect.Descriptor instead.
+func (*DeleteSubscriberReply) Descriptor() ([]byte, []int) {
+	return file_udm_v2_udm_proto_rawDescGZIP(), []int{11}
+}
+
+type ListSubscribersRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	PageSize  int32  `protobuf:"varint,1,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	PageToken string `protobuf:"bytes,2,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+}
+
+func (x *ListSubscribersRequest) Reset() {
+	*x = ListSubscribersRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_udm_v2_udm_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListSubscribersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSubscribersRequest) ProtoMessage() {}
+
+func (x *ListSubscribersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_udm_v2_udm_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSubscribersRequest.ProtoReflect.Descriptor instead.
+func (*ListSubscribersRequest) Descriptor() ([]byte, []int) {
+	return file_udm_v2_udm_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ListSubscribersRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListSubscribersRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+type ListSubscribersReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Subscribers   []*Subscriber `protobuf:"bytes,1,rep,name=subscribers,proto3" json:"subscribers,omitempty"`
+	NextPageToken string        `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+}
+
+func (x *ListSubscribersReply) Reset() {
+	*x = ListSubscribersReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_udm_v2_udm_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListSubscribersReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSubscribersReply) ProtoMessage() {}
+
+func (x *ListSubscribersReply) ProtoReflect() protoreflect.Message {
+	mi := &file_udm_v2_udm_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSubscribersReply.ProtoReflect.Descriptor instead.
+func (*ListSubscribersReply) Descriptor() ([]byte, []int) {
+	return file_udm_v2_udm_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ListSubscribersReply) GetSubscribers() []*Subscriber {
+	if x != nil {
+		return x.Subscribers
+	}
+	return nil
+}
+
+func (x *ListSubscribersReply) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+// ConfirmAuthRequest informs the UDM of the result of an authentication
+// (TS 29.503 5.4.2.4).
+type ConfirmAuthRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Supi               string `protobuf:"bytes,1,opt,name=supi,proto3" json:"supi,omitempty"`
+	ServingNetworkName string `protobuf:"bytes,2,opt,name=serving_network_name,json=servingNetworkName,proto3" json:"serving_network_name,omitempty"`
+	Success            bool   `protobuf:"varint,3,opt,name=success,proto3" json:"success,omitempty"`
+}
+
+func (x *ConfirmAuthRequest) Reset() {
+	*x = ConfirmAuthRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_udm_v2_udm_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ConfirmAuthRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConfirmAuthRequest) ProtoMessage() {}
+
+func (x *ConfirmAuthRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_udm_v2_udm_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConfirmAuthRequest.ProtoReflect.Descriptor instead.
+func (*ConfirmAuthRequest) Descriptor() ([]byte, []int) {
+	return file_udm_v2_udm_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ConfirmAuthRequest) GetSupi() string {
+	if x != nil {
+		return x.Supi
+	}
+	return ""
+}
+
+func (x *ConfirmAuthRequest) GetServingNetworkName() string {
+	if x != nil {
+		return x.ServingNetworkName
+	}
+	return ""
+}
+
+func (x *ConfirmAuthRequest) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+type ConfirmAuthReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *ConfirmAuthReply) Reset() {
+	*x = ConfirmAuthReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_udm_v2_udm_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ConfirmAuthReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConfirmAuthReply) ProtoMessage() {}
+
+func (x *ConfirmAuthReply) ProtoReflect() protoreflect.Message {
+	mi := &file_udm_v2_udm_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConfirmAuthReply.ProtoReflect.Descriptor instead.
+func (*ConfirmAuthReply) Descriptor() ([]byte, []int) {
+	return file_udm_v2_udm_proto_rawDescGZIP(), []int{15}
+}
+
+// UEEvent is an entry of the history of a UE. The servers set both times.
+type UEEvent struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// time is in nanoseconds since the Unix epoch; use timestamp.
+	//
+	// Deprecated: Do not use.
+	Time      int64                `protobuf:"varint,1,opt,name=time,proto3" json:"time,omitempty"`
+	Type      string               `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	Source    string               `protobuf:"bytes,3,opt,name=source,proto3" json:"source,omitempty"`
+	Outcome   string               `protobuf:"bytes,4,opt,name=outcome,proto3" json:"outcome,omitempty"`
+	Detail    map[string]string    `protobuf:"bytes,5,rep,name=detail,proto3" json:"detail,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	Timestamp *timestamp.Timestamp `protobuf:"bytes,6,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+}
+
+func (x *UEEvent) Reset() {
+	*x = UEEvent{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_udm_v2_udm_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UEEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UEEvent) ProtoMessage() {}
+
+func (x *UEEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_udm_v2_udm_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UEEvent.ProtoReflect.Descriptor instead.
+func (*UEEvent) Descriptor() ([]byte, []int) {
+	return file_udm_v2_udm_proto_rawDescGZIP(), []int{16}
+}
+
+// Deprecated: Do not use.
+func (x *UEEvent) GetTime() int64 {
+	if x != nil {
+		return x.Time
+	}
+	return 0
+}
+
+func (x *UEEvent) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *UEEvent) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *UEEvent) GetOutcome() string {
+	if x != nil {
+		return x.Outcome
+	}
+	return ""
+}
+
+func (x *UEEvent) GetDetail() map[string]string {
+	if x != nil {
+		return x.Detail
+	}
+	return nil
+}
+
+func (x *UEEvent) GetTimestamp() *timestamp.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+// GetUEHistoryRequest selects the events of a UE in [since_time,
+// until_time); an unset time leaves an end of the range open.
+type GetUEHistoryRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Supi string `protobuf:"bytes,1,opt,name=supi,proto3" json:"supi,omitempty"`
+	// since and until are in nanoseconds since the Unix epoch; they are
+	// only read when since_time and until_time are not set.
+	//
+	// Deprecated: Do not use.
+	Since int64 `protobuf:"varint,2,opt,name=since,proto3" json:"since,omitempty"`
+	// Deprecated: Do not use.
+	Until     int64                `protobuf:"varint,3,opt,name=until,proto3" json:"until,omitempty"`
+	PageSize  int32                `protobuf:"varint,4,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	PageToken string               `protobuf:"bytes,5,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	SinceTime *timestamp.Timestamp `protobuf:"bytes,6,opt,name=since_time,json=sinceTime,proto3" json:"since_time,omitempty"`
+	UntilTime *timestamp.Timestamp `protobuf:"bytes,7,opt,name=until_time,json=untilTime,proto3" json:"until_time,omitempty"`
+}
+
+func (x *GetUEHistoryRequest) Reset() {
+	*x = GetUEHistoryRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_udm_v2_udm_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetUEHistoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUEHistoryRequest) ProtoMessage() {}
+
+func (x *GetUEHistoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_udm_v2_udm_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUEHistoryRequest.ProtoReflect.Descriptor instead.
+func (*GetUEHistoryRequest) Descriptor() ([]byte, []int) {
+	return file_udm_v2_udm_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *GetUEHistoryRequest) GetSupi() string {
+	if x != nil {
+		return x.Supi
+	}
+	return ""
+}
+
+// Deprecated: Do not use.
+func (x *GetUEHistoryRequest) GetSince() int64 {
+	if x != nil {
+		return x.Since
+	}
+	return 0
+}
+
+// Deprecated: Do not use.
+func (x *GetUEHistoryRequest) GetUntil() int64 {
+	if x != nil {
+		return x.Until
+	}
+	return 0
+}
+
+func (x *GetUEHistoryRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *GetUEHistoryRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+func (x *GetUEHistoryRequest) GetSinceTime() *timestamp.Timestamp {
+	if x != nil {
+		return x.SinceTime
+	}
+	return nil
+}
+
+func (x *GetUEHistoryRequest) GetUntilTime() *timestamp.Timestamp {
+	if x != nil {
+		return x.UntilTime
+	}
+	return nil
+}
+
+type GetUEHistoryReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Events        []*UEEvent `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"`
+	NextPageToken string     `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+}
+
+func (x *GetUEHistoryReply) Reset() {
+	*x = GetUEHistoryReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_udm_v2_udm_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetUEHistoryReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUEHistoryReply) ProtoMessage() {}
+
+func (x *GetUEHistoryReply) ProtoReflect() protoreflect.Message {
+	mi := &file_udm_v2_udm_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUEHistoryReply.ProtoReflect.Descriptor instead.
+func (*GetUEHistoryReply) Descriptor() ([]byte, []int) {
+	return file_udm_v2_udm_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *GetUEHistoryReply) GetEvents() []*UEEvent {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+func (x *GetUEHistoryReply) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+var File_udm_v2_udm_proto protoreflect.FileDescriptor
+
+var file_udm_v2_udm_proto_rawDesc = []byte{
+	0x0a, 0x10, 0x75, 0x64, 0x6d, 0x2f, 0x76, 0x32, 0x2f, 0x75, 0x64, 0x6d, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x12, 0x06, 0x75, 0x64, 0x6d, 0x2e, 0x76, 0x32, 0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67,
+	0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65,
+	0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x3f, 0x0a, 0x15, 0x52,
+	0x65, 0x73, 0x79, 0x6e, 0x63, 0x68, 0x72, 0x6f, 0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x49, 0x6e, 0x66, 0x6f, 0x12, 0x12, 0x0a, 0x04, 0x72, 0x61, 0x6e, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0c, 0x52, 0x04, 0x72, 0x61, 0x6e, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x61, 0x75, 0x74, 0x73,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x61, 0x75, 0x74, 0x73, 0x22, 0x96, 0x01, 0x0a,
+	0x17, 0x47, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x41, 0x75, 0x74, 0x68, 0x44, 0x61, 0x74,
+	0x61, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x75, 0x70, 0x69,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x73, 0x75, 0x70, 0x69, 0x12, 0x30, 0x0a, 0x14,
+	0x73, 0x65, 0x72, 0x76, 0x69, 0x6e, 0x67, 0x5f, 0x6e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x5f,
+	0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x12, 0x73, 0x65, 0x72, 0x76,
+	0x69, 0x6e, 0x67, 0x4e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x35,
+	0x0a, 0x06, 0x72, 0x65, 0x73, 0x79, 0x6e, 0x63, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1d,
+	0x2e, 0x75, 0x64, 0x6d, 0x2e, 0x76, 0x32, 0x2e, 0x52, 0x65, 0x73, 0x79, 0x6e, 0x63, 0x68, 0x72,
+	0x6f, 0x6e, 0x69, 0x7a, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x06, 0x72,
+	0x65, 0x73, 0x79, 0x6e, 0x63, 0x22, 0x7d, 0x0a, 0x15, 0x47, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74,
+	0x65, 0x41, 0x75, 0x74, 0x68, 0x44, 0x61, 0x74, 0x61, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x12,
+	0x0a, 0x04, 0x72, 0x61, 0x6e, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x72, 0x61,
+	0x6e, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x61, 0x75, 0x74, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c,
+	0x52, 0x04, 0x61, 0x75, 0x74, 0x6e, 0x12, 0x1b, 0x0a, 0x09, 0x78, 0x72, 0x65, 0x73, 0x5f, 0x73,
+	0x74, 0x61, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x08, 0x78, 0x72, 0x65, 0x73, 0x53,
+	0x74, 0x61, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x6b, 0x61, 0x75, 0x73, 0x66, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x0c, 0x52, 0x05, 0x6b, 0x61, 0x75, 0x73, 0x66, 0x4a, 0x04, 0x08, 0x05, 0x10, 0x06, 0x52,
+	0x03, 0x65, 0x72, 0x72, 0x22, 0x2a, 0x0a, 0x06, 0x53, 0x6e, 0x73, 0x73, 0x61, 0x69, 0x12, 0x10,
+	0x0a, 0x03, 0x73, 0x73, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x03, 0x73, 0x73, 0x74,
+	0x12, 0x0e, 0x0a, 0x02, 0x73, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x73, 0x64,
+	0x22, 0x3a, 0x0a, 0x04, 0x41, 0x6d, 0x62, 0x72, 0x12, 0x16, 0x0a, 0x06, 0x75, 0x70, 0x6c, 0x69,
+	0x6e, 0x6b, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x75, 0x70, 0x6c, 0x69, 0x6e, 0x6b,
+	0x12, 0x1a, 0x0a, 0x08, 0x64, 0x6f, 0x77, 0x6e, 0x6c, 0x69, 0x6e, 0x6b, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x08, 0x64, 0x6f, 0x77, 0x6e, 0x6c, 0x69, 0x6e, 0x6b, 0x22, 0xd1, 0x01, 0x0a,
+	0x0a, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x73,
+	0x75, 0x70, 0x69, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x73, 0x75, 0x70, 0x69, 0x12,
+	0x0c, 0x0a, 0x01, 0x6b, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x01, 0x6b, 0x12, 0x12, 0x0a,
+	0x02, 0x6f, 0x70, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x42, 0x02, 0x18, 0x01, 0x52, 0x02, 0x6f,
+	0x70, 0x12, 0x10, 0x0a, 0x03, 0x6f, 0x70, 0x63, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x03,
+	0x6f, 0x70, 0x63, 0x12, 0x10, 0x0a, 0x03, 0x61, 0x6d, 0x66, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0c,
+	0x52, 0x03, 0x61, 0x6d, 0x66, 0x12, 0x10, 0x0a, 0x03, 0x73, 0x71, 0x6e, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x0c, 0x52, 0x03, 0x73, 0x71, 0x6e, 0x12, 0x35, 0x0a, 0x0e, 0x64, 0x65, 0x66, 0x61, 0x75,
+	0x6c, 0x74, 0x5f, 0x73, 0x6e, 0x73, 0x73, 0x61, 0x69, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x0e, 0x2e, 0x75, 0x64, 0x6d, 0x2e, 0x76, 0x32, 0x2e, 0x53, 0x6e, 0x73, 0x73, 0x61, 0x69, 0x52,
+	0x0d, 0x64, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x53, 0x6e, 0x73, 0x73, 0x61, 0x69, 0x12, 0x20,
+	0x0a, 0x04, 0x61, 0x6d, 0x62, 0x72, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x75,
+	0x64, 0x6d, 0x2e, 0x76, 0x32, 0x2e, 0x41, 0x6d, 0x62, 0x72, 0x52, 0x04, 0x61, 0x6d, 0x62, 0x72,
+	0x22, 0x4d, 0x0a, 0x17, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72,
+	0x69, 0x62, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x32, 0x0a, 0x0a, 0x73,
+	0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x12, 0x2e, 0x75, 0x64, 0x6d, 0x2e, 0x76, 0x32, 0x2e, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69,
+	0x62, 0x65, 0x72, 0x52, 0x0a, 0x73, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x72, 0x22,
+	0x56, 0x0a, 0x15, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69,
+	0x62, 0x65, 0x72, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x32, 0x0a, 0x0a, 0x73, 0x75, 0x62, 0x73,
+	0x63, 0x72, 0x69, 0x62, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x75,
+	0x64, 0x6d, 0x2e, 0x76, 0x32, 0x2e, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x72,
+	0x52, 0x0a, 0x73, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x72, 0x4a, 0x04, 0x08, 0x02,
+	0x10, 0x03, 0x52, 0x03, 0x65, 0x72, 0x72, 0x22, 0x4d, 0x0a, 0x17, 0x55, 0x70, 0x64, 0x61, 0x74,
+	0x65, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x32, 0x0a, 0x0a, 0x73, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x72,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x75, 0x64, 0x6d, 0x2e, 0x76, 0x32, 0x2e,
+	0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x72, 0x52, 0x0a, 0x73, 0x75, 0x62, 0x73,
+	0x63, 0x72, 0x69, 0x62, 0x65, 0x72, 0x22, 0x56, 0x0a, 0x15, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65,
+	0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x72, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12,
+	0x32, 0x0a, 0x0a, 0x73, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x72, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x75, 0x64, 0x6d, 0x2e, 0x76, 0x32, 0x2e, 0x53, 0x75, 0x62,
+	0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x72, 0x52, 0x0a, 0x73, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69,
+	0x62, 0x65, 0x72, 0x4a, 0x04, 0x08, 0x02, 0x10, 0x03, 0x52, 0x03, 0x65, 0x72, 0x72, 0x22, 0x2d,
+	0x0a, 0x17, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62,
+	0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x75, 0x70,
+	0x69, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x73, 0x75, 0x70, 0x69, 0x22, 0x22, 0x0a,
+	0x15, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65,
+	0x72, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x4a, 0x04, 0x08, 0x01, 0x10, 0x02, 0x52, 0x03, 0x65, 0x72,
+	0x72, 0x22, 0x54, 0x0a, 0x16, 0x4c, 0x69, 0x73, 0x74, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69,
+	0x62, 0x65, 0x72, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1b, 0x0a, 0x09, 0x70,
+	0x61, 0x67, 0x65, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08,
+	0x70, 0x61, 0x67, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x70, 0x61, 0x67, 0x65,
+	0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x70, 0x61,
+	0x67, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x22, 0x7f, 0x0a, 0x14, 0x4c, 0x69, 0x73, 0x74, 0x53,
+	0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x72, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12,
+	0x34, 0x0a, 0x0b, 0x73, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x72, 0x73, 0x18, 0x01,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x75, 0x64, 0x6d, 0x2e, 0x76, 0x32, 0x2e, 0x53, 0x75,
+	0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x72, 0x52, 0x0b, 0x73, 0x75, 0x62, 0x73, 0x63, 0x72,
+	0x69, 0x62, 0x65, 0x72, 0x73, 0x12, 0x26, 0x0a, 0x0f, 0x6e, 0x65, 0x78, 0x74, 0x5f, 0x70, 0x61,
+	0x67, 0x65, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d,
+	0x6e, 0x65, 0x78, 0x74, 0x50, 0x61, 0x67, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x4a, 0x04, 0x08,
+	0x03, 0x10, 0x04, 0x52, 0x03, 0x65, 0x72, 0x72, 0x22, 0x74, 0x0a, 0x12, 0x43, 0x6f, 0x6e, 0x66,
+	0x69, 0x72, 0x6d, 0x41, 0x75, 0x74, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12,
+	0x0a, 0x04, 0x73, 0x75, 0x70, 0x69, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x73, 0x75,
+	0x70, 0x69, 0x12, 0x30, 0x0a, 0x14, 0x73, 0x65, 0x72, 0x76, 0x69, 0x6e, 0x67, 0x5f, 0x6e, 0x65,
+	0x74, 0x77, 0x6f, 0x72, 0x6b, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x12, 0x73, 0x65, 0x72, 0x76, 0x69, 0x6e, 0x67, 0x4e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b,
+	0x4e, 0x61, 0x6d, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x22, 0x1d,
+	0x0a, 0x10, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x72, 0x6d, 0x41, 0x75, 0x74, 0x68, 0x52, 0x65, 0x70,
+	0x6c, 0x79, 0x4a, 0x04, 0x08, 0x01, 0x10, 0x02, 0x52, 0x03, 0x65, 0x72, 0x72, 0x22, 0x91, 0x02,
+	0x0a, 0x07, 0x55, 0x45, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x16, 0x0a, 0x04, 0x74, 0x69, 0x6d,
+	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x42, 0x02, 0x18, 0x01, 0x52, 0x04, 0x74, 0x69, 0x6d,
+	0x65, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x12, 0x18, 0x0a,
+	0x07, 0x6f, 0x75, 0x74, 0x63, 0x6f, 0x6d, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07,
+	0x6f, 0x75, 0x74, 0x63, 0x6f, 0x6d, 0x65, 0x12, 0x33, 0x0a, 0x06, 0x64, 0x65, 0x74, 0x61, 0x69,
+	0x6c, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x75, 0x64, 0x6d, 0x2e, 0x76, 0x32,
+	0x2e, 0x55, 0x45, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x2e, 0x44, 0x65, 0x74, 0x61, 0x69, 0x6c, 0x45,
+	0x6e, 0x74, 0x72, 0x79, 0x52, 0x06, 0x64, 0x65, 0x74, 0x61, 0x69, 0x6c, 0x12, 0x38, 0x0a, 0x09,
+	0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x74, 0x69, 0x6d,
+	0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x1a, 0x39, 0x0a, 0x0b, 0x44, 0x65, 0x74, 0x61, 0x69, 0x6c,
+	0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38,
+	0x01, 0x22, 0x8f, 0x02, 0x0a, 0x13, 0x47, 0x65, 0x74, 0x55, 0x45, 0x48, 0x69, 0x73, 0x74, 0x6f,
+	0x72, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x75, 0x70,
+	0x69, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x73, 0x75, 0x70, 0x69, 0x12, 0x18, 0x0a,
+	0x05, 0x73, 0x69, 0x6e, 0x63, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x42, 0x02, 0x18, 0x01,
+	0x52, 0x05, 0x73, 0x69, 0x6e, 0x63, 0x65, 0x12, 0x18, 0x0a, 0x05, 0x75, 0x6e, 0x74, 0x69, 0x6c,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x42, 0x02, 0x18, 0x01, 0x52, 0x05, 0x75, 0x6e, 0x74, 0x69,
+	0x6c, 0x12, 0x1b, 0x0a, 0x09, 0x70, 0x61, 0x67, 0x65, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x70, 0x61, 0x67, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x1d,
+	0x0a, 0x0a, 0x70, 0x61, 0x67, 0x65, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x09, 0x70, 0x61, 0x67, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x39, 0x0a,
+	0x0a, 0x73, 0x69, 0x6e, 0x63, 0x65, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x73,
+	0x69, 0x6e, 0x63, 0x65, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x39, 0x0a, 0x0a, 0x75, 0x6e, 0x74, 0x69,
+	0x6c, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54,
+	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x75, 0x6e, 0x74, 0x69, 0x6c, 0x54,
+	0x69, 0x6d, 0x65, 0x22, 0x6f, 0x0a, 0x11, 0x47, 0x65, 0x74, 0x55, 0x45, 0x48, 0x69, 0x73, 0x74,
+	0x6f, 0x72, 0x79, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x27, 0x0a, 0x06, 0x65, 0x76, 0x65, 0x6e,
+	0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x75, 0x64, 0x6d, 0x2e, 0x76,
+	0x32, 0x2e, 0x55, 0x45, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x52, 0x06, 0x65, 0x76, 0x65, 0x6e, 0x74,
+	0x73, 0x12, 0x26, 0x0a, 0x0f, 0x6e, 0x65, 0x78, 0x74, 0x5f, 0x70, 0x61, 0x67, 0x65, 0x5f, 0x74,
+	0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x6e, 0x65, 0x78, 0x74,
+	0x50, 0x61, 0x67, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x4a, 0x04, 0x08, 0x03, 0x10, 0x04, 0x52,
+	0x03, 0x65, 0x72, 0x72, 0x32, 0xc1, 0x04, 0x0a, 0x03, 0x55, 0x64, 0x6d, 0x12, 0x54, 0x0a, 0x10,
+	0x47, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x41, 0x75, 0x74, 0x68, 0x44, 0x61, 0x74, 0x61,
+	0x12, 0x1f, 0x2e, 0x75, 0x64, 0x6d, 0x2e, 0x76, 0x32, 0x2e, 0x47, 0x65, 0x6e, 0x65, 0x72, 0x61,
+	0x74, 0x65, 0x41, 0x75, 0x74, 0x68, 0x44, 0x61, 0x74, 0x61, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x1d, 0x2e, 0x75, 0x64, 0x6d, 0x2e, 0x76, 0x32, 0x2e, 0x47, 0x65, 0x6e, 0x65, 0x72,
+	0x61, 0x74, 0x65, 0x41, 0x75, 0x74, 0x68, 0x44, 0x61, 0x74, 0x61, 0x52, 0x65, 0x70, 0x6c, 0x79,
+	0x22, 0x00, 0x12, 0x54, 0x0a, 0x10, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x53, 0x75, 0x62, 0x73,
+	0x63, 0x72, 0x69, 0x62, 0x65, 0x72, 0x12, 0x1f, 0x2e, 0x75, 0x64, 0x6d, 0x2e, 0x76, 0x32, 0x2e,
+	0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x72,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1d, 0x2e, 0x75, 0x64, 0x6d, 0x2e, 0x76, 0x32,
+	0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65,
+	0x72, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x54, 0x0a, 0x10, 0x55, 0x70, 0x64, 0x61,
+	0x74, 0x65, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x72, 0x12, 0x1f, 0x2e, 0x75,
+	0x64, 0x6d, 0x2e, 0x76, 0x32, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x53, 0x75, 0x62, 0x73,
+	0x63, 0x72, 0x69, 0x62, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1d, 0x2e,
+	0x75, 0x64, 0x6d, 0x2e, 0x76, 0x32, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x53, 0x75, 0x62,
+	0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x72, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x54,
+	0x0a, 0x10, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62,
+	0x65, 0x72, 0x12, 0x1f, 0x2e, 0x75, 0x64, 0x6d, 0x2e, 0x76, 0x32, 0x2e, 0x44, 0x65, 0x6c, 0x65,
+	0x74, 0x65, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x1d, 0x2e, 0x75, 0x64, 0x6d, 0x2e, 0x76, 0x32, 0x2e, 0x44, 0x65, 0x6c,
+	0x65, 0x74, 0x65, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x72, 0x52, 0x65, 0x70,
+	0x6c, 0x79, 0x22, 0x00, 0x12, 0x51, 0x0a, 0x0f, 0x4c, 0x69, 0x73, 0x74, 0x53, 0x75, 0x62, 0x73,
+	0x63, 0x72, 0x69, 0x62, 0x65, 0x72, 0x73, 0x12, 0x1e, 0x2e, 0x75, 0x64, 0x6d, 0x2e, 0x76, 0x32,
+	0x2e, 0x4c, 0x69, 0x73, 0x74, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x72, 0x73,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1c, 0x2e, 0x75, 0x64, 0x6d, 0x2e, 0x76, 0x32,
+	0x2e, 0x4c, 0x69, 0x73, 0x74, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x72, 0x73,
+	0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x45, 0x0a, 0x0b, 0x43, 0x6f, 0x6e, 0x66, 0x69,
+	0x72, 0x6d, 0x41, 0x75, 0x74, 0x68, 0x12, 0x1a, 0x2e, 0x75, 0x64, 0x6d, 0x2e, 0x76, 0x32, 0x2e,
+	0x43, 0x6f, 0x6e, 0x66, 0x69, 0x72, 0x6d, 0x41, 0x75, 0x74, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x18, 0x2e, 0x75, 0x64, 0x6d, 0x2e, 0x76, 0x32, 0x2e, 0x43, 0x6f, 0x6e, 0x66,
+	0x69, 0x72, 0x6d, 0x41, 0x75, 0x74, 0x68, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x48,
+	0x0a, 0x0c, 0x47, 0x65, 0x74, 0x55, 0x45, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72, 0x79, 0x12, 0x1b,
+	0x2e, 0x75, 0x64, 0x6d, 0x2e, 0x76, 0x32, 0x2e, 0x47, 0x65, 0x74, 0x55, 0x45, 0x48, 0x69, 0x73,
+	0x74, 0x6f, 0x72, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x19, 0x2e, 0x75, 0x64,
+	0x6d, 0x2e, 0x76, 0x32, 0x2e, 0x47, 0x65, 0x74, 0x55, 0x45, 0x48, 0x69, 0x73, 0x74, 0x6f, 0x72,
+	0x79, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x42, 0x36, 0x5a, 0x34, 0x67, 0x69, 0x74, 0x68,
+	0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6d, 0x69, 0x6b, 0x69, 0x2d, 0x74, 0x6e, 0x74, 0x2f,
+	0x73, 0x61, 0x35, 0x67, 0x2d, 0x67, 0x6f, 0x2d, 0x75, 0x73, 0x76, 0x63, 0x2d, 0x6b, 0x38, 0x73,
+	0x2f, 0x70, 0x62, 0x2f, 0x75, 0x64, 0x6d, 0x2f, 0x76, 0x32, 0x3b, 0x75, 0x64, 0x6d, 0x76, 0x32,
+	0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_udm_v2_udm_proto_rawDescOnce sync.Once
+	file_udm_v2_udm_proto_rawDescData = file_udm_v2_udm_proto_rawDesc
+)
+
+func file_udm_v2_udm_proto_rawDescGZIP() []byte {
+	file_udm_v2_udm_proto_rawDescOnce.Do(func() {
+		file_udm_v2_udm_proto_rawDescData = protoimpl.X.CompressGZIP(file_udm_v2_udm_proto_rawDescData)
+	})
+	return file_udm_v2_udm_proto_rawDescData
+}
+
+var file_udm_v2_udm_proto_msgTypes = make([]protoimpl.MessageInfo, 20)
+var file_udm_v2_udm_proto_goTypes = []interface{}{
+	(*ResynchronizationInfo)(nil),   // 0: udm.v2.ResynchronizationInfo
+	(*GenerateAuthDataRequest)(nil), // 1: udm.v2.GenerateAuthDataRequest
+	(*GenerateAuthDataReply)(nil),   // 2: udm.v2.GenerateAuthDataReply
+	(*Snssai)(nil),                  // 3: udm.v2.Snssai
+	(*Ambr)(nil),                    // 4: udm.v2.Ambr
+	(*Subscriber)(nil),              // 5: udm.v2.Subscriber
+	(*CreateSubscriberRequest)(nil), // 6: udm.v2.CreateSubscriberRequest
+	(*CreateSubscriberReply)(nil),   // 7: udm.v2.CreateSubscriberReply
+	(*UpdateSubscriberRequest)(nil), // 8: udm.v2.UpdateSubscriberRequest
+	(*UpdateSubscriberReply)(nil),   // 9: udm.v2.UpdateSubscriberReply
+	(*DeleteSubscriberRequest)(nil), // 10: udm.v2.DeleteSubscriberRequest
+	(*DeleteSubscriberReply)(nil),   // 11: udm.v2.DeleteSubscriberReply
+	(*ListSubscribersRequest)(nil),  // 12: udm.v2.ListSubscribersRequest
+	(*ListSubscribersReply)(nil),    // 13: udm.v2.ListSubscribersReply
+	(*ConfirmAuthRequest)(nil),      // 14: udm.v2.ConfirmAuthRequest
+	(*ConfirmAuthReply)(nil),        // 15: udm.v2.ConfirmAuthReply
+	(*UEEvent)(nil),                 // 16: udm.v2.UEEvent
+	(*GetUEHistoryRequest)(nil),     // 17: udm.v2.GetUEHistoryRequest
+	(*GetUEHistoryReply)(nil),       // 18: udm.v2.GetUEHistoryReply
+	nil,                             // 19: udm.v2.UEEvent.DetailEntry
+	(*timestamp.Timestamp)(nil),     // 20: google.protobuf.Timestamp
+}
+var file_udm_v2_udm_proto_depIdxs = []int32{
+	0,  // 0: udm.v2.GenerateAuthDataRequest.resync:type_name -> udm.v2.ResynchronizationInfo
+	3,  // 1: udm.v2.Subscriber.default_snssai:type_name -> udm.v2.Snssai
+	4,  // 2: udm.v2.Subscriber.ambr:type_name -> udm.v2.Ambr
+	5,  // 3: udm.v2.CreateSubscriberRequest.subscriber:type_name -> udm.v2.Subscriber
+	5,  // 4: udm.v2.CreateSubscriberReply.subscriber:type_name -> udm.v2.Subscriber
+	5,  // 5: udm.v2.UpdateSubscriberRequest.subscriber:type_name -> udm.v2.Subscriber
+	5,  // 6: udm.v2.UpdateSubscriberReply.subscriber:type_name -> udm.v2.Subscriber
+	5,  // 7: udm.v2.ListSubscribersReply.subscribers:type_name -> udm.v2.Subscriber
+	19, // 8: udm.v2.UEEvent.detail:type_name -> udm.v2.UEEvent.DetailEntry
+	20, // 9: udm.v2.UEEvent.timestamp:type_name -> google.protobuf.Timestamp
+	20, // 10: udm.v2.GetUEHistoryRequest.since_time:type_name -> google.protobuf.Timestamp
+	20, // 11: udm.v2.GetUEHistoryRequest.until_time:type_name -> google.protobuf.Timestamp
+	16, // 12: udm.v2.GetUEHistoryReply.events:type_name -> udm.v2.UEEvent
+	1,  // 13: udm.v2.Udm.GenerateAuthData:input_type -> udm.v2.GenerateAuthDataRequest
+	6,  // 14: udm.v2.Udm.CreateSubscriber:input_type -> udm.v2.CreateSubscriberRequest
+	8,  // 15: udm.v2.Udm.UpdateSubscriber:input_type -> udm.v2.UpdateSubscriberRequest
+	10, // 16: udm.v2.Udm.DeleteSubscriber:input_type -> udm.v2.DeleteSubscriberRequest
+	12, // 17: udm.v2.Udm.ListSubscribers:input_type -> udm.v2.ListSubscribersRequest
+	14, // 18: udm.v2.Udm.ConfirmAuth:input_type -> udm.v2.ConfirmAuthRequest
+	17, // 19: udm.v2.Udm.GetUEHistory:input_type -> udm.v2.GetUEHistoryRequest
+	2,  // 20: udm.v2.Udm.GenerateAuthData:output_type -> udm.v2.GenerateAuthDataReply
+	7,  // 21: udm.v2.Udm.CreateSubscriber:output_type -> udm.v2.CreateSubscriberReply
+	9,  // 22: udm.v2.Udm.UpdateSubscriber:output_type -> udm.v2.UpdateSubscriberReply
+	11, // 23: udm.v2.Udm.DeleteSubscriber:output_type -> udm.v2.DeleteSubscriberReply
+	13, // 24: udm.v2.Udm.ListSubscribers:output_type -> udm.v2.ListSubscribersReply
+	15, // 25: udm.v2.Udm.ConfirmAuth:output_type -> udm.v2.ConfirmAuthReply
+	18, // 26: udm.v2.Udm.GetUEHistory:output_type -> udm.v2.GetUEHistoryReply
+	20, // [20:27] is the sub-list for method output_type
+	13, // [13:20] is the sub-list for method input_type
+	13, // [13:13] is the sub-list for extension type_name
+	13, // [13:13] is the sub-list for extension extendee
+	0,  // [0:13] is the sub-list for field type_name
+}
+
+func init() { file_udm_v2_udm_proto_init() }
+func file_udm_v2_udm_proto_init() {
+	if File_udm_v2_udm_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_udm_v2_udm_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ResynchronizationInfo); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_udm_v2_udm_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GenerateAuthDataRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_udm_v2_udm_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GenerateAuthDataReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_udm_v2_udm_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Snssai); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_udm_v2_udm_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Ambr); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_udm_v2_udm_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Subscriber); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_udm_v2_udm_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CreateSubscriberRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_udm_v2_udm_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CreateSubscriberReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_udm_v2_udm_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UpdateSubscriberRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_udm_v2_udm_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UpdateSubscriberReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_udm_v2_udm_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DeleteSubscriberRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_udm_v2_udm_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DeleteSubscriberReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_udm_v2_udm_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListSubscribersRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_udm_v2_udm_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListSubscribersReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_udm_v2_udm_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ConfirmAuthRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_udm_v2_udm_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ConfirmAuthReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_udm_v2_udm_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UEEvent); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_udm_v2_udm_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetUEHistoryRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_udm_v2_udm_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetUEHistoryReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_udm_v2_udm_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   20,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_udm_v2_udm_proto_goTypes,
+		DependencyIndexes: file_udm_v2_udm_proto_depIdxs,
+		MessageInfos:      file_udm_v2_udm_proto_msgTypes,
+	}.Build()
+	File_udm_v2_udm_proto = out.File
+	file_udm_v2_udm_proto_rawDesc = nil
+	file_udm_v2_udm_proto_goTypes = nil
+	file_udm_v2_udm_proto_depIdxs = nil
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConnInterface
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion6
+
+// UdmClient is the client API for Udm service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type UdmClient interface {
+	GenerateAuthData(ctx context.Context, in *GenerateAuthDataRequest, opts ...grpc.CallOption) (*GenerateAuthDataReply, error)
+	CreateSubscriber(ctx context.Context, in *CreateSubscriberRequest, opts ...grpc.CallOption) (*CreateSubscriberReply, error)
+	UpdateSubscriber(ctx context.Context, in *UpdateSubscriberRequest, opts ...grpc.CallOption) (*UpdateSubscriberReply, error)
+	DeleteSubscriber(ctx context.Context, in *DeleteSubscriberRequest, opts ...grpc.CallOption) (*DeleteSubscriberReply, error)
+	ListSubscribers(ctx context.Context, in *ListSubscribersRequest, opts ...grpc.CallOption) (*ListSubscribersReply, error)
+	ConfirmAuth(ctx context.Context, in *ConfirmAuthRequest, opts ...grpc.CallOption) (*ConfirmAuthReply, error)
+	GetUEHistory(ctx context.Context, in *GetUEHistoryRequest, opts ...grpc.CallOption) (*GetUEHistoryReply, error)
+}
+
+type udmClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewUdmClient(cc grpc.ClientConnInterface) UdmClient {
+	return &udmClient{cc}
+}
+
+func (c *udmClient) GenerateAuthData(ctx context.Context, in *GenerateAuthDataRequest, opts ...grpc.CallOption) (*GenerateAuthDataReply, error) {
+	out := new(GenerateAuthDataReply)
+	err := c.cc.Invoke(ctx, "/udm.v2.Udm/GenerateAuthData", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *udmClient) CreateSubscriber(ctx context.Context, in *CreateSubscriberRequest, opts ...grpc.CallOption) (*CreateSubscriberReply, error) {
+	out := new(CreateSubscriberReply)
+	err := c.cc.Invoke(ctx, "/udm.v2.Udm/CreateSubscriber", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *udmClient) UpdateSubscriber(ctx context.Context, in *UpdateSubscriberRequest, opts ...grpc.CallOption) (*UpdateSubscriberReply, error) {
+	out := new(UpdateSubscriberReply)
+	err := c.cc.Invoke(ctx, "/udm.v2.Udm/UpdateSubscriber", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *udmClient) DeleteSubscriber(ctx context.Context, in *DeleteSubscriberRequest, opts ...grpc.CallOption) (*DeleteSubscriberReply, error) {
+	out := new(DeleteSubscriberReply)
+	err := c.cc.Invoke(ctx, "/udm.v2.Udm/DeleteSubscriber", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *udmClient) ListSubscribers(ctx context.Context, in *ListSubscribersRequest, opts ...grpc.CallOption) (*ListSubscribersReply, error) {
+	out := new(ListSubscribersReply)
+	err := c.cc.Invoke(ctx, "/udm.v2.Udm/ListSubscribers", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *udmClient) ConfirmAuth(ctx context.Context, in *ConfirmAuthRequest, opts ...grpc.CallOption) (*ConfirmAuthReply, error) {
+	out := new(ConfirmAuthReply)
+	err := c.cc.Invoke(ctx, "/udm.v2.Udm/ConfirmAuth", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *udmClient) GetUEHistory(ctx context.Context, in *GetUEHistoryRequest, opts ...grpc.CallOption) (*GetUEHistoryReply, error) {
+	out := new(GetUEHistoryReply)
+	err := c.cc.Invoke(ctx, "/udm.v2.Udm/GetUEHistory", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UdmServer is the server API for Udm service.
+type UdmServer interface {
+	GenerateAuthData(context.Context, *GenerateAuthDataRequest) (*GenerateAuthDataReply, error)
+	CreateSubscriber(context.Context, *CreateSubscriberRequest) (*CreateSubscriberReply, error)
+	UpdateSubscriber(context.Context, *UpdateSubscriberRequest) (*UpdateSubscriberReply, error)
+	DeleteSubscriber(context.Context, *DeleteSubscriberRequest) (*DeleteSubscriberReply, error)
+	ListSubscribers(context.Context, *ListSubscribersRequest) (*ListSubscribersReply, error)
+	ConfirmAuth(context.Context, *ConfirmAuthRequest) (*ConfirmAuthReply, error)
+	GetUEHistory(context.Context, *GetUEHistoryRequest) (*GetUEHistoryReply, error)
+}
+
+// UnimplementedUdmServer can be embedded to have forward compatible implementations.
+type UnimplementedUdmServer struct {
+}
+
+func (*UnimplementedUdmServer) GenerateAuthData(context.Context, *GenerateAuthDataRequest) (*GenerateAuthDataReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GenerateAuthData not implemented")
+}
+func (*UnimplementedUdmServer) CreateSubscriber(context.Context, *CreateSubscriberRequest) (*CreateSubscriberReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateSubscriber not implemented")
+}
+func (*UnimplementedUdmServer) UpdateSubscriber(context.Context, *UpdateSubscriberRequest) (*UpdateSubscriberReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateSubscriber not implemented")
+}
+func (*UnimplementedUdmServer) DeleteSubscriber(context.Context, *DeleteSubscriberRequest) (*DeleteSubscriberReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteSubscriber not implemented")
+}
+func (*UnimplementedUdmServer) ListSubscribers(context.Context, *ListSubscribersRequest) (*ListSubscribersReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSubscribers not implemented")
+}
+func (*UnimplementedUdmServer) ConfirmAuth(context.Context, *ConfirmAuthRequest) (*ConfirmAuthReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ConfirmAuth not implemented")
+}
+func (*UnimplementedUdmServer) GetUEHistory(context.Context, *GetUEHistoryRequest) (*GetUEHistoryReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUEHistory not implemented")
+}
+
+func RegisterUdmServer(s *grpc.Server, srv UdmServer) {
+	s.RegisterService(&_Udm_serviceDesc, srv)
+}
+
+func _Udm_GenerateAuthData_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GenerateAuthDataRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UdmServer).GenerateAuthData(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/udm.v2.Udm/GenerateAuthData",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UdmServer).GenerateAuthData(ctx, req.(*GenerateAuthDataRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Udm_CreateSubscriber_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateSubscriberRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UdmServer).CreateSubscriber(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/udm.v2.Udm/CreateSubscriber",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UdmServer).CreateSubscriber(ctx, req.(*CreateSubscriberRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Udm_UpdateSubscriber_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateSubscriberRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UdmServer).UpdateSubscriber(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/udm.v2.Udm/UpdateSubscriber",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UdmServer).UpdateSubscriber(ctx, req.(*UpdateSubscriberRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Udm_DeleteSubscriber_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteSubscriberRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UdmServer).DeleteSubscriber(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/udm.v2.Udm/DeleteSubscriber",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UdmServer).DeleteSubscriber(ctx, req.(*DeleteSubscriberRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Udm_ListSubscribers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSubscribersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UdmServer).ListSubscribers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/udm.v2.Udm/ListSubscribers",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UdmServer).ListSubscribers(ctx, req.(*ListSubscribersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Udm_ConfirmAuth_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConfirmAuthRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UdmServer).ConfirmAuth(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/udm.v2.Udm/ConfirmAuth",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UdmServer).ConfirmAuth(ctx, req.(*ConfirmAuthRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Udm_GetUEHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUEHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UdmServer).GetUEHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/udm.v2.Udm/GetUEHistory",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UdmServer).GetUEHistory(ctx, req.(*GetUEHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Udm_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "udm.v2.Udm",
+	HandlerType: (*UdmServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GenerateAuthData",
+			Handler:    _Udm_GenerateAuthData_Handler,
+		},
+		{
+			MethodName: "CreateSubscriber",
+			Handler:    _Udm_CreateSubscriber_Handler,
+		},
+		{
+			MethodName: "UpdateSubscriber",
+			Handler:    _Udm_UpdateSubscriber_Handler,
+		},
+		{
+			MethodName: "DeleteSubscriber",
+			Handler:    _Udm_DeleteSubscriber_Handler,
+		},
+		{
+			MethodName: "ListSubscribers",
+			Handler:    _Udm_ListSubscribers_Handler,
+		},
+		{
+			MethodName: "ConfirmAuth",
+			Handler:    _Udm_ConfirmAuth_Handler,
+		},
+		{
+			MethodName: "GetUEHistory",
+			Handler:    _Udm_GetUEHistory_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "udm/v2/udm.proto",
+}
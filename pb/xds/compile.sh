@@ -0,0 +1,7 @@
+#!/usr/bin/env sh
+
+# See ../preamblesvc/compile.sh for the tools. The files are compiled from pb/
+# so that their names are xds/core.proto, xds/discovery.proto and so on in
+# the registry, apart from the names of the Envoy API they are a subset of.
+
+cd .. && protoc xds/core.proto xds/listener.proto xds/route.proto xds/http_connection_manager.proto xds/cluster.proto xds/endpoint.proto xds/discovery.proto --go_out=plugins=grpc,paths=source_relative:.
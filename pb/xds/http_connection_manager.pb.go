@@ -0,0 +1,275 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.24.0
+// 	protoc        v3.12.2
+// source: xds/http_connection_manager.proto
+
+// The subset of the HttpConnectionManager network filter read by pkg/xds
+// (see xds/core.proto).
+
+package xds
+
+import (
+	proto "github.com/golang/protobuf/proto"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// This is a compile-time assertion that a sufficiently up-to-date version
+// of the legacy proto package is being used.
+const _ = proto.ProtoPackageIsVersion4
+
+// HttpConnectionManager gives the routes of a listener, inline or by the
+// name of their RDS resource.
+type HttpConnectionManager struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to RouteSpecifier:
+	//	*HttpConnectionManager_Rds
+	//	*HttpConnectionManager_RouteConfig
+	RouteSpecifier isHttpConnectionManager_RouteSpecifier `protobuf_oneof:"route_specifier"`
+}
+
+func (x *HttpConnectionManager) Reset() {
+	*x = HttpConnectionManager{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_xds_http_connection_manager_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *HttpConnectionManager) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HttpConnectionManager) ProtoMessage() {}
+
+func (x *HttpConnectionManager) ProtoReflect() protoreflect.Message {
+	mi := &file_xds_http_connection_manager_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HttpConnectionManager.ProtoReflect.Descriptor instead.
+func (*HttpConnectionManager) Descriptor() ([]byte, []int) {
+	return file_xds_http_connection_manager_proto_rawDescGZIP(), []int{0}
+}
+
+func (m *HttpConnectionManager) GetRouteSpecifier() isHttpConnectionManager_RouteSpecifier {
+	if m != nil {
+		return m.RouteSpecifier
+	}
+	return nil
+}
+
+func (x *HttpConnectionManager) GetRds() *Rds {
+	if x, ok := x.GetRouteSpecifier().(*HttpConnectionManager_Rds); ok {
+		return x.Rds
+	}
+	return nil
+}
+
+func (x *HttpConnectionManager) GetRouteConfig() *RouteConfiguration {
+	if x, ok := x.GetRouteSpecifier().(*HttpConnectionManager_RouteConfig); ok {
+		return x.RouteConfig
+	}
+	return nil
+}
+
+type isHttpConnectionManager_RouteSpecifier interface {
+	isHttpConnectionManager_RouteSpecifier()
+}
+
+type HttpConnectionManager_Rds struct {
+	Rds *Rds `protobuf:"bytes,3,opt,name=rds,proto3,oneof"`
+}
+
+type HttpConnectionManager_RouteConfig struct {
+	RouteConfig *RouteConfiguration `protobuf:"bytes,4,opt,name=route_config,json=routeConfig,proto3,oneof"`
+}
+
+func (*HttpConnectionManager_Rds) isHttpConnectionManager_RouteSpecifier() {}
+
+func (*HttpConnectionManager_RouteConfig) isHttpConnectionManager_RouteSpecifier() {}
+
+type Rds struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RouteConfigName string `protobuf:"bytes,2,opt,name=route_config_name,json=routeConfigName,proto3" json:"route_config_name,omitempty"`
+}
+
+func (x *Rds) Reset() {
+	*x = Rds{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_xds_http_connection_manager_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Rds) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Rds) ProtoMessage() {}
+
+func (x *Rds) ProtoReflect() protoreflect.Message {
+	mi := &file_xds_http_connection_manager_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Rds.ProtoReflect.Descriptor instead.
+func (*Rds) Descriptor() ([]byte, []int) {
+	return file_xds_http_connection_manager_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Rds) GetRouteConfigName() string {
+	if x != nil {
+		return x.RouteConfigName
+	}
+	return ""
+}
+
+var File_xds_http_connection_manager_proto protoreflect.FileDescriptor
+
+var file_xds_http_connection_manager_proto_rawDesc = []byte{
+	0x0a, 0x21, 0x78, 0x64, 0x73, 0x2f, 0x68, 0x74, 0x74, 0x70, 0x5f, 0x63, 0x6f, 0x6e, 0x6e, 0x65,
+	0x63, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x6d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x72, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x12, 0x3b, 0x65, 0x6e, 0x76, 0x6f, 0x79, 0x2e, 0x65, 0x78, 0x74, 0x65, 0x6e,
+	0x73, 0x69, 0x6f, 0x6e, 0x73, 0x2e, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x73, 0x2e, 0x6e, 0x65,
+	0x74, 0x77, 0x6f, 0x72, 0x6b, 0x2e, 0x68, 0x74, 0x74, 0x70, 0x5f, 0x63, 0x6f, 0x6e, 0x6e, 0x65,
+	0x63, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x6d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x72, 0x2e, 0x76, 0x33,
+	0x1a, 0x0f, 0x78, 0x64, 0x73, 0x2f, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x22, 0xd0, 0x01, 0x0a, 0x15, 0x48, 0x74, 0x74, 0x70, 0x43, 0x6f, 0x6e, 0x6e, 0x65, 0x63,
+	0x74, 0x69, 0x6f, 0x6e, 0x4d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x72, 0x12, 0x54, 0x0a, 0x03, 0x72,
+	0x64, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x40, 0x2e, 0x65, 0x6e, 0x76, 0x6f, 0x79,
+	0x2e, 0x65, 0x78, 0x74, 0x65, 0x6e, 0x73, 0x69, 0x6f, 0x6e, 0x73, 0x2e, 0x66, 0x69, 0x6c, 0x74,
+	0x65, 0x72, 0x73, 0x2e, 0x6e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x2e, 0x68, 0x74, 0x74, 0x70,
+	0x5f, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x6d, 0x61, 0x6e, 0x61,
+	0x67, 0x65, 0x72, 0x2e, 0x76, 0x33, 0x2e, 0x52, 0x64, 0x73, 0x48, 0x00, 0x52, 0x03, 0x72, 0x64,
+	0x73, 0x12, 0x4e, 0x0a, 0x0c, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f, 0x63, 0x6f, 0x6e, 0x66, 0x69,
+	0x67, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x29, 0x2e, 0x65, 0x6e, 0x76, 0x6f, 0x79, 0x2e,
+	0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x2e, 0x76, 0x33, 0x2e,
+	0x52, 0x6f, 0x75, 0x74, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x48, 0x00, 0x52, 0x0b, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x69,
+	0x67, 0x42, 0x11, 0x0a, 0x0f, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x5f, 0x73, 0x70, 0x65, 0x63, 0x69,
+	0x66, 0x69, 0x65, 0x72, 0x22, 0x31, 0x0a, 0x03, 0x52, 0x64, 0x73, 0x12, 0x2a, 0x0a, 0x11, 0x72,
+	0x6f, 0x75, 0x74, 0x65, 0x5f, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x5f, 0x6e, 0x61, 0x6d, 0x65,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x72, 0x6f, 0x75, 0x74, 0x65, 0x43, 0x6f, 0x6e,
+	0x66, 0x69, 0x67, 0x4e, 0x61, 0x6d, 0x65, 0x42, 0x31, 0x5a, 0x2f, 0x67, 0x69, 0x74, 0x68, 0x75,
+	0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6d, 0x69, 0x6b, 0x69, 0x2d, 0x74, 0x6e, 0x74, 0x2f, 0x73,
+	0x61, 0x35, 0x67, 0x2d, 0x67, 0x6f, 0x2d, 0x75, 0x73, 0x76, 0x63, 0x2d, 0x6b, 0x38, 0x73, 0x2f,
+	0x70, 0x62, 0x2f, 0x78, 0x64, 0x73, 0x3b, 0x78, 0x64, 0x73, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x33,
+}
+
+var (
+	file_xds_http_connection_manager_proto_rawDescOnce sync.Once
+	file_xds_http_connection_manager_proto_rawDescData = file_xds_http_connection_manager_proto_rawDesc
+)
+
+func file_xds_http_connection_manager_proto_rawDescGZIP() []byte {
+	file_xds_http_connection_manager_proto_rawDescOnce.Do(func() {
+		file_xds_http_connection_manager_proto_rawDescData = protoimpl.X.CompressGZIP(file_xds_http_connection_manager_proto_rawDescData)
+	})
+	return file_xds_http_connection_manager_proto_rawDescData
+}
+
+var file_xds_http_connection_manager_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_xds_http_connection_manager_proto_goTypes = []interface{}{
+	(*HttpConnectionManager)(nil), // 0: envoy.extensions.filters.network.http_connection_manager.v3.HttpConnectionManager
+	(*Rds)(nil),                   // 1: envoy.extensions.filters.network.http_connection_manager.v3.Rds
+	(*RouteConfiguration)(nil),    // 2: envoy.config.route.v3.RouteConfiguration
+}
+var file_xds_http_connection_manager_proto_depIdxs = []int32{
+	1, // 0: envoy.extensions.filters.network.http_connection_manager.v3.HttpConnectionManager.rds:type_name -> envoy.extensions.filters.network.http_connection_manager.v3.Rds
+	2, // 1: envoy.extensions.filters.network.http_connection_manager.v3.HttpConnectionManager.route_config:type_name -> envoy.config.route.v3.RouteConfiguration
+	2, // [2:2] is the sub-list for method output_type
+	2, // [2:2] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_xds_http_connection_manager_proto_init() }
+func file_xds_http_connection_manager_proto_init() {
+	if File_xds_http_connection_manager_proto != nil {
+		return
+	}
+	file_xds_route_proto_init()
+	if !protoimpl.UnsafeEnabled {
+		file_xds_http_connection_manager_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*HttpConnectionManager); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_xds_http_connection_manager_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Rds); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_xds_http_connection_manager_proto_msgTypes[0].OneofWrappers = []interface{}{
+		(*HttpConnectionManager_Rds)(nil),
+		(*HttpConnectionManager_RouteConfig)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_xds_http_connection_manager_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_xds_http_connection_manager_proto_goTypes,
+		DependencyIndexes: file_xds_http_connection_manager_proto_depIdxs,
+		MessageInfos:      file_xds_http_connection_manager_proto_msgTypes,
+	}.Build()
+	File_xds_http_connection_manager_proto = out.File
+	file_xds_http_connection_manager_proto_rawDesc = nil
+	file_xds_http_connection_manager_proto_goTypes = nil
+	file_xds_http_connection_manager_proto_depIdxs = nil
+}
@@ -2,20 +2,11 @@ package endpoints
 
 import (
 	"context"
-	"time"
 
-	"github.com/go-kit/kit/circuitbreaker"
 	"github.com/go-kit/kit/endpoint"
-	"github.com/go-kit/kit/log"
-	"github.com/go-kit/kit/ratelimit"
-	"github.com/go-kit/kit/tracing/opentracing"
-	"github.com/go-kit/kit/tracing/zipkin"
-	stdopentracing "github.com/opentracing/opentracing-go"
-	stdzipkin "github.com/openzipkin/zipkin-go"
-	"github.com/sony/gobreaker"
-	"golang.org/x/time/rate"
 
 	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/addsvc/service"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/kitx/chain"
 )
 
 // Endpoints collects all of the endpoints that compose the addsvc service. It's
@@ -27,31 +18,12 @@ type Endpoints struct {
 }
 
 // New return a new instance of the endpoint that wraps the provided service.
-func New(svc service.AddsvcService, logger log.Logger, otTracer stdopentracing.Tracer, zipkinTracer *stdzipkin.Tracer) (ep Endpoints) {
-	var sumEndpoint endpoint.Endpoint
-	{
-		method := "sum"
-		sumEndpoint = MakeSumEndpoint(svc)
-		sumEndpoint = ratelimit.NewErroringLimiter(rate.NewLimiter(rate.Every(time.Second), 100))(sumEndpoint)
-		sumEndpoint = circuitbreaker.Gobreaker(gobreaker.NewCircuitBreaker(gobreaker.Settings{}))(sumEndpoint)
-		sumEndpoint = opentracing.TraceServer(otTracer, method)(sumEndpoint)
-		sumEndpoint = zipkin.TraceEndpoint(zipkinTracer, method)(sumEndpoint)
-		sumEndpoint = LoggingMiddleware(log.With(logger, "method", method))(sumEndpoint)
-		ep.SumEndpoint = sumEndpoint
-	}
-
-	var concatEndpoint endpoint.Endpoint
-	{
-		method := "concat"
-		concatEndpoint = MakeConcatEndpoint(svc)
-		concatEndpoint = ratelimit.NewErroringLimiter(rate.NewLimiter(rate.Every(time.Second), 100))(concatEndpoint)
-		concatEndpoint = circuitbreaker.Gobreaker(gobreaker.NewCircuitBreaker(gobreaker.Settings{}))(concatEndpoint)
-		concatEndpoint = opentracing.TraceServer(otTracer, method)(concatEndpoint)
-		concatEndpoint = zipkin.TraceEndpoint(zipkinTracer, method)(concatEndpoint)
-		concatEndpoint = LoggingMiddleware(log.With(logger, "method", method))(concatEndpoint)
-		ep.ConcatEndpoint = concatEndpoint
-	}
-
+// The endpoints run in the middleware stack of mw, the methods being idempotent.
+func New(svc service.AddsvcService, mw chain.MiddlewareConfig) (ep Endpoints) {
+	mw.Logging = LoggingMiddleware
+	c := chain.New(mw)
+	ep.SumEndpoint = c.Build("sum", MakeSumEndpoint(svc), chain.Idempotent(SumResponse{}))
+	ep.ConcatEndpoint = c.Build("concat", MakeConcatEndpoint(svc), chain.Idempotent(ConcatResponse{}))
 	return ep
 }
 
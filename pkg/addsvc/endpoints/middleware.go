@@ -7,20 +7,29 @@ import (
 	"github.com/go-kit/kit/endpoint"
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/clock"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/logging"
 )
 
 // LoggingMiddleware returns an endpoint middleware that logs the
-// duration of each invocation, and the resulting error, if any.
-func LoggingMiddleware(logger log.Logger) endpoint.Middleware {
+// duration of each invocation, timed by clk, and the resulting error, if any. Records carry
+// the trace and span IDs of the call when it is traced, and the UE of the
+// request, which is passed on in the context.
+func LoggingMiddleware(logger log.Logger, clk clock.Clock) endpoint.Middleware {
 	return func(next endpoint.Endpoint) endpoint.Endpoint {
 		return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+			if r, ok := request.(logging.UERequest); ok {
+				ctx = logging.NewUEContext(ctx, r.UEID())
+			}
 			defer func(begin time.Time) {
+				logger := log.With(logger, logging.ContextKeyvals(ctx)...)
 				if err == nil {
-					level.Info(logger).Log("transport_error", err, "took", time.Since(begin))
+					level.Info(logger).Log("transport_error", err, "took", clk.Since(begin))
 				} else {
-					level.Error(logger).Log("transport_error", err, "took", time.Since(begin))
+					level.Error(logger).Log("transport_error", err, "took", clk.Since(begin))
 				}
-			}(time.Now())
+			}(clk.Now())
 			return next(ctx, request)
 		}
 	}
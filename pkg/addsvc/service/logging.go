@@ -6,6 +6,8 @@ import (
 
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/logging"
 )
 
 type loggingMiddleware struct {
@@ -23,7 +25,7 @@ func LoggingMiddleware(logger log.Logger) Middleware {
 
 func (lm loggingMiddleware) Sum(ctx context.Context, a int64, b int64) (rs int64, err error) {
 	defer func(begin time.Time) {
-		lm.logger.Log("method", "Sum", "a", a, "b", b, "err", err)
+		log.With(lm.logger, logging.ContextKeyvals(ctx)...).Log("method", "Sum", "a", a, "b", b, "err", err)
 	}(time.Now())
 
 	return lm.next.Sum(ctx, a, b)
@@ -31,7 +33,7 @@ func (lm loggingMiddleware) Sum(ctx context.Context, a int64, b int64) (rs int64
 
 func (lm loggingMiddleware) Concat(ctx context.Context, a string, b string) (rs string, err error) {
 	defer func(begin time.Time) {
-		lm.logger.Log("method", "Concat", "a", a, "b", b, "err", err)
+		log.With(lm.logger, logging.ContextKeyvals(ctx)...).Log("method", "Concat", "a", a, "b", b, "err", err)
 	}(time.Now())
 
 	return lm.next.Concat(ctx, a, b)
@@ -19,7 +19,7 @@ type AddsvcService interface {
 
 // the concrete implementation of service interface
 type stubAddsvcService struct {
-	logger log.Logger `json:"logger"`
+	logger log.Logger
 }
 
 // New return a new instance of the service.
@@ -22,6 +22,12 @@ import (
 	pb "github.com/miki-tnt/sa5g-go-usvc-k8s/pb/addsvc"
 	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/addsvc/endpoints"
 	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/addsvc/service"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/breakers"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/caller"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/grpcpool"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/idempotency"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/logging"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/meta"
 )
 
 type grpcServer struct {
@@ -60,7 +66,10 @@ func MakeGRPCServer(endpoints endpoints.Endpoints, otTracer stdopentracing.Trace
 	zipkinServer := zipkin.GRPCServerTrace(zipkinTracer)
 
 	options := []grpctransport.ServerOption{
-		grpctransport.ServerErrorLogger(logger),
+		grpctransport.ServerErrorHandler(logging.ErrorHandler(logger)),
+		grpctransport.ServerBefore(idempotency.GRPCToContext()),
+		grpctransport.ServerBefore(caller.GRPCToContext()),
+		grpctransport.ServerBefore(meta.GRPCToContext()),
 		zipkinServer,
 	}
 
@@ -113,7 +122,13 @@ func encodeGRPCConcatResponse(_ context.Context, grpcReply interface{}) (res int
 // of the conn. The caller is responsible for constructing the conn, and
 // eventually closing the underlying transport. We bake-in certain middlewares,
 // implementing the client library pattern.
-func NewGRPCClient(conn *grpc.ClientConn, otTracer stdopentracing.Tracer, zipkinTracer *stdzipkin.Tracer, logger log.Logger) service.AddsvcService { // We construct a single ratelimiter middleware, to limit the total outgoing
+func NewGRPCClient(conn *grpc.ClientConn, otTracer stdopentracing.Tracer, zipkinTracer *stdzipkin.Tracer, logger log.Logger) service.AddsvcService {
+	return NewGRPCPoolClient(grpcpool.FromConn(conn), otTracer, zipkinTracer, logger)
+}
+
+// NewGRPCPoolClient is like NewGRPCClient, but spreads the calls over the
+// connections of the pool. The caller is responsible for closing the pool.
+func NewGRPCPoolClient(pool *grpcpool.Pool, otTracer stdopentracing.Tracer, zipkinTracer *stdzipkin.Tracer, logger log.Logger) service.AddsvcService { // We construct a single ratelimiter middleware, to limit the total outgoing
 	// QPS from this client to all methods on the remote instance. We also
 	// construct per-endpoint circuitbreaker middlewares to demonstrate how
 	// that's done, although they could easily be combined into a single breaker
@@ -131,6 +146,8 @@ func NewGRPCClient(conn *grpc.ClientConn, otTracer stdopentracing.Tracer, zipkin
 
 	// global client middlewares
 	options := []grpctransport.ClientOption{
+		grpctransport.ClientBefore(idempotency.ContextToGRPC()),
+		grpctransport.ClientBefore(meta.ContextToGRPC()),
 		zipkinClient,
 	}
 
@@ -138,18 +155,20 @@ func NewGRPCClient(conn *grpc.ClientConn, otTracer stdopentracing.Tracer, zipkin
 	// middlewares to demonstrate how to specialize per-endpoint.
 	var sumEndpoint endpoint.Endpoint
 	{
-		sumEndpoint = grpctransport.NewClient(
-			conn,
-			"pb.Addsvc",
-			"Sum",
-			encodeGRPCSumRequest,
-			decodeGRPCSumResponse,
-			pb.SumReply{},
-			append(options, grpctransport.ClientBefore(opentracing.ContextToGRPC(otTracer, logger)))...,
-		).Endpoint()
+		sumEndpoint = pool.Endpoint(func(conn *grpc.ClientConn) endpoint.Endpoint {
+			return grpctransport.NewClient(
+				conn,
+				"pb.Addsvc",
+				"Sum",
+				encodeGRPCSumRequest,
+				decodeGRPCSumResponse,
+				pb.SumReply{},
+				append(options, grpctransport.ClientBefore(opentracing.ContextToGRPC(otTracer, logger)))...,
+			).Endpoint()
+		})
 		sumEndpoint = opentracing.TraceClient(otTracer, "Sum")(sumEndpoint)
 		sumEndpoint = limiter(sumEndpoint)
-		sumEndpoint = circuitbreaker.Gobreaker(gobreaker.NewCircuitBreaker(gobreaker.Settings{
+		sumEndpoint = circuitbreaker.Gobreaker(breakers.New(gobreaker.Settings{
 			Name:    "Sum",
 			Timeout: 30 * time.Second,
 		}))(sumEndpoint)
@@ -159,18 +178,20 @@ func NewGRPCClient(conn *grpc.ClientConn, otTracer stdopentracing.Tracer, zipkin
 	// middlewares to demonstrate how to specialize per-endpoint.
 	var concatEndpoint endpoint.Endpoint
 	{
-		concatEndpoint = grpctransport.NewClient(
-			conn,
-			"pb.Addsvc",
-			"Concat",
-			encodeGRPCConcatRequest,
-			decodeGRPCConcatResponse,
-			pb.ConcatReply{},
-			append(options, grpctransport.ClientBefore(opentracing.ContextToGRPC(otTracer, logger)))...,
-		).Endpoint()
+		concatEndpoint = pool.Endpoint(func(conn *grpc.ClientConn) endpoint.Endpoint {
+			return grpctransport.NewClient(
+				conn,
+				"pb.Addsvc",
+				"Concat",
+				encodeGRPCConcatRequest,
+				decodeGRPCConcatResponse,
+				pb.ConcatReply{},
+				append(options, grpctransport.ClientBefore(opentracing.ContextToGRPC(otTracer, logger)))...,
+			).Endpoint()
+		})
 		concatEndpoint = opentracing.TraceClient(otTracer, "Concat")(concatEndpoint)
 		concatEndpoint = limiter(concatEndpoint)
-		concatEndpoint = circuitbreaker.Gobreaker(gobreaker.NewCircuitBreaker(gobreaker.Settings{
+		concatEndpoint = circuitbreaker.Gobreaker(breakers.New(gobreaker.Settings{
 			Name:    "Concat",
 			Timeout: 30 * time.Second,
 		}))(concatEndpoint)
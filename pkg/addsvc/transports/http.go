@@ -21,14 +21,23 @@ import (
 	"github.com/go-kit/kit/tracing/opentracing"
 	"github.com/go-kit/kit/tracing/zipkin"
 	httptransport "github.com/go-kit/kit/transport/http"
+	"github.com/golang/protobuf/proto"
 	stdopentracing "github.com/opentracing/opentracing-go"
 	stdzipkin "github.com/openzipkin/zipkin-go"
 	"github.com/sony/gobreaker"
 	"golang.org/x/time/rate"
 	"google.golang.org/grpc/status"
 
+	pb "github.com/miki-tnt/sa5g-go-usvc-k8s/pb/addsvc"
 	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/addsvc/endpoints"
 	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/addsvc/service"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/breakers"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/caller"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/codec"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/idempotency"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/logging"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/meta"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/openapi"
 )
 
 type errorWrapper struct {
@@ -58,40 +67,43 @@ func NewHTTPHandler(endpoints endpoints.Endpoints, otTracer stdopentracing.Trace
 
 	options := []httptransport.ServerOption{
 		httptransport.ServerErrorEncoder(httpEncodeError),
-		httptransport.ServerErrorLogger(logger),
+		httptransport.ServerErrorHandler(logging.ErrorHandler(logger)),
+		httptransport.ServerBefore(idempotency.HTTPToContext()),
+		httptransport.ServerBefore(caller.HTTPToContext()),
+		httptransport.ServerBefore(meta.HTTPToContext()),
+		httptransport.ServerBefore(codec.Default.HTTPToContext()),
 		zipkinServer,
 	}
 
 	m := http.NewServeMux()
 	m.Handle("/sum", httptransport.NewServer(
 		endpoints.SumEndpoint,
-		decodeHTTPSumRequest,
-		httptransport.EncodeJSONResponse,
+		codec.DecodeRequest(sumBinding),
+		codec.EncodeResponse(sumBinding),
 		append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "Sum", logger)))...,
 	))
 	m.Handle("/concat", httptransport.NewServer(
 		endpoints.ConcatEndpoint,
-		decodeHTTPConcatRequest,
-		httptransport.EncodeJSONResponse,
+		codec.DecodeRequest(concatBinding),
+		codec.EncodeResponse(concatBinding),
 		append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "Concat", logger)))...,
 	))
+	m.Handle(openapi.Path, openapi.Handler(OpenAPI()))
 	return m
 }
 
-// decodeHTTPSumRequest is a transport/http.DecodeRequestFunc that decodes a
-// JSON-encoded request from the HTTP request body. Primarily useful in a server.
-func decodeHTTPSumRequest(_ context.Context, r *http.Request) (interface{}, error) {
-	var req endpoints.SumRequest
-	err := json.NewDecoder(r.Body).Decode(&req)
-	return req, err
+var sumBinding = codec.Binding{
+	Request:   func() interface{} { return &endpoints.SumRequest{} },
+	PBRequest: func() proto.Message { return &pb.SumRequest{} },
+	FromPB:    decodeGRPCSumRequest,
+	ToPB:      encodeGRPCSumResponse,
 }
 
-// decodeHTTPConcatRequest is a transport/http.DecodeRequestFunc that decodes a
-// JSON-encoded request from the HTTP request body. Primarily useful in a server.
-func decodeHTTPConcatRequest(_ context.Context, r *http.Request) (interface{}, error) {
-	var req endpoints.ConcatRequest
-	err := json.NewDecoder(r.Body).Decode(&req)
-	return req, err
+var concatBinding = codec.Binding{
+	Request:   func() interface{} { return &endpoints.ConcatRequest{} },
+	PBRequest: func() proto.Message { return &pb.ConcatRequest{} },
+	FromPB:    decodeGRPCConcatRequest,
+	ToPB:      encodeGRPCConcatResponse,
 }
 
 // NewHTTPClient returns an AddService backed by an HTTP server living at the
@@ -122,6 +134,8 @@ func NewHTTPClient(instance string, otTracer stdopentracing.Tracer, zipkinTracer
 
 	// global client middlewares
 	options := []httptransport.ClientOption{
+		httptransport.ClientBefore(idempotency.ContextToHTTP()),
+		httptransport.ClientBefore(meta.ContextToHTTP()),
 		zipkinClient,
 	}
 
@@ -145,7 +159,7 @@ func NewHTTPClient(instance string, otTracer stdopentracing.Tracer, zipkinTracer
 		sumEndpoint = opentracing.TraceClient(otTracer, "Sum")(sumEndpoint)
 		sumEndpoint = zipkin.TraceEndpoint(zipkinTracer, "Sum")(sumEndpoint)
 		sumEndpoint = limiter(sumEndpoint)
-		sumEndpoint = circuitbreaker.Gobreaker(gobreaker.NewCircuitBreaker(gobreaker.Settings{
+		sumEndpoint = circuitbreaker.Gobreaker(breakers.New(gobreaker.Settings{
 			Name:    "Sum",
 			Timeout: 30 * time.Second,
 		}))(sumEndpoint)
@@ -166,7 +180,7 @@ func NewHTTPClient(instance string, otTracer stdopentracing.Tracer, zipkinTracer
 		concatEndpoint = opentracing.TraceClient(otTracer, "Concat")(concatEndpoint)
 		concatEndpoint = zipkin.TraceEndpoint(zipkinTracer, "Concat")(concatEndpoint)
 		concatEndpoint = limiter(concatEndpoint)
-		concatEndpoint = circuitbreaker.Gobreaker(gobreaker.NewCircuitBreaker(gobreaker.Settings{
+		concatEndpoint = circuitbreaker.Gobreaker(breakers.New(gobreaker.Settings{
 			Name:    "Concat",
 			Timeout: 30 * time.Second,
 		}))(concatEndpoint)
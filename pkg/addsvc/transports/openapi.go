@@ -0,0 +1,27 @@
+package transports
+
+import (
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/addsvc/endpoints"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/openapi"
+)
+
+// OpenAPI returns the description of the HTTP transport, served by
+// NewHTTPHandler on openapi.Path.
+func OpenAPI() *openapi.Document {
+	return openapi.New("addsvc", "v1",
+		openapi.Operation{
+			Path:     "/sum",
+			ID:       "Sum",
+			Summary:  "Sum returns a + b.",
+			Request:  endpoints.SumRequest{},
+			Response: endpoints.SumResponse{},
+		},
+		openapi.Operation{
+			Path:     "/concat",
+			ID:       "Concat",
+			Summary:  "Concat returns a followed by b.",
+			Request:  endpoints.ConcatRequest{},
+			Response: endpoints.ConcatResponse{},
+		},
+	)
+}
@@ -0,0 +1,157 @@
+// Package admin serves the diagnostics of a service on a port of its own:
+// pprof, expvar, the configuration the service runs with, the state of its
+// circuit breakers and connection pools, and the runtime log levels. It is
+// meant for operators and must not be exposed outside the cluster.
+package admin
+
+import (
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"net/http"
+	"net/http/pprof"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/breakers"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/loglevel"
+)
+
+// The paths served besides /debug/pprof/ and /debug/vars.
+const (
+	ConfigPath   = "/admin/config"
+	BreakersPath = "/admin/breakers"
+	PoolsPath    = "/admin/pools"
+)
+
+// Option sets an optional parameter of a Server.
+type Option func(*Server)
+
+// Config sets the configuration reported on ConfigPath. cfg is usually the
+// config struct of the main package; its fields are reported by name, even
+// the unexported ones.
+func Config(cfg interface{}) Option {
+	return func(s *Server) { s.config = cfg }
+}
+
+// Pool adds a pool reported on PoolsPath under name. stats is called on every
+// request and must return a JSON encodable value, such as the Stats of a
+// grpcpool or runtime/pool Pool.
+func Pool(name string, stats func() interface{}) Option {
+	return func(s *Server) { s.pools[name] = stats }
+}
+
+// Handle adds a handler served on path, such as the captured traffic.
+func Handle(path string, handler http.Handler) Option {
+	return func(s *Server) { s.handlers[path] = handler }
+}
+
+// Server is the admin HTTP handler of a service.
+type Server struct {
+	mux      *http.ServeMux
+	config   interface{}
+	handlers map[string]http.Handler
+
+	mtx   sync.Mutex
+	pools map[string]func() interface{}
+}
+
+// New returns the admin handler of a service whose log levels are levels.
+func New(levels *loglevel.Registry, options ...Option) *Server {
+	s := &Server{
+		mux:      http.NewServeMux(),
+		handlers: map[string]http.Handler{},
+		pools:    map[string]func() interface{}{},
+	}
+	for _, option := range options {
+		option(s)
+	}
+
+	s.mux.HandleFunc("/debug/pprof/", pprof.Index)
+	s.mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	s.mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	s.mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	s.mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	s.mux.Handle("/debug/vars", expvar.Handler())
+	s.mux.HandleFunc(ConfigPath, s.serveConfig)
+	s.mux.HandleFunc(BreakersPath, s.serveBreakers)
+	s.mux.HandleFunc(PoolsPath, s.servePools)
+	s.mux.Handle(loglevel.Path, loglevel.Handler(levels))
+	for path, handler := range s.handlers {
+		s.mux.Handle(path, handler)
+	}
+	return s
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) serveConfig(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, configMap(s.config))
+}
+
+func (s *Server) serveBreakers(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, breakers.States())
+}
+
+// PoolState is the state of a pool as reported on PoolsPath.
+type PoolState struct {
+	Name  string      `json:"name"`
+	Stats interface{} `json:"stats"`
+}
+
+func (s *Server) servePools(w http.ResponseWriter, r *http.Request) {
+	s.mtx.Lock()
+	names := make([]string, 0, len(s.pools))
+	for name := range s.pools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	res := make([]PoolState, len(names))
+	for i, name := range names {
+		res[i] = PoolState{Name: name, Stats: s.pools[name]()}
+	}
+	s.mtx.Unlock()
+	writeJSON(w, res)
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// configMap returns the fields of the struct cfg by name, formatted with
+// fmt, those of its embedded structs included. The methods of unexported
+// fields cannot be called, so durations are formatted by hand to stay
+// readable.
+func configMap(cfg interface{}) map[string]string {
+	res := map[string]string{}
+	addFields(res, reflect.Indirect(reflect.ValueOf(cfg)))
+	return res
+}
+
+func addFields(res map[string]string, v reflect.Value) {
+	if v.Kind() != reflect.Struct {
+		return
+	}
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Field(i)
+		if v.Type().Field(i).Anonymous && f.Kind() == reflect.Struct {
+			addFields(res, f)
+			continue
+		}
+		if f.Type() == durationType {
+			res[v.Type().Field(i).Name] = time.Duration(f.Int()).String()
+			continue
+		}
+		res[v.Type().Field(i).Name] = fmt.Sprint(f)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
@@ -0,0 +1,71 @@
+// Package apiversion serves the versions of the HTTP API of a service side by
+// side, each one under a prefix of its own such as /v1 or /v2, and tells the
+// clients of a deprecated version which version replaces it and when it goes
+// away, with the Deprecation (RFC 9745), Sunset (RFC 8594) and Link headers.
+package apiversion
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Version is a version of an HTTP API.
+type Version struct {
+	// Prefix is the path prefix of the version, such as "/v1".
+	Prefix string
+	// Handler serves the paths of the version, without the prefix.
+	Handler http.Handler
+	// Deprecation is set on a version its clients should move off.
+	Deprecation *Deprecation
+}
+
+// Deprecation describes the deprecation of a version.
+type Deprecation struct {
+	// Since is when the version was deprecated.
+	Since time.Time
+	// Sunset is when the version stops being served, unknown when zero.
+	Sunset time.Time
+	// Successor is the prefix of the version replacing this one. The Link
+	// header points to the same path under it.
+	Successor string
+}
+
+// Mux returns the handler of versions. The requests of the paths under no
+// prefix are served by unprefixed, typically the version predating the
+// prefixes, and answered NotFound when it is nil.
+func Mux(unprefixed http.Handler, versions ...Version) http.Handler {
+	m := http.NewServeMux()
+	for _, v := range versions {
+		h := v.Handler
+		if v.Deprecation != nil {
+			h = Deprecate(h, *v.Deprecation)
+		}
+		m.Handle(v.Prefix+"/", http.StripPrefix(v.Prefix, h))
+	}
+	if unprefixed != nil {
+		m.Handle("/", unprefixed)
+	}
+	return m
+}
+
+// Deprecate returns next answering with the headers of the deprecation d.
+func Deprecate(next http.Handler, d Deprecation) http.Handler {
+	deprecation := "@" + strconv.FormatInt(d.Since.Unix(), 10)
+	var sunset string
+	if !d.Sunset.IsZero() {
+		sunset = d.Sunset.UTC().Format(http.TimeFormat)
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h := w.Header()
+		h.Set("Deprecation", deprecation)
+		if sunset != "" {
+			h.Set("Sunset", sunset)
+		}
+		if d.Successor != "" {
+			h.Add("Link", "<"+d.Successor+"/"+strings.TrimPrefix(r.URL.Path, "/")+`>; rel="successor-version"`)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,84 @@
+package endpoints
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/ausf/service"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/kitx/chain"
+)
+
+// Endpoints collects all of the endpoints that compose the ausf service. It's
+// meant to be used as a helper struct, to collect all of the endpoints into a
+// single parameter.
+type Endpoints struct {
+	AuthenticateEndpoint endpoint.Endpoint
+	ConfirmAuthEndpoint  endpoint.Endpoint
+}
+
+// New return a new instance of the endpoint that wraps the provided service.
+// The endpoints run in the middleware stack of mw.
+func New(svc service.AusfService, mw chain.MiddlewareConfig) (ep Endpoints) {
+	mw.Logging = LoggingMiddleware
+	c := chain.New(mw)
+	ep.AuthenticateEndpoint = c.Build("authenticate", MakeAuthenticateEndpoint(svc))
+	ep.ConfirmAuthEndpoint = c.Build("confirmAuth", MakeConfirmAuthEndpoint(svc))
+	return ep
+}
+
+// MakeAuthenticateEndpoint returns an endpoint that invokes Authenticate on the service.
+// Primarily useful in a server.
+func MakeAuthenticateEndpoint(svc service.AusfService) (ep endpoint.Endpoint) {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(AuthenticateRequest)
+		if err := req.validate(); err != nil {
+			return AuthenticateResponse{}, err
+		}
+		var resync *service.Resync
+		if len(req.ResyncRand) != 0 || len(req.ResyncAuts) != 0 {
+			resync = &service.Resync{Rand: req.ResyncRand, Auts: req.ResyncAuts}
+		}
+		ac, err := svc.Authenticate(ctx, req.SupiOrSuci, req.ServingNetworkName, resync)
+		return AuthenticateResponse{AuthCtxID: ac.ID, Rand: ac.Rand, Autn: ac.Autn, HxresStar: ac.HxresStar}, err
+	}
+}
+
+// Authenticate implements the service interface, so Endpoints may be used as a service.
+// This is primarily useful in the context of a client library.
+func (e Endpoints) Authenticate(ctx context.Context, supiOrSuci string, servingNetworkName string, resync *service.Resync) (ac service.AuthContext, err error) {
+	req := AuthenticateRequest{SupiOrSuci: supiOrSuci, ServingNetworkName: servingNetworkName}
+	if resync != nil {
+		req.ResyncRand, req.ResyncAuts = resync.Rand, resync.Auts
+	}
+	resp, err := e.AuthenticateEndpoint(ctx, req)
+	if err != nil {
+		return
+	}
+	response := resp.(AuthenticateResponse)
+	return service.AuthContext{ID: response.AuthCtxID, Rand: response.Rand, Autn: response.Autn, HxresStar: response.HxresStar}, nil
+}
+
+// MakeConfirmAuthEndpoint returns an endpoint that invokes ConfirmAuth on the service.
+// Primarily useful in a server.
+func MakeConfirmAuthEndpoint(svc service.AusfService) (ep endpoint.Endpoint) {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(ConfirmAuthRequest)
+		if err := req.validate(); err != nil {
+			return ConfirmAuthResponse{}, err
+		}
+		cr, err := svc.ConfirmAuth(ctx, req.AuthCtxID, req.ResStar)
+		return ConfirmAuthResponse{SUPI: cr.SUPI, Kseaf: cr.Kseaf}, err
+	}
+}
+
+// ConfirmAuth implements the service interface, so Endpoints may be used as a service.
+// This is primarily useful in the context of a client library.
+func (e Endpoints) ConfirmAuth(ctx context.Context, authCtxID string, resStar []byte) (cr service.ConfirmResult, err error) {
+	resp, err := e.ConfirmAuthEndpoint(ctx, ConfirmAuthRequest{AuthCtxID: authCtxID, ResStar: resStar})
+	if err != nil {
+		return
+	}
+	response := resp.(ConfirmAuthResponse)
+	return service.ConfirmResult{SUPI: response.SUPI, Kseaf: response.Kseaf}, nil
+}
@@ -0,0 +1,43 @@
+package endpoints
+
+import (
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type Request interface {
+	validate() error
+}
+
+// AuthenticateRequest collects the request parameters for the Authenticate method.
+type AuthenticateRequest struct {
+	SupiOrSuci         string `json:"supi_or_suci"`
+	ServingNetworkName string `json:"serving_network_name"`
+	ResyncRand         []byte `json:"resync_rand,omitempty"`
+	ResyncAuts         []byte `json:"resync_auts,omitempty"`
+}
+
+func (r AuthenticateRequest) validate() error {
+	if r.SupiOrSuci == "" {
+		return status.Error(codes.InvalidArgument, "supi_or_suci is required")
+	}
+	return nil
+}
+
+// UEID implements logging.UERequest.
+func (r AuthenticateRequest) UEID() string {
+	return r.SupiOrSuci
+}
+
+// ConfirmAuthRequest collects the request parameters for the ConfirmAuth method.
+type ConfirmAuthRequest struct {
+	AuthCtxID string `json:"auth_ctx_id"`
+	ResStar   []byte `json:"res_star"`
+}
+
+func (r ConfirmAuthRequest) validate() error {
+	if r.AuthCtxID == "" {
+		return status.Error(codes.InvalidArgument, "auth_ctx_id is required")
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package endpoints
+
+import (
+	"net/http"
+
+	httptransport "github.com/go-kit/kit/transport/http"
+)
+
+var (
+	_ httptransport.Headerer = (*AuthenticateResponse)(nil)
+
+	_ httptransport.StatusCoder = (*AuthenticateResponse)(nil)
+
+	_ httptransport.Headerer = (*ConfirmAuthResponse)(nil)
+
+	_ httptransport.StatusCoder = (*ConfirmAuthResponse)(nil)
+)
+
+// AuthenticateResponse collects the response values for the Authenticate method.
+type AuthenticateResponse struct {
+	AuthCtxID string `json:"auth_ctx_id"`
+	Rand      []byte `json:"rand"`
+	Autn      []byte `json:"autn"`
+	HxresStar []byte `json:"hxres_star"`
+	Err       error  `json:"err"`
+}
+
+func (r AuthenticateResponse) StatusCode() int {
+	return http.StatusOK // TBA
+}
+
+func (r AuthenticateResponse) Headers() http.Header {
+	return http.Header{}
+}
+
+// ConfirmAuthResponse collects the response values for the ConfirmAuth method.
+type ConfirmAuthResponse struct {
+	SUPI  string `json:"supi"`
+	Kseaf []byte `json:"kseaf"`
+	Err   error  `json:"err"`
+}
+
+func (r ConfirmAuthResponse) StatusCode() int {
+	return http.StatusOK // TBA
+}
+
+func (r ConfirmAuthResponse) Headers() http.Header {
+	return http.Header{}
+}
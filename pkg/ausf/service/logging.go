@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/logging"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/plmn"
+)
+
+type loggingMiddleware struct {
+	logger log.Logger
+	next   AusfService
+}
+
+// LoggingMiddleware takes a logger as a dependency
+// and returns a ServiceMiddleware.
+func LoggingMiddleware(logger log.Logger) Middleware {
+	return func(next AusfService) AusfService {
+		return loggingMiddleware{level.Info(logger), next}
+	}
+}
+
+func (lm loggingMiddleware) Authenticate(ctx context.Context, supiOrSuci string, servingNetworkName string, resync *Resync) (ac AuthContext, err error) {
+	defer func(begin time.Time) {
+		log.With(lm.logger, append(logging.ContextKeyvals(ctx), plmn.Keyvals(ctx)...)...).Log("method", "Authenticate", "supi_or_suci", supiOrSuci, "snn", servingNetworkName, "resync", resync != nil, "authctx", ac.ID, "err", err)
+	}(time.Now())
+
+	return lm.next.Authenticate(ctx, supiOrSuci, servingNetworkName, resync)
+}
+
+func (lm loggingMiddleware) ConfirmAuth(ctx context.Context, authCtxID string, resStar []byte) (cr ConfirmResult, err error) {
+	defer func(begin time.Time) {
+		log.With(lm.logger, append(logging.ContextKeyvals(ctx), plmn.Keyvals(ctx)...)...).Log("method", "ConfirmAuth", "authctx", authCtxID, "supi", cr.SUPI, "err", err)
+	}(time.Now())
+
+	return lm.next.ConfirmAuth(ctx, authCtxID, resStar)
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/plmn"
+)
+
+type plmnMiddleware struct {
+	served plmn.List
+	next   AusfService
+}
+
+// PLMNMiddleware rejects the subscribers of the PLMNs that are not served,
+// and those of another PLMN than the one of the request. The PLMN of the
+// subscriber is passed on to the UDM with the request.
+func PLMNMiddleware(served plmn.List) Middleware {
+	return func(next AusfService) AusfService {
+		return plmnMiddleware{served, next}
+	}
+}
+
+func (pm plmnMiddleware) Authenticate(ctx context.Context, supiOrSuci string, servingNetworkName string, resync *Resync) (ac AuthContext, err error) {
+	supi, err := SUPIFromSUCI(supiOrSuci)
+	if err != nil {
+		return ac, err
+	}
+	ctx, err = pm.served.Admit(ctx, supi)
+	if err != nil {
+		return ac, err
+	}
+	return pm.next.Authenticate(ctx, supiOrSuci, servingNetworkName, resync)
+}
+
+// ConfirmAuth checks the SUPI once the context is confirmed, the request
+// does not carry it. The context is consumed anyway.
+func (pm plmnMiddleware) ConfirmAuth(ctx context.Context, authCtxID string, resStar []byte) (cr ConfirmResult, err error) {
+	cr, err = pm.next.ConfirmAuth(ctx, authCtxID, resStar)
+	if err != nil {
+		return cr, err
+	}
+	if _, err := pm.served.Admit(ctx, cr.SUPI); err != nil {
+		return ConfirmResult{}, err
+	}
+	return cr, nil
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/plmn"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/schema"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/security"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/store"
+	udmservice "github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/udm/service"
+)
+
+// authCtxPrefix is prepended to the authentication context id to build the
+// store key.
+const authCtxPrefix = "authctx/"
+
+// AuthContextSchema versions the authentication contexts in the store.
+var AuthContextSchema = &schema.Schema{Name: "authctx", Prefix: authCtxPrefix}
+
+var (
+	// ErrAuthCtxNotFound is returned when confirming an unknown or expired
+	// authentication context.
+	ErrAuthCtxNotFound = status.Error(codes.NotFound, "authentication context not found")
+	// ErrAuthFailed is returned when RES* does not match XRES*.
+	ErrAuthFailed = status.Error(codes.Unauthenticated, "authentication failed, RES* mismatch")
+	// ErrSUCI is returned for a SUCI that is malformed or concealed with a
+	// protection scheme other than the null scheme.
+	ErrSUCI = status.Error(codes.InvalidArgument, "unsupported SUCI, only the null scheme is supported")
+)
+
+// Middleware describes a service (as opposed to endpoint) middleware.
+type Middleware func(AusfService) AusfService
+
+//go:generate mockgen -destination=../../testutil/mocks/ausf_service.go -package=mocks github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/ausf/service AusfService
+
+// AusfService runs the home network side of 5G-AKA (TS 33.501 6.1.3.2).
+type AusfService interface {
+	// Authenticate fetches a vector from the UDM and returns the serving
+	// network part of it.
+	Authenticate(ctx context.Context, supiOrSuci string, servingNetworkName string, resync *Resync) (ac AuthContext, err error)
+	// ConfirmAuth checks the RES* returned by the UE and releases K_SEAF.
+	ConfirmAuth(ctx context.Context, authCtxID string, resStar []byte) (cr ConfirmResult, err error)
+}
+
+// Resync carries the RAND and AUTS sent by a UE whose SQN is out of range.
+type Resync = udmservice.Resync
+
+// AuthContext is the 5G serving environment authentication vector handed to
+// the AMF, identified by ID for the confirmation.
+type AuthContext struct {
+	ID        string
+	Rand      []byte
+	Autn      []byte
+	HxresStar []byte
+}
+
+// ConfirmResult is the outcome of a successful confirmation.
+type ConfirmResult struct {
+	SUPI  string
+	Kseaf []byte
+}
+
+// authState is what the AUSF keeps between Authenticate and ConfirmAuth.
+type authState struct {
+	SUPI               string `json:"supi"`
+	ServingNetworkName string `json:"snn"`
+	XresStar           []byte `json:"xres_star"`
+	Kausf              []byte `json:"kausf"`
+}
+
+// the concrete implementation of service interface
+type stubAusfService struct {
+	logger  log.Logger
+	udm     udmservice.UdmService
+	store   store.Store
+	authTTL time.Duration
+}
+
+// New return a new instance of the service.
+// If you want to add service middleware this is the place to put them.
+// Authentication contexts are kept in st for authTTL. Only the subscribers of
+// the served PLMNs are authenticated, all of them when served is empty.
+func New(udm udmservice.UdmService, st store.Store, authTTL time.Duration, served plmn.List, logger log.Logger) (s AusfService) {
+	var svc AusfService
+	{
+		svc = &stubAusfService{logger: logger, udm: udm, store: st, authTTL: authTTL}
+		svc = LoggingMiddleware(logger)(svc)
+		svc = PLMNMiddleware(served)(svc)
+	}
+	return svc
+}
+
+// Implement the business logic of Authenticate
+func (a *stubAusfService) Authenticate(ctx context.Context, supiOrSuci string, servingNetworkName string, resync *Resync) (ac AuthContext, err error) {
+	supi, err := SUPIFromSUCI(supiOrSuci)
+	if err != nil {
+		return ac, err
+	}
+	av, err := a.udm.GenerateAuthData(ctx, supi, servingNetworkName, resync)
+	if err != nil {
+		return ac, err
+	}
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return ac, err
+	}
+	data, err := json.Marshal(authState{SUPI: supi, ServingNetworkName: servingNetworkName, XresStar: av.XresStar, Kausf: av.Kausf})
+	if err != nil {
+		return ac, err
+	}
+	ac.ID = hex.EncodeToString(id)
+	if err := a.store.Set(ctx, authCtxPrefix+ac.ID, data, a.authTTL); err != nil {
+		return AuthContext{}, err
+	}
+	ac.Rand = av.Rand
+	ac.Autn = av.Autn
+	ac.HxresStar = security.HResStar(av.Rand, av.XresStar)
+	return ac, nil
+}
+
+// Implement the business logic of ConfirmAuth. A context can be confirmed
+// once, whatever the outcome, which the UDM is informed of.
+func (a *stubAusfService) ConfirmAuth(ctx context.Context, authCtxID string, resStar []byte) (cr ConfirmResult, err error) {
+	data, err := a.store.Get(ctx, authCtxPrefix+authCtxID)
+	if err == store.ErrNotFound {
+		return cr, ErrAuthCtxNotFound
+	}
+	if err != nil {
+		return cr, err
+	}
+	if err := a.store.Delete(ctx, authCtxPrefix+authCtxID); err != nil {
+		return cr, err
+	}
+	var st authState
+	if err := json.Unmarshal(data, &st); err != nil {
+		return cr, err
+	}
+	success := subtle.ConstantTimeCompare(resStar, st.XresStar) == 1
+	if err := a.udm.ConfirmAuth(ctx, st.SUPI, st.ServingNetworkName, success); err != nil {
+		// the result only goes to the history of the UE
+		a.logger.Log("supi", st.SUPI, "udm", "ConfirmAuth", "err", err)
+	}
+	if !success {
+		return cr, ErrAuthFailed
+	}
+	return ConfirmResult{SUPI: st.SUPI, Kseaf: security.Kseaf(st.Kausf, st.ServingNetworkName)}, nil
+}
+
+// SUPIFromSUCI returns the IMSI based SUPI of a SUCI concealed with the null
+// scheme (TS 23.003 2.2B), for example
+// "suci-0-208-93-0000-0-0-0000000001" gives "imsi-208930000000001". A SUPI
+// is returned unchanged.
+func SUPIFromSUCI(s string) (string, error) {
+	if !strings.HasPrefix(s, "suci-") {
+		return s, nil
+	}
+	// suci-<type>-<mcc>-<mnc>-<routing>-<scheme>-<key id>-<output>
+	f := strings.Split(s, "-")
+	if len(f) != 8 || f[1] != "0" || f[5] != "0" || f[7] == "" {
+		return "", ErrSUCI
+	}
+	return "imsi-" + f[2] + f[3] + f[7], nil
+}
@@ -0,0 +1,44 @@
+package transports
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/log"
+	stdopentracing "github.com/opentracing/opentracing-go"
+	"github.com/openzipkin/zipkin-go"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/ausf/endpoints"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/kitx/fuzz"
+)
+
+var (
+	fuzzOnce    sync.Once
+	fuzzHandler http.Handler
+)
+
+// newFuzzHandler returns the handler of the API, with every codec, over
+// endpoints answering the zero response of their method.
+func newFuzzHandler() http.Handler {
+	zipkinTracer, _ := zipkin.NewTracer(nil, zipkin.WithNoopTracer(true))
+	return NewHTTPHandler(endpoints.Endpoints{
+		AuthenticateEndpoint: answer(endpoints.AuthenticateResponse{}),
+		ConfirmAuthEndpoint:  answer(endpoints.ConfirmAuthResponse{}),
+	}, stdopentracing.NoopTracer{}, zipkinTracer, log.NewNopLogger())
+}
+
+// fuzzHTTP serves data, in the format of fuzz.HTTPSeed, to the HTTP API.
+// The bodies of the protobuf content type go through the request decoders
+// of the gRPC transport as well.
+func fuzzHTTP(data []byte) int {
+	fuzzOnce.Do(func() { fuzzHandler = newFuzzHandler() })
+	return fuzz.Serve(fuzzHandler, data)
+}
+
+func answer(response interface{}) endpoint.Endpoint {
+	return func(context.Context, interface{}) (interface{}, error) {
+		return response, nil
+	}
+}
@@ -0,0 +1,9 @@
+//go:build gofuzz
+// +build gofuzz
+
+package transports
+
+// FuzzHTTP is the go-fuzz function of fuzzHTTP.
+func FuzzHTTP(data []byte) int {
+	return fuzzHTTP(data)
+}
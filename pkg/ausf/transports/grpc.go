@@ -0,0 +1,237 @@
+package transports
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/kit/circuitbreaker"
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/ratelimit"
+	"github.com/go-kit/kit/tracing/opentracing"
+	"github.com/go-kit/kit/tracing/zipkin"
+	grpctransport "github.com/go-kit/kit/transport/grpc"
+	stdopentracing "github.com/opentracing/opentracing-go"
+	stdzipkin "github.com/openzipkin/zipkin-go"
+	"github.com/sony/gobreaker"
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/miki-tnt/sa5g-go-usvc-k8s/pb/ausf"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/ausf/endpoints"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/ausf/service"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/breakers"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/caller"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/grpcpool"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/logging"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/meta"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/plmn"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/priority"
+)
+
+type grpcServer struct {
+	authenticate grpctransport.Handler
+	confirmAuth  grpctransport.Handler
+}
+
+func (s *grpcServer) Authenticate(ctx context.Context, req *pb.AuthenticateRequest) (rep *pb.AuthenticateReply, err error) {
+	_, rp, err := s.authenticate.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcEncodeError(err)
+	}
+	rep = rp.(*pb.AuthenticateReply)
+	return rep, nil
+}
+
+func (s *grpcServer) ConfirmAuth(ctx context.Context, req *pb.ConfirmAuthRequest) (rep *pb.ConfirmAuthReply, err error) {
+	_, rp, err := s.confirmAuth.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcEncodeError(err)
+	}
+	rep = rp.(*pb.ConfirmAuthReply)
+	return rep, nil
+}
+
+// MakeGRPCServer makes a set of endpoints available as a gRPC server.
+func MakeGRPCServer(endpoints endpoints.Endpoints, otTracer stdopentracing.Tracer, zipkinTracer *stdzipkin.Tracer, logger log.Logger) (req pb.AusfServer) {
+	zipkinServer := zipkin.GRPCServerTrace(zipkinTracer)
+
+	options := []grpctransport.ServerOption{
+		grpctransport.ServerErrorHandler(logging.ErrorHandler(logger)),
+		zipkinServer,
+		grpctransport.ServerBefore(plmn.GRPCToContext()),
+		grpctransport.ServerBefore(priority.GRPCToContext()),
+		grpctransport.ServerBefore(caller.GRPCToContext()),
+		grpctransport.ServerBefore(meta.GRPCToContext()),
+	}
+
+	return &grpcServer{
+		authenticate: grpctransport.NewServer(
+			endpoints.AuthenticateEndpoint,
+			decodeGRPCAuthenticateRequest,
+			encodeGRPCAuthenticateResponse,
+			append(options, grpctransport.ServerBefore(opentracing.GRPCToContext(otTracer, "Authenticate", logger)))...,
+		),
+
+		confirmAuth: grpctransport.NewServer(
+			endpoints.ConfirmAuthEndpoint,
+			decodeGRPCConfirmAuthRequest,
+			encodeGRPCConfirmAuthResponse,
+			append(options, grpctransport.ServerBefore(opentracing.GRPCToContext(otTracer, "ConfirmAuth", logger)))...,
+		),
+	}
+}
+
+// decodeGRPCAuthenticateRequest is a transport/grpc.DecodeRequestFunc that converts a
+// gRPC request to a user-domain request. Primarily useful in a server.
+func decodeGRPCAuthenticateRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pb.AuthenticateRequest)
+	return endpoints.AuthenticateRequest{
+		SupiOrSuci:         req.SupiOrSuci,
+		ServingNetworkName: req.ServingNetworkName,
+		ResyncRand:         req.ResyncRand,
+		ResyncAuts:         req.ResyncAuts,
+	}, nil
+}
+
+// encodeGRPCAuthenticateResponse is a transport/grpc.EncodeResponseFunc that converts a
+// user-domain response to a gRPC reply. Primarily useful in a server.
+func encodeGRPCAuthenticateResponse(_ context.Context, grpcReply interface{}) (res interface{}, err error) {
+	reply := grpcReply.(endpoints.AuthenticateResponse)
+	return &pb.AuthenticateReply{AuthCtxId: reply.AuthCtxID, Rand: reply.Rand, Autn: reply.Autn, HxresStar: reply.HxresStar}, grpcEncodeError(reply.Err)
+}
+
+// decodeGRPCConfirmAuthRequest is a transport/grpc.DecodeRequestFunc that converts a
+// gRPC request to a user-domain request. Primarily useful in a server.
+func decodeGRPCConfirmAuthRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pb.ConfirmAuthRequest)
+	return endpoints.ConfirmAuthRequest{AuthCtxID: req.AuthCtxId, ResStar: req.ResStar}, nil
+}
+
+// encodeGRPCConfirmAuthResponse is a transport/grpc.EncodeResponseFunc that converts a
+// user-domain response to a gRPC reply. Primarily useful in a server.
+func encodeGRPCConfirmAuthResponse(_ context.Context, grpcReply interface{}) (res interface{}, err error) {
+	reply := grpcReply.(endpoints.ConfirmAuthResponse)
+	return &pb.ConfirmAuthReply{Supi: reply.SUPI, Kseaf: reply.Kseaf}, grpcEncodeError(reply.Err)
+}
+
+// NewGRPCClient returns an AusfService backed by a gRPC server at the other end
+// of the conn. The caller is responsible for constructing the conn, and
+// eventually closing the underlying transport. We bake-in certain middlewares,
+// implementing the client library pattern.
+func NewGRPCClient(conn *grpc.ClientConn, otTracer stdopentracing.Tracer, zipkinTracer *stdzipkin.Tracer, logger log.Logger) service.AusfService {
+	return NewGRPCPoolClient(grpcpool.FromConn(conn), otTracer, zipkinTracer, logger)
+}
+
+// NewGRPCPoolClient is like NewGRPCClient, but spreads the calls over the
+// connections of the pool. The caller is responsible for closing the pool.
+func NewGRPCPoolClient(pool *grpcpool.Pool, otTracer stdopentracing.Tracer, zipkinTracer *stdzipkin.Tracer, logger log.Logger) service.AusfService {
+	limiter := ratelimit.NewErroringLimiter(rate.NewLimiter(rate.Every(time.Second), 100))
+
+	zipkinClient := zipkin.GRPCClientTrace(zipkinTracer)
+
+	// global client middlewares
+	options := []grpctransport.ClientOption{
+		zipkinClient,
+		grpctransport.ClientBefore(plmn.ContextToGRPC()),
+		grpctransport.ClientBefore(priority.ContextToGRPC()),
+		grpctransport.ClientBefore(meta.ContextToGRPC()),
+	}
+
+	var authenticateEndpoint endpoint.Endpoint
+	{
+		authenticateEndpoint = pool.Endpoint(func(conn *grpc.ClientConn) endpoint.Endpoint {
+			return grpctransport.NewClient(
+				conn,
+				"pb.Ausf",
+				"Authenticate",
+				encodeGRPCAuthenticateRequest,
+				decodeGRPCAuthenticateResponse,
+				pb.AuthenticateReply{},
+				append(options, grpctransport.ClientBefore(opentracing.ContextToGRPC(otTracer, logger)))...,
+			).Endpoint()
+		})
+		authenticateEndpoint = opentracing.TraceClient(otTracer, "Authenticate")(authenticateEndpoint)
+		authenticateEndpoint = limiter(authenticateEndpoint)
+		authenticateEndpoint = circuitbreaker.Gobreaker(breakers.New(gobreaker.Settings{
+			Name:    "Authenticate",
+			Timeout: 30 * time.Second,
+		}))(authenticateEndpoint)
+	}
+
+	var confirmAuthEndpoint endpoint.Endpoint
+	{
+		confirmAuthEndpoint = pool.Endpoint(func(conn *grpc.ClientConn) endpoint.Endpoint {
+			return grpctransport.NewClient(
+				conn,
+				"pb.Ausf",
+				"ConfirmAuth",
+				encodeGRPCConfirmAuthRequest,
+				decodeGRPCConfirmAuthResponse,
+				pb.ConfirmAuthReply{},
+				append(options, grpctransport.ClientBefore(opentracing.ContextToGRPC(otTracer, logger)))...,
+			).Endpoint()
+		})
+		confirmAuthEndpoint = opentracing.TraceClient(otTracer, "ConfirmAuth")(confirmAuthEndpoint)
+		confirmAuthEndpoint = limiter(confirmAuthEndpoint)
+		confirmAuthEndpoint = circuitbreaker.Gobreaker(breakers.New(gobreaker.Settings{
+			Name:    "ConfirmAuth",
+			Timeout: 30 * time.Second,
+		}))(confirmAuthEndpoint)
+	}
+
+	return endpoints.Endpoints{
+		AuthenticateEndpoint: authenticateEndpoint,
+		ConfirmAuthEndpoint:  confirmAuthEndpoint,
+	}
+}
+
+// encodeGRPCAuthenticateRequest is a transport/grpc.EncodeRequestFunc that converts a
+// user-domain Authenticate request to a gRPC Authenticate request. Primarily useful in a client.
+func encodeGRPCAuthenticateRequest(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(endpoints.AuthenticateRequest)
+	return &pb.AuthenticateRequest{
+		SupiOrSuci:         req.SupiOrSuci,
+		ServingNetworkName: req.ServingNetworkName,
+		ResyncRand:         req.ResyncRand,
+		ResyncAuts:         req.ResyncAuts,
+	}, nil
+}
+
+// decodeGRPCAuthenticateResponse is a transport/grpc.DecodeResponseFunc that converts a
+// gRPC Authenticate reply to a user-domain Authenticate response. Primarily useful in a client.
+func decodeGRPCAuthenticateResponse(_ context.Context, grpcReply interface{}) (interface{}, error) {
+	reply := grpcReply.(*pb.AuthenticateReply)
+	return endpoints.AuthenticateResponse{AuthCtxID: reply.AuthCtxId, Rand: reply.Rand, Autn: reply.Autn, HxresStar: reply.HxresStar}, nil
+}
+
+// encodeGRPCConfirmAuthRequest is a transport/grpc.EncodeRequestFunc that converts a
+// user-domain ConfirmAuth request to a gRPC ConfirmAuth request. Primarily useful in a client.
+func encodeGRPCConfirmAuthRequest(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(endpoints.ConfirmAuthRequest)
+	return &pb.ConfirmAuthRequest{AuthCtxId: req.AuthCtxID, ResStar: req.ResStar}, nil
+}
+
+// decodeGRPCConfirmAuthResponse is a transport/grpc.DecodeResponseFunc that converts a
+// gRPC ConfirmAuth reply to a user-domain ConfirmAuth response. Primarily useful in a client.
+func decodeGRPCConfirmAuthResponse(_ context.Context, grpcReply interface{}) (interface{}, error) {
+	reply := grpcReply.(*pb.ConfirmAuthReply)
+	return endpoints.ConfirmAuthResponse{SUPI: reply.Supi, Kseaf: reply.Kseaf}, nil
+}
+
+func grpcEncodeError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	st, ok := status.FromError(err)
+	if ok {
+		return status.Error(st.Code(), st.Message())
+	}
+	switch err {
+	default:
+		return status.Error(codes.Internal, "internal server error")
+	}
+}
@@ -0,0 +1,249 @@
+package transports
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-kit/kit/circuitbreaker"
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/ratelimit"
+	"github.com/go-kit/kit/sd/lb"
+	"github.com/go-kit/kit/tracing/opentracing"
+	"github.com/go-kit/kit/tracing/zipkin"
+	httptransport "github.com/go-kit/kit/transport/http"
+	"github.com/golang/protobuf/proto"
+	stdopentracing "github.com/opentracing/opentracing-go"
+	stdzipkin "github.com/openzipkin/zipkin-go"
+	"github.com/sony/gobreaker"
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/miki-tnt/sa5g-go-usvc-k8s/pb/ausf"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/ausf/endpoints"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/ausf/service"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/breakers"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/caller"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/codec"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/logging"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/meta"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/openapi"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/plmn"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/priority"
+)
+
+type errorWrapper struct {
+	Error string `json:"error"`
+}
+
+func JSONErrorDecoder(r *http.Response) error {
+	contentType := r.Header.Get("Content-Type")
+	if !strings.Contains(contentType, "application/json") {
+		return fmt.Errorf("expected JSON formatted error, got Content-Type %s", contentType)
+	}
+	var w errorWrapper
+	if err := json.NewDecoder(r.Body).Decode(&w); err != nil {
+		return err
+	}
+	return errors.New(w.Error)
+}
+
+// NewHTTPHandler returns a handler that makes a set of endpoints available on
+// predefined paths.
+func NewHTTPHandler(endpoints endpoints.Endpoints, otTracer stdopentracing.Tracer, zipkinTracer *stdzipkin.Tracer, logger log.Logger) http.Handler {
+	zipkinServer := zipkin.HTTPServerTrace(zipkinTracer)
+
+	options := []httptransport.ServerOption{
+		httptransport.ServerErrorEncoder(httpEncodeError),
+		httptransport.ServerErrorHandler(logging.ErrorHandler(logger)),
+		zipkinServer,
+		httptransport.ServerBefore(plmn.HTTPToContext()),
+		httptransport.ServerBefore(priority.HTTPToContext()),
+		httptransport.ServerBefore(caller.HTTPToContext()),
+		httptransport.ServerBefore(meta.HTTPToContext()),
+		httptransport.ServerBefore(codec.Default.HTTPToContext()),
+	}
+
+	m := http.NewServeMux()
+	m.Handle("/authenticate", httptransport.NewServer(
+		endpoints.AuthenticateEndpoint,
+		codec.DecodeRequest(authenticateBinding),
+		codec.EncodeResponse(authenticateBinding),
+		append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "Authenticate", logger)))...,
+	))
+	m.Handle("/confirmAuth", httptransport.NewServer(
+		endpoints.ConfirmAuthEndpoint,
+		codec.DecodeRequest(confirmAuthBinding),
+		codec.EncodeResponse(confirmAuthBinding),
+		append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "ConfirmAuth", logger)))...,
+	))
+	m.Handle(openapi.Path, openapi.Handler(OpenAPI()))
+	return m
+}
+
+var authenticateBinding = codec.Binding{
+	Request:   func() interface{} { return &endpoints.AuthenticateRequest{} },
+	PBRequest: func() proto.Message { return &pb.AuthenticateRequest{} },
+	FromPB:    decodeGRPCAuthenticateRequest,
+	ToPB:      encodeGRPCAuthenticateResponse,
+}
+
+var confirmAuthBinding = codec.Binding{
+	Request:   func() interface{} { return &endpoints.ConfirmAuthRequest{} },
+	PBRequest: func() proto.Message { return &pb.ConfirmAuthRequest{} },
+	FromPB:    decodeGRPCConfirmAuthRequest,
+	ToPB:      encodeGRPCConfirmAuthResponse,
+}
+
+// NewHTTPClient returns an AusfService backed by an HTTP server living at the
+// remote instance. We expect instance to come from a service discovery system,
+// so likely of the form "host:port". We bake-in certain middlewares,
+// implementing the client library pattern.
+func NewHTTPClient(instance string, otTracer stdopentracing.Tracer, zipkinTracer *stdzipkin.Tracer, logger log.Logger) (service.AusfService, error) {
+	if !strings.HasPrefix(instance, "http") {
+		instance = "http://" + instance
+	}
+	u, err := url.Parse(instance)
+	if err != nil {
+		return nil, err
+	}
+
+	limiter := ratelimit.NewErroringLimiter(rate.NewLimiter(rate.Every(time.Second), 100))
+
+	zipkinClient := zipkin.HTTPClientTrace(zipkinTracer)
+
+	// global client middlewares
+	options := []httptransport.ClientOption{
+		zipkinClient,
+		httptransport.ClientBefore(plmn.ContextToHTTP()),
+		httptransport.ClientBefore(priority.ContextToHTTP()),
+		httptransport.ClientBefore(meta.ContextToHTTP()),
+	}
+
+	e := endpoints.Endpoints{}
+
+	var authenticateEndpoint endpoint.Endpoint
+	{
+		authenticateEndpoint = httptransport.NewClient(
+			"POST",
+			copyURL(u, "/authenticate"),
+			encodeHTTPRequest,
+			decodeHTTPAuthenticateResponse,
+			append(options, httptransport.ClientBefore(opentracing.ContextToHTTP(otTracer, logger)))...,
+		).Endpoint()
+		authenticateEndpoint = opentracing.TraceClient(otTracer, "Authenticate")(authenticateEndpoint)
+		authenticateEndpoint = zipkin.TraceEndpoint(zipkinTracer, "Authenticate")(authenticateEndpoint)
+		authenticateEndpoint = limiter(authenticateEndpoint)
+		authenticateEndpoint = circuitbreaker.Gobreaker(breakers.New(gobreaker.Settings{
+			Name:    "Authenticate",
+			Timeout: 30 * time.Second,
+		}))(authenticateEndpoint)
+		e.AuthenticateEndpoint = authenticateEndpoint
+	}
+
+	var confirmAuthEndpoint endpoint.Endpoint
+	{
+		confirmAuthEndpoint = httptransport.NewClient(
+			"POST",
+			copyURL(u, "/confirmAuth"),
+			encodeHTTPRequest,
+			decodeHTTPConfirmAuthResponse,
+			append(options, httptransport.ClientBefore(opentracing.ContextToHTTP(otTracer, logger)))...,
+		).Endpoint()
+		confirmAuthEndpoint = opentracing.TraceClient(otTracer, "ConfirmAuth")(confirmAuthEndpoint)
+		confirmAuthEndpoint = zipkin.TraceEndpoint(zipkinTracer, "ConfirmAuth")(confirmAuthEndpoint)
+		confirmAuthEndpoint = limiter(confirmAuthEndpoint)
+		confirmAuthEndpoint = circuitbreaker.Gobreaker(breakers.New(gobreaker.Settings{
+			Name:    "ConfirmAuth",
+			Timeout: 30 * time.Second,
+		}))(confirmAuthEndpoint)
+		e.ConfirmAuthEndpoint = confirmAuthEndpoint
+	}
+
+	return e, nil
+}
+
+func copyURL(base *url.URL, path string) *url.URL {
+	next := *base
+	next.Path = path
+	return &next
+}
+
+// encodeHTTPRequest is a transport/http.EncodeRequestFunc that
+// JSON-encodes any request to the request body. Primarily useful in a client.
+func encodeHTTPRequest(_ context.Context, r *http.Request, request interface{}) (err error) {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(request); err != nil {
+		return err
+	}
+	r.Body = ioutil.NopCloser(&buf)
+	return nil
+}
+
+// decodeHTTPAuthenticateResponse is a transport/http.DecodeResponseFunc that decodes a
+// JSON-encoded authenticate response from the HTTP response body. If the response has a
+// non-200 status code, we will interpret that as an error and attempt to decode
+// the specific error message from the response body. Primarily useful in a client.
+func decodeHTTPAuthenticateResponse(_ context.Context, r *http.Response) (interface{}, error) {
+	if r.StatusCode != http.StatusOK {
+		return nil, JSONErrorDecoder(r)
+	}
+	var resp endpoints.AuthenticateResponse
+	err := json.NewDecoder(r.Body).Decode(&resp)
+	return resp, err
+}
+
+// decodeHTTPConfirmAuthResponse is a transport/http.DecodeResponseFunc that decodes a
+// JSON-encoded confirmAuth response from the HTTP response body. If the response has a
+// non-200 status code, we will interpret that as an error and attempt to decode
+// the specific error message from the response body. Primarily useful in a client.
+func decodeHTTPConfirmAuthResponse(_ context.Context, r *http.Response) (interface{}, error) {
+	if r.StatusCode != http.StatusOK {
+		return nil, JSONErrorDecoder(r)
+	}
+	var resp endpoints.ConfirmAuthResponse
+	err := json.NewDecoder(r.Body).Decode(&resp)
+	return resp, err
+}
+
+func httpEncodeError(_ context.Context, err error, w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if lberr, ok := err.(lb.RetryError); ok {
+		st, _ := status.FromError(lberr.Final)
+		w.WriteHeader(HTTPStatusFromCode(st.Code()))
+		json.NewEncoder(w).Encode(errorWrapper{Error: st.Message()})
+	} else {
+		st, ok := status.FromError(err)
+		if ok {
+			w.WriteHeader(HTTPStatusFromCode(st.Code()))
+			json.NewEncoder(w).Encode(errorWrapper{Error: st.Message()})
+		} else {
+			switch err {
+			case io.ErrUnexpectedEOF:
+				w.WriteHeader(http.StatusBadRequest)
+			case io.EOF:
+				w.WriteHeader(http.StatusBadRequest)
+			default:
+				switch err.(type) {
+				case *json.SyntaxError:
+					w.WriteHeader(http.StatusBadRequest)
+				case *json.UnmarshalTypeError:
+					w.WriteHeader(http.StatusBadRequest)
+				default:
+					w.WriteHeader(http.StatusInternalServerError)
+				}
+			}
+			json.NewEncoder(w).Encode(errorWrapper{Error: err.Error()})
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package transports
+
+import (
+	"github.com/go-kit/kit/log"
+
+	pb "github.com/miki-tnt/sa5g-go-usvc-k8s/pb/ausf"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/ausf/endpoints"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/ausf/service"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/caller"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/kitx/inproc"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/logging"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/meta"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/plmn"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/priority"
+)
+
+// MakeInprocServer makes a set of endpoints available to the in-process
+// clients of NewInprocClient, with the codecs and metadata of the gRPC
+// transport. The caller runs its Serve method and eventually closes it.
+func MakeInprocServer(endpoints endpoints.Endpoints, logger log.Logger) *inproc.Server {
+	options := []inproc.ServerOption{
+		inproc.ServerErrorHandler(logging.ErrorHandler(logger)),
+		inproc.ServerErrorEncoder(grpcEncodeError),
+		inproc.ServerBefore(plmn.GRPCToContext()),
+		inproc.ServerBefore(priority.GRPCToContext()),
+		inproc.ServerBefore(caller.GRPCToContext()),
+		inproc.ServerBefore(meta.GRPCToContext()),
+	}
+
+	s := inproc.NewServer()
+	s.Handle("Authenticate", endpoints.AuthenticateEndpoint, decodeGRPCAuthenticateRequest, encodeGRPCAuthenticateResponse, pb.AuthenticateRequest{}, options...)
+	s.Handle("ConfirmAuth", endpoints.ConfirmAuthEndpoint, decodeGRPCConfirmAuthRequest, encodeGRPCConfirmAuthResponse, pb.ConfirmAuthRequest{}, options...)
+	return s
+}
+
+// NewInprocClient returns an AusfService calling the server of
+// MakeInprocServer. Unlike the gRPC client, it bakes in no rate limit or
+// circuit breaker, so that the tests using it are deterministic.
+func NewInprocClient(s *inproc.Server) service.AusfService {
+	options := []inproc.ClientOption{
+		inproc.ClientBefore(plmn.ContextToGRPC()),
+		inproc.ClientBefore(priority.ContextToGRPC()),
+		inproc.ClientBefore(meta.ContextToGRPC()),
+	}
+
+	return endpoints.Endpoints{
+		AuthenticateEndpoint: inproc.NewClient(s, "Authenticate", encodeGRPCAuthenticateRequest, decodeGRPCAuthenticateResponse, pb.AuthenticateReply{}, options...).Endpoint(),
+		ConfirmAuthEndpoint:  inproc.NewClient(s, "ConfirmAuth", encodeGRPCConfirmAuthRequest, decodeGRPCConfirmAuthResponse, pb.ConfirmAuthReply{}, options...).Endpoint(),
+	}
+}
@@ -0,0 +1,27 @@
+package transports
+
+import (
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/ausf/endpoints"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/openapi"
+)
+
+// OpenAPI returns the description of the HTTP transport, served by
+// NewHTTPHandler on openapi.Path.
+func OpenAPI() *openapi.Document {
+	return openapi.New("ausf", "v1",
+		openapi.Operation{
+			Path:     "/authenticate",
+			ID:       "Authenticate",
+			Summary:  "Authenticate starts 5G-AKA and returns the serving network authentication vector.",
+			Request:  endpoints.AuthenticateRequest{},
+			Response: endpoints.AuthenticateResponse{},
+		},
+		openapi.Operation{
+			Path:     "/confirmAuth",
+			ID:       "ConfirmAuth",
+			Summary:  "ConfirmAuth checks RES* and returns K_SEAF.",
+			Request:  endpoints.ConfirmAuthRequest{},
+			Response: endpoints.ConfirmAuthResponse{},
+		},
+	)
+}
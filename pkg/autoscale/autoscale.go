@@ -0,0 +1,418 @@
+// Package autoscale exports the signalling load of an NF, for a
+// HorizontalPodAutoscaler to scale it on rather than on its CPU. A Load
+// counts the procedures the NF is asked to run, whether it admits them or
+// not, and samples the gauges the NF declares: the UE contexts it holds,
+// the requests queued by its admission. Handler serves them on the admin
+// port in the Prometheus text format, for the Prometheus adapter to expose
+// them as custom metrics:
+//
+//	sa5g_procedures_total{nf="ausf",procedure="authenticate"} 1234
+//	sa5g_procedure_rate{nf="ausf",procedure="authenticate"} 41.5
+//	sa5g_queue_depth{nf="ausf",queue="admission"} 3
+//	sa5g_active_ues{nf="gnbcu"} 120
+//
+// The rate is that of the last Window, which an HPA can take as is; the
+// counter is there for the rate Prometheus computes.
+//
+// Along with the load go the signals the NF is alerted on (see
+// cmd/alertgen): the procedures that failed and the seconds they took, the
+// state of its circuit breakers and of its links with other nodes, and the
+// error budgets of its methods (see package slo), which the NF declares as
+// gauges too:
+//
+//	sa5g_procedure_failures_total{nf="ausf",procedure="authenticate"} 12
+//	sa5g_procedure_duration_seconds_bucket{nf="ausf",procedure="authenticate",le="0.1"} 1200
+//	sa5g_breaker_open{nf="udm",breaker="generateAuthData"} 0
+//	sa5g_link_up{nf="gnbdu",link="f1"} 1
+package autoscale
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/endpoint"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/exemplar"
+)
+
+// Path is the path Handler is served on.
+const Path = "/metrics/load"
+
+// ContentType is the content type of the responses of Handler.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// The names of the metrics.
+const (
+	Procedures    = "sa5g_procedures_total"
+	ProcedureRate = "sa5g_procedure_rate"
+	ActiveUEs     = "sa5g_active_ues"
+	QueueDepth    = "sa5g_queue_depth"
+	Info          = "sa5g_nf_info"
+	Failures      = "sa5g_procedure_failures_total"
+	Duration      = "sa5g_procedure_duration_seconds"
+	BreakerOpen   = "sa5g_breaker_open"
+	LinkUp        = "sa5g_link_up"
+	BurnRate      = "sa5g_slo_burn_rate"
+	ErrorBudget   = "sa5g_slo_error_budget_remaining"
+)
+
+// DefaultWindow is the window of the procedure rates by default.
+const DefaultWindow = 30 * time.Second
+
+// help describes the metrics.
+var help = map[string]string{
+	Procedures:    "Procedures the NF was asked to run, admitted or not.",
+	ProcedureRate: "Procedures per second the NF was asked to run, over the last window.",
+	ActiveUEs:     "UE contexts the NF holds.",
+	QueueDepth:    "Requests waiting in a queue of the NF.",
+	Info:          "The identity of the NF instance, in its labels.",
+	Failures:      "Procedures the NF failed, those it did not admit included.",
+	Duration:      "Seconds the procedures of the NF took, admitted or not.",
+	BreakerOpen:   "Whether a circuit breaker of the NF is open.",
+	LinkUp:        "Whether a link of the NF with another node is up.",
+	BurnRate:      "The rate the error budget of a method is spent at over the window, 1 to spend it over the window.",
+	ErrorBudget:   "The ratio of the error budget of a method left over the longest window.",
+}
+
+// buckets are the upper bounds, in seconds, of the buckets of the
+// durations, those of the latencies of the requests.
+var buckets = exemplar.DefaultBuckets
+
+// Load is the signalling load of an NF.
+type Load struct {
+	nf  string
+	reg *registry
+}
+
+// registry keeps the series of the Loads of a process.
+type registry struct {
+	window time.Duration
+	now    func() time.Time
+	start  time.Time
+
+	mtx    sync.Mutex
+	counts map[key]*counter
+	gauges map[string][]gauge
+}
+
+// key identifies the counter of a procedure of an NF.
+type key struct {
+	nf, procedure string
+}
+
+// gauge is a gauge sampled when served: value, or values, by the value of
+// label.
+type gauge struct {
+	labels []string
+	value  func() float64
+	label  string
+	values func() map[string]float64
+}
+
+// Option sets an optional parameter of a Load.
+type Option func(*registry)
+
+// Window sets the window of the procedure rates, DefaultWindow by default.
+// It is rounded down to the second.
+func Window(d time.Duration) Option {
+	return func(r *registry) {
+		r.window = d
+	}
+}
+
+// New returns the load of the NF nf.
+func New(nf string, options ...Option) *Load {
+	r := &registry{
+		window: DefaultWindow,
+		now:    time.Now,
+		counts: map[key]*counter{},
+		gauges: map[string][]gauge{},
+	}
+	for _, option := range options {
+		option(r)
+	}
+	if r.window < time.Second {
+		r.window = time.Second
+	}
+	r.start = r.now()
+	return &Load{nf: nf, reg: r}
+}
+
+// NF returns the load of the NF nf, served along with that of l, for a
+// process running several NFs.
+func (l *Load) NF(nf string) *Load {
+	return &Load{nf: nf, reg: l.reg}
+}
+
+// Count counts n procedures of the NF.
+func (l *Load) Count(procedure string, n int) {
+	r := l.reg
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.counter(l.nf, procedure).add(r.now().Unix(), int64(n))
+}
+
+// done counts a procedure of the NF that ended, having failed or not, after
+// seconds.
+func (l *Load) done(procedure string, seconds float64, failed bool) {
+	r := l.reg
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.counter(l.nf, procedure).done(seconds, failed)
+}
+
+// counter returns the counter of the procedure of nf, created when there
+// is none yet.
+func (r *registry) counter(nf, procedure string) *counter {
+	k := key{nf, procedure}
+	c, ok := r.counts[k]
+	if !ok {
+		c = newCounter(int(r.window / time.Second))
+		r.counts[k] = c
+	}
+	return c
+}
+
+// Gauge declares the gauge name of the NF, sampled by value, labelled with
+// labelValues, pairs of label and value.
+func (l *Load) Gauge(name string, value func() float64, labelValues ...string) {
+	r := l.reg
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.gauges[name] = append(r.gauges[name], gauge{labels: append([]string{"nf", l.nf}, labelValues...), value: value})
+}
+
+// Gauges declares the gauges name of the NF sampled by values, one by key
+// of the map it returns, labelled with label set to the key and with
+// labelValues.
+func (l *Load) Gauges(name, label string, values func() map[string]float64, labelValues ...string) {
+	r := l.reg
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.gauges[name] = append(r.gauges[name], gauge{labels: append([]string{"nf", l.nf}, labelValues...), label: label, values: values})
+}
+
+// Middleware counts the calls of the endpoint of procedure in l, the
+// failures and the seconds they took. The procedure is served from the
+// start, counted 0 times.
+func Middleware(l *Load, procedure string) endpoint.Middleware {
+	l.Count(procedure, 0)
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			l.Count(procedure, 1)
+			begin := time.Now()
+			response, err := next(ctx, request)
+			l.done(procedure, time.Since(begin).Seconds(), err != nil)
+			return response, err
+		}
+	}
+}
+
+// Handler returns the handler serving the metrics of l, and of the Loads
+// of its other NFs.
+func Handler(l *Load) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", ContentType)
+		bw := bufio.NewWriter(w)
+		l.reg.write(bw)
+		bw.Flush()
+	})
+}
+
+// write writes the metrics of r.
+func (r *registry) write(w *bufio.Writer) {
+	r.mtx.Lock()
+	keys := make([]key, 0, len(r.counts))
+	for k := range r.counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].nf != keys[j].nf {
+			return keys[i].nf < keys[j].nf
+		}
+		return keys[i].procedure < keys[j].procedure
+	})
+	now := r.now()
+	// the rates are over the time elapsed until the window is
+	seconds := int64(now.Sub(r.start)/time.Second) + 1
+	if window := int64(r.window / time.Second); seconds > window {
+		seconds = window
+	}
+	totals, rates := make([]int64, len(keys)), make([]float64, len(keys))
+	failures, durations := make([]int64, len(keys)), make([]histogram, len(keys))
+	for i, k := range keys {
+		c := r.counts[k]
+		totals[i] = c.total
+		rates[i] = float64(c.sum(now.Unix())) / float64(seconds)
+		failures[i] = c.failures
+		durations[i] = c.duration.copy()
+	}
+	gauges := make(map[string][]gauge, len(r.gauges))
+	for name, gs := range r.gauges {
+		gauges[name] = append([]gauge(nil), gs...)
+	}
+	r.mtx.Unlock()
+
+	if len(keys) != 0 {
+		header(w, Procedures, "counter")
+		for i, k := range keys {
+			fmt.Fprintf(w, "%s%s %d\n", Procedures, labels([]string{"nf", k.nf, "procedure", k.procedure}), totals[i])
+		}
+		header(w, ProcedureRate, "gauge")
+		for i, k := range keys {
+			fmt.Fprintf(w, "%s%s %s\n", ProcedureRate, labels([]string{"nf", k.nf, "procedure", k.procedure}), formatFloat(rates[i]))
+		}
+		header(w, Failures, "counter")
+		for i, k := range keys {
+			fmt.Fprintf(w, "%s%s %d\n", Failures, labels([]string{"nf", k.nf, "procedure", k.procedure}), failures[i])
+		}
+		header(w, Duration, "histogram")
+		for i, k := range keys {
+			durations[i].write(w, Duration, []string{"nf", k.nf, "procedure", k.procedure})
+		}
+	}
+	names := make([]string, 0, len(gauges))
+	for name := range gauges {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		header(w, name, "gauge")
+		// sampled out of the lock, the gauges taking their own
+		for _, g := range gauges[name] {
+			if g.values == nil {
+				fmt.Fprintf(w, "%s%s %s\n", name, labels(g.labels), formatFloat(g.value()))
+				continue
+			}
+			values := g.values()
+			keys := make([]string, 0, len(values))
+			for k := range values {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				fmt.Fprintf(w, "%s%s %s\n", name, labels(append(g.labels[:len(g.labels):len(g.labels)], g.label, k)), formatFloat(values[k]))
+			}
+		}
+	}
+}
+
+func header(w *bufio.Writer, name, typ string) {
+	if h, ok := help[name]; ok {
+		fmt.Fprintf(w, "# HELP %s %s\n", name, h)
+	}
+	fmt.Fprintf(w, "# TYPE %s %s\n", name, typ)
+}
+
+// counter counts the procedures in total and by second over a window, and
+// those that failed and the seconds they took in total.
+type counter struct {
+	total int64
+	// buckets are the counts of the last seconds, by second modulo their
+	// number; last is the last second counted.
+	buckets []int64
+	last    int64
+
+	failures int64
+	duration histogram
+}
+
+func newCounter(seconds int) *counter {
+	return &counter{buckets: make([]int64, seconds), duration: histogram{counts: make([]int64, len(buckets))}}
+}
+
+func (c *counter) done(seconds float64, failed bool) {
+	if failed {
+		c.failures++
+	}
+	c.duration.observe(seconds)
+}
+
+func (c *counter) add(now, n int64) {
+	c.advance(now)
+	c.buckets[now%int64(len(c.buckets))] += n
+	c.total += n
+}
+
+// sum returns the count of the window ending at now.
+func (c *counter) sum(now int64) int64 {
+	c.advance(now)
+	var sum int64
+	for _, n := range c.buckets {
+		sum += n
+	}
+	return sum
+}
+
+// advance clears the buckets of the seconds from the last one to now.
+func (c *counter) advance(now int64) {
+	size := int64(len(c.buckets))
+	for s := c.last + 1; s <= now && s <= c.last+size; s++ {
+		c.buckets[s%size] = 0
+	}
+	if now > c.last {
+		c.last = now
+	}
+}
+
+// histogram counts the seconds observed by bucket, each count being that
+// of its bucket alone.
+type histogram struct {
+	counts []int64
+	count  int64
+	sum    float64
+}
+
+func (h *histogram) observe(seconds float64) {
+	// past the last bucket, in +Inf alone
+	if i := sort.SearchFloat64s(buckets, seconds); i < len(buckets) {
+		h.counts[i]++
+	}
+	h.count++
+	h.sum += seconds
+}
+
+func (h histogram) copy() histogram {
+	h.counts = append([]int64(nil), h.counts...)
+	return h
+}
+
+// write writes the series of h, the histogram name labelled with
+// labelValues.
+func (h histogram) write(w *bufio.Writer, name string, labelValues []string) {
+	var cumulative int64
+	for i, le := range buckets {
+		cumulative += h.counts[i]
+		fmt.Fprintf(w, "%s_bucket%s %d\n", name, labels(append(labelValues[:len(labelValues):len(labelValues)], "le", formatFloat(le))), cumulative)
+	}
+	fmt.Fprintf(w, "%s_bucket%s %d\n", name, labels(append(labelValues[:len(labelValues):len(labelValues)], "le", "+Inf")), h.count)
+	fmt.Fprintf(w, "%s_sum%s %s\n", name, labels(labelValues), formatFloat(h.sum))
+	fmt.Fprintf(w, "%s_count%s %d\n", name, labels(labelValues), h.count)
+}
+
+// labels formats the pairs of label and value of labelValues.
+func labels(labelValues []string) string {
+	if len(labelValues) == 0 {
+		return ""
+	}
+	pairs := make([]string, 0, len(labelValues)/2)
+	for i := 0; i+1 < len(labelValues); i += 2 {
+		pairs = append(pairs, labelValues[i]+`="`+escape(labelValues[i+1])+`"`)
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func escape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,319 @@
+// Package backup snapshots the state of a service to an object storage and
+// restores it, for the disaster recovery of the stateful services. A snapshot
+// gathers the state of its sources, each one typically a set of key prefixes
+// of the service's store, as a gzipped JSON object, encrypted with AES-256-GCM
+// when a key is given. The snapshots are named by their creation time, so that
+// the latest one sorts last.
+package backup
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/store"
+)
+
+// Latest names the most recent snapshot to Restore.
+const Latest = "latest"
+
+// version is the version of the snapshot format.
+const version = 1
+
+// The name of a snapshot is its prefix followed by its creation time.
+const nameLayout = "20060102T150405.000000000Z"
+
+var (
+	// ErrNoSnapshot is returned when restoring the latest snapshot of a
+	// prefix that has none.
+	ErrNoSnapshot = errors.New("backup: no snapshot")
+	// ErrKeySize is returned for an encryption key that is not 32 bytes.
+	ErrKeySize = errors.New("backup: encryption key is not 32 bytes")
+)
+
+// Source is a part of the state of a service.
+type Source interface {
+	// Name identifies the source within the snapshots.
+	Name() string
+	// Snapshot returns the state of the source.
+	Snapshot(ctx context.Context) ([]byte, error)
+	// Restore replaces the state of the source by a snapshot of it.
+	Restore(ctx context.Context, data []byte) error
+}
+
+// Prefix is a set of keys of a store, all starting with Prefix, restored with
+// a time to live of TTL. A zero TTL restores keys that never expire.
+type Prefix struct {
+	Prefix string
+	TTL    time.Duration
+}
+
+type storeSource struct {
+	name     string
+	store    store.Store
+	prefixes []Prefix
+}
+
+// StoreSource returns the source of the keys of st under prefixes. Its
+// snapshot is a JSON object of the keys and their values. Restoring it sets
+// the keys of the snapshot: the keys created since are kept.
+func StoreSource(name string, st store.Store, prefixes ...Prefix) Source {
+	return &storeSource{name: name, store: st, prefixes: prefixes}
+}
+
+func (s *storeSource) Name() string { return s.name }
+
+func (s *storeSource) Snapshot(ctx context.Context) ([]byte, error) {
+	values := map[string][]byte{}
+	for _, p := range s.prefixes {
+		keys, err := s.store.Keys(ctx, p.Prefix)
+		if err != nil {
+			return nil, err
+		}
+		for _, k := range keys {
+			v, err := s.store.Get(ctx, k)
+			if err == store.ErrNotFound {
+				// deleted or expired since Keys
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			values[k] = v
+		}
+	}
+	return json.Marshal(values)
+}
+
+func (s *storeSource) Restore(ctx context.Context, data []byte) error {
+	var values map[string][]byte
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	for k, v := range values {
+		p, ok := s.prefix(k)
+		if !ok {
+			continue
+		}
+		if err := s.store.Set(ctx, k, v, p.TTL); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// prefix returns the longest prefix of k.
+func (s *storeSource) prefix(k string) (Prefix, bool) {
+	var longest Prefix
+	ok := false
+	for _, p := range s.prefixes {
+		if strings.HasPrefix(k, p.Prefix) && (!ok || len(p.Prefix) > len(longest.Prefix)) {
+			longest, ok = p, true
+		}
+	}
+	return longest, ok
+}
+
+// snapshot is the content of a snapshot object.
+type snapshot struct {
+	Version int                        `json:"version"`
+	Created time.Time                  `json:"created"`
+	Sources map[string]json.RawMessage `json:"sources"`
+}
+
+// Option sets an optional parameter of a Backup.
+type Option func(*Backup) error
+
+// Encrypt encrypts the snapshots with AES-256-GCM and key, of 32 bytes.
+func Encrypt(key []byte) Option {
+	return func(b *Backup) error {
+		if len(key) != 32 {
+			return ErrKeySize
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return err
+		}
+		b.aead, err = cipher.NewGCM(block)
+		return err
+	}
+}
+
+// Logger sets the logger of the periodic snapshots.
+func Logger(logger log.Logger) Option {
+	return func(b *Backup) error {
+		b.logger = logger
+		return nil
+	}
+}
+
+// Backup snapshots its sources to a bucket and restores them.
+type Backup struct {
+	bucket  Bucket
+	prefix  string
+	sources []Source
+	aead    cipher.AEAD
+	logger  log.Logger
+}
+
+// New returns the backup of sources to the objects of bucket named prefix
+// followed by their creation time, such as "udm/".
+func New(bucket Bucket, prefix string, sources []Source, options ...Option) (*Backup, error) {
+	b := &Backup{
+		bucket:  bucket,
+		prefix:  prefix,
+		sources: sources,
+		logger:  log.NewNopLogger(),
+	}
+	for _, o := range options {
+		if err := o(b); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+// Snapshot uploads a snapshot of the sources and returns its name.
+func (b *Backup) Snapshot(ctx context.Context) (string, error) {
+	now := time.Now().UTC()
+	s := snapshot{Version: version, Created: now, Sources: map[string]json.RawMessage{}}
+	for _, src := range b.sources {
+		data, err := src.Snapshot(ctx)
+		if err != nil {
+			return "", fmt.Errorf("backup: source %s: %v", src.Name(), err)
+		}
+		s.Sources[src.Name()] = data
+	}
+	data, err := b.seal(s)
+	if err != nil {
+		return "", err
+	}
+	name := b.prefix + now.Format(nameLayout)
+	if err := b.bucket.Put(ctx, name, data); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// Run takes a snapshot every interval until ctx is done. The failed snapshots
+// are logged and taken again at the next interval.
+func (b *Backup) Run(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			start := time.Now()
+			name, err := b.Snapshot(ctx)
+			if err != nil {
+				level.Error(b.logger).Log("backup", "snapshot", "err", err)
+				continue
+			}
+			level.Info(b.logger).Log("backup", "snapshot", "object", name, "took", time.Since(start))
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Restore restores the sources from the snapshot name, or from the latest
+// one, and returns the name of the snapshot. A source missing from the
+// snapshot is left as it is.
+func (b *Backup) Restore(ctx context.Context, name string) (string, error) {
+	if name == Latest {
+		names, err := b.bucket.List(ctx, b.prefix)
+		if err != nil {
+			return "", err
+		}
+		name = ""
+		for _, n := range names {
+			if _, err := time.Parse(nameLayout, strings.TrimPrefix(n, b.prefix)); err == nil && n > name {
+				name = n
+			}
+		}
+		if name == "" {
+			return "", ErrNoSnapshot
+		}
+	}
+	data, err := b.bucket.Get(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	s, err := b.open(data)
+	if err != nil {
+		return "", fmt.Errorf("backup: %s: %v", name, err)
+	}
+	for _, src := range b.sources {
+		data, ok := s.Sources[src.Name()]
+		if !ok {
+			continue
+		}
+		if err := src.Restore(ctx, data); err != nil {
+			return "", fmt.Errorf("backup: source %s: %v", src.Name(), err)
+		}
+	}
+	return name, nil
+}
+
+// seal gzips s and encrypts it, the random nonce first.
+func (b *Backup) seal(s snapshot) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(zw).Encode(s); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	if b.aead == nil {
+		return buf.Bytes(), nil
+	}
+	nonce := make([]byte, b.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return b.aead.Seal(nonce, nonce, buf.Bytes(), nil), nil
+}
+
+// open reverses seal.
+func (b *Backup) open(data []byte) (snapshot, error) {
+	var s snapshot
+	if b.aead != nil {
+		n := b.aead.NonceSize()
+		if len(data) < n {
+			return s, errors.New("snapshot too short")
+		}
+		var err error
+		if data, err = b.aead.Open(nil, data[:n], data[n:], nil); err != nil {
+			return s, err
+		}
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return s, err
+	}
+	plain, err := ioutil.ReadAll(zr)
+	if err != nil {
+		return s, err
+	}
+	if err := json.Unmarshal(plain, &s); err != nil {
+		return s, err
+	}
+	if s.Version != version {
+		return s, fmt.Errorf("snapshot version %d", s.Version)
+	}
+	return s, nil
+}
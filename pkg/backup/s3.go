@@ -0,0 +1,216 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Bucket stores the snapshots.
+type Bucket interface {
+	Put(ctx context.Context, name string, data []byte) error
+	Get(ctx context.Context, name string) ([]byte, error)
+	// List returns the names of the objects starting with prefix, in
+	// lexical order.
+	List(ctx context.Context, prefix string) ([]string, error)
+}
+
+// S3 is a Bucket of an S3 compatible object storage, AWS S3 or MinIO. The
+// requests are signed with AWS Signature Version 4 and address the bucket in
+// the path, which both serve.
+type S3 struct {
+	endpoint  *url.URL
+	region    string
+	bucket    string
+	accessKey string
+	secretKey string
+	client    *http.Client
+}
+
+// NewS3 returns the bucket of the object storage at endpoint, such as
+// https://s3.eu-west-1.amazonaws.com or http://minio:9000, accessed with the
+// keys given.
+func NewS3(endpoint, region, bucket, accessKey, secretKey string) (*S3, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return nil, fmt.Errorf("backup: endpoint %q is not an http(s) URL", endpoint)
+	}
+	if bucket == "" {
+		return nil, fmt.Errorf("backup: missing bucket")
+	}
+	return &S3{
+		endpoint:  u,
+		region:    region,
+		bucket:    bucket,
+		accessKey: accessKey,
+		secretKey: secretKey,
+		client:    &http.Client{Timeout: time.Minute},
+	}, nil
+}
+
+// Put uploads data as the object name.
+func (s *S3) Put(ctx context.Context, name string, data []byte) error {
+	resp, err := s.do(ctx, http.MethodPut, name, nil, data)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// Get downloads the object name.
+func (s *S3) Get(ctx context.Context, name string) ([]byte, error) {
+	resp, err := s.do(ctx, http.MethodGet, name, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return ioutil.ReadAll(resp.Body)
+}
+
+type listBucketResult struct {
+	Contents []struct {
+		Key string
+	}
+	IsTruncated           bool
+	NextContinuationToken string
+}
+
+// List returns the names of the objects starting with prefix.
+func (s *S3) List(ctx context.Context, prefix string) ([]string, error) {
+	var names []string
+	query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+	for {
+		resp, err := s.do(ctx, http.MethodGet, "", query, nil)
+		if err != nil {
+			return nil, err
+		}
+		var res listBucketResult
+		err = xml.NewDecoder(resp.Body).Decode(&res)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range res.Contents {
+			names = append(names, c.Key)
+		}
+		if !res.IsTruncated {
+			break
+		}
+		query.Set("continuation-token", res.NextContinuationToken)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// do sends a signed request for the object name, the bucket itself when
+// name is empty, and fails on the responses that are not 2xx.
+func (s *S3) do(ctx context.Context, method, name string, query url.Values, body []byte) (*http.Response, error) {
+	u := *s.endpoint
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + s.bucket
+	if name != "" {
+		u.Path += "/" + name
+	}
+	u.RawPath = escapePath(u.Path)
+	u.RawQuery = canonicalQuery(query)
+	req, err := http.NewRequest(method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	sign(req, body, s.region, s.accessKey, s.secretKey, time.Now())
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		msg, _ := ioutil.ReadAll(resp.Body)
+		return nil, fmt.Errorf("backup: %s %s: %s: %s", method, u.Path, resp.Status, bytes.TrimSpace(msg))
+	}
+	return resp, nil
+}
+
+// sign adds the AWS Signature Version 4 of req to its headers, req and its
+// x-amz headers all being signed.
+func sign(req *http.Request, body []byte, region, accessKey, secretKey string, t time.Time) {
+	t = t.UTC()
+	date := t.Format("20060102")
+	payload := sha256.Sum256(body)
+	req.Header.Set("X-Amz-Date", t.Format("20060102T150405Z"))
+	req.Header.Set("X-Amz-Content-Sha256", hex.EncodeToString(payload[:]))
+
+	headers := map[string]string{"host": req.URL.Host}
+	for k, v := range req.Header {
+		headers[strings.ToLower(k)] = strings.TrimSpace(strings.Join(v, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, k := range names {
+		canonicalHeaders.WriteString(k + ":" + headers[k] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		hex.EncodeToString(payload[:]),
+	}, "\n")
+	scope := date + "/" + region + "/s3/aws4_request"
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + t.Format("20060102T150405Z") + "\n" + scope + "\n" + hex.EncodeToString(hash[:])
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+accessKey+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// escapePath escapes a path as the signature needs: every byte but the
+// unreserved ones and the slashes.
+func escapePath(path string) string {
+	var b strings.Builder
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || strings.IndexByte("-_.~/", c) >= 0 {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// canonicalQuery encodes query sorted by key, the spaces as %20.
+func canonicalQuery(query url.Values) string {
+	return strings.Replace(query.Encode(), "+", "%20", -1)
+}
@@ -0,0 +1,757 @@
+// Package bootstrap wires what every network function runs with, so that
+// its main package is left with what makes it different: its service, its
+// endpoints and the transports serving them.
+//
+// A Spec declares the NF: its name, its default ports, and which of the
+// optional parts it takes. New reads the configuration from the
+// environment and sets up the logging, the log levels, the tracing, the
+// health, the store, its records versioned, the capture, the per-caller
+// limits and the admission the Spec asks for. The main then builds its endpoints on Middleware,
+// declares its servers with HTTP and GRPC, and calls Run:
+//
+//	nf := bootstrap.New(bootstrap.Spec{Name: "ausf", HTTPPort: "8480", GRPCPort: "8481", Capture: true, Limits: true})
+//	endpoints := endpoints.New(service, nf.Middleware())
+//	nf.HTTP(nf.Config.HTTPPort, func(m *http.ServeMux) {
+//		m.Handle("/", transports.NewHTTPHandler(endpoints, nf.Tracer, nf.ZipkinTracer, nf.Logger))
+//	})
+//	nf.GRPC(nf.Config.GRPCPort, func(s *grpc.Server) {
+//		pb.RegisterAusfServer(s, transports.MakeGRPCServer(endpoints, nf.Tracer, nf.ZipkinTracer, nf.Logger))
+//	})
+//	nf.Run()
+//
+// The admin port serves the signalling load of the NF on /metrics/load, for
+// it to be scaled on (see package autoscale), with the failures, durations
+// and circuit breakers it is alerted on (see cmd/alertgen), the error
+// budgets of its methods on /admin/slo (see package slo), the keys of the
+// pseudonyms of the subscribers in its logs and captures on /admin/privacy
+// (see package privacy), and the NF profile of its identity on
+// /admin/profile (see package identity). It also serves the log levels on
+// /admin/loglevel and the UEs whose trace is active, whose requests
+// Middleware logs in full, on /admin/uetrace (see package uetrace).
+//
+// Run drains the NF on SIGINT and SIGTERM, or from the preStop hook of the
+// pod on /admin/drain of the admin port, once the NFs of
+// QS_<NAME>_DRAIN_AFTER are drained (see package drain).
+//
+// The environment variables keep their names: those of the NF of its own
+// are prefixed with QS_<NAME>_, the others are shared (see Config).
+package bootstrap
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/go-kit/kit/metrics"
+	"github.com/go-kit/kit/metrics/expvar"
+	"github.com/go-redis/redis"
+	stdopentracing "github.com/opentracing/opentracing-go"
+	"github.com/openzipkin/zipkin-go"
+	zipkinhttp "github.com/openzipkin/zipkin-go/reporter/http"
+	"github.com/sony/gobreaker"
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthgrpc "google.golang.org/grpc/health/grpc_health_v1"
+
+	drainpb "github.com/miki-tnt/sa5g-go-usvc-k8s/pb/drain"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/admin"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/autoscale"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/breakers"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/capture"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/compression"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/discovery"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/drain"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/etcd"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/exemplar"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/grpcserver"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/identity"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/kitx/chain"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/logging"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/loglevel"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/operator"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/otlp"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/postgres"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/priority"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/privacy"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/profiling"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/quota"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/recovery"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/sampling"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/schema"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/slo"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/store"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/uetrace"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/xds"
+)
+
+// envIdemWindow is the suffix of the idempotency window of an NF.
+const (
+	envIdemWindow = "IDEMPOTENCY_WINDOW"
+	defIdemWindow = "60s"
+)
+
+// Spec declares a network function.
+type Spec struct {
+	// Name is the default service name of the NF. Upper-cased, it prefixes
+	// the environment variables of its own: QS_UDM_HTTP_PORT and the like.
+	Name string
+	// Type is the NF type of its profile, the upper-cased Name by default.
+	Type string
+	// HTTPPort and GRPCPort are the default ports of its APIs.
+	HTTPPort string
+	GRPCPort string
+
+	// Compression reads the compression of the gRPC clients, see
+	// DialOptions.
+	Compression bool
+	// Capture records the calls, the last ones on the admin port, all of
+	// them in a file.
+	Capture bool
+	// Limits puts the rate limits, the circuit breakers and the per-caller
+	// limits in front of the endpoints.
+	Limits bool
+	// Admission admits the requests by priority.
+	Admission bool
+	// Idempotency replays the responses to the retries carrying the same
+	// idempotency key, for the methods built with chain.Idempotent.
+	Idempotency bool
+	// PLMNs reads the PLMNs served.
+	PLMNs bool
+	// Redis keeps the store, and the per-caller limits, in the Redis
+	// server configured, if any.
+	Redis bool
+	// Postgres keeps the store in the PostgreSQL database configured, if
+	// any, rather than in Redis.
+	Postgres bool
+	// Etcd keeps the store in the etcd cluster configured, if any, rather
+	// than in Redis.
+	Etcd bool
+	// Schemas version the records of the store, which are upgraded as they
+	// are read, see package schema.
+	Schemas []*schema.Schema
+	// GUAMIs reads the GUAMIs of an AMF, GNB the gNB ID of a gNB.
+	GUAMIs bool
+	GNB    bool
+}
+
+// NF is a network function being bootstrapped.
+type NF struct {
+	Spec   Spec
+	Config Config
+
+	// Identity is the identity of the NF instance, stamped into its logs,
+	// its metrics and the procedures it starts, and served as its NF
+	// profile on the admin port.
+	Identity identity.NF
+	// Logger logs for the NF, with its service name and identity.
+	Logger log.Logger
+	// Levels are its runtime log levels.
+	Levels *loglevel.Registry
+
+	Tracer       stdopentracing.Tracer
+	ZipkinTracer *zipkin.Tracer
+	// Health reports the service as serving on the gRPC servers.
+	Health *health.Server
+
+	// Store keeps the state of the NF: in PostgreSQL with Spec.Postgres and
+	// a URL, in etcd with Spec.Etcd and endpoints, in Redis with Spec.Redis
+	// and an address, in memory otherwise, its records of Spec.Schemas
+	// versioned. Redis is the Redis client, if any, Postgres the pool of
+	// connections to the database, if any, which the main keeps its tables
+	// in, Etcd the client of the cluster, if any, which the main watches.
+	Store    store.Store
+	Redis    *redis.Client
+	Postgres *sql.DB
+	Etcd     *etcd.Client
+
+	// Recorder records the calls with Spec.Capture, none otherwise.
+	Recorder capture.Recorder
+	// Limiter holds the callers to their limits with Spec.Limits and a
+	// caller rate, nil otherwise.
+	Limiter quota.Limiter
+	// Admission admits the requests with Spec.Admission and a maximum of
+	// concurrent requests, nil otherwise.
+	Admission *priority.Admission
+	// IdempotencyWindow is how long the responses are replayed with
+	// Spec.Idempotency.
+	IdempotencyWindow time.Duration
+	// Load is the signalling load of the NF, the procedures counted by
+	// Middleware and the gauges the main declares.
+	Load *autoscale.Load
+	// SLO tracks the error budgets of the methods of the NF, classified by
+	// Middleware against Config.SLOs.
+	SLO *slo.Tracker
+	// UETrace are the UEs whose trace is active, whose requests
+	// Middleware logs in full.
+	UETrace *uetrace.List
+	// Privacy makes the pseudonyms of the subscriber identifiers in the
+	// logs and the captures, keyed with Config.PrivacyKeys.
+	Privacy *privacy.Pseudonymizer
+	// Discovery resolves the k8s targets the clients of DialOptions dial
+	// to the pods of their service, weighted by version.
+	Discovery *discovery.Discovery
+	// XDS resolves the xds targets the clients of DialOptions dial with
+	// the resources of the xDS control plane of the gRPC xDS bootstrap.
+	XDS *xds.Client
+	// Metrics pushes the request metrics to the OpenTelemetry collector of
+	// Config.OTLPEndpoint, nil when none is.
+	Metrics *otlp.Provider
+	// Profiler pushes the CPU and heap profiles of the NF to the server of
+	// Config.ProfilingURL, nil when none is.
+	Profiler *profiling.Profiler
+
+	reported interface{}
+	admin    []admin.Option
+	servers  []func(errs chan<- error)
+	// drain drains the NF, after the NFs of Config.DrainAfter and those
+	// of drainAfter.
+	drain      *drain.Coordinator
+	drainAfter []drain.Option
+}
+
+// New reads the configuration of the NF of spec and sets it up. It exits
+// when a part of it cannot be.
+func New(spec Spec) *NF {
+	nf := &NF{Spec: spec, Levels: loglevel.New(loglevel.Info)}
+	{
+		logger := logging.NewLogger(os.Stderr, nf.String(EnvLogFormat, defLogFormat))
+		logger = nf.Levels.NewFilter(logger)
+		if redact, _ := strconv.ParseBool(nf.String(EnvLogRedact, defLogRedact)); redact {
+			// the pseudonyms are keyed once the keys are read, below
+			logger = logging.RedactWith(logger, func(id string) string { return nf.Privacy.Pseudonym(id) }, logging.DefaultRedactedKeys...)
+		}
+		logger = log.With(logger, "ts", log.DefaultTimestampUTC)
+		nf.Logger = log.With(logger, "caller", log.DefaultCaller)
+	}
+	nf.loadConfig()
+	cfg := nf.Config
+	nf.Identity = identity.NF{
+		Type:       spec.NFType(),
+		InstanceID: cfg.NFInstanceID,
+		Name:       cfg.InstanceID,
+		PLMNs:      cfg.ServedPLMNs,
+		GUAMIs:     cfg.GUAMIs,
+		GNBID:      cfg.GNBID,
+	}
+	nf.Logger = log.With(nf.Logger, append([]interface{}{"service", cfg.ServiceName}, nf.Identity.Keyvals()...)...)
+	nf.Privacy = nf.privacy()
+	if l, err := loglevel.Parse(cfg.LogLevel); err != nil {
+		level.Error(nf.Logger).Log("env", spec.Var(envLogLevel), "error", err)
+	} else {
+		nf.Levels.SetDefault(l)
+	}
+	if spec.Compression {
+		if err := compression.SetLevel(cfg.GRPCCompressionLevel); err != nil {
+			level.Error(nf.Logger).Log("env", EnvGRPCCompressionLevel, "error", err)
+		}
+	}
+	if spec.Idempotency {
+		nf.IdempotencyWindow = nf.Duration(spec.Var(envIdemWindow), defIdemWindow)
+	}
+
+	nf.Tracer = stdopentracing.GlobalTracer()
+	nf.ZipkinTracer = nf.zipkin()
+	nf.Health = health.NewServer()
+	nf.Health.SetServingStatus(cfg.ServiceName, healthgrpc.HealthCheckResponse_SERVING)
+	nf.Store, nf.Redis, nf.Postgres, nf.Etcd = nf.store()
+	nf.Load = autoscale.New(spec.Name)
+	nf.Load.Gauge(autoscale.Info, func() float64 { return 1 }, nf.Identity.Labels()...)
+	nf.SLO = slo.New(spec.Name, cfg.SLOs)
+	nf.SLO.Export(nf.Load)
+	nf.UETrace = nf.uetrace()
+	nf.Discovery = nf.discovery()
+	nf.XDS = nf.xds()
+	nf.Metrics = nf.otlp()
+	nf.Profiler = nf.profiler()
+	nf.Admin(
+		admin.Handle(autoscale.Path, autoscale.Handler(nf.Load)),
+		admin.Handle(slo.Path, slo.Handler(nf.SLO)),
+		admin.Handle(uetrace.Path, uetrace.Handler(nf.UETrace)),
+		admin.Handle(identity.ProfilePath, identity.ProfileHandler(nf.Identity)))
+	if spec.Capture {
+		nf.Recorder = nf.capture()
+	}
+	if spec.Limits {
+		nf.Limiter = nf.limiter()
+		nf.Load.Gauges(autoscale.BreakerOpen, "breaker", breakersOpen)
+	}
+	if spec.Admission {
+		nf.Admission = nf.NewAdmission("admission", cfg.MaxConcurrent, cfg.AdmissionQueue)
+	}
+	return nf
+}
+
+// breakersOpen returns 1 for the circuit breakers that are open, 0 for the
+// others, by name.
+func breakersOpen() map[string]float64 {
+	open := make(map[string]float64)
+	for _, s := range breakers.States() {
+		open[s.Name] = 0
+		if s.State == gobreaker.StateOpen.String() {
+			open[s.Name] = 1
+		}
+	}
+	return open
+}
+
+// RequestLogger returns the logger of the requests, which logs one of
+// every QS_LOG_SAMPLE of them; the other logs are not sampled.
+func (nf *NF) RequestLogger() log.Logger {
+	return logging.Sample(nf.Logger, nf.Config.LogSample)
+}
+
+// Middleware returns the middleware stack of the endpoints the Spec asks
+// for, which the main may complete.
+func (nf *NF) Middleware() chain.MiddlewareConfig {
+	mw := chain.MiddlewareConfig{
+		Logger:       nf.RequestLogger(),
+		OTTracer:     nf.Tracer,
+		ZipkinTracer: nf.ZipkinTracer,
+		Recorder:     nf.Recorder,
+		NFInstanceID: nf.Identity.InstanceID.String(),
+		Limiter:      nf.Limiter,
+		Admission:    nf.Admission,
+		Load:         nf.Load,
+		SLO:          nf.SLO,
+		UETrace:      nf.UETrace,
+	}
+	if nf.Spec.Limits {
+		mw.Rate, mw.Burst, mw.Breaker = chain.DefaultRate, chain.DefaultBurst, true
+	}
+	if nf.Spec.Idempotency {
+		mw.Store, mw.IdempotencyWindow = nf.Store, nf.IdempotencyWindow
+	}
+	return mw
+}
+
+// RequestDuration returns the histogram of the durations of the requests,
+// for Middleware, by method and success: the one served with its exemplars
+// on the admin port, and the one pushed to the OpenTelemetry collector too
+// when Metrics is set.
+func (nf *NF) RequestDuration() metrics.Histogram {
+	const (
+		name = "request_duration_seconds"
+		help = "Seconds the requests took, by method and success."
+	)
+	duration := exemplar.NewHistogram(name, help, exemplar.DefaultBuckets)
+	nf.Admin(admin.Handle(exemplar.Path, exemplar.Handler(duration)))
+	if nf.Metrics == nil {
+		return duration
+	}
+	return exemplar.Tee(duration, nf.Metrics.NewHistogram(name, help, exemplar.DefaultBuckets))
+}
+
+// NewAdmission returns an admission of the requests by priority, nil when
+// maxConcurrent is zero. Its queue depth and sheds are reported in expvar,
+// after name, and its state on the admin port under name; its queue depth
+// is a load of the NF too.
+func (nf *NF) NewAdmission(name string, maxConcurrent, queueSize int) *priority.Admission {
+	a := priority.NewAdmission(
+		maxConcurrent,
+		queueSize,
+		priority.QueueDepthGauge(expvar.NewGauge(name+"_queue_depth")),
+		priority.ShedCounter(expvar.NewCounter(name+"_shed")),
+	)
+	if a != nil {
+		nf.Admin(admin.Pool(name, func() interface{} { return a.Stats() }))
+		nf.Load.Gauge(autoscale.QueueDepth, func() float64 {
+			var n int
+			for _, queued := range a.Stats().Queued {
+				n += queued
+			}
+			return float64(n)
+		}, "queue", name)
+	}
+	return a
+}
+
+// DialOptions returns the options of the gRPC clients of the NF: the
+// compression, with Spec.Compression, the message sizes, and the resolution
+// of the k8s targets by Discovery and of the xds targets by XDS.
+func (nf *NF) DialOptions() []grpc.DialOption {
+	cfg := nf.Config
+	opts := []grpc.DialOption{
+		grpc.WithInsecure(),
+		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(cfg.GRPCMaxRecvMsgSize), grpc.MaxCallSendMsgSize(cfg.GRPCMaxSendMsgSize)),
+		grpc.WithResolvers(nf.Discovery, nf.XDS),
+	}
+	if nf.Spec.Compression {
+		opts = append(opts, grpc.WithUnaryInterceptor(compression.UnaryClientInterceptor(cfg.GRPCCompression, cfg.GRPCCompressionMethods)))
+	}
+	return opts
+}
+
+// DrainAfter adds the NFs, by gRPC address, to be drained before this one,
+// as known when the drain starts.
+func (nf *NF) DrainAfter(addresses func() []string) {
+	nf.drainAfter = append(nf.drainAfter, drain.AfterFunc(addresses))
+}
+
+// Admin adds options to the admin server.
+func (nf *NF) Admin(options ...admin.Option) {
+	nf.admin = append(nf.admin, options...)
+}
+
+// Report sets the configuration reported on the admin port, usually the
+// config struct of the main package embedding the Config; the Config is
+// reported otherwise.
+func (nf *NF) Report(cfg interface{}) {
+	nf.reported = cfg
+}
+
+// HTTP serves the routes on port, recovering from the panics of the
+// handlers. The routes are refused while the NF drains.
+func (nf *NF) HTTP(port string, routes func(m *http.ServeMux)) {
+	nf.servers = append(nf.servers, func(errs chan<- error) {
+		m := http.NewServeMux()
+		routes(m)
+		level.Info(nf.Logger).Log("protocol", "HTTP", "exposed", port)
+		errs <- http.ListenAndServe(":"+port, recovery.Handler(nf.drain.Handler(m), nf.Logger))
+	})
+}
+
+// GRPC serves the services register registers on port, along with the
+// health and the drain, with the keepalive and the message sizes
+// configured. The services are refused while the NF drains. It exits when
+// the port cannot be listened on.
+func (nf *NF) GRPC(port string, register func(s *grpc.Server)) {
+	nf.servers = append(nf.servers, func(errs chan<- error) {
+		listener, err := net.Listen("tcp", ":"+port)
+		if err != nil {
+			level.Error(nf.Logger).Log("protocol", "GRPC", "listen", port, "err", err)
+			os.Exit(1)
+		}
+		server := grpcserver.NewServerBuilder(nf.Health).
+			Recovery(nf.Logger).
+			Keepalive(nf.Config.GRPCKeepalive, 0).
+			MaxMsgSize(nf.Config.GRPCMaxRecvMsgSize, nf.Config.GRPCMaxSendMsgSize).
+			UnaryInterceptor(nf.drain.UnaryServerInterceptor()).
+			StreamInterceptor(nf.drain.StreamServerInterceptor()).
+			Build()
+		drainpb.RegisterDrainServer(server, drain.NewGRPCServer(nf.drain))
+		register(server)
+		level.Info(nf.Logger).Log("protocol", "GRPC", "exposed", port)
+		errs <- server.Serve(listener)
+	})
+}
+
+// Listen serves protocol, neither HTTP nor gRPC, on port with serve, which
+// gets the listener. It exits when the port cannot be listened on.
+func (nf *NF) Listen(protocol, port string, serve func(l net.Listener) error) {
+	nf.servers = append(nf.servers, func(errs chan<- error) {
+		listener, err := net.Listen("tcp", ":"+port)
+		if err != nil {
+			level.Error(nf.Logger).Log("protocol", protocol, "listen", port, "err", err)
+			os.Exit(1)
+		}
+		level.Info(nf.Logger).Log("protocol", protocol, "exposed", port)
+		errs <- serve(listener)
+	})
+}
+
+// Run serves the servers of the NF, and the admin server on its port if
+// any, until one fails or the NF is interrupted, and returns why. An
+// interrupted NF is drained first.
+func (nf *NF) Run() error {
+	cfg := nf.Config
+	nf.drain = drain.New(nf.Health, nf.Logger, append([]drain.Option{
+		drain.After(cfg.DrainAfter...),
+		drain.Timeout(cfg.DrainTimeout),
+		drain.DialOptions(nf.DialOptions()...),
+	}, nf.drainAfter...)...)
+	nf.Admin(
+		admin.Handle(drain.Path, nf.drain.DrainHandler()),
+		admin.Pool("drain", nf.drain.Stats))
+
+	errs := make(chan error, len(nf.servers)+2)
+	for _, serve := range nf.servers {
+		go serve(errs)
+	}
+	if port := nf.Config.AdminPort; port != "" {
+		reported := nf.reported
+		if reported == nil {
+			reported = nf.Config
+		}
+		handler := admin.New(nf.Levels, append([]admin.Option{admin.Config(reported)}, nf.admin...)...)
+		go func() {
+			level.Info(nf.Logger).Log("protocol", "HTTP", "admin", port)
+			errs <- http.ListenAndServe(":"+port, handler)
+		}()
+	}
+	go func() {
+		c := make(chan os.Signal, 1)
+		signal.Notify(c, syscall.SIGINT, syscall.SIGTERM)
+		sig := <-c
+		// the drain is bounded by its own timeout
+		nf.drain.Drain(context.Background())
+		errs <- fmt.Errorf("%s", sig)
+	}()
+
+	err := <-errs
+	level.Info(nf.Logger).Log("serviceName", nf.Config.ServiceName, "terminated", err)
+	if nf.Metrics != nil {
+		// the last metrics, those since the last tick
+		ctx, cancel := context.WithTimeout(context.Background(), otlp.DefaultTimeout)
+		if err := nf.Metrics.Push(ctx); err != nil {
+			level.Warn(nf.Logger).Log("otlp", nf.Config.OTLPEndpoint, "err", err)
+		}
+		cancel()
+	}
+	return err
+}
+
+// zipkin returns the Zipkin tracer of the NF, which exports the traces the
+// trace sampling keeps.
+func (nf *NF) zipkin() *zipkin.Tracer {
+	cfg := nf.Config
+	samplingOptions, err := cfg.TraceSampling.Options()
+	if err != nil {
+		nf.Logger.Log("err", err)
+		os.Exit(1)
+	}
+	var (
+		hostPort      = fmt.Sprintf("localhost:%s", cfg.HTTPPort)
+		useNoopTracer = (cfg.ZipkinV2URL == "")
+		reporter      = sampling.NewReporter(zipkinhttp.NewReporter(cfg.ZipkinV2URL), samplingOptions...)
+	)
+	zEP, _ := zipkin.NewEndpoint(cfg.ServiceName, hostPort)
+	zipkinTracer, err := zipkin.NewTracer(reporter, zipkin.WithLocalEndpoint(zEP), zipkin.WithNoopTracer(useNoopTracer))
+	if err != nil {
+		nf.Logger.Log("err", err)
+		os.Exit(1)
+	}
+	if !useNoopTracer {
+		nf.Logger.Log("tracer", "Zipkin", "type", "Native", "URL", cfg.ZipkinV2URL)
+	}
+	return zipkinTracer
+}
+
+// store returns the store of the NF, under its name, and the Redis client,
+// the database and the etcd client it uses, if any: the PostgreSQL store of
+// the database configured, whose expired records it deletes, or else the
+// etcd store of the endpoints configured, or else the Redis store at the
+// address configured, or else an in-memory store. The Redis client is
+// returned with a database or etcd too, for the per-caller limits. The
+// records of Spec.Schemas are upgraded as they are read.
+func (nf *NF) store() (store.Store, *redis.Client, *sql.DB, *etcd.Client) {
+	st := store.NewMemory()
+	var client *redis.Client
+	if addr := nf.Config.RedisAddr; addr != "" {
+		client = redis.NewClient(&redis.Options{Addr: addr})
+		if err := client.Ping().Err(); err != nil {
+			level.Error(nf.Logger).Log("redis", addr, "err", err)
+			os.Exit(1)
+		}
+		nf.Logger.Log("store", "Redis", "addr", addr)
+		st = store.NewRedis(client, nf.Spec.Name+"/")
+	}
+	var ec *etcd.Client
+	if endpoints := nf.Config.EtcdEndpoints; len(endpoints) > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		var err error
+		if ec, err = etcd.New(endpoints, etcd.Logger(log.With(nf.Logger, loglevel.ComponentKey, "etcd"))); err == nil {
+			err = ec.Ping(ctx)
+		}
+		if err != nil {
+			level.Error(nf.Logger).Log("env", nf.Spec.Var(envEtcdEndpoints), "err", err)
+			os.Exit(1)
+		}
+		nf.Logger.Log("store", "etcd", "endpoints", strings.Join(endpoints, ","))
+		st = etcd.NewStore(ec, nf.Spec.Name+"/")
+	}
+	var db *sql.DB
+	if url := nf.Config.PostgresURL; url != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		var err error
+		if db, err = postgres.Open(ctx, url, postgres.MaxConns(nf.Config.PostgresMaxConns)); err != nil {
+			level.Error(nf.Logger).Log("env", nf.Spec.Var(envPostgresURL), "err", err)
+			os.Exit(1)
+		}
+		nf.Logger.Log("store", "PostgreSQL", "max_conns", nf.Config.PostgresMaxConns)
+		st = postgres.NewStore(db, nf.Spec.Name+"/")
+		go postgres.Expire(context.Background(), db, postgres.DefaultExpire, log.With(nf.Logger, loglevel.ComponentKey, "postgres"))
+	}
+	return schema.NewStore(st, nf.Spec.Schemas...), client, db, ec
+}
+
+// uetrace returns the trace activations of the UEs, kept in the Redis of
+// Config.UETraceRedisAddr, shared by every NF using it, or in the store of
+// the NF when there is none or it cannot be reached.
+func (nf *NF) uetrace() *uetrace.List {
+	st := nf.Store
+	if addr := nf.Config.UETraceRedisAddr; addr != "" {
+		client := redis.NewClient(&redis.Options{Addr: addr})
+		if err := client.Ping().Err(); err != nil {
+			level.Error(nf.Logger).Log("env", EnvUETraceRedisAddr, "redis", addr, "err", err)
+		} else {
+			st = store.NewRedis(client, "")
+		}
+	}
+	return uetrace.New(st, uetrace.Logger(log.With(nf.Logger, loglevel.ComponentKey, "uetrace")))
+}
+
+// privacy returns the pseudonymizer of the keys of Config.PrivacyKeys, its
+// state and reveals served on the admin port. It exits when the keys cannot
+// be read, rather than export the identifiers with unkeyed pseudonyms.
+func (nf *NF) privacy() *privacy.Pseudonymizer {
+	p, err := privacy.New(nf.Config.PrivacyKeys,
+		privacy.Logger(log.With(nf.Logger, loglevel.ComponentKey, "privacy")),
+		privacy.RevealEnabled(nf.Config.PrivacyReveal))
+	if err != nil {
+		level.Error(nf.Logger).Log("env", EnvPrivacyKeys, "error", err)
+		os.Exit(1)
+	}
+	nf.Admin(
+		admin.Handle(privacy.Path, privacy.Handler(p)),
+		admin.Handle(privacy.RevealPath, privacy.RevealHandler(p)))
+	return p
+}
+
+// discovery returns the resolver of the k8s targets, reading the pods from
+// Config.KubeAPIURL or the API server of the cluster, its targets served on
+// the admin port. Out of a cluster, the k8s targets fail to dial.
+func (nf *NF) discovery() *discovery.Discovery {
+	cfg := nf.Config
+	var client *operator.Client
+	if cfg.KubeAPIURL != "" {
+		client = operator.NewClient(cfg.KubeAPIURL)
+	} else if c, err := operator.InClusterClient(); err == nil {
+		client = c
+	} else if err != operator.ErrNotInCluster {
+		level.Error(nf.Logger).Log("env", EnvKubeAPIURL, "error", err)
+	}
+	d := discovery.New(client,
+		discovery.Refresh(cfg.DiscoveryRefresh),
+		discovery.VersionLabel(cfg.DiscoveryVersionLabel),
+		discovery.Logger(log.With(nf.Logger, loglevel.ComponentKey, "discovery")))
+	nf.Admin(
+		admin.Handle(discovery.Path, discovery.Handler(d)),
+		admin.Pool("discovery", func() interface{} { return d.Stats() }))
+	return d
+}
+
+// xds returns the xDS client of the bootstrap of xds.EnvBootstrap, its
+// stream and targets served on the admin port. Without a bootstrap, the xds
+// targets fail to dial.
+func (nf *NF) xds() *xds.Client {
+	b, err := xds.ReadBootstrap()
+	if err != nil {
+		level.Error(nf.Logger).Log("env", xds.EnvBootstrap, "error", err)
+	}
+	c := xds.NewClient(b, xds.Logger(log.With(nf.Logger, loglevel.ComponentKey, "xds")))
+	nf.Admin(admin.Pool("xds", func() interface{} { return c.Stats() }))
+	return c
+}
+
+// otlp returns the provider of the metrics pushed to the collector of
+// Config.OTLPEndpoint every Config.OTLPInterval, their resource the service
+// and identity of the NF, nil without an endpoint.
+func (nf *NF) otlp() *otlp.Provider {
+	cfg := nf.Config
+	if cfg.OTLPEndpoint == "" {
+		return nil
+	}
+	resource := append([]string{
+		"service.name", cfg.ServiceName,
+		"service.namespace", cfg.NameSpace,
+		"service.instance.id", cfg.InstanceID,
+	}, nf.Identity.Labels()...)
+	p, err := otlp.New(cfg.OTLPEndpoint,
+		otlp.Resource(resource...),
+		otlp.Logger(log.With(nf.Logger, loglevel.ComponentKey, "otlp")))
+	if err != nil {
+		level.Error(nf.Logger).Log("env", EnvOTLPEndpoint, "error", err)
+		return nil
+	}
+	interval := cfg.OTLPInterval
+	if interval <= 0 {
+		interval, _ = time.ParseDuration(defOTLPInterval)
+	}
+	go p.PushLoop(context.Background(), time.NewTicker(interval).C)
+	nf.Logger.Log("metrics", "OTLP", "URL", cfg.OTLPEndpoint, "interval", interval)
+	return p
+}
+
+// profiler returns the profiler pushing to the server of
+// Config.ProfilingURL, its profiles labelled with the identity and version
+// of the NF, and its pushes served on the admin port. It is nil without a
+// URL.
+func (nf *NF) profiler() *profiling.Profiler {
+	cfg := nf.Config
+	if cfg.ProfilingURL == "" {
+		return nil
+	}
+	labels := append(nf.Identity.Labels(), "namespace", cfg.NameSpace, "version", cfg.Version)
+	p, err := profiling.New(cfg.ProfilingURL, cfg.ServiceName,
+		profiling.Labels(labels...),
+		profiling.Period(cfg.ProfilingPeriod),
+		profiling.Logger(log.With(nf.Logger, loglevel.ComponentKey, "profiling")))
+	if err != nil {
+		level.Error(nf.Logger).Log("env", EnvProfilingURL, "error", err)
+		return nil
+	}
+	go p.Run(context.Background())
+	nf.Admin(admin.Pool("profiling", func() interface{} { return p.Stats() }))
+	nf.Logger.Log("profiling", p.Stats().URL, "period", cfg.ProfilingPeriod, "version", cfg.Version)
+	return p
+}
+
+// capture returns the recorder of the calls captured: the last ones kept
+// in a ring, served on the admin port, and every one appended to a file.
+// Either is off when not configured, and pseudonymizes the subscriber
+// identifiers with the keys of Config.PrivacyKeys.
+func (nf *NF) capture() capture.Recorder {
+	var recs []capture.Recorder
+	if size := nf.Config.CaptureBuffer; size > 0 {
+		ring := capture.NewRing(size)
+		recs = append(recs, ring)
+		nf.Admin(
+			admin.Handle(capture.Path, capture.Handler(ring)),
+			admin.Pool("capture", func() interface{} { return ring.Stats() }))
+	}
+	if file := nf.Config.CaptureFile; file != "" {
+		f, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+		if err != nil {
+			level.Error(nf.Logger).Log("capture", file, "err", err)
+			os.Exit(1)
+		}
+		recs = append(recs, capture.NewWriter(f, nf.Logger))
+	}
+	rec := capture.Multi(recs...)
+	if nf.Config.PrivacyKeys != "" {
+		rec = capture.Pseudonymize(rec, nf.Privacy.Pseudonym, logging.DefaultRedactedKeys...)
+	}
+	return rec
+}
+
+// limiter returns the per-caller limiter of the endpoints, nil when the
+// caller rate is zero. With Redis, the limit of a burst of requests every
+// burst/rate seconds holds across the replicas, each of them falling back
+// to local token buckets while Redis is unreachable. Its decisions are
+// counted in expvar.
+func (nf *NF) limiter() quota.Limiter {
+	callerRate, callerBurst := nf.Config.CallerRate, nf.Config.CallerBurst
+	if callerRate <= 0 {
+		return nil
+	}
+	limiter := quota.NewTokenBuckets(nf.Store, rate.Limit(callerRate), callerBurst)
+	if nf.Redis != nil {
+		window := time.Duration(float64(callerBurst) / callerRate * float64(time.Second))
+		limiter = quota.NewSlidingWindow(nf.Redis, nf.Spec.Name+"/", callerBurst, window,
+			quota.Logger(log.With(nf.Logger, loglevel.ComponentKey, "quota")))
+	}
+	return quota.Instrument(limiter, expvar.NewCounter("quota_allowed"), expvar.NewCounter("quota_denied"))
+}
@@ -0,0 +1,400 @@
+package bootstrap
+
+import (
+	"os"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-kit/kit/log/level"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/compression"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/discovery"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/identity"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/plmn"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/sampling"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/slo"
+)
+
+// The environment variables shared by every NF. Those of an NF of its own
+// are prefixed with QS_<NAME>_, see Spec.Name.
+const (
+	EnvZipkinV2URL            = "QS_ZIPKIN_V2_URL"
+	EnvTraceRate              = "QS_TRACE_SAMPLE_RATE"
+	EnvTraceLimit             = "QS_TRACE_RATE_LIMIT"
+	EnvTraceMethods           = "QS_TRACE_SAMPLE_METHODS"
+	EnvTraceKeep              = "QS_TRACE_KEEP"
+	EnvLogFormat              = "QS_LOG_FORMAT"
+	EnvLogSample              = "QS_LOG_SAMPLE"
+	EnvLogRedact              = "QS_LOG_REDACT"
+	EnvGRPCKeepalive          = "QS_GRPC_KEEPALIVE_TIME"
+	EnvGRPCMaxMsgSize         = "QS_GRPC_MAX_MSG_SIZE"
+	EnvGRPCMaxRecvMsgSize     = "QS_GRPC_MAX_RECV_MSG_SIZE"
+	EnvGRPCMaxSendMsgSize     = "QS_GRPC_MAX_SEND_MSG_SIZE"
+	EnvGRPCCompression        = "QS_GRPC_COMPRESSION"
+	EnvGRPCCompressionLevel   = "QS_GRPC_COMPRESSION_LEVEL"
+	EnvGRPCCompressionMethods = "QS_GRPC_COMPRESSION_METHODS"
+	EnvCaptureBuffer          = "QS_CAPTURE_BUFFER"
+	EnvCaptureFile            = "QS_CAPTURE_FILE"
+	EnvUETraceRedisAddr       = "QS_UE_TRACE_REDIS_ADDR"
+	EnvPrivacyKeys            = "QS_PRIVACY_KEYS_DIR"
+	EnvPrivacyReveal          = "QS_PRIVACY_REVEAL"
+	EnvKubeAPIURL             = "QS_KUBE_API_URL"
+	EnvDiscoveryRefresh       = "QS_DISCOVERY_REFRESH"
+	EnvDiscoveryVersionLabel  = "QS_DISCOVERY_VERSION_LABEL"
+	EnvOTLPEndpoint           = "QS_OTLP_ENDPOINT"
+	EnvOTLPInterval           = "QS_OTLP_INTERVAL"
+	EnvProfilingURL           = "QS_PROFILING_URL"
+	EnvProfilingPeriod        = "QS_PROFILING_PERIOD"
+)
+
+// The suffixes of the environment variables of an NF of its own.
+const (
+	envNameSpace      = "NAMESPACE"
+	envServiceName    = "SERVICE_NAME"
+	envInstanceID     = "INSTANCE_ID"
+	envVersion        = "VERSION"
+	envNFInstanceID   = "NF_INSTANCE_ID"
+	envGUAMIs         = "GUAMIS"
+	envGNBID          = "GNB_ID"
+	envLogLevel       = "LOG_LEVEL"
+	envServiceHost    = "SERVICE_HOST"
+	envHTTPPort       = "HTTP_PORT"
+	envGRPCPort       = "GRPC_PORT"
+	envAdminPort      = "ADMIN_PORT"
+	envCallerRate     = "CALLER_RATE"
+	envCallerBurst    = "CALLER_BURST"
+	envMaxConcurrent  = "MAX_CONCURRENT"
+	envAdmissionQueue = "ADMISSION_QUEUE"
+	envServedPLMNs    = "SERVED_PLMNS"
+	envRedisAddr      = "REDIS_ADDR"
+	envPostgresURL    = "POSTGRES_URL"
+	envPostgresConns  = "POSTGRES_MAX_CONNS"
+	envEtcdEndpoints  = "ETCD_ENDPOINTS"
+	envDrainAfter     = "DRAIN_AFTER"
+	envDrainTimeout   = "DRAIN_TIMEOUT"
+	envSLOs           = "SLOS"
+)
+
+// The defaults of the configuration.
+const (
+	defNameSpace              = "sa5g-go-usvc-k8s"
+	defLogLevel               = "error"
+	defLogFormat              = "logfmt"
+	defLogSample              = "1"
+	defLogRedact              = "true"
+	defGRPCKeepalive          = "0s"
+	defGRPCMaxMsgSize         = "4194304"
+	defGRPCCompression        = compression.Identity
+	defGRPCCompressionLevel   = "-1"
+	defGRPCCompressionMethods = ""
+	defServiceHost            = "localhost"
+	defTraceRate              = "1"
+	defTraceLimit             = "0"
+	defCaptureBuffer          = "0"
+	defCallerRate             = "0"
+	defCallerBurst            = "20"
+	defMaxConcurrent          = "0"
+	defAdmissionQueue         = "100"
+	defPostgresConns          = "10"
+	defDrainTimeout           = "25s"
+	defSLOs                   = "*=500ms/99"
+	defPrivacyReveal          = "false"
+	defDiscoveryRefresh       = "10s"
+	defDiscoveryVersionLabel  = discovery.DefaultVersionLabel
+	defOTLPInterval           = "10s"
+	defProfilingPeriod        = "10s"
+)
+
+// Config is the configuration every NF runs with, read from the
+// environment. The parts a Spec leaves out stay zero.
+type Config struct {
+	NameSpace   string
+	ServiceName string
+	// InstanceID is the name of the instance, NFInstanceID its NF instance
+	// ID, name based on the instance, service and namespace names unless
+	// configured.
+	InstanceID   string
+	NFInstanceID identity.NFInstanceID
+	// Version is the version of the NF, that of its build unless
+	// configured, such as from the version label of its pod.
+	Version            string
+	LogLevel           string
+	LogSample          int
+	GRPCKeepalive      time.Duration
+	GRPCMaxRecvMsgSize int
+	GRPCMaxSendMsgSize int
+	ServiceHost        string
+	HTTPPort           string
+	GRPCPort           string
+	AdminPort          string
+	ZipkinV2URL        string
+	TraceSampling      sampling.Config
+	// DrainAfter are the gRPC addresses of the NFs to be drained before
+	// this one, DrainTimeout bounds the drain.
+	DrainAfter   []string
+	DrainTimeout time.Duration
+	// SLOs are the objectives of its methods, by method name in lower
+	// case (see slo.Parse).
+	SLOs map[string]slo.Objective
+	// UETraceRedisAddr is the Redis of the trace activations of the UEs,
+	// shared by every NF using it.
+	UETraceRedisAddr string
+	// PrivacyKeys is the directory of the keys of the pseudonyms, the
+	// secret mounted, PrivacyReveal whether they may be revealed on the
+	// admin port (see package privacy).
+	PrivacyKeys   string
+	PrivacyReveal bool
+	// KubeAPIURL is the API server the pods of the k8s targets are read
+	// from, such as the http://localhost:8001 of kubectl proxy, that of the
+	// cluster when empty. DiscoveryRefresh is the period they are read
+	// again at, DiscoveryVersionLabel the label giving their version (see
+	// package discovery).
+	KubeAPIURL            string
+	DiscoveryRefresh      time.Duration
+	DiscoveryVersionLabel string
+	// OTLPEndpoint is the OpenTelemetry collector the request metrics are
+	// pushed to over OTLP/HTTP, such as http://otel-collector:4318, every
+	// OTLPInterval, none when empty (see package otlp).
+	OTLPEndpoint string
+	OTLPInterval time.Duration
+	// ProfilingURL is the Pyroscope (http, https) or Parca (grpc) server
+	// the CPU and heap profiles are pushed to, a pair every
+	// ProfilingPeriod, none when empty (see package profiling). Its
+	// credentials, if any, are reported with the configuration.
+	ProfilingURL    string
+	ProfilingPeriod time.Duration
+
+	// Spec.Compression
+	GRPCCompression        string
+	GRPCCompressionLevel   int
+	GRPCCompressionMethods map[string]string
+	// Spec.Capture
+	CaptureBuffer int
+	CaptureFile   string
+	// Spec.Limits
+	CallerRate  float64
+	CallerBurst int
+	// Spec.Admission
+	MaxConcurrent  int
+	AdmissionQueue int
+	// Spec.PLMNs
+	ServedPLMNs plmn.List
+	// Spec.Redis
+	RedisAddr string
+	// Spec.Postgres, the password of the URL best given in PGPASSWORD
+	// rather than reported
+	PostgresURL      string
+	PostgresMaxConns int
+	// Spec.Etcd
+	EtcdEndpoints []string
+	// Spec.GUAMIs
+	GUAMIs identity.GUAMIList
+	// Spec.GNB
+	GNBID identity.GNBID
+}
+
+// NFType returns the NF type of the NF, Type or the upper-cased Name.
+func (s Spec) NFType() string {
+	if s.Type != "" {
+		return s.Type
+	}
+	return strings.ToUpper(s.Name)
+}
+
+// Var returns the name of the environment variable suffix of the NF, such
+// as QS_UDM_HTTP_PORT for HTTP_PORT.
+func (s Spec) Var(suffix string) string {
+	return "QS_" + strings.ToUpper(s.Name) + "_" + suffix
+}
+
+// buildVersion returns the version of the main module the NF was built
+// from, devel for a build of the working tree.
+func buildVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok || info.Main.Version == "" || info.Main.Version == "(devel)" {
+		return "devel"
+	}
+	return info.Main.Version
+}
+
+func (nf *NF) loadConfig() {
+	s, cfg := nf.Spec, &nf.Config
+	cfg.NameSpace = nf.String(s.Var(envNameSpace), defNameSpace)
+	cfg.ServiceName = nf.String(s.Var(envServiceName), s.Name)
+	cfg.InstanceID = nf.String(s.Var(envInstanceID), "")
+	if cfg.InstanceID == "" {
+		// the pod name when running in Kubernetes
+		cfg.InstanceID, _ = os.Hostname()
+	}
+	if id := nf.String(s.Var(envNFInstanceID), ""); id != "" {
+		var err error
+		if cfg.NFInstanceID, err = identity.ParseNFInstanceID(id); err != nil {
+			level.Error(nf.Logger).Log("env", s.Var(envNFInstanceID), "error", err)
+		}
+	}
+	if cfg.NFInstanceID.IsZero() {
+		cfg.NFInstanceID = identity.NameBased(cfg.InstanceID + "." + cfg.ServiceName + "." + cfg.NameSpace)
+	}
+	cfg.Version = nf.String(s.Var(envVersion), buildVersion())
+	cfg.LogLevel = nf.String(s.Var(envLogLevel), defLogLevel)
+	cfg.LogSample = nf.Int(EnvLogSample, defLogSample)
+	cfg.GRPCKeepalive = nf.Duration(EnvGRPCKeepalive, defGRPCKeepalive)
+	// the receive and send sizes default to QS_GRPC_MAX_MSG_SIZE
+	grpcMaxMsgSize := nf.String(EnvGRPCMaxMsgSize, defGRPCMaxMsgSize)
+	cfg.GRPCMaxRecvMsgSize = nf.Int(EnvGRPCMaxRecvMsgSize, grpcMaxMsgSize)
+	cfg.GRPCMaxSendMsgSize = nf.Int(EnvGRPCMaxSendMsgSize, grpcMaxMsgSize)
+	cfg.ServiceHost = nf.String(s.Var(envServiceHost), defServiceHost)
+	cfg.HTTPPort = nf.String(s.Var(envHTTPPort), s.HTTPPort)
+	cfg.GRPCPort = nf.String(s.Var(envGRPCPort), s.GRPCPort)
+	cfg.AdminPort = nf.String(s.Var(envAdminPort), "")
+	cfg.ZipkinV2URL = nf.String(EnvZipkinV2URL, "")
+	cfg.TraceSampling.Rate = nf.Float(EnvTraceRate, defTraceRate)
+	cfg.TraceSampling.RateLimit = nf.Float(EnvTraceLimit, defTraceLimit)
+	traceMethods, err := sampling.ParseMethods(nf.String(EnvTraceMethods, ""))
+	if err != nil {
+		level.Error(nf.Logger).Log("env", EnvTraceMethods, "error", err)
+	}
+	cfg.TraceSampling.Methods = traceMethods
+	cfg.TraceSampling.Tail = nf.String(EnvTraceKeep, "")
+	if _, err := sampling.ParseTail(cfg.TraceSampling.Tail); err != nil {
+		level.Error(nf.Logger).Log("env", EnvTraceKeep, "error", err)
+		cfg.TraceSampling.Tail = ""
+	}
+	if after := nf.String(s.Var(envDrainAfter), ""); after != "" {
+		cfg.DrainAfter = strings.Split(after, ",")
+	}
+	cfg.DrainTimeout = nf.Duration(s.Var(envDrainTimeout), defDrainTimeout)
+	slos, err := slo.Parse(nf.String(s.Var(envSLOs), defSLOs))
+	if err != nil {
+		level.Error(nf.Logger).Log("env", s.Var(envSLOs), "error", err)
+		slos, _ = slo.Parse(defSLOs)
+	}
+	cfg.SLOs = slos
+	cfg.UETraceRedisAddr = nf.String(EnvUETraceRedisAddr, "")
+	cfg.PrivacyKeys = nf.String(EnvPrivacyKeys, "")
+	cfg.PrivacyReveal = nf.Bool(EnvPrivacyReveal, defPrivacyReveal)
+	cfg.KubeAPIURL = nf.String(EnvKubeAPIURL, "")
+	cfg.DiscoveryRefresh = nf.Duration(EnvDiscoveryRefresh, defDiscoveryRefresh)
+	cfg.DiscoveryVersionLabel = nf.String(EnvDiscoveryVersionLabel, defDiscoveryVersionLabel)
+	cfg.OTLPEndpoint = nf.String(EnvOTLPEndpoint, "")
+	cfg.OTLPInterval = nf.Duration(EnvOTLPInterval, defOTLPInterval)
+	cfg.ProfilingURL = nf.String(EnvProfilingURL, "")
+	cfg.ProfilingPeriod = nf.Duration(EnvProfilingPeriod, defProfilingPeriod)
+
+	if s.Compression {
+		cfg.GRPCCompression = nf.String(EnvGRPCCompression, defGRPCCompression)
+		if err := compression.Check(cfg.GRPCCompression); err != nil {
+			level.Error(nf.Logger).Log("env", EnvGRPCCompression, "error", err)
+			cfg.GRPCCompression = compression.Identity
+		}
+		cfg.GRPCCompressionLevel = nf.Int(EnvGRPCCompressionLevel, defGRPCCompressionLevel)
+		methods, err := compression.ParseMethods(nf.String(EnvGRPCCompressionMethods, defGRPCCompressionMethods))
+		if err != nil {
+			level.Error(nf.Logger).Log("env", EnvGRPCCompressionMethods, "error", err)
+		}
+		cfg.GRPCCompressionMethods = methods
+	}
+	if s.Capture {
+		cfg.CaptureBuffer = nf.Int(EnvCaptureBuffer, defCaptureBuffer)
+		cfg.CaptureFile = nf.String(EnvCaptureFile, "")
+	}
+	if s.Limits {
+		cfg.CallerRate = nf.Float(s.Var(envCallerRate), defCallerRate)
+		cfg.CallerBurst = nf.Int(s.Var(envCallerBurst), defCallerBurst)
+	}
+	if s.Admission {
+		cfg.MaxConcurrent = nf.Int(s.Var(envMaxConcurrent), defMaxConcurrent)
+		cfg.AdmissionQueue = nf.Int(s.Var(envAdmissionQueue), defAdmissionQueue)
+	}
+	if s.PLMNs {
+		served, err := plmn.ParseList(nf.String(s.Var(envServedPLMNs), ""))
+		if err != nil {
+			level.Error(nf.Logger).Log("env", s.Var(envServedPLMNs), "error", err)
+		}
+		cfg.ServedPLMNs = served
+	}
+	if s.Redis {
+		cfg.RedisAddr = nf.String(s.Var(envRedisAddr), "")
+	}
+	if s.Postgres {
+		cfg.PostgresURL = nf.String(s.Var(envPostgresURL), "")
+		cfg.PostgresMaxConns = nf.Int(s.Var(envPostgresConns), defPostgresConns)
+	}
+	if s.Etcd {
+		if endpoints := nf.String(s.Var(envEtcdEndpoints), ""); endpoints != "" {
+			cfg.EtcdEndpoints = strings.Split(endpoints, ",")
+		}
+	}
+	if s.GUAMIs {
+		guamis, err := identity.ParseGUAMIList(nf.String(s.Var(envGUAMIs), ""))
+		if err != nil {
+			level.Error(nf.Logger).Log("env", s.Var(envGUAMIs), "error", err)
+		}
+		cfg.GUAMIs = guamis
+	}
+	if s.GNB {
+		if id := nf.String(s.Var(envGNBID), ""); id != "" {
+			var err error
+			if cfg.GNBID, err = identity.ParseGNBID(id); err != nil {
+				level.Error(nf.Logger).Log("env", s.Var(envGNBID), "error", err)
+			}
+		}
+	}
+}
+
+// String returns the environment variable key, or fallback when it is
+// unset or empty.
+func (nf *NF) String(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+// Int returns the environment variable key, or fallback when it is unset
+// or empty. The errors are logged and leave zero.
+func (nf *NF) Int(key, fallback string) int {
+	v, err := strconv.Atoi(nf.String(key, fallback))
+	if err != nil {
+		level.Error(nf.Logger).Log("env", key, "error", err)
+	}
+	return v
+}
+
+// Uint is Int for an unsigned integer.
+func (nf *NF) Uint(key, fallback string) uint64 {
+	v, err := strconv.ParseUint(nf.String(key, fallback), 10, 64)
+	if err != nil {
+		level.Error(nf.Logger).Log("env", key, "error", err)
+	}
+	return v
+}
+
+// Float is Int for a float.
+func (nf *NF) Float(key, fallback string) float64 {
+	v, err := strconv.ParseFloat(nf.String(key, fallback), 64)
+	if err != nil {
+		level.Error(nf.Logger).Log("env", key, "error", err)
+	}
+	return v
+}
+
+// Bool is Int for a boolean.
+func (nf *NF) Bool(key, fallback string) bool {
+	v, err := strconv.ParseBool(nf.String(key, fallback))
+	if err != nil {
+		level.Error(nf.Logger).Log("env", key, "error", err)
+	}
+	return v
+}
+
+// Duration is Int for a duration.
+func (nf *NF) Duration(key, fallback string) time.Duration {
+	v, err := time.ParseDuration(nf.String(key, fallback))
+	if err != nil {
+		level.Error(nf.Logger).Log("env", key, "error", err)
+	}
+	return v
+}
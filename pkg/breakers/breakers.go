@@ -0,0 +1,75 @@
+// Package breakers keeps track of the circuit breakers of a process so that
+// their state can be reported. Breakers are created through New instead of
+// gobreaker.NewCircuitBreaker and are registered in a process wide list, the
+// way expvar registers its variables.
+package breakers
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sony/gobreaker"
+)
+
+// State is a point in time view of a circuit breaker.
+type State struct {
+	Name  string `json:"name"`
+	State string `json:"state"`
+	// Since is the time of the last state change, zero when the breaker
+	// never left the closed state.
+	Since time.Time `json:"since,omitempty"`
+}
+
+type breaker struct {
+	cb *gobreaker.CircuitBreaker
+
+	mtx   sync.Mutex
+	since time.Time
+}
+
+var (
+	mtx      sync.Mutex
+	breakers []*breaker
+)
+
+// New returns a circuit breaker configured by st and registers it. The
+// OnStateChange hook of st is kept.
+func New(st gobreaker.Settings) *gobreaker.CircuitBreaker {
+	b := &breaker{}
+	onStateChange := st.OnStateChange
+	st.OnStateChange = func(name string, from, to gobreaker.State) {
+		b.mtx.Lock()
+		b.since = time.Now()
+		b.mtx.Unlock()
+		if onStateChange != nil {
+			onStateChange(name, from, to)
+		}
+	}
+	b.cb = gobreaker.NewCircuitBreaker(st)
+
+	mtx.Lock()
+	defer mtx.Unlock()
+	breakers = append(breakers, b)
+	return b.cb
+}
+
+// States returns the state of every registered breaker, sorted by name.
+func States() []State {
+	mtx.Lock()
+	list := append([]*breaker(nil), breakers...)
+	mtx.Unlock()
+
+	res := make([]State, 0, len(list))
+	for _, b := range list {
+		// State first: it may move an open breaker to half-open, which
+		// updates since
+		state := b.cb.State()
+		b.mtx.Lock()
+		since := b.since
+		b.mtx.Unlock()
+		res = append(res, State{Name: b.cb.Name(), State: state.String(), Since: since})
+	}
+	sort.SliceStable(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res
+}
@@ -0,0 +1,65 @@
+// Package bufpool pools the buffers the codecs of the hot paths encode
+// their messages in: the NAS messages of package n2, the RRC containers of
+// package f1 and the NWu messages of package nwu. A message then costs the
+// copy it ends in, rather than the buffers it is built in and the copies
+// between them.
+//
+//	b := bufpool.Get()
+//	defer bufpool.Put(b)
+//	b.WriteString(name)
+//	b.WriteByte(' ')
+//	if err := b.JSON(ies); err != nil {
+//		return nil, err
+//	}
+//	return append(dst, b.Bytes()...), nil
+package bufpool
+
+import (
+	"bytes"
+	"encoding/json"
+	"sync"
+)
+
+// MaxSize is the capacity above which a buffer is dropped rather than put
+// back, so that a large message does not keep its memory in the pool.
+const MaxSize = 64 << 10
+
+// Buffer is a buffer of the pool, with its JSON encoder.
+type Buffer struct {
+	bytes.Buffer
+	enc *json.Encoder
+}
+
+var pool = sync.Pool{
+	New: func() interface{} {
+		b := new(Buffer)
+		b.enc = json.NewEncoder(&b.Buffer)
+		return b
+	},
+}
+
+// Get returns an empty buffer of the pool.
+func Get() *Buffer {
+	return pool.Get().(*Buffer)
+}
+
+// Put resets b and puts it back into the pool. Neither b nor the bytes it
+// returned are to be used afterwards.
+func Put(b *Buffer) {
+	if b.Cap() > MaxSize {
+		return
+	}
+	b.Reset()
+	pool.Put(b)
+}
+
+// JSON appends the JSON encoding of v, as json.Marshal encodes it, to b.
+// Nothing is appended when v cannot be encoded.
+func (b *Buffer) JSON(v interface{}) error {
+	if err := b.enc.Encode(v); err != nil {
+		return err
+	}
+	// the newline json.Encoder ends every value with
+	b.Truncate(b.Len() - 1)
+	return nil
+}
@@ -0,0 +1,303 @@
+// Package cache serves repeated reads from memory, so that a registration
+// storm does not read the same NF profiles or subscriber data over and over.
+// Its endpoint middleware is read-through: the responses of a method are kept
+// for a TTL and, past it, for a stale-while-revalidate period during which
+// the stale response is answered while a fresh one is read in the background.
+// The concurrent misses of a key are served by a single call.
+//
+// The cache does not know what the writes change: they invalidate it through
+// Invalidate, InvalidatePrefix or the Invalidator middleware. A read that was
+// in flight when the cache was invalidated is answered but not kept.
+package cache
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/go-kit/kit/metrics"
+	"github.com/go-kit/kit/metrics/discard"
+)
+
+// Default values of the options.
+const (
+	DefaultTTL            = 30 * time.Second
+	DefaultMaxEntries     = 10000
+	DefaultRefreshTimeout = 10 * time.Second
+)
+
+// The results counted by the counter of the cache.
+const (
+	resultHit   = "hit"
+	resultStale = "stale"
+	resultMiss  = "miss"
+)
+
+// Option sets an optional parameter of a Cache.
+type Option func(*Cache)
+
+// TTL sets how long a response is answered as fresh.
+func TTL(d time.Duration) Option {
+	return func(c *Cache) { c.ttl = d }
+}
+
+// StaleWhileRevalidate sets how long past its TTL a response is still
+// answered while it is refreshed. Zero, the default, reads the expired
+// responses again before answering.
+func StaleWhileRevalidate(d time.Duration) Option {
+	return func(c *Cache) { c.swr = d }
+}
+
+// MaxEntries caps the number of responses kept, the least recently used
+// being dropped first.
+func MaxEntries(n int) Option {
+	return func(c *Cache) { c.maxEntries = n }
+}
+
+// RefreshTimeout bounds the background reads of the stale responses.
+func RefreshTimeout(d time.Duration) Option {
+	return func(c *Cache) { c.refreshTimeout = d }
+}
+
+// Counter sets the counter of the reads, labelled with the method and the
+// result: hit, stale or miss.
+func Counter(counter metrics.Counter) Option {
+	return func(c *Cache) { c.counter = counter }
+}
+
+// Logger sets the logger of the failed background reads.
+func Logger(logger log.Logger) Option {
+	return func(c *Cache) { c.logger = logger }
+}
+
+// KeyFunc returns the key of a request within its method, and false for the
+// requests that are not to be cached.
+type KeyFunc func(request interface{}) (key string, ok bool)
+
+// JSONKey keys the requests by their JSON encoding.
+func JSONKey(request interface{}) (string, bool) {
+	b, err := json.Marshal(request)
+	if err != nil {
+		return "", false
+	}
+	return string(b), true
+}
+
+// Cache keeps the responses of the methods it is the middleware of. It is
+// safe for concurrent use.
+type Cache struct {
+	ttl            time.Duration
+	swr            time.Duration
+	maxEntries     int
+	refreshTimeout time.Duration
+	counter        metrics.Counter
+	logger         log.Logger
+
+	mtx     sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+	calls   map[string]*call
+	// gen is bumped by every invalidation, so that the reads started
+	// before are not kept.
+	gen uint64
+}
+
+type entry struct {
+	key      string
+	response interface{}
+	stored   time.Time
+}
+
+// call is a read in flight, that the misses of its key wait for.
+type call struct {
+	done     chan struct{}
+	gen      uint64
+	response interface{}
+	err      error
+}
+
+// New returns an empty cache.
+func New(options ...Option) *Cache {
+	c := &Cache{
+		ttl:            DefaultTTL,
+		maxEntries:     DefaultMaxEntries,
+		refreshTimeout: DefaultRefreshTimeout,
+		counter:        discard.NewCounter(),
+		logger:         log.NewNopLogger(),
+		entries:        map[string]*list.Element{},
+		lru:            list.New(),
+		calls:          map[string]*call{},
+	}
+	for _, o := range options {
+		o(c)
+	}
+	return c
+}
+
+// Middleware returns the read-through middleware of method, whose requests
+// are keyed by key. The failed reads are not kept.
+func (c *Cache) Middleware(method string, key KeyFunc) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			k, ok := key(request)
+			if !ok {
+				return next(ctx, request)
+			}
+			k = method + "/" + k
+
+			c.mtx.Lock()
+			if el, ok := c.entries[k]; ok {
+				e := el.Value.(*entry)
+				age := time.Since(e.stored)
+				if age < c.ttl {
+					c.lru.MoveToFront(el)
+					c.mtx.Unlock()
+					c.counter.With("method", method, "result", resultHit).Add(1)
+					return e.response, nil
+				}
+				if age < c.ttl+c.swr {
+					c.lru.MoveToFront(el)
+					if _, inFlight := c.calls[k]; !inFlight {
+						cl := c.startLocked(k)
+						go c.refresh(detached{ctx}, method, k, next, request, cl)
+					}
+					c.mtx.Unlock()
+					c.counter.With("method", method, "result", resultStale).Add(1)
+					return e.response, nil
+				}
+				c.removeLocked(el)
+			}
+			c.counter.With("method", method, "result", resultMiss).Add(1)
+			if cl, ok := c.calls[k]; ok {
+				c.mtx.Unlock()
+				select {
+				case <-cl.done:
+					return cl.response, cl.err
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				}
+			}
+			cl := c.startLocked(k)
+			c.mtx.Unlock()
+
+			cl.response, cl.err = next(ctx, request)
+			c.finish(k, cl)
+			return cl.response, cl.err
+		}
+	}
+}
+
+// Invalidator returns a middleware that calls invalidate with the requests
+// of a method that writes, once they are served, failed or not: a failed
+// write may have been applied all the same.
+func (c *Cache) Invalidator(invalidate func(c *Cache, request interface{})) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			response, err := next(ctx, request)
+			invalidate(c, request)
+			return response, err
+		}
+	}
+}
+
+// Invalidate drops the response of key of method.
+func (c *Cache) Invalidate(method, key string) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.gen++
+	if el, ok := c.entries[method+"/"+key]; ok {
+		c.removeLocked(el)
+	}
+}
+
+// InvalidatePrefix drops the responses of method whose key starts with
+// prefix, all of them for an empty prefix.
+func (c *Cache) InvalidatePrefix(method, prefix string) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.gen++
+	prefix = method + "/" + prefix
+	for k, el := range c.entries {
+		if strings.HasPrefix(k, prefix) {
+			c.removeLocked(el)
+		}
+	}
+}
+
+// Purge drops every response.
+func (c *Cache) Purge() {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.gen++
+	c.entries = map[string]*list.Element{}
+	c.lru.Init()
+}
+
+// Len returns the number of responses kept, the expired ones included until
+// they are read again or dropped for room.
+func (c *Cache) Len() int {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return c.lru.Len()
+}
+
+func (c *Cache) startLocked(k string) *call {
+	cl := &call{done: make(chan struct{}), gen: c.gen}
+	c.calls[k] = cl
+	return cl
+}
+
+// finish keeps the response of cl, unless it failed or the cache was
+// invalidated meanwhile, and wakes up the reads waiting for it.
+func (c *Cache) finish(k string, cl *call) {
+	c.mtx.Lock()
+	delete(c.calls, k)
+	if cl.err == nil && cl.gen == c.gen {
+		if el, ok := c.entries[k]; ok {
+			e := el.Value.(*entry)
+			e.response, e.stored = cl.response, time.Now()
+			c.lru.MoveToFront(el)
+		} else {
+			c.entries[k] = c.lru.PushFront(&entry{key: k, response: cl.response, stored: time.Now()})
+			for c.maxEntries > 0 && c.lru.Len() > c.maxEntries {
+				c.removeLocked(c.lru.Back())
+			}
+		}
+	}
+	c.mtx.Unlock()
+	close(cl.done)
+}
+
+func (c *Cache) removeLocked(el *list.Element) {
+	delete(c.entries, el.Value.(*entry).key)
+	c.lru.Remove(el)
+}
+
+// refresh reads a stale response again. The stale response stays until the
+// read succeeds.
+func (c *Cache) refresh(ctx context.Context, method, k string, next endpoint.Endpoint, request interface{}, cl *call) {
+	ctx, cancel := context.WithTimeout(ctx, c.refreshTimeout)
+	defer cancel()
+	cl.response, cl.err = next(ctx, request)
+	if cl.err != nil {
+		level.Debug(c.logger).Log("cache", "refresh", "method", method, "err", cl.err)
+	}
+	c.finish(k, cl)
+}
+
+// detached carries the values of a request's context, its trace and
+// procedure metadata, but neither its deadline nor its cancellation, for the
+// background reads that outlive the request.
+type detached struct {
+	context.Context
+}
+
+func (detached) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (detached) Done() <-chan struct{}       { return nil }
+func (detached) Err() error                  { return nil }
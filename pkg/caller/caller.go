@@ -0,0 +1,134 @@
+// Package caller identifies the caller of a request, the NF instance or gNB
+// on the other end of the connection, so that it can be held to limits of
+// its own. The identity is, in order of preference, the first URI or DNS
+// subject alternative name of a verified TLS client certificate, the NF
+// instance ID the caller declares in the gRPC metadata or HTTP header, and
+// the IP address of the peer. The declared NF instance ID is only trusted
+// from a peer of a verified TLS client certificate, or in the process, so
+// that a peer cannot get fresh limits by declaring another one.
+package caller
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+
+	grpctransport "github.com/go-kit/kit/transport/grpc"
+	httptransport "github.com/go-kit/kit/transport/http"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/kitx/inproc"
+)
+
+// MetadataKey is the gRPC metadata key carrying the NF instance ID of the
+// caller, HeaderKey the HTTP header.
+const (
+	MetadataKey = "x-nf-instance-id"
+	HeaderKey   = "X-Nf-Instance-Id"
+)
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying the identity of the caller.
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the identity of the caller carried by ctx, if any.
+func FromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(contextKey{}).(string)
+	return id, ok && id != ""
+}
+
+// GRPCToContext puts the identity of the caller of a gRPC request in the
+// context. Use it as a ServerBefore option.
+func GRPCToContext() grpctransport.ServerRequestFunc {
+	return func(ctx context.Context, md metadata.MD) context.Context {
+		p, _ := peer.FromContext(ctx)
+		trusted := p == nil
+		if p != nil {
+			if info, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+				if id := certificateID(info.State); id != "" {
+					return NewContext(ctx, id)
+				}
+				trusted = verified(info.State)
+			}
+			if _, ok := p.Addr.(inproc.Addr); ok {
+				trusted = true
+			}
+		}
+		if v := md.Get(MetadataKey); trusted && len(v) > 0 && v[0] != "" {
+			return NewContext(ctx, v[0])
+		}
+		if p != nil && p.Addr != nil {
+			return NewContext(ctx, host(p.Addr.String()))
+		}
+		return ctx
+	}
+}
+
+// HTTPToContext puts the identity of the caller of an HTTP request in the
+// context. Use it as a ServerBefore option.
+func HTTPToContext() httptransport.RequestFunc {
+	return func(ctx context.Context, r *http.Request) context.Context {
+		if r.TLS != nil {
+			if id := certificateID(*r.TLS); id != "" {
+				return NewContext(ctx, id)
+			}
+		}
+		if id := r.Header.Get(HeaderKey); id != "" && r.TLS != nil && verified(*r.TLS) {
+			return NewContext(ctx, id)
+		}
+		if r.RemoteAddr != "" {
+			return NewContext(ctx, host(r.RemoteAddr))
+		}
+		return ctx
+	}
+}
+
+// UnaryClientInterceptor returns a gRPC client interceptor declaring id as
+// the NF instance ID of every call, unless the call already declares one, as
+// calls forwarded on behalf of another caller do.
+func UnaryClientInterceptor(id string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if md, ok := metadata.FromOutgoingContext(ctx); !ok || len(md.Get(MetadataKey)) == 0 {
+			ctx = metadata.AppendToOutgoingContext(ctx, MetadataKey, id)
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// verified tells whether the client certificate of state is verified.
+func verified(state tls.ConnectionState) bool {
+	return len(state.VerifiedChains) > 0 && len(state.PeerCertificates) > 0
+}
+
+// certificateID returns the first URI SAN, a SPIFFE ID for instance, or else
+// the first DNS SAN of the verified client certificate of state.
+func certificateID(state tls.ConnectionState) string {
+	if !verified(state) {
+		return ""
+	}
+	cert := state.PeerCertificates[0]
+	if len(cert.URIs) > 0 {
+		return cert.URIs[0].String()
+	}
+	if len(cert.DNSNames) > 0 {
+		return cert.DNSNames[0]
+	}
+	return ""
+}
+
+// host returns the host of a host:port address, addr itself when it has no
+// port.
+func host(addr string) string {
+	h, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return h
+}
@@ -0,0 +1,71 @@
+package caller
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/http/httptest"
+	"testing"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/kitx/inproc"
+)
+
+// verifiedState is the state of a verified client certificate of no SAN.
+var verifiedState = tls.ConnectionState{
+	PeerCertificates: []*x509.Certificate{{}},
+	VerifiedChains:   [][]*x509.Certificate{{{}}},
+}
+
+func TestGRPCToContext(t *testing.T) {
+	addr := &net.TCPAddr{IP: net.IPv4(10, 0, 0, 7), Port: 40000}
+	for _, tt := range []struct {
+		name string
+		peer *peer.Peer
+		want string
+	}{
+		{"plaintext", &peer.Peer{Addr: addr}, "10.0.0.7"},
+		{"unverified TLS", &peer.Peer{Addr: addr, AuthInfo: credentials.TLSInfo{}}, "10.0.0.7"},
+		{"mutual TLS", &peer.Peer{Addr: addr, AuthInfo: credentials.TLSInfo{State: verifiedState}}, "nf-1"},
+		{"in process", &peer.Peer{Addr: inproc.Addr{}}, "nf-1"},
+		{"no peer", nil, "nf-1"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.peer != nil {
+				ctx = peer.NewContext(ctx, tt.peer)
+			}
+			ctx = GRPCToContext()(ctx, metadata.Pairs(MetadataKey, "nf-1"))
+			if id, _ := FromContext(ctx); id != tt.want {
+				t.Errorf("caller %q, want %q", id, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTTPToContext(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		tls  *tls.ConnectionState
+		want string
+	}{
+		{"plaintext", nil, "10.0.0.7"},
+		{"unverified TLS", &tls.ConnectionState{}, "10.0.0.7"},
+		{"mutual TLS", &verifiedState, "nf-1"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/", nil)
+			r.RemoteAddr = "10.0.0.7:40000"
+			r.Header.Set(HeaderKey, "nf-1")
+			r.TLS = tt.tls
+			ctx := HTTPToContext()(context.Background(), r)
+			if id, _ := FromContext(ctx); id != tt.want {
+				t.Errorf("caller %q, want %q", id, tt.want)
+			}
+		})
+	}
+}
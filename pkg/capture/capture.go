@@ -0,0 +1,235 @@
+// Package capture records the traffic of the endpoints of a service so that
+// it can be replayed against another instance with cmd/replay. Every call is
+// one Record: the decoded request and response in their JSON form, when it
+// happened and in which trace. The records are kept in a Ring, served on the
+// admin port, or appended to a file, both as JSON lines.
+//
+// The records hold the requests as sent, subscriber identities included:
+// capture is off unless configured and must stay on the admin port, or
+// records them pseudonymized (see Pseudonymize), which then replay against
+// no real subscriber.
+package capture
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/openzipkin/zipkin-go"
+	"google.golang.org/grpc/status"
+)
+
+// Record is a call of an endpoint. The method of the endpoint is also the
+// path of its HTTP JSON transport.
+type Record struct {
+	Time     time.Time       `json:"time"`
+	Method   string          `json:"method"`
+	TraceID  string          `json:"trace_id,omitempty"`
+	Elapsed  time.Duration   `json:"elapsed_ns"`
+	Request  json.RawMessage `json:"request"`
+	Response json.RawMessage `json:"response,omitempty"`
+	// Code and Err are the gRPC code and message of the error of the call.
+	Code string `json:"code,omitempty"`
+	Err  string `json:"err,omitempty"`
+}
+
+// Recorder keeps records. Record is called concurrently.
+type Recorder interface {
+	Record(r Record)
+}
+
+// Middleware records every call of the endpoint method in rec. A nil rec
+// records nothing.
+func Middleware(rec Recorder, method string) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		if rec == nil {
+			return next
+		}
+		return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+			r := Record{Time: time.Now(), Method: method}
+			if span := zipkin.SpanFromContext(ctx); span != nil && !span.Context().TraceID.Empty() {
+				r.TraceID = span.Context().TraceID.String()
+			}
+			defer func() {
+				r.Elapsed = time.Since(r.Time)
+				r.Request, _ = json.Marshal(request)
+				if err != nil {
+					st, _ := status.FromError(err)
+					r.Code, r.Err = st.Code().String(), st.Message()
+				} else {
+					r.Response, _ = json.Marshal(response)
+				}
+				rec.Record(r)
+			}()
+			return next(ctx, request)
+		}
+	}
+}
+
+// Multi returns a Recorder recording in every one of recs, nil when there is
+// none.
+func Multi(recs ...Recorder) Recorder {
+	switch len(recs) {
+	case 0:
+		return nil
+	case 1:
+		return recs[0]
+	}
+	return multi(recs)
+}
+
+type multi []Recorder
+
+// Pseudonymize returns rec recording the records with the string values of
+// the fields of keys, in their requests and responses at any depth,
+// replaced by their pseudonym.
+func Pseudonymize(rec Recorder, pseudonym func(id string) string, keys ...string) Recorder {
+	if rec == nil {
+		return nil
+	}
+	p := pseudonymizer{next: rec, pseudonym: pseudonym, keys: make(map[string]bool)}
+	for _, k := range keys {
+		p.keys[k] = true
+	}
+	return p
+}
+
+type pseudonymizer struct {
+	next      Recorder
+	pseudonym func(id string) string
+	keys      map[string]bool
+}
+
+func (p pseudonymizer) Record(r Record) {
+	r.Request, r.Response = p.message(r.Request), p.message(r.Response)
+	p.next.Record(r)
+}
+
+// message returns the message m pseudonymized, m itself when it is not
+// JSON.
+func (p pseudonymizer) message(m json.RawMessage) json.RawMessage {
+	var v interface{}
+	if len(m) == 0 || json.Unmarshal(m, &v) != nil {
+		return m
+	}
+	b, err := json.Marshal(p.value(v))
+	if err != nil {
+		return m
+	}
+	return b
+}
+
+func (p pseudonymizer) value(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			if s, ok := e.(string); ok && p.keys[k] {
+				v[k] = p.pseudonym(s)
+			} else {
+				v[k] = p.value(e)
+			}
+		}
+	case []interface{}:
+		for i, e := range v {
+			v[i] = p.value(e)
+		}
+	}
+	return v
+}
+
+func (m multi) Record(r Record) {
+	for _, rec := range m {
+		rec.Record(r)
+	}
+}
+
+// Ring keeps the last records in memory.
+type Ring struct {
+	mtx     sync.Mutex
+	records []Record
+	next    int
+	full    bool
+}
+
+// NewRing returns a Ring keeping the last size records.
+func NewRing(size int) *Ring {
+	return &Ring{records: make([]Record, size)}
+}
+
+// Record implements Recorder.
+func (g *Ring) Record(r Record) {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+	if len(g.records) == 0 {
+		return
+	}
+	g.records[g.next] = r
+	g.next = (g.next + 1) % len(g.records)
+	if g.next == 0 {
+		g.full = true
+	}
+}
+
+// Records returns the records kept, oldest first.
+func (g *Ring) Records() []Record {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+	if !g.full {
+		return append([]Record(nil), g.records[:g.next]...)
+	}
+	return append(append([]Record(nil), g.records[g.next:]...), g.records[:g.next]...)
+}
+
+// Stats returns the number of records kept, for the admin pools.
+func (g *Ring) Stats() map[string]int {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+	kept := g.next
+	if g.full {
+		kept = len(g.records)
+	}
+	return map[string]int{"size": len(g.records), "kept": kept}
+}
+
+// Writer writes the records to an io.Writer as JSON lines.
+type Writer struct {
+	logger log.Logger
+
+	mtx sync.Mutex
+	enc *json.Encoder
+}
+
+// NewWriter returns a Writer writing to w, logging the failed writes.
+func NewWriter(w io.Writer, logger log.Logger) *Writer {
+	return &Writer{logger: logger, enc: json.NewEncoder(w)}
+}
+
+// Record implements Recorder.
+func (w *Writer) Record(r Record) {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	if err := w.enc.Encode(r); err != nil {
+		level.Error(w.logger).Log("capture", "write", "method", r.Method, "err", err)
+	}
+}
+
+// Read returns the records of r, JSON lines as written by a Writer or served
+// by Handler.
+func Read(r io.Reader) ([]Record, error) {
+	var records []Record
+	dec := json.NewDecoder(r)
+	for {
+		var rec Record
+		if err := dec.Decode(&rec); err == io.EOF {
+			return records, nil
+		} else if err != nil {
+			return records, err
+		}
+		records = append(records, rec)
+	}
+}
@@ -0,0 +1,25 @@
+package capture
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Path is where services mount Handler on their admin server.
+const Path = "/admin/capture"
+
+// Handler serves the records of g as JSON lines, oldest first, ready for
+// cmd/replay. The ?method= query parameter keeps the records of a method.
+func Handler(g *Ring) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		method := req.URL.Query().Get("method")
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		enc := json.NewEncoder(w)
+		for _, r := range g.Records() {
+			if method != "" && r.Method != method {
+				continue
+			}
+			enc.Encode(r)
+		}
+	})
+}
@@ -0,0 +1,349 @@
+// Package clock abstracts the time of the time-dependent logic, the
+// procedure timers, the TTLs, the rate limits and the durations logged, so
+// that it can run on a Fake clock in the tests, without sleeps, and on a
+// Scaled clock in the simulations, faster than real time.
+//
+// Real is the clock of the time package, the default of every component
+// taking a Clock. A Fake stands still until advanced:
+//
+//	c := clock.NewFake(time.Unix(0, 0))
+//	wheel := timers.NewWheel(timers.Clock(c))
+//	ue := wheel.Context(supi)
+//	ue.Start(timers.T3560, 0, expired)
+//	c.Advance(6 * time.Second) // T3560 expires
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock tells the time and waits for it.
+type Clock interface {
+	Now() time.Time
+	Since(t time.Time) time.Duration
+	// After waits for d to elapse and sends the time on the channel.
+	After(d time.Duration) <-chan time.Time
+	Sleep(d time.Duration)
+	NewTimer(d time.Duration) Timer
+	NewTicker(d time.Duration) Ticker
+}
+
+// Timer is a time.Timer of a Clock.
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+	Reset(d time.Duration) bool
+}
+
+// Ticker is a time.Ticker of a Clock.
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// Real is the clock of the time package.
+var Real Clock = realClock{}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) Since(t time.Time) time.Duration        { return time.Since(t) }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+func (realClock) Sleep(d time.Duration)                  { time.Sleep(d) }
+
+func (realClock) NewTimer(d time.Duration) Timer {
+	return realTimer{time.NewTimer(d)}
+}
+
+func (realClock) NewTicker(d time.Duration) Ticker {
+	return realTicker{time.NewTicker(d)}
+}
+
+type realTimer struct{ t *time.Timer }
+
+func (t realTimer) C() <-chan time.Time        { return t.t.C }
+func (t realTimer) Stop() bool                 { return t.t.Stop() }
+func (t realTimer) Reset(d time.Duration) bool { return t.t.Reset(d) }
+
+type realTicker struct{ t *time.Ticker }
+
+func (t realTicker) C() <-chan time.Time { return t.t.C }
+func (t realTicker) Stop()               { t.t.Stop() }
+
+// Or returns c, or Real when c is nil.
+func Or(c Clock) Clock {
+	if c == nil {
+		return Real
+	}
+	return c
+}
+
+// Scaled returns a clock running speed times faster than real time from
+// now on: its timers and tickers fire after their duration divided by
+// speed. A speed of zero or less is taken as 1.
+func Scaled(speed float64) Clock {
+	if speed <= 0 {
+		speed = 1
+	}
+	now := time.Now()
+	return &scaled{speed: speed, origin: now, real: now}
+}
+
+type scaled struct {
+	speed        float64
+	origin, real time.Time
+}
+
+func (s *scaled) Now() time.Time {
+	return s.origin.Add(time.Duration(float64(time.Since(s.real)) * s.speed))
+}
+
+func (s *scaled) Since(t time.Time) time.Duration { return s.Now().Sub(t) }
+
+func (s *scaled) After(d time.Duration) <-chan time.Time { return s.NewTimer(d).C() }
+
+func (s *scaled) Sleep(d time.Duration) { time.Sleep(s.realDuration(d)) }
+
+func (s *scaled) NewTimer(d time.Duration) Timer {
+	t := &scaledTimer{s: s, c: make(chan time.Time, 1)}
+	t.t = time.AfterFunc(s.realDuration(d), t.fire)
+	return t
+}
+
+func (s *scaled) NewTicker(d time.Duration) Ticker {
+	t := &scaledTicker{s: s, c: make(chan time.Time, 1), t: time.NewTicker(s.realDuration(d)), quit: make(chan struct{})}
+	go t.loop()
+	return t
+}
+
+// realDuration returns the real time the scaled duration d takes.
+func (s *scaled) realDuration(d time.Duration) time.Duration {
+	r := time.Duration(float64(d) / s.speed)
+	if r <= 0 && d > 0 {
+		r = 1
+	}
+	return r
+}
+
+type scaledTimer struct {
+	s *scaled
+	c chan time.Time
+	t *time.Timer
+}
+
+func (t *scaledTimer) fire() {
+	select {
+	case t.c <- t.s.Now():
+	default:
+	}
+}
+
+func (t *scaledTimer) C() <-chan time.Time        { return t.c }
+func (t *scaledTimer) Stop() bool                 { return t.t.Stop() }
+func (t *scaledTimer) Reset(d time.Duration) bool { return t.t.Reset(t.s.realDuration(d)) }
+
+type scaledTicker struct {
+	s    *scaled
+	c    chan time.Time
+	t    *time.Ticker
+	quit chan struct{}
+	once sync.Once
+}
+
+func (t *scaledTicker) loop() {
+	for {
+		select {
+		case <-t.t.C:
+			select {
+			case t.c <- t.s.Now():
+			default:
+			}
+		case <-t.quit:
+			return
+		}
+	}
+}
+
+func (t *scaledTicker) C() <-chan time.Time { return t.c }
+
+func (t *scaledTicker) Stop() {
+	t.t.Stop()
+	t.once.Do(func() { close(t.quit) })
+}
+
+// Fake is a clock that stands still until advanced, firing its timers and
+// tickers on the way, in the order of their times. Their channels hold one
+// time, as those of the time package; a ticker drops the ticks that find
+// its channel full.
+type Fake struct {
+	mtx     sync.Mutex
+	cond    *sync.Cond
+	now     time.Time
+	waiters []*waiter
+}
+
+// waiter is an armed timer or ticker of a Fake, which ticks every period.
+type waiter struct {
+	at     time.Time
+	period time.Duration
+	c      chan time.Time
+}
+
+// NewFake returns a Fake clock set at now.
+func NewFake(now time.Time) *Fake {
+	f := &Fake{now: now}
+	f.cond = sync.NewCond(&f.mtx)
+	return f
+}
+
+// Now implements Clock.
+func (f *Fake) Now() time.Time {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	return f.now
+}
+
+// Since implements Clock.
+func (f *Fake) Since(t time.Time) time.Duration { return f.Now().Sub(t) }
+
+// After implements Clock.
+func (f *Fake) After(d time.Duration) <-chan time.Time { return f.NewTimer(d).C() }
+
+// Sleep implements Clock, returning once the clock is advanced by d.
+func (f *Fake) Sleep(d time.Duration) { <-f.After(d) }
+
+// NewTimer implements Clock.
+func (f *Fake) NewTimer(d time.Duration) Timer {
+	t := &fakeTimer{f: f, w: &waiter{c: make(chan time.Time, 1)}}
+	t.Reset(d)
+	return t
+}
+
+// NewTicker implements Clock. It panics for a period of zero or less, as
+// time.NewTicker does.
+func (f *Fake) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("clock: non-positive interval for NewTicker")
+	}
+	w := &waiter{period: d, c: make(chan time.Time, 1)}
+	f.mtx.Lock()
+	w.at = f.now.Add(d)
+	f.arm(w)
+	f.mtx.Unlock()
+	return &fakeTicker{f: f, w: w}
+}
+
+// Advance moves the clock d forward, firing the timers and tickers due.
+func (f *Fake) Advance(d time.Duration) {
+	f.mtx.Lock()
+	f.advance(f.now.Add(d))
+	f.mtx.Unlock()
+}
+
+// Set moves the clock to t, forward only, firing the timers and tickers
+// due.
+func (f *Fake) Set(t time.Time) {
+	f.mtx.Lock()
+	if t.After(f.now) {
+		f.advance(t)
+	}
+	f.mtx.Unlock()
+}
+
+// Waiters returns the number of timers and tickers armed.
+func (f *Fake) Waiters() int {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	return len(f.waiters)
+}
+
+// BlockUntil blocks until n timers and tickers are armed, for a test to
+// advance the clock once the goroutines it started are waiting for it.
+func (f *Fake) BlockUntil(n int) {
+	f.mtx.Lock()
+	for len(f.waiters) < n {
+		f.cond.Wait()
+	}
+	f.mtx.Unlock()
+}
+
+func (f *Fake) advance(to time.Time) {
+	for len(f.waiters) > 0 && !f.waiters[0].at.After(to) {
+		w := f.waiters[0]
+		f.now = w.at
+		select {
+		case w.c <- w.at:
+		default:
+		}
+		f.disarm(w)
+		if w.period > 0 {
+			w.at = w.at.Add(w.period)
+			f.arm(w)
+		}
+	}
+	f.now = to
+}
+
+// arm adds w to the waiters, kept in the order of their times.
+func (f *Fake) arm(w *waiter) {
+	i := sort.Search(len(f.waiters), func(i int) bool { return f.waiters[i].at.After(w.at) })
+	f.waiters = append(f.waiters, nil)
+	copy(f.waiters[i+1:], f.waiters[i:])
+	f.waiters[i] = w
+	f.cond.Broadcast()
+}
+
+// disarm removes w from the waiters, and reports whether it was armed.
+func (f *Fake) disarm(w *waiter) bool {
+	for i, v := range f.waiters {
+		if v == w {
+			f.waiters = append(f.waiters[:i], f.waiters[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+type fakeTimer struct {
+	f *Fake
+	w *waiter
+}
+
+func (t *fakeTimer) C() <-chan time.Time { return t.w.c }
+
+func (t *fakeTimer) Stop() bool {
+	t.f.mtx.Lock()
+	defer t.f.mtx.Unlock()
+	return t.f.disarm(t.w)
+}
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	t.f.mtx.Lock()
+	defer t.f.mtx.Unlock()
+	armed := t.f.disarm(t.w)
+	t.w.at = t.f.now.Add(d)
+	if d <= 0 {
+		select {
+		case t.w.c <- t.f.now:
+		default:
+		}
+		return armed
+	}
+	t.f.arm(t.w)
+	return armed
+}
+
+type fakeTicker struct {
+	f *Fake
+	w *waiter
+}
+
+func (t *fakeTicker) C() <-chan time.Time { return t.w.c }
+
+func (t *fakeTicker) Stop() {
+	t.f.mtx.Lock()
+	t.f.disarm(t.w)
+	t.f.mtx.Unlock()
+}
@@ -0,0 +1,111 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+var epoch = time.Unix(0, 0)
+
+// fired returns the time on c, and false when nothing is on it.
+func fired(c <-chan time.Time) (time.Time, bool) {
+	select {
+	case t := <-c:
+		return t, true
+	default:
+		return time.Time{}, false
+	}
+}
+
+func TestFakeTimer(t *testing.T) {
+	c := NewFake(epoch)
+	timer := c.NewTimer(5 * time.Second)
+	c.Advance(4 * time.Second)
+	if _, ok := fired(timer.C()); ok {
+		t.Fatal("fired 1s early")
+	}
+	c.Advance(2 * time.Second)
+	if at, ok := fired(timer.C()); !ok || !at.Equal(epoch.Add(5*time.Second)) {
+		t.Fatalf("fired %v (%v), want at 5s", at, ok)
+	}
+	if now := c.Now(); !now.Equal(epoch.Add(6 * time.Second)) {
+		t.Errorf("now %v, want 6s", now)
+	}
+	if timer.Stop() {
+		t.Error("Stop of a fired timer reported it armed")
+	}
+
+	if timer.Reset(time.Second) {
+		t.Error("Reset of a fired timer reported it armed")
+	}
+	if !timer.Stop() {
+		t.Error("Stop of a reset timer reported it not armed")
+	}
+	c.Advance(time.Hour)
+	if _, ok := fired(timer.C()); ok {
+		t.Error("stopped timer fired")
+	}
+	if timer.Reset(0); c.Waiters() != 0 {
+		t.Error("timer reset to zero armed")
+	}
+	if _, ok := fired(timer.C()); !ok {
+		t.Error("timer reset to zero did not fire")
+	}
+}
+
+func TestFakeTickerRearms(t *testing.T) {
+	c := NewFake(epoch)
+	ticker := c.NewTicker(time.Second)
+	timer := c.NewTimer(2500 * time.Millisecond)
+
+	c.Advance(time.Second)
+	if at, ok := fired(ticker.C()); !ok || !at.Equal(epoch.Add(time.Second)) {
+		t.Fatalf("ticked %v (%v), want at 1s", at, ok)
+	}
+	// the ticks at 3s and 4s find the channel full and are dropped, the
+	// timer fires between them
+	c.Advance(3 * time.Second)
+	if at, ok := fired(ticker.C()); !ok || !at.Equal(epoch.Add(2*time.Second)) {
+		t.Fatalf("ticked %v (%v), want at 2s", at, ok)
+	}
+	if _, ok := fired(ticker.C()); ok {
+		t.Fatal("ticker channel held more than one tick")
+	}
+	if at, ok := fired(timer.C()); !ok || !at.Equal(epoch.Add(2500*time.Millisecond)) {
+		t.Fatalf("timer fired %v (%v), want at 2.5s", at, ok)
+	}
+	if n := c.Waiters(); n != 1 {
+		t.Fatalf("%d waiters, want the ticker re-armed alone", n)
+	}
+	c.Advance(time.Second)
+	if at, ok := fired(ticker.C()); !ok || !at.Equal(epoch.Add(5*time.Second)) {
+		t.Fatalf("ticked %v (%v), want at 5s", at, ok)
+	}
+
+	ticker.Stop()
+	c.Advance(time.Hour)
+	if _, ok := fired(ticker.C()); ok {
+		t.Error("stopped ticker ticked")
+	}
+	if n := c.Waiters(); n != 0 {
+		t.Errorf("%d waiters after Stop", n)
+	}
+}
+
+func TestFakeBlockUntil(t *testing.T) {
+	c := NewFake(epoch)
+	done := make(chan struct{})
+	for i := 0; i < 2; i++ {
+		go func() {
+			c.Sleep(time.Second)
+			done <- struct{}{}
+		}()
+	}
+	c.BlockUntil(2)
+	c.Advance(time.Second)
+	<-done
+	<-done
+	if n := c.Waiters(); n != 0 {
+		t.Errorf("%d waiters after the sleeps", n)
+	}
+}
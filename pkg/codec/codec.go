@@ -0,0 +1,336 @@
+// Package codec serializes the bodies of the HTTP transports in the format the
+// client negotiates: JSON, the default and the one of the 3GPP SBI peers,
+// protobuf, the messages of the gRPC transports, or CBOR, for the constrained
+// test clients. The request body is decoded as its Content-Type and the
+// response encoded as the request's Accept, the codecs being looked up in a
+// Registry.
+package codec
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/fxamacker/cbor/v2"
+	grpctransport "github.com/go-kit/kit/transport/grpc"
+	httptransport "github.com/go-kit/kit/transport/http"
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// The content types of the codecs.
+const (
+	JSONContentType     = "application/json"
+	ProtobufContentType = "application/x-protobuf"
+	CBORContentType     = "application/cbor"
+)
+
+// Codec serializes the bodies of one content type.
+type Codec struct {
+	// ContentType is the media type of the bodies, without parameters.
+	ContentType string
+	Marshal     func(v interface{}) ([]byte, error)
+	Unmarshal   func(data []byte, v interface{}) error
+	// Proto is set when the codec serializes the protobuf messages of the
+	// gRPC transports instead of the endpoints requests and responses.
+	Proto bool
+}
+
+// JSON is the codec of application/json.
+var JSON = Codec{
+	ContentType: JSONContentType,
+	Marshal:     json.Marshal,
+	Unmarshal:   json.Unmarshal,
+}
+
+// Protobuf is the codec of application/x-protobuf.
+var Protobuf = Codec{
+	ContentType: ProtobufContentType,
+	Marshal: func(v interface{}) ([]byte, error) {
+		m, ok := v.(proto.Message)
+		if !ok {
+			return nil, fmt.Errorf("codec: %T is not a protobuf message", v)
+		}
+		return proto.Marshal(m)
+	},
+	Unmarshal: func(data []byte, v interface{}) error {
+		m, ok := v.(proto.Message)
+		if !ok {
+			return fmt.Errorf("codec: %T is not a protobuf message", v)
+		}
+		return proto.Unmarshal(data, m)
+	},
+	Proto: true,
+}
+
+// cborMode encodes the times as JSON does, RFC 3339 with nanoseconds.
+var cborMode, _ = cbor.EncOptions{Time: cbor.TimeRFC3339Nano}.EncMode()
+
+// CBOR is the codec of application/cbor. It names the fields by their json
+// tags, as JSON does.
+var CBOR = Codec{
+	ContentType: CBORContentType,
+	Marshal:     cborMode.Marshal,
+	Unmarshal:   cbor.Unmarshal,
+}
+
+// ProtoJSON is the codec of application/json for the protobuf messages of the
+// gRPC transports, in the proto3 JSON mapping with the field names of the
+// .proto files. A registry of ProtoJSON and Protobuf serves HTTP bodies that
+// are the messages of a gRPC API. The unknown fields are ignored.
+var ProtoJSON = Codec{
+	ContentType: JSONContentType,
+	Marshal: func(v interface{}) ([]byte, error) {
+		m, ok := v.(proto.Message)
+		if !ok {
+			return nil, fmt.Errorf("codec: %T is not a protobuf message", v)
+		}
+		var buf bytes.Buffer
+		if err := (&jsonpb.Marshaler{OrigName: true}).Marshal(&buf, m); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	},
+	Unmarshal: func(data []byte, v interface{}) error {
+		m, ok := v.(proto.Message)
+		if !ok {
+			return fmt.Errorf("codec: %T is not a protobuf message", v)
+		}
+		return (&jsonpb.Unmarshaler{AllowUnknownFields: true}).Unmarshal(bytes.NewReader(data), m)
+	},
+	Proto: true,
+}
+
+// Registry is a set of codecs keyed by content type. The first codec
+// registered is the default one, of the requests without a Content-Type and
+// of the responses whose Accept matches no codec.
+type Registry struct {
+	mtx    sync.RWMutex
+	codecs map[string]Codec
+	def    Codec
+}
+
+// NewRegistry returns a registry of codecs, the first one being the default.
+func NewRegistry(codecs ...Codec) *Registry {
+	r := &Registry{codecs: map[string]Codec{}}
+	for _, c := range codecs {
+		r.Register(c)
+	}
+	return r
+}
+
+// Default is the registry of JSON, the default, Protobuf and CBOR.
+var Default = NewRegistry(JSON, Protobuf, CBOR)
+
+// Register adds c to the registry, replacing the codec of its content type.
+func (r *Registry) Register(c Codec) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	if len(r.codecs) == 0 {
+		r.def = c
+	}
+	r.codecs[c.ContentType] = c
+}
+
+// Lookup returns the codec of contentType, whose parameters are ignored.
+func (r *Registry) Lookup(contentType string) (Codec, bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return Codec{}, false
+	}
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	c, ok := r.codecs[mediaType]
+	return c, ok
+}
+
+// Negotiate returns the codec that accept, the Accept header of a request,
+// prefers. An empty Accept or a wildcard one returns preferred, the codec of
+// the request body, and an Accept that matches no codec the default codec.
+func (r *Registry) Negotiate(accept string, preferred Codec) Codec {
+	if strings.TrimSpace(accept) == "" {
+		return preferred
+	}
+	type mediaRange struct {
+		mediaType string
+		q         float64
+	}
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(part)
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		if q > 0 {
+			ranges = append(ranges, mediaRange{mediaType, q})
+		}
+	}
+	sort.SliceStable(ranges, func(i, j int) bool { return ranges[i].q > ranges[j].q })
+	for _, mr := range ranges {
+		if mr.mediaType == "*/*" || mr.mediaType == strings.SplitN(preferred.ContentType, "/", 2)[0]+"/*" {
+			return preferred
+		}
+		if c, ok := r.Lookup(mr.mediaType); ok {
+			return c
+		}
+	}
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	return r.def
+}
+
+// ErrUnsupportedContentType is returned by the decoding of a protobuf request
+// body for a method without protobuf requests.
+var ErrUnsupportedContentType = errors.New("unsupported Content-Type")
+
+type requestCodecKey struct{}
+type responseCodecKey struct{}
+
+// HTTPToContext moves the codecs of the request body, as set by its
+// Content-Type, and of the response body, as negotiated by its Accept, to the
+// context. A request without a Content-Type, or with one of no codec such as
+// the form one curl -d sends, is decoded by the default codec.
+func (r *Registry) HTTPToContext() httptransport.RequestFunc {
+	return func(ctx context.Context, req *http.Request) context.Context {
+		r.mtx.RLock()
+		c := r.def
+		r.mtx.RUnlock()
+		if rc, ok := r.Lookup(req.Header.Get("Content-Type")); ok {
+			c = rc
+		}
+		ctx = context.WithValue(ctx, requestCodecKey{}, c)
+		return context.WithValue(ctx, responseCodecKey{}, r.Negotiate(req.Header.Get("Accept"), c))
+	}
+}
+
+// requestCodec returns the codec of the request body of ctx, JSON when the
+// context carries none.
+func requestCodec(ctx context.Context) Codec {
+	if c, ok := ctx.Value(requestCodecKey{}).(Codec); ok {
+		return c
+	}
+	return JSON
+}
+
+// responseCodec returns the codec of the response body of ctx, JSON when the
+// context carries none.
+func responseCodec(ctx context.Context) Codec {
+	if c, ok := ctx.Value(responseCodecKey{}).(Codec); ok {
+		return c
+	}
+	return JSON
+}
+
+// Binding binds a method of an HTTP transport to its request and response
+// types, the endpoints ones and the protobuf ones of its gRPC transport. The
+// protobuf bodies are converted by the decode and encode functions of the
+// gRPC transport, so that a method answers the same over both. A transport
+// declares one Binding per method, such as
+//
+//	var sumBinding = codec.Binding{
+//		Request:   func() interface{} { return &endpoints.SumRequest{} },
+//		PBRequest: func() proto.Message { return &pb.SumRequest{} },
+//		FromPB:    decodeGRPCSumRequest,
+//		ToPB:      encodeGRPCSumResponse,
+//	}
+type Binding struct {
+	// Request returns a pointer to a new endpoints request.
+	Request func() interface{}
+	// PBRequest returns a new protobuf request. The method only serves
+	// protobuf bodies when it is set.
+	PBRequest func() proto.Message
+	// FromPB converts the protobuf request to the endpoints one.
+	FromPB grpctransport.DecodeRequestFunc
+	// ToPB converts the endpoints response to the protobuf one.
+	ToPB grpctransport.EncodeResponseFunc
+}
+
+// DecodeRequest returns a transport/http.DecodeRequestFunc that decodes the
+// request body of b with the codec of its Content-Type. The errors are
+// InvalidArgument ones.
+func DecodeRequest(b Binding) httptransport.DecodeRequestFunc {
+	return func(ctx context.Context, r *http.Request) (interface{}, error) {
+		c := requestCodec(ctx)
+		data, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			return nil, err
+		}
+		if c.Proto {
+			if b.PBRequest == nil {
+				return nil, status.Error(codes.InvalidArgument, ErrUnsupportedContentType.Error())
+			}
+			m := b.PBRequest()
+			if err := c.Unmarshal(data, m); err != nil {
+				return nil, status.Error(codes.InvalidArgument, err.Error())
+			}
+			return b.FromPB(ctx, m)
+		}
+		req := b.Request()
+		if err := c.Unmarshal(data, req); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return reflect.ValueOf(req).Elem().Interface(), nil
+	}
+}
+
+// EncodeResponse returns a transport/http.EncodeResponseFunc that encodes the
+// response of b with the negotiated codec, setting the headers and status code
+// of responses that are httptransport.Headerer or httptransport.StatusCoder
+// as httptransport.EncodeJSONResponse does. A method without ToPB answers in
+// JSON the requests accepting protobuf.
+func EncodeResponse(b Binding) httptransport.EncodeResponseFunc {
+	return func(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+		c := responseCodec(ctx)
+		v := response
+		if c.Proto {
+			if b.ToPB == nil {
+				c = JSON
+			} else {
+				var err error
+				if v, err = b.ToPB(ctx, response); err != nil {
+					return err
+				}
+			}
+		}
+		data, err := c.Marshal(v)
+		if err != nil {
+			return err
+		}
+		w.Header().Set("Content-Type", c.ContentType)
+		if headerer, ok := response.(httptransport.Headerer); ok {
+			for k, values := range headerer.Headers() {
+				for _, v := range values {
+					w.Header().Add(k, v)
+				}
+			}
+		}
+		code := http.StatusOK
+		if sc, ok := response.(httptransport.StatusCoder); ok {
+			code = sc.StatusCode()
+		}
+		w.WriteHeader(code)
+		if code == http.StatusNoContent {
+			return nil
+		}
+		_, err = w.Write(data)
+		return err
+	}
+}
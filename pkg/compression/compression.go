@@ -0,0 +1,81 @@
+// Package compression compresses the gRPC messages of the services with gzip.
+// The clients choose, method by method, whether their requests are
+// compressed: the large RRC containers of the F1 gain from it, the small
+// requests only pay for it. The servers accept gzip requests and answer them
+// in kind; grpcserver registers the compressor for every server.
+package compression
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/encoding/gzip"
+)
+
+// The names of the compressors.
+const (
+	Gzip     = gzip.Name
+	Identity = encoding.Identity
+)
+
+// SetLevel sets the level of the gzip compressor of the process, one of
+// compress/gzip. It is not safe to call once the clients and servers run.
+func SetLevel(level int) error {
+	return gzip.SetLevel(level)
+}
+
+// Check returns an error when name is not a compressor of the process.
+func Check(name string) error {
+	if name == "" || name == Identity || encoding.GetCompressor(name) != nil {
+		return nil
+	}
+	return fmt.Errorf("compression: unknown compressor %q", name)
+}
+
+// ParseMethods parses the per method compressors of s, a comma-separated list
+// of method=compressor, such as
+// "InitialULRRCMessageTransfer=gzip,F1Setup=identity". A method is a full
+// gRPC method, /gnodeb.F1CU/F1Setup, or the name of the methods of any
+// service, F1Setup.
+func ParseMethods(s string) (map[string]string, error) {
+	methods := map[string]string{}
+	for _, kv := range strings.Split(s, ",") {
+		kv = strings.TrimSpace(kv)
+		if kv == "" {
+			continue
+		}
+		i := strings.Index(kv, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("compression: %q is not method=compressor", kv)
+		}
+		method, name := strings.TrimSpace(kv[:i]), strings.TrimSpace(kv[i+1:])
+		if err := Check(name); err != nil {
+			return nil, err
+		}
+		methods[method] = name
+	}
+	return methods, nil
+}
+
+// UnaryClientInterceptor compresses the requests of the methods of methods,
+// keyed as by ParseMethods, with their compressor and the requests of the
+// other methods with def. No compressor, or the identity one, leaves the
+// requests as they are. The compressor of a call's own options wins.
+func UnaryClientInterceptor(def string, methods map[string]string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		name, ok := methods[method]
+		if !ok {
+			if name, ok = methods[path.Base(method)]; !ok {
+				name = def
+			}
+		}
+		if name != "" && name != Identity {
+			opts = append([]grpc.CallOption{grpc.UseCompressor(name)}, opts...)
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
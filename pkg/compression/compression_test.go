@@ -0,0 +1,81 @@
+package compression
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc/encoding"
+
+	pb "github.com/miki-tnt/sa5g-go-usvc-k8s/pb/gnodeb"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/gnodeb/f1"
+)
+
+// capability returns a UE radio capability container of n band
+// combinations, as repetitive as the PER encoding of a real one.
+func capability(n int) []byte {
+	c := make([]byte, 0, 8*n)
+	for i := 0; i < n; i++ {
+		c = append(c, 0x20, byte(i%40+1), byte(i*7), 0x11, 0x0c, byte(i%3), 0x80, 0x00)
+	}
+	return c
+}
+
+// rrcTransfers are the F1 RRC Message Transfers of the benchmarks, by the
+// size of their RRC containers: a Measurement Report, and the UE Capability
+// Informations of a UE of few and of many band combinations.
+var rrcTransfers = []struct {
+	name string
+	rrc  []byte
+}{
+	{"MeasurementReport", f1.RRC(f1.MeasurementReport, f1.MeasResults{
+		Serving:    f1.CellMeas{NCI: 4096, RSRP: -95.5},
+		Neighbours: []f1.CellMeas{{NCI: 4097, RSRP: -99}, {NCI: 4098, RSRP: -104.5}},
+	})},
+	{"UECapabilityInformation/64", f1.RRC(f1.UECapabilityInformation, f1.UECapabilityInformationIEs{Container: capability(64)})},
+	{"UECapabilityInformation/1024", f1.RRC(f1.UECapabilityInformation, f1.UECapabilityInformationIEs{Container: capability(1024)})},
+}
+
+// BenchmarkCompression compresses and decompresses the RRC Message
+// Transfers as a client and a server of the F1 do, with gzip and with the
+// identity compressor. The wire-B/op metric is the size of the message on
+// the wire, against the CPU gzip spends for it.
+func BenchmarkCompression(b *testing.B) {
+	for _, t := range rrcTransfers {
+		msg, err := proto.Marshal(&pb.RRCMessageTransferRequest{GnbCuUeF1ApId: 1, GnbDuUeF1ApId: 1, SrbId: 1, RrcContainer: t.rrc})
+		if err != nil {
+			b.Fatal(err)
+		}
+		for _, name := range []string{Identity, Gzip} {
+			b.Run(t.name+"/"+name, func(b *testing.B) {
+				c := encoding.GetCompressor(name)
+				var wire bytes.Buffer
+				b.SetBytes(int64(len(msg)))
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					wire.Reset()
+					if c == nil {
+						wire.Write(msg)
+						continue
+					}
+					w, err := c.Compress(&wire)
+					if err != nil {
+						b.Fatal(err)
+					}
+					w.Write(msg)
+					w.Close()
+					r, err := c.Decompress(bytes.NewReader(wire.Bytes()))
+					if err != nil {
+						b.Fatal(err)
+					}
+					if _, err := io.Copy(ioutil.Discard, r); err != nil {
+						b.Fatal(err)
+					}
+				}
+				b.ReportMetric(float64(wire.Len()), "wire-B/op")
+			})
+		}
+	}
+}
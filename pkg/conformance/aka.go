@@ -0,0 +1,413 @@
+package conformance
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+
+	ausfpb "github.com/miki-tnt/sa5g-go-usvc-k8s/pb/ausf"
+	udmpb "github.com/miki-tnt/sa5g-go-usvc-k8s/pb/udm"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/security"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/security/milenage"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/udm/subscriber"
+)
+
+// Errors of the USIM.
+var (
+	ErrMAC         = errors.New("MAC failure")
+	ErrSyncFailure = errors.New("synchronisation failure, SQN out of range")
+)
+
+// akaCases check 5G-AKA, run by the AUSF and the UDM (TS 33.501 6.1.3.2),
+// and the sequence number management of TS 33.102 6.3 it relies on.
+var akaCases = []Case{
+	{Name: "aka/register", Clause: "TS 33.501 6.1.3.2", run: akaRegister},
+	{Name: "aka/sqn-fresh", Clause: "TS 33.102 6.3.3", run: akaSQNFresh},
+	{Name: "aka/sync-failure", Clause: "TS 33.102 6.3.5", run: akaSyncFailure},
+	{Name: "aka/resync-mac-s", Clause: "TS 33.102 6.3.5", run: akaResyncMACS},
+	{Name: "aka/resync-malformed", Clause: "TS 33.102 6.3.5", run: akaResyncMalformed},
+	{Name: "aka/res-star-mismatch", Clause: "TS 33.501 6.1.3.2.2", run: akaResStarMismatch},
+	{Name: "aka/confirm-twice", Clause: "TS 33.501 6.1.3.2", run: akaConfirmTwice},
+	{Name: "aka/unknown-context", Clause: "TS 33.501 6.1.3.2", run: akaUnknownContext},
+	{Name: "aka/unknown-subscriber", Clause: "TS 29.503 6.3.6.2.2", run: akaUnknownSubscriber},
+	{Name: "aka/suci-null-scheme", Clause: "TS 33.501 6.12.2", run: akaSUCINullScheme},
+	{Name: "aka/suci-protected", Clause: "TS 33.501 6.12.2", run: akaSUCIProtected},
+	{Name: "aka/context-expiry", Clause: "TS 33.501 6.1.3.2", skip: skipAuthTTL, wait: authTTL, run: akaContextExpiry},
+}
+
+// usim is the USIM of a subscriber. It keeps the highest SQN it accepted,
+// and rejects the challenges whose SQN is not above it (TS 33.102 6.3.3).
+type usim struct {
+	m *milenage.Milenage
+	// sqnMS is SQN_MS, the highest SQN accepted.
+	sqnMS uint64
+}
+
+// answer is the answer of a USIM to a challenge.
+type answer struct {
+	resStar  []byte
+	kausf    []byte
+	sqn      uint64
+	sqnXorAK []byte
+}
+
+// answer checks the AUTN of the challenge rand and answers it.
+func (u *usim) answer(rand, autn []byte, servingNetworkName string) (a answer, err error) {
+	if len(rand) != milenage.RandSize || len(autn) != milenage.SQNSize+milenage.AMFSize+milenage.MACSize {
+		return a, ErrMAC
+	}
+	o, err := u.m.F2345(rand)
+	if err != nil {
+		return a, err
+	}
+	// AUTN = SQN xor AK || AMF || MAC
+	sqnXorAK := autn[:milenage.SQNSize]
+	amf := autn[milenage.SQNSize : milenage.SQNSize+milenage.AMFSize]
+	sqn := make([]byte, milenage.SQNSize)
+	for i := range sqn {
+		sqn[i] = sqnXorAK[i] ^ o.AK[i]
+	}
+	mac, err := u.m.F1(rand, sqn, amf)
+	if err != nil {
+		return a, err
+	}
+	if !bytes.Equal(mac[:], autn[milenage.SQNSize+milenage.AMFSize:]) {
+		return a, ErrMAC
+	}
+	a.sqn = subscriber.SQNUint(sqn)
+	if a.sqn <= u.sqnMS {
+		return a, ErrSyncFailure
+	}
+	u.sqnMS = a.sqn
+	a.resStar = security.ResStar(o.CK[:], o.IK[:], servingNetworkName, rand, o.RES[:])
+	a.kausf = security.Kausf(o.CK[:], o.IK[:], servingNetworkName, sqnXorAK)
+	a.sqnXorAK = sqnXorAK
+	return a, nil
+}
+
+// auts returns the AUTS = SQN_MS xor AK* || MAC-S the USIM sends after the
+// synchronisation failure of the challenge rand, MAC-S being computed over a
+// zero AMF (TS 33.102 6.3.3).
+func (u *usim) auts(rand []byte) ([]byte, error) {
+	ak, err := u.m.F5Star(rand)
+	if err != nil {
+		return nil, err
+	}
+	sqnMS := subscriber.SQNBytes(u.sqnMS)
+	macS, err := u.m.F1Star(rand, sqnMS, make([]byte, milenage.AMFSize))
+	if err != nil {
+		return nil, err
+	}
+	auts := make([]byte, 0, milenage.SQNSize+milenage.MACSize)
+	for i := range sqnMS {
+		auts = append(auts, sqnMS[i]^ak[i])
+	}
+	return append(auts, macS[:]...), nil
+}
+
+// provision creates the subscriber of the case, to be deleted once the case
+// is over, and returns its USIM.
+func (c *caseRun) provision(ctx context.Context) (*usim, error) {
+	sub := c.t.Subscriber
+	m, err := milenage.New(sub.K, sub.OPc)
+	if err != nil {
+		return nil, err
+	}
+	supi := c.supi()
+	if _, err := c.t.UDM.CreateSubscriber(ctx, &udmpb.CreateSubscriberRequest{
+		Subscriber: &udmpb.Subscriber{Supi: supi, K: sub.K, Opc: sub.OPc},
+	}); err != nil {
+		return nil, fmt.Errorf("provisioning %s: %v", supi, err)
+	}
+	c.onDone(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		c.t.UDM.DeleteSubscriber(ctx, &udmpb.DeleteSubscriberRequest{Supi: supi})
+	})
+	return &usim{m: m}, nil
+}
+
+// authenticate gets a challenge for the subscriber of the case, or for
+// supiOrSuci when given.
+func (c *caseRun) authenticate(ctx context.Context, supiOrSuci string) (*ausfpb.AuthenticateReply, error) {
+	if supiOrSuci == "" {
+		supiOrSuci = c.supi()
+	}
+	return c.t.AUSF.Authenticate(ctx, &ausfpb.AuthenticateRequest{
+		SupiOrSuci:         supiOrSuci,
+		ServingNetworkName: c.t.Subscriber.ServingNetworkName,
+	})
+}
+
+// challenge gets a challenge for the subscriber of the case and has u answer
+// it.
+func (c *caseRun) challenge(ctx context.Context, u *usim) (*ausfpb.AuthenticateReply, answer, error) {
+	ac, err := c.authenticate(ctx, "")
+	if err != nil {
+		return nil, answer{}, err
+	}
+	a, err := u.answer(ac.Rand, ac.Autn, c.t.Subscriber.ServingNetworkName)
+	return ac, a, err
+}
+
+// confirm confirms the challenge ac with the answer a, and checks the K_SEAF
+// released.
+func (c *caseRun) confirm(ctx context.Context, ac *ausfpb.AuthenticateReply, a answer) error {
+	snn := c.t.Subscriber.ServingNetworkName
+	if !bytes.Equal(security.HResStar(ac.Rand, a.resStar), ac.HxresStar) {
+		return errors.New("HXRES* does not match RES*")
+	}
+	cr, err := c.t.AUSF.ConfirmAuth(ctx, &ausfpb.ConfirmAuthRequest{AuthCtxId: ac.AuthCtxId, ResStar: a.resStar})
+	if err != nil {
+		return err
+	}
+	if cr.Supi != c.supi() {
+		return fmt.Errorf("confirmed %s, not %s", cr.Supi, c.supi())
+	}
+	if !bytes.Equal(security.Kseaf(a.kausf, snn), cr.Kseaf) {
+		return errors.New("K_SEAF differs from the one of the UE")
+	}
+	return nil
+}
+
+// akaRegister checks that 5G-AKA authenticates the network to the UE and
+// the UE to the network, and that both end with the same K_SEAF.
+func akaRegister(ctx context.Context, c *caseRun) error {
+	u, err := c.provision(ctx)
+	if err != nil {
+		return err
+	}
+	ac, a, err := c.challenge(ctx, u)
+	if err != nil {
+		return err
+	}
+	return c.confirm(ctx, ac, a)
+}
+
+// akaSQNFresh checks that every challenge carries an SQN above the previous
+// one, the USIM accepting them in turn.
+func akaSQNFresh(ctx context.Context, c *caseRun) error {
+	u, err := c.provision(ctx)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < 3; i++ {
+		ac, a, err := c.challenge(ctx, u)
+		if err != nil {
+			return fmt.Errorf("challenge %d: %v", i+1, err)
+		}
+		if err := c.confirm(ctx, ac, a); err != nil {
+			return fmt.Errorf("challenge %d: %v", i+1, err)
+		}
+	}
+	return nil
+}
+
+// akaSyncFailure checks that the home network resynchronises with a USIM
+// whose SQN_MS is ahead of its SQN: the USIM rejects the challenge and sends
+// AUTS, and the challenge generated from AUTS is accepted.
+func akaSyncFailure(ctx context.Context, c *caseRun) error {
+	u, err := c.provision(ctx)
+	if err != nil {
+		return err
+	}
+	// as if the USIM had been authenticated by another home network
+	// instance that lost its SQN
+	u.sqnMS = 1 << 40
+	ac, _, err := c.challenge(ctx, u)
+	if err != ErrSyncFailure {
+		return fmt.Errorf("expected the USIM to report %v, got %v", ErrSyncFailure, err)
+	}
+	auts, err := u.auts(ac.Rand)
+	if err != nil {
+		return err
+	}
+	ac, err = c.t.AUSF.Authenticate(ctx, &ausfpb.AuthenticateRequest{
+		SupiOrSuci:         c.supi(),
+		ServingNetworkName: c.t.Subscriber.ServingNetworkName,
+		ResyncRand:         ac.Rand,
+		ResyncAuts:         auts,
+	})
+	if err != nil {
+		return fmt.Errorf("resynchronising: %v", err)
+	}
+	a, err := u.answer(ac.Rand, ac.Autn, c.t.Subscriber.ServingNetworkName)
+	if err != nil {
+		return fmt.Errorf("after the resynchronisation: %v", err)
+	}
+	return c.confirm(ctx, ac, a)
+}
+
+// akaResyncMACS checks that an AUTS whose MAC-S is not that of the
+// subscriber is rejected.
+func akaResyncMACS(ctx context.Context, c *caseRun) error {
+	u, err := c.provision(ctx)
+	if err != nil {
+		return err
+	}
+	ac, err := c.authenticate(ctx, "")
+	if err != nil {
+		return err
+	}
+	u.sqnMS = 1 << 40
+	auts, err := u.auts(ac.Rand)
+	if err != nil {
+		return err
+	}
+	auts[len(auts)-1] ^= 0xff
+	_, err = c.t.AUSF.Authenticate(ctx, &ausfpb.AuthenticateRequest{
+		SupiOrSuci:         c.supi(),
+		ServingNetworkName: c.t.Subscriber.ServingNetworkName,
+		ResyncRand:         ac.Rand,
+		ResyncAuts:         auts,
+	})
+	return expectCode(err, codes.Unauthenticated)
+}
+
+// akaResyncMalformed checks that an AUTS of the wrong size is rejected.
+func akaResyncMalformed(ctx context.Context, c *caseRun) error {
+	if _, err := c.provision(ctx); err != nil {
+		return err
+	}
+	ac, err := c.authenticate(ctx, "")
+	if err != nil {
+		return err
+	}
+	_, err = c.t.AUSF.Authenticate(ctx, &ausfpb.AuthenticateRequest{
+		SupiOrSuci:         c.supi(),
+		ServingNetworkName: c.t.Subscriber.ServingNetworkName,
+		ResyncRand:         ac.Rand,
+		ResyncAuts:         []byte{1, 2, 3},
+	})
+	return expectCode(err, codes.InvalidArgument)
+}
+
+// akaResStarMismatch checks that a wrong RES* fails the authentication, and
+// that the challenge cannot be answered again.
+func akaResStarMismatch(ctx context.Context, c *caseRun) error {
+	u, err := c.provision(ctx)
+	if err != nil {
+		return err
+	}
+	ac, a, err := c.challenge(ctx, u)
+	if err != nil {
+		return err
+	}
+	wrong := append([]byte(nil), a.resStar...)
+	wrong[0] ^= 0xff
+	_, err = c.t.AUSF.ConfirmAuth(ctx, &ausfpb.ConfirmAuthRequest{AuthCtxId: ac.AuthCtxId, ResStar: wrong})
+	if err := expectCode(err, codes.Unauthenticated); err != nil {
+		return err
+	}
+	_, err = c.t.AUSF.ConfirmAuth(ctx, &ausfpb.ConfirmAuthRequest{AuthCtxId: ac.AuthCtxId, ResStar: a.resStar})
+	if err := expectCode(err, codes.NotFound); err != nil {
+		return fmt.Errorf("answering again: %v", err)
+	}
+	return nil
+}
+
+// akaConfirmTwice checks that a challenge is confirmed once, a replayed
+// RES* finding no context.
+func akaConfirmTwice(ctx context.Context, c *caseRun) error {
+	u, err := c.provision(ctx)
+	if err != nil {
+		return err
+	}
+	ac, a, err := c.challenge(ctx, u)
+	if err != nil {
+		return err
+	}
+	if err := c.confirm(ctx, ac, a); err != nil {
+		return err
+	}
+	_, err = c.t.AUSF.ConfirmAuth(ctx, &ausfpb.ConfirmAuthRequest{AuthCtxId: ac.AuthCtxId, ResStar: a.resStar})
+	return expectCode(err, codes.NotFound)
+}
+
+// akaUnknownContext checks that the confirmation of a context never
+// created is rejected.
+func akaUnknownContext(ctx context.Context, c *caseRun) error {
+	_, err := c.t.AUSF.ConfirmAuth(ctx, &ausfpb.ConfirmAuthRequest{AuthCtxId: "00000000000000000000000000000000", ResStar: make([]byte, 16)})
+	return expectCode(err, codes.NotFound)
+}
+
+// akaUnknownSubscriber checks that no challenge is generated for a
+// subscriber the UDM does not have.
+func akaUnknownSubscriber(ctx context.Context, c *caseRun) error {
+	_, err := c.authenticate(ctx, "")
+	return expectCode(err, codes.NotFound)
+}
+
+// akaSUCINullScheme checks that a SUCI concealed with the null scheme is
+// authenticated as its SUPI.
+func akaSUCINullScheme(ctx context.Context, c *caseRun) error {
+	u, err := c.provision(ctx)
+	if err != nil {
+		return err
+	}
+	ac, err := c.authenticate(ctx, suci(c.supi(), "0"))
+	if err != nil {
+		return err
+	}
+	a, err := u.answer(ac.Rand, ac.Autn, c.t.Subscriber.ServingNetworkName)
+	if err != nil {
+		return err
+	}
+	return c.confirm(ctx, ac, a)
+}
+
+// akaSUCIProtected checks that a SUCI concealed with a protection scheme the
+// home network has no key for is rejected.
+func akaSUCIProtected(ctx context.Context, c *caseRun) error {
+	if _, err := c.provision(ctx); err != nil {
+		return err
+	}
+	_, err := c.authenticate(ctx, suci(c.supi(), "1"))
+	return expectCode(err, codes.InvalidArgument)
+}
+
+// akaContextExpiry checks that a challenge answered after the AUSF let its
+// context expire is rejected.
+func akaContextExpiry(ctx context.Context, c *caseRun) error {
+	u, err := c.provision(ctx)
+	if err != nil {
+		return err
+	}
+	ac, a, err := c.challenge(ctx, u)
+	if err != nil {
+		return err
+	}
+	select {
+	case <-time.After(authTTL(c.t)):
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	_, err = c.t.AUSF.ConfirmAuth(ctx, &ausfpb.ConfirmAuthRequest{AuthCtxId: ac.AuthCtxId, ResStar: a.resStar})
+	return expectCode(err, codes.NotFound)
+}
+
+// authTTL is how long the expiry case waits, a second more than the TTL of
+// the contexts.
+func authTTL(t Targets) time.Duration {
+	return t.AuthTTL + time.Second
+}
+
+func skipAuthTTL(t Targets) string {
+	if t.AuthTTL <= 0 {
+		return "the authentication context TTL of the AUSF is not given"
+	}
+	return ""
+}
+
+// suci returns the SUCI of the IMSI based supi concealed with scheme
+// (TS 23.003 2.2B), its MSIN left in clear whatever the scheme. The MNC is
+// taken as 2 digits, the home network reading the IMSI back the same.
+func suci(supi, scheme string) string {
+	imsi := strings.TrimPrefix(supi, "imsi-")
+	return strings.Join([]string{"suci", "0", imsi[:3], imsi[3:5], "0000", scheme, "0", imsi[5:]}, "-")
+}
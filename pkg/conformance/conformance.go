@@ -0,0 +1,223 @@
+// Package conformance checks that the network functions of a deployed stack
+// run their procedures as the specifications say, on the paths a load
+// scenario does not take: the sequence numbers a USIM rejects and the
+// resynchronisation that follows, the challenges answered wrongly or twice,
+// the authentication contexts left to expire, and the RRC messages a UE
+// sends out of turn. Every case names the clause it checks and ends as
+// passed, failed, or skipped when the stack it runs against lacks what it
+// needs.
+//
+//	rep := conformance.Run(ctx, conformance.Cases, conformance.Targets{
+//		AUSF: ausfpb.NewAusfClient(ausfConn),
+//		UDM:  udmpb.NewUdmClient(udmConn),
+//	}, nil)
+//	rep.Write(os.Stdout)
+package conformance
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	ausfpb "github.com/miki-tnt/sa5g-go-usvc-k8s/pb/ausf"
+	udmpb "github.com/miki-tnt/sa5g-go-usvc-k8s/pb/udm"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/scenario"
+)
+
+// Targets are the network functions the cases run against.
+type Targets struct {
+	AUSF ausfpb.AusfClient
+	UDM  udmpb.UdmClient
+	// Subscriber is the subscriber the authentication cases provision and
+	// delete, one per case, at consecutive SUPIs.
+	Subscriber Subscriber
+	// AuthTTL is how long the AUSF keeps an authentication context, its
+	// QS_AUSF_AUTH_TTL. The expiry case waits it out, and is skipped when
+	// zero.
+	AuthTTL time.Duration
+	// GNBDU is the gNB-DU the RRC cases go through, which are skipped when
+	// nil. NCI is an active cell of it.
+	GNBDU *GNBDU
+	NCI   uint64
+	// Timeout bounds the calls of every case, the waits of the timer cases
+	// aside.
+	Timeout time.Duration
+}
+
+// Subscriber are the credentials of the subscribers provisioned.
+type Subscriber struct {
+	SUPI               string
+	K, OPc             []byte
+	ServingNetworkName string
+}
+
+// Case is a conformance case.
+type Case struct {
+	// Name is the procedure, then what the case checks of it.
+	Name string
+	// Clause is the clause of the specification the case checks.
+	Clause string
+	// skip returns why the case cannot run against t, if it cannot.
+	skip func(t Targets) string
+	// wait is how long the case waits on top of its calls, beyond
+	// Targets.Timeout.
+	wait func(t Targets) time.Duration
+	run  func(ctx context.Context, c *caseRun) error
+}
+
+// Cases are the conformance cases, those of 5G-AKA then those of the RRC.
+var Cases = append(append([]Case(nil), akaCases...), rrcCases...)
+
+// The outcomes of a case.
+const (
+	Pass = "PASS"
+	Fail = "FAIL"
+	Skip = "SKIP"
+)
+
+// Result is the outcome of a case.
+type Result struct {
+	Name    string        `json:"name"`
+	Clause  string        `json:"clause"`
+	Outcome string        `json:"outcome"`
+	Reason  string        `json:"reason,omitempty"`
+	Elapsed time.Duration `json:"elapsed"`
+}
+
+// Report is the outcome of a run.
+type Report struct {
+	Results []Result      `json:"results"`
+	Elapsed time.Duration `json:"elapsed"`
+}
+
+// Run runs the cases matched by match, all of them when match is nil, one
+// after the other.
+func Run(ctx context.Context, cases []Case, t Targets, match func(name string) bool) *Report {
+	rep := &Report{}
+	begin := time.Now()
+	for i, cs := range cases {
+		if match != nil && !match(cs.Name) {
+			continue
+		}
+		r := Result{Name: cs.Name, Clause: cs.Clause}
+		if reason := skip(cs, t); reason != "" {
+			r.Outcome, r.Reason = Skip, reason
+			rep.Results = append(rep.Results, r)
+			continue
+		}
+		start := time.Now()
+		err := runCase(ctx, cs, &caseRun{t: t, index: i})
+		r.Elapsed = time.Since(start)
+		r.Outcome = Pass
+		if err != nil {
+			r.Outcome, r.Reason = Fail, err.Error()
+		}
+		rep.Results = append(rep.Results, r)
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	rep.Elapsed = time.Since(begin)
+	return rep
+}
+
+func skip(cs Case, t Targets) string {
+	if cs.skip == nil {
+		return ""
+	}
+	return cs.skip(t)
+}
+
+// runCase runs cs, then what it left to clean up.
+func runCase(ctx context.Context, cs Case, c *caseRun) error {
+	defer c.cleanup()
+	if timeout := c.t.Timeout; timeout > 0 {
+		if cs.wait != nil {
+			timeout += cs.wait(c.t)
+		}
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return cs.run(ctx, c)
+}
+
+// caseRun is the state of a case being run.
+type caseRun struct {
+	t Targets
+	// index is the index of the case, which gives the SUPI of its
+	// subscriber.
+	index    int
+	cleanups []func()
+}
+
+// onDone adds f to what is done once the case is over.
+func (c *caseRun) onDone(f func()) {
+	c.cleanups = append(c.cleanups, f)
+}
+
+func (c *caseRun) cleanup() {
+	for i := len(c.cleanups) - 1; i >= 0; i-- {
+		c.cleanups[i]()
+	}
+}
+
+// supi returns the SUPI of the subscriber of the case.
+func (c *caseRun) supi() string {
+	return scenario.UEs{SUPI: c.t.Subscriber.SUPI}.SUPIOf(c.index)
+}
+
+// Failures returns the results of the cases that failed.
+func (r *Report) Failures() []Result {
+	var failed []Result
+	for _, res := range r.Results {
+		if res.Outcome == Fail {
+			failed = append(failed, res)
+		}
+	}
+	return failed
+}
+
+// Write writes the outcome of every case to w, then why the cases failed or
+// were skipped, and whether the run passed.
+func (r *Report) Write(w io.Writer) error {
+	counts := map[string]int{}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "CASE\tCLAUSE\tRESULT\tTIME")
+	for _, res := range r.Results {
+		counts[res.Outcome]++
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%v\n", res.Name, res.Clause, res.Outcome, res.Elapsed.Round(time.Millisecond))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintln(w)
+	for _, res := range r.Results {
+		if res.Reason != "" {
+			fmt.Fprintf(w, "%s: %s: %s\n", res.Name, res.Outcome, res.Reason)
+		}
+	}
+	fmt.Fprintf(w, "%d passed, %d failed, %d skipped in %v\n", counts[Pass], counts[Fail], counts[Skip], r.Elapsed.Round(time.Millisecond))
+	if counts[Fail] != 0 {
+		_, err := fmt.Fprintln(w, Fail)
+		return err
+	}
+	_, err := fmt.Fprintln(w, Pass)
+	return err
+}
+
+// expectCode checks that err is a status of code.
+func expectCode(err error, code codes.Code) error {
+	if err == nil {
+		return fmt.Errorf("expected %v, got success", code)
+	}
+	if got := status.Code(err); got != code {
+		return fmt.Errorf("expected %v, got %v: %v", code, got, err)
+	}
+	return nil
+}
@@ -0,0 +1,239 @@
+package conformance
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/gnodeb/f1"
+)
+
+// rrcCases check the RRC connection of a UE through the split gNB: its setup
+// (TS 38.401 8.1), and how the gNB-CU takes the RRC messages a UE sends out
+// of turn (TS 38.331 5.7.3). The UEs they set up stay in the gNB-DU.
+var rrcCases = []Case{
+	{Name: "rrc/setup", Clause: "TS 38.401 8.1", skip: skipGNBDU, run: rrcSetup},
+	{Name: "rrc/reconfiguration-complete-early", Clause: "TS 38.331 5.7.3", skip: skipGNBDU, run: rrcReconfigurationCompleteEarly},
+	{Name: "rrc/setup-complete-twice", Clause: "TS 38.331 5.7.3", skip: skipGNBDU, run: rrcSetupCompleteTwice},
+	{Name: "rrc/unknown-message", Clause: "TS 38.331 5.7.3", skip: skipGNBDU, run: rrcUnknownMessage},
+	{Name: "rrc/malformed-measurement-report", Clause: "TS 38.331 5.7.3", skip: skipGNBDU, run: rrcMalformedMeasurementReport},
+	{Name: "rrc/unknown-ue", Clause: "TS 38.473 8.4.2", skip: skipGNBDU, run: rrcUnknownUE},
+	{Name: "rrc/cell-not-served", Clause: "TS 38.401 8.1", skip: skipGNBDU, run: rrcCellNotServed},
+}
+
+// GNBDU is the client of the HTTP API of a gNB-DU standing in for the radio.
+type GNBDU struct {
+	url    string
+	client *http.Client
+}
+
+// NewGNBDU returns the client of the gNB-DU at url, such as
+// http://localhost:8680.
+func NewGNBDU(url string) *GNBDU {
+	return &GNBDU{url: strings.TrimSuffix(url, "/"), client: http.DefaultClient}
+}
+
+// StatusError is the error a gNB-DU answered a call with.
+type StatusError struct {
+	Path    string
+	Status  int
+	Message string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("gNB-DU %s: %d %s", e.Path, e.Status, e.Message)
+}
+
+// radioUE is the context of a UE in a gNB-DU.
+type radioUE struct {
+	DUUEID   uint32   `json:"gnb_du_ue_f1ap_id"`
+	Downlink []string `json:"downlink"`
+}
+
+func (g *GNBDU) access(ctx context.Context, nci uint64) (u radioUE, err error) {
+	err = g.call(ctx, "/access", map[string]interface{}{"nci": nci}, &u)
+	return u, err
+}
+
+func (g *GNBDU) uplink(ctx context.Context, duUEID uint32, rrc []byte) error {
+	return g.call(ctx, "/uplink", map[string]interface{}{"gnb_du_ue_f1ap_id": duUEID, "rrc": string(rrc)}, nil)
+}
+
+func (g *GNBDU) ue(ctx context.Context, duUEID uint32) (u radioUE, err error) {
+	err = g.call(ctx, "/ue", map[string]interface{}{"gnb_du_ue_f1ap_id": duUEID}, &u)
+	return u, err
+}
+
+// call posts req to path and decodes the response in res, unless nil. The
+// error of a failed call is a *StatusError.
+func (g *GNBDU) call(ctx context.Context, path string, req, res interface{}) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	r, err := http.NewRequest(http.MethodPost, g.url+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	r.Header.Set("Content-Type", "application/json")
+	resp, err := g.client.Do(r.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		var e struct {
+			Error string `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&e)
+		return &StatusError{Path: path, Status: resp.StatusCode, Message: e.Error}
+	}
+	if res == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(res)
+}
+
+// expectStatus checks that err is the HTTP status code of a gNB-DU.
+func expectStatus(err error, code int) error {
+	if err == nil {
+		return fmt.Errorf("expected %d %s, got success", code, http.StatusText(code))
+	}
+	e, ok := err.(*StatusError)
+	if !ok || e.Status != code {
+		return fmt.Errorf("expected %d %s, got %v", code, http.StatusText(code), err)
+	}
+	return nil
+}
+
+// expectRRC checks that the last RRC message u received is name.
+func expectRRC(u radioUE, name string) error {
+	var last string
+	if n := len(u.Downlink); n > 0 {
+		last, _ = f1.ParseRRC([]byte(u.Downlink[n-1]))
+	}
+	if last != name {
+		return fmt.Errorf("expected %s, got %q", name, last)
+	}
+	return nil
+}
+
+// setupRequest sends the RRC Setup Request of a new UE on the cell of the
+// case, and checks that the RRC Setup comes back.
+func (c *caseRun) setupRequest(ctx context.Context) (uint32, error) {
+	du := c.t.GNBDU
+	u, err := du.access(ctx, c.t.NCI)
+	if err != nil {
+		return 0, err
+	}
+	return u.DUUEID, expectRRC(u, f1.RRCSetup)
+}
+
+// setupComplete completes the RRC Setup of the UE id, and checks that the
+// RRC Reconfiguration comes back.
+func (c *caseRun) setupComplete(ctx context.Context, id uint32) error {
+	du := c.t.GNBDU
+	if err := du.uplink(ctx, id, []byte(f1.RRCSetupComplete)); err != nil {
+		return err
+	}
+	u, err := du.ue(ctx, id)
+	if err != nil {
+		return err
+	}
+	return expectRRC(u, f1.RRCReconfiguration)
+}
+
+// connect sets up the RRC connection of a new UE.
+func (c *caseRun) connect(ctx context.Context) (uint32, error) {
+	id, err := c.setupRequest(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if err := c.setupComplete(ctx, id); err != nil {
+		return 0, err
+	}
+	return id, c.t.GNBDU.uplink(ctx, id, []byte(f1.RRCReconfigurationComplete))
+}
+
+// rrcSetup checks that a UE connects, from the RRC Setup Request to the RRC
+// Reconfiguration Complete.
+func rrcSetup(ctx context.Context, c *caseRun) error {
+	_, err := c.connect(ctx)
+	return err
+}
+
+// rrcReconfigurationCompleteEarly checks that an RRC Reconfiguration
+// Complete sent before the RRC Setup Complete is refused, and leaves the
+// setup to go on.
+func rrcReconfigurationCompleteEarly(ctx context.Context, c *caseRun) error {
+	id, err := c.setupRequest(ctx)
+	if err != nil {
+		return err
+	}
+	err = c.t.GNBDU.uplink(ctx, id, []byte(f1.RRCReconfigurationComplete))
+	if err := expectStatus(err, http.StatusPreconditionFailed); err != nil {
+		return err
+	}
+	if err := c.setupComplete(ctx, id); err != nil {
+		return fmt.Errorf("after the refusal: %v", err)
+	}
+	return nil
+}
+
+// rrcSetupCompleteTwice checks that an RRC Setup Complete sent by a
+// connected UE is refused.
+func rrcSetupCompleteTwice(ctx context.Context, c *caseRun) error {
+	id, err := c.connect(ctx)
+	if err != nil {
+		return err
+	}
+	err = c.t.GNBDU.uplink(ctx, id, []byte(f1.RRCSetupComplete))
+	return expectStatus(err, http.StatusPreconditionFailed)
+}
+
+// rrcUnknownMessage checks that an RRC message the gNB-CU does not know is
+// refused.
+func rrcUnknownMessage(ctx context.Context, c *caseRun) error {
+	id, err := c.connect(ctx)
+	if err != nil {
+		return err
+	}
+	err = c.t.GNBDU.uplink(ctx, id, []byte("RRCConnectionRequest"))
+	return expectStatus(err, http.StatusBadRequest)
+}
+
+// rrcMalformedMeasurementReport checks that a measurement report whose
+// results cannot be read is refused.
+func rrcMalformedMeasurementReport(ctx context.Context, c *caseRun) error {
+	id, err := c.connect(ctx)
+	if err != nil {
+		return err
+	}
+	err = c.t.GNBDU.uplink(ctx, id, []byte(f1.MeasurementReport+" {"))
+	return expectStatus(err, http.StatusBadRequest)
+}
+
+// rrcUnknownUE checks that an RRC message of a UE the gNB-DU has no context
+// for is refused.
+func rrcUnknownUE(ctx context.Context, c *caseRun) error {
+	err := c.t.GNBDU.uplink(ctx, 1<<32-1, []byte(f1.RRCSetupComplete))
+	return expectStatus(err, http.StatusNotFound)
+}
+
+// rrcCellNotServed checks that a UE cannot access a cell the gNB-DU does not
+// serve.
+func rrcCellNotServed(ctx context.Context, c *caseRun) error {
+	// the largest NCI, 36 bits
+	_, err := c.t.GNBDU.access(ctx, 1<<36-1)
+	return expectStatus(err, http.StatusNotFound)
+}
+
+func skipGNBDU(t Targets) string {
+	if t.GNBDU == nil {
+		return "no gNB-DU is given"
+	}
+	return ""
+}
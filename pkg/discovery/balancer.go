@@ -0,0 +1,393 @@
+package discovery
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc/attributes"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Balancer is the name of the balancer splitting the calls between groups
+// of endpoints by weight, the versions of the pods of a k8s target or the
+// clusters of an xds one, then between the endpoints of a group by weight,
+// or round robin when they have none. The balancers named Balancer_p2c and
+// Balancer_ewma pick the endpoints without a weight by their load instead.
+// The addresses resolved for it carry their group and its Source, see
+// Attributes.
+const Balancer = "weighted"
+
+// The picks of an endpoint among those of its group, given in the balancer
+// parameter of a k8s target.
+const (
+	// RoundRobin takes the endpoints in turn, the default.
+	RoundRobin = "round_robin"
+	// P2C draws two endpoints and takes the one with the fewest calls in
+	// flight, the power of two choices. It spreads the calls nearly as well
+	// as the least loaded endpoint would, without sending every concurrent
+	// call to the same idle one.
+	P2C = "p2c"
+	// EWMA takes the endpoint with the lowest expected wait, its average
+	// latency times its calls in flight plus one, so that a slow endpoint
+	// gets fewer calls. The endpoints not called yet are tried first.
+	EWMA = "ewma"
+)
+
+// decayTime is the time constant of the latency averages: an observation
+// weighs about a third after decayTime, and next to nothing after three.
+const decayTime = 10 * time.Second
+
+// ServiceConfig returns the service config of the connections picking the
+// endpoints of a group with pick, which the resolvers hand them.
+func ServiceConfig(pick string) string {
+	return `{"loadBalancingPolicy": "` + balancerName(pick) + `"}`
+}
+
+func balancerName(pick string) string {
+	if pick == RoundRobin || pick == "" {
+		return Balancer
+	}
+	return Balancer + "_" + pick
+}
+
+// parsePick checks the pick of a target, RoundRobin when empty.
+func parsePick(pick string) (string, error) {
+	switch pick {
+	case "":
+		return RoundRobin, nil
+	case RoundRobin, P2C, EWMA:
+		return pick, nil
+	}
+	return "", fmt.Errorf("unknown balancer %q", pick)
+}
+
+func init() {
+	for _, pick := range []string{RoundRobin, P2C, EWMA} {
+		balancer.Register(builder{name: balancerName(pick), pick: pick})
+	}
+}
+
+// Source is the target the addresses of a resolver come from, which
+// weighs their groups and endpoints, and is told of the picks. Its methods
+// are called with it locked, the weights thus being read on every pick, so
+// that they take effect without the connections being rebuilt.
+type Source interface {
+	sync.Locker
+	// GroupWeight returns the weight of a group, 0 for none.
+	GroupWeight(group string) uint32
+	// Weight returns the weight of the endpoint addr of group, 0 for the
+	// endpoints of a group all equal.
+	Weight(group, addr string) uint32
+	// Ejected tells whether the endpoint addr of group takes no calls for
+	// now, unless all the endpoints are ejected.
+	Ejected(group, addr string, now time.Time) bool
+	// Picked counts a pick of the endpoint addr of group, and returns the
+	// function to call with the error of the call once done, if any.
+	Picked(group, addr string) func(err error)
+}
+
+// The keys of the attributes of the addresses resolved for the balancer.
+type (
+	sourceKey struct{}
+	groupKey  struct{}
+)
+
+// Attributes returns the attributes of an address of group, from the
+// target s.
+func Attributes(s Source, group string) *attributes.Attributes {
+	return attributes.New(sourceKey{}, s, groupKey{}, group)
+}
+
+// builder builds the balancer of a connection, its picker builder keeping
+// the load of the endpoints from one picker to the next.
+type builder struct {
+	name, pick string
+}
+
+func (b builder) Name() string {
+	return b.name
+}
+
+func (b builder) Build(cc balancer.ClientConn, opts balancer.BuildOptions) balancer.Balancer {
+	pb := &pickerBuilder{pick: b.pick, loads: map[balancer.SubConn]*load{}}
+	return base.NewBalancerBuilderV2(b.name, pb, base.Config{}).Build(cc, opts)
+}
+
+// pickerBuilder builds the pickers of a connection, one at a time.
+type pickerBuilder struct {
+	pick  string
+	loads map[balancer.SubConn]*load
+}
+
+// Build returns the picker of the ready endpoints, grouped. The loads of
+// the endpoints no longer ready are dropped.
+func (b *pickerBuilder) Build(info base.PickerBuildInfo) balancer.V2Picker {
+	if len(info.ReadySCs) == 0 {
+		b.loads = map[balancer.SubConn]*load{}
+		return base.NewErrPickerV2(balancer.ErrNoSubConnAvailable)
+	}
+	p := &picker{pick: b.pick, groups: map[string][]balancer.SubConn{}, endpoints: map[balancer.SubConn]endpoint{}, next: map[string]int{}}
+	if b.pick != RoundRobin {
+		loads := make(map[balancer.SubConn]*load, len(info.ReadySCs))
+		for sc := range info.ReadySCs {
+			if loads[sc] = b.loads[sc]; loads[sc] == nil {
+				loads[sc] = &load{}
+			}
+		}
+		b.loads, p.loads = loads, loads
+	}
+	for sc, sci := range info.ReadySCs {
+		group, _ := sci.Address.Attributes.Value(groupKey{}).(string)
+		p.groups[group] = append(p.groups[group], sc)
+		p.endpoints[sc] = endpoint{group: group, addr: sci.Address.Addr}
+		p.all = append(p.all, sc)
+		if p.s == nil {
+			p.s, _ = sci.Address.Attributes.Value(sourceKey{}).(Source)
+		}
+	}
+	if p.s == nil {
+		return base.NewErrPickerV2(balancer.ErrNoSubConnAvailable)
+	}
+	for group := range p.groups {
+		p.names = append(p.names, group)
+	}
+	sort.Strings(p.names)
+	// the endpoints in the order of their addresses, for the draws and the
+	// round robin
+	byAddr := func(scs []balancer.SubConn) {
+		sort.Slice(scs, func(i, j int) bool { return p.endpoints[scs[i]].addr < p.endpoints[scs[j]].addr })
+	}
+	byAddr(p.all)
+	for _, scs := range p.groups {
+		byAddr(scs)
+	}
+	return p
+}
+
+// endpoint is the group and the address of a SubConn.
+type endpoint struct {
+	group, addr string
+}
+
+// picker picks a group by weight among those with endpoints not ejected,
+// then an endpoint of the group by weight, round robin or by load. When no
+// group has a weight, or a ready endpoint, the endpoints are all in the
+// group "*".
+type picker struct {
+	s         Source
+	pick      string
+	groups    map[string][]balancer.SubConn
+	endpoints map[balancer.SubConn]endpoint
+	names     []string
+	all       []balancer.SubConn
+	// loads are those of the endpoints, but with RoundRobin
+	loads map[balancer.SubConn]*load
+
+	mtx  sync.Mutex
+	next map[string]int
+}
+
+func (p *picker) Pick(balancer.PickInfo) (balancer.PickResult, error) {
+	now := time.Now()
+	p.s.Lock()
+	group, scs := p.group(now, true)
+	if scs == nil {
+		group, scs = p.group(now, false)
+	}
+	if scs == nil {
+		group, scs = "*", p.all
+	}
+	sc := p.weighted(scs)
+	if sc == nil {
+		switch p.pick {
+		case P2C:
+			sc = p.p2c(scs)
+		case EWMA:
+			sc = p.leastWait(scs)
+		default:
+			sc = p.roundRobin(group, scs)
+		}
+	}
+	e := p.endpoints[sc]
+	picked := p.s.Picked(e.group, e.addr)
+	p.s.Unlock()
+
+	l := p.loads[sc]
+	if l == nil && picked == nil {
+		return balancer.PickResult{SubConn: sc}, nil
+	}
+	if l != nil {
+		atomic.AddInt64(&l.inflight, 1)
+	}
+	start := time.Now()
+	return balancer.PickResult{SubConn: sc, Done: func(di balancer.DoneInfo) {
+		if l != nil {
+			l.done(start, di)
+		}
+		if picked != nil {
+			picked(di.Err)
+		}
+	}}, nil
+}
+
+// group returns a group drawn by weight among those with ready endpoints,
+// and these endpoints, the ejected ones aside when eject is set.
+func (p *picker) group(now time.Time, eject bool) (string, []balancer.SubConn) {
+	total := uint32(0)
+	ready := make(map[string][]balancer.SubConn, len(p.names))
+	for _, group := range p.names {
+		w := p.s.GroupWeight(group)
+		if w == 0 {
+			continue
+		}
+		scs := p.groups[group]
+		if eject {
+			scs = nil
+			for _, sc := range p.groups[group] {
+				if !p.s.Ejected(group, p.endpoints[sc].addr, now) {
+					scs = append(scs, sc)
+				}
+			}
+		}
+		if len(scs) > 0 {
+			ready[group] = scs
+			total += w
+		}
+	}
+	if total == 0 {
+		return "", nil
+	}
+	n := uint32(rand.Int63n(int64(total)))
+	for _, group := range p.names {
+		scs, ok := ready[group]
+		if !ok {
+			continue
+		}
+		if w := p.s.GroupWeight(group); n >= w {
+			n -= w
+			continue
+		}
+		return group, scs
+	}
+	panic("unreachable")
+}
+
+// weighted returns an endpoint of scs drawn by weight, nil when none has a
+// weight.
+func (p *picker) weighted(scs []balancer.SubConn) balancer.SubConn {
+	total := uint32(0)
+	for _, sc := range scs {
+		e := p.endpoints[sc]
+		total += p.s.Weight(e.group, e.addr)
+	}
+	if total == 0 {
+		return nil
+	}
+	n := uint32(rand.Int63n(int64(total)))
+	for _, sc := range scs {
+		e := p.endpoints[sc]
+		w := p.s.Weight(e.group, e.addr)
+		if n < w {
+			return sc
+		}
+		n -= w
+	}
+	panic("unreachable")
+}
+
+// roundRobin returns the next endpoint of scs, those of group.
+func (p *picker) roundRobin(group string, scs []balancer.SubConn) balancer.SubConn {
+	p.mtx.Lock()
+	i := p.next[group]
+	p.next[group] = i + 1
+	p.mtx.Unlock()
+	return scs[i%len(scs)]
+}
+
+// p2c returns the one of two endpoints of scs drawn at random with the
+// fewest calls in flight.
+func (p *picker) p2c(scs []balancer.SubConn) balancer.SubConn {
+	if len(scs) == 1 {
+		return scs[0]
+	}
+	i := rand.Intn(len(scs))
+	j := rand.Intn(len(scs) - 1)
+	if j >= i {
+		j++
+	}
+	a, b := scs[i], scs[j]
+	if atomic.LoadInt64(&p.loads[b].inflight) < atomic.LoadInt64(&p.loads[a].inflight) {
+		return b
+	}
+	return a
+}
+
+// leastWait returns the endpoint of scs with the lowest expected wait.
+func (p *picker) leastWait(scs []balancer.SubConn) balancer.SubConn {
+	var (
+		best     balancer.SubConn
+		bestCost float64
+		bestLoad int64
+	)
+	for _, sc := range scs {
+		l := p.loads[sc]
+		inflight := atomic.LoadInt64(&l.inflight)
+		cost := float64(l.latency.value()) * float64(inflight+1)
+		if best == nil || cost < bestCost || (cost == bestCost && inflight < bestLoad) {
+			best, bestCost, bestLoad = sc, cost, inflight
+		}
+	}
+	return best
+}
+
+// load is the load of an endpoint, as seen by a connection: its calls in
+// flight and their latency.
+type load struct {
+	inflight int64
+	latency  ewma
+}
+
+// done ends a call started at start. The calls cancelled by the caller, such
+// as the losers of a hedge, say nothing of the latency of the endpoint.
+func (l *load) done(start time.Time, di balancer.DoneInfo) {
+	atomic.AddInt64(&l.inflight, -1)
+	if status.Code(di.Err) == codes.Canceled {
+		return
+	}
+	now := time.Now()
+	l.latency.observe(now.Sub(start), now)
+}
+
+// ewma is a peak-sensitive, time decaying average of latencies.
+type ewma struct {
+	mtx  sync.Mutex
+	avg  float64
+	last time.Time
+}
+
+func (e *ewma) observe(d time.Duration, now time.Time) {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+	sample := float64(d)
+	if e.last.IsZero() || sample > e.avg {
+		e.avg = sample
+	} else {
+		w := math.Exp(-float64(now.Sub(e.last)) / float64(decayTime))
+		e.avg = e.avg*w + sample*(1-w)
+	}
+	e.last = now
+}
+
+func (e *ewma) value() time.Duration {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+	return time.Duration(e.avg)
+}
@@ -0,0 +1,447 @@
+// Package discovery resolves the gRPC targets of the k8s scheme,
+//
+//	k8s:///udm.default:8381
+//
+// to the ready pods of a Kubernetes service, read from the API server, so
+// that a client balances its calls over the pods itself rather than through
+// the virtual IP of the service. The pods are read again every refresh
+// period, and whenever gRPC asks for it.
+//
+// The pods carry their version in a label, version=v2 by default, and the
+// calls are split between the versions by weight, for the progressive
+// delivery of a new version at the client-library level, without a service
+// mesh. The weights are given in the target,
+//
+//	k8s:///udm.default:8381?weights=v1:90,v2:10
+//
+// and changed at runtime on the admin port (see Handler). Within a version
+// the calls go round robin over its pods, or to the pod the balancer of the
+// target picks by load, P2C or EWMA:
+//
+//	k8s:///udm.default:8381?weights=v1:90,v2:10&balancer=ewma
+//
+// Without weights the pods are all equal; with them, the pods of a version
+// without a weight take no calls, unless no version with a weight has a
+// ready pod. A new version thus takes no traffic until it is given a
+// weight.
+package discovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/serviceconfig"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/operator"
+)
+
+// Scheme is the scheme of the targets resolved.
+const Scheme = "k8s"
+
+// Default values of the options.
+const (
+	DefaultRefresh      = 10 * time.Second
+	DefaultVersionLabel = "version"
+)
+
+// resolveTimeout bounds the calls to the API server of a resolution.
+const resolveTimeout = 10 * time.Second
+
+// Option sets an optional parameter of a Discovery.
+type Option func(*Discovery)
+
+// Refresh sets the period the pods are read again at.
+func Refresh(interval time.Duration) Option {
+	return func(d *Discovery) { d.refresh = interval }
+}
+
+// VersionLabel sets the label of the pods giving their version.
+func VersionLabel(key string) Option {
+	return func(d *Discovery) { d.versionLabel = key }
+}
+
+// Logger sets the logger of the failed resolutions.
+func Logger(logger log.Logger) Option {
+	return func(d *Discovery) { d.logger = logger }
+}
+
+// Discovery is the resolver.Builder of the k8s scheme, to be passed to
+// grpc.WithResolvers. It keeps the targets it resolves, for their weights to
+// be changed and their stats read.
+type Discovery struct {
+	client       *operator.Client
+	refresh      time.Duration
+	versionLabel string
+	logger       log.Logger
+
+	mtx     sync.Mutex
+	targets map[string]*target
+}
+
+// New returns the Discovery reading the pods with client. A nil client, out
+// of a cluster, fails the dials of the k8s targets.
+func New(client *operator.Client, options ...Option) *Discovery {
+	d := &Discovery{
+		client:       client,
+		refresh:      DefaultRefresh,
+		versionLabel: DefaultVersionLabel,
+		logger:       log.NewNopLogger(),
+		targets:      map[string]*target{},
+	}
+	for _, o := range options {
+		o(d)
+	}
+	return d
+}
+
+// Scheme returns the k8s scheme.
+func (d *Discovery) Scheme() string {
+	return Scheme
+}
+
+// Build starts resolving the target t of cc.
+func (d *Discovery) Build(t resolver.Target, cc resolver.ClientConn, _ resolver.BuildOptions) (resolver.Resolver, error) {
+	if d.client == nil {
+		return nil, operator.ErrNotInCluster
+	}
+	name, namespace, port, weights, pick, err := parseTarget(t.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	key := name + "." + namespace + ":" + port
+	d.mtx.Lock()
+	tg, ok := d.targets[key]
+	if !ok {
+		// the connections of a pool share the target, and its weights
+		tg = &target{key: key, name: name, namespace: namespace, port: port, weights: weights, picks: map[string]int64{}}
+		d.targets[key] = tg
+	}
+	tg.resolvers++
+	d.mtx.Unlock()
+
+	r := &k8sResolver{
+		d:      d,
+		t:      tg,
+		cc:     cc,
+		config: cc.ParseServiceConfig(ServiceConfig(pick)),
+		addrs:  map[string]resolver.Address{},
+		now:    make(chan struct{}, 1),
+		quit:   make(chan struct{}),
+	}
+	go r.run()
+	return r, nil
+}
+
+// parseTarget splits the endpoint of a target, service.namespace:port, its
+// weights and its pick of the pods, the namespace being that of the pod when
+// omitted.
+func parseTarget(endpoint string) (name, namespace, port string, weights map[string]int, pick string, err error) {
+	hostport, query := endpoint, ""
+	if i := strings.IndexByte(endpoint, '?'); i >= 0 {
+		hostport, query = endpoint[:i], endpoint[i+1:]
+	}
+	host, port, err := net.SplitHostPort(hostport)
+	if err != nil {
+		return "", "", "", nil, "", fmt.Errorf("discovery: %s: %v", endpoint, err)
+	}
+	if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+		return "", "", "", nil, "", fmt.Errorf("discovery: %s: port %q is not a number", endpoint, port)
+	}
+	name, namespace = host, operator.InClusterNamespace()
+	if i := strings.IndexByte(host, '.'); i >= 0 {
+		name, namespace = host[:i], host[i+1:]
+	}
+	if namespace == "" {
+		namespace = "default"
+	}
+	q, err := url.ParseQuery(query)
+	if err != nil {
+		return "", "", "", nil, "", fmt.Errorf("discovery: %s: %v", endpoint, err)
+	}
+	if weights, err = ParseWeights(q.Get("weights")); err != nil {
+		return "", "", "", nil, "", fmt.Errorf("discovery: %s: %v", endpoint, err)
+	}
+	if pick, err = parsePick(q.Get("balancer")); err != nil {
+		return "", "", "", nil, "", fmt.Errorf("discovery: %s: %v", endpoint, err)
+	}
+	return name, namespace, port, weights, pick, nil
+}
+
+// ParseWeights parses the weights of the versions, such as "v1:90,v2:10",
+// nil when s is empty.
+func ParseWeights(s string) (map[string]int, error) {
+	if s == "" {
+		return nil, nil
+	}
+	weights := map[string]int{}
+	for _, vw := range strings.Split(s, ",") {
+		i := strings.LastIndexByte(vw, ':')
+		if i < 1 {
+			return nil, fmt.Errorf("weight %q is not version:weight", vw)
+		}
+		w, err := strconv.Atoi(vw[i+1:])
+		if err != nil || w < 0 {
+			return nil, fmt.Errorf("weight %q is not a positive number", vw)
+		}
+		weights[vw[:i]] = w
+	}
+	return weights, nil
+}
+
+// SetWeights sets the weights of the versions of the pods of target, the
+// service.namespace:port of a target dialed, effective with the next call.
+// Nil weights make the pods all equal.
+func (d *Discovery) SetWeights(target string, weights map[string]int) error {
+	for v, w := range weights {
+		if w < 0 {
+			return fmt.Errorf("discovery: negative weight %d of version %s", w, v)
+		}
+	}
+	d.mtx.Lock()
+	t, ok := d.targets[target]
+	d.mtx.Unlock()
+	if !ok {
+		return fmt.Errorf("discovery: target %s not dialed", target)
+	}
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	t.weights = weights
+	return nil
+}
+
+// target is a service dialed, shared by the connections dialing it.
+type target struct {
+	key, name, namespace, port string
+	// resolvers counts the connections dialing the target, under the mtx
+	// of the Discovery.
+	resolvers int
+
+	mtx      sync.Mutex
+	weights  map[string]int
+	pods     map[string]int
+	picks    map[string]int64
+	resolved time.Time
+	err      error
+}
+
+// Lock and Unlock lock the target, for the picks of the balancer.
+func (t *target) Lock()   { t.mtx.Lock() }
+func (t *target) Unlock() { t.mtx.Unlock() }
+
+// GroupWeight returns the weight of the version v.
+func (t *target) GroupWeight(v string) uint32 {
+	return uint32(t.weights[v])
+}
+
+// Weight returns 0, the pods of a version being equal.
+func (t *target) Weight(v, addr string) uint32 {
+	return 0
+}
+
+// Ejected returns false, the pods not being ejected.
+func (t *target) Ejected(v, addr string, now time.Time) bool {
+	return false
+}
+
+// Picked counts the calls sent to the version v.
+func (t *target) Picked(v, addr string) func(error) {
+	t.picks[v]++
+	return nil
+}
+
+// TargetStats are the pods of a target dialed, by version, and the calls
+// sent to them.
+type TargetStats struct {
+	Target   string           `json:"target"`
+	Weights  map[string]int   `json:"weights"`
+	Pods     map[string]int   `json:"pods"`
+	Picks    map[string]int64 `json:"picks"`
+	Resolved time.Time        `json:"resolved"`
+	Error    string           `json:"error,omitempty"`
+}
+
+// Stats returns the stats of the targets dialed, by target.
+func (d *Discovery) Stats() []TargetStats {
+	d.mtx.Lock()
+	targets := make([]*target, 0, len(d.targets))
+	for _, t := range d.targets {
+		targets = append(targets, t)
+	}
+	d.mtx.Unlock()
+	stats := make([]TargetStats, 0, len(targets))
+	for _, t := range targets {
+		t.mtx.Lock()
+		s := TargetStats{Target: t.key, Weights: t.weights, Pods: t.pods, Picks: map[string]int64{}, Resolved: t.resolved}
+		for v, n := range t.picks {
+			s.Picks[v] = n
+		}
+		if t.err != nil {
+			s.Error = t.err.Error()
+		}
+		t.mtx.Unlock()
+		stats = append(stats, s)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Target < stats[j].Target })
+	return stats
+}
+
+// service and podList are the fields read of the Service and Pod objects.
+type service struct {
+	Spec struct {
+		Selector map[string]string `json:"selector"`
+	} `json:"spec"`
+}
+
+type podList struct {
+	Items []struct {
+		Metadata struct {
+			Name              string            `json:"name"`
+			Labels            map[string]string `json:"labels"`
+			DeletionTimestamp *time.Time        `json:"deletionTimestamp"`
+		} `json:"metadata"`
+		Status struct {
+			PodIP      string      `json:"podIP"`
+			Conditions []condition `json:"conditions"`
+		} `json:"status"`
+	} `json:"items"`
+}
+
+type condition struct {
+	Type   string `json:"type"`
+	Status string `json:"status"`
+}
+
+// k8sResolver resolves a target for a gRPC connection.
+type k8sResolver struct {
+	d      *Discovery
+	t      *target
+	cc     resolver.ClientConn
+	config *serviceconfig.ParseResult
+	// addrs are the addresses of the pods by address and version, kept so
+	// that the balancer does not replace the connections to the pods that
+	// stay.
+	addrs map[string]resolver.Address
+	now   chan struct{}
+	quit  chan struct{}
+	once  sync.Once
+}
+
+func (r *k8sResolver) ResolveNow(resolver.ResolveNowOptions) {
+	select {
+	case r.now <- struct{}{}:
+	default:
+	}
+}
+
+func (r *k8sResolver) Close() {
+	r.once.Do(func() {
+		close(r.quit)
+		d := r.d
+		d.mtx.Lock()
+		defer d.mtx.Unlock()
+		if r.t.resolvers--; r.t.resolvers == 0 {
+			delete(d.targets, r.t.key)
+		}
+	})
+}
+
+func (r *k8sResolver) run() {
+	for {
+		r.resolve()
+		select {
+		case <-r.quit:
+			return
+		case <-r.now:
+		case <-time.After(r.d.refresh):
+		}
+	}
+}
+
+// resolve reads the ready pods of the service, and updates the connection
+// with their addresses. A failed read keeps the addresses of the last one.
+func (r *k8sResolver) resolve() {
+	t := r.t
+	ctx, cancel := context.WithTimeout(context.Background(), resolveTimeout)
+	defer cancel()
+	addrs, pods, err := r.pods(ctx)
+	t.mtx.Lock()
+	t.err = err
+	if err == nil {
+		t.pods, t.resolved = pods, time.Now().UTC()
+	}
+	t.mtx.Unlock()
+	if err != nil {
+		level.Warn(r.d.logger).Log("discovery", t.key, "err", err)
+		if len(r.addrs) == 0 {
+			r.cc.ReportError(err)
+		}
+		return
+	}
+	r.cc.UpdateState(resolver.State{Addresses: addrs, ServiceConfig: r.config})
+}
+
+// pods returns the addresses of the ready pods of the service, and their
+// number by version.
+func (r *k8sResolver) pods(ctx context.Context) ([]resolver.Address, map[string]int, error) {
+	t := r.t
+	var svc service
+	if err := r.d.client.Get(ctx, "v1", "Service", t.namespace, t.name, &svc); err != nil {
+		return nil, nil, err
+	}
+	if len(svc.Spec.Selector) == 0 {
+		return nil, nil, errors.New("discovery: service " + t.namespace + "/" + t.name + " has no selector")
+	}
+	selector := make([]string, 0, len(svc.Spec.Selector))
+	for k, v := range svc.Spec.Selector {
+		selector = append(selector, k+"="+v)
+	}
+	sort.Strings(selector)
+	var list podList
+	if err := r.d.client.List(ctx, "v1", "Pod", t.namespace, strings.Join(selector, ","), &list); err != nil {
+		return nil, nil, err
+	}
+	addrs := make([]resolver.Address, 0, len(list.Items))
+	pods := map[string]int{}
+	kept := make(map[string]resolver.Address, len(list.Items))
+	for _, p := range list.Items {
+		if p.Status.PodIP == "" || p.Metadata.DeletionTimestamp != nil || !ready(p.Status.Conditions) {
+			continue
+		}
+		version := p.Metadata.Labels[r.d.versionLabel]
+		hostport := net.JoinHostPort(p.Status.PodIP, t.port)
+		key := hostport + "/" + version
+		a, ok := r.addrs[key]
+		if !ok {
+			a = resolver.Address{
+				Addr:       hostport,
+				ServerName: t.name,
+				Attributes: Attributes(t, version),
+			}
+		}
+		kept[key] = a
+		addrs = append(addrs, a)
+		pods[version]++
+	}
+	r.addrs = kept
+	return addrs, pods, nil
+}
+
+func ready(conditions []condition) bool {
+	for _, c := range conditions {
+		if c.Type == "Ready" {
+			return c.Status == "True"
+		}
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+package discovery
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Path is where services mount Handler on their admin server.
+const Path = "/admin/discovery"
+
+// SetRequest is the body of a PUT to Handler.
+type SetRequest struct {
+	// Target is the service.namespace:port of a target dialed.
+	Target string `json:"target"`
+	// Weights are the weights of its versions, the pods all being equal
+	// when empty.
+	Weights map[string]int `json:"weights"`
+}
+
+type errorWrapper struct {
+	Error string `json:"error"`
+}
+
+// Handler serves the targets of d over HTTP:
+//
+//	GET    lists the targets dialed, their pods and weights, and the calls
+//	       sent to every version
+//	PUT    sets the weights of a target from a SetRequest body
+func Handler(d *Discovery) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.Method {
+		case http.MethodGet:
+		case http.MethodPut, http.MethodPost:
+			var sr SetRequest
+			if err := json.NewDecoder(req.Body).Decode(&sr); err != nil {
+				writeError(w, http.StatusBadRequest, err)
+				return
+			}
+			if len(sr.Weights) == 0 {
+				sr.Weights = nil
+			}
+			if err := d.SetWeights(sr.Target, sr.Weights); err != nil {
+				writeError(w, http.StatusBadRequest, err)
+				return
+			}
+		default:
+			w.Header().Set("Allow", "GET, PUT")
+			writeError(w, http.StatusMethodNotAllowed, nil)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(d.Stats())
+	})
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	msg := http.StatusText(code)
+	if err != nil {
+		msg = err.Error()
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(errorWrapper{Error: msg})
+}
@@ -0,0 +1,295 @@
+// Package drain stops a network function without dropping the calls of the
+// NFs it serves. When the NFs of a namespace stop together, an NF drains
+// only once the NFs calling it are drained, so that their last calls are
+// served: a UDM waits for the AUSFs, a gNB-CU for its gNB-DUs. The NF then
+// refuses the new calls, reports itself as not serving on the health
+// service, and waits for the calls in flight.
+//
+// A Coordinator drains the NF. Its interceptors and HTTP handler count the
+// calls in flight and refuse the new ones while it drains. Its gRPC server
+// tells the NFs waiting on it how far it is, and its HTTP handler starts the
+// drain from the preStop hook of the pod:
+//
+//	lifecycle:
+//	  preStop:
+//	    httpGet:
+//	      path: /admin/drain
+//	      port: 6022
+package drain
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/miki-tnt/sa5g-go-usvc-k8s/pb/drain"
+)
+
+// The defaults of a Coordinator. The timeout leaves the NF time to stop
+// within the 30s Kubernetes grants a pod by default.
+const (
+	DefaultTimeout  = 25 * time.Second
+	DefaultInterval = 500 * time.Millisecond
+)
+
+// ErrDraining is returned for the calls refused while the NF drains.
+var ErrDraining = status.Error(codes.Unavailable, "drain: the NF is draining")
+
+// Coordinator drains an NF.
+type Coordinator struct {
+	health   *health.Server
+	logger   log.Logger
+	after    []func() []string
+	timeout  time.Duration
+	interval time.Duration
+	dial     []grpc.DialOption
+
+	once sync.Once
+	// done is closed once the NF is drained.
+	done chan struct{}
+
+	mtx      sync.Mutex
+	state    pb.State
+	inflight int64
+	// idle is closed once the NF drains with no call in flight.
+	idle chan struct{}
+	// waited is the last state known of the NFs waited for.
+	waited map[string]pb.State
+}
+
+// Option sets an optional parameter of a Coordinator.
+type Option func(*Coordinator)
+
+// After adds the NFs, by gRPC address, to be drained before this one.
+func After(addresses ...string) Option {
+	return func(c *Coordinator) {
+		c.after = append(c.after, func() []string { return addresses })
+	}
+}
+
+// AfterFunc adds the NFs to be drained before this one, as known when the
+// drain starts: the gNB-DUs that set up the F1 with a gNB-CU, for example.
+func AfterFunc(addresses func() []string) Option {
+	return func(c *Coordinator) {
+		c.after = append(c.after, addresses)
+	}
+}
+
+// Timeout bounds the drain, the wait for the other NFs included.
+// DefaultTimeout by default.
+func Timeout(d time.Duration) Option {
+	return func(c *Coordinator) {
+		c.timeout = d
+	}
+}
+
+// Interval sets how often the NFs waited for are asked how far they are.
+// DefaultInterval by default.
+func Interval(d time.Duration) Option {
+	return func(c *Coordinator) {
+		c.interval = d
+	}
+}
+
+// DialOptions sets the options of the connections to the NFs waited for.
+func DialOptions(opts ...grpc.DialOption) Option {
+	return func(c *Coordinator) {
+		c.dial = append(c.dial, opts...)
+	}
+}
+
+// New returns the coordinator of the NF whose health hs reports, nil to
+// report none.
+func New(hs *health.Server, logger log.Logger, options ...Option) *Coordinator {
+	c := &Coordinator{
+		health:   hs,
+		logger:   logger,
+		timeout:  DefaultTimeout,
+		interval: DefaultInterval,
+		done:     make(chan struct{}),
+		idle:     make(chan struct{}),
+		waited:   map[string]pb.State{},
+	}
+	for _, option := range options {
+		option(c)
+	}
+	if len(c.dial) == 0 {
+		c.dial = []grpc.DialOption{grpc.WithInsecure()}
+	}
+	return c
+}
+
+// Drain drains the NF, if it is not already, and returns once it is drained
+// or ctx is done. The drain itself goes on until done or timed out.
+func (c *Coordinator) Drain(ctx context.Context) pb.State {
+	c.once.Do(func() { go c.drain() })
+	select {
+	case <-c.done:
+	case <-ctx.Done():
+	}
+	return c.State()
+}
+
+// Done returns a channel closed once the NF is drained.
+func (c *Coordinator) Done() <-chan struct{} {
+	return c.done
+}
+
+// drain waits for the NFs to be drained first, then refuses the new calls
+// and waits for those in flight.
+func (c *Coordinator) drain() {
+	begin := time.Now()
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	var addresses []string
+	for _, after := range c.after {
+		addresses = append(addresses, after()...)
+	}
+	c.setState(pb.State_WAITING)
+	level.Info(c.logger).Log("drain", pb.State_WAITING, "after", len(addresses))
+	var wg sync.WaitGroup
+	for _, address := range addresses {
+		wg.Add(1)
+		go func(address string) {
+			defer wg.Done()
+			c.wait(ctx, address)
+		}(address)
+	}
+	wg.Wait()
+
+	c.mtx.Lock()
+	c.state = pb.State_DRAINING
+	if c.inflight == 0 {
+		c.closeIdle()
+	}
+	c.mtx.Unlock()
+	if c.health != nil {
+		c.health.Shutdown()
+	}
+	level.Info(c.logger).Log("drain", pb.State_DRAINING, "inflight", c.stats().Inflight)
+	select {
+	case <-c.idle:
+	case <-ctx.Done():
+		level.Warn(c.logger).Log("drain", "timeout", "inflight", c.stats().Inflight)
+	}
+
+	c.setState(pb.State_DRAINED)
+	level.Info(c.logger).Log("drain", pb.State_DRAINED, "took", time.Since(begin))
+	close(c.done)
+}
+
+// wait waits for the NF at address to be drained. An NF that cannot be
+// reached, or that has no drain, is taken as drained: it is gone already, or
+// will not tell.
+func (c *Coordinator) wait(ctx context.Context, address string) {
+	conn, err := grpc.DialContext(ctx, address, c.dial...)
+	if err != nil {
+		c.waitedFor(address, pb.State_DRAINED)
+		return
+	}
+	defer conn.Close()
+	client := pb.NewDrainClient(conn)
+	for {
+		st, err := client.Status(ctx, &pb.StatusRequest{})
+		switch {
+		case ctx.Err() != nil:
+			level.Warn(c.logger).Log("drain", "timeout", "after", address)
+			return
+		case err != nil:
+			level.Debug(c.logger).Log("drain", "gone", "after", address, "err", err)
+			c.waitedFor(address, pb.State_DRAINED)
+			return
+		}
+		c.waitedFor(address, st.State)
+		if st.State == pb.State_DRAINED {
+			return
+		}
+		select {
+		case <-time.After(c.interval):
+		case <-ctx.Done():
+		}
+	}
+}
+
+func (c *Coordinator) waitedFor(address string, state pb.State) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.waited[address] = state
+}
+
+func (c *Coordinator) setState(state pb.State) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.state = state
+}
+
+// State returns how far the NF is.
+func (c *Coordinator) State() pb.State {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return c.state
+}
+
+// begin counts a call in, unless the NF drains.
+func (c *Coordinator) begin() bool {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if c.state >= pb.State_DRAINING {
+		return false
+	}
+	c.inflight++
+	return true
+}
+
+// end counts a call out.
+func (c *Coordinator) end() {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.inflight--
+	if c.inflight == 0 && c.state == pb.State_DRAINING {
+		c.closeIdle()
+	}
+}
+
+// closeIdle closes idle, once.
+func (c *Coordinator) closeIdle() {
+	select {
+	case <-c.idle:
+	default:
+		close(c.idle)
+	}
+}
+
+// stats returns the state of the NF, of the calls in flight and of the NFs
+// waited for.
+func (c *Coordinator) stats() *pb.StatusReply {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	after := make(map[string]pb.State, len(c.waited))
+	for address, state := range c.waited {
+		after[address] = state
+	}
+	return &pb.StatusReply{State: c.state, Inflight: c.inflight, After: after}
+}
+
+// Stats returns the state of the drain, for the admin port.
+func (c *Coordinator) Stats() interface{} {
+	st := c.stats()
+	after := make(map[string]string, len(st.After))
+	for address, state := range st.After {
+		after[address] = state.String()
+	}
+	return struct {
+		State    string            `json:"state"`
+		Inflight int64             `json:"inflight"`
+		After    map[string]string `json:"after,omitempty"`
+	}{st.State.String(), st.Inflight, after}
+}
@@ -0,0 +1,67 @@
+package drain
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+
+	pb "github.com/miki-tnt/sa5g-go-usvc-k8s/pb/drain"
+)
+
+// exempt are the prefixes of the gRPC methods served while the NF drains:
+// the drain itself, the health and the reflection.
+var exempt = []string{"/drain.Drain/", "/grpc.health.v1.Health/", "/grpc.reflection."}
+
+func exempted(method string) bool {
+	for _, prefix := range exempt {
+		if strings.HasPrefix(method, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// UnaryServerInterceptor counts the unary calls in flight, and refuses the
+// new ones with ErrDraining while the NF drains.
+func (c *Coordinator) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if exempted(info.FullMethod) {
+			return handler(ctx, req)
+		}
+		if !c.begin() {
+			return nil, ErrDraining
+		}
+		defer c.end()
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor refuses the new streams with ErrDraining while the
+// NF drains. The streams open are not waited for, as they may last as long
+// as the NF.
+func (c *Coordinator) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !exempted(info.FullMethod) && c.State() >= pb.State_DRAINING {
+			return ErrDraining
+		}
+		return handler(srv, ss)
+	}
+}
+
+type grpcServer struct {
+	c *Coordinator
+}
+
+// NewGRPCServer returns the drain service of c.
+func NewGRPCServer(c *Coordinator) pb.DrainServer {
+	return &grpcServer{c: c}
+}
+
+func (s *grpcServer) Drain(ctx context.Context, req *pb.DrainRequest) (*pb.DrainReply, error) {
+	return &pb.DrainReply{State: s.c.Drain(ctx)}, nil
+}
+
+func (s *grpcServer) Status(ctx context.Context, req *pb.StatusRequest) (*pb.StatusReply, error) {
+	return s.c.stats(), nil
+}
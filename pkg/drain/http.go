@@ -0,0 +1,42 @@
+package drain
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// Path is where services mount the drain on their admin server.
+const Path = "/admin/drain"
+
+// Handler counts the HTTP requests to h in flight, and refuses the new ones
+// with 503 Service Unavailable while the NF drains. The paths under
+// /admin/ are served all along.
+func (c *Coordinator) Handler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/admin/") {
+			h.ServeHTTP(w, r)
+			return
+		}
+		if !c.begin() {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Connection", "close")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			json.NewEncoder(w).Encode(map[string]string{"error": "drain: the NF is draining"})
+			return
+		}
+		defer c.end()
+		h.ServeHTTP(w, r)
+	})
+}
+
+// DrainHandler drains the NF and answers once it is drained, or the request
+// is canceled, with the state of the drain. It drains on GET too, the method
+// of the preStop hooks of Kubernetes.
+func (c *Coordinator) DrainHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.Drain(r.Context())
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(c.Stats())
+	})
+}
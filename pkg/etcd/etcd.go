@@ -0,0 +1,322 @@
+// Package etcd keeps the state of the NFs in etcd, for the clusters already
+// running it and wanting their state strongly consistent: its reads are
+// linearizable, and its writes are seen by every replica as soon as they
+// are acknowledged.
+//
+// A Store keeps the records of an NF, their keys prefixed as in Redis, the
+// times to live held by leases. Watch streams the changes of the keys under
+// a prefix, whoever made them: the replicas of the NF, or a provisioning
+// system writing etcd directly. Invalidate turns them into the
+// invalidations of a cache of the reads. The subscribers of the UDM are
+// updated by comparing and swapping their revision, so that the UDMs
+// sharing etcd take their sequence numbers in turn.
+//
+// The Client speaks the JSON gateway of the v3 API, served by every etcd
+// member on its client port, rather than gRPC: the etcd client module
+// requires a newer gRPC than the services are built with.
+package etcd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-kit/kit/log"
+)
+
+// Default values of the options.
+const (
+	DefaultTimeout = 5 * time.Second
+	// DefaultMaxTxnOps is the maximum of operations of a transaction of
+	// etcd, unless its members run with another --max-txn-ops.
+	DefaultMaxTxnOps = 128
+)
+
+// Option sets an optional parameter of a Client.
+type Option func(*Client)
+
+// Timeout bounds the requests other than the watches, DefaultTimeout by
+// default.
+func Timeout(d time.Duration) Option {
+	return func(c *Client) { c.timeout = d }
+}
+
+// HTTPClient sets the HTTP client of the requests, to set its TLS
+// configuration for the https endpoints among others. It must not time the
+// requests out, the watches lasting for ever.
+func HTTPClient(client *http.Client) Option {
+	return func(c *Client) { c.http = client }
+}
+
+// Logger sets the logger of the watches broken and resumed.
+func Logger(logger log.Logger) Option {
+	return func(c *Client) { c.logger = logger }
+}
+
+// Client calls the members of an etcd cluster. It is safe for concurrent
+// use.
+type Client struct {
+	endpoints []string
+	timeout   time.Duration
+	http      *http.Client
+	logger    log.Logger
+	// current is the index of the endpoint that answered last, tried
+	// first.
+	current uint32
+}
+
+// New returns a client of the members of endpoints, such as
+// "http://etcd-0.etcd:2379". The requests go to one member, the next ones
+// when it cannot be reached.
+func New(endpoints []string, options ...Option) (*Client, error) {
+	if len(endpoints) == 0 {
+		return nil, errors.New("etcd: no endpoints")
+	}
+	c := &Client{
+		timeout: DefaultTimeout,
+		http:    &http.Client{},
+		logger:  log.NewNopLogger(),
+	}
+	for _, e := range endpoints {
+		c.endpoints = append(c.endpoints, strings.TrimSuffix(e, "/"))
+	}
+	for _, o := range options {
+		o(c)
+	}
+	return c, nil
+}
+
+// Ping reads the status of a member.
+func (c *Client) Ping(ctx context.Context) error {
+	return c.call(ctx, "/v3/maintenance/status", struct{}{}, nil)
+}
+
+// The messages of the gateway. Its integers of 64 bits are strings, its
+// byte strings base64.
+
+type header struct {
+	Revision int64 `json:"revision,string"`
+}
+
+type keyValue struct {
+	Key            []byte `json:"key"`
+	Value          []byte `json:"value"`
+	CreateRevision int64  `json:"create_revision,string"`
+	ModRevision    int64  `json:"mod_revision,string"`
+}
+
+type rangeRequest struct {
+	Key      []byte `json:"key"`
+	RangeEnd []byte `json:"range_end,omitempty"`
+	KeysOnly bool   `json:"keys_only,omitempty"`
+}
+
+type rangeResponse struct {
+	Header header     `json:"header"`
+	KVs    []keyValue `json:"kvs"`
+}
+
+type putRequest struct {
+	Key   []byte `json:"key"`
+	Value []byte `json:"value"`
+	Lease int64  `json:"lease,string,omitempty"`
+}
+
+type deleteRequest struct {
+	Key []byte `json:"key"`
+}
+
+// compare is a condition of a transaction: its target, CREATE or MOD, of
+// key equal to the revision.
+type compare struct {
+	Key            []byte `json:"key"`
+	Target         string `json:"target"`
+	Result         string `json:"result"`
+	CreateRevision int64  `json:"create_revision,string"`
+	ModRevision    int64  `json:"mod_revision,string"`
+}
+
+type requestOp struct {
+	Put    *putRequest    `json:"request_put,omitempty"`
+	Delete *deleteRequest `json:"request_delete_range,omitempty"`
+}
+
+type txnRequest struct {
+	Compare []compare   `json:"compare,omitempty"`
+	Success []requestOp `json:"success,omitempty"`
+}
+
+type txnResponse struct {
+	Succeeded bool `json:"succeeded"`
+}
+
+type leaseRequest struct {
+	TTL int64 `json:"TTL,string"`
+}
+
+type leaseResponse struct {
+	ID int64 `json:"ID,string"`
+}
+
+// errorResponse is the body of a failed request.
+type errorResponse struct {
+	Error   string `json:"error"`
+	Message string `json:"message"`
+}
+
+// get returns the record of key, nil when there is none.
+func (c *Client) get(ctx context.Context, key string) (*keyValue, error) {
+	var resp rangeResponse
+	if err := c.call(ctx, "/v3/kv/range", rangeRequest{Key: []byte(key)}, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.KVs) == 0 {
+		return nil, nil
+	}
+	return &resp.KVs[0], nil
+}
+
+// keys returns the keys starting with prefix, in order.
+func (c *Client) keys(ctx context.Context, prefix string) ([]string, error) {
+	var resp rangeResponse
+	if err := c.call(ctx, "/v3/kv/range", rangeRequest{Key: rangeKey(prefix), RangeEnd: rangeEnd(prefix), KeysOnly: true}, &resp); err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(resp.KVs))
+	for _, kv := range resp.KVs {
+		keys = append(keys, string(kv.Key))
+	}
+	return keys, nil
+}
+
+// put returns the request putting value at key, for ttl when not zero.
+func (c *Client) put(ctx context.Context, key string, value []byte, ttl time.Duration) (*putRequest, error) {
+	p := &putRequest{Key: []byte(key), Value: value}
+	if ttl > 0 {
+		lease, err := c.grant(ctx, ttl)
+		if err != nil {
+			return nil, err
+		}
+		p.Lease = lease
+	}
+	return p, nil
+}
+
+// grant returns a lease of ttl, rounded up to the second. etcd lengthens
+// the leases shorter than its minimum, of a few seconds.
+func (c *Client) grant(ctx context.Context, ttl time.Duration) (int64, error) {
+	var resp leaseResponse
+	err := c.call(ctx, "/v3/lease/grant", leaseRequest{TTL: int64((ttl + time.Second - 1) / time.Second)}, &resp)
+	return resp.ID, err
+}
+
+// txn runs the transaction of the puts of success if compare holds, and
+// tells whether it did.
+func (c *Client) txn(ctx context.Context, compare []compare, success ...*putRequest) (bool, error) {
+	ops := make([]requestOp, 0, len(success))
+	for _, p := range success {
+		ops = append(ops, requestOp{Put: p})
+	}
+	return c.txnOps(ctx, compare, ops...)
+}
+
+// txnOps runs the transaction of the operations of success if compare
+// holds, and tells whether it did.
+func (c *Client) txnOps(ctx context.Context, compare []compare, success ...requestOp) (bool, error) {
+	req := txnRequest{Compare: compare, Success: success}
+	var resp txnResponse
+	err := c.call(ctx, "/v3/kv/txn", req, &resp)
+	return resp.Succeeded, err
+}
+
+// call posts req to path and decodes the response into resp, unless nil.
+func (c *Client) call(ctx context.Context, path string, req, resp interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	r, err := c.do(ctx, path, req)
+	if err != nil {
+		return err
+	}
+	defer r.Body.Close()
+	if resp == nil {
+		return nil
+	}
+	return json.NewDecoder(r.Body).Decode(resp)
+}
+
+// do posts req to path, trying the endpoints in turn from the one that
+// answered last, and returns the response of a successful request.
+func (c *Client) do(ctx context.Context, path string, req interface{}) (*http.Response, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	first := atomic.LoadUint32(&c.current)
+	for i := range c.endpoints {
+		n := (int(first) + i) % len(c.endpoints)
+		var r *http.Response
+		r, err = c.post(ctx, c.endpoints[n]+path, body)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			continue
+		}
+		atomic.StoreUint32(&c.current, uint32(n))
+		if r.StatusCode != http.StatusOK {
+			defer r.Body.Close()
+			return nil, responseError(r)
+		}
+		return r, nil
+	}
+	return nil, err
+}
+
+func (c *Client) post(ctx context.Context, url string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return c.http.Do(req.WithContext(ctx))
+}
+
+// responseError returns the error of a failed request.
+func responseError(r *http.Response) error {
+	data, _ := ioutil.ReadAll(r.Body)
+	var e errorResponse
+	if json.Unmarshal(data, &e) == nil && (e.Message != "" || e.Error != "") {
+		if e.Message == "" {
+			e.Message = e.Error
+		}
+		return fmt.Errorf("etcd: %s", e.Message)
+	}
+	return fmt.Errorf("etcd: %s", r.Status)
+}
+
+// rangeKey returns the first key of the range of prefix, rangeEnd the key
+// past its end: the range of an empty prefix is every key.
+func rangeKey(prefix string) []byte {
+	if prefix == "" {
+		return []byte{0}
+	}
+	return []byte(prefix)
+}
+
+func rangeEnd(prefix string) []byte {
+	end := []byte(prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	return []byte{0}
+}
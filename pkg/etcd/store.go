@@ -0,0 +1,92 @@
+package etcd
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/store"
+)
+
+type etcdStore struct {
+	c      *Client
+	prefix string
+}
+
+// NewStore returns a Store backed by the etcd cluster of c, shared by every
+// replica using it. Keys are prefixed with prefix so that several services
+// can use one cluster. Every record with a time to live is granted a lease
+// of its own, which etcd revokes as it expires.
+func NewStore(c *Client, prefix string) store.Store {
+	return &etcdStore{c: c, prefix: prefix}
+}
+
+func (s *etcdStore) Get(ctx context.Context, key string) ([]byte, error) {
+	kv, err := s.c.get(ctx, s.prefix+key)
+	if err != nil {
+		return nil, err
+	}
+	if kv == nil {
+		return nil, store.ErrNotFound
+	}
+	return kv.Value, nil
+}
+
+func (s *etcdStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	p, err := s.c.put(ctx, s.prefix+key, value, ttl)
+	if err != nil {
+		return err
+	}
+	return s.c.call(ctx, "/v3/kv/put", p, nil)
+}
+
+// SetNX puts the record only if its key was never created, or deleted
+// since.
+func (s *etcdStore) SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	p, err := s.c.put(ctx, s.prefix+key, value, ttl)
+	if err != nil {
+		return false, err
+	}
+	return s.c.txn(ctx, []compare{{Key: p.Key, Target: "CREATE", Result: "EQUAL"}}, p)
+}
+
+// CompareAndSwap puts the record only if it was not modified since it was
+// read holding old.
+func (s *etcdStore) CompareAndSwap(ctx context.Context, key string, old, value []byte, ttl time.Duration) (bool, error) {
+	kv, err := s.c.get(ctx, s.prefix+key)
+	if err != nil || kv == nil || !bytes.Equal(kv.Value, old) {
+		return false, err
+	}
+	p, err := s.c.put(ctx, s.prefix+key, value, ttl)
+	if err != nil {
+		return false, err
+	}
+	return s.c.txn(ctx, []compare{{Key: p.Key, Target: "MOD", Result: "EQUAL", ModRevision: kv.ModRevision}}, p)
+}
+
+func (s *etcdStore) Delete(ctx context.Context, key string) error {
+	return s.c.call(ctx, "/v3/kv/deleterange", deleteRequest{Key: []byte(s.prefix + key)}, nil)
+}
+
+// CompareAndDelete deletes the record only if it was not modified since it
+// was read holding old.
+func (s *etcdStore) CompareAndDelete(ctx context.Context, key string, old []byte) (bool, error) {
+	kv, err := s.c.get(ctx, s.prefix+key)
+	if err != nil || kv == nil || !bytes.Equal(kv.Value, old) {
+		return false, err
+	}
+	return s.c.txnOps(ctx, []compare{{Key: kv.Key, Target: "MOD", Result: "EQUAL", ModRevision: kv.ModRevision}},
+		requestOp{Delete: &deleteRequest{Key: kv.Key}})
+}
+
+// Keys reads the keys in one request, sorted by etcd.
+func (s *etcdStore) Keys(ctx context.Context, prefix string) ([]string, error) {
+	keys, err := s.c.keys(ctx, s.prefix+prefix)
+	if err != nil {
+		return nil, err
+	}
+	for i, k := range keys {
+		keys[i] = k[len(s.prefix):]
+	}
+	return keys, nil
+}
@@ -0,0 +1,98 @@
+package etcd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"google.golang.org/grpc/status"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/schema"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/udm/subscriber"
+)
+
+// attempts is the number of times an update whose subscriber changed since
+// it was read is tried.
+const attempts = 5
+
+type subscribers struct {
+	subscriber.Repository
+	c      *Client
+	prefix string
+}
+
+// NewSubscribers returns the Repository of the subscribers of the store of
+// c under prefix, as NewStore keeps them, which updates a subscriber only if
+// unchanged since it was read, trying again otherwise, and puts the
+// subscribers of PutAll in a transaction of at most DefaultMaxTxnOps.
+func NewSubscribers(c *Client, prefix string) subscriber.Transactional {
+	st := schema.NewStore(NewStore(c, prefix), subscriber.Schema)
+	return &subscribers{Repository: subscriber.NewRepository(st), c: c, prefix: prefix}
+}
+
+func (r *subscribers) Update(ctx context.Context, supi string, fn func(s *subscriber.Subscriber) error) error {
+	key := r.prefix + subscriber.KeyPrefix + supi
+	for i := 0; ; i++ {
+		kv, err := r.c.get(ctx, key)
+		if err != nil {
+			return err
+		}
+		if kv == nil {
+			return subscriber.ErrNotFound
+		}
+		data, _, err := subscriber.Schema.Upgrade(kv.Value)
+		if err != nil {
+			return err
+		}
+		var s subscriber.Subscriber
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		if err := fn(&s); err != nil {
+			return err
+		}
+		p, err := r.put(key, s)
+		if err != nil {
+			return err
+		}
+		ok, err := r.c.txn(ctx, []compare{{Key: p.Key, Target: "MOD", Result: "EQUAL", ModRevision: kv.ModRevision}}, p)
+		if err != nil || ok {
+			return err
+		}
+		if i+1 == attempts {
+			return fmt.Errorf("etcd: %s: changed by %d updates in a row", supi, attempts)
+		}
+	}
+}
+
+func (r *subscribers) PutAll(ctx context.Context, all []subscriber.Subscriber) error {
+	if len(all) > DefaultMaxTxnOps {
+		return fmt.Errorf("etcd: %d subscribers, more than the %d of a transaction", len(all), DefaultMaxTxnOps)
+	}
+	puts := make([]*putRequest, 0, len(all))
+	for _, s := range all {
+		p, err := r.put(r.prefix+subscriber.KeyPrefix+s.SUPI, s)
+		if err != nil {
+			return fmt.Errorf("%s: %s", s.SUPI, status.Convert(err).Message())
+		}
+		puts = append(puts, p)
+	}
+	if len(puts) == 0 {
+		return nil
+	}
+	_, err := r.c.txn(ctx, nil, puts...)
+	return err
+}
+
+// put returns the request putting s at key, stamped with the version of
+// its schema.
+func (r *subscribers) put(key string, s subscriber.Subscriber) (*putRequest, error) {
+	if err := s.Normalize(); err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	return &putRequest{Key: []byte(key), Value: subscriber.Schema.Stamp(data)}, nil
+}
@@ -0,0 +1,178 @@
+package etcd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/go-kit/kit/log/level"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/cache"
+)
+
+// The bounds of the time between two attempts to open a broken watch.
+const (
+	minBackoff = time.Second
+	maxBackoff = 30 * time.Second
+)
+
+// EventType is the kind of an Event.
+type EventType string
+
+// The types of the events.
+const (
+	Put    EventType = "PUT"
+	Delete EventType = "DELETE"
+	// Reset is the event of a watch that missed changes, the revision it
+	// resumed from being compacted: anything under its prefix may have
+	// changed.
+	Reset EventType = "RESET"
+)
+
+// Event is a change of a key.
+type Event struct {
+	Type EventType
+	Key  string
+	// Value is the value put, PrevValue the value replaced or deleted,
+	// nil for a key created.
+	Value, PrevValue []byte
+	// Revision is the revision of the cluster at the change.
+	Revision int64
+}
+
+type watchRequest struct {
+	Create watchCreate `json:"create_request"`
+}
+
+type watchCreate struct {
+	Key           []byte `json:"key"`
+	RangeEnd      []byte `json:"range_end"`
+	StartRevision int64  `json:"start_revision,string,omitempty"`
+	PrevKV        bool   `json:"prev_kv"`
+}
+
+// watchMessage is a message of the stream of a watch, a response or an
+// error.
+type watchMessage struct {
+	Result *struct {
+		Header          header `json:"header"`
+		Created         bool   `json:"created"`
+		Canceled        bool   `json:"canceled"`
+		CompactRevision int64  `json:"compact_revision,string"`
+		CancelReason    string `json:"cancel_reason"`
+		Events          []struct {
+			Type   string    `json:"type"`
+			KV     keyValue  `json:"kv"`
+			PrevKV *keyValue `json:"prev_kv"`
+		} `json:"events"`
+	} `json:"result"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// Watch calls fn with the changes of the keys starting with prefix, in the
+// order of their revisions, until ctx is done. A watch broken is opened
+// again from the revision after the last change seen, so that no change is
+// missed, unless that revision was compacted meanwhile: fn is then called
+// with a Reset, and the changes since the compaction. fn is called by one
+// goroutine, and holds the stream up while it runs.
+func (c *Client) Watch(ctx context.Context, prefix string, fn func(Event)) {
+	var rev int64
+	backoff := minBackoff
+	for {
+		created, err := c.watch(ctx, prefix, &rev, fn)
+		if ctx.Err() != nil {
+			return
+		}
+		if created {
+			backoff = minBackoff
+		}
+		if err == nil {
+			continue
+		}
+		level.Warn(c.logger).Log("watch", prefix, "revision", rev, "err", err, "retry", backoff)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// watch streams the changes of prefix after *rev, the current revision if
+// zero, to fn, setting *rev to their revisions, until the stream breaks. It
+// tells whether the watch was created, and returns no error when its start
+// was compacted.
+func (c *Client) watch(ctx context.Context, prefix string, rev *int64, fn func(Event)) (created bool, err error) {
+	req := watchRequest{Create: watchCreate{Key: rangeKey(prefix), RangeEnd: rangeEnd(prefix), PrevKV: true}}
+	if *rev > 0 {
+		req.Create.StartRevision = *rev + 1
+	}
+	r, err := c.do(ctx, "/v3/watch", req)
+	if err != nil {
+		return false, err
+	}
+	defer r.Body.Close()
+	dec := json.NewDecoder(r.Body)
+	for {
+		var m watchMessage
+		if err := dec.Decode(&m); err != nil {
+			return created, err
+		}
+		if m.Error != nil {
+			return created, errors.New("etcd: " + m.Error.Message)
+		}
+		res := m.Result
+		if res == nil {
+			continue
+		}
+		if res.CompactRevision > 0 {
+			// the revisions from the compaction on are still kept
+			fn(Event{Type: Reset, Revision: res.CompactRevision})
+			*rev = res.CompactRevision - 1
+			return created, nil
+		}
+		if res.Canceled {
+			return created, errors.New("etcd: watch canceled: " + res.CancelReason)
+		}
+		if res.Created {
+			created = true
+			if *rev == 0 {
+				*rev = res.Header.Revision
+			}
+		}
+		for _, e := range res.Events {
+			ev := Event{Type: Put, Key: string(e.KV.Key), Value: e.KV.Value, Revision: e.KV.ModRevision}
+			if e.Type == string(Delete) {
+				ev.Type, ev.Value = Delete, nil
+			}
+			if e.PrevKV != nil {
+				ev.PrevValue = e.PrevKV.Value
+			}
+			*rev = ev.Revision
+			fn(ev)
+		}
+	}
+}
+
+// Invalidate returns the function of a watch of the keys under prefix that
+// invalidates c: invalidate is called with the key of every change, prefix
+// trimmed, and a Reset purges c. A service caching the reads of another
+// that keeps its state in etcd thus drops the responses changed by any
+// replica, or by a provisioning system, rather than waiting for them to
+// expire.
+func Invalidate(c *cache.Cache, prefix string, invalidate func(c *cache.Cache, key string)) func(Event) {
+	return func(e Event) {
+		if e.Type == Reset {
+			c.Purge()
+			return
+		}
+		invalidate(c, strings.TrimPrefix(e.Key, prefix))
+	}
+}
@@ -0,0 +1,200 @@
+// Package events fans the events of a service out to the dashboards watching
+// it: the UE registrations and authentications, and later the load of the
+// cells and the alarms. A Broker hands every published event to the
+// subscriptions whose filter it matches, and Handler serves the
+// subscriptions over WebSocket.
+//
+// Publishing never blocks: every subscription has a bounded buffer, and the
+// events it has no room for are dropped and counted, so that a slow browser
+// loses events rather than slowing the service down.
+package events
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-kit/kit/metrics"
+	"github.com/go-kit/kit/metrics/discard"
+)
+
+// The topics of the events.
+const (
+	// TopicUE is the topic of the events of a UE, keyed by its SUPI.
+	TopicUE = "ue"
+	// TopicCell is the topic of the load reports of a cell, keyed by its
+	// cell ID.
+	TopicCell = "cell"
+	// TopicAlarm is the topic of the alarms raised and cleared.
+	TopicAlarm = "alarm"
+)
+
+// DefaultBuffer is the number of events a subscription buffers, unless
+// given otherwise.
+const DefaultBuffer = 256
+
+// Event is an event of a service.
+type Event struct {
+	Time  time.Time `json:"time"`
+	Topic string    `json:"topic"`
+	Type  string    `json:"type"`
+	// Key identifies what the event is about within its topic: the SUPI of
+	// a UE, the ID of a cell.
+	Key     string            `json:"key,omitempty"`
+	Source  string            `json:"source,omitempty"`
+	Outcome string            `json:"outcome,omitempty"`
+	Data    map[string]string `json:"data,omitempty"`
+}
+
+// Filter selects events. An empty list matches every value.
+type Filter struct {
+	Topics []string `json:"topics,omitempty"`
+	Types  []string `json:"types,omitempty"`
+	Keys   []string `json:"keys,omitempty"`
+}
+
+// Match tells whether e passes f.
+func (f Filter) Match(e Event) bool {
+	return in(f.Topics, e.Topic) && in(f.Types, e.Type) && in(f.Keys, e.Key)
+}
+
+func in(values []string, v string) bool {
+	if len(values) == 0 {
+		return true
+	}
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Option sets an optional parameter of a Broker.
+type Option func(*Broker)
+
+// PublishedCounter sets the counter incremented for every event published.
+func PublishedCounter(c metrics.Counter) Option {
+	return func(b *Broker) { b.publishedCounter = c }
+}
+
+// DroppedCounter sets the counter incremented for every event a
+// subscription had no room for.
+func DroppedCounter(c metrics.Counter) Option {
+	return func(b *Broker) { b.droppedCounter = c }
+}
+
+// SubscribersGauge sets the gauge reporting the number of subscriptions.
+func SubscribersGauge(g metrics.Gauge) Option {
+	return func(b *Broker) { b.subscribersGauge = g }
+}
+
+// Broker fans the events out to the subscriptions.
+type Broker struct {
+	publishedCounter metrics.Counter
+	droppedCounter   metrics.Counter
+	subscribersGauge metrics.Gauge
+
+	mtx  sync.RWMutex
+	subs map[*Subscription]struct{}
+}
+
+// NewBroker returns a Broker without subscriptions.
+func NewBroker(options ...Option) *Broker {
+	b := &Broker{
+		publishedCounter: discard.NewCounter(),
+		droppedCounter:   discard.NewCounter(),
+		subscribersGauge: discard.NewGauge(),
+		subs:             map[*Subscription]struct{}{},
+	}
+	for _, option := range options {
+		option(b)
+	}
+	return b
+}
+
+// Publish hands e to the subscriptions it matches, dropping it for those
+// whose buffer is full. The time of e is set to now when it is zero. A nil
+// Broker drops every event.
+func (b *Broker) Publish(e Event) {
+	if b == nil {
+		return
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	b.publishedCounter.Add(1)
+	b.mtx.RLock()
+	defer b.mtx.RUnlock()
+	for s := range b.subs {
+		if !s.Filter().Match(e) {
+			continue
+		}
+		select {
+		case s.c <- e:
+		default:
+			atomic.AddInt64(&s.dropped, 1)
+			b.droppedCounter.Add(1)
+		}
+	}
+}
+
+// Subscribe returns a subscription to the events matching f, buffering up
+// to buffer of them.
+func (b *Broker) Subscribe(f Filter, buffer int) *Subscription {
+	if buffer <= 0 {
+		buffer = DefaultBuffer
+	}
+	s := &Subscription{b: b, c: make(chan Event, buffer), filter: f}
+	b.mtx.Lock()
+	b.subs[s] = struct{}{}
+	b.subscribersGauge.Set(float64(len(b.subs)))
+	b.mtx.Unlock()
+	return s
+}
+
+// Subscription is a subscription to the events of a Broker.
+type Subscription struct {
+	b       *Broker
+	c       chan Event
+	dropped int64
+
+	mtx    sync.Mutex
+	filter Filter
+}
+
+// C returns the channel the events are delivered on. It is closed by Close.
+func (s *Subscription) C() <-chan Event {
+	return s.c
+}
+
+// Filter returns the filter of s.
+func (s *Subscription) Filter() Filter {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.filter
+}
+
+// SetFilter replaces the filter of s, for the events published from now on.
+func (s *Subscription) SetFilter(f Filter) {
+	s.mtx.Lock()
+	s.filter = f
+	s.mtx.Unlock()
+}
+
+// Dropped returns the number of events dropped since the last call.
+func (s *Subscription) Dropped() int64 {
+	return atomic.SwapInt64(&s.dropped, 0)
+}
+
+// Close ends s and closes its channel.
+func (s *Subscription) Close() {
+	s.b.mtx.Lock()
+	defer s.b.mtx.Unlock()
+	if _, ok := s.b.subs[s]; !ok {
+		return
+	}
+	delete(s.b.subs, s)
+	s.b.subscribersGauge.Set(float64(len(s.b.subs)))
+	close(s.c)
+}
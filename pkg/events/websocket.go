@@ -0,0 +1,205 @@
+package events
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/gorilla/websocket"
+)
+
+// Path is the path Handler is usually served on.
+const Path = "/events"
+
+const (
+	// writeWait is the time allowed to write a frame: a client that does
+	// not read for that long is disconnected.
+	writeWait = 10 * time.Second
+	// pongWait is the time allowed between two frames of the client, the
+	// pongs answering the pings included.
+	pongWait = 60 * time.Second
+	// pingPeriod is the time between two pings, shorter than pongWait.
+	pingPeriod = pongWait * 9 / 10
+	// maxMessageSize caps the size of the filters sent by a client.
+	maxMessageSize = 4096
+)
+
+// DecodeFilterFunc decodes the filter of a new subscription from its HTTP
+// request.
+type DecodeFilterFunc func(r *http.Request) (Filter, error)
+
+// EncodeMessageFunc encodes a message sent to the client, a Message.
+type EncodeMessageFunc func(w io.Writer, m Message) error
+
+// Message is a frame sent to the client: an event, or the number of events
+// dropped because the client was not reading fast enough.
+type Message struct {
+	Event   *Event `json:"event,omitempty"`
+	Dropped int64  `json:"dropped,omitempty"`
+}
+
+// HandlerOption sets an optional parameter of Handler.
+type HandlerOption func(*handler)
+
+// HandlerDecodeFilter sets the decoder of the filters of the new
+// subscriptions, decodeWSFilter by default.
+func HandlerDecodeFilter(dec DecodeFilterFunc) HandlerOption {
+	return func(h *handler) { h.dec = dec }
+}
+
+// HandlerEncodeMessage sets the encoder of the frames, encodeWSMessage by
+// default.
+func HandlerEncodeMessage(enc EncodeMessageFunc) HandlerOption {
+	return func(h *handler) { h.enc = enc }
+}
+
+// HandlerBuffer sets the number of events buffered per connection.
+func HandlerBuffer(n int) HandlerOption {
+	return func(h *handler) { h.buffer = n }
+}
+
+// HandlerCheckOrigin sets the check of the Origin header of the upgrade
+// requests. Only the pages of the same host may connect by default.
+func HandlerCheckOrigin(check func(r *http.Request) bool) HandlerOption {
+	return func(h *handler) { h.upgrader.CheckOrigin = check }
+}
+
+// HandlerPseudonymize sets the pseudonym the keys of the events of the UEs
+// are sent as. The filters keep selecting them by SUPI.
+func HandlerPseudonymize(pseudonym func(id string) string) HandlerOption {
+	return func(h *handler) { h.pseudonym = pseudonym }
+}
+
+type handler struct {
+	b         *Broker
+	logger    log.Logger
+	dec       DecodeFilterFunc
+	enc       EncodeMessageFunc
+	buffer    int
+	upgrader  websocket.Upgrader
+	pseudonym func(id string) string
+}
+
+// Handler returns a handler streaming the events of b over WebSocket. The
+// filter of a connection is decoded from its request, and replaced by every
+// filter the client sends, as a JSON Filter. The events are sent as JSON
+// Messages.
+func Handler(b *Broker, logger log.Logger, options ...HandlerOption) http.Handler {
+	h := &handler{
+		b:      b,
+		logger: logger,
+		dec:    decodeWSFilter,
+		enc:    encodeWSMessage,
+		buffer: DefaultBuffer,
+	}
+	for _, option := range options {
+		option(h)
+	}
+	return h
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f, err := h.dec(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// the upgrader answered the request already
+		return
+	}
+	sub := h.b.Subscribe(f, h.buffer)
+	level.Debug(h.logger).Log("events", "subscribe", "remote", r.RemoteAddr)
+	go h.read(conn, sub)
+	h.write(conn, sub)
+}
+
+// read applies the filters sent by the client, until the connection fails
+// or is closed, and then ends the subscription.
+func (h *handler) read(conn *websocket.Conn, sub *Subscription) {
+	defer sub.Close()
+	conn.SetReadLimit(maxMessageSize)
+	conn.SetReadDeadline(time.Now().Add(pongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		conn.SetReadDeadline(time.Now().Add(pongWait))
+		var f Filter
+		if err := json.Unmarshal(data, &f); err != nil {
+			level.Debug(h.logger).Log("events", "filter", "err", err)
+			continue
+		}
+		sub.SetFilter(f)
+	}
+}
+
+// write sends the events of sub and the pings, until the subscription ends
+// or a write fails.
+func (h *handler) write(conn *websocket.Conn, sub *Subscription) {
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		sub.Close()
+		conn.Close()
+	}()
+	for {
+		select {
+		case e, ok := <-sub.C():
+			if !ok {
+				conn.SetWriteDeadline(time.Now().Add(writeWait))
+				conn.WriteMessage(websocket.CloseMessage, []byte{})
+				return
+			}
+			if n := sub.Dropped(); n > 0 {
+				if err := h.send(conn, Message{Dropped: n}); err != nil {
+					return
+				}
+			}
+			if h.pseudonym != nil && e.Topic == TopicUE {
+				e.Key = h.pseudonym(e.Key)
+			}
+			if err := h.send(conn, Message{Event: &e}); err != nil {
+				return
+			}
+		case <-ticker.C:
+			conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (h *handler) send(conn *websocket.Conn, m Message) error {
+	conn.SetWriteDeadline(time.Now().Add(writeWait))
+	w, err := conn.NextWriter(websocket.TextMessage)
+	if err != nil {
+		return err
+	}
+	if err := h.enc(w, m); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// decodeWSFilter is a DecodeFilterFunc that decodes the filter from the
+// query of the request: every topic, type and key parameter adds a value.
+func decodeWSFilter(r *http.Request) (Filter, error) {
+	q := r.URL.Query()
+	return Filter{Topics: q["topic"], Types: q["type"], Keys: q["key"]}, nil
+}
+
+// encodeWSMessage is an EncodeMessageFunc that encodes the message as JSON.
+func encodeWSMessage(w io.Writer, m Message) error {
+	return json.NewEncoder(w).Encode(m)
+}
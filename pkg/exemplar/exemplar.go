@@ -0,0 +1,246 @@
+// Package exemplar links the latency histograms of the services to their
+// traces. Its Histogram keeps, in every bucket, the trace ID of the last
+// observation that fell in it, and Handler serves the histograms in the
+// OpenMetrics text format with these exemplars, for Prometheus to scrape
+// and Grafana to jump from a latency spike to a trace of it in Zipkin.
+package exemplar
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/metrics"
+	"github.com/openzipkin/zipkin-go"
+)
+
+// Path is the path Handler is served on.
+const Path = "/metrics"
+
+// ContentType is the content type of the responses of Handler.
+const ContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+
+// DefaultBuckets are the upper bounds, in seconds, of the buckets of the
+// latencies of the requests.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Observer is implemented by the histograms that take exemplars.
+type Observer interface {
+	// ObserveWithExemplar observes value, traceID being its exemplar.
+	ObserveWithExemplar(value float64, traceID string)
+}
+
+// Observe observes value in h with the trace of ctx as its exemplar, when h
+// is an Observer and ctx carries a Zipkin span, and without one otherwise.
+func Observe(ctx context.Context, h metrics.Histogram, value float64) {
+	if o, ok := h.(Observer); ok {
+		if span := zipkin.SpanFromContext(ctx); span != nil && !span.Context().TraceID.Empty() {
+			o.ObserveWithExemplar(value, span.Context().TraceID.String())
+			return
+		}
+	}
+	h.Observe(value)
+}
+
+// Histogram is a metrics.Histogram whose buckets carry exemplars. Its label
+// values are set by With, as those of the other go-kit metrics.
+type Histogram struct {
+	name        string
+	help        string
+	buckets     []float64
+	labelValues []string
+	series      *seriesSet
+}
+
+type seriesSet struct {
+	mtx    sync.Mutex
+	series map[string]*series
+}
+
+// series are the observations of a set of label values.
+type series struct {
+	labelValues []string
+	// counts holds the observations of each bucket, not cumulated, the
+	// last one being the +Inf bucket.
+	counts    []uint64
+	exemplars []*exemplarValue
+	count     uint64
+	sum       float64
+}
+
+type exemplarValue struct {
+	traceID string
+	value   float64
+	time    time.Time
+}
+
+// NewHistogram returns the histogram name, described by help, with buckets
+// of the upper bounds buckets, sorted.
+func NewHistogram(name, help string, buckets []float64) *Histogram {
+	return &Histogram{
+		name:    name,
+		help:    help,
+		buckets: buckets,
+		series:  &seriesSet{series: map[string]*series{}},
+	}
+}
+
+// With returns the histogram of the label values of h followed by
+// labelValues, pairs of label and value.
+func (h *Histogram) With(labelValues ...string) metrics.Histogram {
+	if len(labelValues)%2 != 0 {
+		labelValues = append(labelValues, "unknown")
+	}
+	c := *h
+	c.labelValues = append(append([]string(nil), h.labelValues...), labelValues...)
+	return &c
+}
+
+// Observe observes value without an exemplar.
+func (h *Histogram) Observe(value float64) {
+	h.observe(value, "")
+}
+
+// ObserveWithExemplar observes value, traceID being the exemplar of its
+// bucket until the next observation of the bucket.
+func (h *Histogram) ObserveWithExemplar(value float64, traceID string) {
+	h.observe(value, traceID)
+}
+
+func (h *Histogram) observe(value float64, traceID string) {
+	key := strings.Join(h.labelValues, "\xff")
+	i := sort.SearchFloat64s(h.buckets, value)
+	h.series.mtx.Lock()
+	defer h.series.mtx.Unlock()
+	s, ok := h.series.series[key]
+	if !ok {
+		s = &series{
+			labelValues: h.labelValues,
+			counts:      make([]uint64, len(h.buckets)+1),
+			exemplars:   make([]*exemplarValue, len(h.buckets)+1),
+		}
+		h.series.series[key] = s
+	}
+	s.counts[i]++
+	s.count++
+	s.sum += value
+	if traceID != "" {
+		s.exemplars[i] = &exemplarValue{traceID: traceID, value: value, time: time.Now()}
+	}
+}
+
+// Handler returns the handler of the histograms hs in the OpenMetrics text
+// format.
+func Handler(hs ...*Histogram) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", ContentType)
+		bw := bufio.NewWriter(w)
+		for _, h := range hs {
+			h.write(bw)
+		}
+		fmt.Fprint(bw, "# EOF\n")
+		bw.Flush()
+	})
+}
+
+func (h *Histogram) write(w *bufio.Writer) {
+	fmt.Fprintf(w, "# TYPE %s histogram\n", h.name)
+	if h.help != "" {
+		fmt.Fprintf(w, "# HELP %s %s\n", h.name, escape(h.help))
+	}
+	h.series.mtx.Lock()
+	defer h.series.mtx.Unlock()
+	keys := make([]string, 0, len(h.series.series))
+	for k := range h.series.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		s := h.series.series[k]
+		var cumulated uint64
+		for i, n := range s.counts {
+			cumulated += n
+			le := math.Inf(1)
+			if i < len(h.buckets) {
+				le = h.buckets[i]
+			}
+			fmt.Fprintf(w, "%s_bucket%s %d", h.name, labels(s.labelValues, "le", formatFloat(le)), cumulated)
+			if e := s.exemplars[i]; e != nil {
+				fmt.Fprintf(w, " # {trace_id=\"%s\"} %s %s", e.traceID, formatFloat(e.value),
+					strconv.FormatFloat(float64(e.time.UnixNano())/1e9, 'f', 3, 64))
+			}
+			fmt.Fprint(w, "\n")
+		}
+		fmt.Fprintf(w, "%s_count%s %d\n", h.name, labels(s.labelValues), s.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, labels(s.labelValues), formatFloat(s.sum))
+	}
+}
+
+// labels formats the pairs of label and value of labelValues and extra.
+func labels(labelValues []string, extra ...string) string {
+	all := append(append([]string(nil), labelValues...), extra...)
+	if len(all) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("{")
+	for i := 0; i+1 < len(all); i += 2 {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		fmt.Fprintf(&b, "%s=\"%s\"", all[i], escape(all[i+1]))
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+func escape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
+
+func formatFloat(f float64) string {
+	if math.IsInf(f, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// Tee returns a histogram observing in every one of hs, such as a Histogram
+// served to Prometheus and one pushed elsewhere, the exemplars going to
+// those that are Observers.
+func Tee(hs ...metrics.Histogram) metrics.Histogram {
+	return tee(hs)
+}
+
+type tee []metrics.Histogram
+
+func (t tee) With(labelValues ...string) metrics.Histogram {
+	hs := make(tee, len(t))
+	for i, h := range t {
+		hs[i] = h.With(labelValues...)
+	}
+	return hs
+}
+
+func (t tee) Observe(value float64) {
+	for _, h := range t {
+		h.Observe(value)
+	}
+}
+
+func (t tee) ObserveWithExemplar(value float64, traceID string) {
+	for _, h := range t {
+		if o, ok := h.(Observer); ok {
+			o.ObserveWithExemplar(value, traceID)
+		} else {
+			h.Observe(value)
+		}
+	}
+}
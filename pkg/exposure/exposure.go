@@ -0,0 +1,439 @@
+// Package exposure exposes the events of a service to external consumers,
+// in the manner of the event exposure of the NEF (TS 29.522): a consumer
+// subscribes to the events matching a filter with the URI of a webhook, and
+// every event matching it is posted there as a Notification.
+//
+// The subscriptions and the pending notifications are kept in a store, so
+// that they survive a restart and are shared by the replicas. A notification
+// is deleted once the consumer answers it with a 2xx status, and retried with
+// an exponential backoff until then, or until its retention runs out. The
+// delivery is thus at least once: a consumer may get a notification twice,
+// and tells the copies apart by their ID. The notifications of a
+// subscription are delivered in order, one at a time.
+package exposure
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/go-kit/kit/metrics"
+	"github.com/go-kit/kit/metrics/discard"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/clock"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/events"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/store"
+)
+
+// KeyPrefix starts the store keys of the subscriptions and notifications.
+const KeyPrefix = "exposure/"
+
+const (
+	subscriptionPrefix = KeyPrefix + "subscriptions/"
+	pendingPrefix      = KeyPrefix + "pending/"
+	leasePrefix        = KeyPrefix + "lease/"
+	// leaseTTL bounds the time a replica that died delivering the
+	// notifications of a subscription keeps the others from doing it.
+	leaseTTL = time.Minute
+)
+
+// Defaults of the options.
+const (
+	// DefaultInterval is the period Run delivers the pending notifications
+	// at.
+	DefaultInterval = time.Second
+	// DefaultRetention is the time an undelivered notification is kept.
+	DefaultRetention = 24 * time.Hour
+	// DefaultMinBackoff and DefaultMaxBackoff bound the time between two
+	// attempts to deliver the notifications of a failing subscription.
+	DefaultMinBackoff = time.Second
+	DefaultMaxBackoff = time.Minute
+)
+
+var (
+	// ErrNotFound is returned for an unknown subscription.
+	ErrNotFound = status.Error(codes.NotFound, "exposure: unknown subscription")
+	// ErrNotifyURI is returned for a subscription without a valid webhook.
+	ErrNotifyURI = status.Error(codes.InvalidArgument, "exposure: notify_uri must be an absolute http or https URL")
+)
+
+// Subscription is the subscription of a consumer to the events matching its
+// filter.
+type Subscription struct {
+	ID        string        `json:"id"`
+	Filter    events.Filter `json:"filter"`
+	NotifyURI string        `json:"notify_uri"`
+	Created   time.Time     `json:"created"`
+}
+
+// Notification is the body posted to the webhook of a subscription.
+type Notification struct {
+	// ID is the same for every attempt to deliver the notification.
+	ID             string       `json:"id"`
+	SubscriptionID string       `json:"subscription_id"`
+	Event          events.Event `json:"event"`
+}
+
+// Option sets an optional parameter of an Exposure.
+type Option func(*Exposure)
+
+// Client sets the HTTP client posting the notifications, one with a 10s
+// timeout by default.
+func Client(c *http.Client) Option {
+	return func(x *Exposure) { x.client = c }
+}
+
+// Retention sets the time an undelivered notification is kept,
+// DefaultRetention by default.
+func Retention(d time.Duration) Option {
+	return func(x *Exposure) { x.retention = d }
+}
+
+// Backoff sets the bounds of the time between two attempts to deliver the
+// notifications of a failing subscription.
+func Backoff(min, max time.Duration) Option {
+	return func(x *Exposure) { x.minBackoff, x.maxBackoff = min, max }
+}
+
+// DeliveredCounter sets the counter incremented for every notification
+// delivered.
+func DeliveredCounter(c metrics.Counter) Option {
+	return func(x *Exposure) { x.delivered = c }
+}
+
+// FailedCounter sets the counter incremented for every failed attempt to
+// deliver a notification.
+func FailedCounter(c metrics.Counter) Option {
+	return func(x *Exposure) { x.failed = c }
+}
+
+// Clock sets the clock of the backoffs, of the loop of Run and of the
+// times of the subscriptions and events, clock.Real by default.
+func Clock(c clock.Clock) Option {
+	return func(x *Exposure) { x.clock = c }
+}
+
+// Exposure keeps the subscriptions and delivers their notifications.
+type Exposure struct {
+	st         store.Store
+	logger     log.Logger
+	client     *http.Client
+	retention  time.Duration
+	minBackoff time.Duration
+	maxBackoff time.Duration
+	delivered  metrics.Counter
+	failed     metrics.Counter
+	clock      clock.Clock
+
+	mtx  sync.RWMutex
+	subs map[string]Subscription
+	// retries are the failing subscriptions by ID.
+	retries map[string]*retry
+}
+
+// retry is the backoff of a failing subscription.
+type retry struct {
+	failures int
+	next     time.Time
+}
+
+// New returns the Exposure of the subscriptions kept in st.
+func New(st store.Store, logger log.Logger, options ...Option) *Exposure {
+	x := &Exposure{
+		st:         st,
+		logger:     logger,
+		client:     &http.Client{Timeout: 10 * time.Second},
+		retention:  DefaultRetention,
+		minBackoff: DefaultMinBackoff,
+		maxBackoff: DefaultMaxBackoff,
+		delivered:  discard.NewCounter(),
+		failed:     discard.NewCounter(),
+		clock:      clock.Real,
+		subs:       make(map[string]Subscription),
+		retries:    make(map[string]*retry),
+	}
+	for _, option := range options {
+		option(x)
+	}
+	return x
+}
+
+// Subscribe adds the subscription s, whose ID and creation time are set.
+func (x *Exposure) Subscribe(ctx context.Context, s Subscription) (Subscription, error) {
+	u, err := url.Parse(s.NotifyURI)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return Subscription{}, ErrNotifyURI
+	}
+	s.ID = newID()
+	s.Created = x.clock.Now().UTC()
+	data, err := json.Marshal(s)
+	if err != nil {
+		return Subscription{}, err
+	}
+	if err := x.st.Set(ctx, subscriptionPrefix+s.ID, data, 0); err != nil {
+		return Subscription{}, err
+	}
+	x.mtx.Lock()
+	x.subs[s.ID] = s
+	x.mtx.Unlock()
+	return s, nil
+}
+
+// Subscription returns the subscription id.
+func (x *Exposure) Subscription(ctx context.Context, id string) (Subscription, error) {
+	var s Subscription
+	data, err := x.st.Get(ctx, subscriptionPrefix+id)
+	if err == store.ErrNotFound {
+		return s, ErrNotFound
+	}
+	if err != nil {
+		return s, err
+	}
+	err = json.Unmarshal(data, &s)
+	return s, err
+}
+
+// Subscriptions returns the subscriptions, the oldest first.
+func (x *Exposure) Subscriptions(ctx context.Context) ([]Subscription, error) {
+	keys, err := x.st.Keys(ctx, subscriptionPrefix)
+	if err != nil {
+		return nil, err
+	}
+	subs := make([]Subscription, 0, len(keys))
+	for _, key := range keys {
+		s, err := x.Subscription(ctx, strings.TrimPrefix(key, subscriptionPrefix))
+		if err == ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		subs = append(subs, s)
+	}
+	sort.Slice(subs, func(i, j int) bool { return subs[i].Created.Before(subs[j].Created) })
+	return subs, nil
+}
+
+// Unsubscribe deletes the subscription id and its pending notifications.
+func (x *Exposure) Unsubscribe(ctx context.Context, id string) error {
+	if _, err := x.Subscription(ctx, id); err != nil {
+		return err
+	}
+	if err := x.st.Delete(ctx, subscriptionPrefix+id); err != nil {
+		return err
+	}
+	x.mtx.Lock()
+	delete(x.subs, id)
+	delete(x.retries, id)
+	x.mtx.Unlock()
+	keys, err := x.st.Keys(ctx, pendingPrefix+id+"/")
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if err := x.st.Delete(ctx, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Publish queues a notification of e for every subscription it matches,
+// among those this replica knows of: the ones it created and the ones Run
+// loaded last. The notifications are stored before Publish returns, so
+// that they survive a restart; a failure to store one is logged.
+func (x *Exposure) Publish(e events.Event) {
+	if e.Time.IsZero() {
+		e.Time = x.clock.Now()
+	}
+	x.mtx.RLock()
+	var matched []string
+	for id, s := range x.subs {
+		if s.Filter.Match(e) {
+			matched = append(matched, id)
+		}
+	}
+	x.mtx.RUnlock()
+	for _, id := range matched {
+		n := Notification{ID: newID(), SubscriptionID: id, Event: e}
+		data, err := json.Marshal(n)
+		if err == nil {
+			key := fmt.Sprintf("%s%s/%019d-%s", pendingPrefix, id, e.Time.UnixNano(), n.ID)
+			err = x.st.Set(context.Background(), key, data, x.retention)
+		}
+		if err != nil {
+			level.Error(x.logger).Log("exposure", "publish", "subscription", id, "err", err)
+		}
+	}
+}
+
+// Run loads the subscriptions of the store, those of a previous run and of
+// the other replicas included, and delivers the pending notifications, at
+// once and then every interval until ctx is done.
+func (x *Exposure) Run(ctx context.Context, interval time.Duration) {
+	t := x.clock.NewTicker(interval)
+	defer t.Stop()
+	for {
+		if err := x.load(ctx); err != nil {
+			level.Error(x.logger).Log("exposure", "load", "err", err)
+		} else if err := x.deliver(ctx); err != nil {
+			level.Error(x.logger).Log("exposure", "deliver", "err", err)
+		}
+		select {
+		case <-t.C():
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// load replaces the subscriptions known with those of the store.
+func (x *Exposure) load(ctx context.Context) error {
+	subs, err := x.Subscriptions(ctx)
+	if err != nil {
+		return err
+	}
+	m := make(map[string]Subscription, len(subs))
+	for _, s := range subs {
+		m[s.ID] = s
+	}
+	x.mtx.Lock()
+	defer x.mtx.Unlock()
+	x.subs = m
+	for id := range x.retries {
+		if _, ok := m[id]; !ok {
+			delete(x.retries, id)
+		}
+	}
+	return nil
+}
+
+// deliver delivers the pending notifications of the subscriptions that are
+// not backing off, and that no other replica is delivering.
+func (x *Exposure) deliver(ctx context.Context) error {
+	keys, err := x.st.Keys(ctx, pendingPrefix)
+	if err != nil {
+		return err
+	}
+	pending := make(map[string][]string)
+	var order []string
+	for _, key := range keys {
+		id := strings.SplitN(strings.TrimPrefix(key, pendingPrefix), "/", 2)[0]
+		if _, ok := pending[id]; !ok {
+			order = append(order, id)
+		}
+		pending[id] = append(pending[id], key)
+	}
+	now := x.clock.Now()
+	for _, id := range order {
+		x.mtx.RLock()
+		s, ok := x.subs[id]
+		r := x.retries[id]
+		x.mtx.RUnlock()
+		if !ok {
+			// unsubscribed through another replica
+			for _, key := range pending[id] {
+				x.st.Delete(ctx, key)
+			}
+			continue
+		}
+		if r != nil && now.Before(r.next) {
+			continue
+		}
+		leased, err := x.st.SetNX(ctx, leasePrefix+id, nil, leaseTTL)
+		if err != nil {
+			return err
+		}
+		if !leased {
+			continue
+		}
+		err = x.deliverAll(ctx, s, pending[id])
+		x.st.Delete(ctx, leasePrefix+id)
+		x.backoff(id, err)
+		if err != nil {
+			level.Warn(x.logger).Log("exposure", "deliver", "subscription", id, "notify_uri", s.NotifyURI, "err", err)
+		}
+	}
+	return nil
+}
+
+// deliverAll delivers the notifications of s under keys in order, stopping
+// at the first failure.
+func (x *Exposure) deliverAll(ctx context.Context, s Subscription, keys []string) error {
+	for _, key := range keys {
+		data, err := x.st.Get(ctx, key)
+		if err == store.ErrNotFound {
+			// expired, or delivered by another replica
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := x.post(ctx, s.NotifyURI, data); err != nil {
+			x.failed.Add(1)
+			return err
+		}
+		x.delivered.Add(1)
+		if err := x.st.Delete(ctx, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (x *Exposure) post(ctx context.Context, uri string, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, uri, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := x.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("notify_uri answered %s", resp.Status)
+	}
+	return nil
+}
+
+// backoff records the outcome err of a delivery to the subscription id.
+func (x *Exposure) backoff(id string, err error) {
+	x.mtx.Lock()
+	defer x.mtx.Unlock()
+	if err == nil {
+		delete(x.retries, id)
+		return
+	}
+	r := x.retries[id]
+	if r == nil {
+		r = &retry{}
+		x.retries[id] = r
+	}
+	d := x.minBackoff << uint(r.failures)
+	if d > x.maxBackoff || d <= 0 {
+		d = x.maxBackoff
+	}
+	r.failures++
+	r.next = x.clock.Now().Add(d)
+}
+
+func newID() string {
+	var b [8]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
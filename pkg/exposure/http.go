@@ -0,0 +1,80 @@
+package exposure
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Path is the path Handler is usually served on, along with the paths under
+// it.
+const Path = "/exposure/subscriptions"
+
+// Handler returns the HTTP API of the subscriptions of x, served on Path:
+//
+//	POST   /exposure/subscriptions       subscribes, from a JSON Subscription
+//	GET    /exposure/subscriptions       lists the subscriptions
+//	GET    /exposure/subscriptions/{id}  returns a subscription
+//	DELETE /exposure/subscriptions/{id}  unsubscribes
+func Handler(x *Exposure) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := strings.Trim(strings.TrimPrefix(r.URL.Path, Path), "/")
+		switch {
+		case id == "" && r.Method == http.MethodPost:
+			var s Subscription
+			if err := json.NewDecoder(r.Body).Decode(&s); err != nil {
+				writeError(w, status.Error(codes.InvalidArgument, "exposure: "+err.Error()))
+				return
+			}
+			s, err := x.Subscribe(r.Context(), s)
+			if err != nil {
+				writeError(w, err)
+				return
+			}
+			w.Header().Set("Location", Path+"/"+s.ID)
+			writeJSON(w, http.StatusCreated, s)
+		case id == "" && r.Method == http.MethodGet:
+			subs, err := x.Subscriptions(r.Context())
+			if err != nil {
+				writeError(w, err)
+				return
+			}
+			writeJSON(w, http.StatusOK, subs)
+		case id != "" && r.Method == http.MethodGet:
+			s, err := x.Subscription(r.Context(), id)
+			if err != nil {
+				writeError(w, err)
+				return
+			}
+			writeJSON(w, http.StatusOK, s)
+		case id != "" && r.Method == http.MethodDelete:
+			if err := x.Unsubscribe(r.Context(), id); err != nil {
+				writeError(w, err)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	code := http.StatusInternalServerError
+	switch status.Code(err) {
+	case codes.InvalidArgument:
+		code = http.StatusBadRequest
+	case codes.NotFound:
+		code = http.StatusNotFound
+	}
+	writeJSON(w, code, map[string]string{"error": status.Convert(err).Message()})
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
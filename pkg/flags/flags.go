@@ -0,0 +1,258 @@
+// Package flags evaluates the feature flags of a service, so that a risky
+// behaviour can be turned on for some PLMNs, some slices or a percentage of
+// the UEs, and off again, without a redeploy. The flags are read from a JSON
+// file, typically a Kubernetes ConfigMap mounted in the pod, and reloaded when
+// it changes. A flag that is not defined is off.
+package flags
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/plmn"
+)
+
+// Path is where services mount Handler on their admin server.
+const Path = "/admin/flags"
+
+// DefaultInterval is the period the File client checks its file at.
+const DefaultInterval = 10 * time.Second
+
+// Target is what a flag is evaluated for.
+type Target struct {
+	// PLMN is the PLMN of the request, the zero ID when unknown.
+	PLMN plmn.ID
+	// Slice is the S-NSSAI written SST or SST-SD, such as "1-010203", empty
+	// when unknown.
+	Slice string
+	// Key identifies the UE, usually its SUPI. The percentages of the rules
+	// are taken over the keys, so a UE always gets the same value.
+	Key string
+}
+
+// Rule turns a flag on or off for the targets it matches.
+type Rule struct {
+	// PLMN is the MCC-MNC the rule applies to, every PLMN when empty.
+	PLMN string `json:"plmn,omitempty"`
+	// Slice is the S-NSSAI the rule applies to, every slice when empty. An
+	// SST alone matches the slices of that SST whatever their SD.
+	Slice string `json:"slice,omitempty"`
+	// Percentage is the share of the keys the rule applies to, all of them
+	// when omitted.
+	Percentage *float64 `json:"percentage,omitempty"`
+	// Enabled is the value of the flag for the targets matched.
+	Enabled bool `json:"enabled"`
+}
+
+// Flag is the definition of a flag: the first of its rules that matches a
+// target gives its value, Enabled when none does.
+type Flag struct {
+	Enabled bool   `json:"enabled"`
+	Rules   []Rule `json:"rules,omitempty"`
+}
+
+// Set is a set of flags by name, as written in the flags file:
+//
+//	{
+//	  "strict_validation": {
+//	    "enabled": false,
+//	    "rules": [{"plmn": "208-93", "slice": "1", "percentage": 10, "enabled": true}]
+//	  }
+//	}
+type Set map[string]Flag
+
+// Parse parses a set of flags, checking the PLMNs and percentages of their
+// rules.
+func Parse(data []byte) (Set, error) {
+	var s Set
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+	for name, f := range s {
+		for i, r := range f.Rules {
+			if r.PLMN != "" {
+				if _, err := plmn.Parse(r.PLMN); err != nil {
+					return nil, fmt.Errorf("flags: %s rule %d: %v", name, i, err)
+				}
+			}
+			if r.Percentage != nil && (*r.Percentage < 0 || *r.Percentage > 100) {
+				return nil, fmt.Errorf("flags: %s rule %d: percentage out of [0, 100]", name, i)
+			}
+		}
+	}
+	return s, nil
+}
+
+// Enabled returns the value of the flag name for t.
+func (s Set) Enabled(name string, t Target) bool {
+	f, ok := s[name]
+	if !ok {
+		return false
+	}
+	for _, r := range f.Rules {
+		if r.matches(name, t) {
+			return r.Enabled
+		}
+	}
+	return f.Enabled
+}
+
+func (r Rule) matches(name string, t Target) bool {
+	if r.PLMN != "" && r.PLMN != t.PLMN.String() {
+		return false
+	}
+	if r.Slice != "" && r.Slice != t.Slice && !strings.HasPrefix(t.Slice, r.Slice+"-") {
+		return false
+	}
+	if r.Percentage == nil {
+		return true
+	}
+	return bucket(name, t.Key) < *r.Percentage
+}
+
+// bucket places key in [0, 100), differently for every flag so that the
+// UEs enabled at 10% are not always the same ones.
+func bucket(name, key string) float64 {
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	h.Write([]byte{0})
+	h.Write([]byte(key))
+	return float64(h.Sum32()%10000) / 100
+}
+
+//go:generate mockgen -destination=../testutil/mocks/flags_client.go -package=mocks github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/flags Client
+
+// Client evaluates the flags of a service.
+type Client interface {
+	// Enabled returns the value of the flag name for t.
+	Enabled(ctx context.Context, name string, t Target) bool
+}
+
+// Static returns the client of the flags s, which never change. A nil Set
+// has every flag off.
+func Static(s Set) Client {
+	return static(s)
+}
+
+type static Set
+
+func (s static) Enabled(ctx context.Context, name string, t Target) bool {
+	return Set(s).Enabled(name, t)
+}
+
+// File is the client of the flags of a file, reloaded by Run when the file
+// changes. A Kubernetes ConfigMap mounted as a volume is updated in place by
+// the kubelet, a minute or so after it is edited.
+type File struct {
+	path   string
+	logger log.Logger
+
+	mtx      sync.RWMutex
+	set      Set
+	data     []byte
+	loadedAt time.Time
+}
+
+// NewFile returns the client of the flags of the file path, which must
+// parse.
+func NewFile(path string, logger log.Logger) (*File, error) {
+	f := &File{path: path, logger: logger}
+	if _, err := f.Reload(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Enabled implements Client.
+func (f *File) Enabled(ctx context.Context, name string, t Target) bool {
+	f.mtx.RLock()
+	defer f.mtx.RUnlock()
+	return f.set.Enabled(name, t)
+}
+
+// Reload reads the file again and reports whether the flags changed. The
+// flags are kept as they are when the file does not parse.
+func (f *File) Reload() (bool, error) {
+	data, err := ioutil.ReadFile(f.path)
+	if err != nil {
+		return false, err
+	}
+	f.mtx.RLock()
+	same := bytes.Equal(data, f.data)
+	f.mtx.RUnlock()
+	if same {
+		return false, nil
+	}
+	s, err := Parse(data)
+	if err != nil {
+		return false, err
+	}
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.set, f.data, f.loadedAt = s, data, time.Now()
+	return true, nil
+}
+
+// Run reloads the file every interval until ctx is done. The failed reloads
+// are logged and tried again at the next interval.
+func (f *File) Run(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			changed, err := f.Reload()
+			if err != nil {
+				level.Error(f.logger).Log("flags", f.path, "err", err)
+				continue
+			}
+			if changed {
+				level.Info(f.logger).Log("flags", f.path, "reloaded", strings.Join(f.names(), ","))
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (f *File) names() []string {
+	f.mtx.RLock()
+	defer f.mtx.RUnlock()
+	names := make([]string, 0, len(f.set))
+	for name := range f.set {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// State is the body returned by Handler.
+type State struct {
+	File     string    `json:"file"`
+	LoadedAt time.Time `json:"loaded_at"`
+	Flags    Set       `json:"flags"`
+}
+
+// Handler serves the flags in effect in f, read only: they are changed by
+// editing the file.
+func Handler(f *File) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.mtx.RLock()
+		s := State{File: f.path, LoadedAt: f.loadedAt, Flags: f.set}
+		f.mtx.RUnlock()
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(s)
+	})
+}
@@ -2,20 +2,11 @@ package endpoints
 
 import (
 	"context"
-	"time"
 
-	"github.com/go-kit/kit/circuitbreaker"
 	"github.com/go-kit/kit/endpoint"
-	"github.com/go-kit/kit/log"
-	"github.com/go-kit/kit/ratelimit"
-	"github.com/go-kit/kit/tracing/opentracing"
-	"github.com/go-kit/kit/tracing/zipkin"
-	stdopentracing "github.com/opentracing/opentracing-go"
-	stdzipkin "github.com/openzipkin/zipkin-go"
-	"github.com/sony/gobreaker"
-	"golang.org/x/time/rate"
 
 	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/foosvc/service"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/kitx/chain"
 )
 
 // Endpoints collects all of the endpoints that compose the foosvc service. It's
@@ -26,19 +17,12 @@ type Endpoints struct {
 }
 
 // New return a new instance of the endpoint that wraps the provided service.
-func New(svc service.FoosvcService, logger log.Logger, otTracer stdopentracing.Tracer, zipkinTracer *stdzipkin.Tracer) (ep Endpoints) {
-	var fooEndpoint endpoint.Endpoint
-	{
-		method := "foo"
-		fooEndpoint = MakeFooEndpoint(svc)
-		fooEndpoint = ratelimit.NewErroringLimiter(rate.NewLimiter(rate.Every(time.Second), 100))(fooEndpoint)
-		fooEndpoint = circuitbreaker.Gobreaker(gobreaker.NewCircuitBreaker(gobreaker.Settings{}))(fooEndpoint)
-		fooEndpoint = opentracing.TraceServer(otTracer, method)(fooEndpoint)
-		fooEndpoint = zipkin.TraceEndpoint(zipkinTracer, method)(fooEndpoint)
-		fooEndpoint = LoggingMiddleware(log.With(logger, "method", method))(fooEndpoint)
-		ep.FooEndpoint = fooEndpoint
-	}
-
+// The endpoints run in the middleware stack of mw, the methods being idempotent.
+func New(svc service.FoosvcService, mw chain.MiddlewareConfig) (ep Endpoints) {
+	mw.Logging = LoggingMiddleware
+	mw.Instrumenting = InstrumentingMiddleware
+	c := chain.New(mw)
+	ep.FooEndpoint = c.Build("foo", MakeFooEndpoint(svc), chain.Idempotent(FooResponse{}))
 	return ep
 }
 
@@ -6,6 +6,8 @@ import (
 
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/logging"
 )
 
 type loggingMiddleware struct {
@@ -23,7 +25,7 @@ func LoggingMiddleware(logger log.Logger) Middleware {
 
 func (lm loggingMiddleware) Foo(ctx context.Context, s string) (res string, err error) {
 	defer func(begin time.Time) {
-		lm.logger.Log("method", "Foo", "s", s, "err", err)
+		log.With(lm.logger, logging.ContextKeyvals(ctx)...).Log("method", "Foo", "s", s, "err", err)
 	}(time.Now())
 
 	return lm.next.Foo(ctx, s)
@@ -20,7 +20,7 @@ type FoosvcService interface {
 
 // the concrete implementation of service interface
 type stubFoosvcService struct {
-	logger log.Logger `json:"logger"`
+	logger log.Logger
 	addsvc addsvcservice.AddsvcService
 }
 
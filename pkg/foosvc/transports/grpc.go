@@ -20,8 +20,14 @@ import (
 	"google.golang.org/grpc/status"
 
 	pb "github.com/miki-tnt/sa5g-go-usvc-k8s/pb/foosvc"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/breakers"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/caller"
 	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/foosvc/endpoints"
 	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/foosvc/service"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/grpcpool"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/idempotency"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/logging"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/meta"
 )
 
 type grpcServer struct {
@@ -50,7 +56,10 @@ func MakeGRPCServer(endpoints endpoints.Endpoints, otTracer stdopentracing.Trace
 	zipkinServer := zipkin.GRPCServerTrace(zipkinTracer)
 
 	options := []grpctransport.ServerOption{
-		grpctransport.ServerErrorLogger(logger),
+		grpctransport.ServerErrorHandler(logging.ErrorHandler(logger)),
+		grpctransport.ServerBefore(idempotency.GRPCToContext()),
+		grpctransport.ServerBefore(caller.GRPCToContext()),
+		grpctransport.ServerBefore(meta.GRPCToContext()),
 		zipkinServer,
 	}
 
@@ -82,7 +91,13 @@ func encodeGRPCFooResponse(_ context.Context, grpcReply interface{}) (res interf
 // of the conn. The caller is responsible for constructing the conn, and
 // eventually closing the underlying transport. We bake-in certain middlewares,
 // implementing the client library pattern.
-func NewGRPCClient(conn *grpc.ClientConn, otTracer stdopentracing.Tracer, zipkinTracer *stdzipkin.Tracer, logger log.Logger) service.FoosvcService { // We construct a single ratelimiter middleware, to limit the total outgoing
+func NewGRPCClient(conn *grpc.ClientConn, otTracer stdopentracing.Tracer, zipkinTracer *stdzipkin.Tracer, logger log.Logger) service.FoosvcService {
+	return NewGRPCPoolClient(grpcpool.FromConn(conn), otTracer, zipkinTracer, logger)
+}
+
+// NewGRPCPoolClient is like NewGRPCClient, but spreads the calls over the
+// connections of the pool. The caller is responsible for closing the pool.
+func NewGRPCPoolClient(pool *grpcpool.Pool, otTracer stdopentracing.Tracer, zipkinTracer *stdzipkin.Tracer, logger log.Logger) service.FoosvcService { // We construct a single ratelimiter middleware, to limit the total outgoing
 	// QPS from this client to all methods on the remote instance. We also
 	// construct per-endpoint circuitbreaker middlewares to demonstrate how
 	// that's done, although they could easily be combined into a single breaker
@@ -100,6 +115,8 @@ func NewGRPCClient(conn *grpc.ClientConn, otTracer stdopentracing.Tracer, zipkin
 
 	// global client middlewares
 	options := []grpctransport.ClientOption{
+		grpctransport.ClientBefore(idempotency.ContextToGRPC()),
+		grpctransport.ClientBefore(meta.ContextToGRPC()),
 		zipkinClient,
 	}
 
@@ -107,18 +124,20 @@ func NewGRPCClient(conn *grpc.ClientConn, otTracer stdopentracing.Tracer, zipkin
 	// middlewares to demonstrate how to specialize per-endpoint.
 	var fooEndpoint endpoint.Endpoint
 	{
-		fooEndpoint = grpctransport.NewClient(
-			conn,
-			"pb.Foosvc",
-			"Foo",
-			encodeGRPCFooRequest,
-			decodeGRPCFooResponse,
-			pb.FooReply{},
-			append(options, grpctransport.ClientBefore(opentracing.ContextToGRPC(otTracer, logger)))...,
-		).Endpoint()
+		fooEndpoint = pool.Endpoint(func(conn *grpc.ClientConn) endpoint.Endpoint {
+			return grpctransport.NewClient(
+				conn,
+				"pb.Foosvc",
+				"Foo",
+				encodeGRPCFooRequest,
+				decodeGRPCFooResponse,
+				pb.FooReply{},
+				append(options, grpctransport.ClientBefore(opentracing.ContextToGRPC(otTracer, logger)))...,
+			).Endpoint()
+		})
 		fooEndpoint = opentracing.TraceClient(otTracer, "Foo")(fooEndpoint)
 		fooEndpoint = limiter(fooEndpoint)
-		fooEndpoint = circuitbreaker.Gobreaker(gobreaker.NewCircuitBreaker(gobreaker.Settings{
+		fooEndpoint = circuitbreaker.Gobreaker(breakers.New(gobreaker.Settings{
 			Name:    "Foo",
 			Timeout: 30 * time.Second,
 		}))(fooEndpoint)
@@ -21,14 +21,23 @@ import (
 	"github.com/go-kit/kit/tracing/opentracing"
 	"github.com/go-kit/kit/tracing/zipkin"
 	httptransport "github.com/go-kit/kit/transport/http"
+	"github.com/golang/protobuf/proto"
 	stdopentracing "github.com/opentracing/opentracing-go"
 	stdzipkin "github.com/openzipkin/zipkin-go"
 	"github.com/sony/gobreaker"
 	"golang.org/x/time/rate"
 	"google.golang.org/grpc/status"
 
+	pb "github.com/miki-tnt/sa5g-go-usvc-k8s/pb/foosvc"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/breakers"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/caller"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/codec"
 	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/foosvc/endpoints"
 	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/foosvc/service"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/idempotency"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/logging"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/meta"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/openapi"
 )
 
 type errorWrapper struct {
@@ -58,26 +67,30 @@ func NewHTTPHandler(endpoints endpoints.Endpoints, otTracer stdopentracing.Trace
 
 	options := []httptransport.ServerOption{
 		httptransport.ServerErrorEncoder(httpEncodeError),
-		httptransport.ServerErrorLogger(logger),
+		httptransport.ServerErrorHandler(logging.ErrorHandler(logger)),
+		httptransport.ServerBefore(idempotency.HTTPToContext()),
+		httptransport.ServerBefore(caller.HTTPToContext()),
+		httptransport.ServerBefore(meta.HTTPToContext()),
+		httptransport.ServerBefore(codec.Default.HTTPToContext()),
 		zipkinServer,
 	}
 
 	m := http.NewServeMux()
 	m.Handle("/foo", httptransport.NewServer(
 		endpoints.FooEndpoint,
-		decodeHTTPFooRequest,
-		httptransport.EncodeJSONResponse,
+		codec.DecodeRequest(fooBinding),
+		codec.EncodeResponse(fooBinding),
 		append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "Foo", logger)))...,
 	))
+	m.Handle(openapi.Path, openapi.Handler(OpenAPI()))
 	return m
 }
 
-// decodeHTTPFooRequest is a transport/http.DecodeRequestFunc that decodes a
-// JSON-encoded request from the HTTP request body. Primarily useful in a server.
-func decodeHTTPFooRequest(_ context.Context, r *http.Request) (interface{}, error) {
-	var req endpoints.FooRequest
-	err := json.NewDecoder(r.Body).Decode(&req)
-	return req, err
+var fooBinding = codec.Binding{
+	Request:   func() interface{} { return &endpoints.FooRequest{} },
+	PBRequest: func() proto.Message { return &pb.FooRequest{} },
+	FromPB:    decodeGRPCFooRequest,
+	ToPB:      encodeGRPCFooResponse,
 }
 
 // NewHTTPClient returns an AddService backed by an HTTP server living at the
@@ -108,6 +121,8 @@ func NewHTTPClient(instance string, otTracer stdopentracing.Tracer, zipkinTracer
 
 	// global client middlewares
 	options := []httptransport.ClientOption{
+		httptransport.ClientBefore(idempotency.ContextToHTTP()),
+		httptransport.ClientBefore(meta.ContextToHTTP()),
 		zipkinClient,
 	}
 
@@ -131,7 +146,7 @@ func NewHTTPClient(instance string, otTracer stdopentracing.Tracer, zipkinTracer
 		fooEndpoint = opentracing.TraceClient(otTracer, "Foo")(fooEndpoint)
 		fooEndpoint = zipkin.TraceEndpoint(zipkinTracer, "Foo")(fooEndpoint)
 		fooEndpoint = limiter(fooEndpoint)
-		fooEndpoint = circuitbreaker.Gobreaker(gobreaker.NewCircuitBreaker(gobreaker.Settings{
+		fooEndpoint = circuitbreaker.Gobreaker(breakers.New(gobreaker.Settings{
 			Name:    "Foo",
 			Timeout: 30 * time.Second,
 		}))(fooEndpoint)
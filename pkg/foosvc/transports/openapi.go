@@ -0,0 +1,20 @@
+package transports
+
+import (
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/foosvc/endpoints"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/openapi"
+)
+
+// OpenAPI returns the description of the HTTP transport, served by
+// NewHTTPHandler on openapi.Path.
+func OpenAPI() *openapi.Document {
+	return openapi.New("foosvc", "v1",
+		openapi.Operation{
+			Path:     "/foo",
+			ID:       "Foo",
+			Summary:  "Foo calls addsvc Concat with s.",
+			Request:  endpoints.FooRequest{},
+			Response: endpoints.FooResponse{},
+		},
+	)
+}
@@ -0,0 +1,276 @@
+// Package fsm describes the procedures of the network functions as finite
+// state machines. A Definition lists the states, the events and the
+// transitions between them, with their guards and actions, and the hooks run
+// on entering and leaving a state; New checks it once, and every procedure
+// then runs an Instance of the machine. An event that the current state does
+// not expect is rejected with an error rather than handled by accident.
+//
+//	def := fsm.Definition{
+//		Name:    "registration",
+//		Initial: Deregistered,
+//		Transitions: []fsm.Transition{
+//			{From: []fsm.State{Deregistered}, Event: Register, To: Authenticating, Action: authenticate},
+//			{From: []fsm.State{Authenticating}, Event: Confirm, To: Registered, Action: confirm},
+//		},
+//	}
+package fsm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// State is a state of a machine.
+type State string
+
+// Event is an event fired at a machine.
+type Event string
+
+// Guard decides whether a transition may be taken. arg is the argument the
+// event was fired with.
+type Guard func(ctx context.Context, arg interface{}) bool
+
+// Action runs during a transition. An error aborts the transition, leaving
+// the instance in the state it was in.
+type Action func(ctx context.Context, arg interface{}) error
+
+// Hook runs when an instance enters or leaves a state.
+type Hook func(ctx context.Context, from, to State, event Event)
+
+// Transition moves an instance from one of the From states, or from any state
+// when From is empty, to To when Event is fired and Guard, if any, allows it.
+// Several transitions may share their From state and Event as long as they
+// have guards: the first one whose guard allows it is taken.
+type Transition struct {
+	From   []State
+	Event  Event
+	To     State
+	Guard  Guard
+	Action Action
+}
+
+// Definition is the declaration of a machine.
+type Definition struct {
+	Name        string
+	Initial     State
+	Transitions []Transition
+	OnEnter     map[State]Hook
+	OnExit      map[State]Hook
+}
+
+// InvalidTransitionError is returned when an event is fired in a state that
+// has no transition for it.
+type InvalidTransitionError struct {
+	Machine string
+	State   State
+	Event   Event
+}
+
+func (e *InvalidTransitionError) Error() string {
+	return fmt.Sprintf("%s: event %s is invalid in state %s", e.Machine, e.Event, e.State)
+}
+
+// GuardError is returned when the guards of every transition of an event
+// reject it.
+type GuardError struct {
+	Machine string
+	State   State
+	Event   Event
+}
+
+func (e *GuardError) Error() string {
+	return fmt.Sprintf("%s: event %s rejected by the guards of state %s", e.Machine, e.Event, e.State)
+}
+
+// Machine is a checked Definition.
+type Machine struct {
+	def    Definition
+	states []State
+	// the transitions by state and event
+	table map[State]map[Event][]*Transition
+}
+
+// New checks def and returns its machine. Every state must be reachable from
+// the initial one, and a state cannot have two unguarded transitions for the
+// same event.
+func New(def Definition) (*Machine, error) {
+	if def.Initial == "" {
+		return nil, fmt.Errorf("%s: no initial state", def.Name)
+	}
+	m := &Machine{def: def, table: map[State]map[Event][]*Transition{}}
+	seen := map[State]bool{}
+	add := func(s State) {
+		if !seen[s] {
+			seen[s] = true
+			m.states = append(m.states, s)
+		}
+	}
+	add(def.Initial)
+	for i := range def.Transitions {
+		t := &def.Transitions[i]
+		if t.Event == "" || t.To == "" {
+			return nil, fmt.Errorf("%s: transition %d: no event or target state", def.Name, i+1)
+		}
+		for _, s := range t.From {
+			add(s)
+		}
+		add(t.To)
+	}
+	for i := range def.Transitions {
+		t := &def.Transitions[i]
+		from := t.From
+		if len(from) == 0 {
+			from = m.states
+		}
+		for _, s := range from {
+			if m.table[s] == nil {
+				m.table[s] = map[Event][]*Transition{}
+			}
+			for _, o := range m.table[s][t.Event] {
+				if o.Guard == nil {
+					return nil, fmt.Errorf("%s: event %s of state %s has a transition after an unguarded one", def.Name, t.Event, s)
+				}
+			}
+			m.table[s][t.Event] = append(m.table[s][t.Event], t)
+		}
+	}
+	if s, ok := m.unreachable(); ok {
+		return nil, fmt.Errorf("%s: state %s is unreachable", def.Name, s)
+	}
+	return m, nil
+}
+
+// MustNew is like New but panics on an invalid definition. It is meant for
+// the machines declared as package variables.
+func MustNew(def Definition) *Machine {
+	m, err := New(def)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// Name returns the name of the machine.
+func (m *Machine) Name() string {
+	return m.def.Name
+}
+
+// States returns the states of the machine, the initial one first.
+func (m *Machine) States() []State {
+	return append([]State(nil), m.states...)
+}
+
+// Instance returns a new instance of the machine in its initial state.
+func (m *Machine) Instance() *Instance {
+	return &Instance{m: m, state: m.def.Initial}
+}
+
+// Mermaid returns the state diagram of the machine in the Mermaid syntax,
+// for the documentation.
+func (m *Machine) Mermaid() string {
+	var b strings.Builder
+	b.WriteString("stateDiagram-v2\n")
+	fmt.Fprintf(&b, "    [*] --> %s\n", m.def.Initial)
+	for _, t := range m.def.Transitions {
+		from := t.From
+		if len(from) == 0 {
+			from = m.states
+		}
+		label := string(t.Event)
+		if t.Guard != nil {
+			label += " [guarded]"
+		}
+		for _, s := range from {
+			fmt.Fprintf(&b, "    %s --> %s: %s\n", s, t.To, label)
+		}
+	}
+	return b.String()
+}
+
+func (m *Machine) unreachable() (State, bool) {
+	reached := map[State]bool{m.def.Initial: true}
+	queue := []State{m.def.Initial}
+	for len(queue) > 0 {
+		s := queue[0]
+		queue = queue[1:]
+		for _, ts := range m.table[s] {
+			for _, t := range ts {
+				if !reached[t.To] {
+					reached[t.To] = true
+					queue = append(queue, t.To)
+				}
+			}
+		}
+	}
+	for _, s := range m.states {
+		if !reached[s] {
+			return s, true
+		}
+	}
+	return "", false
+}
+
+// Instance is a running machine. Its events are handled one at a time.
+type Instance struct {
+	m     *Machine
+	mtx   sync.Mutex
+	state State
+}
+
+// State returns the current state of the instance.
+func (in *Instance) State() State {
+	in.mtx.Lock()
+	defer in.mtx.Unlock()
+	return in.state
+}
+
+// Can reports whether event has a transition from the current state, its
+// guards aside.
+func (in *Instance) Can(event Event) bool {
+	in.mtx.Lock()
+	defer in.mtx.Unlock()
+	return len(in.m.table[in.state][event]) > 0
+}
+
+// Fire handles event: it takes the first transition of the current state
+// for event that its guard allows, runs its action and moves the instance to
+// its target state. The exit hook of the current state and the enter hook of
+// the target state run after the action when the state changes. Fire
+// returns an *InvalidTransitionError or a *GuardError when no transition can
+// be taken, and the error of the action when it fails.
+func (in *Instance) Fire(ctx context.Context, event Event, arg interface{}) error {
+	in.mtx.Lock()
+	defer in.mtx.Unlock()
+	from := in.state
+	ts := in.m.table[from][event]
+	if len(ts) == 0 {
+		return &InvalidTransitionError{Machine: in.m.def.Name, State: from, Event: event}
+	}
+	var t *Transition
+	for _, c := range ts {
+		if c.Guard == nil || c.Guard(ctx, arg) {
+			t = c
+			break
+		}
+	}
+	if t == nil {
+		return &GuardError{Machine: in.m.def.Name, State: from, Event: event}
+	}
+	if t.Action != nil {
+		if err := t.Action(ctx, arg); err != nil {
+			return err
+		}
+	}
+	in.state = t.To
+	if from != t.To {
+		if h := in.m.def.OnExit[from]; h != nil {
+			h(ctx, from, t.To, event)
+		}
+		if h := in.m.def.OnEnter[t.To]; h != nil {
+			h(ctx, from, t.To, event)
+		}
+	}
+	return nil
+}
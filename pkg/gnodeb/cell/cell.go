@@ -0,0 +1,79 @@
+// Package cell models the cells of a gNB and their radio resources. A
+// Manager holds the configured cells, changed at runtime through its gRPC
+// and HTTP API, and admits the work of each cell against its physical
+// resource blocks (PRBs): a preamble is only processed when its cell is
+// enabled and has the PRBs to spare, and when every admission hook agrees.
+package cell
+
+import (
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Limits of the identities of a cell (TS 38.300, TS 38.211, TS 23.003).
+const (
+	MaxNCI = 1<<36 - 1
+	MaxPCI = 1007
+	MaxTAC = 1<<24 - 1
+)
+
+// Cell is a configured NR cell.
+type Cell struct {
+	// NCI is the NR cell identity, 36 bits.
+	NCI uint64 `json:"nci"`
+	// PCI is the physical cell identity, from 0 to 1007.
+	PCI uint32 `json:"pci"`
+	// TAC is the tracking area code, 24 bits.
+	TAC uint32 `json:"tac"`
+	// Band is the NR operating band, 78 for n78.
+	Band uint32 `json:"band"`
+	// Bandwidth is the channel bandwidth in MHz.
+	Bandwidth uint32 `json:"bandwidth_mhz"`
+	// SCS is the subcarrier spacing in kHz: 15, 30 or 60.
+	SCS     uint32 `json:"scs_khz"`
+	Enabled bool   `json:"enabled"`
+}
+
+// prbs is the maximum transmission bandwidth in resource blocks, by
+// subcarrier spacing and channel bandwidth, of FR1 (TS 38.101-1 table
+// 5.3.2-1).
+var prbs = map[uint32]map[uint32]int{
+	15: {5: 25, 10: 52, 15: 79, 20: 106, 25: 133, 30: 160, 40: 216, 50: 270},
+	30: {5: 11, 10: 24, 15: 38, 20: 51, 25: 65, 30: 78, 40: 106, 50: 133, 60: 162, 70: 189, 80: 217, 90: 245, 100: 273},
+	60: {10: 11, 15: 18, 20: 24, 25: 31, 30: 38, 40: 51, 50: 65, 60: 79, 70: 93, 80: 107, 90: 121, 100: 135},
+}
+
+// PRBs returns the number of resource blocks of the carrier of c.
+func (c Cell) PRBs() int {
+	return prbs[c.SCS][c.Bandwidth]
+}
+
+// Validate checks the identities of c and that its bandwidth is one of its
+// subcarrier spacing.
+func (c Cell) Validate() error {
+	switch {
+	case c.NCI == 0 || c.NCI > MaxNCI:
+		return invalid("nci %d out of range", c.NCI)
+	case c.PCI > MaxPCI:
+		return invalid("pci %d out of range", c.PCI)
+	case c.TAC > MaxTAC:
+		return invalid("tac %d out of range", c.TAC)
+	case c.Band == 0:
+		return invalid("missing band")
+	case prbs[c.SCS] == nil:
+		return invalid("unsupported subcarrier spacing %d kHz", c.SCS)
+	case c.PRBs() == 0:
+		return invalid("unsupported bandwidth %d MHz at %d kHz", c.Bandwidth, c.SCS)
+	}
+	return nil
+}
+
+func (c Cell) String() string {
+	return fmt.Sprintf("%09x", c.NCI)
+}
+
+func invalid(format string, args ...interface{}) error {
+	return status.Errorf(codes.InvalidArgument, "cell: "+format, args...)
+}
@@ -0,0 +1,273 @@
+package cell
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/go-kit/kit/metrics"
+	"github.com/go-kit/kit/metrics/discard"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	// ErrNotFound is returned for a cell that is not configured.
+	ErrNotFound = status.Error(codes.NotFound, "cell not found")
+	// ErrExists is returned when adding a cell already configured.
+	ErrExists = status.Error(codes.AlreadyExists, "cell already exists")
+	// ErrDisabled is returned for the work of a disabled cell.
+	ErrDisabled = status.Error(codes.Unavailable, "cell disabled")
+	// ErrCongested is returned for the work of a cell without the PRBs to
+	// spare.
+	ErrCongested = status.Error(codes.ResourceExhausted, "cell congested")
+	// ErrInUse is returned when shrinking a cell below the PRBs in use.
+	ErrInUse = status.Error(codes.FailedPrecondition, "cell resources in use")
+)
+
+// Usage is the use of the PRBs of a cell.
+type Usage struct {
+	PRBs     int   `json:"prbs"`
+	Used     int   `json:"used"`
+	Peak     int   `json:"peak"`
+	Admitted int64 `json:"admitted"`
+	Rejected int64 `json:"rejected"`
+}
+
+// Utilization returns the fraction of the PRBs in use.
+func (u Usage) Utilization() float64 {
+	if u.PRBs == 0 {
+		return 0
+	}
+	return float64(u.Used) / float64(u.PRBs)
+}
+
+// Hook decides whether the work of c needing prbs more PRBs is admitted, u
+// being the use of the PRBs of c before it. It returns the error rejecting
+// the work, nil to admit it. Hooks run with the lock of the manager held and
+// must not call it.
+type Hook func(ctx context.Context, c Cell, u Usage, prbs int) error
+
+// MaxUtilization returns a hook rejecting the work that would take the
+// utilization of a cell over max, in (0, 1], keeping headroom for the
+// connected UEs.
+func MaxUtilization(max float64) Hook {
+	return func(_ context.Context, c Cell, u Usage, prbs int) error {
+		if float64(u.Used+prbs) > max*float64(u.PRBs) {
+			return ErrCongested
+		}
+		return nil
+	}
+}
+
+// Option sets an optional parameter of a Manager.
+type Option func(*Manager)
+
+// AdmissionHook adds a hook run on every admission, in the order given.
+func AdmissionHook(h Hook) Option {
+	return func(m *Manager) { m.hooks = append(m.hooks, h) }
+}
+
+// UtilizationGauge sets the gauge reporting the PRB utilization of the cells,
+// with the label "nci".
+func UtilizationGauge(g metrics.Gauge) Option {
+	return func(m *Manager) { m.utilizationGauge = g }
+}
+
+// AdmissionCounter sets the counter incremented on every admission, with the
+// labels "nci" and "admitted", "true" or "false".
+func AdmissionCounter(c metrics.Counter) Option {
+	return func(m *Manager) { m.admissionCounter = c }
+}
+
+// Manager holds the cells of a gNB and the use of their PRBs.
+type Manager struct {
+	hooks            []Hook
+	utilizationGauge metrics.Gauge
+	admissionCounter metrics.Counter
+
+	mtx   sync.Mutex
+	cells map[uint64]*state
+}
+
+type state struct {
+	cell  Cell
+	usage Usage
+}
+
+// NewManager returns a Manager without cells.
+func NewManager(options ...Option) *Manager {
+	m := &Manager{
+		utilizationGauge: discard.NewGauge(),
+		admissionCounter: discard.NewCounter(),
+		cells:            map[uint64]*state{},
+	}
+	for _, option := range options {
+		option(m)
+	}
+	return m
+}
+
+// Add configures the cell c.
+func (m *Manager) Add(c Cell) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	if _, ok := m.cells[c.NCI]; ok {
+		return ErrExists
+	}
+	m.cells[c.NCI] = &state{cell: c, usage: Usage{PRBs: c.PRBs()}}
+	m.observe(m.cells[c.NCI])
+	return nil
+}
+
+// Update replaces the configuration of the cell c.NCI. The work admitted
+// keeps its PRBs; a carrier is not shrunk below the PRBs in use.
+func (m *Manager) Update(c Cell) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	s, ok := m.cells[c.NCI]
+	if !ok {
+		return ErrNotFound
+	}
+	if c.PRBs() < s.usage.Used {
+		return ErrInUse
+	}
+	s.cell = c
+	s.usage.PRBs = c.PRBs()
+	m.observe(s)
+	return nil
+}
+
+// SetEnabled enables or disables the cell nci. A disabled cell admits no
+// work, the work admitted before runs to its end.
+func (m *Manager) SetEnabled(nci uint64, enabled bool) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	s, ok := m.cells[nci]
+	if !ok {
+		return ErrNotFound
+	}
+	s.cell.Enabled = enabled
+	return nil
+}
+
+// Remove deletes the cell nci, which must be disabled and idle.
+func (m *Manager) Remove(nci uint64) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	s, ok := m.cells[nci]
+	if !ok {
+		return ErrNotFound
+	}
+	if s.cell.Enabled || s.usage.Used > 0 {
+		return ErrInUse
+	}
+	delete(m.cells, nci)
+	return nil
+}
+
+// Get returns the cell nci and the use of its PRBs.
+func (m *Manager) Get(nci uint64) (Cell, Usage, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	s, ok := m.cells[nci]
+	if !ok {
+		return Cell{}, Usage{}, ErrNotFound
+	}
+	return s.cell, s.usage, nil
+}
+
+// Status is a cell and the use of its PRBs.
+type Status struct {
+	Cell
+	Usage Usage `json:"usage"`
+}
+
+// List returns the cells, by NCI.
+func (m *Manager) List() []Status {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	list := make([]Status, 0, len(m.cells))
+	for _, s := range m.cells {
+		list = append(list, Status{Cell: s.cell, Usage: s.usage})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].NCI < list[j].NCI })
+	return list
+}
+
+// Admit admits work of the cell nci needing prbs PRBs, which it holds until
+// the returned release is called.
+func (m *Manager) Admit(ctx context.Context, nci uint64, prbs int) (release func(), err error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	s, ok := m.cells[nci]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if err := m.admit(ctx, s, prbs); err != nil {
+		s.usage.Rejected++
+		m.admissionCounter.With("nci", s.cell.String(), "admitted", "false").Add(1)
+		return nil, err
+	}
+	s.usage.Used += prbs
+	if s.usage.Used > s.usage.Peak {
+		s.usage.Peak = s.usage.Used
+	}
+	s.usage.Admitted++
+	m.admissionCounter.With("nci", s.cell.String(), "admitted", "true").Add(1)
+	m.observe(s)
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			m.mtx.Lock()
+			defer m.mtx.Unlock()
+			s.usage.Used -= prbs
+			m.observe(s)
+		})
+	}, nil
+}
+
+// admit runs the checks of an admission, the lock held.
+func (m *Manager) admit(ctx context.Context, s *state, prbs int) error {
+	if !s.cell.Enabled {
+		return ErrDisabled
+	}
+	if s.usage.Used+prbs > s.usage.PRBs {
+		return ErrCongested
+	}
+	for _, h := range m.hooks {
+		if err := h(ctx, s.cell, s.usage, prbs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// observe reports the utilization of s, the lock held.
+func (m *Manager) observe(s *state) {
+	m.utilizationGauge.With("nci", s.cell.String()).Set(s.usage.Utilization())
+}
+
+// Load adds the cells of data, a JSON array of cells, and returns how many
+// it added.
+func (m *Manager) Load(data []byte) (int, error) {
+	var cells []Cell
+	if err := json.Unmarshal(data, &cells); err != nil {
+		return 0, err
+	}
+	for i, c := range cells {
+		if err := m.Add(c); err != nil {
+			return i, status.Errorf(status.Code(err), "cell %s: %s", strconv.FormatUint(c.NCI, 10), status.Convert(err).Message())
+		}
+	}
+	return len(cells), nil
+}
@@ -0,0 +1,166 @@
+package endpoints
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/gnodeb/cu/service"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/gnodeb/f1"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/gnodeb/xn"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/kitx/chain"
+)
+
+// Endpoints collects all of the endpoints that compose the gnbcu service. It's
+// meant to be used as a helper struct, to collect all of the endpoints into a
+// single parameter.
+type Endpoints struct {
+	F1SetupEndpoint                     endpoint.Endpoint `json:""`
+	InitialULRRCMessageTransferEndpoint endpoint.Endpoint `json:""`
+	ULRRCMessageTransferEndpoint        endpoint.Endpoint `json:""`
+	UERadioCapabilityEndpoint           endpoint.Endpoint `json:""`
+	HandoverPreparationEndpoint         endpoint.Endpoint `json:""`
+	HandoverCancelEndpoint              endpoint.Endpoint `json:""`
+	UEContextReleaseEndpoint            endpoint.Endpoint `json:""`
+}
+
+// New return a new instance of the endpoint that wraps the provided service.
+// The endpoints run in the middleware stack of mw.
+func New(svc service.GnbcuService, mw chain.MiddlewareConfig) (ep Endpoints) {
+	mw.Logging = LoggingMiddleware
+	c := chain.New(mw)
+	ep.F1SetupEndpoint = c.Build("f1Setup", MakeF1SetupEndpoint(svc))
+	ep.InitialULRRCMessageTransferEndpoint = c.Build("initialULRRCMessageTransfer", MakeInitialULRRCMessageTransferEndpoint(svc))
+	ep.ULRRCMessageTransferEndpoint = c.Build("ulRRCMessageTransfer", MakeULRRCMessageTransferEndpoint(svc))
+	ep.UERadioCapabilityEndpoint = c.Build("ueRadioCapability", MakeUERadioCapabilityEndpoint(svc))
+	ep.HandoverPreparationEndpoint = c.Build("handoverPreparation", MakeHandoverPreparationEndpoint(svc))
+	ep.HandoverCancelEndpoint = c.Build("handoverCancel", MakeHandoverCancelEndpoint(svc))
+	ep.UEContextReleaseEndpoint = c.Build("ueContextRelease", MakeUEContextReleaseEndpoint(svc))
+	return ep
+}
+
+// MakeF1SetupEndpoint returns an endpoint that invokes F1Setup on the service.
+// Primarily useful in a server.
+func MakeF1SetupEndpoint(svc service.GnbcuService) (ep endpoint.Endpoint) {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(f1.SetupRequest)
+		return svc.F1Setup(ctx, req)
+	}
+}
+
+// F1Setup implements the service interface, so Endpoints may be used as a service.
+// This is primarily useful in the context of a client library.
+func (e Endpoints) F1Setup(ctx context.Context, req f1.SetupRequest) (rs f1.SetupResponse, err error) {
+	resp, err := e.F1SetupEndpoint(ctx, req)
+	if err != nil {
+		return
+	}
+	return resp.(f1.SetupResponse), nil
+}
+
+// MakeInitialULRRCMessageTransferEndpoint returns an endpoint that invokes
+// InitialULRRCMessageTransfer on the service. Primarily useful in a server.
+func MakeInitialULRRCMessageTransferEndpoint(svc service.GnbcuService) (ep endpoint.Endpoint) {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(f1.InitialULRRCMessage)
+		cuUEID, err := svc.InitialULRRCMessageTransfer(ctx, req)
+		return InitialULRRCMessageTransferResponse{CUUEID: cuUEID}, err
+	}
+}
+
+// InitialULRRCMessageTransfer implements the service interface, so Endpoints may be used as a service.
+// This is primarily useful in the context of a client library.
+func (e Endpoints) InitialULRRCMessageTransfer(ctx context.Context, m f1.InitialULRRCMessage) (cuUEID uint32, err error) {
+	resp, err := e.InitialULRRCMessageTransferEndpoint(ctx, m)
+	if err != nil {
+		return
+	}
+	return resp.(InitialULRRCMessageTransferResponse).CUUEID, nil
+}
+
+// MakeULRRCMessageTransferEndpoint returns an endpoint that invokes
+// ULRRCMessageTransfer on the service. Primarily useful in a server.
+func MakeULRRCMessageTransferEndpoint(svc service.GnbcuService) (ep endpoint.Endpoint) {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(f1.RRCMessage)
+		return RRCMessageTransferResponse{}, svc.ULRRCMessageTransfer(ctx, req)
+	}
+}
+
+// ULRRCMessageTransfer implements the service interface, so Endpoints may be used as a service.
+// This is primarily useful in the context of a client library.
+func (e Endpoints) ULRRCMessageTransfer(ctx context.Context, m f1.RRCMessage) (err error) {
+	_, err = e.ULRRCMessageTransferEndpoint(ctx, m)
+	return
+}
+
+// MakeUERadioCapabilityEndpoint returns an endpoint that invokes
+// UERadioCapability on the service. Primarily useful in a server.
+func MakeUERadioCapabilityEndpoint(svc service.GnbcuService) (ep endpoint.Endpoint) {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(f1.UERadioCapabilityRequest)
+		container, err := svc.UERadioCapability(ctx, req)
+		return UERadioCapabilityResponse{Container: container}, err
+	}
+}
+
+// UERadioCapability implements the service interface, so Endpoints may be used as a service.
+// This is primarily useful in the context of a client library.
+func (e Endpoints) UERadioCapability(ctx context.Context, req f1.UERadioCapabilityRequest) (container []byte, err error) {
+	resp, err := e.UERadioCapabilityEndpoint(ctx, req)
+	if err != nil {
+		return
+	}
+	return resp.(UERadioCapabilityResponse).Container, nil
+}
+
+// MakeHandoverPreparationEndpoint returns an endpoint that invokes
+// HandoverPreparation on the service. Primarily useful in a server.
+func MakeHandoverPreparationEndpoint(svc service.GnbcuService) (ep endpoint.Endpoint) {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(xn.HandoverRequest)
+		return svc.HandoverPreparation(ctx, req)
+	}
+}
+
+// HandoverPreparation implements the service interface, so Endpoints may be used as a service.
+// This is primarily useful in the context of a client library.
+func (e Endpoints) HandoverPreparation(ctx context.Context, req xn.HandoverRequest) (ack xn.HandoverRequestAcknowledge, err error) {
+	resp, err := e.HandoverPreparationEndpoint(ctx, req)
+	if err != nil {
+		return
+	}
+	return resp.(xn.HandoverRequestAcknowledge), nil
+}
+
+// MakeHandoverCancelEndpoint returns an endpoint that invokes
+// HandoverCancel on the service. Primarily useful in a server.
+func MakeHandoverCancelEndpoint(svc service.GnbcuService) (ep endpoint.Endpoint) {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(xn.HandoverCancel)
+		return XnResponse{}, svc.HandoverCancel(ctx, req)
+	}
+}
+
+// HandoverCancel implements the service interface, so Endpoints may be used as a service.
+// This is primarily useful in the context of a client library.
+func (e Endpoints) HandoverCancel(ctx context.Context, m xn.HandoverCancel) (err error) {
+	_, err = e.HandoverCancelEndpoint(ctx, m)
+	return
+}
+
+// MakeUEContextReleaseEndpoint returns an endpoint that invokes
+// UEContextRelease on the service. Primarily useful in a server.
+func MakeUEContextReleaseEndpoint(svc service.GnbcuService) (ep endpoint.Endpoint) {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(xn.UEContextRelease)
+		return XnResponse{}, svc.UEContextRelease(ctx, req)
+	}
+}
+
+// UEContextRelease implements the service interface, so Endpoints may be used as a service.
+// This is primarily useful in the context of a client library.
+func (e Endpoints) UEContextRelease(ctx context.Context, m xn.UEContextRelease) (err error) {
+	_, err = e.UEContextReleaseEndpoint(ctx, m)
+	return
+}
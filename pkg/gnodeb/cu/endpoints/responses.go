@@ -0,0 +1,23 @@
+package endpoints
+
+// The requests of the F1 and Xn methods are the messages of packages f1 and
+// xn, and so are the responses of F1Setup and HandoverPreparation.
+
+// InitialULRRCMessageTransferResponse collects the response values for the
+// InitialULRRCMessageTransfer method.
+type InitialULRRCMessageTransferResponse struct {
+	CUUEID uint32 `json:"gnb_cu_ue_f1ap_id"`
+}
+
+// UERadioCapabilityResponse collects the response values for the
+// UERadioCapability method.
+type UERadioCapabilityResponse struct {
+	Container []byte `json:"ue_capability_rat_container_list"`
+}
+
+// RRCMessageTransferResponse collects the response values for the RRC
+// message transfers.
+type RRCMessageTransferResponse struct{}
+
+// XnResponse collects the response values for the Xn methods without one.
+type XnResponse struct{}
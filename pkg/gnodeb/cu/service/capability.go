@@ -0,0 +1,211 @@
+package service
+
+import (
+	"bytes"
+	"compress/flate"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/store"
+)
+
+// The defaults of the UE radio capabilities kept: the largest container
+// taken, the size above which it is compressed in the store, and how long
+// it is kept after the last connection of its UE.
+const (
+	DefaultCapabilityMaxSize      = 64 << 10
+	DefaultCapabilityCompressSize = 1 << 10
+	DefaultCapabilityTTL          = 24 * time.Hour
+)
+
+// capabilityPrefix prefixes the keys of the UE radio capabilities, the
+// 5G-S-TMSI of their UE following.
+const capabilityPrefix = "ue-capability/"
+
+// The codecs of the containers in the store, the first byte of every value.
+// The containers are compressed with DEFLATE: zstd would compress them
+// better and faster, but its pure Go implementation requires a newer Go
+// than the module's, and its binding cgo, which the images are built
+// without. The codec byte leaves room for it.
+const (
+	codecNone    byte = 0
+	codecDeflate byte = 1
+)
+
+// Capabilities keeps the UE radio capability containers of the UEs in a
+// store, keyed by their 5G-S-TMSI, so that a UE connecting again, to any
+// gNB-CU sharing the store, is not asked for its capability again (TS
+// 38.300 16.1.2). Capabilities is safe for concurrent use.
+type Capabilities struct {
+	store    store.Store
+	maxSize  int
+	compress int
+	ttl      time.Duration
+	logger   log.Logger
+
+	mtx   sync.Mutex
+	stats CapabilityStats
+}
+
+// CapabilityOption sets an optional parameter of the capabilities.
+type CapabilityOption func(*Capabilities)
+
+// CapabilityMaxSize sets the largest container taken, in bytes.
+func CapabilityMaxSize(n int) CapabilityOption {
+	return func(c *Capabilities) { c.maxSize = n }
+}
+
+// CapabilityCompressSize sets the size, in bytes, above which the
+// containers are compressed in the store. Zero compresses none.
+func CapabilityCompressSize(n int) CapabilityOption {
+	return func(c *Capabilities) { c.compress = n }
+}
+
+// CapabilityTTL sets how long a container is kept after the last connection
+// of its UE.
+func CapabilityTTL(ttl time.Duration) CapabilityOption {
+	return func(c *Capabilities) { c.ttl = ttl }
+}
+
+// CapabilityLogger sets the logger of the failures of the store, which
+// only cost the UEs an enquiry.
+func CapabilityLogger(logger log.Logger) CapabilityOption {
+	return func(c *Capabilities) { c.logger = logger }
+}
+
+// NewCapabilities returns the capabilities kept in s.
+func NewCapabilities(s store.Store, options ...CapabilityOption) *Capabilities {
+	c := &Capabilities{
+		store:    s,
+		maxSize:  DefaultCapabilityMaxSize,
+		compress: DefaultCapabilityCompressSize,
+		ttl:      DefaultCapabilityTTL,
+		logger:   log.NewNopLogger(),
+	}
+	for _, option := range options {
+		option(c)
+	}
+	return c
+}
+
+// check returns an error for a container larger than the largest taken.
+func (c *Capabilities) check(container []byte) error {
+	if len(container) == 0 {
+		return status.Error(codes.InvalidArgument, "f1: empty UE radio capability")
+	}
+	if len(container) > c.maxSize {
+		c.count(func(s *CapabilityStats) { s.Rejected++ })
+		return status.Errorf(codes.InvalidArgument, "f1: UE radio capability of %d bytes, over the %d taken", len(container), c.maxSize)
+	}
+	return nil
+}
+
+// get returns the container of the UE of stmsi, nil when there is none.
+// Reading it again renews it.
+func (c *Capabilities) get(ctx context.Context, stmsi string) []byte {
+	v, err := c.store.Get(ctx, capabilityPrefix+stmsi)
+	if err == store.ErrNotFound {
+		c.count(func(s *CapabilityStats) { s.Misses++ })
+		return nil
+	}
+	if err != nil {
+		level.Warn(c.logger).Log("ng_5g_s_tmsi", stmsi, "err", err)
+		return nil
+	}
+	container, err := c.decode(v)
+	if err != nil {
+		level.Warn(c.logger).Log("ng_5g_s_tmsi", stmsi, "err", err)
+		c.store.Delete(ctx, capabilityPrefix+stmsi)
+		return nil
+	}
+	if err := c.store.Set(ctx, capabilityPrefix+stmsi, v, c.ttl); err != nil {
+		level.Warn(c.logger).Log("ng_5g_s_tmsi", stmsi, "err", err)
+	}
+	c.count(func(s *CapabilityStats) { s.Hits++ })
+	return container
+}
+
+// put keeps the container of the UE of stmsi, compressed when it is larger
+// than the compression size and compression pays.
+func (c *Capabilities) put(ctx context.Context, stmsi string, container []byte) {
+	v := append([]byte{codecNone}, container...)
+	compressed := false
+	if c.compress > 0 && len(container) > c.compress {
+		if d := deflate(container); len(d) < len(container) {
+			v, compressed = append([]byte{codecDeflate}, d...), true
+		}
+	}
+	if err := c.store.Set(ctx, capabilityPrefix+stmsi, v, c.ttl); err != nil {
+		level.Warn(c.logger).Log("ng_5g_s_tmsi", stmsi, "err", err)
+		return
+	}
+	c.count(func(s *CapabilityStats) {
+		s.Stored++
+		s.Bytes += len(container)
+		s.StoredBytes += len(v) - 1
+		if compressed {
+			s.Compressed++
+		}
+	})
+}
+
+func (c *Capabilities) count(f func(*CapabilityStats)) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	f(&c.stats)
+}
+
+// CapabilityStats counts the UE radio capabilities: those stored, compressed
+// or not, and rejected for their size, and the lookups finding one or none.
+// Bytes are the sizes of the containers stored, StoredBytes the sizes they
+// took in the store.
+type CapabilityStats struct {
+	Stored      int `json:"stored"`
+	Compressed  int `json:"compressed"`
+	Rejected    int `json:"rejected"`
+	Hits        int `json:"hits"`
+	Misses      int `json:"misses"`
+	Bytes       int `json:"bytes"`
+	StoredBytes int `json:"stored_bytes"`
+}
+
+// Stats returns the counters of c.
+func (c *Capabilities) Stats() CapabilityStats {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return c.stats
+}
+
+func deflate(b []byte) []byte {
+	var buf bytes.Buffer
+	w, _ := flate.NewWriter(&buf, flate.DefaultCompression)
+	w.Write(b)
+	w.Close()
+	return buf.Bytes()
+}
+
+// decode returns the container of the value v of the store, no larger than
+// the largest taken.
+func (c *Capabilities) decode(v []byte) ([]byte, error) {
+	if len(v) == 0 {
+		return nil, fmt.Errorf("ue capability: empty value")
+	}
+	switch v[0] {
+	case codecNone:
+		return v[1:], nil
+	case codecDeflate:
+		r := flate.NewReader(bytes.NewReader(v[1:]))
+		defer r.Close()
+		return ioutil.ReadAll(io.LimitReader(r, int64(c.maxSize)))
+	}
+	return nil, fmt.Errorf("ue capability: unknown codec %d", v[0])
+}
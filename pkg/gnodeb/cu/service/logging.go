@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/gnodeb/f1"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/gnodeb/xn"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/logging"
+)
+
+type loggingMiddleware struct {
+	logger log.Logger
+	next   GnbcuService
+}
+
+// LoggingMiddleware takes a logger as a dependency
+// and returns a ServiceMiddleware.
+func LoggingMiddleware(logger log.Logger) Middleware {
+	return func(next GnbcuService) GnbcuService {
+		return loggingMiddleware{level.Info(logger), next}
+	}
+}
+
+func (lm loggingMiddleware) F1Setup(ctx context.Context, req f1.SetupRequest) (rs f1.SetupResponse, err error) {
+	defer func(begin time.Time) {
+		log.With(lm.logger, logging.ContextKeyvals(ctx)...).Log("method", "F1Setup", "gnb_du_id", req.DUID, "address", req.Address, "cells", len(req.Cells), "activated", len(rs.Activated), "err", err)
+	}(time.Now())
+
+	return lm.next.F1Setup(ctx, req)
+}
+
+func (lm loggingMiddleware) InitialULRRCMessageTransfer(ctx context.Context, m f1.InitialULRRCMessage) (cuUEID uint32, err error) {
+	defer func(begin time.Time) {
+		log.With(lm.logger, logging.ContextKeyvals(ctx)...).Log("method", "InitialULRRCMessageTransfer", "gnb_du_id", m.DUID, "gnb_du_ue_f1ap_id", m.DUUEID, "gnb_cu_ue_f1ap_id", cuUEID, "nci", m.NCI, "err", err)
+	}(time.Now())
+
+	return lm.next.InitialULRRCMessageTransfer(ctx, m)
+}
+
+func (lm loggingMiddleware) UERadioCapability(ctx context.Context, req f1.UERadioCapabilityRequest) (container []byte, err error) {
+	defer func(begin time.Time) {
+		log.With(lm.logger, logging.ContextKeyvals(ctx)...).Log("method", "UERadioCapability", "gnb_cu_ue_f1ap_id", req.CUUEID, "gnb_du_ue_f1ap_id", req.DUUEID, "bytes", len(container), "err", err)
+	}(time.Now())
+
+	return lm.next.UERadioCapability(ctx, req)
+}
+
+func (lm loggingMiddleware) ULRRCMessageTransfer(ctx context.Context, m f1.RRCMessage) (err error) {
+	defer func(begin time.Time) {
+		log.With(lm.logger, logging.ContextKeyvals(ctx)...).Log("method", "ULRRCMessageTransfer", "gnb_cu_ue_f1ap_id", m.CUUEID, "gnb_du_ue_f1ap_id", m.DUUEID, "rrc", string(m.RRC), "err", err)
+	}(time.Now())
+
+	return lm.next.ULRRCMessageTransfer(ctx, m)
+}
+
+func (lm loggingMiddleware) HandoverPreparation(ctx context.Context, req xn.HandoverRequest) (ack xn.HandoverRequestAcknowledge, err error) {
+	defer func(begin time.Time) {
+		log.With(lm.logger, logging.ContextKeyvals(ctx)...).Log("method", "HandoverPreparation", "source_gnb_id", req.Source, "source_ng_ran_node_ue_xnap_id", req.SourceUEID, "target_nci", req.TargetNCI, "target_ng_ran_node_ue_xnap_id", ack.TargetUEID, "err", err)
+	}(time.Now())
+
+	return lm.next.HandoverPreparation(ctx, req)
+}
+
+func (lm loggingMiddleware) HandoverCancel(ctx context.Context, m xn.HandoverCancel) (err error) {
+	defer func(begin time.Time) {
+		log.With(lm.logger, logging.ContextKeyvals(ctx)...).Log("method", "HandoverCancel", "source_ng_ran_node_ue_xnap_id", m.SourceUEID, "target_ng_ran_node_ue_xnap_id", m.TargetUEID, "err", err)
+	}(time.Now())
+
+	return lm.next.HandoverCancel(ctx, m)
+}
+
+func (lm loggingMiddleware) UEContextRelease(ctx context.Context, m xn.UEContextRelease) (err error) {
+	defer func(begin time.Time) {
+		log.With(lm.logger, logging.ContextKeyvals(ctx)...).Log("method", "UEContextRelease", "source_ng_ran_node_ue_xnap_id", m.SourceUEID, "target_ng_ran_node_ue_xnap_id", m.TargetUEID, "err", err)
+	}(time.Now())
+
+	return lm.next.UEContextRelease(ctx, m)
+}
@@ -0,0 +1,1059 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/fsm"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/gnodeb/f1"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/gnodeb/xn"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/identity"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/store"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/timers"
+)
+
+// Middleware describes a service (as opposed to endpoint) middleware.
+type Middleware func(GnbcuService) GnbcuService
+
+// GnbcuService describes the gNB-CU: it serves the F1 to the gNB-DUs and
+// terminates the RRC of their UEs, and serves the Xn to the gNB-CUs of the
+// neighbour gNBs.
+type GnbcuService interface {
+	f1.CU
+	xn.GNB
+}
+
+// Dialer connects to the end of the F1 a gNB-DU serves at address. The
+// closer is closed when the gNB-DU sets up the F1 again.
+type Dialer func(address string) (f1.DU, io.Closer, error)
+
+// The RRC states of a UE as the gNB-CU sees them, from the RRC Setup
+// Request to the first RRC Reconfiguration (TS 38.401 8.1), through the
+// enquiry of its radio capability (TS 38.300 16.1.2) and the handovers
+// between the cells of its gNB-DUs (TS 38.401 8.2.1), back to IDLE when the
+// connection is released for inactivity (TS 38.331 5.3.8). A UE handed over
+// from or to a neighbour gNB over the Xn (TS 38.423 8.2) is in HANDOVER from
+// the Handover Request to the release of its context in the source, which
+// leaves it IDLE there.
+const (
+	stateIdle          fsm.State = "IDLE"
+	stateSetup         fsm.State = "SETUP"
+	stateCapability    fsm.State = "CAPABILITY_ENQUIRY"
+	stateReconfiguring fsm.State = "RECONFIGURING"
+	stateConnected     fsm.State = "CONNECTED"
+	stateHandover      fsm.State = "HANDOVER"
+)
+
+const (
+	eventSetupRequest            fsm.Event = "setupRequest"
+	eventSetupComplete           fsm.Event = "setupComplete"
+	eventCapabilityInformation   fsm.Event = "capabilityInformation"
+	eventReconfigurationComplete fsm.Event = "reconfigurationComplete"
+	eventMeasurementReport       fsm.Event = "measurementReport"
+	eventInactivity              fsm.Event = "inactivity"
+	eventHandoverRequest         fsm.Event = "handoverRequest"
+	eventHandoverCancel          fsm.Event = "handoverCancel"
+	eventContextRelease          fsm.Event = "contextRelease"
+)
+
+// connection is the RRC connection of a UE. The actions get the *procedure
+// of the RRC message. A UE giving its 5G-S-TMSI is asked for its radio
+// capability unless the gNB-CU keeps it already; the others are not asked
+// at all. A measurement report that finds no better cell, or
+// that comes during a handover, is taken without a change, unless the target
+// gNB-DU of the handover set up the F1 again meanwhile. A UE without
+// activity is released, unless it is being handed over within the gNB; a
+// handover over the Xn is given up instead.
+var connection = fsm.MustNew(fsm.Definition{
+	Name:    "rrc",
+	Initial: stateIdle,
+	Transitions: []fsm.Transition{
+		{From: []fsm.State{stateIdle}, Event: eventSetupRequest, To: stateSetup, Action: sendRRCSetup},
+		{From: []fsm.State{stateSetup}, Event: eventSetupComplete, To: stateCapability, Guard: unknownCapability, Action: enquireCapability},
+		{From: []fsm.State{stateSetup}, Event: eventSetupComplete, To: stateReconfiguring, Action: setupUEContext},
+		{From: []fsm.State{stateCapability}, Event: eventCapabilityInformation, To: stateReconfiguring, Action: keepCapability},
+		{From: []fsm.State{stateReconfiguring}, Event: eventReconfigurationComplete, To: stateConnected},
+		{From: []fsm.State{stateConnected}, Event: eventMeasurementReport, To: stateHandover, Guard: betterCell, Action: prepareHandover},
+		{From: []fsm.State{stateHandover}, Event: eventMeasurementReport, To: stateHandover, Guard: handingOver},
+		{From: []fsm.State{stateConnected, stateHandover}, Event: eventMeasurementReport, To: stateConnected},
+		{From: []fsm.State{stateHandover}, Event: eventReconfigurationComplete, To: stateConnected, Action: completeHandover},
+		{From: []fsm.State{stateSetup, stateCapability, stateReconfiguring, stateConnected}, Event: eventInactivity, To: stateIdle, Action: releaseConnection},
+		{From: []fsm.State{stateIdle}, Event: eventHandoverRequest, To: stateHandover, Action: admitUE},
+		{From: []fsm.State{stateHandover}, Event: eventHandoverCancel, To: stateIdle, Action: dropUE},
+		{From: []fsm.State{stateHandover}, Event: eventContextRelease, To: stateIdle, Action: releaseSource},
+		{From: []fsm.State{stateHandover}, Event: eventInactivity, To: stateIdle, Guard: overXn, Action: giveUpHandover},
+	},
+})
+
+// events are the events fired by the UL RRC messages.
+var events = map[string]fsm.Event{
+	f1.RRCSetupRequest:            eventSetupRequest,
+	f1.RRCSetupComplete:           eventSetupComplete,
+	f1.UECapabilityInformation:    eventCapabilityInformation,
+	f1.RRCReconfigurationComplete: eventReconfigurationComplete,
+	f1.MeasurementReport:          eventMeasurementReport,
+}
+
+// defaultDRB is the DRB set up for every UE.
+const defaultDRB uint32 = 1
+
+// a3Offset is how much stronger, in dB, a neighbour cell must be heard than
+// the serving cell for the UE to be handed over to it, as for the event A3
+// of TS 38.331 5.5.4.4.
+const a3Offset = 3
+
+// releaseTimeout bounds the release of a UE without activity.
+const releaseTimeout = 5 * time.Second
+
+// the concrete implementation of service interface
+type stubGnbcuService struct {
+	name   string
+	gnbID  identity.GNBID
+	reg    *Registry
+	caps   *Capabilities
+	dial   Dialer
+	logger log.Logger
+	// inactivity is how long a UE may go without an RRC message before
+	// its connection is released, timers the wheel timing it.
+	inactivity time.Duration
+	timers     *timers.Wheel
+	// neighbours are the gNBs the UEs are handed over to over the Xn.
+	neighbours []neighbour
+}
+
+// neighbour is a neighbour gNB and its end of the Xn.
+type neighbour struct {
+	id  identity.GNBID
+	gnb xn.GNB
+}
+
+// Option sets an optional parameter of the gNB-CU.
+type Option func(*stubGnbcuService)
+
+// GNBID sets the gNB ID of the gNB-CU, whose cells the gNB-DUs must serve.
+// Without one, the gNB-DUs serve any cell.
+func GNBID(id identity.GNBID) Option {
+	return func(cu *stubGnbcuService) { cu.gnbID = id }
+}
+
+// UECapabilities sets where the gNB-CU keeps the radio capabilities of the
+// UEs across their connections. Without it, they are kept in memory with
+// the defaults.
+func UECapabilities(c *Capabilities) Option {
+	return func(cu *stubGnbcuService) { cu.caps = c }
+}
+
+// Inactivity releases the RRC connection of a UE that sends no RRC message
+// for d, as the gNB does when the UE has no traffic either, bringing it to
+// RRC idle. Without it, or with d zero, the connections stay until their
+// gNB-DU sets up the F1 again.
+func Inactivity(d time.Duration) Option {
+	return func(cu *stubGnbcuService) { cu.inactivity = d }
+}
+
+// Neighbour hands the UEs over to the neighbour gNB id, through its end of
+// the Xn, for the cells of id no gNB-DU serves, and admits its UEs.
+func Neighbour(id identity.GNBID, gnb xn.GNB) Option {
+	return func(cu *stubGnbcuService) { cu.neighbours = append(cu.neighbours, neighbour{id: id, gnb: gnb}) }
+}
+
+// New return a new instance of the service.
+// If you want to add service middleware this is the place to put them.
+// The gNB-CU is called name, keeps its gNB-DUs and UE contexts in reg and
+// connects to the gNB-DUs with dial.
+func New(name string, reg *Registry, dial Dialer, logger log.Logger, options ...Option) (s GnbcuService) {
+	var svc GnbcuService
+	{
+		cu := &stubGnbcuService{name: name, reg: reg, dial: dial, logger: logger}
+		for _, option := range options {
+			option(cu)
+		}
+		if cu.caps == nil {
+			cu.caps = NewCapabilities(store.NewMemory())
+		}
+		if cu.inactivity > 0 {
+			cu.timers = timers.NewWheel(timers.Logger(logger))
+		}
+		svc = cu
+		svc = LoggingMiddleware(logger)(svc)
+	}
+	return svc
+}
+
+// Implement the business logic of F1Setup. The gNB-CU connects to the
+// gNB-DU and activates its enabled cells, which must be cells of its gNB ID
+// if it has one. A gNB-DU setting up the F1 again loses its UE contexts.
+func (cu *stubGnbcuService) F1Setup(ctx context.Context, req f1.SetupRequest) (rs f1.SetupResponse, err error) {
+	if req.DUID == 0 {
+		return rs, status.Error(codes.InvalidArgument, "f1: missing gNB-DU ID")
+	}
+	if req.Address == "" {
+		return rs, status.Error(codes.InvalidArgument, "f1: missing gNB-DU address")
+	}
+	d := &du{id: req.DUID, name: req.DUName, address: req.Address, active: make(map[uint64]bool)}
+	for _, c := range req.Cells {
+		if err := c.Validate(); err != nil {
+			return rs, err
+		}
+		if !cu.gnbID.IsZero() && !cu.gnbID.Serves(c.NCI) {
+			return rs, status.Errorf(codes.InvalidArgument, "f1: cell %d is not a cell of gNB %s", c.NCI, cu.gnbID)
+		}
+		if c.Enabled {
+			d.active[c.NCI] = true
+			rs.Activated = append(rs.Activated, c.NCI)
+		}
+	}
+	d.client, d.closer, err = cu.dial(req.Address)
+	if err != nil {
+		return rs, status.Errorf(codes.Unavailable, "f1: connecting to gNB-DU %d: %v", req.DUID, err)
+	}
+	if old := cu.reg.setup(d); old != nil {
+		if err := old.closer.Close(); err != nil {
+			level.Warn(cu.logger).Log("gnb_du_id", old.id, "err", err)
+		}
+	}
+	rs.CUName = cu.name
+	return rs, nil
+}
+
+// Implement the business logic of InitialULRRCMessageTransfer. The gNB-CU
+// creates the context of the UE and answers its RRC Setup Request with an
+// RRC Setup.
+func (cu *stubGnbcuService) InitialULRRCMessageTransfer(ctx context.Context, m f1.InitialULRRCMessage) (cuUEID uint32, err error) {
+	if string(m.RRC) != f1.RRCSetupRequest {
+		return 0, status.Errorf(codes.InvalidArgument, "f1: unexpected initial RRC message %q", m.RRC)
+	}
+	u, err := cu.reg.newUE(m)
+	if err != nil {
+		return 0, err
+	}
+	if err := u.rrc.Fire(ctx, eventSetupRequest, cu.procedure(u)); err != nil {
+		cu.reg.remove(u)
+		return 0, err
+	}
+	cu.watch(u)
+	return u.id, nil
+}
+
+// Implement the business logic of ULRRCMessageTransfer. The RRC messages
+// move the UE through the connection setup and the enquiry of its radio
+// capability, and its measurement reports through the handovers. During a
+// handover, the UE is reached through the target gNB-DU as well. Any RRC
+// message restarts the inactivity timer of the UE.
+func (cu *stubGnbcuService) ULRRCMessageTransfer(ctx context.Context, m f1.RRCMessage) (err error) {
+	u, err := cu.reg.ue(m.CUUEID, m.DUUEID)
+	if err != nil {
+		return err
+	}
+	if cu.timers != nil {
+		cu.timers.Context(ueKey(u.id)).Restart(timers.UEInactivity)
+	}
+	name, ies := f1.ParseRRC(m.RRC)
+	event, ok := events[name]
+	if !ok || m.SRB != f1.SRB1 {
+		return status.Errorf(codes.InvalidArgument, "f1: unexpected RRC message %q on SRB%d", name, m.SRB)
+	}
+	p := cu.procedure(u)
+	switch event {
+	case eventMeasurementReport:
+		err = json.Unmarshal(ies, &p.meas)
+	case eventSetupComplete:
+		if ies != nil {
+			err = json.Unmarshal(ies, &p.setup)
+		}
+		if err == nil && p.setup.STMSI != "" && !validSTMSI(p.setup.STMSI) {
+			err = fmt.Errorf("invalid 5G-S-TMSI %q", p.setup.STMSI)
+		}
+	case eventCapabilityInformation:
+		var ci f1.UECapabilityInformationIEs
+		if err = json.Unmarshal(ies, &ci); err == nil {
+			if err := cu.caps.check(ci.Container); err != nil {
+				return err
+			}
+			p.capability = ci.Container
+		}
+	}
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "f1: malformed %s: %v", name, err)
+	}
+	err = u.rrc.Fire(ctx, event, p)
+	switch err.(type) {
+	case *fsm.InvalidTransitionError, *fsm.GuardError:
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return err
+}
+
+// Implement the business logic of UERadioCapability. The radio capability
+// is that of the UE context, known once the UE has been asked for it or
+// found in the store.
+func (cu *stubGnbcuService) UERadioCapability(ctx context.Context, req f1.UERadioCapabilityRequest) (container []byte, err error) {
+	u, err := cu.reg.ue(req.CUUEID, req.DUUEID)
+	if err != nil {
+		return nil, err
+	}
+	if container = cu.reg.capability(u); container == nil {
+		return nil, f1.ErrNoCapability
+	}
+	return container, nil
+}
+
+// Implement the business logic of HandoverPreparation. The gNB-CU admits
+// the UE of a neighbour in the gNB-DU of the target cell, as for a handover
+// between its own gNB-DUs, and returns the RRC Reconfiguration the source
+// sends the UE.
+func (cu *stubGnbcuService) HandoverPreparation(ctx context.Context, req xn.HandoverRequest) (ack xn.HandoverRequestAcknowledge, err error) {
+	source := cu.neighbour(req.Source)
+	if source == nil {
+		return ack, status.Errorf(codes.FailedPrecondition, "xn: gNB %s is not a neighbour", req.Source)
+	}
+	d := cu.reg.du(req.TargetNCI)
+	if d == nil {
+		return ack, xn.ErrUnknownCell
+	}
+	u := cu.reg.newIncomingUE(d, req, source)
+	p := cu.procedure(u)
+	if err := u.rrc.Fire(ctx, eventHandoverRequest, p); err != nil {
+		cu.reg.remove(u)
+		return ack, err
+	}
+	cu.watch(u)
+	return p.ack, nil
+}
+
+// Implement the business logic of HandoverCancel. The UE admitted is
+// dropped from the gNB-DU of the target cell.
+func (cu *stubGnbcuService) HandoverCancel(ctx context.Context, m xn.HandoverCancel) (err error) {
+	u := cu.reg.holder(m.TargetUEID)
+	if u == nil {
+		return xn.ErrUnknownUE
+	}
+	if source := cu.reg.source(u); source == nil || source.ueID != m.SourceUEID {
+		return xn.ErrUnknownUE
+	}
+	return cu.fireXn(ctx, u, eventHandoverCancel)
+}
+
+// Implement the business logic of UEContextRelease. The UE that reached
+// the target gNB is released from the source gNB-DU and forgotten.
+func (cu *stubGnbcuService) UEContextRelease(ctx context.Context, m xn.UEContextRelease) (err error) {
+	u := cu.reg.holder(m.SourceUEID)
+	if u == nil {
+		return xn.ErrUnknownUE
+	}
+	if h := cu.reg.target(u); h == nil || h.gnb == nil || h.ueID != m.TargetUEID {
+		return xn.ErrUnknownUE
+	}
+	return cu.fireXn(ctx, u, eventContextRelease)
+}
+
+// fireXn fires the event of an Xn message on u, and stops its timers once
+// it is forgotten.
+func (cu *stubGnbcuService) fireXn(ctx context.Context, u *ue, event fsm.Event) error {
+	err := u.rrc.Fire(ctx, event, cu.procedure(u))
+	switch err.(type) {
+	case nil:
+		if cu.timers != nil {
+			cu.timers.Context(ueKey(u.id)).StopAll()
+		}
+	case *fsm.InvalidTransitionError, *fsm.GuardError:
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return err
+}
+
+// neighbour returns the end of the Xn of the neighbour gNB id, nil when id
+// is not a neighbour.
+func (cu *stubGnbcuService) neighbour(id identity.GNBID) xn.GNB {
+	for _, n := range cu.neighbours {
+		if n.id == id {
+			return n.gnb
+		}
+	}
+	return nil
+}
+
+// procedure returns the procedure of an RRC or Xn message of u.
+func (cu *stubGnbcuService) procedure(u *ue) *procedure {
+	return &procedure{reg: cu.reg, caps: cu.caps, u: u, gnbID: cu.gnbID, neighbours: cu.neighbours}
+}
+
+// watch starts the inactivity timer of u.
+func (cu *stubGnbcuService) watch(u *ue) {
+	if cu.timers == nil {
+		return
+	}
+	cu.timers.Context(ueKey(u.id)).Start(timers.UEInactivity, cu.inactivity, func(timers.Expiry) { cu.inactive(u) })
+}
+
+// inactive releases the RRC connection of u once its inactivity timer
+// expired. The timer starts over for a UE being handed over within the gNB;
+// a UE whose release fails is released in the gNB-CU all the same.
+func (cu *stubGnbcuService) inactive(u *ue) {
+	t := cu.timers.Context(ueKey(u.id))
+	switch cu.reg.holder(u.id) {
+	case u:
+	case nil:
+		t.StopAll()
+		return
+	default:
+		// the F1AP ID was given to another UE, whose timer this is now
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), releaseTimeout)
+	defer cancel()
+	err := u.rrc.Fire(ctx, eventInactivity, cu.procedure(u))
+	if _, ok := err.(*fsm.InvalidTransitionError); ok {
+		t.Restart(timers.UEInactivity)
+		return
+	}
+	if err != nil {
+		level.Warn(cu.logger).Log("gnb_cu_ue_f1ap_id", u.id, "event", eventInactivity, "err", err)
+		cu.reg.release(u)
+	}
+	t.StopAll()
+}
+
+func ueKey(cuUEID uint32) string {
+	return strconv.FormatUint(uint64(cuUEID), 10)
+}
+
+// procedure is what the transitions of a UE work on.
+type procedure struct {
+	reg  *Registry
+	caps *Capabilities
+	u    *ue
+	// setup are the IEs of the RRC Setup Complete, capability the
+	// container of a UE capability information.
+	setup      f1.SetupCompleteIEs
+	capability []byte
+	// meas are the results of a measurement report.
+	meas f1.MeasResults
+	// target is the cell a measurement report found better.
+	target *handover
+	// gnbID and neighbours are those of the gNB-CU, for the handovers over
+	// the Xn, and ack the acknowledge of a Handover Request.
+	gnbID      identity.GNBID
+	neighbours []neighbour
+	ack        xn.HandoverRequestAcknowledge
+}
+
+func sendRRCSetup(ctx context.Context, arg interface{}) error {
+	u := arg.(*procedure).u
+	return u.du.client.DLRRCMessageTransfer(ctx, f1.RRCMessage{
+		CUUEID: u.id,
+		DUUEID: u.duUEID,
+		SRB:    f1.SRB0,
+		RRC:    []byte(f1.RRCSetup),
+	})
+}
+
+func setupUEContext(ctx context.Context, arg interface{}) error {
+	u := arg.(*procedure).u
+	_, err := u.du.client.UEContextSetup(ctx, f1.UEContextSetupRequest{
+		CUUEID: u.id,
+		DUUEID: u.duUEID,
+		NCI:    u.nci,
+		DRBs:   []uint32{defaultDRB},
+		RRC:    []byte(f1.RRCReconfiguration),
+	})
+	return err
+}
+
+// unknownCapability asks for the radio capability of a UE giving its
+// 5G-S-TMSI when the store has none, and otherwise takes it in the UE
+// context.
+func unknownCapability(ctx context.Context, arg interface{}) bool {
+	p := arg.(*procedure)
+	if p.setup.STMSI == "" {
+		return false
+	}
+	p.reg.identify(p.u, p.setup.STMSI)
+	container := p.caps.get(ctx, p.setup.STMSI)
+	if container == nil {
+		return true
+	}
+	p.reg.keep(p.u, container)
+	return false
+}
+
+func enquireCapability(ctx context.Context, arg interface{}) error {
+	u := arg.(*procedure).u
+	return u.du.client.DLRRCMessageTransfer(ctx, f1.RRCMessage{
+		CUUEID: u.id,
+		DUUEID: u.duUEID,
+		SRB:    f1.SRB1,
+		RRC:    []byte(f1.UECapabilityEnquiry),
+	})
+}
+
+// keepCapability keeps the radio capability of the UE in its context and in
+// the store, then sets up the context of the UE in the gNB-DU.
+func keepCapability(ctx context.Context, arg interface{}) error {
+	p := arg.(*procedure)
+	if err := setupUEContext(ctx, arg); err != nil {
+		return err
+	}
+	p.reg.keep(p.u, p.capability)
+	p.caps.put(ctx, p.reg.stmsi(p.u), p.capability)
+	return nil
+}
+
+// validSTMSI tells a 5G-S-TMSI of 48 bits in hexadecimal.
+func validSTMSI(s string) bool {
+	if len(s) != 12 {
+		return false
+	}
+	_, err := strconv.ParseUint(s, 16, 64)
+	return err == nil
+}
+
+// betterCell allows a handover when the best neighbour cell of a
+// measurement report is heard a3Offset stronger than the serving cell, and
+// is active in a gNB-DU or a cell of a neighbour gNB.
+func betterCell(ctx context.Context, arg interface{}) bool {
+	p := arg.(*procedure)
+	if p.meas.Serving.NCI != p.u.nci {
+		return false
+	}
+	var best *f1.CellMeas
+	for i, n := range p.meas.Neighbours {
+		if n.NCI != p.u.nci && (best == nil || n.RSRP > best.RSRP) {
+			best = &p.meas.Neighbours[i]
+		}
+	}
+	if best == nil || best.RSRP < p.meas.Serving.RSRP+a3Offset {
+		return false
+	}
+	if d := p.reg.du(best.NCI); d != nil {
+		p.target = &handover{du: d, nci: best.NCI}
+		return true
+	}
+	for _, n := range p.neighbours {
+		if n.id.Serves(best.NCI) {
+			p.target = &handover{gnb: n.gnb, nci: best.NCI}
+			return true
+		}
+	}
+	return false
+}
+
+// handingOver tells the measurement reports of a UE being handed over, to
+// or from the gNB.
+func handingOver(ctx context.Context, arg interface{}) bool {
+	p := arg.(*procedure)
+	return p.reg.target(p.u) != nil || p.reg.source(p.u) != nil
+}
+
+// overXn tells a UE being handed over to or from a neighbour gNB.
+func overXn(ctx context.Context, arg interface{}) bool {
+	p := arg.(*procedure)
+	h := p.reg.target(p.u)
+	return h != nil && h.gnb != nil || p.reg.source(p.u) != nil
+}
+
+// prepareHandover sets up the context of the UE in the target gNB-DU and
+// sends the UE the RRC Reconfiguration with sync through the source gNB-DU
+// (TS 38.401 8.2.1.1). The target of a neighbour gNB sets it up itself.
+func prepareHandover(ctx context.Context, arg interface{}) error {
+	p := arg.(*procedure)
+	u, h := p.u, p.target
+	if h.gnb != nil {
+		return prepareXnHandover(ctx, p)
+	}
+	rs, err := h.du.client.UEContextSetup(ctx, f1.UEContextSetupRequest{
+		CUUEID: u.id,
+		NCI:    h.nci,
+		DRBs:   []uint32{defaultDRB},
+	})
+	if err != nil {
+		return err
+	}
+	h.duUEID, h.crnti = rs.DUUEID, rs.CRNTI
+	err = u.du.client.DLRRCMessageTransfer(ctx, f1.RRCMessage{
+		CUUEID: u.id,
+		DUUEID: u.duUEID,
+		SRB:    f1.SRB1,
+		RRC:    f1.RRC(f1.RRCReconfiguration, f1.ReconfigurationWithSync{NCI: h.nci, CRNTI: h.crnti}),
+	})
+	if err != nil {
+		h.du.client.UEContextRelease(ctx, f1.UEContextRelease{CUUEID: u.id, DUUEID: h.duUEID})
+		return err
+	}
+	p.reg.prepare(u, h)
+	return nil
+}
+
+// prepareXnHandover asks the neighbour gNB of the target cell to admit the
+// UE, and sends the UE the RRC Reconfiguration of the target through the
+// source gNB-DU. The handover is cancelled when the UE cannot be sent it.
+func prepareXnHandover(ctx context.Context, p *procedure) error {
+	u, h := p.u, p.target
+	ack, err := h.gnb.HandoverPreparation(ctx, xn.HandoverRequest{
+		Source:     p.gnbID,
+		SourceUEID: u.id,
+		TargetNCI:  h.nci,
+		STMSI:      p.reg.stmsi(u),
+		Capability: p.reg.capability(u),
+		DRBs:       []uint32{defaultDRB},
+	})
+	if err != nil {
+		return err
+	}
+	h.ueID = ack.TargetUEID
+	err = u.du.client.DLRRCMessageTransfer(ctx, f1.RRCMessage{
+		CUUEID: u.id,
+		DUUEID: u.duUEID,
+		SRB:    f1.SRB1,
+		RRC:    ack.RRC,
+	})
+	if err != nil {
+		h.gnb.HandoverCancel(ctx, xn.HandoverCancel{SourceUEID: u.id, TargetUEID: h.ueID})
+		return err
+	}
+	p.reg.prepare(u, h)
+	return nil
+}
+
+// admitUE sets up the context of the UE of a neighbour gNB in the gNB-DU of
+// the target cell, and builds the RRC Reconfiguration with sync the source
+// sends the UE (TS 38.423 8.2.1).
+func admitUE(ctx context.Context, arg interface{}) error {
+	p := arg.(*procedure)
+	u := p.u
+	rs, err := u.du.client.UEContextSetup(ctx, f1.UEContextSetupRequest{
+		CUUEID: u.id,
+		NCI:    u.nci,
+		DRBs:   []uint32{defaultDRB},
+	})
+	if err != nil {
+		return err
+	}
+	p.reg.admit(u, rs.DUUEID, rs.CRNTI)
+	p.ack = xn.HandoverRequestAcknowledge{
+		TargetUEID: u.id,
+		DRBs:       rs.DRBs,
+		RRC:        f1.RRC(f1.RRCReconfiguration, f1.ReconfigurationWithSync{NCI: u.nci, CRNTI: rs.CRNTI}),
+	}
+	return nil
+}
+
+// dropUE releases the context of a UE admitted from a neighbour gNB in the
+// gNB-DU of the target cell, the source having cancelled the handover.
+func dropUE(ctx context.Context, arg interface{}) error {
+	p := arg.(*procedure)
+	u := p.u
+	if err := u.du.client.UEContextRelease(ctx, f1.UEContextRelease{CUUEID: u.id, DUUEID: u.duUEID}); err != nil {
+		return err
+	}
+	p.reg.remove(u)
+	return nil
+}
+
+// releaseSource releases the context of the UE in the source gNB-DU once
+// the UE reached the cell of the neighbour gNB, and forgets the UE.
+func releaseSource(ctx context.Context, arg interface{}) error {
+	p := arg.(*procedure)
+	u := p.u
+	if err := u.du.client.UEContextRelease(ctx, f1.UEContextRelease{CUUEID: u.id, DUUEID: u.duUEID}); err != nil {
+		return err
+	}
+	p.reg.handedOver(u)
+	return nil
+}
+
+// giveUpHandover forgets a UE handed over to or from a neighbour gNB that
+// did not complete, releasing it from its gNB-DU. The source cancels the
+// handover with the target first.
+func giveUpHandover(ctx context.Context, arg interface{}) error {
+	p := arg.(*procedure)
+	u := p.u
+	if h := p.reg.target(u); h != nil && h.gnb != nil {
+		if err := h.gnb.HandoverCancel(ctx, xn.HandoverCancel{SourceUEID: u.id, TargetUEID: h.ueID}); err != nil {
+			return err
+		}
+	}
+	if err := u.du.client.UEContextRelease(ctx, f1.UEContextRelease{CUUEID: u.id, DUUEID: u.duUEID}); err != nil {
+		return err
+	}
+	p.reg.release(u)
+	return nil
+}
+
+// completeHandover releases the context of the UE in the source gNB-DU once
+// the UE reached the target cell, and makes the target the serving cell. A
+// UE from a neighbour gNB is released by the source gNB-CU instead.
+func completeHandover(ctx context.Context, arg interface{}) error {
+	p := arg.(*procedure)
+	u := p.u
+	if source := p.reg.source(u); source != nil {
+		if err := source.gnb.UEContextRelease(ctx, xn.UEContextRelease{SourceUEID: source.ueID, TargetUEID: u.id}); err != nil {
+			return err
+		}
+		p.reg.arrived(u)
+		return nil
+	}
+	if p.reg.target(u) == nil {
+		return f1.ErrUnknownUE
+	}
+	if err := u.du.client.UEContextRelease(ctx, f1.UEContextRelease{CUUEID: u.id, DUUEID: u.duUEID}); err != nil {
+		return err
+	}
+	p.reg.complete(u)
+	return nil
+}
+
+// releaseConnection sends the RRC Release to a UE without activity, then
+// releases its context in the gNB-DU and in the gNB-CU (TS 38.473 8.3.3). The
+// UE Context Release Command carries no RRC container here, the RRC Release
+// goes ahead of it.
+func releaseConnection(ctx context.Context, arg interface{}) error {
+	p := arg.(*procedure)
+	u := p.u
+	err := u.du.client.DLRRCMessageTransfer(ctx, f1.RRCMessage{
+		CUUEID: u.id,
+		DUUEID: u.duUEID,
+		SRB:    f1.SRB1,
+		RRC:    []byte(f1.RRCRelease),
+	})
+	if err != nil {
+		return err
+	}
+	if err := u.du.client.UEContextRelease(ctx, f1.UEContextRelease{CUUEID: u.id, DUUEID: u.duUEID}); err != nil {
+		return err
+	}
+	p.reg.release(u)
+	return nil
+}
+
+// du is a gNB-DU that set up the F1.
+type du struct {
+	id      uint64
+	name    string
+	address string
+	active  map[uint64]bool
+	client  f1.DU
+	closer  io.Closer
+}
+
+// ue is the context of a UE in the gNB-CU. Its gNB-DU fields change, under
+// the lock of the registry, with the handovers.
+type ue struct {
+	id     uint32
+	du     *du
+	duUEID uint32
+	nci    uint64
+	crnti  uint32
+	rrc    *fsm.Instance
+	// target is the cell the UE is being handed over to, source the
+	// neighbour gNB it is being handed over from.
+	target *handover
+	source *xnSource
+	// stmsi is the 5G-S-TMSI the UE gave, capability its radio capability
+	// container, once known.
+	stmsi      string
+	capability []byte
+}
+
+// handover is the target of the handover of a UE: a cell of a gNB-DU, or
+// of the neighbour gNB gnb, which gave the UE the XnAP ID ueID.
+type handover struct {
+	du     *du
+	nci    uint64
+	duUEID uint32
+	crnti  uint32
+	gnb    xn.GNB
+	ueID   uint32
+}
+
+// xnSource is the neighbour gNB a UE is being handed over from, which gave
+// the UE the XnAP ID ueID.
+type xnSource struct {
+	gnb  xn.GNB
+	ueID uint32
+}
+
+// Registry keeps the gNB-DUs that set up the F1 and the UE contexts.
+type Registry struct {
+	mtx       sync.Mutex
+	dus       map[uint64]*du
+	ues       map[uint32]*ue
+	next      uint32
+	handovers int
+	releases  int
+	// xnIn and xnOut count the handovers from and to the neighbour gNBs.
+	xnIn, xnOut int
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{dus: make(map[uint64]*du), ues: make(map[uint32]*ue)}
+}
+
+// setup adds d, dropping the gNB-DU of the same ID and its UEs, which it
+// returns.
+func (r *Registry) setup(d *du) (old *du) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	old = r.dus[d.id]
+	if old != nil {
+		for id, u := range r.ues {
+			if u.du == old {
+				delete(r.ues, id)
+			} else if u.target != nil && u.target.du == old {
+				u.target = nil
+			}
+		}
+	}
+	r.dus[d.id] = d
+	return old
+}
+
+// newUE adds the context of the UE of m, with a free gNB-CU UE F1AP ID.
+func (r *Registry) newUE(m f1.InitialULRRCMessage) (*ue, error) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	d, ok := r.dus[m.DUID]
+	if !ok {
+		return nil, f1.ErrUnknownDU
+	}
+	if !d.active[m.NCI] {
+		return nil, f1.ErrCellNotActive
+	}
+	u := &ue{id: r.nextID(), du: d, duUEID: m.DUUEID, nci: m.NCI, crnti: m.CRNTI, rrc: connection.Instance()}
+	r.ues[u.id] = u
+	return u, nil
+}
+
+// newIncomingUE adds the context of the UE of the Handover Request req from
+// the neighbour gNB source, on the cell of the gNB-DU d, with a free gNB-CU
+// UE F1AP ID, its XnAP ID as well.
+func (r *Registry) newIncomingUE(d *du, req xn.HandoverRequest, source xn.GNB) *ue {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	u := &ue{
+		id:         r.nextID(),
+		du:         d,
+		nci:        req.TargetNCI,
+		rrc:        connection.Instance(),
+		source:     &xnSource{gnb: source, ueID: req.SourceUEID},
+		stmsi:      req.STMSI,
+		capability: req.Capability,
+	}
+	r.ues[u.id] = u
+	return u
+}
+
+// nextID returns a free gNB-CU UE F1AP ID.
+func (r *Registry) nextID() uint32 {
+	for {
+		r.next++
+		if _, used := r.ues[r.next]; r.next != 0 && !used {
+			return r.next
+		}
+	}
+}
+
+// ue returns the UE context of the F1AP IDs, in the serving or the target
+// gNB-DU.
+func (r *Registry) ue(cuUEID, duUEID uint32) (*ue, error) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	u, ok := r.ues[cuUEID]
+	if !ok || u.duUEID != duUEID && (u.target == nil || u.target.duUEID != duUEID) {
+		return nil, f1.ErrUnknownUE
+	}
+	return u, nil
+}
+
+// du returns the gNB-DU the cell nci is active in, nil when none.
+func (r *Registry) du(nci uint64) *du {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	for _, d := range r.dus {
+		if d.active[nci] {
+			return d
+		}
+	}
+	return nil
+}
+
+// target returns the target of the handover of u, nil when there is none.
+func (r *Registry) target(u *ue) *handover {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	return u.target
+}
+
+// source returns the neighbour gNB u is being handed over from, nil when
+// there is none.
+func (r *Registry) source(u *ue) *xnSource {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	return u.source
+}
+
+// admit keeps the gNB-DU UE F1AP ID and the C-RNTI of u in the gNB-DU of
+// the target cell.
+func (r *Registry) admit(u *ue, duUEID, crnti uint32) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	u.duUEID, u.crnti = duUEID, crnti
+}
+
+// arrived makes u, which reached the target cell, a UE of the gNB.
+func (r *Registry) arrived(u *ue) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	u.source = nil
+	r.xnIn++
+}
+
+// handedOver removes u, handed over to a neighbour gNB.
+func (r *Registry) handedOver(u *ue) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	if r.ues[u.id] == u {
+		delete(r.ues, u.id)
+		r.xnOut++
+	}
+}
+
+func (r *Registry) prepare(u *ue, h *handover) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	u.target = h
+}
+
+// complete moves u to the target of its handover.
+func (r *Registry) complete(u *ue) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	h := u.target
+	u.du, u.nci, u.duUEID, u.crnti, u.target = h.du, h.nci, h.duUEID, h.crnti, nil
+	r.handovers++
+}
+
+func (r *Registry) identify(u *ue, stmsi string) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	u.stmsi = stmsi
+}
+
+func (r *Registry) stmsi(u *ue) string {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	return u.stmsi
+}
+
+// keep keeps the radio capability container in the context of u.
+func (r *Registry) keep(u *ue, container []byte) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	u.capability = container
+}
+
+func (r *Registry) capability(u *ue) []byte {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	return u.capability
+}
+
+func (r *Registry) remove(u *ue) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	if r.ues[u.id] == u {
+		delete(r.ues, u.id)
+	}
+}
+
+// release removes u, released for inactivity.
+func (r *Registry) release(u *ue) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	if r.ues[u.id] == u {
+		delete(r.ues, u.id)
+		r.releases++
+	}
+}
+
+// holder returns the UE context of the gNB-CU UE F1AP ID, nil when none.
+func (r *Registry) holder(cuUEID uint32) *ue {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	return r.ues[cuUEID]
+}
+
+// DUStats describes a gNB-DU that set up the F1.
+type DUStats struct {
+	ID      uint64   `json:"gnb_du_id"`
+	Name    string   `json:"gnb_du_name"`
+	Address string   `json:"address"`
+	Cells   []uint64 `json:"activated_cells"`
+	UEs     int      `json:"ues"`
+}
+
+// Addresses returns where the gNB-DUs of r serve their end of the F1.
+func (r *Registry) Addresses() []string {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	addresses := make([]string, 0, len(r.dus))
+	for _, d := range r.dus {
+		addresses = append(addresses, d.address)
+	}
+	sort.Strings(addresses)
+	return addresses
+}
+
+// ActiveUEs returns the number of UE contexts of r.
+func (r *Registry) ActiveUEs() int {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	return len(r.ues)
+}
+
+// Stats describes the content of a registry: its gNB-DUs, the number of UE
+// contexts in every RRC state, of the handovers completed, between its
+// gNB-DUs and from and to the neighbour gNBs, and of the connections
+// released for inactivity.
+type Stats struct {
+	DUs            []DUStats         `json:"gnb_dus"`
+	UEs            map[fsm.State]int `json:"ues"`
+	Handovers      int               `json:"handovers"`
+	XnHandoversIn  int               `json:"xn_handovers_in"`
+	XnHandoversOut int               `json:"xn_handovers_out"`
+	Releases       int               `json:"releases"`
+}
+
+// Stats returns the gNB-DUs and the UE contexts of r.
+func (r *Registry) Stats() Stats {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	s := Stats{DUs: make([]DUStats, 0, len(r.dus)), UEs: make(map[fsm.State]int), Handovers: r.handovers, XnHandoversIn: r.xnIn, XnHandoversOut: r.xnOut, Releases: r.releases}
+	ues := make(map[*du]int)
+	for _, u := range r.ues {
+		ues[u.du]++
+		s.UEs[u.rrc.State()]++
+	}
+	for _, d := range r.dus {
+		ds := DUStats{ID: d.id, Name: d.name, Address: d.address, UEs: ues[d]}
+		for nci := range d.active {
+			ds.Cells = append(ds.Cells, nci)
+		}
+		sort.Slice(ds.Cells, func(i, j int) bool { return ds.Cells[i] < ds.Cells[j] })
+		s.DUs = append(s.DUs, ds)
+	}
+	sort.Slice(s.DUs, func(i, j int) bool { return s.DUs[i].ID < s.DUs[j].ID })
+	return s
+}
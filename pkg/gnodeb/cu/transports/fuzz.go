@@ -0,0 +1,95 @@
+package transports
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+
+	"github.com/go-kit/kit/log"
+	"github.com/golang/protobuf/proto"
+	stdopentracing "github.com/opentracing/opentracing-go"
+	"github.com/openzipkin/zipkin-go"
+
+	pb "github.com/miki-tnt/sa5g-go-usvc-k8s/pb/gnodeb"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/codec"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/gnodeb/cu/endpoints"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/gnodeb/cu/service"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/gnodeb/f1"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/kitx/chain"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/kitx/fuzz"
+)
+
+// fuzzF1 plays data, the F1 messages of a gNB-DU, against a gNB-CU of its
+// own, through the gRPC decoders and the middleware chain of its endpoints
+// down to the RRC of the UEs. Every line of data is a procedure and its
+// request in the proto3 JSON mapping, as in
+//
+//	F1Setup {"gnb_du_id":"1","address":"du","served_cells":[{"nci":"4096","band":78,"bandwidth_mhz":100,"scs_khz":30,"enabled":true}]}
+//	InitialULRRCMessageTransfer {"gnb_du_id":"1","gnb_du_ue_f1ap_id":1,"nci":"4096","c_rnti":17921,"rrc_container":"UlJDU2V0dXBSZXF1ZXN0"}
+//	ULRRCMessageTransfer {"gnb_cu_ue_f1ap_id":1,"gnb_du_ue_f1ap_id":1,"srb_id":1,"rrc_container":"UlJDU2V0dXBDb21wbGV0ZQ=="}
+//	UERadioCapability {"gnb_cu_ue_f1ap_id":1,"gnb_du_ue_f1ap_id":1}
+//
+// The RRC containers are base64, as the JSON mapping of bytes.
+func fuzzF1(data []byte) int {
+	logger := log.NewNopLogger()
+	dial := func(string) (f1.DU, io.Closer, error) { return nopDU{}, ioutil.NopCloser(nil), nil }
+	svc := service.New("fuzz", service.NewRegistry(), dial, logger)
+	zipkinTracer, _ := zipkin.NewTracer(nil, zipkin.WithNoopTracer(true))
+	s := MakeGRPCServer(endpoints.New(svc, chain.MiddlewareConfig{Logger: logger}), stdopentracing.NoopTracer{}, zipkinTracer, logger)
+
+	ctx := context.Background()
+	score := fuzz.Interesting
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		i := bytes.IndexByte(line, ' ')
+		if i < 0 {
+			return fuzz.Discard
+		}
+		var req proto.Message
+		switch string(line[:i]) {
+		case "F1Setup":
+			req = &pb.F1SetupRequest{}
+		case "InitialULRRCMessageTransfer":
+			req = &pb.InitialULRRCMessageTransferRequest{}
+		case "ULRRCMessageTransfer":
+			req = &pb.RRCMessageTransferRequest{}
+		case "UERadioCapability":
+			req = &pb.UERadioCapabilityRequest{}
+		default:
+			return fuzz.Discard
+		}
+		if err := codec.ProtoJSON.Unmarshal(line[i+1:], req); err != nil {
+			return fuzz.Discard
+		}
+		var err error
+		switch req := req.(type) {
+		case *pb.F1SetupRequest:
+			_, err = s.F1Setup(ctx, req)
+		case *pb.InitialULRRCMessageTransferRequest:
+			_, err = s.InitialULRRCMessageTransfer(ctx, req)
+		case *pb.RRCMessageTransferRequest:
+			_, err = s.ULRRCMessageTransfer(ctx, req)
+		case *pb.UERadioCapabilityRequest:
+			_, err = s.UERadioCapability(ctx, req)
+		}
+		if err != nil {
+			score = fuzz.Normal
+		}
+	}
+	return score
+}
+
+// nopDU is a gNB-DU accepting every procedure of the gNB-CU.
+type nopDU struct{}
+
+func (nopDU) UEContextSetup(_ context.Context, req f1.UEContextSetupRequest) (f1.UEContextSetupResponse, error) {
+	rs := f1.UEContextSetupResponse{DUUEID: req.DUUEID, CRNTI: 1, DRBs: req.DRBs}
+	if rs.DUUEID == 0 {
+		rs.DUUEID = 1 << 16
+	}
+	return rs, nil
+}
+
+func (nopDU) DLRRCMessageTransfer(context.Context, f1.RRCMessage) error { return nil }
+
+func (nopDU) UEContextRelease(context.Context, f1.UEContextRelease) error { return nil }
@@ -0,0 +1,19 @@
+package transports
+
+import (
+	"testing"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/kitx/fuzz"
+)
+
+func TestFuzzF1Corpus(t *testing.T) {
+	seeds, err := fuzz.Corpus("FuzzF1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range seeds {
+		if score := fuzzF1(data); score == fuzz.Discard {
+			t.Errorf("seed %s discarded", name)
+		}
+	}
+}
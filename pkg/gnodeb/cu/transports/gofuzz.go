@@ -0,0 +1,9 @@
+//go:build gofuzz
+// +build gofuzz
+
+package transports
+
+// FuzzF1 is the go-fuzz function of fuzzF1.
+func FuzzF1(data []byte) int {
+	return fuzzF1(data)
+}
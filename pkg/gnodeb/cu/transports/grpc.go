@@ -0,0 +1,299 @@
+package transports
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/tracing/opentracing"
+	"github.com/go-kit/kit/tracing/zipkin"
+	grpctransport "github.com/go-kit/kit/transport/grpc"
+	stdopentracing "github.com/opentracing/opentracing-go"
+	stdzipkin "github.com/openzipkin/zipkin-go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/miki-tnt/sa5g-go-usvc-k8s/pb/gnodeb"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/gnodeb/cell"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/gnodeb/cu/endpoints"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/gnodeb/f1"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/logging"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/meta"
+)
+
+type grpcServer struct {
+	f1Setup                     grpctransport.Handler `json:""`
+	initialULRRCMessageTransfer grpctransport.Handler `json:""`
+	ulRRCMessageTransfer        grpctransport.Handler `json:""`
+	ueRadioCapability           grpctransport.Handler `json:""`
+}
+
+func (s *grpcServer) F1Setup(ctx context.Context, req *pb.F1SetupRequest) (rep *pb.F1SetupResponse, err error) {
+	_, rp, err := s.f1Setup.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcEncodeError(err)
+	}
+	rep = rp.(*pb.F1SetupResponse)
+	return rep, nil
+}
+
+func (s *grpcServer) InitialULRRCMessageTransfer(ctx context.Context, req *pb.InitialULRRCMessageTransferRequest) (rep *pb.InitialULRRCMessageTransferReply, err error) {
+	_, rp, err := s.initialULRRCMessageTransfer.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcEncodeError(err)
+	}
+	rep = rp.(*pb.InitialULRRCMessageTransferReply)
+	return rep, nil
+}
+
+func (s *grpcServer) ULRRCMessageTransfer(ctx context.Context, req *pb.RRCMessageTransferRequest) (rep *pb.RRCMessageTransferReply, err error) {
+	_, rp, err := s.ulRRCMessageTransfer.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcEncodeError(err)
+	}
+	rep = rp.(*pb.RRCMessageTransferReply)
+	return rep, nil
+}
+
+func (s *grpcServer) UERadioCapability(ctx context.Context, req *pb.UERadioCapabilityRequest) (rep *pb.UERadioCapabilityReply, err error) {
+	_, rp, err := s.ueRadioCapability.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcEncodeError(err)
+	}
+	rep = rp.(*pb.UERadioCapabilityReply)
+	return rep, nil
+}
+
+// MakeGRPCServer makes a set of endpoints available as a gRPC server.
+func MakeGRPCServer(endpoints endpoints.Endpoints, otTracer stdopentracing.Tracer, zipkinTracer *stdzipkin.Tracer, logger log.Logger) (req pb.F1CUServer) {
+	zipkinServer := zipkin.GRPCServerTrace(zipkinTracer)
+
+	options := []grpctransport.ServerOption{
+		grpctransport.ServerErrorHandler(logging.ErrorHandler(logger)),
+		grpctransport.ServerBefore(meta.GRPCToContext()),
+		zipkinServer,
+	}
+
+	return &grpcServer{
+		f1Setup: grpctransport.NewServer(
+			endpoints.F1SetupEndpoint,
+			decodeGRPCF1SetupRequest,
+			encodeGRPCF1SetupResponse,
+			append(options, grpctransport.ServerBefore(opentracing.GRPCToContext(otTracer, "F1Setup", logger)))...,
+		),
+
+		initialULRRCMessageTransfer: grpctransport.NewServer(
+			endpoints.InitialULRRCMessageTransferEndpoint,
+			decodeGRPCInitialULRRCMessageTransferRequest,
+			encodeGRPCInitialULRRCMessageTransferResponse,
+			append(options, grpctransport.ServerBefore(opentracing.GRPCToContext(otTracer, "InitialULRRCMessageTransfer", logger)))...,
+		),
+
+		ulRRCMessageTransfer: grpctransport.NewServer(
+			endpoints.ULRRCMessageTransferEndpoint,
+			decodeGRPCRRCMessageTransferRequest,
+			encodeGRPCRRCMessageTransferResponse,
+			append(options, grpctransport.ServerBefore(opentracing.GRPCToContext(otTracer, "ULRRCMessageTransfer", logger)))...,
+		),
+
+		ueRadioCapability: grpctransport.NewServer(
+			endpoints.UERadioCapabilityEndpoint,
+			decodeGRPCUERadioCapabilityRequest,
+			encodeGRPCUERadioCapabilityResponse,
+			append(options, grpctransport.ServerBefore(opentracing.GRPCToContext(otTracer, "UERadioCapability", logger)))...,
+		),
+	}
+}
+
+// decodeGRPCF1SetupRequest is a transport/grpc.DecodeRequestFunc that converts a
+// gRPC request to a user-domain request. Primarily useful in a server.
+func decodeGRPCF1SetupRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pb.F1SetupRequest)
+	cells := make([]cell.Cell, len(req.ServedCells))
+	for i, c := range req.ServedCells {
+		cells[i] = cellFromPB(c)
+	}
+	return f1.SetupRequest{DUID: req.GnbDuId, DUName: req.GnbDuName, Address: req.Address, Cells: cells}, nil
+}
+
+// encodeGRPCF1SetupResponse is a transport/grpc.EncodeResponseFunc that converts a
+// user-domain response to a gRPC reply. Primarily useful in a server.
+func encodeGRPCF1SetupResponse(_ context.Context, grpcReply interface{}) (res interface{}, err error) {
+	reply := grpcReply.(f1.SetupResponse)
+	return &pb.F1SetupResponse{GnbCuName: reply.CUName, ActivatedCells: reply.Activated}, nil
+}
+
+// decodeGRPCInitialULRRCMessageTransferRequest is a transport/grpc.DecodeRequestFunc that converts a
+// gRPC request to a user-domain request. Primarily useful in a server.
+func decodeGRPCInitialULRRCMessageTransferRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pb.InitialULRRCMessageTransferRequest)
+	return f1.InitialULRRCMessage{
+		DUID:   req.GnbDuId,
+		DUUEID: req.GnbDuUeF1ApId,
+		NCI:    req.Nci,
+		CRNTI:  req.CRnti,
+		RRC:    req.RrcContainer,
+	}, nil
+}
+
+// encodeGRPCInitialULRRCMessageTransferResponse is a transport/grpc.EncodeResponseFunc that converts a
+// user-domain response to a gRPC reply. Primarily useful in a server.
+func encodeGRPCInitialULRRCMessageTransferResponse(_ context.Context, grpcReply interface{}) (res interface{}, err error) {
+	reply := grpcReply.(endpoints.InitialULRRCMessageTransferResponse)
+	return &pb.InitialULRRCMessageTransferReply{GnbCuUeF1ApId: reply.CUUEID}, nil
+}
+
+// decodeGRPCRRCMessageTransferRequest is a transport/grpc.DecodeRequestFunc that converts a
+// gRPC request to a user-domain request. Primarily useful in a server.
+func decodeGRPCRRCMessageTransferRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pb.RRCMessageTransferRequest)
+	return f1.RRCMessage{CUUEID: req.GnbCuUeF1ApId, DUUEID: req.GnbDuUeF1ApId, SRB: req.SrbId, RRC: req.RrcContainer}, nil
+}
+
+// encodeGRPCRRCMessageTransferResponse is a transport/grpc.EncodeResponseFunc that converts a
+// user-domain response to a gRPC reply. Primarily useful in a server.
+func encodeGRPCRRCMessageTransferResponse(_ context.Context, _ interface{}) (res interface{}, err error) {
+	return &pb.RRCMessageTransferReply{}, nil
+}
+
+// decodeGRPCUERadioCapabilityRequest is a transport/grpc.DecodeRequestFunc that converts a
+// gRPC request to a user-domain request. Primarily useful in a server.
+func decodeGRPCUERadioCapabilityRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pb.UERadioCapabilityRequest)
+	return f1.UERadioCapabilityRequest{CUUEID: req.GnbCuUeF1ApId, DUUEID: req.GnbDuUeF1ApId}, nil
+}
+
+// encodeGRPCUERadioCapabilityResponse is a transport/grpc.EncodeResponseFunc that converts a
+// user-domain response to a gRPC reply. Primarily useful in a server.
+func encodeGRPCUERadioCapabilityResponse(_ context.Context, grpcReply interface{}) (res interface{}, err error) {
+	reply := grpcReply.(endpoints.UERadioCapabilityResponse)
+	return &pb.UERadioCapabilityReply{UeCapabilityRatContainerList: reply.Container}, nil
+}
+
+// NewGRPCClient returns the gNB-CU end of the F1 backed by a gRPC server at
+// the other end of the conn, for a gNB-DU. The caller is responsible for
+// constructing the conn, and eventually closing the underlying transport.
+func NewGRPCClient(conn *grpc.ClientConn, otTracer stdopentracing.Tracer, zipkinTracer *stdzipkin.Tracer, logger log.Logger) f1.CU {
+	zipkinClient := zipkin.GRPCClientTrace(zipkinTracer)
+
+	// global client middlewares
+	options := []grpctransport.ClientOption{
+		zipkinClient,
+		grpctransport.ClientBefore(meta.ContextToGRPC()),
+		grpctransport.ClientBefore(opentracing.ContextToGRPC(otTracer, logger)),
+	}
+
+	client := func(method string, enc grpctransport.EncodeRequestFunc, dec grpctransport.DecodeResponseFunc, reply interface{}) endpoint.Endpoint {
+		e := grpctransport.NewClient(conn, "gnodeb.F1CU", method, enc, dec, reply, options...).Endpoint()
+		return opentracing.TraceClient(otTracer, method)(e)
+	}
+
+	return endpoints.Endpoints{
+		F1SetupEndpoint:                     client("F1Setup", encodeGRPCF1SetupRequest, decodeGRPCF1SetupResponse, pb.F1SetupResponse{}),
+		InitialULRRCMessageTransferEndpoint: client("InitialULRRCMessageTransfer", encodeGRPCInitialULRRCMessageTransferRequest, decodeGRPCInitialULRRCMessageTransferResponse, pb.InitialULRRCMessageTransferReply{}),
+		ULRRCMessageTransferEndpoint:        client("ULRRCMessageTransfer", encodeGRPCRRCMessageTransferRequest, decodeGRPCRRCMessageTransferResponse, pb.RRCMessageTransferReply{}),
+		UERadioCapabilityEndpoint:           client("UERadioCapability", encodeGRPCUERadioCapabilityRequest, decodeGRPCUERadioCapabilityResponse, pb.UERadioCapabilityReply{}),
+	}
+}
+
+// encodeGRPCF1SetupRequest is a transport/grpc.EncodeRequestFunc that converts a
+// user-domain F1Setup request to a gRPC F1Setup request. Primarily useful in a client.
+func encodeGRPCF1SetupRequest(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(f1.SetupRequest)
+	cells := make([]*pb.Cell, len(req.Cells))
+	for i, c := range req.Cells {
+		cells[i] = cellToPB(c)
+	}
+	return &pb.F1SetupRequest{GnbDuId: req.DUID, GnbDuName: req.DUName, Address: req.Address, ServedCells: cells}, nil
+}
+
+// decodeGRPCF1SetupResponse is a transport/grpc.DecodeResponseFunc that converts a
+// gRPC F1Setup reply to a user-domain F1Setup response. Primarily useful in a client.
+func decodeGRPCF1SetupResponse(_ context.Context, grpcReply interface{}) (interface{}, error) {
+	reply := grpcReply.(*pb.F1SetupResponse)
+	return f1.SetupResponse{CUName: reply.GnbCuName, Activated: reply.ActivatedCells}, nil
+}
+
+// encodeGRPCInitialULRRCMessageTransferRequest is a transport/grpc.EncodeRequestFunc that converts a
+// user-domain InitialULRRCMessageTransfer request to a gRPC request. Primarily useful in a client.
+func encodeGRPCInitialULRRCMessageTransferRequest(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(f1.InitialULRRCMessage)
+	return &pb.InitialULRRCMessageTransferRequest{
+		GnbDuId:       req.DUID,
+		GnbDuUeF1ApId: req.DUUEID,
+		Nci:           req.NCI,
+		CRnti:         req.CRNTI,
+		RrcContainer:  req.RRC,
+	}, nil
+}
+
+// decodeGRPCInitialULRRCMessageTransferResponse is a transport/grpc.DecodeResponseFunc that converts a
+// gRPC InitialULRRCMessageTransfer reply to a user-domain response. Primarily useful in a client.
+func decodeGRPCInitialULRRCMessageTransferResponse(_ context.Context, grpcReply interface{}) (interface{}, error) {
+	reply := grpcReply.(*pb.InitialULRRCMessageTransferReply)
+	return endpoints.InitialULRRCMessageTransferResponse{CUUEID: reply.GnbCuUeF1ApId}, nil
+}
+
+// encodeGRPCRRCMessageTransferRequest is a transport/grpc.EncodeRequestFunc that converts a
+// user-domain RRC message transfer to a gRPC request. Primarily useful in a client.
+func encodeGRPCRRCMessageTransferRequest(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(f1.RRCMessage)
+	return &pb.RRCMessageTransferRequest{GnbCuUeF1ApId: req.CUUEID, GnbDuUeF1ApId: req.DUUEID, SrbId: req.SRB, RrcContainer: req.RRC}, nil
+}
+
+// decodeGRPCRRCMessageTransferResponse is a transport/grpc.DecodeResponseFunc that converts a
+// gRPC RRC message transfer reply to a user-domain response. Primarily useful in a client.
+func decodeGRPCRRCMessageTransferResponse(_ context.Context, _ interface{}) (interface{}, error) {
+	return endpoints.RRCMessageTransferResponse{}, nil
+}
+
+// encodeGRPCUERadioCapabilityRequest is a transport/grpc.EncodeRequestFunc that converts a
+// user-domain UERadioCapability request to a gRPC request. Primarily useful in a client.
+func encodeGRPCUERadioCapabilityRequest(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(f1.UERadioCapabilityRequest)
+	return &pb.UERadioCapabilityRequest{GnbCuUeF1ApId: req.CUUEID, GnbDuUeF1ApId: req.DUUEID}, nil
+}
+
+// decodeGRPCUERadioCapabilityResponse is a transport/grpc.DecodeResponseFunc that converts a
+// gRPC UERadioCapability reply to a user-domain response. Primarily useful in a client.
+func decodeGRPCUERadioCapabilityResponse(_ context.Context, grpcReply interface{}) (interface{}, error) {
+	reply := grpcReply.(*pb.UERadioCapabilityReply)
+	return endpoints.UERadioCapabilityResponse{Container: reply.UeCapabilityRatContainerList}, nil
+}
+
+func cellFromPB(c *pb.Cell) cell.Cell {
+	return cell.Cell{
+		NCI:       c.GetNci(),
+		PCI:       c.GetPci(),
+		TAC:       c.GetTac(),
+		Band:      c.GetBand(),
+		Bandwidth: c.GetBandwidthMhz(),
+		SCS:       c.GetScsKhz(),
+		Enabled:   c.GetEnabled(),
+	}
+}
+
+func cellToPB(c cell.Cell) *pb.Cell {
+	return &pb.Cell{
+		Nci:          c.NCI,
+		Pci:          c.PCI,
+		Tac:          c.TAC,
+		Band:         c.Band,
+		BandwidthMhz: c.Bandwidth,
+		ScsKhz:       c.SCS,
+		Enabled:      c.Enabled,
+	}
+}
+
+func grpcEncodeError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	st, ok := status.FromError(err)
+	if ok {
+		return status.Error(st.Code(), st.Message())
+	}
+	return status.Error(codes.Internal, "internal server error")
+}
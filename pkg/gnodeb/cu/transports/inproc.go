@@ -0,0 +1,45 @@
+package transports
+
+import (
+	"github.com/go-kit/kit/log"
+
+	pb "github.com/miki-tnt/sa5g-go-usvc-k8s/pb/gnodeb"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/gnodeb/cu/endpoints"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/gnodeb/f1"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/kitx/inproc"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/logging"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/meta"
+)
+
+// MakeInprocServer makes a set of endpoints available to the in-process
+// clients of NewInprocClient, with the codecs and metadata of the gRPC
+// transport. The caller runs its Serve method and eventually closes it.
+func MakeInprocServer(endpoints endpoints.Endpoints, logger log.Logger) *inproc.Server {
+	options := []inproc.ServerOption{
+		inproc.ServerErrorHandler(logging.ErrorHandler(logger)),
+		inproc.ServerErrorEncoder(grpcEncodeError),
+		inproc.ServerBefore(meta.GRPCToContext()),
+	}
+
+	s := inproc.NewServer()
+	s.Handle("F1Setup", endpoints.F1SetupEndpoint, decodeGRPCF1SetupRequest, encodeGRPCF1SetupResponse, pb.F1SetupRequest{}, options...)
+	s.Handle("InitialULRRCMessageTransfer", endpoints.InitialULRRCMessageTransferEndpoint, decodeGRPCInitialULRRCMessageTransferRequest, encodeGRPCInitialULRRCMessageTransferResponse, pb.InitialULRRCMessageTransferRequest{}, options...)
+	s.Handle("ULRRCMessageTransfer", endpoints.ULRRCMessageTransferEndpoint, decodeGRPCRRCMessageTransferRequest, encodeGRPCRRCMessageTransferResponse, pb.RRCMessageTransferRequest{}, options...)
+	s.Handle("UERadioCapability", endpoints.UERadioCapabilityEndpoint, decodeGRPCUERadioCapabilityRequest, encodeGRPCUERadioCapabilityResponse, pb.UERadioCapabilityRequest{}, options...)
+	return s
+}
+
+// NewInprocClient returns the gNB-CU end of the F1 calling the server of
+// MakeInprocServer, for a gNB-DU in the same process.
+func NewInprocClient(s *inproc.Server) f1.CU {
+	options := []inproc.ClientOption{
+		inproc.ClientBefore(meta.ContextToGRPC()),
+	}
+
+	return endpoints.Endpoints{
+		F1SetupEndpoint:                     inproc.NewClient(s, "F1Setup", encodeGRPCF1SetupRequest, decodeGRPCF1SetupResponse, pb.F1SetupResponse{}, options...).Endpoint(),
+		InitialULRRCMessageTransferEndpoint: inproc.NewClient(s, "InitialULRRCMessageTransfer", encodeGRPCInitialULRRCMessageTransferRequest, decodeGRPCInitialULRRCMessageTransferResponse, pb.InitialULRRCMessageTransferReply{}, options...).Endpoint(),
+		ULRRCMessageTransferEndpoint:        inproc.NewClient(s, "ULRRCMessageTransfer", encodeGRPCRRCMessageTransferRequest, decodeGRPCRRCMessageTransferResponse, pb.RRCMessageTransferReply{}, options...).Endpoint(),
+		UERadioCapabilityEndpoint:           inproc.NewClient(s, "UERadioCapability", encodeGRPCUERadioCapabilityRequest, decodeGRPCUERadioCapabilityResponse, pb.UERadioCapabilityReply{}, options...).Endpoint(),
+	}
+}
@@ -0,0 +1,202 @@
+package transports
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/tracing/opentracing"
+	"github.com/go-kit/kit/tracing/zipkin"
+	grpctransport "github.com/go-kit/kit/transport/grpc"
+	stdopentracing "github.com/opentracing/opentracing-go"
+	stdzipkin "github.com/openzipkin/zipkin-go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/miki-tnt/sa5g-go-usvc-k8s/pb/gnodeb"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/gnodeb/cu/endpoints"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/gnodeb/xn"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/identity"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/logging"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/meta"
+)
+
+type xnGRPCServer struct {
+	handoverPreparation grpctransport.Handler `json:""`
+	handoverCancel      grpctransport.Handler `json:""`
+	ueContextRelease    grpctransport.Handler `json:""`
+}
+
+func (s *xnGRPCServer) HandoverPreparation(ctx context.Context, req *pb.XnHandoverRequest) (rep *pb.XnHandoverRequestAcknowledge, err error) {
+	_, rp, err := s.handoverPreparation.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcEncodeError(err)
+	}
+	rep = rp.(*pb.XnHandoverRequestAcknowledge)
+	return rep, nil
+}
+
+func (s *xnGRPCServer) HandoverCancel(ctx context.Context, req *pb.XnHandoverCancel) (rep *pb.XnReply, err error) {
+	_, rp, err := s.handoverCancel.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcEncodeError(err)
+	}
+	rep = rp.(*pb.XnReply)
+	return rep, nil
+}
+
+func (s *xnGRPCServer) UEContextRelease(ctx context.Context, req *pb.XnUEContextRelease) (rep *pb.XnReply, err error) {
+	_, rp, err := s.ueContextRelease.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcEncodeError(err)
+	}
+	rep = rp.(*pb.XnReply)
+	return rep, nil
+}
+
+// MakeXnGRPCServer makes the Xn endpoints of a set available as a gRPC
+// server, for the neighbour gNB-CUs.
+func MakeXnGRPCServer(endpoints endpoints.Endpoints, otTracer stdopentracing.Tracer, zipkinTracer *stdzipkin.Tracer, logger log.Logger) (req pb.XnAPServer) {
+	zipkinServer := zipkin.GRPCServerTrace(zipkinTracer)
+
+	options := []grpctransport.ServerOption{
+		grpctransport.ServerErrorHandler(logging.ErrorHandler(logger)),
+		grpctransport.ServerBefore(meta.GRPCToContext()),
+		zipkinServer,
+	}
+
+	return &xnGRPCServer{
+		handoverPreparation: grpctransport.NewServer(
+			endpoints.HandoverPreparationEndpoint,
+			decodeGRPCHandoverRequest,
+			encodeGRPCHandoverRequestAcknowledge,
+			append(options, grpctransport.ServerBefore(opentracing.GRPCToContext(otTracer, "HandoverPreparation", logger)))...,
+		),
+
+		handoverCancel: grpctransport.NewServer(
+			endpoints.HandoverCancelEndpoint,
+			decodeGRPCHandoverCancel,
+			encodeGRPCXnResponse,
+			append(options, grpctransport.ServerBefore(opentracing.GRPCToContext(otTracer, "HandoverCancel", logger)))...,
+		),
+
+		ueContextRelease: grpctransport.NewServer(
+			endpoints.UEContextReleaseEndpoint,
+			decodeGRPCXnUEContextRelease,
+			encodeGRPCXnResponse,
+			append(options, grpctransport.ServerBefore(opentracing.GRPCToContext(otTracer, "UEContextRelease", logger)))...,
+		),
+	}
+}
+
+// decodeGRPCHandoverRequest is a transport/grpc.DecodeRequestFunc that converts a
+// gRPC request to a user-domain request. Primarily useful in a server.
+func decodeGRPCHandoverRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pb.XnHandoverRequest)
+	source, err := identity.ParseGNBID(req.SourceGnbId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return xn.HandoverRequest{
+		Source:     source,
+		SourceUEID: req.SourceNgRanNodeUeXnapId,
+		TargetNCI:  req.TargetNci,
+		STMSI:      req.Ng_5GSTmsi,
+		Capability: req.UeCapabilityRatContainerList,
+		DRBs:       req.DrbIds,
+	}, nil
+}
+
+// encodeGRPCHandoverRequestAcknowledge is a transport/grpc.EncodeResponseFunc that converts a
+// user-domain response to a gRPC reply. Primarily useful in a server.
+func encodeGRPCHandoverRequestAcknowledge(_ context.Context, grpcReply interface{}) (res interface{}, err error) {
+	reply := grpcReply.(xn.HandoverRequestAcknowledge)
+	return &pb.XnHandoverRequestAcknowledge{TargetNgRanNodeUeXnapId: reply.TargetUEID, DrbIds: reply.DRBs, RrcContainer: reply.RRC}, nil
+}
+
+// decodeGRPCHandoverCancel is a transport/grpc.DecodeRequestFunc that converts a
+// gRPC request to a user-domain request. Primarily useful in a server.
+func decodeGRPCHandoverCancel(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pb.XnHandoverCancel)
+	return xn.HandoverCancel{SourceUEID: req.SourceNgRanNodeUeXnapId, TargetUEID: req.TargetNgRanNodeUeXnapId}, nil
+}
+
+// decodeGRPCXnUEContextRelease is a transport/grpc.DecodeRequestFunc that converts a
+// gRPC request to a user-domain request. Primarily useful in a server.
+func decodeGRPCXnUEContextRelease(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pb.XnUEContextRelease)
+	return xn.UEContextRelease{SourceUEID: req.SourceNgRanNodeUeXnapId, TargetUEID: req.TargetNgRanNodeUeXnapId}, nil
+}
+
+// encodeGRPCXnResponse is a transport/grpc.EncodeResponseFunc that converts a
+// user-domain response to a gRPC reply. Primarily useful in a server.
+func encodeGRPCXnResponse(_ context.Context, _ interface{}) (res interface{}, err error) {
+	return &pb.XnReply{}, nil
+}
+
+// NewXnGRPCClient returns the end of the Xn of a neighbour gNB-CU backed by
+// a gRPC server at the other end of the conn. The caller is responsible for
+// constructing the conn, and eventually closing the underlying transport.
+func NewXnGRPCClient(conn *grpc.ClientConn, otTracer stdopentracing.Tracer, zipkinTracer *stdzipkin.Tracer, logger log.Logger) xn.GNB {
+	zipkinClient := zipkin.GRPCClientTrace(zipkinTracer)
+
+	// global client middlewares
+	options := []grpctransport.ClientOption{
+		zipkinClient,
+		grpctransport.ClientBefore(meta.ContextToGRPC()),
+		grpctransport.ClientBefore(opentracing.ContextToGRPC(otTracer, logger)),
+	}
+
+	client := func(method string, enc grpctransport.EncodeRequestFunc, dec grpctransport.DecodeResponseFunc, reply interface{}) endpoint.Endpoint {
+		e := grpctransport.NewClient(conn, "gnodeb.XnAP", method, enc, dec, reply, options...).Endpoint()
+		return opentracing.TraceClient(otTracer, method)(e)
+	}
+
+	return endpoints.Endpoints{
+		HandoverPreparationEndpoint: client("HandoverPreparation", encodeGRPCHandoverRequest, decodeGRPCHandoverRequestAcknowledge, pb.XnHandoverRequestAcknowledge{}),
+		HandoverCancelEndpoint:      client("HandoverCancel", encodeGRPCHandoverCancel, decodeGRPCXnResponse, pb.XnReply{}),
+		UEContextReleaseEndpoint:    client("UEContextRelease", encodeGRPCXnUEContextRelease, decodeGRPCXnResponse, pb.XnReply{}),
+	}
+}
+
+// encodeGRPCHandoverRequest is a transport/grpc.EncodeRequestFunc that converts a
+// user-domain Handover Request to a gRPC request. Primarily useful in a client.
+func encodeGRPCHandoverRequest(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(xn.HandoverRequest)
+	return &pb.XnHandoverRequest{
+		SourceGnbId:                  req.Source.String(),
+		SourceNgRanNodeUeXnapId:      req.SourceUEID,
+		TargetNci:                    req.TargetNCI,
+		Ng_5GSTmsi:                   req.STMSI,
+		UeCapabilityRatContainerList: req.Capability,
+		DrbIds:                       req.DRBs,
+	}, nil
+}
+
+// decodeGRPCHandoverRequestAcknowledge is a transport/grpc.DecodeResponseFunc that converts a
+// gRPC reply to a user-domain Handover Request Acknowledge. Primarily useful in a client.
+func decodeGRPCHandoverRequestAcknowledge(_ context.Context, grpcReply interface{}) (interface{}, error) {
+	reply := grpcReply.(*pb.XnHandoverRequestAcknowledge)
+	return xn.HandoverRequestAcknowledge{TargetUEID: reply.TargetNgRanNodeUeXnapId, DRBs: reply.DrbIds, RRC: reply.RrcContainer}, nil
+}
+
+// encodeGRPCHandoverCancel is a transport/grpc.EncodeRequestFunc that converts a
+// user-domain Handover Cancel to a gRPC request. Primarily useful in a client.
+func encodeGRPCHandoverCancel(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(xn.HandoverCancel)
+	return &pb.XnHandoverCancel{SourceNgRanNodeUeXnapId: req.SourceUEID, TargetNgRanNodeUeXnapId: req.TargetUEID}, nil
+}
+
+// encodeGRPCXnUEContextRelease is a transport/grpc.EncodeRequestFunc that converts a
+// user-domain UE Context Release to a gRPC request. Primarily useful in a client.
+func encodeGRPCXnUEContextRelease(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(xn.UEContextRelease)
+	return &pb.XnUEContextRelease{SourceNgRanNodeUeXnapId: req.SourceUEID, TargetNgRanNodeUeXnapId: req.TargetUEID}, nil
+}
+
+// decodeGRPCXnResponse is a transport/grpc.DecodeResponseFunc that converts a
+// gRPC Xn reply to a user-domain response. Primarily useful in a client.
+func decodeGRPCXnResponse(_ context.Context, _ interface{}) (interface{}, error) {
+	return endpoints.XnResponse{}, nil
+}
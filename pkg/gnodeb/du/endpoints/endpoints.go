@@ -0,0 +1,146 @@
+package endpoints
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/gnodeb/du/service"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/gnodeb/f1"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/kitx/chain"
+)
+
+// Endpoints collects all of the endpoints that compose the gnbdu service. It's
+// meant to be used as a helper struct, to collect all of the endpoints into a
+// single parameter.
+type Endpoints struct {
+	F1SetupEndpoint              endpoint.Endpoint `json:""`
+	AccessEndpoint               endpoint.Endpoint `json:""`
+	UplinkEndpoint               endpoint.Endpoint `json:""`
+	UEEndpoint                   endpoint.Endpoint `json:""`
+	CapabilityEndpoint           endpoint.Endpoint `json:""`
+	UEContextSetupEndpoint       endpoint.Endpoint `json:""`
+	DLRRCMessageTransferEndpoint endpoint.Endpoint `json:""`
+	UEContextReleaseEndpoint     endpoint.Endpoint `json:""`
+}
+
+// New return a new instance of the endpoint that wraps the provided service.
+// The endpoints run in the middleware stack of mw.
+func New(svc service.GnbduService, mw chain.MiddlewareConfig) (ep Endpoints) {
+	mw.Logging = LoggingMiddleware
+	c := chain.New(mw)
+	ep.F1SetupEndpoint = c.Build("f1Setup", MakeF1SetupEndpoint(svc))
+	ep.AccessEndpoint = c.Build("access", MakeAccessEndpoint(svc))
+	ep.UplinkEndpoint = c.Build("uplink", MakeUplinkEndpoint(svc))
+	ep.UEEndpoint = c.Build("ue", MakeUEEndpoint(svc))
+	ep.CapabilityEndpoint = c.Build("capability", MakeCapabilityEndpoint(svc))
+	ep.UEContextSetupEndpoint = c.Build("ueContextSetup", MakeUEContextSetupEndpoint(svc))
+	ep.DLRRCMessageTransferEndpoint = c.Build("dlRRCMessageTransfer", MakeDLRRCMessageTransferEndpoint(svc))
+	ep.UEContextReleaseEndpoint = c.Build("ueContextRelease", MakeUEContextReleaseEndpoint(svc))
+	return ep
+}
+
+// MakeF1SetupEndpoint returns an endpoint that invokes F1Setup on the service.
+// Primarily useful in a server.
+func MakeF1SetupEndpoint(svc service.GnbduService) (ep endpoint.Endpoint) {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		return svc.F1Setup(ctx)
+	}
+}
+
+// MakeAccessEndpoint returns an endpoint that invokes Access on the service.
+// Primarily useful in a server.
+func MakeAccessEndpoint(svc service.GnbduService) (ep endpoint.Endpoint) {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(AccessRequest)
+		if err := req.validate(); err != nil {
+			return service.UE{}, err
+		}
+		return svc.Access(ctx, req.NCI)
+	}
+}
+
+// MakeUplinkEndpoint returns an endpoint that invokes Uplink on the service.
+// Primarily useful in a server.
+func MakeUplinkEndpoint(svc service.GnbduService) (ep endpoint.Endpoint) {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(UplinkRequest)
+		if err := req.validate(); err != nil {
+			return UplinkResponse{}, err
+		}
+		return UplinkResponse{}, svc.Uplink(ctx, req.DUUEID, req.RRC)
+	}
+}
+
+// MakeUEEndpoint returns an endpoint that invokes UE on the service.
+// Primarily useful in a server.
+func MakeUEEndpoint(svc service.GnbduService) (ep endpoint.Endpoint) {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(UERequest)
+		return svc.UE(ctx, req.DUUEID)
+	}
+}
+
+// MakeCapabilityEndpoint returns an endpoint that invokes Capability on the
+// service. Primarily useful in a server.
+func MakeCapabilityEndpoint(svc service.GnbduService) (ep endpoint.Endpoint) {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(CapabilityRequest)
+		container, err := svc.Capability(ctx, req.DUUEID)
+		return CapabilityResponse{Container: container}, err
+	}
+}
+
+// MakeUEContextSetupEndpoint returns an endpoint that invokes UEContextSetup
+// on the service. Primarily useful in a server.
+func MakeUEContextSetupEndpoint(svc service.GnbduService) (ep endpoint.Endpoint) {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(f1.UEContextSetupRequest)
+		return svc.UEContextSetup(ctx, req)
+	}
+}
+
+// UEContextSetup implements the F1 of the service, so Endpoints may be used
+// as the gNB-DU end of the F1. This is primarily useful in the context of a
+// client library.
+func (e Endpoints) UEContextSetup(ctx context.Context, req f1.UEContextSetupRequest) (rs f1.UEContextSetupResponse, err error) {
+	resp, err := e.UEContextSetupEndpoint(ctx, req)
+	if err != nil {
+		return
+	}
+	return resp.(f1.UEContextSetupResponse), nil
+}
+
+// MakeDLRRCMessageTransferEndpoint returns an endpoint that invokes
+// DLRRCMessageTransfer on the service. Primarily useful in a server.
+func MakeDLRRCMessageTransferEndpoint(svc service.GnbduService) (ep endpoint.Endpoint) {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(f1.RRCMessage)
+		return RRCMessageTransferResponse{}, svc.DLRRCMessageTransfer(ctx, req)
+	}
+}
+
+// DLRRCMessageTransfer implements the F1 of the service, so Endpoints may be
+// used as the gNB-DU end of the F1. This is primarily useful in the context
+// of a client library.
+func (e Endpoints) DLRRCMessageTransfer(ctx context.Context, m f1.RRCMessage) (err error) {
+	_, err = e.DLRRCMessageTransferEndpoint(ctx, m)
+	return
+}
+
+// MakeUEContextReleaseEndpoint returns an endpoint that invokes
+// UEContextRelease on the service. Primarily useful in a server.
+func MakeUEContextReleaseEndpoint(svc service.GnbduService) (ep endpoint.Endpoint) {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(f1.UEContextRelease)
+		return UEContextReleaseResponse{}, svc.UEContextRelease(ctx, req)
+	}
+}
+
+// UEContextRelease implements the F1 of the service, so Endpoints may be
+// used as the gNB-DU end of the F1. This is primarily useful in the context
+// of a client library.
+func (e Endpoints) UEContextRelease(ctx context.Context, m f1.UEContextRelease) (err error) {
+	_, err = e.UEContextReleaseEndpoint(ctx, m)
+	return
+}
@@ -0,0 +1,51 @@
+package endpoints
+
+import (
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// The requests of the F1 methods are the F1 messages of package f1.
+
+type Request interface {
+	validate() error
+}
+
+// F1SetupRequest collects the request parameters for the F1Setup method.
+type F1SetupRequest struct{}
+
+// AccessRequest collects the request parameters for the Access method.
+type AccessRequest struct {
+	NCI uint64 `json:"nci"`
+}
+
+func (r AccessRequest) validate() error {
+	if r.NCI == 0 {
+		return status.Error(codes.InvalidArgument, "missing nci")
+	}
+	return nil
+}
+
+// UplinkRequest collects the request parameters for the Uplink method.
+type UplinkRequest struct {
+	DUUEID uint32 `json:"gnb_du_ue_f1ap_id"`
+	RRC    string `json:"rrc"`
+}
+
+func (r UplinkRequest) validate() error {
+	if r.RRC == "" {
+		return status.Error(codes.InvalidArgument, "missing rrc")
+	}
+	return nil
+}
+
+// UERequest collects the request parameters for the UE method.
+type UERequest struct {
+	DUUEID uint32 `json:"gnb_du_ue_f1ap_id"`
+}
+
+// CapabilityRequest collects the request parameters for the Capability
+// method.
+type CapabilityRequest struct {
+	DUUEID uint32 `json:"gnb_du_ue_f1ap_id"`
+}
@@ -0,0 +1,21 @@
+package endpoints
+
+// The responses of F1Setup and UEContextSetup are the F1 messages of package
+// f1, those of Access and UE the service.UE.
+
+// UplinkResponse collects the response values for the Uplink method.
+type UplinkResponse struct{}
+
+// CapabilityResponse collects the response values for the Capability
+// method.
+type CapabilityResponse struct {
+	Container []byte `json:"ue_capability_rat_container_list"`
+}
+
+// RRCMessageTransferResponse collects the response values for the
+// DLRRCMessageTransfer method.
+type RRCMessageTransferResponse struct{}
+
+// UEContextReleaseResponse collects the response values for the
+// UEContextRelease method.
+type UEContextReleaseResponse struct{}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/gnodeb/f1"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/logging"
+)
+
+type loggingMiddleware struct {
+	logger log.Logger
+	next   GnbduService
+}
+
+// LoggingMiddleware takes a logger as a dependency
+// and returns a ServiceMiddleware.
+func LoggingMiddleware(logger log.Logger) Middleware {
+	return func(next GnbduService) GnbduService {
+		return loggingMiddleware{level.Info(logger), next}
+	}
+}
+
+func (lm loggingMiddleware) F1Setup(ctx context.Context) (rs f1.SetupResponse, err error) {
+	defer func(begin time.Time) {
+		log.With(lm.logger, logging.ContextKeyvals(ctx)...).Log("method", "F1Setup", "gnb_cu_name", rs.CUName, "activated", len(rs.Activated), "err", err)
+	}(time.Now())
+
+	return lm.next.F1Setup(ctx)
+}
+
+func (lm loggingMiddleware) Access(ctx context.Context, nci uint64) (u UE, err error) {
+	defer func(begin time.Time) {
+		log.With(lm.logger, logging.ContextKeyvals(ctx)...).Log("method", "Access", "nci", nci, "gnb_du_ue_f1ap_id", u.DUUEID, "err", err)
+	}(time.Now())
+
+	return lm.next.Access(ctx, nci)
+}
+
+func (lm loggingMiddleware) Capability(ctx context.Context, duUEID uint32) (container []byte, err error) {
+	defer func(begin time.Time) {
+		log.With(lm.logger, logging.ContextKeyvals(ctx)...).Log("method", "Capability", "gnb_du_ue_f1ap_id", duUEID, "bytes", len(container), "err", err)
+	}(time.Now())
+
+	return lm.next.Capability(ctx, duUEID)
+}
+
+func (lm loggingMiddleware) Uplink(ctx context.Context, duUEID uint32, rrc string) (err error) {
+	defer func(begin time.Time) {
+		log.With(lm.logger, logging.ContextKeyvals(ctx)...).Log("method", "Uplink", "gnb_du_ue_f1ap_id", duUEID, "rrc", rrc, "err", err)
+	}(time.Now())
+
+	return lm.next.Uplink(ctx, duUEID, rrc)
+}
+
+func (lm loggingMiddleware) UE(ctx context.Context, duUEID uint32) (u UE, err error) {
+	defer func(begin time.Time) {
+		log.With(lm.logger, logging.ContextKeyvals(ctx)...).Log("method", "UE", "gnb_du_ue_f1ap_id", duUEID, "err", err)
+	}(time.Now())
+
+	return lm.next.UE(ctx, duUEID)
+}
+
+func (lm loggingMiddleware) ActiveUEs(ctx context.Context) (ues map[uint64]int, err error) {
+	defer func(begin time.Time) {
+		log.With(lm.logger, logging.ContextKeyvals(ctx)...).Log("method", "ActiveUEs", "cells", len(ues), "err", err)
+	}(time.Now())
+
+	return lm.next.ActiveUEs(ctx)
+}
+
+func (lm loggingMiddleware) UEContextSetup(ctx context.Context, req f1.UEContextSetupRequest) (rs f1.UEContextSetupResponse, err error) {
+	defer func(begin time.Time) {
+		log.With(lm.logger, logging.ContextKeyvals(ctx)...).Log("method", "UEContextSetup", "gnb_cu_ue_f1ap_id", req.CUUEID, "gnb_du_ue_f1ap_id", req.DUUEID, "drbs", len(req.DRBs), "err", err)
+	}(time.Now())
+
+	return lm.next.UEContextSetup(ctx, req)
+}
+
+func (lm loggingMiddleware) DLRRCMessageTransfer(ctx context.Context, m f1.RRCMessage) (err error) {
+	defer func(begin time.Time) {
+		log.With(lm.logger, logging.ContextKeyvals(ctx)...).Log("method", "DLRRCMessageTransfer", "gnb_cu_ue_f1ap_id", m.CUUEID, "gnb_du_ue_f1ap_id", m.DUUEID, "rrc", string(m.RRC), "err", err)
+	}(time.Now())
+
+	return lm.next.DLRRCMessageTransfer(ctx, m)
+}
+
+func (lm loggingMiddleware) UEContextRelease(ctx context.Context, m f1.UEContextRelease) (err error) {
+	defer func(begin time.Time) {
+		log.With(lm.logger, logging.ContextKeyvals(ctx)...).Log("method", "UEContextRelease", "gnb_cu_ue_f1ap_id", m.CUUEID, "gnb_du_ue_f1ap_id", m.DUUEID, "err", err)
+	}(time.Now())
+
+	return lm.next.UEContextRelease(ctx, m)
+}
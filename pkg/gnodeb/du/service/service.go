@@ -0,0 +1,326 @@
+package service
+
+import (
+	"context"
+	"sync"
+
+	"github.com/go-kit/kit/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/gnodeb/cell"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/gnodeb/f1"
+)
+
+// MaxCRNTI is the highest C-RNTI given to a UE (TS 38.321 7.1).
+const MaxCRNTI = 0xffef
+
+// ErrNoF1 is returned for the UEs of a gNB-DU that has not set up the F1.
+var ErrNoF1 = status.Error(codes.Unavailable, "f1: not set up with the gNB-CU")
+
+// Middleware describes a service (as opposed to endpoint) middleware.
+type Middleware func(GnbduService) GnbduService
+
+// GnbduService describes the gNB-DU: it serves the F1 to its gNB-CU and
+// stands in for the radio towards the UEs of its cells.
+type GnbduService interface {
+	f1.DU
+	// F1Setup sets up the F1 with the gNB-CU, announcing the cells.
+	F1Setup(ctx context.Context) (rs f1.SetupResponse, err error)
+	// Access runs the initial access of a UE on the cell nci, passing its
+	// RRC Setup Request to the gNB-CU.
+	Access(ctx context.Context, nci uint64) (u UE, err error)
+	// Uplink passes an RRC message of the UE on SRB1 to the gNB-CU.
+	Uplink(ctx context.Context, duUEID uint32, rrc string) (err error)
+	// UE returns the context of the UE.
+	UE(ctx context.Context, duUEID uint32) (u UE, err error)
+	// Capability returns the radio capability container of the UE,
+	// fetched from the gNB-CU the first time it is needed.
+	Capability(ctx context.Context, duUEID uint32) (container []byte, err error)
+	// ActiveUEs returns the number of UE contexts of every cell with any.
+	ActiveUEs(ctx context.Context) (ues map[uint64]int, err error)
+}
+
+// UE is the context of a UE in the gNB-DU.
+type UE struct {
+	DUUEID uint32   `json:"gnb_du_ue_f1ap_id"`
+	CUUEID uint32   `json:"gnb_cu_ue_f1ap_id"`
+	CRNTI  uint32   `json:"c_rnti"`
+	NCI    uint64   `json:"nci"`
+	DRBs   []uint32 `json:"drb_ids"`
+	// Downlink are the RRC messages the gNB-CU sent the UE, oldest first.
+	Downlink []string `json:"downlink"`
+	// capability is the radio capability container of the UE, once
+	// fetched.
+	capability []byte
+}
+
+func (u *UE) copy() UE {
+	c := *u
+	c.DRBs = append([]uint32(nil), u.DRBs...)
+	c.Downlink = append([]string(nil), u.Downlink...)
+	return c
+}
+
+// the concrete implementation of service interface
+type stubGnbduService struct {
+	id      uint64
+	name    string
+	address string
+	cu      f1.CU
+	cells   *cell.Manager
+	prbs    int
+	logger  log.Logger
+
+	mtx    sync.Mutex
+	active map[uint64]bool // nil until the F1 is set up
+	ues    map[uint32]*UE
+	next   uint32
+}
+
+// New return a new instance of the service.
+// If you want to add service middleware this is the place to put them.
+// The gNB-DU id, called name, serves the F1 at address to cu and the UEs of
+// cells. An access holds prbs resource blocks of its cell.
+func New(id uint64, name, address string, cu f1.CU, cells *cell.Manager, prbs int, logger log.Logger) (s GnbduService) {
+	var svc GnbduService
+	{
+		svc = &stubGnbduService{
+			id:      id,
+			name:    name,
+			address: address,
+			cu:      cu,
+			cells:   cells,
+			prbs:    prbs,
+			logger:  logger,
+			ues:     make(map[uint32]*UE),
+		}
+		svc = LoggingMiddleware(logger)(svc)
+	}
+	return svc
+}
+
+// Implement the business logic of F1Setup. The UE contexts do not survive
+// a new F1.
+func (du *stubGnbduService) F1Setup(ctx context.Context) (rs f1.SetupResponse, err error) {
+	req := f1.SetupRequest{DUID: du.id, DUName: du.name, Address: du.address}
+	for _, s := range du.cells.List() {
+		req.Cells = append(req.Cells, s.Cell)
+	}
+	rs, err = du.cu.F1Setup(ctx, req)
+	if err != nil {
+		return rs, err
+	}
+	active := make(map[uint64]bool, len(rs.Activated))
+	for _, nci := range rs.Activated {
+		active[nci] = true
+	}
+	du.mtx.Lock()
+	defer du.mtx.Unlock()
+	du.active = active
+	du.ues = make(map[uint32]*UE)
+	return rs, nil
+}
+
+// Implement the business logic of Access. The access holds the PRBs of the
+// cell until the gNB-CU has answered.
+func (du *stubGnbduService) Access(ctx context.Context, nci uint64) (u UE, err error) {
+	release, err := du.cells.Admit(ctx, nci, du.prbs)
+	if err != nil {
+		return u, err
+	}
+	defer release()
+	ue, err := du.newUE(nci)
+	if err != nil {
+		return u, err
+	}
+	cuUEID, err := du.cu.InitialULRRCMessageTransfer(ctx, f1.InitialULRRCMessage{
+		DUID:   du.id,
+		DUUEID: ue.DUUEID,
+		NCI:    nci,
+		CRNTI:  ue.CRNTI,
+		RRC:    []byte(f1.RRCSetupRequest),
+	})
+	du.mtx.Lock()
+	defer du.mtx.Unlock()
+	if err != nil {
+		delete(du.ues, ue.DUUEID)
+		return u, err
+	}
+	ue.CUUEID = cuUEID
+	return ue.copy(), nil
+}
+
+// Implement the business logic of Uplink.
+func (du *stubGnbduService) Uplink(ctx context.Context, duUEID uint32, rrc string) (err error) {
+	du.mtx.Lock()
+	ue, ok := du.ues[duUEID]
+	var cuUEID uint32
+	if ok {
+		cuUEID = ue.CUUEID
+	}
+	du.mtx.Unlock()
+	if !ok || cuUEID == 0 {
+		return f1.ErrUnknownUE
+	}
+	return du.cu.ULRRCMessageTransfer(ctx, f1.RRCMessage{CUUEID: cuUEID, DUUEID: duUEID, SRB: f1.SRB1, RRC: []byte(rrc)})
+}
+
+// Implement the business logic of UE.
+func (du *stubGnbduService) UE(ctx context.Context, duUEID uint32) (u UE, err error) {
+	du.mtx.Lock()
+	defer du.mtx.Unlock()
+	ue, ok := du.ues[duUEID]
+	if !ok {
+		return u, f1.ErrUnknownUE
+	}
+	return ue.copy(), nil
+}
+
+// Implement the business logic of Capability. The gNB-CU keeps the radio
+// capability of the UE, and the gNB-DU asks for it only when it needs it,
+// rather than with every UE Context Setup.
+func (du *stubGnbduService) Capability(ctx context.Context, duUEID uint32) (container []byte, err error) {
+	du.mtx.Lock()
+	ue, ok := du.ues[duUEID]
+	var cuUEID uint32
+	if ok {
+		cuUEID, container = ue.CUUEID, ue.capability
+	}
+	du.mtx.Unlock()
+	if !ok || cuUEID == 0 {
+		return nil, f1.ErrUnknownUE
+	}
+	if container != nil {
+		return container, nil
+	}
+	container, err = du.cu.UERadioCapability(ctx, f1.UERadioCapabilityRequest{CUUEID: cuUEID, DUUEID: duUEID})
+	if err != nil {
+		return nil, err
+	}
+	du.mtx.Lock()
+	defer du.mtx.Unlock()
+	if ue, ok := du.ues[duUEID]; ok && ue.CUUEID == cuUEID {
+		ue.capability = container
+	}
+	return container, nil
+}
+
+// Implement the business logic of ActiveUEs.
+func (du *stubGnbduService) ActiveUEs(ctx context.Context) (ues map[uint64]int, err error) {
+	du.mtx.Lock()
+	defer du.mtx.Unlock()
+	ues = make(map[uint64]int)
+	for _, ue := range du.ues {
+		ues[ue.NCI]++
+	}
+	return ues, nil
+}
+
+// Implement the business logic of UEContextSetup. The DRBs are set up on a
+// cell that is still enabled, and the RRC container goes to the UE. A request
+// without a gNB-DU UE F1AP ID creates the context of a UE handed over to the
+// cell.
+func (du *stubGnbduService) UEContextSetup(ctx context.Context, req f1.UEContextSetupRequest) (rs f1.UEContextSetupResponse, err error) {
+	c, _, err := du.cells.Get(req.NCI)
+	if err != nil {
+		return rs, err
+	}
+	if !c.Enabled {
+		return rs, cell.ErrDisabled
+	}
+	du.mtx.Lock()
+	defer du.mtx.Unlock()
+	var ue *UE
+	if req.DUUEID == 0 {
+		if req.CUUEID == 0 {
+			return rs, status.Error(codes.InvalidArgument, "f1: missing gNB-CU UE F1AP ID")
+		}
+		if ue, err = du.add(req.NCI); err != nil {
+			return rs, err
+		}
+		ue.CUUEID = req.CUUEID
+	} else if ue, err = du.ue(req.CUUEID, req.DUUEID); err != nil {
+		return rs, err
+	}
+	if ue.NCI != req.NCI {
+		return rs, status.Errorf(codes.InvalidArgument, "f1: UE is on cell %09x, not %09x", ue.NCI, req.NCI)
+	}
+	ue.DRBs = append(ue.DRBs, req.DRBs...)
+	if len(req.RRC) > 0 {
+		ue.Downlink = append(ue.Downlink, string(req.RRC))
+	}
+	return f1.UEContextSetupResponse{DUUEID: ue.DUUEID, CRNTI: ue.CRNTI, DRBs: req.DRBs}, nil
+}
+
+// Implement the business logic of DLRRCMessageTransfer. The RRC message goes
+// to the UE.
+func (du *stubGnbduService) DLRRCMessageTransfer(ctx context.Context, m f1.RRCMessage) (err error) {
+	du.mtx.Lock()
+	defer du.mtx.Unlock()
+	ue, err := du.ue(m.CUUEID, m.DUUEID)
+	if err != nil {
+		return err
+	}
+	ue.Downlink = append(ue.Downlink, string(m.RRC))
+	return nil
+}
+
+// Implement the business logic of UEContextRelease. The UE is gone from
+// the gNB-DU, as after a handover to another one.
+func (du *stubGnbduService) UEContextRelease(ctx context.Context, m f1.UEContextRelease) (err error) {
+	du.mtx.Lock()
+	defer du.mtx.Unlock()
+	if _, err := du.ue(m.CUUEID, m.DUUEID); err != nil {
+		return err
+	}
+	delete(du.ues, m.DUUEID)
+	return nil
+}
+
+// newUE adds the context of a UE on the cell nci, with a free gNB-DU UE F1AP
+// ID and C-RNTI.
+func (du *stubGnbduService) newUE(nci uint64) (*UE, error) {
+	du.mtx.Lock()
+	defer du.mtx.Unlock()
+	return du.add(nci)
+}
+
+// add is newUE, the lock held.
+func (du *stubGnbduService) add(nci uint64) (*UE, error) {
+	if du.active == nil {
+		return nil, ErrNoF1
+	}
+	if !du.active[nci] {
+		return nil, f1.ErrCellNotActive
+	}
+	if len(du.ues) >= MaxCRNTI {
+		return nil, status.Error(codes.ResourceExhausted, "f1: no C-RNTI left")
+	}
+	for {
+		du.next = du.next%MaxCRNTI + 1
+		if _, used := du.ues[du.next]; !used {
+			break
+		}
+	}
+	// the C-RNTI doubles as the gNB-DU UE F1AP ID
+	ue := &UE{DUUEID: du.next, CRNTI: du.next, NCI: nci}
+	du.ues[ue.DUUEID] = ue
+	return ue, nil
+}
+
+// ue returns the UE context of the F1AP IDs, the lock held. The gNB-CU UE
+// F1AP ID is learnt from the first message of the gNB-CU.
+func (du *stubGnbduService) ue(cuUEID, duUEID uint32) (*UE, error) {
+	ue, ok := du.ues[duUEID]
+	if !ok {
+		return nil, f1.ErrUnknownUE
+	}
+	if ue.CUUEID == 0 {
+		ue.CUUEID = cuUEID
+	}
+	if ue.CUUEID != cuUEID {
+		return nil, f1.ErrUnknownUE
+	}
+	return ue, nil
+}
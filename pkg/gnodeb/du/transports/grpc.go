@@ -0,0 +1,218 @@
+package transports
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/tracing/opentracing"
+	"github.com/go-kit/kit/tracing/zipkin"
+	grpctransport "github.com/go-kit/kit/transport/grpc"
+	stdopentracing "github.com/opentracing/opentracing-go"
+	stdzipkin "github.com/openzipkin/zipkin-go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/miki-tnt/sa5g-go-usvc-k8s/pb/gnodeb"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/gnodeb/du/endpoints"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/gnodeb/f1"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/logging"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/meta"
+)
+
+type grpcServer struct {
+	ueContextSetup       grpctransport.Handler `json:""`
+	dlRRCMessageTransfer grpctransport.Handler `json:""`
+	ueContextRelease     grpctransport.Handler `json:""`
+}
+
+func (s *grpcServer) UEContextSetup(ctx context.Context, req *pb.UEContextSetupRequest) (rep *pb.UEContextSetupResponse, err error) {
+	_, rp, err := s.ueContextSetup.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcEncodeError(err)
+	}
+	rep = rp.(*pb.UEContextSetupResponse)
+	return rep, nil
+}
+
+func (s *grpcServer) DLRRCMessageTransfer(ctx context.Context, req *pb.RRCMessageTransferRequest) (rep *pb.RRCMessageTransferReply, err error) {
+	_, rp, err := s.dlRRCMessageTransfer.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcEncodeError(err)
+	}
+	rep = rp.(*pb.RRCMessageTransferReply)
+	return rep, nil
+}
+
+func (s *grpcServer) UEContextRelease(ctx context.Context, req *pb.UEContextReleaseCommand) (rep *pb.UEContextReleaseComplete, err error) {
+	_, rp, err := s.ueContextRelease.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcEncodeError(err)
+	}
+	rep = rp.(*pb.UEContextReleaseComplete)
+	return rep, nil
+}
+
+// MakeGRPCServer makes the F1 endpoints available as a gRPC server.
+func MakeGRPCServer(endpoints endpoints.Endpoints, otTracer stdopentracing.Tracer, zipkinTracer *stdzipkin.Tracer, logger log.Logger) (req pb.F1DUServer) {
+	zipkinServer := zipkin.GRPCServerTrace(zipkinTracer)
+
+	options := []grpctransport.ServerOption{
+		grpctransport.ServerErrorHandler(logging.ErrorHandler(logger)),
+		grpctransport.ServerBefore(meta.GRPCToContext()),
+		zipkinServer,
+	}
+
+	return &grpcServer{
+		ueContextSetup: grpctransport.NewServer(
+			endpoints.UEContextSetupEndpoint,
+			decodeGRPCUEContextSetupRequest,
+			encodeGRPCUEContextSetupResponse,
+			append(options, grpctransport.ServerBefore(opentracing.GRPCToContext(otTracer, "UEContextSetup", logger)))...,
+		),
+
+		dlRRCMessageTransfer: grpctransport.NewServer(
+			endpoints.DLRRCMessageTransferEndpoint,
+			decodeGRPCRRCMessageTransferRequest,
+			encodeGRPCRRCMessageTransferResponse,
+			append(options, grpctransport.ServerBefore(opentracing.GRPCToContext(otTracer, "DLRRCMessageTransfer", logger)))...,
+		),
+
+		ueContextRelease: grpctransport.NewServer(
+			endpoints.UEContextReleaseEndpoint,
+			decodeGRPCUEContextReleaseRequest,
+			encodeGRPCUEContextReleaseResponse,
+			append(options, grpctransport.ServerBefore(opentracing.GRPCToContext(otTracer, "UEContextRelease", logger)))...,
+		),
+	}
+}
+
+// decodeGRPCUEContextSetupRequest is a transport/grpc.DecodeRequestFunc that converts a
+// gRPC request to a user-domain request. Primarily useful in a server.
+func decodeGRPCUEContextSetupRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pb.UEContextSetupRequest)
+	return f1.UEContextSetupRequest{
+		CUUEID: req.GnbCuUeF1ApId,
+		DUUEID: req.GnbDuUeF1ApId,
+		NCI:    req.Nci,
+		DRBs:   req.DrbIds,
+		RRC:    req.RrcContainer,
+	}, nil
+}
+
+// encodeGRPCUEContextSetupResponse is a transport/grpc.EncodeResponseFunc that converts a
+// user-domain response to a gRPC reply. Primarily useful in a server.
+func encodeGRPCUEContextSetupResponse(_ context.Context, grpcReply interface{}) (res interface{}, err error) {
+	reply := grpcReply.(f1.UEContextSetupResponse)
+	return &pb.UEContextSetupResponse{GnbDuUeF1ApId: reply.DUUEID, CRnti: reply.CRNTI, DrbIds: reply.DRBs}, nil
+}
+
+// decodeGRPCRRCMessageTransferRequest is a transport/grpc.DecodeRequestFunc that converts a
+// gRPC request to a user-domain request. Primarily useful in a server.
+func decodeGRPCRRCMessageTransferRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pb.RRCMessageTransferRequest)
+	return f1.RRCMessage{CUUEID: req.GnbCuUeF1ApId, DUUEID: req.GnbDuUeF1ApId, SRB: req.SrbId, RRC: req.RrcContainer}, nil
+}
+
+// encodeGRPCRRCMessageTransferResponse is a transport/grpc.EncodeResponseFunc that converts a
+// user-domain response to a gRPC reply. Primarily useful in a server.
+func encodeGRPCRRCMessageTransferResponse(_ context.Context, _ interface{}) (res interface{}, err error) {
+	return &pb.RRCMessageTransferReply{}, nil
+}
+
+// decodeGRPCUEContextReleaseRequest is a transport/grpc.DecodeRequestFunc that converts a
+// gRPC request to a user-domain request. Primarily useful in a server.
+func decodeGRPCUEContextReleaseRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pb.UEContextReleaseCommand)
+	return f1.UEContextRelease{CUUEID: req.GnbCuUeF1ApId, DUUEID: req.GnbDuUeF1ApId}, nil
+}
+
+// encodeGRPCUEContextReleaseResponse is a transport/grpc.EncodeResponseFunc that converts a
+// user-domain response to a gRPC reply. Primarily useful in a server.
+func encodeGRPCUEContextReleaseResponse(_ context.Context, _ interface{}) (res interface{}, err error) {
+	return &pb.UEContextReleaseComplete{}, nil
+}
+
+// NewGRPCClient returns the gNB-DU end of the F1 backed by a gRPC server at
+// the other end of the conn, for the gNB-CU. The caller is responsible for
+// constructing the conn, and eventually closing the underlying transport.
+func NewGRPCClient(conn *grpc.ClientConn, otTracer stdopentracing.Tracer, zipkinTracer *stdzipkin.Tracer, logger log.Logger) f1.DU {
+	zipkinClient := zipkin.GRPCClientTrace(zipkinTracer)
+
+	// global client middlewares
+	options := []grpctransport.ClientOption{
+		zipkinClient,
+		grpctransport.ClientBefore(meta.ContextToGRPC()),
+		grpctransport.ClientBefore(opentracing.ContextToGRPC(otTracer, logger)),
+	}
+
+	client := func(method string, enc grpctransport.EncodeRequestFunc, dec grpctransport.DecodeResponseFunc, reply interface{}) endpoint.Endpoint {
+		e := grpctransport.NewClient(conn, "gnodeb.F1DU", method, enc, dec, reply, options...).Endpoint()
+		return opentracing.TraceClient(otTracer, method)(e)
+	}
+
+	return endpoints.Endpoints{
+		UEContextSetupEndpoint:       client("UEContextSetup", encodeGRPCUEContextSetupRequest, decodeGRPCUEContextSetupResponse, pb.UEContextSetupResponse{}),
+		DLRRCMessageTransferEndpoint: client("DLRRCMessageTransfer", encodeGRPCRRCMessageTransferRequest, decodeGRPCRRCMessageTransferResponse, pb.RRCMessageTransferReply{}),
+		UEContextReleaseEndpoint:     client("UEContextRelease", encodeGRPCUEContextReleaseRequest, decodeGRPCUEContextReleaseResponse, pb.UEContextReleaseComplete{}),
+	}
+}
+
+// encodeGRPCUEContextSetupRequest is a transport/grpc.EncodeRequestFunc that converts a
+// user-domain UEContextSetup request to a gRPC request. Primarily useful in a client.
+func encodeGRPCUEContextSetupRequest(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(f1.UEContextSetupRequest)
+	return &pb.UEContextSetupRequest{
+		GnbCuUeF1ApId: req.CUUEID,
+		GnbDuUeF1ApId: req.DUUEID,
+		Nci:           req.NCI,
+		DrbIds:        req.DRBs,
+		RrcContainer:  req.RRC,
+	}, nil
+}
+
+// decodeGRPCUEContextSetupResponse is a transport/grpc.DecodeResponseFunc that converts a
+// gRPC UEContextSetup reply to a user-domain response. Primarily useful in a client.
+func decodeGRPCUEContextSetupResponse(_ context.Context, grpcReply interface{}) (interface{}, error) {
+	reply := grpcReply.(*pb.UEContextSetupResponse)
+	return f1.UEContextSetupResponse{DUUEID: reply.GnbDuUeF1ApId, CRNTI: reply.CRnti, DRBs: reply.DrbIds}, nil
+}
+
+// encodeGRPCRRCMessageTransferRequest is a transport/grpc.EncodeRequestFunc that converts a
+// user-domain RRC message transfer to a gRPC request. Primarily useful in a client.
+func encodeGRPCRRCMessageTransferRequest(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(f1.RRCMessage)
+	return &pb.RRCMessageTransferRequest{GnbCuUeF1ApId: req.CUUEID, GnbDuUeF1ApId: req.DUUEID, SrbId: req.SRB, RrcContainer: req.RRC}, nil
+}
+
+// decodeGRPCRRCMessageTransferResponse is a transport/grpc.DecodeResponseFunc that converts a
+// gRPC RRC message transfer reply to a user-domain response. Primarily useful in a client.
+func decodeGRPCRRCMessageTransferResponse(_ context.Context, _ interface{}) (interface{}, error) {
+	return endpoints.RRCMessageTransferResponse{}, nil
+}
+
+// encodeGRPCUEContextReleaseRequest is a transport/grpc.EncodeRequestFunc that converts a
+// user-domain UE context release to a gRPC request. Primarily useful in a client.
+func encodeGRPCUEContextReleaseRequest(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(f1.UEContextRelease)
+	return &pb.UEContextReleaseCommand{GnbCuUeF1ApId: req.CUUEID, GnbDuUeF1ApId: req.DUUEID}, nil
+}
+
+// decodeGRPCUEContextReleaseResponse is a transport/grpc.DecodeResponseFunc that converts a
+// gRPC UE context release reply to a user-domain response. Primarily useful in a client.
+func decodeGRPCUEContextReleaseResponse(_ context.Context, _ interface{}) (interface{}, error) {
+	return endpoints.UEContextReleaseResponse{}, nil
+}
+
+func grpcEncodeError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	st, ok := status.FromError(err)
+	if ok {
+		return status.Error(st.Code(), st.Message())
+	}
+	return status.Error(codes.Internal, "internal server error")
+}
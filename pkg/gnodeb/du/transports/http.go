@@ -0,0 +1,131 @@
+package transports
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/tracing/opentracing"
+	"github.com/go-kit/kit/tracing/zipkin"
+	httptransport "github.com/go-kit/kit/transport/http"
+	stdopentracing "github.com/opentracing/opentracing-go"
+	stdzipkin "github.com/openzipkin/zipkin-go"
+	"google.golang.org/grpc/status"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/gnodeb/du/endpoints"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/logging"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/meta"
+)
+
+type errorWrapper struct {
+	Error string `json:"error"`
+}
+
+// NewHTTPHandler returns a handler that makes the endpoints standing in for
+// the radio available on predefined paths. Every method is a POST of a JSON
+// request.
+func NewHTTPHandler(endpoints endpoints.Endpoints, otTracer stdopentracing.Tracer, zipkinTracer *stdzipkin.Tracer, logger log.Logger) http.Handler {
+	zipkinServer := zipkin.HTTPServerTrace(zipkinTracer)
+
+	options := []httptransport.ServerOption{
+		httptransport.ServerErrorEncoder(httpEncodeError),
+		httptransport.ServerErrorHandler(logging.ErrorHandler(logger)),
+		httptransport.ServerBefore(meta.HTTPToContext()),
+		zipkinServer,
+	}
+
+	m := http.NewServeMux()
+	m.Handle("/f1Setup", httptransport.NewServer(
+		endpoints.F1SetupEndpoint,
+		decodeHTTPF1SetupRequest,
+		httptransport.EncodeJSONResponse,
+		append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "F1Setup", logger)))...,
+	))
+	m.Handle("/access", httptransport.NewServer(
+		endpoints.AccessEndpoint,
+		decodeHTTPAccessRequest,
+		httptransport.EncodeJSONResponse,
+		append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "Access", logger)))...,
+	))
+	m.Handle("/uplink", httptransport.NewServer(
+		endpoints.UplinkEndpoint,
+		decodeHTTPUplinkRequest,
+		httptransport.EncodeJSONResponse,
+		append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "Uplink", logger)))...,
+	))
+	m.Handle("/ue", httptransport.NewServer(
+		endpoints.UEEndpoint,
+		decodeHTTPUERequest,
+		httptransport.EncodeJSONResponse,
+		append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "UE", logger)))...,
+	))
+	m.Handle("/capability", httptransport.NewServer(
+		endpoints.CapabilityEndpoint,
+		decodeHTTPCapabilityRequest,
+		httptransport.EncodeJSONResponse,
+		append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "Capability", logger)))...,
+	))
+	return m
+}
+
+// decodeHTTPF1SetupRequest is a transport/http.DecodeRequestFunc that decodes a
+// JSON-encoded request from the HTTP request body. Primarily useful in a server.
+func decodeHTTPF1SetupRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	return endpoints.F1SetupRequest{}, nil
+}
+
+// decodeHTTPAccessRequest is a transport/http.DecodeRequestFunc that decodes a
+// JSON-encoded request from the HTTP request body. Primarily useful in a server.
+func decodeHTTPAccessRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req endpoints.AccessRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	return req, err
+}
+
+// decodeHTTPUplinkRequest is a transport/http.DecodeRequestFunc that decodes a
+// JSON-encoded request from the HTTP request body. Primarily useful in a server.
+func decodeHTTPUplinkRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req endpoints.UplinkRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	return req, err
+}
+
+// decodeHTTPUERequest is a transport/http.DecodeRequestFunc that decodes a
+// JSON-encoded request from the HTTP request body. Primarily useful in a server.
+func decodeHTTPUERequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req endpoints.UERequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	return req, err
+}
+
+// decodeHTTPCapabilityRequest is a transport/http.DecodeRequestFunc that decodes a
+// JSON-encoded request from the HTTP request body. Primarily useful in a server.
+func decodeHTTPCapabilityRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req endpoints.CapabilityRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	return req, err
+}
+
+func httpEncodeError(_ context.Context, err error, w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+
+	st, ok := status.FromError(err)
+	if ok {
+		w.WriteHeader(HTTPStatusFromCode(st.Code()))
+		json.NewEncoder(w).Encode(errorWrapper{Error: st.Message()})
+		return
+	}
+	switch err.(type) {
+	case *json.SyntaxError, *json.UnmarshalTypeError:
+		w.WriteHeader(http.StatusBadRequest)
+	default:
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			w.WriteHeader(http.StatusBadRequest)
+		} else {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}
+	json.NewEncoder(w).Encode(errorWrapper{Error: err.Error()})
+}
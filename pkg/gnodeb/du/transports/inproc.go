@@ -0,0 +1,44 @@
+package transports
+
+import (
+	"github.com/go-kit/kit/log"
+
+	pb "github.com/miki-tnt/sa5g-go-usvc-k8s/pb/gnodeb"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/gnodeb/du/endpoints"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/gnodeb/f1"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/kitx/inproc"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/logging"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/meta"
+)
+
+// MakeInprocServer makes the F1 endpoints of a set of endpoints available
+// to the in-process clients of NewInprocClient, with the codecs and
+// metadata of the gRPC transport. The caller runs its Serve method and
+// eventually closes it.
+func MakeInprocServer(endpoints endpoints.Endpoints, logger log.Logger) *inproc.Server {
+	options := []inproc.ServerOption{
+		inproc.ServerErrorHandler(logging.ErrorHandler(logger)),
+		inproc.ServerErrorEncoder(grpcEncodeError),
+		inproc.ServerBefore(meta.GRPCToContext()),
+	}
+
+	s := inproc.NewServer()
+	s.Handle("UEContextSetup", endpoints.UEContextSetupEndpoint, decodeGRPCUEContextSetupRequest, encodeGRPCUEContextSetupResponse, pb.UEContextSetupRequest{}, options...)
+	s.Handle("DLRRCMessageTransfer", endpoints.DLRRCMessageTransferEndpoint, decodeGRPCRRCMessageTransferRequest, encodeGRPCRRCMessageTransferResponse, pb.RRCMessageTransferRequest{}, options...)
+	s.Handle("UEContextRelease", endpoints.UEContextReleaseEndpoint, decodeGRPCUEContextReleaseRequest, encodeGRPCUEContextReleaseResponse, pb.UEContextReleaseCommand{}, options...)
+	return s
+}
+
+// NewInprocClient returns the gNB-DU end of the F1 calling the server of
+// MakeInprocServer, for a gNB-CU in the same process.
+func NewInprocClient(s *inproc.Server) f1.DU {
+	options := []inproc.ClientOption{
+		inproc.ClientBefore(meta.ContextToGRPC()),
+	}
+
+	return endpoints.Endpoints{
+		UEContextSetupEndpoint:       inproc.NewClient(s, "UEContextSetup", encodeGRPCUEContextSetupRequest, decodeGRPCUEContextSetupResponse, pb.UEContextSetupResponse{}, options...).Endpoint(),
+		DLRRCMessageTransferEndpoint: inproc.NewClient(s, "DLRRCMessageTransfer", encodeGRPCRRCMessageTransferRequest, decodeGRPCRRCMessageTransferResponse, pb.RRCMessageTransferReply{}, options...).Endpoint(),
+		UEContextReleaseEndpoint:     inproc.NewClient(s, "UEContextRelease", encodeGRPCUEContextReleaseRequest, decodeGRPCUEContextReleaseResponse, pb.UEContextReleaseComplete{}, options...).Endpoint(),
+	}
+}
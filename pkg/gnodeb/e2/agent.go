@@ -0,0 +1,240 @@
+// Package e2 is an E2 node of the gNB (O-RAN E2AP, E2SM-KPM) for
+// experimenting with a near-RT RIC, rendered over gRPC. The Agent connects to
+// the RIC, reports the PRB usage, active UEs and RACH attempts of the cells
+// every period, and applies the policies of the RIC: admission thresholds
+// enforced by a hook of the cell.Manager.
+package e2
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+
+	pb "github.com/miki-tnt/sa5g-go-usvc-k8s/pb/gnodeb"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/gnodeb/cell"
+)
+
+// ActiveUEs returns the number of UE contexts of every cell with any.
+type ActiveUEs func(ctx context.Context) (map[uint64]int, error)
+
+// Option sets an optional parameter of an Agent.
+type Option func(*Agent)
+
+// Interval sets the period of the KPM indications, 10s by default.
+func Interval(d time.Duration) Option {
+	return func(a *Agent) { a.interval = d }
+}
+
+// Retry sets the wait before connecting again to the near-RT RIC, 5s by
+// default.
+func Retry(d time.Duration) Option {
+	return func(a *Agent) { a.retry = d }
+}
+
+// Agent is the E2 node of a gNB: it reports the KPMs of its cells to the
+// near-RT RIC and applies the policies the RIC sends back to the admission
+// thresholds.
+type Agent struct {
+	nodeID     string
+	cells      *cell.Manager
+	ues        ActiveUEs
+	thresholds *Thresholds
+	interval   time.Duration
+	retry      time.Duration
+	logger     log.Logger
+
+	mtx         sync.Mutex
+	connected   bool
+	indications int64
+	policies    int64
+	last        map[uint64]cell.Usage // the usage at the last indication
+}
+
+// NewAgent returns the Agent of the E2 node nodeID, with the cells, their
+// UEs and the thresholds the policies set.
+func NewAgent(nodeID string, cells *cell.Manager, ues ActiveUEs, thresholds *Thresholds, logger log.Logger, options ...Option) *Agent {
+	a := &Agent{
+		nodeID:     nodeID,
+		cells:      cells,
+		ues:        ues,
+		thresholds: thresholds,
+		interval:   10 * time.Second,
+		retry:      5 * time.Second,
+		logger:     logger,
+		last:       map[uint64]cell.Usage{},
+	}
+	for _, option := range options {
+		option(a)
+	}
+	return a
+}
+
+// Run connects to the near-RT RIC, again every retry after the connection
+// fails, until ctx is done.
+func (a *Agent) Run(ctx context.Context, client pb.E2Client) {
+	for {
+		err := a.connect(ctx, client)
+		a.setConnected(false)
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+		level.Warn(a.logger).Log("e2", "connect", "retry", a.retry, "err", err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(a.retry):
+		}
+	}
+}
+
+// Status is the state of an Agent.
+type Status struct {
+	Connected   bool               `json:"connected"`
+	Indications int64              `json:"indications"`
+	Policies    int64              `json:"policies"`
+	Thresholds  map[uint64]float64 `json:"thresholds"`
+}
+
+// Status returns the state of the agent.
+func (a *Agent) Status() Status {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	return Status{
+		Connected:   a.connected,
+		Indications: a.indications,
+		Policies:    a.policies,
+		Thresholds:  a.thresholds.List(),
+	}
+}
+
+// connect runs a connection: the E2 setup, then the indications every
+// interval and the policies as they come, until either end fails.
+func (a *Agent) connect(ctx context.Context, client pb.E2Client) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := client.Connect(ctx)
+	if err != nil {
+		return err
+	}
+	// the sends of the indications and of the policy results are serialized
+	var mtx sync.Mutex
+	send := func(m *pb.E2NodeMessage) error {
+		mtx.Lock()
+		defer mtx.Unlock()
+		return stream.Send(m)
+	}
+	setup := &pb.E2SetupRequest{NodeId: a.nodeID}
+	for _, s := range a.cells.List() {
+		setup.Cells = append(setup.Cells, s.NCI)
+	}
+	if err := send(&pb.E2NodeMessage{Message: &pb.E2NodeMessage_Setup{Setup: setup}}); err != nil {
+		return err
+	}
+	a.setConnected(true)
+	level.Info(a.logger).Log("e2", "setup", "node_id", a.nodeID, "cells", len(setup.Cells))
+
+	errc := make(chan error, 1)
+	go func() {
+		for {
+			m, err := stream.Recv()
+			if err != nil {
+				errc <- err
+				return
+			}
+			if p := m.GetPolicy(); p != nil {
+				r := &pb.PolicyResult{PolicyId: p.PolicyId}
+				if err := a.apply(p); err != nil {
+					r.Err = err.Error()
+				}
+				if err := send(&pb.E2NodeMessage{Message: &pb.E2NodeMessage_PolicyResult{PolicyResult: r}}); err != nil {
+					errc <- err
+					return
+				}
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(a.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			ind, err := a.indication(ctx)
+			if err != nil {
+				level.Warn(a.logger).Log("e2", "indication", "err", err)
+				continue
+			}
+			if err := send(&pb.E2NodeMessage{Message: &pb.E2NodeMessage_Indication{Indication: ind}}); err != nil {
+				return err
+			}
+		case err := <-errc:
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// apply sets the admission threshold of a policy.
+func (a *Agent) apply(p *pb.Policy) error {
+	if p.Nci != 0 {
+		if _, _, err := a.cells.Get(p.Nci); err != nil {
+			return err
+		}
+	}
+	if err := a.thresholds.Set(p.Nci, p.MaxPrbUtilization); err != nil {
+		level.Warn(a.logger).Log("e2", "policy", "policy_id", p.PolicyId, "err", err)
+		return err
+	}
+	a.mtx.Lock()
+	a.policies++
+	a.mtx.Unlock()
+	level.Info(a.logger).Log("e2", "policy", "policy_id", p.PolicyId, "nci", p.Nci, "max_prb_utilization", p.MaxPrbUtilization)
+	return nil
+}
+
+// indication returns the KPMs of the cells since the last indication.
+func (a *Agent) indication(ctx context.Context) (*pb.KPMIndication, error) {
+	ues, err := a.ues(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ind := &pb.KPMIndication{
+		NodeId:    a.nodeID,
+		Timestamp: time.Now().UnixNano() / int64(time.Millisecond),
+		PeriodMs:  int64(a.interval / time.Millisecond),
+	}
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	for _, s := range a.cells.List() {
+		last := a.last[s.NCI]
+		// the counters start again with a cell added again
+		if s.Usage.Admitted < last.Admitted || s.Usage.Rejected < last.Rejected {
+			last = cell.Usage{}
+		}
+		failures := s.Usage.Rejected - last.Rejected
+		ind.Cells = append(ind.Cells, &pb.CellKPM{
+			Nci:            s.NCI,
+			Prbs:           int32(s.Usage.PRBs),
+			PrbsUsed:       int32(s.Usage.Used),
+			PrbUtilization: s.Usage.Utilization(),
+			ActiveUes:      int32(ues[s.NCI]),
+			RachAttempts:   s.Usage.Admitted - last.Admitted + failures,
+			RachFailures:   failures,
+		})
+		a.last[s.NCI] = s.Usage
+	}
+	a.indications++
+	return ind, nil
+}
+
+func (a *Agent) setConnected(connected bool) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	a.connected = connected
+}
@@ -0,0 +1,72 @@
+package e2
+
+import (
+	"context"
+	"sync"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/gnodeb/cell"
+)
+
+// ErrInvalidThreshold is returned for a PRB utilization threshold out of
+// [0, 1].
+var ErrInvalidThreshold = status.Error(codes.InvalidArgument, "e2: max PRB utilization out of [0, 1]")
+
+// Thresholds are the admission thresholds set by the near-RT RIC: the
+// highest PRB utilization of a cell the admissions may take it to. The
+// threshold of NCI 0 applies to the cells without their own.
+type Thresholds struct {
+	mtx sync.RWMutex
+	max map[uint64]float64
+}
+
+// NewThresholds returns Thresholds admitting everything.
+func NewThresholds() *Thresholds {
+	return &Thresholds{max: map[uint64]float64{}}
+}
+
+// Set sets the threshold of the cell nci to max, in (0, 1]. A zero max
+// removes the threshold.
+func (t *Thresholds) Set(nci uint64, max float64) error {
+	if max < 0 || max > 1 {
+		return ErrInvalidThreshold
+	}
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	if max == 0 {
+		delete(t.max, nci)
+		return nil
+	}
+	t.max[nci] = max
+	return nil
+}
+
+// List returns the thresholds, by NCI.
+func (t *Thresholds) List() map[uint64]float64 {
+	t.mtx.RLock()
+	defer t.mtx.RUnlock()
+	list := make(map[uint64]float64, len(t.max))
+	for nci, max := range t.max {
+		list[nci] = max
+	}
+	return list
+}
+
+// Hook returns the admission hook of a cell.Manager enforcing the
+// thresholds.
+func (t *Thresholds) Hook() cell.Hook {
+	return func(ctx context.Context, c cell.Cell, u cell.Usage, prbs int) error {
+		t.mtx.RLock()
+		max, ok := t.max[c.NCI]
+		if !ok {
+			max, ok = t.max[0]
+		}
+		t.mtx.RUnlock()
+		if !ok {
+			return nil
+		}
+		return cell.MaxUtilization(max)(ctx, c, u, prbs)
+	}
+}
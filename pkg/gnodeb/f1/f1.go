@@ -0,0 +1,205 @@
+// Package f1 is the F1 interface between the gNB-CU and the gNB-DUs of a
+// split gNB (TS 38.473), rendered over gRPC. A gNB-DU sets up the F1 with its
+// gNB-CU, announcing the cells it serves, and carries the RRC messages of its
+// UEs to and from the gNB-CU. The gNB-CU terminates RRC and sets up the
+// contexts of the UEs in the gNB-DU.
+//
+// CU and DU are the elementary procedures each end serves to the other: the
+// gNB-CU service implements CU and a client of the gNB-DU service, DU.
+package f1
+
+import (
+	"bytes"
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/bufpool"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/gnodeb/cell"
+)
+
+// The RRC messages of the connection setup and release, of the UE
+// capability transfer and of the handover (TS 38.331). Without an ASN.1
+// codec, an RRC container carries the name of its message, followed by its
+// IEs in JSON when it has any (see RRC).
+const (
+	RRCSetupRequest            = "RRCSetupRequest"
+	RRCSetup                   = "RRCSetup"
+	RRCSetupComplete           = "RRCSetupComplete"
+	RRCRelease                 = "RRCRelease"
+	UECapabilityEnquiry        = "UECapabilityEnquiry"
+	UECapabilityInformation    = "UECapabilityInformation"
+	RRCReconfiguration         = "RRCReconfiguration"
+	RRCReconfigurationComplete = "RRCReconfigurationComplete"
+	MeasurementReport          = "MeasurementReport"
+)
+
+// The signalling radio bearers the RRC messages are carried on.
+const (
+	SRB0 uint32 = 0
+	SRB1 uint32 = 1
+)
+
+var (
+	// ErrUnknownDU is returned for a gNB-DU that has not set up the F1.
+	ErrUnknownDU = status.Error(codes.NotFound, "f1: unknown gNB-DU")
+	// ErrUnknownUE is returned for the F1AP IDs of no UE context.
+	ErrUnknownUE = status.Error(codes.NotFound, "f1: unknown UE context")
+	// ErrCellNotActive is returned for a cell the gNB-CU has not activated.
+	ErrCellNotActive = status.Error(codes.FailedPrecondition, "f1: cell not active")
+	// ErrNoCapability is returned for a UE whose radio capability the
+	// gNB-CU does not have yet.
+	ErrNoCapability = status.Error(codes.NotFound, "f1: no UE radio capability")
+)
+
+// RRC returns the RRC container of the message name, with the IEs ies when
+// they are not nil, such as
+//
+//	MeasurementReport {"serving":{"nci":4096,"rsrp":-95.5}}
+func RRC(name string, ies interface{}) []byte {
+	return AppendRRC(nil, name, ies)
+}
+
+// AppendRRC appends the RRC container of the message name, with the IEs
+// ies, to dst, and returns the extended buffer, as n2.AppendNAS does.
+func AppendRRC(dst []byte, name string, ies interface{}) []byte {
+	if ies == nil {
+		return append(dst, name...)
+	}
+	b := bufpool.Get()
+	defer bufpool.Put(b)
+	b.WriteString(name)
+	b.WriteByte(' ')
+	if err := b.JSON(ies); err != nil {
+		panic(err)
+	}
+	return append(dst, b.Bytes()...)
+}
+
+// ParseRRC splits the RRC container rrc into the name of its message and
+// its IEs, nil when there are none.
+func ParseRRC(rrc []byte) (name string, ies []byte) {
+	if i := bytes.IndexByte(rrc, ' '); i >= 0 {
+		return string(rrc[:i]), rrc[i+1:]
+	}
+	return string(rrc), nil
+}
+
+// MeasResults are the IEs of a MeasurementReport: the RSRP, in dBm, of the
+// serving cell and of the neighbour cells the UE hears, best first.
+type MeasResults struct {
+	Serving    CellMeas   `json:"serving"`
+	Neighbours []CellMeas `json:"neighbours,omitempty"`
+}
+
+// SetupCompleteIEs are the IEs of an RRCSetupComplete: the 5G-S-TMSI of a
+// UE registered with the network, as 12 hexadecimal digits (TS 23.003
+// 2.11). The gNB-CU keeps the radio capability of such a UE across its
+// connections, and enquires it only the first time.
+type SetupCompleteIEs struct {
+	STMSI string `json:"ng_5g_s_tmsi,omitempty"`
+}
+
+// UECapabilityInformationIEs are the IEs of a UECapabilityInformation: the
+// UE radio capability container, opaque to the gNB-CU.
+type UECapabilityInformationIEs struct {
+	Container []byte `json:"ue_capability_rat_container_list"`
+}
+
+// CellMeas is the measurement of a cell.
+type CellMeas struct {
+	NCI  uint64  `json:"nci"`
+	RSRP float64 `json:"rsrp"`
+}
+
+// ReconfigurationWithSync are the IEs of the RRCReconfiguration ordering a
+// handover: the target cell and the C-RNTI of the UE in it.
+type ReconfigurationWithSync struct {
+	NCI   uint64 `json:"nci"`
+	CRNTI uint32 `json:"c_rnti"`
+}
+
+// SetupRequest is the F1 Setup Request of a gNB-DU.
+type SetupRequest struct {
+	DUID   uint64 `json:"gnb_du_id"`
+	DUName string `json:"gnb_du_name"`
+	// Address is where the gNB-DU serves its end of the F1.
+	Address string      `json:"address"`
+	Cells   []cell.Cell `json:"served_cells"`
+}
+
+// SetupResponse is the F1 Setup Response of the gNB-CU.
+type SetupResponse struct {
+	CUName    string   `json:"gnb_cu_name"`
+	Activated []uint64 `json:"activated_cells"`
+}
+
+// InitialULRRCMessage is the Initial UL RRC Message Transfer of the first
+// RRC message of a UE, on SRB0.
+type InitialULRRCMessage struct {
+	DUID   uint64 `json:"gnb_du_id"`
+	DUUEID uint32 `json:"gnb_du_ue_f1ap_id"`
+	NCI    uint64 `json:"nci"`
+	CRNTI  uint32 `json:"c_rnti"`
+	RRC    []byte `json:"rrc_container"`
+}
+
+// RRCMessage is the UL or DL RRC Message Transfer of an RRC message of a UE.
+type RRCMessage struct {
+	CUUEID uint32 `json:"gnb_cu_ue_f1ap_id"`
+	DUUEID uint32 `json:"gnb_du_ue_f1ap_id"`
+	SRB    uint32 `json:"srb_id"`
+	RRC    []byte `json:"rrc_container"`
+}
+
+// UEContextSetupRequest asks the gNB-DU to set up the context of a UE and
+// its DRBs, and to pass RRC on to the UE. Without a gNB-DU UE F1AP ID, the
+// gNB-DU creates the context, as for the target of a handover.
+type UEContextSetupRequest struct {
+	CUUEID uint32   `json:"gnb_cu_ue_f1ap_id"`
+	DUUEID uint32   `json:"gnb_du_ue_f1ap_id"`
+	NCI    uint64   `json:"nci"`
+	DRBs   []uint32 `json:"drb_ids"`
+	RRC    []byte   `json:"rrc_container"`
+}
+
+// UEContextSetupResponse lists the DRBs the gNB-DU set up, along with the
+// C-RNTI of the UE.
+type UEContextSetupResponse struct {
+	DUUEID uint32   `json:"gnb_du_ue_f1ap_id"`
+	CRNTI  uint32   `json:"c_rnti"`
+	DRBs   []uint32 `json:"drb_ids"`
+}
+
+// UEContextRelease is the UE Context Release Command of the gNB-CU, which
+// releases the context of a UE in the gNB-DU.
+type UEContextRelease struct {
+	CUUEID uint32 `json:"gnb_cu_ue_f1ap_id"`
+	DUUEID uint32 `json:"gnb_du_ue_f1ap_id"`
+}
+
+// UERadioCapabilityRequest asks the gNB-CU for the radio capability of a
+// UE, which the gNB-DU fetches when it needs it rather than with every UE
+// Context Setup.
+type UERadioCapabilityRequest struct {
+	CUUEID uint32 `json:"gnb_cu_ue_f1ap_id"`
+	DUUEID uint32 `json:"gnb_du_ue_f1ap_id"`
+}
+
+//go:generate mockgen -destination=../../testutil/mocks/f1.go -package=mocks github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/gnodeb/f1 CU,DU
+
+// CU is the gNB-CU end of the F1.
+type CU interface {
+	F1Setup(ctx context.Context, req SetupRequest) (SetupResponse, error)
+	InitialULRRCMessageTransfer(ctx context.Context, m InitialULRRCMessage) (cuUEID uint32, err error)
+	ULRRCMessageTransfer(ctx context.Context, m RRCMessage) error
+	UERadioCapability(ctx context.Context, req UERadioCapabilityRequest) (container []byte, err error)
+}
+
+// DU is the gNB-DU end of the F1.
+type DU interface {
+	UEContextSetup(ctx context.Context, req UEContextSetupRequest) (UEContextSetupResponse, error)
+	DLRRCMessageTransfer(ctx context.Context, m RRCMessage) error
+	UEContextRelease(ctx context.Context, m UEContextRelease) error
+}
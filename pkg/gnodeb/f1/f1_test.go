@@ -0,0 +1,26 @@
+package f1
+
+import "testing"
+
+// measurement are the IEs of the RRC container of the benchmarks, a
+// Measurement Report with two neighbour cells.
+var measurement = MeasResults{
+	Serving:    CellMeas{NCI: 4096, RSRP: -95.5},
+	Neighbours: []CellMeas{{NCI: 4097, RSRP: -99}, {NCI: 4098, RSRP: -104.5}},
+}
+
+func BenchmarkAppendRRC(b *testing.B) {
+	b.Run("new", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			RRC(MeasurementReport, measurement)
+		}
+	})
+	b.Run("reused", func(b *testing.B) {
+		b.ReportAllocs()
+		var rrc []byte
+		for i := 0; i < b.N; i++ {
+			rrc = AppendRRC(rrc[:0], MeasurementReport, measurement)
+		}
+	})
+}
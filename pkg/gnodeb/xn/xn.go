@@ -0,0 +1,104 @@
+// Package xn is the Xn interface between the gNB-CUs of neighbour gNBs (TS
+// 38.423), rendered over gRPC, for the handovers of their UEs from one gNB
+// to the other. The source gNB-CU asks the gNB-CU serving the target cell to
+// admit the UE, and sends the UE the RRC Reconfiguration the target built.
+// The target tells the source to release the UE once it reached the target
+// cell, or the source cancels the handover.
+//
+// The gNBs have no N2 and no user plane, so the Path Switch Request of the
+// target to the AMF and the SN Status Transfer of the PDCP COUNTs of the
+// DRBs have no part in the procedure.
+package xn
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/identity"
+)
+
+var (
+	// ErrUnknownCell is returned for a target cell the gNB-CU has not
+	// activated.
+	ErrUnknownCell = status.Error(codes.NotFound, "xn: unknown target cell")
+	// ErrUnknownUE is returned for the XnAP IDs of no UE context.
+	ErrUnknownUE = status.Error(codes.NotFound, "xn: unknown UE context")
+	// ErrInvalidNeighbours is returned when parsing malformed neighbours.
+	ErrInvalidNeighbours = errors.New("invalid Xn neighbours, want GNB_ID=ADDRESS pairs such as 2/24=gnbcu-2:8581")
+)
+
+// HandoverRequest asks the gNB-CU of the target cell to admit the UE of the
+// source gNB, with its context: its 5G-S-TMSI and radio capability, once
+// known, and its DRBs.
+type HandoverRequest struct {
+	Source     identity.GNBID `json:"source_gnb_id"`
+	SourceUEID uint32         `json:"source_ng_ran_node_ue_xnap_id"`
+	TargetNCI  uint64         `json:"target_nci"`
+	STMSI      string         `json:"ng_5g_s_tmsi,omitempty"`
+	Capability []byte         `json:"ue_capability_rat_container_list,omitempty"`
+	DRBs       []uint32       `json:"drb_ids"`
+}
+
+// HandoverRequestAcknowledge admits the UE in the target gNB-CU, and carries
+// the RRC Reconfiguration the source sends the UE to hand it over.
+type HandoverRequestAcknowledge struct {
+	TargetUEID uint32   `json:"target_ng_ran_node_ue_xnap_id"`
+	DRBs       []uint32 `json:"drb_ids"`
+	RRC        []byte   `json:"rrc_container"`
+}
+
+// HandoverCancel is sent by the source gNB-CU to drop the UE the target
+// admitted, which the UE did not reach.
+type HandoverCancel struct {
+	SourceUEID uint32 `json:"source_ng_ran_node_ue_xnap_id"`
+	TargetUEID uint32 `json:"target_ng_ran_node_ue_xnap_id"`
+}
+
+// UEContextRelease is sent by the target gNB-CU once the UE reached the
+// target cell, for the source to release the UE.
+type UEContextRelease struct {
+	SourceUEID uint32 `json:"source_ng_ran_node_ue_xnap_id"`
+	TargetUEID uint32 `json:"target_ng_ran_node_ue_xnap_id"`
+}
+
+// GNB is the gNB-CU end of the Xn, which every gNB-CU serves to its
+// neighbours, as the source or the target of a handover.
+type GNB interface {
+	HandoverPreparation(ctx context.Context, req HandoverRequest) (HandoverRequestAcknowledge, error)
+	HandoverCancel(ctx context.Context, m HandoverCancel) error
+	UEContextRelease(ctx context.Context, m UEContextRelease) error
+}
+
+// Neighbour is a neighbour gNB, which the UEs are handed over to over the Xn
+// for the cells of its gNB ID.
+type Neighbour struct {
+	GNBID   identity.GNBID
+	Address string
+}
+
+// ParseNeighbours parses a comma separated list of neighbours written
+// GNB_ID=ADDRESS, the gNB ID as identity.ParseGNBID takes it and the
+// address where the gNB-CU serves the Xn. The empty string gives no
+// neighbour.
+func ParseNeighbours(s string) ([]Neighbour, error) {
+	var ns []Neighbour
+	for _, f := range strings.Split(s, ",") {
+		if f = strings.TrimSpace(f); f == "" {
+			continue
+		}
+		i := strings.IndexByte(f, '=')
+		if i < 0 || i == len(f)-1 {
+			return nil, ErrInvalidNeighbours
+		}
+		id, err := identity.ParseGNBID(f[:i])
+		if err != nil {
+			return nil, err
+		}
+		ns = append(ns, Neighbour{GNBID: id, Address: f[i+1:]})
+	}
+	return ns, nil
+}
@@ -0,0 +1,120 @@
+package grpcpool
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-kit/kit/log/level"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Outliers configures the passive health checking of the connections: a
+// connection whose calls fail more than FailureRate of the time, over the
+// calls of a health interval, is ejected from the pool for a while, the
+// other connections taking its calls. A connection ejected again stays out
+// twice as long each time, up to MaxEjection, and the ejection time shrinks
+// back while it behaves.
+//
+// Only the failures of the backend count: Unavailable, DeadlineExceeded,
+// Internal and Unknown, not the errors of the requests themselves, such as
+// NotFound, nor the calls cancelled by the caller.
+type Outliers struct {
+	// FailureRate is the fraction of failed calls, in (0, 1], above which
+	// a connection is ejected.
+	FailureRate float64
+	// MinRequests is the number of calls within an interval needed to
+	// judge a connection.
+	MinRequests int
+	// BaseEjection is the time of a first ejection.
+	BaseEjection time.Duration
+	// MaxEjection caps the ejection time.
+	MaxEjection time.Duration
+	// MaxEjectedPercent caps the connections ejected at once, in percents
+	// of the pool, so that a failing target is not ejected as a whole.
+	// Zero disables the ejections.
+	MaxEjectedPercent int
+}
+
+// DefaultOutliers is the outlier detection of a pool, unless set otherwise.
+var DefaultOutliers = Outliers{
+	FailureRate:       0.5,
+	MinRequests:       10,
+	BaseEjection:      30 * time.Second,
+	MaxEjection:       5 * time.Minute,
+	MaxEjectedPercent: 50,
+}
+
+// OutlierDetection sets the outlier detection of the pool.
+func OutlierDetection(o Outliers) Option {
+	return func(p *Pool) { p.outliers = o }
+}
+
+// backendFailure tells whether err, returned by a call, is the failure of the
+// backend rather than of the request or of the caller.
+func backendFailure(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() == context.Canceled {
+		return false
+	}
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.Internal, codes.Unknown:
+		return true
+	}
+	return false
+}
+
+// record counts a call over c and ejects c when it fails too often.
+func (p *Pool) record(c *conn, failed bool, now time.Time) {
+	calls := atomic.AddInt64(&c.calls, 1)
+	if !failed {
+		return
+	}
+	failures := atomic.AddInt64(&c.errors, 1)
+	o := p.outliers
+	if o.MaxEjectedPercent <= 0 || calls < int64(o.MinRequests) || float64(failures) < o.FailureRate*float64(calls) {
+		return
+	}
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	if p.closed || c.ejected(now) {
+		return
+	}
+	ejected := 0
+	for _, other := range p.conns {
+		if other.ejected(now) {
+			ejected++
+		}
+	}
+	if (ejected+1)*100 > o.MaxEjectedPercent*len(p.conns) {
+		return
+	}
+
+	d := o.BaseEjection << c.ejections
+	if d <= 0 || d > o.MaxEjection {
+		d = o.MaxEjection
+	}
+	c.ejections++
+	c.ejectedUntil = now.Add(d)
+	atomic.StoreInt64(&c.calls, 0)
+	atomic.StoreInt64(&c.errors, 0)
+	atomic.AddInt64(&p.ejections, 1)
+	p.ejectionCounter.Add(1)
+	level.Warn(p.logger).Log("target", p.target, "eject", "outlier", "calls", calls, "failures", failures, "for", d)
+}
+
+// resetOutlier starts a new interval of calls for c, the lock held. A
+// connection back from an ejection gets one ejection forgiven per interval
+// without failures.
+func (c *conn) resetOutlier(now time.Time) {
+	atomic.StoreInt64(&c.calls, 0)
+	if atomic.SwapInt64(&c.errors, 0) == 0 && c.ejections > 0 && !c.ejected(now) {
+		c.ejections--
+	}
+}
+
+// ejected tells whether c is out of the pool at now.
+func (c *conn) ejected(now time.Time) bool {
+	return now.Before(c.ejectedUntil)
+}
@@ -0,0 +1,442 @@
+// Package grpcpool maintains several gRPC client connections to a single
+// target and hands each call to the least loaded healthy one, so that a busy
+// client is not serialized behind a single HTTP/2 connection. The
+// connections whose calls keep failing are ejected for a while, see
+// Outliers.
+//
+// The connections all go to the same target, a virtual IP usually, so the
+// pool balances the calls over its connections, not over the replicas
+// behind them. The targets resolving to the replicas themselves, such as
+// those of package discovery, balance the calls of every connection over
+// the replicas with their own balancer.
+package grpcpool
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/go-kit/kit/metrics"
+	"github.com/go-kit/kit/metrics/discard"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	healthgrpc "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const (
+	defHealthInterval = 5 * time.Second
+	defMinBackoff     = 500 * time.Millisecond
+	defMaxBackoff     = 30 * time.Second
+	defStreamsPerConn = 100
+)
+
+// ErrClosed is returned by endpoints of a pool that has been closed.
+var ErrClosed = errors.New("grpcpool: pool is closed")
+
+// Option sets an optional parameter of a Pool.
+type Option func(*Pool)
+
+// DialOptions sets the options used to dial every connection of the pool.
+// The default is a plain insecure connection.
+func DialOptions(opts ...grpc.DialOption) Option {
+	return func(p *Pool) { p.dialOpts = opts }
+}
+
+// HealthInterval sets how often the connections are checked.
+func HealthInterval(d time.Duration) Option {
+	return func(p *Pool) { p.healthInterval = d }
+}
+
+// HealthService enables the grpc.health.v1 check against the given service
+// name in addition to the connectivity state.
+func HealthService(name string) Option {
+	return func(p *Pool) { p.healthService = name }
+}
+
+// Backoff sets the bounds of the exponential backoff between reconnects of a
+// failed connection.
+func Backoff(min, max time.Duration) Option {
+	return func(p *Pool) { p.minBackoff, p.maxBackoff = min, max }
+}
+
+// StreamsPerConn sets the number of concurrent calls a single connection is
+// expected to carry. It is only used to compute the saturation metric.
+func StreamsPerConn(n int) Option {
+	return func(p *Pool) { p.streamsPerConn = n }
+}
+
+// Logger sets the logger used to report reconnects.
+func Logger(logger log.Logger) Option {
+	return func(p *Pool) { p.logger = logger }
+}
+
+// InflightGauge sets the gauge reporting the number of calls in flight.
+func InflightGauge(g metrics.Gauge) Option {
+	return func(p *Pool) { p.inflightGauge = g }
+}
+
+// SaturationGauge sets the gauge reporting calls in flight divided by the
+// capacity of the healthy connections.
+func SaturationGauge(g metrics.Gauge) Option {
+	return func(p *Pool) { p.saturationGauge = g }
+}
+
+// ReconnectCounter sets the counter incremented on every reconnect.
+func ReconnectCounter(c metrics.Counter) Option {
+	return func(p *Pool) { p.reconnectCounter = c }
+}
+
+// EjectionCounter sets the counter incremented on every ejection of an
+// outlier.
+func EjectionCounter(c metrics.Counter) Option {
+	return func(p *Pool) { p.ejectionCounter = c }
+}
+
+// Stats is a point in time view of a pool.
+type Stats struct {
+	Target     string  `json:"target"`
+	Size       int     `json:"size"`
+	Healthy    int     `json:"healthy"`
+	InFlight   int64   `json:"in_flight"`
+	Saturation float64 `json:"saturation"`
+	Reconnects int64   `json:"reconnects"`
+	Ejected    int     `json:"ejected"`
+	Ejections  int64   `json:"ejections"`
+}
+
+// Pool is a fixed size set of connections to one target.
+type Pool struct {
+	target           string
+	dialOpts         []grpc.DialOption
+	healthInterval   time.Duration
+	healthService    string
+	minBackoff       time.Duration
+	maxBackoff       time.Duration
+	streamsPerConn   int
+	logger           log.Logger
+	inflightGauge    metrics.Gauge
+	saturationGauge  metrics.Gauge
+	reconnectCounter metrics.Counter
+	ejectionCounter  metrics.Counter
+	outliers         Outliers
+
+	mtx        sync.RWMutex
+	conns      []*conn
+	inflight   int64
+	reconnects int64
+	ejections  int64
+	closed     bool
+	quit       chan struct{}
+}
+
+type conn struct {
+	cc       *grpc.ClientConn
+	inflight int64
+	healthy  bool
+	failures uint
+	nextDial time.Time
+
+	// the calls and the failed ones of the current interval
+	calls        int64
+	errors       int64
+	ejections    uint
+	ejectedUntil time.Time
+}
+
+// New dials size connections to target and starts checking their health in
+// the background. Dialing does not block, so an unreachable target is
+// reported by the calls rather than by New.
+func New(ctx context.Context, target string, size int, options ...Option) (*Pool, error) {
+	if size < 1 {
+		size = 1
+	}
+	p := &Pool{
+		target:           target,
+		dialOpts:         []grpc.DialOption{grpc.WithInsecure()},
+		healthInterval:   defHealthInterval,
+		minBackoff:       defMinBackoff,
+		maxBackoff:       defMaxBackoff,
+		streamsPerConn:   defStreamsPerConn,
+		logger:           log.NewNopLogger(),
+		inflightGauge:    discard.NewGauge(),
+		saturationGauge:  discard.NewGauge(),
+		reconnectCounter: discard.NewCounter(),
+		ejectionCounter:  discard.NewCounter(),
+		outliers:         DefaultOutliers,
+		quit:             make(chan struct{}),
+	}
+	for _, option := range options {
+		option(p)
+	}
+
+	for i := 0; i < size; i++ {
+		cc, err := grpc.DialContext(ctx, target, p.dialOpts...)
+		if err != nil {
+			p.Close()
+			return nil, err
+		}
+		p.conns = append(p.conns, &conn{cc: cc, healthy: true})
+	}
+	go p.loop()
+	return p, nil
+}
+
+// FromConn wraps an existing connection in a pool of one. The pool does not
+// check or replace the connection, and closing the pool closes it.
+func FromConn(cc *grpc.ClientConn) *Pool {
+	var target string
+	if cc != nil {
+		target = cc.Target()
+	}
+	return &Pool{
+		target:           target,
+		streamsPerConn:   defStreamsPerConn,
+		logger:           log.NewNopLogger(),
+		inflightGauge:    discard.NewGauge(),
+		saturationGauge:  discard.NewGauge(),
+		reconnectCounter: discard.NewCounter(),
+		ejectionCounter:  discard.NewCounter(),
+		outliers:         DefaultOutliers,
+		conns:            []*conn{{cc: cc, healthy: true}},
+	}
+}
+
+// Endpoint returns an endpoint that sends each request over the least loaded
+// healthy connection. factory is called once per connection to build the
+// endpoint bound to it, typically a go-kit grpc transport client. The error
+// of every call feeds the outlier detection.
+func (p *Pool) Endpoint(factory func(*grpc.ClientConn) endpoint.Endpoint) endpoint.Endpoint {
+	var (
+		mtx sync.Mutex
+		eps = map[*grpc.ClientConn]endpoint.Endpoint{}
+	)
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		c, err := p.acquire()
+		if err != nil {
+			return nil, err
+		}
+		defer p.release(c)
+
+		mtx.Lock()
+		e, ok := eps[c.cc]
+		if !ok {
+			// Connections are replaced on reconnect, drop the stale ones.
+			for cc := range eps {
+				if cc.GetState() == connectivity.Shutdown {
+					delete(eps, cc)
+				}
+			}
+			e = factory(c.cc)
+			eps[c.cc] = e
+		}
+		mtx.Unlock()
+
+		response, err := e(ctx, request)
+		p.record(c, backendFailure(ctx, err), time.Now())
+		return response, err
+	}
+}
+
+// Stats returns the current state of the pool.
+func (p *Pool) Stats() Stats {
+	p.mtx.RLock()
+	defer p.mtx.RUnlock()
+	s := Stats{
+		Target:     p.target,
+		Size:       len(p.conns),
+		InFlight:   atomic.LoadInt64(&p.inflight),
+		Reconnects: atomic.LoadInt64(&p.reconnects),
+		Ejections:  atomic.LoadInt64(&p.ejections),
+	}
+	now := time.Now()
+	for _, c := range p.conns {
+		if c.healthy {
+			s.Healthy++
+		}
+		if c.ejected(now) {
+			s.Ejected++
+		}
+	}
+	s.Saturation = p.saturation(s.InFlight, s.Healthy)
+	return s
+}
+
+// Close stops the health checks and closes every connection.
+func (p *Pool) Close() error {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	if p.closed {
+		return nil
+	}
+	p.closed = true
+	if p.quit != nil {
+		close(p.quit)
+	}
+	var err error
+	for _, c := range p.conns {
+		if cerr := c.cc.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// acquire picks the connection of a call: the healthy one with the fewest
+// calls in flight, the ejected ones counting as unhealthy. When no
+// connection is healthy the least loaded one is used anyway and the call is
+// left to fail or wait on its own.
+func (p *Pool) acquire() (*conn, error) {
+	p.mtx.RLock()
+	defer p.mtx.RUnlock()
+	if p.closed {
+		return nil, ErrClosed
+	}
+
+	var (
+		best        *conn
+		bestHealthy bool
+		bestLoad    int64
+		healthy     int
+	)
+	now := time.Now()
+	for _, c := range p.conns {
+		if c.healthy {
+			healthy++
+		}
+		ok, load := c.healthy && !c.ejected(now), atomic.LoadInt64(&c.inflight)
+		if best == nil || (ok && !bestHealthy) || (ok == bestHealthy && load < bestLoad) {
+			best, bestHealthy, bestLoad = c, ok, load
+		}
+	}
+	atomic.AddInt64(&best.inflight, 1)
+	p.observe(atomic.AddInt64(&p.inflight, 1), healthy)
+	return best, nil
+}
+
+func (p *Pool) release(c *conn) {
+	atomic.AddInt64(&c.inflight, -1)
+	p.mtx.RLock()
+	healthy := 0
+	for _, c := range p.conns {
+		if c.healthy {
+			healthy++
+		}
+	}
+	p.mtx.RUnlock()
+	p.observe(atomic.AddInt64(&p.inflight, -1), healthy)
+}
+
+func (p *Pool) observe(inflight int64, healthy int) {
+	p.inflightGauge.Set(float64(inflight))
+	p.saturationGauge.Set(p.saturation(inflight, healthy))
+}
+
+func (p *Pool) saturation(inflight int64, healthy int) float64 {
+	if healthy == 0 || p.streamsPerConn <= 0 {
+		if inflight > 0 {
+			return 1
+		}
+		return 0
+	}
+	return float64(inflight) / float64(healthy*p.streamsPerConn)
+}
+
+func (p *Pool) loop() {
+	ticker := time.NewTicker(p.healthInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			p.check()
+		case <-p.quit:
+			return
+		}
+	}
+}
+
+// check refreshes the health of every connection and replaces the ones that
+// failed, backing off exponentially while a target keeps failing. It also
+// starts a new interval of the outlier detection.
+func (p *Pool) check() {
+	p.mtx.RLock()
+	conns := make([]*conn, len(p.conns))
+	copy(conns, p.conns)
+	p.mtx.RUnlock()
+
+	now := time.Now()
+	for i, c := range conns {
+		healthy := p.healthy(c.cc)
+
+		p.mtx.Lock()
+		if p.closed {
+			p.mtx.Unlock()
+			return
+		}
+		c.healthy = healthy
+		if healthy {
+			c.failures = 0
+		}
+		c.resetOutlier(now)
+		redial := !healthy && !now.Before(c.nextDial)
+		p.mtx.Unlock()
+
+		if redial {
+			p.redial(i, c, now)
+		}
+	}
+}
+
+func (p *Pool) healthy(cc *grpc.ClientConn) bool {
+	switch cc.GetState() {
+	case connectivity.TransientFailure, connectivity.Shutdown:
+		return false
+	}
+	if p.healthService == "" {
+		return true
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), p.healthInterval)
+	defer cancel()
+	resp, err := healthgrpc.NewHealthClient(cc).Check(ctx, &healthgrpc.HealthCheckRequest{Service: p.healthService})
+	return err == nil && resp.Status == healthgrpc.HealthCheckResponse_SERVING
+}
+
+func (p *Pool) redial(i int, old *conn, now time.Time) {
+	backoff := p.minBackoff << old.failures
+	if backoff <= 0 || backoff > p.maxBackoff {
+		backoff = p.maxBackoff
+	}
+
+	cc, err := grpc.DialContext(context.Background(), p.target, p.dialOpts...)
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	if p.closed {
+		if err == nil {
+			cc.Close()
+		}
+		return
+	}
+	if err != nil {
+		old.failures++
+		old.nextDial = now.Add(backoff)
+		level.Error(p.logger).Log("target", p.target, "reconnect", "failed", "backoff", backoff, "err", err)
+		return
+	}
+
+	// In-flight calls keep using the old connection until they return.
+	go func(cc *grpc.ClientConn, inflight *int64) {
+		for atomic.LoadInt64(inflight) > 0 {
+			time.Sleep(p.minBackoff)
+		}
+		cc.Close()
+	}(old.cc, &old.inflight)
+
+	p.conns[i] = &conn{cc: cc, healthy: true, failures: old.failures + 1, nextDial: now.Add(backoff), ejections: old.ejections, ejectedUntil: old.ejectedUntil}
+	atomic.AddInt64(&p.reconnects, 1)
+	p.reconnectCounter.Add(1)
+	level.Info(p.logger).Log("target", p.target, "reconnect", i, "backoff", backoff)
+}
@@ -0,0 +1,151 @@
+// Package grpcserver builds the gRPC servers of the services. Every server
+// gets the go-kit interceptor, the health service and server reflection, so
+// that grpcurl works against any of them, and accepts the requests compressed
+// with gzip, answering them in kind; the keepalive parameters, the message
+// sizes and extra interceptors are left to the caller.
+package grpcserver
+
+import (
+	"time"
+
+	"github.com/go-kit/kit/log"
+	kitgrpc "github.com/go-kit/kit/transport/grpc"
+	"google.golang.org/grpc"
+	_ "google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/health"
+	healthgrpc "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/reflection"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/recovery"
+)
+
+// Defaults of the message sizes, the ones of grpc-go.
+const (
+	DefaultMaxRecvMsgSize = 4 << 20
+	DefaultMaxSendMsgSize = 1<<31 - 1
+)
+
+// ServerBuilder collects the options of a gRPC server. Its methods return the
+// builder so that calls can be chained; Build creates the server.
+type ServerBuilder struct {
+	options    []grpc.ServerOption
+	unary      []grpc.UnaryServerInterceptor
+	stream     []grpc.StreamServerInterceptor
+	keepalive  *keepalive.ServerParameters
+	policy     *keepalive.EnforcementPolicy
+	maxRecv    int
+	maxSend    int
+	health     *health.Server
+	reflection bool
+}
+
+// NewServerBuilder returns a builder of servers registering hs and
+// reflection. hs may be nil to leave the health service out.
+func NewServerBuilder(hs *health.Server) *ServerBuilder {
+	return &ServerBuilder{
+		unary:      []grpc.UnaryServerInterceptor{kitgrpc.Interceptor},
+		maxRecv:    DefaultMaxRecvMsgSize,
+		maxSend:    DefaultMaxSendMsgSize,
+		health:     hs,
+		reflection: true,
+	}
+}
+
+// Keepalive sets the time after which an idle connection is pinged and the
+// time the server waits for the acknowledgement. Zero values keep the grpc-go
+// defaults.
+func (b *ServerBuilder) Keepalive(t, timeout time.Duration) *ServerBuilder {
+	b.keepalive = &keepalive.ServerParameters{Time: t, Timeout: timeout}
+	return b
+}
+
+// KeepaliveParams sets all the keepalive parameters of the server.
+func (b *ServerBuilder) KeepaliveParams(p keepalive.ServerParameters) *ServerBuilder {
+	b.keepalive = &p
+	return b
+}
+
+// KeepaliveEnforcement sets how often clients may ping the server.
+func (b *ServerBuilder) KeepaliveEnforcement(p keepalive.EnforcementPolicy) *ServerBuilder {
+	b.policy = &p
+	return b
+}
+
+// MaxMsgSize sets the largest messages the server receives and sends, in
+// bytes. Values of zero or less keep the current sizes.
+func (b *ServerBuilder) MaxMsgSize(recv, send int) *ServerBuilder {
+	if recv > 0 {
+		b.maxRecv = recv
+	}
+	if send > 0 {
+		b.maxSend = send
+	}
+	return b
+}
+
+// UnaryInterceptor appends interceptors to the unary chain. They run after
+// the go-kit interceptor, in the order they are added.
+func (b *ServerBuilder) UnaryInterceptor(i ...grpc.UnaryServerInterceptor) *ServerBuilder {
+	b.unary = append(b.unary, i...)
+	return b
+}
+
+// StreamInterceptor appends interceptors to the stream chain.
+func (b *ServerBuilder) StreamInterceptor(i ...grpc.StreamServerInterceptor) *ServerBuilder {
+	b.stream = append(b.stream, i...)
+	return b
+}
+
+// Recovery puts the interceptors recovering the panics of the handlers
+// first in the unary and stream chains, so that they also cover the other
+// interceptors.
+func (b *ServerBuilder) Recovery(logger log.Logger) *ServerBuilder {
+	b.unary = append([]grpc.UnaryServerInterceptor{recovery.UnaryServerInterceptor(logger)}, b.unary...)
+	b.stream = append([]grpc.StreamServerInterceptor{recovery.StreamServerInterceptor(logger)}, b.stream...)
+	return b
+}
+
+// Option appends raw server options, for those the builder has no method
+// for.
+func (b *ServerBuilder) Option(o ...grpc.ServerOption) *ServerBuilder {
+	b.options = append(b.options, o...)
+	return b
+}
+
+// Reflection sets whether the server registers the reflection service.
+func (b *ServerBuilder) Reflection(on bool) *ServerBuilder {
+	b.reflection = on
+	return b
+}
+
+// Build creates the server and registers the health and reflection services.
+// The caller registers its own services and serves.
+func (b *ServerBuilder) Build() *grpc.Server {
+	options := []grpc.ServerOption{
+		grpc.MaxRecvMsgSize(b.maxRecv),
+		grpc.MaxSendMsgSize(b.maxSend),
+	}
+	if len(b.unary) > 0 {
+		options = append(options, grpc.ChainUnaryInterceptor(b.unary...))
+	}
+	if len(b.stream) > 0 {
+		options = append(options, grpc.ChainStreamInterceptor(b.stream...))
+	}
+	if b.keepalive != nil {
+		options = append(options, grpc.KeepaliveParams(*b.keepalive))
+	}
+	if b.policy != nil {
+		options = append(options, grpc.KeepaliveEnforcementPolicy(*b.policy))
+	}
+	options = append(options, b.options...)
+
+	server := grpc.NewServer(options...)
+	if b.health != nil {
+		healthgrpc.RegisterHealthServer(server, b.health)
+	}
+	if b.reflection {
+		reflection.Register(server)
+	}
+	return server
+}
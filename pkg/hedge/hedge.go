@@ -0,0 +1,222 @@
+// Package hedge cuts the tail latency of idempotent reads by hedging: when a
+// call has not answered after the usual latency of the endpoint, a percentile
+// of its recent calls, a second attempt is sent, and the first answer wins,
+// the other attempt being cancelled. Over a grpcpool endpoint the second
+// attempt goes to the least loaded connection, another one than the first
+// attempt's, so usually another replica.
+//
+// Hedging a call that changes state would apply it twice: only the reads go
+// through the middleware. The number of hedges is capped to a fraction of the
+// calls, so that a slow backend does not get twice the load.
+package hedge
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/metrics"
+	"github.com/go-kit/kit/metrics/discard"
+)
+
+// Default values of the options.
+const (
+	DefaultPercentile = 0.95
+	DefaultMaxRatio   = 0.1
+	DefaultMinDelay   = 5 * time.Millisecond
+	DefaultWindow     = 1000
+)
+
+// minSamples is the number of latencies needed before hedging, so that the
+// delay is not computed from a handful of calls.
+const minSamples = 20
+
+// maxBudget caps the hedges saved up during a quiet period, so that they are
+// not all spent in the burst that follows.
+const maxBudget = 10
+
+// Option sets an optional parameter of the middleware.
+type Option func(*hedger)
+
+// Percentile sets the percentile, in (0, 1), of the recent latencies after
+// which the second attempt is sent.
+func Percentile(p float64) Option {
+	return func(h *hedger) { h.percentile = p }
+}
+
+// MaxRatio caps the hedges to a fraction of the calls. Zero disables hedging.
+func MaxRatio(r float64) Option {
+	return func(h *hedger) { h.maxRatio = r }
+}
+
+// MinDelay sets the least delay before the second attempt, for the endpoints
+// that answer faster than hedging is worth.
+func MinDelay(d time.Duration) Option {
+	return func(h *hedger) { h.minDelay = d }
+}
+
+// Window sets the number of recent latencies the percentile is computed on.
+func Window(n int) Option {
+	return func(h *hedger) {
+		if n > 0 {
+			h.window = n
+		}
+	}
+}
+
+// Counter sets the counter incremented on every hedge, with the label "won"
+// set to "true" when the second attempt answered first.
+func Counter(c metrics.Counter) Option {
+	return func(h *hedger) { h.counter = c }
+}
+
+type hedger struct {
+	percentile float64
+	maxRatio   float64
+	minDelay   time.Duration
+	window     int
+	counter    metrics.Counter
+
+	mtx       sync.Mutex
+	latencies []time.Duration
+	next      int
+	// the observations since delay was computed
+	stale  int
+	delay  time.Duration
+	budget float64
+}
+
+type result struct {
+	response interface{}
+	err      error
+	hedge    bool
+	took     time.Duration
+}
+
+// Middleware returns a middleware hedging the calls of the endpoint. Every
+// endpoint needs its own middleware, its latencies being its own.
+func Middleware(options ...Option) endpoint.Middleware {
+	h := &hedger{
+		percentile: DefaultPercentile,
+		maxRatio:   DefaultMaxRatio,
+		minDelay:   DefaultMinDelay,
+		window:     DefaultWindow,
+		counter:    discard.NewCounter(),
+	}
+	for _, option := range options {
+		option(h)
+	}
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			delay, ok := h.plan()
+			if !ok {
+				start := time.Now()
+				response, err := next(ctx, request)
+				if err == nil {
+					h.observe(time.Since(start))
+				}
+				return response, err
+			}
+
+			ctx, cancel := context.WithCancel(ctx)
+			// cancels the attempt that lost
+			defer cancel()
+			results := make(chan result, 2)
+			attempt := func(hedge bool) {
+				start := time.Now()
+				response, err := next(ctx, request)
+				results <- result{response, err, hedge, time.Since(start)}
+			}
+			go attempt(false)
+			timer := time.NewTimer(delay)
+			defer timer.Stop()
+			hedged := false
+			for {
+				select {
+				case r := <-results:
+					if r.err == nil {
+						h.observe(r.took)
+					}
+					if hedged {
+						won := "false"
+						if r.hedge {
+							won = "true"
+						}
+						h.counter.With("won", won).Add(1)
+					}
+					return r.response, r.err
+				case <-timer.C:
+					if h.spend() {
+						hedged = true
+						go attempt(true)
+					}
+				}
+			}
+		}
+	}
+}
+
+// plan returns the delay before hedging a call, and false when the call is
+// not to be hedged. Every call adds to the budget of hedges.
+func (h *hedger) plan() (time.Duration, bool) {
+	if h.maxRatio <= 0 {
+		return 0, false
+	}
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	h.budget += h.maxRatio
+	if h.budget > maxBudget {
+		h.budget = maxBudget
+	}
+	if len(h.latencies) < minSamples || h.budget < 1 {
+		return 0, false
+	}
+	if h.delay == 0 || h.stale >= h.window/10 {
+		h.delay = h.compute()
+		h.stale = 0
+	}
+	return h.delay, true
+}
+
+// spend takes a hedge out of the budget, if there is one left.
+func (h *hedger) spend() bool {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	if h.budget < 1 {
+		return false
+	}
+	h.budget--
+	return true
+}
+
+func (h *hedger) observe(d time.Duration) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	if len(h.latencies) < h.window {
+		h.latencies = append(h.latencies, d)
+	} else {
+		h.latencies[h.next] = d
+		h.next = (h.next + 1) % h.window
+	}
+	h.stale++
+}
+
+// compute returns the percentile of the latencies, the lock held.
+func (h *hedger) compute() time.Duration {
+	sorted := append([]time.Duration(nil), h.latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	i := int(h.percentile * float64(len(sorted)-1))
+	if i < 0 {
+		i = 0
+	}
+	if i >= len(sorted) {
+		i = len(sorted) - 1
+	}
+	d := sorted[i]
+	if d < h.minDelay {
+		d = h.minDelay
+	}
+	return d
+}
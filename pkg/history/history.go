@@ -0,0 +1,224 @@
+// Package history keeps an append-only log of what happened to every UE:
+// its registration attempts, the results of its authentications, its PDU
+// session events and its handovers, as recorded by the network functions
+// handling them. Support engineers read it back, by UE and time range, to
+// reconstruct the story of a subscriber.
+//
+// The events are kept in a store, one key per event, ordered by time. They
+// are never updated; they go away when their retention runs out.
+package history
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/clock"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/schema"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/store"
+)
+
+// KeyPrefix is prepended to the SUPI to build the store keys, the keys of
+// the events all starting with it.
+const KeyPrefix = "history/"
+
+// Schema versions the events in the store.
+var Schema = &schema.Schema{Name: "history", Prefix: KeyPrefix}
+
+// Page sizes of Query.
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 1000
+)
+
+// ErrPageToken is returned for a page token not issued by Query.
+var ErrPageToken = status.Error(codes.InvalidArgument, "invalid page token")
+
+// Type is the kind of an event.
+type Type string
+
+// The types of the events.
+const (
+	// RegistrationAttempt is recorded when a UE starts a registration, on
+	// the request of its authentication data.
+	RegistrationAttempt Type = "registration_attempt"
+	// AuthResult is recorded when the authentication of a UE is confirmed
+	// or rejected.
+	AuthResult Type = "auth_result"
+	// SessionEvent is recorded when a PDU session of a UE is established,
+	// modified or released.
+	SessionEvent Type = "session"
+	// Handover is recorded when a UE is handed over to another cell.
+	Handover Type = "handover"
+)
+
+// Outcomes of the events.
+const (
+	Success = "success"
+	Failure = "failure"
+)
+
+// Event is an entry of the history of a UE.
+type Event struct {
+	Time time.Time `json:"time"`
+	Type Type      `json:"type"`
+	// Source identifies the network function instance that recorded the
+	// event, or the one it recorded it for.
+	Source  string `json:"source,omitempty"`
+	Outcome string `json:"outcome,omitempty"`
+	// Detail holds the parameters of the event, such as the serving network
+	// name of an authentication or the target cell of a handover.
+	Detail map[string]string `json:"detail,omitempty"`
+}
+
+// Option sets an optional parameter of a Log.
+type Option func(*Log)
+
+// Retention sets how long the events are kept. The zero retention, the
+// default, keeps them forever.
+func Retention(d time.Duration) Option {
+	return func(l *Log) { l.retention = d }
+}
+
+// Notify sets a function called with every event appended, to publish it
+// as it happens. It must not block.
+func Notify(notify func(supi string, e Event)) Option {
+	return func(l *Log) { l.notify = notify }
+}
+
+// Clock sets the clock telling the time of the events, clock.Real by
+// default. The store keeps its own time for the retention.
+func Clock(c clock.Clock) Option {
+	return func(l *Log) { l.clock = c }
+}
+
+// Log is the history of the UEs kept in a store.
+type Log struct {
+	st        store.Store
+	retention time.Duration
+	notify    func(supi string, e Event)
+	clock     clock.Clock
+}
+
+// New returns the Log keeping its events in st.
+func New(st store.Store, options ...Option) *Log {
+	l := &Log{st: st, clock: clock.Real}
+	for _, option := range options {
+		option(l)
+	}
+	return l
+}
+
+// Append adds e to the history of the UE supi. The time of e is set to now
+// when it is zero.
+func (l *Log) Append(ctx context.Context, supi string, e Event) error {
+	if e.Time.IsZero() {
+		e.Time = l.clock.Now()
+	}
+	e.Time = e.Time.UTC()
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	// the random suffix keeps apart the events recorded in the same
+	// nanosecond, by this replica or another one
+	for {
+		ok, err := l.st.SetNX(ctx, key(supi, e.Time, rand.Uint32()), data, l.retention)
+		if err != nil {
+			return err
+		}
+		if ok {
+			break
+		}
+	}
+	if l.notify != nil {
+		l.notify(supi, e)
+	}
+	return nil
+}
+
+// Query returns up to pageSize events of the UE supi, oldest first, that
+// happened in [since, until), starting after pageToken, and the token of the
+// next page, empty on the last one. A zero since or until leaves that end of
+// the range open.
+func (l *Log) Query(ctx context.Context, supi string, since, until time.Time, pageSize int, pageToken string) ([]Event, string, error) {
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+	if pageSize > MaxPageSize {
+		pageSize = MaxPageSize
+	}
+	prefix := KeyPrefix + supi + "/"
+	from := prefix
+	if !since.IsZero() {
+		from = key(supi, since, 0)
+	}
+	var after string
+	if pageToken != "" {
+		b, err := hex.DecodeString(pageToken)
+		if err != nil || !strings.HasPrefix(string(b), prefix) {
+			return nil, "", ErrPageToken
+		}
+		after = string(b)
+	}
+	keys, err := l.st.Keys(ctx, prefix)
+	if err != nil {
+		return nil, "", err
+	}
+	i := sort.SearchStrings(keys, from)
+	if after != "" {
+		if j := sort.SearchStrings(keys, after); j > i {
+			i = j
+		}
+		if i < len(keys) && keys[i] == after {
+			i++
+		}
+	}
+	end := len(keys)
+	if !until.IsZero() {
+		end = sort.SearchStrings(keys, key(supi, until, 0))
+	}
+
+	var events []Event
+	last := ""
+	for ; i < end && len(events) < pageSize; i++ {
+		data, err := l.st.Get(ctx, keys[i])
+		if err == store.ErrNotFound {
+			// expired since Keys
+			continue
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		var e Event
+		if err := json.Unmarshal(data, &e); err != nil {
+			return nil, "", err
+		}
+		events = append(events, e)
+		last = keys[i]
+	}
+	var next string
+	if i < end && last != "" {
+		next = hex.EncodeToString([]byte(last))
+	}
+	return events, next, nil
+}
+
+// key returns the store key of an event of supi at t. The time is written
+// with a fixed width so that the keys sort in time order.
+func key(supi string, t time.Time, suffix uint32) string {
+	ns := t.UnixNano()
+	if ns < 0 {
+		ns = 0
+	}
+	return KeyPrefix + supi + "/" + fmt.Sprintf("%019d", ns) + "-" + strconv.FormatUint(uint64(suffix), 16)
+}
@@ -0,0 +1,180 @@
+// Package idempotency lets clients retry mutating calls safely. The client
+// supplies a key with the request; the first successful response stored
+// under that key is replayed to every retry for a configurable window
+// instead of running the call again.
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"time"
+
+	"github.com/go-kit/kit/endpoint"
+	grpctransport "github.com/go-kit/kit/transport/grpc"
+	httptransport "github.com/go-kit/kit/transport/http"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/store"
+)
+
+const (
+	// HTTPHeader carries the idempotency key of an HTTP request.
+	HTTPHeader = "Idempotency-Key"
+	// GRPCMetadataKey carries the idempotency key of a gRPC request.
+	GRPCMetadataKey = "idempotency-key"
+
+	keyPrefix = "idempotency/"
+)
+
+var (
+	// ErrInProgress is returned to a retry that arrives while the first
+	// request with the same key is still being served.
+	ErrInProgress = status.Error(codes.Aborted, "a request with the same idempotency key is in progress")
+	// ErrKeyReused is returned when a key is sent again with a different
+	// request.
+	ErrKeyReused = status.Error(codes.FailedPrecondition, "idempotency key was used with a different request")
+)
+
+type contextKey int
+
+const idempotencyKey contextKey = iota
+
+// NewContext returns a copy of ctx carrying the idempotency key.
+func NewContext(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKey, key)
+}
+
+// FromContext returns the idempotency key carried by ctx, if any.
+func FromContext(ctx context.Context) (string, bool) {
+	key, ok := ctx.Value(idempotencyKey).(string)
+	return key, ok && key != ""
+}
+
+// HTTPToContext moves the idempotency key from the request header to the
+// context. Use it as a ServerBefore option.
+func HTTPToContext() httptransport.RequestFunc {
+	return func(ctx context.Context, r *http.Request) context.Context {
+		if key := r.Header.Get(HTTPHeader); key != "" {
+			return NewContext(ctx, key)
+		}
+		return ctx
+	}
+}
+
+// ContextToHTTP sets the request header from the idempotency key in the
+// context. Use it as a ClientBefore option.
+func ContextToHTTP() httptransport.RequestFunc {
+	return func(ctx context.Context, r *http.Request) context.Context {
+		if key, ok := FromContext(ctx); ok {
+			r.Header.Set(HTTPHeader, key)
+		}
+		return ctx
+	}
+}
+
+// GRPCToContext moves the idempotency key from the incoming metadata to the
+// context. Use it as a ServerBefore option.
+func GRPCToContext() grpctransport.ServerRequestFunc {
+	return func(ctx context.Context, md metadata.MD) context.Context {
+		if vs := md.Get(GRPCMetadataKey); len(vs) > 0 && vs[0] != "" {
+			return NewContext(ctx, vs[0])
+		}
+		return ctx
+	}
+}
+
+// ContextToGRPC sets the outgoing metadata from the idempotency key in the
+// context. Use it as a ClientBefore option.
+func ContextToGRPC() grpctransport.ClientRequestFunc {
+	return func(ctx context.Context, md *metadata.MD) context.Context {
+		if key, ok := FromContext(ctx); ok {
+			md.Set(GRPCMetadataKey, key)
+		}
+		return ctx
+	}
+}
+
+type record struct {
+	Done        bool            `json:"done"`
+	Fingerprint string          `json:"fingerprint"`
+	Response    json.RawMessage `json:"response,omitempty"`
+}
+
+// Middleware returns an endpoint middleware that serves each idempotency key
+// once per window. Requests without a key pass through untouched. response
+// is a zero value of the endpoint's response type, used to decode replayed
+// responses. Failed calls are not recorded, so they can be retried.
+func Middleware(s store.Store, window time.Duration, method string, response interface{}) endpoint.Middleware {
+	responseType := reflect.TypeOf(response)
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			key, ok := FromContext(ctx)
+			if !ok {
+				return next(ctx, request)
+			}
+			key = keyPrefix + method + "/" + key
+			fingerprint := fingerprintOf(request)
+
+			pending, _ := json.Marshal(record{Fingerprint: fingerprint})
+			created, err := s.SetNX(ctx, key, pending, window)
+			if err != nil {
+				// The store is only an optimization; serve the call anyway.
+				return next(ctx, request)
+			}
+			if !created {
+				return replay(ctx, s, key, fingerprint, responseType)
+			}
+
+			resp, err := next(ctx, request)
+			if err != nil {
+				s.Delete(ctx, key)
+				return resp, err
+			}
+			if b, merr := json.Marshal(resp); merr == nil {
+				done, _ := json.Marshal(record{Done: true, Fingerprint: fingerprint, Response: b})
+				s.Set(ctx, key, done, window)
+			} else {
+				s.Delete(ctx, key)
+			}
+			return resp, nil
+		}
+	}
+}
+
+func replay(ctx context.Context, s store.Store, key, fingerprint string, responseType reflect.Type) (interface{}, error) {
+	b, err := s.Get(ctx, key)
+	if err != nil {
+		// Expired or released between the two calls.
+		return nil, ErrInProgress
+	}
+	var rec record
+	if err := json.Unmarshal(b, &rec); err != nil {
+		return nil, err
+	}
+	if rec.Fingerprint != fingerprint {
+		return nil, ErrKeyReused
+	}
+	if !rec.Done {
+		return nil, ErrInProgress
+	}
+	resp := reflect.New(responseType)
+	if err := json.Unmarshal(rec.Response, resp.Interface()); err != nil {
+		return nil, err
+	}
+	return resp.Elem().Interface(), nil
+}
+
+func fingerprintOf(request interface{}) string {
+	b, err := json.Marshal(request)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
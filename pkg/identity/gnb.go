@@ -0,0 +1,64 @@
+package identity
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidGNBID is returned when parsing a malformed gNB ID.
+var ErrInvalidGNBID = errors.New("invalid gNB ID, want ID/LENGTH with a length of 22 to 32 bits, such as 1/24")
+
+// nciBits is the length of an NR cell identity, which starts with the gNB
+// ID.
+const nciBits = 36
+
+// GNBID is the gNB ID of a gNB, on Length bits from 22 to 32 (TS 38.413
+// 9.3.1.6). The zero GNBID, of no length, identifies no gNB.
+type GNBID struct {
+	Value  uint32
+	Length uint8
+}
+
+// ParseGNBID parses a gNB ID written ID/LENGTH, the ID in decimal or, with
+// a 0x prefix, in hexadecimal, such as 1/24.
+func ParseGNBID(s string) (GNBID, error) {
+	f := strings.Split(s, "/")
+	if len(f) != 2 {
+		return GNBID{}, ErrInvalidGNBID
+	}
+	length, err := strconv.ParseUint(f[1], 10, 8)
+	if err != nil || length < 22 || length > 32 {
+		return GNBID{}, ErrInvalidGNBID
+	}
+	v, err := strconv.ParseUint(f[0], 0, 32)
+	if err != nil || v >= 1<<length {
+		return GNBID{}, ErrInvalidGNBID
+	}
+	return GNBID{Value: uint32(v), Length: uint8(length)}, nil
+}
+
+// String returns id as ID/LENGTH, or the empty string for the zero GNBID.
+func (id GNBID) String() string {
+	if id.IsZero() {
+		return ""
+	}
+	return strconv.FormatUint(uint64(id.Value), 10) + "/" + strconv.Itoa(int(id.Length))
+}
+
+// IsZero tells whether id is the zero GNBID.
+func (id GNBID) IsZero() bool {
+	return id.Length == 0
+}
+
+// NCI returns the NR cell identity of the cell cellID of the gNB (TS 38.300
+// 8.2), cellID taking the 36 - Length bits left.
+func (id GNBID) NCI(cellID uint64) uint64 {
+	return uint64(id.Value)<<(nciBits-id.Length) | cellID&(1<<(nciBits-id.Length)-1)
+}
+
+// Serves tells whether the cell nci is a cell of the gNB, its NCI starting
+// with the gNB ID.
+func (id GNBID) Serves(nci uint64) bool {
+	return nci>>(nciBits-id.Length) == uint64(id.Value)
+}
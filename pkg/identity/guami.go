@@ -0,0 +1,82 @@
+package identity
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/plmn"
+)
+
+var (
+	// ErrInvalidAMFID is returned when parsing a malformed AMF ID.
+	ErrInvalidAMFID = errors.New("invalid AMF ID, want 6 hexadecimal digits such as cafe00")
+	// ErrInvalidGUAMI is returned when parsing a malformed GUAMI.
+	ErrInvalidGUAMI = errors.New("invalid GUAMI, want MCC-MNC-AMFID such as 208-93-cafe00")
+)
+
+// AMFID identifies an AMF within a PLMN (TS 23.003 2.10.1): the AMF Region
+// ID on 8 bits, the AMF Set ID on 10 and the AMF Pointer on 6.
+type AMFID struct {
+	Region  uint8
+	Set     uint16
+	Pointer uint8
+}
+
+// ParseAMFID parses an AMF ID written as its 24 bits in hexadecimal, as in
+// TS 29.571 5.4.4.3, such as cafe00: region ca, set 3f8, pointer 0.
+func ParseAMFID(s string) (AMFID, error) {
+	if len(s) != 6 {
+		return AMFID{}, ErrInvalidAMFID
+	}
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return AMFID{}, ErrInvalidAMFID
+	}
+	return AMFID{Region: uint8(v >> 16), Set: uint16(v>>6) & 0x3ff, Pointer: uint8(v) & 0x3f}, nil
+}
+
+// String returns id as its 24 bits in hexadecimal.
+func (id AMFID) String() string {
+	return fmt.Sprintf("%06x", uint32(id.Region)<<16|uint32(id.Set&0x3ff)<<6|uint32(id.Pointer&0x3f))
+}
+
+// SetID returns the AMF Set ID of an NF profile, as 3 hexadecimal digits.
+func (id AMFID) SetID() string {
+	return fmt.Sprintf("%03x", id.Set&0x3ff)
+}
+
+// RegionID returns the AMF Region ID of an NF profile, as 2 hexadecimal
+// digits.
+func (id AMFID) RegionID() string {
+	return fmt.Sprintf("%02x", id.Region)
+}
+
+// GUAMI is the globally unique AMF identifier (TS 23.003 2.10.1).
+type GUAMI struct {
+	PLMN  plmn.ID
+	AMFID AMFID
+}
+
+// ParseGUAMI parses a GUAMI written MCC-MNC-AMFID, such as 208-93-cafe00.
+func ParseGUAMI(s string) (GUAMI, error) {
+	i := strings.LastIndexByte(s, '-')
+	if i < 0 {
+		return GUAMI{}, ErrInvalidGUAMI
+	}
+	id, err := plmn.Parse(s[:i])
+	if err != nil {
+		return GUAMI{}, ErrInvalidGUAMI
+	}
+	amfID, err := ParseAMFID(s[i+1:])
+	if err != nil {
+		return GUAMI{}, ErrInvalidGUAMI
+	}
+	return GUAMI{PLMN: id, AMFID: amfID}, nil
+}
+
+// String returns g as MCC-MNC-AMFID.
+func (g GUAMI) String() string {
+	return g.PLMN.String() + "-" + g.AMFID.String()
+}
@@ -0,0 +1,135 @@
+// Package identity is the identity of an NF instance, in the types of TS
+// 23.003 and TS 29.510 rather than strings: the NF instance ID, a UUID, the
+// GUAMIs of an AMF and the gNB ID of a gNB. bootstrap reads it from the
+// environment of the NF; the NF stamps it into its logs, its metrics, the
+// procedures it starts and the NF profile it would register with an NRF.
+package identity
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"strings"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/plmn"
+)
+
+// ErrInvalidNFInstanceID is returned when parsing a malformed NF instance
+// ID.
+var ErrInvalidNFInstanceID = errors.New("invalid NF instance ID, want a UUID such as 4947a69a-f61b-4bc1-b9da-47c9c5d14b64")
+
+// NFInstanceID is the NF instance ID of an NF instance, a UUID (TS 29.571
+// 5.3.2).
+type NFInstanceID [16]byte
+
+// dnsNamespace is the namespace of the name based UUIDs of DNS names (RFC
+// 4122 appendix C).
+var dnsNamespace = NFInstanceID{0x6b, 0xa7, 0xb8, 0x10, 0x9d, 0xad, 0x11, 0xd1, 0x80, 0xb4, 0x00, 0xc0, 0x4f, 0xd4, 0x30, 0xc8}
+
+// ParseNFInstanceID parses a UUID written in its canonical form.
+func ParseNFInstanceID(s string) (id NFInstanceID, err error) {
+	if len(s) != 36 || s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+		return id, ErrInvalidNFInstanceID
+	}
+	b, err := hex.DecodeString(s[0:8] + s[9:13] + s[14:18] + s[19:23] + s[24:])
+	if err != nil {
+		return id, ErrInvalidNFInstanceID
+	}
+	copy(id[:], b)
+	return id, nil
+}
+
+// NameBased returns the name based NF instance ID of the DNS name name,
+// such as <pod>.<service>.<namespace>, the same for the same name (RFC 4122
+// 4.3, version 5).
+func NameBased(name string) NFInstanceID {
+	h := sha1.New()
+	h.Write(dnsNamespace[:])
+	h.Write([]byte(name))
+	var id NFInstanceID
+	copy(id[:], h.Sum(nil))
+	id[6] = id[6]&0x0f | 0x50
+	id[8] = id[8]&0x3f | 0x80
+	return id
+}
+
+// String returns id in its canonical form, or the empty string for the
+// zero ID.
+func (id NFInstanceID) String() string {
+	if id.IsZero() {
+		return ""
+	}
+	s := hex.EncodeToString(id[:])
+	return s[0:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:]
+}
+
+// IsZero tells whether id is the zero ID, which identifies no instance.
+func (id NFInstanceID) IsZero() bool {
+	return id == NFInstanceID{}
+}
+
+// NF is the identity of an NF instance.
+type NF struct {
+	// Type is the NF type, such as UDM (TS 29.510 6.1.6.3.3).
+	Type       string
+	InstanceID NFInstanceID
+	// Name is the name of the instance, the pod name in Kubernetes.
+	Name string
+	// PLMNs are the PLMNs served, every one when empty.
+	PLMNs plmn.List
+	// GUAMIs are those of an AMF, GNBID that of a gNB, if any.
+	GUAMIs []GUAMI
+	GNBID  GNBID
+}
+
+// Keyvals returns the identity of n for a logger, the parts n lacks left
+// out.
+func (n NF) Keyvals() []interface{} {
+	keyvals := []interface{}{"nf_type", n.Type, "nf_instance_id", n.InstanceID.String(), "nf_instance_name", n.Name}
+	if len(n.GUAMIs) != 0 {
+		keyvals = append(keyvals, "guami", GUAMIList(n.GUAMIs).String())
+	}
+	if !n.GNBID.IsZero() {
+		keyvals = append(keyvals, "gnb_id", n.GNBID.String())
+	}
+	return keyvals
+}
+
+// Labels returns the identity of n as the pairs of label and value of a
+// metric.
+func (n NF) Labels() []string {
+	labels := []string{"nf_type", n.Type, "nf_instance_id", n.InstanceID.String(), "nf_instance_name", n.Name}
+	if !n.GNBID.IsZero() {
+		labels = append(labels, "gnb_id", n.GNBID.String())
+	}
+	return labels
+}
+
+// GUAMIList is a list of GUAMIs.
+type GUAMIList []GUAMI
+
+// ParseGUAMIList parses a comma separated list of GUAMIs. The empty string
+// gives an empty list.
+func ParseGUAMIList(s string) (GUAMIList, error) {
+	var l GUAMIList
+	for _, f := range strings.Split(s, ",") {
+		if f = strings.TrimSpace(f); f == "" {
+			continue
+		}
+		g, err := ParseGUAMI(f)
+		if err != nil {
+			return nil, err
+		}
+		l = append(l, g)
+	}
+	return l, nil
+}
+
+// String returns l as a comma separated list.
+func (l GUAMIList) String() string {
+	s := make([]string, len(l))
+	for i, g := range l {
+		s[i] = g.String()
+	}
+	return strings.Join(s, ",")
+}
@@ -0,0 +1,76 @@
+package identity
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// ProfilePath is the path of the admin port ProfileHandler is served on.
+const ProfilePath = "/admin/profile"
+
+// NFStatusRegistered is the status of an NF serving its profile.
+const NFStatusRegistered = "REGISTERED"
+
+// Profile is the NF profile of an NF instance, as it registers it with an
+// NRF (TS 29.510 6.1.6.2.2), down to its identity.
+type Profile struct {
+	NFInstanceID   string   `json:"nfInstanceId"`
+	NFInstanceName string   `json:"nfInstanceName,omitempty"`
+	NFType         string   `json:"nfType"`
+	NFStatus       string   `json:"nfStatus"`
+	PLMNList       []PLMNID `json:"plmnList,omitempty"`
+	AMFInfo        *AMFInfo `json:"amfInfo,omitempty"`
+}
+
+// PLMNID is a PLMN ID of an NF profile.
+type PLMNID struct {
+	MCC string `json:"mcc"`
+	MNC string `json:"mnc"`
+}
+
+// AMFInfo is the AMF Set, the AMF Region and the GUAMIs of an AMF.
+type AMFInfo struct {
+	AMFSetID    string  `json:"amfSetId"`
+	AMFRegionID string  `json:"amfRegionId"`
+	GUAMIList   []Guami `json:"guamiList"`
+}
+
+// Guami is a GUAMI of an NF profile.
+type Guami struct {
+	PLMNID PLMNID `json:"plmnId"`
+	AMFID  string `json:"amfId"`
+}
+
+// Profile returns the NF profile of n. The AMF Set and Region are those of
+// its first GUAMI, an AMF belonging to a single set.
+func (n NF) Profile() Profile {
+	p := Profile{
+		NFInstanceID:   n.InstanceID.String(),
+		NFInstanceName: n.Name,
+		NFType:         n.Type,
+		NFStatus:       NFStatusRegistered,
+	}
+	for _, id := range n.PLMNs {
+		p.PLMNList = append(p.PLMNList, PLMNID{MCC: id.MCC, MNC: id.MNC})
+	}
+	if len(n.GUAMIs) != 0 {
+		first := n.GUAMIs[0].AMFID
+		p.AMFInfo = &AMFInfo{AMFSetID: first.SetID(), AMFRegionID: first.RegionID()}
+		for _, g := range n.GUAMIs {
+			p.AMFInfo.GUAMIList = append(p.AMFInfo.GUAMIList, Guami{
+				PLMNID: PLMNID{MCC: g.PLMN.MCC, MNC: g.PLMN.MNC},
+				AMFID:  g.AMFID.String(),
+			})
+		}
+	}
+	return p
+}
+
+// ProfileHandler serves the NF profile of n in JSON.
+func ProfileHandler(n NF) http.Handler {
+	p := n.Profile()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(p)
+	})
+}
@@ -0,0 +1,82 @@
+package identity
+
+import (
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidSNSSAI is returned when parsing a malformed S-NSSAI.
+var ErrInvalidSNSSAI = errors.New("invalid S-NSSAI, want SST or SST-SD such as 1-010203")
+
+// SNSSAI identifies a network slice (TS 23.003 28.4.2).
+type SNSSAI struct {
+	SST uint8 `json:"sst"`
+	// SD is the 6 hex digit slice differentiator, empty when absent.
+	SD string `json:"sd,omitempty"`
+}
+
+// ParseSNSSAI parses an S-NSSAI written SST or SST-SD, such as 1-010203.
+func ParseSNSSAI(s string) (SNSSAI, error) {
+	sst, sd := s, ""
+	if i := strings.IndexByte(s, '-'); i >= 0 {
+		sst, sd = s[:i], strings.ToLower(s[i+1:])
+		if b, err := hex.DecodeString(sd); err != nil || len(b) != 3 {
+			return SNSSAI{}, ErrInvalidSNSSAI
+		}
+	}
+	v, err := strconv.ParseUint(sst, 10, 8)
+	if err != nil {
+		return SNSSAI{}, ErrInvalidSNSSAI
+	}
+	return SNSSAI{SST: uint8(v), SD: sd}, nil
+}
+
+// String returns s as SST or SST-SD, as the slices of the feature flags
+// are written.
+func (s SNSSAI) String() string {
+	if s.SD == "" {
+		return strconv.Itoa(int(s.SST))
+	}
+	return strconv.Itoa(int(s.SST)) + "-" + s.SD
+}
+
+// NSSAI is a list of S-NSSAIs.
+type NSSAI []SNSSAI
+
+// ParseNSSAI parses a comma separated list of S-NSSAIs. The empty string
+// gives an empty list.
+func ParseNSSAI(s string) (NSSAI, error) {
+	var l NSSAI
+	for _, f := range strings.Split(s, ",") {
+		if f = strings.TrimSpace(f); f == "" {
+			continue
+		}
+		sn, err := ParseSNSSAI(f)
+		if err != nil {
+			return nil, err
+		}
+		l = append(l, sn)
+	}
+	return l, nil
+}
+
+// Contains tells whether l has the S-NSSAI s.
+func (l NSSAI) Contains(s SNSSAI) bool {
+	for _, sn := range l {
+		if sn == s {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns l as a comma separated list.
+func (l NSSAI) String() string {
+	s := make([]string, len(l))
+	for i, sn := range l {
+		s[i] = sn.String()
+	}
+	return strings.Join(s, ",")
+}
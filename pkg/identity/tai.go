@@ -0,0 +1,74 @@
+package identity
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/plmn"
+)
+
+var (
+	// ErrInvalidTAC is returned when parsing a malformed TAC.
+	ErrInvalidTAC = errors.New("invalid TAC, want 6 hexadecimal digits such as 000001")
+	// ErrInvalidTAI is returned when parsing a malformed TAI.
+	ErrInvalidTAI = errors.New("invalid TAI, want MCC-MNC-TAC such as 208-93-000001")
+)
+
+// TAI identifies a tracking area (TS 23.003 19.4.2.3): the PLMN and the
+// 24 bit TAC of 5GS.
+type TAI struct {
+	PLMN plmn.ID
+	TAC  uint32
+}
+
+// ParseTAC parses a TAC written as its 24 bits in hexadecimal, as in TS
+// 29.571 5.4.2, such as 000001.
+func ParseTAC(s string) (uint32, error) {
+	if len(s) != 6 {
+		return 0, ErrInvalidTAC
+	}
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return 0, ErrInvalidTAC
+	}
+	return uint32(v), nil
+}
+
+// ParseTAI parses a TAI written MCC-MNC-TAC, such as 208-93-000001.
+func ParseTAI(s string) (TAI, error) {
+	i := strings.LastIndexByte(s, '-')
+	if i < 0 {
+		return TAI{}, ErrInvalidTAI
+	}
+	id, err := plmn.Parse(s[:i])
+	if err != nil {
+		return TAI{}, ErrInvalidTAI
+	}
+	tac, err := ParseTAC(s[i+1:])
+	if err != nil {
+		return TAI{}, ErrInvalidTAI
+	}
+	return TAI{PLMN: id, TAC: tac}, nil
+}
+
+// String returns t as MCC-MNC-TAC.
+func (t TAI) String() string {
+	return fmt.Sprintf("%s-%06x", t.PLMN, t.TAC)
+}
+
+// MarshalText writes t as MCC-MNC-TAC.
+func (t TAI) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText parses t written MCC-MNC-TAC.
+func (t *TAI) UnmarshalText(b []byte) error {
+	v, err := ParseTAI(string(b))
+	if err != nil {
+		return err
+	}
+	*t = v
+	return nil
+}
@@ -0,0 +1,120 @@
+package idpool
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/store"
+)
+
+// NewTEIDPool returns the pool named name of the GTP-U TEIDs. TEID 0 is
+// reserved (TS 29.281) and never allocated.
+func NewTEIDPool(ctx context.Context, st store.Store, name string, options ...Option) (*Pool, error) {
+	return New(ctx, st, name, 1, math.MaxUint32, options...)
+}
+
+// IPPool allocates the UE addresses of an IPv4 or IPv6 prefix. The
+// identifiers of the underlying Pool, as found in its Allocations, are the
+// offsets of the addresses in the prefix.
+type IPPool struct {
+	*Pool
+	network *net.IPNet
+}
+
+// NewIPPool returns the pool named name of the addresses of cidr, such as
+// "10.45.0.0/16" or "2001:db8:cafe::/48". The first address of the prefix is
+// not allocated, nor the broadcast address of an IPv4 prefix. Only the first
+// 2^64 addresses of larger IPv6 prefixes are used.
+func NewIPPool(ctx context.Context, st store.Store, name, cidr string, options ...Option) (*IPPool, error) {
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil, err
+	}
+	if ip4 := network.IP.To4(); ip4 != nil {
+		network.IP = ip4
+	}
+	ones, bits := network.Mask.Size()
+	host := uint(bits - ones)
+	last := uint64(math.MaxUint64)
+	if host < 64 {
+		last = 1<<host - 1
+	}
+	if bits == 8*net.IPv4len {
+		last--
+	}
+	if last < 1 {
+		return nil, errors.New("idpool: no address to allocate in " + cidr)
+	}
+	p, err := New(ctx, st, name, 1, last, options...)
+	if err != nil {
+		return nil, err
+	}
+	return &IPPool{Pool: p, network: network}, nil
+}
+
+// Allocate allocates a free address to owner.
+func (p *IPPool) Allocate(ctx context.Context, owner string) (net.IP, error) {
+	id, err := p.Pool.Allocate(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+	return p.IP(id), nil
+}
+
+// Renew extends the lease of the address allocated to owner.
+func (p *IPPool) Renew(ctx context.Context, ip net.IP, owner string) error {
+	id, ok := p.Offset(ip)
+	if !ok {
+		return ErrNotAllocated
+	}
+	return p.Pool.Renew(ctx, id, owner)
+}
+
+// Release frees the address allocated to owner.
+func (p *IPPool) Release(ctx context.Context, ip net.IP, owner string) error {
+	id, ok := p.Offset(ip)
+	if !ok {
+		return ErrNotAllocated
+	}
+	return p.Pool.Release(ctx, id, owner)
+}
+
+// IP returns the address at offset id in the prefix.
+func (p *IPPool) IP(id uint64) net.IP {
+	ip := make(net.IP, len(p.network.IP))
+	copy(ip, p.network.IP)
+	for i := len(ip) - 1; i >= 0 && id > 0; i-- {
+		sum := uint64(ip[i]) + id&0xff
+		ip[i] = byte(sum)
+		id = id>>8 + sum>>8
+	}
+	return ip
+}
+
+// Offset returns the offset of ip in the prefix, and whether ip is in the
+// prefix at all.
+func (p *IPPool) Offset(ip net.IP) (uint64, bool) {
+	if ip4 := ip.To4(); ip4 != nil && len(p.network.IP) == net.IPv4len {
+		ip = ip4
+	}
+	if len(ip) != len(p.network.IP) || !p.network.Contains(ip) {
+		return 0, false
+	}
+	var id uint64
+	start := len(ip) - 8
+	if start < 0 {
+		start = 0
+	}
+	for i := 0; i < start; i++ {
+		// past the first 2^64 addresses
+		if ip[i]&^p.network.Mask[i] != 0 {
+			return 0, false
+		}
+	}
+	for i := start; i < len(ip); i++ {
+		id = id<<8 | uint64(ip[i]&^p.network.Mask[i])
+	}
+	return id, true
+}
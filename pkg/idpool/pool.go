@@ -0,0 +1,330 @@
+// Package idpool allocates the identifiers the user plane needs per session,
+// GTP-U TEIDs and UE IP addresses, from fixed ranges. Allocations are kept in
+// a store, so that they survive restarts and are shared by the replicas using
+// the same Redis server. Every allocation holds a lease its owner renews; the
+// leases of sessions torn down without releasing their identifiers run out,
+// and the reclamation job reports them as leaks and frees them.
+package idpool
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/metrics"
+	"github.com/go-kit/kit/metrics/discard"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/schema"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/store"
+)
+
+// KeyPrefix starts the store keys of the allocations of every pool.
+const KeyPrefix = "idpool/"
+
+// Schema versions the allocations in the store.
+var Schema = &schema.Schema{Name: "idpool", Prefix: KeyPrefix}
+
+var (
+	// ErrExhausted is returned when every identifier of a pool is allocated.
+	ErrExhausted = errors.New("idpool: pool exhausted")
+	// ErrNotAllocated is returned for an identifier that is not allocated,
+	// or not to the owner given.
+	ErrNotAllocated = errors.New("idpool: identifier not allocated")
+)
+
+// Option sets an optional parameter of a Pool.
+type Option func(*Pool)
+
+// Lease sets how long an allocation lasts without being renewed. The zero
+// lease, the default, never runs out, and nothing is reclaimed.
+func Lease(d time.Duration) Option {
+	return func(p *Pool) { p.lease = d }
+}
+
+// Logger sets the logger reporting the leaks.
+func Logger(logger log.Logger) Option {
+	return func(p *Pool) { p.logger = logger }
+}
+
+// LeakCounter sets the counter incremented for every allocation reclaimed.
+func LeakCounter(c metrics.Counter) Option {
+	return func(p *Pool) { p.leakCounter = c }
+}
+
+// Allocation is an identifier of a pool and the session holding it.
+type Allocation struct {
+	ID        uint64    `json:"id"`
+	Owner     string    `json:"owner"`
+	Allocated time.Time `json:"allocated"`
+	// Expires is zero when the lease never runs out.
+	Expires time.Time `json:"expires,omitempty"`
+}
+
+func (a Allocation) expired(now time.Time) bool {
+	return !a.Expires.IsZero() && !now.Before(a.Expires)
+}
+
+// Pool allocates the identifiers of the range [first, last].
+type Pool struct {
+	name        string
+	first, last uint64
+	st          store.Store
+	lease       time.Duration
+	logger      log.Logger
+	leakCounter metrics.Counter
+
+	mtx  sync.Mutex
+	used map[uint64]bool
+	next uint64
+}
+
+// New returns the pool named name of the identifiers first to last, whose
+// allocations are kept in st. The allocations already in st, made before a
+// restart or by another replica, are loaded.
+func New(ctx context.Context, st store.Store, name string, first, last uint64, options ...Option) (*Pool, error) {
+	if last < first {
+		return nil, errors.New("idpool: empty range")
+	}
+	p := &Pool{
+		name:        name,
+		first:       first,
+		last:        last,
+		st:          st,
+		logger:      log.NewNopLogger(),
+		leakCounter: discard.NewCounter(),
+		used:        map[uint64]bool{},
+		next:        first,
+	}
+	for _, option := range options {
+		option(p)
+	}
+	allocs, err := p.Allocations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range allocs {
+		p.used[a.ID] = true
+	}
+	return p, nil
+}
+
+// Allocate allocates a free identifier to owner, the session using it. The
+// identifiers are handed out in turn, so that a released one is not reused
+// before the others.
+func (p *Pool) Allocate(ctx context.Context, owner string) (uint64, error) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	// size is 0 for the whole range of uint64
+	size := p.last - p.first + 1
+	for tried := uint64(0); size == 0 || tried < size; tried++ {
+		if size != 0 && uint64(len(p.used)) >= size {
+			break
+		}
+		id := p.next
+		if p.next == p.last {
+			p.next = p.first
+		} else {
+			p.next++
+		}
+		if p.used[id] {
+			continue
+		}
+		now := time.Now()
+		a := Allocation{ID: id, Owner: owner, Allocated: now}
+		if p.lease > 0 {
+			a.Expires = now.Add(p.lease)
+		}
+		data, _ := json.Marshal(a)
+		created, err := p.st.SetNX(ctx, p.key(id), data, 0)
+		if err != nil {
+			return 0, err
+		}
+		p.used[id] = true
+		if created {
+			return id, nil
+		}
+		// allocated by another replica
+	}
+	return 0, ErrExhausted
+}
+
+// Renew extends the lease of the identifier allocated to owner. The lease
+// is only written back over the allocation it read, so that an allocation
+// reclaimed, and maybe allocated again, meanwhile is not renewed.
+func (p *Pool) Renew(ctx context.Context, id uint64, owner string) error {
+	for {
+		a, old, err := p.get(ctx, id, owner)
+		if err != nil {
+			return err
+		}
+		if p.lease > 0 {
+			a.Expires = time.Now().Add(p.lease)
+		}
+		data, _ := json.Marshal(a)
+		swapped, err := p.st.CompareAndSwap(ctx, p.key(id), old, data, 0)
+		if err != nil || swapped {
+			return err
+		}
+		// changed since it was read, by a renewal or a reclamation
+	}
+}
+
+// Release frees the identifier allocated to owner, only if it is still
+// allocated to owner as it is deleted.
+func (p *Pool) Release(ctx context.Context, id uint64, owner string) error {
+	for {
+		_, old, err := p.get(ctx, id, owner)
+		if err != nil {
+			return err
+		}
+		deleted, err := p.st.CompareAndDelete(ctx, p.key(id), old)
+		if err != nil {
+			return err
+		}
+		if deleted {
+			break
+		}
+	}
+	p.mtx.Lock()
+	delete(p.used, id)
+	p.mtx.Unlock()
+	return nil
+}
+
+// Allocations returns the allocations of the pool, in the order of the keys
+// of the store. It reads the whole pool and is meant for administration.
+func (p *Pool) Allocations(ctx context.Context) ([]Allocation, error) {
+	stored, err := p.allocations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	allocs := make([]Allocation, 0, len(stored))
+	for _, s := range stored {
+		allocs = append(allocs, s.Allocation)
+	}
+	return allocs, nil
+}
+
+// stored is an allocation and its record in the store.
+type stored struct {
+	Allocation
+	data []byte
+}
+
+func (p *Pool) allocations(ctx context.Context) ([]stored, error) {
+	keys, err := p.st.Keys(ctx, p.prefix())
+	if err != nil {
+		return nil, err
+	}
+	allocs := make([]stored, 0, len(keys))
+	for _, key := range keys {
+		data, err := p.st.Get(ctx, key)
+		if err == store.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		var a Allocation
+		if err := json.Unmarshal(data, &a); err != nil {
+			continue
+		}
+		allocs = append(allocs, stored{Allocation: a, data: data})
+	}
+	return allocs, nil
+}
+
+// Reclaim frees the allocations whose lease ran out, reporting each of them
+// as a leak, and returns how many it freed. An allocation renewed or
+// released since it was read is left alone. It also forgets the
+// identifiers released by other replicas.
+func (p *Pool) Reclaim(ctx context.Context) (int, error) {
+	allocs, err := p.allocations(ctx)
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now()
+	used := make(map[uint64]bool, len(allocs))
+	n := 0
+	for _, a := range allocs {
+		if !a.expired(now) {
+			used[a.ID] = true
+			continue
+		}
+		deleted, err := p.st.CompareAndDelete(ctx, p.key(a.ID), a.data)
+		if err != nil || !deleted {
+			used[a.ID] = true
+			continue
+		}
+		p.logger.Log("pool", p.name, "leak", a.ID, "owner", a.Owner, "allocated", a.Allocated, "expired", a.Expires)
+		p.leakCounter.Add(1)
+		n++
+	}
+	p.mtx.Lock()
+	p.used = used
+	p.mtx.Unlock()
+	return n, nil
+}
+
+// Run reclaims the leaked allocations every interval until ctx is done.
+func (p *Pool) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := p.Reclaim(ctx); err != nil {
+				p.logger.Log("pool", p.name, "reclaim", "failed", "err", err)
+			}
+		}
+	}
+}
+
+// Stats is a point in time view of a pool.
+type Stats struct {
+	Name      string `json:"name"`
+	First     uint64 `json:"first"`
+	Last      uint64 `json:"last"`
+	Allocated int    `json:"allocated"`
+}
+
+// Stats returns the current state of the pool, as known to this replica.
+func (p *Pool) Stats() Stats {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	return Stats{Name: p.name, First: p.first, Last: p.last, Allocated: len(p.used)}
+}
+
+// get returns the identifier id allocated to owner, and its record in the
+// store.
+func (p *Pool) get(ctx context.Context, id uint64, owner string) (Allocation, []byte, error) {
+	data, err := p.st.Get(ctx, p.key(id))
+	if err == store.ErrNotFound {
+		return Allocation{}, nil, ErrNotAllocated
+	}
+	if err != nil {
+		return Allocation{}, nil, err
+	}
+	var a Allocation
+	if err := json.Unmarshal(data, &a); err != nil {
+		return Allocation{}, nil, err
+	}
+	if a.Owner != owner {
+		return Allocation{}, nil, ErrNotAllocated
+	}
+	return a, data, nil
+}
+
+func (p *Pool) prefix() string {
+	return KeyPrefix + p.name + "/"
+}
+
+func (p *Pool) key(id uint64) string {
+	return p.prefix() + strconv.FormatUint(id, 10)
+}
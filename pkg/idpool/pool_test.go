@@ -0,0 +1,142 @@
+package idpool
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/store"
+)
+
+// racingStore runs race once, right after the first read of an
+// allocation, as another replica would between the read and the write.
+type racingStore struct {
+	store.Store
+	race func()
+	once sync.Once
+}
+
+func (s *racingStore) Get(ctx context.Context, key string) ([]byte, error) {
+	data, err := s.Store.Get(ctx, key)
+	s.once.Do(s.race)
+	return data, err
+}
+
+// expire stores the allocation of id to owner of p, its lease run out.
+func expire(t *testing.T, p *Pool, id uint64, owner string) {
+	t.Helper()
+	now := time.Now()
+	data, _ := json.Marshal(Allocation{ID: id, Owner: owner, Allocated: now.Add(-2 * time.Hour), Expires: now.Add(-time.Hour)})
+	if err := p.st.Set(context.Background(), p.key(id), data, 0); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAllocateConcurrently(t *testing.T) {
+	ctx := context.Background()
+	st := store.NewMemory()
+	// two replicas sharing the store
+	var replicas []*Pool
+	for i := 0; i < 2; i++ {
+		p, err := New(ctx, st, "teid", 1, 100)
+		if err != nil {
+			t.Fatal(err)
+		}
+		replicas = append(replicas, p)
+	}
+	var (
+		wg  sync.WaitGroup
+		mtx sync.Mutex
+		ids = map[uint64]int{}
+	)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(p *Pool) {
+			defer wg.Done()
+			for j := 0; j < 5; j++ {
+				id, err := p.Allocate(ctx, "session")
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				mtx.Lock()
+				ids[id]++
+				mtx.Unlock()
+			}
+		}(replicas[i%2])
+	}
+	wg.Wait()
+	if len(ids) != 100 {
+		t.Fatalf("%d identifiers allocated, want 100", len(ids))
+	}
+	for id, n := range ids {
+		if n != 1 {
+			t.Errorf("identifier %d allocated %d times", id, n)
+		}
+	}
+	for _, p := range replicas {
+		if _, err := p.Allocate(ctx, "session"); err != ErrExhausted {
+			t.Errorf("Allocate of a full pool: %v, want %v", err, ErrExhausted)
+		}
+	}
+}
+
+func TestRenewWhileReclaiming(t *testing.T) {
+	ctx := context.Background()
+	st := store.NewMemory()
+	other, err := New(ctx, st, "teid", 1, 1, Lease(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expire(t, other, 1, "a")
+	reclaimed := 0
+	p, err := New(ctx, st, "teid", 1, 1, Lease(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the lease runs out as it is renewed
+	p.st = &racingStore{Store: st, race: func() { reclaimed, _ = other.Reclaim(ctx) }}
+	if err := p.Renew(ctx, 1, "a"); err != ErrNotAllocated {
+		t.Fatalf("Renew of a reclaimed allocation: %v, want %v", err, ErrNotAllocated)
+	}
+	if reclaimed != 1 {
+		t.Fatalf("%d allocations reclaimed, want 1", reclaimed)
+	}
+	if allocs, _ := p.Allocations(ctx); len(allocs) != 0 {
+		t.Fatalf("reclaimed allocation renewed: %+v", allocs)
+	}
+}
+
+func TestReleaseWhileReallocating(t *testing.T) {
+	ctx := context.Background()
+	st := store.NewMemory()
+	other, err := New(ctx, st, "teid", 1, 1, Lease(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expire(t, other, 1, "a")
+	p, err := New(ctx, st, "teid", 1, 1, Lease(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the other replica reclaims the lease and allocates the identifier
+	// anew as it is released
+	p.st = &racingStore{Store: st, race: func() {
+		other.Reclaim(ctx)
+		if _, err := other.Allocate(ctx, "b"); err != nil {
+			t.Error(err)
+		}
+	}}
+	if err := p.Release(ctx, 1, "a"); err != ErrNotAllocated {
+		t.Fatalf("Release of a reallocated identifier: %v, want %v", err, ErrNotAllocated)
+	}
+	allocs, err := p.Allocations(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(allocs) != 1 || allocs[0].Owner != "b" {
+		t.Fatalf("allocations after the release of a: %+v, want that of b", allocs)
+	}
+}
@@ -0,0 +1,228 @@
+// Package chain assembles the middleware stack of the endpoints of a
+// service from a declarative MiddlewareConfig, so that every service runs the
+// same middlewares in the same order. A middleware added here is adopted by
+// every service setting its field.
+//
+// The stack, from the endpoint out:
+//
+//	recovery → idempotency → priority admission → rate limit → circuit breaker
+//	→ per-caller quota → opentracing → zipkin → logging → instrumenting
+//	→ ue trace → procedure metadata → load → slo → capture
+package chain
+
+import (
+	"time"
+
+	"github.com/go-kit/kit/circuitbreaker"
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/metrics"
+	"github.com/go-kit/kit/ratelimit"
+	"github.com/go-kit/kit/tracing/opentracing"
+	"github.com/go-kit/kit/tracing/zipkin"
+	stdopentracing "github.com/opentracing/opentracing-go"
+	stdzipkin "github.com/openzipkin/zipkin-go"
+	"github.com/sony/gobreaker"
+	"golang.org/x/time/rate"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/autoscale"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/breakers"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/capture"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/clock"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/idempotency"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/meta"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/priority"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/quota"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/recovery"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/slo"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/store"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/uetrace"
+)
+
+// The rate limit of each method of the services, one request per second on
+// average with bursts of 100.
+const (
+	DefaultRate  = rate.Limit(1)
+	DefaultBurst = 100
+)
+
+// MiddlewareConfig declares the middlewares of the endpoints of a service.
+// The zero value of a field leaves its middleware out; the recovery always
+// goes in.
+type MiddlewareConfig struct {
+	Logger log.Logger
+	// Logging is the logging middleware of the service, given the logger of
+	// a method and the clock timing its requests. The endpoints packages set
+	// their LoggingMiddleware.
+	Logging      func(log.Logger, clock.Clock) endpoint.Middleware
+	OTTracer     stdopentracing.Tracer
+	ZipkinTracer *stdzipkin.Tracer
+
+	// Duration observes the seconds the requests of each method take,
+	// labelled with the method, through Instrumenting, the instrumenting
+	// middleware of the service given the histogram of a method. The
+	// endpoints packages that have one set Instrumenting.
+	Duration      metrics.Histogram
+	Instrumenting func(metrics.Histogram) endpoint.Middleware
+
+	// Rate and Burst limit the requests of each method.
+	Rate  rate.Limit
+	Burst int
+	// Breaker puts a circuit breaker, named after the method, in front of
+	// each method.
+	Breaker bool
+	// Limiter holds each caller to its limits.
+	Limiter quota.Limiter
+	// Admission admits the requests by priority.
+	Admission *priority.Admission
+	// EmergencyBypass lets the emergency requests skip the rate limits and
+	// the per-caller limits.
+	EmergencyBypass bool
+
+	// Store keeps the responses to the requests carrying an idempotency key
+	// for IdempotencyWindow, and replays them to retries, for the methods
+	// built with Idempotent.
+	Store             store.Store
+	IdempotencyWindow time.Duration
+
+	// UETrace logs the requests of the UEs whose trace is active, inside
+	// the procedure metadata which tells their UE.
+	UETrace *uetrace.List
+
+	// NFInstanceID is the NF instance of the service, which starts a
+	// procedure for the requests that are not part of one (see pkg/meta).
+	NFInstanceID string
+
+	// Clock is the clock of the rate limits and of the durations logged,
+	// clock.Real when nil.
+	Clock clock.Clock
+
+	// Recorder records every call.
+	Recorder capture.Recorder
+	// Load counts the calls of the signalling methods, those shed or
+	// limited included, for the NF to be scaled on.
+	Load *autoscale.Load
+	// SLO classifies the responses of the signalling methods against
+	// their objectives, the shed and limited ones included, tracking their
+	// error budgets.
+	SLO *slo.Tracker
+
+	// OAM, when set, replaces the rate limits and the admission above for
+	// the operation and maintenance methods, those built with OAM, so that
+	// a runaway dashboard or provisioning script cannot hold the signalling
+	// back. Left nil, they share the limits of the signalling.
+	OAM *Limits
+}
+
+// Limits are the limits of a class of methods.
+type Limits struct {
+	// Rate and Burst limit the requests of each method.
+	Rate  rate.Limit
+	Burst int
+	// Admission admits the requests by priority.
+	Admission *priority.Admission
+}
+
+// Option sets an optional parameter of the stack of a method.
+type Option func(*options)
+
+type options struct {
+	response interface{}
+	oam      bool
+}
+
+// Idempotent makes the method idempotent, response being a zero value of its
+// response type.
+func Idempotent(response interface{}) Option {
+	return func(o *options) { o.response = response }
+}
+
+// OAM makes the method an operation and maintenance one, held to the
+// MiddlewareConfig.OAM limits.
+func OAM() Option {
+	return func(o *options) { o.oam = true }
+}
+
+// Chain builds the middleware stacks of a MiddlewareConfig.
+type Chain struct {
+	cfg MiddlewareConfig
+}
+
+// New returns a Chain building the stacks of cfg.
+func New(cfg MiddlewareConfig) *Chain {
+	if cfg.Logger == nil {
+		cfg.Logger = log.NewNopLogger()
+	}
+	return &Chain{cfg: cfg}
+}
+
+// Build returns e, the endpoint of method, wrapped in its stack.
+func (c *Chain) Build(method string, e endpoint.Endpoint, opts ...Option) endpoint.Endpoint {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	cfg := c.cfg
+	if o.oam && cfg.OAM != nil {
+		cfg.Rate, cfg.Burst, cfg.Admission = cfg.OAM.Rate, cfg.OAM.Burst, cfg.OAM.Admission
+	}
+	limit := func(mw endpoint.Middleware) endpoint.Middleware {
+		if cfg.EmergencyBypass {
+			return priority.Bypass(mw)
+		}
+		return mw
+	}
+	clk := clock.Or(cfg.Clock)
+
+	e = recovery.Middleware(cfg.Logger, method)(e)
+	if cfg.Store != nil && o.response != nil {
+		e = idempotency.Middleware(cfg.Store, cfg.IdempotencyWindow, method, o.response)(e)
+	}
+	if cfg.Admission != nil {
+		e = priority.Middleware(cfg.Admission)(e)
+	}
+	if cfg.Rate > 0 {
+		e = limit(ratelimit.NewErroringLimiter(allower{rate.NewLimiter(cfg.Rate, cfg.Burst), clk}))(e)
+	}
+	if cfg.Breaker {
+		e = circuitbreaker.Gobreaker(breakers.New(gobreaker.Settings{Name: method}))(e)
+	}
+	if cfg.Limiter != nil {
+		e = limit(quota.Middleware(cfg.Limiter, method))(e)
+	}
+	if cfg.OTTracer != nil {
+		e = opentracing.TraceServer(cfg.OTTracer, method)(e)
+	}
+	if cfg.ZipkinTracer != nil {
+		e = zipkin.TraceEndpoint(cfg.ZipkinTracer, method)(e)
+	}
+	if cfg.Logging != nil {
+		e = cfg.Logging(log.With(cfg.Logger, "method", method), clk)(e)
+	}
+	if cfg.Duration != nil && cfg.Instrumenting != nil {
+		e = cfg.Instrumenting(cfg.Duration.With("method", method))(e)
+	}
+	if cfg.UETrace != nil {
+		e = uetrace.Middleware(cfg.UETrace, method)(e)
+	}
+	if cfg.NFInstanceID != "" {
+		e = meta.Middleware(cfg.NFInstanceID)(e)
+	}
+	if cfg.Load != nil && !o.oam {
+		e = autoscale.Middleware(cfg.Load, method)(e)
+	}
+	if cfg.SLO != nil && !o.oam {
+		e = slo.Middleware(cfg.SLO, method)(e)
+	}
+	return capture.Middleware(cfg.Recorder, method)(e)
+}
+
+// allower takes the tokens of a rate.Limiter at the time of a clock rather
+// than of the time package.
+type allower struct {
+	l   *rate.Limiter
+	clk clock.Clock
+}
+
+// Allow implements ratelimit.Allower.
+func (a allower) Allow() bool { return a.l.AllowN(a.clk.Now(), 1) }
@@ -0,0 +1,108 @@
+// Package fuzz is what the fuzzing harnesses of the transports share: the
+// format of their inputs and the names of the seeds of their corpora.
+//
+// The harnesses are in the fuzz.go files of their packages, and their
+// go-fuzz functions in the gofuzz.go files, built only with the gofuzz
+// build tag. A test of every package runs the seeds of the corpus of its
+// harnesses through them (see Corpus), so that go test catches an input
+// that panics one. An HTTP harness serves its input to the HTTP handler of
+// a service whose endpoints answer without calling the service, so that
+// every codec and request decoder gets the malformed bodies a rogue peer
+// could send. Its seeds are the requests captured from the service (see
+// cmd/replay -corpus). The other harnesses play lines of messages, and
+// document their format.
+package fuzz
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+)
+
+// The scores a harness returns to go-fuzz for an input.
+const (
+	// Interesting inputs were served; go-fuzz favours them.
+	Interesting = 1
+	// Normal inputs were rejected by the decoders.
+	Normal = 0
+	// Discard inputs are not in the format of the harness, go-fuzz drops
+	// them from the corpus.
+	Discard = -1
+)
+
+// HTTPSeed returns the input of an HTTP harness posting body to path, with
+// the content type contentType: the path and the content type on the first
+// line, the body after it, as in
+//
+//	/generateAuthData application/json
+//	{"supi":"imsi-208930000000001","serving_network_name":"5G:mnc093.mcc208.3gppnetwork.org"}
+func HTTPSeed(path, contentType string, body []byte) []byte {
+	return append([]byte(path+" "+contentType+"\n"), body...)
+}
+
+// HTTPRequest returns the request of the input data of an HTTP harness, in
+// the format of HTTPSeed, and false when data is not in it.
+func HTTPRequest(data []byte) (*http.Request, bool) {
+	i := bytes.IndexByte(data, '\n')
+	if i < 0 {
+		return nil, false
+	}
+	f := bytes.Fields(data[:i])
+	if len(f) != 2 || f[0][0] != '/' {
+		return nil, false
+	}
+	r, err := http.NewRequest(http.MethodPost, "http://fuzz"+string(f[0]), bytes.NewReader(data[i+1:]))
+	if err != nil {
+		return nil, false
+	}
+	r.Header.Set("Content-Type", string(f[1]))
+	return r, true
+}
+
+// Serve serves the input data of an HTTP harness to h and returns its
+// score.
+func Serve(h http.Handler, data []byte) int {
+	r, ok := HTTPRequest(data)
+	if !ok {
+		return Discard
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code == http.StatusOK {
+		return Interesting
+	}
+	return Normal
+}
+
+// Name returns the file name of the seed data in a corpus, its SHA-1 in
+// hex, as go-fuzz names the inputs it finds.
+func Name(data []byte) string {
+	sum := sha1.Sum(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Corpus returns the seeds of the corpus of the harness fuzzFunc, kept in
+// testdata/fuzz/<fuzzFunc>/corpus of the package, by file name.
+func Corpus(fuzzFunc string) (map[string][]byte, error) {
+	dir := filepath.Join("testdata", "fuzz", fuzzFunc, "corpus")
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	seeds := make(map[string][]byte, len(files))
+	for _, f := range files {
+		if f.IsDir() {
+			continue
+		}
+		data, err := ioutil.ReadFile(filepath.Join(dir, f.Name()))
+		if err != nil {
+			return nil, err
+		}
+		seeds[f.Name()] = data
+	}
+	return seeds, nil
+}
@@ -0,0 +1,297 @@
+// Package inproc is a go-kit transport binding the client endpoints of a
+// service to its server endpoints in the same process, through channels
+// rather than a network, for the integration tests that want neither ports
+// nor containers.
+//
+// It carries everything the gRPC transport does: the client encodes its
+// request to the protobuf message of the method and marshals it, the
+// ClientBefore functions write the metadata, and the server unmarshals the
+// message, reads the metadata with its ServerBefore functions, decodes the
+// request and serves it through the middleware chain of its endpoint. The
+// reply goes back the same way. Only the deadline and cancellation of the
+// context of the client reach the server, not its values, and errors reach
+// the client as gRPC statuses, as they would over the wire.
+package inproc
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/transport"
+	grpctransport "github.com/go-kit/kit/transport/grpc"
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	// ErrServerStopped is returned by Serve once the server is closed.
+	ErrServerStopped = errors.New("inproc: server stopped")
+	// ErrUnavailable is returned to the clients of a closed server.
+	ErrUnavailable = status.Error(codes.Unavailable, "inproc: server stopped")
+)
+
+// Addr is the address of the peer of every call, as the server sees it.
+type Addr struct{}
+
+// Network implements net.Addr.
+func (Addr) Network() string { return "inproc" }
+
+// String implements net.Addr.
+func (Addr) String() string { return "inproc" }
+
+// call is a request on its way to the server, result its reply.
+type call struct {
+	ctx    context.Context
+	method string
+	md     metadata.MD
+	req    []byte
+	done   chan result
+}
+
+type result struct {
+	rep []byte
+	err error
+}
+
+// Server serves the methods registered with Handle to the clients bound to
+// it by NewClient.
+type Server struct {
+	mtx      sync.RWMutex
+	handlers map[string]*handler
+
+	calls     chan call
+	quit      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewServer returns a Server with no methods. The clients block until it is
+// served.
+func NewServer() *Server {
+	return &Server{
+		handlers: map[string]*handler{},
+		calls:    make(chan call),
+		quit:     make(chan struct{}),
+	}
+}
+
+// Handle serves method with e. Like the gRPC transport, request is the
+// protobuf message of the request, which dec converts to the request of e,
+// and enc converts the response of e to the protobuf message of the reply.
+func (s *Server) Handle(method string, e endpoint.Endpoint, dec grpctransport.DecodeRequestFunc, enc grpctransport.EncodeResponseFunc, request interface{}, options ...ServerOption) {
+	h := &handler{
+		e:            e,
+		dec:          dec,
+		enc:          enc,
+		request:      reflect.TypeOf(request),
+		errorHandler: transport.NewLogErrorHandler(log.NewNopLogger()),
+		errorEncoder: func(err error) error { return err },
+	}
+	for _, option := range options {
+		option(h)
+	}
+	s.mtx.Lock()
+	s.handlers[method] = h
+	s.mtx.Unlock()
+}
+
+// Serve serves the calls of the clients, each in its own goroutine, until
+// the server is closed. It returns ErrServerStopped.
+func (s *Server) Serve() error {
+	for {
+		select {
+		case c := <-s.calls:
+			go s.serve(c)
+		case <-s.quit:
+			return ErrServerStopped
+		}
+	}
+}
+
+// Close stops the server. The calls it is serving run to completion, the
+// clients of the others get ErrUnavailable.
+func (s *Server) Close() {
+	s.closeOnce.Do(func() { close(s.quit) })
+}
+
+func (s *Server) serve(c call) {
+	s.mtx.RLock()
+	h, ok := s.handlers[c.method]
+	s.mtx.RUnlock()
+	if !ok {
+		c.done <- result{err: status.Errorf(codes.Unimplemented, "unknown method %s", c.method)}
+		return
+	}
+	rep, err := h.serve(c)
+	c.done <- result{rep: rep, err: wire(err)}
+}
+
+// ServerOption sets an optional parameter of the handler of a method.
+type ServerOption func(*handler)
+
+// ServerBefore adds functions reading the metadata of the request into the
+// context, as the gRPC ServerBefore option does.
+func ServerBefore(before ...grpctransport.ServerRequestFunc) ServerOption {
+	return func(h *handler) { h.before = append(h.before, before...) }
+}
+
+// ServerErrorHandler handles the errors of the method, which are otherwise
+// dropped.
+func ServerErrorHandler(errorHandler transport.ErrorHandler) ServerOption {
+	return func(h *handler) { h.errorHandler = errorHandler }
+}
+
+// ServerErrorEncoder converts the errors of the method to what the server
+// of the gRPC transport returns; they are sent unchanged otherwise.
+func ServerErrorEncoder(encode func(error) error) ServerOption {
+	return func(h *handler) { h.errorEncoder = encode }
+}
+
+type handler struct {
+	e            endpoint.Endpoint
+	dec          grpctransport.DecodeRequestFunc
+	enc          grpctransport.EncodeResponseFunc
+	request      reflect.Type
+	before       []grpctransport.ServerRequestFunc
+	errorHandler transport.ErrorHandler
+	errorEncoder func(error) error
+}
+
+func (h *handler) serve(c call) ([]byte, error) {
+	ctx := peer.NewContext(c.ctx, &peer.Peer{Addr: Addr{}})
+	ctx = metadata.NewIncomingContext(ctx, c.md)
+	for _, f := range h.before {
+		ctx = f(ctx, c.md)
+	}
+
+	req := reflect.New(h.request).Interface().(proto.Message)
+	if err := proto.Unmarshal(c.req, req); err != nil {
+		h.errorHandler.Handle(ctx, err)
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	request, err := h.dec(ctx, req)
+	if err != nil {
+		h.errorHandler.Handle(ctx, err)
+		return nil, h.errorEncoder(err)
+	}
+	response, err := h.e(ctx, request)
+	if err != nil {
+		h.errorHandler.Handle(ctx, err)
+		return nil, h.errorEncoder(err)
+	}
+	rep, err := h.enc(ctx, response)
+	if err != nil {
+		h.errorHandler.Handle(ctx, err)
+		return nil, h.errorEncoder(err)
+	}
+	msg, ok := rep.(proto.Message)
+	if !ok {
+		return nil, status.Errorf(codes.Internal, "inproc: reply %T is not a protobuf message", rep)
+	}
+	return proto.Marshal(msg)
+}
+
+// Client calls a method of a Server.
+type Client struct {
+	server *Server
+	method string
+	enc    grpctransport.EncodeRequestFunc
+	dec    grpctransport.DecodeResponseFunc
+	reply  reflect.Type
+	before []grpctransport.ClientRequestFunc
+}
+
+// NewClient returns a client of method of server. Like the gRPC transport,
+// enc converts a request to the protobuf message of the request, reply is
+// the protobuf message of the reply, and dec converts it to a response.
+func NewClient(server *Server, method string, enc grpctransport.EncodeRequestFunc, dec grpctransport.DecodeResponseFunc, reply interface{}, options ...ClientOption) *Client {
+	c := &Client{
+		server: server,
+		method: method,
+		enc:    enc,
+		dec:    dec,
+		reply:  reflect.TypeOf(reply),
+	}
+	for _, option := range options {
+		option(c)
+	}
+	return c
+}
+
+// ClientOption sets an optional parameter of a Client.
+type ClientOption func(*Client)
+
+// ClientBefore adds functions writing the context into the metadata of the
+// request, as the gRPC ClientBefore option does.
+func ClientBefore(before ...grpctransport.ClientRequestFunc) ClientOption {
+	return func(c *Client) { c.before = append(c.before, before...) }
+}
+
+// Endpoint returns a usable endpoint that invokes the method.
+func (c Client) Endpoint() endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req, err := c.enc(ctx, request)
+		if err != nil {
+			return nil, err
+		}
+		msg, ok := req.(proto.Message)
+		if !ok {
+			return nil, status.Errorf(codes.Internal, "inproc: request %T is not a protobuf message", req)
+		}
+		data, err := proto.Marshal(msg)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		md := metadata.MD{}
+		for _, f := range c.before {
+			ctx = f(ctx, &md)
+		}
+
+		done := make(chan result, 1)
+		select {
+		case c.server.calls <- call{ctx: detached{ctx}, method: c.method, md: md, req: data, done: done}:
+		case <-c.server.quit:
+			return nil, ErrUnavailable
+		case <-ctx.Done():
+			return nil, status.FromContextError(ctx.Err()).Err()
+		}
+		var res result
+		select {
+		case res = <-done:
+		case <-ctx.Done():
+			return nil, status.FromContextError(ctx.Err()).Err()
+		}
+		if res.err != nil {
+			return nil, res.err
+		}
+
+		rep := reflect.New(c.reply).Interface().(proto.Message)
+		if err := proto.Unmarshal(res.rep, rep); err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		return c.dec(ctx, rep)
+	}
+}
+
+// detached is the context of a call as the server gets it: the deadline
+// and cancellation of the client, none of its values.
+type detached struct {
+	context.Context
+}
+
+func (detached) Value(interface{}) interface{} { return nil }
+
+// wire returns err as the client of the gRPC transport would get it.
+func wire(err error) error {
+	if err == nil {
+		return nil
+	}
+	st := status.Convert(err)
+	return status.Error(st.Code(), st.Message())
+}
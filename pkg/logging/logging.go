@@ -0,0 +1,210 @@
+// Package logging holds the logger decorators shared by the services: the
+// output format, the sampling of the per-request logs and the redaction of
+// subscriber identifiers. They sit in the go-kit logger stack next to the
+// loglevel filter. It also carries the keyvals that correlate the records of
+// a request, trace and UE procedure, from one layer to the other through the
+// context.
+package logging
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+	"sync/atomic"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/go-kit/kit/transport"
+	"github.com/openzipkin/zipkin-go"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/meta"
+)
+
+// The output formats of NewLogger.
+const (
+	FormatLogfmt = "logfmt"
+	FormatJSON   = "json"
+)
+
+// DefaultRedactedKeys are the log keys carrying subscriber identifiers.
+var DefaultRedactedKeys = []string{"supi", "suci", "imsi", "supi_or_suci", "msisdn", "gpsi", "ue_id"}
+
+// NewLogger returns a logger writing records to w in format, logfmt when
+// format is not FormatJSON.
+func NewLogger(w io.Writer, format string) log.Logger {
+	if strings.EqualFold(format, FormatJSON) {
+		return log.NewJSONLogger(log.NewSyncWriter(w))
+	}
+	return log.NewLogfmtLogger(log.NewSyncWriter(w))
+}
+
+// Sample wraps next with a logger that keeps one out of n records below the
+// warn level, and every warn and error record or record with a non-nil "err"
+// value. An n of one or less keeps everything. It is meant for the
+// per-request logs, so that successes are sampled while failures are all
+// reported. Every call returns a sampler with its own counter.
+func Sample(next log.Logger, n int) log.Logger {
+	if n <= 1 {
+		return next
+	}
+	return &sampler{next: next, n: uint64(n)}
+}
+
+type sampler struct {
+	next log.Logger
+	n    uint64
+	seen uint64
+}
+
+func (s *sampler) Log(keyvals ...interface{}) error {
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		switch keyvals[i] {
+		case level.Key():
+			if l := fmt.Sprint(keyvals[i+1]); l == level.ErrorValue().String() || l == level.WarnValue().String() {
+				return s.next.Log(keyvals...)
+			}
+		case "err", "error":
+			if keyvals[i+1] != nil {
+				return s.next.Log(keyvals...)
+			}
+		}
+	}
+	if (atomic.AddUint64(&s.seen, 1)-1)%s.n != 0 {
+		return nil
+	}
+	return s.next.Log(keyvals...)
+}
+
+// Redact wraps next with a logger replacing the values of keys by their
+// Pseudonym.
+func Redact(next log.Logger, keys ...string) log.Logger {
+	return RedactWith(next, Pseudonym, keys...)
+}
+
+// RedactWith is Redact with the pseudonyms of pseudonym, such as the keyed
+// ones of pkg/privacy.
+func RedactWith(next log.Logger, pseudonym func(id string) string, keys ...string) log.Logger {
+	r := &redactor{next: next, pseudonym: pseudonym, keys: map[string]bool{}}
+	for _, k := range keys {
+		r.keys[k] = true
+	}
+	return r
+}
+
+type redactor struct {
+	next      log.Logger
+	pseudonym func(id string) string
+	keys      map[string]bool
+}
+
+func (r *redactor) Log(keyvals ...interface{}) error {
+	var redacted []interface{}
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		k, ok := keyvals[i].(string)
+		if !ok || !r.keys[k] {
+			continue
+		}
+		if redacted == nil {
+			// the caller may reuse keyvals, work on a copy
+			redacted = append([]interface{}(nil), keyvals...)
+		}
+		redacted[i+1] = r.pseudonym(fmt.Sprint(keyvals[i+1]))
+	}
+	if redacted == nil {
+		return r.next.Log(keyvals...)
+	}
+	return r.next.Log(redacted...)
+}
+
+// Pseudonym hides a subscriber identifier. The type prefix and the PLMN
+// digits are kept, the rest is replaced by a short hash so that the records
+// of one subscriber can still be correlated:
+// "imsi-208930000000001" becomes "imsi-20893#4b3c0d1e".
+func Pseudonym(id string) string {
+	if id == "" {
+		return id
+	}
+	sum := sha256.Sum256([]byte(id))
+	return PseudonymPrefix(id) + "#" + hex.EncodeToString(sum[:4])
+}
+
+// PseudonymPrefix returns the part of id its pseudonym keeps: the type
+// prefix and the PLMN digits.
+func PseudonymPrefix(id string) string {
+	i := strings.IndexByte(id, '-')
+	if i < 0 {
+		return ""
+	}
+	prefix, rest := id[:i+1], id[i+1:]
+	if n := plmnDigits(rest); n > 0 {
+		prefix += rest[:n]
+	}
+	return prefix
+}
+
+// plmnDigits returns the number of leading digits of id to keep: the MCC and
+// a two-digit MNC, when id starts with at least that many digits.
+func plmnDigits(id string) int {
+	const n = 5
+	if len(id) <= n {
+		return 0
+	}
+	for i := 0; i < n; i++ {
+		if id[i] < '0' || id[i] > '9' {
+			return 0
+		}
+	}
+	return n
+}
+
+// TraceKeyvals returns the "trace_id" and "span_id" keyvals of the Zipkin
+// span in ctx, or nothing when there is none or tracing is off, to correlate
+// a record with its trace.
+func TraceKeyvals(ctx context.Context) []interface{} {
+	span := zipkin.SpanFromContext(ctx)
+	if span == nil {
+		return nil
+	}
+	sc := span.Context()
+	if sc.TraceID.Empty() {
+		return nil
+	}
+	return []interface{}{"trace_id", sc.TraceID.String(), "span_id", sc.ID.String()}
+}
+
+// UERequest is implemented by the requests that concern a single UE. The
+// endpoint logging middlewares put the UE ID of such requests in the context.
+type UERequest interface {
+	UEID() string
+}
+
+// NewUEContext returns a copy of ctx carrying the ID of the UE, a SUPI or a
+// SUCI, that the request concerns. It is the UE of the metadata of pkg/meta,
+// passed on to the services called.
+func NewUEContext(ctx context.Context, id string) context.Context {
+	return meta.NewContext(ctx, meta.Meta{UEID: id})
+}
+
+// ContextKeyvals returns the keyvals of TraceKeyvals followed by those of
+// the metadata of ctx: "ue_id", "procedure_id" and "origin_nf_instance_id".
+func ContextKeyvals(ctx context.Context) []interface{} {
+	return append(TraceKeyvals(ctx), meta.Keyvals(ctx)...)
+}
+
+// ErrorHandler returns a transport error handler logging the errors with
+// the keyvals of their context. It replaces the handler of
+// ServerErrorLogger in the transports, which knows nothing of the context.
+func ErrorHandler(logger log.Logger) transport.ErrorHandler {
+	return errorHandler{logger}
+}
+
+type errorHandler struct {
+	logger log.Logger
+}
+
+func (h errorHandler) Handle(ctx context.Context, err error) {
+	log.With(h.logger, ContextKeyvals(ctx)...).Log("err", err)
+}
@@ -0,0 +1,66 @@
+//go:build go1.21
+// +build go1.21
+
+package logging
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+)
+
+// NewSlogLogger returns a go-kit logger writing its records to l, so that the
+// services, which take a go-kit logger, can log through a slog handler. The
+// go-kit level of a record becomes its slog level, info when it has none, and
+// its "msg" value, if any, the slog message. The other keyvals, the
+// correlation keyvals included, become attributes.
+//
+// A zap logger is plugged in the same way with go-kit's log/zap package.
+func NewSlogLogger(l *slog.Logger) log.Logger {
+	return slogLogger{l}
+}
+
+type slogLogger struct {
+	l *slog.Logger
+}
+
+func (s slogLogger) Log(keyvals ...interface{}) error {
+	lvl, msg := slog.LevelInfo, ""
+	attrs := make([]slog.Attr, 0, len(keyvals)/2)
+	for i := 0; i < len(keyvals); i += 2 {
+		k := fmt.Sprint(keyvals[i])
+		var v interface{} = log.ErrMissingValue
+		if i+1 < len(keyvals) {
+			v = keyvals[i+1]
+		}
+		if lv, ok := v.(level.Value); ok && keyvals[i] == level.Key() {
+			lvl = slogLevel(lv)
+			continue
+		}
+		if k == "msg" {
+			msg = fmt.Sprint(v)
+			continue
+		}
+		if err, ok := v.(error); ok {
+			v = err.Error()
+		}
+		attrs = append(attrs, slog.Any(k, v))
+	}
+	s.l.LogAttrs(context.Background(), lvl, msg, attrs...)
+	return nil
+}
+
+func slogLevel(v level.Value) slog.Level {
+	switch v {
+	case level.DebugValue():
+		return slog.LevelDebug
+	case level.WarnValue():
+		return slog.LevelWarn
+	case level.ErrorValue():
+		return slog.LevelError
+	}
+	return slog.LevelInfo
+}
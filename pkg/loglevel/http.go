@@ -0,0 +1,79 @@
+package loglevel
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// Path is where services mount Handler on their HTTP server.
+const Path = "/admin/loglevel"
+
+// SetRequest is the body of a PUT to Handler.
+type SetRequest struct {
+	Component string `json:"component"`
+	Level     string `json:"level"`
+	// TTL is a time.ParseDuration string, DefaultTTL when empty.
+	TTL string `json:"ttl,omitempty"`
+}
+
+// State is the body returned by Handler.
+type State struct {
+	Default   string     `json:"default"`
+	Overrides []Override `json:"overrides"`
+}
+
+type errorWrapper struct {
+	Error string `json:"error"`
+}
+
+// Handler serves the registry over HTTP:
+//
+//	GET    lists the default level and the overrides in effect
+//	PUT    sets an override from a SetRequest body
+//	DELETE resets the override of the ?component= query parameter
+func Handler(r *Registry) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.Method {
+		case http.MethodGet:
+		case http.MethodPut, http.MethodPost:
+			var sr SetRequest
+			if err := json.NewDecoder(req.Body).Decode(&sr); err != nil {
+				writeError(w, http.StatusBadRequest, err)
+				return
+			}
+			l, err := Parse(sr.Level)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, err)
+				return
+			}
+			var ttl time.Duration
+			if sr.TTL != "" {
+				if ttl, err = time.ParseDuration(sr.TTL); err != nil {
+					writeError(w, http.StatusBadRequest, err)
+					return
+				}
+			}
+			r.Set(sr.Component, l, ttl)
+		case http.MethodDelete:
+			r.Reset(req.URL.Query().Get("component"))
+		default:
+			w.Header().Set("Allow", "GET, PUT, DELETE")
+			writeError(w, http.StatusMethodNotAllowed, nil)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(State{Default: r.Default().String(), Overrides: r.Overrides()})
+	})
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	msg := http.StatusText(code)
+	if err != nil {
+		msg = err.Error()
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(errorWrapper{Error: msg})
+}
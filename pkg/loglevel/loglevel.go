@@ -0,0 +1,203 @@
+// Package loglevel keeps the log verbosity of every component of a service
+// and lets it be changed at runtime. Components are dotted names such as
+// "rach" or "rach.msg3"; a component without a level of its own inherits the
+// one of its closest parent, and finally the default level. Runtime overrides
+// carry an expiry so a verbosity raised for debugging resets by itself.
+package loglevel
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+)
+
+// ComponentKey is the log key naming the component a record belongs to.
+const ComponentKey = "component"
+
+// DefaultTTL is the lifetime of an override set without an explicit ttl.
+const DefaultTTL = 15 * time.Minute
+
+// Level is a log verbosity, from the most verbose Trace to the least
+// verbose Error.
+type Level int
+
+// The supported levels. Trace is more verbose than the go-kit levels and is
+// logged through TraceLogger.
+const (
+	Trace Level = iota
+	Debug
+	Info
+	Warn
+	Error
+)
+
+var levelNames = []string{"trace", "debug", "info", "warn", "error"}
+
+// ErrUnknownLevel is returned when parsing an unsupported level name.
+var ErrUnknownLevel = errors.New("loglevel: unknown level")
+
+func (l Level) String() string {
+	if l < Trace || l > Error {
+		return fmt.Sprintf("level(%d)", int(l))
+	}
+	return levelNames[l]
+}
+
+// Parse returns the level named s. "warning" is accepted for "warn".
+func Parse(s string) (Level, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "warning" {
+		s = "warn"
+	}
+	for i, name := range levelNames {
+		if name == s {
+			return Level(i), nil
+		}
+	}
+	return Info, ErrUnknownLevel
+}
+
+// TraceLogger returns a logger that logs at the trace level.
+func TraceLogger(logger log.Logger) log.Logger {
+	return log.WithPrefix(logger, level.Key(), levelNames[Trace])
+}
+
+// Override is a runtime level set on a component.
+type Override struct {
+	Component string    `json:"component"`
+	Level     string    `json:"level"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type override struct {
+	level     Level
+	expiresAt time.Time
+}
+
+// Registry holds the default level and the per-component overrides.
+type Registry struct {
+	mtx       sync.RWMutex
+	def       Level
+	overrides map[string]override
+	now       func() time.Time
+}
+
+// New returns a registry logging at def for every component.
+func New(def Level) *Registry {
+	return &Registry{
+		def:       def,
+		overrides: map[string]override{},
+		now:       time.Now,
+	}
+}
+
+// SetDefault changes the level of the components without an override.
+func (r *Registry) SetDefault(l Level) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.def = l
+}
+
+// Default returns the level of the components without an override.
+func (r *Registry) Default() Level {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	return r.def
+}
+
+// Set overrides the level of component and of its children until ttl has
+// elapsed. A ttl of zero or less uses DefaultTTL.
+func (r *Registry) Set(component string, l Level, ttl time.Duration) Override {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	o := override{level: l, expiresAt: r.now().Add(ttl)}
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.overrides[component] = o
+	return Override{Component: component, Level: l.String(), ExpiresAt: o.expiresAt}
+}
+
+// Reset removes the override of component.
+func (r *Registry) Reset(component string) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	delete(r.overrides, component)
+}
+
+// Overrides returns the overrides still in effect, sorted by component.
+func (r *Registry) Overrides() []Override {
+	now := r.now()
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	res := make([]Override, 0, len(r.overrides))
+	for c, o := range r.overrides {
+		if !now.Before(o.expiresAt) {
+			delete(r.overrides, c)
+			continue
+		}
+		res = append(res, Override{Component: c, Level: o.level.String(), ExpiresAt: o.expiresAt})
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Component < res[j].Component })
+	return res
+}
+
+// Level returns the level in effect for component.
+func (r *Registry) Level(component string) Level {
+	now := r.now()
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	for c := component; ; {
+		if o, ok := r.overrides[c]; ok && now.Before(o.expiresAt) {
+			return o.level
+		}
+		if c == "" {
+			return r.def
+		}
+		if i := strings.LastIndexByte(c, '.'); i >= 0 {
+			c = c[:i]
+		} else {
+			c = ""
+		}
+	}
+}
+
+// NewFilter wraps next with a logger that drops the records below the level
+// of their component. The component and the level are read from the
+// ComponentKey and the go-kit level key of each record, so the filter must
+// sit below any log.With adding them. Records without a level are kept.
+func (r *Registry) NewFilter(next log.Logger) log.Logger {
+	return &filter{registry: r, next: next}
+}
+
+type filter struct {
+	registry *Registry
+	next     log.Logger
+}
+
+func (f *filter) Log(keyvals ...interface{}) error {
+	var (
+		component string
+		lvl       = -1
+	)
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		switch keyvals[i] {
+		case ComponentKey:
+			component = fmt.Sprint(keyvals[i+1])
+		case level.Key():
+			if l, err := Parse(fmt.Sprint(keyvals[i+1])); err == nil {
+				lvl = int(l)
+			}
+		}
+	}
+	if lvl >= 0 && Level(lvl) < f.registry.Level(component) {
+		return nil
+	}
+	return f.next.Log(keyvals...)
+}
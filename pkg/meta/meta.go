@@ -0,0 +1,207 @@
+// Package meta carries the metadata that joins the records of one UE
+// procedure across the services it goes through, the gNB to the core: the
+// UE, the ID of the procedure and the NF instance that started it. The
+// metadata travels with the requests as gRPC metadata, HTTP headers or the
+// headers of a message, such as a NATS one, whose header has the layout of
+// http.Header. The logs carry it through logging.ContextKeyvals and the
+// Zipkin spans as tags set by Middleware.
+//
+// The NF instance of the metadata is the one that started the procedure, as
+// opposed to the caller of each hop, which is pkg/caller's.
+package meta
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/go-kit/kit/endpoint"
+	grpctransport "github.com/go-kit/kit/transport/grpc"
+	httptransport "github.com/go-kit/kit/transport/http"
+	"github.com/openzipkin/zipkin-go"
+	"google.golang.org/grpc/metadata"
+)
+
+// The gRPC metadata keys carrying the metadata.
+const (
+	UEIDMetadataKey         = "x-ue-id"
+	ProcedureIDMetadataKey  = "x-procedure-id"
+	NFInstanceIDMetadataKey = "x-origin-nf-instance-id"
+)
+
+// The HTTP and message headers carrying the metadata.
+const (
+	UEIDHeader         = "X-Ue-Id"
+	ProcedureIDHeader  = "X-Procedure-Id"
+	NFInstanceIDHeader = "X-Origin-Nf-Instance-Id"
+)
+
+// Meta is the metadata of a request. An empty field is unknown.
+type Meta struct {
+	// UEID is the SUPI or SUCI of the UE the procedure concerns.
+	UEID string
+	// ProcedureID identifies the procedure, from its first request on.
+	ProcedureID string
+	// NFInstanceID is the NF instance that started the procedure.
+	NFInstanceID string
+}
+
+type ueIDKey struct{}
+type procedureIDKey struct{}
+type nfInstanceIDKey struct{}
+
+// NewContext returns a copy of ctx carrying the fields of m that are set, the
+// others keeping the values of ctx.
+func NewContext(ctx context.Context, m Meta) context.Context {
+	if m.UEID != "" {
+		ctx = context.WithValue(ctx, ueIDKey{}, m.UEID)
+	}
+	if m.ProcedureID != "" {
+		ctx = context.WithValue(ctx, procedureIDKey{}, m.ProcedureID)
+	}
+	if m.NFInstanceID != "" {
+		ctx = context.WithValue(ctx, nfInstanceIDKey{}, m.NFInstanceID)
+	}
+	return ctx
+}
+
+// FromContext returns the metadata carried by ctx.
+func FromContext(ctx context.Context) Meta {
+	var m Meta
+	m.UEID, _ = ctx.Value(ueIDKey{}).(string)
+	m.ProcedureID, _ = ctx.Value(procedureIDKey{}).(string)
+	m.NFInstanceID, _ = ctx.Value(nfInstanceIDKey{}).(string)
+	return m
+}
+
+// NewProcedureID returns a random procedure ID.
+func NewProcedureID() string {
+	var b [8]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// Keyvals returns the metadata of ctx as log key values, the unknown fields
+// left out.
+func Keyvals(ctx context.Context) []interface{} {
+	m := FromContext(ctx)
+	var keyvals []interface{}
+	if m.UEID != "" {
+		keyvals = append(keyvals, "ue_id", m.UEID)
+	}
+	if m.ProcedureID != "" {
+		keyvals = append(keyvals, "procedure_id", m.ProcedureID)
+	}
+	if m.NFInstanceID != "" {
+		keyvals = append(keyvals, "origin_nf_instance_id", m.NFInstanceID)
+	}
+	return keyvals
+}
+
+// UERequest is implemented by the requests that concern a single UE.
+type UERequest interface {
+	UEID() string
+}
+
+// Middleware returns an endpoint middleware that starts a procedure at
+// nfInstanceID for the requests that are not part of one, and takes the UE of
+// the requests that concern one when the context does not carry it. The
+// procedure is set as tags of the Zipkin span of the request. The UE is not:
+// the spans leave the service unredacted.
+func Middleware(nfInstanceID string) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			m := FromContext(ctx)
+			if m.ProcedureID == "" {
+				m.ProcedureID = NewProcedureID()
+				m.NFInstanceID = nfInstanceID
+			}
+			if r, ok := request.(UERequest); ok && m.UEID == "" {
+				m.UEID = r.UEID()
+			}
+			ctx = NewContext(ctx, m)
+			if span := zipkin.SpanFromContext(ctx); span != nil {
+				span.Tag("procedure_id", m.ProcedureID)
+				if m.NFInstanceID != "" {
+					span.Tag("origin_nf_instance_id", m.NFInstanceID)
+				}
+			}
+			return next(ctx, request)
+		}
+	}
+}
+
+// FromHeader returns a copy of ctx carrying the metadata of h, the header of
+// an HTTP request or of a message.
+func FromHeader(ctx context.Context, h http.Header) context.Context {
+	return NewContext(ctx, Meta{
+		UEID:         h.Get(UEIDHeader),
+		ProcedureID:  h.Get(ProcedureIDHeader),
+		NFInstanceID: h.Get(NFInstanceIDHeader),
+	})
+}
+
+// ToHeader sets the metadata of ctx in h, the header of an HTTP request or of
+// a message.
+func ToHeader(ctx context.Context, h http.Header) {
+	m := FromContext(ctx)
+	set := func(key, v string) {
+		if v != "" {
+			h.Set(key, v)
+		}
+	}
+	set(UEIDHeader, m.UEID)
+	set(ProcedureIDHeader, m.ProcedureID)
+	set(NFInstanceIDHeader, m.NFInstanceID)
+}
+
+// GRPCToContext moves the metadata of the incoming gRPC metadata to the
+// context.
+func GRPCToContext() grpctransport.ServerRequestFunc {
+	return func(ctx context.Context, md metadata.MD) context.Context {
+		get := func(key string) string {
+			if v := md.Get(key); len(v) > 0 {
+				return v[0]
+			}
+			return ""
+		}
+		return NewContext(ctx, Meta{
+			UEID:         get(UEIDMetadataKey),
+			ProcedureID:  get(ProcedureIDMetadataKey),
+			NFInstanceID: get(NFInstanceIDMetadataKey),
+		})
+	}
+}
+
+// ContextToGRPC moves the metadata of the context to the outgoing gRPC
+// metadata.
+func ContextToGRPC() grpctransport.ClientRequestFunc {
+	return func(ctx context.Context, md *metadata.MD) context.Context {
+		m := FromContext(ctx)
+		set := func(key, v string) {
+			if v != "" {
+				(*md)[key] = []string{v}
+			}
+		}
+		set(UEIDMetadataKey, m.UEID)
+		set(ProcedureIDMetadataKey, m.ProcedureID)
+		set(NFInstanceIDMetadataKey, m.NFInstanceID)
+		return ctx
+	}
+}
+
+// HTTPToContext moves the metadata of the request header to the context.
+func HTTPToContext() httptransport.RequestFunc {
+	return func(ctx context.Context, r *http.Request) context.Context {
+		return FromHeader(ctx, r.Header)
+	}
+}
+
+// ContextToHTTP moves the metadata of the context to the request header.
+func ContextToHTTP() httptransport.RequestFunc {
+	return func(ctx context.Context, r *http.Request) context.Context {
+		ToHeader(ctx, r.Header)
+		return ctx
+	}
+}
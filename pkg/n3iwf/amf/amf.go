@@ -0,0 +1,1023 @@
+// Package amf is the AMF behind the N2 of the N3IWF, a stand-in the tree
+// having no AMF of its own. It runs the registration of the UEs (TS 24.501
+// 5.5.1.2) through 5G-AKA with the AUSF, as the SEAF, and their
+// deregistration, and hands the N3IWF the K_N3IWF of the UEs it accepts. It
+// keeps its UE contexts in memory.
+//
+// Once authenticated, a UE takes the NAS security context of its K_AMF in
+// the security mode control (TS 24.501 5.4.2), NEA2 and NIA2 selected, and
+// its NAS messages are integrity protected and ciphered from then on, the
+// initial ones being integrity protected only. K_N3IWF is derived with the
+// uplink NAS COUNT of the Security Mode Complete, or of the Registration
+// Request of the UE moving to another connection, whose NAS security
+// context moves along. The AMF discards the messages that do not verify
+// or were replayed.
+//
+// A registered UE whose signalling connection the N3IWF releases, on its
+// request or for inactivity, goes CM-IDLE, and back to CM-CONNECTED with a
+// Service Request, paged with the DRX cycle negotiated at its registration
+// when the AMF has downlink NAS messages for it. The paging is sent again
+// every T3513 until the Service Request, up to maxPagings times, the UE
+// being unreachable afterwards (see CMStats). The AMF also routes the short
+// messages of its UEs as an SMSF would (see SMSStats), and logs the NAS
+// messages of those whose trace is active, by SUPI, SUCI or 5G-GUTI (see
+// Trace).
+//
+// The AMF keeps the location of every UE, the TAI of the N3IWF and the
+// outer address of the UE that come with its NAS messages, and its last
+// locations (see UELocation). The registration area it gives a UE is the
+// TAI of the UE. A registered UE updates its registration every T3512, and
+// registers with its 5G-GUTI on a new connection of the N3IWF, when it
+// moved to another access, the AMF moving its context there without
+// 5G-AKA. A CM-IDLE UE that neither comes back nor updates its
+// registration is deregistered implicitly (see RegistrationStats).
+//
+// The AMF serves the slices it is configured with (see Slices). Without the
+// subscription of the UEs, it allows a UE the slices it requests among
+// those, rejecting the others, and the first of them when it requests none;
+// a UE allowed none is rejected (see SliceStats).
+//
+// Under overload, the AMF has the N3IWF reject the UEs setting up their
+// signalling connection, by their establishment cause, until the NAS
+// messages in process are back down (see Overload).
+//
+// The contexts of the registered UEs may be replicated to the AMF of another
+// region (see Replicate), a UE registering again after its region failed
+// keeping the 5G-GUTI it had there. They may also be exported, to be
+// imported by the AMF of another deployment (see Export and Import), the UEs
+// keeping their 5G-GUTI when they register there, and persisted, to be
+// imported again when the AMF restarts (see Persist).
+package amf
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	ausfservice "github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/ausf/service"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/fsm"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/identity"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/n3iwf/n2"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/n3iwf/n2/nasbuild"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/replication"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/security"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/security/nas"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/timers"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/uetrace"
+)
+
+// The 5GMM states of a UE as the AMF sees them (TS 24.501 5.1.3.2.2),
+// AUTHENTICATING, SECURING and ACCEPTED splitting the common procedures of
+// the registration: before 5G-AKA, before the security mode control and
+// after it.
+const (
+	stateDeregistered   fsm.State = "DEREGISTERED"
+	stateAuthenticating fsm.State = "AUTHENTICATING"
+	stateSecuring       fsm.State = "SECURING"
+	stateAccepted       fsm.State = "ACCEPTED"
+	stateRegistered     fsm.State = "REGISTERED"
+)
+
+const (
+	eventRegistrationRequest    fsm.Event = "registrationRequest"
+	eventAuthenticationResponse fsm.Event = "authenticationResponse"
+	eventAuthenticationFailure  fsm.Event = "authenticationFailure"
+	eventSecurityModeComplete   fsm.Event = "securityModeComplete"
+	eventSecurityModeReject     fsm.Event = "securityModeReject"
+	eventRegistrationComplete   fsm.Event = "registrationComplete"
+	eventDeregistrationRequest  fsm.Event = "deregistrationRequest"
+)
+
+// registration is the registration of a UE. The actions get the *procedure
+// of the NAS message, and set the downlink answering it.
+var registration = fsm.MustNew(fsm.Definition{
+	Name:    "5gmm",
+	Initial: stateDeregistered,
+	Transitions: []fsm.Transition{
+		{From: []fsm.State{stateDeregistered}, Event: eventRegistrationRequest, To: stateAccepted, Guard: registrationUpdate, Action: moveIn},
+		{From: []fsm.State{stateDeregistered}, Event: eventRegistrationRequest, To: stateAuthenticating, Action: authenticate},
+		{From: []fsm.State{stateAuthenticating}, Event: eventAuthenticationResponse, To: stateSecuring, Action: secure},
+		{From: []fsm.State{stateAuthenticating}, Event: eventAuthenticationFailure, To: stateDeregistered, Action: rejectAuthentication},
+		{From: []fsm.State{stateSecuring}, Event: eventSecurityModeComplete, To: stateAccepted, Action: accept},
+		{From: []fsm.State{stateSecuring}, Event: eventSecurityModeReject, To: stateDeregistered, Action: rejectSecurityMode},
+		{From: []fsm.State{stateAccepted}, Event: eventRegistrationComplete, To: stateRegistered, Action: complete},
+		{From: []fsm.State{stateRegistered}, Event: eventRegistrationRequest, To: stateRegistered, Action: updateRegistration},
+		{From: []fsm.State{stateAccepted, stateRegistered}, Event: eventDeregistrationRequest, To: stateDeregistered, Action: deregister},
+	},
+})
+
+// events are the events fired by the uplink NAS messages.
+var events = map[string]fsm.Event{
+	n2.RegistrationRequest:    eventRegistrationRequest,
+	n2.AuthenticationResponse: eventAuthenticationResponse,
+	n2.AuthenticationFailure:  eventAuthenticationFailure,
+	n2.SecurityModeComplete:   eventSecurityModeComplete,
+	n2.SecurityModeReject:     eventSecurityModeReject,
+	n2.RegistrationComplete:   eventRegistrationComplete,
+	n2.DeregistrationRequest:  eventDeregistrationRequest,
+}
+
+// abba is the ABBA parameter of the keys of the UEs (TS 33.501 A.7.1).
+var abba = []byte{0x00, 0x00}
+
+// maxPagings is the number of times a CM-IDLE UE is paged, T3513 apart,
+// before the AMF gives up reaching it, as the paging retransmission
+// strategy of the AMF allows (TS 23.502 4.2.3.3).
+const maxPagings = 4
+
+// Option sets an optional parameter of the AMF.
+type Option func(*AMF)
+
+// Logger sets the logger of the registrations.
+func Logger(logger log.Logger) Option {
+	return func(a *AMF) { a.logger = logger }
+}
+
+// Trace logs the NAS messages of the UEs whose trace is active in traces,
+// by SUPI, SUCI or 5G-GUTI.
+func Trace(traces *uetrace.List) Option {
+	return func(a *AMF) { a.traces = traces }
+}
+
+// Slices sets the slices the AMF serves, SST 1 (eMBB) alone by default.
+func Slices(nssai identity.NSSAI) Option {
+	return func(a *AMF) {
+		if len(nssai) > 0 {
+			a.nssai = nssai
+		}
+	}
+}
+
+// T3513 sets the duration of T3513, timers.Defaults by default.
+func T3513(d time.Duration) Option {
+	return func(a *AMF) { a.t3513 = d }
+}
+
+// T3512 sets the period of the periodic registration updates of the UEs,
+// timers.Defaults by default.
+func T3512(d time.Duration) Option {
+	return func(a *AMF) { a.t3512 = d }
+}
+
+// ImplicitDeregistration sets how long a CM-IDLE UE stays registered
+// without coming back or updating its registration, 4 minutes more than
+// T3512 by default.
+func ImplicitDeregistration(d time.Duration) Option {
+	return func(a *AMF) { a.implicit = d }
+}
+
+// Persist writes the contexts the AMF holds to r, behind the changes, for
+// the AMF to get them back when it restarts (see Restore).
+func Persist(r Repository) Option {
+	return func(a *AMF) { a.repo = r }
+}
+
+// Replicate keeps the contexts of the registered UEs in replicas, by SUPI,
+// along with those of the AMF of the other region.
+func Replicate(replicas *replication.Table) Option {
+	return func(a *AMF) { a.replicas = replicas }
+}
+
+// AMF is the stand-in AMF, identified by its GUAMI. It implements n2.AMF.
+type AMF struct {
+	ausf   ausfservice.AusfService
+	guami  identity.GUAMI
+	snn    string
+	nssai  identity.NSSAI
+	logger log.Logger
+	traces *uetrace.List
+	// timers run T3513 for the UEs paged, and the implicit deregistration
+	// of the CM-IDLE UEs.
+	timers   *timers.Wheel
+	t3513    time.Duration
+	t3512    time.Duration
+	implicit time.Duration
+	// replicas are the contexts of the registered UEs of both regions,
+	// nil without replication.
+	replicas *replication.Table
+	// repo keeps the contexts durably, nil without persistence, syncs
+	// waking the writer of those of the SUPIs dirty.
+	repo  Repository
+	syncs chan struct{}
+	// ran is set by the NG Setup, before the first UE.
+	ran n2.RAN
+	// ovl is the overload control of the RAN.
+	ovl overload
+
+	mtx sync.Mutex
+	ues map[uint64]*ue
+	// registered are the registered UEs by SUPI, the short messages being
+	// addressed to them.
+	registered map[string]*ue
+	// imported are the contexts imported for the UEs yet to register
+	// again, by SUPI.
+	imported map[string]replica
+	// dirty are the SUPIs whose context changed since it was last
+	// written to repo.
+	dirty    map[string]bool
+	sms      smsStats
+	cm       cmStats
+	reg      regStats
+	nextID   uint64
+	nextTMSI uint32
+	// unserved count the requests of the slices the AMF does not serve,
+	// by S-NSSAI.
+	unserved map[string]int
+}
+
+// ue is the context of a UE in the AMF.
+type ue struct {
+	id      uint64
+	fivegmm *fsm.Instance
+	// authCtxID and hxresStar are those of the 5G-AKA in progress.
+	authCtxID string
+	rand      []byte
+	hxresStar []byte
+	// mobileIdentity is the SUPI or the SUCI of its Registration Request.
+	mobileIdentity string
+	supi           string
+	guti           string
+	// kamf is K_AMF, which K_N3IWF is derived from again when the UE
+	// moves to another connection.
+	kamf []byte
+	// sec is the NAS security context of the UE from its Security Mode
+	// Command on, nil before. It is set before the UE registers, or with
+	// the lock of the AMF held when the UE moves in.
+	sec *nas.Context
+	// drx is the DRX cycle negotiated with the UE, nssai the slices
+	// allowed it and rejected those refused, until its Registration Accept.
+	drx      uint16
+	nssai    identity.NSSAI
+	rejected identity.NSSAI
+
+	// The fields below are guarded by the lock of the AMF.
+	ranUEID uint64
+	// idle is true while the UE is CM-IDLE, pending being the downlink
+	// NAS messages stored until its Service Request.
+	idle    bool
+	pending [][]byte
+	// mr is the last RP message reference of the RP-DATAs delivered to the
+	// UE, mt those not acknowledged yet.
+	mr uint8
+	mt map[uint8]delivery
+	// location is where the UE is, history where it was before, the last
+	// location first.
+	location Location
+	history  []Location
+}
+
+// cmStats count the transitions of the UEs between CM-CONNECTED and
+// CM-IDLE, and the pagings of the CM-IDLE UEs: sent again on the expiry of
+// T3513, or unanswered.
+type cmStats struct {
+	releases, serviceRequests int
+	pagings, repagings        int
+	unreachable               int
+}
+
+// procedure is what the transitions of a UE work on: the NAS message msg
+// as received, the IEs of its plain message, its security header type ht
+// and its uplink NAS COUNT, when the UE has a NAS security context.
+type procedure struct {
+	a     *AMF
+	u     *ue
+	msg   []byte
+	ies   []byte
+	ht    nas.HeaderType
+	count uint32
+	an    n2.ANParameters
+	dl    n2.Downlink
+}
+
+// New returns the AMF of guami, which authenticates the UEs with ausf in
+// the serving network of the PLMN of guami.
+func New(ausf ausfservice.AusfService, guami identity.GUAMI, options ...Option) *AMF {
+	a := &AMF{
+		ausf:       ausf,
+		guami:      guami,
+		snn:        guami.PLMN.ServingNetworkName(),
+		nssai:      identity.NSSAI{{SST: 1}},
+		logger:     log.NewNopLogger(),
+		ues:        make(map[uint64]*ue),
+		registered: make(map[string]*ue),
+		imported:   make(map[string]replica),
+		unserved:   make(map[string]int),
+		nextID:     1,
+	}
+	for _, option := range options {
+		option(a)
+	}
+	if a.t3512 == 0 {
+		a.t3512 = timers.Defaults[timers.T3512]
+	}
+	if a.implicit == 0 {
+		a.implicit = a.t3512 + implicitMargin
+	}
+	a.timers = timers.NewWheel(timers.Logger(a.logger))
+	if a.repo != nil {
+		a.dirty = make(map[string]bool)
+		a.syncs = make(chan struct{}, 1)
+		go a.persist()
+	}
+	return a
+}
+
+// NGSetup sets up the N2 with ran.
+func (a *AMF) NGSetup(ran n2.RAN) {
+	a.ran = ran
+}
+
+// InitialUEMessage creates the context of the UE ranUEID at loc, whose
+// first NAS message must be a Registration Request, and starts 5G-AKA, or
+// moves the context of the UE of a registration update there. A Service
+// Request or a registration update brings the CM-IDLE UE ranUEID back
+// instead. The initial NAS messages may be integrity protected, not
+// ciphered.
+func (a *AMF) InitialUEMessage(ctx context.Context, ranUEID uint64, msg []byte, an n2.ANParameters, loc n2.UserLocation) (dl n2.Downlink, err error) {
+	a.process(ctx, 1)
+	defer a.process(ctx, -1)
+	plain, ok := nas.Plain(msg)
+	if !ok {
+		return dl, status.Error(codes.InvalidArgument, "n2: ciphered initial NAS message")
+	}
+	switch name, _ := n2.ParseNAS(plain); name {
+	case n2.RegistrationRequest:
+	case n2.ServiceRequest:
+		return a.serviceRequest(ctx, ranUEID, msg, loc)
+	default:
+		return dl, status.Errorf(codes.InvalidArgument, "n2: unexpected initial NAS message %q", name)
+	}
+	a.mtx.Lock()
+	if u, ok := a.ues[ranUEID]; ok {
+		idle := u.idle
+		a.mtx.Unlock()
+		if !idle {
+			return dl, status.Errorf(codes.AlreadyExists, "n2: UE context %d exists", ranUEID)
+		}
+		return a.idleUpdate(ctx, ranUEID, u, msg, loc)
+	}
+	u := &ue{id: a.nextID, fivegmm: registration.Instance(), ranUEID: ranUEID, mt: make(map[uint8]delivery)}
+	a.nextID++
+	a.ues[ranUEID] = u
+	a.locateLocked(u, loc)
+	a.mtx.Unlock()
+	return a.fire(ctx, ranUEID, u, msg, an)
+}
+
+// UplinkNASTransport moves the UE ranUEID at loc through its registration.
+func (a *AMF) UplinkNASTransport(ctx context.Context, ranUEID uint64, nas []byte, loc n2.UserLocation) (dl n2.Downlink, err error) {
+	a.process(ctx, 1)
+	defer a.process(ctx, -1)
+	a.mtx.Lock()
+	u, ok := a.ues[ranUEID]
+	if ok {
+		a.locateLocked(u, loc)
+	}
+	a.mtx.Unlock()
+	if !ok {
+		return dl, n2.ErrUnknownUE
+	}
+	return a.fire(ctx, ranUEID, u, nas, n2.ANParameters{})
+}
+
+// ANRelease makes the registered UE ranUEID CM-IDLE.
+func (a *AMF) ANRelease(ctx context.Context, ranUEID uint64) error {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	u, ok := a.ues[ranUEID]
+	if !ok {
+		return n2.ErrUnknownUE
+	}
+	if u.fivegmm.State() != stateRegistered {
+		return status.Errorf(codes.FailedPrecondition, "n2: UE %d is %s", ranUEID, u.fivegmm.State())
+	}
+	u.idle = true
+	a.cm.releases++
+	a.replicateLocked(u)
+	a.timers.Context(u.key()).Start(timers.ImplicitDeregistration, a.implicit, func(timers.Expiry) {
+		ctx, cancel := context.WithTimeout(context.Background(), pagingTimeout)
+		defer cancel()
+		a.deregisterImplicitly(ctx, u)
+	})
+	level.Debug(a.logger).Log("amf_ue_ngap_id", u.id, "cm", "CM-IDLE")
+	return nil
+}
+
+// serviceRequest brings the CM-IDLE UE ranUEID at loc back to
+// CM-CONNECTED, and sends it the downlink NAS messages stored meanwhile.
+func (a *AMF) serviceRequest(ctx context.Context, ranUEID uint64, msg []byte, loc n2.UserLocation) (dl n2.Downlink, err error) {
+	a.mtx.Lock()
+	u, ok := a.ues[ranUEID]
+	if !ok {
+		a.mtx.Unlock()
+		return dl, n2.ErrUnknownUE
+	}
+	plain, _, _, err := a.unprotect(u, msg)
+	if err != nil {
+		a.mtx.Unlock()
+		return dl, err
+	}
+	req := n2.GetIEs(n2.ServiceRequest).(*n2.ServiceRequestIEs)
+	defer n2.PutIEs(n2.ServiceRequest, req)
+	_, ies := n2.ParseNAS(plain)
+	if err := json.Unmarshal(ies, req); err != nil {
+		a.mtx.Unlock()
+		return dl, status.Errorf(codes.InvalidArgument, "n2: malformed %s: %v", n2.ServiceRequest, err)
+	}
+	if !u.idle || u.guti != req.GUTI {
+		a.mtx.Unlock()
+		return dl, status.Errorf(codes.FailedPrecondition, "n2: no CM-IDLE UE %d of 5G-GUTI %s", ranUEID, req.GUTI)
+	}
+	a.cm.serviceRequests++
+	a.locateLocked(u, loc)
+	pending := a.connectLocked(u)
+	a.mtx.Unlock()
+	a.trace(ctx, u, "uplink", n2.ServiceRequest, "downlink", n2.ServiceAccept)
+	a.flush(ctx, u, pending)
+	accept, err := protect(u.sec, []byte(n2.ServiceAccept))
+	return n2.Downlink{AMFUEID: u.id, NAS: accept}, err
+}
+
+// idleUpdate updates the registration of the CM-IDLE UE ranUEID at loc,
+// which goes back to CM-CONNECTED, and sends it the downlink NAS messages
+// stored meanwhile.
+func (a *AMF) idleUpdate(ctx context.Context, ranUEID uint64, u *ue, msg []byte, loc n2.UserLocation) (n2.Downlink, error) {
+	a.mtx.Lock()
+	a.locateLocked(u, loc)
+	a.mtx.Unlock()
+	dl, err := a.fire(ctx, ranUEID, u, msg, n2.ANParameters{})
+	if err != nil || dl.Release {
+		return dl, err
+	}
+	a.mtx.Lock()
+	pending := a.connectLocked(u)
+	a.mtx.Unlock()
+	a.flush(ctx, u, pending)
+	return dl, nil
+}
+
+// connectLocked brings the CM-IDLE UE u back to CM-CONNECTED, with the
+// lock of the AMF held, and returns the downlink NAS messages stored for
+// it meanwhile.
+func (a *AMF) connectLocked(u *ue) [][]byte {
+	u.idle = false
+	t := a.timers.Context(u.key())
+	t.Stop(timers.T3513)
+	t.Stop(timers.ImplicitDeregistration)
+	a.replicateLocked(u)
+	pending := u.pending
+	u.pending = nil
+	return pending
+}
+
+// flush sends the UE u, back to CM-CONNECTED, the downlink NAS messages
+// stored while it was CM-IDLE, protected as they are sent.
+func (a *AMF) flush(ctx context.Context, u *ue, pending [][]byte) {
+	a.mtx.Lock()
+	ranUEID, sec := u.ranUEID, u.sec
+	a.mtx.Unlock()
+	for _, msg := range pending {
+		protected, err := protect(sec, msg)
+		if err == nil {
+			err = a.ran.DownlinkNASTransport(ctx, ranUEID, protected)
+		}
+		if err != nil {
+			level.Warn(a.logger).Log("amf_ue_ngap_id", u.id, "downlink_nas_transport", "failed", "err", err)
+		}
+		a.trace(ctx, u, "downlink", msg, "err", err)
+	}
+	level.Debug(a.logger).Log("amf_ue_ngap_id", u.id, "cm", "CM-CONNECTED", "pending", len(pending))
+}
+
+// UEContextRelease forgets the UE ranUEID, registered or not.
+func (a *AMF) UEContextRelease(ctx context.Context, ranUEID uint64) error {
+	a.mtx.Lock()
+	u, ok := a.ues[ranUEID]
+	a.mtx.Unlock()
+	if !ok {
+		return n2.ErrUnknownUE
+	}
+	a.forget(ctx, u)
+	return nil
+}
+
+// Stats returns the number of UE contexts in every 5GMM state.
+func (a *AMF) Stats() map[fsm.State]int {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	s := make(map[fsm.State]int)
+	for _, u := range a.ues {
+		s[u.fivegmm.State()]++
+	}
+	return s
+}
+
+// CMStats returns the number of registered UEs in every 5GMM connection
+// management state (TS 23.501 5.3.3.2), and of the transitions between the
+// two: the releases of their signalling connection and the Service
+// Requests bringing them back.
+func (a *AMF) CMStats() map[string]int {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	s := map[string]int{
+		CMIdle:             0,
+		CMConnected:        0,
+		"releases":         a.cm.releases,
+		"service_requests": a.cm.serviceRequests,
+		"pagings":          a.cm.pagings,
+		"repagings":        a.cm.repagings,
+		"unreachable":      a.cm.unreachable,
+	}
+	for _, u := range a.ues {
+		if u.fivegmm.State() != stateRegistered {
+			continue
+		}
+		if u.idle {
+			s[CMIdle]++
+		} else {
+			s[CMConnected]++
+		}
+	}
+	return s
+}
+
+// SliceStats returns, by slice, the number of registered UEs allowed it,
+// and of the requests of it the AMF rejected, not serving it.
+func (a *AMF) SliceStats() map[string]map[string]int {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	s := make(map[string]map[string]int)
+	slice := func(sn string) map[string]int {
+		if s[sn] == nil {
+			s[sn] = map[string]int{"registered": 0, "rejected": 0}
+		}
+		return s[sn]
+	}
+	for _, sn := range a.nssai {
+		slice(sn.String())
+	}
+	for sn, n := range a.unserved {
+		slice(sn)["rejected"] = n
+	}
+	for _, u := range a.registered {
+		for _, sn := range u.nssai {
+			slice(sn.String())["registered"]++
+		}
+	}
+	return s
+}
+
+// allow returns the slices of requested the AMF serves, its first one when
+// none is requested, and the slices it rejects.
+func (a *AMF) allow(requested identity.NSSAI) (allowed, rejected identity.NSSAI) {
+	if len(requested) == 0 {
+		return a.nssai[:1], nil
+	}
+	for _, sn := range requested {
+		if a.nssai.Contains(sn) {
+			allowed = append(allowed, sn)
+		} else {
+			rejected = append(rejected, sn)
+		}
+	}
+	a.mtx.Lock()
+	for _, sn := range rejected {
+		a.unserved[sn.String()]++
+	}
+	a.mtx.Unlock()
+	return allowed, rejected
+}
+
+// page pages the CM-IDLE UE u, and starts T3513, which pages it again on
+// its expiry, until the UE has been paged maxPagings times. A UE that
+// answers none is unreachable: the downlink NAS messages stored for it are
+// dropped, and the short messages among them fail.
+func (a *AMF) page(ctx context.Context, u *ue) {
+	a.sendPaging(ctx, u, false)
+	t := a.timers.Context(u.key())
+	t.Start(timers.T3513, a.t3513, func(e timers.Expiry) {
+		ctx, cancel := context.WithTimeout(context.Background(), pagingTimeout)
+		defer cancel()
+		if e.N < maxPagings {
+			if a.sendPaging(ctx, u, true) {
+				t.Restart(timers.T3513)
+			}
+			return
+		}
+		a.unreachable(ctx, u)
+	})
+}
+
+// pagingTimeout bounds the pagings sent again and the failures of the
+// short messages of the UEs unreachable.
+const pagingTimeout = 5 * time.Second
+
+// sendPaging pages the UE u, again when repaging, unless it is no longer
+// CM-IDLE. It reports whether the UE was paged.
+func (a *AMF) sendPaging(ctx context.Context, u *ue, repaging bool) bool {
+	a.mtx.Lock()
+	if !u.idle || a.registered[u.supi] != u {
+		a.mtx.Unlock()
+		return false
+	}
+	if repaging {
+		a.cm.repagings++
+	} else {
+		a.cm.pagings++
+	}
+	a.mtx.Unlock()
+	if err := a.ran.Paging(ctx, n2.Paging{GUTI: u.guti, DRX: u.drx}); err != nil {
+		level.Warn(a.logger).Log("amf_ue_ngap_id", u.id, "paging", "failed", "err", err)
+	}
+	return true
+}
+
+// unreachable drops the downlink NAS messages stored for the UE u, which
+// answered none of its pagings, and fails the short messages it did not
+// acknowledge.
+func (a *AMF) unreachable(ctx context.Context, u *ue) {
+	a.mtx.Lock()
+	if !u.idle || a.registered[u.supi] != u {
+		a.mtx.Unlock()
+		return
+	}
+	dropped, mt := len(u.pending), u.mt
+	u.pending, u.mt = nil, make(map[uint8]delivery)
+	a.cm.unreachable++
+	a.mtx.Unlock()
+	level.Info(a.logger).Log("amf_ue_ngap_id", u.id, "paging", "unanswered", "dropped", dropped)
+	for _, d := range mt {
+		if !d.report {
+			a.fail(ctx, u.supi, d, n2.RPCauseDestinationOutOfOrder)
+		}
+	}
+}
+
+// key identifies the timers of u.
+func (u *ue) key() string {
+	return strconv.FormatUint(u.id, 10)
+}
+
+// fire fires the event of the NAS message msg at the UE ranUEID, and
+// releases its context when it is rejected or deregistered. The short
+// messages of a UL NAS Transport go to the SMSF instead. The downlink NAS
+// message answering msg is protected once the UE has a NAS security
+// context.
+func (a *AMF) fire(ctx context.Context, ranUEID uint64, u *ue, msg []byte, an n2.ANParameters) (n2.Downlink, error) {
+	plain, ht, count, err := a.unprotect(u, msg)
+	if err != nil {
+		return n2.Downlink{}, err
+	}
+	name, ies := n2.ParseNAS(plain)
+	if name == n2.ULNASTransport {
+		dl, err := a.transport(ctx, u, ies)
+		a.trace(ctx, u, "uplink", plain, "downlink", dl.NAS, "err", err)
+		if err == nil {
+			dl.NAS, err = protect(u.sec, dl.NAS)
+		}
+		return dl, err
+	}
+	event, ok := events[name]
+	if !ok {
+		return n2.Downlink{}, status.Errorf(codes.InvalidArgument, "n2: unexpected NAS message %q", name)
+	}
+	p := &procedure{a: a, u: u, msg: msg, ies: ies, ht: ht, count: count, an: an, dl: n2.Downlink{AMFUEID: u.id}}
+	err = u.fivegmm.Fire(ctx, event, p)
+	if r, ok := err.(*rejection); ok {
+		p.dl.NAS, p.dl.Release, err = r.nas, true, nil
+	}
+	a.trace(ctx, u, "uplink", plain, "downlink", p.dl.NAS, "state", u.fivegmm.State(), "err", err)
+	if err == nil {
+		p.dl.NAS, err = protect(u.sec, p.dl.NAS)
+	}
+	switch err.(type) {
+	case nil:
+	case *fsm.InvalidTransitionError, *fsm.GuardError:
+		return p.dl, status.Error(codes.FailedPrecondition, err.Error())
+	default:
+		return p.dl, err
+	}
+	if p.dl.Release {
+		a.forget(ctx, u)
+	}
+	level.Debug(a.logger).Log("ran_ue_ngap_id", ranUEID, "amf_ue_ngap_id", u.id, "nas", name, "state", u.fivegmm.State(), "release", p.dl.Release)
+	return p.dl, nil
+}
+
+// unprotect returns the plain NAS message of msg, uplink from the UE u,
+// with its security header type and its uplink NAS COUNT. The messages of
+// a UE without NAS security context are taken plain, or integrity
+// protected as the initial ones of a UE moving in, which moveIn checks.
+// Afterwards they must verify, the Security Mode Reject of a UE turning
+// the context down aside.
+func (a *AMF) unprotect(u *ue, msg []byte) ([]byte, nas.HeaderType, uint32, error) {
+	if u.sec == nil {
+		plain, ok := nas.Plain(msg)
+		if !ok {
+			return nil, 0, 0, status.Errorf(codes.InvalidArgument, "n2: ciphered NAS message of UE %d without NAS security context", u.id)
+		}
+		return plain, nas.PlainNAS, 0, nil
+	}
+	if len(msg) == 0 || msg[0] != nas.EPD5GMM {
+		if name, _ := n2.ParseNAS(msg); name == n2.SecurityModeReject {
+			return msg, nas.PlainNAS, 0, nil
+		}
+		return nil, 0, 0, status.Errorf(codes.PermissionDenied, "n2: NAS message of UE %d not security protected", u.id)
+	}
+	plain, ht, count, err := u.sec.Unprotect(msg, nas.Uplink)
+	if err != nil {
+		level.Info(a.logger).Log("amf_ue_ngap_id", u.id, "nas", "discarded", "err", err)
+		return nil, 0, 0, status.Errorf(codes.PermissionDenied, "n2: NAS message of UE %d discarded: %v", u.id, err)
+	}
+	return plain, ht, count, nil
+}
+
+// protect returns the downlink NAS message msg integrity protected and
+// ciphered with sec, as it is without NAS security context or when
+// already protected.
+func protect(sec *nas.Context, msg []byte) ([]byte, error) {
+	if sec == nil || len(msg) == 0 || msg[0] == nas.EPD5GMM {
+		return msg, nil
+	}
+	return sec.Protect(msg, nas.IntegrityProtectedAndCiphered, nas.Downlink)
+}
+
+// trace logs keyvals, NAS messages of the UE u, when its trace is active
+// under any of its identities.
+func (a *AMF) trace(ctx context.Context, u *ue, keyvals ...interface{}) {
+	t, ok := a.traces.Active(u.supi, u.guti, u.mobileIdentity)
+	if !ok {
+		return
+	}
+	for i := 1; i < len(keyvals); i += 2 {
+		if nas, ok := keyvals[i].([]byte); ok {
+			keyvals[i] = uetrace.Dump(nas)
+		}
+	}
+	a.traces.Log(ctx, t, append([]interface{}{"amf_ue_ngap_id", u.id}, keyvals...)...)
+}
+
+// forget removes the context of the UE u, deregistered or released, and
+// fails the short messages it did not acknowledge.
+func (a *AMF) forget(ctx context.Context, u *ue) {
+	a.mtx.Lock()
+	if a.ues[u.ranUEID] == u {
+		delete(a.ues, u.ranUEID)
+	}
+	if a.registered[u.supi] == u {
+		delete(a.registered, u.supi)
+		a.persistLocked(u.supi)
+		if a.replicas != nil {
+			a.replicas.Delete(u.supi)
+		}
+	}
+	mt := u.mt
+	u.mt, u.pending = nil, nil
+	a.mtx.Unlock()
+	a.timers.Context(u.key()).StopAll()
+	for _, d := range mt {
+		if !d.report {
+			a.fail(ctx, u.supi, d, n2.RPCauseDestinationOutOfOrder)
+		}
+	}
+}
+
+// rejection is returned by the actions rejecting the UE with nas, its
+// context being released.
+type rejection struct {
+	nas []byte
+}
+
+func (r *rejection) Error() string {
+	name, _ := n2.ParseNAS(r.nas)
+	return name
+}
+
+func reject(cause uint8) error {
+	return &rejection{nas: nasbuild.RegistrationReject(cause).NAS()}
+}
+
+// authenticate gets a challenge for the UE from the AUSF, rejecting the UE
+// of another PLMN or unknown to the home network.
+func authenticate(ctx context.Context, arg interface{}) error {
+	p := arg.(*procedure)
+	a := p.a
+	req := n2.GetIEs(n2.RegistrationRequest).(*n2.RegistrationRequestIEs)
+	defer n2.PutIEs(n2.RegistrationRequest, req)
+	if err := json.Unmarshal(p.ies, req); err != nil || req.MobileIdentity == "" {
+		return reject(n2.CauseProtocolError)
+	}
+	if p.an.SelectedPLMN != "" && p.an.SelectedPLMN != a.guami.PLMN.String() {
+		return reject(n2.CausePLMNNotAllowed)
+	}
+	p.u.mobileIdentity = req.MobileIdentity
+	if p.u.nssai, p.u.rejected = a.allow(req.RequestedNSSAI); len(p.u.nssai) == 0 {
+		level.Info(a.logger).Log("amf_ue_ngap_id", p.u.id, "mobile_identity", req.MobileIdentity, "rejected_nssai", p.u.rejected)
+		return reject(n2.CauseNoSlicesAvailable)
+	}
+	p.u.drx = n2.DefaultDRX
+	if n2.ValidDRX(req.RequestedDRX) {
+		p.u.drx = req.RequestedDRX
+	}
+	ac, err := a.ausf.Authenticate(ctx, req.MobileIdentity, a.snn, nil)
+	switch status.Code(err) {
+	case codes.OK:
+	case codes.NotFound, codes.InvalidArgument, codes.PermissionDenied:
+		level.Info(a.logger).Log("amf_ue_ngap_id", p.u.id, "mobile_identity", req.MobileIdentity, "rejected", err)
+		return reject(n2.CauseIllegalUE)
+	default:
+		return err
+	}
+	p.u.authCtxID, p.u.rand, p.u.hxresStar = ac.ID, ac.Rand, ac.HxresStar
+	p.dl.NAS = nasbuild.AuthenticationRequest().WithABBA(abba).WithChallenge(ac.Rand, ac.Autn).NAS()
+	return nil
+}
+
+// secure checks the RES* of the UE against HXRES*, then has the AUSF
+// confirm it, and starts the security mode control with the NAS security
+// context of K_AMF, the Security Mode Command integrity protected with it.
+func secure(ctx context.Context, arg interface{}) error {
+	p := arg.(*procedure)
+	a, u := p.a, p.u
+	rsp := n2.GetIEs(n2.AuthenticationResponse).(*n2.AuthenticationResponseIEs)
+	defer n2.PutIEs(n2.AuthenticationResponse, rsp)
+	if err := json.Unmarshal(p.ies, rsp); err != nil {
+		return reject(n2.CauseProtocolError)
+	}
+	if !bytes.Equal(security.HResStar(u.rand, rsp.ResStar), u.hxresStar) {
+		return &rejection{nas: []byte(n2.AuthenticationReject)}
+	}
+	cr, err := a.ausf.ConfirmAuth(ctx, u.authCtxID, rsp.ResStar)
+	switch status.Code(err) {
+	case codes.OK:
+	case codes.Unauthenticated, codes.NotFound:
+		return &rejection{nas: []byte(n2.AuthenticationReject)}
+	default:
+		return err
+	}
+	u.supi, u.kamf = cr.SUPI, security.Kamf(cr.Kseaf, cr.SUPI, abba)
+	u.authCtxID, u.rand, u.hxresStar = "", nil, nil
+	u.sec = nas.NewContext(u.kamf, nas.NEA2, nas.NIA2, nas.AccessNon3GPP)
+	smc := nasbuild.SecurityModeCommand().WithAlgorithms(u.sec.EncAlg, u.sec.IntAlg).WithABBA(abba).NAS()
+	p.dl.NAS, err = u.sec.Protect(smc, nas.IntegrityProtectedWithNewContext, nas.Downlink)
+	return err
+}
+
+// accept accepts the registration of the UE whose Security Mode Complete
+// verified with the new NAS security context, with the K_N3IWF of its
+// uplink NAS COUNT for the N3IWF.
+func accept(ctx context.Context, arg interface{}) error {
+	p := arg.(*procedure)
+	a, u := p.a, p.u
+	if p.ht != nas.IntegrityProtectedAndCipheredNew {
+		return status.Errorf(codes.PermissionDenied, "n2: %s of UE %d not protected with the new NAS security context", n2.SecurityModeComplete, u.id)
+	}
+	if r, from, ok := a.takeOver(u.supi); ok {
+		u.guti = r.GUTI
+		level.Info(a.logger).Log("amf_ue_ngap_id", u.id, "supi", u.supi, "guti", u.guti, "taken_over_from", from)
+	} else {
+		a.mtx.Lock()
+		a.nextTMSI++
+		tmsi := a.nextTMSI
+		a.mtx.Unlock()
+		u.guti = fmt.Sprintf("%s%08x", a.gutiPrefix(), tmsi)
+	}
+	p.dl.NAS = a.registrationAccept(u).WithRejectedNSSAI(u.rejected...).NAS()
+	p.dl.SecurityKey = security.Kn3iwf(u.kamf, p.count)
+	u.rejected = nil
+	a.mtx.Lock()
+	a.reg.initial++
+	a.mtx.Unlock()
+	level.Info(a.logger).Log("amf_ue_ngap_id", u.id, "supi", u.supi, "guti", u.guti, "nssai", u.nssai, "registered", true)
+	return nil
+}
+
+// complete completes the registration, the UE being reachable for short
+// messages from then on. It replaces the context of an earlier
+// registration of the SUPI, if any, keeping its locations, and sends the
+// UE that moved in the downlink NAS messages stored for it.
+func complete(ctx context.Context, arg interface{}) error {
+	p := arg.(*procedure)
+	a, u := p.a, p.u
+	a.mtx.Lock()
+	if earlier := a.registered[u.supi]; earlier != nil && earlier != u {
+		u.history = appendLocation(earlier.history, earlier.location)
+	}
+	a.registered[u.supi] = u
+	a.replicateLocked(u)
+	pending := u.pending
+	u.pending = nil
+	a.mtx.Unlock()
+	if len(pending) > 0 {
+		a.flush(ctx, u, pending)
+	}
+	return nil
+}
+
+// rejectAuthentication ends the registration of a UE that failed to
+// authenticate the network. A synch failure is not recovered from, the AMF
+// not resynchronising the SQN of the UE with the AUSF.
+func rejectAuthentication(ctx context.Context, arg interface{}) error {
+	p := arg.(*procedure)
+	var f n2.CauseIEs
+	json.Unmarshal(p.ies, &f)
+	level.Info(p.a.logger).Log("amf_ue_ngap_id", p.u.id, "authentication_failure", f.Cause)
+	p.dl.NAS, p.dl.Release = []byte(n2.AuthenticationReject), true
+	return nil
+}
+
+// rejectSecurityMode ends the registration of a UE that turned the NAS
+// security context of its Security Mode Command down. The Registration
+// Reject goes in the clear, the UE not having taken the context.
+func rejectSecurityMode(ctx context.Context, arg interface{}) error {
+	p := arg.(*procedure)
+	var r n2.CauseIEs
+	json.Unmarshal(p.ies, &r)
+	level.Info(p.a.logger).Log("amf_ue_ngap_id", p.u.id, "security_mode_reject", r.Cause)
+	p.u.sec = nil
+	p.dl.NAS, p.dl.Release = nasbuild.RegistrationReject(n2.CauseSecurityModeRejected).NAS(), true
+	return nil
+}
+
+// deregister accepts the deregistration of the UE, whose context is
+// released.
+func deregister(ctx context.Context, arg interface{}) error {
+	p := arg.(*procedure)
+	level.Info(p.a.logger).Log("amf_ue_ngap_id", p.u.id, "supi", p.u.supi, "deregistered", true)
+	p.dl.NAS, p.dl.Release = []byte(n2.DeregistrationAccept), true
+	return nil
+}
+
+// replica is the context of a registered UE as replicated.
+type replica struct {
+	SUPI  string         `json:"supi"`
+	GUTI  string         `json:"guti"`
+	DRX   uint16         `json:"drx"`
+	NSSAI identity.NSSAI `json:"allowed_nssai,omitempty"`
+	Idle  bool           `json:"cm_idle"`
+	// TAI is the last TAI of the UE.
+	TAI *identity.TAI `json:"tai,omitempty"`
+	// Region is the region of the AMF the UE registered with.
+	Region string `json:"region"`
+}
+
+// replicateLocked replicates the context of the registered UE u, and has
+// it persisted, with the lock of the AMF held.
+func (a *AMF) replicateLocked(u *ue) {
+	if a.registered[u.supi] != u {
+		return
+	}
+	a.persistLocked(u.supi)
+	if a.replicas == nil {
+		return
+	}
+	b, _ := json.Marshal(a.replicaLocked(u))
+	a.replicas.Put(u.supi, b)
+}
+
+// replicaLocked returns the context of the registered UE u, with the lock
+// of the AMF held.
+func (a *AMF) replicaLocked(u *ue) replica {
+	r := replica{SUPI: u.supi, GUTI: u.guti, DRX: u.drx, NSSAI: u.nssai, Idle: u.idle}
+	if !u.location.Time.IsZero() {
+		tai := u.location.TAI
+		r.TAI = &tai
+	}
+	if a.replicas != nil {
+		r.Region = a.replicas.Region()
+	}
+	return r
+}
+
+// takeOver returns the context of supi imported, or else registered in the
+// other region, and where it came from: "import" or the region. It returns
+// false when there is none. An imported context is taken over once.
+func (a *AMF) takeOver(supi string) (r replica, from string, ok bool) {
+	a.mtx.Lock()
+	r, ok = a.imported[supi]
+	if ok {
+		delete(a.imported, supi)
+		a.persistLocked(supi)
+	}
+	a.mtx.Unlock()
+	if ok {
+		return r, "import", true
+	}
+	if a.replicas == nil {
+		return r, "", false
+	}
+	b, ok := a.replicas.Get(supi)
+	if !ok || json.Unmarshal(b, &r) != nil || r.Region == a.replicas.Region() {
+		return r, "", false
+	}
+	return r, r.Region, true
+}
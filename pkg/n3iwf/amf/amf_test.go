@@ -0,0 +1,90 @@
+package amf
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/n3iwf/n2"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/n3iwf/n2/nasbuild"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/security"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/security/nas"
+)
+
+func TestSecurityModeControl(t *testing.T) {
+	ctx := context.Background()
+	a := New(fuzzAUSF{}, fuzzGUAMI)
+	a.NGSetup(fuzzRAN{})
+
+	dl, err := a.InitialUEMessage(ctx, 1, nasbuild.RegistrationRequest().WithMobileIdentity(fuzzSUPI).NAS(), n2.ANParameters{}, n2.UserLocation{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name, _ := n2.ParseNAS(dl.NAS); name != n2.AuthenticationRequest {
+		t.Fatalf("got %q, want %s", name, n2.AuthenticationRequest)
+	}
+	dl, err = a.UplinkNASTransport(ctx, 1, nasbuild.AuthenticationResponse(fuzzResStar).NAS(), n2.UserLocation{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	kamf := security.Kamf(fuzzKseaf, fuzzSUPI, abba)
+	ue := nas.NewContext(kamf, nas.NEA2, nas.NIA2, nas.AccessNon3GPP)
+	smc, ht, _, err := ue.Unprotect(dl.NAS, nas.Downlink)
+	if err != nil || ht != nas.IntegrityProtectedWithNewContext {
+		t.Fatalf("Security Mode Command: header type %d, %v", ht, err)
+	}
+	if name, _ := n2.ParseNAS(smc); name != n2.SecurityModeCommand {
+		t.Fatalf("got %q, want %s", name, n2.SecurityModeCommand)
+	}
+
+	// a plain message is refused once the security mode control started
+	if _, err := a.UplinkNASTransport(ctx, 1, nasbuild.SecurityModeComplete().NAS(), n2.UserLocation{}); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("plain %s: %v, want %s", n2.SecurityModeComplete, err, codes.PermissionDenied)
+	}
+	complete, _ := ue.Protect(nasbuild.SecurityModeComplete().NAS(), nas.IntegrityProtectedAndCipheredNew, nas.Uplink)
+	dl, err = a.UplinkNASTransport(ctx, 1, complete, n2.UserLocation{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(dl.SecurityKey, security.Kn3iwf(kamf, 0)) {
+		t.Error("K_N3IWF not of the uplink NAS COUNT of the Security Mode Complete")
+	}
+	accept, ht, _, err := ue.Unprotect(dl.NAS, nas.Downlink)
+	if err != nil || ht != nas.IntegrityProtectedAndCiphered {
+		t.Fatalf("Registration Accept: header type %d, %v", ht, err)
+	}
+	if name, _ := n2.ParseNAS(accept); name != n2.RegistrationAccept {
+		t.Fatalf("got %q, want %s", name, n2.RegistrationAccept)
+	}
+	if _, err := a.UplinkNASTransport(ctx, 1, complete, n2.UserLocation{}); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("replayed %s: %v, want %s", n2.SecurityModeComplete, err, codes.PermissionDenied)
+	}
+	rc, _ := ue.Protect(nasbuild.RegistrationComplete().NAS(), nas.IntegrityProtectedAndCiphered, nas.Uplink)
+	if _, err := a.UplinkNASTransport(ctx, 1, rc, n2.UserLocation{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the UE moves to another connection with its NAS security context
+	guti := a.registered[fuzzSUPI].guti
+	count := ue.Count(nas.Uplink)
+	update, _ := ue.Protect(nasbuild.RegistrationRequest().WithRegistrationType(n2.RegistrationMobility).WithMobileIdentity(guti).NAS(), nas.IntegrityProtected, nas.Uplink)
+	dl, err = a.InitialUEMessage(ctx, 2, update, n2.ANParameters{}, n2.UserLocation{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(dl.SecurityKey, security.Kn3iwf(kamf, count)) {
+		t.Errorf("K_N3IWF not of the uplink NAS COUNT %d of the registration update", count)
+	}
+	if _, _, _, err := ue.Unprotect(dl.NAS, nas.Downlink); err != nil {
+		t.Fatalf("Registration Accept of the move: %v", err)
+	}
+	if _, err := a.InitialUEMessage(ctx, 3, update, n2.ANParameters{}, n2.UserLocation{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := a.ues[3]; ok {
+		t.Error("replayed registration update accepted")
+	}
+}
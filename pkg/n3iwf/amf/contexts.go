@@ -0,0 +1,173 @@
+package amf
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-kit/kit/log/level"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/identity"
+)
+
+// The CM states of the registered UEs (TS 23.501 5.3.3.2).
+const (
+	CMIdle      = "CM-IDLE"
+	CMConnected = "CM-CONNECTED"
+)
+
+// castagnoli is the table of the CRC-32C of the contexts exported.
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// UEContext is the context of a UE as exported, by SUPI: its JSON, and
+// the CRC-32C of it.
+type UEContext struct {
+	SUPI    string
+	Context []byte
+	CRC32C  uint32
+}
+
+// Filter selects the UE contexts exported. The zero Filter selects them
+// all.
+type Filter struct {
+	// SNSSAI selects the UEs allowed the slice.
+	SNSSAI *identity.SNSSAI
+	// CMState selects the UEs CMIdle or CMConnected.
+	CMState string
+	// TAC selects the UEs whose last TAI has the TAC.
+	TAC *uint32
+}
+
+// Conflict is what becomes of a context imported for a SUPI the AMF
+// already holds, registered or imported.
+type Conflict int
+
+// The conflict policies of an import.
+const (
+	// ConflictReject fails the import, which imports nothing.
+	ConflictReject Conflict = iota
+	// ConflictSkip keeps the context held.
+	ConflictSkip
+	// ConflictReplace replaces the context held with the one imported.
+	ConflictReplace
+)
+
+// ImportResult counts the contexts of an import.
+type ImportResult struct {
+	Imported, Skipped, Replaced int
+}
+
+// Export returns the contexts the AMF holds that f selects, ordered by
+// SUPI: those of the registered UEs, and those imported for the UEs yet to
+// register again.
+func (a *AMF) Export(f Filter) []UEContext {
+	a.mtx.Lock()
+	rs := make(map[string]replica, len(a.registered)+len(a.imported))
+	for supi, r := range a.imported {
+		rs[supi] = r
+	}
+	for supi, u := range a.registered {
+		rs[supi] = a.replicaLocked(u)
+	}
+	a.mtx.Unlock()
+	cs := make([]UEContext, 0, len(rs))
+	for _, r := range rs {
+		if f.SNSSAI != nil && !r.NSSAI.Contains(*f.SNSSAI) {
+			continue
+		}
+		if f.CMState == CMIdle && !r.Idle || f.CMState == CMConnected && r.Idle {
+			continue
+		}
+		if f.TAC != nil && (r.TAI == nil || r.TAI.TAC != *f.TAC) {
+			continue
+		}
+		cs = append(cs, encode(r))
+	}
+	sort.Slice(cs, func(i, j int) bool { return cs[i].SUPI < cs[j].SUPI })
+	return cs
+}
+
+// Import stores the contexts cs, which the UEs keep, with their 5G-GUTI,
+// when they register again, and reserves the 5G-TMSIs of the 5G-GUTIs of
+// the AMF. A registered UE keeps its context until then, even replaced.
+// The contexts are all checked first, none being imported unless all are
+// valid and, under ConflictReject, none is held.
+func (a *AMF) Import(cs []UEContext, policy Conflict) (ImportResult, error) {
+	var res ImportResult
+	if policy < ConflictReject || policy > ConflictReplace {
+		return res, status.Errorf(codes.InvalidArgument, "amf: unknown conflict policy %d", policy)
+	}
+	rs := make(map[string]replica, len(cs))
+	for _, c := range cs {
+		if crc32.Checksum(c.Context, castagnoli) != c.CRC32C {
+			return res, status.Errorf(codes.DataLoss, "amf: context of %s fails its CRC-32C", c.SUPI)
+		}
+		var r replica
+		if err := json.Unmarshal(c.Context, &r); err != nil || r.SUPI != c.SUPI || r.GUTI == "" {
+			return res, status.Errorf(codes.InvalidArgument, "amf: malformed context of %s", c.SUPI)
+		}
+		if _, ok := rs[r.SUPI]; ok {
+			return res, status.Errorf(codes.InvalidArgument, "amf: context of %s imported twice", c.SUPI)
+		}
+		rs[r.SUPI] = r
+	}
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	for _, c := range cs {
+		if a.heldLocked(c.SUPI) && policy == ConflictReject {
+			return res, status.Errorf(codes.FailedPrecondition, "amf: context of %s exists", c.SUPI)
+		}
+	}
+	for supi, r := range rs {
+		switch {
+		case !a.heldLocked(supi):
+			res.Imported++
+		case policy == ConflictSkip:
+			res.Skipped++
+			continue
+		default:
+			res.Replaced++
+		}
+		a.imported[supi] = r
+		a.reserveLocked(r.GUTI)
+		a.persistLocked(supi)
+	}
+	level.Info(a.logger).Log("imported", res.Imported, "skipped", res.Skipped, "replaced", res.Replaced)
+	return res, nil
+}
+
+// encode returns the context r as exported.
+func encode(r replica) UEContext {
+	b, _ := json.Marshal(r)
+	return UEContext{SUPI: r.SUPI, Context: b, CRC32C: crc32.Checksum(b, castagnoli)}
+}
+
+// heldLocked tells whether the AMF holds a context of supi, with its lock
+// held.
+func (a *AMF) heldLocked(supi string) bool {
+	_, ok := a.imported[supi]
+	return ok || a.registered[supi] != nil
+}
+
+// gutiPrefix is the 5G-GUTIs of the AMF without their 5G-TMSI.
+func (a *AMF) gutiPrefix() string {
+	return fmt.Sprintf("5g-guti-%s%s%s", a.guami.PLMN.MCC, a.guami.PLMN.MNC, a.guami.AMFID)
+}
+
+// reserveLocked keeps the 5G-TMSI of guti, a 5G-GUTI of the AMF, from
+// being given to another UE, with the lock of the AMF held.
+func (a *AMF) reserveLocked(guti string) {
+	prefix := a.gutiPrefix()
+	if !strings.HasPrefix(guti, prefix) {
+		return
+	}
+	tmsi, err := strconv.ParseUint(guti[len(prefix):], 16, 32)
+	if err == nil && uint32(tmsi) > a.nextTMSI {
+		a.nextTMSI = uint32(tmsi)
+	}
+}
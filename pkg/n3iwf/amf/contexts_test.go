@@ -0,0 +1,67 @@
+package amf
+
+import (
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/identity"
+)
+
+func TestImportExport(t *testing.T) {
+	guami, _ := identity.ParseGUAMI("208-93-cafe00")
+	from, to := New(nil, guami), New(nil, guami)
+	from.imported["imsi-208930000000001"] = replica{SUPI: "imsi-208930000000001", GUTI: "5g-guti-20893cafe0000000007", DRX: 64, NSSAI: identity.NSSAI{{SST: 1}}}
+	from.imported["imsi-208930000000002"] = replica{SUPI: "imsi-208930000000002", GUTI: "5g-guti-20893beef0000000009", NSSAI: identity.NSSAI{{SST: 2, SD: "000001"}}, Idle: true, TAI: &identity.TAI{PLMN: guami.PLMN, TAC: 0x2a}}
+
+	cs := from.Export(Filter{})
+	if len(cs) != 2 || cs[0].SUPI != "imsi-208930000000001" {
+		t.Fatalf("export: got %+v", cs)
+	}
+	if got := from.Export(Filter{SNSSAI: &identity.SNSSAI{SST: 2, SD: "000001"}, CMState: CMIdle}); len(got) != 1 || got[0].SUPI != "imsi-208930000000002" {
+		t.Errorf("filtered export: got %+v", got)
+	}
+	if got := from.Export(Filter{CMState: CMConnected}); len(got) != 1 || got[0].SUPI != "imsi-208930000000001" {
+		t.Errorf("CM-CONNECTED export: got %+v", got)
+	}
+	tac := uint32(0x2a)
+	if got := from.Export(Filter{TAC: &tac}); len(got) != 1 || got[0].SUPI != "imsi-208930000000002" {
+		t.Errorf("TAC export: got %+v", got)
+	}
+
+	corrupt := append([]UEContext(nil), cs...)
+	corrupt[1].CRC32C++
+	if _, err := to.Import(corrupt, ConflictReject); status.Code(err) != codes.DataLoss {
+		t.Errorf("corrupt import: got %v, want DataLoss", err)
+	}
+	if len(to.imported) != 0 {
+		t.Errorf("corrupt import: imported %d contexts, want none", len(to.imported))
+	}
+	for _, tc := range []struct {
+		policy Conflict
+		want   ImportResult
+		code   codes.Code
+	}{
+		{ConflictReject, ImportResult{Imported: 2}, codes.OK},
+		{ConflictReject, ImportResult{}, codes.FailedPrecondition},
+		{ConflictSkip, ImportResult{Skipped: 2}, codes.OK},
+		{ConflictReplace, ImportResult{Replaced: 2}, codes.OK},
+	} {
+		res, err := to.Import(cs, tc.policy)
+		if status.Code(err) != tc.code || res != tc.want {
+			t.Errorf("import %d: got %+v, %v, want %+v, %v", tc.policy, res, err, tc.want, tc.code)
+		}
+	}
+	// the 5G-TMSI of the 5G-GUTI of the AMF is reserved, not that of
+	// another AMF
+	if to.nextTMSI != 7 {
+		t.Errorf("next 5G-TMSI: got %d, want 7", to.nextTMSI)
+	}
+	if r, from, ok := to.takeOver("imsi-208930000000001"); !ok || from != "import" || r.GUTI != "5g-guti-20893cafe0000000007" {
+		t.Errorf("take over: got %+v, %q, %t", r, from, ok)
+	}
+	if _, _, ok := to.takeOver("imsi-208930000000001"); ok {
+		t.Error("take over: imported context taken over twice")
+	}
+}
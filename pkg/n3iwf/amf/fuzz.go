@@ -0,0 +1,103 @@
+package amf
+
+import (
+	"bytes"
+	"context"
+
+	ausfservice "github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/ausf/service"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/identity"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/kitx/fuzz"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/n3iwf/n2"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/security"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/security/nas"
+)
+
+// fuzzSUPI is the subscriber fuzzAUSF authenticates every UE as, with
+// fuzzRand and fuzzKseaf, of RES* fuzzResStar.
+const fuzzSUPI = "imsi-208930000000001"
+
+var (
+	fuzzGUAMI, _ = identity.ParseGUAMI("208-93-cafe00")
+	fuzzRand     = bytes.Repeat([]byte{0x23}, 16)
+	fuzzResStar  = bytes.Repeat([]byte{0x42}, 16)
+	fuzzKseaf    = bytes.Repeat([]byte{0x5a}, 32)
+)
+
+// fuzzAUSF runs 5G-AKA for every UE as fuzzSUPI, so that the harness and
+// the tests authenticate without a USIM.
+type fuzzAUSF struct{}
+
+func (fuzzAUSF) Authenticate(ctx context.Context, supiOrSuci, snn string, resync *ausfservice.Resync) (ausfservice.AuthContext, error) {
+	return ausfservice.AuthContext{ID: "1", Rand: fuzzRand, Autn: make([]byte, 16), HxresStar: security.HResStar(fuzzRand, fuzzResStar)}, nil
+}
+
+func (fuzzAUSF) ConfirmAuth(ctx context.Context, authCtxID string, resStar []byte) (ausfservice.ConfirmResult, error) {
+	return ausfservice.ConfirmResult{SUPI: fuzzSUPI, Kseaf: fuzzKseaf}, nil
+}
+
+// fuzzRAN drops what the AMF sends the N3IWF.
+type fuzzRAN struct{}
+
+func (fuzzRAN) DownlinkNASTransport(context.Context, uint64, []byte) error { return nil }
+func (fuzzRAN) Paging(context.Context, n2.Paging) error                    { return nil }
+func (fuzzRAN) UEContextReleaseCommand(context.Context, uint64) error      { return nil }
+func (fuzzRAN) OverloadStart(context.Context, n2.Overload) error           { return nil }
+func (fuzzRAN) OverloadStop(context.Context) error                         { return nil }
+
+// fuzzNAS plays data, the plain NAS messages of a UE, one per line, against
+// an AMF of its own: the first in an Initial UE Message, the others in UL
+// NAS Transports. Once the AMF sent the Security Mode Command, the harness
+// protects them as the UE would, with the NAS security context of K_AMF:
+// the Security Mode Complete with the new context, the Registration
+// Requests integrity protected only, the others ciphered too. A session is
+// a line of data, as in
+//
+//	RegistrationRequest {"mobile_identity":"imsi-208930000000001"}
+//	AuthenticationResponse {"res_star":"QkJCQkJCQkJCQkJCQkJCQg=="}
+//	SecurityModeComplete
+//	RegistrationComplete
+//	DeregistrationRequest
+func fuzzNAS(data []byte) int {
+	a := New(fuzzAUSF{}, fuzzGUAMI)
+	defer a.timers.Close()
+	a.NGSetup(fuzzRAN{})
+	ue := nas.NewContext(security.Kamf(fuzzKseaf, fuzzSUPI, abba), nas.NEA2, nas.NIA2, nas.AccessNon3GPP)
+
+	ctx := context.Background()
+	score, secured := fuzz.Interesting, false
+	for i, msg := range bytes.Split(data, []byte("\n")) {
+		name, _ := n2.ParseNAS(msg)
+		if name == "" {
+			return fuzz.Discard
+		}
+		if secured {
+			ht := nas.IntegrityProtectedAndCiphered
+			switch name {
+			case n2.SecurityModeComplete:
+				ht = nas.IntegrityProtectedAndCipheredNew
+			case n2.RegistrationRequest:
+				ht = nas.IntegrityProtected
+			}
+			msg, _ = ue.Protect(msg, ht, nas.Uplink)
+		}
+		var (
+			dl  n2.Downlink
+			err error
+		)
+		if i == 0 {
+			dl, err = a.InitialUEMessage(ctx, 1, msg, n2.ANParameters{}, n2.UserLocation{})
+		} else {
+			dl, err = a.UplinkNASTransport(ctx, 1, msg, n2.UserLocation{})
+		}
+		if err != nil {
+			score = fuzz.Normal
+			continue
+		}
+		if plain, ok := nas.Plain(dl.NAS); ok {
+			if name, _ := n2.ParseNAS(plain); name == n2.SecurityModeCommand {
+				secured = true
+			}
+		}
+	}
+	return score
+}
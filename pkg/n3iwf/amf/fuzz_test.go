@@ -0,0 +1,19 @@
+package amf
+
+import (
+	"testing"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/kitx/fuzz"
+)
+
+func TestFuzzNASCorpus(t *testing.T) {
+	seeds, err := fuzz.Corpus("FuzzNAS")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range seeds {
+		if score := fuzzNAS(data); score == fuzz.Discard {
+			t.Errorf("seed %s discarded", name)
+		}
+	}
+}
@@ -0,0 +1,9 @@
+//go:build gofuzz
+// +build gofuzz
+
+package amf
+
+// FuzzNAS is the go-fuzz function of fuzzNAS.
+func FuzzNAS(data []byte) int {
+	return fuzzNAS(data)
+}
@@ -0,0 +1,94 @@
+package amf
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/miki-tnt/sa5g-go-usvc-k8s/pb/amf"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/identity"
+)
+
+type grpcServer struct {
+	a *AMF
+}
+
+// NewGRPCServer returns the service exporting and importing the UE
+// contexts of a, and telling the locations of its UEs.
+func NewGRPCServer(a *AMF) pb.UEContextsServer {
+	return &grpcServer{a: a}
+}
+
+func (s *grpcServer) Export(req *pb.ExportRequest, stream pb.UEContexts_ExportServer) error {
+	var f Filter
+	if req.Snssai != "" {
+		sn, err := identity.ParseSNSSAI(req.Snssai)
+		if err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		f.SNSSAI = &sn
+	}
+	switch req.CmState {
+	case "", CMIdle, CMConnected:
+		f.CMState = req.CmState
+	default:
+		return status.Errorf(codes.InvalidArgument, "amf: unknown CM state %q", req.CmState)
+	}
+	if req.Tac != "" {
+		tac, err := identity.ParseTAC(req.Tac)
+		if err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		f.TAC = &tac
+	}
+	for _, c := range s.a.Export(f) {
+		if err := stream.Send(&pb.UEContext{Supi: c.SUPI, Context: c.Context, Crc32C: c.CRC32C}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *grpcServer) Import(stream pb.UEContexts_ImportServer) error {
+	var cs []UEContext
+	policy := ConflictReject
+	for first := true; ; first = false {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if first {
+			policy = Conflict(req.Conflict)
+		}
+		if c := req.Context; c != nil {
+			cs = append(cs, UEContext{SUPI: c.Supi, Context: c.Context, CRC32C: c.Crc32C})
+		}
+	}
+	res, err := s.a.Import(cs, policy)
+	if err != nil {
+		return err
+	}
+	return stream.SendAndClose(&pb.ImportReply{Imported: uint32(res.Imported), Skipped: uint32(res.Skipped), Replaced: uint32(res.Replaced)})
+}
+
+func (s *grpcServer) GetUELocation(ctx context.Context, req *pb.GetUELocationRequest) (*pb.UELocation, error) {
+	l, history, err := s.a.UELocation(req.Supi)
+	if err != nil {
+		return nil, err
+	}
+	rsp := &pb.UELocation{Location: location(l)}
+	for _, h := range history {
+		rsp.History = append(rsp.History, location(h))
+	}
+	return rsp, nil
+}
+
+func location(l Location) *pb.Location {
+	return &pb.Location{Tai: l.TAI.String(), UeAddress: l.UEAddress, Since: l.Time.UnixNano() / int64(time.Millisecond)}
+}
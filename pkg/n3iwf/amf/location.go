@@ -0,0 +1,230 @@
+package amf
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/go-kit/kit/log/level"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/identity"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/n3iwf/n2"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/n3iwf/n2/nasbuild"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/security"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/security/nas"
+)
+
+// maxHistory is the number of earlier locations kept for a UE.
+const maxHistory = 16
+
+// implicitMargin is how much longer than T3512 a CM-IDLE UE stays
+// registered by default, as the mobile reachable timer of TS 24.501 5.3.7.
+const implicitMargin = 4 * time.Minute
+
+// Location is where a UE is: the TAI of the N3IWF and the outer address
+// of the UE, since Time.
+type Location struct {
+	TAI       identity.TAI `json:"tai"`
+	UEAddress string       `json:"ue_address"`
+	Time      time.Time    `json:"time"`
+}
+
+// regStats count the initial registrations, the registration updates, and
+// the UEs deregistered implicitly.
+type regStats struct {
+	initial, mobility, periodic int
+	implicit                    int
+}
+
+// update counts a registration update of type typ.
+func (s *regStats) update(typ string) {
+	if typ == n2.RegistrationMobility {
+		s.mobility++
+	} else {
+		s.periodic++
+	}
+}
+
+// RegistrationStats returns the number of initial registrations, of
+// mobility and periodic registration updates, and of the UEs deregistered
+// implicitly.
+func (a *AMF) RegistrationStats() map[string]int {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	return map[string]int{
+		"initial":                  a.reg.initial,
+		"mobility_updates":         a.reg.mobility,
+		"periodic_updates":         a.reg.periodic,
+		"implicit_deregistrations": a.reg.implicit,
+	}
+}
+
+// UELocation returns the location of the registered UE supi, and its
+// earlier locations, the last one first.
+func (a *AMF) UELocation(supi string) (Location, []Location, error) {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	u := a.registered[supi]
+	if u == nil {
+		return Location{}, nil, status.Errorf(codes.NotFound, "amf: no registered UE %s", supi)
+	}
+	return u.location, append([]Location(nil), u.history...), nil
+}
+
+// locateLocked moves the UE u to loc, with the lock of the AMF held, its
+// location before going to its history.
+func (a *AMF) locateLocked(u *ue, loc n2.UserLocation) {
+	if u.location.TAI == loc.TAI && u.location.UEAddress == loc.UEAddress && !u.location.Time.IsZero() {
+		return
+	}
+	u.history = appendLocation(u.history, u.location)
+	u.location = Location{TAI: loc.TAI, UEAddress: loc.UEAddress, Time: time.Now()}
+}
+
+// appendLocation returns history with l ahead, the last location first,
+// keeping maxHistory locations. The zero l is not kept.
+func appendLocation(history []Location, l Location) []Location {
+	if l.Time.IsZero() {
+		return history
+	}
+	h := make([]Location, 0, maxHistory)
+	h = append(h, l)
+	for _, e := range history {
+		if len(h) == maxHistory {
+			break
+		}
+		h = append(h, e)
+	}
+	return h
+}
+
+// registrationAccept returns the Registration Accept of the UE u, whose
+// registration area is the TAI of its location.
+func (a *AMF) registrationAccept(u *ue) nasbuild.RegistrationAcceptBuilder {
+	return nasbuild.RegistrationAccept(u.guti).WithDRX(u.drx).WithAllowedNSSAI(u.nssai...).WithTAIList(u.location.TAI).WithT3512(a.t3512)
+}
+
+// byGUTILocked returns the registered UE of guti, nil if none, with the
+// lock of the AMF held.
+func (a *AMF) byGUTILocked(guti string) *ue {
+	for _, u := range a.registered {
+		if u.guti == guti {
+			return u
+		}
+	}
+	return nil
+}
+
+// registrationUpdate tells a Registration Request of a registration
+// update, its type not being initial.
+func registrationUpdate(ctx context.Context, arg interface{}) bool {
+	p := arg.(*procedure)
+	req := n2.GetIEs(n2.RegistrationRequest).(*n2.RegistrationRequestIEs)
+	defer n2.PutIEs(n2.RegistrationRequest, req)
+	if err := json.Unmarshal(p.ies, req); err != nil {
+		return false
+	}
+	return req.RegistrationType != "" && req.RegistrationType != n2.RegistrationInitial
+}
+
+// updateType tells the types of the registration updates.
+func updateType(typ string) bool {
+	return typ == n2.RegistrationMobility || typ == n2.RegistrationPeriodic
+}
+
+// moveIn moves the context of the registered UE of the 5G-GUTI of a
+// registration update to the new connection of the UE, which moved to
+// another access, and accepts it without 5G-AKA, with K_N3IWF derived from
+// its K_AMF again and the uplink NAS COUNT of the registration update,
+// which must verify with its NAS security context. The earlier connection
+// is released. A 5G-GUTI of no registered UE, or a registration update
+// that does not verify, is rejected, the UE registering anew.
+func moveIn(ctx context.Context, arg interface{}) error {
+	p := arg.(*procedure)
+	a, u := p.a, p.u
+	req := n2.GetIEs(n2.RegistrationRequest).(*n2.RegistrationRequestIEs)
+	defer n2.PutIEs(n2.RegistrationRequest, req)
+	if err := json.Unmarshal(p.ies, req); err != nil || !updateType(req.RegistrationType) {
+		return reject(n2.CauseProtocolError)
+	}
+	u.mobileIdentity = req.MobileIdentity
+	a.mtx.Lock()
+	old := a.byGUTILocked(req.MobileIdentity)
+	if old == nil {
+		a.mtx.Unlock()
+		level.Info(a.logger).Log("amf_ue_ngap_id", u.id, "guti", req.MobileIdentity, "registration_type", req.RegistrationType, "rejected", "unknown 5G-GUTI")
+		return reject(n2.CauseUEIdentityNotDerived)
+	}
+	var count uint32
+	err := nas.ErrMessage
+	if old.sec != nil {
+		_, _, count, err = old.sec.Unprotect(p.msg, nas.Uplink)
+	}
+	if err != nil {
+		a.mtx.Unlock()
+		level.Info(a.logger).Log("amf_ue_ngap_id", u.id, "guti", req.MobileIdentity, "registration_type", req.RegistrationType, "rejected", err)
+		return reject(n2.CauseUEIdentityNotDerived)
+	}
+	u.supi, u.guti, u.kamf, u.sec, u.drx, u.nssai = old.supi, old.guti, old.kamf, old.sec, old.drx, old.nssai
+	u.history = appendLocation(old.history, old.location)
+	u.mr, u.mt, u.pending = old.mr, old.mt, old.pending
+	old.mt, old.pending = make(map[uint8]delivery), nil
+	if a.ues[old.ranUEID] == old {
+		delete(a.ues, old.ranUEID)
+	}
+	a.registered[u.supi] = u
+	a.replicateLocked(u)
+	a.reg.update(req.RegistrationType)
+	oldRANUEID := old.ranUEID
+	a.mtx.Unlock()
+	a.timers.Context(old.key()).StopAll()
+	if err := a.ran.UEContextReleaseCommand(ctx, oldRANUEID); err != nil {
+		level.Debug(a.logger).Log("amf_ue_ngap_id", old.id, "ue_context_release_command", "failed", "err", err)
+	}
+	p.dl.NAS = a.registrationAccept(u).NAS()
+	p.dl.SecurityKey = security.Kn3iwf(u.kamf, count)
+	level.Info(a.logger).Log("amf_ue_ngap_id", u.id, "supi", u.supi, "guti", u.guti, "registration_type", req.RegistrationType, "moved_from", old.id)
+	return nil
+}
+
+// updateRegistration accepts the registration update of the registered
+// UE, which gets the registration area of its location.
+func updateRegistration(ctx context.Context, arg interface{}) error {
+	p := arg.(*procedure)
+	a, u := p.a, p.u
+	req := n2.GetIEs(n2.RegistrationRequest).(*n2.RegistrationRequestIEs)
+	defer n2.PutIEs(n2.RegistrationRequest, req)
+	if err := json.Unmarshal(p.ies, req); err != nil || !updateType(req.RegistrationType) {
+		return reject(n2.CauseMessageNotExpected)
+	}
+	if req.MobileIdentity != u.guti {
+		return reject(n2.CauseUEIdentityNotDerived)
+	}
+	a.mtx.Lock()
+	a.reg.update(req.RegistrationType)
+	a.replicateLocked(u)
+	a.mtx.Unlock()
+	p.dl.NAS = a.registrationAccept(u).NAS()
+	level.Debug(a.logger).Log("amf_ue_ngap_id", u.id, "supi", u.supi, "registration_type", req.RegistrationType, "tai", u.location.TAI)
+	return nil
+}
+
+// deregisterImplicitly deregisters the UE u, CM-IDLE since its implicit
+// deregistration timer started, and has the N3IWF release it.
+func (a *AMF) deregisterImplicitly(ctx context.Context, u *ue) {
+	a.mtx.Lock()
+	if !u.idle || a.registered[u.supi] != u {
+		a.mtx.Unlock()
+		return
+	}
+	a.reg.implicit++
+	ranUEID := u.ranUEID
+	a.mtx.Unlock()
+	level.Info(a.logger).Log("amf_ue_ngap_id", u.id, "supi", u.supi, "deregistered", "implicitly")
+	if err := a.ran.UEContextReleaseCommand(ctx, ranUEID); err != nil {
+		level.Debug(a.logger).Log("amf_ue_ngap_id", u.id, "ue_context_release_command", "failed", "err", err)
+	}
+	a.forget(ctx, u)
+}
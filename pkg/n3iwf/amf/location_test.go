@@ -0,0 +1,28 @@
+package amf
+
+import (
+	"testing"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/identity"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/n3iwf/n2"
+)
+
+func TestLocate(t *testing.T) {
+	guami, _ := identity.ParseGUAMI("208-93-cafe00")
+	a := New(nil, guami)
+	u := &ue{}
+	a.locateLocked(u, n2.UserLocation{TAI: identity.TAI{TAC: 1}, UEAddress: "192.0.2.1:500"})
+	a.locateLocked(u, n2.UserLocation{TAI: identity.TAI{TAC: 1}, UEAddress: "192.0.2.1:500"})
+	if len(u.history) != 0 {
+		t.Fatalf("same location: got history %+v", u.history)
+	}
+	for tac := uint32(2); tac < maxHistory+4; tac++ {
+		a.locateLocked(u, n2.UserLocation{TAI: identity.TAI{TAC: tac}, UEAddress: "192.0.2.1:500"})
+	}
+	if len(u.history) != maxHistory {
+		t.Fatalf("history: got %d locations, want %d", len(u.history), maxHistory)
+	}
+	if u.location.TAI.TAC != maxHistory+3 || u.history[0].TAI.TAC != maxHistory+2 {
+		t.Errorf("locations: got %v, then %v", u.location.TAI, u.history[0].TAI)
+	}
+}
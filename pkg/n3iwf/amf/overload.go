@@ -0,0 +1,85 @@
+package amf
+
+import (
+	"context"
+	"sync"
+
+	"github.com/go-kit/kit/log/level"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/n3iwf/n2"
+)
+
+// Overload has the AMF start the overload control of the RAN with o once
+// start NAS messages are in process, and stop it once they are down to
+// stop, below start, the gap keeping the control from flapping (TS
+// 23.501 5.19.5.2). The NAS messages in process are those of the UEs
+// waiting for the AMF, and for the AUSF behind it: the queue of the
+// signalling of the AMF rather than its CPU, which the stand-in shares
+// with the N3IWF. Without it, or with start zero, the AMF never starts the
+// control.
+func Overload(start, stop int, o n2.Overload) Option {
+	return func(a *AMF) {
+		if stop >= start {
+			stop = start - 1
+		}
+		a.ovl.start, a.ovl.stop, a.ovl.o = start, stop, o
+	}
+}
+
+// overload is the overload control of the AMF.
+type overload struct {
+	start, stop int
+	o           n2.Overload
+
+	// mtx serializes the changes of the overload and their signalling, for
+	// the Overload Start and Stop to reach the RAN in order.
+	mtx           sync.Mutex
+	inProcess     int
+	overloaded    bool
+	starts, stops int
+}
+
+// OverloadStats returns the number of NAS messages in process, whether the
+// overload control of the RAN runs, and the number of times it started and
+// stopped.
+func (a *AMF) OverloadStats() map[string]int {
+	a.ovl.mtx.Lock()
+	defer a.ovl.mtx.Unlock()
+	s := map[string]int{
+		"in_process": a.ovl.inProcess,
+		"overloaded": 0,
+		"starts":     a.ovl.starts,
+		"stops":      a.ovl.stops,
+	}
+	if a.ovl.overloaded {
+		s["overloaded"] = 1
+	}
+	return s
+}
+
+// process counts delta more NAS messages in process, and starts or stops
+// the overload control of the RAN as they cross its thresholds.
+func (a *AMF) process(ctx context.Context, delta int) {
+	a.ovl.mtx.Lock()
+	defer a.ovl.mtx.Unlock()
+	a.ovl.inProcess += delta
+	if a.ovl.start <= 0 {
+		return
+	}
+	var err error
+	switch {
+	case !a.ovl.overloaded && a.ovl.inProcess >= a.ovl.start:
+		a.ovl.overloaded = true
+		a.ovl.starts++
+		level.Warn(a.logger).Log("overload", "start", "in_process", a.ovl.inProcess, "action", a.ovl.o.Action, "traffic_load_reduction", a.ovl.o.TrafficLoadReduction)
+		err = a.ran.OverloadStart(ctx, a.ovl.o)
+	case a.ovl.overloaded && a.ovl.inProcess <= a.ovl.stop:
+		a.ovl.overloaded = false
+		a.ovl.stops++
+		level.Info(a.logger).Log("overload", "stop", "in_process", a.ovl.inProcess)
+		err = a.ran.OverloadStop(ctx)
+	}
+	if err != nil {
+		level.Warn(a.logger).Log("overload", "not signalled", "err", err)
+	}
+}
@@ -0,0 +1,87 @@
+package amf
+
+import (
+	"context"
+	"testing"
+
+	ausfservice "github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/ausf/service"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/n3iwf/n2"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/n3iwf/n2/nasbuild"
+)
+
+// slowAUSF holds every authentication until released, to keep the NAS
+// messages of the UEs in process.
+type slowAUSF struct {
+	fuzzAUSF
+	held, release chan struct{}
+}
+
+func (s slowAUSF) Authenticate(ctx context.Context, supiOrSuci, snn string, resync *ausfservice.Resync) (ausfservice.AuthContext, error) {
+	s.held <- struct{}{}
+	<-s.release
+	return s.fuzzAUSF.Authenticate(ctx, supiOrSuci, snn, resync)
+}
+
+// overloadRAN records the Overload Starts and Stops of the AMF.
+type overloadRAN struct {
+	fuzzRAN
+	signals chan string
+}
+
+func (r overloadRAN) OverloadStart(ctx context.Context, o n2.Overload) error {
+	r.signals <- "start " + o.Action
+	return nil
+}
+
+func (r overloadRAN) OverloadStop(ctx context.Context) error {
+	r.signals <- "stop"
+	return nil
+}
+
+func TestOverload(t *testing.T) {
+	ausf := slowAUSF{held: make(chan struct{}), release: make(chan struct{})}
+	ran := overloadRAN{signals: make(chan string, 4)}
+	a := New(ausf, fuzzGUAMI, Overload(3, 1, n2.Overload{Action: n2.OverloadRejectSignalling}))
+	defer a.timers.Close()
+	a.NGSetup(ran)
+
+	done := make(chan error)
+	for id := uint64(1); id <= 3; id++ {
+		go func(id uint64) {
+			_, err := a.InitialUEMessage(context.Background(), id, nasbuild.RegistrationRequest().WithMobileIdentity(fuzzSUPI).NAS(), n2.ANParameters{}, n2.UserLocation{})
+			done <- err
+		}(id)
+		<-ausf.held
+	}
+	if s := <-ran.signals; s != "start "+n2.OverloadRejectSignalling {
+		t.Fatalf("got %q, want the Overload Start at 3 NAS messages in process", s)
+	}
+
+	// the control goes on down to the stop threshold
+	ausf.release <- struct{}{}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if s := a.OverloadStats(); s["in_process"] != 2 || s["overloaded"] != 1 {
+		t.Fatalf("stats %v, want overloaded with 2 in process", s)
+	}
+	ausf.release <- struct{}{}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if s := <-ran.signals; s != "stop" {
+		t.Fatalf("got %q, want the Overload Stop at 1 NAS message in process", s)
+	}
+	ausf.release <- struct{}{}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case s := <-ran.signals:
+		t.Errorf("got %q after the Overload Stop", s)
+	default:
+	}
+	if s := a.OverloadStats(); s["in_process"] != 0 || s["starts"] != 1 || s["stops"] != 1 {
+		t.Errorf("stats %v", s)
+	}
+}
@@ -0,0 +1,93 @@
+package amf
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/kit/log/level"
+)
+
+// persistTimeout bounds a write of the contexts changed, persistRetry
+// being how long the writer waits before writing them again when it fails.
+const (
+	persistTimeout = 5 * time.Second
+	persistRetry   = time.Second
+)
+
+// Repository keeps the contexts of an AMF durably, by SUPI, as exported.
+type Repository interface {
+	// Sync puts the contexts cs and deletes those of the SUPIs of deleted,
+	// all of them or none.
+	Sync(ctx context.Context, cs []UEContext, deleted []string) error
+	// List returns the contexts, in SUPI order.
+	List(ctx context.Context) ([]UEContext, error)
+}
+
+// Restore imports the contexts of the repository of the AMF, those of the
+// UEs registered before it restarted, the UEs keeping their 5G-GUTI when
+// they register again. It returns the number of contexts imported.
+func (a *AMF) Restore(ctx context.Context) (int, error) {
+	cs, err := a.repo.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+	res, err := a.Import(cs, ConflictReplace)
+	return res.Imported + res.Replaced, err
+}
+
+// persistLocked has the context of supi written to the repository, or
+// deleted from it when the AMF no longer holds it, with the lock of the
+// AMF held.
+func (a *AMF) persistLocked(supi string) {
+	if a.repo == nil {
+		return
+	}
+	a.dirty[supi] = true
+	a.wake()
+}
+
+// wake wakes the writer of the contexts, unless it is awake already.
+func (a *AMF) wake() {
+	select {
+	case a.syncs <- struct{}{}:
+	default:
+	}
+}
+
+// persist writes the contexts changed to the repository, in one Sync, and
+// writes them again after persistRetry when it fails.
+func (a *AMF) persist() {
+	for range a.syncs {
+		a.mtx.Lock()
+		var cs []UEContext
+		var deleted []string
+		for supi := range a.dirty {
+			r, ok := a.imported[supi]
+			if u := a.registered[supi]; u != nil {
+				r, ok = a.replicaLocked(u), true
+			}
+			if ok {
+				cs = append(cs, encode(r))
+			} else {
+				deleted = append(deleted, supi)
+			}
+		}
+		changed := a.dirty
+		a.dirty = make(map[string]bool)
+		a.mtx.Unlock()
+
+		ctx, cancel := context.WithTimeout(context.Background(), persistTimeout)
+		err := a.repo.Sync(ctx, cs, deleted)
+		cancel()
+		if err == nil {
+			continue
+		}
+		level.Warn(a.logger).Log("persist", "failed", "contexts", len(changed), "err", err)
+		a.mtx.Lock()
+		for supi := range changed {
+			a.dirty[supi] = true
+		}
+		a.mtx.Unlock()
+		time.AfterFunc(persistRetry, a.wake)
+	}
+}
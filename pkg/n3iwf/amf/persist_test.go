@@ -0,0 +1,81 @@
+package amf
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/identity"
+)
+
+// memRepository is a Repository in memory.
+type memRepository struct {
+	mtx sync.Mutex
+	cs  map[string]UEContext
+}
+
+func (r *memRepository) Sync(ctx context.Context, cs []UEContext, deleted []string) error {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	for _, c := range cs {
+		r.cs[c.SUPI] = c
+	}
+	for _, supi := range deleted {
+		delete(r.cs, supi)
+	}
+	return nil
+}
+
+func (r *memRepository) List(ctx context.Context) ([]UEContext, error) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	cs := make([]UEContext, 0, len(r.cs))
+	for _, c := range r.cs {
+		cs = append(cs, c)
+	}
+	sort.Slice(cs, func(i, j int) bool { return cs[i].SUPI < cs[j].SUPI })
+	return cs, nil
+}
+
+func (r *memRepository) len() int {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	return len(r.cs)
+}
+
+func TestPersist(t *testing.T) {
+	guami, _ := identity.ParseGUAMI("208-93-cafe00")
+	repo := &memRepository{cs: make(map[string]UEContext)}
+	from := New(nil, guami, Persist(repo))
+	from.imported["imsi-208930000000001"] = replica{SUPI: "imsi-208930000000001", GUTI: "5g-guti-20893cafe0000000007"}
+	from.imported["imsi-208930000000002"] = replica{SUPI: "imsi-208930000000002", GUTI: "5g-guti-20893cafe0000000009"}
+	if _, err := from.Import(from.Export(Filter{}), ConflictReplace); err != nil {
+		t.Fatal(err)
+	}
+	// the contexts are written behind
+	wait := func(n int) {
+		for deadline := time.Now().Add(time.Second); repo.len() != n; time.Sleep(10 * time.Millisecond) {
+			if time.Now().After(deadline) {
+				t.Fatalf("persisted %d contexts, want %d", repo.len(), n)
+			}
+		}
+	}
+	wait(2)
+	if _, _, ok := from.takeOver("imsi-208930000000002"); !ok {
+		t.Fatal("take over: no context")
+	}
+	wait(1)
+
+	to := New(nil, guami, Persist(repo))
+	if n, err := to.Restore(context.Background()); n != 1 || err != nil {
+		t.Fatalf("restore: got %d, %v", n, err)
+	}
+	if r, _, ok := to.takeOver("imsi-208930000000001"); !ok || r.GUTI != "5g-guti-20893cafe0000000007" {
+		t.Errorf("take over restored: got %+v, %t", r, ok)
+	}
+	if to.nextTMSI != 7 {
+		t.Errorf("next 5G-TMSI: got %d, want 7", to.nextTMSI)
+	}
+}
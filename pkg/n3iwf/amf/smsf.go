@@ -0,0 +1,243 @@
+package amf
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/go-kit/kit/log/level"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/n3iwf/n2"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/n3iwf/n2/nasbuild"
+)
+
+// maxPending bounds the downlink NAS messages stored for a CM-IDLE UE.
+const maxPending = 16
+
+var (
+	errStoreFull = errors.New("amf: store of the CM-IDLE UE full")
+	errGone      = errors.New("amf: UE deregistered")
+)
+
+// delivery is a short message, or the status report of one, delivered to
+// a UE and not acknowledged yet.
+type delivery struct {
+	// originator and reference are the originator of the short message
+	// and the reference of the RP-DATA it submitted it in.
+	originator string
+	reference  uint8
+	report     bool
+}
+
+// smsStats count the short messages submitted to the SMSF, then delivered
+// or failed, and the pagings of the UEs they were stored for.
+type smsStats struct {
+	submitted, delivered, failed, paged int
+}
+
+// SMSStats returns the number of short messages submitted, delivered and
+// failed, of the pagings, and of the UEs and downlink NAS messages stored
+// for them while CM-IDLE.
+//
+// The SMSF routes the RP-DATA a UE submits to the registered UE of its
+// destination SUPI, answering the originator with an RP-ACK, or with an
+// RP-ERROR when the destination is unknown. A CM-CONNECTED destination gets
+// the short message in a DL NAS Transport at once; for a CM-IDLE one it is
+// stored, the UE being paged, and delivered on its Service Request. The
+// RP-ACK or RP-ERROR of the destination comes back to the originator as a
+// status report, stored and delivered the same way; a destination that
+// deregisters first fails the short messages it did not acknowledge.
+func (a *AMF) SMSStats() map[string]int {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	s := map[string]int{
+		"submitted": a.sms.submitted,
+		"delivered": a.sms.delivered,
+		"failed":    a.sms.failed,
+		"paged":     a.sms.paged,
+	}
+	for _, u := range a.ues {
+		if u.idle {
+			s["idle"]++
+			s["stored"] += len(u.pending)
+		}
+	}
+	return s
+}
+
+// transport hands the short message relay message of the UL NAS Transport
+// of the UE u to the SMSF, answering with the RP-ACK or the RP-ERROR of an
+// RP-DATA.
+func (a *AMF) transport(ctx context.Context, u *ue, ies []byte) (dl n2.Downlink, err error) {
+	dl.AMFUEID = u.id
+	a.mtx.Lock()
+	idle := u.idle
+	a.mtx.Unlock()
+	if u.fivegmm.State() != stateRegistered || idle {
+		return dl, status.Errorf(codes.FailedPrecondition, "n2: UE %d is not registered and CM-CONNECTED", u.id)
+	}
+	t := n2.GetIEs(n2.ULNASTransport).(*n2.NASTransportIEs)
+	defer n2.PutIEs(n2.ULNASTransport, t)
+	if err := json.Unmarshal(ies, t); err != nil || t.PayloadContainerType != n2.PayloadContainerSMS {
+		return dl, status.Errorf(codes.InvalidArgument, "n2: %s carries no short message", n2.ULNASTransport)
+	}
+	name, rp := n2.ParseNAS(t.PayloadContainer)
+	switch name {
+	case n2.RPData:
+		var data n2.RPDataIEs
+		if err := json.Unmarshal(rp, &data); err != nil || data.SMS == nil || data.SMS.Destination == "" {
+			dl.NAS = nasbuild.RPError(data.Reference, n2.RPCauseProtocolError).Downlink().NAS()
+			return dl, nil
+		}
+		dl.NAS = downlinkSMS(a.submit(ctx, u, data.Reference, *data.SMS))
+	case n2.RPAck:
+		var ack n2.RPAckIEs
+		json.Unmarshal(rp, &ack)
+		a.acknowledge(ctx, u, ack.Reference, 0)
+	case n2.RPError:
+		var e n2.RPErrorIEs
+		json.Unmarshal(rp, &e)
+		if e.Cause == 0 {
+			e.Cause = n2.RPCauseProtocolError
+		}
+		a.acknowledge(ctx, u, e.Reference, e.Cause)
+	default:
+		return dl, status.Errorf(codes.InvalidArgument, "n2: unexpected short message relay message %q", name)
+	}
+	return dl, nil
+}
+
+// submit routes the short message sms the UE u submitted in the RP-DATA of
+// reference to its destination, and returns the RP-ACK or the RP-ERROR
+// answering the UE.
+func (a *AMF) submit(ctx context.Context, u *ue, reference uint8, sms n2.SMS) []byte {
+	a.mtx.Lock()
+	v := a.registered[sms.Destination]
+	a.mtx.Unlock()
+	cause := n2.RPCauseUnknownSubscriber
+	if v != nil {
+		err := a.deliver(ctx, v, delivery{originator: u.supi, reference: reference}, n2.RPDataIEs{
+			SMS: &n2.SMS{Originator: u.supi, UserData: sms.UserData},
+		})
+		switch err {
+		case nil:
+			a.mtx.Lock()
+			a.sms.submitted++
+			a.mtx.Unlock()
+			level.Debug(a.logger).Log("amf_ue_ngap_id", u.id, "sms", "submitted", "destination", sms.Destination)
+			return nasbuild.RPAck(reference).NAS()
+		case errGone:
+		case errStoreFull:
+			cause = n2.RPCauseCongestion
+		default:
+			cause = n2.RPCauseTemporaryFailure
+		}
+	}
+	a.mtx.Lock()
+	a.sms.failed++
+	a.mtx.Unlock()
+	level.Info(a.logger).Log("amf_ue_ngap_id", u.id, "sms", "rejected", "destination", sms.Destination, "cause", cause)
+	return nasbuild.RPError(reference, cause).NAS()
+}
+
+// deliver delivers the RP-DATA of data to the UE v, now when CM-CONNECTED,
+// or on its Service Request when CM-IDLE, paging it. The DL NAS Transport
+// is protected as it is sent, the stored ones when flushed.
+func (a *AMF) deliver(ctx context.Context, v *ue, d delivery, data n2.RPDataIEs) error {
+	a.mtx.Lock()
+	if a.registered[v.supi] != v {
+		a.mtx.Unlock()
+		return errGone
+	}
+	if v.idle && len(v.pending) >= maxPending {
+		a.mtx.Unlock()
+		return errStoreFull
+	}
+	v.mr++
+	data.Reference = v.mr
+	v.mt[data.Reference] = d
+	nas := downlinkSMS(n2.NAS(n2.RPData, data))
+	if v.idle {
+		v.pending = append(v.pending, nas)
+		page := len(v.pending) == 1
+		if page {
+			a.sms.paged++
+		}
+		a.mtx.Unlock()
+		if page {
+			a.page(ctx, v)
+		}
+		return nil
+	}
+	ranUEID, sec := v.ranUEID, v.sec
+	a.mtx.Unlock()
+	protected, err := protect(sec, nas)
+	if err == nil {
+		err = a.ran.DownlinkNASTransport(ctx, ranUEID, protected)
+	}
+	if err != nil {
+		a.mtx.Lock()
+		delete(v.mt, data.Reference)
+		a.mtx.Unlock()
+		return err
+	}
+	a.trace(ctx, v, "downlink", nas)
+	return nil
+}
+
+// acknowledge ends the delivery of the RP-DATA of reference to the UE u,
+// which acknowledged it with an RP-ACK, or an RP-ERROR of cause, and
+// reports the delivery of a short message to its originator.
+func (a *AMF) acknowledge(ctx context.Context, u *ue, reference uint8, cause uint8) {
+	a.mtx.Lock()
+	d, ok := u.mt[reference]
+	delete(u.mt, reference)
+	if ok && !d.report && cause == 0 {
+		a.sms.delivered++
+	}
+	a.mtx.Unlock()
+	switch {
+	case !ok || d.report:
+	case cause == 0:
+		a.report(ctx, u.supi, d, 0)
+	default:
+		a.fail(ctx, u.supi, d, cause)
+	}
+}
+
+// fail reports the failed delivery of d to recipient with cause.
+func (a *AMF) fail(ctx context.Context, recipient string, d delivery, cause uint8) {
+	a.mtx.Lock()
+	a.sms.failed++
+	a.mtx.Unlock()
+	a.report(ctx, recipient, d, cause)
+}
+
+// report delivers to the originator of d the status report of its delivery
+// to recipient, successful when cause is zero. The report is lost when the
+// originator deregistered.
+func (a *AMF) report(ctx context.Context, recipient string, d delivery, cause uint8) {
+	a.mtx.Lock()
+	o := a.registered[d.originator]
+	a.mtx.Unlock()
+	if o == nil {
+		return
+	}
+	err := a.deliver(ctx, o, delivery{report: true}, n2.RPDataIEs{StatusReport: &n2.StatusReport{
+		MessageReference: d.reference,
+		Recipient:        recipient,
+		Delivered:        cause == 0,
+		Cause:            cause,
+	}})
+	if err != nil {
+		level.Info(a.logger).Log("amf_ue_ngap_id", o.id, "status_report", "lost", "err", err)
+	}
+}
+
+// downlinkSMS returns the DL NAS Transport of the short message relay
+// message rp.
+func downlinkSMS(rp []byte) []byte {
+	return n2.NAS(n2.DLNASTransport, n2.NASTransportIEs{PayloadContainerType: n2.PayloadContainerSMS, PayloadContainer: rp})
+}
@@ -0,0 +1,73 @@
+package endpoints
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/kitx/chain"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/n3iwf/service"
+)
+
+// Endpoints collects all of the endpoints that compose the n3iwf service. It's
+// meant to be used as a helper struct, to collect all of the endpoints into a
+// single parameter.
+type Endpoints struct {
+	ListUEsEndpoint   endpoint.Endpoint `json:""`
+	ReleaseUEEndpoint endpoint.Endpoint `json:""`
+}
+
+// New return a new instance of the endpoint that wraps the provided service.
+// The endpoints run in the middleware stack of mw.
+func New(svc service.N3iwfService, mw chain.MiddlewareConfig) (ep Endpoints) {
+	mw.Logging = LoggingMiddleware
+	mw.Instrumenting = InstrumentingMiddleware
+	c := chain.New(mw)
+	ep.ListUEsEndpoint = c.Build("listUEs", MakeListUEsEndpoint(svc))
+	ep.ReleaseUEEndpoint = c.Build("releaseUE", MakeReleaseUEEndpoint(svc))
+	return ep
+}
+
+// MakeListUEsEndpoint returns an endpoint that invokes ListUEs on the service.
+// Primarily useful in a server.
+func MakeListUEsEndpoint(svc service.N3iwfService) (ep endpoint.Endpoint) {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(ListUEsRequest)
+		if err := req.validate(); err != nil {
+			return ListUEsResponse{}, err
+		}
+		ues, err := svc.ListUEs(ctx)
+		return ListUEsResponse{UEs: ues}, err
+	}
+}
+
+// ListUEs implements the service interface, so Endpoints may be used as a service.
+// This is primarily useful in the context of a client library.
+func (e Endpoints) ListUEs(ctx context.Context) (ues []service.UE, err error) {
+	resp, err := e.ListUEsEndpoint(ctx, ListUEsRequest{})
+	if err != nil {
+		return
+	}
+	response := resp.(ListUEsResponse)
+	return response.UEs, nil
+}
+
+// MakeReleaseUEEndpoint returns an endpoint that invokes ReleaseUE on the service.
+// Primarily useful in a server.
+func MakeReleaseUEEndpoint(svc service.N3iwfService) (ep endpoint.Endpoint) {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(ReleaseUERequest)
+		if err := req.validate(); err != nil {
+			return ReleaseUEResponse{}, err
+		}
+		err := svc.ReleaseUE(ctx, req.RanUENgapID)
+		return ReleaseUEResponse{}, err
+	}
+}
+
+// ReleaseUE implements the service interface, so Endpoints may be used as a service.
+// This is primarily useful in the context of a client library.
+func (e Endpoints) ReleaseUE(ctx context.Context, ranUENgapID uint64) (err error) {
+	_, err = e.ReleaseUEEndpoint(ctx, ReleaseUERequest{RanUENgapID: ranUENgapID})
+	return err
+}
@@ -0,0 +1,55 @@
+package endpoints
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/go-kit/kit/metrics"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/clock"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/exemplar"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/logging"
+)
+
+// InstrumentingMiddleware returns an endpoint middleware that records
+// the duration of each invocation to the passed histogram. The middleware adds
+// a single field: "success", which is "true" if no error is returned, and
+// "false" otherwise. The trace of the call is the exemplar of its duration
+// when the histogram takes exemplars.
+func InstrumentingMiddleware(duration metrics.Histogram) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+			defer func(begin time.Time) {
+				exemplar.Observe(ctx, duration.With("success", fmt.Sprint(err == nil)), time.Since(begin).Seconds())
+			}(time.Now())
+			return next(ctx, request)
+		}
+	}
+}
+
+// LoggingMiddleware returns an endpoint middleware that logs the
+// duration of each invocation, timed by clk, and the resulting error, if any. Records carry
+// the trace and span IDs of the call when it is traced, and the UE of the
+// request, which is passed on in the context.
+func LoggingMiddleware(logger log.Logger, clk clock.Clock) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+			if r, ok := request.(logging.UERequest); ok {
+				ctx = logging.NewUEContext(ctx, r.UEID())
+			}
+			defer func(begin time.Time) {
+				logger := log.With(logger, logging.ContextKeyvals(ctx)...)
+				if err == nil {
+					level.Info(logger).Log("transport_error", err, "took", clk.Since(begin))
+				} else {
+					level.Error(logger).Log("transport_error", err, "took", clk.Since(begin))
+				}
+			}(clk.Now())
+			return next(ctx, request)
+		}
+	}
+}
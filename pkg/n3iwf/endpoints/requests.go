@@ -0,0 +1,30 @@
+package endpoints
+
+import (
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type Request interface {
+	validate() error
+}
+
+// ListUEsRequest collects the request parameters for the ListUEs method.
+type ListUEsRequest struct {
+}
+
+func (r ListUEsRequest) validate() error {
+	return nil // TBA
+}
+
+// ReleaseUERequest collects the request parameters for the ReleaseUE method.
+type ReleaseUERequest struct {
+	RanUENgapID uint64 `json:"ran_ue_ngap_id"`
+}
+
+func (r ReleaseUERequest) validate() error {
+	if r.RanUENgapID == 0 {
+		return status.Error(codes.InvalidArgument, "ran_ue_ngap_id is required")
+	}
+	return nil
+}
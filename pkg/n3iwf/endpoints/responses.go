@@ -0,0 +1,45 @@
+package endpoints
+
+import (
+	"net/http"
+
+	httptransport "github.com/go-kit/kit/transport/http"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/n3iwf/service"
+)
+
+var (
+	_ httptransport.Headerer = (*ListUEsResponse)(nil)
+
+	_ httptransport.StatusCoder = (*ListUEsResponse)(nil)
+	_ httptransport.Headerer    = (*ReleaseUEResponse)(nil)
+
+	_ httptransport.StatusCoder = (*ReleaseUEResponse)(nil)
+)
+
+// ListUEsResponse collects the response values for the ListUEs method.
+type ListUEsResponse struct {
+	UEs []service.UE `json:"ues"`
+	Err error        `json:"err"`
+}
+
+func (r ListUEsResponse) StatusCode() int {
+	return http.StatusOK // TBA
+}
+
+func (r ListUEsResponse) Headers() http.Header {
+	return http.Header{}
+}
+
+// ReleaseUEResponse collects the response values for the ReleaseUE method.
+type ReleaseUEResponse struct {
+	Err error `json:"err"`
+}
+
+func (r ReleaseUEResponse) StatusCode() int {
+	return http.StatusOK // TBA
+}
+
+func (r ReleaseUEResponse) Headers() http.Header {
+	return http.Header{}
+}
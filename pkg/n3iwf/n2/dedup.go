@@ -0,0 +1,111 @@
+package n2
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/store"
+)
+
+// The NGAP procedures whose messages Dedup deduplicates (TS 38.413 8.6).
+const (
+	ProcedureInitialUEMessage   = "InitialUEMessage"
+	ProcedureUplinkNASTransport = "UplinkNASTransport"
+)
+
+// ErrDuplicate answers a NAS message delivered again while the AMF is still
+// processing it; the first delivery gets the outcome.
+var ErrDuplicate = errors.New("n2: duplicate of a NAS message in process")
+
+const dedupPrefix = "n2-dedup/"
+
+// Middleware is a middleware of an AMF.
+type Middleware func(AMF) AMF
+
+// dedupRecord is the outcome of the last NAS message of a procedure of a UE,
+// Done once the AMF answered it.
+type dedupRecord struct {
+	Done        bool      `json:"done"`
+	Fingerprint string    `json:"fingerprint"`
+	Downlink    *Downlink `json:"downlink,omitempty"`
+}
+
+type dedup struct {
+	AMF
+	s         store.Store
+	window    time.Duration
+	ranNodeID string
+}
+
+// Dedup answers the NAS messages that the N2 delivers twice, such as the
+// InitialUEMessages an SCTP retransmission repeats, with the outcome of the
+// first delivery rather than running them again. The messages are keyed in
+// s on the RAN node ranNodeID, the RAN UE NGAP ID of the UE and the NGAP
+// procedure, and are duplicates when their NAS message is the same. The
+// outcome is kept for window after the last delivery, the window sliding
+// with every duplicate; a different NAS message of the procedure replaces
+// it. An error of the AMF is not kept, so that the message can be sent
+// again, and the AMF gets the message anyway when s fails.
+//
+// The outcome holds K_N3IWF once the UE is authenticated: s is one of the
+// N3IWF, and window no longer than the retransmissions last.
+func Dedup(s store.Store, window time.Duration, ranNodeID string) Middleware {
+	return func(next AMF) AMF {
+		return dedup{next, s, window, ranNodeID}
+	}
+}
+
+func (d dedup) InitialUEMessage(ctx context.Context, ranUEID uint64, nas []byte, an ANParameters, loc UserLocation) (Downlink, error) {
+	return d.once(ctx, ranUEID, ProcedureInitialUEMessage, nas, func() (Downlink, error) {
+		return d.AMF.InitialUEMessage(ctx, ranUEID, nas, an, loc)
+	})
+}
+
+func (d dedup) UplinkNASTransport(ctx context.Context, ranUEID uint64, nas []byte, loc UserLocation) (Downlink, error) {
+	return d.once(ctx, ranUEID, ProcedureUplinkNASTransport, nas, func() (Downlink, error) {
+		return d.AMF.UplinkNASTransport(ctx, ranUEID, nas, loc)
+	})
+}
+
+// once runs the NAS message nas of the procedure of the UE ranUEID with
+// next, or answers it with the outcome of its first delivery.
+func (d dedup) once(ctx context.Context, ranUEID uint64, procedure string, nas []byte, next func() (Downlink, error)) (Downlink, error) {
+	key := dedupPrefix + d.ranNodeID + "/" + strconv.FormatUint(ranUEID, 10) + "/" + procedure
+	sum := sha256.Sum256(nas)
+	fingerprint := hex.EncodeToString(sum[:])
+
+	pending, _ := json.Marshal(dedupRecord{Fingerprint: fingerprint})
+	created, err := d.s.SetNX(ctx, key, pending, d.window)
+	if err != nil {
+		// the store is only a safeguard; run the message anyway
+		return next()
+	}
+	if !created {
+		b, err := d.s.Get(ctx, key)
+		var rec dedupRecord
+		if err == nil && json.Unmarshal(b, &rec) == nil && rec.Fingerprint == fingerprint {
+			if !rec.Done {
+				return Downlink{}, ErrDuplicate
+			}
+			d.s.Set(ctx, key, b, d.window)
+			return *rec.Downlink, nil
+		}
+		// a new message of the procedure, or the record expired meanwhile
+		d.s.Set(ctx, key, pending, d.window)
+	}
+
+	dl, err := next()
+	if err != nil {
+		d.s.CompareAndDelete(ctx, key, pending)
+		return dl, err
+	}
+	if done, err := json.Marshal(dedupRecord{Done: true, Fingerprint: fingerprint, Downlink: &dl}); err == nil {
+		d.s.CompareAndSwap(ctx, key, pending, done, d.window)
+	}
+	return dl, nil
+}
@@ -0,0 +1,116 @@
+package n2
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/clock"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/store"
+)
+
+// countingAMF answers every NAS message with the next AMF UE NGAP ID, or
+// with err. With held set, it signals entered for every message and holds
+// it until held is closed.
+type countingAMF struct {
+	AMF
+	calls         uint64
+	err           error
+	entered, held chan struct{}
+}
+
+func (c *countingAMF) InitialUEMessage(ctx context.Context, ranUEID uint64, nas []byte, an ANParameters, loc UserLocation) (Downlink, error) {
+	return c.answer(nas)
+}
+
+func (c *countingAMF) UplinkNASTransport(ctx context.Context, ranUEID uint64, nas []byte, loc UserLocation) (Downlink, error) {
+	return c.answer(nas)
+}
+
+func (c *countingAMF) answer(nas []byte) (Downlink, error) {
+	if c.held != nil {
+		c.entered <- struct{}{}
+		<-c.held
+	}
+	c.calls++
+	if c.err != nil {
+		return Downlink{}, c.err
+	}
+	return Downlink{AMFUEID: c.calls, NAS: NAS(AuthenticationRequest, authRequest)}, nil
+}
+
+func TestDedup(t *testing.T) {
+	ctx := context.Background()
+	fake := clock.NewFake(time.Unix(0, 0))
+	amf := &countingAMF{}
+	d := Dedup(store.NewMemory(store.MemoryClock(fake)), 5*time.Second, "n3iwf-1")(amf)
+	registration := NAS(RegistrationRequest, RegistrationRequestIEs{MobileIdentity: "imsi-208930000000001"})
+
+	first, err := d.InitialUEMessage(ctx, 1, registration, ANParameters{}, UserLocation{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the window slides with every duplicate
+	for i := 0; i < 3; i++ {
+		fake.Advance(4 * time.Second)
+		dl, err := d.InitialUEMessage(ctx, 1, registration, ANParameters{}, UserLocation{})
+		if err != nil || dl.AMFUEID != first.AMFUEID || string(dl.NAS) != string(first.NAS) {
+			t.Fatalf("duplicate answered %+v, %v, want %+v", dl, err, first)
+		}
+	}
+	if amf.calls != 1 {
+		t.Fatalf("AMF got %d InitialUEMessages, want 1", amf.calls)
+	}
+
+	// the message of another UE, of another procedure or another message
+	// of the procedure are no duplicates
+	d.InitialUEMessage(ctx, 2, registration, ANParameters{}, UserLocation{})
+	d.UplinkNASTransport(ctx, 1, registration, UserLocation{})
+	d.UplinkNASTransport(ctx, 1, NAS(AuthenticationResponse, nil), UserLocation{})
+	if amf.calls != 4 {
+		t.Fatalf("AMF got %d messages, want 4", amf.calls)
+	}
+
+	// past the window, the message runs again
+	fake.Advance(5 * time.Second)
+	if dl, _ := d.InitialUEMessage(ctx, 1, registration, ANParameters{}, UserLocation{}); dl.AMFUEID != 5 {
+		t.Errorf("InitialUEMessage after the window answered by AMF UE %d, want 5", dl.AMFUEID)
+	}
+}
+
+func TestDedupErrors(t *testing.T) {
+	ctx := context.Background()
+	amf := &countingAMF{err: errors.New("AMF down")}
+	d := Dedup(store.NewMemory(), time.Minute, "n3iwf-1")(amf)
+	registration := NAS(RegistrationRequest, nil)
+
+	// an error is not kept: the message sent again runs again
+	for i := 0; i < 2; i++ {
+		if _, err := d.InitialUEMessage(ctx, 1, registration, ANParameters{}, UserLocation{}); err != amf.err {
+			t.Fatalf("got %v, want the error of the AMF", err)
+		}
+	}
+	if amf.calls != 2 {
+		t.Fatalf("AMF got %d InitialUEMessages, want 2", amf.calls)
+	}
+
+	// a duplicate of a message in process is answered ErrDuplicate
+	amf.err, amf.entered, amf.held = nil, make(chan struct{}), make(chan struct{})
+	done := make(chan error)
+	go func() {
+		_, err := d.InitialUEMessage(ctx, 1, registration, ANParameters{}, UserLocation{})
+		done <- err
+	}()
+	<-amf.entered
+	if _, err := d.InitialUEMessage(ctx, 1, registration, ANParameters{}, UserLocation{}); err != ErrDuplicate {
+		t.Errorf("duplicate in process answered %v, want ErrDuplicate", err)
+	}
+	close(amf.held)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if amf.calls != 3 {
+		t.Errorf("AMF got %d InitialUEMessages, want 3", amf.calls)
+	}
+}
@@ -0,0 +1,307 @@
+// Package n2 is the N2 interface between the N3IWF and the AMF (TS 38.413),
+// as far as the N3IWF relays the NAS messages of its UEs (TS 24.501) to the
+// AMF and gets their security context back. The N3IWF implements the RAN
+// side and calls an AMF.
+//
+// The NAS messages are those of the registration and deregistration of a UE
+// over a non-3GPP access, of its security mode control, of its registration
+// updates and service requests, and of the NAS transport of its short
+// messages (see RPData). Without a NAS codec, a NAS message carries the
+// name of its message, followed by its IEs in JSON when it has any (see
+// NAS), as the RRC messages of package f1 do. Package nasbuild builds them
+// with defaults. Once the security mode control ran, the NAS messages are
+// security protected, as package nas of package security protects them;
+// the N3IWF relays them as they are.
+package n2
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/bufpool"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/identity"
+)
+
+// The 5GMM messages of the registration, the authentication, the security
+// mode control, the deregistration, the service request and the NAS
+// transport (TS 24.501 8.2).
+const (
+	RegistrationRequest    = "RegistrationRequest"
+	RegistrationAccept     = "RegistrationAccept"
+	RegistrationComplete   = "RegistrationComplete"
+	RegistrationReject     = "RegistrationReject"
+	AuthenticationRequest  = "AuthenticationRequest"
+	AuthenticationResponse = "AuthenticationResponse"
+	AuthenticationFailure  = "AuthenticationFailure"
+	AuthenticationReject   = "AuthenticationReject"
+	SecurityModeCommand    = "SecurityModeCommand"
+	SecurityModeComplete   = "SecurityModeComplete"
+	SecurityModeReject     = "SecurityModeReject"
+	DeregistrationRequest  = "DeregistrationRequest"
+	DeregistrationAccept   = "DeregistrationAccept"
+	ServiceRequest         = "ServiceRequest"
+	ServiceAccept          = "ServiceAccept"
+	ULNASTransport         = "ULNASTransport"
+	DLNASTransport         = "DLNASTransport"
+)
+
+// The 5GMM causes of the rejects and failures (TS 24.501 9.11.3.2).
+const (
+	CauseIllegalUE            uint8 = 3
+	CauseUEIdentityNotDerived uint8 = 9
+	CausePLMNNotAllowed       uint8 = 11
+	CauseMACFailure           uint8 = 20
+	CauseSynchFailure         uint8 = 21
+	CauseSecurityModeRejected uint8 = 24
+	CauseNoSlicesAvailable    uint8 = 62
+	CauseMessageNotExpected   uint8 = 98
+	CauseProtocolError        uint8 = 111
+)
+
+// ErrUnknownUE is returned for the RAN UE NGAP ID of no UE context.
+var ErrUnknownUE = errors.New("n2: unknown UE context")
+
+// NAS returns the NAS message name, with the IEs ies when they are not nil,
+// such as
+//
+//	AuthenticationResponse {"res_star":"..."}
+func NAS(name string, ies interface{}) []byte {
+	return AppendNAS(nil, name, ies)
+}
+
+// AppendNAS appends the NAS message name, with the IEs ies, to dst, and
+// returns the extended buffer. The message is built in a buffer of package
+// bufpool, for dst to take it in one copy.
+func AppendNAS(dst []byte, name string, ies interface{}) []byte {
+	if ies == nil {
+		return append(dst, name...)
+	}
+	b := bufpool.Get()
+	defer bufpool.Put(b)
+	b.WriteString(name)
+	b.WriteByte(' ')
+	if err := b.JSON(ies); err != nil {
+		panic(err)
+	}
+	return append(dst, b.Bytes()...)
+}
+
+// ParseNAS splits the NAS message nas into its name and its IEs, nil when
+// there are none.
+func ParseNAS(nas []byte) (name string, ies []byte) {
+	if i := bytes.IndexByte(nas, ' '); i >= 0 {
+		return string(nas[:i]), nas[i+1:]
+	}
+	return string(nas), nil
+}
+
+// The 5GS registration types of a RegistrationRequest (TS 24.501
+// 9.11.3.7), RegistrationInitial when it has none.
+const (
+	RegistrationInitial  = "initial"
+	RegistrationMobility = "mobility"
+	RegistrationPeriodic = "periodic"
+)
+
+// RegistrationRequestIEs are the IEs of a RegistrationRequest. The mobile
+// identity is the SUPI or the SUCI of the UE, or its 5G-GUTI for a
+// registration update, the requested DRX the DRX cycle it would be paged
+// with, in radio frames, if it asks for one, and the requested NSSAI the
+// slices it would use, if it asks for any.
+type RegistrationRequestIEs struct {
+	RegistrationType string         `json:"registration_type,omitempty"`
+	MobileIdentity   string         `json:"mobile_identity"`
+	RequestedDRX     uint16         `json:"requested_drx,omitempty"`
+	RequestedNSSAI   identity.NSSAI `json:"requested_nssai,omitempty"`
+}
+
+// DefaultDRX is the DRX cycle of the UEs asking for none or for one not
+// allowed, in radio frames, the default paging cycle of the cells.
+const DefaultDRX uint16 = 128
+
+// ValidDRX tells the DRX cycles allowed (TS 24.501 9.11.3.2A).
+func ValidDRX(drx uint16) bool {
+	switch drx {
+	case 32, 64, 128, 256:
+		return true
+	}
+	return false
+}
+
+// AuthenticationRequestIEs are the IEs of an AuthenticationRequest: the
+// ngKSI and the ABBA the keys of the UE are derived with, and the challenge
+// of 5G-AKA.
+type AuthenticationRequestIEs struct {
+	NgKSI uint8  `json:"ngksi"`
+	ABBA  []byte `json:"abba"`
+	RAND  []byte `json:"rand"`
+	AUTN  []byte `json:"autn"`
+}
+
+// SecurityModeCommandIEs are the IEs of a SecurityModeCommand: the NAS
+// ciphering and integrity algorithms the AMF selected (TS 33.501 5.11.1),
+// and the ngKSI and the ABBA of K_AMF, which the NAS keys are derived from.
+type SecurityModeCommandIEs struct {
+	CipheringAlgorithm uint8  `json:"ciphering_algorithm"`
+	IntegrityAlgorithm uint8  `json:"integrity_algorithm"`
+	NgKSI              uint8  `json:"ngksi"`
+	ABBA               []byte `json:"abba"`
+}
+
+// AuthenticationResponseIEs are the IEs of an AuthenticationResponse.
+type AuthenticationResponseIEs struct {
+	ResStar []byte `json:"res_star"`
+}
+
+// CauseIEs are the IEs of an AuthenticationFailure, a SecurityModeReject
+// or a RegistrationReject.
+type CauseIEs struct {
+	Cause uint8 `json:"cause"`
+}
+
+// RegistrationAcceptIEs are the IEs of a RegistrationAccept: the 5G-GUTI
+// the AMF assigned the UE, the DRX cycle it negotiated, the slices it
+// allowed the UE and those it rejected among the requested ones, the TAIs
+// of its registration area, and T3512, the period of its periodic
+// registration updates, in seconds.
+type RegistrationAcceptIEs struct {
+	GUTI          string         `json:"guti"`
+	NegotiatedDRX uint16         `json:"negotiated_drx,omitempty"`
+	AllowedNSSAI  identity.NSSAI `json:"allowed_nssai,omitempty"`
+	RejectedNSSAI identity.NSSAI `json:"rejected_nssai,omitempty"`
+	TAIList       []identity.TAI `json:"tai_list,omitempty"`
+	T3512         uint32         `json:"t3512,omitempty"`
+}
+
+// ServiceRequestIEs are the IEs of a ServiceRequest: the 5G-GUTI of the
+// UE, in place of its 5G-S-TMSI.
+type ServiceRequestIEs struct {
+	GUTI string `json:"guti"`
+}
+
+// PayloadContainerSMS is the payload container type of the short messages
+// (TS 24.501 9.11.3.40).
+const PayloadContainerSMS uint8 = 0x02
+
+// NASTransportIEs are the IEs of a ULNASTransport or a DLNASTransport.
+type NASTransportIEs struct {
+	PayloadContainerType uint8  `json:"payload_container_type"`
+	PayloadContainer     []byte `json:"payload_container"`
+}
+
+// ANParameters are the access network parameters a UE gives with its first
+// NAS message, for the N3IWF to select its AMF (TS 24.502 9.3.2.2.2).
+type ANParameters struct {
+	GUAMI              string `json:"guami,omitempty"`
+	SelectedPLMN       string `json:"selected_plmn,omitempty"`
+	EstablishmentCause string `json:"establishment_cause,omitempty"`
+}
+
+// UserLocation is the N3IWF User Location Information that the N3IWF
+// sends with every NAS message of a UE (TS 38.413 9.3.1.16): the TAI of
+// the N3IWF, and the outer address of the UE, its IP address and port.
+type UserLocation struct {
+	TAI       identity.TAI
+	UEAddress string
+}
+
+// Downlink is what the AMF answers a NAS message of a UE with.
+type Downlink struct {
+	AMFUEID uint64
+	// NAS is the downlink NAS message to the UE, if any.
+	NAS []byte
+	// SecurityKey is K_N3IWF, set by the Initial Context Setup the AMF
+	// runs once the UE is authenticated.
+	SecurityKey []byte
+	// Release tells that the AMF released the UE context, after the NAS
+	// message: the UE is rejected or deregistered.
+	Release bool
+}
+
+// AMF is the AMF as the N3IWF sees it. The UEs are identified by the RAN UE
+// NGAP IDs the N3IWF gives them.
+type AMF interface {
+	// NGSetup sets up the N2 with ran, which the AMF sends the downlink NAS
+	// messages and the pagings of the UEs to.
+	NGSetup(ran RAN)
+	// InitialUEMessage forwards the first NAS message of the UE ranUEID at
+	// loc: its Registration Request, or the Service Request or the
+	// registration update of the UE back from CM-IDLE.
+	InitialUEMessage(ctx context.Context, ranUEID uint64, nas []byte, an ANParameters, loc UserLocation) (Downlink, error)
+	// UplinkNASTransport forwards a NAS message of the UE ranUEID at loc.
+	UplinkNASTransport(ctx context.Context, ranUEID uint64, nas []byte, loc UserLocation) (Downlink, error)
+	// ANRelease releases the signalling connection of the registered UE
+	// ranUEID, which goes CM-IDLE until its next Service Request.
+	ANRelease(ctx context.Context, ranUEID uint64) error
+	// UEContextRelease releases the UE context of ranUEID, the N3IWF
+	// having lost the UE.
+	UEContextRelease(ctx context.Context, ranUEID uint64) error
+}
+
+// RAN is the N3IWF as the AMF sees it.
+type RAN interface {
+	// DownlinkNASTransport sends the NAS message nas to the CM-CONNECTED
+	// UE ranUEID.
+	DownlinkNASTransport(ctx context.Context, ranUEID uint64, nas []byte) error
+	// Paging pages the CM-IDLE UE of p.
+	Paging(ctx context.Context, p Paging) error
+	// UEContextReleaseCommand releases the UE ranUEID, whose context the
+	// AMF released: the UE registered again on another connection, or was
+	// deregistered implicitly.
+	UEContextReleaseCommand(ctx context.Context, ranUEID uint64) error
+	// OverloadStart has the RAN reject the UEs of o setting up their
+	// signalling connection, until OverloadStop.
+	OverloadStart(ctx context.Context, o Overload) error
+	// OverloadStop ends the Overload Start of the AMF.
+	OverloadStop(ctx context.Context) error
+}
+
+// radioFrame is the length of a radio frame, the SFN counting them modulo
+// sfnCycle.
+const (
+	radioFrame = 10 * time.Millisecond
+	sfnCycle   = 1024
+)
+
+// Paging is the paging of a CM-IDLE UE: its 5G-GUTI and the DRX cycle it
+// listens to the paging with.
+type Paging struct {
+	GUTI string
+	DRX  uint16
+}
+
+// Delay returns how long after now the UE of p listens to the paging, the
+// start of its next paging frame: the radio frame whose SFN modulo the DRX
+// cycle T equals its UE_ID, the 5G-S-TMSI modulo 1024, modulo T, one paging
+// frame per cycle (TS 38.304 7.1). The SFN is that of radio frames counted
+// from the Unix epoch.
+func (p Paging) Delay(now time.Time) time.Duration {
+	t := int64(p.DRX)
+	if !ValidDRX(p.DRX) {
+		t = int64(DefaultDRX)
+	}
+	frames := now.UnixNano() / int64(radioFrame)
+	sfn := frames % sfnCycle
+	delta := (p.ueID()%t - sfn%t + t) % t
+	if delta == 0 {
+		return 0
+	}
+	into := time.Duration(now.UnixNano() - frames*int64(radioFrame))
+	return time.Duration(delta)*radioFrame - into
+}
+
+// ueID returns the UE_ID of the UE, the 5G-TMSI that ends its 5G-GUTI
+// modulo 1024, zero when it cannot be read.
+func (p Paging) ueID() int64 {
+	if len(p.GUTI) < 8 {
+		return 0
+	}
+	tmsi, err := strconv.ParseUint(p.GUTI[len(p.GUTI)-8:], 16, 32)
+	if err != nil {
+		return 0
+	}
+	return int64(tmsi % sfnCycle)
+}
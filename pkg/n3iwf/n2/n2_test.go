@@ -0,0 +1,44 @@
+package n2
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// authRequest are the IEs of the NAS message of the benchmarks, the
+// Authentication Request the AMF sends for every registration.
+var authRequest = AuthenticationRequestIEs{
+	NgKSI: 1,
+	ABBA:  []byte{0, 0},
+	RAND:  make([]byte, 16),
+	AUTN:  make([]byte, 16),
+}
+
+func BenchmarkAppendNAS(b *testing.B) {
+	b.Run("new", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			NAS(AuthenticationRequest, authRequest)
+		}
+	})
+	b.Run("reused", func(b *testing.B) {
+		b.ReportAllocs()
+		var nas []byte
+		for i := 0; i < b.N; i++ {
+			nas = AppendNAS(nas[:0], AuthenticationRequest, authRequest)
+		}
+	})
+}
+
+func BenchmarkParseNAS(b *testing.B) {
+	nas := NAS(AuthenticationResponse, AuthenticationResponseIEs{ResStar: make([]byte, 16)})
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		name, ies := ParseNAS(nas)
+		rsp := GetIEs(name)
+		if err := json.Unmarshal(ies, rsp); err != nil {
+			b.Fatal(err)
+		}
+		PutIEs(name, rsp)
+	}
+}
@@ -0,0 +1,353 @@
+// Package nasbuild builds the NAS messages of package n2 with sensible
+// defaults, so that the tests, the scenarios and the stand-in AMF write a
+// message in a line rather than fill its IEs:
+//
+//	nas := nasbuild.RegistrationRequest().WithMobileIdentity(supi).WithDRX(64).NAS()
+//	nas = nasbuild.SMS(peer, "hello").WithReference(mr).Uplink().NAS()
+//
+// The builders are values: a With method returns a copy of the builder,
+// which may thus be reused as a template.
+package nasbuild
+
+import (
+	"time"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/identity"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/n3iwf/n2"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/security/nas"
+)
+
+// DefaultMobileIdentity is the mobile identity of the Registration
+// Requests by default, the SUCI of the null scheme of the first subscriber
+// of the test PLMN 208/93.
+const DefaultMobileIdentity = "suci-0-208-93-0000-0-0-0000000001"
+
+// Builder builds a NAS message.
+type Builder interface {
+	NAS() []byte
+}
+
+// Message is a NAS message, its name and its IEs, nil for none.
+type Message struct {
+	Name string
+	IEs  interface{}
+}
+
+// NAS returns the NAS message.
+func (m Message) NAS() []byte {
+	return n2.NAS(m.Name, m.IEs)
+}
+
+// AppendNAS appends the NAS message to dst, and returns the extended
+// buffer.
+func (m Message) AppendNAS(dst []byte) []byte {
+	return n2.AppendNAS(dst, m.Name, m.IEs)
+}
+
+// RegistrationRequestBuilder builds a Registration Request.
+type RegistrationRequestBuilder struct {
+	ies n2.RegistrationRequestIEs
+}
+
+// RegistrationRequest returns the builder of a Registration Request of
+// DefaultMobileIdentity, asking for no DRX cycle and no slice.
+func RegistrationRequest() RegistrationRequestBuilder {
+	return RegistrationRequestBuilder{ies: n2.RegistrationRequestIEs{MobileIdentity: DefaultMobileIdentity}}
+}
+
+// WithMobileIdentity sets the SUPI or the SUCI of the UE.
+func (b RegistrationRequestBuilder) WithMobileIdentity(id string) RegistrationRequestBuilder {
+	b.ies.MobileIdentity = id
+	return b
+}
+
+// WithRegistrationType sets the registration type, such as
+// n2.RegistrationPeriodic. The mobile identity of a registration update is
+// the 5G-GUTI of the UE.
+func (b RegistrationRequestBuilder) WithRegistrationType(typ string) RegistrationRequestBuilder {
+	b.ies.RegistrationType = typ
+	return b
+}
+
+// WithDRX sets the DRX cycle the UE asks for, in radio frames.
+func (b RegistrationRequestBuilder) WithDRX(drx uint16) RegistrationRequestBuilder {
+	b.ies.RequestedDRX = drx
+	return b
+}
+
+// WithNSSAI sets the slices the UE asks for.
+func (b RegistrationRequestBuilder) WithNSSAI(nssai ...identity.SNSSAI) RegistrationRequestBuilder {
+	b.ies.RequestedNSSAI = nssai
+	return b
+}
+
+// NAS returns the message.
+func (b RegistrationRequestBuilder) NAS() []byte {
+	return n2.NAS(n2.RegistrationRequest, b.ies)
+}
+
+// AuthenticationRequestBuilder builds an Authentication Request.
+type AuthenticationRequestBuilder struct {
+	ies n2.AuthenticationRequestIEs
+}
+
+// AuthenticationRequest returns the builder of an Authentication Request
+// of ngKSI 0 and ABBA 0x0000, whose RAND and AUTN are zeroes.
+func AuthenticationRequest() AuthenticationRequestBuilder {
+	return AuthenticationRequestBuilder{ies: n2.AuthenticationRequestIEs{
+		ABBA: []byte{0x00, 0x00},
+		RAND: make([]byte, 16),
+		AUTN: make([]byte, 16),
+	}}
+}
+
+// WithNgKSI sets the ngKSI of the keys of the UE.
+func (b AuthenticationRequestBuilder) WithNgKSI(ngKSI uint8) AuthenticationRequestBuilder {
+	b.ies.NgKSI = ngKSI
+	return b
+}
+
+// WithABBA sets the ABBA the keys of the UE are derived with.
+func (b AuthenticationRequestBuilder) WithABBA(abba []byte) AuthenticationRequestBuilder {
+	b.ies.ABBA = abba
+	return b
+}
+
+// WithChallenge sets the challenge of 5G-AKA, RAND and AUTN.
+func (b AuthenticationRequestBuilder) WithChallenge(rand, autn []byte) AuthenticationRequestBuilder {
+	b.ies.RAND, b.ies.AUTN = rand, autn
+	return b
+}
+
+// NAS returns the message.
+func (b AuthenticationRequestBuilder) NAS() []byte {
+	return n2.NAS(n2.AuthenticationRequest, b.ies)
+}
+
+// AuthenticationResponse returns the Authentication Response of RES*.
+func AuthenticationResponse(resStar []byte) Message {
+	return Message{Name: n2.AuthenticationResponse, IEs: n2.AuthenticationResponseIEs{ResStar: resStar}}
+}
+
+// AuthenticationFailure returns the Authentication Failure of the 5GMM
+// cause, such as n2.CauseMACFailure.
+func AuthenticationFailure(cause uint8) Message {
+	return Message{Name: n2.AuthenticationFailure, IEs: n2.CauseIEs{Cause: cause}}
+}
+
+// AuthenticationReject returns an Authentication Reject.
+func AuthenticationReject() Message {
+	return Message{Name: n2.AuthenticationReject}
+}
+
+// SecurityModeCommandBuilder builds a Security Mode Command.
+type SecurityModeCommandBuilder struct {
+	ies n2.SecurityModeCommandIEs
+}
+
+// SecurityModeCommand returns the builder of a Security Mode Command
+// selecting NEA2 and NIA2, of ngKSI 0 and ABBA 0x0000.
+func SecurityModeCommand() SecurityModeCommandBuilder {
+	return SecurityModeCommandBuilder{ies: n2.SecurityModeCommandIEs{
+		CipheringAlgorithm: uint8(nas.NEA2),
+		IntegrityAlgorithm: uint8(nas.NIA2),
+		ABBA:               []byte{0x00, 0x00},
+	}}
+}
+
+// WithAlgorithms sets the NAS ciphering and integrity algorithms selected.
+func (b SecurityModeCommandBuilder) WithAlgorithms(enc nas.CipheringAlg, integrity nas.IntegrityAlg) SecurityModeCommandBuilder {
+	b.ies.CipheringAlgorithm, b.ies.IntegrityAlgorithm = uint8(enc), uint8(integrity)
+	return b
+}
+
+// WithNgKSI sets the ngKSI of K_AMF.
+func (b SecurityModeCommandBuilder) WithNgKSI(ngKSI uint8) SecurityModeCommandBuilder {
+	b.ies.NgKSI = ngKSI
+	return b
+}
+
+// WithABBA sets the ABBA K_AMF is derived with.
+func (b SecurityModeCommandBuilder) WithABBA(abba []byte) SecurityModeCommandBuilder {
+	b.ies.ABBA = abba
+	return b
+}
+
+// NAS returns the message.
+func (b SecurityModeCommandBuilder) NAS() []byte {
+	return n2.NAS(n2.SecurityModeCommand, b.ies)
+}
+
+// SecurityModeComplete returns a Security Mode Complete.
+func SecurityModeComplete() Message {
+	return Message{Name: n2.SecurityModeComplete}
+}
+
+// SecurityModeReject returns the Security Mode Reject of the 5GMM cause,
+// such as n2.CauseSecurityModeRejected.
+func SecurityModeReject(cause uint8) Message {
+	return Message{Name: n2.SecurityModeReject, IEs: n2.CauseIEs{Cause: cause}}
+}
+
+// RegistrationAcceptBuilder builds a Registration Accept.
+type RegistrationAcceptBuilder struct {
+	ies n2.RegistrationAcceptIEs
+}
+
+// RegistrationAccept returns the builder of the Registration Accept of the
+// 5G-GUTI guti, with the default DRX cycle.
+func RegistrationAccept(guti string) RegistrationAcceptBuilder {
+	return RegistrationAcceptBuilder{ies: n2.RegistrationAcceptIEs{GUTI: guti, NegotiatedDRX: n2.DefaultDRX}}
+}
+
+// WithDRX sets the DRX cycle negotiated with the UE, in radio frames.
+func (b RegistrationAcceptBuilder) WithDRX(drx uint16) RegistrationAcceptBuilder {
+	b.ies.NegotiatedDRX = drx
+	return b
+}
+
+// WithAllowedNSSAI sets the slices allowed the UE.
+func (b RegistrationAcceptBuilder) WithAllowedNSSAI(nssai ...identity.SNSSAI) RegistrationAcceptBuilder {
+	b.ies.AllowedNSSAI = nssai
+	return b
+}
+
+// WithRejectedNSSAI sets the slices the UE asked for and was refused.
+func (b RegistrationAcceptBuilder) WithRejectedNSSAI(nssai ...identity.SNSSAI) RegistrationAcceptBuilder {
+	b.ies.RejectedNSSAI = nssai
+	return b
+}
+
+// WithTAIList sets the TAIs of the registration area of the UE.
+func (b RegistrationAcceptBuilder) WithTAIList(tais ...identity.TAI) RegistrationAcceptBuilder {
+	b.ies.TAIList = tais
+	return b
+}
+
+// WithT3512 sets the period of the periodic registration updates of the
+// UE, in whole seconds.
+func (b RegistrationAcceptBuilder) WithT3512(d time.Duration) RegistrationAcceptBuilder {
+	b.ies.T3512 = uint32(d / time.Second)
+	return b
+}
+
+// NAS returns the message.
+func (b RegistrationAcceptBuilder) NAS() []byte {
+	return n2.NAS(n2.RegistrationAccept, b.ies)
+}
+
+// RegistrationComplete returns a Registration Complete.
+func RegistrationComplete() Message {
+	return Message{Name: n2.RegistrationComplete}
+}
+
+// RegistrationReject returns the Registration Reject of the 5GMM cause,
+// such as n2.CauseIllegalUE.
+func RegistrationReject(cause uint8) Message {
+	return Message{Name: n2.RegistrationReject, IEs: n2.CauseIEs{Cause: cause}}
+}
+
+// DeregistrationRequest returns the Deregistration Request of a UE.
+func DeregistrationRequest() Message {
+	return Message{Name: n2.DeregistrationRequest}
+}
+
+// DeregistrationAccept returns a Deregistration Accept.
+func DeregistrationAccept() Message {
+	return Message{Name: n2.DeregistrationAccept}
+}
+
+// ServiceRequest returns the Service Request of the UE of the 5G-GUTI guti.
+func ServiceRequest(guti string) Message {
+	return Message{Name: n2.ServiceRequest, IEs: n2.ServiceRequestIEs{GUTI: guti}}
+}
+
+// ServiceAccept returns a Service Accept.
+func ServiceAccept() Message {
+	return Message{Name: n2.ServiceAccept}
+}
+
+// RP is a message of the short message relay protocol, carried in a NAS
+// transport message.
+type RP struct {
+	Message
+}
+
+// Uplink returns the UL NAS Transport of the message.
+func (r RP) Uplink() Message {
+	return transport(n2.ULNASTransport, r.NAS())
+}
+
+// Downlink returns the DL NAS Transport of the message.
+func (r RP) Downlink() Message {
+	return transport(n2.DLNASTransport, r.NAS())
+}
+
+func transport(name string, rp []byte) Message {
+	return Message{Name: name, IEs: n2.NASTransportIEs{PayloadContainerType: n2.PayloadContainerSMS, PayloadContainer: rp}}
+}
+
+// RPDataBuilder builds an RP-DATA.
+type RPDataBuilder struct {
+	ies n2.RPDataIEs
+}
+
+// SMS returns the builder of the RP-DATA of message reference 1 of a short
+// message to destination, a SUPI, as a UE submits it.
+func SMS(destination, text string) RPDataBuilder {
+	return RPDataBuilder{ies: n2.RPDataIEs{Reference: 1, SMS: &n2.SMS{Destination: destination, UserData: text}}}
+}
+
+// StatusReport returns the builder of the RP-DATA of message reference 1
+// of the status report r.
+func StatusReport(r n2.StatusReport) RPDataBuilder {
+	return RPDataBuilder{ies: n2.RPDataIEs{Reference: 1, StatusReport: &r}}
+}
+
+// WithReference sets the RP message reference.
+func (b RPDataBuilder) WithReference(reference uint8) RPDataBuilder {
+	b.ies.Reference = reference
+	return b
+}
+
+// From sets the originator of the short message, as the SMSF delivers it,
+// and drops its destination.
+func (b RPDataBuilder) From(originator string) RPDataBuilder {
+	if b.ies.SMS != nil {
+		sms := *b.ies.SMS
+		sms.Originator, sms.Destination = originator, ""
+		b.ies.SMS = &sms
+	}
+	return b
+}
+
+// RP returns the message.
+func (b RPDataBuilder) RP() RP {
+	return RP{Message{Name: n2.RPData, IEs: b.ies}}
+}
+
+// NAS returns the message.
+func (b RPDataBuilder) NAS() []byte {
+	return b.RP().NAS()
+}
+
+// Uplink returns the UL NAS Transport of the message.
+func (b RPDataBuilder) Uplink() Message {
+	return b.RP().Uplink()
+}
+
+// Downlink returns the DL NAS Transport of the message.
+func (b RPDataBuilder) Downlink() Message {
+	return b.RP().Downlink()
+}
+
+// RPAck returns the RP-ACK of the RP-DATA of reference.
+func RPAck(reference uint8) RP {
+	return RP{Message{Name: n2.RPAck, IEs: n2.RPAckIEs{Reference: reference}}}
+}
+
+// RPError returns the RP-ERROR of the RP-DATA of reference, of the RP
+// cause, such as n2.RPCauseDestinationOutOfOrder.
+func RPError(reference, cause uint8) RP {
+	return RP{Message{Name: n2.RPError, IEs: n2.RPErrorIEs{Reference: reference, Cause: cause}}}
+}
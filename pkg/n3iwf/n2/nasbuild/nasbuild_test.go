@@ -0,0 +1,56 @@
+package nasbuild
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/identity"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/n3iwf/n2"
+)
+
+func TestBuilders(t *testing.T) {
+	const supi = "imsi-208930000000001"
+	template := RegistrationRequest().WithDRX(64)
+	tai, _ := identity.ParseTAI("208-93-000001")
+	for _, tc := range []struct {
+		name string
+		b    Builder
+		want []byte
+	}{
+		{"default registration request", RegistrationRequest(),
+			n2.NAS(n2.RegistrationRequest, n2.RegistrationRequestIEs{MobileIdentity: DefaultMobileIdentity})},
+		{"registration request", template.WithMobileIdentity(supi),
+			n2.NAS(n2.RegistrationRequest, n2.RegistrationRequestIEs{MobileIdentity: supi, RequestedDRX: 64})},
+		{"template untouched", template,
+			n2.NAS(n2.RegistrationRequest, n2.RegistrationRequestIEs{MobileIdentity: DefaultMobileIdentity, RequestedDRX: 64})},
+		{"sliced registration request", RegistrationRequest().WithNSSAI(identity.SNSSAI{SST: 1}, identity.SNSSAI{SST: 2, SD: "000001"}),
+			n2.NAS(n2.RegistrationRequest, n2.RegistrationRequestIEs{MobileIdentity: DefaultMobileIdentity, RequestedNSSAI: identity.NSSAI{{SST: 1}, {SST: 2, SD: "000001"}}})},
+		{"periodic registration update", RegistrationRequest().WithRegistrationType(n2.RegistrationPeriodic).WithMobileIdentity("5g-guti-1"),
+			n2.NAS(n2.RegistrationRequest, n2.RegistrationRequestIEs{RegistrationType: n2.RegistrationPeriodic, MobileIdentity: "5g-guti-1"})},
+		{"authentication request", AuthenticationRequest().WithNgKSI(1),
+			n2.NAS(n2.AuthenticationRequest, n2.AuthenticationRequestIEs{NgKSI: 1, ABBA: []byte{0, 0}, RAND: make([]byte, 16), AUTN: make([]byte, 16)})},
+		{"security mode command", SecurityModeCommand().WithNgKSI(1),
+			n2.NAS(n2.SecurityModeCommand, n2.SecurityModeCommandIEs{CipheringAlgorithm: 2, IntegrityAlgorithm: 2, NgKSI: 1, ABBA: []byte{0, 0}})},
+		{"registration accept", RegistrationAccept("5g-guti-1"),
+			n2.NAS(n2.RegistrationAccept, n2.RegistrationAcceptIEs{GUTI: "5g-guti-1", NegotiatedDRX: n2.DefaultDRX})},
+		{"registration accept of an area", RegistrationAccept("5g-guti-1").WithTAIList(tai).WithT3512(54 * time.Minute),
+			n2.NAS(n2.RegistrationAccept, n2.RegistrationAcceptIEs{GUTI: "5g-guti-1", NegotiatedDRX: n2.DefaultDRX, TAIList: []identity.TAI{tai}, T3512: 3240})},
+		{"registration complete", RegistrationComplete(), []byte(n2.RegistrationComplete)},
+		{"service request", ServiceRequest("5g-guti-1"),
+			n2.NAS(n2.ServiceRequest, n2.ServiceRequestIEs{GUTI: "5g-guti-1"})},
+		{"uplink sms", SMS(supi, "hi").WithReference(7).Uplink(),
+			n2.NAS(n2.ULNASTransport, n2.NASTransportIEs{
+				PayloadContainerType: n2.PayloadContainerSMS,
+				PayloadContainer:     n2.NAS(n2.RPData, n2.RPDataIEs{Reference: 7, SMS: &n2.SMS{Destination: supi, UserData: "hi"}}),
+			})},
+		{"delivered sms", SMS(supi, "hi").From("imsi-208930000000002"),
+			n2.NAS(n2.RPData, n2.RPDataIEs{Reference: 1, SMS: &n2.SMS{Originator: "imsi-208930000000002", UserData: "hi"}})},
+		{"rp-error", RPError(3, n2.RPCauseCongestion),
+			n2.NAS(n2.RPError, n2.RPErrorIEs{Reference: 3, Cause: n2.RPCauseCongestion})},
+	} {
+		if got := tc.b.NAS(); !bytes.Equal(got, tc.want) {
+			t.Errorf("%s: got %s, want %s", tc.name, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package n2
+
+// The overload actions of an Overload Start, telling the UEs setting up
+// their signalling connection that the RAN rejects (TS 38.413 9.3.1.105),
+// by their establishment cause as TS 23.501 5.19.5.2 maps them.
+const (
+	// OverloadRejectMOData rejects the mobile originated data.
+	OverloadRejectMOData = "reject-non-emergency-mo-dt"
+	// OverloadRejectSignalling rejects the mobile originated signalling
+	// and data.
+	OverloadRejectSignalling = "reject-rrc-cr-signalling"
+	// OverloadPermitEmergencyMT rejects all but the emergencies and the
+	// mobile terminated services.
+	OverloadPermitEmergencyMT = "permit-emergency-sessions-and-mobile-terminated-services-only"
+	// OverloadPermitHighPriorityMT rejects all but the high priority
+	// accesses and the mobile terminated services.
+	OverloadPermitHighPriorityMT = "permit-high-priority-sessions-and-mobile-terminated-services-only"
+)
+
+// ValidOverloadAction tells the overload actions.
+func ValidOverloadAction(action string) bool {
+	switch action {
+	case OverloadRejectMOData, OverloadRejectSignalling, OverloadPermitEmergencyMT, OverloadPermitHighPriorityMT:
+		return true
+	}
+	return false
+}
+
+// The establishment causes of the AN parameters (TS 24.502 9.3.2.2.2).
+const (
+	EstablishmentEmergency    = "emergency"
+	EstablishmentHighPriority = "highPriorityAccess"
+	EstablishmentMOSignalling = "mo-Signalling"
+	EstablishmentMOData       = "mo-Data"
+	EstablishmentMPSPriority  = "mps-PriorityAccess"
+	EstablishmentMCSPriority  = "mcs-PriorityAccess"
+)
+
+// Overload is the Overload Start of an AMF: the overload action, and the
+// traffic load reduction indication, the percentage of the UEs of the
+// action that the RAN rejects, all of them when zero (TS 38.413 9.2.6.1).
+type Overload struct {
+	Action               string
+	TrafficLoadReduction int
+}
+
+// Rejects tells whether the action of o rejects a UE setting up its
+// signalling connection with the establishment cause cause, or answering a
+// paging when mt. The traffic load reduction is left to the RAN.
+func (o Overload) Rejects(cause string, mt bool) bool {
+	if mt {
+		return false
+	}
+	switch o.Action {
+	case OverloadRejectMOData:
+		return cause == EstablishmentMOData
+	case OverloadRejectSignalling:
+		return cause == EstablishmentMOData || cause == EstablishmentMOSignalling
+	case OverloadPermitEmergencyMT:
+		return cause != EstablishmentEmergency
+	case OverloadPermitHighPriorityMT:
+		return cause != EstablishmentHighPriority && cause != EstablishmentMPSPriority && cause != EstablishmentMCSPriority
+	}
+	return false
+}
@@ -0,0 +1,33 @@
+package n2
+
+import "testing"
+
+func TestOverloadRejects(t *testing.T) {
+	causes := []string{EstablishmentEmergency, EstablishmentHighPriority, EstablishmentMPSPriority, EstablishmentMOSignalling, EstablishmentMOData}
+	for _, test := range []struct {
+		action   string
+		rejected []string
+	}{
+		{OverloadRejectMOData, []string{EstablishmentMOData}},
+		{OverloadRejectSignalling, []string{EstablishmentMOSignalling, EstablishmentMOData}},
+		{OverloadPermitEmergencyMT, []string{EstablishmentHighPriority, EstablishmentMPSPriority, EstablishmentMOSignalling, EstablishmentMOData}},
+		{OverloadPermitHighPriorityMT, []string{EstablishmentEmergency, EstablishmentMOSignalling, EstablishmentMOData}},
+	} {
+		o := Overload{Action: test.action}
+		for _, cause := range causes {
+			want := false
+			for _, r := range test.rejected {
+				want = want || r == cause
+			}
+			if got := o.Rejects(cause, false); got != want {
+				t.Errorf("%s: %s rejected %v, want %v", test.action, cause, got, want)
+			}
+			if o.Rejects(cause, true) {
+				t.Errorf("%s: %s answering a paging rejected", test.action, cause)
+			}
+		}
+	}
+	if (Overload{}).Rejects(EstablishmentMOData, false) {
+		t.Error("no action rejected a UE")
+	}
+}
@@ -0,0 +1,89 @@
+package n2
+
+import "sync"
+
+// IEs are the IEs of a NAS message, reused across the messages: a
+// decoder gets them from the pool of their message with GetIEs, and puts
+// them back with PutIEs once read, Reset zeroing them in between.
+type IEs interface {
+	Reset()
+}
+
+// Reset zeroes the IEs.
+func (ies *RegistrationRequestIEs) Reset() { *ies = RegistrationRequestIEs{} }
+
+// Reset zeroes the IEs.
+func (ies *AuthenticationRequestIEs) Reset() { *ies = AuthenticationRequestIEs{} }
+
+// Reset zeroes the IEs.
+func (ies *SecurityModeCommandIEs) Reset() { *ies = SecurityModeCommandIEs{} }
+
+// Reset zeroes the IEs.
+func (ies *AuthenticationResponseIEs) Reset() { *ies = AuthenticationResponseIEs{} }
+
+// Reset zeroes the IEs.
+func (ies *CauseIEs) Reset() { *ies = CauseIEs{} }
+
+// Reset zeroes the IEs.
+func (ies *RegistrationAcceptIEs) Reset() { *ies = RegistrationAcceptIEs{} }
+
+// Reset zeroes the IEs.
+func (ies *ServiceRequestIEs) Reset() { *ies = ServiceRequestIEs{} }
+
+// Reset zeroes the IEs.
+func (ies *NASTransportIEs) Reset() { *ies = NASTransportIEs{} }
+
+// Reset zeroes the IEs.
+func (ies *RPDataIEs) Reset() { *ies = RPDataIEs{} }
+
+// Reset zeroes the IEs.
+func (ies *RPAckIEs) Reset() { *ies = RPAckIEs{} }
+
+// Reset zeroes the IEs.
+func (ies *RPErrorIEs) Reset() { *ies = RPErrorIEs{} }
+
+// pools are the pools of the IEs, by message name, for the messages with
+// IEs of their own.
+var pools = map[string]*sync.Pool{
+	RegistrationRequest:    newPool(func() IEs { return new(RegistrationRequestIEs) }),
+	RegistrationAccept:     newPool(func() IEs { return new(RegistrationAcceptIEs) }),
+	RegistrationReject:     newPool(func() IEs { return new(CauseIEs) }),
+	AuthenticationRequest:  newPool(func() IEs { return new(AuthenticationRequestIEs) }),
+	AuthenticationResponse: newPool(func() IEs { return new(AuthenticationResponseIEs) }),
+	AuthenticationFailure:  newPool(func() IEs { return new(CauseIEs) }),
+	SecurityModeCommand:    newPool(func() IEs { return new(SecurityModeCommandIEs) }),
+	SecurityModeReject:     newPool(func() IEs { return new(CauseIEs) }),
+	ServiceRequest:         newPool(func() IEs { return new(ServiceRequestIEs) }),
+	ULNASTransport:         newPool(func() IEs { return new(NASTransportIEs) }),
+	DLNASTransport:         newPool(func() IEs { return new(NASTransportIEs) }),
+	RPData:                 newPool(func() IEs { return new(RPDataIEs) }),
+	RPAck:                  newPool(func() IEs { return new(RPAckIEs) }),
+	RPError:                newPool(func() IEs { return new(RPErrorIEs) }),
+}
+
+func newPool(fn func() IEs) *sync.Pool {
+	return &sync.Pool{New: func() interface{} { return fn() }}
+}
+
+// GetIEs returns zero IEs of the message name, such as a
+// *RegistrationRequestIEs for a RegistrationRequest, to decode its IEs
+// into. It returns nil for a message without IEs of its own.
+func GetIEs(name string) IEs {
+	p, ok := pools[name]
+	if !ok {
+		return nil
+	}
+	return p.Get().(IEs)
+}
+
+// PutIEs resets the IEs ies of the message name, got with GetIEs, and puts
+// them back into its pool. The IEs are not to be used afterwards, the
+// values read from them staying valid.
+func PutIEs(name string, ies IEs) {
+	p, ok := pools[name]
+	if !ok || ies == nil {
+		return
+	}
+	ies.Reset()
+	p.Put(ies)
+}
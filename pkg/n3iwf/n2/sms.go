@@ -0,0 +1,60 @@
+package n2
+
+// The messages of the short message relay protocol (TS 24.011 7.3), carried
+// in the payload containers of the ULNASTransport and DLNASTransport
+// messages, SMS over NAS (TS 23.502 4.13.3). They are written as the NAS
+// messages are, their name followed by their IEs in JSON.
+const (
+	RPData  = "RP-DATA"
+	RPAck   = "RP-ACK"
+	RPError = "RP-ERROR"
+)
+
+// The RP causes of the RP-ERRORs and of the failed status reports
+// (TS 24.011 8.2.5.4).
+const (
+	RPCauseDestinationOutOfOrder uint8 = 27
+	RPCauseUnknownSubscriber     uint8 = 30
+	RPCauseTemporaryFailure      uint8 = 41
+	RPCauseCongestion            uint8 = 42
+	RPCauseProtocolError         uint8 = 111
+)
+
+// RPDataIEs are the IEs of an RP-DATA, which carries either a short
+// message or the status report of one. The reference is the RP message
+// reference, which the RP-ACK or the RP-ERROR answering it repeats.
+type RPDataIEs struct {
+	Reference    uint8         `json:"reference"`
+	SMS          *SMS          `json:"sms,omitempty"`
+	StatusReport *StatusReport `json:"status_report,omitempty"`
+}
+
+// SMS is a short message. The addresses are the SUPIs of the UEs, the
+// subscribers having no MSISDN: the destination of a short message the UE
+// submits, the originator of one delivered to the UE.
+type SMS struct {
+	Originator  string `json:"originator,omitempty"`
+	Destination string `json:"destination,omitempty"`
+	UserData    string `json:"user_data"`
+}
+
+// StatusReport reports to its originator the delivery of the short message
+// it submitted in the RP-DATA of message reference, or the cause of its
+// failure.
+type StatusReport struct {
+	MessageReference uint8  `json:"message_reference"`
+	Recipient        string `json:"recipient"`
+	Delivered        bool   `json:"delivered"`
+	Cause            uint8  `json:"cause,omitempty"`
+}
+
+// RPAckIEs are the IEs of an RP-ACK.
+type RPAckIEs struct {
+	Reference uint8 `json:"reference"`
+}
+
+// RPErrorIEs are the IEs of an RP-ERROR.
+type RPErrorIEs struct {
+	Reference uint8 `json:"reference"`
+	Cause     uint8 `json:"cause"`
+}
@@ -0,0 +1,229 @@
+package nwu
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/n3iwf/n2"
+)
+
+var (
+	// ErrEAPFailure is returned when the N3IWF ends EAP-5G with a failure,
+	// the AMF having rejected the UE.
+	ErrEAPFailure = errors.New("nwu: EAP failure")
+	// ErrAuth is returned when the AUTH of the N3IWF does not match
+	// K_N3IWF.
+	ErrAuth = errors.New("nwu: AUTH of the N3IWF mismatch")
+)
+
+// closeTimeout bounds the deletion of the IKE SA when closing a Client.
+const closeTimeout = time.Second
+
+// Client is the UE end of the NWu. Its methods run one exchange each, in
+// the order of the registration, and must not be called concurrently. The
+// requests of the N3IWF are answered as they are read, and queued for
+// Downlink, but for the releases of the signalling connection, which
+// Released reports.
+type Client struct {
+	conn       *Conn
+	spiI, spiR uint64
+	// id is the message ID of the next request.
+	id uint32
+	// eapID is the identifier of the last EAP request.
+	eapID uint8
+	// inbox are the requests of the N3IWF read and not returned by
+	// Downlink yet.
+	inbox []Message
+	// released is true once the N3IWF released the signalling connection
+	// of the UE, until Released reports it.
+	released bool
+}
+
+// Dial connects to the N3IWF at address over TLS with config, sets up the
+// IKE SA and starts the IKE_AUTH, which the N3IWF answers with the EAP-5G
+// 5G-Start.
+func Dial(ctx context.Context, address string, config *tls.Config) (*Client, error) {
+	var d net.Dialer
+	raw, err := d.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	if config.ServerName == "" && !config.InsecureSkipVerify {
+		config = config.Clone()
+		config.ServerName, _, _ = net.SplitHostPort(address)
+	}
+	tc := tls.Client(raw, config)
+	c := &Client{conn: NewConn(tc)}
+	var spi [8]byte
+	rand.Read(spi[:])
+	c.spiI = binary.BigEndian.Uint64(spi[:])
+	if err := c.dial(ctx); err != nil {
+		raw.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *Client) dial(ctx context.Context) error {
+	m, err := c.exchange(ctx, Message{Exchange: ExchangeIKESAInit})
+	if err != nil {
+		return err
+	}
+	c.spiR = m.SPIr
+	// no identity and no AUTH: the UE asks for EAP-5G
+	m, err = c.exchange(ctx, Message{Exchange: ExchangeIKEAuth})
+	if err != nil {
+		return err
+	}
+	if m.EAP == nil || m.EAP.Code != EAPRequest || m.EAP.MsgID != EAP5GStart {
+		return fmt.Errorf("nwu: expected EAP-Request/%s", EAP5GStart)
+	}
+	c.eapID = m.EAP.Identifier
+	return nil
+}
+
+// EAP sends the NAS message nas to the AMF in an EAP-Response/5G-NAS, with
+// an for the first one, and returns the NAS message of the EAP-Request/5G-NAS
+// the N3IWF answers with. success is true when the N3IWF answers with an
+// EAP-Success instead, the AMF having sent K_N3IWF; an EAP-Failure returns
+// ErrEAPFailure along with the NAS message rejecting the UE.
+func (c *Client) EAP(ctx context.Context, nas []byte, an *n2.ANParameters) (dl []byte, success bool, err error) {
+	m, err := c.exchange(ctx, Message{
+		Exchange: ExchangeIKEAuth,
+		EAP:      &EAP{Code: EAPResponse, Identifier: c.eapID, MsgID: EAP5GNAS, AN: an, NAS: nas},
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	if m.EAP == nil {
+		return nil, false, errors.New("nwu: expected EAP")
+	}
+	c.eapID = m.EAP.Identifier
+	switch m.EAP.Code {
+	case EAPSuccess:
+		return nil, true, nil
+	case EAPFailure:
+		return m.EAP.NAS, false, ErrEAPFailure
+	}
+	return m.EAP.NAS, false, nil
+}
+
+// Authenticate ends the IKE_AUTH with the AUTH of the UE, computed from
+// key, K_N3IWF, and checks that of the N3IWF. It returns the inner address
+// of the UE and the first NAS message of the signalling IPsec SA, the
+// Registration Accept.
+func (c *Client) Authenticate(ctx context.Context, key []byte) (cfg Config, nas []byte, err error) {
+	m, err := c.exchange(ctx, Message{Exchange: ExchangeIKEAuth, Auth: Auth(key, c.spiI, c.spiR, true)})
+	if err != nil {
+		return cfg, nil, err
+	}
+	if !hmac.Equal(m.Auth, Auth(key, c.spiI, c.spiR, false)) {
+		return cfg, nil, ErrAuth
+	}
+	if m.Config == nil {
+		return cfg, nil, errors.New("nwu: expected the configuration of the UE")
+	}
+	return *m.Config, m.NAS, nil
+}
+
+// NAS sends a NAS message over the signalling IPsec SA and returns the one
+// the AMF answers with, if any.
+func (c *Client) NAS(ctx context.Context, nas []byte) (dl []byte, err error) {
+	m, err := c.exchange(ctx, Message{Exchange: ExchangeNAS, NAS: nas})
+	if err != nil {
+		return nil, err
+	}
+	return m.NAS, nil
+}
+
+// Idle asks the N3IWF to release the signalling connection of the UE, as
+// the N3IWF would on inactivity: the UE goes CM-IDLE, and sends a Service
+// Request over NAS, paged or not, to go back to CM-CONNECTED.
+func (c *Client) Idle(ctx context.Context) error {
+	_, err := c.exchange(ctx, Message{Exchange: ExchangeInformational, Idle: true})
+	return err
+}
+
+// Released reports whether the N3IWF released the signalling connection of
+// the UE since the last call, for inactivity: the UE is CM-IDLE and sends a
+// Service Request before any other NAS message.
+func (c *Client) Released() bool {
+	released := c.released
+	c.released = false
+	return released
+}
+
+// Downlink returns the next request of the N3IWF, a downlink NAS message or
+// a paging, waiting for it until the deadline of ctx.
+func (c *Client) Downlink(ctx context.Context) (Message, error) {
+	deadline, _ := ctx.Deadline()
+	c.conn.SetDeadline(deadline)
+	for len(c.inbox) == 0 {
+		r, err := c.read()
+		if err != nil {
+			return Message{}, err
+		}
+		if r.Response {
+			return Message{}, fmt.Errorf("nwu: unexpected response to %s %d", r.Exchange, r.MessageID)
+		}
+	}
+	m := c.inbox[0]
+	c.inbox = c.inbox[1:]
+	return m, nil
+}
+
+// Close deletes the IKE SA and closes the connection.
+func (c *Client) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), closeTimeout)
+	defer cancel()
+	c.exchange(ctx, Message{Exchange: ExchangeInformational, Delete: true})
+	return c.conn.Close()
+}
+
+// exchange sends the request m and returns its response, the deadline of
+// ctx bounding both.
+func (c *Client) exchange(ctx context.Context, m Message) (Message, error) {
+	deadline, _ := ctx.Deadline()
+	c.conn.SetDeadline(deadline)
+	m.MessageID, m.SPIi, m.SPIr = c.id, c.spiI, c.spiR
+	c.id++
+	if err := c.conn.Send(m); err != nil {
+		return Message{}, err
+	}
+	var r Message
+	for !r.Response {
+		var err error
+		if r, err = c.read(); err != nil {
+			return r, err
+		}
+	}
+	if r.MessageID != m.MessageID || r.Exchange != m.Exchange {
+		return r, fmt.Errorf("nwu: expected the response to %s %d", m.Exchange, m.MessageID)
+	}
+	if r.Notify != "" {
+		return r, &NotifyError{Type: r.Notify}
+	}
+	return r, nil
+}
+
+// read reads the next message, answering and queuing it when it is a
+// request of the N3IWF.
+func (c *Client) read() (Message, error) {
+	r, err := c.conn.Receive()
+	if err != nil || r.Response {
+		return r, err
+	}
+	if r.Exchange == ExchangeInformational && r.Idle {
+		c.released = true
+	} else {
+		c.inbox = append(c.inbox, r)
+	}
+	return r, c.conn.Send(Message{Exchange: r.Exchange, MessageID: r.MessageID, Response: true, SPIi: c.spiI, SPIr: c.spiR})
+}
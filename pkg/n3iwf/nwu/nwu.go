@@ -0,0 +1,173 @@
+// Package nwu is the NWu interface between a UE on an untrusted non-3GPP
+// access, such as a WiFi hotspot, and the N3IWF (TS 24.502). It stands in
+// for IKEv2 and IPsec (RFC 7296, RFC 4303): the UE connects over TLS, which
+// protects the connection in place of the IKE SA and of the IPsec SAs, and
+// exchanges messages mimicking those of IKEv2, in JSON, one per line:
+//
+//	IKE_SA_INIT    sets up the IKE SA, its SPIs
+//	IKE_AUTH       carries EAP-5G (TS 24.502 9.3.2), and the NAS messages
+//	               in it, until the AMF authenticated the UE; then the AUTH
+//	               of both ends, computed from K_N3IWF, and the inner address
+//	               of the UE
+//	NAS            carries a NAS message of the registered UE, either way,
+//	               as the signalling IPsec SA would (TS 24.502 9.4)
+//	INFORMATIONAL  deletes the IKE SA; makes the UE CM-IDLE; pages it
+//
+// The N3IWF initiates the NAS exchanges of the downlink NAS messages, the
+// INFORMATIONAL ones paging the UEs and those releasing the signalling
+// connection of a UE without activity, the UE every other exchange. Each end
+// answers a request of the other with a response of the same message ID,
+// the requests of either end being numbered on their own.
+//
+// A CM-IDLE UE keeps its connection, as a UE in RRC idle keeps listening to
+// the paging of its cell in its paging frames, and sends its Service Request
+// over it. With IKEv2, the N3IWF would delete the signalling IPsec SA of the
+// UE instead, and page it over 3GPP access if at all.
+package nwu
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"net"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/bufpool"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/n3iwf/n2"
+)
+
+// DefaultPort is the port of the NWu, that of IKEv2 with NAT traversal.
+const DefaultPort = "4500"
+
+// The exchanges of the NWu.
+const (
+	ExchangeIKESAInit     = "IKE_SA_INIT"
+	ExchangeIKEAuth       = "IKE_AUTH"
+	ExchangeNAS           = "NAS"
+	ExchangeInformational = "INFORMATIONAL"
+)
+
+// The codes of the EAP packets (RFC 3748 4).
+const (
+	EAPRequest  = "Request"
+	EAPResponse = "Response"
+	EAPSuccess  = "Success"
+	EAPFailure  = "Failure"
+)
+
+// The messages of EAP-5G (TS 24.502 9.3.2.2.1).
+const (
+	EAP5GStart = "5G-Start"
+	EAP5GNAS   = "5G-NAS"
+	EAP5GStop  = "5G-Stop"
+)
+
+// The error notifications of the responses (RFC 7296 3.10.1).
+const (
+	NotifyInvalidSyntax          = "INVALID_SYNTAX"
+	NotifyAuthenticationFailed   = "AUTHENTICATION_FAILED"
+	NotifyInternalAddressFailure = "INTERNAL_ADDRESS_FAILURE"
+	NotifyTemporaryFailure       = "TEMPORARY_FAILURE"
+)
+
+// Message is a request or a response of an exchange.
+type Message struct {
+	Exchange  string `json:"exchange"`
+	MessageID uint32 `json:"message_id"`
+	Response  bool   `json:"response,omitempty"`
+	// SPIi and SPIr are the SPIs of the initiator and of the responder.
+	SPIi uint64 `json:"spi_i"`
+	SPIr uint64 `json:"spi_r,omitempty"`
+	// Notify is the error a response reports, if any.
+	Notify string `json:"notify,omitempty"`
+
+	EAP    *EAP    `json:"eap,omitempty"`
+	Auth   []byte  `json:"auth,omitempty"`
+	Config *Config `json:"config,omitempty"`
+	NAS    []byte  `json:"nas,omitempty"`
+	// Delete deletes the IKE SA in an INFORMATIONAL exchange, Idle
+	// releases the signalling connection of the UE, at the request of
+	// either end, and Paging is the 5G-GUTI of the UE the N3IWF pages.
+	Delete bool   `json:"delete,omitempty"`
+	Idle   bool   `json:"idle,omitempty"`
+	Paging string `json:"paging,omitempty"`
+}
+
+// EAP is an EAP-5G packet. The AN parameters go with the first NAS message
+// of the UE.
+type EAP struct {
+	Code       string           `json:"code"`
+	Identifier uint8            `json:"identifier"`
+	MsgID      string           `json:"msg_id,omitempty"`
+	AN         *n2.ANParameters `json:"an_parameters,omitempty"`
+	NAS        []byte           `json:"nas,omitempty"`
+}
+
+// Config is the configuration the N3IWF gives the UE once authenticated:
+// the inner address of the UE, which its IPsec SAs would carry.
+type Config struct {
+	InnerIP string `json:"inner_ip"`
+}
+
+// NotifyError is the error notified by a response.
+type NotifyError struct {
+	Type string
+}
+
+func (e *NotifyError) Error() string {
+	return "nwu: " + e.Type
+}
+
+// Conn is a connection carrying the messages of the NWu.
+type Conn struct {
+	net.Conn
+	dec *json.Decoder
+}
+
+// NewConn returns the NWu over c.
+func NewConn(c net.Conn) *Conn {
+	return &Conn{Conn: c, dec: json.NewDecoder(bufio.NewReader(c))}
+}
+
+// Send sends m, encoded in a buffer of package bufpool.
+func (c *Conn) Send(m Message) error {
+	b := bufpool.Get()
+	defer bufpool.Put(b)
+	if err := b.JSON(m); err != nil {
+		return err
+	}
+	b.WriteByte('\n')
+	_, err := c.Write(b.Bytes())
+	return err
+}
+
+// Receive receives the next message.
+func (c *Conn) Receive() (m Message, err error) {
+	err = c.dec.Decode(&m)
+	return m, err
+}
+
+// keyPad is the key pad of the AUTH payloads (RFC 7296 2.15).
+const keyPad = "Key Pad for IKEv2"
+
+// Auth returns the AUTH payload of the initiator, or of the responder, of
+// the IKE SA of spiI and spiR, computed from key, K_N3IWF, as from the MSK
+// of an EAP method (RFC 7296 2.16).
+func Auth(key []byte, spiI, spiR uint64, initiator bool) []byte {
+	role := "responder"
+	if initiator {
+		role = "initiator"
+	}
+	octets := make([]byte, len(role)+16)
+	copy(octets, role)
+	binary.BigEndian.PutUint64(octets[len(role):], spiI)
+	binary.BigEndian.PutUint64(octets[len(role)+8:], spiR)
+	return prf(prf(key, []byte(keyPad)), octets)
+}
+
+func prf(key, data []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
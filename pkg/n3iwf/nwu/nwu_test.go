@@ -0,0 +1,40 @@
+package nwu
+
+import (
+	"net"
+	"testing"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/n3iwf/n2"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/n3iwf/n2/nasbuild"
+)
+
+// discard is a connection whose writes succeed and go nowhere.
+type discard struct {
+	net.Conn
+}
+
+func (discard) Write(p []byte) (int, error) { return len(p), nil }
+
+func BenchmarkSend(b *testing.B) {
+	// the IKE_AUTH carrying the Registration Request of a UE
+	m := Message{
+		Exchange:  ExchangeIKEAuth,
+		MessageID: 1,
+		SPIi:      0x1122334455667788,
+		SPIr:      0x8877665544332211,
+		EAP: &EAP{
+			Code:       EAPResponse,
+			Identifier: 1,
+			MsgID:      EAP5GNAS,
+			AN:         &n2.ANParameters{SelectedPLMN: "20893"},
+			NAS:        nasbuild.RegistrationRequest().NAS(),
+		},
+	}
+	c := NewConn(discard{})
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := c.Send(m); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
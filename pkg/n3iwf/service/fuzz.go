@@ -0,0 +1,121 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"time"
+
+	"github.com/go-kit/kit/log"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/idpool"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/kitx/fuzz"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/n3iwf/n2"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/n3iwf/nwu"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/security/nas"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/store"
+)
+
+// fuzzTimeout bounds the exchanges of the harness.
+const fuzzTimeout = 100 * time.Millisecond
+
+// fuzzKey is the K_N3IWF fuzzAMF hands the N3IWF for every UE.
+var fuzzKey = bytes.Repeat([]byte{0x5a}, 32)
+
+// fuzzSPIs make every SPI of the N3IWF 0x0101010101010101.
+type fuzzSPIs struct{}
+
+func (fuzzSPIs) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0x01
+	}
+	return len(p), nil
+}
+
+// fuzzAMF accepts every UE at its first NAS message, with fuzzKey, and
+// answers every NAS message with itself, releasing the UE on its
+// Deregistration Request.
+type fuzzAMF struct{}
+
+func (fuzzAMF) NGSetup(n2.RAN) {}
+
+func (fuzzAMF) InitialUEMessage(ctx context.Context, ranUEID uint64, msg []byte, an n2.ANParameters, loc n2.UserLocation) (n2.Downlink, error) {
+	dl := fuzzDownlink(ranUEID, msg)
+	dl.SecurityKey = fuzzKey
+	return dl, nil
+}
+
+func (fuzzAMF) UplinkNASTransport(ctx context.Context, ranUEID uint64, msg []byte, loc n2.UserLocation) (n2.Downlink, error) {
+	return fuzzDownlink(ranUEID, msg), nil
+}
+
+func (fuzzAMF) ANRelease(context.Context, uint64) error { return nil }
+
+func (fuzzAMF) UEContextRelease(context.Context, uint64) error { return nil }
+
+func fuzzDownlink(ranUEID uint64, msg []byte) n2.Downlink {
+	plain, _ := nas.Plain(msg)
+	name, _ := n2.ParseNAS(plain)
+	return n2.Downlink{AMFUEID: ranUEID, NAS: msg, Release: name == n2.DeregistrationRequest}
+}
+
+// fuzzNWu plays data, the NWu requests of a UE, one JSON message per line,
+// against an N3IWF of its own, fuzzAMF behind it, each after the response
+// to the one before. The SPI of the N3IWF is 72340172838076673, and K_N3IWF fuzzKey,
+// as in
+//
+//	{"exchange":"IKE_SA_INIT","message_id":0,"spi_i":1}
+//	{"exchange":"IKE_AUTH","message_id":1,"spi_i":1,"spi_r":72340172838076673}
+//	{"exchange":"IKE_AUTH","message_id":2,"spi_i":1,"spi_r":72340172838076673,"eap":{"code":"Response","identifier":1,"msg_id":"5G-NAS","nas":"…"}}
+//
+// The NAS messages are base64, as the JSON encoding of bytes.
+func fuzzNWu(data []byte) int {
+	pool, err := idpool.NewIPPool(context.Background(), store.NewMemory(), "fuzz", "10.60.0.0/24")
+	if err != nil {
+		return fuzz.Discard
+	}
+	s := NewSessions(fuzzAMF{}, pool, fuzzTimeout, log.NewNopLogger())
+	s.spis = fuzzSPIs{}
+	ue, n3iwf := net.Pipe()
+	done := make(chan struct{})
+	go func() {
+		s.serve(n3iwf)
+		close(done)
+	}()
+	lines := bytes.Split(data, []byte("\n"))
+	responses := make(chan struct{}, len(lines))
+	go func() {
+		c := nwu.NewConn(ue)
+		for {
+			m, err := c.Receive()
+			if err != nil {
+				return
+			}
+			if m.Response {
+				responses <- struct{}{}
+			}
+		}
+	}()
+
+	// every request waits for its response, or for the N3IWF to end the
+	// session
+	score := fuzz.Interesting
+play:
+	for _, line := range lines {
+		ue.SetWriteDeadline(time.Now().Add(fuzzTimeout))
+		if _, err := ue.Write(append(line, '\n')); err != nil {
+			score = fuzz.Normal
+			break
+		}
+		select {
+		case <-responses:
+		case <-done:
+			score = fuzz.Normal
+			break play
+		case <-time.After(fuzzTimeout):
+		}
+	}
+	ue.Close()
+	<-done
+	return score
+}
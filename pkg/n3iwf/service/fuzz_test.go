@@ -0,0 +1,19 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/kitx/fuzz"
+)
+
+func TestFuzzNWuCorpus(t *testing.T) {
+	seeds, err := fuzz.Corpus("FuzzNWu")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range seeds {
+		if score := fuzzNWu(data); score == fuzz.Discard {
+			t.Errorf("seed %s discarded", name)
+		}
+	}
+}
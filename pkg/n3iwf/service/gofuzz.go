@@ -0,0 +1,9 @@
+//go:build gofuzz
+// +build gofuzz
+
+package service
+
+// FuzzNWu is the go-fuzz function of fuzzNWu.
+func FuzzNWu(data []byte) int {
+	return fuzzNWu(data)
+}
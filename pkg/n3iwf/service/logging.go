@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/logging"
+)
+
+type loggingMiddleware struct {
+	logger log.Logger   `json:""`
+	next   N3iwfService `json:""`
+}
+
+// LoggingMiddleware takes a logger as a dependency
+// and returns a ServiceMiddleware.
+func LoggingMiddleware(logger log.Logger) Middleware {
+	return func(next N3iwfService) N3iwfService {
+		return loggingMiddleware{level.Info(logger), next}
+	}
+}
+
+func (lm loggingMiddleware) ListUEs(ctx context.Context) (ues []UE, err error) {
+	defer func(begin time.Time) {
+		log.With(lm.logger, logging.ContextKeyvals(ctx)...).Log("method", "ListUEs", "ues", len(ues), "err", err)
+	}(time.Now())
+
+	return lm.next.ListUEs(ctx)
+}
+
+func (lm loggingMiddleware) ReleaseUE(ctx context.Context, ranUENgapID uint64) (err error) {
+	defer func(begin time.Time) {
+		log.With(lm.logger, logging.ContextKeyvals(ctx)...).Log("method", "ReleaseUE", "ran_ue_ngap_id", ranUENgapID, "err", err)
+	}(time.Now())
+
+	return lm.next.ReleaseUE(ctx, ranUENgapID)
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-kit/kit/log/level"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/n3iwf/n2"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/security/nas"
+)
+
+// errOverload ends the session of a UE rejected in its registration for
+// the overload of the AMF.
+var errOverload = errors.New("rejected for the overload of the AMF")
+
+// OverloadStart has the N3IWF reject the UEs of o setting up their
+// signalling connection, the share of them of its traffic load reduction,
+// until OverloadStop. A UE rejected in its registration is answered
+// TEMPORARY_FAILURE to its first EAP-5G message, its IKE SA deleted. A
+// CM-IDLE UE is answered TEMPORARY_FAILURE to its Service Request or
+// registration update, and stays IDLE; one answering a paging is never
+// rejected.
+func (s *Sessions) OverloadStart(ctx context.Context, o n2.Overload) error {
+	s.mtx.Lock()
+	s.overload, s.share = &o, 0
+	s.mtx.Unlock()
+	level.Warn(s.logger).Log("overload", "start", "action", o.Action, "traffic_load_reduction", o.TrafficLoadReduction)
+	return nil
+}
+
+// OverloadStop stops rejecting UEs.
+func (s *Sessions) OverloadStop(ctx context.Context) error {
+	s.mtx.Lock()
+	s.overload = nil
+	s.mtx.Unlock()
+	level.Info(s.logger).Log("overload", "stop")
+	return nil
+}
+
+// OverloadStats returns whether the overload control of the AMF runs, and
+// the number of UEs rejected for it.
+func (s *Sessions) OverloadStats() map[string]int {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	st := map[string]int{"overloaded": 0, "rejected": s.rejected}
+	if s.overload != nil {
+		st["overloaded"] = 1
+	}
+	return st
+}
+
+// throttle tells whether the N3IWF rejects a UE setting up its signalling
+// connection with the establishment cause cause, or answering a paging when
+// mt. Of the UEs the overload action rejects, the traffic load reduction
+// out of every 100 are, spread evenly.
+func (s *Sessions) throttle(cause string, mt bool) bool {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.overload == nil || !s.overload.Rejects(cause, mt) {
+		return false
+	}
+	if r := s.overload.TrafficLoadReduction; r > 0 && r < 100 {
+		s.share += r
+		if s.share < 100 {
+			return false
+		}
+		s.share -= 100
+	}
+	s.rejected++
+	return true
+}
+
+// establishmentCause returns the establishment cause of a UE setting up its
+// signalling connection with the NAS message msg: that of an, or else
+// mo-Data for a Service Request and mo-Signalling for a registration.
+func establishmentCause(an *n2.ANParameters, msg []byte) string {
+	if an != nil && an.EstablishmentCause != "" {
+		return an.EstablishmentCause
+	}
+	plain, _ := nas.Plain(msg)
+	if name, _ := n2.ParseNAS(plain); name == n2.ServiceRequest {
+		return n2.EstablishmentMOData
+	}
+	return n2.EstablishmentMOSignalling
+}
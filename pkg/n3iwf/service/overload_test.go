@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-kit/kit/log"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/n3iwf/n2"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/n3iwf/n2/nasbuild"
+)
+
+func TestThrottle(t *testing.T) {
+	s := NewSessions(fuzzAMF{}, nil, fuzzTimeout, log.NewNopLogger())
+	if s.throttle(n2.EstablishmentMOSignalling, false) {
+		t.Fatal("UE rejected without overload")
+	}
+	s.OverloadStart(context.Background(), n2.Overload{Action: n2.OverloadRejectSignalling, TrafficLoadReduction: 30})
+	rejected := 0
+	for i := 0; i < 100; i++ {
+		if s.throttle(n2.EstablishmentMOSignalling, false) {
+			rejected++
+		}
+		if s.throttle(n2.EstablishmentMOSignalling, true) || s.throttle(n2.EstablishmentEmergency, false) {
+			t.Fatal("UE paged or in emergency rejected")
+		}
+	}
+	if rejected != 30 {
+		t.Errorf("%d UEs out of 100 rejected, want 30", rejected)
+	}
+	if st := s.OverloadStats(); st["overloaded"] != 1 || st["rejected"] != 30 {
+		t.Errorf("stats %v", st)
+	}
+
+	s.OverloadStart(context.Background(), n2.Overload{Action: n2.OverloadRejectMOData})
+	if !s.throttle(establishmentCause(&n2.ANParameters{}, nasbuild.ServiceRequest("5g-guti-20893cafe0000000001").NAS()), false) {
+		t.Error("Service Request of an IDLE UE not rejected")
+	}
+	if s.throttle(establishmentCause(&n2.ANParameters{}, nasbuild.RegistrationRequest().NAS()), false) {
+		t.Error("registration rejected for mobile originated data")
+	}
+	s.OverloadStop(context.Background())
+	if s.throttle(n2.EstablishmentMOData, false) {
+		t.Error("UE rejected after the Overload Stop")
+	}
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"net"
+
+	"github.com/go-kit/kit/log/level"
+)
+
+// Repository keeps the records of the UEs of an N3IWF durably, by RAN UE
+// NGAP ID.
+type Repository interface {
+	Put(ctx context.Context, ue UE) error
+	Delete(ctx context.Context, ranUEID uint64) error
+	// List returns the records, by RAN UE NGAP ID.
+	List(ctx context.Context) ([]UE, error)
+}
+
+// Recover releases the inner addresses of the UEs the N3IWF had before it
+// restarted, found in its repository, and deletes their records, the IKE
+// SAs of the UEs having gone with their connections. The RAN UE NGAP IDs
+// of the new UEs follow theirs. It returns the number of UEs recovered,
+// and should be called before Serve.
+func (s *Sessions) Recover(ctx context.Context) (int, error) {
+	ues, err := s.repo.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for _, ue := range ues {
+		if ue.InnerIP != "" {
+			if err := s.pool.Release(ctx, net.ParseIP(ue.InnerIP), owner(ue.RANUEID)); err != nil {
+				level.Warn(s.logger).Log("ran_ue_ngap_id", ue.RANUEID, "inner_ip", ue.InnerIP, "err", err)
+			}
+		}
+		if err := s.repo.Delete(ctx, ue.RANUEID); err != nil {
+			return 0, err
+		}
+		s.mtx.Lock()
+		if ue.RANUEID >= s.nextID {
+			s.nextID = ue.RANUEID + 1
+		}
+		s.mtx.Unlock()
+	}
+	return len(ues), nil
+}
+
+// persist writes the record ue of the UE, when the records are persisted.
+func (se *session) persist(ue UE) {
+	if se.s.repo == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), se.s.timeout)
+	defer cancel()
+	if err := se.s.repo.Put(ctx, ue); err != nil {
+		level.Warn(se.logger).Log("persist", "failed", "err", err)
+	}
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/n3iwf/n2"
+)
+
+// Middleware describes a service (as opposed to endpoint) middleware.
+type Middleware func(N3iwfService) N3iwfService
+
+// N3iwfService describes the N3IWF, the gateway of the UEs on untrusted
+// non-3GPP accesses: the O&M of the UEs its Sessions serve the NWu to.
+type N3iwfService interface {
+	// ListUEs lists the UEs connected through the NWu.
+	ListUEs(ctx context.Context) (ues []UE, err error)
+	// ReleaseUE releases the UE of ran_ue_ngap_id, deleting its IKE SA.
+	ReleaseUE(ctx context.Context, ranUENgapID uint64) (err error)
+}
+
+// the concrete implementation of service interface
+type stubN3iwfService struct {
+	sessions *Sessions
+	logger   log.Logger
+}
+
+// New return a new instance of the service.
+// If you want to add service middleware this is the place to put them.
+// The UEs are those of sessions.
+func New(sessions *Sessions, logger log.Logger) (s N3iwfService) {
+	var svc N3iwfService
+	{
+		svc = &stubN3iwfService{sessions: sessions, logger: logger}
+		svc = LoggingMiddleware(logger)(svc)
+	}
+	return svc
+}
+
+// Implement the business logic of ListUEs
+func (svc *stubN3iwfService) ListUEs(ctx context.Context) (ues []UE, err error) {
+	return svc.sessions.UEs(), nil
+}
+
+// Implement the business logic of ReleaseUE. The UE learns of it from its
+// connection being closed.
+func (svc *stubN3iwfService) ReleaseUE(ctx context.Context, ranUENgapID uint64) (err error) {
+	if err := svc.sessions.Release(ranUENgapID); err == n2.ErrUnknownUE {
+		return status.Errorf(codes.NotFound, "n3iwf: unknown UE %d", ranUENgapID)
+	}
+	return nil
+}
@@ -0,0 +1,638 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/identity"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/idpool"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/n3iwf/n2"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/n3iwf/nwu"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/security/nas"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/timers"
+)
+
+// The states of the IKE SA of a UE, IDLE being that of a CM-IDLE UE.
+const (
+	StateInit      = "IKE_SA_INIT"
+	StateEAP       = "EAP"
+	StateAuth      = "AUTH"
+	StateConnected = "CONNECTED"
+	StateIdle      = "IDLE"
+)
+
+// errRejected ends the session of a UE the AMF rejected.
+var errRejected = errors.New("rejected by the AMF")
+
+// UE is a UE connected through the NWu.
+type UE struct {
+	RANUEID uint64 `json:"ran_ue_ngap_id"`
+	// AMFUEID is zero until the AMF has the context of the UE.
+	AMFUEID uint64 `json:"amf_ue_ngap_id,omitempty"`
+	State   string `json:"state"`
+	// InnerIP is the inner address of the UE once authenticated.
+	InnerIP       string `json:"inner_ip,omitempty"`
+	RemoteAddress string `json:"remote_address"`
+}
+
+// Sessions are the IKE SAs of the UEs connected through the NWu. Sessions
+// serves the N3IWF end of the NWu, relaying the NAS messages of the UEs to
+// the AMF and giving the UEs it accepts an inner address of pool. timeout
+// bounds every exchange until the UE is connected, and every call to the
+// AMF. Sessions implement n2.RAN, the downlink NAS messages and the pagings
+// of the AMF going to the UEs in requests of the N3IWF, the pagings in the
+// paging frames of the UEs. The NAS messages go to the AMF with the TAI of
+// the N3IWF and the outer address of their UE.
+type Sessions struct {
+	amf     n2.AMF
+	pool    *idpool.IPPool
+	timeout time.Duration
+	logger  log.Logger
+	// inactivity is how long a CONNECTED UE may go without a NAS message
+	// before its signalling connection is released, timers the wheel
+	// timing it.
+	inactivity time.Duration
+	timers     *timers.Wheel
+	tai        identity.TAI
+	// repo keeps the records of the UEs, nil without persistence.
+	repo Repository
+	// spis are where the SPIs of the N3IWF are drawn from, crypto/rand
+	// but in the fuzzing harness.
+	spis io.Reader
+
+	mtx      sync.Mutex
+	sessions map[uint64]*session
+	nextID   uint64
+	// overload is the Overload Start of the AMF, nil without overload,
+	// share the count of the traffic load reduction, and rejected the UEs
+	// rejected for it.
+	overload *n2.Overload
+	share    int
+	rejected int
+}
+
+// SessionsOption sets an optional parameter of the sessions.
+type SessionsOption func(*Sessions)
+
+// Inactivity releases the signalling connection of a CONNECTED UE that
+// exchanges no NAS message for d, with the AMF and then with the UE, which
+// goes CM-IDLE as if it had asked to. Without it, or with d zero, the UEs
+// stay CONNECTED until they ask.
+func Inactivity(d time.Duration) SessionsOption {
+	return func(s *Sessions) { s.inactivity = d }
+}
+
+// TAI sets the TAI of the N3IWF, the tracking area of its UEs.
+func TAI(tai identity.TAI) SessionsOption {
+	return func(s *Sessions) { s.tai = tai }
+}
+
+// Persist writes the records of the UEs to r as they change, for the N3IWF
+// to release what they hold when it restarts (see Recover).
+func Persist(r Repository) SessionsOption {
+	return func(s *Sessions) { s.repo = r }
+}
+
+// NewSessions returns the sessions of the N3IWF.
+func NewSessions(amf n2.AMF, pool *idpool.IPPool, timeout time.Duration, logger log.Logger, options ...SessionsOption) *Sessions {
+	s := &Sessions{amf: amf, pool: pool, timeout: timeout, logger: logger, spis: rand.Reader, sessions: make(map[uint64]*session), nextID: 1}
+	for _, option := range options {
+		option(s)
+	}
+	if s.inactivity > 0 {
+		s.timers = timers.NewWheel(timers.Logger(logger))
+	}
+	amf.NGSetup(s)
+	return s
+}
+
+// session is the IKE SA of a UE.
+type session struct {
+	s    *Sessions
+	conn *nwu.Conn
+	// ue is guarded by the lock of the sessions.
+	ue         UE
+	spiI, spiR uint64
+	// messageID is the message ID of the next request of the UE.
+	messageID uint32
+	eapID     uint8
+	// wmtx serializes the messages sent to the UE, the requests of the
+	// N3IWF being sent from the calls of the AMF. requestID is the
+	// message ID of the next of them.
+	wmtx      sync.Mutex
+	requestID uint32
+	// released is true once the AMF no longer has the context of the UE.
+	released bool
+	// paged is true from the paging of the IDLE UE to its next Service
+	// Request, guarded by pmtx.
+	paged bool
+	// pmtx serializes the requests of the UE, the release of its
+	// signalling connection for inactivity, its pagings and the release of
+	// its context by the AMF.
+	pmtx   sync.Mutex
+	logger log.Logger
+}
+
+// Serve serves the NWu on l, which should be a TLS listener, until it
+// fails.
+func (s *Sessions) Serve(l net.Listener) error {
+	for {
+		c, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go s.serve(c)
+	}
+}
+
+// UEs returns the UEs connected, by RAN UE NGAP ID.
+func (s *Sessions) UEs() []UE {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	ues := make([]UE, 0, len(s.sessions))
+	for _, se := range s.sessions {
+		ues = append(ues, se.ue)
+	}
+	sort.Slice(ues, func(i, j int) bool { return ues[i].RANUEID < ues[j].RANUEID })
+	return ues
+}
+
+// Active returns the number of UEs connected.
+func (s *Sessions) Active() int {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return len(s.sessions)
+}
+
+// Release deletes the IKE SA of the UE ranUEID, closing its connection,
+// and has the AMF release its context.
+func (s *Sessions) Release(ranUEID uint64) error {
+	s.mtx.Lock()
+	se, ok := s.sessions[ranUEID]
+	s.mtx.Unlock()
+	if !ok {
+		return n2.ErrUnknownUE
+	}
+	return se.conn.Close()
+}
+
+// DownlinkNASTransport sends the NAS message nas to the CM-CONNECTED UE
+// ranUEID. The AMF may send it before the Service Accept of an IDLE UE.
+// It restarts the inactivity timer of the UE.
+func (s *Sessions) DownlinkNASTransport(ctx context.Context, ranUEID uint64, nas []byte) error {
+	s.mtx.Lock()
+	se, ok := s.sessions[ranUEID]
+	s.mtx.Unlock()
+	if !ok {
+		return n2.ErrUnknownUE
+	}
+	se.active()
+	return se.request(ctx, nwu.Message{Exchange: nwu.ExchangeNAS, NAS: nas})
+}
+
+// UEContextReleaseCommand deletes the IKE SA of the UE ranUEID, whose
+// context the AMF released, closing its connection.
+func (s *Sessions) UEContextReleaseCommand(ctx context.Context, ranUEID uint64) error {
+	s.mtx.Lock()
+	se, ok := s.sessions[ranUEID]
+	s.mtx.Unlock()
+	if !ok {
+		return n2.ErrUnknownUE
+	}
+	se.pmtx.Lock()
+	se.released = true
+	se.pmtx.Unlock()
+	level.Debug(se.logger).Log("ue_context_release_command", "received")
+	return se.conn.Close()
+}
+
+// Paging pages the UE of p among the IDLE UEs, every one of them hearing
+// the paging as they would on a cell. The paging goes out in the next
+// paging frame of the UE, after Paging returned.
+func (s *Sessions) Paging(ctx context.Context, p n2.Paging) error {
+	time.AfterFunc(p.Delay(time.Now()), func() {
+		s.mtx.Lock()
+		var idle []*session
+		for _, se := range s.sessions {
+			if se.ue.State == StateIdle {
+				idle = append(idle, se)
+			}
+		}
+		s.mtx.Unlock()
+		ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+		defer cancel()
+		for _, se := range idle {
+			if err := se.page(ctx, p.GUTI); err != nil {
+				level.Debug(se.logger).Log("paging", "failed", "err", err)
+			}
+		}
+	})
+	return nil
+}
+
+// page pages the UE of guti to the UE while it is IDLE, the release of its
+// signalling connection having reached it first.
+func (se *session) page(ctx context.Context, guti string) error {
+	se.pmtx.Lock()
+	defer se.pmtx.Unlock()
+	if se.state() != StateIdle {
+		return nil
+	}
+	se.paged = true
+	return se.request(ctx, nwu.Message{Exchange: nwu.ExchangeInformational, Paging: guti})
+}
+
+func (s *Sessions) serve(c net.Conn) {
+	s.mtx.Lock()
+	se := &session{s: s, conn: nwu.NewConn(c), ue: UE{RANUEID: s.nextID, State: StateInit, RemoteAddress: c.RemoteAddr().String()}}
+	s.nextID++
+	s.sessions[se.ue.RANUEID] = se
+	s.mtx.Unlock()
+	se.logger = log.With(s.logger, "ran_ue_ngap_id", se.ue.RANUEID, "remote", se.ue.RemoteAddress)
+
+	err := se.run()
+	se.end()
+	if err != nil {
+		level.Info(se.logger).Log("ike_sa", "deleted", "err", err)
+		return
+	}
+	level.Debug(se.logger).Log("ike_sa", "deleted")
+}
+
+// run runs the exchanges of the UE, up to the deletion of its IKE SA.
+func (se *session) run() error {
+	m, err := se.receive(nwu.ExchangeIKESAInit, true)
+	if err != nil {
+		return err
+	}
+	se.spiI = m.SPIi
+	var spi [8]byte
+	io.ReadFull(se.s.spis, spi[:])
+	se.spiR = binary.BigEndian.Uint64(spi[:])
+	if err := se.respond(m, nwu.Message{}); err != nil {
+		return err
+	}
+
+	// the UE asks for EAP-5G, which the N3IWF starts
+	if m, err = se.receive(nwu.ExchangeIKEAuth, true); err != nil {
+		return err
+	}
+	se.setState(StateEAP)
+	se.eapID++
+	if err := se.respond(m, nwu.Message{EAP: &nwu.EAP{Code: nwu.EAPRequest, Identifier: se.eapID, MsgID: nwu.EAP5GStart}}); err != nil {
+		return err
+	}
+	key, accept, err := se.eap()
+	if err != nil {
+		return err
+	}
+
+	se.setState(StateAuth)
+	if m, err = se.receive(nwu.ExchangeIKEAuth, true); err != nil {
+		return err
+	}
+	if !hmac.Equal(m.Auth, nwu.Auth(key, se.spiI, se.spiR, true)) {
+		se.notify(m, nwu.NotifyAuthenticationFailed)
+		return errors.New("AUTH mismatch")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), se.s.timeout)
+	ip, err := se.s.pool.Allocate(ctx, se.owner())
+	cancel()
+	if err != nil {
+		se.notify(m, nwu.NotifyInternalAddressFailure)
+		return err
+	}
+	se.s.mtx.Lock()
+	se.ue.InnerIP = ip.String()
+	se.s.mtx.Unlock()
+	se.setState(StateConnected)
+	se.watch()
+	level.Info(se.logger).Log("ike_sa", "established", "inner_ip", ip)
+	if err := se.respond(m, nwu.Message{
+		Auth:   nwu.Auth(key, se.spiI, se.spiR, false),
+		Config: &nwu.Config{InnerIP: ip.String()},
+		NAS:    accept,
+	}); err != nil {
+		return err
+	}
+
+	// the signalling IPsec SA, up until the UE deletes the IKE SA
+	for {
+		m, err := se.receive("", false)
+		if err != nil {
+			return err
+		}
+		if done, err := se.handle(m); done || err != nil {
+			return err
+		}
+	}
+}
+
+// handle handles the request m of the CONNECTED or IDLE UE, and reports
+// whether the UE deleted the IKE SA.
+func (se *session) handle(m nwu.Message) (done bool, err error) {
+	se.pmtx.Lock()
+	defer se.pmtx.Unlock()
+	idle := se.state() == StateIdle
+	switch {
+	case m.Exchange == nwu.ExchangeInformational && m.Delete:
+		return true, se.respond(m, nwu.Message{})
+	case m.Exchange == nwu.ExchangeInformational && m.Idle && !idle && !se.released:
+		if err := se.release(); err != nil {
+			se.notify(m, nwu.NotifyTemporaryFailure)
+			return false, err
+		}
+		return false, se.respond(m, nwu.Message{})
+	case m.Exchange == nwu.ExchangeInformational && m.Idle && idle:
+		// released for inactivity meanwhile
+		return false, se.respond(m, nwu.Message{})
+	case m.Exchange == nwu.ExchangeNAS && idle && !se.released && !initialNAS(m.NAS):
+		// the N3IWF released the UE while its NAS message was on the
+		// way: it tries again once back to CM-CONNECTED
+		se.notify(m, nwu.NotifyTemporaryFailure)
+		return false, nil
+	case m.Exchange == nwu.ExchangeNAS && !se.released:
+		// the Service Request or the registration update of a CM-IDLE
+		// UE sets up the signalling connection anew, in an Initial UE
+		// Message, unless the AMF is overloaded
+		var an *n2.ANParameters
+		if idle {
+			an = &n2.ANParameters{}
+			if se.s.throttle(establishmentCause(an, m.NAS), se.paged) {
+				se.notify(m, nwu.NotifyTemporaryFailure)
+				return false, nil
+			}
+		}
+		dl, err := se.uplink(m.NAS, an)
+		if err != nil {
+			se.notify(m, nwu.NotifyTemporaryFailure)
+			return false, err
+		}
+		if idle && !dl.Release {
+			se.paged = false
+			se.setState(StateConnected)
+		}
+		se.active()
+		return false, se.respond(m, nwu.Message{NAS: dl.NAS})
+	}
+	se.notify(m, nwu.NotifyInvalidSyntax)
+	return false, errors.New("unexpected " + m.Exchange)
+}
+
+// initialNAS tells the NAS messages a CM-IDLE UE sets up its signalling
+// connection with: a Service Request, or the Registration Request of a
+// registration update, integrity protected but not ciphered.
+func initialNAS(msg []byte) bool {
+	plain, ok := nas.Plain(msg)
+	if !ok {
+		return false
+	}
+	name, _ := n2.ParseNAS(plain)
+	return name == n2.ServiceRequest || name == n2.RegistrationRequest
+}
+
+// watch starts the inactivity timer of the UE, once CONNECTED.
+func (se *session) watch() {
+	if se.s.timers == nil {
+		return
+	}
+	se.s.timers.Context(se.owner()).Start(timers.UEInactivity, se.s.inactivity, func(timers.Expiry) { se.inactive() })
+}
+
+// active restarts the inactivity timer of the UE.
+func (se *session) active() {
+	if se.s.timers != nil {
+		se.s.timers.Context(se.owner()).Restart(timers.UEInactivity)
+	}
+}
+
+// inactive releases the signalling connection of the CONNECTED UE whose
+// inactivity timer expired, and then tells the UE, which goes CM-IDLE. The
+// timer starts again with the next NAS message of the UE.
+func (se *session) inactive() {
+	se.pmtx.Lock()
+	defer se.pmtx.Unlock()
+	if se.state() != StateConnected || se.released {
+		return
+	}
+	if err := se.release(); err != nil {
+		level.Warn(se.logger).Log("inactivity", "release failed", "err", err)
+		se.active()
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), se.s.timeout)
+	defer cancel()
+	if err := se.request(ctx, nwu.Message{Exchange: nwu.ExchangeInformational, Idle: true}); err != nil {
+		level.Debug(se.logger).Log("inactivity", "release not sent", "err", err)
+	}
+}
+
+// eap relays the NAS messages of the EAP-5G session to the AMF until it
+// sends K_N3IWF, returned with the Registration Accept for the signalling
+// IPsec SA.
+func (se *session) eap() (key, accept []byte, err error) {
+	for {
+		m, err := se.receive(nwu.ExchangeIKEAuth, true)
+		if err != nil {
+			return nil, nil, err
+		}
+		if m.EAP == nil || m.EAP.Code != nwu.EAPResponse || m.EAP.MsgID != nwu.EAP5GNAS || m.EAP.Identifier != se.eapID {
+			se.notify(m, nwu.NotifyInvalidSyntax)
+			return nil, nil, errors.New("expected EAP-Response/" + nwu.EAP5GNAS)
+		}
+		an := m.EAP.AN
+		if se.ue.AMFUEID != 0 {
+			an = nil
+		} else {
+			if an == nil {
+				an = &n2.ANParameters{}
+			}
+			if se.s.throttle(establishmentCause(an, m.EAP.NAS), false) {
+				se.notify(m, nwu.NotifyTemporaryFailure)
+				return nil, nil, errOverload
+			}
+		}
+		dl, err := se.uplink(m.EAP.NAS, an)
+		if err != nil {
+			se.notify(m, nwu.NotifyTemporaryFailure)
+			return nil, nil, err
+		}
+		se.eapID++
+		switch {
+		case dl.Release:
+			// the reject goes with the EAP-Failure
+			se.respond(m, nwu.Message{EAP: &nwu.EAP{Code: nwu.EAPFailure, Identifier: se.eapID, NAS: dl.NAS}})
+			return nil, nil, errRejected
+		case dl.SecurityKey != nil:
+			return dl.SecurityKey, dl.NAS, se.respond(m, nwu.Message{EAP: &nwu.EAP{Code: nwu.EAPSuccess, Identifier: se.eapID}})
+		}
+		if err := se.respond(m, nwu.Message{EAP: &nwu.EAP{Code: nwu.EAPRequest, Identifier: se.eapID, MsgID: nwu.EAP5GNAS, NAS: dl.NAS}}); err != nil {
+			return nil, nil, err
+		}
+	}
+}
+
+// uplink relays the NAS message nas to the AMF, in an Initial UE Message
+// with an for the first one, with the location of the UE.
+func (se *session) uplink(nas []byte, an *n2.ANParameters) (dl n2.Downlink, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), se.s.timeout)
+	defer cancel()
+	loc := n2.UserLocation{TAI: se.s.tai, UEAddress: se.ue.RemoteAddress}
+	if an != nil {
+		dl, err = se.s.amf.InitialUEMessage(ctx, se.ue.RANUEID, nas, *an, loc)
+	} else {
+		dl, err = se.s.amf.UplinkNASTransport(ctx, se.ue.RANUEID, nas, loc)
+	}
+	if dl.AMFUEID != 0 {
+		se.s.mtx.Lock()
+		se.ue.AMFUEID = dl.AMFUEID
+		se.s.mtx.Unlock()
+	}
+	if err != nil {
+		return dl, err
+	}
+	se.released = dl.Release
+	return dl, nil
+}
+
+// release has the AMF release the signalling connection of the UE, which
+// goes IDLE.
+func (se *session) release() error {
+	ctx, cancel := context.WithTimeout(context.Background(), se.s.timeout)
+	defer cancel()
+	if err := se.s.amf.ANRelease(ctx, se.ue.RANUEID); err != nil {
+		return err
+	}
+	se.setState(StateIdle)
+	level.Debug(se.logger).Log("ue", "idle")
+	return nil
+}
+
+// end releases the context of the UE in the AMF, unless the AMF released
+// it, and its inner address, and closes its connection.
+func (se *session) end() {
+	s := se.s
+	s.mtx.Lock()
+	delete(s.sessions, se.ue.RANUEID)
+	ue := se.ue
+	s.mtx.Unlock()
+	se.conn.Close()
+	if s.timers != nil {
+		s.timers.Context(se.owner()).StopAll()
+	}
+
+	se.pmtx.Lock()
+	released := se.released
+	se.pmtx.Unlock()
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+	if ue.AMFUEID != 0 && !released {
+		if err := s.amf.UEContextRelease(ctx, ue.RANUEID); err != nil {
+			level.Warn(se.logger).Log("ue_context_release", "failed", "err", err)
+		}
+	}
+	if ue.InnerIP != "" {
+		if err := s.pool.Release(ctx, net.ParseIP(ue.InnerIP), se.owner()); err != nil {
+			level.Warn(se.logger).Log("inner_ip", ue.InnerIP, "err", err)
+		}
+	}
+	if s.repo != nil && ue.State != StateInit {
+		if err := s.repo.Delete(ctx, ue.RANUEID); err != nil {
+			level.Warn(se.logger).Log("persist", "failed", "err", err)
+		}
+	}
+}
+
+// receive receives the next request of the UE, of exchange unless it is
+// empty, within the timeout when timed. The responses of the UE to the
+// requests of the N3IWF are skipped.
+func (se *session) receive(exchange string, timed bool) (nwu.Message, error) {
+	var deadline time.Time
+	if timed {
+		deadline = time.Now().Add(se.s.timeout)
+	}
+	se.conn.SetReadDeadline(deadline)
+	m, err := se.conn.Receive()
+	for err == nil && m.Response {
+		m, err = se.conn.Receive()
+	}
+	if err != nil {
+		return m, err
+	}
+	if m.MessageID != se.messageID || (se.spiR != 0 && (m.SPIi != se.spiI || m.SPIr != se.spiR)) {
+		se.notify(m, nwu.NotifyInvalidSyntax)
+		return m, errors.New("unexpected message ID or SPIs")
+	}
+	if exchange != "" && m.Exchange != exchange {
+		se.notify(m, nwu.NotifyInvalidSyntax)
+		return m, errors.New("expected " + exchange + ", got " + m.Exchange)
+	}
+	se.messageID++
+	return m, nil
+}
+
+// respond sends the response r to the request m.
+func (se *session) respond(m, r nwu.Message) error {
+	r.Exchange, r.MessageID, r.Response = m.Exchange, m.MessageID, true
+	r.SPIi, r.SPIr = se.spiI, se.spiR
+	se.wmtx.Lock()
+	defer se.wmtx.Unlock()
+	se.conn.SetWriteDeadline(time.Now().Add(se.s.timeout))
+	return se.conn.Send(r)
+}
+
+// request sends the request m of the N3IWF to the UE, whose response is
+// skipped when received.
+func (se *session) request(ctx context.Context, m nwu.Message) error {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(se.s.timeout)
+	}
+	se.wmtx.Lock()
+	defer se.wmtx.Unlock()
+	m.MessageID, m.SPIi, m.SPIr = se.requestID, se.spiI, se.spiR
+	se.requestID++
+	se.conn.SetWriteDeadline(deadline)
+	return se.conn.Send(m)
+}
+
+// notify responds to m with the error notification typ.
+func (se *session) notify(m nwu.Message, typ string) {
+	se.respond(m, nwu.Message{Notify: typ})
+}
+
+func (se *session) state() string {
+	se.s.mtx.Lock()
+	defer se.s.mtx.Unlock()
+	return se.ue.State
+}
+
+func (se *session) setState(state string) {
+	se.s.mtx.Lock()
+	se.ue.State = state
+	ue := se.ue
+	se.s.mtx.Unlock()
+	se.persist(ue)
+}
+
+// owner returns the owner of the inner address of the UE in the pool.
+func (se *session) owner() string {
+	return owner(se.ue.RANUEID)
+}
+
+// owner returns the owner of the inner address of the UE ranUEID.
+func owner(ranUEID uint64) string {
+	return "ran-ue-" + strconv.FormatUint(ranUEID, 10)
+}
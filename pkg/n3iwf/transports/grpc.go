@@ -0,0 +1,247 @@
+package transports
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/kit/circuitbreaker"
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/ratelimit"
+	"github.com/go-kit/kit/tracing/opentracing"
+	"github.com/go-kit/kit/tracing/zipkin"
+	grpctransport "github.com/go-kit/kit/transport/grpc"
+	stdopentracing "github.com/opentracing/opentracing-go"
+	stdzipkin "github.com/openzipkin/zipkin-go"
+	"github.com/sony/gobreaker"
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/miki-tnt/sa5g-go-usvc-k8s/pb/n3iwf"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/breakers"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/caller"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/grpcpool"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/idempotency"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/logging"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/meta"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/n3iwf/endpoints"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/n3iwf/service"
+)
+
+type grpcServer struct {
+	listUEs   grpctransport.Handler `json:""`
+	releaseUE grpctransport.Handler `json:""`
+}
+
+func (s *grpcServer) ListUEs(ctx context.Context, req *pb.ListUEsRequest) (rep *pb.ListUEsReply, err error) {
+	_, rp, err := s.listUEs.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcEncodeError(err)
+	}
+	rep = rp.(*pb.ListUEsReply)
+	return rep, nil
+}
+
+func (s *grpcServer) ReleaseUE(ctx context.Context, req *pb.ReleaseUERequest) (rep *pb.ReleaseUEReply, err error) {
+	_, rp, err := s.releaseUE.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcEncodeError(err)
+	}
+	rep = rp.(*pb.ReleaseUEReply)
+	return rep, nil
+}
+
+// MakeGRPCServer makes a set of endpoints available as a gRPC server.
+func MakeGRPCServer(endpoints endpoints.Endpoints, otTracer stdopentracing.Tracer, zipkinTracer *stdzipkin.Tracer, logger log.Logger) (req pb.N3IwfServer) {
+	// A global Zipkin tracing service is fed to each Go kit gRPC server, the
+	// operation name being the gRPC method path.
+	zipkinServer := zipkin.GRPCServerTrace(zipkinTracer)
+
+	options := []grpctransport.ServerOption{
+		grpctransport.ServerErrorHandler(logging.ErrorHandler(logger)),
+		grpctransport.ServerBefore(idempotency.GRPCToContext()),
+		grpctransport.ServerBefore(caller.GRPCToContext()),
+		grpctransport.ServerBefore(meta.GRPCToContext()),
+		zipkinServer,
+	}
+
+	return &grpcServer{
+		listUEs: grpctransport.NewServer(
+			endpoints.ListUEsEndpoint,
+			decodeGRPCListUEsRequest,
+			encodeGRPCListUEsResponse,
+			append(options, grpctransport.ServerBefore(opentracing.GRPCToContext(otTracer, "ListUEs", logger)))...,
+		),
+		releaseUE: grpctransport.NewServer(
+			endpoints.ReleaseUEEndpoint,
+			decodeGRPCReleaseUERequest,
+			encodeGRPCReleaseUEResponse,
+			append(options, grpctransport.ServerBefore(opentracing.GRPCToContext(otTracer, "ReleaseUE", logger)))...,
+		),
+	}
+}
+
+// decodeGRPCListUEsRequest is a transport/grpc.DecodeRequestFunc that converts a
+// gRPC request to a user-domain request. Primarily useful in a server.
+func decodeGRPCListUEsRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	return endpoints.ListUEsRequest{}, nil
+}
+
+// encodeGRPCListUEsResponse is a transport/grpc.EncodeResponseFunc that converts a
+// user-domain response to a gRPC reply. Primarily useful in a server.
+func encodeGRPCListUEsResponse(_ context.Context, grpcReply interface{}) (res interface{}, err error) {
+	reply := grpcReply.(endpoints.ListUEsResponse)
+	ues := make([]*pb.UE, len(reply.UEs))
+	for i, u := range reply.UEs {
+		ues[i] = &pb.UE{
+			RanUeNgapId:   u.RANUEID,
+			AmfUeNgapId:   u.AMFUEID,
+			State:         u.State,
+			InnerIp:       u.InnerIP,
+			RemoteAddress: u.RemoteAddress,
+		}
+	}
+	return &pb.ListUEsReply{Ues: ues}, grpcEncodeError(reply.Err)
+}
+
+// decodeGRPCReleaseUERequest is a transport/grpc.DecodeRequestFunc that converts a
+// gRPC request to a user-domain request. Primarily useful in a server.
+func decodeGRPCReleaseUERequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pb.ReleaseUERequest)
+	return endpoints.ReleaseUERequest{RanUENgapID: req.RanUeNgapId}, nil
+}
+
+// encodeGRPCReleaseUEResponse is a transport/grpc.EncodeResponseFunc that converts a
+// user-domain response to a gRPC reply. Primarily useful in a server.
+func encodeGRPCReleaseUEResponse(_ context.Context, grpcReply interface{}) (res interface{}, err error) {
+	reply := grpcReply.(endpoints.ReleaseUEResponse)
+	return &pb.ReleaseUEReply{}, grpcEncodeError(reply.Err)
+}
+
+// NewGRPCClient returns a N3iwfService backed by a gRPC server at the other end
+// of the conn. The caller is responsible for constructing the conn, and
+// eventually closing the underlying transport. We bake-in certain middlewares,
+// implementing the client library pattern.
+func NewGRPCClient(conn *grpc.ClientConn, otTracer stdopentracing.Tracer, zipkinTracer *stdzipkin.Tracer, logger log.Logger) service.N3iwfService {
+	return NewGRPCPoolClient(grpcpool.FromConn(conn), otTracer, zipkinTracer, logger)
+}
+
+// NewGRPCPoolClient is like NewGRPCClient, but spreads the calls over the
+// connections of the pool. The caller is responsible for closing the pool.
+func NewGRPCPoolClient(pool *grpcpool.Pool, otTracer stdopentracing.Tracer, zipkinTracer *stdzipkin.Tracer, logger log.Logger) service.N3iwfService {
+	// We construct a single ratelimiter middleware, to limit the total outgoing
+	// QPS from this client to all methods on the remote instance, and
+	// per-endpoint circuitbreaker middlewares.
+	limiter := ratelimit.NewErroringLimiter(rate.NewLimiter(rate.Every(time.Second), 100))
+
+	// A global Zipkin tracing client is fed to each Go kit client, the
+	// operation name being the gRPC method path.
+	zipkinClient := zipkin.GRPCClientTrace(zipkinTracer)
+
+	// global client middlewares
+	options := []grpctransport.ClientOption{
+		grpctransport.ClientBefore(idempotency.ContextToGRPC()),
+		grpctransport.ClientBefore(meta.ContextToGRPC()),
+		zipkinClient,
+	}
+
+	var listUEsEndpoint endpoint.Endpoint
+	{
+		listUEsEndpoint = pool.Endpoint(func(conn *grpc.ClientConn) endpoint.Endpoint {
+			return grpctransport.NewClient(
+				conn,
+				"pb.N3iwf",
+				"ListUEs",
+				encodeGRPCListUEsRequest,
+				decodeGRPCListUEsResponse,
+				pb.ListUEsReply{},
+				append(options, grpctransport.ClientBefore(opentracing.ContextToGRPC(otTracer, logger)))...,
+			).Endpoint()
+		})
+		listUEsEndpoint = opentracing.TraceClient(otTracer, "ListUEs")(listUEsEndpoint)
+		listUEsEndpoint = limiter(listUEsEndpoint)
+		listUEsEndpoint = circuitbreaker.Gobreaker(breakers.New(gobreaker.Settings{
+			Name:    "ListUEs",
+			Timeout: 30 * time.Second,
+		}))(listUEsEndpoint)
+	}
+
+	var releaseUEEndpoint endpoint.Endpoint
+	{
+		releaseUEEndpoint = pool.Endpoint(func(conn *grpc.ClientConn) endpoint.Endpoint {
+			return grpctransport.NewClient(
+				conn,
+				"pb.N3iwf",
+				"ReleaseUE",
+				encodeGRPCReleaseUERequest,
+				decodeGRPCReleaseUEResponse,
+				pb.ReleaseUEReply{},
+				append(options, grpctransport.ClientBefore(opentracing.ContextToGRPC(otTracer, logger)))...,
+			).Endpoint()
+		})
+		releaseUEEndpoint = opentracing.TraceClient(otTracer, "ReleaseUE")(releaseUEEndpoint)
+		releaseUEEndpoint = limiter(releaseUEEndpoint)
+		releaseUEEndpoint = circuitbreaker.Gobreaker(breakers.New(gobreaker.Settings{
+			Name:    "ReleaseUE",
+			Timeout: 30 * time.Second,
+		}))(releaseUEEndpoint)
+	}
+
+	return endpoints.Endpoints{
+		ListUEsEndpoint:   listUEsEndpoint,
+		ReleaseUEEndpoint: releaseUEEndpoint,
+	}
+}
+
+// encodeGRPCListUEsRequest is a transport/grpc.EncodeRequestFunc that converts a
+// user-domain ListUEs request to a gRPC ListUEs request. Primarily useful in a client.
+func encodeGRPCListUEsRequest(_ context.Context, request interface{}) (interface{}, error) {
+	return &pb.ListUEsRequest{}, nil
+}
+
+// decodeGRPCListUEsResponse is a transport/grpc.DecodeResponseFunc that converts a
+// gRPC ListUEs reply to a user-domain ListUEs response. Primarily useful in a client.
+func decodeGRPCListUEsResponse(_ context.Context, grpcReply interface{}) (interface{}, error) {
+	reply := grpcReply.(*pb.ListUEsReply)
+	ues := make([]service.UE, len(reply.Ues))
+	for i, u := range reply.Ues {
+		ues[i] = service.UE{
+			RANUEID:       u.GetRanUeNgapId(),
+			AMFUEID:       u.GetAmfUeNgapId(),
+			State:         u.GetState(),
+			InnerIP:       u.GetInnerIp(),
+			RemoteAddress: u.GetRemoteAddress(),
+		}
+	}
+	return endpoints.ListUEsResponse{UEs: ues}, nil
+}
+
+// encodeGRPCReleaseUERequest is a transport/grpc.EncodeRequestFunc that converts a
+// user-domain ReleaseUE request to a gRPC ReleaseUE request. Primarily useful in a client.
+func encodeGRPCReleaseUERequest(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(endpoints.ReleaseUERequest)
+	return &pb.ReleaseUERequest{RanUeNgapId: req.RanUENgapID}, nil
+}
+
+// decodeGRPCReleaseUEResponse is a transport/grpc.DecodeResponseFunc that converts a
+// gRPC ReleaseUE reply to a user-domain ReleaseUE response. Primarily useful in a client.
+func decodeGRPCReleaseUEResponse(_ context.Context, grpcReply interface{}) (interface{}, error) {
+	return endpoints.ReleaseUEResponse{}, nil
+}
+
+func grpcEncodeError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	st, ok := status.FromError(err)
+	if ok {
+		return status.Error(st.Code(), st.Message())
+	}
+	switch err {
+	default:
+		return status.Error(codes.Internal, "internal server error")
+	}
+}
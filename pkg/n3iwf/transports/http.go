@@ -0,0 +1,269 @@
+package transports
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-kit/kit/circuitbreaker"
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/ratelimit"
+	"github.com/go-kit/kit/sd/lb"
+	"github.com/go-kit/kit/tracing/opentracing"
+	"github.com/go-kit/kit/tracing/zipkin"
+	httptransport "github.com/go-kit/kit/transport/http"
+	"github.com/golang/protobuf/proto"
+	stdopentracing "github.com/opentracing/opentracing-go"
+	stdzipkin "github.com/openzipkin/zipkin-go"
+	"github.com/sony/gobreaker"
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/miki-tnt/sa5g-go-usvc-k8s/pb/n3iwf"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/breakers"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/caller"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/codec"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/idempotency"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/logging"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/meta"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/n3iwf/endpoints"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/n3iwf/service"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/openapi"
+)
+
+type errorWrapper struct {
+	Error string `json:"error"`
+}
+
+func JSONErrorDecoder(r *http.Response) error {
+	contentType := r.Header.Get("Content-Type")
+	if !strings.Contains(contentType, "application/json") {
+		return fmt.Errorf("expected JSON formatted error, got Content-Type %s", contentType)
+	}
+	var w errorWrapper
+	if err := json.NewDecoder(r.Body).Decode(&w); err != nil {
+		return err
+	}
+	return errors.New(w.Error)
+}
+
+// NewHTTPHandler returns a handler that makes a set of endpoints available on
+// predefined paths.
+func NewHTTPHandler(endpoints endpoints.Endpoints, otTracer stdopentracing.Tracer, zipkinTracer *stdzipkin.Tracer, logger log.Logger) http.Handler {
+	// A global Zipkin tracing service is fed to each Go kit endpoint, the
+	// operation name being the HTTP method.
+	zipkinServer := zipkin.HTTPServerTrace(zipkinTracer)
+
+	options := []httptransport.ServerOption{
+		httptransport.ServerErrorEncoder(httpEncodeError),
+		httptransport.ServerErrorHandler(logging.ErrorHandler(logger)),
+		httptransport.ServerBefore(idempotency.HTTPToContext()),
+		httptransport.ServerBefore(caller.HTTPToContext()),
+		httptransport.ServerBefore(meta.HTTPToContext()),
+		httptransport.ServerBefore(codec.Default.HTTPToContext()),
+		zipkinServer,
+	}
+
+	m := http.NewServeMux()
+	m.Handle("/listUEs", httptransport.NewServer(
+		endpoints.ListUEsEndpoint,
+		codec.DecodeRequest(listUEsBinding),
+		codec.EncodeResponse(listUEsBinding),
+		append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "ListUEs", logger)))...,
+	))
+	m.Handle("/releaseUE", httptransport.NewServer(
+		endpoints.ReleaseUEEndpoint,
+		codec.DecodeRequest(releaseUEBinding),
+		codec.EncodeResponse(releaseUEBinding),
+		append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "ReleaseUE", logger)))...,
+	))
+	m.Handle(openapi.Path, openapi.Handler(OpenAPI()))
+	return m
+}
+
+var listUEsBinding = codec.Binding{
+	Request:   func() interface{} { return &endpoints.ListUEsRequest{} },
+	PBRequest: func() proto.Message { return &pb.ListUEsRequest{} },
+	FromPB:    decodeGRPCListUEsRequest,
+	ToPB:      encodeGRPCListUEsResponse,
+}
+
+var releaseUEBinding = codec.Binding{
+	Request:   func() interface{} { return &endpoints.ReleaseUERequest{} },
+	PBRequest: func() proto.Message { return &pb.ReleaseUERequest{} },
+	FromPB:    decodeGRPCReleaseUERequest,
+	ToPB:      encodeGRPCReleaseUEResponse,
+}
+
+// NewHTTPClient returns a N3iwfService backed by an HTTP server living at the
+// remote instance. We expect instance to come from a service discovery system,
+// so likely of the form "host:port". We bake-in certain middlewares,
+// implementing the client library pattern.
+func NewHTTPClient(instance string, otTracer stdopentracing.Tracer, zipkinTracer *stdzipkin.Tracer, logger log.Logger) (service.N3iwfService, error) {
+	// Quickly sanitize the instance string.
+	if !strings.HasPrefix(instance, "http") {
+		instance = "http://" + instance
+	}
+	u, err := url.Parse(instance)
+	if err != nil {
+		return nil, err
+	}
+
+	// We construct a single ratelimiter middleware, to limit the total outgoing
+	// QPS from this client to all methods on the remote instance, and
+	// per-endpoint circuitbreaker middlewares.
+	limiter := ratelimit.NewErroringLimiter(rate.NewLimiter(rate.Every(time.Second), 100))
+
+	// A global Zipkin tracing client is fed to each Go kit endpoint, the
+	// operation name being the HTTP method.
+	zipkinClient := zipkin.HTTPClientTrace(zipkinTracer)
+
+	// global client middlewares
+	options := []httptransport.ClientOption{
+		httptransport.ClientBefore(idempotency.ContextToHTTP()),
+		httptransport.ClientBefore(meta.ContextToHTTP()),
+		zipkinClient,
+	}
+
+	e := endpoints.Endpoints{}
+
+	// Each individual endpoint is an http/transport.Client (which implements
+	// endpoint.Endpoint) that gets wrapped with various middlewares.
+	var listUEsEndpoint endpoint.Endpoint
+	{
+		listUEsEndpoint = httptransport.NewClient(
+			"POST",
+			copyURL(u, "/listUEs"),
+			encodeHTTPListUEsRequest,
+			decodeHTTPListUEsResponse,
+			append(options, httptransport.ClientBefore(opentracing.ContextToHTTP(otTracer, logger)))...,
+		).Endpoint()
+		listUEsEndpoint = opentracing.TraceClient(otTracer, "ListUEs")(listUEsEndpoint)
+		listUEsEndpoint = zipkin.TraceEndpoint(zipkinTracer, "ListUEs")(listUEsEndpoint)
+		listUEsEndpoint = limiter(listUEsEndpoint)
+		listUEsEndpoint = circuitbreaker.Gobreaker(breakers.New(gobreaker.Settings{
+			Name:    "ListUEs",
+			Timeout: 30 * time.Second,
+		}))(listUEsEndpoint)
+		e.ListUEsEndpoint = listUEsEndpoint
+	}
+	var releaseUEEndpoint endpoint.Endpoint
+	{
+		releaseUEEndpoint = httptransport.NewClient(
+			"POST",
+			copyURL(u, "/releaseUE"),
+			encodeHTTPReleaseUERequest,
+			decodeHTTPReleaseUEResponse,
+			append(options, httptransport.ClientBefore(opentracing.ContextToHTTP(otTracer, logger)))...,
+		).Endpoint()
+		releaseUEEndpoint = opentracing.TraceClient(otTracer, "ReleaseUE")(releaseUEEndpoint)
+		releaseUEEndpoint = zipkin.TraceEndpoint(zipkinTracer, "ReleaseUE")(releaseUEEndpoint)
+		releaseUEEndpoint = limiter(releaseUEEndpoint)
+		releaseUEEndpoint = circuitbreaker.Gobreaker(breakers.New(gobreaker.Settings{
+			Name:    "ReleaseUE",
+			Timeout: 30 * time.Second,
+		}))(releaseUEEndpoint)
+		e.ReleaseUEEndpoint = releaseUEEndpoint
+	}
+
+	// Returning the endpoint.Set as a service.Service relies on the
+	// endpoint.Set implementing the Service methods. That's just a simple bit
+	// of glue code.
+	return e, nil
+}
+
+func copyURL(base *url.URL, path string) *url.URL {
+	next := *base
+	next.Path = path
+	return &next
+}
+
+// encodeHTTPListUEsRequest is a transport/http.EncodeRequestFunc that
+// JSON-encodes any request to the request body. Primarily useful in a client.
+func encodeHTTPListUEsRequest(_ context.Context, r *http.Request, request interface{}) (err error) {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(request); err != nil {
+		return err
+	}
+	r.Body = ioutil.NopCloser(&buf)
+	return nil
+}
+
+// decodeHTTPListUEsResponse is a transport/http.DecodeResponseFunc that decodes a
+// JSON-encoded ListUEs response from the HTTP response body. If the response has a
+// non-200 status code, we will interpret that as an error and attempt to decode
+// the specific error message from the response body. Primarily useful in a client.
+func decodeHTTPListUEsResponse(_ context.Context, r *http.Response) (interface{}, error) {
+	if r.StatusCode != http.StatusOK {
+		return nil, JSONErrorDecoder(r)
+	}
+	var resp endpoints.ListUEsResponse
+	err := json.NewDecoder(r.Body).Decode(&resp)
+	return resp, err
+}
+
+// encodeHTTPReleaseUERequest is a transport/http.EncodeRequestFunc that
+// JSON-encodes any request to the request body. Primarily useful in a client.
+func encodeHTTPReleaseUERequest(_ context.Context, r *http.Request, request interface{}) (err error) {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(request); err != nil {
+		return err
+	}
+	r.Body = ioutil.NopCloser(&buf)
+	return nil
+}
+
+// decodeHTTPReleaseUEResponse is a transport/http.DecodeResponseFunc that decodes a
+// JSON-encoded ReleaseUE response from the HTTP response body. If the response has a
+// non-200 status code, we will interpret that as an error and attempt to decode
+// the specific error message from the response body. Primarily useful in a client.
+func decodeHTTPReleaseUEResponse(_ context.Context, r *http.Response) (interface{}, error) {
+	if r.StatusCode != http.StatusOK {
+		return nil, JSONErrorDecoder(r)
+	}
+	var resp endpoints.ReleaseUEResponse
+	err := json.NewDecoder(r.Body).Decode(&resp)
+	return resp, err
+}
+
+func httpEncodeError(_ context.Context, err error, w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if lberr, ok := err.(lb.RetryError); ok {
+		st, _ := status.FromError(lberr.Final)
+		w.WriteHeader(HTTPStatusFromCode(st.Code()))
+		json.NewEncoder(w).Encode(errorWrapper{Error: st.Message()})
+	} else {
+		st, ok := status.FromError(err)
+		if ok {
+			w.WriteHeader(HTTPStatusFromCode(st.Code()))
+			json.NewEncoder(w).Encode(errorWrapper{Error: st.Message()})
+		} else {
+			switch err {
+			case io.ErrUnexpectedEOF:
+				w.WriteHeader(http.StatusBadRequest)
+			case io.EOF:
+				w.WriteHeader(http.StatusBadRequest)
+			default:
+				switch err.(type) {
+				case *json.SyntaxError:
+					w.WriteHeader(http.StatusBadRequest)
+				case *json.UnmarshalTypeError:
+					w.WriteHeader(http.StatusBadRequest)
+				default:
+					w.WriteHeader(http.StatusInternalServerError)
+				}
+			}
+			json.NewEncoder(w).Encode(errorWrapper{Error: err.Error()})
+		}
+	}
+}
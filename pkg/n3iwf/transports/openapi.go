@@ -0,0 +1,27 @@
+package transports
+
+import (
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/n3iwf/endpoints"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/openapi"
+)
+
+// OpenAPI returns the description of the HTTP transport, served by
+// NewHTTPHandler on openapi.Path.
+func OpenAPI() *openapi.Document {
+	return openapi.New("n3iwf", "v1",
+		openapi.Operation{
+			Path:     "/listUEs",
+			ID:       "ListUEs",
+			Summary:  "ListUEs lists the UEs connected through the NWu.",
+			Request:  endpoints.ListUEsRequest{},
+			Response: endpoints.ListUEsResponse{},
+		},
+		openapi.Operation{
+			Path:     "/releaseUE",
+			ID:       "ReleaseUE",
+			Summary:  "ReleaseUE releases the UE of ran_ue_ngap_id, deleting its IKE SA.",
+			Request:  endpoints.ReleaseUERequest{},
+			Response: endpoints.ReleaseUEResponse{},
+		},
+	)
+}
@@ -0,0 +1,230 @@
+// Package openapi describes the HTTP JSON transports of the services as an
+// OpenAPI 3 document, served on Path. The schemas are derived from the
+// request and response structs of the endpoints packages by reflection,
+// following their json tags, so the document cannot drift from the code.
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Path is where every service serves its document.
+const Path = "/openapi.json"
+
+// Operation is a JSON over HTTP POST operation. Request and Response are
+// values of the structs decoded from the request body and encoded in the
+// response body.
+type Operation struct {
+	Path     string
+	ID       string
+	Summary  string
+	Request  interface{}
+	Response interface{}
+}
+
+// Document is an OpenAPI 3.0 document.
+type Document struct {
+	OpenAPI    string                `json:"openapi"`
+	Info       Info                  `json:"info"`
+	Paths      map[string]PathItem   `json:"paths"`
+	Components Components            `json:"components"`
+	schemas    map[reflect.Type]bool `json:"-"`
+}
+
+// Info is the info object of a Document.
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// PathItem holds the operations of a path.
+type PathItem struct {
+	Post *OperationObject `json:"post,omitempty"`
+}
+
+// OperationObject is the description of an operation in a Document.
+type OperationObject struct {
+	OperationID string              `json:"operationId"`
+	Summary     string              `json:"summary,omitempty"`
+	RequestBody *Body               `json:"requestBody,omitempty"`
+	Responses   map[string]Response `json:"responses"`
+}
+
+// Body is a JSON request body.
+type Body struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response is a JSON response.
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType holds the schema of a body.
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components holds the schemas referenced by the operations.
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+// Schema is the subset of the JSON schema used to describe Go types.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+}
+
+// errorSchema is the body written by the error encoders of the transports.
+const errorSchema = "Error"
+
+// New returns the document of a service named title.
+func New(title, version string, ops ...Operation) *Document {
+	d := &Document{
+		OpenAPI:    "3.0.3",
+		Info:       Info{Title: title, Version: version},
+		Paths:      map[string]PathItem{},
+		Components: Components{Schemas: map[string]*Schema{}},
+		schemas:    map[reflect.Type]bool{},
+	}
+	d.Components.Schemas[errorSchema] = &Schema{
+		Type:       "object",
+		Properties: map[string]*Schema{"error": {Type: "string"}},
+	}
+	for _, op := range ops {
+		d.Paths[op.Path] = PathItem{Post: d.operation(op)}
+	}
+	return d
+}
+
+// Handler serves d as JSON.
+func Handler(d *Document) http.Handler {
+	data, err := json.MarshalIndent(d, "", "  ")
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	})
+}
+
+func (d *Document) operation(op Operation) *OperationObject {
+	o := &OperationObject{
+		OperationID: op.ID,
+		Summary:     op.Summary,
+		Responses: map[string]Response{
+			"200": {Description: "OK", Content: jsonContent(d.schema(reflect.TypeOf(op.Response)))},
+			"default": {
+				Description: "the error, with the HTTP status of its gRPC code",
+				Content:     jsonContent(&Schema{Ref: "#/components/schemas/" + errorSchema}),
+			},
+		},
+	}
+	if op.Request != nil {
+		o.RequestBody = &Body{Required: true, Content: jsonContent(d.schema(reflect.TypeOf(op.Request)))}
+	}
+	return o
+}
+
+func jsonContent(s *Schema) map[string]MediaType {
+	return map[string]MediaType{"application/json": {Schema: s}}
+}
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	durationType  = reflect.TypeOf(time.Duration(0))
+	marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// schema returns the schema of t, a reference for named structs, which are
+// added to the components.
+func (d *Document) schema(t reflect.Type) *Schema {
+	if t.Kind() == reflect.Ptr {
+		s := d.schema(t.Elem())
+		if s == nil || s.Ref != "" {
+			return s
+		}
+		s.Nullable = true
+		return s
+	}
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == durationType:
+		return &Schema{Type: "integer", Format: "int64"}
+	case t.Implements(marshalerType) || reflect.PtrTo(t).Implements(marshalerType):
+		// custom encodings are strings in this repository, such as the
+		// hex strings of the subscriber package
+		return &Schema{Type: "string"}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: d.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: d.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return d.object(t)
+		}
+		if !d.schemas[t] {
+			d.schemas[t] = true
+			d.Components.Schemas[t.Name()] = d.object(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + t.Name()}
+	}
+	// interfaces, such as the Err fields of the responses, are not part of
+	// the successful responses
+	return nil
+}
+
+func (d *Document) object(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		name := f.Name
+		if tag := f.Tag.Get("json"); tag != "" {
+			if tag == "-" {
+				continue
+			}
+			if n := strings.Split(tag, ",")[0]; n != "" {
+				name = n
+			}
+		}
+		if fs := d.schema(f.Type); fs != nil {
+			s.Properties[name] = fs
+		}
+	}
+	return s
+}
@@ -0,0 +1,198 @@
+package operator
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// The files and the environment variables of a pod that give the API
+// server and the credentials of its service account.
+const (
+	serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+	envServiceHost    = "KUBERNETES_SERVICE_HOST"
+	envServicePort    = "KUBERNETES_SERVICE_PORT"
+)
+
+// ErrNotInCluster is returned by InClusterClient out of a pod.
+var ErrNotInCluster = errors.New("operator: not running in a pod, " + envServiceHost + " is unset")
+
+// Client is a client of the Kubernetes API.
+type Client struct {
+	url    string
+	client *http.Client
+	// tokenFile is read on every call: the token of the service account
+	// is rotated.
+	tokenFile string
+}
+
+// NewClient returns the client of the API server at url, such as the
+// http://localhost:8001 of kubectl proxy, which authenticates the calls.
+func NewClient(url string) *Client {
+	return &Client{url: strings.TrimSuffix(url, "/"), client: &http.Client{Timeout: 30 * time.Second}}
+}
+
+// InClusterNamespace returns the namespace of the pod, empty out of a pod.
+func InClusterNamespace() string {
+	b, err := ioutil.ReadFile(serviceAccountDir + "/namespace")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(b))
+}
+
+// InClusterClient returns the client of the API server of the cluster the
+// pod runs in, authenticated as its service account.
+func InClusterClient() (*Client, error) {
+	host, port := os.Getenv(envServiceHost), os.Getenv(envServicePort)
+	if host == "" {
+		return nil, ErrNotInCluster
+	}
+	ca, err := ioutil.ReadFile(serviceAccountDir + "/ca.crt")
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, fmt.Errorf("operator: no certificate in %s/ca.crt", serviceAccountDir)
+	}
+	c := NewClient("https://" + net.JoinHostPort(host, port))
+	c.client.Transport = &http.Transport{
+		Proxy:           http.ProxyFromEnvironment,
+		TLSClientConfig: &tls.Config{RootCAs: pool},
+	}
+	c.tokenFile = serviceAccountDir + "/token"
+	return c, nil
+}
+
+// StatusError is the error the API server answered a call with.
+type StatusError struct {
+	Method, Path string
+	Code         int
+	Reason       string
+	Message      string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s %s: %d %s: %s", e.Method, e.Path, e.Code, e.Reason, e.Message)
+}
+
+// IsNotFound tells whether err is the answer of the API server to a call
+// on an object that does not exist.
+func IsNotFound(err error) bool {
+	e, ok := err.(*StatusError)
+	return ok && e.Code == http.StatusNotFound
+}
+
+// collection returns the path of the objects of kind of apiVersion in
+// namespace, in every namespace when empty.
+func collection(apiVersion, kind, namespace string) string {
+	path := "/apis/" + apiVersion
+	if apiVersion == "v1" {
+		path = "/api/v1"
+	}
+	if namespace != "" {
+		path += "/namespaces/" + namespace
+	}
+	return path + "/" + strings.ToLower(kind) + "s"
+}
+
+// List lists the objects of kind of apiVersion in namespace, in every
+// namespace when empty, whose labels match selector, in the Items of
+// list.
+func (c *Client) List(ctx context.Context, apiVersion, kind, namespace, selector string, list interface{}) error {
+	path := collection(apiVersion, kind, namespace)
+	if selector != "" {
+		path += "?labelSelector=" + url.QueryEscape(selector)
+	}
+	return c.do(ctx, http.MethodGet, path, "", nil, list)
+}
+
+// Get gets the object name of kind of apiVersion in namespace in obj.
+func (c *Client) Get(ctx context.Context, apiVersion, kind, namespace, name string, obj interface{}) error {
+	return c.do(ctx, http.MethodGet, collection(apiVersion, kind, namespace)+"/"+name, "", nil, obj)
+}
+
+// Apply applies obj with a server-side apply, taking the fields over from
+// the other managers, and decodes what the API server made of it in res,
+// unless nil.
+func (c *Client) Apply(ctx context.Context, obj Object, res interface{}) error {
+	t, m := obj.Type(), obj.Meta()
+	path := collection(t.APIVersion, t.Kind, m.Namespace) + "/" + m.Name +
+		"?fieldManager=" + ManagedBy + "&force=true"
+	// JSON being YAML, the object is applied as is
+	return c.do(ctx, http.MethodPatch, path, "application/apply-patch+yaml", obj, res)
+}
+
+// PatchStatus replaces the status of the object name of kind of
+// apiVersion in namespace.
+func (c *Client) PatchStatus(ctx context.Context, apiVersion, kind, namespace, name string, status interface{}) error {
+	path := collection(apiVersion, kind, namespace) + "/" + name + "/status"
+	patch := map[string]interface{}{"status": status}
+	return c.do(ctx, http.MethodPatch, path, "application/merge-patch+json", patch, nil)
+}
+
+// Delete deletes the object name of kind of apiVersion in namespace, and
+// in the background the objects it owns.
+func (c *Client) Delete(ctx context.Context, apiVersion, kind, namespace, name string) error {
+	path := collection(apiVersion, kind, namespace) + "/" + name
+	options := map[string]string{"propagationPolicy": "Background"}
+	return c.do(ctx, http.MethodDelete, path, "application/json", options, nil)
+}
+
+// do calls path with req as a body of contentType, unless nil, and
+// decodes the response in res, unless nil. The error of a failed call is
+// a *StatusError.
+func (c *Client) do(ctx context.Context, method, path, contentType string, req, res interface{}) error {
+	var body []byte
+	if req != nil {
+		var err error
+		if body, err = json.Marshal(req); err != nil {
+			return err
+		}
+	}
+	r, err := http.NewRequest(method, c.url+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	r.Header.Set("Accept", "application/json")
+	if contentType != "" {
+		r.Header.Set("Content-Type", contentType)
+	}
+	if c.tokenFile != "" {
+		token, err := ioutil.ReadFile(c.tokenFile)
+		if err != nil {
+			return err
+		}
+		r.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	}
+	resp, err := c.client.Do(r.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		// the API server answers with a Status
+		var st struct {
+			Reason  string `json:"reason"`
+			Message string `json:"message"`
+		}
+		json.NewDecoder(resp.Body).Decode(&st)
+		return &StatusError{Method: method, Path: path, Code: resp.StatusCode, Reason: st.Reason, Message: st.Message}
+	}
+	if res == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(res)
+}
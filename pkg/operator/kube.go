@@ -0,0 +1,234 @@
+package operator
+
+// The objects of the Kubernetes API the operator reads and writes, down to
+// the fields it uses.
+
+// TypeMeta is the kind of an object.
+type TypeMeta struct {
+	APIVersion string `json:"apiVersion,omitempty"`
+	Kind       string `json:"kind,omitempty"`
+}
+
+// ObjectMeta is the metadata of an object.
+type ObjectMeta struct {
+	Name              string            `json:"name,omitempty"`
+	Namespace         string            `json:"namespace,omitempty"`
+	UID               string            `json:"uid,omitempty"`
+	Generation        int64             `json:"generation,omitempty"`
+	DeletionTimestamp string            `json:"deletionTimestamp,omitempty"`
+	Labels            map[string]string `json:"labels,omitempty"`
+	Annotations       map[string]string `json:"annotations,omitempty"`
+	OwnerReferences   []OwnerReference  `json:"ownerReferences,omitempty"`
+}
+
+// OwnerReference makes the object it is set on deleted with its owner.
+type OwnerReference struct {
+	APIVersion         string `json:"apiVersion"`
+	Kind               string `json:"kind"`
+	Name               string `json:"name"`
+	UID                string `json:"uid"`
+	Controller         bool   `json:"controller"`
+	BlockOwnerDeletion bool   `json:"blockOwnerDeletion"`
+}
+
+// Object is an object the operator applies.
+type Object interface {
+	Meta() *ObjectMeta
+	Type() TypeMeta
+}
+
+// Deployment is an apps/v1 Deployment.
+type Deployment struct {
+	TypeMeta
+	Metadata ObjectMeta        `json:"metadata"`
+	Spec     DeploymentSpec    `json:"spec"`
+	Status   *DeploymentStatus `json:"status,omitempty"`
+}
+
+// DeploymentSpec is the spec of a Deployment.
+type DeploymentSpec struct {
+	Replicas *int32          `json:"replicas,omitempty"`
+	Selector LabelSelector   `json:"selector"`
+	Template PodTemplateSpec `json:"template"`
+}
+
+// DeploymentStatus is the status of a Deployment.
+type DeploymentStatus struct {
+	ObservedGeneration int64       `json:"observedGeneration,omitempty"`
+	Replicas           int32       `json:"replicas,omitempty"`
+	UpdatedReplicas    int32       `json:"updatedReplicas,omitempty"`
+	AvailableReplicas  int32       `json:"availableReplicas,omitempty"`
+	Conditions         []Condition `json:"conditions,omitempty"`
+}
+
+// LabelSelector selects objects by their labels.
+type LabelSelector struct {
+	MatchLabels map[string]string `json:"matchLabels"`
+}
+
+// PodTemplateSpec is the template of the pods of a Deployment.
+type PodTemplateSpec struct {
+	Metadata ObjectMeta `json:"metadata"`
+	Spec     PodSpec    `json:"spec"`
+}
+
+// PodSpec is the spec of a pod.
+type PodSpec struct {
+	Containers    []Container `json:"containers"`
+	Volumes       []Volume    `json:"volumes,omitempty"`
+	RestartPolicy string      `json:"restartPolicy,omitempty"`
+}
+
+// Container is a container of a pod.
+type Container struct {
+	Name         string          `json:"name"`
+	Image        string          `json:"image"`
+	Env          []EnvVar        `json:"env,omitempty"`
+	Ports        []ContainerPort `json:"ports,omitempty"`
+	Lifecycle    *Lifecycle      `json:"lifecycle,omitempty"`
+	VolumeMounts []VolumeMount   `json:"volumeMounts,omitempty"`
+}
+
+// EnvVar is an environment variable of a container, whose value is given
+// or read from a field of the pod.
+type EnvVar struct {
+	Name      string        `json:"name"`
+	Value     string        `json:"value,omitempty"`
+	ValueFrom *EnvVarSource `json:"valueFrom,omitempty"`
+}
+
+// EnvVarSource reads the value of an environment variable.
+type EnvVarSource struct {
+	FieldRef *FieldSelector `json:"fieldRef,omitempty"`
+}
+
+// FieldSelector selects a field of the pod, such as status.podIP.
+type FieldSelector struct {
+	FieldPath string `json:"fieldPath"`
+}
+
+// ContainerPort is a port a container listens on.
+type ContainerPort struct {
+	Name          string `json:"name"`
+	ContainerPort int32  `json:"containerPort"`
+}
+
+// Lifecycle are the hooks of a container.
+type Lifecycle struct {
+	PreStop *Handler `json:"preStop,omitempty"`
+}
+
+// Handler is a hook of a container.
+type Handler struct {
+	HTTPGet *HTTPGetAction `json:"httpGet,omitempty"`
+}
+
+// HTTPGetAction gets path on port of the container.
+type HTTPGetAction struct {
+	Path string `json:"path"`
+	Port int32  `json:"port"`
+}
+
+// VolumeMount mounts a volume in a container.
+type VolumeMount struct {
+	Name      string `json:"name"`
+	MountPath string `json:"mountPath"`
+	ReadOnly  bool   `json:"readOnly,omitempty"`
+}
+
+// Volume is a volume of a pod, made of a ConfigMap.
+type Volume struct {
+	Name      string                 `json:"name"`
+	ConfigMap *ConfigMapVolumeSource `json:"configMap,omitempty"`
+}
+
+// ConfigMapVolumeSource names the ConfigMap of a volume.
+type ConfigMapVolumeSource struct {
+	Name string `json:"name"`
+}
+
+// ConfigMap is a v1 ConfigMap.
+type ConfigMap struct {
+	TypeMeta
+	Metadata ObjectMeta        `json:"metadata"`
+	Data     map[string]string `json:"data"`
+}
+
+// Service is a v1 Service.
+type Service struct {
+	TypeMeta
+	Metadata ObjectMeta  `json:"metadata"`
+	Spec     ServiceSpec `json:"spec"`
+}
+
+// ServiceSpec is the spec of a Service.
+type ServiceSpec struct {
+	Selector map[string]string `json:"selector"`
+	Ports    []ServicePort     `json:"ports"`
+}
+
+// ServicePort is a port of a Service.
+type ServicePort struct {
+	Name       string `json:"name"`
+	Port       int32  `json:"port"`
+	TargetPort int32  `json:"targetPort"`
+}
+
+// HorizontalPodAutoscaler is an autoscaling/v2 HorizontalPodAutoscaler.
+type HorizontalPodAutoscaler struct {
+	TypeMeta
+	Metadata ObjectMeta                  `json:"metadata"`
+	Spec     HorizontalPodAutoscalerSpec `json:"spec"`
+}
+
+// HorizontalPodAutoscalerSpec is the spec of a HorizontalPodAutoscaler.
+type HorizontalPodAutoscalerSpec struct {
+	ScaleTargetRef CrossVersionObjectReference `json:"scaleTargetRef"`
+	MinReplicas    *int32                      `json:"minReplicas,omitempty"`
+	MaxReplicas    int32                       `json:"maxReplicas"`
+	Metrics        []MetricSpec                `json:"metrics"`
+}
+
+// CrossVersionObjectReference names the object a HorizontalPodAutoscaler
+// scales.
+type CrossVersionObjectReference struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+}
+
+// MetricSpec is a metric a HorizontalPodAutoscaler scales on, here a
+// metric of the pods, averaged over them.
+type MetricSpec struct {
+	Type string            `json:"type"`
+	Pods *PodsMetricSource `json:"pods,omitempty"`
+}
+
+// PodsMetricSource is a metric of the pods and its target.
+type PodsMetricSource struct {
+	Metric MetricIdentifier `json:"metric"`
+	Target MetricTarget     `json:"target"`
+}
+
+// MetricIdentifier names a metric, and selects its series by label.
+type MetricIdentifier struct {
+	Name     string         `json:"name"`
+	Selector *LabelSelector `json:"selector,omitempty"`
+}
+
+// MetricTarget is the value of a metric a HorizontalPodAutoscaler scales
+// towards.
+type MetricTarget struct {
+	Type         string `json:"type"`
+	AverageValue string `json:"averageValue,omitempty"`
+}
+
+func (d *Deployment) Meta() *ObjectMeta { return &d.Metadata }
+func (d *Deployment) Type() TypeMeta    { return d.TypeMeta }
+func (c *ConfigMap) Meta() *ObjectMeta  { return &c.Metadata }
+func (c *ConfigMap) Type() TypeMeta     { return c.TypeMeta }
+func (s *Service) Meta() *ObjectMeta    { return &s.Metadata }
+func (s *Service) Type() TypeMeta       { return s.TypeMeta }
+
+func (h *HorizontalPodAutoscaler) Meta() *ObjectMeta { return &h.Metadata }
+func (h *HorizontalPodAutoscaler) Type() TypeMeta    { return h.TypeMeta }
@@ -0,0 +1,125 @@
+// Package operator deploys the network functions from three resources of
+// the sa5g.miki-tnt.io group, in place of the manifests of deployments/k8s:
+//
+//   - a CoreNetwork is a UDM and the AUSF calling it,
+//   - a GNodeB is a gNB-CU and its gNB-DUs,
+//   - a Slice is a network slice of a PLMN a CoreNetwork serves, and the
+//     subscribers put on it.
+//
+// The Operator reconciles them: it applies the Deployment, the Service and
+// the ConfigMap of every NF, as the hand written manifests did, with the
+// HorizontalPodAutoscaler of those scaled on their load, and deletes those
+// of the NFs no longer declared. A new version rolls out one NF after
+// the other, in the order they call each other: the UDM before the AUSF,
+// the gNB-CU before the gNB-DUs. The conditions Ready, Progressing and
+// Degraded of the status of every resource tell how far it is.
+//
+//	apiVersion: sa5g.miki-tnt.io/v1alpha1
+//	kind: CoreNetwork
+//	metadata:
+//	  name: core
+//	spec:
+//	  version: "1.1"
+//	  udm: {replicas: 2}
+//	  ausf: {authTTL: 30s}
+//
+// The CRDs and the deployment of the operator are in deployments/operator.
+package operator
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// Resources are resources of the operator.
+type Resources struct {
+	CoreNetworks []CoreNetwork
+	GNodeBs      []GNodeB
+	Slices       []Slice
+}
+
+// Add decodes the resource of JSON b into r.
+func (r *Resources) Add(b []byte) error {
+	var t TypeMeta
+	if err := json.Unmarshal(b, &t); err != nil {
+		return err
+	}
+	if t.APIVersion != APIVersion {
+		return fmt.Errorf("operator: apiVersion %q is not %s", t.APIVersion, APIVersion)
+	}
+	switch t.Kind {
+	case KindCoreNetwork:
+		var cn CoreNetwork
+		if err := json.Unmarshal(b, &cn); err != nil {
+			return err
+		}
+		r.CoreNetworks = append(r.CoreNetworks, cn)
+	case KindGNodeB:
+		var g GNodeB
+		if err := json.Unmarshal(b, &g); err != nil {
+			return err
+		}
+		r.GNodeBs = append(r.GNodeBs, g)
+	case KindSlice:
+		var s Slice
+		if err := json.Unmarshal(b, &s); err != nil {
+			return err
+		}
+		r.Slices = append(r.Slices, s)
+	default:
+		return fmt.Errorf("operator: unknown kind %q", t.Kind)
+	}
+	return nil
+}
+
+// Render returns the objects of the resources at their desired version,
+// as the Operator applies them once they have rolled out. The Slices that
+// are not valid are left out.
+func (r *Resources) Render() []Object {
+	slices, _ := r.slices()
+	var objects []Object
+	for i := range r.CoreNetworks {
+		cn := &r.CoreNetworks[i]
+		for _, w := range RenderCoreNetwork(cn, slices[key(cn.Metadata)]) {
+			objects = append(objects, w.Objects()...)
+		}
+	}
+	for i := range r.GNodeBs {
+		for _, w := range RenderGNodeB(&r.GNodeBs[i]) {
+			objects = append(objects, w.Objects()...)
+		}
+	}
+	return objects
+}
+
+// slices returns the valid Slices of r by the key of their CoreNetwork,
+// sorted by name, and why the others are not, by the key of the Slice.
+func (r *Resources) slices() (map[string][]Slice, map[string]error) {
+	cores := map[string]bool{}
+	for _, cn := range r.CoreNetworks {
+		cores[key(cn.Metadata)] = true
+	}
+	valid, invalid := map[string][]Slice{}, map[string]error{}
+	for _, s := range r.Slices {
+		core := key(ObjectMeta{Namespace: s.Metadata.Namespace, Name: s.Spec.CoreNetwork})
+		if err := s.validate(); err != nil {
+			invalid[key(s.Metadata)] = err
+			continue
+		}
+		if !cores[core] {
+			invalid[key(s.Metadata)] = errNoCoreNetwork
+			continue
+		}
+		valid[core] = append(valid[core], s)
+	}
+	for _, slices := range valid {
+		sort.Slice(slices, func(i, j int) bool { return slices[i].Metadata.Name < slices[j].Metadata.Name })
+	}
+	return valid, invalid
+}
+
+// key identifies an object by its namespace and name.
+func key(m ObjectMeta) string {
+	return m.Namespace + "/" + m.Name
+}
@@ -0,0 +1,473 @@
+package operator
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/plmn"
+)
+
+// DefaultResync is how often the resources are reconciled by default.
+const DefaultResync = 10 * time.Second
+
+// The reasons of the conditions.
+const (
+	ReasonRolledOut          = "RolledOut"
+	ReasonRollingOut         = "RollingOut"
+	ReasonUpgrading          = "Upgrading"
+	ReasonRolloutStuck       = "RolloutStuck"
+	ReasonApplyFailed        = "ApplyFailed"
+	ReasonInvalidSpec        = "InvalidSpec"
+	ReasonCoreNetworkMissing = "CoreNetworkNotFound"
+	ReasonCoreNetworkPending = "CoreNetworkNotReady"
+	ReasonAsExpected         = "AsExpected"
+)
+
+var errNoCoreNetwork = errors.New("the CoreNetwork does not exist")
+
+// validate tells why s is not valid, if it is not.
+func (s *Slice) validate() error {
+	if s.Spec.CoreNetwork == "" {
+		return errors.New("no coreNetwork")
+	}
+	if _, err := plmn.Parse(s.Spec.PLMN); err != nil {
+		return fmt.Errorf("plmn %q: %v", s.Spec.PLMN, err)
+	}
+	if sd := s.Spec.SNSSAI.SD; sd != "" {
+		if _, err := hex.DecodeString(sd); err != nil || len(sd) != 6 {
+			return fmt.Errorf("snssai.sd %q is not 6 hex digits", sd)
+		}
+	}
+	for _, sub := range s.Spec.Subscribers {
+		if sub.SUPI == "" {
+			return errors.New("a subscriber has no supi")
+		}
+	}
+	return nil
+}
+
+// Operator reconciles the resources of the operator.
+type Operator struct {
+	client    *Client
+	logger    log.Logger
+	namespace string
+	now       func() time.Time
+
+	mtx   sync.Mutex
+	stats Stats
+}
+
+// Stats are the counts of the Operator, for the admin port.
+type Stats struct {
+	CoreNetworks int       `json:"corenetworks"`
+	GNodeBs      int       `json:"gnodebs"`
+	Slices       int       `json:"slices"`
+	Ready        int       `json:"ready"`
+	Reconciles   int64     `json:"reconciles"`
+	Errors       int64     `json:"errors"`
+	LastError    string    `json:"last_error,omitempty"`
+	Last         time.Time `json:"last"`
+}
+
+// Option sets an optional parameter of an Operator.
+type Option func(*Operator)
+
+// Namespace restricts the Operator to the resources of namespace; it
+// reconciles those of every namespace by default.
+func Namespace(namespace string) Option {
+	return func(op *Operator) {
+		op.namespace = namespace
+	}
+}
+
+// New returns the operator reconciling the resources through client.
+func New(client *Client, logger log.Logger, options ...Option) *Operator {
+	op := &Operator{client: client, logger: logger, now: time.Now}
+	for _, option := range options {
+		option(op)
+	}
+	return op
+}
+
+// Run reconciles the resources every interval until ctx is done.
+func (op *Operator) Run(ctx context.Context, interval time.Duration) {
+	for {
+		if err := op.Reconcile(ctx); err != nil && ctx.Err() == nil {
+			level.Error(op.logger).Log("reconcile", "failed", "err", err)
+		}
+		select {
+		case <-time.After(interval):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Reconcile reconciles every resource once. It returns the first error,
+// the resources that could not be reconciled being Degraded.
+func (op *Operator) Reconcile(ctx context.Context) error {
+	r, err := op.list(ctx)
+	if err != nil {
+		op.count(nil, 0, err)
+		return err
+	}
+	slices, invalid := r.slices()
+
+	var errs []error
+	ready := 0
+	// the Ready condition of the CoreNetworks, for their Slices
+	cores := map[string]*Condition{}
+	for i := range r.CoreNetworks {
+		cn := &r.CoreNetworks[i]
+		if cn.Metadata.DeletionTimestamp != "" {
+			continue
+		}
+		st, err := op.rollout(ctx, owner{cn.TypeMeta, cn.Metadata}, cn.Spec.Version, cn.Status,
+			RenderCoreNetwork(cn, slices[key(cn.Metadata)]))
+		errs = append(errs, err)
+		errs = append(errs, op.patchStatus(ctx, cn.TypeMeta, cn.Metadata, cn.Status, st))
+		cores[key(cn.Metadata)] = find(st.Conditions, Ready)
+		if isTrue(st.Conditions, Ready) {
+			ready++
+		}
+	}
+	for i := range r.GNodeBs {
+		g := &r.GNodeBs[i]
+		if g.Metadata.DeletionTimestamp != "" {
+			continue
+		}
+		st, err := op.rollout(ctx, owner{g.TypeMeta, g.Metadata}, g.Spec.Version, g.Status, RenderGNodeB(g))
+		errs = append(errs, err)
+		errs = append(errs, op.patchStatus(ctx, g.TypeMeta, g.Metadata, g.Status, st))
+		if isTrue(st.Conditions, Ready) {
+			ready++
+		}
+	}
+	for i := range r.Slices {
+		s := &r.Slices[i]
+		if s.Metadata.DeletionTimestamp != "" {
+			continue
+		}
+		core := key(ObjectMeta{Namespace: s.Metadata.Namespace, Name: s.Spec.CoreNetwork})
+		st := op.sliceStatus(s, invalid[key(s.Metadata)], cores[core])
+		errs = append(errs, op.patchStatus(ctx, s.TypeMeta, s.Metadata, s.Status, st))
+		if isTrue(st.Conditions, Ready) {
+			ready++
+		}
+	}
+	err = first(errs)
+	op.count(r, ready, err)
+	return err
+}
+
+// list lists the resources, in the namespace of the Operator if any.
+func (op *Operator) list(ctx context.Context) (*Resources, error) {
+	r := &Resources{}
+	var cns struct{ Items []CoreNetwork }
+	if err := op.client.List(ctx, APIVersion, KindCoreNetwork, op.namespace, "", &cns); err != nil {
+		return nil, err
+	}
+	var gnbs struct{ Items []GNodeB }
+	if err := op.client.List(ctx, APIVersion, KindGNodeB, op.namespace, "", &gnbs); err != nil {
+		return nil, err
+	}
+	var slices struct{ Items []Slice }
+	if err := op.client.List(ctx, APIVersion, KindSlice, op.namespace, "", &slices); err != nil {
+		return nil, err
+	}
+	// the items of a list may come without their kind
+	for _, cn := range cns.Items {
+		cn.TypeMeta = TypeMeta{APIVersion: APIVersion, Kind: KindCoreNetwork}
+		r.CoreNetworks = append(r.CoreNetworks, cn)
+	}
+	for _, g := range gnbs.Items {
+		g.TypeMeta = TypeMeta{APIVersion: APIVersion, Kind: KindGNodeB}
+		r.GNodeBs = append(r.GNodeBs, g)
+	}
+	for _, s := range slices.Items {
+		s.TypeMeta = TypeMeta{APIVersion: APIVersion, Kind: KindSlice}
+		r.Slices = append(r.Slices, s)
+	}
+	return r, nil
+}
+
+// rollout applies the workloads of o, which wants version, one after the
+// other: a workload keeps the version it runs until those before it have
+// rolled out at version. It then deletes the objects of o no longer
+// rendered, and returns the status of o, which was old.
+func (op *Operator) rollout(ctx context.Context, o owner, version string, old Status, workloads []*Workload) (Status, error) {
+	st := Status{
+		ObservedGeneration: o.Metadata.Generation,
+		Version:            old.Version,
+		Conditions:         append([]Condition(nil), old.Conditions...),
+	}
+	conds := &st.Conditions
+	now := op.now()
+	fail := func(reason string, err error) (Status, error) {
+		op.setCondition(conds, Condition{Type: Ready, Status: "False", Reason: reason, Message: err.Error()}, o, now)
+		op.setCondition(conds, Condition{Type: Progressing, Status: "False", Reason: reason, Message: err.Error()}, o, now)
+		op.setCondition(conds, Condition{Type: Degraded, Status: "True", Reason: reason, Message: err.Error()}, o, now)
+		return st, err
+	}
+
+	// held is the first NF not rolled out at version, which holds the
+	// upgrade of the next ones back
+	var held, stuck string
+	upgrading := false
+	rendered := map[string]bool{}
+	for _, w := range workloads {
+		d := w.Deployment
+		var live Deployment
+		err := op.client.Get(ctx, d.APIVersion, d.Kind, d.Metadata.Namespace, d.Metadata.Name, &live)
+		switch {
+		case IsNotFound(err):
+		case err != nil:
+			return fail(ReasonApplyFailed, err)
+		case held != "":
+			w.SetVersion(live.Metadata.Labels[LabelVersion])
+		}
+		if err == nil && live.Metadata.Labels[LabelVersion] != version {
+			upgrading = true
+		}
+		// the Deployment as applied, with its status
+		var applied Deployment
+		for _, obj := range w.Objects() {
+			rendered[obj.Type().Kind+"/"+obj.Meta().Name] = true
+			var res interface{}
+			if obj == Object(d) {
+				res = &applied
+			}
+			if err := op.client.Apply(ctx, obj, res); err != nil {
+				return fail(ReasonApplyFailed, fmt.Errorf("%s %s: %v", obj.Type().Kind, obj.Meta().Name, err))
+			}
+		}
+		live = applied
+
+		nf := NFStatus{Name: d.Metadata.Name, Version: live.Metadata.Labels[LabelVersion]}
+		if s := live.Status; s != nil {
+			nf.Replicas, nf.Updated, nf.Available = s.Replicas, s.UpdatedReplicas, s.AvailableReplicas
+		}
+		st.NFs = append(st.NFs, nf)
+		if held == "" && (nf.Version != version || !rolledOut(&live)) {
+			held = nf.Name
+		}
+		if stuck == "" && deadlineExceeded(&live) {
+			stuck = nf.Name
+		}
+	}
+	if err := op.prune(ctx, o, rendered); err != nil {
+		return fail(ReasonApplyFailed, err)
+	}
+
+	switch {
+	case held == "":
+		st.Version = version
+		msg := fmt.Sprintf("%d NFs run version %q", len(st.NFs), version)
+		op.setCondition(conds, Condition{Type: Ready, Status: "True", Reason: ReasonRolledOut, Message: msg}, o, now)
+		op.setCondition(conds, Condition{Type: Progressing, Status: "False", Reason: ReasonRolledOut, Message: msg}, o, now)
+	default:
+		msg := "waiting for " + held + " to roll out"
+		reason := ReasonRollingOut
+		if upgrading {
+			reason = ReasonUpgrading
+			msg = fmt.Sprintf("upgrading from %q to %q, %s", old.Version, version, msg)
+		}
+		op.setCondition(conds, Condition{Type: Ready, Status: "False", Reason: reason, Message: msg}, o, now)
+		op.setCondition(conds, Condition{Type: Progressing, Status: "True", Reason: reason, Message: msg}, o, now)
+	}
+	if stuck != "" {
+		msg := stuck + " exceeded its progress deadline"
+		op.setCondition(conds, Condition{Type: Degraded, Status: "True", Reason: ReasonRolloutStuck, Message: msg}, o, now)
+	} else {
+		op.setCondition(conds, Condition{Type: Degraded, Status: "False", Reason: ReasonAsExpected}, o, now)
+	}
+	return st, nil
+}
+
+// rolledOut tells whether every replica of d runs its last spec and is
+// available.
+func rolledOut(d *Deployment) bool {
+	want := int32(1)
+	if d.Spec.Replicas != nil {
+		want = *d.Spec.Replicas
+	}
+	s := d.Status
+	return s != nil && s.ObservedGeneration >= d.Metadata.Generation &&
+		s.UpdatedReplicas == want && s.AvailableReplicas == want && s.Replicas == want
+}
+
+// deadlineExceeded tells whether d failed to roll out in its progress
+// deadline.
+func deadlineExceeded(d *Deployment) bool {
+	if d.Status == nil {
+		return false
+	}
+	c := find(d.Status.Conditions, Progressing)
+	return c != nil && c.Status == "False" && c.Reason == "ProgressDeadlineExceeded"
+}
+
+// managed are the kinds of the objects the operator applies, by API
+// version.
+var managed = []TypeMeta{
+	{APIVersion: "apps/v1", Kind: "Deployment"},
+	{APIVersion: "v1", Kind: "Service"},
+	{APIVersion: "v1", Kind: "ConfigMap"},
+	{APIVersion: "autoscaling/v2", Kind: "HorizontalPodAutoscaler"},
+}
+
+// prune deletes the objects of o that are not rendered, such as those of
+// a gNB-DU removed from its GNodeB.
+func (op *Operator) prune(ctx context.Context, o owner, rendered map[string]bool) error {
+	selector := LabelManagedBy + "=" + ManagedBy + "," + LabelOwner + "=" + o.label()
+	for _, t := range managed {
+		var list struct {
+			Items []struct {
+				Metadata ObjectMeta `json:"metadata"`
+			}
+		}
+		if err := op.client.List(ctx, t.APIVersion, t.Kind, o.Metadata.Namespace, selector, &list); err != nil {
+			return err
+		}
+		for _, item := range list.Items {
+			if rendered[t.Kind+"/"+item.Metadata.Name] {
+				continue
+			}
+			level.Info(op.logger).Log("owner", o.label(), "prune", t.Kind, "name", item.Metadata.Name)
+			if err := op.client.Delete(ctx, t.APIVersion, t.Kind, o.Metadata.Namespace, item.Metadata.Name); err != nil && !IsNotFound(err) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// sliceStatus returns the status of s, which is invalid for err, if not
+// nil, and whose CoreNetwork is as ready as core, nil when it is not
+// reconciled.
+func (op *Operator) sliceStatus(s *Slice, invalid error, core *Condition) Status {
+	st := Status{ObservedGeneration: s.Metadata.Generation, Conditions: append([]Condition(nil), s.Status.Conditions...)}
+	o, now := owner{s.TypeMeta, s.Metadata}, op.now()
+	var ready Condition
+	switch {
+	case invalid == errNoCoreNetwork:
+		ready = Condition{Status: "False", Reason: ReasonCoreNetworkMissing, Message: fmt.Sprintf("CoreNetwork %q: %v", s.Spec.CoreNetwork, invalid)}
+	case invalid != nil:
+		ready = Condition{Status: "False", Reason: ReasonInvalidSpec, Message: invalid.Error()}
+	case core == nil || core.Status != "True":
+		ready = Condition{Status: "False", Reason: ReasonCoreNetworkPending, Message: fmt.Sprintf("CoreNetwork %q is not ready", s.Spec.CoreNetwork)}
+	default:
+		ready = Condition{Status: "True", Reason: ReasonAsExpected, Message: fmt.Sprintf("served by CoreNetwork %q", s.Spec.CoreNetwork)}
+	}
+	ready.Type = Ready
+	op.setCondition(&st.Conditions, ready, o, now)
+	degraded := Condition{Type: Degraded, Status: "False", Reason: ReasonAsExpected}
+	if invalid != nil {
+		degraded = Condition{Type: Degraded, Status: "True", Reason: ready.Reason, Message: ready.Message}
+	}
+	op.setCondition(&st.Conditions, degraded, o, now)
+	return st
+}
+
+// setCondition sets c in conds, keeping the time of its last transition
+// when its status is unchanged, and logs the transitions.
+func (op *Operator) setCondition(conds *[]Condition, c Condition, o owner, now time.Time) {
+	c.ObservedGeneration = o.Metadata.Generation
+	c.LastTransitionTime = now.UTC().Format(time.RFC3339)
+	for i := range *conds {
+		prev := &(*conds)[i]
+		if prev.Type != c.Type {
+			continue
+		}
+		if prev.Status == c.Status {
+			c.LastTransitionTime = prev.LastTransitionTime
+		} else {
+			level.Info(op.logger).Log("owner", o.label(), "condition", c.Type, "status", c.Status, "reason", c.Reason, "message", c.Message)
+		}
+		*prev = c
+		return
+	}
+	*conds = append(*conds, c)
+}
+
+// patchStatus replaces the status of the resource of meta, old, with st
+// unless they are the same.
+func (op *Operator) patchStatus(ctx context.Context, t TypeMeta, meta ObjectMeta, old, st Status) error {
+	if reflect.DeepEqual(normalize(old), normalize(st)) {
+		return nil
+	}
+	return op.client.PatchStatus(ctx, t.APIVersion, t.Kind, meta.Namespace, meta.Name, st)
+}
+
+// normalize returns st as decoded from the API server.
+func normalize(st Status) Status {
+	b, _ := json.Marshal(st)
+	var n Status
+	json.Unmarshal(b, &n)
+	return n
+}
+
+func find(conds []Condition, t string) *Condition {
+	for i := range conds {
+		if conds[i].Type == t {
+			return &conds[i]
+		}
+	}
+	return nil
+}
+
+func isTrue(conds []Condition, t string) bool {
+	c := find(conds, t)
+	return c != nil && c.Status == "True"
+}
+
+// first returns the first error of errs that is not nil, with the number
+// of the others.
+func first(errs []error) error {
+	var msgs []string
+	for _, err := range errs {
+		if err != nil {
+			msgs = append(msgs, err.Error())
+		}
+	}
+	switch len(msgs) {
+	case 0:
+		return nil
+	case 1:
+		return errors.New(msgs[0])
+	}
+	return fmt.Errorf("%s (and %d more errors)", msgs[0], len(msgs)-1)
+}
+
+func (op *Operator) count(r *Resources, ready int, err error) {
+	op.mtx.Lock()
+	defer op.mtx.Unlock()
+	op.stats.Reconciles++
+	op.stats.Last = op.now()
+	op.stats.LastError = ""
+	if err != nil {
+		op.stats.Errors++
+		op.stats.LastError = err.Error()
+	}
+	if r != nil {
+		op.stats.CoreNetworks = len(r.CoreNetworks)
+		op.stats.GNodeBs = len(r.GNodeBs)
+		op.stats.Slices = len(r.Slices)
+		op.stats.Ready = ready
+	}
+}
+
+// Stats returns the counts of the Operator, for the admin port.
+func (op *Operator) Stats() interface{} {
+	op.mtx.Lock()
+	defer op.mtx.Unlock()
+	return op.stats
+}
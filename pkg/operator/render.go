@@ -0,0 +1,451 @@
+package operator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/autoscale"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/drain"
+)
+
+// The labels and annotations the operator sets.
+const (
+	LabelManagedBy = "app.kubernetes.io/managed-by"
+	LabelName      = "app.kubernetes.io/name"
+	LabelInstance  = "app.kubernetes.io/instance"
+	LabelVersion   = "app.kubernetes.io/version"
+	// LabelOwner is the resource an object was rendered for, as
+	// <kind>.<name> in lower case.
+	LabelOwner = Group + "/owner"
+	// AnnotationConfigHash rolls the pods out when their ConfigMap changes.
+	AnnotationConfigHash = Group + "/config-hash"
+	// ManagedBy is the value of LabelManagedBy, and the field manager of
+	// the objects applied.
+	ManagedBy = "sa5g-operator"
+)
+
+// zipkinURL is the Zipkin collector, through the upstream of the sidecar.
+const zipkinURL = "http://localhost:9411/api/v2/spans"
+
+// kind is a kind of NF: its name, which names its image and prefixes its
+// environment variables, and the ports its sidecar proxies, as in
+// deployments/k8s.
+type kind struct {
+	name                  string
+	http, grpc, adminPort int32
+}
+
+var (
+	kindUDM   = kind{"udm", 6020, 6021, 6022}
+	kindAUSF  = kind{"ausf", 5020, 5021, 5022}
+	kindGNBCU = kind{"gnbcu", 4020, 4021, 4022}
+	kindGNBDU = kind{"gnbdu", 4120, 4121, 4122}
+)
+
+// env returns the name of the environment variable of the NF of its own.
+func (k kind) env(suffix string) string {
+	return "QS_" + strings.ToUpper(k.name) + "_" + suffix
+}
+
+// upstream returns the upstream of the NF named name, as the sidecars of
+// the NFs calling it declare it.
+func (k kind) upstream(name string) string {
+	return name + ":" + port(k.grpc)
+}
+
+// local returns the gRPC address of the NF of kind k as the NFs calling it
+// reach it, through their sidecar.
+func (k kind) local() string {
+	return "localhost:" + port(k.grpc)
+}
+
+func port(p int32) string {
+	return strconv.Itoa(int(p))
+}
+
+// Workload is the Deployment of an NF, with its Service, ConfigMap and
+// HorizontalPodAutoscaler.
+type Workload struct {
+	Deployment *Deployment
+	Service    *Service
+	ConfigMap  *ConfigMap
+	Autoscaler *HorizontalPodAutoscaler
+	// repository is the image of the Deployment, without the tag.
+	repository string
+}
+
+// Objects returns the objects of w, the ConfigMap first and the
+// HorizontalPodAutoscaler last.
+func (w *Workload) Objects() []Object {
+	var objects []Object
+	if w.ConfigMap != nil {
+		objects = append(objects, w.ConfigMap)
+	}
+	objects = append(objects, w.Service, w.Deployment)
+	if w.Autoscaler != nil {
+		objects = append(objects, w.Autoscaler)
+	}
+	return objects
+}
+
+// Version returns the version the Deployment of w runs.
+func (w *Workload) Version() string {
+	return w.Deployment.Metadata.Labels[LabelVersion]
+}
+
+// SetVersion makes the Deployment of w run version, which an upgrade holds
+// back until the NFs before it have rolled out.
+func (w *Workload) SetVersion(version string) {
+	w.Deployment.Spec.Template.Spec.Containers[0].Image = image(w.repository, version)
+	setVersion(&w.Deployment.Metadata, version)
+	setVersion(&w.Deployment.Spec.Template.Metadata, version)
+}
+
+func setVersion(m *ObjectMeta, version string) {
+	if version == "" {
+		delete(m.Labels, LabelVersion)
+		return
+	}
+	m.Labels[LabelVersion] = version
+}
+
+func image(repository, version string) string {
+	if version == "" {
+		return repository
+	}
+	return repository + ":" + version
+}
+
+// owner is the resource the objects are rendered for.
+type owner struct {
+	TypeMeta
+	Metadata ObjectMeta
+}
+
+// label returns the LabelOwner of the objects of o.
+func (o owner) label() string {
+	return strings.ToLower(o.Kind) + "." + o.Metadata.Name
+}
+
+// meta returns the metadata of the object name of o, of the NF of kind k.
+// The object is owned by o once o has been created, and has a UID.
+func (o owner) meta(name string, k kind) ObjectMeta {
+	m := ObjectMeta{
+		Name:      name,
+		Namespace: o.Metadata.Namespace,
+		Labels: map[string]string{
+			"app":          name,
+			LabelName:      k.name,
+			LabelInstance:  o.Metadata.Name,
+			LabelManagedBy: ManagedBy,
+			LabelOwner:     o.label(),
+		},
+	}
+	if o.Metadata.UID != "" {
+		m.OwnerReferences = []OwnerReference{{
+			APIVersion:         o.APIVersion,
+			Kind:               o.Kind,
+			Name:               o.Metadata.Name,
+			UID:                o.Metadata.UID,
+			Controller:         true,
+			BlockOwnerDeletion: true,
+		}}
+	}
+	return m
+}
+
+// nf is an NF to render.
+type nf struct {
+	kind kind
+	// name names the Deployment, the Service and the service of the
+	// sidecar.
+	name      string
+	version   string
+	prefix    string
+	spec      NFSpec
+	env       []EnvVar
+	upstreams []string
+	// files are the files of the ConfigMap of the NF, mounted in
+	// /etc/<kind>.
+	files map[string]string
+	// autoscaling, if not nil, scales the NF in place of spec.Replicas.
+	autoscaling *Autoscaling
+}
+
+// render renders n for o.
+func (o owner) render(n nf) *Workload {
+	prefix := n.prefix
+	if prefix == "" {
+		prefix = DefaultImagePrefix
+	}
+	w := &Workload{repository: prefix + "-" + n.kind.name}
+	k := n.kind
+
+	w.Service = &Service{
+		TypeMeta: TypeMeta{APIVersion: "v1", Kind: "Service"},
+		Metadata: o.meta(n.name, k),
+		Spec: ServiceSpec{
+			Selector: map[string]string{"app": n.name},
+			Ports: []ServicePort{
+				{Name: "http", Port: k.http, TargetPort: k.http},
+				{Name: "grpc", Port: k.grpc, TargetPort: k.grpc},
+				{Name: "admin", Port: k.adminPort, TargetPort: k.adminPort},
+			},
+		},
+	}
+
+	logLevel := n.spec.LogLevel
+	if logLevel == "" {
+		logLevel = "info"
+	}
+	env := []EnvVar{
+		{Name: k.env("GRPC_PORT"), Value: port(k.grpc)},
+		{Name: k.env("HTTP_PORT"), Value: port(k.http)},
+		{Name: k.env("ADMIN_PORT"), Value: port(k.adminPort)},
+		{Name: k.env("LOG_LEVEL"), Value: logLevel},
+	}
+	env = append(env, n.env...)
+	// bootstrap.EnvZipkinV2URL, bootstrap importing this package for its
+	// discovery of the pods
+	env = append(env, EnvVar{Name: "QS_ZIPKIN_V2_URL", Value: zipkinURL})
+	env = append(env, n.spec.Env...)
+	container := Container{
+		Name:  k.name,
+		Image: image(w.repository, n.version),
+		Env:   env,
+		Ports: []ContainerPort{
+			{Name: "http", ContainerPort: k.http},
+			{Name: "grpc", ContainerPort: k.grpc},
+			{Name: "admin", ContainerPort: k.adminPort},
+		},
+		Lifecycle: &Lifecycle{PreStop: &Handler{
+			HTTPGet: &HTTPGetAction{Path: drain.Path, Port: k.adminPort},
+		}},
+	}
+
+	template := PodTemplateSpec{
+		Metadata: ObjectMeta{
+			Labels: map[string]string{
+				"app":         n.name,
+				LabelName:     k.name,
+				LabelInstance: o.Metadata.Name,
+			},
+			Annotations: map[string]string{
+				"consul.hashicorp.com/connect-inject":           "true",
+				"consul.hashicorp.com/connect-service":          n.name,
+				"consul.hashicorp.com/connect-service-port":     port(k.grpc),
+				"consul.hashicorp.com/connect-service-protocol": "grpc",
+				"consul.hashicorp.com/connect-service-upstreams": strings.Join(
+					append(append([]string(nil), n.upstreams...), "zipkin:9411"), ","),
+			},
+		},
+		Spec: PodSpec{RestartPolicy: "Always"},
+	}
+	if len(n.files) != 0 {
+		name := n.name + "-config"
+		w.ConfigMap = &ConfigMap{
+			TypeMeta: TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+			Metadata: o.meta(name, k),
+			Data:     n.files,
+		}
+		container.VolumeMounts = []VolumeMount{{Name: "config", MountPath: "/etc/" + k.name, ReadOnly: true}}
+		template.Spec.Volumes = []Volume{{Name: "config", ConfigMap: &ConfigMapVolumeSource{Name: name}}}
+		template.Metadata.Annotations[AnnotationConfigHash] = hash(n.files)
+	}
+	template.Spec.Containers = []Container{container}
+
+	w.Deployment = &Deployment{
+		TypeMeta: TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+		Metadata: o.meta(n.name, k),
+		Spec: DeploymentSpec{
+			Selector: LabelSelector{MatchLabels: map[string]string{"app": n.name}},
+			Template: template,
+		},
+	}
+	if a := n.autoscaling; a != nil {
+		// the replicas are left to the HorizontalPodAutoscaler
+		w.Autoscaler = o.autoscaler(n.name, k, a)
+	} else {
+		replicas := int32(1)
+		if n.spec.Replicas != nil {
+			replicas = *n.spec.Replicas
+		}
+		w.Deployment.Spec.Replicas = &replicas
+	}
+	w.SetVersion(n.version)
+	return w
+}
+
+// autoscaler returns the HorizontalPodAutoscaler of the Deployment name of
+// o, scaling it on the procedures its pods run per second and, if a
+// target is set, on the requests they hold queued.
+func (o owner) autoscaler(name string, k kind, a *Autoscaling) *HorizontalPodAutoscaler {
+	metrics := []MetricSpec{{
+		Type: "Pods",
+		Pods: &PodsMetricSource{
+			Metric: MetricIdentifier{Name: autoscale.ProcedureRate},
+			Target: MetricTarget{Type: "AverageValue", AverageValue: a.TargetRate},
+		},
+	}}
+	if a.TargetQueueDepth != "" {
+		metrics = append(metrics, MetricSpec{
+			Type: "Pods",
+			Pods: &PodsMetricSource{
+				Metric: MetricIdentifier{
+					Name:     autoscale.QueueDepth,
+					Selector: &LabelSelector{MatchLabels: map[string]string{"queue": "admission"}},
+				},
+				Target: MetricTarget{Type: "AverageValue", AverageValue: a.TargetQueueDepth},
+			},
+		})
+	}
+	return &HorizontalPodAutoscaler{
+		TypeMeta: TypeMeta{APIVersion: "autoscaling/v2", Kind: "HorizontalPodAutoscaler"},
+		Metadata: o.meta(name, k),
+		Spec: HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: CrossVersionObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Name: name},
+			MinReplicas:    a.MinReplicas,
+			MaxReplicas:    a.MaxReplicas,
+			Metrics:        metrics,
+		},
+	}
+}
+
+// hash returns a digest of files.
+func hash(files map[string]string) string {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	h := sha256.New()
+	for _, name := range names {
+		h.Write([]byte(name))
+		h.Write([]byte{0})
+		h.Write([]byte(files[name]))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// RenderCoreNetwork returns the workloads of cn, the UDM then the AUSF, at
+// the desired version. The NFs serve the PLMNs of slices, whose
+// subscribers are provisioned in the UDM; slices are those of cn, and
+// valid.
+func RenderCoreNetwork(cn *CoreNetwork, slices []Slice) []*Workload {
+	o := owner{cn.TypeMeta, cn.Metadata}
+	name := cn.Metadata.Name
+	udmName, ausfName := name+"-udm", name+"-ausf"
+
+	subscribers := append([]Subscriber(nil), cn.Spec.UDM.Subscribers...)
+	served := map[string]bool{}
+	for _, s := range slices {
+		served[s.Spec.PLMN] = true
+		snssai := s.Spec.SNSSAI
+		for _, sub := range s.Spec.Subscribers {
+			if sub.DefaultSNSSAI == nil {
+				sub.DefaultSNSSAI = &snssai
+			}
+			subscribers = append(subscribers, sub)
+		}
+	}
+	var plmns []string
+	for id := range served {
+		plmns = append(plmns, id)
+	}
+	sort.Strings(plmns)
+	if subscribers == nil {
+		subscribers = []Subscriber{}
+	}
+	b, _ := json.MarshalIndent(subscribers, "", "  ")
+
+	udmEnv := []EnvVar{
+		// the AUSFs drain first, their last calls being served
+		{Name: kindUDM.env("DRAIN_AFTER"), Value: kindAUSF.local()},
+		{Name: kindUDM.env("SUBSCRIBERS_FILE"), Value: "/etc/udm/subscribers.json"},
+	}
+	ausfEnv := []EnvVar{{Name: "QS_UDM_URL", Value: kindUDM.local()}}
+	if ttl := cn.Spec.AUSF.AuthTTL; ttl != "" {
+		ausfEnv = append(ausfEnv, EnvVar{Name: kindAUSF.env("AUTH_TTL"), Value: ttl})
+	}
+	if len(plmns) != 0 {
+		list := strings.Join(plmns, ",")
+		udmEnv = append(udmEnv, EnvVar{Name: kindUDM.env("SERVED_PLMNS"), Value: list})
+		ausfEnv = append(ausfEnv, EnvVar{Name: kindAUSF.env("SERVED_PLMNS"), Value: list})
+	}
+
+	return []*Workload{
+		o.render(nf{
+			kind:        kindUDM,
+			name:        udmName,
+			version:     cn.Spec.Version,
+			prefix:      cn.Spec.ImagePrefix,
+			spec:        cn.Spec.UDM.NFSpec,
+			env:         udmEnv,
+			upstreams:   []string{kindAUSF.upstream(ausfName)},
+			files:       map[string]string{"subscribers.json": string(b) + "\n"},
+			autoscaling: cn.Spec.UDM.Autoscaling,
+		}),
+		o.render(nf{
+			kind:        kindAUSF,
+			name:        ausfName,
+			version:     cn.Spec.Version,
+			prefix:      cn.Spec.ImagePrefix,
+			spec:        cn.Spec.AUSF.NFSpec,
+			env:         ausfEnv,
+			upstreams:   []string{kindUDM.upstream(udmName)},
+			autoscaling: cn.Spec.AUSF.Autoscaling,
+		}),
+	}
+}
+
+// RenderGNodeB returns the workloads of g, the gNB-CU then the gNB-DUs,
+// at the desired version.
+func RenderGNodeB(g *GNodeB) []*Workload {
+	o := owner{g.TypeMeta, g.Metadata}
+	cuName := g.Metadata.Name + "-cu"
+	var cuEnv []EnvVar
+	if g.Spec.GNBID != "" {
+		cuEnv = append(cuEnv, EnvVar{Name: kindGNBCU.env("GNB_ID"), Value: g.Spec.GNBID})
+	}
+	workloads := []*Workload{o.render(nf{
+		kind:    kindGNBCU,
+		name:    cuName,
+		version: g.Spec.Version,
+		prefix:  g.Spec.ImagePrefix,
+		spec:    g.Spec.CU,
+		env:     cuEnv,
+	})}
+	for _, du := range g.Spec.DUs {
+		env := []EnvVar{
+			{Name: kindGNBDU.env("ID"), Value: strconv.FormatUint(du.ID, 10)},
+			{Name: kindGNBDU.env("CU_URL"), Value: kindGNBCU.local()},
+			// the gNB-CU connects back to the pod for its end of the F1
+			{Name: kindGNBDU.env("SERVICE_HOST"), ValueFrom: &EnvVarSource{FieldRef: &FieldSelector{FieldPath: "status.podIP"}}},
+		}
+		if g.Spec.GNBID != "" {
+			env = append(env, EnvVar{Name: kindGNBDU.env("GNB_ID"), Value: g.Spec.GNBID})
+		}
+		var files map[string]string
+		if len(du.Cells) != 0 {
+			b, _ := json.MarshalIndent(du.Cells, "", "  ")
+			files = map[string]string{"cells.json": string(b) + "\n"}
+			env = append(env, EnvVar{Name: kindGNBDU.env("CELLS_FILE"), Value: "/etc/gnbdu/cells.json"})
+		}
+		workloads = append(workloads, o.render(nf{
+			kind:      kindGNBDU,
+			name:      g.Metadata.Name + "-du-" + du.Name,
+			version:   g.Spec.Version,
+			prefix:    g.Spec.ImagePrefix,
+			spec:      du.NFSpec,
+			env:       env,
+			upstreams: []string{kindGNBCU.upstream(cuName)},
+			files:     files,
+		}))
+	}
+	return workloads
+}
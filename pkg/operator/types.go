@@ -0,0 +1,200 @@
+package operator
+
+import (
+	"encoding/json"
+)
+
+// The API group of the resources of the operator.
+const (
+	Group      = "sa5g.miki-tnt.io"
+	Version    = "v1alpha1"
+	APIVersion = Group + "/" + Version
+)
+
+// The kinds of the resources of the operator.
+const (
+	KindCoreNetwork = "CoreNetwork"
+	KindGNodeB      = "GNodeB"
+	KindSlice       = "Slice"
+)
+
+// DefaultImagePrefix prefixes the images of the NFs, which are named
+// <prefix>-<nf>:<version>.
+const DefaultImagePrefix = "miki-tnt/sa5g-go-usvc-k8s"
+
+// CoreNetwork is a 5G core: a UDM and the AUSF calling it.
+type CoreNetwork struct {
+	TypeMeta
+	Metadata ObjectMeta      `json:"metadata"`
+	Spec     CoreNetworkSpec `json:"spec"`
+	Status   Status          `json:"status,omitempty"`
+}
+
+// CoreNetworkSpec is the desired state of a CoreNetwork.
+type CoreNetworkSpec struct {
+	// Version is the tag of the images of the NFs. Changing it upgrades the
+	// UDM, then the AUSF once the UDM has rolled out.
+	Version     string   `json:"version,omitempty"`
+	ImagePrefix string   `json:"imagePrefix,omitempty"`
+	UDM         UDMSpec  `json:"udm,omitempty"`
+	AUSF        AUSFSpec `json:"ausf,omitempty"`
+}
+
+// NFSpec is what every NF of a resource is given.
+type NFSpec struct {
+	// Replicas is 1 by default.
+	Replicas *int32 `json:"replicas,omitempty"`
+	// LogLevel is the QS_<NF>_LOG_LEVEL, info by default.
+	LogLevel string `json:"logLevel,omitempty"`
+	// Env is added to the environment of the NF, after the variables the
+	// operator sets.
+	Env []EnvVar `json:"env,omitempty"`
+}
+
+// Autoscaling scales an NF on its signalling load, as exported by package
+// autoscale and exposed as custom metrics by the Prometheus adapter of
+// deployments/autoscaling, in place of its Replicas.
+type Autoscaling struct {
+	// MinReplicas is 1 by default.
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+	MaxReplicas int32  `json:"maxReplicas"`
+	// TargetRate is the procedures per second each pod is to run on
+	// average, a quantity such as 50 or 2500m.
+	TargetRate string `json:"targetRate"`
+	// TargetQueueDepth, if set, is the requests each pod is to hold queued
+	// by its admission on average, which takes a QS_<NF>_MAX_CONCURRENT.
+	TargetQueueDepth string `json:"targetQueueDepth,omitempty"`
+}
+
+// UDMSpec is the desired state of the UDM of a CoreNetwork.
+type UDMSpec struct {
+	NFSpec      `json:",inline"`
+	Autoscaling *Autoscaling `json:"autoscaling,omitempty"`
+	// Subscribers are provisioned at startup, along with those of the
+	// Slices of the CoreNetwork.
+	Subscribers []Subscriber `json:"subscribers,omitempty"`
+}
+
+// AUSFSpec is the desired state of the AUSF of a CoreNetwork.
+type AUSFSpec struct {
+	NFSpec      `json:",inline"`
+	Autoscaling *Autoscaling `json:"autoscaling,omitempty"`
+	// AuthTTL is the QS_AUSF_AUTH_TTL, such as 30s.
+	AuthTTL string `json:"authTTL,omitempty"`
+}
+
+// Subscriber is a subscriber provisioned in the UDM, as in its
+// QS_UDM_SUBSCRIBERS_FILE.
+type Subscriber struct {
+	SUPI          string  `json:"supi"`
+	K             string  `json:"k,omitempty"`
+	OP            string  `json:"op,omitempty"`
+	OPc           string  `json:"opc,omitempty"`
+	AMF           string  `json:"amf,omitempty"`
+	SQN           string  `json:"sqn,omitempty"`
+	DefaultSNSSAI *SNSSAI `json:"default_snssai,omitempty"`
+}
+
+// SNSSAI identifies a network slice (TS 23.003 28.4.2).
+type SNSSAI struct {
+	SST uint8  `json:"sst"`
+	SD  string `json:"sd,omitempty"`
+}
+
+// GNodeB is a split gNB: a gNB-CU and its gNB-DUs.
+type GNodeB struct {
+	TypeMeta
+	Metadata ObjectMeta `json:"metadata"`
+	Spec     GNodeBSpec `json:"spec"`
+	Status   Status     `json:"status,omitempty"`
+}
+
+// GNodeBSpec is the desired state of a GNodeB.
+type GNodeBSpec struct {
+	// Version is the tag of the images of the NFs. Changing it upgrades the
+	// gNB-CU, then the gNB-DUs once the gNB-CU has rolled out.
+	Version     string `json:"version,omitempty"`
+	ImagePrefix string `json:"imagePrefix,omitempty"`
+	// GNBID is the gNB ID of the gNB, written ID/LENGTH such as 1/24, which
+	// the NR cell identities of its cells start with.
+	GNBID string   `json:"gnbId,omitempty"`
+	CU    NFSpec   `json:"cu,omitempty"`
+	DUs   []DUSpec `json:"dus,omitempty"`
+}
+
+// DUSpec is the desired state of a gNB-DU of a GNodeB.
+type DUSpec struct {
+	// Name names the gNB-DU within the GNodeB.
+	Name   string `json:"name"`
+	NFSpec `json:",inline"`
+	// ID is the QS_GNBDU_ID, unique among the gNB-DUs of the gNB-CU.
+	ID uint64 `json:"id"`
+	// Cells are the cells of the QS_GNBDU_CELLS_FILE, as read by package
+	// cell.
+	Cells []json.RawMessage `json:"cells,omitempty"`
+}
+
+// Slice is a network slice of a PLMN served by a CoreNetwork, and the
+// subscribers put on it.
+type Slice struct {
+	TypeMeta
+	Metadata ObjectMeta `json:"metadata"`
+	Spec     SliceSpec  `json:"spec"`
+	Status   Status     `json:"status,omitempty"`
+}
+
+// SliceSpec is the desired state of a Slice.
+type SliceSpec struct {
+	// CoreNetwork is the name of the CoreNetwork serving the slice, in the
+	// namespace of the Slice.
+	CoreNetwork string `json:"coreNetwork"`
+	// PLMN is written MCC-MNC, such as 208-93. The NFs of the CoreNetwork
+	// serve the PLMNs of its Slices.
+	PLMN   string `json:"plmn"`
+	SNSSAI SNSSAI `json:"snssai"`
+	// Subscribers are provisioned in the UDM of the CoreNetwork, on the
+	// slice unless they name their own.
+	Subscribers []Subscriber `json:"subscribers,omitempty"`
+}
+
+// Status is the observed state of a resource.
+type Status struct {
+	ObservedGeneration int64       `json:"observedGeneration,omitempty"`
+	Conditions         []Condition `json:"conditions,omitempty"`
+	// Version is the version every NF of the resource runs.
+	Version string `json:"version,omitempty"`
+	// NFs is the state of the NFs of the resource, by Deployment name.
+	NFs []NFStatus `json:"nfs,omitempty"`
+}
+
+// NFStatus is the observed state of the Deployment of an NF.
+type NFStatus struct {
+	Name      string `json:"name"`
+	Version   string `json:"version,omitempty"`
+	Replicas  int32  `json:"replicas"`
+	Updated   int32  `json:"updated"`
+	Available int32  `json:"available"`
+}
+
+// The condition types.
+const (
+	// Ready is true once every NF runs the desired version and is
+	// available.
+	Ready = "Ready"
+	// Progressing is true while the NFs roll out.
+	Progressing = "Progressing"
+	// Degraded is true when the resource cannot be reconciled, or an NF
+	// cannot roll out.
+	Degraded = "Degraded"
+)
+
+// Condition is a condition of the status of a resource, as in the
+// conditions of the Kubernetes API.
+type Condition struct {
+	Type               string `json:"type"`
+	Status             string `json:"status"`
+	Reason             string `json:"reason,omitempty"`
+	Message            string `json:"message,omitempty"`
+	LastTransitionTime string `json:"lastTransitionTime,omitempty"`
+	ObservedGeneration int64  `json:"observedGeneration,omitempty"`
+}
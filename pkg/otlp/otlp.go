@@ -0,0 +1,458 @@
+// Package otlp pushes go-kit metrics to an OpenTelemetry collector over
+// OTLP/HTTP, in the JSON encoding of OTLP, for the deployments that collect
+// their metrics with the collector rather than by scraping the admin port.
+// A Provider makes the counters, gauges and histograms, and PushLoop sends
+// all of them to the collector every tick.
+//
+// The counters and histograms are cumulative, from the start of the
+// Provider, as the Prometheus ones are, so that a push lost is not a loss
+// of counts. The histograms take exemplars as those of pkg/exemplar do,
+// the trace of the last observation of every bucket since the last push.
+package otlp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/go-kit/kit/metrics"
+)
+
+// MetricsPath is the path of the metrics on a collector, appended to an
+// endpoint without a path.
+const MetricsPath = "/v1/metrics"
+
+// DefaultTimeout is the timeout of a push by default.
+const DefaultTimeout = 5 * time.Second
+
+// scope is the instrumentation scope of the metrics.
+const scope = "github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/otlp"
+
+// The aggregation temporality of the sums and histograms, cumulative.
+const cumulative = 2
+
+// Option sets an optional parameter of a Provider.
+type Option func(*Provider)
+
+// Resource sets the attributes of the resource of the metrics, pairs of
+// key and value, such as "service.name" and the name of the NF.
+func Resource(keyvals ...string) Option {
+	return func(p *Provider) { p.resource = attributes(keyvals) }
+}
+
+// Timeout bounds every push.
+func Timeout(d time.Duration) Option {
+	return func(p *Provider) { p.client.Timeout = d }
+}
+
+// Logger sets the logger of the failed pushes.
+func Logger(logger log.Logger) Option {
+	return func(p *Provider) { p.logger = logger }
+}
+
+// Provider makes metrics and pushes them to a collector. It is safe for
+// concurrent use.
+type Provider struct {
+	url      string
+	resource []keyValue
+	client   *http.Client
+	logger   log.Logger
+	start    time.Time
+
+	mtx     sync.Mutex
+	metrics []*metric
+}
+
+// New returns the Provider pushing to the collector at endpoint, such as
+// http://otel-collector:4318.
+func New(endpoint string, options ...Option) (*Provider, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("otlp: %v", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("otlp: endpoint %s is not an http or https URL", endpoint)
+	}
+	if u.Path == "" || u.Path == "/" {
+		u.Path = MetricsPath
+	}
+	p := &Provider{
+		url:    u.String(),
+		client: &http.Client{Timeout: DefaultTimeout},
+		logger: log.NewNopLogger(),
+		start:  time.Now(),
+	}
+	for _, o := range options {
+		o(p)
+	}
+	return p, nil
+}
+
+// The kinds of the metrics.
+const (
+	kindSum       = "sum"
+	kindGauge     = "gauge"
+	kindHistogram = "histogram"
+)
+
+// metric is a metric of a Provider, and its series by label values.
+type metric struct {
+	name, help, kind string
+	buckets          []float64
+
+	mtx    sync.Mutex
+	series map[string]*series
+	keys   []string
+}
+
+// series are the values of a set of label values.
+type series struct {
+	labelValues []string
+	value       float64
+	// counts holds the observations of each bucket, not cumulated, the
+	// last one being the +Inf bucket.
+	counts    []uint64
+	count     uint64
+	sum       float64
+	exemplars []*exemplarValue
+}
+
+type exemplarValue struct {
+	traceID string
+	value   float64
+	time    time.Time
+}
+
+func (p *Provider) newMetric(name, help, kind string, buckets []float64) *metric {
+	m := &metric{name: name, help: help, kind: kind, buckets: buckets, series: map[string]*series{}}
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	p.metrics = append(p.metrics, m)
+	return m
+}
+
+// seriesLocked returns the series of labelValues, the mtx of m held.
+func (m *metric) seriesLocked(labelValues []string) *series {
+	key := strings.Join(labelValues, "\xff")
+	s, ok := m.series[key]
+	if !ok {
+		s = &series{labelValues: labelValues}
+		if m.kind == kindHistogram {
+			s.counts = make([]uint64, len(m.buckets)+1)
+			s.exemplars = make([]*exemplarValue, len(m.buckets)+1)
+		}
+		m.series[key] = s
+		m.keys = append(m.keys, key)
+	}
+	return s
+}
+
+// withLabels returns labelValues followed by more, pairs of label and value.
+func withLabels(labelValues []string, more []string) []string {
+	if len(more)%2 != 0 {
+		more = append(more, "unknown")
+	}
+	return append(append([]string(nil), labelValues...), more...)
+}
+
+// Counter is a monotonic sum.
+type Counter struct {
+	m           *metric
+	labelValues []string
+}
+
+// NewCounter returns the counter name, described by help.
+func (p *Provider) NewCounter(name, help string) *Counter {
+	return &Counter{m: p.newMetric(name, help, kindSum, nil)}
+}
+
+// With returns the counter of the label values of c followed by
+// labelValues.
+func (c *Counter) With(labelValues ...string) metrics.Counter {
+	return &Counter{m: c.m, labelValues: withLabels(c.labelValues, labelValues)}
+}
+
+// Add adds delta to the counter.
+func (c *Counter) Add(delta float64) {
+	c.m.mtx.Lock()
+	defer c.m.mtx.Unlock()
+	c.m.seriesLocked(c.labelValues).value += delta
+}
+
+// Gauge is a gauge.
+type Gauge struct {
+	m           *metric
+	labelValues []string
+}
+
+// NewGauge returns the gauge name, described by help.
+func (p *Provider) NewGauge(name, help string) *Gauge {
+	return &Gauge{m: p.newMetric(name, help, kindGauge, nil)}
+}
+
+// With returns the gauge of the label values of g followed by labelValues.
+func (g *Gauge) With(labelValues ...string) metrics.Gauge {
+	return &Gauge{m: g.m, labelValues: withLabels(g.labelValues, labelValues)}
+}
+
+// Set sets the gauge to value.
+func (g *Gauge) Set(value float64) {
+	g.m.mtx.Lock()
+	defer g.m.mtx.Unlock()
+	g.m.seriesLocked(g.labelValues).value = value
+}
+
+// Add adds delta to the gauge.
+func (g *Gauge) Add(delta float64) {
+	g.m.mtx.Lock()
+	defer g.m.mtx.Unlock()
+	g.m.seriesLocked(g.labelValues).value += delta
+}
+
+// Histogram is a histogram of explicit buckets, which takes exemplars (see
+// exemplar.Observer).
+type Histogram struct {
+	m           *metric
+	labelValues []string
+}
+
+// NewHistogram returns the histogram name, described by help, with buckets
+// of the upper bounds buckets, sorted.
+func (p *Provider) NewHistogram(name, help string, buckets []float64) *Histogram {
+	return &Histogram{m: p.newMetric(name, help, kindHistogram, buckets)}
+}
+
+// With returns the histogram of the label values of h followed by
+// labelValues.
+func (h *Histogram) With(labelValues ...string) metrics.Histogram {
+	return &Histogram{m: h.m, labelValues: withLabels(h.labelValues, labelValues)}
+}
+
+// Observe observes value without an exemplar.
+func (h *Histogram) Observe(value float64) {
+	h.observe(value, "")
+}
+
+// ObserveWithExemplar observes value, traceID being the exemplar of its
+// bucket until the next push.
+func (h *Histogram) ObserveWithExemplar(value float64, traceID string) {
+	h.observe(value, traceID)
+}
+
+func (h *Histogram) observe(value float64, traceID string) {
+	i := sort.SearchFloat64s(h.m.buckets, value)
+	h.m.mtx.Lock()
+	defer h.m.mtx.Unlock()
+	s := h.m.seriesLocked(h.labelValues)
+	s.counts[i]++
+	s.count++
+	s.sum += value
+	if traceID != "" {
+		if len(traceID) < 32 {
+			// the trace IDs of OTLP are of 128 bits, Zipkin's may be of 64
+			traceID = strings.Repeat("0", 32-len(traceID)) + traceID
+		}
+		s.exemplars[i] = &exemplarValue{traceID: traceID, value: value, time: time.Now()}
+	}
+}
+
+// PushLoop pushes the metrics on every tick of c, until ctx is done, and a
+// last time then.
+func (p *Provider) PushLoop(ctx context.Context, c <-chan time.Time) {
+	for {
+		select {
+		case <-c:
+		case <-ctx.Done():
+			push, cancel := context.WithTimeout(context.Background(), p.client.Timeout)
+			p.push(push)
+			cancel()
+			return
+		}
+		p.push(ctx)
+	}
+}
+
+func (p *Provider) push(ctx context.Context) {
+	if err := p.Push(ctx); err != nil {
+		level.Warn(p.logger).Log("otlp", p.url, "err", err)
+	}
+}
+
+// Push pushes the metrics to the collector. The exemplars pushed are
+// dropped, pushed or not.
+func (p *Provider) Push(ctx context.Context) error {
+	body, err := json.Marshal(p.export(time.Now()))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := p.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("otlp: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	io.Copy(ioutil.Discard, resp.Body)
+	return nil
+}
+
+// The messages of OTLP, in its JSON encoding: the 64-bit integers are
+// strings, the trace IDs hexadecimal.
+type (
+	exportRequest struct {
+		ResourceMetrics []resourceMetrics `json:"resourceMetrics"`
+	}
+	resourceMetrics struct {
+		Resource     resource       `json:"resource"`
+		ScopeMetrics []scopeMetrics `json:"scopeMetrics"`
+	}
+	resource struct {
+		Attributes []keyValue `json:"attributes"`
+	}
+	scopeMetrics struct {
+		Scope   instrumentationScope `json:"scope"`
+		Metrics []metricData         `json:"metrics"`
+	}
+	instrumentationScope struct {
+		Name string `json:"name"`
+	}
+	metricData struct {
+		Name        string         `json:"name"`
+		Description string         `json:"description,omitempty"`
+		Sum         *sumData       `json:"sum,omitempty"`
+		Gauge       *gaugeData     `json:"gauge,omitempty"`
+		Histogram   *histogramData `json:"histogram,omitempty"`
+	}
+	sumData struct {
+		DataPoints             []numberDataPoint `json:"dataPoints"`
+		AggregationTemporality int               `json:"aggregationTemporality"`
+		IsMonotonic            bool              `json:"isMonotonic"`
+	}
+	gaugeData struct {
+		DataPoints []numberDataPoint `json:"dataPoints"`
+	}
+	histogramData struct {
+		DataPoints             []histogramDataPoint `json:"dataPoints"`
+		AggregationTemporality int                  `json:"aggregationTemporality"`
+	}
+	numberDataPoint struct {
+		Attributes        []keyValue `json:"attributes,omitempty"`
+		StartTimeUnixNano string     `json:"startTimeUnixNano,omitempty"`
+		TimeUnixNano      string     `json:"timeUnixNano"`
+		AsDouble          float64    `json:"asDouble"`
+	}
+	histogramDataPoint struct {
+		Attributes        []keyValue     `json:"attributes,omitempty"`
+		StartTimeUnixNano string         `json:"startTimeUnixNano"`
+		TimeUnixNano      string         `json:"timeUnixNano"`
+		Count             string         `json:"count"`
+		Sum               float64        `json:"sum"`
+		BucketCounts      []string       `json:"bucketCounts"`
+		ExplicitBounds    []float64      `json:"explicitBounds"`
+		Exemplars         []exemplarData `json:"exemplars,omitempty"`
+	}
+	exemplarData struct {
+		TimeUnixNano string  `json:"timeUnixNano"`
+		AsDouble     float64 `json:"asDouble"`
+		TraceID      string  `json:"traceId"`
+	}
+	keyValue struct {
+		Key   string   `json:"key"`
+		Value anyValue `json:"value"`
+	}
+	anyValue struct {
+		StringValue string `json:"stringValue"`
+	}
+)
+
+// attributes returns the attributes of keyvals, pairs of key and value.
+func attributes(keyvals []string) []keyValue {
+	attrs := make([]keyValue, 0, len(keyvals)/2)
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		attrs = append(attrs, keyValue{Key: keyvals[i], Value: anyValue{StringValue: keyvals[i+1]}})
+	}
+	return attrs
+}
+
+func nanos(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
+
+// export returns the request exporting the metrics at now, and drops the
+// exemplars.
+func (p *Provider) export(now time.Time) exportRequest {
+	p.mtx.Lock()
+	ms := append([]*metric(nil), p.metrics...)
+	p.mtx.Unlock()
+	start, at := nanos(p.start), nanos(now)
+	data := make([]metricData, 0, len(ms))
+	for _, m := range ms {
+		d := metricData{Name: m.name, Description: m.help}
+		m.mtx.Lock()
+		if len(m.keys) == 0 {
+			m.mtx.Unlock()
+			continue
+		}
+		switch m.kind {
+		case kindSum:
+			d.Sum = &sumData{AggregationTemporality: cumulative, IsMonotonic: true}
+			for _, k := range m.keys {
+				s := m.series[k]
+				d.Sum.DataPoints = append(d.Sum.DataPoints, numberDataPoint{Attributes: attributes(s.labelValues), StartTimeUnixNano: start, TimeUnixNano: at, AsDouble: s.value})
+			}
+		case kindGauge:
+			d.Gauge = &gaugeData{}
+			for _, k := range m.keys {
+				s := m.series[k]
+				d.Gauge.DataPoints = append(d.Gauge.DataPoints, numberDataPoint{Attributes: attributes(s.labelValues), TimeUnixNano: at, AsDouble: s.value})
+			}
+		case kindHistogram:
+			d.Histogram = &histogramData{AggregationTemporality: cumulative}
+			for _, k := range m.keys {
+				s := m.series[k]
+				dp := histogramDataPoint{
+					Attributes:        attributes(s.labelValues),
+					StartTimeUnixNano: start,
+					TimeUnixNano:      at,
+					Count:             strconv.FormatUint(s.count, 10),
+					Sum:               s.sum,
+					BucketCounts:      make([]string, len(s.counts)),
+					ExplicitBounds:    m.buckets,
+				}
+				for i, n := range s.counts {
+					dp.BucketCounts[i] = strconv.FormatUint(n, 10)
+					if e := s.exemplars[i]; e != nil {
+						dp.Exemplars = append(dp.Exemplars, exemplarData{TimeUnixNano: nanos(e.time), AsDouble: e.value, TraceID: e.traceID})
+						s.exemplars[i] = nil
+					}
+				}
+				d.Histogram.DataPoints = append(d.Histogram.DataPoints, dp)
+			}
+		}
+		m.mtx.Unlock()
+		data = append(data, d)
+	}
+	return exportRequest{ResourceMetrics: []resourceMetrics{{
+		Resource:     resource{Attributes: p.resource},
+		ScopeMetrics: []scopeMetrics{{Scope: instrumentationScope{Name: scope}, Metrics: data}},
+	}}}
+}
@@ -0,0 +1,247 @@
+// Package plmn identifies the PLMN (the operator) a request belongs to, so
+// that a single deployment can host several test operators. The PLMN travels
+// with the request as gRPC metadata or an HTTP header, and services check the
+// subscriber identities they handle against the PLMNs they serve.
+package plmn
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	grpctransport "github.com/go-kit/kit/transport/grpc"
+	httptransport "github.com/go-kit/kit/transport/http"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// MetadataKey is the gRPC metadata key carrying the PLMN of a request,
+// HeaderKey the HTTP header.
+const (
+	MetadataKey = "x-plmn-id"
+	HeaderKey   = "X-Plmn-Id"
+)
+
+var (
+	// ErrInvalid is returned when parsing a malformed PLMN ID.
+	ErrInvalid = errors.New("invalid PLMN ID, want MCC-MNC such as 208-93")
+	// ErrForeign is returned for a request or a subscriber of a PLMN that is
+	// not served.
+	ErrForeign = status.Error(codes.PermissionDenied, "PLMN not served")
+)
+
+// ID is a PLMN ID: a 3 digit MCC and a 2 or 3 digit MNC.
+type ID struct {
+	MCC string
+	MNC string
+}
+
+// Parse parses an ID written MCC-MNC, for example "208-93".
+func Parse(s string) (ID, error) {
+	f := strings.Split(s, "-")
+	if len(f) != 2 {
+		return ID{}, ErrInvalid
+	}
+	id := ID{MCC: f[0], MNC: f[1]}
+	if !digits(id.MCC, 3, 3) || !digits(id.MNC, 2, 3) {
+		return ID{}, ErrInvalid
+	}
+	return id, nil
+}
+
+// FromServingNetworkName returns the PLMN of a 5G serving network name
+// (TS 24.501 9.12.1), for example "5G:mnc093.mcc208.3gppnetwork.org".
+func FromServingNetworkName(snn string) (ID, error) {
+	// 5G:mnc<MNC>.mcc<MCC>.3gppnetwork.org
+	f := strings.Split(strings.TrimPrefix(snn, "5G:"), ".")
+	if len(f) < 2 || !strings.HasPrefix(f[0], "mnc") || !strings.HasPrefix(f[1], "mcc") {
+		return ID{}, ErrInvalid
+	}
+	mnc := strings.TrimPrefix(f[0], "mnc")
+	// the MNC is padded to 3 digits, a 2 digit MNC cannot be told from a 3
+	// digit one starting with 0; 2 digits are assumed, as in the examples of
+	// TS 23.003
+	if len(mnc) == 3 && mnc[0] == '0' {
+		mnc = mnc[1:]
+	}
+	return Parse(strings.TrimPrefix(f[1], "mcc") + "-" + mnc)
+}
+
+// String returns id as MCC-MNC, or the empty string for the zero ID.
+func (id ID) String() string {
+	if id == (ID{}) {
+		return ""
+	}
+	return id.MCC + "-" + id.MNC
+}
+
+// ServingNetworkName returns the 5G serving network name of id.
+func (id ID) ServingNetworkName() string {
+	mnc := id.MNC
+	if len(mnc) == 2 {
+		mnc = "0" + mnc
+	}
+	return "5G:mnc" + mnc + ".mcc" + id.MCC + ".3gppnetwork.org"
+}
+
+// List is a list of served PLMNs. An empty list serves every PLMN.
+type List []ID
+
+// ParseList parses a comma separated list of IDs. The empty string gives an
+// empty list.
+func ParseList(s string) (List, error) {
+	var l List
+	for _, f := range strings.Split(s, ",") {
+		if f = strings.TrimSpace(f); f == "" {
+			continue
+		}
+		id, err := Parse(f)
+		if err != nil {
+			return nil, err
+		}
+		l = append(l, id)
+	}
+	return l, nil
+}
+
+// String returns l as a comma separated list.
+func (l List) String() string {
+	s := make([]string, len(l))
+	for i, id := range l {
+		s[i] = id.String()
+	}
+	return strings.Join(s, ",")
+}
+
+// Serves reports whether id is served.
+func (l List) Serves(id ID) bool {
+	if len(l) == 0 {
+		return true
+	}
+	for _, v := range l {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Home returns the served PLMN of an IMSI based SUPI such as
+// "imsi-208930000000001". The length of the MNC is not part of an IMSI, it is
+// found by matching the served PLMNs, so an empty list never finds a PLMN.
+func (l List) Home(supi string) (ID, bool) {
+	if !strings.HasPrefix(supi, "imsi-") {
+		return ID{}, false
+	}
+	imsi := strings.TrimPrefix(supi, "imsi-")
+	for _, id := range l {
+		if strings.HasPrefix(imsi, id.MCC+id.MNC) {
+			return id, true
+		}
+	}
+	return ID{}, false
+}
+
+// Admit returns ErrForeign unless the SUPI belongs to a served PLMN and to
+// the PLMN of the request, if ctx carries one. An empty list admits any SUPI
+// of the PLMN of the request. The context returned carries the PLMN of the
+// SUPI when it is known.
+func (l List) Admit(ctx context.Context, supi string) (context.Context, error) {
+	id, ok := FromContext(ctx)
+	if len(l) == 0 {
+		if ok && !strings.HasPrefix(supi, "imsi-"+id.MCC+id.MNC) {
+			return ctx, ErrForeign
+		}
+		return ctx, nil
+	}
+	home, found := l.Home(supi)
+	if !found || (ok && home != id) {
+		return ctx, ErrForeign
+	}
+	return NewContext(ctx, home), nil
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying the PLMN id.
+func NewContext(ctx context.Context, id ID) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the PLMN carried by ctx, if any.
+func FromContext(ctx context.Context) (ID, bool) {
+	id, ok := ctx.Value(contextKey{}).(ID)
+	return id, ok
+}
+
+// Keyvals returns the PLMN of ctx as log key values, nothing when ctx does
+// not carry one.
+func Keyvals(ctx context.Context) []interface{} {
+	id, ok := FromContext(ctx)
+	if !ok {
+		return nil
+	}
+	return []interface{}{"plmn", id.String()}
+}
+
+// GRPCToContext moves the PLMN of the incoming metadata to the context. A
+// malformed value is ignored.
+func GRPCToContext() grpctransport.ServerRequestFunc {
+	return func(ctx context.Context, md metadata.MD) context.Context {
+		v := md.Get(MetadataKey)
+		if len(v) == 0 {
+			return ctx
+		}
+		id, err := Parse(v[0])
+		if err != nil {
+			return ctx
+		}
+		return NewContext(ctx, id)
+	}
+}
+
+// ContextToGRPC moves the PLMN of the context to the outgoing metadata.
+func ContextToGRPC() grpctransport.ClientRequestFunc {
+	return func(ctx context.Context, md *metadata.MD) context.Context {
+		if id, ok := FromContext(ctx); ok {
+			(*md)[MetadataKey] = []string{id.String()}
+		}
+		return ctx
+	}
+}
+
+// HTTPToContext moves the PLMN of the request header to the context. A
+// malformed value is ignored.
+func HTTPToContext() httptransport.RequestFunc {
+	return func(ctx context.Context, r *http.Request) context.Context {
+		id, err := Parse(r.Header.Get(HeaderKey))
+		if err != nil {
+			return ctx
+		}
+		return NewContext(ctx, id)
+	}
+}
+
+// ContextToHTTP moves the PLMN of the context to the request header.
+func ContextToHTTP() httptransport.RequestFunc {
+	return func(ctx context.Context, r *http.Request) context.Context {
+		if id, ok := FromContext(ctx); ok {
+			r.Header.Set(HeaderKey, id.String())
+		}
+		return ctx
+	}
+}
+
+func digits(s string, min, max int) bool {
+	if len(s) < min || len(s) > max {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
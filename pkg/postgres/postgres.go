@@ -0,0 +1,180 @@
+// Package postgres keeps the state of the NFs in PostgreSQL, for the
+// deployments wanting it durable, and relational, rather than in Redis.
+//
+// A Store keeps the records of an NF, its UE contexts, sessions and the
+// like, in a table of keys and values shared by the NFs, their keys
+// prefixed as in Redis; Expire deletes those whose time to live ran out,
+// which the Store ignores meanwhile. The subscribers of the UDM have a
+// table of their own, its Repository updating them in transactions, so that
+// the UDMs sharing the database take their sequence numbers in turn. So do
+// the UE contexts of the AMF stand-in of an N3IWF, by GUAMI, written in
+// batches in transactions, and the sessions of the UEs of an N3IWF, by NF
+// instance.
+//
+// Open connects the pool of connections of database/sql, and creates the
+// tables or upgrades them to the version of the package, in a transaction
+// holding an advisory lock, so that the replicas starting together upgrade
+// them once. The password is best given in PGPASSWORD, which the driver
+// reads, rather than in the URL.
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	// the driver of database/sql
+	_ "github.com/lib/pq"
+)
+
+// DefaultMaxConns is the default size of the pool of connections,
+// DefaultExpire the default period of Expire.
+const (
+	DefaultMaxConns = 10
+	DefaultExpire   = time.Minute
+)
+
+// lockID is the key of the advisory lock held while upgrading the tables.
+const lockID = 0x5a5a5a5a
+
+// migrations upgrade the tables, migrations[i] from version i to i+1. The
+// keys are collated as bytes, so that they sort as in Go.
+var migrations = []string{
+	`CREATE TABLE kv (
+		key        text COLLATE "C" PRIMARY KEY,
+		value      bytea NOT NULL,
+		expires_at timestamptz
+	);
+	CREATE INDEX kv_expires_at ON kv (expires_at) WHERE expires_at IS NOT NULL;
+	CREATE TABLE subscribers (
+		supi          text COLLATE "C" PRIMARY KEY,
+		k             bytea NOT NULL,
+		opc           bytea NOT NULL,
+		amf           bytea NOT NULL,
+		sqn           bytea NOT NULL,
+		default_sst   smallint,
+		default_sd    text,
+		ambr_uplink   text,
+		ambr_downlink text
+	);`,
+	`CREATE TABLE ue_contexts (
+		amf        text COLLATE "C" NOT NULL,
+		supi       text COLLATE "C" NOT NULL,
+		context    bytea NOT NULL,
+		crc32c     bigint NOT NULL,
+		updated_at timestamptz NOT NULL DEFAULT now(),
+		PRIMARY KEY (amf, supi)
+	);
+	CREATE TABLE sessions (
+		n3iwf          text COLLATE "C" NOT NULL,
+		ran_ue_ngap_id bigint NOT NULL,
+		amf_ue_ngap_id bigint,
+		state          text NOT NULL,
+		inner_ip       inet,
+		remote_address text NOT NULL,
+		updated_at     timestamptz NOT NULL DEFAULT now(),
+		PRIMARY KEY (n3iwf, ran_ue_ngap_id)
+	);`,
+}
+
+// Option sets an optional parameter of the pool of connections.
+type Option func(*sql.DB)
+
+// MaxConns sets the number of connections of the pool, DefaultMaxConns by
+// default. They are kept open when idle.
+func MaxConns(n int) Option {
+	return func(db *sql.DB) {
+		db.SetMaxOpenConns(n)
+		db.SetMaxIdleConns(n)
+	}
+}
+
+// ConnMaxLifetime sets how long a connection is reused for, forever by
+// default.
+func ConnMaxLifetime(d time.Duration) Option {
+	return func(db *sql.DB) { db.SetConnMaxLifetime(d) }
+}
+
+// Open returns the pool of connections to the database of url, such as
+// "postgres://sa5g@postgres:5432/sa5g?sslmode=disable", its tables upgraded.
+func Open(ctx context.Context, url string, options ...Option) (*sql.DB, error) {
+	db, err := sql.Open("postgres", url)
+	if err != nil {
+		return nil, err
+	}
+	MaxConns(DefaultMaxConns)(db)
+	for _, option := range options {
+		option(db)
+	}
+	if err := upgrade(ctx, db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// upgrade runs the migrations the tables of db lack.
+func upgrade(ctx context.Context, db *sql.DB) error {
+	return transaction(ctx, db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, lockID); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (version integer PRIMARY KEY, applied_at timestamptz NOT NULL DEFAULT now())`); err != nil {
+			return err
+		}
+		var v int
+		if err := tx.QueryRowContext(ctx, `SELECT coalesce(max(version), 0) FROM schema_migrations`).Scan(&v); err != nil {
+			return err
+		}
+		if v > len(migrations) {
+			return fmt.Errorf("postgres: tables of version %d, newer than %d", v, len(migrations))
+		}
+		for ; v < len(migrations); v++ {
+			if _, err := tx.ExecContext(ctx, migrations[v]); err != nil {
+				return fmt.Errorf("postgres: version %d: %v", v+1, err)
+			}
+			if _, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version) VALUES ($1)`, v+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Expire deletes the expired records of the stores of db every interval,
+// until ctx is done.
+func Expire(ctx context.Context, db *sql.DB, interval time.Duration, logger log.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		res, err := db.ExecContext(ctx, `DELETE FROM kv WHERE expires_at <= now()`)
+		if err != nil {
+			level.Warn(logger).Log("expire", "failed", "err", err)
+			continue
+		}
+		if n, _ := res.RowsAffected(); n > 0 {
+			level.Debug(logger).Log("expired", n)
+		}
+	}
+}
+
+// transaction runs fn in a transaction of db, committed unless fn fails.
+func transaction(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
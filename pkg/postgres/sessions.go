@@ -0,0 +1,65 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/n3iwf/service"
+)
+
+type sessions struct {
+	db    *sql.DB
+	n3iwf string
+}
+
+// NewSessions returns the Repository of the sessions of the UEs of the
+// N3IWF of the NF instance id in the table of db, shared by the N3IWFs
+// using the same database.
+func NewSessions(db *sql.DB, id string) service.Repository {
+	return &sessions{db: db, n3iwf: id}
+}
+
+func (r *sessions) Put(ctx context.Context, ue service.UE) error {
+	var amfUEID, innerIP interface{}
+	if ue.AMFUEID != 0 {
+		amfUEID = int64(ue.AMFUEID)
+	}
+	if ue.InnerIP != "" {
+		innerIP = ue.InnerIP
+	}
+	_, err := r.db.ExecContext(ctx, `INSERT INTO sessions (n3iwf, ran_ue_ngap_id, amf_ue_ngap_id, state, inner_ip, remote_address)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (n3iwf, ran_ue_ngap_id) DO UPDATE SET amf_ue_ngap_id = excluded.amf_ue_ngap_id, state = excluded.state,
+			inner_ip = excluded.inner_ip, remote_address = excluded.remote_address, updated_at = now()`,
+		r.n3iwf, int64(ue.RANUEID), amfUEID, ue.State, innerIP, ue.RemoteAddress)
+	return err
+}
+
+func (r *sessions) Delete(ctx context.Context, ranUEID uint64) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM sessions WHERE n3iwf = $1 AND ran_ue_ngap_id = $2`, r.n3iwf, int64(ranUEID))
+	return err
+}
+
+func (r *sessions) List(ctx context.Context) ([]service.UE, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT ran_ue_ngap_id, amf_ue_ngap_id, state, host(inner_ip), remote_address
+		FROM sessions WHERE n3iwf = $1 ORDER BY ran_ue_ngap_id`, r.n3iwf)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ues []service.UE
+	for rows.Next() {
+		var (
+			ue      service.UE
+			ranUEID int64
+			amfUEID sql.NullInt64
+			innerIP sql.NullString
+		)
+		if err := rows.Scan(&ranUEID, &amfUEID, &ue.State, &innerIP, &ue.RemoteAddress); err != nil {
+			return nil, err
+		}
+		ue.RANUEID, ue.AMFUEID, ue.InnerIP = uint64(ranUEID), uint64(amfUEID.Int64), innerIP.String
+		ues = append(ues, ue)
+	}
+	return ues, rows.Err()
+}
@@ -0,0 +1,117 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/store"
+)
+
+// likeEscaper escapes the wildcards of LIKE.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+type pgStore struct {
+	db     *sql.DB
+	prefix string
+}
+
+// NewStore returns a Store backed by the key/value table of db, shared by
+// every replica using the same database. Keys are prefixed with prefix so
+// that several services can use one database. The times to live run on the
+// clock of the database.
+func NewStore(db *sql.DB, prefix string) store.Store {
+	return &pgStore{db: db, prefix: prefix}
+}
+
+func (s *pgStore) Get(ctx context.Context, key string) ([]byte, error) {
+	var v []byte
+	err := s.db.QueryRowContext(ctx, `SELECT value FROM kv WHERE key = $1 AND (expires_at IS NULL OR expires_at > now())`, s.prefix+key).Scan(&v)
+	if err == sql.ErrNoRows {
+		return nil, store.ErrNotFound
+	}
+	return v, err
+}
+
+func (s *pgStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if value == nil {
+		value = []byte{}
+	}
+	_, err := s.db.ExecContext(ctx, `INSERT INTO kv (key, value, expires_at) VALUES ($1, $2, `+expiresAt+`)
+		ON CONFLICT (key) DO UPDATE SET value = excluded.value, expires_at = excluded.expires_at`,
+		s.prefix+key, value, ttl/time.Microsecond)
+	return err
+}
+
+func (s *pgStore) SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	if value == nil {
+		value = []byte{}
+	}
+	// an expired record, not deleted yet, does not exist
+	res, err := s.db.ExecContext(ctx, `INSERT INTO kv (key, value, expires_at) VALUES ($1, $2, `+expiresAt+`)
+		ON CONFLICT (key) DO UPDATE SET value = excluded.value, expires_at = excluded.expires_at
+		WHERE kv.expires_at <= now()`,
+		s.prefix+key, value, ttl/time.Microsecond)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
+
+func (s *pgStore) CompareAndSwap(ctx context.Context, key string, old, value []byte, ttl time.Duration) (bool, error) {
+	if value == nil {
+		value = []byte{}
+	}
+	res, err := s.db.ExecContext(ctx, `UPDATE kv SET value = $2, expires_at = `+expiresAt+`
+		WHERE key = $1 AND value = $4 AND (expires_at IS NULL OR expires_at > now())`,
+		s.prefix+key, value, ttl/time.Microsecond, old)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
+
+// expiresAt is the expiry of a record of a time to live of $3 microseconds,
+// none when zero.
+const expiresAt = `CASE WHEN $3::bigint > 0 THEN now() + $3::bigint * interval '1 microsecond' END`
+
+func (s *pgStore) Delete(ctx context.Context, key string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM kv WHERE key = $1`, s.prefix+key)
+	return err
+}
+
+func (s *pgStore) CompareAndDelete(ctx context.Context, key string, old []byte) (bool, error) {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM kv WHERE key = $1 AND value = $2 AND (expires_at IS NULL OR expires_at > now())`,
+		s.prefix+key, old)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
+
+func (s *pgStore) Keys(ctx context.Context, prefix string) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT key FROM kv WHERE key LIKE $1 AND (expires_at IS NULL OR expires_at > now())`,
+		likeEscaper.Replace(s.prefix+prefix)+"%")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var keys []string
+	for rows.Next() {
+		var k string
+		if err := rows.Scan(&k); err != nil {
+			return nil, err
+		}
+		keys = append(keys, k[len(s.prefix):])
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
@@ -0,0 +1,249 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc/status"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/backup"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/udm/subscriber"
+)
+
+const subscriberColumns = `supi, k, opc, amf, sqn, default_sst, default_sd, ambr_uplink, ambr_downlink`
+
+// querier is a database or a transaction.
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+type subscribers struct {
+	db *sql.DB
+}
+
+// NewSubscribers returns the Repository of the subscribers of the table of
+// db, which updates them in transactions.
+func NewSubscribers(db *sql.DB) subscriber.Transactional {
+	return &subscribers{db: db}
+}
+
+func (r *subscribers) Get(ctx context.Context, supi string) (subscriber.Subscriber, error) {
+	return get(ctx, r.db, supi, "")
+}
+
+func (r *subscribers) Put(ctx context.Context, s subscriber.Subscriber) error {
+	return put(ctx, r.db, s)
+}
+
+func (r *subscribers) Create(ctx context.Context, s subscriber.Subscriber) error {
+	if err := s.Normalize(); err != nil {
+		return err
+	}
+	res, err := r.db.ExecContext(ctx, `INSERT INTO subscribers (`+subscriberColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (supi) DO NOTHING`, args(s)...)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return subscriber.ErrExists
+	}
+	return nil
+}
+
+func (r *subscribers) Delete(ctx context.Context, supi string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM subscribers WHERE supi = $1`, supi)
+	return err
+}
+
+// List uses the last SUPI of a page as the token of the next one, as the
+// Repository of a store does.
+func (r *subscribers) List(ctx context.Context, pageSize int, pageToken string) ([]subscriber.Subscriber, string, error) {
+	var after string
+	if pageToken != "" {
+		b, err := hex.DecodeString(pageToken)
+		if err != nil {
+			return nil, "", subscriber.ErrPageToken
+		}
+		after = string(b)
+	}
+	if pageSize <= 0 {
+		return nil, "", nil
+	}
+	// one more than the page tells whether there is a next one
+	rows, err := r.db.QueryContext(ctx, `SELECT `+subscriberColumns+` FROM subscribers WHERE supi > $1 ORDER BY supi LIMIT $2`, after, pageSize+1)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+	var page []subscriber.Subscriber
+	for rows.Next() {
+		s, err := scan(rows)
+		if err != nil {
+			return nil, "", err
+		}
+		page = append(page, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
+	var next string
+	if len(page) > pageSize {
+		page = page[:pageSize]
+		next = hex.EncodeToString([]byte(page[pageSize-1].SUPI))
+	}
+	return page, next, nil
+}
+
+// Update locks the row of the subscriber until the transaction ends.
+func (r *subscribers) Update(ctx context.Context, supi string, fn func(s *subscriber.Subscriber) error) error {
+	return transaction(ctx, r.db, func(tx *sql.Tx) error {
+		s, err := get(ctx, tx, supi, "FOR UPDATE")
+		if err != nil {
+			return err
+		}
+		if err := fn(&s); err != nil {
+			return err
+		}
+		return put(ctx, tx, s)
+	})
+}
+
+func (r *subscribers) PutAll(ctx context.Context, all []subscriber.Subscriber) error {
+	return transaction(ctx, r.db, func(tx *sql.Tx) error {
+		for _, s := range all {
+			if err := put(ctx, tx, s); err != nil {
+				return fmt.Errorf("%s: %s", s.SUPI, status.Convert(err).Message())
+			}
+		}
+		return nil
+	})
+}
+
+// get returns the subscriber of supi, the query ending with lock.
+func get(ctx context.Context, q querier, supi, lock string) (subscriber.Subscriber, error) {
+	s, err := scan(q.QueryRowContext(ctx, `SELECT `+subscriberColumns+` FROM subscribers WHERE supi = $1 `+lock, supi))
+	if err == sql.ErrNoRows {
+		return s, subscriber.ErrNotFound
+	}
+	return s, err
+}
+
+func put(ctx context.Context, q querier, s subscriber.Subscriber) error {
+	if err := s.Normalize(); err != nil {
+		return err
+	}
+	_, err := q.ExecContext(ctx, `INSERT INTO subscribers (`+subscriberColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (supi) DO UPDATE SET k = excluded.k, opc = excluded.opc, amf = excluded.amf, sqn = excluded.sqn,
+			default_sst = excluded.default_sst, default_sd = excluded.default_sd,
+			ambr_uplink = excluded.ambr_uplink, ambr_downlink = excluded.ambr_downlink`, args(s)...)
+	return err
+}
+
+// args returns the values of the columns of s, a normalized subscriber.
+func args(s subscriber.Subscriber) []interface{} {
+	var sst, sd, ul, dl interface{}
+	if s.DefaultSNSSAI != nil {
+		sst = int64(s.DefaultSNSSAI.SST)
+		if s.DefaultSNSSAI.SD != "" {
+			sd = s.DefaultSNSSAI.SD
+		}
+	}
+	if s.AMBR != nil {
+		ul, dl = s.AMBR.Uplink, s.AMBR.Downlink
+	}
+	return []interface{}{s.SUPI, []byte(s.K), []byte(s.OPc), []byte(s.AMF), []byte(s.SQN), sst, sd, ul, dl}
+}
+
+// scanner is a row, or rows.
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scan returns the subscriber of a row of the columns.
+func scan(row scanner) (subscriber.Subscriber, error) {
+	var (
+		s      subscriber.Subscriber
+		sst    sql.NullInt64
+		sd     sql.NullString
+		ul, dl sql.NullString
+	)
+	err := row.Scan(&s.SUPI, (*[]byte)(&s.K), (*[]byte)(&s.OPc), (*[]byte)(&s.AMF), (*[]byte)(&s.SQN), &sst, &sd, &ul, &dl)
+	if err != nil {
+		return subscriber.Subscriber{}, err
+	}
+	if sst.Valid {
+		s.DefaultSNSSAI = &subscriber.SNSSAI{SST: uint8(sst.Int64), SD: sd.String}
+	}
+	if ul.Valid || dl.Valid {
+		s.AMBR = &subscriber.AMBR{Uplink: ul.String, Downlink: dl.String}
+	}
+	return s, nil
+}
+
+type subscriberSource struct {
+	r *subscribers
+}
+
+// SubscriberSource returns the backup source of the subscribers of db,
+// named "subscribers". Its snapshots are those of the subscribers of a
+// store, so that a UDM moving from Redis to PostgreSQL, or back, restores
+// those of the other. Restoring one puts its subscribers in a transaction.
+func SubscriberSource(db *sql.DB) backup.Source {
+	return subscriberSource{r: &subscribers{db: db}}
+}
+
+func (subscriberSource) Name() string { return "subscribers" }
+
+func (s subscriberSource) Snapshot(ctx context.Context) ([]byte, error) {
+	rows, err := s.r.db.QueryContext(ctx, `SELECT `+subscriberColumns+` FROM subscribers`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	values := map[string][]byte{}
+	for rows.Next() {
+		sub, err := scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		v, err := json.Marshal(sub)
+		if err != nil {
+			return nil, err
+		}
+		values[subscriber.KeyPrefix+sub.SUPI] = subscriber.Schema.Stamp(v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return json.Marshal(values)
+}
+
+func (s subscriberSource) Restore(ctx context.Context, data []byte) error {
+	var values map[string][]byte
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	all := make([]subscriber.Subscriber, 0, len(values))
+	for k, v := range values {
+		if !strings.HasPrefix(k, subscriber.KeyPrefix) {
+			continue
+		}
+		v, _, err := subscriber.Schema.Upgrade(v)
+		if err != nil {
+			return err
+		}
+		var sub subscriber.Subscriber
+		if err := json.Unmarshal(v, &sub); err != nil {
+			return fmt.Errorf("%s: %v", k, err)
+		}
+		all = append(all, sub)
+	}
+	return s.r.PutAll(ctx, all)
+}
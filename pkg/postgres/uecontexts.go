@@ -0,0 +1,58 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/n3iwf/amf"
+)
+
+type ueContexts struct {
+	db  *sql.DB
+	amf string
+}
+
+// NewUEContexts returns the Repository of the UE contexts of the AMF of
+// guami in the table of db, shared by the AMFs using the same database.
+func NewUEContexts(db *sql.DB, guami string) amf.Repository {
+	return &ueContexts{db: db, amf: guami}
+}
+
+// Sync writes the contexts in a transaction.
+func (r *ueContexts) Sync(ctx context.Context, cs []amf.UEContext, deleted []string) error {
+	return transaction(ctx, r.db, func(tx *sql.Tx) error {
+		for _, c := range cs {
+			_, err := tx.ExecContext(ctx, `INSERT INTO ue_contexts (amf, supi, context, crc32c) VALUES ($1, $2, $3, $4)
+				ON CONFLICT (amf, supi) DO UPDATE SET context = excluded.context, crc32c = excluded.crc32c, updated_at = now()`,
+				r.amf, c.SUPI, c.Context, int64(c.CRC32C))
+			if err != nil {
+				return err
+			}
+		}
+		for _, supi := range deleted {
+			if _, err := tx.ExecContext(ctx, `DELETE FROM ue_contexts WHERE amf = $1 AND supi = $2`, r.amf, supi); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *ueContexts) List(ctx context.Context) ([]amf.UEContext, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT supi, context, crc32c FROM ue_contexts WHERE amf = $1 ORDER BY supi`, r.amf)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var cs []amf.UEContext
+	for rows.Next() {
+		var c amf.UEContext
+		var crc int64
+		if err := rows.Scan(&c.SUPI, &c.Context, &crc); err != nil {
+			return nil, err
+		}
+		c.CRC32C = uint32(crc)
+		cs = append(cs, c)
+	}
+	return cs, rows.Err()
+}
@@ -0,0 +1,106 @@
+package endpoints
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/log"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/clock"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/gnodeb/cell"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/recovery"
+)
+
+// CellEndpoints collects the endpoints of the API changing the cells of the
+// gNB at runtime.
+type CellEndpoints struct {
+	AddCellEndpoint        endpoint.Endpoint `json:""`
+	UpdateCellEndpoint     endpoint.Endpoint `json:""`
+	SetCellEnabledEndpoint endpoint.Endpoint `json:""`
+	RemoveCellEndpoint     endpoint.Endpoint `json:""`
+	ListCellsEndpoint      endpoint.Endpoint `json:""`
+}
+
+// NewCellEndpoints returns the endpoints of the API of the cells of m.
+func NewCellEndpoints(m *cell.Manager, logger log.Logger) (ep CellEndpoints) {
+	wrap := func(method string, e endpoint.Endpoint) endpoint.Endpoint {
+		e = recovery.Middleware(logger, method)(e)
+		return LoggingMiddleware(log.With(logger, "method", method), clock.Real)(e)
+	}
+	ep.AddCellEndpoint = wrap("addCell", MakeAddCellEndpoint(m))
+	ep.UpdateCellEndpoint = wrap("updateCell", MakeUpdateCellEndpoint(m))
+	ep.SetCellEnabledEndpoint = wrap("setCellEnabled", MakeSetCellEnabledEndpoint(m))
+	ep.RemoveCellEndpoint = wrap("removeCell", MakeRemoveCellEndpoint(m))
+	ep.ListCellsEndpoint = wrap("listCells", MakeListCellsEndpoint(m))
+	return ep
+}
+
+// CellRequest collects the request parameters for the AddCell and UpdateCell
+// methods.
+type CellRequest struct {
+	Cell cell.Cell `json:"cell"`
+}
+
+// SetCellEnabledRequest collects the request parameters for the
+// SetCellEnabled method.
+type SetCellEnabledRequest struct {
+	NCI     uint64 `json:"nci"`
+	Enabled bool   `json:"enabled"`
+}
+
+// RemoveCellRequest collects the request parameters for the RemoveCell method.
+type RemoveCellRequest struct {
+	NCI uint64 `json:"nci"`
+}
+
+// ListCellsRequest collects the request parameters for the ListCells method.
+type ListCellsRequest struct{}
+
+// CellResponse collects the response values for the methods changing a cell.
+type CellResponse struct{}
+
+// ListCellsResponse collects the response values for the ListCells method.
+type ListCellsResponse struct {
+	Cells []cell.Status `json:"cells"`
+}
+
+// MakeAddCellEndpoint returns an endpoint that adds a cell to m.
+func MakeAddCellEndpoint(m *cell.Manager) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(CellRequest)
+		return CellResponse{}, m.Add(req.Cell)
+	}
+}
+
+// MakeUpdateCellEndpoint returns an endpoint that modifies a cell of m.
+func MakeUpdateCellEndpoint(m *cell.Manager) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(CellRequest)
+		return CellResponse{}, m.Update(req.Cell)
+	}
+}
+
+// MakeSetCellEnabledEndpoint returns an endpoint that enables or disables a
+// cell of m.
+func MakeSetCellEnabledEndpoint(m *cell.Manager) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(SetCellEnabledRequest)
+		return CellResponse{}, m.SetEnabled(req.NCI, req.Enabled)
+	}
+}
+
+// MakeRemoveCellEndpoint returns an endpoint that removes a cell of m.
+func MakeRemoveCellEndpoint(m *cell.Manager) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(RemoveCellRequest)
+		return CellResponse{}, m.Remove(req.NCI)
+	}
+}
+
+// MakeListCellsEndpoint returns an endpoint that lists the cells of m and
+// their usage.
+func MakeListCellsEndpoint(m *cell.Manager) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		return ListCellsResponse{Cells: m.List()}, nil
+	}
+}
@@ -2,19 +2,12 @@ package endpoints
 
 import (
 	"context"
-	"time"
+	"errors"
 
-	"github.com/go-kit/kit/circuitbreaker"
 	"github.com/go-kit/kit/endpoint"
-	"github.com/go-kit/kit/log"
-	"github.com/go-kit/kit/ratelimit"
-	"github.com/go-kit/kit/tracing/opentracing"
-	"github.com/go-kit/kit/tracing/zipkin"
-	stdopentracing "github.com/opentracing/opentracing-go"
-	stdzipkin "github.com/openzipkin/zipkin-go"
-	"github.com/sony/gobreaker"
-	"golang.org/x/time/rate"
+	"google.golang.org/grpc/status"
 
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/kitx/chain"
 	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/preamblesvc/service"
 )
 
@@ -22,46 +15,86 @@ import (
 // meant to be used as a helper struct, to collect all of the endpoints into a
 // single parameter.
 type Endpoints struct {
-	PreambleEndpoint endpoint.Endpoint `json:""`
+	PreambleEndpoint      endpoint.Endpoint `json:""`
+	PreambleBatchEndpoint endpoint.Endpoint `json:""`
 }
 
 // New return a new instance of the endpoint that wraps the provided service.
-func New(svc service.PreamblesvcService, logger log.Logger, otTracer stdopentracing.Tracer, zipkinTracer *stdzipkin.Tracer) (ep Endpoints) {
-	var preambleEndpoint endpoint.Endpoint
-	{
-		method := "sum"
-		preambleEndpoint = MakePreambleEndpoint(svc)
-		preambleEndpoint = ratelimit.NewErroringLimiter(rate.NewLimiter(rate.Every(time.Second), 100))(preambleEndpoint)
-		preambleEndpoint = circuitbreaker.Gobreaker(gobreaker.NewCircuitBreaker(gobreaker.Settings{}))(preambleEndpoint)
-		preambleEndpoint = opentracing.TraceServer(otTracer, method)(preambleEndpoint)
-		preambleEndpoint = zipkin.TraceEndpoint(zipkinTracer, method)(preambleEndpoint)
-		preambleEndpoint = LoggingMiddleware(log.With(logger, "method", method))(preambleEndpoint)
-		ep.PreambleEndpoint = preambleEndpoint
-	}
-
+// The endpoints run in the middleware stack of mw, the methods being idempotent.
+func New(svc service.PreamblesvcService, mw chain.MiddlewareConfig) (ep Endpoints) {
+	mw.Logging = LoggingMiddleware
+	c := chain.New(mw)
+	ep.PreambleEndpoint = c.Build("preamble", MakePreambleEndpoint(svc), chain.Idempotent(PreambleResponse{}))
+	ep.PreambleBatchEndpoint = c.Build("preambleBatch", MakePreambleBatchEndpoint(svc), chain.Idempotent(PreambleBatchResponse{}))
 	return ep
 }
 
 // MakePreambleEndpoint returns an endpoint that invokes Preamble on the service.
 // Primarily useful in a server.
-func MakePreambleEndpoint(svc service.preamblesvcService) (ep endpoint.Endpoint) {
+func MakePreambleEndpoint(svc service.PreamblesvcService) (ep endpoint.Endpoint) {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(PreambleRequest)
 		if err := req.validate(); err != nil {
 			return PreambleResponse{}, err
 		}
-		rs, err := svc.Preamble(ctx, req.Msg)
+		rs, err := svc.Preamble(ctx, req.NCI, req.Msg)
 		return PreambleResponse{Rs: rs}, err
 	}
 }
 
 // Preamble implements the service interface, so Endpoints may be used as a service.
 // This is primarily useful in the context of a client library.
-func (e Endpoints) Preamble(ctx context.Context, msg int64) (rs int64, err error) {
-	resp, err := e.PreambleEndpoint(ctx, PreambleRequest{Msg: msg})
+func (e Endpoints) Preamble(ctx context.Context, nci uint64, msg int64) (rs int64, err error) {
+	resp, err := e.PreambleEndpoint(ctx, PreambleRequest{NCI: nci, Msg: msg})
 	if err != nil {
 		return
 	}
 	response := resp.(PreambleResponse)
 	return response.Rs, nil
 }
+
+// MakePreambleBatchEndpoint returns an endpoint that invokes PreambleBatch on the service.
+// Primarily useful in a server.
+func MakePreambleBatchEndpoint(svc service.PreamblesvcService) (ep endpoint.Endpoint) {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(PreambleBatchRequest)
+		if err := req.validate(); err != nil {
+			return PreambleBatchResponse{}, err
+		}
+		preambles := make([]service.Preamble, len(req.Preambles))
+		for i, p := range req.Preambles {
+			preambles[i] = service.Preamble{NCI: p.NCI, Msg: p.Msg}
+		}
+		rs, err := svc.PreambleBatch(ctx, preambles)
+		results := make([]PreambleResult, len(rs))
+		for i, r := range rs {
+			results[i].Rs = r.Rs
+			if r.Err != nil {
+				results[i].Err = status.Convert(r.Err).Message()
+			}
+		}
+		return PreambleBatchResponse{Results: results}, err
+	}
+}
+
+// PreambleBatch implements the service interface, so Endpoints may be used as a service.
+// This is primarily useful in the context of a client library.
+func (e Endpoints) PreambleBatch(ctx context.Context, preambles []service.Preamble) (rs []service.PreambleResult, err error) {
+	reqs := make([]PreambleRequest, len(preambles))
+	for i, p := range preambles {
+		reqs[i] = PreambleRequest{NCI: p.NCI, Msg: p.Msg}
+	}
+	resp, err := e.PreambleBatchEndpoint(ctx, PreambleBatchRequest{Preambles: reqs})
+	if err != nil {
+		return
+	}
+	response := resp.(PreambleBatchResponse)
+	rs = make([]service.PreambleResult, len(response.Results))
+	for i, r := range response.Results {
+		rs[i].Rs = r.Rs
+		if r.Err != "" {
+			rs[i].Err = errors.New(r.Err)
+		}
+	}
+	return rs, nil
+}
@@ -1,14 +1,42 @@
 package endpoints
 
+import (
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// MaxPreambleBatch is the largest number of preambles a PreambleBatch
+// request may carry.
+const MaxPreambleBatch = 64
+
 type Request interface {
 	validate() error
 }
 
-// PreambleRequest collects the request parameters for the Sum method.
+// PreambleRequest collects the request parameters for the Preamble method.
+// NCI names the cell the preamble was received on and may be left zero.
 type PreambleRequest struct {
-	Msg int64 `json:"msg"`
+	NCI uint64 `json:"nci,omitempty"`
+	Msg int64  `json:"msg"`
 }
 
 func (r PreambleRequest) validate() error {
 	return nil // TBA
 }
+
+// PreambleBatchRequest collects the request parameters for the PreambleBatch method.
+type PreambleBatchRequest struct {
+	Preambles []PreambleRequest `json:"preambles"`
+}
+
+func (r PreambleBatchRequest) validate() error {
+	if len(r.Preambles) > MaxPreambleBatch {
+		return status.Errorf(codes.InvalidArgument, "batch of %d preambles exceeds the limit of %d", len(r.Preambles), MaxPreambleBatch)
+	}
+	for _, p := range r.Preambles {
+		if err := p.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -10,9 +10,13 @@ var (
 	_ httptransport.Headerer = (*PreambleResponse)(nil)
 
 	_ httptransport.StatusCoder = (*PreambleResponse)(nil)
+
+	_ httptransport.Headerer = (*PreambleBatchResponse)(nil)
+
+	_ httptransport.StatusCoder = (*PreambleBatchResponse)(nil)
 )
 
-// PreambleResponse collects the response values for the Sum method.
+// PreambleResponse collects the response values for the Preamble method.
 type PreambleResponse struct {
 	Rs  int64 `json:"rs"`
 	Err error `json:"err"`
@@ -25,3 +29,23 @@ func (r PreambleResponse) StatusCode() int {
 func (r PreambleResponse) Headers() http.Header {
 	return http.Header{}
 }
+
+// PreambleResult is the outcome of one preamble of a batch.
+type PreambleResult struct {
+	Rs  int64  `json:"rs"`
+	Err string `json:"err,omitempty"`
+}
+
+// PreambleBatchResponse collects the response values for the PreambleBatch method.
+type PreambleBatchResponse struct {
+	Results []PreambleResult `json:"results"`
+	Err     error            `json:"err"`
+}
+
+func (r PreambleBatchResponse) StatusCode() int {
+	return http.StatusOK // TBA
+}
+
+func (r PreambleBatchResponse) Headers() http.Header {
+	return http.Header{}
+}
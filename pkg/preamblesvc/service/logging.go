@@ -6,11 +6,13 @@ import (
 
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/logging"
 )
 
 type loggingMiddleware struct {
-	logger log.Logger         `json:""`
-	next   PreamblesvcService `json:""`
+	logger log.Logger
+	next   PreamblesvcService
 }
 
 // LoggingMiddleware takes a logger as a dependency
@@ -21,10 +23,18 @@ func LoggingMiddleware(logger log.Logger) Middleware {
 	}
 }
 
-func (lm loggingMiddleware) Preamble(ctx context.Context, msg int64) (rs int64, err error) {
+func (lm loggingMiddleware) Preamble(ctx context.Context, nci uint64, msg int64) (rs int64, err error) {
+	defer func(begin time.Time) {
+		log.With(lm.logger, logging.ContextKeyvals(ctx)...).Log("method", "Preamble", "nci", nci, "msg", msg, "err", err)
+	}(time.Now())
+
+	return lm.next.Preamble(ctx, nci, msg)
+}
+
+func (lm loggingMiddleware) PreambleBatch(ctx context.Context, preambles []Preamble) (rs []PreambleResult, err error) {
 	defer func(begin time.Time) {
-		lm.logger.Log("method", "Preable", "msg", msg, "err", err)
+		log.With(lm.logger, logging.ContextKeyvals(ctx)...).Log("method", "PreambleBatch", "size", len(preambles), "err", err)
 	}(time.Now())
 
-	return lm.next.Preamble(ctx, a, b)
+	return lm.next.PreambleBatch(ctx, preambles)
 }
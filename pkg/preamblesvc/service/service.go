@@ -2,10 +2,20 @@ package service
 
 import (
 	"context"
+	"sync"
 
 	"github.com/go-kit/kit/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/gnodeb/cell"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/runtime/pool"
 )
 
+// ErrThrottled is returned for the preambles shed because the worker pool
+// is saturated. Callers should back off and retry.
+var ErrThrottled = status.Error(codes.ResourceExhausted, pool.ErrThrottled.Error())
+
 // Middleware describes a service (as opposed to endpoint) middleware.
 type Middleware func(PreamblesvcService) PreamblesvcService
 
@@ -13,26 +23,104 @@ type Middleware func(PreamblesvcService) PreamblesvcService
 // Implement yor service methods methods.
 // e.x: Foo(ctx context.Context, s string)(rs string, err error)
 type PreamblesvcService interface {
-	Preamble(ctx context.Context, msg int64) (rs int64, err error)
+	Preamble(ctx context.Context, nci uint64, msg int64) (rs int64, err error)
+	PreambleBatch(ctx context.Context, preambles []Preamble) (rs []PreambleResult, err error)
+}
+
+// Preamble is one preamble of a batch. NCI names the cell it was received
+// on and is zero when the cell is not known.
+type Preamble struct {
+	NCI uint64
+	Msg int64
+}
+
+// PreambleResult is the outcome of one preamble of a batch.
+type PreambleResult struct {
+	Rs  int64
+	Err error
 }
 
 // the concrete implementation of service interface
 type stubPreamblesvcService struct {
-	logger log.Logger `json:"logger"`
+	logger log.Logger
+	// workers bounds the number of preambles processed at once across all
+	// calls and sheds the excess.
+	workers *pool.Pool
+	// cells admits the preambles received on a known cell, holding prbs
+	// resource blocks of it while each one is processed.
+	cells *cell.Manager
+	prbs  int
 }
 
 // New return a new instance of the service.
 // If you want to add service middleware this is the place to put them.
-func New(logger log.Logger) (s PreamblesvcService) {
+// The preambles naming a cell are admitted by cells, which may be nil, and
+// each one holds prbs resource blocks of the cell while it is processed.
+func New(logger log.Logger, workers *pool.Pool, cells *cell.Manager, prbs int) (s PreamblesvcService) {
 	var svc PreamblesvcService
 	{
-		svc = &stubPreamblesvcService{logger: logger}
+		svc = &stubPreamblesvcService{logger: logger, workers: workers, cells: cells, prbs: prbs}
 		svc = LoggingMiddleware(logger)(svc)
 	}
 	return svc
 }
 
 // Implement the business logic of Preamble
-func (ad *stubPreamblesvcService) Preamble(ctx context.Context, msg int64) (rs int64, err error) {
-	return msg, err
+func (ad *stubPreamblesvcService) Preamble(ctx context.Context, nci uint64, msg int64) (rs int64, err error) {
+	done := make(chan PreambleResult, 1)
+	if err := ad.workers.Submit(ctx, "preamble", func(ctx context.Context) {
+		var r PreambleResult
+		r.Rs, r.Err = ad.preamble(ctx, nci, msg)
+		done <- r
+	}); err != nil {
+		return 0, throttled(err)
+	}
+	select {
+	case r := <-done:
+		return r.Rs, r.Err
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+}
+
+// Implement the business logic of PreambleBatch. The preambles are queued on
+// the worker pool one by one and the results keep the order of preambles. The
+// preambles that do not fit in the queue fail with ErrThrottled.
+func (ad *stubPreamblesvcService) PreambleBatch(ctx context.Context, preambles []Preamble) (rs []PreambleResult, err error) {
+	rs = make([]PreambleResult, len(preambles))
+	var wg sync.WaitGroup
+	for i, p := range preambles {
+		wg.Add(1)
+		i, p := i, p
+		if err := ad.workers.Submit(ctx, "preambleBatch", func(ctx context.Context) {
+			defer wg.Done()
+			rs[i].Rs, rs[i].Err = ad.preamble(ctx, p.NCI, p.Msg)
+		}); err != nil {
+			rs[i].Err = throttled(err)
+			wg.Done()
+		}
+	}
+	wg.Wait()
+	return rs, nil
+}
+
+func (ad *stubPreamblesvcService) preamble(ctx context.Context, nci uint64, msg int64) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	if nci != 0 && ad.cells != nil {
+		release, err := ad.cells.Admit(ctx, nci, ad.prbs)
+		if err != nil {
+			return 0, err
+		}
+		defer release()
+	}
+	return msg, nil
+}
+
+func throttled(err error) error {
+	if err == pool.ErrThrottled {
+		return ErrThrottled
+	}
+	return err
 }
@@ -0,0 +1,233 @@
+package transports
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/log"
+	grpctransport "github.com/go-kit/kit/transport/grpc"
+	httptransport "github.com/go-kit/kit/transport/http"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pb/gnodeb"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/gnodeb/cell"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/logging"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/preamblesvc/endpoints"
+)
+
+// CellsPath is the path prefix of the HTTP API of the cells.
+const CellsPath = "/cells/"
+
+type cellsServer struct {
+	addCell        grpctransport.Handler
+	updateCell     grpctransport.Handler
+	setCellEnabled grpctransport.Handler
+	removeCell     grpctransport.Handler
+	listCells      grpctransport.Handler
+}
+
+func (s *cellsServer) AddCell(ctx context.Context, req *gnodeb.AddCellRequest) (*gnodeb.AddCellReply, error) {
+	_, rp, err := s.addCell.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcEncodeError(err)
+	}
+	return rp.(*gnodeb.AddCellReply), nil
+}
+
+func (s *cellsServer) UpdateCell(ctx context.Context, req *gnodeb.UpdateCellRequest) (*gnodeb.UpdateCellReply, error) {
+	_, rp, err := s.updateCell.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcEncodeError(err)
+	}
+	return rp.(*gnodeb.UpdateCellReply), nil
+}
+
+func (s *cellsServer) SetCellEnabled(ctx context.Context, req *gnodeb.SetCellEnabledRequest) (*gnodeb.SetCellEnabledReply, error) {
+	_, rp, err := s.setCellEnabled.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcEncodeError(err)
+	}
+	return rp.(*gnodeb.SetCellEnabledReply), nil
+}
+
+func (s *cellsServer) RemoveCell(ctx context.Context, req *gnodeb.RemoveCellRequest) (*gnodeb.RemoveCellReply, error) {
+	_, rp, err := s.removeCell.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcEncodeError(err)
+	}
+	return rp.(*gnodeb.RemoveCellReply), nil
+}
+
+func (s *cellsServer) ListCells(ctx context.Context, req *gnodeb.ListCellsRequest) (*gnodeb.ListCellsReply, error) {
+	_, rp, err := s.listCells.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcEncodeError(err)
+	}
+	return rp.(*gnodeb.ListCellsReply), nil
+}
+
+// MakeCellsGRPCServer makes the API of the cells available as a gRPC server.
+func MakeCellsGRPCServer(endpoints endpoints.CellEndpoints, logger log.Logger) gnodeb.CellsServer {
+	options := []grpctransport.ServerOption{
+		grpctransport.ServerErrorHandler(logging.ErrorHandler(logger)),
+	}
+
+	return &cellsServer{
+		addCell: grpctransport.NewServer(
+			endpoints.AddCellEndpoint,
+			decodeGRPCAddCellRequest,
+			encodeGRPCAddCellResponse,
+			options...,
+		),
+		updateCell: grpctransport.NewServer(
+			endpoints.UpdateCellEndpoint,
+			decodeGRPCUpdateCellRequest,
+			encodeGRPCUpdateCellResponse,
+			options...,
+		),
+		setCellEnabled: grpctransport.NewServer(
+			endpoints.SetCellEnabledEndpoint,
+			decodeGRPCSetCellEnabledRequest,
+			encodeGRPCSetCellEnabledResponse,
+			options...,
+		),
+		removeCell: grpctransport.NewServer(
+			endpoints.RemoveCellEndpoint,
+			decodeGRPCRemoveCellRequest,
+			encodeGRPCRemoveCellResponse,
+			options...,
+		),
+		listCells: grpctransport.NewServer(
+			endpoints.ListCellsEndpoint,
+			decodeGRPCListCellsRequest,
+			encodeGRPCListCellsResponse,
+			options...,
+		),
+	}
+}
+
+func decodeGRPCAddCellRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*gnodeb.AddCellRequest)
+	return endpoints.CellRequest{Cell: cellFromPB(req.GetCell())}, nil
+}
+
+func encodeGRPCAddCellResponse(_ context.Context, _ interface{}) (interface{}, error) {
+	return &gnodeb.AddCellReply{}, nil
+}
+
+func decodeGRPCUpdateCellRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*gnodeb.UpdateCellRequest)
+	return endpoints.CellRequest{Cell: cellFromPB(req.GetCell())}, nil
+}
+
+func encodeGRPCUpdateCellResponse(_ context.Context, _ interface{}) (interface{}, error) {
+	return &gnodeb.UpdateCellReply{}, nil
+}
+
+func decodeGRPCSetCellEnabledRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*gnodeb.SetCellEnabledRequest)
+	return endpoints.SetCellEnabledRequest{NCI: req.Nci, Enabled: req.Enabled}, nil
+}
+
+func encodeGRPCSetCellEnabledResponse(_ context.Context, _ interface{}) (interface{}, error) {
+	return &gnodeb.SetCellEnabledReply{}, nil
+}
+
+func decodeGRPCRemoveCellRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*gnodeb.RemoveCellRequest)
+	return endpoints.RemoveCellRequest{NCI: req.Nci}, nil
+}
+
+func encodeGRPCRemoveCellResponse(_ context.Context, _ interface{}) (interface{}, error) {
+	return &gnodeb.RemoveCellReply{}, nil
+}
+
+func decodeGRPCListCellsRequest(_ context.Context, _ interface{}) (interface{}, error) {
+	return endpoints.ListCellsRequest{}, nil
+}
+
+func encodeGRPCListCellsResponse(_ context.Context, grpcReply interface{}) (interface{}, error) {
+	reply := grpcReply.(endpoints.ListCellsResponse)
+	cells := make([]*gnodeb.CellStatus, len(reply.Cells))
+	for i, s := range reply.Cells {
+		cells[i] = &gnodeb.CellStatus{
+			Cell: cellToPB(s.Cell),
+			Usage: &gnodeb.Usage{
+				Prbs:     int32(s.Usage.PRBs),
+				Used:     int32(s.Usage.Used),
+				Peak:     int32(s.Usage.Peak),
+				Admitted: s.Usage.Admitted,
+				Rejected: s.Usage.Rejected,
+			},
+		}
+	}
+	return &gnodeb.ListCellsReply{Cells: cells}, nil
+}
+
+func cellFromPB(c *gnodeb.Cell) cell.Cell {
+	return cell.Cell{
+		NCI:       c.GetNci(),
+		PCI:       c.GetPci(),
+		TAC:       c.GetTac(),
+		Band:      c.GetBand(),
+		Bandwidth: c.GetBandwidthMhz(),
+		SCS:       c.GetScsKhz(),
+		Enabled:   c.GetEnabled(),
+	}
+}
+
+func cellToPB(c cell.Cell) *gnodeb.Cell {
+	return &gnodeb.Cell{
+		Nci:          c.NCI,
+		Pci:          c.PCI,
+		Tac:          c.TAC,
+		Band:         c.Band,
+		BandwidthMhz: c.Bandwidth,
+		ScsKhz:       c.SCS,
+		Enabled:      c.Enabled,
+	}
+}
+
+// NewCellsHTTPHandler returns a handler that makes the API of the cells
+// available under CellsPath. Every method is a POST of a JSON request.
+func NewCellsHTTPHandler(endpoints endpoints.CellEndpoints, logger log.Logger) http.Handler {
+	options := []httptransport.ServerOption{
+		httptransport.ServerErrorEncoder(httpEncodeError),
+		httptransport.ServerErrorHandler(logging.ErrorHandler(logger)),
+	}
+
+	handler := func(e endpoint.Endpoint, dec httptransport.DecodeRequestFunc) http.Handler {
+		return httptransport.NewServer(e, dec, httptransport.EncodeJSONResponse, options...)
+	}
+
+	m := http.NewServeMux()
+	m.Handle(CellsPath+"add", handler(endpoints.AddCellEndpoint, decodeHTTPCellRequest))
+	m.Handle(CellsPath+"update", handler(endpoints.UpdateCellEndpoint, decodeHTTPCellRequest))
+	m.Handle(CellsPath+"setEnabled", handler(endpoints.SetCellEnabledEndpoint, decodeHTTPSetCellEnabledRequest))
+	m.Handle(CellsPath+"remove", handler(endpoints.RemoveCellEndpoint, decodeHTTPRemoveCellRequest))
+	m.Handle(CellsPath+"list", handler(endpoints.ListCellsEndpoint, decodeHTTPListCellsRequest))
+	return m
+}
+
+func decodeHTTPCellRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req endpoints.CellRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	return req, err
+}
+
+func decodeHTTPSetCellEnabledRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req endpoints.SetCellEnabledRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	return req, err
+}
+
+func decodeHTTPRemoveCellRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var req endpoints.RemoveCellRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	return req, err
+}
+
+func decodeHTTPListCellsRequest(_ context.Context, _ *http.Request) (interface{}, error) {
+	return endpoints.ListCellsRequest{}, nil
+}
@@ -2,6 +2,7 @@ package transports
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/go-kit/kit/circuitbreaker"
@@ -20,13 +21,19 @@ import (
 	"google.golang.org/grpc/status"
 
 	pb "github.com/miki-tnt/sa5g-go-usvc-k8s/pb/preamblesvc"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/breakers"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/caller"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/grpcpool"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/idempotency"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/logging"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/meta"
 	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/preamblesvc/endpoints"
 	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/preamblesvc/service"
 )
 
 type grpcServer struct {
-	preamble grpctransport.Handler `json:""`
-	concat   grpctransport.Handler `json:""`
+	preamble      grpctransport.Handler `json:""`
+	preambleBatch grpctransport.Handler `json:""`
 }
 
 func (s *grpcServer) Preamble(ctx context.Context, req *pb.PreambleRequest) (rep *pb.PreambleReply, err error) {
@@ -38,6 +45,15 @@ func (s *grpcServer) Preamble(ctx context.Context, req *pb.PreambleRequest) (rep
 	return rep, nil
 }
 
+func (s *grpcServer) PreambleBatch(ctx context.Context, req *pb.PreambleBatchRequest) (rep *pb.PreambleBatchReply, err error) {
+	_, rp, err := s.preambleBatch.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcEncodeError(err)
+	}
+	rep = rp.(*pb.PreambleBatchReply)
+	return rep, nil
+}
+
 // MakeGRPCServer makes a set of endpoints available as a gRPC server.
 func MakeGRPCServer(endpoints endpoints.Endpoints, otTracer stdopentracing.Tracer, zipkinTracer *stdzipkin.Tracer, logger log.Logger) (req pb.PreamblesvcServer) { // Zipkin GRPC Server Trace can either be instantiated per gRPC method with a
 	// provided operation name or a global tracing service can be instantiated
@@ -51,7 +67,10 @@ func MakeGRPCServer(endpoints endpoints.Endpoints, otTracer stdopentracing.Trace
 	zipkinServer := zipkin.GRPCServerTrace(zipkinTracer)
 
 	options := []grpctransport.ServerOption{
-		grpctransport.ServerErrorLogger(logger),
+		grpctransport.ServerErrorHandler(logging.ErrorHandler(logger)),
+		grpctransport.ServerBefore(idempotency.GRPCToContext()),
+		grpctransport.ServerBefore(caller.GRPCToContext()),
+		grpctransport.ServerBefore(meta.GRPCToContext()),
 		zipkinServer,
 	}
 
@@ -62,6 +81,13 @@ func MakeGRPCServer(endpoints endpoints.Endpoints, otTracer stdopentracing.Trace
 			encodeGRPCPreambleResponse,
 			append(options, grpctransport.ServerBefore(opentracing.GRPCToContext(otTracer, "Preamble", logger)))...,
 		),
+
+		preambleBatch: grpctransport.NewServer(
+			endpoints.PreambleBatchEndpoint,
+			decodeGRPCPreambleBatchRequest,
+			encodeGRPCPreambleBatchResponse,
+			append(options, grpctransport.ServerBefore(opentracing.GRPCToContext(otTracer, "PreambleBatch", logger)))...,
+		),
 	}
 }
 
@@ -69,7 +95,7 @@ func MakeGRPCServer(endpoints endpoints.Endpoints, otTracer stdopentracing.Trace
 // gRPC request to a user-domain request. Primarily useful in a server.
 func decodeGRPCPreambleRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
 	req := grpcReq.(*pb.PreambleRequest)
-	return endpoints.PreambleRequest{A: req.A, B: req.B}, nil
+	return endpoints.PreambleRequest{NCI: req.Nci, Msg: req.Msg}, nil
 }
 
 // encodeGRPCPreambleResponse is a transport/grpc.EncodeResponseFunc that converts a
@@ -79,25 +105,39 @@ func encodeGRPCPreambleResponse(_ context.Context, grpcReply interface{}) (res i
 	return &pb.PreambleReply{Rs: reply.Rs}, grpcEncodeError(reply.Err)
 }
 
-// decodeGRPCConcatRequest is a transport/grpc.DecodeRequestFunc that converts a
+// decodeGRPCPreambleBatchRequest is a transport/grpc.DecodeRequestFunc that converts a
 // gRPC request to a user-domain request. Primarily useful in a server.
-func decodeGRPCConcatRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
-	req := grpcReq.(*pb.ConcatRequest)
-	return endpoints.ConcatRequest{A: req.A, B: req.B}, nil
+func decodeGRPCPreambleBatchRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pb.PreambleBatchRequest)
+	preambles := make([]endpoints.PreambleRequest, len(req.Preambles))
+	for i, p := range req.Preambles {
+		preambles[i] = endpoints.PreambleRequest{NCI: p.GetNci(), Msg: p.GetMsg()}
+	}
+	return endpoints.PreambleBatchRequest{Preambles: preambles}, nil
 }
 
-// encodeGRPCConcatResponse is a transport/grpc.EncodeResponseFunc that converts a
+// encodeGRPCPreambleBatchResponse is a transport/grpc.EncodeResponseFunc that converts a
 // user-domain response to a gRPC reply. Primarily useful in a server.
-func encodeGRPCConcatResponse(_ context.Context, grpcReply interface{}) (res interface{}, err error) {
-	reply := grpcReply.(endpoints.ConcatResponse)
-	return &pb.ConcatReply{Rs: reply.Rs}, grpcEncodeError(reply.Err)
+func encodeGRPCPreambleBatchResponse(_ context.Context, grpcReply interface{}) (res interface{}, err error) {
+	reply := grpcReply.(endpoints.PreambleBatchResponse)
+	results := make([]*pb.PreambleReply, len(reply.Results))
+	for i, r := range reply.Results {
+		results[i] = &pb.PreambleReply{Rs: r.Rs, Err: r.Err}
+	}
+	return &pb.PreambleBatchReply{Results: results}, grpcEncodeError(reply.Err)
 }
 
-// NewGRPCClient returns an AddService backed by a gRPC server at the other end
+// NewGRPCClient returns an PreamblesvcService backed by a gRPC server at the other end
 // of the conn. The caller is responsible for constructing the conn, and
 // eventually closing the underlying transport. We bake-in certain middlewares,
 // implementing the client library pattern.
-func NewGRPCClient(conn *grpc.ClientConn, otTracer stdopentracing.Tracer, zipkinTracer *stdzipkin.Tracer, logger log.Logger) service.PreamblesvcService { // We construct a single ratelimiter middleware, to limit the total outgoing
+func NewGRPCClient(conn *grpc.ClientConn, otTracer stdopentracing.Tracer, zipkinTracer *stdzipkin.Tracer, logger log.Logger) service.PreamblesvcService {
+	return NewGRPCPoolClient(grpcpool.FromConn(conn), otTracer, zipkinTracer, logger)
+}
+
+// NewGRPCPoolClient is like NewGRPCClient, but spreads the calls over the
+// connections of the pool. The caller is responsible for closing the pool.
+func NewGRPCPoolClient(pool *grpcpool.Pool, otTracer stdopentracing.Tracer, zipkinTracer *stdzipkin.Tracer, logger log.Logger) service.PreamblesvcService { // We construct a single ratelimiter middleware, to limit the total outgoing
 	// QPS from this client to all methods on the remote instance. We also
 	// construct per-endpoint circuitbreaker middlewares to demonstrate how
 	// that's done, although they could easily be combined into a single breaker
@@ -115,54 +155,60 @@ func NewGRPCClient(conn *grpc.ClientConn, otTracer stdopentracing.Tracer, zipkin
 
 	// global client middlewares
 	options := []grpctransport.ClientOption{
+		grpctransport.ClientBefore(idempotency.ContextToGRPC()),
+		grpctransport.ClientBefore(meta.ContextToGRPC()),
 		zipkinClient,
 	}
 
 	// The Preamble endpoint is the same thing, with slightly different
 	// middlewares to demonstrate how to specialize per-endpoint.
-	var sumEndpoint endpoint.Endpoint
+	var preambleEndpoint endpoint.Endpoint
 	{
-		sumEndpoint = grpctransport.NewClient(
-			conn,
-			"pb.Preamblesvc",
-			"Preamble",
-			encodeGRPCPreambleRequest,
-			decodeGRPCPreambleResponse,
-			pb.PreambleReply{},
-			append(options, grpctransport.ClientBefore(opentracing.ContextToGRPC(otTracer, logger)))...,
-		).Endpoint()
-		sumEndpoint = opentracing.TraceClient(otTracer, "Preamble")(sumEndpoint)
-		sumEndpoint = limiter(sumEndpoint)
-		sumEndpoint = circuitbreaker.Gobreaker(gobreaker.NewCircuitBreaker(gobreaker.Settings{
+		preambleEndpoint = pool.Endpoint(func(conn *grpc.ClientConn) endpoint.Endpoint {
+			return grpctransport.NewClient(
+				conn,
+				"pb.Preamblesvc",
+				"Preamble",
+				encodeGRPCPreambleRequest,
+				decodeGRPCPreambleResponse,
+				pb.PreambleReply{},
+				append(options, grpctransport.ClientBefore(opentracing.ContextToGRPC(otTracer, logger)))...,
+			).Endpoint()
+		})
+		preambleEndpoint = opentracing.TraceClient(otTracer, "Preamble")(preambleEndpoint)
+		preambleEndpoint = limiter(preambleEndpoint)
+		preambleEndpoint = circuitbreaker.Gobreaker(breakers.New(gobreaker.Settings{
 			Name:    "Preamble",
 			Timeout: 30 * time.Second,
-		}))(sumEndpoint)
+		}))(preambleEndpoint)
 	}
 
-	// The Concat endpoint is the same thing, with slightly different
+	// The PreambleBatch endpoint is the same thing, with slightly different
 	// middlewares to demonstrate how to specialize per-endpoint.
-	var concatEndpoint endpoint.Endpoint
+	var preambleBatchEndpoint endpoint.Endpoint
 	{
-		concatEndpoint = grpctransport.NewClient(
-			conn,
-			"pb.Preamblesvc",
-			"Concat",
-			encodeGRPCConcatRequest,
-			decodeGRPCConcatResponse,
-			pb.ConcatReply{},
-			append(options, grpctransport.ClientBefore(opentracing.ContextToGRPC(otTracer, logger)))...,
-		).Endpoint()
-		concatEndpoint = opentracing.TraceClient(otTracer, "Concat")(concatEndpoint)
-		concatEndpoint = limiter(concatEndpoint)
-		concatEndpoint = circuitbreaker.Gobreaker(gobreaker.NewCircuitBreaker(gobreaker.Settings{
-			Name:    "Concat",
+		preambleBatchEndpoint = pool.Endpoint(func(conn *grpc.ClientConn) endpoint.Endpoint {
+			return grpctransport.NewClient(
+				conn,
+				"pb.Preamblesvc",
+				"PreambleBatch",
+				encodeGRPCPreambleBatchRequest,
+				decodeGRPCPreambleBatchResponse,
+				pb.PreambleBatchReply{},
+				append(options, grpctransport.ClientBefore(opentracing.ContextToGRPC(otTracer, logger)))...,
+			).Endpoint()
+		})
+		preambleBatchEndpoint = opentracing.TraceClient(otTracer, "PreambleBatch")(preambleBatchEndpoint)
+		preambleBatchEndpoint = limiter(preambleBatchEndpoint)
+		preambleBatchEndpoint = circuitbreaker.Gobreaker(breakers.New(gobreaker.Settings{
+			Name:    "PreambleBatch",
 			Timeout: 30 * time.Second,
-		}))(concatEndpoint)
+		}))(preambleBatchEndpoint)
 	}
 
 	return endpoints.Endpoints{
-		PreambleEndpoint: sumEndpoint,
-		ConcatEndpoint:   concatEndpoint,
+		PreambleEndpoint:      preambleEndpoint,
+		PreambleBatchEndpoint: preambleBatchEndpoint,
 	}
 }
 
@@ -170,7 +216,7 @@ func NewGRPCClient(conn *grpc.ClientConn, otTracer stdopentracing.Tracer, zipkin
 // user-domain Preamble request to a gRPC Preamble request. Primarily useful in a client.
 func encodeGRPCPreambleRequest(_ context.Context, request interface{}) (interface{}, error) {
 	req := request.(endpoints.PreambleRequest)
-	return &pb.PreambleRequest{A: req.A, B: req.B}, nil
+	return &pb.PreambleRequest{Msg: req.Msg, Nci: req.NCI}, nil
 }
 
 // decodeGRPCPreambleResponse is a transport/grpc.DecodeResponseFunc that converts a
@@ -180,18 +226,30 @@ func decodeGRPCPreambleResponse(_ context.Context, grpcReply interface{}) (inter
 	return endpoints.PreambleResponse{Rs: reply.Rs}, nil
 }
 
-// encodeGRPCConcatRequest is a transport/grpc.EncodeRequestFunc that converts a
-// user-domain Concat request to a gRPC Concat request. Primarily useful in a client.
-func encodeGRPCConcatRequest(_ context.Context, request interface{}) (interface{}, error) {
-	req := request.(endpoints.ConcatRequest)
-	return &pb.ConcatRequest{A: req.A, B: req.B}, nil
+// encodeGRPCPreambleBatchRequest is a transport/grpc.EncodeRequestFunc that converts a
+// user-domain PreambleBatch request to a gRPC PreambleBatch request. Primarily useful in a client.
+func encodeGRPCPreambleBatchRequest(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(endpoints.PreambleBatchRequest)
+	preambles := make([]*pb.PreambleRequest, len(req.Preambles))
+	for i, p := range req.Preambles {
+		preambles[i] = &pb.PreambleRequest{Msg: p.Msg, Nci: p.NCI}
+	}
+	return &pb.PreambleBatchRequest{Preambles: preambles}, nil
 }
 
-// decodeGRPCConcatResponse is a transport/grpc.DecodeResponseFunc that converts a
-// gRPC Concat reply to a user-domain Concat response. Primarily useful in a client.
-func decodeGRPCConcatResponse(_ context.Context, grpcReply interface{}) (interface{}, error) {
-	reply := grpcReply.(*pb.ConcatReply)
-	return endpoints.ConcatResponse{Rs: reply.Rs}, nil
+// decodeGRPCPreambleBatchResponse is a transport/grpc.DecodeResponseFunc that converts a
+// gRPC PreambleBatch reply to a user-domain PreambleBatch response. Primarily useful in a client.
+func decodeGRPCPreambleBatchResponse(_ context.Context, grpcReply interface{}) (interface{}, error) {
+	reply := grpcReply.(*pb.PreambleBatchReply)
+	results := make([]endpoints.PreambleResult, len(reply.Results))
+	for i, r := range reply.Results {
+		results[i] = endpoints.PreambleResult{Rs: r.GetRs(), Err: r.GetErr()}
+	}
+	var err error
+	if reply.Err != "" {
+		err = errors.New(reply.Err)
+	}
+	return endpoints.PreambleBatchResponse{Results: results, Err: err}, nil
 }
 
 func grpcEncodeError(err error) error {
@@ -21,14 +21,23 @@ import (
 	"github.com/go-kit/kit/tracing/opentracing"
 	"github.com/go-kit/kit/tracing/zipkin"
 	httptransport "github.com/go-kit/kit/transport/http"
+	"github.com/golang/protobuf/proto"
 	stdopentracing "github.com/opentracing/opentracing-go"
 	stdzipkin "github.com/openzipkin/zipkin-go"
 	"github.com/sony/gobreaker"
 	"golang.org/x/time/rate"
 	"google.golang.org/grpc/status"
 
+	pb "github.com/miki-tnt/sa5g-go-usvc-k8s/pb/preamblesvc"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/breakers"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/caller"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/codec"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/logging"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/meta"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/openapi"
 	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/preamblesvc/endpoints"
 	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/preamblesvc/service"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/idempotency"
 )
 
 type errorWrapper struct {
@@ -58,37 +67,46 @@ func NewHTTPHandler(endpoints endpoints.Endpoints, otTracer stdopentracing.Trace
 
 	options := []httptransport.ServerOption{
 		httptransport.ServerErrorEncoder(httpEncodeError),
-		httptransport.ServerErrorLogger(logger),
+		httptransport.ServerErrorHandler(logging.ErrorHandler(logger)),
+		httptransport.ServerBefore(idempotency.HTTPToContext()),
+		httptransport.ServerBefore(caller.HTTPToContext()),
+		httptransport.ServerBefore(meta.HTTPToContext()),
+		httptransport.ServerBefore(codec.Default.HTTPToContext()),
 		zipkinServer,
 	}
 
 	m := http.NewServeMux()
 	m.Handle("/preamble", httptransport.NewServer(
 		endpoints.PreambleEndpoint,
-		decodeHTTPPreambleRequest,
-		httptransport.EncodeJSONResponse,
+		codec.DecodeRequest(preambleBinding),
+		codec.EncodeResponse(preambleBinding),
 		append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "Preamble", logger)))...,
 	))
+	m.Handle("/preambleBatch", httptransport.NewServer(
+		endpoints.PreambleBatchEndpoint,
+		codec.DecodeRequest(preambleBatchBinding),
+		codec.EncodeResponse(preambleBatchBinding),
+		append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "PreambleBatch", logger)))...,
+	))
+	m.Handle(openapi.Path, openapi.Handler(OpenAPI()))
 	return m
 }
 
-// decodeHTTPPreambleRequest is a transport/http.DecodeRequestFunc that decodes a
-// JSON-encoded request from the HTTP request body. Primarily useful in a server.
-func decodeHTTPPreambleRequest(_ context.Context, r *http.Request) (interface{}, error) {
-	var req endpoints.PreambleRequest
-	err := json.NewDecoder(r.Body).Decode(&req)
-	return req, err
+var preambleBinding = codec.Binding{
+	Request:   func() interface{} { return &endpoints.PreambleRequest{} },
+	PBRequest: func() proto.Message { return &pb.PreambleRequest{} },
+	FromPB:    decodeGRPCPreambleRequest,
+	ToPB:      encodeGRPCPreambleResponse,
 }
 
-// decodeHTTPConcatRequest is a transport/http.DecodeRequestFunc that decodes a
-// JSON-encoded request from the HTTP request body. Primarily useful in a server.
-func decodeHTTPConcatRequest(_ context.Context, r *http.Request) (interface{}, error) {
-	var req endpoints.ConcatRequest
-	err := json.NewDecoder(r.Body).Decode(&req)
-	return req, err
+var preambleBatchBinding = codec.Binding{
+	Request:   func() interface{} { return &endpoints.PreambleBatchRequest{} },
+	PBRequest: func() proto.Message { return &pb.PreambleBatchRequest{} },
+	FromPB:    decodeGRPCPreambleBatchRequest,
+	ToPB:      encodeGRPCPreambleBatchResponse,
 }
 
-// NewHTTPClient returns an AddService backed by an HTTP server living at the
+// NewHTTPClient returns an PreamblesvcService backed by an HTTP server living at the
 // remote instance. We expect instance to come from a service discovery system,
 // so likely of the form "host:port". We bake-in certain middlewares,
 // implementing the client library pattern.
@@ -116,6 +134,8 @@ func NewHTTPClient(instance string, otTracer stdopentracing.Tracer, zipkinTracer
 
 	// global client middlewares
 	options := []httptransport.ClientOption{
+		httptransport.ClientBefore(idempotency.ContextToHTTP()),
+		httptransport.ClientBefore(meta.ContextToHTTP()),
 		zipkinClient,
 	}
 
@@ -139,32 +159,32 @@ func NewHTTPClient(instance string, otTracer stdopentracing.Tracer, zipkinTracer
 		preambleEndpoint = opentracing.TraceClient(otTracer, "Preamble")(preambleEndpoint)
 		preambleEndpoint = zipkin.TraceEndpoint(zipkinTracer, "Preamble")(preambleEndpoint)
 		preambleEndpoint = limiter(preambleEndpoint)
-		preambleEndpoint = circuitbreaker.Gobreaker(gobreaker.NewCircuitBreaker(gobreaker.Settings{
+		preambleEndpoint = circuitbreaker.Gobreaker(breakers.New(gobreaker.Settings{
 			Name:    "Preamble",
 			Timeout: 30 * time.Second,
 		}))(preambleEndpoint)
 		e.PreambleEndpoint = preambleEndpoint
 	}
 
-	// The Concat endpoint is the same thing, with slightly different
+	// The PreambleBatch endpoint is the same thing, with slightly different
 	// middlewares to demonstrate how to specialize per-endpoint.
-	var concatEndpoint endpoint.Endpoint
+	var preambleBatchEndpoint endpoint.Endpoint
 	{
-		concatEndpoint = httptransport.NewClient(
+		preambleBatchEndpoint = httptransport.NewClient(
 			"POST",
-			copyURL(u, "/concat"),
-			encodeHTTPConcatRequest,
-			decodeHTTPConcatResponse,
+			copyURL(u, "/preambleBatch"),
+			encodeHTTPPreambleBatchRequest,
+			decodeHTTPPreambleBatchResponse,
 			append(options, httptransport.ClientBefore(opentracing.ContextToHTTP(otTracer, logger)))...,
 		).Endpoint()
-		concatEndpoint = opentracing.TraceClient(otTracer, "Concat")(concatEndpoint)
-		concatEndpoint = zipkin.TraceEndpoint(zipkinTracer, "Concat")(concatEndpoint)
-		concatEndpoint = limiter(concatEndpoint)
-		concatEndpoint = circuitbreaker.Gobreaker(gobreaker.NewCircuitBreaker(gobreaker.Settings{
-			Name:    "Concat",
+		preambleBatchEndpoint = opentracing.TraceClient(otTracer, "PreambleBatch")(preambleBatchEndpoint)
+		preambleBatchEndpoint = zipkin.TraceEndpoint(zipkinTracer, "PreambleBatch")(preambleBatchEndpoint)
+		preambleBatchEndpoint = limiter(preambleBatchEndpoint)
+		preambleBatchEndpoint = circuitbreaker.Gobreaker(breakers.New(gobreaker.Settings{
+			Name:    "PreambleBatch",
 			Timeout: 30 * time.Second,
-		}))(concatEndpoint)
-		e.ConcatEndpoint = concatEndpoint
+		}))(preambleBatchEndpoint)
+		e.PreambleBatchEndpoint = preambleBatchEndpoint
 	}
 
 	// Returning the endpoint.Set as a service.Service relies on the
@@ -204,9 +224,9 @@ func decodeHTTPPreambleResponse(_ context.Context, r *http.Response) (interface{
 	return resp, err
 }
 
-// encodeHTTPConcatRequest is a transport/http.EncodeRequestFunc that
+// encodeHTTPPreambleBatchRequest is a transport/http.EncodeRequestFunc that
 // JSON-encodes any request to the request body. Primarily useful in a client.
-func encodeHTTPConcatRequest(_ context.Context, r *http.Request, request interface{}) (err error) {
+func encodeHTTPPreambleBatchRequest(_ context.Context, r *http.Request, request interface{}) (err error) {
 	var buf bytes.Buffer
 	if err := json.NewEncoder(&buf).Encode(request); err != nil {
 		return err
@@ -215,15 +235,15 @@ func encodeHTTPConcatRequest(_ context.Context, r *http.Request, request interfa
 	return nil
 }
 
-// decodeHTTPConcatResponse is a transport/http.DecodeResponseFunc that decodes a
+// decodeHTTPPreambleBatchResponse is a transport/http.DecodeResponseFunc that decodes a
 // JSON-encoded preamble response from the HTTP response body. If the response has a
 // non-200 status code, we will interpret that as an error and attempt to decode
 // the specific error message from the response body. Primarily useful in a client.
-func decodeHTTPConcatResponse(_ context.Context, r *http.Response) (interface{}, error) {
+func decodeHTTPPreambleBatchResponse(_ context.Context, r *http.Response) (interface{}, error) {
 	if r.StatusCode != http.StatusOK {
 		return nil, JSONErrorDecoder(r)
 	}
-	var resp endpoints.ConcatResponse
+	var resp endpoints.PreambleBatchResponse
 	err := json.NewDecoder(r.Body).Decode(&resp)
 	return resp, err
 }
@@ -0,0 +1,27 @@
+package transports
+
+import (
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/openapi"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/preamblesvc/endpoints"
+)
+
+// OpenAPI returns the description of the HTTP transport, served by
+// NewHTTPHandler on openapi.Path.
+func OpenAPI() *openapi.Document {
+	return openapi.New("preamblesvc", "v1",
+		openapi.Operation{
+			Path:     "/preamble",
+			ID:       "Preamble",
+			Summary:  "Preamble processes one random access preamble.",
+			Request:  endpoints.PreambleRequest{},
+			Response: endpoints.PreambleResponse{},
+		},
+		openapi.Operation{
+			Path:     "/preambleBatch",
+			ID:       "PreambleBatch",
+			Summary:  "PreambleBatch processes up to 64 preambles, each with its own result.",
+			Request:  endpoints.PreambleBatchRequest{},
+			Response: endpoints.PreambleBatchResponse{},
+		},
+	)
+}
@@ -0,0 +1,253 @@
+package priority
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/metrics"
+	"github.com/go-kit/kit/metrics/discard"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultMaxWait is the longest a request waits in the queue, unless set
+// otherwise.
+const DefaultMaxWait = time.Second
+
+// ErrShed is returned for a request shed under overload: turned away, or
+// preempted in the queue by a request of a higher priority, or waiting for
+// longer than the Admission allows.
+var ErrShed = status.Error(codes.ResourceExhausted, "overloaded, request shed")
+
+// AdmissionOption sets an optional parameter of an Admission.
+type AdmissionOption func(*Admission)
+
+// MaxWait sets the longest a request waits in the queue before being shed.
+func MaxWait(d time.Duration) AdmissionOption {
+	return func(a *Admission) { a.maxWait = d }
+}
+
+// QueueDepthGauge sets the gauge reporting the number of queued requests,
+// with the label "priority" set to their level.
+func QueueDepthGauge(g metrics.Gauge) AdmissionOption {
+	return func(a *Admission) { a.depthGauge = g }
+}
+
+// ShedCounter sets the counter incremented for every shed request, with the
+// label "priority" set to its level.
+func ShedCounter(c metrics.Counter) AdmissionOption {
+	return func(a *Admission) { a.shedCounter = c }
+}
+
+// Admission admits up to a number of concurrent requests to a service. The
+// requests over the limit wait in a queue, by priority level and then in
+// their order of arrival; when the queue is full, a request of a higher
+// priority than the lowest queued one takes its place and the other is shed.
+// Emergency requests go through at once, over the limit if need be.
+type Admission struct {
+	limit       int
+	queueSize   int
+	maxWait     time.Duration
+	depthGauge  metrics.Gauge
+	shedCounter metrics.Counter
+
+	mtx     sync.Mutex
+	running int
+	queued  int
+	// the waiting requests of each level, oldest first
+	queues [Lowest + 1][]*waiter
+	sheds  [Lowest + 1]int64
+}
+
+type waiter struct {
+	level Level
+	// receives nil when admitted, ErrShed when preempted
+	done chan error
+}
+
+// NewAdmission returns an Admission running up to limit requests at once,
+// with room for queueSize more to wait. A limit of zero or less admits every
+// request: NewAdmission returns nil, which Middleware accepts.
+func NewAdmission(limit, queueSize int, options ...AdmissionOption) *Admission {
+	if limit <= 0 {
+		return nil
+	}
+	if queueSize < 0 {
+		queueSize = 0
+	}
+	a := &Admission{
+		limit:       limit,
+		queueSize:   queueSize,
+		maxWait:     DefaultMaxWait,
+		depthGauge:  discard.NewGauge(),
+		shedCounter: discard.NewCounter(),
+	}
+	for _, option := range options {
+		option(a)
+	}
+	return a
+}
+
+// Middleware returns an endpoint middleware admitting the requests with a.
+// The endpoints of a service share its Admission. A nil Admission admits
+// every request.
+func Middleware(a *Admission) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		if a == nil {
+			return next
+		}
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			if err := a.acquire(ctx, FromContext(ctx)); err != nil {
+				return nil, err
+			}
+			defer a.release()
+			return next(ctx, request)
+		}
+	}
+}
+
+// acquire waits for the admission of a request of level l.
+func (a *Admission) acquire(ctx context.Context, l Level) error {
+	if l < Emergency || l > Lowest {
+		l = Default
+	}
+
+	a.mtx.Lock()
+	if l == Emergency || (a.running < a.limit && a.queued == 0) {
+		a.running++
+		a.mtx.Unlock()
+		return nil
+	}
+	if a.queued >= a.queueSize {
+		victim := a.lowest()
+		if victim == nil || victim.level <= l {
+			a.shed(l)
+			a.mtx.Unlock()
+			return ErrShed
+		}
+		a.remove(victim)
+		victim.done <- ErrShed
+		a.shed(victim.level)
+	}
+	w := &waiter{level: l, done: make(chan error, 1)}
+	a.queues[l] = append(a.queues[l], w)
+	a.queued++
+	a.depthGauge.With("priority", l.String()).Set(float64(len(a.queues[l])))
+	a.mtx.Unlock()
+
+	timer := time.NewTimer(a.maxWait)
+	defer timer.Stop()
+	var err error
+	select {
+	case err := <-w.done:
+		return err
+	case <-timer.C:
+		err = ErrShed
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	a.mtx.Lock()
+	removed := a.remove(w)
+	a.mtx.Unlock()
+	if !removed {
+		// admitted or preempted meanwhile
+		if admitErr := <-w.done; admitErr != nil {
+			return admitErr
+		}
+		a.release()
+	}
+	if err == ErrShed {
+		a.mtx.Lock()
+		a.shed(l)
+		a.mtx.Unlock()
+	}
+	return err
+}
+
+// release frees the slot of a request, handing it to the first waiting
+// request of the highest priority.
+func (a *Admission) release() {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	a.running--
+	for a.running < a.limit {
+		w := a.highest()
+		if w == nil {
+			return
+		}
+		a.remove(w)
+		a.running++
+		w.done <- nil
+	}
+}
+
+// highest returns the oldest waiting request of the highest priority, the
+// lock held.
+func (a *Admission) highest() *waiter {
+	for l := Emergency; l <= Lowest; l++ {
+		if q := a.queues[l]; len(q) > 0 {
+			return q[0]
+		}
+	}
+	return nil
+}
+
+// lowest returns the newest waiting request of the lowest priority, the lock
+// held.
+func (a *Admission) lowest() *waiter {
+	for l := Lowest; l >= Emergency; l-- {
+		if q := a.queues[l]; len(q) > 0 {
+			return q[len(q)-1]
+		}
+	}
+	return nil
+}
+
+// remove takes w out of its queue, the lock held, and tells whether it was
+// still there.
+func (a *Admission) remove(w *waiter) bool {
+	q := a.queues[w.level]
+	for i, other := range q {
+		if other == w {
+			a.queues[w.level] = append(q[:i], q[i+1:]...)
+			a.queued--
+			a.depthGauge.With("priority", w.level.String()).Set(float64(len(a.queues[w.level])))
+			return true
+		}
+	}
+	return false
+}
+
+// shed counts a shed request of level l, the lock held.
+func (a *Admission) shed(l Level) {
+	a.sheds[l]++
+	a.shedCounter.With("priority", l.String()).Add(1)
+}
+
+// Stats is a point in time view of an Admission. Queued and Shed are keyed
+// by priority level, the levels without requests left out.
+type Stats struct {
+	Limit   int              `json:"limit"`
+	Running int              `json:"running"`
+	Queued  map[string]int   `json:"queued"`
+	Shed    map[string]int64 `json:"shed"`
+}
+
+// Stats returns the current state of a.
+func (a *Admission) Stats() Stats {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+	s := Stats{Limit: a.limit, Running: a.running, Queued: map[string]int{}, Shed: map[string]int64{}}
+	for l := Emergency; l <= Lowest; l++ {
+		if n := len(a.queues[l]); n > 0 {
+			s.Queued[l.String()] = n
+		}
+		if n := a.sheds[l]; n > 0 {
+			s.Shed[l.String()] = n
+		}
+	}
+	return s
+}
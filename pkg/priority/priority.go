@@ -0,0 +1,140 @@
+// Package priority carries the allocation and retention priority (ARP) of a
+// request and admits the requests by it under overload. The priority level
+// travels with the request as gRPC metadata or an HTTP header, like the
+// PLMN, and the Admission of a service queues the requests it has no room
+// for, highest priority first, shedding the lowest ones when the queue is
+// full. Emergency requests are never queued nor shed.
+//
+// The level is declared by the caller and not checked: it is meant for the
+// NFs of the core, not for the clients outside of it.
+package priority
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/go-kit/kit/endpoint"
+	grpctransport "github.com/go-kit/kit/transport/grpc"
+	httptransport "github.com/go-kit/kit/transport/http"
+	"google.golang.org/grpc/metadata"
+)
+
+// MetadataKey is the gRPC metadata key carrying the priority level of a
+// request, HeaderKey the HTTP header.
+const (
+	MetadataKey = "x-arp-priority"
+	HeaderKey   = "X-Arp-Priority"
+)
+
+// Level is an ARP priority level (TS 23.501 5.7.2.2), from 1, the highest,
+// to 15, the lowest.
+type Level int
+
+// The levels with a meaning of their own.
+const (
+	// Emergency is the level of the emergency registrations and sessions,
+	// always admitted.
+	Emergency Level = 1
+	// Default is the level of the requests that do not declare one.
+	Default Level = 8
+	// Lowest is the lowest level.
+	Lowest Level = 15
+)
+
+// Parse parses a level written in decimal, from 1 to 15.
+func Parse(s string) (Level, bool) {
+	n, err := strconv.Atoi(s)
+	if err != nil || n < int(Emergency) || n > int(Lowest) {
+		return 0, false
+	}
+	return Level(n), true
+}
+
+func (l Level) String() string {
+	return strconv.Itoa(int(l))
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying the level l.
+func NewContext(ctx context.Context, l Level) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the level carried by ctx, Default when it carries
+// none.
+func FromContext(ctx context.Context) Level {
+	if l, ok := ctx.Value(contextKey{}).(Level); ok {
+		return l
+	}
+	return Default
+}
+
+// IsEmergency tells whether the request of ctx is an emergency.
+func IsEmergency(ctx context.Context) bool {
+	return FromContext(ctx) == Emergency
+}
+
+// GRPCToContext moves the level of the incoming metadata to the context. A
+// malformed value is ignored.
+func GRPCToContext() grpctransport.ServerRequestFunc {
+	return func(ctx context.Context, md metadata.MD) context.Context {
+		v := md.Get(MetadataKey)
+		if len(v) == 0 {
+			return ctx
+		}
+		l, ok := Parse(v[0])
+		if !ok {
+			return ctx
+		}
+		return NewContext(ctx, l)
+	}
+}
+
+// ContextToGRPC moves the level of the context to the outgoing metadata, so
+// that the requests made on behalf of a request keep its priority.
+func ContextToGRPC() grpctransport.ClientRequestFunc {
+	return func(ctx context.Context, md *metadata.MD) context.Context {
+		if l, ok := ctx.Value(contextKey{}).(Level); ok {
+			(*md)[MetadataKey] = []string{l.String()}
+		}
+		return ctx
+	}
+}
+
+// HTTPToContext moves the level of the request header to the context. A
+// malformed value is ignored.
+func HTTPToContext() httptransport.RequestFunc {
+	return func(ctx context.Context, r *http.Request) context.Context {
+		l, ok := Parse(r.Header.Get(HeaderKey))
+		if !ok {
+			return ctx
+		}
+		return NewContext(ctx, l)
+	}
+}
+
+// ContextToHTTP moves the level of the context to the request header.
+func ContextToHTTP() httptransport.RequestFunc {
+	return func(ctx context.Context, r *http.Request) context.Context {
+		if l, ok := ctx.Value(contextKey{}).(Level); ok {
+			r.Header.Set(HeaderKey, l.String())
+		}
+		return ctx
+	}
+}
+
+// Bypass returns mw skipped by the emergency requests. It wraps the limits
+// that must not turn an emergency away, such as the rate limits.
+func Bypass(mw endpoint.Middleware) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		limited := mw(next)
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			if IsEmergency(ctx) {
+				return next(ctx, request)
+			}
+			return limited(ctx, request)
+		}
+	}
+}
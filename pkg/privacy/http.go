@@ -0,0 +1,95 @@
+package privacy
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-kit/kit/log/level"
+)
+
+// Path and RevealPath are where services mount Handler and RevealHandler on
+// their admin server.
+const (
+	Path       = "/admin/privacy"
+	RevealPath = "/admin/privacy/reveal"
+)
+
+// RevealRequest is the body of a POST to RevealHandler.
+type RevealRequest struct {
+	Pseudonym string `json:"pseudonym"`
+	// Reason is why the pseudonym is revealed, logged with it.
+	Reason string `json:"reason"`
+}
+
+// RevealResponse is the body returned by RevealHandler.
+type RevealResponse struct {
+	Pseudonym string `json:"pseudonym"`
+	ID        string `json:"id"`
+}
+
+type errorWrapper struct {
+	Error string `json:"error"`
+}
+
+// Handler serves the Status of p to GET requests.
+func Handler(p *Pseudonymizer) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, nil)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(p.Status())
+	})
+}
+
+// RevealHandler reveals the pseudonym of a RevealRequest POSTed to it, when
+// p has reveals enabled and the request gives its reason. Every reveal is
+// logged, with its reason and the address it comes from, but not the
+// identifier revealed.
+func RevealHandler(p *Pseudonymizer) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			w.Header().Set("Allow", "POST")
+			writeError(w, http.StatusMethodNotAllowed, nil)
+			return
+		}
+		if !p.revealEnabled {
+			writeError(w, http.StatusForbidden, errors.New("privacy: reveals disabled"))
+			return
+		}
+		var rr RevealRequest
+		if err := json.NewDecoder(req.Body).Decode(&rr); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		if rr.Pseudonym == "" || rr.Reason == "" {
+			writeError(w, http.StatusBadRequest, errors.New("privacy: a pseudonym and a reason are required"))
+			return
+		}
+		id, err := p.Reveal(req.Context(), rr.Pseudonym)
+		level.Warn(p.logger).Log("reveal", rr.Pseudonym, "reason", rr.Reason, "remote", req.RemoteAddr, "revealed", err == nil)
+		switch err {
+		case nil:
+		case ErrUnknownKey, ErrNotFound:
+			writeError(w, http.StatusNotFound, err)
+			return
+		default:
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(RevealResponse{Pseudonym: rr.Pseudonym, ID: id})
+	})
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	msg := http.StatusText(code)
+	if err != nil {
+		msg = err.Error()
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(errorWrapper{Error: msg})
+}
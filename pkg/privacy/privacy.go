@@ -0,0 +1,317 @@
+// Package privacy pseudonymizes the subscriber identifiers, the SUPIs
+// foremost, in what the services export for debugging: their logs, the
+// events streamed to the dashboards, the calls captured and the labels of
+// the series, should one be labelled with a UE. A Pseudonymizer maps an
+// identifier to the same pseudonym wherever it is exported, with an
+// HMAC-SHA-256 keyed by a Kubernetes secret, so that the records of a
+// subscriber can be joined across them without naming it, and shared.
+//
+// The keys are the files of a directory, the secret mounted as a volume,
+// named after their IDs. The current key is the one of the greatest ID,
+// such as "2026-10" of "2026-09" and "2026-10": a key is rotated by adding a
+// newer one to the secret, the kubelet updating the volume, and the
+// Pseudonymizer reads the directory again every reload period. The older
+// keys stay in the secret for the pseudonyms they made to be revealed, until
+// those are no longer of interest. A pseudonym names its key:
+// "imsi-208930000000001" becomes "imsi-20893#2026-10.4b3c0d1e5f60".
+//
+// Without keys, the pseudonyms are those of logging.Pseudonym, unkeyed,
+// which hashing the SUPIs of a PLMN reverses.
+//
+// Reveal maps a pseudonym back to its identifier, for an operator chasing
+// the problem of a subscriber: among the pseudonyms the Pseudonymizer made
+// lately, or else among the candidates it is given, such as the subscribers
+// of the UDM. Its admin API is off unless enabled, asks for a reason and
+// logs every reveal (see RevealHandler).
+package privacy
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/clock"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/logging"
+)
+
+// DefaultReload is the period the keys are read again with, DefaultRecent
+// the number of the last pseudonyms made that Reveal finds without
+// candidates.
+const (
+	DefaultReload = time.Minute
+	DefaultRecent = 1 << 16
+)
+
+// minKeySize is the minimum size of a key, in bytes.
+const minKeySize = 16
+
+// The errors of Reveal.
+var (
+	ErrUnknownKey = errors.New("privacy: unknown key")
+	ErrNotFound   = errors.New("privacy: pseudonym not found")
+)
+
+// Option sets an optional parameter of a Pseudonymizer.
+type Option func(*Pseudonymizer)
+
+// Logger sets the logger of the key rotations and reveals.
+func Logger(logger log.Logger) Option {
+	return func(p *Pseudonymizer) { p.logger = logger }
+}
+
+// Reload sets the period the keys are read again with, DefaultReload by
+// default.
+func Reload(d time.Duration) Option {
+	return func(p *Pseudonymizer) { p.reload = d }
+}
+
+// RevealEnabled enables the reveals of RevealHandler, off by default.
+func RevealEnabled(enabled bool) Option {
+	return func(p *Pseudonymizer) { p.revealEnabled = enabled }
+}
+
+// Clock sets the clock of the reloads, clock.Real by default.
+func Clock(c clock.Clock) Option {
+	return func(p *Pseudonymizer) { p.clock = c }
+}
+
+// Pseudonymizer makes the pseudonyms of the identifiers. A nil Pseudonymizer
+// makes unkeyed ones.
+type Pseudonymizer struct {
+	dir           string
+	reload        time.Duration
+	logger        log.Logger
+	revealEnabled bool
+	clock         clock.Clock
+
+	mtx        sync.Mutex
+	candidates func(ctx context.Context) ([]string, error)
+	keys       map[string][]byte
+	current    string
+	loaded     time.Time
+	loading    bool
+	// ids are the identifiers of the recent pseudonyms of the current
+	// key, pseudonyms the other way round, recent the pseudonyms in the
+	// order they were made, next the oldest of them once full.
+	ids        map[string]string
+	pseudonyms map[string]string
+	recent     []string
+	next       int
+}
+
+// New returns the Pseudonymizer of the keys of dir, which makes unkeyed
+// pseudonyms when dir is empty. It fails when the keys cannot be read.
+func New(dir string, options ...Option) (*Pseudonymizer, error) {
+	p := &Pseudonymizer{
+		dir:    dir,
+		reload: DefaultReload,
+		logger: log.NewNopLogger(),
+		clock:  clock.Real,
+		recent: make([]string, 0, DefaultRecent),
+	}
+	for _, option := range options {
+		option(p)
+	}
+	if dir == "" {
+		return p, nil
+	}
+	keys, err := readKeys(dir)
+	if err != nil {
+		return nil, err
+	}
+	p.setKeys(keys)
+	return p, nil
+}
+
+// Pseudonym returns the pseudonym of id under the current key.
+func (p *Pseudonymizer) Pseudonym(id string) string {
+	if p == nil || p.dir == "" || id == "" {
+		return logging.Pseudonym(id)
+	}
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	if now := p.clock.Now(); !p.loading && now.Sub(p.loaded) >= p.reload {
+		p.loading = true
+		go p.load()
+	}
+	if ps, ok := p.pseudonyms[id]; ok {
+		return ps
+	}
+	ps := pseudonym(p.current, p.keys[p.current], id)
+	if len(p.recent) < cap(p.recent) {
+		p.recent = append(p.recent, ps)
+	} else {
+		delete(p.pseudonyms, p.ids[p.recent[p.next]])
+		delete(p.ids, p.recent[p.next])
+		p.recent[p.next] = ps
+		p.next = (p.next + 1) % len(p.recent)
+	}
+	p.ids[ps], p.pseudonyms[id] = id, ps
+	return ps
+}
+
+// Reveal returns the identifier of the pseudonym ps: one of the recent ones,
+// or else the candidate whose pseudonym under the key of ps, or unkeyed, it
+// is. Its callers are to log why.
+func (p *Pseudonymizer) Reveal(ctx context.Context, ps string) (string, error) {
+	keyID := keyOf(ps)
+	p.mtx.Lock()
+	id, ok := p.ids[ps]
+	key := p.keys[keyID]
+	candidatesOf := p.candidates
+	p.mtx.Unlock()
+	switch {
+	case ok:
+		return id, nil
+	case keyID != "" && key == nil:
+		return "", ErrUnknownKey
+	case candidatesOf == nil:
+		return "", ErrNotFound
+	}
+	of := logging.Pseudonym
+	if keyID != "" {
+		of = func(id string) string { return pseudonym(keyID, key, id) }
+	}
+	candidates, err := candidatesOf(ctx)
+	if err != nil {
+		return "", err
+	}
+	for _, id := range candidates {
+		if of(id) == ps {
+			return id, nil
+		}
+	}
+	return "", ErrNotFound
+}
+
+// SetCandidates sets the identifiers Reveal looks the pseudonyms it did not
+// make lately up among.
+func (p *Pseudonymizer) SetCandidates(candidates func(ctx context.Context) ([]string, error)) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	p.candidates = candidates
+}
+
+// Status is the state of a Pseudonymizer.
+type Status struct {
+	// Keys are the IDs of the keys, Current the one of the current key,
+	// none without keys.
+	Keys    []string `json:"keys"`
+	Current string   `json:"current,omitempty"`
+	// Recent is the number of the recent pseudonyms.
+	Recent        int  `json:"recent"`
+	RevealEnabled bool `json:"reveal_enabled"`
+}
+
+// Status returns the state of p, its keys named but not shown.
+func (p *Pseudonymizer) Status() Status {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	s := Status{Keys: []string{}, Current: p.current, Recent: len(p.ids), RevealEnabled: p.revealEnabled}
+	for id := range p.keys {
+		s.Keys = append(s.Keys, id)
+	}
+	sort.Strings(s.Keys)
+	return s
+}
+
+func (p *Pseudonymizer) load() {
+	keys, err := readKeys(p.dir)
+	if err != nil {
+		level.Error(p.logger).Log("keys", p.dir, "err", err)
+	}
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	if err == nil {
+		p.setKeys(keys)
+	}
+	p.loading = false
+	p.loaded = p.clock.Now()
+}
+
+// setKeys replaces the keys of p, forgetting the recent pseudonyms when the
+// current key changes.
+func (p *Pseudonymizer) setKeys(keys map[string][]byte) {
+	current := ""
+	for id := range keys {
+		if id > current {
+			current = id
+		}
+	}
+	if current != p.current || !bytes.Equal(keys[current], p.keys[current]) {
+		if p.current != "" {
+			level.Info(p.logger).Log("key", current, "rotated_from", p.current)
+		}
+		p.current = current
+		p.ids, p.pseudonyms = make(map[string]string), make(map[string]string)
+		p.recent, p.next = p.recent[:0], 0
+	}
+	p.keys, p.loaded = keys, p.clock.Now()
+}
+
+// readKeys returns the keys of dir by ID, the names of its files. The
+// entries starting with a dot, those of the kubelet among them, are not
+// keys.
+func readKeys(dir string) (map[string][]byte, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	keys := make(map[string][]byte)
+	for _, f := range files {
+		id := f.Name()
+		if strings.HasPrefix(id, ".") {
+			continue
+		}
+		// the files of a secret volume are symbolic links
+		if fi, err := os.Stat(filepath.Join(dir, id)); err != nil || fi.IsDir() {
+			continue
+		}
+		if strings.ContainsAny(id, "#. ") {
+			return nil, fmt.Errorf("privacy: key ID %q has a '#', a '.' or a space", id)
+		}
+		key, err := ioutil.ReadFile(filepath.Join(dir, id))
+		if err != nil {
+			return nil, err
+		}
+		if key = []byte(strings.TrimSpace(string(key))); len(key) < minKeySize {
+			return nil, fmt.Errorf("privacy: key %s shorter than %d bytes", id, minKeySize)
+		}
+		keys[id] = key
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("privacy: no key in %s", dir)
+	}
+	return keys, nil
+}
+
+// pseudonym returns the pseudonym of id under the key keyID.
+func pseudonym(keyID string, key []byte, id string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(id))
+	return logging.PseudonymPrefix(id) + "#" + keyID + "." + hex.EncodeToString(mac.Sum(nil)[:6])
+}
+
+// keyOf returns the ID of the key of the pseudonym ps, none when unkeyed.
+func keyOf(ps string) string {
+	i := strings.LastIndexByte(ps, '#')
+	j := strings.LastIndexByte(ps, '.')
+	if i < 0 || j < i {
+		return ""
+	}
+	return ps[i+1 : j]
+}
@@ -0,0 +1,228 @@
+// Package profiling profiles an NF continuously, for the regressions of its
+// codecs and state machines to be tracked from one version to the next.
+// Every period, a Profiler takes a CPU profile of the period and a heap
+// profile at its end, and pushes both, labelled with the NF and its
+// version, to a profiling server:
+//
+//	http://pyroscope:4040    the ingest API of a Pyroscope server
+//	grpc://parca:7070        the profile store of a Parca server, over gRPC
+//
+// The profiles are those of runtime/pprof, in the pprof format. A CPU
+// profile already being taken, from /debug/pprof/profile of the admin port
+// for instance, the period goes without one.
+package profiling
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"runtime/pprof"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"google.golang.org/grpc"
+)
+
+// DefaultPeriod is the period of the profiles by default, that of a CPU
+// profile and between two heap profiles.
+const DefaultPeriod = 10 * time.Second
+
+// The types of the profiles.
+const (
+	CPU  = "cpu"
+	Heap = "heap"
+)
+
+// Option sets an optional parameter of a Profiler.
+type Option func(*Profiler)
+
+// Labels sets the labels of the profiles, pairs of name and value, such as
+// "nf_type" and "UDM".
+func Labels(keyvals ...string) Option {
+	return func(p *Profiler) {
+		if len(keyvals)%2 != 0 {
+			keyvals = append(keyvals, "unknown")
+		}
+		p.labels = keyvals
+	}
+}
+
+// Period sets the period of the profiles.
+func Period(d time.Duration) Option {
+	return func(p *Profiler) { p.period = d }
+}
+
+// Logger sets the logger of the profiles that fail to be taken or pushed.
+func Logger(logger log.Logger) Option {
+	return func(p *Profiler) { p.logger = logger }
+}
+
+// DialOptions sets the options of the connection to a Parca server,
+// plaintext by default.
+func DialOptions(options ...grpc.DialOption) Option {
+	return func(p *Profiler) { p.dialOptions = options }
+}
+
+// profile is a profile taken, gzipped in the pprof format.
+type profile struct {
+	typ         string
+	from, until time.Time
+	data        []byte
+}
+
+// pusher pushes the profiles of an application, with labels, to a server.
+type pusher interface {
+	push(ctx context.Context, app string, labels []string, p *profile) error
+	close() error
+}
+
+// Profiler takes the profiles of the process and pushes them.
+type Profiler struct {
+	url         string
+	app         string
+	labels      []string
+	period      time.Duration
+	logger      log.Logger
+	dialOptions []grpc.DialOption
+	pusher      pusher
+
+	mtx    sync.Mutex
+	pushed map[string]int64
+	failed map[string]int64
+	err    error
+	last   time.Time
+}
+
+// New returns the Profiler of the application app, its service name,
+// pushing to the server of rawurl, http or https for Pyroscope and grpc for
+// Parca.
+func New(rawurl, app string, options ...Option) (*Profiler, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, fmt.Errorf("profiling: %v", err)
+	}
+	shown := *u
+	shown.User = nil
+	p := &Profiler{
+		url:         shown.String(),
+		app:         app,
+		period:      DefaultPeriod,
+		logger:      log.NewNopLogger(),
+		dialOptions: []grpc.DialOption{grpc.WithInsecure()},
+		pushed:      map[string]int64{},
+		failed:      map[string]int64{},
+	}
+	for _, o := range options {
+		o(p)
+	}
+	if p.period <= 0 {
+		p.period = DefaultPeriod
+	}
+	switch u.Scheme {
+	case "http", "https":
+		p.pusher = newPyroscope(u)
+	case "grpc":
+		if u.Host == "" {
+			return nil, fmt.Errorf("profiling: %s has no host", p.url)
+		}
+		conn, err := grpc.Dial(u.Host, p.dialOptions...)
+		if err != nil {
+			return nil, fmt.Errorf("profiling: %v", err)
+		}
+		p.pusher = newParca(conn)
+	default:
+		return nil, fmt.Errorf("profiling: %s is not an http, https or grpc URL", p.url)
+	}
+	return p, nil
+}
+
+// Run profiles the process until ctx is done, and closes the connection to
+// the server then.
+func (p *Profiler) Run(ctx context.Context) {
+	defer p.pusher.close()
+	for {
+		from := time.Now()
+		var cpu bytes.Buffer
+		profiling := true
+		if err := pprof.StartCPUProfile(&cpu); err != nil {
+			level.Debug(p.logger).Log("profile", CPU, "err", err)
+			profiling = false
+		}
+		t := time.NewTimer(p.period)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			if profiling {
+				pprof.StopCPUProfile()
+			}
+			return
+		}
+		if profiling {
+			pprof.StopCPUProfile()
+			p.push(ctx, &profile{typ: CPU, from: from, until: time.Now(), data: cpu.Bytes()})
+		}
+		var heap bytes.Buffer
+		if err := pprof.Lookup("heap").WriteTo(&heap, 0); err != nil {
+			level.Warn(p.logger).Log("profile", Heap, "err", err)
+			continue
+		}
+		p.push(ctx, &profile{typ: Heap, from: from, until: time.Now(), data: heap.Bytes()})
+	}
+}
+
+func (p *Profiler) push(ctx context.Context, pr *profile) {
+	ctx, cancel := context.WithTimeout(ctx, p.period)
+	defer cancel()
+	err := p.pusher.push(ctx, p.app, p.labels, pr)
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	if err != nil {
+		p.failed[pr.typ]++
+		p.err = err
+		level.Warn(p.logger).Log("profiling", p.url, "profile", pr.typ, "err", err)
+		return
+	}
+	p.pushed[pr.typ]++
+	p.err = nil
+	p.last = pr.until
+}
+
+// Stats are the profiles pushed by a Profiler, served on the admin port, the
+// credentials of its URL aside.
+type Stats struct {
+	URL    string           `json:"url"`
+	Period string           `json:"period"`
+	Labels []string         `json:"labels"`
+	Pushed map[string]int64 `json:"pushed"`
+	Failed map[string]int64 `json:"failed"`
+	Last   time.Time        `json:"last,omitempty"`
+	Error  string           `json:"error,omitempty"`
+}
+
+// Stats returns the profiles pushed so far, by type.
+func (p *Profiler) Stats() Stats {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	s := Stats{
+		URL:    p.url,
+		Period: p.period.String(),
+		Labels: p.labels,
+		Pushed: make(map[string]int64, len(p.pushed)),
+		Failed: make(map[string]int64, len(p.failed)),
+		Last:   p.last,
+	}
+	for typ, n := range p.pushed {
+		s.Pushed[typ] = n
+	}
+	for typ, n := range p.failed {
+		s.Failed[typ] = n
+	}
+	if p.err != nil {
+		s.Error = p.err.Error()
+	}
+	return s
+}
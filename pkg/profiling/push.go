@@ -0,0 +1,118 @@
+package profiling
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc"
+
+	pb "github.com/miki-tnt/sa5g-go-usvc-k8s/pb/parca"
+)
+
+// pyroscope pushes to the ingest API of a Pyroscope server, the
+// application named with its labels as name{label=value,...}.
+type pyroscope struct {
+	url    url.URL
+	client *http.Client
+}
+
+func newPyroscope(u *url.URL) *pyroscope {
+	ingest := *u
+	ingest.Path = strings.TrimSuffix(ingest.Path, "/") + "/ingest"
+	return &pyroscope{url: ingest, client: &http.Client{}}
+}
+
+func (s *pyroscope) push(ctx context.Context, app string, labels []string, p *profile) error {
+	pairs := make([]string, 0, len(labels)/2)
+	for i := 0; i+1 < len(labels); i += 2 {
+		pairs = append(pairs, labels[i]+"="+labels[i+1])
+	}
+	sort.Strings(pairs)
+	q := url.Values{}
+	q.Set("name", app+"{"+strings.Join(pairs, ",")+"}")
+	q.Set("from", strconv.FormatInt(p.from.Unix(), 10))
+	q.Set("until", strconv.FormatInt(p.until.Unix(), 10))
+	q.Set("format", "pprof")
+	q.Set("spyName", "gospy")
+	if p.typ == CPU {
+		// the rate of the samples of runtime/pprof
+		q.Set("sampleRate", "100")
+	}
+	u := s.url
+	u.RawQuery = q.Encode()
+
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	part, err := w.CreateFormFile("profile", "profile.pprof")
+	if err != nil {
+		return err
+	}
+	part.Write(p.data)
+	if err := w.Close(); err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, u.String(), &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	resp, err := s.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("pyroscope: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	io.Copy(ioutil.Discard, resp.Body)
+	return nil
+}
+
+func (s *pyroscope) close() error { return nil }
+
+// The names of the series of a Parca server, by type of profile.
+var parcaNames = map[string]string{
+	CPU:  "process_cpu",
+	Heap: "memory",
+}
+
+// parca pushes to the profile store of a Parca server, the application
+// being the service_name label of the series.
+type parca struct {
+	conn   *grpc.ClientConn
+	client pb.ProfileStoreServiceClient
+}
+
+func newParca(conn *grpc.ClientConn) *parca {
+	return &parca{conn: conn, client: pb.NewProfileStoreServiceClient(conn)}
+}
+
+func (s *parca) push(ctx context.Context, app string, labels []string, p *profile) error {
+	ls := []*pb.Label{
+		{Name: "__name__", Value: parcaNames[p.typ]},
+		{Name: "service_name", Value: app},
+	}
+	for i := 0; i+1 < len(labels); i += 2 {
+		ls = append(ls, &pb.Label{Name: labels[i], Value: labels[i+1]})
+	}
+	sort.Slice(ls, func(i, j int) bool { return ls[i].Name < ls[j].Name })
+	_, err := s.client.WriteRaw(ctx, &pb.WriteRawRequest{
+		Series: []*pb.RawProfileSeries{{
+			Labels:  &pb.LabelSet{Labels: ls},
+			Samples: []*pb.RawSample{{RawProfile: p.data}},
+		}},
+	})
+	return err
+}
+
+func (s *parca) close() error { return s.conn.Close() }
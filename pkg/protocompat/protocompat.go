@@ -0,0 +1,353 @@
+// Package protocompat checks that a version of a protobuf API can be rolled
+// out next to the version it replaces: that the messages of each version
+// decode with the same meaning in the other, so that a client and a server
+// of different versions, during a rolling upgrade, understand each other.
+//
+// Check compares the declarations of the two versions; Replay goes further
+// and decodes, with the message types of the other version, messages with
+// every field of one version set, reporting the values that do not come out
+// the same. The messages of the versions are matched by their names in their
+// packages, the fields by their numbers.
+package protocompat
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// maxDepth bounds the nesting of the messages Replay fills in, for the
+// recursive ones.
+const maxDepth = 4
+
+// Problem is an incompatibility between two versions.
+type Problem struct {
+	// Message is the name of the message in its package.
+	Message string
+	// Field is the name of the field in the older version, if the problem is
+	// about a field.
+	Field  string
+	Reason string
+}
+
+func (p Problem) String() string {
+	if p.Field == "" {
+		return p.Message + ": " + p.Reason
+	}
+	return p.Message + "." + p.Field + ": " + p.Reason
+}
+
+// Check reports the declarations of next that break the messages of prev:
+//   - a message of prev missing from next;
+//   - a field number of prev reused with another type or cardinality;
+//   - a field of prev removed without reserving its number and its name;
+//   - a field of next using a number or a name reserved in prev.
+func Check(prev, next protoreflect.FileDescriptor) []Problem {
+	var problems []Problem
+	walk(prev.Messages(), func(pm protoreflect.MessageDescriptor) {
+		name := relName(prev, pm)
+		nm := find(next, name)
+		if nm == nil {
+			problems = append(problems, Problem{Message: name, Reason: "removed"})
+			return
+		}
+		pfs := pm.Fields()
+		for i := 0; i < pfs.Len(); i++ {
+			pf := pfs.Get(i)
+			nf := nm.Fields().ByNumber(pf.Number())
+			if nf == nil {
+				if !nm.ReservedRanges().Has(pf.Number()) {
+					problems = append(problems, Problem{name, string(pf.Name()), fmt.Sprintf("removed without reserving number %d", pf.Number())})
+				}
+				if !nm.ReservedNames().Has(pf.Name()) {
+					problems = append(problems, Problem{name, string(pf.Name()), "removed without reserving its name"})
+				}
+				continue
+			}
+			if reason := typeChange(prev, next, pf, nf); reason != "" {
+				problems = append(problems, Problem{name, string(pf.Name()), reason})
+			}
+		}
+		nfs := nm.Fields()
+		for i := 0; i < nfs.Len(); i++ {
+			nf := nfs.Get(i)
+			if pm.ReservedRanges().Has(nf.Number()) {
+				problems = append(problems, Problem{Message: name, Reason: fmt.Sprintf("field %s reuses the reserved number %d", nf.Name(), nf.Number())})
+			}
+			if pm.ReservedNames().Has(nf.Name()) {
+				problems = append(problems, Problem{Message: name, Reason: fmt.Sprintf("field %s reuses a reserved name", nf.Name())})
+			}
+		}
+	})
+	return problems
+}
+
+// typeChange describes how the type of a field changed between the versions,
+// or returns "" if it did not.
+func typeChange(prev, next protoreflect.FileDescriptor, pf, nf protoreflect.FieldDescriptor) string {
+	if pf.Cardinality() != nf.Cardinality() || pf.IsMap() != nf.IsMap() {
+		return fmt.Sprintf("number %d changed from %s to %s", pf.Number(), cardinality(pf), cardinality(nf))
+	}
+	if pf.IsMap() {
+		if reason := typeChange(prev, next, pf.MapKey(), nf.MapKey()); reason != "" {
+			return "map key: " + reason
+		}
+		if reason := typeChange(prev, next, pf.MapValue(), nf.MapValue()); reason != "" {
+			return "map value: " + reason
+		}
+		return ""
+	}
+	if pf.Kind() != nf.Kind() {
+		return fmt.Sprintf("number %d changed from %s to %s", pf.Number(), pf.Kind(), nf.Kind())
+	}
+	var pt, nt string
+	switch pf.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		pt, nt = typeName(prev, pf.Message()), typeName(next, nf.Message())
+	case protoreflect.EnumKind:
+		pt, nt = typeName(prev, pf.Enum()), typeName(next, nf.Enum())
+	}
+	if pt != nt {
+		return fmt.Sprintf("number %d changed from %s to %s", pf.Number(), pt, nt)
+	}
+	return ""
+}
+
+// Replay fills in every message of from, encodes it and decodes it as the
+// message of the same name of to, and reports the fields whose values are
+// lost or changed. The fields to does not declare, removed from it or added
+// after it, are expected to be dropped.
+func Replay(from, to protoreflect.FileDescriptor) []Problem {
+	var problems []Problem
+	walk(from.Messages(), func(fm protoreflect.MessageDescriptor) {
+		name := relName(from, fm)
+		tm := find(to, name)
+		if tm == nil {
+			return
+		}
+		src := newMessage(fm)
+		fill(src, 0)
+		b, err := proto.Marshal(src.Interface())
+		if err != nil {
+			problems = append(problems, Problem{Message: name, Reason: "encoding: " + err.Error()})
+			return
+		}
+		dst := newMessage(tm)
+		if err := (proto.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(b, dst.Interface()); err != nil {
+			problems = append(problems, Problem{Message: name, Reason: "decoding: " + err.Error()})
+			return
+		}
+		problems = append(problems, diff(name, src, dst)...)
+	})
+	return problems
+}
+
+// diff compares the fields set in src with those of dst.
+func diff(name string, src, dst protoreflect.Message) []Problem {
+	var problems []Problem
+	src.Range(func(sf protoreflect.FieldDescriptor, sv protoreflect.Value) bool {
+		df := dst.Descriptor().Fields().ByNumber(sf.Number())
+		if df == nil {
+			// removed, or added after the version of dst
+			return true
+		}
+		if !dst.Has(df) {
+			problems = append(problems, Problem{name, string(sf.Name()), "lost"})
+			return true
+		}
+		if !equal(sf, sv, df, dst.Get(df)) {
+			problems = append(problems, Problem{name, string(sf.Name()), "changed value"})
+		}
+		return true
+	})
+	return problems
+}
+
+func equal(sf protoreflect.FieldDescriptor, sv protoreflect.Value, df protoreflect.FieldDescriptor, dv protoreflect.Value) bool {
+	switch {
+	case sf.IsList():
+		sl, dl := sv.List(), dv.List()
+		if sl.Len() != dl.Len() {
+			return false
+		}
+		for i := 0; i < sl.Len(); i++ {
+			if !equalSingular(sf, sl.Get(i), df, dl.Get(i)) {
+				return false
+			}
+		}
+		return true
+	case sf.IsMap():
+		sm, dm := sv.Map(), dv.Map()
+		if sm.Len() != dm.Len() {
+			return false
+		}
+		eq := true
+		sm.Range(func(k protoreflect.MapKey, v protoreflect.Value) bool {
+			eq = dm.Has(k) && equalSingular(sf.MapValue(), v, df.MapValue(), dm.Get(k))
+			return eq
+		})
+		return eq
+	}
+	return equalSingular(sf, sv, df, dv)
+}
+
+func equalSingular(sf protoreflect.FieldDescriptor, sv protoreflect.Value, df protoreflect.FieldDescriptor, dv protoreflect.Value) bool {
+	if sf.Kind() != df.Kind() {
+		return false
+	}
+	switch sf.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return len(diff("", sv.Message(), dv.Message())) == 0
+	case protoreflect.BytesKind:
+		return string(sv.Bytes()) == string(dv.Bytes())
+	case protoreflect.EnumKind:
+		return sv.Enum() == dv.Enum()
+	}
+	return sv.Interface() == dv.Interface()
+}
+
+// fill sets every field of m to a value that is not the default one.
+func fill(m protoreflect.Message, depth int) {
+	fields := m.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if fd.ContainingOneof() != nil && fd.ContainingOneof().Fields().Get(0) != fd {
+			// one field of a oneof at a time
+			continue
+		}
+		switch {
+		case fd.IsList():
+			if fd.Message() != nil && depth >= maxDepth {
+				continue
+			}
+			l := m.Mutable(fd).List()
+			for j := 0; j < 2; j++ {
+				l.Append(sample(m, fd, depth, j))
+			}
+		case fd.IsMap():
+			if fd.MapValue().Message() != nil && depth >= maxDepth {
+				continue
+			}
+			mm := m.Mutable(fd).Map()
+			k := sample(m, fd.MapKey(), depth, 0).MapKey()
+			if fd.MapValue().Message() != nil {
+				v := mm.NewValue()
+				fill(v.Message(), depth+1)
+				mm.Set(k, v)
+			} else {
+				mm.Set(k, sample(m, fd.MapValue(), depth, 0))
+			}
+		default:
+			if fd.Message() != nil && depth >= maxDepth {
+				continue
+			}
+			m.Set(fd, sample(m, fd, depth, 0))
+		}
+	}
+}
+
+// sample returns the i-th sample value of the field fd of m.
+func sample(m protoreflect.Message, fd protoreflect.FieldDescriptor, depth, i int) protoreflect.Value {
+	n := int64(fd.Number()) + int64(i)*1000
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return protoreflect.ValueOfBool(true)
+	case protoreflect.EnumKind:
+		values := fd.Enum().Values()
+		return protoreflect.ValueOfEnum(values.Get(values.Len() - 1).Number())
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return protoreflect.ValueOfInt32(int32(n))
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return protoreflect.ValueOfInt64(n)
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return protoreflect.ValueOfUint32(uint32(n))
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return protoreflect.ValueOfUint64(uint64(n))
+	case protoreflect.FloatKind:
+		return protoreflect.ValueOfFloat32(float32(n) + 0.5)
+	case protoreflect.DoubleKind:
+		return protoreflect.ValueOfFloat64(float64(n) + 0.5)
+	case protoreflect.StringKind:
+		return protoreflect.ValueOfString(fmt.Sprintf("%s-%d", fd.Name(), i))
+	case protoreflect.BytesKind:
+		return protoreflect.ValueOfBytes([]byte(fmt.Sprintf("%s-%d", fd.Name(), i)))
+	}
+	var v protoreflect.Value
+	if fd.IsList() {
+		v = m.Mutable(fd).List().NewElement()
+	} else {
+		v = m.NewField(fd)
+	}
+	fill(v.Message(), depth+1)
+	return v
+}
+
+// newMessage returns a message of the type md, the generated one when it is
+// linked in.
+func newMessage(md protoreflect.MessageDescriptor) protoreflect.Message {
+	if mt, err := protoregistry.GlobalTypes.FindMessageByName(md.FullName()); err == nil {
+		return mt.New()
+	}
+	return dynamicpb.NewMessage(md)
+}
+
+// walk calls fn on every message of messages, the nested ones included, the
+// map entries excluded.
+func walk(messages protoreflect.MessageDescriptors, fn func(protoreflect.MessageDescriptor)) {
+	for i := 0; i < messages.Len(); i++ {
+		md := messages.Get(i)
+		if md.IsMapEntry() {
+			continue
+		}
+		fn(md)
+		walk(md.Messages(), fn)
+	}
+}
+
+// find returns the message of fd named name in its package, or nil.
+func find(fd protoreflect.FileDescriptor, name string) protoreflect.MessageDescriptor {
+	var found protoreflect.MessageDescriptor
+	walk(fd.Messages(), func(md protoreflect.MessageDescriptor) {
+		if found == nil && relName(fd, md) == name {
+			found = md
+		}
+	})
+	return found
+}
+
+// relName returns the name of d in the package of fd.
+func relName(fd protoreflect.FileDescriptor, d protoreflect.Descriptor) string {
+	return strings.TrimPrefix(string(d.FullName()), string(fd.Package())+".")
+}
+
+// typeName returns the name of a message or enum type, relative to the
+// package of fd when it is declared there, so that the types of the two
+// versions compare equal.
+func typeName(fd protoreflect.FileDescriptor, d protoreflect.Descriptor) string {
+	if d.ParentFile() != nil && d.ParentFile().Package() == fd.Package() {
+		return relName(fd, d)
+	}
+	return string(d.FullName())
+}
+
+func cardinality(fd protoreflect.FieldDescriptor) string {
+	if fd.IsMap() {
+		return "map"
+	}
+	return fd.Cardinality().String()
+}
+
+// Sort orders problems by message and field, for stable reports.
+func Sort(problems []Problem) {
+	sort.Slice(problems, func(i, j int) bool {
+		if problems[i].Message != problems[j].Message {
+			return problems[i].Message < problems[j].Message
+		}
+		return problems[i].Field < problems[j].Field
+	})
+}
@@ -0,0 +1,44 @@
+package protocompat
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	pb "github.com/miki-tnt/sa5g-go-usvc-k8s/pb/udm"
+	pbv2 "github.com/miki-tnt/sa5g-go-usvc-k8s/pb/udm/v2"
+)
+
+// versions lists the consecutive versions of every API still served.
+var versions = []struct {
+	name       string
+	prev, next protoreflect.FileDescriptor
+}{
+	{"udm v1 -> v2", pb.File_udm_proto, pbv2.File_udm_v2_udm_proto},
+}
+
+// TestVersions checks that every version still served reserves the fields
+// of the previous one it removes, without reusing or retyping their
+// numbers, and that it decodes the messages of the previous one, and the
+// previous one its messages, with the same values.
+func TestVersions(t *testing.T) {
+	for _, v := range versions {
+		var problems []Problem
+		for _, p := range Check(v.prev, v.next) {
+			p.Reason = "schema: " + p.Reason
+			problems = append(problems, p)
+		}
+		for _, p := range Replay(v.prev, v.next) {
+			p.Reason = "old message decoded by new version: " + p.Reason
+			problems = append(problems, p)
+		}
+		for _, p := range Replay(v.next, v.prev) {
+			p.Reason = "new message decoded by old version: " + p.Reason
+			problems = append(problems, p)
+		}
+		Sort(problems)
+		for _, p := range problems {
+			t.Errorf("%s: %s", v.name, p)
+		}
+	}
+}
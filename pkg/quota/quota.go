@@ -0,0 +1,170 @@
+// Package quota limits the request rate of every caller of a service on its
+// own, so that one misbehaving NF instance or gNB cannot use up the capacity
+// of the others. Callers are told apart by the identity the caller package
+// puts in the context.
+package quota
+
+import (
+	"context"
+	"encoding/binary"
+	"hash/fnv"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/metrics"
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/caller"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/clock"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/store"
+)
+
+const keyPrefix = "quota/"
+
+// Anonymous is the key shared by the requests whose caller is not known.
+const Anonymous = "anonymous"
+
+// ErrLimited is returned to a caller that is over its limit.
+var ErrLimited = status.Error(codes.ResourceExhausted, "caller rate limit exceeded")
+
+// Limiter decides whether the caller identified by key may make one more
+// request.
+type Limiter interface {
+	Allow(ctx context.Context, key string) (bool, error)
+}
+
+// Middleware returns an endpoint middleware that rejects the requests of a
+// caller over its limit with ErrLimited. Every method has limits of its own.
+// A nil Limiter limits nothing. When the limiter fails, the request is let
+// through: the limits protect the service, they must not take it down.
+func Middleware(l Limiter, method string) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		if l == nil {
+			return next
+		}
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			id, ok := caller.FromContext(ctx)
+			if !ok {
+				id = Anonymous
+			}
+			if allowed, err := l.Allow(ctx, method+"/"+id); err == nil && !allowed {
+				return nil, ErrLimited
+			}
+			return next(ctx, request)
+		}
+	}
+}
+
+// Instrument returns l counting its decisions in allowed and denied. The
+// failures of l are counted in neither.
+func Instrument(l Limiter, allowed, denied metrics.Counter) Limiter {
+	return instrumented{l, allowed, denied}
+}
+
+type instrumented struct {
+	Limiter
+	allowed, denied metrics.Counter
+}
+
+func (i instrumented) Allow(ctx context.Context, key string) (bool, error) {
+	ok, err := i.Limiter.Allow(ctx, key)
+	switch {
+	case err != nil:
+	case ok:
+		i.allowed.Add(1)
+	default:
+		i.denied.Add(1)
+	}
+	return ok, err
+}
+
+// tokenBuckets is a Limiter giving every key a token bucket kept in a store.
+type tokenBuckets struct {
+	st    store.Store
+	limit rate.Limit
+	burst int
+	ttl   time.Duration
+	clock clock.Clock
+	locks [64]sync.Mutex
+}
+
+// BucketOption sets an optional parameter of the token buckets.
+type BucketOption func(*tokenBuckets)
+
+// Clock sets the clock the buckets fill up on, clock.Real by default. The
+// store keeps its own time for their expiry.
+func Clock(c clock.Clock) BucketOption {
+	return func(b *tokenBuckets) { b.clock = c }
+}
+
+// NewTokenBuckets returns a Limiter allowing every key burst requests at
+// once and limit requests per second in the long run. The buckets are kept
+// in st and expire once full again. The read and write of a bucket
+// are not atomic, so replicas sharing a Redis store only approximate the
+// limits, by excess.
+func NewTokenBuckets(st store.Store, limit rate.Limit, burst int, options ...BucketOption) Limiter {
+	// a bucket left alone for ttl is full again
+	ttl := time.Duration(float64(burst) / float64(limit) * float64(time.Second))
+	if ttl < time.Second {
+		ttl = time.Second
+	}
+	b := &tokenBuckets{st: st, limit: limit, burst: burst, ttl: ttl, clock: clock.Real}
+	for _, option := range options {
+		option(b)
+	}
+	return b
+}
+
+func (b *tokenBuckets) Allow(ctx context.Context, key string) (bool, error) {
+	key = keyPrefix + key
+	mtx := b.lock(key)
+	mtx.Lock()
+	defer mtx.Unlock()
+
+	now := b.clock.Now()
+	tokens := float64(b.burst)
+	data, err := b.st.Get(ctx, key)
+	switch {
+	case err == store.ErrNotFound:
+	case err != nil:
+		return false, err
+	default:
+		if t, last, ok := decodeBucket(data); ok {
+			tokens = math.Min(tokens, t+now.Sub(last).Seconds()*float64(b.limit))
+		}
+	}
+	if tokens < 1 {
+		return false, nil
+	}
+	return true, b.st.Set(ctx, key, encodeBucket(tokens-1, now), b.ttl)
+}
+
+// lock returns the mutex serializing the updates of the bucket of key in
+// this process.
+func (b *tokenBuckets) lock(key string) *sync.Mutex {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return &b.locks[h.Sum32()%uint32(len(b.locks))]
+}
+
+// A bucket is stored as its tokens, a float64, and the time they were
+// counted, in Unix nanoseconds.
+func encodeBucket(tokens float64, at time.Time) []byte {
+	data := make([]byte, 16)
+	binary.BigEndian.PutUint64(data, math.Float64bits(tokens))
+	binary.BigEndian.PutUint64(data[8:], uint64(at.UnixNano()))
+	return data
+}
+
+func decodeBucket(data []byte) (float64, time.Time, bool) {
+	if len(data) != 16 {
+		return 0, time.Time{}, false
+	}
+	tokens := math.Float64frombits(binary.BigEndian.Uint64(data))
+	at := time.Unix(0, int64(binary.BigEndian.Uint64(data[8:])))
+	return tokens, at, true
+}
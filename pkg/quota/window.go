@@ -0,0 +1,139 @@
+package quota
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-redis/redis"
+	"golang.org/x/time/rate"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/store"
+)
+
+// retryAfter is how long a sliding window leaves Redis alone after it failed
+// to reach it.
+const retryAfter = time.Second
+
+// windowScript counts a request in the current window of a key unless the
+// requests of the current window, added to those of the previous one
+// weighted by the part of it still inside the sliding window, reach the
+// limit. It returns 1 when the request is allowed.
+//
+// KEYS[1] is the counter of the current window, KEYS[2] the counter of the
+// previous one. ARGV[1] is the limit, ARGV[2] the weight of the previous
+// window and ARGV[3] the time to live of a counter, in milliseconds.
+var windowScript = redis.NewScript(`
+local current = tonumber(redis.call('GET', KEYS[1]) or '0')
+local previous = tonumber(redis.call('GET', KEYS[2]) or '0')
+if previous * tonumber(ARGV[2]) + current >= tonumber(ARGV[1]) then
+	return 0
+end
+redis.call('INCR', KEYS[1])
+redis.call('PEXPIRE', KEYS[1], ARGV[3])
+return 1
+`)
+
+// WindowOption sets an optional parameter of NewSlidingWindow.
+type WindowOption func(*slidingWindow)
+
+// Fallback sets the limiter used while Redis cannot be reached. The default
+// is local token buckets with the same average rate, so each replica then
+// allows the whole limit on its own.
+func Fallback(l Limiter) WindowOption {
+	return func(w *slidingWindow) { w.fallback = l }
+}
+
+// Logger sets the logger reporting the switches to and from the fallback.
+func Logger(logger log.Logger) WindowOption {
+	return func(w *slidingWindow) { w.logger = logger }
+}
+
+// slidingWindow is a Limiter whose counters are kept in Redis, so the limits
+// hold across the replicas of a service.
+type slidingWindow struct {
+	client   *redis.Client
+	prefix   string
+	limit    int
+	window   time.Duration
+	fallback Limiter
+	logger   log.Logger
+
+	mtx       sync.Mutex
+	down      bool
+	downUntil time.Time
+}
+
+// NewSlidingWindow returns a Limiter allowing every key limit requests in
+// any window of time, approximated from the counts of the current and the
+// previous fixed windows. The counters are kept in Redis under prefix and
+// updated atomically, so every replica using the same server enforces the
+// same limits. When Redis cannot be reached the fallback decides, and Redis
+// is tried again a second later.
+func NewSlidingWindow(client *redis.Client, prefix string, limit int, window time.Duration, options ...WindowOption) Limiter {
+	w := &slidingWindow{
+		client: client,
+		prefix: prefix,
+		limit:  limit,
+		window: window,
+		logger: log.NewNopLogger(),
+	}
+	for _, option := range options {
+		option(w)
+	}
+	if w.fallback == nil {
+		w.fallback = NewTokenBuckets(store.NewMemory(), rate.Limit(float64(limit)/window.Seconds()), limit)
+	}
+	return w
+}
+
+func (w *slidingWindow) Allow(ctx context.Context, key string) (bool, error) {
+	now := time.Now()
+	if !w.available(now) {
+		return w.fallback.Allow(ctx, key)
+	}
+	n := now.UnixNano() / int64(w.window)
+	elapsed := float64(now.UnixNano()%int64(w.window)) / float64(w.window)
+	// a counter is read as the previous window during the next one
+	ttl := int64(2 * w.window / time.Millisecond)
+	k := w.prefix + keyPrefix + key + "/"
+	keys := []string{k + strconv.FormatInt(n, 10), k + strconv.FormatInt(n-1, 10)}
+	res, err := windowScript.Run(w.client.WithContext(ctx), keys, w.limit, 1-elapsed, ttl).Int64()
+	if err != nil && ctx.Err() != nil {
+		return false, err
+	}
+	if err != nil {
+		w.failed(now, err)
+		return w.fallback.Allow(ctx, key)
+	}
+	w.reached()
+	return res == 1, nil
+}
+
+// available reports whether Redis may be tried at now.
+func (w *slidingWindow) available(now time.Time) bool {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	return !w.down || !now.Before(w.downUntil)
+}
+
+func (w *slidingWindow) failed(now time.Time, err error) {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	if !w.down {
+		w.logger.Log("limiter", "redis", "fallback", "local", "err", err)
+	}
+	w.down = true
+	w.downUntil = now.Add(retryAfter)
+}
+
+func (w *slidingWindow) reached() {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	if w.down {
+		w.logger.Log("limiter", "redis", "fallback", "off")
+	}
+	w.down = false
+}
@@ -0,0 +1,145 @@
+// Package recovery turns the panics of the request handlers into errors, so
+// that a bug hit by one request fails that request rather than the stream it
+// came on or the whole pod. The gRPC interceptors, the endpoint middleware
+// and the HTTP handler recover the panic, log it with its stack at the error
+// level, count it and answer Internal, or 500 over HTTP.
+//
+// The panics are counted in expvar as "panics", with the label "method" for
+// the metrics backends that keep it.
+package recovery
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/go-kit/kit/metrics"
+	"github.com/go-kit/kit/metrics/expvar"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrPanic is returned in place of a panic. Its message is the one of the
+// other internal errors, the panic itself is only logged.
+var ErrPanic = status.Error(codes.Internal, "internal server error")
+
+// Panics counts the recovered panics.
+var Panics metrics.Counter = expvar.NewCounter("panics")
+
+// recovered logs and counts the panic r of method.
+func recovered(logger log.Logger, method string, r interface{}) {
+	Panics.With("method", method).Add(1)
+	level.Error(logger).Log("method", method, "panic", fmt.Sprint(r), "stack", string(debug.Stack()))
+}
+
+// UnaryServerInterceptor returns an interceptor recovering the panics of the
+// unary handlers.
+func UnaryServerInterceptor(logger log.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				recovered(logger, info.FullMethod, r)
+				resp, err = nil, ErrPanic
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns an interceptor recovering the panics of the
+// stream handlers. The stream ends with Internal.
+func StreamServerInterceptor(logger log.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				recovered(logger, info.FullMethod, r)
+				err = ErrPanic
+			}
+		}()
+		return handler(srv, ss)
+	}
+}
+
+// Middleware returns an endpoint middleware recovering the panics of the
+// endpoint. It goes first on the endpoint, so that the middlewares wrapping
+// it, the circuit breaker and the logging among them, see the panic as an
+// error.
+func Middleware(logger log.Logger, method string) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					recovered(logger, method, r)
+					response, err = nil, ErrPanic
+				}
+			}()
+			return next(ctx, request)
+		}
+	}
+}
+
+// Handler wraps next with a handler recovering its panics, those of the
+// decoders and encoders of the transports included, and answering 500 when
+// nothing was written yet. http.ErrAbortHandler is let through, as net/http
+// expects.
+func Handler(next http.Handler, logger log.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rw := &responseWriter{ResponseWriter: w}
+		defer func() {
+			p := recover()
+			if p == nil {
+				return
+			}
+			if p == http.ErrAbortHandler {
+				panic(p)
+			}
+			recovered(logger, r.URL.Path, p)
+			if !rw.written {
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			}
+		}()
+		next.ServeHTTP(rw, r)
+	})
+}
+
+// responseWriter records whether the response was started.
+type responseWriter struct {
+	http.ResponseWriter
+	written bool
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	w.written = true
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	w.written = true
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush keeps the streaming responses working.
+func (w *responseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack keeps the WebSocket upgrades working. A panic after it is only
+// logged, the connection no longer speaks HTTP.
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("recovery: the response cannot be hijacked")
+	}
+	w.written = true
+	return h.Hijack()
+}
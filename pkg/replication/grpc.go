@@ -0,0 +1,106 @@
+package replication
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/kit/log/level"
+	"google.golang.org/grpc/metadata"
+
+	pb "github.com/miki-tnt/sa5g-go-usvc-k8s/pb/replication"
+)
+
+type grpcServer struct {
+	t *Table
+}
+
+// NewGRPCServer returns the replication service streaming the log of t.
+func NewGRPCServer(t *Table) pb.ReplicationServer {
+	return &grpcServer{t: t}
+}
+
+func (s *grpcServer) Stream(req *pb.StreamRequest, stream pb.Replication_StreamServer) error {
+	t := s.t
+	t.setFollowers(1)
+	defer t.setFollowers(-1)
+	after := req.After
+	if req.Log != t.id {
+		after = 0
+	}
+	level.Info(t.logger).Log("replication", "follower", "region", req.Region, "after", after)
+	// the follower waits for the headers to know it follows
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+	for {
+		changes, changed := t.since(after)
+		for _, c := range changes {
+			if err := stream.Send(c); err != nil {
+				return err
+			}
+			after = c.Seq
+		}
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case <-changed:
+		}
+	}
+}
+
+// Follow applies the changes of the log of the other region, served by
+// client, from the last one applied, following it again every retry after
+// the stream fails, until ctx is done.
+func (t *Table) Follow(ctx context.Context, client pb.ReplicationClient) {
+	for {
+		err := t.follow(ctx, client)
+		t.setFollowing(false)
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+		level.Warn(t.logger).Log("replication", "follow", "retry", t.retry, "err", err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(t.retry):
+		}
+	}
+}
+
+// follow applies the changes of a stream of the log of the other region,
+// until it fails.
+func (t *Table) follow(ctx context.Context, client pb.ReplicationClient) error {
+	t.mtx.Lock()
+	req := &pb.StreamRequest{Region: t.region, After: t.applied, Log: t.peerLog}
+	t.mtx.Unlock()
+	stream, err := client.Stream(ctx, req)
+	if err != nil {
+		return err
+	}
+	if _, err := stream.Header(); err != nil {
+		return err
+	}
+	t.setFollowing(true)
+	level.Info(t.logger).Log("replication", "following", "after", req.After)
+	for {
+		c, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		t.apply(c)
+	}
+}
+
+func (t *Table) setFollowers(delta int) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	t.followers += delta
+}
+
+func (t *Table) setFollowing(following bool) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	t.following = following
+}
@@ -0,0 +1,317 @@
+// Package replication replicates the UE contexts of a deployment to the
+// deployment of another region, for warm-standby geo-redundancy: the
+// standby holds the contexts of the UEs of the active region, and takes
+// them over when it fails.
+//
+// A Table holds the contexts of both regions by key, as opaque values. Every
+// change, made in the region or applied from the other, is appended to its
+// log with a sequence number; the log is compacted, holding the last change
+// of every key only. The other region follows the log asynchronously over a
+// gRPC stream (see NewGRPCServer and Follow), from the last change it
+// applied, and the changes it made itself come back to a region that lost
+// them, restarted.
+//
+// The changes of a key are ordered by their time and their region: the last
+// writer wins, without CRDTs. A change of the other region that was made
+// without having seen the change of the key made here conflicts with it: the
+// regions were both active. The conflicts are resolved in the same way on
+// both sides, so that they converge, and surfaced: counted, logged and kept
+// for the status of the Table. The sequence numbers start again with the
+// process, so the conflicts of the changes made while the other region
+// restarted go undetected, though resolved all the same.
+package replication
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+
+	pb "github.com/miki-tnt/sa5g-go-usvc-k8s/pb/replication"
+)
+
+// Default values of the options.
+const (
+	DefaultRetry     = 5 * time.Second
+	DefaultConflicts = 100
+)
+
+// Option sets an optional parameter of a Table.
+type Option func(*Table)
+
+// Logger sets the logger of the conflicts and of the stream of the other
+// region.
+func Logger(logger log.Logger) Option {
+	return func(t *Table) { t.logger = logger }
+}
+
+// Retry sets the wait before following the log of the other region again,
+// DefaultRetry by default.
+func Retry(d time.Duration) Option {
+	return func(t *Table) { t.retry = d }
+}
+
+// Conflicts sets the number of the last conflicts kept for the status,
+// DefaultConflicts by default.
+func Conflicts(n int) Option {
+	return func(t *Table) { t.maxConflicts = n }
+}
+
+// Pseudonymize replaces the keys of the conflicts of the status, the SUPIs
+// of the UEs, with fn.
+func Pseudonymize(fn func(key string) string) Option {
+	return func(t *Table) { t.pseudonymize = fn }
+}
+
+// Version orders the changes of a key.
+type Version struct {
+	Time   time.Time `json:"time"`
+	Origin string    `json:"origin"`
+}
+
+// After tells whether v is the version of a change made after that of o:
+// the later one, that of the greater region for the same time.
+func (v Version) After(o Version) bool {
+	if v.Time.Equal(o.Time) {
+		return v.Origin > o.Origin
+	}
+	return v.Time.After(o.Time)
+}
+
+// Conflict is a change of the other region concurrent with a change of the
+// region.
+type Conflict struct {
+	Key    string  `json:"key"`
+	Local  Version `json:"local"`
+	Remote Version `json:"remote"`
+	// Winner is the region whose change was kept.
+	Winner   string    `json:"winner"`
+	Detected time.Time `json:"detected"`
+}
+
+// entry is the last change of a key.
+type entry struct {
+	value   []byte
+	deleted bool
+	version Version
+	// seq is the sequence number of the change in the log, seen that of
+	// the last change of the other region applied when it was made here.
+	seq, seen uint64
+}
+
+// Table holds the contexts of the two regions. It is safe for concurrent
+// use.
+type Table struct {
+	region       string
+	logger       log.Logger
+	retry        time.Duration
+	maxConflicts int
+	pseudonymize func(string) string
+	// id identifies the log, whose sequence numbers start again with the
+	// process.
+	id string
+
+	mtx     sync.Mutex
+	entries map[string]*entry
+	seq     uint64
+	// changed is closed, and replaced, by every change.
+	changed chan struct{}
+	// peer is the other region, peerLog its log, applied the sequence
+	// number of its last change applied.
+	peer      string
+	peerLog   string
+	applied   uint64
+	following bool
+	followers int
+	conflicts []Conflict
+	total     int64
+}
+
+// New returns the empty table of region.
+func New(region string, options ...Option) *Table {
+	b := make([]byte, 8)
+	rand.Read(b)
+	t := &Table{
+		region:       region,
+		logger:       log.NewNopLogger(),
+		retry:        DefaultRetry,
+		maxConflicts: DefaultConflicts,
+		pseudonymize: func(key string) string { return key },
+		id:           hex.EncodeToString(b),
+		entries:      map[string]*entry{},
+		changed:      make(chan struct{}),
+	}
+	for _, o := range options {
+		o(t)
+	}
+	return t
+}
+
+// Region returns the region of t.
+func (t *Table) Region() string {
+	return t.region
+}
+
+// Put sets the value of key, a change of the region.
+func (t *Table) Put(key string, value []byte) {
+	t.write(key, value, false)
+}
+
+// Delete deletes key, a change of the region. The key is kept deleted, so
+// that an older change of the other region does not bring it back.
+func (t *Table) Delete(key string) {
+	t.write(key, nil, true)
+}
+
+// Get returns the value of key, and false when it has none.
+func (t *Table) Get(key string) ([]byte, bool) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	e, ok := t.entries[key]
+	if !ok || e.deleted {
+		return nil, false
+	}
+	return e.value, true
+}
+
+// Range calls fn with the keys and values of t, in no order, and their
+// region, until it returns false.
+func (t *Table) Range(fn func(key string, value []byte, origin string) bool) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	for k, e := range t.entries {
+		if !e.deleted && !fn(k, e.value, e.version.Origin) {
+			return
+		}
+	}
+}
+
+// write makes a change of the region, later than the change of key it
+// replaces whatever the clocks of the regions.
+func (t *Table) write(key string, value []byte, deleted bool) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	v := Version{Time: time.Now().UTC(), Origin: t.region}
+	if cur, ok := t.entries[key]; ok && !v.After(cur.version) {
+		v.Time = cur.version.Time.Add(time.Nanosecond)
+	}
+	t.appendLocked(key, &entry{value: value, deleted: deleted, version: v, seen: t.applied})
+}
+
+// appendLocked appends the change e of key to the log.
+func (t *Table) appendLocked(key string, e *entry) {
+	t.seq++
+	e.seq = t.seq
+	t.entries[key] = e
+	close(t.changed)
+	t.changed = make(chan struct{})
+}
+
+// since returns the changes of the log after seq, by sequence number, and
+// the channel closed by the next change.
+func (t *Table) since(seq uint64) ([]*pb.Change, <-chan struct{}) {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	var changes []*pb.Change
+	for k, e := range t.entries {
+		if e.seq > seq {
+			changes = append(changes, &pb.Change{
+				Seq:     e.seq,
+				Key:     k,
+				Value:   e.value,
+				Deleted: e.deleted,
+				Time:    e.version.Time.UnixNano(),
+				Origin:  e.version.Origin,
+				Seen:    e.seen,
+				Log:     t.id,
+			})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Seq < changes[j].Seq })
+	return changes, t.changed
+}
+
+// apply applies the change c of the log of the other region, unless the
+// change of its key here is later. A change of the other region conflicts
+// with the change here it had not seen.
+func (t *Table) apply(c *pb.Change) {
+	t.mtx.Lock()
+	t.peerLog, t.applied = c.Log, c.Seq
+	remote := Version{Time: time.Unix(0, c.Time).UTC(), Origin: c.Origin}
+	if c.Origin != t.region {
+		t.peer = c.Origin
+	}
+	cur, ok := t.entries[c.Key]
+	var conflict *Conflict
+	if ok && c.Origin != t.region && cur.version.Origin == t.region && cur.seq > c.Seen {
+		conflict = &Conflict{Key: c.Key, Local: cur.version, Remote: remote, Winner: t.region, Detected: time.Now().UTC()}
+	}
+	if !ok || remote.After(cur.version) {
+		t.appendLocked(c.Key, &entry{value: c.Value, deleted: c.Deleted, version: remote})
+		if conflict != nil {
+			conflict.Winner = c.Origin
+		}
+	}
+	if conflict != nil {
+		t.total++
+		t.conflicts = append(t.conflicts, *conflict)
+		if len(t.conflicts) > t.maxConflicts {
+			t.conflicts = t.conflicts[len(t.conflicts)-t.maxConflicts:]
+		}
+	}
+	t.mtx.Unlock()
+	if conflict != nil {
+		level.Warn(t.logger).Log("replication", "conflict", "ue_id", c.Key, "local", conflict.Local.Time, "remote", conflict.Remote.Time, "remote_region", c.Origin, "winner", conflict.Winner)
+	}
+}
+
+// Status is the state of a Table.
+type Status struct {
+	Region string `json:"region"`
+	Log    string `json:"log"`
+	Seq    uint64 `json:"seq"`
+	// Keys counts the keys by the region of their last change.
+	Keys map[string]int `json:"keys"`
+	Peer string         `json:"peer,omitempty"`
+	// Following tells whether the log of the other region is streamed,
+	// Applied the sequence number of its last change applied.
+	Following bool   `json:"following"`
+	Applied   uint64 `json:"applied"`
+	// Followers is the number of streams of the log.
+	Followers int   `json:"followers"`
+	Conflicts int64 `json:"conflicts"`
+	// Recent are the last conflicts, the latest last.
+	Recent []Conflict `json:"recent_conflicts"`
+}
+
+// Status returns the state of t.
+func (t *Table) Status() Status {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	s := Status{
+		Region:    t.region,
+		Log:       t.id,
+		Seq:       t.seq,
+		Keys:      map[string]int{},
+		Peer:      t.peer,
+		Following: t.following,
+		Applied:   t.applied,
+		Followers: t.followers,
+		Conflicts: t.total,
+		Recent:    make([]Conflict, 0, len(t.conflicts)),
+	}
+	for _, e := range t.entries {
+		if !e.deleted {
+			s.Keys[e.version.Origin]++
+		}
+	}
+	for _, c := range t.conflicts {
+		c.Key = t.pseudonymize(c.Key)
+		s.Recent = append(s.Recent, c)
+	}
+	return s
+}
@@ -8,36 +8,37 @@ import (
 	"github.com/go-kit/kit/log"
 	stdopentracing "github.com/opentracing/opentracing-go"
 	stdzipkin "github.com/openzipkin/zipkin-go"
-	"google.golang.org/grpc"
 
 	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/addsvc/endpoints"
 	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/addsvc/service"
 	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/addsvc/transports"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/grpcpool"
 )
 
-func MakeAddSvcHandler(ctx context.Context, target string, tracer stdopentracing.Tracer, zipkinTracer *stdzipkin.Tracer, logger log.Logger) http.Handler {
+func MakeAddSvcHandler(ctx context.Context, target string, poolSize int, poolOptions []grpcpool.Option, tracer stdopentracing.Tracer, zipkinTracer *stdzipkin.Tracer, logger log.Logger) http.Handler {
+	pool, err := grpcpool.New(ctx, target, poolSize, poolOptions...)
+
 	var eps = endpoints.Endpoints{}
-	eps.SumEndpoint = addSvcFactory(ctx, target, endpoints.MakeSumEndpoint, tracer, zipkinTracer, logger)
-	eps.ConcatEndpoint = addSvcFactory(ctx, target, endpoints.MakeConcatEndpoint, tracer, zipkinTracer, logger)
+	eps.SumEndpoint = addSvcFactory(pool, err, endpoints.MakeSumEndpoint, tracer, zipkinTracer, logger)
+	eps.ConcatEndpoint = addSvcFactory(pool, err, endpoints.MakeConcatEndpoint, tracer, zipkinTracer, logger)
 
 	return transports.NewHTTPHandler(eps, tracer, zipkinTracer, logger)
 }
 
 func addSvcFactory(
-	ctx context.Context,
-	target string,
+	pool *grpcpool.Pool,
+	err error,
 	makeEndpoint func(service.AddsvcService) endpoint.Endpoint,
 	tracer stdopentracing.Tracer,
 	zipkinTracer *stdzipkin.Tracer,
-	logger log.Logger) (endpoint.Endpoint) {
+	logger log.Logger) endpoint.Endpoint {
 
-	conn, err := grpc.DialContext(ctx, target, grpc.WithInsecure())
 	if err != nil {
 		return func(ctx context.Context, request interface{}) (interface{}, error) {
 			return nil, err
 		}
 	}
-	svc := transports.NewGRPCClient(conn, tracer, zipkinTracer, logger)
+	svc := transports.NewGRPCPoolClient(pool, tracer, zipkinTracer, logger)
 
 	return makeEndpoint(svc)
 }
@@ -6,38 +6,38 @@ import (
 
 	"github.com/go-kit/kit/endpoint"
 	"github.com/go-kit/kit/log"
-
 	stdopentracing "github.com/opentracing/opentracing-go"
 	stdzipkin "github.com/openzipkin/zipkin-go"
-	"google.golang.org/grpc"
 
 	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/foosvc/endpoints"
 	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/foosvc/service"
 	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/foosvc/transports"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/grpcpool"
 )
 
-func MakeFooSvcHandler(ctx context.Context, target string, tracer stdopentracing.Tracer, zipkinTracer *stdzipkin.Tracer, logger log.Logger) http.Handler {
+func MakeFooSvcHandler(ctx context.Context, target string, poolSize int, poolOptions []grpcpool.Option, tracer stdopentracing.Tracer, zipkinTracer *stdzipkin.Tracer, logger log.Logger) http.Handler {
+	pool, err := grpcpool.New(ctx, target, poolSize, poolOptions...)
+
 	var eps = endpoints.Endpoints{}
-	eps.FooEndpoint = fooSvcFactory(ctx, target, endpoints.MakeFooEndpoint, tracer, zipkinTracer, logger)
+	eps.FooEndpoint = fooSvcFactory(pool, err, endpoints.MakeFooEndpoint, tracer, zipkinTracer, logger)
 
 	return transports.NewHTTPHandler(eps, tracer, zipkinTracer, logger)
 }
 
 func fooSvcFactory(
-	ctx context.Context,
-	target string,
+	pool *grpcpool.Pool,
+	err error,
 	makeEndpoint func(service.FoosvcService) endpoint.Endpoint,
 	tracer stdopentracing.Tracer,
 	zipkinTracer *stdzipkin.Tracer,
-	logger log.Logger) (endpoint.Endpoint) {
+	logger log.Logger) endpoint.Endpoint {
 
-	conn, err := grpc.DialContext(ctx, target, grpc.WithInsecure())
 	if err != nil {
 		return func(ctx context.Context, request interface{}) (interface{}, error) {
 			return nil, err
 		}
 	}
-	svc := transports.NewGRPCClient(conn, tracer, zipkinTracer, logger)
+	svc := transports.NewGRPCPoolClient(pool, tracer, zipkinTracer, logger)
 
 	return makeEndpoint(svc)
 }
@@ -0,0 +1,248 @@
+// Package pool runs tasks on a fixed number of goroutines fed by a bounded
+// queue. When the queue is full new tasks are shed with ErrThrottled instead
+// of piling up, so a burst of traffic costs rejected calls rather than
+// unbounded goroutines and memory.
+//
+// The queue is served earliest deadline first: the task whose context
+// expires soonest runs next, so that a request about to time out is served
+// while it is still worth it rather than after the ones that can wait. The
+// tasks without a deadline run after those with one, in their order of
+// arrival.
+package pool
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-kit/kit/metrics"
+	"github.com/go-kit/kit/metrics/discard"
+)
+
+var (
+	// ErrThrottled is returned by Submit when the queue is full.
+	ErrThrottled = errors.New("pool: throttled, queue is full")
+	// ErrClosed is returned by Submit after Close.
+	ErrClosed = errors.New("pool: closed")
+)
+
+// Option sets an optional parameter of a Pool.
+type Option func(*Pool)
+
+// QueueDepthGauge sets the gauge reporting the number of queued tasks.
+func QueueDepthGauge(g metrics.Gauge) Option {
+	return func(p *Pool) { p.queueGauge = g }
+}
+
+// BusyGauge sets the gauge reporting the number of workers running a task.
+func BusyGauge(g metrics.Gauge) Option {
+	return func(p *Pool) { p.busyGauge = g }
+}
+
+// ThrottledCounter sets the counter incremented for every shed task.
+func ThrottledCounter(c metrics.Counter) Option {
+	return func(p *Pool) { p.throttledCounter = c }
+}
+
+// WaitHistogram sets the histogram observing the seconds every task waited
+// in the queue, labelled by "method".
+func WaitHistogram(h metrics.Histogram) Option {
+	return func(p *Pool) { p.waitHistogram = h }
+}
+
+// ExpiredCounter sets the counter incremented for every task whose context
+// was done when a worker picked it.
+func ExpiredCounter(c metrics.Counter) Option {
+	return func(p *Pool) { p.expiredCounter = c }
+}
+
+// Stats is a point in time view of a pool.
+type Stats struct {
+	Workers   int   `json:"workers"`
+	Busy      int64 `json:"busy"`
+	Queued    int   `json:"queued"`
+	Capacity  int   `json:"capacity"`
+	Throttled int64 `json:"throttled"`
+	Expired   int64 `json:"expired"`
+}
+
+type task struct {
+	ctx      context.Context
+	method   string
+	fn       func(context.Context)
+	deadline time.Time // zero without one
+	seq      uint64
+	queued   time.Time
+}
+
+// queue is a heap of tasks, earliest deadline first.
+type queue []*task
+
+func (q queue) Len() int { return len(q) }
+
+func (q queue) Less(i, j int) bool {
+	di, dj := q[i].deadline, q[j].deadline
+	switch {
+	case di.IsZero() != dj.IsZero():
+		return dj.IsZero()
+	case !di.Equal(dj):
+		return di.Before(dj)
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q queue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *queue) Push(x interface{}) { *q = append(*q, x.(*task)) }
+
+func (q *queue) Pop() interface{} {
+	old := *q
+	t := old[len(old)-1]
+	old[len(old)-1] = nil
+	*q = old[:len(old)-1]
+	return t
+}
+
+// Pool is a bounded goroutine pool.
+type Pool struct {
+	workers          int
+	capacity         int
+	queueGauge       metrics.Gauge
+	busyGauge        metrics.Gauge
+	throttledCounter metrics.Counter
+	waitHistogram    metrics.Histogram
+	expiredCounter   metrics.Counter
+
+	mtx       sync.Mutex
+	cond      *sync.Cond
+	closed    bool
+	queue     queue
+	seq       uint64
+	idle      int // workers waiting for a task
+	wg        sync.WaitGroup
+	busy      int64
+	throttled int64
+	expired   int64
+}
+
+// New starts workers goroutines serving a queue of queueSize tasks. A
+// queueSize of zero sheds every task arriving while all workers are busy.
+func New(workers, queueSize int, options ...Option) *Pool {
+	if workers < 1 {
+		workers = 1
+	}
+	if queueSize < 0 {
+		queueSize = 0
+	}
+	p := &Pool{
+		workers:          workers,
+		capacity:         queueSize,
+		queueGauge:       discard.NewGauge(),
+		busyGauge:        discard.NewGauge(),
+		throttledCounter: discard.NewCounter(),
+		waitHistogram:    discard.NewHistogram(),
+		expiredCounter:   discard.NewCounter(),
+	}
+	p.cond = sync.NewCond(&p.mtx)
+	for _, option := range options {
+		option(p)
+	}
+
+	p.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go p.work()
+	}
+	return p
+}
+
+// Submit queues fn to run with ctx on a worker, its wait being observed for
+// method. It never blocks: when no worker is free and the queue is full it
+// returns ErrThrottled and fn is not run. fn is still run when ctx is done by
+// the time a worker picks it, so that it can report the cancellation to its
+// caller.
+func (p *Pool) Submit(ctx context.Context, method string, fn func(context.Context)) error {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	if p.closed {
+		return ErrClosed
+	}
+	// the idle workers take the tasks beyond the capacity of the queue
+	if len(p.queue) >= p.capacity+p.idle {
+		atomic.AddInt64(&p.throttled, 1)
+		p.throttledCounter.Add(1)
+		return ErrThrottled
+	}
+	deadline, _ := ctx.Deadline()
+	p.seq++
+	heap.Push(&p.queue, &task{ctx: ctx, method: method, fn: fn, deadline: deadline, seq: p.seq, queued: time.Now()})
+	p.queueGauge.Set(float64(len(p.queue)))
+	p.cond.Signal()
+	return nil
+}
+
+// Stats returns the current state of the pool.
+func (p *Pool) Stats() Stats {
+	p.mtx.Lock()
+	queued := len(p.queue)
+	p.mtx.Unlock()
+	return Stats{
+		Workers:   p.workers,
+		Busy:      atomic.LoadInt64(&p.busy),
+		Queued:    queued,
+		Capacity:  p.capacity,
+		Throttled: atomic.LoadInt64(&p.throttled),
+		Expired:   atomic.LoadInt64(&p.expired),
+	}
+}
+
+// Close stops accepting tasks and waits for the queued ones to complete.
+func (p *Pool) Close() {
+	p.mtx.Lock()
+	if p.closed {
+		p.mtx.Unlock()
+		return
+	}
+	p.closed = true
+	p.cond.Broadcast()
+	p.mtx.Unlock()
+	p.wg.Wait()
+}
+
+func (p *Pool) work() {
+	defer p.wg.Done()
+	for {
+		t, ok := p.next()
+		if !ok {
+			return
+		}
+		p.waitHistogram.With("method", t.method).Observe(time.Since(t.queued).Seconds())
+		if t.ctx.Err() != nil {
+			atomic.AddInt64(&p.expired, 1)
+			p.expiredCounter.Add(1)
+		}
+		p.busyGauge.Set(float64(atomic.AddInt64(&p.busy, 1)))
+		t.fn(t.ctx)
+		p.busyGauge.Set(float64(atomic.AddInt64(&p.busy, -1)))
+	}
+}
+
+// next waits for the task to run next, and reports false once the pool is
+// closed and its queue drained.
+func (p *Pool) next() (*task, bool) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	for len(p.queue) == 0 {
+		if p.closed {
+			return nil, false
+		}
+		p.idle++
+		p.cond.Wait()
+		p.idle--
+	}
+	t := heap.Pop(&p.queue).(*task)
+	p.queueGauge.Set(float64(len(p.queue)))
+	return t, true
+}
@@ -0,0 +1,321 @@
+// Package saga runs the procedures that span several resources, such as the
+// establishment of a PDU session allocating a TEID and a UE IP address and
+// creating a PFCP session, as sagas: a sequence of steps, each with the
+// compensating action undoing it. When a step fails, the steps done so far
+// are compensated in reverse order, so that a partial failure does not leak
+// the resources of the steps that succeeded.
+//
+// The state of every saga is kept in a store, before and after each step, so
+// that the sagas cut short by a restart are found by Recover and compensated
+// by the replica that picks them up. A step may thus have run, or partly
+// run, without its completion being recorded: compensations must tolerate a
+// resource that was never created or is already freed.
+//
+//	establish := saga.Definition{
+//		Name: "pdu-session-establishment",
+//		Steps: []saga.Step{
+//			{Name: "teid", Do: allocateTEID, Compensate: releaseTEID},
+//			{Name: "ip", Do: allocateIP, Compensate: releaseIP},
+//			{Name: "pfcp", Do: createPFCPSession, Compensate: deletePFCPSession},
+//		},
+//	}
+//	err := coordinator.Run(ctx, establish, sessionID, nil)
+package saga
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/go-kit/kit/metrics"
+	"github.com/go-kit/kit/metrics/discard"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/store"
+)
+
+const keyPrefix = "saga/"
+
+// Default values of the options.
+const (
+	DefaultRetention = 24 * time.Hour
+	DefaultRetries   = 3
+	DefaultBackoff   = 100 * time.Millisecond
+)
+
+var (
+	// ErrExists is returned by Run for a saga ID already in use.
+	ErrExists = errors.New("saga: saga already exists")
+	// ErrUnknown is returned by Recover for a saga whose definition is not
+	// given.
+	ErrUnknown = errors.New("saga: unknown definition")
+)
+
+// Status is the status of a saga.
+type Status string
+
+// The statuses of a saga. Done, Compensated and Failed are final.
+const (
+	Running      Status = "running"
+	Compensating Status = "compensating"
+	Done         Status = "done"
+	Compensated  Status = "compensated"
+	// Failed is the status of a saga whose compensation failed. Its
+	// resources are left for an operator or a reclamation job to free.
+	Failed Status = "failed"
+)
+
+func (s Status) final() bool {
+	return s == Done || s == Compensated || s == Failed
+}
+
+// State is the persisted state of a saga. Data holds the values the steps
+// pass on to each other and to their compensations, such as the TEID
+// allocated by a step.
+type State struct {
+	ID         string `json:"id"`
+	Definition string `json:"definition"`
+	Status     Status `json:"status"`
+	// Step is the index of the step running, the number of steps done, or
+	// of the next step to compensate.
+	Step    int               `json:"step"`
+	Data    map[string]string `json:"data,omitempty"`
+	Error   string            `json:"error,omitempty"`
+	Started time.Time         `json:"started"`
+	Updated time.Time         `json:"updated"`
+}
+
+// Step is a step of a saga. Do and Compensate may change the Data of the
+// state they are given, it is saved after them. Compensate may be nil for a
+// step with nothing to undo.
+type Step struct {
+	Name       string
+	Do         func(ctx context.Context, s *State) error
+	Compensate func(ctx context.Context, s *State) error
+}
+
+// Definition is a saga: its steps run in order.
+type Definition struct {
+	Name  string
+	Steps []Step
+}
+
+// Option sets an optional parameter of a Coordinator.
+type Option func(*Coordinator)
+
+// Retention sets how long the state of a finished saga is kept, for the
+// operators to look at. Zero keeps it forever.
+func Retention(d time.Duration) Option {
+	return func(c *Coordinator) { c.retention = d }
+}
+
+// Retries sets how many more times a failing compensation is tried, backoff
+// apart and doubling, before the saga is left Failed.
+func Retries(n int, backoff time.Duration) Option {
+	return func(c *Coordinator) { c.retries, c.backoff = n, backoff }
+}
+
+// Logger sets the logger reporting the failures and the compensations.
+func Logger(logger log.Logger) Option {
+	return func(c *Coordinator) { c.logger = logger }
+}
+
+// Counter sets the counter incremented for every saga finished, with the
+// labels "saga" and "status".
+func Counter(counter metrics.Counter) Option {
+	return func(c *Coordinator) { c.counter = counter }
+}
+
+// Coordinator runs sagas and keeps their state in a store.
+type Coordinator struct {
+	st        store.Store
+	retention time.Duration
+	retries   int
+	backoff   time.Duration
+	logger    log.Logger
+	counter   metrics.Counter
+}
+
+// New returns a Coordinator keeping the state of the sagas in st.
+func New(st store.Store, options ...Option) *Coordinator {
+	c := &Coordinator{
+		st:        st,
+		retention: DefaultRetention,
+		retries:   DefaultRetries,
+		backoff:   DefaultBackoff,
+		logger:    log.NewNopLogger(),
+		counter:   discard.NewCounter(),
+	}
+	for _, option := range options {
+		option(c)
+	}
+	return c
+}
+
+// Run runs the saga def under id, a unique ID such as the one of the
+// session, with the initial data. When a step fails, the steps done are
+// compensated and its error is returned. The error of a compensation is only
+// recorded in the state and logged, the caller already has a failure to
+// handle. The compensations run to the end even when ctx is done.
+func (c *Coordinator) Run(ctx context.Context, def Definition, id string, data map[string]string) error {
+	now := time.Now()
+	s := &State{ID: id, Definition: def.Name, Status: Running, Data: data, Started: now, Updated: now}
+	if s.Data == nil {
+		s.Data = map[string]string{}
+	}
+	value, _ := json.Marshal(s)
+	created, err := c.st.SetNX(ctx, c.key(id), value, 0)
+	if err != nil {
+		return err
+	}
+	if !created {
+		return ErrExists
+	}
+
+	for s.Step < len(def.Steps) {
+		step := def.Steps[s.Step]
+		if err := step.Do(ctx, s); err != nil {
+			s.Error = fmt.Sprintf("%s: %v", step.Name, err)
+			level.Warn(c.logger).Log("saga", def.Name, "id", id, "step", step.Name, "err", err)
+			c.compensate(context.Background(), def, s)
+			return err
+		}
+		s.Step++
+		if s.Step == len(def.Steps) {
+			s.Status = Done
+		}
+		if err := c.save(ctx, s); err != nil {
+			// the step is done but not recorded: undo it with the others
+			s.Step--
+			s.Status = Running
+			s.Error = fmt.Sprintf("%s: %v", step.Name, err)
+			c.compensate(context.Background(), def, s)
+			return err
+		}
+	}
+	c.counter.With("saga", def.Name, "status", string(Done)).Add(1)
+	return nil
+}
+
+// Get returns the state of the saga id.
+func (c *Coordinator) Get(ctx context.Context, id string) (State, error) {
+	var s State
+	value, err := c.st.Get(ctx, c.key(id))
+	if err != nil {
+		return s, err
+	}
+	err = json.Unmarshal(value, &s)
+	return s, err
+}
+
+// Recover compensates the sagas left running or compensating, by a restart
+// for instance, whose definition is among defs, and returns how many it
+// compensated. Their callers gave up on them with the replica that ran them.
+// Recover must only run when no replica is running sagas, at start up
+// typically, lest it compensates a saga still in progress.
+func (c *Coordinator) Recover(ctx context.Context, defs ...Definition) (int, error) {
+	byName := make(map[string]Definition, len(defs))
+	for _, def := range defs {
+		byName[def.Name] = def
+	}
+	keys, err := c.st.Keys(ctx, keyPrefix)
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, key := range keys {
+		s, err := c.Get(ctx, key[len(keyPrefix):])
+		if err == store.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return n, err
+		}
+		if s.Status.final() {
+			continue
+		}
+		def, ok := byName[s.Definition]
+		if !ok {
+			level.Error(c.logger).Log("saga", s.Definition, "id", s.ID, "err", ErrUnknown)
+			continue
+		}
+		level.Info(c.logger).Log("saga", def.Name, "id", s.ID, "recover", s.Status, "step", s.Step)
+		if s.Error == "" {
+			s.Error = "interrupted"
+		}
+		if s.Step >= len(def.Steps) {
+			s.Step = len(def.Steps) - 1
+		}
+		c.compensate(ctx, def, &s)
+		n++
+	}
+	return n, nil
+}
+
+// compensate undoes the steps of s from its current one down to the first,
+// the current one included since it may have partly run.
+func (c *Coordinator) compensate(ctx context.Context, def Definition, s *State) {
+	s.Status = Compensating
+	c.save(ctx, s)
+	for s.Step >= 0 {
+		step := def.Steps[s.Step]
+		if step.Compensate == nil {
+			s.Step--
+			continue
+		}
+		if err := c.retry(ctx, step, s); err != nil {
+			s.Status = Failed
+			s.Error = fmt.Sprintf("%s; compensating %s: %v", s.Error, step.Name, err)
+			level.Error(c.logger).Log("saga", def.Name, "id", s.ID, "compensate", step.Name, "err", err)
+			c.finish(ctx, def, s)
+			return
+		}
+		// recorded, so that a compensation is not run twice
+		s.Step--
+		c.save(ctx, s)
+	}
+	s.Step = 0
+	s.Status = Compensated
+	c.finish(ctx, def, s)
+}
+
+// retry runs the compensation of step, retrying it while it fails.
+func (c *Coordinator) retry(ctx context.Context, step Step, s *State) error {
+	backoff := c.backoff
+	err := step.Compensate(ctx, s)
+	for i := 0; err != nil && i < c.retries; i++ {
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return err
+		}
+		backoff *= 2
+		err = step.Compensate(ctx, s)
+	}
+	return err
+}
+
+func (c *Coordinator) finish(ctx context.Context, def Definition, s *State) {
+	if err := c.save(ctx, s); err != nil {
+		level.Error(c.logger).Log("saga", def.Name, "id", s.ID, "status", s.Status, "err", err)
+	}
+	c.counter.With("saga", def.Name, "status", string(s.Status)).Add(1)
+}
+
+// save writes s to the store, with the retention of a final state.
+func (c *Coordinator) save(ctx context.Context, s *State) error {
+	s.Updated = time.Now()
+	value, _ := json.Marshal(s)
+	var ttl time.Duration
+	if s.Status.final() {
+		ttl = c.retention
+	}
+	return c.st.Set(ctx, c.key(s.ID), value, ttl)
+}
+
+func (c *Coordinator) key(id string) string {
+	return keyPrefix + id
+}
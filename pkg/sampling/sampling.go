@@ -0,0 +1,436 @@
+// Package sampling decides which traces a service exports to Zipkin, to keep
+// tracing affordable during signalling storms. Its Reporter sits between the
+// tracer and the Zipkin reporter, and holds the spans of a trace until the
+// trace's decision.
+//
+// The head decision keeps a fraction of the traces, the fraction of a method
+// overriding the default one, up to a number of traces per second. It hashes
+// the trace ID, so the services sampling at the same rate keep the same
+// traces. The tail samplers see every span of the trace and keep the traces
+// they want whatever the head decision, such as the ones that failed or were
+// slow.
+package sampling
+
+import (
+	"container/list"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openzipkin/zipkin-go/model"
+	"github.com/openzipkin/zipkin-go/reporter"
+	"golang.org/x/time/rate"
+)
+
+// Default values of the options.
+const (
+	DefaultDecisionWait = 5 * time.Second
+	DefaultMaxTraces    = 10000
+)
+
+// TailSampler keeps the traces it is interested in, once their spans are
+// reported.
+type TailSampler interface {
+	Keep(spans []model.SpanModel) bool
+}
+
+// TailSamplerFunc is a function TailSampler.
+type TailSamplerFunc func(spans []model.SpanModel) bool
+
+// Keep calls f.
+func (f TailSamplerFunc) Keep(spans []model.SpanModel) bool { return f(spans) }
+
+// KeepErrors keeps the traces with a span tagged with an error.
+func KeepErrors() TailSampler {
+	return TailSamplerFunc(func(spans []model.SpanModel) bool {
+		for _, s := range spans {
+			if _, ok := s.Tags["error"]; ok {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// KeepSlowerThan keeps the traces whose longest span took d or more.
+func KeepSlowerThan(d time.Duration) TailSampler {
+	return TailSamplerFunc(func(spans []model.SpanModel) bool {
+		return duration(spans) >= d
+	})
+}
+
+// quantileWindow is the number of the last traces KeepSlowest ranks a trace
+// among.
+const quantileWindow = 1000
+
+// KeepSlowest keeps the traces slower than the quantile q, such as 0.99, of
+// the last traces, the duration of a trace being the one of its longest span.
+// The quantile is computed again every tenth of its window.
+func KeepSlowest(q float64) TailSampler {
+	var (
+		mtx       sync.Mutex
+		window    []time.Duration
+		next      int
+		seen      int
+		threshold time.Duration
+	)
+	return TailSamplerFunc(func(spans []model.SpanModel) bool {
+		d := duration(spans)
+		mtx.Lock()
+		defer mtx.Unlock()
+		if len(window) < quantileWindow {
+			window = append(window, d)
+		} else {
+			window[next] = d
+			next = (next + 1) % quantileWindow
+		}
+		seen++
+		if seen%(quantileWindow/10) == 0 {
+			sorted := append([]time.Duration(nil), window...)
+			sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+			threshold = sorted[int(q*float64(len(sorted)-1))]
+		}
+		// the first traces are ranked among too few to tell
+		return seen >= quantileWindow/10 && d > threshold
+	})
+}
+
+func duration(spans []model.SpanModel) time.Duration {
+	var d time.Duration
+	for _, s := range spans {
+		if s.Duration > d {
+			d = s.Duration
+		}
+	}
+	return d
+}
+
+// ParseMethods parses the sampling rates of the methods of s, a
+// comma-separated list of method=rate such as
+// "generateAuthData=0.01,confirmAuth=1". A method is the name of the spans of
+// its calls, such as /pb.Udm/GenerateAuthData, or its last element,
+// GenerateAuthData, in any case.
+func ParseMethods(s string) (map[string]float64, error) {
+	methods := map[string]float64{}
+	for _, kv := range strings.Split(s, ",") {
+		kv = strings.TrimSpace(kv)
+		if kv == "" {
+			continue
+		}
+		i := strings.Index(kv, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("sampling: %q is not method=rate", kv)
+		}
+		r, err := strconv.ParseFloat(strings.TrimSpace(kv[i+1:]), 64)
+		if err != nil || r < 0 || r > 1 {
+			return nil, fmt.Errorf("sampling: rate of %q is not between 0 and 1", kv)
+		}
+		methods[strings.ToLower(strings.TrimSpace(kv[:i]))] = r
+	}
+	return methods, nil
+}
+
+// ParseTail parses the tail samplers of s, a comma-separated list of
+// "errors", KeepErrors, "slow=<duration>", KeepSlowerThan, and
+// "p<percentile>", such as p99 or p99.9, KeepSlowest.
+func ParseTail(s string) ([]TailSampler, error) {
+	var samplers []TailSampler
+	for _, t := range strings.Split(s, ",") {
+		t = strings.TrimSpace(t)
+		switch {
+		case t == "":
+		case t == "errors":
+			samplers = append(samplers, KeepErrors())
+		case strings.HasPrefix(t, "slow="):
+			d, err := time.ParseDuration(strings.TrimPrefix(t, "slow="))
+			if err != nil {
+				return nil, fmt.Errorf("sampling: %q: %v", t, err)
+			}
+			samplers = append(samplers, KeepSlowerThan(d))
+		case strings.HasPrefix(t, "p"):
+			p, err := strconv.ParseFloat(t[1:], 64)
+			if err != nil || p <= 0 || p >= 100 {
+				return nil, fmt.Errorf("sampling: %q is not a percentile", t)
+			}
+			samplers = append(samplers, KeepSlowest(p/100))
+		default:
+			return nil, fmt.Errorf("sampling: unknown tail sampler %q", t)
+		}
+	}
+	return samplers, nil
+}
+
+// Option sets an optional parameter of a Reporter.
+type Option func(*Reporter)
+
+// Rate sets the fraction of the traces the head decision keeps, all of them
+// by default.
+func Rate(r float64) Option {
+	return func(rp *Reporter) { rp.rate = r }
+}
+
+// Methods sets the rates of the methods, keyed as by ParseMethods, which
+// override the default one for the traces spanning them. A trace spanning
+// several of them is kept at the highest rate.
+func Methods(methods map[string]float64) Option {
+	return func(rp *Reporter) { rp.methods = methods }
+}
+
+// RateLimit caps the traces the head decision keeps to perSecond, with bursts
+// of as many. Zero, the default, does not cap them.
+func RateLimit(perSecond float64) Option {
+	return func(rp *Reporter) {
+		if perSecond > 0 {
+			burst := int(perSecond)
+			if burst < 1 {
+				burst = 1
+			}
+			rp.limiter = rate.NewLimiter(rate.Limit(perSecond), burst)
+		}
+	}
+}
+
+// Tail adds tail samplers, which keep the traces they want beyond the head
+// decision and its rate limit.
+func Tail(samplers ...TailSampler) Option {
+	return func(rp *Reporter) { rp.tail = append(rp.tail, samplers...) }
+}
+
+// DecisionWait sets how long the spans of a trace wait for the local root,
+// the span of the call the service served, before the trace is decided
+// without it.
+func DecisionWait(d time.Duration) Option {
+	return func(rp *Reporter) { rp.wait = d }
+}
+
+// MaxTraces caps the traces waiting for their decision, the oldest being
+// decided early past it.
+func MaxTraces(n int) Option {
+	return func(rp *Reporter) { rp.maxTraces = n }
+}
+
+// Config is the sampling of a service, as configured by its environment.
+type Config struct {
+	// Rate is the fraction of the traces kept.
+	Rate float64
+	// RateLimit caps the traces kept per second, zero not capping them.
+	RateLimit float64
+	// Methods are the rates of the methods, as parsed by ParseMethods.
+	Methods map[string]float64
+	// Tail are the tail samplers, as parsed by ParseTail.
+	Tail string
+}
+
+// Options returns the options of the Reporter of c.
+func (c Config) Options() ([]Option, error) {
+	tail, err := ParseTail(c.Tail)
+	if err != nil {
+		return nil, err
+	}
+	return []Option{Rate(c.Rate), RateLimit(c.RateLimit), Methods(c.Methods), Tail(tail...)}, nil
+}
+
+// Reporter is a reporter.Reporter that reports to another one the spans of
+// the traces it keeps.
+type Reporter struct {
+	next      reporter.Reporter
+	rate      float64
+	methods   map[string]float64
+	limiter   *rate.Limiter
+	tail      []TailSampler
+	wait      time.Duration
+	maxTraces int
+
+	mtx    sync.Mutex
+	traces map[model.TraceID]*list.Element
+	order  *list.List
+	quit   chan struct{}
+	done   chan struct{}
+}
+
+// The states of a trace.
+const (
+	waiting = iota
+	deciding
+	decided
+)
+
+type trace struct {
+	id    model.TraceID
+	first time.Time
+	spans []model.SpanModel
+	state int
+	keep  bool
+}
+
+// NewReporter returns a Reporter reporting to next the spans of the traces
+// it keeps.
+func NewReporter(next reporter.Reporter, options ...Option) *Reporter {
+	r := &Reporter{
+		next:      next,
+		rate:      1,
+		wait:      DefaultDecisionWait,
+		maxTraces: DefaultMaxTraces,
+		traces:    map[model.TraceID]*list.Element{},
+		order:     list.New(),
+		quit:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	for _, o := range options {
+		o(r)
+	}
+	go r.loop()
+	return r
+}
+
+// Send holds s until its trace is decided, and reports it when the trace is
+// kept. The local root of a trace, a server span or one without a parent,
+// decides it.
+func (r *Reporter) Send(s model.SpanModel) {
+	r.mtx.Lock()
+	el, ok := r.traces[s.TraceID]
+	if !ok {
+		el = r.order.PushBack(&trace{id: s.TraceID, first: time.Now()})
+		r.traces[s.TraceID] = el
+	}
+	t := el.Value.(*trace)
+	if t.state == decided {
+		keep := t.keep
+		r.mtx.Unlock()
+		if keep {
+			r.next.Send(s)
+		}
+		return
+	}
+	t.spans = append(t.spans, s)
+	var ready []*trace
+	if t.state == waiting && (s.Kind == model.Server || s.ParentID == nil) {
+		ready = append(ready, r.takeLocked(t))
+	}
+	for e := r.order.Front(); len(r.traces) > r.maxTraces && e != nil; {
+		next := e.Next()
+		if t := e.Value.(*trace); t.state == waiting {
+			ready = append(ready, r.takeLocked(t))
+		}
+		r.removeLocked(e)
+		e = next
+	}
+	r.mtx.Unlock()
+	r.decide(ready)
+}
+
+// Close decides the traces waiting and closes the next reporter.
+func (r *Reporter) Close() error {
+	close(r.quit)
+	<-r.done
+	r.mtx.Lock()
+	var ready []*trace
+	for e := r.order.Front(); e != nil; e = e.Next() {
+		if t := e.Value.(*trace); t.state == waiting {
+			ready = append(ready, r.takeLocked(t))
+		}
+	}
+	r.mtx.Unlock()
+	r.decide(ready)
+	return r.next.Close()
+}
+
+// loop decides the traces that waited long enough for their local root, and
+// forgets the decisions of the traces whose late spans are not expected any
+// more.
+func (r *Reporter) loop() {
+	defer close(r.done)
+	tick := time.NewTicker(r.wait / 4)
+	defer tick.Stop()
+	for {
+		select {
+		case <-tick.C:
+		case <-r.quit:
+			return
+		}
+		now := time.Now()
+		var ready []*trace
+		r.mtx.Lock()
+		for e := r.order.Front(); e != nil; {
+			t := e.Value.(*trace)
+			age := now.Sub(t.first)
+			if age < r.wait {
+				break
+			}
+			next := e.Next()
+			if t.state == waiting {
+				ready = append(ready, r.takeLocked(t))
+			}
+			if age >= 2*r.wait {
+				r.removeLocked(e)
+			}
+			e = next
+		}
+		r.mtx.Unlock()
+		r.decide(ready)
+	}
+}
+
+// takeLocked marks t as being decided and returns a copy of it with its
+// spans, for decide. The spans reported meanwhile wait in t.
+func (r *Reporter) takeLocked(t *trace) *trace {
+	t.state = deciding
+	spans := t.spans
+	t.spans = nil
+	return &trace{id: t.id, spans: spans}
+}
+
+func (r *Reporter) removeLocked(e *list.Element) {
+	delete(r.traces, e.Value.(*trace).id)
+	r.order.Remove(e)
+}
+
+// decide reports the spans of the traces kept, those reported while they were
+// decided included, and records the decisions for their late spans.
+func (r *Reporter) decide(ready []*trace) {
+	for _, t := range ready {
+		keep := r.keep(t.id, t.spans)
+		spans := t.spans
+		r.mtx.Lock()
+		if el, ok := r.traces[t.id]; ok {
+			w := el.Value.(*trace)
+			spans = append(spans, w.spans...)
+			w.spans, w.state, w.keep = nil, decided, keep
+		}
+		r.mtx.Unlock()
+		if keep {
+			for _, s := range spans {
+				r.next.Send(s)
+			}
+		}
+	}
+}
+
+func (r *Reporter) keep(id model.TraceID, spans []model.SpanModel) bool {
+	for _, ts := range r.tail {
+		if ts.Keep(spans) {
+			return true
+		}
+	}
+	sampleRate := r.rate
+	matched := false
+	for _, s := range spans {
+		name := strings.ToLower(s.Name)
+		mr, ok := r.methods[name]
+		if !ok {
+			mr, ok = r.methods[path.Base(name)]
+		}
+		if ok && (!matched || mr > sampleRate) {
+			sampleRate, matched = mr, true
+		}
+	}
+	// the low bits of the trace ID are random
+	if float64(id.Low%10000) >= sampleRate*10000 {
+		return false
+	}
+	return r.limiter == nil || r.limiter.Allow()
+}
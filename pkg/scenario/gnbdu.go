@@ -0,0 +1,94 @@
+package scenario
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// GNBDU is the client of the HTTP API of a gNB-DU standing in for the radio,
+// which the UEs of the mobility steps go through.
+type GNBDU struct {
+	url    string
+	client *http.Client
+}
+
+// NewGNBDU returns the client of the gNB-DU at url, such as
+// http://localhost:8680.
+func NewGNBDU(url string) *GNBDU {
+	return &GNBDU{url: strings.TrimSuffix(url, "/"), client: http.DefaultClient}
+}
+
+// radioUE is the context of a UE in a gNB-DU.
+type radioUE struct {
+	DUUEID   uint32   `json:"gnb_du_ue_f1ap_id"`
+	CRNTI    uint32   `json:"c_rnti"`
+	NCI      uint64   `json:"nci"`
+	Downlink []string `json:"downlink"`
+}
+
+func (g *GNBDU) access(ctx context.Context, nci uint64) (u radioUE, err error) {
+	err = g.call(ctx, "/access", map[string]interface{}{"nci": nci}, &u)
+	return u, err
+}
+
+func (g *GNBDU) uplink(ctx context.Context, duUEID uint32, rrc []byte) error {
+	return g.call(ctx, "/uplink", map[string]interface{}{"gnb_du_ue_f1ap_id": duUEID, "rrc": string(rrc)}, nil)
+}
+
+func (g *GNBDU) ue(ctx context.Context, duUEID uint32) (u radioUE, err error) {
+	err = g.call(ctx, "/ue", map[string]interface{}{"gnb_du_ue_f1ap_id": duUEID}, &u)
+	return u, err
+}
+
+// call posts req to path and decodes the response in res, unless nil. The
+// error of a failed call is the one the gNB-DU answered with.
+func (g *GNBDU) call(ctx context.Context, path string, req, res interface{}) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	r, err := http.NewRequest(http.MethodPost, g.url+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	r.Header.Set("Content-Type", "application/json")
+	resp, err := g.client.Do(r.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		var e struct {
+			Error string `json:"error"`
+		}
+		if json.NewDecoder(resp.Body).Decode(&e) != nil || e.Error == "" {
+			e.Error = fmt.Sprintf("gNB-DU %s: %s", path, resp.Status)
+		}
+		return &callError{status: resp.StatusCode, msg: e.Error}
+	}
+	if res == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(res)
+}
+
+// callError is the error a gNB-DU answered a call with.
+type callError struct {
+	status int
+	msg    string
+}
+
+func (e *callError) Error() string {
+	return e.msg
+}
+
+// released tells the error of a call for a UE the gNB-DU no longer has the
+// context of, its RRC connection having been released for inactivity.
+func released(err error) bool {
+	e, ok := err.(*callError)
+	return ok && e.status == http.StatusNotFound
+}
@@ -0,0 +1,242 @@
+package scenario
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/gnodeb/f1"
+)
+
+// The mobility models.
+const (
+	// ModelRandomWaypoint moves every UE to a random point of the area,
+	// pauses, and sets off again to the next one.
+	ModelRandomWaypoint = "random_waypoint"
+	// ModelRoute moves every UE along the route, in a loop, from a random
+	// point of it.
+	ModelRoute = "route"
+)
+
+// DefaultReportInterval is the period of the measurement reports of a
+// scenario that sets none.
+const DefaultReportInterval = time.Second
+
+// DefaultCellPower is the reference signal power, in dBm, of a cell that
+// sets none.
+const DefaultCellPower = 15
+
+// minRSRP is the RSRP, in dBm, below which a UE does not hear a cell.
+const minRSRP = -140
+
+// Mobility describes how the UEs of a scenario move among the cells of the
+// gNB-DUs, in metres and metres per second.
+type Mobility struct {
+	Model string `yaml:"model"`
+	// Area is the corner, opposite to the origin, of the rectangle the
+	// random waypoints are drawn in.
+	Area Point `yaml:"area"`
+	// Route are the waypoints of the route model.
+	Route []Point `yaml:"route"`
+	// Speed is the range the speed of a UE to each waypoint is drawn in.
+	Speed Speed `yaml:"speed"`
+	// Pause is the time a UE stays at each waypoint.
+	Pause time.Duration `yaml:"pause"`
+	// ReportInterval is the period of the measurement reports of a moving
+	// UE, DefaultReportInterval when not set.
+	ReportInterval time.Duration `yaml:"report_interval"`
+	Cells          []Cell        `yaml:"cells"`
+}
+
+// Point is a position.
+type Point struct {
+	X float64 `yaml:"x"`
+	Y float64 `yaml:"y"`
+}
+
+// Speed is a range of speeds.
+type Speed struct {
+	Min float64 `yaml:"min"`
+	Max float64 `yaml:"max"`
+}
+
+// Cell is a cell of a gNB-DU, as the UEs hear it.
+type Cell struct {
+	NCI uint64 `yaml:"nci"`
+	// GNBDU is the name of the gNB-DU serving the cell among the targets.
+	GNBDU string  `yaml:"gnb_du"`
+	X     float64 `yaml:"x"`
+	Y     float64 `yaml:"y"`
+	// Power is the reference signal power of the cell in dBm,
+	// DefaultCellPower when not set.
+	Power float64 `yaml:"power"`
+}
+
+// rsrp returns the RSRP of c at p, with the urban macro path loss of TR
+// 36.942 4.5 for a 2 GHz carrier.
+func (c Cell) rsrp(p Point) float64 {
+	d := math.Max(math.Hypot(p.X-c.X, p.Y-c.Y), 10)
+	return c.Power - (128.1 + 37.6*math.Log10(d/1000))
+}
+
+func (m *Mobility) normalize() error {
+	switch m.Model {
+	case ModelRandomWaypoint:
+		if m.Area.X <= 0 || m.Area.Y <= 0 {
+			return errors.New("mobility: random_waypoint needs a positive area")
+		}
+	case ModelRoute:
+		if len(m.Route) < 2 {
+			return errors.New("mobility: route needs at least two points")
+		}
+		for i, p := range m.Route {
+			if p == m.Route[(i+1)%len(m.Route)] {
+				return fmt.Errorf("mobility: route point %d repeats the previous one", (i+1)%len(m.Route)+1)
+			}
+		}
+	default:
+		return fmt.Errorf("mobility: unknown model %q", m.Model)
+	}
+	if m.Speed.Min <= 0 || m.Speed.Min > m.Speed.Max {
+		return errors.New("mobility: speed must be a range of positive speeds")
+	}
+	if m.ReportInterval <= 0 {
+		m.ReportInterval = DefaultReportInterval
+	}
+	if len(m.Cells) == 0 {
+		return errors.New("mobility: no cells")
+	}
+	seen := make(map[uint64]bool)
+	for i := range m.Cells {
+		c := &m.Cells[i]
+		if c.NCI == 0 || seen[c.NCI] {
+			return fmt.Errorf("mobility: cell %d: missing or duplicate nci", i+1)
+		}
+		seen[c.NCI] = true
+		if c.GNBDU == "" {
+			return fmt.Errorf("mobility: cell %d: missing gnb_du", i+1)
+		}
+		if c.Power == 0 {
+			c.Power = DefaultCellPower
+		}
+	}
+	return nil
+}
+
+// cell returns the cell nci, nil when the scenario has no such cell.
+func (m *Mobility) cell(nci uint64) *Cell {
+	for i := range m.Cells {
+		if m.Cells[i].NCI == nci {
+			return &m.Cells[i]
+		}
+	}
+	return nil
+}
+
+// measure returns the measurement of the cell serving at p and of the
+// cells heard there, best first.
+func (m *Mobility) measure(p Point, serving uint64) f1.MeasResults {
+	var res f1.MeasResults
+	for _, c := range m.Cells {
+		meas := f1.CellMeas{NCI: c.NCI, RSRP: math.Round(c.rsrp(p)*10) / 10}
+		if c.NCI == serving {
+			res.Serving = meas
+		} else if meas.RSRP >= minRSRP {
+			res.Neighbours = append(res.Neighbours, meas)
+		}
+	}
+	sort.Slice(res.Neighbours, func(i, j int) bool { return res.Neighbours[i].RSRP > res.Neighbours[j].RSRP })
+	return res
+}
+
+// best returns the cell heard best at p.
+func (m *Mobility) best(p Point) *Cell {
+	var best *Cell
+	for i := range m.Cells {
+		if best == nil || m.Cells[i].rsrp(p) > best.rsrp(p) {
+			best = &m.Cells[i]
+		}
+	}
+	return best
+}
+
+// mover is the position of a UE, which moves with the time.
+type mover struct {
+	m   *Mobility
+	rnd *rand.Rand
+	// at is the time pos was computed for.
+	at    time.Time
+	pos   Point
+	dest  Point
+	leg   int
+	speed float64
+	// paused is the time the UE has yet to pause at pos.
+	paused time.Duration
+}
+
+// newMover places a UE at a random starting point of m, rnd drawing its
+// waypoints and speeds.
+func newMover(m *Mobility, rnd *rand.Rand, now time.Time) *mover {
+	mv := &mover{m: m, rnd: rnd, at: now}
+	switch m.Model {
+	case ModelRandomWaypoint:
+		mv.pos = mv.waypoint()
+	case ModelRoute:
+		// somewhere on a random leg, so that the UEs spread along the route
+		mv.leg = rnd.Intn(len(m.Route))
+		from, to := m.Route[mv.leg], m.Route[(mv.leg+1)%len(m.Route)]
+		f := rnd.Float64()
+		mv.pos = Point{from.X + f*(to.X-from.X), from.Y + f*(to.Y-from.Y)}
+	}
+	mv.next()
+	return mv
+}
+
+// waypoint draws a random point of the area.
+func (mv *mover) waypoint() Point {
+	return Point{mv.rnd.Float64() * mv.m.Area.X, mv.rnd.Float64() * mv.m.Area.Y}
+}
+
+// next sets off to the next waypoint.
+func (mv *mover) next() {
+	switch mv.m.Model {
+	case ModelRandomWaypoint:
+		mv.dest = mv.waypoint()
+	case ModelRoute:
+		mv.leg = (mv.leg + 1) % len(mv.m.Route)
+		mv.dest = mv.m.Route[mv.leg]
+	}
+	mv.speed = mv.m.Speed.Min + mv.rnd.Float64()*(mv.m.Speed.Max-mv.m.Speed.Min)
+}
+
+// position returns the position of the UE at now.
+func (mv *mover) position(now time.Time) Point {
+	left := now.Sub(mv.at)
+	mv.at = now
+	for left > 0 {
+		if mv.paused > 0 {
+			d := mv.paused
+			if d > left {
+				d = left
+			}
+			mv.paused -= d
+			left -= d
+			continue
+		}
+		dist := math.Hypot(mv.dest.X-mv.pos.X, mv.dest.Y-mv.pos.Y)
+		travel := mv.speed * left.Seconds()
+		if travel < dist {
+			f := travel / dist
+			mv.pos = Point{mv.pos.X + f*(mv.dest.X-mv.pos.X), mv.pos.Y + f*(mv.dest.Y-mv.pos.Y)}
+			break
+		}
+		left -= time.Duration(dist / mv.speed * float64(time.Second))
+		mv.pos = mv.dest
+		mv.paused = mv.m.Pause
+		mv.next()
+	}
+	return mv.pos
+}
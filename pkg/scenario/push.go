@@ -0,0 +1,75 @@
+package scenario
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Push sends the report to the Prometheus Pushgateway at gateway, replacing
+// the metrics of the previous push of job. The metrics are:
+//
+//	scenario_step_duration_seconds histogram of the successful steps
+//	scenario_step_failures_total   failed steps
+//	scenario_phase_arrivals_total  arrivals of a load phase
+//	scenario_phase_dropped_total   arrivals dropped
+//
+// labeled with the scenario name and the step or phase.
+func Push(gateway, job string, r *Report) error {
+	var b bytes.Buffer
+	name := escape(r.Name)
+	fmt.Fprintf(&b, "# TYPE scenario_step_duration_seconds histogram\n")
+	for _, s := range r.Steps {
+		labels := fmt.Sprintf(`scenario="%s",step="%s"`, name, escape(s.Name))
+		cumulative, count, sum := s.Histogram(DefaultBuckets)
+		for i, le := range DefaultBuckets {
+			fmt.Fprintf(&b, "scenario_step_duration_seconds_bucket{%s,le=\"%s\"} %d\n", labels, seconds(le), cumulative[i])
+		}
+		fmt.Fprintf(&b, "scenario_step_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", labels, count)
+		fmt.Fprintf(&b, "scenario_step_duration_seconds_sum{%s} %s\n", labels, seconds(sum))
+		fmt.Fprintf(&b, "scenario_step_duration_seconds_count{%s} %d\n", labels, count)
+	}
+	fmt.Fprintf(&b, "# TYPE scenario_step_failures_total counter\n")
+	for _, s := range r.Steps {
+		_, failures := s.Count()
+		fmt.Fprintf(&b, "scenario_step_failures_total{scenario=\"%s\",step=\"%s\"} %d\n", name, escape(s.Name), failures)
+	}
+	if len(r.Phases) != 0 {
+		fmt.Fprintf(&b, "# TYPE scenario_phase_arrivals_total counter\n")
+		for _, p := range r.Phases {
+			fmt.Fprintf(&b, "scenario_phase_arrivals_total{scenario=\"%s\",phase=\"%s\"} %d\n", name, escape(p.Name), p.Arrivals)
+		}
+		fmt.Fprintf(&b, "# TYPE scenario_phase_dropped_total counter\n")
+		for _, p := range r.Phases {
+			fmt.Fprintf(&b, "scenario_phase_dropped_total{scenario=\"%s\",phase=\"%s\"} %d\n", name, escape(p.Name), p.Dropped)
+		}
+	}
+
+	req, err := http.NewRequest(http.MethodPut, strings.TrimSuffix(gateway, "/")+"/metrics/job/"+url.PathEscape(job), &b)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; version=0.0.4")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("pushgateway: %s", resp.Status)
+	}
+	return nil
+}
+
+func seconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'g', -1, 64)
+}
+
+// escape escapes a label value of the text exposition format.
+func escape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
@@ -0,0 +1,367 @@
+package scenario
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"text/tabwriter"
+	"time"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/clock"
+)
+
+// maxErrors is the number of distinct error messages kept per step.
+const maxErrors = 5
+
+// Run runs sc against t and reports its outcome. Canceling ctx stops the
+// UEs that are not started yet; the run then reports what was done.
+func Run(ctx context.Context, sc *Scenario, t Targets) *Report {
+	if sc.Load != nil {
+		return runLoad(ctx, sc, t)
+	}
+	rep := newReport(sc)
+	clk := clock.Or(t.Clock)
+
+	var tick <-chan time.Time
+	if sc.Rate > 0 {
+		ticker := clk.NewTicker(time.Duration(float64(time.Second) / sc.Rate))
+		defer ticker.Stop()
+		tick = ticker.C()
+	}
+	sem := make(chan struct{}, sc.Concurrency)
+	var wg sync.WaitGroup
+	begin := clk.Now()
+start:
+	for i := 0; i < sc.UEs.Count; i++ {
+		if tick != nil && i > 0 {
+			select {
+			case <-tick:
+			case <-ctx.Done():
+				break start
+			}
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			break start
+		}
+		wg.Add(1)
+		go func(u *ue) {
+			defer func() { <-sem; wg.Done() }()
+			runUE(ctx, sc, u, rep)
+		}(newUE(sc.UEs.SUPIOf(i), &sc.UEs, sc.Mobility, t))
+	}
+	wg.Wait()
+	rep.Elapsed = clk.Since(begin)
+	return rep
+}
+
+// runLoad runs sc open loop: the UEs start at the rate of the load phases,
+// in turns, unless MaxInflight of them are still running.
+func runLoad(ctx context.Context, sc *Scenario, t Targets) *Report {
+	rep := newReport(sc)
+	clk := clock.Or(t.Clock)
+	var (
+		wg       sync.WaitGroup
+		inflight int64
+		k        int
+	)
+	begin := clk.Now()
+phases:
+	for _, p := range sc.Load.Phases {
+		pr := &PhaseReport{Name: p.Name}
+		rep.Phases = append(rep.Phases, pr)
+		start := clk.Now()
+		var next time.Duration
+		for {
+			r := p.RateAt(next)
+			if r <= 0 {
+				// look again in a while, the rate may be ramping up
+				next += 10 * time.Millisecond
+			} else {
+				next += time.Duration(float64(time.Second) / r)
+			}
+			if next >= p.Duration {
+				break
+			}
+			sleep(ctx, clk, start.Add(next).Sub(clk.Now()))
+			if ctx.Err() != nil {
+				pr.Elapsed = clk.Since(start)
+				break phases
+			}
+			if r <= 0 {
+				continue
+			}
+			pr.Arrivals++
+			if atomic.LoadInt64(&inflight) >= int64(sc.Load.MaxInflight) {
+				pr.Dropped++
+				continue
+			}
+			atomic.AddInt64(&inflight, 1)
+			wg.Add(1)
+			go func(u *ue, rep *Report) {
+				defer func() { atomic.AddInt64(&inflight, -1); wg.Done() }()
+				runUE(ctx, sc, u, rep)
+			}(newUE(sc.UEs.SUPIOf(k%sc.UEs.Count), &sc.UEs, sc.Mobility, t), reportUnless(p.Warmup, rep))
+			k++
+		}
+		sleep(ctx, clk, start.Add(p.Duration).Sub(clk.Now()))
+		pr.Elapsed = clk.Since(start)
+	}
+	wg.Wait()
+	rep.Elapsed = clk.Since(begin)
+	return rep
+}
+
+// reportUnless returns rep, or nil to leave the steps unreported when skip
+// is set.
+func reportUnless(skip bool, rep *Report) *Report {
+	if skip {
+		return nil
+	}
+	return rep
+}
+
+// runUE runs the steps of sc for u. A UE stops at its first failure, the
+// steps it does not run are not counted. Nothing is reported when rep is
+// nil. The pauses and waits are on the clock of the targets, the latencies
+// in real time.
+func runUE(ctx context.Context, sc *Scenario, u *ue, rep *Report) {
+	clk := clock.Or(u.t.Clock)
+	for i, st := range sc.Steps {
+		for n := 0; n < st.Repeat; n++ {
+			if ctx.Err() != nil {
+				return
+			}
+			if st.Action == ActionMove {
+				// a move reports its handovers rather than itself
+				var s *StepReport
+				if rep != nil {
+					s = rep.Steps[i]
+				}
+				if u.move(ctx, st, s) != nil {
+					return
+				}
+				sleep(ctx, clk, st.Pause)
+				continue
+			}
+			var err error
+			begin := time.Now()
+			if st.Action == ActionWait {
+				sleep(ctx, clk, st.Duration)
+			} else {
+				rctx, cancel := context.WithTimeout(ctx, st.Timeout)
+				err = u.run(rctx, st.Action)
+				cancel()
+			}
+			if rep != nil {
+				rep.Steps[i].add(time.Since(begin), err)
+			}
+			if err != nil {
+				return
+			}
+			sleep(ctx, clk, st.Pause)
+		}
+	}
+}
+
+func sleep(ctx context.Context, clk clock.Clock, d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	t := clk.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C():
+	case <-ctx.Done():
+	}
+}
+
+// Report is the outcome of a run.
+type Report struct {
+	Name    string
+	UEs     int
+	Elapsed time.Duration
+	Steps   []*StepReport
+	// Phases is set by load runs only.
+	Phases []*PhaseReport
+	expect Expect
+}
+
+// PhaseReport is the outcome of a load phase.
+type PhaseReport struct {
+	Name    string
+	Elapsed time.Duration
+	// Arrivals is the number of UEs that were to start, Dropped the number
+	// of them that did not because MaxInflight UEs were running.
+	Arrivals int
+	Dropped  int
+}
+
+// Rate returns the rate at which the UEs of the phase started.
+func (p *PhaseReport) Rate() float64 {
+	if p.Elapsed <= 0 {
+		return 0
+	}
+	return float64(p.Arrivals-p.Dropped) / p.Elapsed.Seconds()
+}
+
+// StepReport collects the outcome of one step over all the UEs.
+type StepReport struct {
+	Name string
+
+	mtx       sync.Mutex
+	latencies []time.Duration
+	failures  int
+	errors    map[string]int
+}
+
+func newReport(sc *Scenario) *Report {
+	rep := &Report{Name: sc.Name, UEs: sc.UEs.Count, expect: sc.Expect}
+	for i, st := range sc.Steps {
+		rep.Steps = append(rep.Steps, &StepReport{Name: st.Name(i), errors: map[string]int{}})
+	}
+	return rep
+}
+
+func (s *StepReport) add(d time.Duration, err error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if err != nil {
+		s.failures++
+		msg := err.Error()
+		if _, ok := s.errors[msg]; ok || len(s.errors) < maxErrors {
+			s.errors[msg]++
+		}
+		return
+	}
+	s.latencies = append(s.latencies, d)
+}
+
+// Count returns the number of times the step ran, and how many of them
+// failed.
+func (s *StepReport) Count() (n, failures int) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return len(s.latencies) + s.failures, s.failures
+}
+
+// Percentile returns the p-th percentile (0 < p <= 100) of the latencies of
+// the successful runs of the step, zero when there are none.
+func (s *StepReport) Percentile(p float64) time.Duration {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if len(s.latencies) == 0 {
+		return 0
+	}
+	sort.Slice(s.latencies, func(i, j int) bool { return s.latencies[i] < s.latencies[j] })
+	// nearest rank
+	rank := int(p/100*float64(len(s.latencies))+0.999999) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(s.latencies) {
+		rank = len(s.latencies) - 1
+	}
+	return s.latencies[rank]
+}
+
+// DefaultBuckets are the upper bounds of the latency histograms.
+var DefaultBuckets = []time.Duration{
+	500 * time.Microsecond, time.Millisecond, 2500 * time.Microsecond, 5 * time.Millisecond,
+	10 * time.Millisecond, 25 * time.Millisecond, 50 * time.Millisecond, 100 * time.Millisecond,
+	250 * time.Millisecond, 500 * time.Millisecond, time.Second, 2500 * time.Millisecond,
+	5 * time.Second, 10 * time.Second,
+}
+
+// Histogram returns the number of successful runs of the step whose latency
+// is at most each of the buckets, which must be sorted, along with the count
+// and the sum of all the latencies.
+func (s *StepReport) Histogram(buckets []time.Duration) (cumulative []int, count int, sum time.Duration) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	cumulative = make([]int, len(buckets))
+	for _, d := range s.latencies {
+		sum += d
+		for i := len(buckets) - 1; i >= 0 && d <= buckets[i]; i-- {
+			cumulative[i]++
+		}
+	}
+	return cumulative, len(s.latencies), sum
+}
+
+// Failures returns the reasons the run does not meet its expectations, none
+// when it passed. Dropped arrivals fail a load run.
+func (r *Report) Failures() []string {
+	var res []string
+	for _, p := range r.Phases {
+		if p.Dropped > 0 {
+			res = append(res, fmt.Sprintf("phase %s: %d arrivals dropped, max_inflight reached", p.Name, p.Dropped))
+		}
+	}
+	for _, s := range r.Steps {
+		if _, failures := s.Count(); failures > r.expect.MaxFailures {
+			res = append(res, fmt.Sprintf("%s: %d failures, at most %d expected", s.Name, failures, r.expect.MaxFailures))
+		}
+		if p := s.Percentile(50); r.expect.P50 > 0 && p > r.expect.P50 {
+			res = append(res, fmt.Sprintf("%s: p50 %v, at most %v expected", s.Name, p, r.expect.P50))
+		}
+		if p := s.Percentile(99); r.expect.P99 > 0 && p > r.expect.P99 {
+			res = append(res, fmt.Sprintf("%s: p99 %v, at most %v expected", s.Name, p, r.expect.P99))
+		}
+	}
+	return res
+}
+
+// Write writes the report as a table followed by the errors seen and the
+// verdict.
+func (r *Report) Write(w io.Writer) error {
+	fmt.Fprintf(w, "scenario %q: %d UEs in %v\n\n", r.Name, r.UEs, r.Elapsed.Round(time.Millisecond))
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "STEP\tRUNS\tFAILED\tP50\tP90\tP99\tMAX")
+	for _, s := range r.Steps {
+		n, failures := s.Count()
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%v\t%v\t%v\t%v\n", s.Name, n, failures,
+			round(s.Percentile(50)), round(s.Percentile(90)), round(s.Percentile(99)), round(s.Percentile(100)))
+	}
+	if len(r.Phases) != 0 {
+		fmt.Fprintln(tw)
+		fmt.Fprintln(tw, "PHASE\tARRIVALS\tDROPPED\tRATE\tELAPSED")
+		for _, p := range r.Phases {
+			fmt.Fprintf(tw, "%s\t%d\t%d\t%.1f/s\t%v\n", p.Name, p.Arrivals, p.Dropped, p.Rate(), p.Elapsed.Round(time.Millisecond))
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintln(w)
+	for _, s := range r.Steps {
+		s.mtx.Lock()
+		msgs := make([]string, 0, len(s.errors))
+		for msg := range s.errors {
+			msgs = append(msgs, msg)
+		}
+		sort.Strings(msgs)
+		for _, msg := range msgs {
+			fmt.Fprintf(w, "%s: %dx %s\n", s.Name, s.errors[msg], msg)
+		}
+		s.mtx.Unlock()
+	}
+	failures := r.Failures()
+	if len(failures) == 0 {
+		_, err := fmt.Fprintln(w, "PASS")
+		return err
+	}
+	fmt.Fprintln(w, "FAIL")
+	for _, f := range failures {
+		fmt.Fprintf(w, "  %s\n", f)
+	}
+	return nil
+}
+
+func round(d time.Duration) time.Duration {
+	return d.Round(10 * time.Microsecond)
+}
@@ -0,0 +1,359 @@
+// Package scenario runs end-to-end test scenarios against the network
+// functions. A scenario, written in YAML, describes a population of UEs and
+// the sequence of procedures each of them goes through; running it gives the
+// outcome and the latency percentiles of every step.
+//
+//	name: register
+//	ues:
+//	  count: 20
+//	  supi: imsi-208930000000101
+//	  k: 465b5ce8b199b49faa5f0a2ee238a6bc
+//	  opc: cd63cb71954a9f4e48a5994e37a02baf
+//	  serving_network_name: 5G:mnc093.mcc208.3gppnetwork.org
+//	concurrency: 10
+//	rate: 50
+//	steps:
+//	  - action: provision
+//	  - action: register
+//	    repeat: 3
+//	    pause: 100ms
+//	  - action: deprovision
+//	expect:
+//	  max_failures: 0
+//	  p99: 200ms
+//
+// A scenario with a load section is run open loop instead: the UEs run the
+// steps at the rate of the load phases, whether the previous ones are done
+// or not, taking turns.
+//
+//	load:
+//	  max_inflight: 500
+//	  phases:
+//	    - {name: warm-up, duration: 10s, rate: 5, warmup: true}
+//	    - {name: ramp, duration: 30s, rate: 5, to: 50}
+//	    - {name: steady, duration: 60s, rate: 50}
+//	    - {name: spike, duration: 5s, rate: 200}
+//
+// A scenario with a mobility section moves its UEs among the cells of the
+// gNB-DUs: they connect to the best cell where they start, then send
+// measurement reports as they move, and the gNB-CU hands them over.
+//
+//	mobility:
+//	  model: random_waypoint
+//	  area: {x: 2000, y: 1000}
+//	  speed: {min: 10, max: 30}
+//	  pause: 2s
+//	  report_interval: 500ms
+//	  cells:
+//	    - {nci: 4096, gnb_du: du-1, x: 500, y: 500}
+//	    - {nci: 8192, gnb_du: du-2, x: 1500, y: 500}
+//	steps:
+//	  - action: connect
+//	  - action: move
+//	    duration: 60s
+//
+// The UEs may register over WiFi instead, through the N3IWF.
+//
+//	steps:
+//	  - action: provision
+//	  - action: register_wifi
+//	  - action: deregister_wifi
+//
+// Registered over WiFi, the UEs pair up and exchange short messages, CM-IDLE
+// or not.
+//
+//	steps:
+//	  - action: register_wifi
+//	  - action: idle_wifi
+//	  - action: send_sms
+//	  - action: receive_sms
+//
+// They may also update their registration, and move to a new connection
+// with the N3IWF.
+//
+//	steps:
+//	  - action: register_wifi
+//	  - action: idle_wifi
+//	  - action: update_wifi
+//	  - action: move_wifi
+package scenario
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/identity"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/security/milenage"
+)
+
+// The actions a step may run.
+const (
+	// ActionProvision creates the subscriber of the UE in the UDM. A
+	// subscriber that already exists is not a failure.
+	ActionProvision = "provision"
+	// ActionRegister runs 5G-AKA through the AUSF, the UE side included.
+	ActionRegister = "register"
+	// ActionDeprovision deletes the subscriber of the UE from the UDM.
+	ActionDeprovision = "deprovision"
+	// ActionWait does nothing for the duration of the step.
+	ActionWait = "wait"
+	// ActionConnect sets up the RRC connection of the UE on the cell it
+	// hears best, through its gNB-DU.
+	ActionConnect = "connect"
+	// ActionMove moves the connected UE for the duration of the step,
+	// sending measurement reports and completing the handovers the gNB-CU
+	// orders. Every handover is reported as a run of the step, its latency
+	// from the measurement report to the RRC Reconfiguration Complete.
+	ActionMove = "move"
+	// ActionRegisterWiFi registers the UE over WiFi through the N3IWF,
+	// EAP-5G carrying its registration and 5G-AKA, and keeps its IKE SA.
+	ActionRegisterWiFi = "register_wifi"
+	// ActionDeregisterWiFi deregisters the UE registered over WiFi and
+	// deletes its IKE SA.
+	ActionDeregisterWiFi = "deregister_wifi"
+	// ActionIdleWiFi makes the UE registered over WiFi CM-IDLE. The
+	// actions below send a Service Request first, or on paging.
+	ActionIdleWiFi = "idle_wifi"
+	// ActionUpdateWiFi updates the registration of the UE registered over
+	// WiFi, as on the expiry of T3512, CM-IDLE or not. The UE is
+	// CM-CONNECTED afterwards.
+	ActionUpdateWiFi = "update_wifi"
+	// ActionMoveWiFi moves the UE registered over WiFi to a new IKE SA with
+	// the N3IWF, as having moved to another access: its mobility
+	// registration update skips 5G-AKA, and the earlier IKE SA is deleted.
+	ActionMoveWiFi = "move_wifi"
+	// ActionSendSMS submits a short message over NAS to the peer of the UE
+	// registered over WiFi, the UEs pairing up in the order of their
+	// SUPIs.
+	ActionSendSMS = "send_sms"
+	// ActionReceiveSMS waits for the short message of the peer of the UE,
+	// and for the delivery reports of those the UE sent.
+	ActionReceiveSMS = "receive_sms"
+)
+
+// DefaultTimeout bounds every request of a step that sets no timeout.
+const DefaultTimeout = 5 * time.Second
+
+// DefaultMaxInflight bounds the UEs running at the same time in a load run
+// that sets no limit.
+const DefaultMaxInflight = 1000
+
+// Scenario is the description of a run.
+type Scenario struct {
+	Name string `yaml:"name"`
+	UEs  UEs    `yaml:"ues"`
+	// Concurrency is the number of UEs running at the same time, 1 when
+	// not set.
+	Concurrency int `yaml:"concurrency"`
+	// Rate is the number of UEs started per second. When zero they start
+	// as fast as Concurrency allows.
+	Rate   float64 `yaml:"rate"`
+	Steps  []Step  `yaml:"steps"`
+	Expect Expect  `yaml:"expect"`
+	// Load, when set, makes the run open loop. Concurrency and Rate do not
+	// apply then.
+	Load *Load `yaml:"load"`
+	// Mobility is needed by the connect and move steps.
+	Mobility *Mobility `yaml:"mobility"`
+}
+
+// Load describes an open loop run as a sequence of phases.
+type Load struct {
+	Phases []Phase `yaml:"phases"`
+	// MaxInflight is the number of UEs running at the same time past which
+	// new arrivals are dropped, DefaultMaxInflight when not set.
+	MaxInflight int `yaml:"max_inflight"`
+}
+
+// Phase is a period of a load run during which UEs start Rate times per
+// second. When To is set the rate ramps linearly from Rate to To. The steps
+// run during a Warmup phase are not reported.
+type Phase struct {
+	Name     string        `yaml:"name"`
+	Duration time.Duration `yaml:"duration"`
+	Rate     float64       `yaml:"rate"`
+	To       float64       `yaml:"to"`
+	Warmup   bool          `yaml:"warmup"`
+}
+
+// RateAt returns the arrival rate at d into the phase.
+func (p Phase) RateAt(d time.Duration) float64 {
+	if p.To == 0 || p.Duration <= 0 {
+		return p.Rate
+	}
+	return p.Rate + (p.To-p.Rate)*float64(d)/float64(p.Duration)
+}
+
+// UEs describes a population of UEs sharing their credentials. Their SUPIs
+// are consecutive, starting at SUPI.
+type UEs struct {
+	Count              int    `yaml:"count"`
+	SUPI               string `yaml:"supi"`
+	K                  string `yaml:"k"`
+	OPc                string `yaml:"opc"`
+	ServingNetworkName string `yaml:"serving_network_name"`
+	// NSSAI are the slices the UEs request over WiFi, such as "1,2-000001",
+	// the first being the default slice of their subscription. They request
+	// none when it is empty.
+	NSSAI string `yaml:"nssai"`
+
+	k, opc []byte
+	nssai  identity.NSSAI
+}
+
+// Step is one action run by every UE, Repeat times with a Pause after each
+// time.
+type Step struct {
+	Action string `yaml:"action"`
+	// Repeat is 1 when not set.
+	Repeat  int           `yaml:"repeat"`
+	Pause   time.Duration `yaml:"pause"`
+	Timeout time.Duration `yaml:"timeout"`
+	// Duration is the time a wait step waits, or a move step moves.
+	Duration time.Duration `yaml:"duration"`
+}
+
+// Name returns the name of the i-th step in reports.
+func (s Step) Name(i int) string {
+	return strconv.Itoa(i+1) + "." + s.Action
+}
+
+// Expect holds the pass criteria of a run, checked on every step. Zero
+// durations are not checked, while the default MaxFailures fails a run on
+// the first failure.
+type Expect struct {
+	MaxFailures int           `yaml:"max_failures"`
+	P50         time.Duration `yaml:"p50"`
+	P99         time.Duration `yaml:"p99"`
+}
+
+// Parse reads a scenario and checks it.
+func Parse(data []byte) (*Scenario, error) {
+	var sc Scenario
+	if err := yaml.UnmarshalStrict(data, &sc); err != nil {
+		return nil, err
+	}
+	if err := sc.normalize(); err != nil {
+		return nil, err
+	}
+	return &sc, nil
+}
+
+func (sc *Scenario) normalize() (err error) {
+	if sc.Concurrency <= 0 {
+		sc.Concurrency = 1
+	}
+	if sc.Rate < 0 {
+		return errors.New("rate must not be negative")
+	}
+	if sc.UEs.Count <= 0 {
+		return errors.New("ues.count must be positive")
+	}
+	if _, _, err := splitSUPI(sc.UEs.SUPI); err != nil {
+		return err
+	}
+	if sc.UEs.k, err = hex.DecodeString(sc.UEs.K); err != nil || len(sc.UEs.k) != milenage.KeySize {
+		return errors.New("ues.k must be 16 bytes of hex")
+	}
+	if sc.UEs.opc, err = hex.DecodeString(sc.UEs.OPc); err != nil || len(sc.UEs.opc) != milenage.KeySize {
+		return errors.New("ues.opc must be 16 bytes of hex")
+	}
+	if sc.UEs.nssai, err = identity.ParseNSSAI(sc.UEs.NSSAI); err != nil {
+		return fmt.Errorf("ues.nssai: %v", err)
+	}
+	if len(sc.Steps) == 0 {
+		return errors.New("no steps")
+	}
+	if sc.Load != nil {
+		if err := sc.Load.normalize(); err != nil {
+			return err
+		}
+	}
+	if sc.Mobility != nil {
+		if err := sc.Mobility.normalize(); err != nil {
+			return err
+		}
+	}
+	for i := range sc.Steps {
+		st := &sc.Steps[i]
+		switch st.Action {
+		case ActionProvision, ActionDeprovision, ActionWait, ActionDeregisterWiFi, ActionIdleWiFi, ActionUpdateWiFi:
+		case ActionSendSMS, ActionReceiveSMS:
+			if sc.UEs.Count%2 != 0 {
+				return fmt.Errorf("step %d: %s needs an even ues.count, the UEs pairing up", i+1, st.Action)
+			}
+		case ActionRegister, ActionRegisterWiFi, ActionMoveWiFi:
+			if sc.UEs.ServingNetworkName == "" {
+				return fmt.Errorf("step %d: %s needs ues.serving_network_name", i+1, st.Action)
+			}
+		case ActionConnect, ActionMove:
+			if sc.Mobility == nil {
+				return fmt.Errorf("step %d: %s needs a mobility section", i+1, st.Action)
+			}
+			if st.Action == ActionMove && st.Duration <= 0 {
+				return fmt.Errorf("step %d: move needs a positive duration", i+1)
+			}
+		default:
+			return fmt.Errorf("step %d: unknown action %q", i+1, st.Action)
+		}
+		if st.Repeat <= 0 {
+			st.Repeat = 1
+		}
+		if st.Timeout <= 0 {
+			st.Timeout = DefaultTimeout
+		}
+	}
+	return nil
+}
+
+func (l *Load) normalize() error {
+	if len(l.Phases) == 0 {
+		return errors.New("load: no phases")
+	}
+	if l.MaxInflight <= 0 {
+		l.MaxInflight = DefaultMaxInflight
+	}
+	for i, p := range l.Phases {
+		if p.Duration <= 0 {
+			return fmt.Errorf("load: phase %d: duration must be positive", i+1)
+		}
+		if p.Rate < 0 || p.To < 0 {
+			return fmt.Errorf("load: phase %d: rate must not be negative", i+1)
+		}
+		if p.Name == "" {
+			l.Phases[i].Name = strconv.Itoa(i + 1)
+		}
+	}
+	return nil
+}
+
+// SUPIOf returns the SUPI of the i-th UE.
+func (u UEs) SUPIOf(i int) string {
+	prefix, n, _ := splitSUPI(u.SUPI)
+	digits := strconv.FormatUint(n+uint64(i), 10)
+	width := len(u.SUPI) - len(prefix)
+	if len(digits) < width {
+		digits = strings.Repeat("0", width-len(digits)) + digits
+	}
+	return prefix + digits
+}
+
+// splitSUPI splits an IMSI based SUPI into its "imsi-" prefix and the IMSI
+// as a number.
+func splitSUPI(supi string) (string, uint64, error) {
+	const prefix = "imsi-"
+	if !strings.HasPrefix(supi, prefix) {
+		return "", 0, errors.New("ues.supi must be an IMSI based SUPI such as imsi-208930000000001")
+	}
+	n, err := strconv.ParseUint(supi[len(prefix):], 10, 64)
+	if err != nil {
+		return "", 0, errors.New("ues.supi must be an IMSI based SUPI such as imsi-208930000000001")
+	}
+	return prefix, n, nil
+}
@@ -0,0 +1,421 @@
+package scenario
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	ausfpb "github.com/miki-tnt/sa5g-go-usvc-k8s/pb/ausf"
+	udmpb "github.com/miki-tnt/sa5g-go-usvc-k8s/pb/udm"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/clock"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/fsm"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/gnodeb/f1"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/identity"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/security"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/security/milenage"
+)
+
+// Errors of the UE side of 5G-AKA.
+var (
+	ErrAUTN    = errors.New("AUTN is malformed")
+	ErrMAC     = errors.New("network authentication failed, MAC mismatch")
+	ErrHXRES   = errors.New("HXRES* does not match RES*")
+	ErrKSEAF   = errors.New("K_SEAF differs from the one of the UE")
+	errUnknown = errors.New("unknown action")
+)
+
+// Errors of the RRC connection.
+var (
+	ErrConnected    = errors.New("UE already connected")
+	ErrNotConnected = errors.New("UE not connected")
+)
+
+// Targets are the network functions a scenario runs against. The generated
+// clients are used as is, so that the latencies measured are those of the
+// services and not of client side middlewares.
+type Targets struct {
+	AUSF ausfpb.AusfClient
+	UDM  udmpb.UdmClient
+	// N3IWF is the N3IWF the UEs register through over WiFi.
+	N3IWF *N3IWF
+	// GNBDUs are the gNB-DUs the cells of the mobility section name.
+	GNBDUs map[string]*GNBDU
+	// Clock paces the run, the arrivals, the pauses, the waits and the moves
+	// of the UEs, and times the phases; clock.Real when nil. A Scaled clock
+	// runs the scenario faster than real time.
+	Clock clock.Clock
+}
+
+// The 5GMM states of a UE (TS 24.501 5.1.3.2), as far as registration goes.
+const (
+	stateDeregistered   fsm.State = "DEREGISTERED"
+	stateAuthenticating fsm.State = "AUTHENTICATING"
+	stateRegistered     fsm.State = "REGISTERED"
+)
+
+const (
+	eventRegister fsm.Event = "register"
+	eventConfirm  fsm.Event = "confirm"
+	eventFail     fsm.Event = "fail"
+)
+
+// registration is the registration procedure of a UE. A registered UE
+// registers again as for a periodic registration update. The actions get the
+// *procedure being run.
+var registration = fsm.MustNew(fsm.Definition{
+	Name:    "registration",
+	Initial: stateDeregistered,
+	Transitions: []fsm.Transition{
+		{From: []fsm.State{stateDeregistered, stateRegistered}, Event: eventRegister, To: stateAuthenticating, Action: authenticate},
+		{From: []fsm.State{stateAuthenticating}, Event: eventConfirm, To: stateRegistered, Action: confirm},
+		{From: []fsm.State{stateAuthenticating}, Event: eventFail, To: stateDeregistered},
+	},
+})
+
+// ue is a simulated UE.
+type ue struct {
+	supi string
+	ues  *UEs
+	mob  *Mobility
+	t    Targets
+	nas  *fsm.Instance
+	// radio is the RRC connection of the UE, nil until it connects.
+	radio *radio
+	// wifi is the registration of the UE over WiFi, nil until it
+	// registers through the N3IWF.
+	wifi *wifi
+}
+
+func newUE(supi string, ues *UEs, mob *Mobility, t Targets) *ue {
+	return &ue{supi: supi, ues: ues, mob: mob, t: t, nas: registration.Instance()}
+}
+
+// radio is the RRC connection of a UE to a cell.
+type radio struct {
+	mv     *mover
+	cell   *Cell
+	du     *GNBDU
+	duUEID uint32
+	// seen is the number of downlink RRC messages of the gNB-DU context
+	// the UE has read.
+	seen int
+}
+
+// procedure is the state of a registration between its transitions.
+type procedure struct {
+	u        *ue
+	ac       *ausfpb.AuthenticateReply
+	ck, ik   []byte
+	sqnXorAK []byte
+	resStar  []byte
+}
+
+func (u *ue) run(ctx context.Context, action string) error {
+	switch action {
+	case ActionProvision:
+		return u.provision(ctx)
+	case ActionRegister:
+		return u.register(ctx)
+	case ActionDeprovision:
+		return u.deprovision(ctx)
+	case ActionConnect:
+		return u.connect(ctx)
+	case ActionRegisterWiFi:
+		return u.registerWiFi(ctx)
+	case ActionDeregisterWiFi:
+		return u.deregisterWiFi(ctx)
+	case ActionIdleWiFi:
+		return u.idleWiFi(ctx)
+	case ActionUpdateWiFi:
+		return u.updateWiFi(ctx)
+	case ActionMoveWiFi:
+		return u.moveWiFi(ctx)
+	case ActionSendSMS:
+		return u.sendSMS(ctx)
+	case ActionReceiveSMS:
+		return u.receiveSMS(ctx)
+	}
+	return errUnknown
+}
+
+func (u *ue) provision(ctx context.Context) error {
+	_, err := u.t.UDM.CreateSubscriber(ctx, &udmpb.CreateSubscriberRequest{
+		Subscriber: &udmpb.Subscriber{Supi: u.supi, K: u.ues.k, Opc: u.ues.opc, DefaultSnssai: defaultSlice(u.ues.nssai)},
+	})
+	if status.Code(err) == codes.AlreadyExists {
+		return nil
+	}
+	return err
+}
+
+// defaultSlice returns the first slice of nssai, the default one of the
+// subscriptions, nil when it is empty.
+func defaultSlice(nssai identity.NSSAI) *udmpb.Snssai {
+	if len(nssai) == 0 {
+		return nil
+	}
+	return &udmpb.Snssai{Sst: uint32(nssai[0].SST), Sd: nssai[0].SD}
+}
+
+func (u *ue) deprovision(ctx context.Context) error {
+	_, err := u.t.UDM.DeleteSubscriber(ctx, &udmpb.DeleteSubscriberRequest{Supi: u.supi})
+	return err
+}
+
+// register runs 5G-AKA (TS 33.501 6.1.3.2): the UE authenticates the network
+// with AUTN, answers with RES*, and checks the K_SEAF the AMF would get.
+func (u *ue) register(ctx context.Context) error {
+	p := &procedure{u: u}
+	if err := u.nas.Fire(ctx, eventRegister, p); err != nil {
+		return err
+	}
+	if err := u.nas.Fire(ctx, eventConfirm, p); err != nil {
+		u.nas.Fire(ctx, eventFail, p)
+		return err
+	}
+	return nil
+}
+
+// authenticate gets a challenge from the AUSF, checks AUTN and computes
+// RES*.
+func authenticate(ctx context.Context, arg interface{}) error {
+	p := arg.(*procedure)
+	u := p.u
+	ac, err := u.t.AUSF.Authenticate(ctx, &ausfpb.AuthenticateRequest{SupiOrSuci: u.supi, ServingNetworkName: u.ues.ServingNetworkName})
+	if err != nil {
+		return err
+	}
+	ck, ik, sqnXorAK, resStar, err := u.challenge(ac.Rand, ac.Autn)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(security.HResStar(ac.Rand, resStar), ac.HxresStar) {
+		return ErrHXRES
+	}
+	p.ac, p.ck, p.ik, p.sqnXorAK, p.resStar = ac, ck, ik, sqnXorAK, resStar
+	return nil
+}
+
+// challenge runs the UE side of the 5G-AKA challenge RAND and AUTN, as the
+// USIM does: it authenticates the network with AUTN and returns CK, IK, SQN
+// xor AK and RES*.
+func (u *ue) challenge(rand, autn []byte) (ck, ik, sqnXorAK, resStar []byte, err error) {
+	if len(rand) != milenage.RandSize || len(autn) != milenage.SQNSize+milenage.AMFSize+milenage.MACSize {
+		return nil, nil, nil, nil, ErrAUTN
+	}
+
+	m, err := milenage.New(u.ues.k, u.ues.opc)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	o, err := m.F2345(rand)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	// AUTN = SQN xor AK || AMF || MAC
+	sqnXorAK = autn[:milenage.SQNSize]
+	amf := autn[milenage.SQNSize : milenage.SQNSize+milenage.AMFSize]
+	sqn := make([]byte, milenage.SQNSize)
+	for i := range sqn {
+		sqn[i] = sqnXorAK[i] ^ o.AK[i]
+	}
+	mac, err := m.F1(rand, sqn, amf)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	if !bytes.Equal(mac[:], autn[milenage.SQNSize+milenage.AMFSize:]) {
+		return nil, nil, nil, nil, ErrMAC
+	}
+	resStar = security.ResStar(o.CK[:], o.IK[:], u.ues.ServingNetworkName, rand, o.RES[:])
+	return o.CK[:], o.IK[:], sqnXorAK, resStar, nil
+}
+
+// confirm sends RES* to the AUSF and checks the K_SEAF it releases.
+func confirm(ctx context.Context, arg interface{}) error {
+	p := arg.(*procedure)
+	u := p.u
+	snn := u.ues.ServingNetworkName
+	cr, err := u.t.AUSF.ConfirmAuth(ctx, &ausfpb.ConfirmAuthRequest{AuthCtxId: p.ac.AuthCtxId, ResStar: p.resStar})
+	if err != nil {
+		return err
+	}
+	kausf := security.Kausf(p.ck, p.ik, snn, p.sqnXorAK)
+	if !bytes.Equal(security.Kseaf(kausf, snn), cr.Kseaf) {
+		return ErrKSEAF
+	}
+	return nil
+}
+
+// connect sets up the RRC connection of the UE on the cell it hears best
+// (TS 38.401 8.1), checking the RRC messages it receives on the way. The UE
+// starts moving from there.
+func (u *ue) connect(ctx context.Context) error {
+	if u.radio != nil {
+		return ErrConnected
+	}
+	now := clock.Or(u.t.Clock).Now()
+	return u.setup(ctx, newMover(u.mob, rand.New(rand.NewSource(seed(u.supi))), now), now)
+}
+
+// setup sets up the RRC connection of the UE moving as mv on the cell it
+// hears best at now.
+func (u *ue) setup(ctx context.Context, mv *mover, now time.Time) error {
+	c := u.mob.best(mv.position(now))
+	du, err := u.gnbdu(c)
+	if err != nil {
+		return err
+	}
+	rue, err := du.access(ctx, c.NCI)
+	if err != nil {
+		return err
+	}
+	if err := expectRRC(rue, f1.RRCSetup); err != nil {
+		return err
+	}
+	if err := du.uplink(ctx, rue.DUUEID, []byte(f1.RRCSetupComplete)); err != nil {
+		return err
+	}
+	if rue, err = du.ue(ctx, rue.DUUEID); err != nil {
+		return err
+	}
+	if err := expectRRC(rue, f1.RRCReconfiguration); err != nil {
+		return err
+	}
+	if err := du.uplink(ctx, rue.DUUEID, []byte(f1.RRCReconfigurationComplete)); err != nil {
+		return err
+	}
+	u.radio = &radio{mv: mv, cell: c, du: du, duUEID: rue.DUUEID, seen: len(rue.Downlink)}
+	return nil
+}
+
+// move moves the connected UE for the duration of st, reporting its
+// measurements every report interval, and adds the handovers and the
+// failure, if any, to s unless it is nil.
+func (u *ue) move(ctx context.Context, st Step, s *StepReport) error {
+	add := func(d time.Duration, err error) {
+		if s != nil {
+			s.add(d, err)
+		}
+	}
+	if u.radio == nil {
+		add(0, ErrNotConnected)
+		return ErrNotConnected
+	}
+	clk := clock.Or(u.t.Clock)
+	end := clk.Now().Add(st.Duration)
+	for left := st.Duration; left > 0; left = end.Sub(clk.Now()) {
+		if left > u.mob.ReportInterval {
+			left = u.mob.ReportInterval
+		}
+		sleep(ctx, clk, left)
+		if ctx.Err() != nil {
+			return nil
+		}
+		begin := time.Now()
+		rctx, cancel := context.WithTimeout(ctx, st.Timeout)
+		handedOver, err := u.report(rctx, clk.Now())
+		cancel()
+		if err != nil {
+			add(time.Since(begin), err)
+			return err
+		}
+		if handedOver {
+			add(time.Since(begin), nil)
+		}
+	}
+	return nil
+}
+
+// report sends the measurement report of the UE at now, and completes the
+// handover the gNB-CU answers it with, if any. A UE whose RRC connection
+// was released for inactivity, between two steps, connects again where it
+// is.
+func (u *ue) report(ctx context.Context, now time.Time) (handedOver bool, err error) {
+	r := u.radio
+	meas := u.mob.measure(r.mv.position(now), r.cell.NCI)
+	err = r.du.uplink(ctx, r.duUEID, f1.RRC(f1.MeasurementReport, meas))
+	if released(err) {
+		u.radio = nil
+		return false, u.setup(ctx, r.mv, now)
+	}
+	if err != nil {
+		return false, err
+	}
+	rue, err := r.du.ue(ctx, r.duUEID)
+	if err != nil {
+		return false, err
+	}
+	var dl []string
+	if r.seen < len(rue.Downlink) {
+		dl = rue.Downlink[r.seen:]
+	}
+	r.seen = len(rue.Downlink)
+	for _, m := range dl {
+		name, ies := f1.ParseRRC([]byte(m))
+		if name != f1.RRCReconfiguration || ies == nil {
+			continue
+		}
+		var sync f1.ReconfigurationWithSync
+		if err := json.Unmarshal(ies, &sync); err != nil {
+			return false, fmt.Errorf("malformed %s: %v", name, err)
+		}
+		return true, u.handover(ctx, sync)
+	}
+	return false, nil
+}
+
+// handover moves the UE to the target cell of sync, where it completes the
+// RRC Reconfiguration.
+func (u *ue) handover(ctx context.Context, sync f1.ReconfigurationWithSync) error {
+	c := u.mob.cell(sync.NCI)
+	if c == nil {
+		return fmt.Errorf("handover to cell %09x, not in the scenario", sync.NCI)
+	}
+	du, err := u.gnbdu(c)
+	if err != nil {
+		return err
+	}
+	// the gNB-DUs use the C-RNTI as gNB-DU UE F1AP ID
+	if err := du.uplink(ctx, sync.CRNTI, []byte(f1.RRCReconfigurationComplete)); err != nil {
+		return err
+	}
+	u.radio.cell, u.radio.du, u.radio.duUEID, u.radio.seen = c, du, sync.CRNTI, 0
+	return nil
+}
+
+// gnbdu returns the target serving c.
+func (u *ue) gnbdu(c *Cell) (*GNBDU, error) {
+	du, ok := u.t.GNBDUs[c.GNBDU]
+	if !ok {
+		return nil, fmt.Errorf("no target for gNB-DU %q", c.GNBDU)
+	}
+	return du, nil
+}
+
+// expectRRC checks that the last RRC message the UE received is name.
+func expectRRC(rue radioUE, name string) error {
+	var last string
+	if n := len(rue.Downlink); n > 0 {
+		last, _ = f1.ParseRRC([]byte(rue.Downlink[n-1]))
+	}
+	if last != name {
+		return fmt.Errorf("expected %s, got %q", name, last)
+	}
+	return nil
+}
+
+// seed returns the seed of the random moves of the UE supi, so that a
+// scenario moves its UEs the same way from one run to the next.
+func seed(supi string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(supi))
+	return int64(h.Sum64())
+}
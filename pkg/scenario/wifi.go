@@ -0,0 +1,495 @@
+package scenario
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/n3iwf/n2"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/n3iwf/n2/nasbuild"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/n3iwf/nwu"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/plmn"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/security"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/security/nas"
+)
+
+// Errors of the registration over WiFi.
+var (
+	ErrWiFiRegistered    = errors.New("UE already registered over WiFi")
+	ErrWiFiNotRegistered = errors.New("UE not registered over WiFi")
+)
+
+// establishmentCause is the establishment cause of the AN parameters of the
+// UEs.
+const establishmentCause = n2.EstablishmentMOSignalling
+
+// N3IWF is the N3IWF the UEs register through over WiFi, an untrusted
+// non-3GPP access.
+type N3IWF struct {
+	address string
+	config  *tls.Config
+}
+
+// NewN3IWF returns the N3IWF at address, its NWu reached over TLS with
+// config.
+func NewN3IWF(address string, config *tls.Config) *N3IWF {
+	return &N3IWF{address: address, config: config}
+}
+
+// wifi is the registration of a UE over WiFi: its IKE SA with the N3IWF.
+type wifi struct {
+	c       *nwu.Client
+	innerIP string
+	guti    string
+	// kamf is the K_AMF of the registration, which the K_N3IWF of the IKE
+	// SA is derived from, again when the UE moves to another, and sec its
+	// NAS security context, which the UE keeps.
+	kamf []byte
+	sec  *nas.Context
+	// idle is true while the UE is CM-IDLE, on its request or released by
+	// the N3IWF for inactivity.
+	idle bool
+	// mr is the reference of the last short message the UE submitted,
+	// reports those whose status report it waits for.
+	mr      uint8
+	reports map[uint8]bool
+}
+
+// registerWiFi registers the UE through the N3IWF (TS 24.502 7.3.2): EAP-5G
+// carries its Registration Request, 5G-AKA and the security mode control,
+// then the UE derives K_N3IWF from K_AMF and the uplink NAS COUNT of its
+// Security Mode Complete as the AMF does, authenticates the IKE SA with it
+// and gets its inner address and the Registration Accept.
+func (u *ue) registerWiFi(ctx context.Context) (err error) {
+	if u.wifi != nil {
+		return ErrWiFiRegistered
+	}
+	if u.t.N3IWF == nil {
+		return errors.New("no target for the N3IWF")
+	}
+	snn := u.ues.ServingNetworkName
+	selected, err := plmn.FromServingNetworkName(snn)
+	if err != nil {
+		return err
+	}
+	c, err := nwu.Dial(ctx, u.t.N3IWF.address, u.t.N3IWF.config)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			c.Close()
+		}
+	}()
+
+	dl, _, err := c.EAP(ctx, nasbuild.RegistrationRequest().WithMobileIdentity(u.supi).WithNSSAI(u.ues.nssai...).NAS(),
+		&n2.ANParameters{SelectedPLMN: selected.String(), EstablishmentCause: establishmentCause})
+	if err != nil {
+		return rejected(dl, err)
+	}
+	var ar n2.AuthenticationRequestIEs
+	if err := expectNAS(dl, n2.AuthenticationRequest, &ar); err != nil {
+		return err
+	}
+	ck, ik, sqnXorAK, resStar, err := u.challenge(ar.RAND, ar.AUTN)
+	if err == ErrMAC {
+		c.EAP(ctx, nasbuild.AuthenticationFailure(n2.CauseMACFailure).NAS(), nil)
+	}
+	if err != nil {
+		return err
+	}
+	dl, _, err = c.EAP(ctx, nasbuild.AuthenticationResponse(resStar).NAS(), nil)
+	if err != nil {
+		return rejected(dl, err)
+	}
+
+	kseaf := security.Kseaf(security.Kausf(ck, ik, snn, sqnXorAK), snn)
+	kamf := security.Kamf(kseaf, u.supi, ar.ABBA)
+	sec, err := securityMode(ctx, c, kamf, dl)
+	if err != nil {
+		return err
+	}
+	count := sec.Count(nas.Uplink)
+	complete, err := sec.Protect(nasbuild.SecurityModeComplete().NAS(), nas.IntegrityProtectedAndCipheredNew, nas.Uplink)
+	if err != nil {
+		return err
+	}
+	dl, success, err := c.EAP(ctx, complete, nil)
+	if err != nil {
+		return rejected(unprotectedOrNil(sec, dl), err)
+	}
+	if !success {
+		return fmt.Errorf("expected EAP-Success, got %q", nasName(sec, dl))
+	}
+	cfg, dl, err := c.Authenticate(ctx, security.Kn3iwf(kamf, count))
+	if err != nil {
+		return err
+	}
+	w := &wifi{c: c, innerIP: cfg.InnerIP, kamf: kamf, sec: sec, reports: make(map[uint8]bool)}
+	if err := w.accepted(ctx, dl); err != nil {
+		return err
+	}
+	u.wifi = w
+	return nil
+}
+
+// securityMode takes the NAS security context of kamf that the Security
+// Mode Command smc selects, once it verifies with it. A Security Mode
+// Command that does not is turned down with a Security Mode Reject.
+func securityMode(ctx context.Context, c *nwu.Client, kamf, smc []byte) (*nas.Context, error) {
+	var cmd n2.SecurityModeCommandIEs
+	plain, ok := nas.Plain(smc)
+	if !ok {
+		return nil, errors.New("ciphered Security Mode Command")
+	}
+	if err := expectNAS(plain, n2.SecurityModeCommand, &cmd); err != nil {
+		return nil, err
+	}
+	sec := nas.NewContext(kamf, nas.CipheringAlg(cmd.CipheringAlgorithm), nas.IntegrityAlg(cmd.IntegrityAlgorithm), nas.AccessNon3GPP)
+	if _, ht, _, err := sec.Unprotect(smc, nas.Downlink); err != nil || ht != nas.IntegrityProtectedWithNewContext {
+		c.EAP(ctx, nasbuild.SecurityModeReject(n2.CauseSecurityModeRejected).NAS(), nil)
+		if err == nil {
+			err = fmt.Errorf("security header type %d", ht)
+		}
+		return nil, fmt.Errorf("Security Mode Command turned down: %v", err)
+	}
+	return sec, nil
+}
+
+// accepted checks that the NAS message dl, which came with the IKE_AUTH
+// response, is the Registration Accept of the UE, and completes the
+// registration.
+func (w *wifi) accepted(ctx context.Context, dl []byte) error {
+	dl, err := unprotect(w.sec, dl)
+	if err != nil {
+		return err
+	}
+	var ra n2.RegistrationAcceptIEs
+	if err := expectNAS(dl, n2.RegistrationAccept, &ra); err != nil {
+		return err
+	}
+	w.guti = ra.GUTI
+	_, err = w.exchange(ctx, nasbuild.RegistrationComplete().NAS(), false)
+	return err
+}
+
+// updateWiFi sends the periodic registration update of the UE registered
+// over WiFi (TS 24.501 5.5.1.3), which a CM-IDLE UE sends in place of a
+// Service Request, and checks that the AMF accepts it.
+func (u *ue) updateWiFi(ctx context.Context) error {
+	w := u.wifi
+	if w == nil {
+		return ErrWiFiNotRegistered
+	}
+	// the N3IWF takes the update as the first NAS message of a signalling
+	// connection when it released the UE meanwhile
+	dl, err := w.exchange(ctx, nasbuild.RegistrationRequest().WithRegistrationType(n2.RegistrationPeriodic).WithMobileIdentity(w.guti).NAS(), true)
+	if err != nil {
+		return rejected(dl, err)
+	}
+	var ra n2.RegistrationAcceptIEs
+	if err := expectNAS(dl, n2.RegistrationAccept, &ra); err != nil {
+		return err
+	}
+	// a release read meanwhile came before the Registration Accept
+	w.c.Released()
+	w.idle = false
+	return nil
+}
+
+// moveWiFi moves the UE registered over WiFi to a new IKE SA, as having
+// moved to another access (TS 24.502 7.3.2): EAP-5G carries its mobility
+// registration update with its 5G-GUTI, integrity protected with the NAS
+// security context it has, then the UE authenticates the IKE SA with the
+// K_N3IWF of its K_AMF and the uplink NAS COUNT of the update, 5G-AKA being
+// skipped. The N3IWF deletes the earlier IKE SA.
+func (u *ue) moveWiFi(ctx context.Context) (err error) {
+	w := u.wifi
+	if w == nil {
+		return ErrWiFiNotRegistered
+	}
+	selected, err := plmn.FromServingNetworkName(u.ues.ServingNetworkName)
+	if err != nil {
+		return err
+	}
+	c, err := nwu.Dial(ctx, u.t.N3IWF.address, u.t.N3IWF.config)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			c.Close()
+		}
+	}()
+	count := w.sec.Count(nas.Uplink)
+	update, err := w.sec.Protect(nasbuild.RegistrationRequest().WithRegistrationType(n2.RegistrationMobility).WithMobileIdentity(w.guti).NAS(), nas.IntegrityProtected, nas.Uplink)
+	if err != nil {
+		return err
+	}
+	dl, success, err := c.EAP(ctx, update, &n2.ANParameters{SelectedPLMN: selected.String(), EstablishmentCause: establishmentCause})
+	if err != nil {
+		return rejected(unprotectedOrNil(w.sec, dl), err)
+	}
+	if !success {
+		return fmt.Errorf("expected EAP-Success, got %q", nasName(w.sec, dl))
+	}
+	cfg, dl, err := c.Authenticate(ctx, security.Kn3iwf(w.kamf, count))
+	if err != nil {
+		return err
+	}
+	moved := &wifi{c: c, innerIP: cfg.InnerIP, kamf: w.kamf, sec: w.sec, mr: w.mr, reports: w.reports}
+	if err := moved.accepted(ctx, dl); err != nil {
+		return err
+	}
+	w.c.Close()
+	u.wifi = moved
+	return nil
+}
+
+// idleWiFi makes the UE registered over WiFi CM-IDLE.
+func (u *ue) idleWiFi(ctx context.Context) error {
+	if u.wifi == nil {
+		return ErrWiFiNotRegistered
+	}
+	if err := u.wifi.c.Idle(ctx); err != nil {
+		return err
+	}
+	u.wifi.idle = true
+	return nil
+}
+
+// serviceRequest brings the UE registered over WiFi back to CM-CONNECTED
+// when CM-IDLE.
+func (w *wifi) serviceRequest(ctx context.Context) error {
+	if w.c.Released() {
+		w.idle = true
+	}
+	if !w.idle {
+		return nil
+	}
+	dl, err := w.exchange(ctx, nasbuild.ServiceRequest(w.guti).NAS(), true)
+	if err != nil {
+		return err
+	}
+	if err := expectNAS(dl, n2.ServiceAccept, nil); err != nil {
+		return err
+	}
+	// a release read meanwhile came before the Service Accept
+	w.c.Released()
+	w.idle = false
+	return nil
+}
+
+// nas sends the NAS message msg of the UE registered over WiFi, going
+// CM-CONNECTED first, and returns the one answering it. A NAS message the
+// N3IWF turns down, having released the UE for inactivity while it was on
+// the way, is sent again after a Service Request.
+func (w *wifi) nas(ctx context.Context, msg []byte) ([]byte, error) {
+	if err := w.serviceRequest(ctx); err != nil {
+		return nil, err
+	}
+	dl, err := w.exchange(ctx, msg, false)
+	if e, ok := err.(*nwu.NotifyError); ok && e.Type == nwu.NotifyTemporaryFailure && w.c.Released() {
+		w.idle = true
+		if err := w.serviceRequest(ctx); err != nil {
+			return nil, err
+		}
+		return w.exchange(ctx, msg, false)
+	}
+	return dl, err
+}
+
+// exchange sends the plain NAS message msg of the UE registered over WiFi
+// integrity protected, and ciphered unless initial, the N3IWF reading the
+// initial ones, and returns the plain NAS message answering it.
+func (w *wifi) exchange(ctx context.Context, msg []byte, initial bool) ([]byte, error) {
+	ht := nas.IntegrityProtectedAndCiphered
+	if initial {
+		ht = nas.IntegrityProtected
+	}
+	protected, err := w.sec.Protect(msg, ht, nas.Uplink)
+	if err != nil {
+		return nil, err
+	}
+	dl, err := w.c.NAS(ctx, protected)
+	if err != nil {
+		return unprotectedOrNil(w.sec, dl), err
+	}
+	return unprotect(w.sec, dl)
+}
+
+// sendSMS submits a short message to the peer of the UE registered over
+// WiFi (SMS over NAS, TS 23.502 4.13.3.3), going CM-CONNECTED first, and
+// checks that the SMSF accepts it.
+func (u *ue) sendSMS(ctx context.Context) error {
+	w := u.wifi
+	if w == nil {
+		return ErrWiFiNotRegistered
+	}
+	w.mr++
+	dl, err := w.nas(ctx, nasbuild.SMS(u.peer(), "hello from "+u.supi).WithReference(w.mr).Uplink().NAS())
+	if err != nil {
+		return err
+	}
+	var t n2.NASTransportIEs
+	if err := expectNAS(dl, n2.DLNASTransport, &t); err != nil {
+		return err
+	}
+	name, ies := n2.ParseNAS(t.PayloadContainer)
+	if name == n2.RPError {
+		var e n2.RPErrorIEs
+		json.Unmarshal(ies, &e)
+		return fmt.Errorf("short message rejected, RP cause %d", e.Cause)
+	}
+	var ack n2.RPAckIEs
+	if err := expectNAS(t.PayloadContainer, n2.RPAck, &ack); err != nil {
+		return err
+	}
+	if ack.Reference != w.mr {
+		return fmt.Errorf("RP-ACK of reference %d, %d expected", ack.Reference, w.mr)
+	}
+	w.reports[w.mr] = true
+	return nil
+}
+
+// receiveSMS waits for the short message of the peer of the UE registered
+// over WiFi, and for the status reports of those the UE submitted,
+// acknowledging them. A CM-IDLE UE waits to be paged first (TS 23.502
+// 4.13.3.6).
+func (u *ue) receiveSMS(ctx context.Context) error {
+	w := u.wifi
+	if w == nil {
+		return ErrWiFiNotRegistered
+	}
+	peer := u.peer()
+	received := false
+	for !received || len(w.reports) != 0 {
+		m, err := w.c.Downlink(ctx)
+		if err != nil {
+			return err
+		}
+		if m.Paging != "" {
+			if m.Paging == w.guti {
+				if err := w.serviceRequest(ctx); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		var t n2.NASTransportIEs
+		var data n2.RPDataIEs
+		dl, err := unprotect(w.sec, m.NAS)
+		if err != nil {
+			return err
+		}
+		if err := expectNAS(dl, n2.DLNASTransport, &t); err != nil {
+			return err
+		}
+		if err := expectNAS(t.PayloadContainer, n2.RPData, &data); err != nil {
+			return err
+		}
+		if _, err := w.nas(ctx, nasbuild.RPAck(data.Reference).Uplink().NAS()); err != nil {
+			return err
+		}
+		switch r := data.StatusReport; {
+		case data.SMS != nil && data.SMS.Originator == peer:
+			received = true
+		case r != nil && w.reports[r.MessageReference]:
+			delete(w.reports, r.MessageReference)
+			if !r.Delivered {
+				return fmt.Errorf("short message %d not delivered to %s, RP cause %d", r.MessageReference, r.Recipient, r.Cause)
+			}
+		}
+	}
+	return nil
+}
+
+// deregisterWiFi deregisters the UE registered over WiFi and deletes its
+// IKE SA.
+func (u *ue) deregisterWiFi(ctx context.Context) error {
+	if u.wifi == nil {
+		return ErrWiFiNotRegistered
+	}
+	w := u.wifi
+	u.wifi = nil
+	defer w.c.Close()
+	dl, err := w.nas(ctx, nasbuild.DeregistrationRequest().NAS())
+	if err != nil {
+		return err
+	}
+	return expectNAS(dl, n2.DeregistrationAccept, nil)
+}
+
+// peer returns the SUPI of the UE the UE exchanges short messages with,
+// the UEs pairing up in the order of their SUPIs.
+func (u *ue) peer() string {
+	_, first, _ := splitSUPI(u.ues.SUPI)
+	_, n, _ := splitSUPI(u.supi)
+	return u.ues.SUPIOf(int(n-first) ^ 1)
+}
+
+// expectNAS checks that nas is the NAS message name, and decodes its IEs
+// into ies unless it is nil.
+func expectNAS(nas []byte, name string, ies interface{}) error {
+	got, b := n2.ParseNAS(nas)
+	if got != name {
+		return fmt.Errorf("expected %s, got %q", name, got)
+	}
+	if ies == nil {
+		return nil
+	}
+	if err := json.Unmarshal(b, ies); err != nil {
+		return fmt.Errorf("malformed %s: %v", name, err)
+	}
+	return nil
+}
+
+// unprotect returns the plain NAS message of msg, downlink to the UE of
+// the NAS security context sec, which must verify. Only an empty message
+// is taken as it is.
+func unprotect(sec *nas.Context, msg []byte) ([]byte, error) {
+	if len(msg) == 0 {
+		return msg, nil
+	}
+	if msg[0] != nas.EPD5GMM {
+		got, _ := n2.ParseNAS(msg)
+		return nil, fmt.Errorf("%q not security protected", got)
+	}
+	plain, _, _, err := sec.Unprotect(msg, nas.Downlink)
+	return plain, err
+}
+
+// unprotectedOrNil returns the plain NAS message of msg, downlink to the UE
+// of sec: msg itself when not security protected, as a reject may be, and
+// nil when it does not verify.
+func unprotectedOrNil(sec *nas.Context, msg []byte) []byte {
+	if len(msg) == 0 || msg[0] != nas.EPD5GMM {
+		return msg
+	}
+	plain, _, _, err := sec.Unprotect(msg, nas.Downlink)
+	if err != nil {
+		return nil
+	}
+	return plain
+}
+
+// nasName returns the name of the NAS message msg, downlink to the UE of
+// sec.
+func nasName(sec *nas.Context, msg []byte) string {
+	got, _ := n2.ParseNAS(unprotectedOrNil(sec, msg))
+	return got
+}
+
+// rejected returns err with the cause of the NAS message nas rejecting the
+// UE, if any.
+func rejected(nas []byte, err error) error {
+	name, b := n2.ParseNAS(nas)
+	if name == "" {
+		return err
+	}
+	var ci n2.CauseIEs
+	json.Unmarshal(b, &ci)
+	return fmt.Errorf("%v: %s, cause %d", err, name, ci.Cause)
+}
@@ -0,0 +1,223 @@
+// Package schema versions the records the services keep in their stores,
+// the JSON objects of their subscribers, UE contexts and sessions, so that
+// their models can change without the stores being wiped on upgrade.
+//
+// Every record of a Schema is stamped with its version, the first member
+// of its object: {"schema_version":2,...}. The records written before their
+// schema was versioned carry no stamp and are of version 1. A new version
+// of a model comes with the Migration upgrading the records of the previous
+// one, which works on the decoded object rather than on the Go type, long
+// gone by then:
+//
+//	var Schema = &schema.Schema{Name: "subscriber", Prefix: KeyPrefix, Migrations: []schema.Migration{
+//		{Description: "amf as a hex string", Up: func(r map[string]interface{}) error { ... }},
+//	}}
+//
+// The records are upgraded either lazily, by the Store of NewStore, which
+// upgrades those it reads and stamps those it writes, so that they are
+// stored upgraded once written again, or offline by cmd/migrate, which
+// rewrites them all before the new release starts. The migrations only go
+// forward: an older release reads the records of a newer one as they are,
+// its JSON decoding ignoring the stamp and the members it does not know.
+package schema
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/store"
+)
+
+// VersionKey is the member of the records holding their version.
+const VersionKey = "schema_version"
+
+var stamp = []byte(`{"` + VersionKey + `":`)
+
+// Migration upgrades a record to the next version of its schema.
+type Migration struct {
+	// Description says what the migration changes, for cmd/migrate.
+	Description string
+	// Up changes the decoded object r of the record in place, its numbers
+	// being json.Numbers.
+	Up func(r map[string]interface{}) error
+}
+
+// Schema is the versioned model of the records under a key prefix.
+type Schema struct {
+	// Name names the model, in the errors and cmd/migrate.
+	Name string
+	// Prefix starts the store keys of the records.
+	Prefix string
+	// Migrations upgrade the records, Migrations[i] those of version i+1.
+	Migrations []Migration
+}
+
+// Version returns the current version of s, 1 without migrations.
+func (s *Schema) Version() int {
+	return len(s.Migrations) + 1
+}
+
+// Stamp returns the record data stamped with the current version of s. It
+// returns data as it is when it is already stamped, or is not a JSON
+// object.
+func (s *Schema) Stamp(data []byte) []byte {
+	trimmed := bytes.TrimLeft(data, " \t\r\n")
+	if len(trimmed) == 0 || trimmed[0] != '{' || bytes.HasPrefix(trimmed, stamp) {
+		return data
+	}
+	rest := bytes.TrimLeft(trimmed[1:], " \t\r\n")
+	stamped := make([]byte, 0, len(stamp)+4+len(rest))
+	stamped = append(stamped, stamp...)
+	stamped = strconv.AppendInt(stamped, int64(s.Version()), 10)
+	if len(rest) == 0 || rest[0] != '}' {
+		stamped = append(stamped, ',')
+	}
+	return append(stamped, rest...)
+}
+
+// VersionOf returns the version the record data is stamped with, 1 when it
+// is not.
+func VersionOf(data []byte) int {
+	data = bytes.TrimLeft(data, " \t\r\n")
+	if !bytes.HasPrefix(data, stamp) {
+		return 1
+	}
+	data = data[len(stamp):]
+	i := 0
+	for i < len(data) && '0' <= data[i] && data[i] <= '9' {
+		i++
+	}
+	v, err := strconv.Atoi(string(data[:i]))
+	if err != nil {
+		return 1
+	}
+	return v
+}
+
+// Upgrade returns the record data upgraded to the current version of s,
+// and the version it had. It returns data as it is when it is current, or
+// of a newer version.
+func (s *Schema) Upgrade(data []byte) ([]byte, int, error) {
+	from := VersionOf(data)
+	if from >= s.Version() {
+		return data, from, nil
+	}
+	var r map[string]interface{}
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	if err := d.Decode(&r); err != nil {
+		return nil, from, fmt.Errorf("schema: %s version %d: %v", s.Name, from, err)
+	}
+	for v := from; v < s.Version(); v++ {
+		if err := s.Migrations[v-1].Up(r); err != nil {
+			return nil, from, fmt.Errorf("schema: %s version %d to %d: %v", s.Name, v, v+1, err)
+		}
+	}
+	delete(r, VersionKey)
+	upgraded, err := json.Marshal(r)
+	if err != nil {
+		return nil, from, fmt.Errorf("schema: %s: %v", s.Name, err)
+	}
+	return s.Stamp(upgraded), from, nil
+}
+
+type versionedStore struct {
+	store.Store
+	schemas []*Schema
+}
+
+// NewStore returns st, upgrading the records of schemas it reads to their
+// current version and stamping the records it writes with it. The keys of
+// no schema are passed through, and st itself returned without schemas.
+func NewStore(st store.Store, schemas ...*Schema) store.Store {
+	if len(schemas) == 0 {
+		return st
+	}
+	return &versionedStore{Store: st, schemas: schemas}
+}
+
+// schema returns the schema of key, nil when there is none.
+func (s *versionedStore) schema(key string) *Schema {
+	var match *Schema
+	for _, sc := range s.schemas {
+		if strings.HasPrefix(key, sc.Prefix) && (match == nil || len(sc.Prefix) > len(match.Prefix)) {
+			match = sc
+		}
+	}
+	return match
+}
+
+func (s *versionedStore) Get(ctx context.Context, key string) ([]byte, error) {
+	value, err := s.Store.Get(ctx, key)
+	if err != nil {
+		return value, err
+	}
+	if sc := s.schema(key); sc != nil {
+		value, _, err = sc.Upgrade(value)
+	}
+	return value, err
+}
+
+func (s *versionedStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if sc := s.schema(key); sc != nil {
+		value = sc.Stamp(value)
+	}
+	return s.Store.Set(ctx, key, value, ttl)
+}
+
+func (s *versionedStore) SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	if sc := s.schema(key); sc != nil {
+		value = sc.Stamp(value)
+	}
+	return s.Store.SetNX(ctx, key, value, ttl)
+}
+
+// CompareAndSwap swaps the record of key if it holds old once upgraded, as
+// Get returned it.
+func (s *versionedStore) CompareAndSwap(ctx context.Context, key string, old, value []byte, ttl time.Duration) (bool, error) {
+	sc := s.schema(key)
+	if sc == nil {
+		return s.Store.CompareAndSwap(ctx, key, old, value, ttl)
+	}
+	raw, ok, err := s.stored(ctx, sc, key, old)
+	if !ok || err != nil {
+		return false, err
+	}
+	return s.Store.CompareAndSwap(ctx, key, raw, sc.Stamp(value), ttl)
+}
+
+// CompareAndDelete deletes the record of key if it holds old once
+// upgraded, as Get returned it.
+func (s *versionedStore) CompareAndDelete(ctx context.Context, key string, old []byte) (bool, error) {
+	sc := s.schema(key)
+	if sc == nil {
+		return s.Store.CompareAndDelete(ctx, key, old)
+	}
+	raw, ok, err := s.stored(ctx, sc, key, old)
+	if !ok || err != nil {
+		return false, err
+	}
+	return s.Store.CompareAndDelete(ctx, key, raw)
+}
+
+// stored returns the record of key as it is stored, and whether it holds
+// old once upgraded by sc.
+func (s *versionedStore) stored(ctx context.Context, sc *Schema, key string, old []byte) ([]byte, bool, error) {
+	raw, err := s.Store.Get(ctx, key)
+	if err == store.ErrNotFound {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	upgraded, _, err := sc.Upgrade(raw)
+	if err != nil {
+		return nil, false, err
+	}
+	return raw, bytes.Equal(upgraded, old), nil
+}
@@ -0,0 +1,103 @@
+// Package security implements the 5G key hierarchy of 3GPP TS 33.501 and the
+// helpers of the 5G-AKA exchange.
+package security
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+)
+
+// Function codes of the key derivations of TS 33.501 Annex A.
+const (
+	FCKausf   byte = 0x6A
+	FCResStar byte = 0x6B
+	FCKseaf   byte = 0x6C
+	FCKamf    byte = 0x6D
+	FCAlgKey  byte = 0x69
+	FCKgnb    byte = 0x6E
+)
+
+// Access type distinguishers of K_gNB and K_N3IWF (TS 33.501 A.9).
+const (
+	Access3GPP    byte = 0x01
+	AccessNon3GPP byte = 0x02
+)
+
+// Algorithm type distinguishers of the NAS keys (TS 33.501 A.8).
+const (
+	NASEncAlg byte = 0x01
+	NASIntAlg byte = 0x02
+)
+
+// KDF is the generic key derivation function of TS 33.220 Annex B.2:
+// HMAC-SHA-256(key, FC || P0 || L0 || P1 || L1 || ...).
+func KDF(key []byte, fc byte, params ...[]byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte{fc})
+	var l [2]byte
+	for _, p := range params {
+		mac.Write(p)
+		binary.BigEndian.PutUint16(l[:], uint16(len(p)))
+		mac.Write(l[:])
+	}
+	return mac.Sum(nil)
+}
+
+// ServingNetworkName returns the serving network name of TS 24.501 for the
+// PLMN, for example "5G:mnc093.mcc208.3gppnetwork.org".
+func ServingNetworkName(mcc, mnc string) string {
+	if len(mnc) == 2 {
+		mnc = "0" + mnc
+	}
+	return fmt.Sprintf("5G:mnc%s.mcc%s.3gppnetwork.org", mnc, mcc)
+}
+
+// Kausf derives K_AUSF from CK, IK and SQN xor AK (TS 33.501 A.2).
+func Kausf(ck, ik []byte, servingNetworkName string, sqnXorAK []byte) []byte {
+	return KDF(concat(ck, ik), FCKausf, []byte(servingNetworkName), sqnXorAK)
+}
+
+// ResStar derives RES* or XRES* from CK, IK, RAND and RES or XRES
+// (TS 33.501 A.4).
+func ResStar(ck, ik []byte, servingNetworkName string, rand, res []byte) []byte {
+	return KDF(concat(ck, ik), FCResStar, []byte(servingNetworkName), rand, res)[16:]
+}
+
+// HResStar derives HRES* or HXRES* from RAND and RES* or XRES*
+// (TS 33.501 A.5).
+func HResStar(rand, resStar []byte) []byte {
+	sum := sha256.Sum256(concat(rand, resStar))
+	return sum[16:]
+}
+
+// Kseaf derives K_SEAF from K_AUSF (TS 33.501 A.6).
+func Kseaf(kausf []byte, servingNetworkName string) []byte {
+	return KDF(kausf, FCKseaf, []byte(servingNetworkName))
+}
+
+// Kamf derives K_AMF from K_SEAF, the SUPI and the ABBA parameter
+// (TS 33.501 A.7).
+func Kamf(kseaf []byte, supi string, abba []byte) []byte {
+	return KDF(kseaf, FCKamf, []byte(supi), abba)
+}
+
+// Kn3iwf derives K_N3IWF from K_AMF and the uplink NAS COUNT of the
+// non-3GPP access (TS 33.501 A.9).
+func Kn3iwf(kamf []byte, ulNASCount uint32) []byte {
+	var count [4]byte
+	binary.BigEndian.PutUint32(count[:], ulNASCount)
+	return KDF(kamf, FCKgnb, count[:], []byte{AccessNon3GPP})
+}
+
+// NASKey derives the 128-bit NAS key of the algorithm alg from K_AMF. typ is
+// NASEncAlg or NASIntAlg (TS 33.501 A.8).
+func NASKey(kamf []byte, typ, alg byte) []byte {
+	return KDF(kamf, FCAlgKey, []byte{typ}, []byte{alg})[16:]
+}
+
+func concat(a, b []byte) []byte {
+	res := make([]byte, 0, len(a)+len(b))
+	return append(append(res, a...), b...)
+}
@@ -0,0 +1,108 @@
+package security
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+)
+
+func unhex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// hmacSHA256 is the KDF of TS 33.220 B.2 on the input string s, spelled out
+// by the tests parameter by parameter.
+func hmacSHA256(key []byte, s string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(s))
+	return mac.Sum(nil)
+}
+
+// The keys of the derivations of TS 33.501 Annex A, which publishes no test
+// data: CK, IK, RAND, RES and SQN xor AK are those of test set 1 of
+// TS 35.208, and the input strings S are spelled out with their FC and
+// lengths.
+const (
+	snn       = "5G:mnc093.mcc208.3gppnetwork.org"
+	ck        = "b40ba9a3c58b2a05bbf0d987b21bf8cb"
+	ik        = "f769bcd751044604127672711c6d3441"
+	rand      = "23553cbe9637a89d218ae64dae47bf35"
+	res       = "a54211d5e3ba50bf"
+	sqnXorAK  = "55f328b43577"
+	supiIMSI  = "208930000000001"
+	abbaValue = "0000"
+)
+
+func TestServingNetworkName(t *testing.T) {
+	if got := ServingNetworkName("208", "93"); got != snn {
+		t.Errorf("got %q, want %q", got, snn)
+	}
+	if got := ServingNetworkName("310", "410"); got != "5G:mnc410.mcc310.3gppnetwork.org" {
+		t.Errorf("got %q for a 3-digit MNC", got)
+	}
+}
+
+func TestKeyDerivations(t *testing.T) {
+	ckik := unhex(t, ck+ik)
+
+	// A.2: FC 0x6A, P0 the serving network name, P1 SQN xor AK
+	kausf := Kausf(unhex(t, ck), unhex(t, ik), snn, unhex(t, sqnXorAK))
+	if want := hmacSHA256(ckik, "\x6a"+snn+"\x00\x20"+string(unhex(t, sqnXorAK))+"\x00\x06"); !bytes.Equal(kausf, want) {
+		t.Errorf("K_AUSF %x, want %x", kausf, want)
+	}
+	if len(kausf) != 32 {
+		t.Errorf("K_AUSF of %d bytes", len(kausf))
+	}
+
+	// A.4: FC 0x6B, P0 the serving network name, P1 RAND, P2 RES, the 128
+	// least significant bits
+	resStar := ResStar(unhex(t, ck), unhex(t, ik), snn, unhex(t, rand), unhex(t, res))
+	s := "\x6b" + snn + "\x00\x20" + string(unhex(t, rand)) + "\x00\x10" + string(unhex(t, res)) + "\x00\x08"
+	if want := hmacSHA256(ckik, s)[16:]; !bytes.Equal(resStar, want) {
+		t.Errorf("RES* %x, want %x", resStar, want)
+	}
+
+	// A.5: SHA-256 of RAND || RES*, the 128 least significant bits
+	hresStar := HResStar(unhex(t, rand), resStar)
+	sum := sha256.Sum256(append(unhex(t, rand), resStar...))
+	if !bytes.Equal(hresStar, sum[16:]) {
+		t.Errorf("HRES* %x, want %x", hresStar, sum[16:])
+	}
+
+	// A.6: FC 0x6C, P0 the serving network name
+	kseaf := Kseaf(kausf, snn)
+	if want := hmacSHA256(kausf, "\x6c"+snn+"\x00\x20"); !bytes.Equal(kseaf, want) {
+		t.Errorf("K_SEAF %x, want %x", kseaf, want)
+	}
+
+	// A.7: FC 0x6D, P0 the SUPI, P1 ABBA
+	kamf := Kamf(kseaf, supiIMSI, unhex(t, abbaValue))
+	if want := hmacSHA256(kseaf, "\x6d"+supiIMSI+"\x00\x0f\x00\x00\x00\x02"); !bytes.Equal(kamf, want) {
+		t.Errorf("K_AMF %x, want %x", kamf, want)
+	}
+
+	// A.8: FC 0x69, P0 the algorithm type distinguisher, P1 the algorithm
+	// identity, the 128 least significant bits
+	knasInt := NASKey(kamf, NASIntAlg, 2)
+	if want := hmacSHA256(kamf, "\x69\x02\x00\x01\x02\x00\x01")[16:]; !bytes.Equal(knasInt, want) {
+		t.Errorf("K_NASint %x, want %x", knasInt, want)
+	}
+	knasEnc := NASKey(kamf, NASEncAlg, 2)
+	if want := hmacSHA256(kamf, "\x69\x01\x00\x01\x02\x00\x01")[16:]; !bytes.Equal(knasEnc, want) {
+		t.Errorf("K_NASenc %x, want %x", knasEnc, want)
+	}
+
+	// A.9: FC 0x6E, P0 the uplink NAS COUNT, P1 the access type
+	// distinguisher of the non-3GPP access
+	kn3iwf := Kn3iwf(kamf, 0x01020304)
+	if want := hmacSHA256(kamf, "\x6e\x01\x02\x03\x04\x00\x04\x02\x00\x01"); !bytes.Equal(kn3iwf, want) {
+		t.Errorf("K_N3IWF %x, want %x", kn3iwf, want)
+	}
+}
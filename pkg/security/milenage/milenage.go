@@ -0,0 +1,176 @@
+// Package milenage implements the MILENAGE authentication and key generation
+// functions f1, f1*, f2, f3, f4, f5 and f5* of 3GPP TS 35.206.
+package milenage
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"errors"
+)
+
+// Sizes of the MILENAGE inputs and outputs, in bytes.
+const (
+	KeySize  = 16
+	RandSize = 16
+	SQNSize  = 6
+	AMFSize  = 2
+	MACSize  = 8
+	RESSize  = 8
+	AKSize   = 6
+)
+
+// ErrSize is returned when an input does not have the size MILENAGE expects.
+var ErrSize = errors.New("milenage: invalid input size")
+
+// Milenage holds the subscriber key K and the operator variant OPc.
+type Milenage struct {
+	block cipher.Block
+	opc   [16]byte
+}
+
+// Output collects the results of f2, f3, f4 and f5 for one RAND.
+type Output struct {
+	RES [RESSize]byte
+	CK  [KeySize]byte
+	IK  [KeySize]byte
+	AK  [AKSize]byte
+}
+
+// New returns the MILENAGE functions for the subscriber key k and the
+// operator variant opc.
+func New(k, opc []byte) (*Milenage, error) {
+	if len(k) != KeySize || len(opc) != KeySize {
+		return nil, ErrSize
+	}
+	block, err := aes.NewCipher(k)
+	if err != nil {
+		return nil, err
+	}
+	m := &Milenage{block: block}
+	copy(m.opc[:], opc)
+	return m, nil
+}
+
+// OPc derives the operator variant OPc from the key k and the operator
+// variant OP.
+func OPc(k, op []byte) ([]byte, error) {
+	if len(k) != KeySize || len(op) != KeySize {
+		return nil, ErrSize
+	}
+	block, err := aes.NewCipher(k)
+	if err != nil {
+		return nil, err
+	}
+	opc := make([]byte, KeySize)
+	block.Encrypt(opc, op)
+	xor(opc, opc, op)
+	return opc, nil
+}
+
+// F1 computes the network authentication code MAC-A.
+func (m *Milenage) F1(rand, sqn, amf []byte) ([MACSize]byte, error) {
+	var mac [MACSize]byte
+	out, err := m.out1(rand, sqn, amf)
+	if err != nil {
+		return mac, err
+	}
+	copy(mac[:], out[:8])
+	return mac, nil
+}
+
+// F1Star computes the resynchronisation authentication code MAC-S.
+func (m *Milenage) F1Star(rand, sqn, amf []byte) ([MACSize]byte, error) {
+	var mac [MACSize]byte
+	out, err := m.out1(rand, sqn, amf)
+	if err != nil {
+		return mac, err
+	}
+	copy(mac[:], out[8:])
+	return mac, nil
+}
+
+// F2345 computes RES, CK, IK and AK.
+func (m *Milenage) F2345(rand []byte) (Output, error) {
+	var o Output
+	temp, err := m.temp(rand)
+	if err != nil {
+		return o, err
+	}
+	out2 := m.out(temp, 0, 1)
+	copy(o.AK[:], out2[:6])
+	copy(o.RES[:], out2[8:])
+	out3 := m.out(temp, 4, 2)
+	copy(o.CK[:], out3[:])
+	out4 := m.out(temp, 8, 4)
+	copy(o.IK[:], out4[:])
+	return o, nil
+}
+
+// F5Star computes the resynchronisation anonymity key AK*.
+func (m *Milenage) F5Star(rand []byte) ([AKSize]byte, error) {
+	var ak [AKSize]byte
+	temp, err := m.temp(rand)
+	if err != nil {
+		return ak, err
+	}
+	out5 := m.out(temp, 12, 8)
+	copy(ak[:], out5[:6])
+	return ak, nil
+}
+
+// temp computes TEMP = E_K(RAND xor OPc).
+func (m *Milenage) temp(rand []byte) ([16]byte, error) {
+	var temp [16]byte
+	if len(rand) != RandSize {
+		return temp, ErrSize
+	}
+	xor(temp[:], rand, m.opc[:])
+	m.block.Encrypt(temp[:], temp[:])
+	return temp, nil
+}
+
+// out1 computes OUT1 = E_K(TEMP xor rot(IN1 xor OPc, r1) xor c1) xor OPc.
+func (m *Milenage) out1(rand, sqn, amf []byte) ([16]byte, error) {
+	var out [16]byte
+	if len(sqn) != SQNSize || len(amf) != AMFSize {
+		return out, ErrSize
+	}
+	temp, err := m.temp(rand)
+	if err != nil {
+		return out, err
+	}
+	var in1 [16]byte
+	copy(in1[0:], sqn)
+	copy(in1[6:], amf)
+	copy(in1[8:], sqn)
+	copy(in1[14:], amf)
+	xor(in1[:], in1[:], m.opc[:])
+	// r1 = 64 bits, c1 = 0.
+	for i := range out {
+		out[i] = temp[i] ^ in1[(i+8)%16]
+	}
+	m.block.Encrypt(out[:], out[:])
+	xor(out[:], out[:], m.opc[:])
+	return out, nil
+}
+
+// out computes OUTn = E_K(rot(TEMP xor OPc, r) xor c) xor OPc, with the
+// rotation r given in bytes and c the value of the last byte of the
+// constant, the other bytes being zero.
+func (m *Milenage) out(temp [16]byte, r int, c byte) [16]byte {
+	var in, out [16]byte
+	xor(in[:], temp[:], m.opc[:])
+	for i := range out {
+		out[i] = in[(i+r)%16]
+	}
+	out[15] ^= c
+	m.block.Encrypt(out[:], out[:])
+	xor(out[:], out[:], m.opc[:])
+	return out
+}
+
+func xor(dst, a, b []byte) {
+	for i := range dst {
+		dst[i] = a[i] ^ b[i]
+	}
+}
@@ -0,0 +1,119 @@
+package milenage
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func unhex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// The test sets 1 to 4 of TS 35.208 4.3.
+var testSets = []struct {
+	k, rand, sqn, amf, op, opc string
+	f1, f1Star, f2, f5, f3, f4 string
+	f5Star                     string
+}{
+	{
+		k: "465b5ce8b199b49faa5f0a2ee238a6bc", rand: "23553cbe9637a89d218ae64dae47bf35",
+		sqn: "ff9bb4d0b607", amf: "b9b9",
+		op: "cdc202d5123e20f62b6d676ac72cb318", opc: "cd63cb71954a9f4e48a5994e37a02baf",
+		f1: "4a9ffac354dfafb3", f1Star: "01cfaf9ec4e871e9", f2: "a54211d5e3ba50bf", f5: "aa689c648370",
+		f3: "b40ba9a3c58b2a05bbf0d987b21bf8cb", f4: "f769bcd751044604127672711c6d3441",
+		f5Star: "451e8beca43b",
+	},
+	{
+		k: "0396eb317b6d1c36f19c1c84cd6ffd16", rand: "c00d603103dcee52c4478119494202e8",
+		sqn: "fd8eef40df7d", amf: "af17",
+		op: "ff53bade17df5d4e793073ce9d7579fa", opc: "53c15671c60a4b731c55b4a441c0bde2",
+		f1: "5df5b31807e258b0", f1Star: "a8c016e51ef4a343", f2: "d3a628ed988620f0", f5: "c47783995f72",
+		f3: "58c433ff7a7082acd424220f2b67c556", f4: "21a8c1f929702adb3e738488b9f5c5da",
+		f5Star: "30f1197061c1",
+	},
+	{
+		k: "fec86ba6eb707ed08905757b1bb44b8f", rand: "9f7c8d021accf4db213ccff0c7f71a6a",
+		sqn: "9d0277595ffc", amf: "725c",
+		op: "dbc59adcb6f9a0ef735477b7fadf8374", opc: "1006020f0a478bf6b699f15c062e42b3",
+		f1: "9cabc3e99baf7281", f1Star: "95814ba2b3044324", f2: "8011c48c0c214ed2", f5: "33484dc2136b",
+		f3: "5dbdbb2954e8f3cde665b046179a5098", f4: "59a92d3b476a0443487055cf88b2307b",
+		f5Star: "deacdd848cc6",
+	},
+	{
+		k: "9e5944aea94b81165c82fbf9f32db751", rand: "ce83dbc54ac0274a157c17f80d017bd6",
+		sqn: "0b604a81eca8", amf: "9e09",
+		op: "223014c5806694c007ca1eeef57f004f", opc: "a64a507ae1a2a98bb88eb4210135dc87",
+		f1: "74a58220cba84c49", f1Star: "ac2cc74a96871837", f2: "f365cd683cd92e96", f5: "f0b9c08ad02e",
+		f3: "e203edb3971574f5a94b0d61b816345d", f4: "0c4524adeac041c4dd830d20854fc46b",
+		f5Star: "6085a86c6f63",
+	},
+}
+
+func TestTestSets(t *testing.T) {
+	for i, ts := range testSets {
+		check := func(f string, got []byte, want string) {
+			t.Helper()
+			if w := unhex(t, want); !bytes.Equal(got, w) {
+				t.Errorf("test set %d: %s %x, want %x", i+1, f, got, w)
+			}
+		}
+		k, rand, sqn, amf := unhex(t, ts.k), unhex(t, ts.rand), unhex(t, ts.sqn), unhex(t, ts.amf)
+		opc, err := OPc(k, unhex(t, ts.op))
+		if err != nil {
+			t.Fatal(err)
+		}
+		check("OPc", opc, ts.opc)
+		m, err := New(k, opc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mac, err := m.F1(rand, sqn, amf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		check("f1", mac[:], ts.f1)
+		macS, err := m.F1Star(rand, sqn, amf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		check("f1*", macS[:], ts.f1Star)
+		o, err := m.F2345(rand)
+		if err != nil {
+			t.Fatal(err)
+		}
+		check("f2", o.RES[:], ts.f2)
+		check("f3", o.CK[:], ts.f3)
+		check("f4", o.IK[:], ts.f4)
+		check("f5", o.AK[:], ts.f5)
+		akS, err := m.F5Star(rand)
+		if err != nil {
+			t.Fatal(err)
+		}
+		check("f5*", akS[:], ts.f5Star)
+	}
+}
+
+func TestSizes(t *testing.T) {
+	if _, err := New(make([]byte, 15), make([]byte, 16)); err != ErrSize {
+		t.Errorf("New of a short K: %v, want %v", err, ErrSize)
+	}
+	if _, err := OPc(make([]byte, 16), make([]byte, 17)); err != ErrSize {
+		t.Errorf("OPc of a long OP: %v, want %v", err, ErrSize)
+	}
+	m, err := New(make([]byte, 16), make([]byte, 16))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.F1(make([]byte, 16), make([]byte, 5), make([]byte, 2)); err != ErrSize {
+		t.Errorf("f1 of a short SQN: %v, want %v", err, ErrSize)
+	}
+	if _, err := m.F2345(make([]byte, 8)); err != ErrSize {
+		t.Errorf("f2345 of a short RAND: %v, want %v", err, ErrSize)
+	}
+}
@@ -0,0 +1,194 @@
+// Package nas implements the 5G NAS security of 3GPP TS 33.501 and
+// TS 24.501: the ciphering and integrity algorithms and the security context
+// protecting the NAS messages exchanged by a UE and its AMF.
+package nas
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// KeySize is the size of the NAS keys, in bytes.
+const KeySize = 16
+
+// MACSize is the size of a NAS message authentication code, in bytes.
+const MACSize = 4
+
+// CipheringAlg is a 5G NAS encryption algorithm identity (TS 33.501 5.11.1.1).
+type CipheringAlg uint8
+
+// The ciphering algorithms. NEA1 (SNOW 3G) and NEA3 (ZUC) are not
+// implemented.
+const (
+	NEA0 CipheringAlg = iota
+	NEA1
+	NEA2
+	NEA3
+)
+
+// IntegrityAlg is a 5G NAS integrity algorithm identity (TS 33.501 5.11.1.2).
+type IntegrityAlg uint8
+
+// The integrity algorithms. NIA1 (SNOW 3G) and NIA3 (ZUC) are not
+// implemented.
+const (
+	NIA0 IntegrityAlg = iota
+	NIA1
+	NIA2
+	NIA3
+)
+
+// Direction is the direction bit of the algorithms' input.
+type Direction uint8
+
+// The directions.
+const (
+	Uplink   Direction = 0
+	Downlink Direction = 1
+)
+
+// Bearer is the BEARER input of the NAS algorithms: the access type the
+// messages are sent on (TS 33.501 6.4.3.1).
+type Bearer uint8
+
+// The bearers.
+const (
+	Access3GPP    Bearer = 0
+	AccessNon3GPP Bearer = 1
+)
+
+var (
+	// ErrUnsupportedAlg is returned for an algorithm this package does not
+	// implement.
+	ErrUnsupportedAlg = errors.New("nas: unsupported algorithm")
+	// ErrKeySize is returned for a key that is not 128-bit long.
+	ErrKeySize = errors.New("nas: invalid key size")
+)
+
+func (a CipheringAlg) String() string {
+	return fmt.Sprintf("NEA%d", uint8(a))
+}
+
+func (a IntegrityAlg) String() string {
+	return fmt.Sprintf("NIA%d", uint8(a))
+}
+
+// Encrypt ciphers or deciphers data with alg; both are the same operation.
+// data is left unchanged, the result is a new slice.
+func Encrypt(alg CipheringAlg, key []byte, count uint32, bearer Bearer, dir Direction, data []byte) ([]byte, error) {
+	res := make([]byte, len(data))
+	switch alg {
+	case NEA0:
+		copy(res, data)
+		return res, nil
+	case NEA2:
+		block, err := newBlock(key)
+		if err != nil {
+			return nil, err
+		}
+		var iv [aes.BlockSize]byte
+		binary.BigEndian.PutUint32(iv[:4], count)
+		iv[4] = byte(bearer)<<3 | byte(dir)<<2
+		cipher.NewCTR(block, iv[:]).XORKeyStream(res, data)
+		return res, nil
+	default:
+		return nil, ErrUnsupportedAlg
+	}
+}
+
+// MAC computes the message authentication code of msg with alg. NIA0 returns
+// a zero MAC.
+func MAC(alg IntegrityAlg, key []byte, count uint32, bearer Bearer, dir Direction, msg []byte) (mac [MACSize]byte, err error) {
+	switch alg {
+	case NIA0:
+		return mac, nil
+	case NIA2:
+		block, err := newBlock(key)
+		if err != nil {
+			return mac, err
+		}
+		m := make([]byte, 8+len(msg))
+		binary.BigEndian.PutUint32(m[:4], count)
+		m[4] = byte(bearer)<<3 | byte(dir)<<2
+		copy(m[8:], msg)
+		t := cmac(block, m)
+		copy(mac[:], t[:MACSize])
+		return mac, nil
+	default:
+		return mac, ErrUnsupportedAlg
+	}
+}
+
+// VerifyMAC reports whether mac is the code of msg, in constant time.
+func VerifyMAC(alg IntegrityAlg, key []byte, count uint32, bearer Bearer, dir Direction, msg, mac []byte) (bool, error) {
+	want, err := MAC(alg, key, count, bearer, dir, msg)
+	if err != nil {
+		return false, err
+	}
+	return subtle.ConstantTimeCompare(want[:], mac) == 1, nil
+}
+
+func newBlock(key []byte) (cipher.Block, error) {
+	if len(key) != KeySize {
+		return nil, ErrKeySize
+	}
+	return aes.NewCipher(key)
+}
+
+// cmac is AES-CMAC of RFC 4493.
+func cmac(block cipher.Block, msg []byte) [aes.BlockSize]byte {
+	var l, k1, k2 [aes.BlockSize]byte
+	block.Encrypt(l[:], l[:])
+	shiftLeft(k1[:], l[:])
+	shiftLeft(k2[:], k1[:])
+
+	n := (len(msg) + aes.BlockSize - 1) / aes.BlockSize
+	complete := n > 0 && len(msg)%aes.BlockSize == 0
+	if n == 0 {
+		n = 1
+	}
+	var last [aes.BlockSize]byte
+	tail := msg[(n-1)*aes.BlockSize:]
+	if complete {
+		for i := range last {
+			last[i] = tail[i] ^ k1[i]
+		}
+	} else {
+		copy(last[:], tail)
+		last[len(tail)] = 0x80
+		for i := range last {
+			last[i] ^= k2[i]
+		}
+	}
+
+	var x [aes.BlockSize]byte
+	for b := 0; b < n-1; b++ {
+		for i := range x {
+			x[i] ^= msg[b*aes.BlockSize+i]
+		}
+		block.Encrypt(x[:], x[:])
+	}
+	for i := range x {
+		x[i] ^= last[i]
+	}
+	block.Encrypt(x[:], x[:])
+	return x
+}
+
+// shiftLeft sets dst to src shifted left by one bit, xored with the
+// constant Rb when the most significant bit of src is set.
+func shiftLeft(dst, src []byte) {
+	var carry byte
+	for i := len(src) - 1; i >= 0; i-- {
+		b := src[i]
+		dst[i] = b<<1 | carry
+		carry = b >> 7
+	}
+	if carry != 0 {
+		dst[len(dst)-1] ^= 0x87
+	}
+}
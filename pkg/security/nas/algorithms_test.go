@@ -0,0 +1,90 @@
+package nas
+
+import (
+	"bytes"
+	"crypto/aes"
+	"encoding/hex"
+	"testing"
+)
+
+func unhex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// The examples of RFC 4493 4, on the first 0, 16, 40 and 64 bytes of msg.
+func TestCMAC(t *testing.T) {
+	const (
+		key = "2b7e151628aed2a6abf7158809cf4f3c"
+		msg = "6bc1bee22e409f96e93d7e117393172a" +
+			"ae2d8a571e03ac9c9eb76fac45af8e51" +
+			"30c81c46a35ce411e5fbc1191a0a52ef" +
+			"f69f2445df4f9b17ad2b417be66c3710"
+	)
+	block, err := aes.NewCipher(unhex(t, key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		len int
+		mac string
+	}{
+		{0, "bb1d6929e95937287fa37d129b756746"},
+		{16, "070a16b46b4d4144f79bdd9dd04a287c"},
+		{40, "dfa66747de9ae63030ca32611497c827"},
+		{64, "51f0bebf7e3b9d92fc49741779363cfe"},
+	} {
+		mac := cmac(block, unhex(t, msg)[:test.len])
+		if want := unhex(t, test.mac); !bytes.Equal(mac[:], want) {
+			t.Errorf("%d bytes: CMAC %x, want %x", test.len, mac, want)
+		}
+	}
+}
+
+// 128-EIA2 test set 2 of TS 33.401 C.2.2, the first of a whole number of
+// bytes; the 58 bits of test set 1 cannot be given to MAC.
+func TestMACNIA2(t *testing.T) {
+	key := unhex(t, "d3c5d592327fb11c4035c6680af8c6d1")
+	msg := unhex(t, "484583d5afe082ae")
+	mac, err := MAC(NIA2, key, 0x398a59b4, 0x1a, Downlink, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := unhex(t, "b93787e6"); !bytes.Equal(mac[:], want) {
+		t.Errorf("MAC %x, want %x", mac, want)
+	}
+	if ok, err := VerifyMAC(NIA2, key, 0x398a59b4, 0x1a, Downlink, msg, mac[:]); !ok || err != nil {
+		t.Errorf("VerifyMAC = %v, %v, want true", ok, err)
+	}
+	if ok, _ := VerifyMAC(NIA2, key, 0x398a59b4, 0x1a, Uplink, msg, mac[:]); ok {
+		t.Error("VerifyMAC of the other direction = true, want false")
+	}
+}
+
+// 128-EEA2 test set 1 of TS 33.401 C.1.1: 253 bits, the last 3 bits of its
+// plaintext and ciphertext being zero.
+func TestEncryptNEA2(t *testing.T) {
+	key := unhex(t, "d3c5d592327fb11c4035c6680af8c6d1")
+	plain := unhex(t, "981ba6824c1bfb1ab485472029b71d808ce33e2cc3c0b5fc1f3de8a6dc66b1f0")
+	want := unhex(t, "e9fed8a63d155304d71df20bf3e82214b20ed7dad2f233dc3c22d7bdeeed8e78")
+	ct, err := Encrypt(NEA2, key, 0x398a59b4, 0x15, Downlink, plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ct[len(ct)-1] &^= 0x07
+	if !bytes.Equal(ct, want) {
+		t.Errorf("Encrypt = %x, want %x", ct, want)
+	}
+	back, err := Encrypt(NEA2, key, 0x398a59b4, 0x15, Downlink, want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	back[len(back)-1] &^= 0x07
+	if !bytes.Equal(back, plain) {
+		t.Errorf("Encrypt of the ciphertext = %x, want %x", back, plain)
+	}
+}
@@ -0,0 +1,198 @@
+package nas
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/security"
+)
+
+// EPD5GMM is the extended protocol discriminator of the 5G mobility
+// management messages, which carry the security header.
+const EPD5GMM byte = 0x7E
+
+// HeaderType is the security header type of a 5GMM message
+// (TS 24.501 9.3.1).
+type HeaderType uint8
+
+// The security header types.
+const (
+	PlainNAS                         HeaderType = 0
+	IntegrityProtected               HeaderType = 1
+	IntegrityProtectedAndCiphered    HeaderType = 2
+	IntegrityProtectedWithNewContext HeaderType = 3
+	IntegrityProtectedAndCipheredNew HeaderType = 4
+)
+
+// headerSize is the size of the security protected header: EPD, security
+// header type, MAC and sequence number.
+const headerSize = 2 + MACSize + 1
+
+var (
+	// ErrMessage is returned for a message too short or not a 5GMM
+	// message.
+	ErrMessage = errors.New("nas: malformed security protected message")
+	// ErrIntegrity is returned when the MAC of a message does not verify.
+	ErrIntegrity = errors.New("nas: integrity check failed")
+	// ErrReplay is returned for a message of a NAS COUNT already received.
+	ErrReplay = errors.New("nas: replayed message")
+)
+
+// replayWindow is the number of NAS COUNTs below the highest one received
+// whose messages are still accepted, once each, having been overtaken.
+const replayWindow = 64
+
+// Context is a 5G NAS security context (TS 33.501 6.4): the selected
+// algorithms, the NAS keys derived from K_AMF and the uplink and downlink
+// NAS COUNTs. The UE and the AMF each hold one; the sender of a direction
+// increments its COUNT after every protected message.
+type Context struct {
+	EncAlg  CipheringAlg
+	IntAlg  IntegrityAlg
+	Bearer  Bearer
+	KnasEnc []byte
+	KnasInt []byte
+
+	mtx sync.Mutex
+	// counts are the next COUNT of each direction, past the highest one
+	// received for the direction received, seen the COUNTs of the
+	// replayWindow below it received, bit i for counts-1-i.
+	counts [2]uint32
+	seen   [2]uint64
+}
+
+// NewContext derives the NAS keys of encAlg and intAlg from kamf.
+func NewContext(kamf []byte, encAlg CipheringAlg, intAlg IntegrityAlg, bearer Bearer) *Context {
+	return &Context{
+		EncAlg:  encAlg,
+		IntAlg:  intAlg,
+		Bearer:  bearer,
+		KnasEnc: security.NASKey(kamf, security.NASEncAlg, byte(encAlg)),
+		KnasInt: security.NASKey(kamf, security.NASIntAlg, byte(intAlg)),
+	}
+}
+
+// Count returns the next NAS COUNT of dir.
+func (c *Context) Count(dir Direction) uint32 {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return c.counts[dir]
+}
+
+// Protect returns msg, a plain 5GMM message, integrity protected and, for
+// the ciphered header types, ciphered with the COUNT of dir, which it then
+// increments.
+func (c *Context) Protect(msg []byte, ht HeaderType, dir Direction) ([]byte, error) {
+	if ht == PlainNAS || ht > IntegrityProtectedAndCipheredNew {
+		return nil, ErrMessage
+	}
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	count := c.counts[dir]
+
+	payload := msg
+	if ht == IntegrityProtectedAndCiphered || ht == IntegrityProtectedAndCipheredNew {
+		var err error
+		if payload, err = Encrypt(c.EncAlg, c.KnasEnc, count, c.Bearer, dir, msg); err != nil {
+			return nil, err
+		}
+	}
+
+	res := make([]byte, headerSize+len(payload))
+	res[0] = EPD5GMM
+	res[1] = byte(ht)
+	res[headerSize-1] = byte(count)
+	copy(res[headerSize:], payload)
+	mac, err := MAC(c.IntAlg, c.KnasInt, count, c.Bearer, dir, res[headerSize-1:])
+	if err != nil {
+		return nil, err
+	}
+	copy(res[2:], mac[:])
+	c.counts[dir] = (count + 1) & 0xFFFFFF
+	return res, nil
+}
+
+// Unprotect checks the MAC of msg, a security protected 5GMM message received
+// in dir, and returns the plain message with its header type and its NAS
+// COUNT. The COUNT is estimated from the sequence number of msg, as that of
+// a message overtaken by at most replayWindow others or else the next ones,
+// and only recorded when the message verifies; a COUNT is accepted once.
+// Plain messages are returned as they are; accepting them is up to the
+// caller.
+func (c *Context) Unprotect(msg []byte, dir Direction) ([]byte, HeaderType, uint32, error) {
+	if len(msg) < 2 || msg[0] != EPD5GMM {
+		return nil, 0, 0, ErrMessage
+	}
+	ht := HeaderType(msg[1] & 0x0F)
+	if ht == PlainNAS {
+		return msg, ht, 0, nil
+	}
+	if ht > IntegrityProtectedAndCipheredNew || len(msg) < headerSize {
+		return nil, 0, 0, ErrMessage
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	next := c.counts[dir]
+	// the sequence number is the COUNT modulo 256, ahead of next by delta
+	delta := uint32(msg[headerSize-1] - byte(next))
+	count := (next + delta) & 0xFFFFFF
+	late := delta >= 0x100-replayWindow
+	if late {
+		behind := 0x100 - delta
+		if behind > next {
+			return nil, 0, 0, ErrReplay
+		}
+		count = next - behind
+		if c.seen[dir]&(1<<(behind-1)) != 0 {
+			return nil, 0, 0, ErrReplay
+		}
+	}
+
+	ok, err := VerifyMAC(c.IntAlg, c.KnasInt, count, c.Bearer, dir, msg[headerSize-1:], msg[2:2+MACSize])
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if !ok {
+		return nil, 0, 0, ErrIntegrity
+	}
+
+	payload := msg[headerSize:]
+	if ht == IntegrityProtectedAndCiphered || ht == IntegrityProtectedAndCipheredNew {
+		if payload, err = Encrypt(c.EncAlg, c.KnasEnc, count, c.Bearer, dir, payload); err != nil {
+			return nil, 0, 0, err
+		}
+	} else {
+		payload = append([]byte(nil), payload...)
+	}
+	if late {
+		c.seen[dir] |= 1 << (next - 1 - count)
+	} else {
+		if shift := delta + 1; shift < 64 {
+			c.seen[dir] = c.seen[dir]<<shift | 1
+		} else {
+			c.seen[dir] = 1
+		}
+		c.counts[dir] = (count + 1) & 0xFFFFFF
+	}
+	return payload, ht, count, nil
+}
+
+// Plain returns the plain 5GMM message of msg without checking its MAC:
+// msg itself when it is not security protected, the message it carries
+// when only integrity protected, as the initial NAS messages are. It
+// returns false for a ciphered or malformed message.
+func Plain(msg []byte) ([]byte, bool) {
+	if len(msg) < 2 || msg[0] != EPD5GMM {
+		return msg, true
+	}
+	switch HeaderType(msg[1] & 0x0F) {
+	case PlainNAS:
+		return msg, true
+	case IntegrityProtected, IntegrityProtectedWithNewContext:
+		if len(msg) >= headerSize {
+			return msg[headerSize:], true
+		}
+	}
+	return nil, false
+}
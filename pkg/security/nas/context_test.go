@@ -0,0 +1,94 @@
+package nas
+
+import (
+	"bytes"
+	"testing"
+)
+
+// pair returns the contexts of a UE and its AMF, of the same K_AMF.
+func pair() (ue, amf *Context) {
+	kamf := bytes.Repeat([]byte{0x5a}, 32)
+	return NewContext(kamf, NEA2, NIA2, AccessNon3GPP), NewContext(kamf, NEA2, NIA2, AccessNon3GPP)
+}
+
+func TestProtectUnprotect(t *testing.T) {
+	ue, amf := pair()
+	msg := []byte(`RegistrationComplete`)
+	for i := uint32(0); i < 300; i++ {
+		protected, err := ue.Protect(msg, IntegrityProtectedAndCiphered, Uplink)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bytes.Contains(protected, msg) {
+			t.Fatalf("COUNT %d: message not ciphered: %q", i, protected)
+		}
+		plain, ht, count, err := amf.Unprotect(protected, Uplink)
+		if err != nil {
+			t.Fatalf("COUNT %d: %v", i, err)
+		}
+		if !bytes.Equal(plain, msg) || ht != IntegrityProtectedAndCiphered || count != i {
+			t.Fatalf("COUNT %d: Unprotect = %q, %d, %d", i, plain, ht, count)
+		}
+	}
+	if c := amf.Count(Uplink); c != 300 {
+		t.Errorf("next uplink COUNT %d, want 300", c)
+	}
+}
+
+func TestUnprotectTampered(t *testing.T) {
+	ue, amf := pair()
+	protected, err := ue.Protect([]byte(`ServiceRequest {"guti":"x"}`), IntegrityProtected, Uplink)
+	if err != nil {
+		t.Fatal(err)
+	}
+	protected[len(protected)-2] ^= 0x01
+	if _, _, _, err := amf.Unprotect(protected, Uplink); err != ErrIntegrity {
+		t.Errorf("Unprotect of a tampered message: %v, want %v", err, ErrIntegrity)
+	}
+	if _, _, _, err := amf.Unprotect(protected, Downlink); err != ErrIntegrity {
+		t.Errorf("Unprotect in the other direction: %v, want %v", err, ErrIntegrity)
+	}
+}
+
+// The messages overtaken by others are accepted once, the replayed ones
+// refused.
+func TestUnprotectOvertaken(t *testing.T) {
+	ue, amf := pair()
+	var sent [][]byte
+	for i := 0; i < 3; i++ {
+		protected, err := amf.Protect([]byte("DLNASTransport"), IntegrityProtectedAndCiphered, Downlink)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sent = append(sent, protected)
+	}
+	for _, i := range []int{2, 0, 1} {
+		if _, _, count, err := ue.Unprotect(sent[i], Downlink); err != nil || count != uint32(i) {
+			t.Fatalf("message %d: COUNT %d, %v", i, count, err)
+		}
+	}
+	for i := range sent {
+		if _, _, _, err := ue.Unprotect(sent[i], Downlink); err != ErrReplay {
+			t.Errorf("replay of message %d: %v, want %v", i, err, ErrReplay)
+		}
+	}
+	if c := ue.Count(Downlink); c != 3 {
+		t.Errorf("next downlink COUNT %d, want 3", c)
+	}
+}
+
+func TestPlain(t *testing.T) {
+	ue, _ := pair()
+	msg := []byte(`ServiceRequest {"guti":"x"}`)
+	if plain, ok := Plain(msg); !ok || !bytes.Equal(plain, msg) {
+		t.Errorf("Plain of a plain message = %q, %v", plain, ok)
+	}
+	protected, _ := ue.Protect(msg, IntegrityProtected, Uplink)
+	if plain, ok := Plain(protected); !ok || !bytes.Equal(plain, msg) {
+		t.Errorf("Plain of an integrity protected message = %q, %v", plain, ok)
+	}
+	ciphered, _ := ue.Protect(msg, IntegrityProtectedAndCiphered, Uplink)
+	if _, ok := Plain(ciphered); ok {
+		t.Error("Plain of a ciphered message = true, want false")
+	}
+}
@@ -0,0 +1,297 @@
+// Package shadow mirrors a share of the calls of a client to a shadow
+// backend, such as a canary build of the service called, to validate a new
+// version with production-shaped traffic before it takes any. The mirrored
+// call is made in the background once the call has been answered: the
+// response of the shadow is never returned, and its latency does not add to
+// that of the call. The two responses are compared, and the divergences
+// counted, logged and kept for the stats of the Mirror.
+//
+// The shadow applies the calls that change state too: it needs a state of its
+// own, a copy of the production one, rather than the production one itself.
+// The calls mirrored at once are capped, the others being dropped, so that a
+// slow shadow neither piles goroutines up nor holds the client back.
+package shadow
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/go-kit/kit/metrics"
+	"github.com/go-kit/kit/metrics/discard"
+	"google.golang.org/grpc/status"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/meta"
+)
+
+// Default values of the options.
+const (
+	DefaultRatio       = 0.01
+	DefaultTimeout     = 5 * time.Second
+	DefaultMaxInFlight = 64
+	DefaultDivergences = 20
+)
+
+// The outcomes counted by the counter of the Mirror.
+const (
+	outcomeMatch    = "match"
+	outcomeDiverged = "diverged"
+	outcomeDropped  = "dropped"
+)
+
+// Option sets an optional parameter of a Mirror.
+type Option func(*Mirror)
+
+// Ratio sets the share of the calls of every method mirrored, in [0, 1].
+// Zero turns the mirroring off.
+func Ratio(r float64) Option {
+	return func(m *Mirror) { m.ratio = r }
+}
+
+// Timeout bounds the mirrored calls, which outlive the calls they mirror.
+func Timeout(d time.Duration) Option {
+	return func(m *Mirror) { m.timeout = d }
+}
+
+// MaxInFlight caps the mirrored calls in flight, the calls over it not being
+// mirrored.
+func MaxInFlight(n int) Option {
+	return func(m *Mirror) { m.maxInFlight = n }
+}
+
+// Divergences sets the number of the last divergences kept for the stats.
+func Divergences(n int) Option {
+	return func(m *Mirror) { m.maxDivergences = n }
+}
+
+// Counter sets the counter of the mirrored calls, labelled with the method
+// and the outcome: match, diverged or dropped.
+func Counter(c metrics.Counter) Option {
+	return func(m *Mirror) { m.counter = c }
+}
+
+// Logger sets the logger of the divergences, logged at debug level.
+func Logger(logger log.Logger) Option {
+	return func(m *Mirror) { m.logger = logger }
+}
+
+// Equal tells whether the response of the shadow, shadow, matches that of
+// the backend, primary, for a call answered without error by both.
+type Equal func(primary, shadow interface{}) bool
+
+// Divergence is a mirrored call answered otherwise by the shadow.
+type Divergence struct {
+	Method string `json:"method"`
+	// Primary and Shadow are the gRPC status codes of the calls, Reason
+	// what differed: the status or the response.
+	Primary  string    `json:"primary"`
+	Shadow   string    `json:"shadow"`
+	Reason   string    `json:"reason"`
+	Detected time.Time `json:"detected"`
+}
+
+// counts are the mirrored calls of a method.
+type counts struct {
+	calls, mirrored, matched, diverged, dropped int64
+	// the time the calls mirrored took, on either side
+	primary, shadow time.Duration
+}
+
+// Mirror mirrors the calls of the endpoints it is the middleware of. It is
+// safe for concurrent use.
+type Mirror struct {
+	ratio          float64
+	timeout        time.Duration
+	maxInFlight    int
+	maxDivergences int
+	counter        metrics.Counter
+	logger         log.Logger
+
+	mtx         sync.Mutex
+	methods     map[string]*counts
+	budget      float64
+	inFlight    int
+	divergences []Divergence
+}
+
+// New returns a Mirror.
+func New(options ...Option) *Mirror {
+	m := &Mirror{
+		ratio:          DefaultRatio,
+		timeout:        DefaultTimeout,
+		maxInFlight:    DefaultMaxInFlight,
+		maxDivergences: DefaultDivergences,
+		counter:        discard.NewCounter(),
+		logger:         log.NewNopLogger(),
+		methods:        map[string]*counts{},
+	}
+	for _, option := range options {
+		option(m)
+	}
+	return m
+}
+
+// Middleware returns a middleware mirroring the calls of method to shadow,
+// the endpoint of method of the shadow, and comparing the responses with
+// equal, reflect.DeepEqual when nil. The calls that fail are compared by
+// their status code only.
+func (m *Mirror) Middleware(method string, shadow endpoint.Endpoint, equal Equal) endpoint.Middleware {
+	if equal == nil {
+		equal = func(primary, shadow interface{}) bool { return reflect.DeepEqual(primary, shadow) }
+	}
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			start := time.Now()
+			response, err := next(ctx, request)
+			took := time.Since(start)
+			if m.plan(method) {
+				go m.mirror(detached{ctx}, method, shadow, equal, request, response, err, took)
+			}
+			return response, err
+		}
+	}
+}
+
+// plan tells whether a call of method is to be mirrored. Every call adds
+// ratio to the budget of the mirrored calls, so that the share mirrored is
+// the ratio whatever the rate of the calls.
+func (m *Mirror) plan(method string) bool {
+	if m.ratio <= 0 {
+		return false
+	}
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	c := m.countsLocked(method)
+	c.calls++
+	m.budget += m.ratio
+	if m.budget < 1 {
+		return false
+	}
+	m.budget--
+	if m.inFlight >= m.maxInFlight {
+		c.dropped++
+		m.counter.With("method", method, "outcome", outcomeDropped).Add(1)
+		return false
+	}
+	m.inFlight++
+	return true
+}
+
+// mirror makes the call of request to shadow, and compares its response to
+// response and err, the answer of the call, which took took.
+func (m *Mirror) mirror(ctx context.Context, method string, shadow endpoint.Endpoint, equal Equal, request, response interface{}, err error, took time.Duration) {
+	ctx, cancel := context.WithTimeout(ctx, m.timeout)
+	defer cancel()
+	start := time.Now()
+	shadowResponse, shadowErr := shadow(ctx, request)
+	shadowTook := time.Since(start)
+
+	d := Divergence{Method: method, Primary: status.Code(err).String(), Shadow: status.Code(shadowErr).String()}
+	switch {
+	case d.Primary != d.Shadow:
+		d.Reason = "status"
+	case err == nil && !equal(response, shadowResponse):
+		d.Reason = "response"
+	}
+
+	m.mtx.Lock()
+	m.inFlight--
+	c := m.countsLocked(method)
+	c.mirrored++
+	c.primary += took
+	c.shadow += shadowTook
+	outcome := outcomeMatch
+	if d.Reason != "" {
+		outcome = outcomeDiverged
+		c.diverged++
+		d.Detected = time.Now().UTC()
+		m.divergences = append(m.divergences, d)
+		if len(m.divergences) > m.maxDivergences {
+			m.divergences = m.divergences[len(m.divergences)-m.maxDivergences:]
+		}
+	} else {
+		c.matched++
+	}
+	m.mtx.Unlock()
+
+	m.counter.With("method", method, "outcome", outcome).Add(1)
+	if d.Reason != "" {
+		keyvals := []interface{}{"shadow", "diverged", "method", method, "reason", d.Reason, "primary", d.Primary, "shadow_status", d.Shadow}
+		if r, ok := request.(meta.UERequest); ok {
+			keyvals = append(keyvals, "ue_id", r.UEID())
+		}
+		if shadowErr != nil {
+			keyvals = append(keyvals, "shadow_err", shadowErr)
+		}
+		level.Debug(m.logger).Log(keyvals...)
+	}
+}
+
+func (m *Mirror) countsLocked(method string) *counts {
+	c, ok := m.methods[method]
+	if !ok {
+		c = &counts{}
+		m.methods[method] = c
+	}
+	return c
+}
+
+// MethodStats are the mirrored calls of a method. The latencies are the
+// mean, in milliseconds, of the calls mirrored on either side.
+type MethodStats struct {
+	Method        string  `json:"method"`
+	Calls         int64   `json:"calls"`
+	Mirrored      int64   `json:"mirrored"`
+	Matched       int64   `json:"matched"`
+	Diverged      int64   `json:"diverged"`
+	Dropped       int64   `json:"dropped"`
+	PrimaryMillis float64 `json:"primary_latency_ms"`
+	ShadowMillis  float64 `json:"shadow_latency_ms"`
+}
+
+// Stats are the mirrored calls of a Mirror, by method, and its last
+// divergences, the latest last.
+type Stats struct {
+	Ratio       float64       `json:"ratio"`
+	InFlight    int           `json:"in_flight"`
+	Methods     []MethodStats `json:"methods"`
+	Divergences []Divergence  `json:"recent_divergences"`
+}
+
+// Stats returns the stats of m.
+func (m *Mirror) Stats() Stats {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	s := Stats{
+		Ratio:       m.ratio,
+		InFlight:    m.inFlight,
+		Methods:     make([]MethodStats, 0, len(m.methods)),
+		Divergences: append([]Divergence{}, m.divergences...),
+	}
+	for method, c := range m.methods {
+		ms := MethodStats{Method: method, Calls: c.calls, Mirrored: c.mirrored, Matched: c.matched, Diverged: c.diverged, Dropped: c.dropped}
+		if c.mirrored > 0 {
+			ms.PrimaryMillis = c.primary.Seconds() * 1000 / float64(c.mirrored)
+			ms.ShadowMillis = c.shadow.Seconds() * 1000 / float64(c.mirrored)
+		}
+		s.Methods = append(s.Methods, ms)
+	}
+	sort.Slice(s.Methods, func(i, j int) bool { return s.Methods[i].Method < s.Methods[j].Method })
+	return s
+}
+
+// detached carries the values of a call's context, its trace and procedure
+// metadata, but neither its deadline nor its cancellation, for the mirrored
+// calls that outlive the call.
+type detached struct {
+	context.Context
+}
+
+func (detached) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (detached) Done() <-chan struct{}       { return nil }
+func (detached) Err() error                  { return nil }
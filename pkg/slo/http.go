@@ -0,0 +1,49 @@
+package slo
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+type errorWrapper struct {
+	Error string `json:"error"`
+}
+
+// Handler serves the state of the error budgets of the methods of t, and of
+// the other NFs of its process, to GET requests: all of them, or those of
+// the ?nf= and ?method= query parameters, the method in any case.
+func Handler(t *Tracker) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, nil)
+			return
+		}
+		nf, method := req.URL.Query().Get("nf"), req.URL.Query().Get("method")
+		res := []Status{}
+		for _, s := range t.Statuses() {
+			if (nf == "" || s.NF == nf) && (method == "" || strings.EqualFold(s.Method, method)) {
+				res = append(res, s)
+			}
+		}
+		if method != "" && len(res) == 0 {
+			writeError(w, http.StatusNotFound, errors.New("slo: no objective for method "+method))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		enc.Encode(res)
+	})
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	msg := http.StatusText(code)
+	if err != nil {
+		msg = err.Error()
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(errorWrapper{Error: msg})
+}
@@ -0,0 +1,429 @@
+// Package slo tracks the error budgets of the methods of an NF. Each method
+// has an objective: the ratio of its responses that are to be good, such as
+// 99.9%, a good response being one returned within the latency of the
+// objective, either successful or failing with a code the objective
+// accepts, one blaming the caller such as NotFound. The responses left
+// count against the error budget of the method, the remaining 0.1%.
+//
+// A Tracker counts the good and the bad responses over rolling windows,
+// and derives from them the burn rate of each window, the rate the budget
+// is spent at, 1 when it would be spent exactly over the window, and the
+// budget remaining over the longest window. Export serves them with the
+// load of the NF (see package autoscale):
+//
+//	sa5g_slo_burn_rate{nf="udm",method="generateAuthData",window="1h"} 0.4
+//	sa5g_slo_error_budget_remaining{nf="udm",method="generateAuthData"} 0.6
+//
+// and Handler on the admin port, on Path.
+package slo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/endpoint"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/autoscale"
+)
+
+// Path is the path Handler is served on.
+const Path = "/admin/slo"
+
+// Default is the key of the objective of the methods without one of their
+// own.
+const Default = "*"
+
+// DefaultWindows are the windows of the burn rates by default: the short
+// and long windows of the alerts on a fast burn, and the long one of those
+// on a slow burn.
+var DefaultWindows = []time.Duration{5 * time.Minute, time.Hour, 6 * time.Hour}
+
+// DefaultCodes are the codes an objective accepts when it names none: those
+// of the errors of the callers, and of the calls they cancelled.
+var DefaultCodes = []codes.Code{
+	codes.Canceled,
+	codes.InvalidArgument,
+	codes.NotFound,
+	codes.AlreadyExists,
+	codes.PermissionDenied,
+	codes.FailedPrecondition,
+	codes.OutOfRange,
+	codes.Unauthenticated,
+}
+
+// slots is the number of slots of a window, which rolls by a slot at a
+// time.
+const slots = 60
+
+// Objective is the service level objective of a method.
+type Objective struct {
+	// Latency is the longest a good response takes.
+	Latency time.Duration
+	// Target is the ratio of the responses that are to be good, such as
+	// 0.999, below 1 to leave a budget.
+	Target float64
+	// Codes are the codes of the errors that are good responses.
+	Codes []codes.Code
+}
+
+// good reports whether the response of err, returned after d, is good.
+func (o Objective) good(d time.Duration, err error) bool {
+	if d > o.Latency {
+		return false
+	}
+	if err == nil {
+		return true
+	}
+	code := status.Code(err)
+	for _, c := range o.Codes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// Tracker tracks the error budgets of the methods of an NF.
+type Tracker struct {
+	nf  string
+	reg *registry
+}
+
+// registry keeps the methods of the Trackers of a process.
+type registry struct {
+	objectives map[string]Objective
+	windows    []time.Duration
+	now        func() time.Time
+
+	mtx     sync.Mutex
+	methods map[key]*method
+}
+
+// key identifies a method of an NF.
+type key struct {
+	nf, method string
+}
+
+// Option sets an optional parameter of a Tracker.
+type Option func(*registry)
+
+// Windows sets the windows of the burn rates, DefaultWindows by default.
+func Windows(ds ...time.Duration) Option {
+	return func(r *registry) { r.windows = ds }
+}
+
+// New returns the tracker of the NF nf, holding its methods to objectives,
+// by method name in lower case, those without one to that of Default if
+// any.
+func New(nf string, objectives map[string]Objective, options ...Option) *Tracker {
+	r := &registry{
+		objectives: objectives,
+		windows:    DefaultWindows,
+		now:        time.Now,
+		methods:    map[key]*method{},
+	}
+	for _, option := range options {
+		option(r)
+	}
+	r.windows = append([]time.Duration(nil), r.windows...)
+	sort.Slice(r.windows, func(i, j int) bool { return r.windows[i] < r.windows[j] })
+	return &Tracker{nf: nf, reg: r}
+}
+
+// NF returns the tracker of the NF nf, reported along with t, for a process
+// running several NFs.
+func (t *Tracker) NF(nf string) *Tracker {
+	return &Tracker{nf: nf, reg: t.reg}
+}
+
+// Middleware classifies the responses of the endpoint of the method against
+// its objective in t. A method without one is left alone.
+func Middleware(t *Tracker, method string) endpoint.Middleware {
+	m := t.method(method)
+	if m == nil {
+		return func(next endpoint.Endpoint) endpoint.Endpoint { return next }
+	}
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			begin := time.Now()
+			response, err := next(ctx, request)
+			t.reg.count(m, m.objective.good(time.Since(begin), err))
+			return response, err
+		}
+	}
+}
+
+// method returns the method name of the NF, registered with its objective,
+// nil when it has none.
+func (t *Tracker) method(name string) *method {
+	r := t.reg
+	o, ok := r.objectives[strings.ToLower(name)]
+	if !ok {
+		if o, ok = r.objectives[Default]; !ok {
+			return nil
+		}
+	}
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	k := key{t.nf, name}
+	m, ok := r.methods[k]
+	if !ok {
+		m = &method{name: name, objective: o}
+		for _, d := range r.windows {
+			m.windows = append(m.windows, &window{size: d, slots: make([]counts, slots)})
+		}
+		r.methods[k] = m
+	}
+	return m
+}
+
+func (r *registry) count(m *method, good bool) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	m.count(r.now(), good)
+}
+
+// method is the tally of the responses of a method.
+type method struct {
+	name      string
+	objective Objective
+	total     counts
+	windows   []*window
+}
+
+// counts counts good and bad responses.
+type counts struct {
+	good, bad int64
+}
+
+func (c *counts) add(good bool) {
+	if good {
+		c.good++
+	} else {
+		c.bad++
+	}
+}
+
+// burnRate returns the rate the responses of c spend the budget of o at.
+func (c counts) burnRate(o Objective) float64 {
+	n := c.good + c.bad
+	if n == 0 {
+		return 0
+	}
+	return float64(c.bad) / float64(n) / (1 - o.Target)
+}
+
+func (m *method) count(now time.Time, good bool) {
+	m.total.add(good)
+	for _, w := range m.windows {
+		w.count(now, good)
+	}
+}
+
+// window counts the responses of its size by slot, by slot since the epoch
+// modulo their number; last is the last slot counted.
+type window struct {
+	size  time.Duration
+	slots []counts
+	last  int64
+}
+
+// slot returns the slot of now.
+func (w *window) slot(now time.Time) int64 {
+	return now.UnixNano() / int64(w.size/time.Duration(len(w.slots)))
+}
+
+func (w *window) count(now time.Time, good bool) {
+	s := w.slot(now)
+	w.advance(s)
+	w.slots[s%int64(len(w.slots))].add(good)
+}
+
+// sum returns the counts of the window ending at now.
+func (w *window) sum(now time.Time) counts {
+	w.advance(w.slot(now))
+	var sum counts
+	for _, c := range w.slots {
+		sum.good += c.good
+		sum.bad += c.bad
+	}
+	return sum
+}
+
+// advance clears the slots from the last one to s.
+func (w *window) advance(s int64) {
+	size := int64(len(w.slots))
+	for i := w.last + 1; i <= s && i <= w.last+size; i++ {
+		w.slots[i%size] = counts{}
+	}
+	if s > w.last {
+		w.last = s
+	}
+}
+
+// Status is the state of the error budget of a method.
+type Status struct {
+	NF     string `json:"nf"`
+	Method string `json:"method"`
+	// Objective is the target of its objective, as a percentage, Latency
+	// its latency.
+	Objective float64  `json:"objective"`
+	Latency   string   `json:"latency"`
+	Codes     []string `json:"codes"`
+	// Good and Bad count its responses since the start.
+	Good    int64          `json:"good"`
+	Bad     int64          `json:"bad"`
+	Windows []WindowStatus `json:"windows"`
+	// BudgetRemaining is the ratio of the budget of the longest window that
+	// is left, below 0 when overspent.
+	BudgetRemaining float64 `json:"error_budget_remaining"`
+}
+
+// WindowStatus is the state of the error budget of a method over a window.
+type WindowStatus struct {
+	Window   string  `json:"window"`
+	Good     int64   `json:"good"`
+	Bad      int64   `json:"bad"`
+	BurnRate float64 `json:"burn_rate"`
+}
+
+// Statuses returns the state of the error budgets of the methods of every
+// NF of t, sorted by NF and method.
+func (t *Tracker) Statuses() []Status {
+	r := t.reg
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	now := r.now()
+	res := make([]Status, 0, len(r.methods))
+	for k, m := range r.methods {
+		s := Status{
+			NF:        k.nf,
+			Method:    m.name,
+			Objective: m.objective.Target * 100,
+			Latency:   m.objective.Latency.String(),
+			Good:      m.total.good,
+			Bad:       m.total.bad,
+		}
+		for _, c := range m.objective.Codes {
+			s.Codes = append(s.Codes, c.String())
+		}
+		for _, w := range m.windows {
+			sum := w.sum(now)
+			s.Windows = append(s.Windows, WindowStatus{Window: Label(w.size), Good: sum.good, Bad: sum.bad, BurnRate: sum.burnRate(m.objective)})
+		}
+		s.BudgetRemaining = 1
+		if n := len(s.Windows); n != 0 {
+			s.BudgetRemaining -= s.Windows[n-1].BurnRate
+		}
+		res = append(res, s)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].NF != res[j].NF {
+			return res[i].NF < res[j].NF
+		}
+		return res[i].Method < res[j].Method
+	})
+	return res
+}
+
+// Export declares the burn rates and the remaining budgets of the methods
+// of the NF of t as gauges of l.
+func (t *Tracker) Export(l *autoscale.Load) {
+	for i, d := range t.reg.windows {
+		i := i
+		l.Gauges(autoscale.BurnRate, "method", func() map[string]float64 {
+			return t.sample(func(s Status) float64 { return s.Windows[i].BurnRate })
+		}, "window", Label(d))
+	}
+	l.Gauges(autoscale.ErrorBudget, "method", func() map[string]float64 {
+		return t.sample(func(s Status) float64 { return s.BudgetRemaining })
+	})
+}
+
+// sample returns value of the status of each method of the NF of t.
+func (t *Tracker) sample(value func(Status) float64) map[string]float64 {
+	values := make(map[string]float64)
+	for _, s := range t.Statuses() {
+		if s.NF == t.nf {
+			values[s.Method] = value(s)
+		}
+	}
+	return values
+}
+
+// Label returns the label of the window d, a Prometheus duration in its
+// largest whole unit, such as 5m or 6h.
+func Label(d time.Duration) string {
+	for _, u := range []struct {
+		d    time.Duration
+		unit string
+	}{{time.Hour, "h"}, {time.Minute, "m"}, {time.Second, "s"}} {
+		if d%u.d == 0 {
+			return strconv.FormatInt(int64(d/u.d), 10) + u.unit
+		}
+	}
+	return strconv.FormatInt(int64(d/time.Millisecond), 10) + "ms"
+}
+
+// codeNames maps the names of the codes to them.
+var codeNames = func() map[string]codes.Code {
+	m := make(map[string]codes.Code)
+	for c := codes.OK; c <= codes.Unauthenticated; c++ {
+		m[strings.ToLower(c.String())] = c
+	}
+	return m
+}()
+
+// Parse parses the objectives of s, a comma-separated list of
+// method=latency/target[/codes], the target being a percentage and the
+// codes accepted, DefaultCodes by default, being separated by +, such as
+// "generateAuthData=100ms/99.9,*=500ms/99/NotFound+InvalidArgument". The
+// method Default sets the objective of the others. The objectives are keyed
+// by method name in lower case.
+func Parse(s string) (map[string]Objective, error) {
+	objectives := map[string]Objective{}
+	for _, kv := range strings.Split(s, ",") {
+		kv = strings.TrimSpace(kv)
+		if kv == "" {
+			continue
+		}
+		i := strings.Index(kv, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("slo: %q is not method=latency/target[/codes]", kv)
+		}
+		fields := strings.Split(kv[i+1:], "/")
+		if len(fields) < 2 || len(fields) > 3 {
+			return nil, fmt.Errorf("slo: %q is not method=latency/target[/codes]", kv)
+		}
+		var o Objective
+		var err error
+		if o.Latency, err = time.ParseDuration(strings.TrimSpace(fields[0])); err != nil || o.Latency <= 0 {
+			return nil, fmt.Errorf("slo: latency of %q is not a positive duration", kv)
+		}
+		target, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(fields[1]), "%"), 64)
+		if err != nil || target <= 0 || target >= 100 {
+			return nil, fmt.Errorf("slo: target of %q is not a percentage below 100", kv)
+		}
+		o.Target = target / 100
+		o.Codes = DefaultCodes
+		if len(fields) == 3 {
+			o.Codes = nil
+			for _, name := range strings.Split(fields[2], "+") {
+				c, ok := codeNames[strings.ToLower(strings.TrimSpace(name))]
+				if !ok {
+					return nil, fmt.Errorf("slo: unknown code %q in %q", name, kv)
+				}
+				o.Codes = append(o.Codes, c)
+			}
+		}
+		objectives[strings.ToLower(strings.TrimSpace(kv[:i]))] = o
+	}
+	return objectives, nil
+}
@@ -0,0 +1,146 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/clock"
+)
+
+// sweepEvery is the number of writes between two sweeps of expired keys.
+const sweepEvery = 1024
+
+type entry struct {
+	value   []byte
+	expires time.Time
+}
+
+func (e entry) expired(now time.Time) bool {
+	return !e.expires.IsZero() && !now.Before(e.expires)
+}
+
+type memoryStore struct {
+	mtx    sync.RWMutex
+	data   map[string]entry
+	writes int
+	clock  clock.Clock
+}
+
+// MemoryOption sets an optional parameter of a memory Store.
+type MemoryOption func(*memoryStore)
+
+// MemoryClock sets the clock the TTLs run on, clock.Real by default.
+func MemoryClock(c clock.Clock) MemoryOption {
+	return func(s *memoryStore) { s.clock = c }
+}
+
+// NewMemory returns a Store keeping its data in the process memory. It is
+// what a single replica needs; the data does not survive a restart.
+func NewMemory(options ...MemoryOption) Store {
+	s := &memoryStore{data: map[string]entry{}, clock: clock.Real}
+	for _, option := range options {
+		option(s)
+	}
+	return s
+}
+
+func (s *memoryStore) Get(_ context.Context, key string) ([]byte, error) {
+	s.mtx.RLock()
+	e, ok := s.data[key]
+	s.mtx.RUnlock()
+	if !ok || e.expired(s.clock.Now()) {
+		return nil, ErrNotFound
+	}
+	return copyBytes(e.value), nil
+}
+
+func (s *memoryStore) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.set(key, value, ttl, s.clock.Now())
+	return nil
+}
+
+func (s *memoryStore) SetNX(_ context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	now := s.clock.Now()
+	if e, ok := s.data[key]; ok && !e.expired(now) {
+		return false, nil
+	}
+	s.set(key, value, ttl, now)
+	return true, nil
+}
+
+func (s *memoryStore) CompareAndSwap(_ context.Context, key string, old, value []byte, ttl time.Duration) (bool, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	now := s.clock.Now()
+	if e, ok := s.data[key]; !ok || e.expired(now) || !bytes.Equal(e.value, old) {
+		return false, nil
+	}
+	s.set(key, value, ttl, now)
+	return true, nil
+}
+
+func (s *memoryStore) Delete(_ context.Context, key string) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	delete(s.data, key)
+	return nil
+}
+
+func (s *memoryStore) CompareAndDelete(_ context.Context, key string, old []byte) (bool, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if e, ok := s.data[key]; !ok || e.expired(s.clock.Now()) || !bytes.Equal(e.value, old) {
+		return false, nil
+	}
+	delete(s.data, key)
+	return true, nil
+}
+
+func (s *memoryStore) Keys(_ context.Context, prefix string) ([]string, error) {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	now := s.clock.Now()
+	var keys []string
+	for k, e := range s.data {
+		if strings.HasPrefix(k, prefix) && !e.expired(now) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// set must be called with the lock held.
+func (s *memoryStore) set(key string, value []byte, ttl time.Duration, now time.Time) {
+	e := entry{value: copyBytes(value)}
+	if ttl > 0 {
+		e.expires = now.Add(ttl)
+	}
+	s.data[key] = e
+
+	s.writes++
+	if s.writes%sweepEvery == 0 {
+		for k, e := range s.data {
+			if e.expired(now) {
+				delete(s.data, k)
+			}
+		}
+	}
+}
+
+func copyBytes(b []byte) []byte {
+	if b == nil {
+		return nil
+	}
+	c := make([]byte, len(b))
+	copy(c, b)
+	return c
+}
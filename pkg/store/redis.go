@@ -0,0 +1,97 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+// swapScript sets KEYS[1] to ARGV[2] if it holds ARGV[1], with a time to
+// live of ARGV[3] milliseconds, none when zero. It returns 1 when it did.
+var swapScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) ~= ARGV[1] then
+	return 0
+end
+if tonumber(ARGV[3]) > 0 then
+	redis.call('SET', KEYS[1], ARGV[2], 'PX', ARGV[3])
+else
+	redis.call('SET', KEYS[1], ARGV[2])
+end
+return 1
+`)
+
+// deleteScript deletes KEYS[1] if it holds ARGV[1]. It returns 1 when it
+// did.
+var deleteScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) ~= ARGV[1] then
+	return 0
+end
+redis.call('DEL', KEYS[1])
+return 1
+`)
+
+type redisStore struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedis returns a Store backed by Redis, shared by every replica using
+// the same server. Keys are prefixed with prefix so that several services
+// can use one database.
+func NewRedis(client *redis.Client, prefix string) Store {
+	return &redisStore{client: client, prefix: prefix}
+}
+
+func (s *redisStore) Get(ctx context.Context, key string) ([]byte, error) {
+	v, err := s.client.WithContext(ctx).Get(s.prefix + key).Bytes()
+	if err == redis.Nil {
+		return nil, ErrNotFound
+	}
+	return v, err
+}
+
+func (s *redisStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return s.client.WithContext(ctx).Set(s.prefix+key, value, ttl).Err()
+}
+
+func (s *redisStore) SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	return s.client.WithContext(ctx).SetNX(s.prefix+key, value, ttl).Result()
+}
+
+func (s *redisStore) CompareAndSwap(ctx context.Context, key string, old, value []byte, ttl time.Duration) (bool, error) {
+	n, err := swapScript.Run(s.client.WithContext(ctx), []string{s.prefix + key}, old, value, int64(ttl/time.Millisecond)).Int()
+	return n == 1, err
+}
+
+func (s *redisStore) Delete(ctx context.Context, key string) error {
+	return s.client.WithContext(ctx).Del(s.prefix + key).Err()
+}
+
+func (s *redisStore) CompareAndDelete(ctx context.Context, key string, old []byte) (bool, error) {
+	n, err := deleteScript.Run(s.client.WithContext(ctx), []string{s.prefix + key}, old).Int()
+	return n == 1, err
+}
+
+func (s *redisStore) Keys(ctx context.Context, prefix string) ([]string, error) {
+	var (
+		keys   []string
+		cursor uint64
+	)
+	client := s.client.WithContext(ctx)
+	for {
+		page, next, err := client.Scan(cursor, s.prefix+prefix+"*", 1000).Result()
+		if err != nil {
+			return nil, err
+		}
+		for _, k := range page {
+			keys = append(keys, k[len(s.prefix):])
+		}
+		if cursor = next; cursor == 0 {
+			break
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
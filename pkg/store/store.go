@@ -0,0 +1,39 @@
+// Package store holds the short-lived state the services and their
+// middlewares share: idempotency records, counters and the like. Values are
+// opaque bytes with an optional time to live.
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrNotFound is returned when a key does not exist or has expired.
+var ErrNotFound = errors.New("store: key not found")
+
+//go:generate mockgen -destination=../testutil/mocks/store.go -package=mocks github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/store Store
+
+// Store is a key/value store with per-key expiry. A zero ttl means the key
+// never expires.
+type Store interface {
+	// Get returns the value stored under key.
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Set stores value under key, replacing any previous value.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// SetNX stores value under key only if the key does not exist yet, and
+	// reports whether it did.
+	SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error)
+	// CompareAndSwap stores value under key only if the key holds old, and
+	// reports whether it did.
+	CompareAndSwap(ctx context.Context, key string, old, value []byte, ttl time.Duration) (bool, error)
+	// Delete removes key. Deleting a missing key is not an error.
+	Delete(ctx context.Context, key string) error
+	// CompareAndDelete removes key only if it holds old, and reports
+	// whether it did.
+	CompareAndDelete(ctx context.Context, key string, old []byte) (bool, error)
+	// Keys returns the keys starting with prefix, sorted. It walks the
+	// whole key space and is meant for administration, not for the hot
+	// path.
+	Keys(ctx context.Context, prefix string) ([]string, error)
+}
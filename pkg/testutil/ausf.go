@@ -0,0 +1,107 @@
+package testutil
+
+import (
+	"net/http/httptest"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	stdopentracing "github.com/opentracing/opentracing-go"
+	"github.com/openzipkin/zipkin-go"
+
+	pb "github.com/miki-tnt/sa5g-go-usvc-k8s/pb/ausf"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/ausf/endpoints"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/ausf/service"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/ausf/transports"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/grpcserver"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/kitx/chain"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/store"
+	udmservice "github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/udm/service"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/udm/subscriber"
+)
+
+// AuthTTL is the time the AUSF of the harness waits for the confirmation
+// of an authentication.
+const AuthTTL = 5 * time.Minute
+
+// AUSF is an AUSF run in process over an in-memory store, serving every
+// PLMN.
+type AUSF struct {
+	server
+	// Store keeps the authentications awaiting their confirmation.
+	Store store.Store
+	// Service is the AUSF itself, Endpoints its endpoints.
+	Service   service.AusfService
+	Endpoints endpoints.Endpoints
+	// Client calls it over gRPC.
+	Client service.AusfService
+	// Inproc calls it through the in-process transport, with the codecs
+	// of its gRPC API.
+	Inproc service.AusfService
+	// URL is the base URL of its HTTP API.
+	URL string
+}
+
+// NewAUSF starts an AUSF authenticating the subscribers of udm, a UDM
+// client, a mock or a fake.
+func NewAUSF(udm udmservice.UdmService) (*AUSF, error) {
+	logger := log.NewNopLogger()
+	a := &AUSF{Store: store.NewMemory()}
+	a.Service = service.New(udm, a.Store, AuthTTL, nil, logger)
+	a.Endpoints = endpoints.New(a.Service, chain.MiddlewareConfig{Logger: logger, NFInstanceID: "ausf"})
+
+	tracer := stdopentracing.GlobalTracer()
+	zipkinTracer, _ := zipkin.NewTracer(nil, zipkin.WithNoopTracer(true))
+	gs := grpcserver.NewServerBuilder(nil).Recovery(logger).Build()
+	pb.RegisterAusfServer(gs, transports.MakeGRPCServer(a.Endpoints, tracer, zipkinTracer, logger))
+	if err := a.serveGRPC(gs); err != nil {
+		a.Close()
+		return nil, err
+	}
+	a.Client = transports.NewGRPCClient(a.conn, tracer, zipkinTracer, logger)
+	a.serveInproc(transports.MakeInprocServer(a.Endpoints, logger))
+	a.Inproc = transports.NewInprocClient(a.inproc)
+	a.http = httptest.NewServer(transports.NewHTTPHandler(a.Endpoints, tracer, zipkinTracer, logger))
+	a.URL = a.http.URL
+	return a, nil
+}
+
+// Core is a UDM and an AUSF calling it over gRPC.
+type Core struct {
+	UDM  *UDM
+	AUSF *AUSF
+}
+
+// NewCore starts a Core whose UDM is provisioned with subs.
+func NewCore(subs ...subscriber.Subscriber) (*Core, error) {
+	udm, err := NewUDM(subs...)
+	if err != nil {
+		return nil, err
+	}
+	ausf, err := NewAUSF(udm.Client)
+	if err != nil {
+		udm.Close()
+		return nil, err
+	}
+	return &Core{UDM: udm, AUSF: ausf}, nil
+}
+
+// NewInprocCore is like NewCore, but its AUSF calls the UDM through the
+// in-process transport.
+func NewInprocCore(subs ...subscriber.Subscriber) (*Core, error) {
+	udm, err := NewUDM(subs...)
+	if err != nil {
+		return nil, err
+	}
+	ausf, err := NewAUSF(udm.Inproc)
+	if err != nil {
+		udm.Close()
+		return nil, err
+	}
+	return &Core{UDM: udm, AUSF: ausf}, nil
+}
+
+// Close stops the AUSF and the UDM.
+func (c *Core) Close() {
+	c.AUSF.Close()
+	c.UDM.Close()
+}
@@ -0,0 +1,173 @@
+// Package testutil runs the network functions of the core in process, for
+// the tests of the code that integrates with them: a UDM and an AUSF served
+// over in-memory gRPC connections and local HTTP servers, the canned
+// subscribers they serve, and the USIM of a subscriber answering their
+// challenges. Their Inproc clients skip the network altogether, going
+// through the in-process transport of kitx/inproc. The mocks of the interfaces of the services, generated by
+// mockgen from their go:generate directives, are in testutil/mocks.
+//
+// A test starts what it calls and closes it when done:
+//
+//	core, err := testutil.NewCore(testutil.Subscribers...)
+//	if err != nil {
+//		t.Fatal(err)
+//	}
+//	defer core.Close()
+//	ac, err := core.AUSF.Client.Authenticate(ctx, testutil.SUCI, testutil.ServingNetworkName, nil)
+package testutil
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"net/http/httptest"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/kitx/inproc"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/security"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/security/milenage"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/udm/subscriber"
+)
+
+// The identities of the canned subscribers, those of
+// deployments/udm/subscribers.json.
+const (
+	// SUPI is the SUPI of the first subscriber, SUCI the same concealed
+	// with the null scheme.
+	SUPI = "imsi-208930000000001"
+	SUCI = "suci-0-208-93-0000-0-0-0000000001"
+	// ServingNetworkName is that of their home PLMN, 208-93.
+	ServingNetworkName = "5G:mnc093.mcc208.3gppnetwork.org"
+)
+
+// Subscribers are the canned subscribers, the first one with OPc, the
+// second with OP.
+var Subscribers = []subscriber.Subscriber{
+	{
+		SUPI: SUPI,
+		K:    mustHex("465b5ce8b199b49faa5f0a2ee238a6bc"),
+		OPc:  mustHex("cd63cb71954a9f4e48a5994e37a02baf"),
+	},
+	{
+		SUPI: "imsi-208930000000002",
+		K:    mustHex("8baf473f2f8fd09487cccbd7097c6862"),
+		OP:   mustHex("8e27b6af0e692e750f32667a3b14605d"),
+		AMF:  mustHex("8000"),
+	},
+}
+
+// ErrMAC is returned by a USIM for a challenge whose AUTN is not that of
+// its subscriber.
+var ErrMAC = errors.New("testutil: MAC failure")
+
+// USIM is the USIM of a subscriber, answering the 5G-AKA challenges of its
+// home network.
+type USIM struct {
+	k, opc []byte
+}
+
+// NewUSIM returns the USIM of sub, which carries K and OP or OPc.
+func NewUSIM(sub subscriber.Subscriber) (*USIM, error) {
+	opc := []byte(sub.OPc)
+	if len(opc) == 0 {
+		var err error
+		if opc, err = milenage.OPc(sub.K, sub.OP); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := milenage.New(sub.K, opc); err != nil {
+		return nil, err
+	}
+	return &USIM{k: sub.K, opc: opc}, nil
+}
+
+// Answer checks the AUTN of the challenge rand and returns RES*, and the
+// K_AUSF the home network derived.
+func (u *USIM) Answer(rand, autn []byte, servingNetworkName string) (resStar, kausf []byte, err error) {
+	if len(autn) != milenage.SQNSize+milenage.AMFSize+milenage.MACSize {
+		return nil, nil, ErrMAC
+	}
+	m, err := milenage.New(u.k, u.opc)
+	if err != nil {
+		return nil, nil, err
+	}
+	o, err := m.F2345(rand)
+	if err != nil {
+		return nil, nil, err
+	}
+	// AUTN = SQN xor AK || AMF || MAC
+	sqnXorAK := autn[:milenage.SQNSize]
+	amf := autn[milenage.SQNSize : milenage.SQNSize+milenage.AMFSize]
+	sqn := make([]byte, milenage.SQNSize)
+	for i := range sqn {
+		sqn[i] = sqnXorAK[i] ^ o.AK[i]
+	}
+	mac, err := m.F1(rand, sqn, amf)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !bytes.Equal(mac[:], autn[milenage.SQNSize+milenage.AMFSize:]) {
+		return nil, nil, ErrMAC
+	}
+	resStar = security.ResStar(o.CK[:], o.IK[:], servingNetworkName, rand, o.RES[:])
+	kausf = security.Kausf(o.CK[:], o.IK[:], servingNetworkName, sqnXorAK)
+	return resStar, kausf, nil
+}
+
+// server is a gRPC server, an HTTP server and an in-process server run in
+// process.
+type server struct {
+	grpc   *grpc.Server
+	http   *httptest.Server
+	conn   *grpc.ClientConn
+	inproc *inproc.Server
+}
+
+// serveGRPC serves s on an in-memory listener, and returns the connection
+// of its clients.
+func (s *server) serveGRPC(gs *grpc.Server) error {
+	lis := bufconn.Listen(1 << 20)
+	go gs.Serve(lis)
+	s.grpc = gs
+	conn, err := grpc.Dial("bufconn",
+		grpc.WithInsecure(),
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.Dial()
+		}),
+	)
+	s.conn = conn
+	return err
+}
+
+// serveInproc serves is.
+func (s *server) serveInproc(is *inproc.Server) {
+	go is.Serve()
+	s.inproc = is
+}
+
+// Close stops the servers and closes the connection of their clients.
+func (s *server) Close() {
+	if s.inproc != nil {
+		s.inproc.Close()
+	}
+	if s.conn != nil {
+		s.conn.Close()
+	}
+	if s.grpc != nil {
+		s.grpc.Stop()
+	}
+	if s.http != nil {
+		s.http.Close()
+	}
+}
+
+func mustHex(s string) subscriber.Hex {
+	var h subscriber.Hex
+	if err := h.UnmarshalJSON([]byte(`"` + s + `"`)); err != nil {
+		panic(err)
+	}
+	return h
+}
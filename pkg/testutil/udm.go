@@ -0,0 +1,69 @@
+package testutil
+
+import (
+	"context"
+	"net/http/httptest"
+
+	"github.com/go-kit/kit/log"
+	stdopentracing "github.com/opentracing/opentracing-go"
+	"github.com/openzipkin/zipkin-go"
+
+	pb "github.com/miki-tnt/sa5g-go-usvc-k8s/pb/udm"
+	pbv2 "github.com/miki-tnt/sa5g-go-usvc-k8s/pb/udm/v2"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/flags"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/grpcserver"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/history"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/kitx/chain"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/store"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/udm/endpoints"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/udm/service"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/udm/subscriber"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/udm/transports"
+)
+
+// UDM is a UDM run in process over an in-memory store, serving every
+// PLMN with its feature flags off.
+type UDM struct {
+	server
+	// Store keeps its subscribers and the histories of the UEs.
+	Store store.Store
+	// Service is the UDM itself, Endpoints its endpoints.
+	Service   service.UdmService
+	Endpoints endpoints.Endpoints
+	// Client calls it over gRPC, version 1 of its API, as the AUSF does.
+	Client service.UdmService
+	// Inproc calls it through the in-process transport, with the codecs
+	// of version 1 of its gRPC API.
+	Inproc service.UdmService
+	// URL is the base URL of its HTTP API.
+	URL string
+}
+
+// NewUDM starts a UDM provisioned with subs.
+func NewUDM(subs ...subscriber.Subscriber) (*UDM, error) {
+	logger := log.NewNopLogger()
+	u := &UDM{Store: store.NewMemory()}
+	u.Service = service.New(subscriber.NewRepository(u.Store), history.New(u.Store), nil, flags.Static(nil), logger)
+	for _, sub := range subs {
+		if _, err := u.Service.CreateSubscriber(context.Background(), sub); err != nil {
+			return nil, err
+		}
+	}
+	u.Endpoints = endpoints.New(u.Service, chain.MiddlewareConfig{Logger: logger, NFInstanceID: "udm"})
+
+	tracer := stdopentracing.GlobalTracer()
+	zipkinTracer, _ := zipkin.NewTracer(nil, zipkin.WithNoopTracer(true))
+	gs := grpcserver.NewServerBuilder(nil).Recovery(logger).Build()
+	pb.RegisterUdmServer(gs, transports.MakeGRPCServer(u.Endpoints, tracer, zipkinTracer, logger))
+	pbv2.RegisterUdmServer(gs, transports.MakeGRPCServerV2(u.Endpoints, tracer, zipkinTracer, logger))
+	if err := u.serveGRPC(gs); err != nil {
+		u.Close()
+		return nil, err
+	}
+	u.Client = transports.NewGRPCClient(u.conn, tracer, zipkinTracer, logger)
+	u.serveInproc(transports.MakeInprocServer(u.Endpoints, logger))
+	u.Inproc = transports.NewInprocClient(u.inproc)
+	u.http = httptest.NewServer(transports.NewHTTPHandler(u.Endpoints, tracer, zipkinTracer, logger))
+	u.URL = u.http.URL
+	return u, nil
+}
@@ -0,0 +1,401 @@
+// Package timers runs the procedure timers of the NAS protocols, T3510,
+// T3560 and the like, and the inactivity timers of the RAN, for many UEs at
+// once. The timers live in a hashed
+// timing wheel advanced by a single goroutine, so that keeping one or two
+// timers running for each of a large number of UEs stays cheap; the price is
+// that they expire on a tick, up to one tick late.
+//
+// A UE has a Context of named timers. Starting a timer that is running
+// restarts it, as the specifications require; a timer expires by calling its
+// callback, which may restart it to retransmit a message, the Expiry telling
+// how many times it has expired since it was started.
+//
+//	ue := wheel.Context(supi)
+//	ue.Start(timers.T3560, 0, func(e timers.Expiry) {
+//		if e.N < 5 {
+//			resend()
+//			ue.Restart(timers.T3560)
+//			return
+//		}
+//		abort()
+//	})
+//	...
+//	ue.Stop(timers.T3560)
+package timers
+
+import (
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/metrics"
+	"github.com/go-kit/kit/metrics/discard"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/clock"
+)
+
+// The timers of TS 24.501 used by the procedures of the network functions.
+const (
+	// T3510 guards the registration procedure, on the UE side.
+	T3510 = "T3510"
+	// T3512 is the period of the periodic registration updates of the UE,
+	// given it in the registration accept.
+	T3512 = "T3512"
+	// T3513 guards the paging of a CM-IDLE UE, until its service request,
+	// as it does in EPS (TS 24.301 10.2).
+	T3513 = "T3513"
+	// T3550 guards the registration accept, until the registration complete.
+	T3550 = "T3550"
+	// T3560 guards the authentication and security mode procedures.
+	T3560 = "T3560"
+	// T3570 guards the identification procedure.
+	T3570 = "T3570"
+	// T3580 guards the PDU session establishment, on the UE side.
+	T3580 = "T3580"
+	// T3591 guards the PDU session modification command.
+	T3591 = "T3591"
+	// T3592 guards the PDU session release command.
+	T3592 = "T3592"
+)
+
+// ImplicitDeregistration deregisters a CM-IDLE UE that neither came back
+// nor updated its registration, in the AMF, as the non-3GPP implicit
+// de-registration timer does (TS 24.501 5.3.7). It runs 4 minutes longer
+// than T3512 by default.
+const ImplicitDeregistration = "implicit-deregistration"
+
+// The timers of the RAN.
+const (
+	// UEInactivity releases the connection of a UE without activity, the
+	// user inactivity of TS 38.413 9.3.1.2, bringing it to CM-IDLE.
+	UEInactivity = "ue-inactivity"
+)
+
+// Defaults holds the default durations of the timers (TS 24.501 10.2 and
+// 10.3), used when a timer is started with a zero duration. The inactivity
+// timer, left to the operator, is as long as the common deployments set it.
+var Defaults = map[string]time.Duration{
+	T3510: 15 * time.Second,
+	T3512: 54 * time.Minute,
+	T3513: 6 * time.Second,
+	T3550: 6 * time.Second,
+	T3560: 6 * time.Second,
+	T3570: 6 * time.Second,
+	T3580: 16 * time.Second,
+	T3591: 16 * time.Second,
+	T3592: 16 * time.Second,
+
+	ImplicitDeregistration: 58 * time.Minute,
+
+	UEInactivity: 10 * time.Second,
+}
+
+// Default values of the wheel parameters: the wheel turns in a little less
+// than a minute, longer timers take several turns.
+const (
+	DefaultTick  = 100 * time.Millisecond
+	DefaultSlots = 512
+)
+
+// Expiry describes the expiry of a timer.
+type Expiry struct {
+	UE    string
+	Timer string
+	// N is the number of times the timer has expired since it was started,
+	// this expiry included.
+	N int
+}
+
+// Option sets an optional parameter of a Wheel.
+type Option func(*Wheel)
+
+// Tick sets the resolution of the wheel.
+func Tick(d time.Duration) Option {
+	return func(w *Wheel) { w.tick = d }
+}
+
+// Slots sets the number of slots of the wheel, the number of ticks in a turn.
+func Slots(n int) Option {
+	return func(w *Wheel) { w.slots = make([]map[*timer]struct{}, n) }
+}
+
+// Logger sets the logger reporting the callbacks that panic.
+func Logger(logger log.Logger) Option {
+	return func(w *Wheel) { w.logger = logger }
+}
+
+// Clock sets the clock turning the wheel, clock.Real by default. On a
+// clock.Fake, the timers expire as the clock is advanced.
+func Clock(c clock.Clock) Option {
+	return func(w *Wheel) { w.clock = c }
+}
+
+// ExpiryCounter sets the counter incremented on every expiry, with the label
+// "timer" set to the name of the timer.
+func ExpiryCounter(c metrics.Counter) Option {
+	return func(w *Wheel) { w.expiryCounter = c }
+}
+
+type timer struct {
+	ue, name string
+	d        time.Duration
+	fn       func(Expiry)
+	n        int
+	armed    bool
+	slot     int
+	rounds   int
+}
+
+// Wheel runs the timers of every UE.
+type Wheel struct {
+	tick          time.Duration
+	clock         clock.Clock
+	logger        log.Logger
+	expiryCounter metrics.Counter
+
+	mtx     sync.Mutex
+	slots   []map[*timer]struct{}
+	pos     int
+	ues     map[string]map[string]*timer
+	running int
+	expired uint64
+	quit    chan struct{}
+	closed  bool
+}
+
+// NewWheel returns a wheel and starts turning it.
+func NewWheel(options ...Option) *Wheel {
+	w := &Wheel{
+		tick:          DefaultTick,
+		clock:         clock.Real,
+		logger:        log.NewNopLogger(),
+		expiryCounter: discard.NewCounter(),
+		slots:         make([]map[*timer]struct{}, DefaultSlots),
+		ues:           map[string]map[string]*timer{},
+		quit:          make(chan struct{}),
+	}
+	for _, option := range options {
+		option(w)
+	}
+	if len(w.slots) == 0 {
+		w.slots = make([]map[*timer]struct{}, 1)
+	}
+	for i := range w.slots {
+		w.slots[i] = map[*timer]struct{}{}
+	}
+	// the ticker is armed before the wheel is returned, so that advancing
+	// a fake clock right away turns it
+	go w.loop(w.clock.NewTicker(w.tick), w.clock.Now())
+	return w
+}
+
+// Close stops the wheel. The timers running do not expire.
+func (w *Wheel) Close() {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	if !w.closed {
+		w.closed = true
+		close(w.quit)
+	}
+}
+
+// Stats is a point in time view of a wheel.
+type Stats struct {
+	UEs     int    `json:"ues"`
+	Running int    `json:"running"`
+	Expired uint64 `json:"expired"`
+}
+
+// Stats returns the current state of the wheel.
+func (w *Wheel) Stats() Stats {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	return Stats{UEs: len(w.ues), Running: w.running, Expired: w.expired}
+}
+
+// Context returns the timers of the UE identified by ue.
+func (w *Wheel) Context(ue string) Context {
+	return Context{w: w, ue: ue}
+}
+
+// Context holds the timers of a UE. Its methods are safe for concurrent use,
+// callbacks included.
+type Context struct {
+	w  *Wheel
+	ue string
+}
+
+// Start starts the timer name for d, the default duration of the timer when
+// d is zero, calling fn when it expires. A timer already running is
+// restarted. The count of expirations starts over.
+func (c Context) Start(name string, d time.Duration, fn func(Expiry)) {
+	if d == 0 {
+		d = Defaults[name]
+	}
+	w := c.w
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	timers := w.ues[c.ue]
+	if timers == nil {
+		timers = map[string]*timer{}
+		w.ues[c.ue] = timers
+	}
+	t := timers[name]
+	if t == nil {
+		t = &timer{ue: c.ue, name: name}
+		timers[name] = t
+	}
+	w.disarm(t)
+	t.d, t.fn, t.n = d, fn, 0
+	w.arm(t)
+}
+
+// Restart starts the timer name again with the duration and callback it was
+// last started with, keeping its count of expirations. It reports whether the
+// timer was started before.
+func (c Context) Restart(name string) bool {
+	w := c.w
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	t := w.ues[c.ue][name]
+	if t == nil {
+		return false
+	}
+	w.disarm(t)
+	w.arm(t)
+	return true
+}
+
+// Stop stops the timer name and forgets it. It reports whether the timer was
+// running.
+func (c Context) Stop(name string) bool {
+	w := c.w
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	t := w.ues[c.ue][name]
+	if t == nil {
+		return false
+	}
+	running := t.armed
+	w.disarm(t)
+	delete(w.ues[c.ue], name)
+	if len(w.ues[c.ue]) == 0 {
+		delete(w.ues, c.ue)
+	}
+	return running
+}
+
+// StopAll stops every timer of the UE and forgets them, when the UE context
+// is released. It returns the number of timers that were running.
+func (c Context) StopAll() int {
+	w := c.w
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	n := 0
+	for _, t := range w.ues[c.ue] {
+		if t.armed {
+			n++
+		}
+		w.disarm(t)
+	}
+	delete(w.ues, c.ue)
+	return n
+}
+
+// Remaining returns the time left before the timer name expires, rounded up
+// to the tick, and whether it is running.
+func (c Context) Remaining(name string) (time.Duration, bool) {
+	w := c.w
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	t := w.ues[c.ue][name]
+	if t == nil || !t.armed {
+		return 0, false
+	}
+	n := len(w.slots)
+	dist := (t.slot - w.pos + n) % n
+	if dist == 0 {
+		dist = n
+	}
+	return time.Duration(dist+t.rounds*n) * w.tick, true
+}
+
+// arm puts t in the slot it expires in, the lock held.
+func (w *Wheel) arm(t *timer) {
+	ticks := int((t.d + w.tick - 1) / w.tick)
+	if ticks < 1 {
+		ticks = 1
+	}
+	n := len(w.slots)
+	t.slot = (w.pos + ticks) % n
+	t.rounds = (ticks - 1) / n
+	t.armed = true
+	w.slots[t.slot][t] = struct{}{}
+	w.running++
+}
+
+// disarm takes t out of the wheel, the lock held.
+func (w *Wheel) disarm(t *timer) {
+	if !t.armed {
+		return
+	}
+	delete(w.slots[t.slot], t)
+	t.armed = false
+	w.running--
+}
+
+// loop turns the wheel as many ticks as the clock has, the ticks its ticker
+// dropped included.
+func (w *Wheel) loop(ticker clock.Ticker, last time.Time) {
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.quit:
+			return
+		case <-ticker.C():
+			n := w.clock.Since(last) / w.tick
+			last = last.Add(n * w.tick)
+			for ; n > 0; n-- {
+				w.advance()
+			}
+		}
+	}
+}
+
+// advance moves the wheel one tick forward and calls the callbacks of the
+// timers expiring, each in a goroutine of its own so that a slow callback
+// does not hold up the others.
+func (w *Wheel) advance() {
+	w.mtx.Lock()
+	w.pos = (w.pos + 1) % len(w.slots)
+	var expired []Expiry
+	var fns []func(Expiry)
+	for t := range w.slots[w.pos] {
+		if t.rounds > 0 {
+			t.rounds--
+			continue
+		}
+		w.disarm(t)
+		t.n++
+		w.expired++
+		expired = append(expired, Expiry{UE: t.ue, Timer: t.name, N: t.n})
+		fns = append(fns, t.fn)
+	}
+	w.mtx.Unlock()
+
+	for i, e := range expired {
+		w.expiryCounter.With("timer", e.Timer).Add(1)
+		if fns[i] != nil {
+			go w.call(fns[i], e)
+		}
+	}
+}
+
+func (w *Wheel) call(fn func(Expiry), e Expiry) {
+	defer func() {
+		if r := recover(); r != nil {
+			w.logger.Log("ue_id", e.UE, "timer", e.Timer, "panic", r)
+		}
+	}()
+	fn(e)
+}
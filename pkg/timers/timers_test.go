@@ -0,0 +1,177 @@
+package timers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/clock"
+)
+
+// newWheel returns a wheel of 8 slots of a second on a fake clock, which the
+// tests turn tick by tick with advance, and a channel of its expiries.
+func newWheel() (*Wheel, *clock.Fake, chan Expiry) {
+	c := clock.NewFake(time.Unix(0, 0))
+	w := NewWheel(Clock(c), Tick(time.Second), Slots(8))
+	return w, c, make(chan Expiry, 8)
+}
+
+// turn moves w n ticks forward, and returns the number of timers that
+// expired.
+func turn(w *Wheel, n int) int {
+	before := w.Stats().Expired
+	for i := 0; i < n; i++ {
+		w.advance()
+	}
+	return int(w.Stats().Expired - before)
+}
+
+func remaining(t *testing.T, ue Context, name string, want time.Duration) {
+	t.Helper()
+	if d, ok := ue.Remaining(name); !ok || d != want {
+		t.Fatalf("%s remaining %v (%v), want %v", name, d, ok, want)
+	}
+}
+
+func TestStartAcrossTurns(t *testing.T) {
+	w, _, expiries := newWheel()
+	defer w.Close()
+	ue := w.Context("imsi-208930000000001")
+	// 20 ticks on 8 slots: two turns, then 4 slots
+	ue.Start(T3512, 20*time.Second, func(e Expiry) { expiries <- e })
+	remaining(t, ue, T3512, 20*time.Second)
+	for i := 1; i < 20; i++ {
+		if n := turn(w, 1); n != 0 {
+			t.Fatalf("expired after %d ticks", i)
+		}
+		remaining(t, ue, T3512, time.Duration(20-i)*time.Second)
+	}
+	if n := turn(w, 1); n != 1 {
+		t.Fatal("not expired after 20 ticks")
+	}
+	if e := <-expiries; e != (Expiry{UE: "imsi-208930000000001", Timer: T3512, N: 1}) {
+		t.Errorf("got %+v", e)
+	}
+	if _, ok := ue.Remaining(T3512); ok {
+		t.Error("expired timer running")
+	}
+}
+
+func TestStartWholeTurns(t *testing.T) {
+	w, _, _ := newWheel()
+	defer w.Close()
+	ue := w.Context("imsi-208930000000001")
+	// 16 ticks expire in the slot of the start, a turn later
+	ue.Start(T3512, 16*time.Second, nil)
+	remaining(t, ue, T3512, 16*time.Second)
+	if n := turn(w, 8); n != 0 {
+		t.Fatal("expired a turn early")
+	}
+	remaining(t, ue, T3512, 8*time.Second)
+	if n := turn(w, 8); n != 1 {
+		t.Fatal("not expired after two turns")
+	}
+}
+
+func TestRemainingRoundsUp(t *testing.T) {
+	w, _, _ := newWheel()
+	defer w.Close()
+	ue := w.Context("imsi-208930000000001")
+	ue.Start(T3560, 1500*time.Millisecond, nil)
+	remaining(t, ue, T3560, 2*time.Second)
+	ue.Start(T3570, time.Nanosecond, nil)
+	remaining(t, ue, T3570, time.Second)
+	ue.Start(T3550, 0, nil)
+	remaining(t, ue, T3550, Defaults[T3550])
+}
+
+func TestRestartKeepsExpiries(t *testing.T) {
+	w, _, expiries := newWheel()
+	defer w.Close()
+	ue := w.Context("imsi-208930000000001")
+	if ue.Restart(T3560) {
+		t.Fatal("Restart of a timer never started")
+	}
+	ue.Start(T3560, 3*time.Second, func(e Expiry) { expiries <- e })
+	turn(w, 2)
+	if !ue.Restart(T3560) {
+		t.Fatal("Restart of a running timer")
+	}
+	remaining(t, ue, T3560, 3*time.Second)
+	if n := turn(w, 2); n != 0 {
+		t.Fatal("restart did not push the expiry back")
+	}
+	for i := 1; i <= 3; i++ {
+		if n := turn(w, 1); n != 1 {
+			t.Fatalf("expiry %d missing", i)
+		}
+		if e := <-expiries; e.N != i {
+			t.Fatalf("expiry %d counted %d", i, e.N)
+		}
+		ue.Restart(T3560)
+		turn(w, 2)
+	}
+
+	// Start counts the expiries over
+	ue.Start(T3560, 3*time.Second, func(e Expiry) { expiries <- e })
+	turn(w, 3)
+	if e := <-expiries; e.N != 1 {
+		t.Errorf("expiry after Start counted %d", e.N)
+	}
+}
+
+func TestStop(t *testing.T) {
+	w, _, _ := newWheel()
+	defer w.Close()
+	ue := w.Context("imsi-208930000000001")
+	ue.Start(T3512, 20*time.Second, nil)
+	ue.Start(T3513, 6*time.Second, nil)
+	turn(w, 10)
+	if !ue.Stop(T3512) {
+		t.Fatal("Stop of a running timer")
+	}
+	if ue.Stop(T3512) {
+		t.Fatal("Stop of a stopped timer reported it running")
+	}
+	if n := turn(w, 30); n != 0 {
+		t.Fatalf("%d stopped timers expired", n)
+	}
+	if s := w.Stats(); s.Running != 0 || s.UEs != 1 {
+		t.Fatalf("stats %+v, want the expired T3513 kept", s)
+	}
+	if ue.Stop(T3513) {
+		t.Error("Stop of an expired timer reported it running")
+	}
+	if s := w.Stats(); s.UEs != 0 {
+		t.Errorf("stats %+v, want the UE forgotten", s)
+	}
+
+	ue.Start(T3560, 0, nil)
+	ue.Start(T3570, 0, nil)
+	if n := ue.StopAll(); n != 2 {
+		t.Errorf("StopAll stopped %d timers, want 2", n)
+	}
+	if n := turn(w, 16); n != 0 {
+		t.Errorf("%d timers expired after StopAll", n)
+	}
+}
+
+func TestClockTurnsWheel(t *testing.T) {
+	w, c, expiries := newWheel()
+	defer w.Close()
+	ue := w.Context("imsi-208930000000001")
+	ue.Start(T3513, 6*time.Second, func(e Expiry) { expiries <- e })
+	// the ticker drops the ticks after the first, the wheel catches up with
+	// them
+	c.Advance(6 * time.Second)
+	select {
+	case e := <-expiries:
+		if e.Timer != T3513 || e.N != 1 {
+			t.Errorf("got %+v", e)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("T3513 not expired as the clock advanced")
+	}
+	if _, ok := ue.Remaining(T3513); ok {
+		t.Error("expired timer running")
+	}
+}
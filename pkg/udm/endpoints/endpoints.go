@@ -0,0 +1,232 @@
+package endpoints
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/kit/endpoint"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/history"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/kitx/chain"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/udm/service"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/udm/subscriber"
+)
+
+// Endpoints collects all of the endpoints that compose the udm service. It's
+// meant to be used as a helper struct, to collect all of the endpoints into a
+// single parameter.
+type Endpoints struct {
+	GenerateAuthDataEndpoint endpoint.Endpoint
+	CreateSubscriberEndpoint endpoint.Endpoint
+	UpdateSubscriberEndpoint endpoint.Endpoint
+	DeleteSubscriberEndpoint endpoint.Endpoint
+	ListSubscribersEndpoint  endpoint.Endpoint
+	ConfirmAuthEndpoint      endpoint.Endpoint
+	GetUEHistoryEndpoint     endpoint.Endpoint
+}
+
+// New return a new instance of the endpoint that wraps the provided service.
+// The endpoints run in the middleware stack of mw, the provisioning and the
+// history ones as operation and maintenance methods.
+func New(svc service.UdmService, mw chain.MiddlewareConfig) (ep Endpoints) {
+	mw.Logging = LoggingMiddleware
+	c := chain.New(mw)
+	ep.GenerateAuthDataEndpoint = c.Build("generateAuthData", MakeGenerateAuthDataEndpoint(svc))
+	ep.CreateSubscriberEndpoint = c.Build("createSubscriber", MakeCreateSubscriberEndpoint(svc), chain.OAM())
+	ep.UpdateSubscriberEndpoint = c.Build("updateSubscriber", MakeUpdateSubscriberEndpoint(svc), chain.OAM())
+	ep.DeleteSubscriberEndpoint = c.Build("deleteSubscriber", MakeDeleteSubscriberEndpoint(svc), chain.OAM())
+	ep.ListSubscribersEndpoint = c.Build("listSubscribers", MakeListSubscribersEndpoint(svc), chain.OAM())
+	ep.ConfirmAuthEndpoint = c.Build("confirmAuth", MakeConfirmAuthEndpoint(svc))
+	ep.GetUEHistoryEndpoint = c.Build("getUEHistory", MakeGetUEHistoryEndpoint(svc), chain.OAM())
+	return ep
+}
+
+// Signalling returns the endpoints of e serving the signalling only, for
+// the ports the other network functions call: the operation and maintenance
+// ones answer Unimplemented, served on ports of their own.
+func (e Endpoints) Signalling() Endpoints {
+	e.CreateSubscriberEndpoint = oamOnly
+	e.UpdateSubscriberEndpoint = oamOnly
+	e.DeleteSubscriberEndpoint = oamOnly
+	e.ListSubscribersEndpoint = oamOnly
+	e.GetUEHistoryEndpoint = oamOnly
+	return e
+}
+
+// ErrOAMOnly is returned for an operation and maintenance method called on
+// a signalling port.
+var ErrOAMOnly = status.Error(codes.Unimplemented, "served on the operation and maintenance ports only")
+
+func oamOnly(context.Context, interface{}) (interface{}, error) {
+	return nil, ErrOAMOnly
+}
+
+// MakeGenerateAuthDataEndpoint returns an endpoint that invokes GenerateAuthData on the service.
+// Primarily useful in a server.
+func MakeGenerateAuthDataEndpoint(svc service.UdmService) (ep endpoint.Endpoint) {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(GenerateAuthDataRequest)
+		if err := req.validate(); err != nil {
+			return GenerateAuthDataResponse{}, err
+		}
+		var resync *service.Resync
+		if req.Resync != nil {
+			resync = &service.Resync{Rand: req.Resync.Rand, Auts: req.Resync.Auts}
+		}
+		av, err := svc.GenerateAuthData(ctx, req.SUPI, req.ServingNetworkName, resync)
+		return GenerateAuthDataResponse{Rand: av.Rand, Autn: av.Autn, XresStar: av.XresStar, Kausf: av.Kausf}, err
+	}
+}
+
+// GenerateAuthData implements the service interface, so Endpoints may be used as a service.
+// This is primarily useful in the context of a client library.
+func (e Endpoints) GenerateAuthData(ctx context.Context, supi string, servingNetworkName string, resync *service.Resync) (av service.AuthVector, err error) {
+	req := GenerateAuthDataRequest{SUPI: supi, ServingNetworkName: servingNetworkName}
+	if resync != nil {
+		req.Resync = &ResyncInfo{Rand: resync.Rand, Auts: resync.Auts}
+	}
+	resp, err := e.GenerateAuthDataEndpoint(ctx, req)
+	if err != nil {
+		return
+	}
+	response := resp.(GenerateAuthDataResponse)
+	return service.AuthVector{Rand: response.Rand, Autn: response.Autn, XresStar: response.XresStar, Kausf: response.Kausf}, nil
+}
+
+// MakeCreateSubscriberEndpoint returns an endpoint that invokes CreateSubscriber on the service.
+// Primarily useful in a server.
+func MakeCreateSubscriberEndpoint(svc service.UdmService) (ep endpoint.Endpoint) {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(CreateSubscriberRequest)
+		if err := req.validate(); err != nil {
+			return CreateSubscriberResponse{}, err
+		}
+		sub, err := svc.CreateSubscriber(ctx, req.Subscriber)
+		return CreateSubscriberResponse{Subscriber: sub}, err
+	}
+}
+
+// CreateSubscriber implements the service interface, so Endpoints may be used as a service.
+// This is primarily useful in the context of a client library.
+func (e Endpoints) CreateSubscriber(ctx context.Context, sub subscriber.Subscriber) (res subscriber.Subscriber, err error) {
+	resp, err := e.CreateSubscriberEndpoint(ctx, CreateSubscriberRequest{Subscriber: sub})
+	if err != nil {
+		return
+	}
+	response := resp.(CreateSubscriberResponse)
+	return response.Subscriber, nil
+}
+
+// MakeUpdateSubscriberEndpoint returns an endpoint that invokes UpdateSubscriber on the service.
+// Primarily useful in a server.
+func MakeUpdateSubscriberEndpoint(svc service.UdmService) (ep endpoint.Endpoint) {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(UpdateSubscriberRequest)
+		if err := req.validate(); err != nil {
+			return UpdateSubscriberResponse{}, err
+		}
+		sub, err := svc.UpdateSubscriber(ctx, req.Subscriber)
+		return UpdateSubscriberResponse{Subscriber: sub}, err
+	}
+}
+
+// UpdateSubscriber implements the service interface, so Endpoints may be used as a service.
+// This is primarily useful in the context of a client library.
+func (e Endpoints) UpdateSubscriber(ctx context.Context, sub subscriber.Subscriber) (res subscriber.Subscriber, err error) {
+	resp, err := e.UpdateSubscriberEndpoint(ctx, UpdateSubscriberRequest{Subscriber: sub})
+	if err != nil {
+		return
+	}
+	response := resp.(UpdateSubscriberResponse)
+	return response.Subscriber, nil
+}
+
+// MakeDeleteSubscriberEndpoint returns an endpoint that invokes DeleteSubscriber on the service.
+// Primarily useful in a server.
+func MakeDeleteSubscriberEndpoint(svc service.UdmService) (ep endpoint.Endpoint) {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(DeleteSubscriberRequest)
+		if err := req.validate(); err != nil {
+			return DeleteSubscriberResponse{}, err
+		}
+		err := svc.DeleteSubscriber(ctx, req.SUPI)
+		return DeleteSubscriberResponse{}, err
+	}
+}
+
+// DeleteSubscriber implements the service interface, so Endpoints may be used as a service.
+// This is primarily useful in the context of a client library.
+func (e Endpoints) DeleteSubscriber(ctx context.Context, supi string) (err error) {
+	_, err = e.DeleteSubscriberEndpoint(ctx, DeleteSubscriberRequest{SUPI: supi})
+	return err
+}
+
+// MakeListSubscribersEndpoint returns an endpoint that invokes ListSubscribers on the service.
+// Primarily useful in a server.
+func MakeListSubscribersEndpoint(svc service.UdmService) (ep endpoint.Endpoint) {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(ListSubscribersRequest)
+		if err := req.validate(); err != nil {
+			return ListSubscribersResponse{}, err
+		}
+		subs, next, err := svc.ListSubscribers(ctx, req.PageSize, req.PageToken)
+		return ListSubscribersResponse{Subscribers: subs, NextPageToken: next}, err
+	}
+}
+
+// ListSubscribers implements the service interface, so Endpoints may be used as a service.
+// This is primarily useful in the context of a client library.
+func (e Endpoints) ListSubscribers(ctx context.Context, pageSize int, pageToken string) (subs []subscriber.Subscriber, nextPageToken string, err error) {
+	resp, err := e.ListSubscribersEndpoint(ctx, ListSubscribersRequest{PageSize: pageSize, PageToken: pageToken})
+	if err != nil {
+		return
+	}
+	response := resp.(ListSubscribersResponse)
+	return response.Subscribers, response.NextPageToken, nil
+}
+
+// MakeConfirmAuthEndpoint returns an endpoint that invokes ConfirmAuth on the service.
+// Primarily useful in a server.
+func MakeConfirmAuthEndpoint(svc service.UdmService) (ep endpoint.Endpoint) {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(ConfirmAuthRequest)
+		if err := req.validate(); err != nil {
+			return ConfirmAuthResponse{}, err
+		}
+		err := svc.ConfirmAuth(ctx, req.SUPI, req.ServingNetworkName, req.Success)
+		return ConfirmAuthResponse{}, err
+	}
+}
+
+// ConfirmAuth implements the service interface, so Endpoints may be used as a service.
+// This is primarily useful in the context of a client library.
+func (e Endpoints) ConfirmAuth(ctx context.Context, supi string, servingNetworkName string, success bool) (err error) {
+	_, err = e.ConfirmAuthEndpoint(ctx, ConfirmAuthRequest{SUPI: supi, ServingNetworkName: servingNetworkName, Success: success})
+	return err
+}
+
+// MakeGetUEHistoryEndpoint returns an endpoint that invokes GetUEHistory on the service.
+// Primarily useful in a server.
+func MakeGetUEHistoryEndpoint(svc service.UdmService) (ep endpoint.Endpoint) {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(GetUEHistoryRequest)
+		if err := req.validate(); err != nil {
+			return GetUEHistoryResponse{}, err
+		}
+		events, next, err := svc.GetUEHistory(ctx, req.SUPI, req.Since, req.Until, req.PageSize, req.PageToken)
+		return GetUEHistoryResponse{Events: events, NextPageToken: next}, err
+	}
+}
+
+// GetUEHistory implements the service interface, so Endpoints may be used as a service.
+// This is primarily useful in the context of a client library.
+func (e Endpoints) GetUEHistory(ctx context.Context, supi string, since, until time.Time, pageSize int, pageToken string) (events []history.Event, nextPageToken string, err error) {
+	resp, err := e.GetUEHistoryEndpoint(ctx, GetUEHistoryRequest{SUPI: supi, Since: since, Until: until, PageSize: pageSize, PageToken: pageToken})
+	if err != nil {
+		return
+	}
+	response := resp.(GetUEHistoryResponse)
+	return response.Events, response.NextPageToken, nil
+}
@@ -0,0 +1,122 @@
+package endpoints
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/cache"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/clock"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/history"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/logging"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/meta"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/plmn"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/shadow"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/udm/service"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/udm/subscriber"
+)
+
+// LoggingMiddleware returns an endpoint middleware that logs the
+// duration of each invocation, timed by clk, and the resulting error, if any. Records carry
+// the trace and span IDs of the call when it is traced, the UE of the
+// request, which is passed on in the context, and its PLMN when the request
+// carries one.
+func LoggingMiddleware(logger log.Logger, clk clock.Clock) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+			if r, ok := request.(logging.UERequest); ok {
+				ctx = logging.NewUEContext(ctx, r.UEID())
+			}
+			defer func(begin time.Time) {
+				logger := log.With(logger, append(logging.ContextKeyvals(ctx), plmn.Keyvals(ctx)...)...)
+				if err == nil {
+					level.Info(logger).Log("transport_error", err, "took", clk.Since(begin))
+				} else {
+					level.Error(logger).Log("transport_error", err, "took", clk.Since(begin))
+				}
+			}(clk.Now())
+			return next(ctx, request)
+		}
+	}
+}
+
+// Cached returns the endpoints of e, those of a client usually, whose reads
+// of the subscriber data, ListSubscribers and GetUEHistory, are served from
+// c. The writes invalidate the pages of subscribers and the history of their
+// UE; the history the UDM records on its own, the authentications of the
+// other clients among others, shows once the responses expire.
+func Cached(e Endpoints, c *cache.Cache) Endpoints {
+	e.ListSubscribersEndpoint = c.Middleware("listSubscribers", cache.JSONKey)(e.ListSubscribersEndpoint)
+	e.GetUEHistoryEndpoint = c.Middleware("getUEHistory", func(request interface{}) (string, bool) {
+		key, ok := cache.JSONKey(request)
+		return request.(GetUEHistoryRequest).SUPI + "/" + key, ok
+	})(e.GetUEHistoryEndpoint)
+	invalidateUE := c.Invalidator(func(c *cache.Cache, request interface{}) {
+		if r, ok := request.(meta.UERequest); ok {
+			c.InvalidatePrefix("getUEHistory", r.UEID()+"/")
+		}
+	})
+	invalidateSubscriber := c.Invalidator(func(c *cache.Cache, request interface{}) {
+		c.InvalidatePrefix("listSubscribers", "")
+		if r, ok := request.(meta.UERequest); ok {
+			c.InvalidatePrefix("getUEHistory", r.UEID()+"/")
+		}
+	})
+	e.GenerateAuthDataEndpoint = invalidateUE(e.GenerateAuthDataEndpoint)
+	e.ConfirmAuthEndpoint = invalidateUE(e.ConfirmAuthEndpoint)
+	e.CreateSubscriberEndpoint = invalidateSubscriber(e.CreateSubscriberEndpoint)
+	e.UpdateSubscriberEndpoint = invalidateSubscriber(e.UpdateSubscriberEndpoint)
+	e.DeleteSubscriberEndpoint = invalidateSubscriber(e.DeleteSubscriberEndpoint)
+	return e
+}
+
+// Mirrored returns the endpoints of svc, a client usually, mirroring the
+// calls of the AUSF, GenerateAuthData and ConfirmAuth, and the reads of the
+// subscriber data to canary through m. The authentication vectors being
+// random, those of the canary match when they have the sizes of the vectors
+// of svc. The provisioning is not mirrored: the canary keeps a copy of the
+// subscribers of its own, which a share of the provisioning would leave
+// inconsistent.
+func Mirrored(svc, canary service.UdmService, m *shadow.Mirror) Endpoints {
+	e := Endpoints{
+		GenerateAuthDataEndpoint: MakeGenerateAuthDataEndpoint(svc),
+		CreateSubscriberEndpoint: MakeCreateSubscriberEndpoint(svc),
+		UpdateSubscriberEndpoint: MakeUpdateSubscriberEndpoint(svc),
+		DeleteSubscriberEndpoint: MakeDeleteSubscriberEndpoint(svc),
+		ListSubscribersEndpoint:  MakeListSubscribersEndpoint(svc),
+		ConfirmAuthEndpoint:      MakeConfirmAuthEndpoint(svc),
+		GetUEHistoryEndpoint:     MakeGetUEHistoryEndpoint(svc),
+	}
+	e.GenerateAuthDataEndpoint = m.Middleware("generateAuthData", MakeGenerateAuthDataEndpoint(canary), sameVectorSizes)(e.GenerateAuthDataEndpoint)
+	e.ConfirmAuthEndpoint = m.Middleware("confirmAuth", MakeConfirmAuthEndpoint(canary), nil)(e.ConfirmAuthEndpoint)
+	e.ListSubscribersEndpoint = m.Middleware("listSubscribers", MakeListSubscribersEndpoint(canary), nil)(e.ListSubscribersEndpoint)
+	e.GetUEHistoryEndpoint = m.Middleware("getUEHistory", MakeGetUEHistoryEndpoint(canary), nil)(e.GetUEHistoryEndpoint)
+	return e
+}
+
+func sameVectorSizes(primary, canary interface{}) bool {
+	p, c := primary.(GenerateAuthDataResponse), canary.(GenerateAuthDataResponse)
+	return len(p.Rand) == len(c.Rand) && len(p.Autn) == len(c.Autn) && len(p.XresStar) == len(c.XresStar) && len(p.Kausf) == len(c.Kausf)
+}
+
+// InvalidateKey drops the responses of c changed by the record of key of
+// the store of the UDM, for the watches of etcd.Invalidate: a subscriber
+// changes the pages of subscribers and the history of its UE, an event the
+// history of its UE. The history the UDM records on its own thus shows as
+// soon as it is.
+func InvalidateKey(c *cache.Cache, key string) {
+	switch {
+	case strings.HasPrefix(key, subscriber.KeyPrefix):
+		c.InvalidatePrefix("listSubscribers", "")
+		c.InvalidatePrefix("getUEHistory", strings.TrimPrefix(key, subscriber.KeyPrefix)+"/")
+	case strings.HasPrefix(key, history.KeyPrefix):
+		supi := strings.TrimPrefix(key, history.KeyPrefix)
+		if i := strings.Index(supi, "/"); i >= 0 {
+			c.InvalidatePrefix("getUEHistory", supi[:i]+"/")
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package endpoints
+
+import (
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/udm/subscriber"
+)
+
+type Request interface {
+	validate() error
+}
+
+// ResyncInfo carries the RAND and AUTS of a synchronisation failure.
+type ResyncInfo struct {
+	Rand []byte `json:"rand"`
+	Auts []byte `json:"auts"`
+}
+
+// GenerateAuthDataRequest collects the request parameters for the GenerateAuthData method.
+type GenerateAuthDataRequest struct {
+	SUPI               string      `json:"supi"`
+	ServingNetworkName string      `json:"serving_network_name"`
+	Resync             *ResyncInfo `json:"resync,omitempty"`
+}
+
+func (r GenerateAuthDataRequest) validate() error {
+	if r.SUPI == "" {
+		return status.Error(codes.InvalidArgument, "supi is required")
+	}
+	return nil
+}
+
+// UEID implements logging.UERequest.
+func (r GenerateAuthDataRequest) UEID() string {
+	return r.SUPI
+}
+
+// CreateSubscriberRequest collects the request parameters for the CreateSubscriber method.
+type CreateSubscriberRequest struct {
+	Subscriber subscriber.Subscriber `json:"subscriber"`
+}
+
+func (r CreateSubscriberRequest) validate() error {
+	if r.Subscriber.SUPI == "" {
+		return status.Error(codes.InvalidArgument, "supi is required")
+	}
+	return nil
+}
+
+// UEID implements logging.UERequest.
+func (r CreateSubscriberRequest) UEID() string {
+	return r.Subscriber.SUPI
+}
+
+// UpdateSubscriberRequest collects the request parameters for the UpdateSubscriber method.
+type UpdateSubscriberRequest struct {
+	Subscriber subscriber.Subscriber `json:"subscriber"`
+}
+
+func (r UpdateSubscriberRequest) validate() error {
+	if r.Subscriber.SUPI == "" {
+		return status.Error(codes.InvalidArgument, "supi is required")
+	}
+	return nil
+}
+
+// UEID implements logging.UERequest.
+func (r UpdateSubscriberRequest) UEID() string {
+	return r.Subscriber.SUPI
+}
+
+// DeleteSubscriberRequest collects the request parameters for the DeleteSubscriber method.
+type DeleteSubscriberRequest struct {
+	SUPI string `json:"supi"`
+}
+
+func (r DeleteSubscriberRequest) validate() error {
+	if r.SUPI == "" {
+		return status.Error(codes.InvalidArgument, "supi is required")
+	}
+	return nil
+}
+
+// UEID implements logging.UERequest.
+func (r DeleteSubscriberRequest) UEID() string {
+	return r.SUPI
+}
+
+// ListSubscribersRequest collects the request parameters for the ListSubscribers method.
+type ListSubscribersRequest struct {
+	PageSize  int    `json:"page_size"`
+	PageToken string `json:"page_token"`
+}
+
+func (r ListSubscribersRequest) validate() error {
+	if r.PageSize < 0 {
+		return status.Error(codes.InvalidArgument, "page_size must not be negative")
+	}
+	return nil
+}
+
+// ConfirmAuthRequest collects the request parameters for the ConfirmAuth method.
+type ConfirmAuthRequest struct {
+	SUPI               string `json:"supi"`
+	ServingNetworkName string `json:"serving_network_name"`
+	Success            bool   `json:"success"`
+}
+
+func (r ConfirmAuthRequest) validate() error {
+	if r.SUPI == "" {
+		return status.Error(codes.InvalidArgument, "supi is required")
+	}
+	return nil
+}
+
+// UEID implements logging.UERequest.
+func (r ConfirmAuthRequest) UEID() string {
+	return r.SUPI
+}
+
+// GetUEHistoryRequest collects the request parameters for the GetUEHistory
+// method. Since and Until bound the time range, each of them left open when
+// zero.
+type GetUEHistoryRequest struct {
+	SUPI      string    `json:"supi"`
+	Since     time.Time `json:"since"`
+	Until     time.Time `json:"until"`
+	PageSize  int       `json:"page_size"`
+	PageToken string    `json:"page_token"`
+}
+
+func (r GetUEHistoryRequest) validate() error {
+	if r.SUPI == "" {
+		return status.Error(codes.InvalidArgument, "supi is required")
+	}
+	if r.PageSize < 0 {
+		return status.Error(codes.InvalidArgument, "page_size must not be negative")
+	}
+	if !r.Since.IsZero() && !r.Until.IsZero() && !r.Since.Before(r.Until) {
+		return status.Error(codes.InvalidArgument, "since must be before until")
+	}
+	return nil
+}
+
+// UEID implements logging.UERequest.
+func (r GetUEHistoryRequest) UEID() string {
+	return r.SUPI
+}
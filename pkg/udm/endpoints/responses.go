@@ -0,0 +1,129 @@
+package endpoints
+
+import (
+	"net/http"
+
+	httptransport "github.com/go-kit/kit/transport/http"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/history"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/udm/subscriber"
+)
+
+var (
+	_ httptransport.Headerer = (*GenerateAuthDataResponse)(nil)
+	_ httptransport.Headerer = (*CreateSubscriberResponse)(nil)
+	_ httptransport.Headerer = (*UpdateSubscriberResponse)(nil)
+	_ httptransport.Headerer = (*DeleteSubscriberResponse)(nil)
+	_ httptransport.Headerer = (*ListSubscribersResponse)(nil)
+	_ httptransport.Headerer = (*ConfirmAuthResponse)(nil)
+	_ httptransport.Headerer = (*GetUEHistoryResponse)(nil)
+
+	_ httptransport.StatusCoder = (*GenerateAuthDataResponse)(nil)
+	_ httptransport.StatusCoder = (*CreateSubscriberResponse)(nil)
+	_ httptransport.StatusCoder = (*UpdateSubscriberResponse)(nil)
+	_ httptransport.StatusCoder = (*DeleteSubscriberResponse)(nil)
+	_ httptransport.StatusCoder = (*ListSubscribersResponse)(nil)
+	_ httptransport.StatusCoder = (*ConfirmAuthResponse)(nil)
+	_ httptransport.StatusCoder = (*GetUEHistoryResponse)(nil)
+)
+
+// GenerateAuthDataResponse collects the response values for the GenerateAuthData method.
+type GenerateAuthDataResponse struct {
+	Rand     []byte `json:"rand"`
+	Autn     []byte `json:"autn"`
+	XresStar []byte `json:"xres_star"`
+	Kausf    []byte `json:"kausf"`
+	Err      error  `json:"err"`
+}
+
+func (r GenerateAuthDataResponse) StatusCode() int {
+	return http.StatusOK // TBA
+}
+
+func (r GenerateAuthDataResponse) Headers() http.Header {
+	return http.Header{}
+}
+
+// CreateSubscriberResponse collects the response values for the CreateSubscriber method.
+type CreateSubscriberResponse struct {
+	Subscriber subscriber.Subscriber `json:"subscriber"`
+	Err        error                 `json:"err"`
+}
+
+func (r CreateSubscriberResponse) StatusCode() int {
+	return http.StatusOK // TBA
+}
+
+func (r CreateSubscriberResponse) Headers() http.Header {
+	return http.Header{}
+}
+
+// UpdateSubscriberResponse collects the response values for the UpdateSubscriber method.
+type UpdateSubscriberResponse struct {
+	Subscriber subscriber.Subscriber `json:"subscriber"`
+	Err        error                 `json:"err"`
+}
+
+func (r UpdateSubscriberResponse) StatusCode() int {
+	return http.StatusOK // TBA
+}
+
+func (r UpdateSubscriberResponse) Headers() http.Header {
+	return http.Header{}
+}
+
+// DeleteSubscriberResponse collects the response values for the DeleteSubscriber method.
+type DeleteSubscriberResponse struct {
+	Err error `json:"err"`
+}
+
+func (r DeleteSubscriberResponse) StatusCode() int {
+	return http.StatusOK // TBA
+}
+
+func (r DeleteSubscriberResponse) Headers() http.Header {
+	return http.Header{}
+}
+
+// ListSubscribersResponse collects the response values for the ListSubscribers method.
+type ListSubscribersResponse struct {
+	Subscribers   []subscriber.Subscriber `json:"subscribers"`
+	NextPageToken string                  `json:"next_page_token,omitempty"`
+	Err           error                   `json:"err"`
+}
+
+func (r ListSubscribersResponse) StatusCode() int {
+	return http.StatusOK // TBA
+}
+
+func (r ListSubscribersResponse) Headers() http.Header {
+	return http.Header{}
+}
+
+// ConfirmAuthResponse collects the response values for the ConfirmAuth method.
+type ConfirmAuthResponse struct {
+	Err error `json:"err"`
+}
+
+func (r ConfirmAuthResponse) StatusCode() int {
+	return http.StatusOK // TBA
+}
+
+func (r ConfirmAuthResponse) Headers() http.Header {
+	return http.Header{}
+}
+
+// GetUEHistoryResponse collects the response values for the GetUEHistory method.
+type GetUEHistoryResponse struct {
+	Events        []history.Event `json:"events"`
+	NextPageToken string          `json:"next_page_token,omitempty"`
+	Err           error           `json:"err"`
+}
+
+func (r GetUEHistoryResponse) StatusCode() int {
+	return http.StatusOK // TBA
+}
+
+func (r GetUEHistoryResponse) Headers() http.Header {
+	return http.Header{}
+}
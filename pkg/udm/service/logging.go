@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/history"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/logging"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/plmn"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/udm/subscriber"
+)
+
+type loggingMiddleware struct {
+	logger log.Logger
+	next   UdmService
+}
+
+// LoggingMiddleware takes a logger as a dependency
+// and returns a ServiceMiddleware.
+func LoggingMiddleware(logger log.Logger) Middleware {
+	return func(next UdmService) UdmService {
+		return loggingMiddleware{level.Info(logger), next}
+	}
+}
+
+func (lm loggingMiddleware) GenerateAuthData(ctx context.Context, supi string, servingNetworkName string, resync *Resync) (av AuthVector, err error) {
+	defer func(begin time.Time) {
+		log.With(lm.logger, append(logging.ContextKeyvals(ctx), plmn.Keyvals(ctx)...)...).Log("method", "GenerateAuthData", "supi", supi, "snn", servingNetworkName, "resync", resync != nil, "err", err)
+	}(time.Now())
+
+	return lm.next.GenerateAuthData(ctx, supi, servingNetworkName, resync)
+}
+
+func (lm loggingMiddleware) CreateSubscriber(ctx context.Context, sub subscriber.Subscriber) (res subscriber.Subscriber, err error) {
+	defer func(begin time.Time) {
+		log.With(lm.logger, append(logging.ContextKeyvals(ctx), plmn.Keyvals(ctx)...)...).Log("method", "CreateSubscriber", "supi", sub.SUPI, "err", err)
+	}(time.Now())
+
+	return lm.next.CreateSubscriber(ctx, sub)
+}
+
+func (lm loggingMiddleware) UpdateSubscriber(ctx context.Context, sub subscriber.Subscriber) (res subscriber.Subscriber, err error) {
+	defer func(begin time.Time) {
+		log.With(lm.logger, append(logging.ContextKeyvals(ctx), plmn.Keyvals(ctx)...)...).Log("method", "UpdateSubscriber", "supi", sub.SUPI, "err", err)
+	}(time.Now())
+
+	return lm.next.UpdateSubscriber(ctx, sub)
+}
+
+func (lm loggingMiddleware) DeleteSubscriber(ctx context.Context, supi string) (err error) {
+	defer func(begin time.Time) {
+		log.With(lm.logger, append(logging.ContextKeyvals(ctx), plmn.Keyvals(ctx)...)...).Log("method", "DeleteSubscriber", "supi", supi, "err", err)
+	}(time.Now())
+
+	return lm.next.DeleteSubscriber(ctx, supi)
+}
+
+func (lm loggingMiddleware) ListSubscribers(ctx context.Context, pageSize int, pageToken string) (subs []subscriber.Subscriber, nextPageToken string, err error) {
+	defer func(begin time.Time) {
+		log.With(lm.logger, append(logging.ContextKeyvals(ctx), plmn.Keyvals(ctx)...)...).Log("method", "ListSubscribers", "page_size", pageSize, "page_token", pageToken, "n", len(subs), "err", err)
+	}(time.Now())
+
+	return lm.next.ListSubscribers(ctx, pageSize, pageToken)
+}
+
+func (lm loggingMiddleware) ConfirmAuth(ctx context.Context, supi string, servingNetworkName string, success bool) (err error) {
+	defer func(begin time.Time) {
+		log.With(lm.logger, append(logging.ContextKeyvals(ctx), plmn.Keyvals(ctx)...)...).Log("method", "ConfirmAuth", "supi", supi, "snn", servingNetworkName, "success", success, "err", err)
+	}(time.Now())
+
+	return lm.next.ConfirmAuth(ctx, supi, servingNetworkName, success)
+}
+
+func (lm loggingMiddleware) GetUEHistory(ctx context.Context, supi string, since, until time.Time, pageSize int, pageToken string) (events []history.Event, nextPageToken string, err error) {
+	defer func(begin time.Time) {
+		log.With(lm.logger, append(logging.ContextKeyvals(ctx), plmn.Keyvals(ctx)...)...).Log("method", "GetUEHistory", "supi", supi, "page_size", pageSize, "page_token", pageToken, "n", len(events), "err", err)
+	}(time.Now())
+
+	return lm.next.GetUEHistory(ctx, supi, since, until, pageSize, pageToken)
+}
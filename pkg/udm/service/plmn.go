@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/history"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/plmn"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/udm/subscriber"
+)
+
+type plmnMiddleware struct {
+	served plmn.List
+	next   UdmService
+}
+
+// PLMNMiddleware rejects the subscribers of the PLMNs that are not served,
+// and those of another PLMN than the one of the request. Listed subscribers
+// are filtered the same way, so a page may hold fewer than pageSize of them.
+func PLMNMiddleware(served plmn.List) Middleware {
+	return func(next UdmService) UdmService {
+		return plmnMiddleware{served, next}
+	}
+}
+
+func (pm plmnMiddleware) GenerateAuthData(ctx context.Context, supi string, servingNetworkName string, resync *Resync) (av AuthVector, err error) {
+	ctx, err = pm.served.Admit(ctx, supi)
+	if err != nil {
+		return av, err
+	}
+	return pm.next.GenerateAuthData(ctx, supi, servingNetworkName, resync)
+}
+
+func (pm plmnMiddleware) CreateSubscriber(ctx context.Context, sub subscriber.Subscriber) (res subscriber.Subscriber, err error) {
+	ctx, err = pm.served.Admit(ctx, sub.SUPI)
+	if err != nil {
+		return res, err
+	}
+	return pm.next.CreateSubscriber(ctx, sub)
+}
+
+func (pm plmnMiddleware) UpdateSubscriber(ctx context.Context, sub subscriber.Subscriber) (res subscriber.Subscriber, err error) {
+	ctx, err = pm.served.Admit(ctx, sub.SUPI)
+	if err != nil {
+		return res, err
+	}
+	return pm.next.UpdateSubscriber(ctx, sub)
+}
+
+func (pm plmnMiddleware) DeleteSubscriber(ctx context.Context, supi string) (err error) {
+	ctx, err = pm.served.Admit(ctx, supi)
+	if err != nil {
+		return err
+	}
+	return pm.next.DeleteSubscriber(ctx, supi)
+}
+
+func (pm plmnMiddleware) ListSubscribers(ctx context.Context, pageSize int, pageToken string) (subs []subscriber.Subscriber, nextPageToken string, err error) {
+	subs, nextPageToken, err = pm.next.ListSubscribers(ctx, pageSize, pageToken)
+	if err != nil {
+		return nil, "", err
+	}
+	res := subs[:0]
+	for _, sub := range subs {
+		if _, err := pm.served.Admit(ctx, sub.SUPI); err == nil {
+			res = append(res, sub)
+		}
+	}
+	return res, nextPageToken, nil
+}
+
+func (pm plmnMiddleware) ConfirmAuth(ctx context.Context, supi string, servingNetworkName string, success bool) (err error) {
+	ctx, err = pm.served.Admit(ctx, supi)
+	if err != nil {
+		return err
+	}
+	return pm.next.ConfirmAuth(ctx, supi, servingNetworkName, success)
+}
+
+func (pm plmnMiddleware) GetUEHistory(ctx context.Context, supi string, since, until time.Time, pageSize int, pageToken string) (events []history.Event, nextPageToken string, err error) {
+	ctx, err = pm.served.Admit(ctx, supi)
+	if err != nil {
+		return nil, "", err
+	}
+	return pm.next.GetUEHistory(ctx, supi, since, until, pageSize, pageToken)
+}
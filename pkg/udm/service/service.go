@@ -0,0 +1,329 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/caller"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/flags"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/history"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/plmn"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/security"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/security/milenage"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/udm/subscriber"
+)
+
+// Page sizes of ListSubscribers.
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 1000
+)
+
+// sqnStep is added to SQN for every vector. The 5 low bits are the IND
+// index of TS 33.102 C.3.2, left at zero.
+const sqnStep = 1 << 5
+
+var (
+	// ErrServingNetworkName is returned when no serving network name is
+	// given.
+	ErrServingNetworkName = status.Error(codes.InvalidArgument, "serving network name is required")
+	// ErrResync is returned when the AUTS of a resynchronisation request
+	// does not verify.
+	ErrResync = status.Error(codes.Unauthenticated, "resynchronisation failed, MAC-S mismatch")
+	// ErrResyncInfo is returned when RAND or AUTS have the wrong size.
+	ErrResyncInfo = status.Error(codes.InvalidArgument, "invalid resynchronisation info")
+)
+
+// Middleware describes a service (as opposed to endpoint) middleware.
+type Middleware func(UdmService) UdmService
+
+//go:generate mockgen -destination=../../testutil/mocks/udm_service.go -package=mocks github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/udm/service UdmService
+
+// UdmService generates the 5G-AKA authentication vectors of the subscribers.
+// It also provisions them; the subscribers it returns never carry K or OPc.
+// The authentications of every UE are kept in its history.
+type UdmService interface {
+	GenerateAuthData(ctx context.Context, supi string, servingNetworkName string, resync *Resync) (av AuthVector, err error)
+	CreateSubscriber(ctx context.Context, sub subscriber.Subscriber) (res subscriber.Subscriber, err error)
+	UpdateSubscriber(ctx context.Context, sub subscriber.Subscriber) (res subscriber.Subscriber, err error)
+	DeleteSubscriber(ctx context.Context, supi string) (err error)
+	ListSubscribers(ctx context.Context, pageSize int, pageToken string) (subs []subscriber.Subscriber, nextPageToken string, err error)
+	// ConfirmAuth records the result of an authentication of the UE, as
+	// reported by the AUSF.
+	ConfirmAuth(ctx context.Context, supi string, servingNetworkName string, success bool) (err error)
+	// GetUEHistory returns a page of the events of the UE that happened in
+	// [since, until), oldest first.
+	GetUEHistory(ctx context.Context, supi string, since, until time.Time, pageSize int, pageToken string) (events []history.Event, nextPageToken string, err error)
+}
+
+// Resync carries the RAND and AUTS sent by a UE whose SQN is out of range.
+type Resync struct {
+	Rand []byte
+	Auts []byte
+}
+
+// AuthVector is a 5G home environment authentication vector
+// (TS 33.501 6.1.3.2).
+type AuthVector struct {
+	Rand     []byte
+	Autn     []byte
+	XresStar []byte
+	Kausf    []byte
+}
+
+// the concrete implementation of service interface
+type stubUdmService struct {
+	logger      log.Logger
+	subscribers subscriber.Repository
+	history     *history.Log
+	// mtx serializes the SQN updates, unless the repository is
+	// Transactional. The repository may be shared with other replicas,
+	// which then need a single UDM writer per subscriber, or a
+	// Transactional repository.
+	mtx sync.Mutex
+}
+
+// New return a new instance of the service.
+// If you want to add service middleware this is the place to put them.
+// The events of the UEs are appended to hist. Only the subscribers of the
+// served PLMNs are handled, all of them when served is empty. The requests
+// are validated strictly where the StrictValidation flag of fl is on.
+func New(subscribers subscriber.Repository, hist *history.Log, served plmn.List, fl flags.Client, logger log.Logger) (s UdmService) {
+	var svc UdmService
+	{
+		svc = &stubUdmService{logger: logger, subscribers: subscribers, history: hist}
+		svc = ValidationMiddleware(fl)(svc)
+		svc = LoggingMiddleware(logger)(svc)
+		svc = PLMNMiddleware(served)(svc)
+	}
+	return svc
+}
+
+// Implement the business logic of GenerateAuthData. Every request is a
+// registration attempt of the UE, recorded in its history whatever the
+// outcome.
+func (u *stubUdmService) GenerateAuthData(ctx context.Context, supi string, servingNetworkName string, resync *Resync) (av AuthVector, err error) {
+	if servingNetworkName == "" {
+		return av, ErrServingNetworkName
+	}
+	defer func() {
+		detail := map[string]string{"serving_network_name": servingNetworkName}
+		if resync != nil {
+			detail["resync"] = "true"
+		}
+		u.record(ctx, supi, history.RegistrationAttempt, err, detail)
+	}()
+
+	err = u.update(ctx, supi, func(sub *subscriber.Subscriber) (err error) {
+		av, err = vector(sub, servingNetworkName, resync)
+		return err
+	})
+	if err != nil {
+		return AuthVector{}, err
+	}
+	return av, nil
+}
+
+// vector returns the next authentication vector of sub, whose SQN it
+// increments.
+func vector(sub *subscriber.Subscriber, servingNetworkName string, resync *Resync) (av AuthVector, err error) {
+	m, err := milenage.New(sub.K, sub.OPc)
+	if err != nil {
+		return av, err
+	}
+
+	sqn := subscriber.SQNUint(sub.SQN)
+	if resync != nil {
+		if sqn, err = verifyResync(m, resync); err != nil {
+			return av, err
+		}
+	}
+	sub.SQN = subscriber.SQNBytes(sqn + sqnStep)
+
+	av.Rand = make([]byte, milenage.RandSize)
+	if _, err := rand.Read(av.Rand); err != nil {
+		return av, err
+	}
+	mac, err := m.F1(av.Rand, sub.SQN, sub.AMF)
+	if err != nil {
+		return av, err
+	}
+	o, err := m.F2345(av.Rand)
+	if err != nil {
+		return av, err
+	}
+
+	sqnXorAK := make([]byte, milenage.SQNSize)
+	for i := range sqnXorAK {
+		sqnXorAK[i] = sub.SQN[i] ^ o.AK[i]
+	}
+	av.Autn = append(append(append(make([]byte, 0, 16), sqnXorAK...), sub.AMF...), mac[:]...)
+	av.XresStar = security.ResStar(o.CK[:], o.IK[:], servingNetworkName, av.Rand, o.RES[:])
+	av.Kausf = security.Kausf(o.CK[:], o.IK[:], servingNetworkName, sqnXorAK)
+	return av, nil
+}
+
+// update applies fn to the subscriber of supi and puts it, in a transaction
+// of the repository when it is Transactional, under mtx otherwise.
+func (u *stubUdmService) update(ctx context.Context, supi string, fn func(sub *subscriber.Subscriber) error) error {
+	if t, ok := u.subscribers.(subscriber.Transactional); ok {
+		return t.Update(ctx, supi, fn)
+	}
+	u.mtx.Lock()
+	defer u.mtx.Unlock()
+	sub, err := u.subscribers.Get(ctx, supi)
+	if err != nil {
+		return err
+	}
+	if err := fn(&sub); err != nil {
+		return err
+	}
+	return u.subscribers.Put(ctx, sub)
+}
+
+// Implement the business logic of CreateSubscriber
+func (u *stubUdmService) CreateSubscriber(ctx context.Context, sub subscriber.Subscriber) (res subscriber.Subscriber, err error) {
+	u.mtx.Lock()
+	defer u.mtx.Unlock()
+	if err := u.subscribers.Create(ctx, sub); err != nil {
+		return res, err
+	}
+	res, err = u.subscribers.Get(ctx, sub.SUPI)
+	return res.Redacted(), err
+}
+
+// Implement the business logic of UpdateSubscriber. The fields left empty in
+// sub keep their provisioned value, so that SQN in particular is not reset by
+// an update that does not ask for it.
+func (u *stubUdmService) UpdateSubscriber(ctx context.Context, sub subscriber.Subscriber) (res subscriber.Subscriber, err error) {
+	err = u.update(ctx, sub.SUPI, func(cur *subscriber.Subscriber) error {
+		merge(cur, sub)
+		return nil
+	})
+	if err != nil {
+		return res, err
+	}
+	res, err = u.subscribers.Get(ctx, sub.SUPI)
+	return res.Redacted(), err
+}
+
+// merge sets the fields of cur that sub has.
+func merge(cur *subscriber.Subscriber, sub subscriber.Subscriber) {
+	if len(sub.K) != 0 {
+		cur.K = sub.K
+	}
+	if len(sub.OPc) != 0 {
+		cur.OPc = sub.OPc
+	} else if len(sub.OP) != 0 {
+		cur.OP, cur.OPc = sub.OP, nil
+	}
+	if len(sub.AMF) != 0 {
+		cur.AMF = sub.AMF
+	}
+	if len(sub.SQN) != 0 {
+		cur.SQN = sub.SQN
+	}
+	if sub.DefaultSNSSAI != nil {
+		cur.DefaultSNSSAI = sub.DefaultSNSSAI
+	}
+	if sub.AMBR != nil {
+		cur.AMBR = sub.AMBR
+	}
+}
+
+// Implement the business logic of DeleteSubscriber
+func (u *stubUdmService) DeleteSubscriber(ctx context.Context, supi string) (err error) {
+	u.mtx.Lock()
+	defer u.mtx.Unlock()
+	if _, err := u.subscribers.Get(ctx, supi); err != nil {
+		return err
+	}
+	return u.subscribers.Delete(ctx, supi)
+}
+
+// Implement the business logic of ListSubscribers
+func (u *stubUdmService) ListSubscribers(ctx context.Context, pageSize int, pageToken string) (subs []subscriber.Subscriber, nextPageToken string, err error) {
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+	if pageSize > MaxPageSize {
+		pageSize = MaxPageSize
+	}
+	subs, nextPageToken, err = u.subscribers.List(ctx, pageSize, pageToken)
+	for i := range subs {
+		subs[i] = subs[i].Redacted()
+	}
+	return subs, nextPageToken, err
+}
+
+// Implement the business logic of ConfirmAuth
+func (u *stubUdmService) ConfirmAuth(ctx context.Context, supi string, servingNetworkName string, success bool) (err error) {
+	if servingNetworkName == "" {
+		return ErrServingNetworkName
+	}
+	if _, err := u.subscribers.Get(ctx, supi); err != nil {
+		return err
+	}
+	e := history.Event{
+		Type:    history.AuthResult,
+		Outcome: history.Success,
+		Detail:  map[string]string{"serving_network_name": servingNetworkName},
+	}
+	if !success {
+		e.Outcome = history.Failure
+	}
+	e.Source, _ = caller.FromContext(ctx)
+	return u.history.Append(ctx, supi, e)
+}
+
+// Implement the business logic of GetUEHistory. The history of a UE outlives
+// its subscription, so that of a deleted subscriber can still be read.
+func (u *stubUdmService) GetUEHistory(ctx context.Context, supi string, since, until time.Time, pageSize int, pageToken string) (events []history.Event, nextPageToken string, err error) {
+	return u.history.Query(ctx, supi, since, until, pageSize, pageToken)
+}
+
+// record appends an event of the UE to its history. A history that cannot
+// be written does not fail the procedure; it is only logged.
+func (u *stubUdmService) record(ctx context.Context, supi string, typ history.Type, err error, detail map[string]string) {
+	e := history.Event{Type: typ, Outcome: history.Success, Detail: detail}
+	if err != nil {
+		e.Outcome = history.Failure
+		e.Detail["err"] = status.Convert(err).Message()
+	}
+	e.Source, _ = caller.FromContext(ctx)
+	if err := u.history.Append(ctx, supi, e); err != nil {
+		u.logger.Log("supi", supi, "history", typ, "err", err)
+	}
+}
+
+// verifyResync checks the AUTS = SQN_MS xor AK* || MAC-S of a UE and returns
+// SQN_MS. MAC-S is computed over a zero AMF (TS 33.102 6.3.3).
+func verifyResync(m *milenage.Milenage, resync *Resync) (uint64, error) {
+	if len(resync.Rand) != milenage.RandSize || len(resync.Auts) != milenage.SQNSize+milenage.MACSize {
+		return 0, ErrResyncInfo
+	}
+	ak, err := m.F5Star(resync.Rand)
+	if err != nil {
+		return 0, err
+	}
+	sqnMS := make([]byte, milenage.SQNSize)
+	for i := range sqnMS {
+		sqnMS[i] = resync.Auts[i] ^ ak[i]
+	}
+	macS, err := m.F1Star(resync.Rand, sqnMS, make([]byte, milenage.AMFSize))
+	if err != nil {
+		return 0, err
+	}
+	for i := range macS {
+		if macS[i] != resync.Auts[milenage.SQNSize+i] {
+			return 0, ErrResync
+		}
+	}
+	return subscriber.SQNUint(sqnMS), nil
+}
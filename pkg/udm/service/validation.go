@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"regexp"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/flags"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/history"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/plmn"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/udm/subscriber"
+)
+
+// StrictValidation is the flag turning the strict validation of the
+// requests on.
+const StrictValidation = "strict_validation"
+
+var (
+	// ErrStrictSUPI is returned, under strict validation, for a SUPI that
+	// is not an IMSI based one (TS 29.571 5.3.2).
+	ErrStrictSUPI = status.Error(codes.InvalidArgument, "supi must be imsi- followed by 5 to 15 digits")
+	// ErrStrictServingNetworkName is returned, under strict validation, for
+	// a malformed serving network name (TS 24.501 9.12.1).
+	ErrStrictServingNetworkName = status.Error(codes.InvalidArgument, "serving network name must be 5G:mncXXX.mccXXX.3gppnetwork.org")
+	// ErrStrictAMBR is returned, under strict validation, for a bit rate of
+	// the AMBR that is not written as in TS 29.571, such as "1 Gbps".
+	ErrStrictAMBR = status.Error(codes.InvalidArgument, "ambr bit rates must be written such as 1 Gbps")
+)
+
+var (
+	supiPattern    = regexp.MustCompile(`^imsi-[0-9]{5,15}$`)
+	bitRatePattern = regexp.MustCompile(`^\d+(\.\d+)? (bps|Kbps|Mbps|Gbps|Tbps)$`)
+)
+
+type validationMiddleware struct {
+	flags flags.Client
+	next  UdmService
+}
+
+// ValidationMiddleware checks the requests of the UEs the StrictValidation
+// flag of fl is on for against the formats of the 3GPP specifications,
+// rather than only for the fields the service needs. The flag is evaluated
+// for the PLMN of the request, the default slice of the subscriber when
+// provisioning it, and the SUPI.
+func ValidationMiddleware(fl flags.Client) Middleware {
+	return func(next UdmService) UdmService {
+		return validationMiddleware{fl, next}
+	}
+}
+
+func (vm validationMiddleware) strict(ctx context.Context, supi string, slice *subscriber.SNSSAI) bool {
+	t := flags.Target{Key: supi}
+	t.PLMN, _ = plmn.FromContext(ctx)
+	if slice != nil {
+		t.Slice = slice.String()
+	}
+	return vm.flags.Enabled(ctx, StrictValidation, t)
+}
+
+func validateSUPI(supi string) error {
+	if !supiPattern.MatchString(supi) {
+		return ErrStrictSUPI
+	}
+	return nil
+}
+
+func validateServingNetworkName(snn string) error {
+	if _, err := plmn.FromServingNetworkName(snn); err != nil {
+		return ErrStrictServingNetworkName
+	}
+	return nil
+}
+
+func validateSubscriber(sub subscriber.Subscriber) error {
+	if err := validateSUPI(sub.SUPI); err != nil {
+		return err
+	}
+	if sub.AMBR != nil && (!bitRatePattern.MatchString(sub.AMBR.Uplink) || !bitRatePattern.MatchString(sub.AMBR.Downlink)) {
+		return ErrStrictAMBR
+	}
+	return nil
+}
+
+func (vm validationMiddleware) GenerateAuthData(ctx context.Context, supi string, servingNetworkName string, resync *Resync) (av AuthVector, err error) {
+	if vm.strict(ctx, supi, nil) {
+		if err := validateSUPI(supi); err != nil {
+			return av, err
+		}
+		if err := validateServingNetworkName(servingNetworkName); err != nil {
+			return av, err
+		}
+	}
+	return vm.next.GenerateAuthData(ctx, supi, servingNetworkName, resync)
+}
+
+func (vm validationMiddleware) CreateSubscriber(ctx context.Context, sub subscriber.Subscriber) (res subscriber.Subscriber, err error) {
+	if vm.strict(ctx, sub.SUPI, sub.DefaultSNSSAI) {
+		if err := validateSubscriber(sub); err != nil {
+			return res, err
+		}
+	}
+	return vm.next.CreateSubscriber(ctx, sub)
+}
+
+func (vm validationMiddleware) UpdateSubscriber(ctx context.Context, sub subscriber.Subscriber) (res subscriber.Subscriber, err error) {
+	if vm.strict(ctx, sub.SUPI, sub.DefaultSNSSAI) {
+		if err := validateSubscriber(sub); err != nil {
+			return res, err
+		}
+	}
+	return vm.next.UpdateSubscriber(ctx, sub)
+}
+
+func (vm validationMiddleware) DeleteSubscriber(ctx context.Context, supi string) (err error) {
+	return vm.next.DeleteSubscriber(ctx, supi)
+}
+
+func (vm validationMiddleware) ListSubscribers(ctx context.Context, pageSize int, pageToken string) (subs []subscriber.Subscriber, nextPageToken string, err error) {
+	return vm.next.ListSubscribers(ctx, pageSize, pageToken)
+}
+
+func (vm validationMiddleware) ConfirmAuth(ctx context.Context, supi string, servingNetworkName string, success bool) (err error) {
+	if vm.strict(ctx, supi, nil) {
+		if err := validateServingNetworkName(servingNetworkName); err != nil {
+			return err
+		}
+	}
+	return vm.next.ConfirmAuth(ctx, supi, servingNetworkName, success)
+}
+
+func (vm validationMiddleware) GetUEHistory(ctx context.Context, supi string, since, until time.Time, pageSize int, pageToken string) (events []history.Event, nextPageToken string, err error) {
+	return vm.next.GetUEHistory(ctx, supi, since, until, pageSize, pageToken)
+}
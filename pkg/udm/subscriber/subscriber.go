@@ -0,0 +1,290 @@
+// Package subscriber holds the subscription data the UDM serves: the
+// long-term key K, the operator variant OPc and the sequence number of every
+// subscriber, keyed by SUPI.
+package subscriber
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/identity"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/schema"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/security/milenage"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/store"
+)
+
+// KeyPrefix is prepended to the SUPI to build the store key, the keys of
+// the subscribers all starting with it.
+const KeyPrefix = "subscriber/"
+
+// Schema versions the subscribers in the store.
+var Schema = &schema.Schema{Name: "subscriber", Prefix: KeyPrefix}
+
+// DefaultAMF is the authentication management field used when a subscriber
+// has none. Its separation bit is set, as 5G authentication requires.
+var DefaultAMF = Hex{0x80, 0x00}
+
+var (
+	// ErrNotFound is returned for an unknown SUPI.
+	ErrNotFound = status.Error(codes.NotFound, "subscriber not found")
+	// ErrInvalid is returned when a subscriber is missing K or OP/OPc, or
+	// carries a field of the wrong size.
+	ErrInvalid = status.Error(codes.InvalidArgument, "invalid subscriber")
+	// ErrExists is returned when creating a subscriber whose SUPI is
+	// already provisioned.
+	ErrExists = status.Error(codes.AlreadyExists, "subscriber already exists")
+	// ErrPageToken is returned for a page token not issued by List.
+	ErrPageToken = status.Error(codes.InvalidArgument, "invalid page token")
+)
+
+// Hex is a byte string written as hex in JSON.
+type Hex []byte
+
+// MarshalJSON implements json.Marshaler.
+func (h Hex) MarshalJSON() ([]byte, error) {
+	return json.Marshal(hex.EncodeToString(h))
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (h *Hex) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return err
+	}
+	*h = b
+	return nil
+}
+
+// Subscriber is the subscription of one UE.
+type Subscriber struct {
+	SUPI string `json:"supi"`
+	K    Hex    `json:"k,omitempty"`
+	// OP is only used to derive OPc when OPc is not given; it is not
+	// stored.
+	OP  Hex `json:"op,omitempty"`
+	OPc Hex `json:"opc,omitempty"`
+	AMF Hex `json:"amf"`
+	SQN Hex `json:"sqn"`
+	// DefaultSNSSAI is the slice the UE is put on when it requests none.
+	DefaultSNSSAI *SNSSAI `json:"default_snssai,omitempty"`
+	// AMBR is the aggregate bit rate of the subscription.
+	AMBR *AMBR `json:"ambr,omitempty"`
+}
+
+// SNSSAI identifies a network slice (TS 23.003 28.4.2).
+type SNSSAI = identity.SNSSAI
+
+// AMBR is an aggregate maximum bit rate, written as in TS 29.571, for
+// instance "1 Gbps".
+type AMBR struct {
+	Uplink   string `json:"uplink"`
+	Downlink string `json:"downlink"`
+}
+
+// Redacted returns s without its secrets, as reported to provisioning
+// clients.
+func (s Subscriber) Redacted() Subscriber {
+	s.K, s.OP, s.OPc = nil, nil, nil
+	return s
+}
+
+// Normalize checks s, derives OPc from OP and fills the defaults.
+func (s *Subscriber) Normalize() error {
+	if s.SUPI == "" || len(s.K) != milenage.KeySize {
+		return ErrInvalid
+	}
+	if len(s.OPc) == 0 && len(s.OP) != 0 {
+		opc, err := milenage.OPc(s.K, s.OP)
+		if err != nil {
+			return ErrInvalid
+		}
+		s.OPc = opc
+	}
+	s.OP = nil
+	if len(s.OPc) != milenage.KeySize {
+		return ErrInvalid
+	}
+	if len(s.AMF) == 0 {
+		s.AMF = DefaultAMF
+	}
+	if len(s.SQN) == 0 {
+		s.SQN = make(Hex, milenage.SQNSize)
+	}
+	if len(s.AMF) != milenage.AMFSize || len(s.SQN) != milenage.SQNSize {
+		return ErrInvalid
+	}
+	if s.DefaultSNSSAI != nil && s.DefaultSNSSAI.SD != "" {
+		if sd, err := hex.DecodeString(s.DefaultSNSSAI.SD); err != nil || len(sd) != 3 {
+			return ErrInvalid
+		}
+		s.DefaultSNSSAI.SD = strings.ToLower(s.DefaultSNSSAI.SD)
+	}
+	return nil
+}
+
+// SQNUint returns the sequence number as an integer.
+func SQNUint(sqn []byte) uint64 {
+	var b [8]byte
+	copy(b[2:], sqn)
+	return binary.BigEndian.Uint64(b[:])
+}
+
+// SQNBytes returns the 48-bit sequence number n.
+func SQNBytes(n uint64) Hex {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], n&(1<<48-1))
+	return Hex(b[2:])
+}
+
+//go:generate mockgen -destination=../../testutil/mocks/subscriber_repository.go -package=mocks github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/udm/subscriber Repository
+
+// Repository stores the subscribers.
+type Repository interface {
+	Get(ctx context.Context, supi string) (Subscriber, error)
+	Put(ctx context.Context, s Subscriber) error
+	// Create puts s only when its SUPI is not provisioned yet.
+	Create(ctx context.Context, s Subscriber) error
+	Delete(ctx context.Context, supi string) error
+	// List returns up to pageSize subscribers in SUPI order, starting after
+	// pageToken, and the token of the next page, empty on the last one.
+	List(ctx context.Context, pageSize int, pageToken string) ([]Subscriber, string, error)
+}
+
+// Transactional is a Repository updating the subscribers in transactions,
+// atomic across the replicas sharing it.
+type Transactional interface {
+	Repository
+	// Update applies fn to the subscriber of supi and puts it, unless fn
+	// fails, in a transaction.
+	Update(ctx context.Context, supi string, fn func(s *Subscriber) error) error
+	// PutAll puts every subscriber of subscribers, or none of them.
+	PutAll(ctx context.Context, subscribers []Subscriber) error
+}
+
+type repository struct {
+	store store.Store
+}
+
+// NewRepository returns a Repository keeping the subscribers in st.
+func NewRepository(st store.Store) Repository {
+	return &repository{store: st}
+}
+
+func (r *repository) Get(ctx context.Context, supi string) (Subscriber, error) {
+	var s Subscriber
+	data, err := r.store.Get(ctx, KeyPrefix+supi)
+	if err == store.ErrNotFound {
+		return s, ErrNotFound
+	}
+	if err != nil {
+		return s, err
+	}
+	err = json.Unmarshal(data, &s)
+	return s, err
+}
+
+func (r *repository) Put(ctx context.Context, s Subscriber) error {
+	if err := s.Normalize(); err != nil {
+		return err
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return r.store.Set(ctx, KeyPrefix+s.SUPI, data, 0)
+}
+
+func (r *repository) Create(ctx context.Context, s Subscriber) error {
+	if err := s.Normalize(); err != nil {
+		return err
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	ok, err := r.store.SetNX(ctx, KeyPrefix+s.SUPI, data, 0)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrExists
+	}
+	return nil
+}
+
+func (r *repository) Delete(ctx context.Context, supi string) error {
+	return r.store.Delete(ctx, KeyPrefix+supi)
+}
+
+// List uses the last SUPI of a page as the token of the next one, so pages
+// stay consistent while subscribers are added or removed.
+func (r *repository) List(ctx context.Context, pageSize int, pageToken string) ([]Subscriber, string, error) {
+	var after string
+	if pageToken != "" {
+		b, err := hex.DecodeString(pageToken)
+		if err != nil {
+			return nil, "", ErrPageToken
+		}
+		after = string(b)
+	}
+	keys, err := r.store.Keys(ctx, KeyPrefix)
+	if err != nil {
+		return nil, "", err
+	}
+	i := sort.SearchStrings(keys, KeyPrefix+after)
+	if i < len(keys) && keys[i] == KeyPrefix+after {
+		i++
+	}
+
+	var subscribers []Subscriber
+	for ; i < len(keys) && len(subscribers) < pageSize; i++ {
+		s, err := r.Get(ctx, strings.TrimPrefix(keys[i], KeyPrefix))
+		if err == ErrNotFound {
+			// deleted since Keys
+			continue
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		subscribers = append(subscribers, s)
+	}
+	var next string
+	if i < len(keys) && len(subscribers) > 0 {
+		next = hex.EncodeToString([]byte(subscribers[len(subscribers)-1].SUPI))
+	}
+	return subscribers, next, nil
+}
+
+// Load puts every subscriber of the JSON array data into r, in one
+// transaction when r is Transactional.
+func Load(ctx context.Context, r Repository, data []byte) (n int, err error) {
+	var subscribers []Subscriber
+	if err := json.Unmarshal(data, &subscribers); err != nil {
+		return 0, err
+	}
+	if t, ok := r.(Transactional); ok {
+		if err := t.PutAll(ctx, subscribers); err != nil {
+			return 0, err
+		}
+		return len(subscribers), nil
+	}
+	for _, s := range subscribers {
+		if err := r.Put(ctx, s); err != nil {
+			return n, fmt.Errorf("%s: %s", s.SUPI, status.Convert(err).Message())
+		}
+		n++
+	}
+	return n, nil
+}
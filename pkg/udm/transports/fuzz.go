@@ -0,0 +1,49 @@
+package transports
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/log"
+	stdopentracing "github.com/opentracing/opentracing-go"
+	"github.com/openzipkin/zipkin-go"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/kitx/fuzz"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/udm/endpoints"
+)
+
+var (
+	fuzzOnce    sync.Once
+	fuzzHandler http.Handler
+)
+
+// newFuzzHandler returns the handler of both versions of the API, with
+// every codec, over endpoints answering the zero response of their method.
+func newFuzzHandler() http.Handler {
+	zipkinTracer, _ := zipkin.NewTracer(nil, zipkin.WithNoopTracer(true))
+	return NewHTTPHandler(endpoints.Endpoints{
+		GenerateAuthDataEndpoint: answer(endpoints.GenerateAuthDataResponse{}),
+		CreateSubscriberEndpoint: answer(endpoints.CreateSubscriberResponse{}),
+		UpdateSubscriberEndpoint: answer(endpoints.UpdateSubscriberResponse{}),
+		DeleteSubscriberEndpoint: answer(endpoints.DeleteSubscriberResponse{}),
+		ListSubscribersEndpoint:  answer(endpoints.ListSubscribersResponse{}),
+		ConfirmAuthEndpoint:      answer(endpoints.ConfirmAuthResponse{}),
+		GetUEHistoryEndpoint:     answer(endpoints.GetUEHistoryResponse{}),
+	}, stdopentracing.NoopTracer{}, zipkinTracer, log.NewNopLogger())
+}
+
+// fuzzHTTP serves data, in the format of fuzz.HTTPSeed, to the HTTP API.
+// The bodies of the protobuf content types go through the request decoders
+// of the gRPC transports as well.
+func fuzzHTTP(data []byte) int {
+	fuzzOnce.Do(func() { fuzzHandler = newFuzzHandler() })
+	return fuzz.Serve(fuzzHandler, data)
+}
+
+func answer(response interface{}) endpoint.Endpoint {
+	return func(context.Context, interface{}) (interface{}, error) {
+		return response, nil
+	}
+}
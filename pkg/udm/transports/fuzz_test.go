@@ -0,0 +1,19 @@
+package transports
+
+import (
+	"testing"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/kitx/fuzz"
+)
+
+func TestFuzzHTTPCorpus(t *testing.T) {
+	seeds, err := fuzz.Corpus("FuzzHTTP")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range seeds {
+		if score := fuzzHTTP(data); score == fuzz.Discard {
+			t.Errorf("seed %s discarded", name)
+		}
+	}
+}
@@ -0,0 +1,647 @@
+package transports
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/kit/circuitbreaker"
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/ratelimit"
+	"github.com/go-kit/kit/tracing/opentracing"
+	"github.com/go-kit/kit/tracing/zipkin"
+	grpctransport "github.com/go-kit/kit/transport/grpc"
+	stdopentracing "github.com/opentracing/opentracing-go"
+	stdzipkin "github.com/openzipkin/zipkin-go"
+	"github.com/sony/gobreaker"
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/miki-tnt/sa5g-go-usvc-k8s/pb/udm"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/breakers"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/caller"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/grpcpool"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/hedge"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/history"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/logging"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/meta"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/plmn"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/priority"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/udm/endpoints"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/udm/service"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/udm/subscriber"
+)
+
+type grpcServer struct {
+	generateAuthData grpctransport.Handler
+	createSubscriber grpctransport.Handler
+	updateSubscriber grpctransport.Handler
+	deleteSubscriber grpctransport.Handler
+	listSubscribers  grpctransport.Handler
+	confirmAuth      grpctransport.Handler
+	getUEHistory     grpctransport.Handler
+}
+
+func (s *grpcServer) GenerateAuthData(ctx context.Context, req *pb.GenerateAuthDataRequest) (rep *pb.GenerateAuthDataReply, err error) {
+	_, rp, err := s.generateAuthData.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcEncodeError(err)
+	}
+	rep = rp.(*pb.GenerateAuthDataReply)
+	return rep, nil
+}
+
+func (s *grpcServer) CreateSubscriber(ctx context.Context, req *pb.CreateSubscriberRequest) (rep *pb.CreateSubscriberReply, err error) {
+	_, rp, err := s.createSubscriber.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcEncodeError(err)
+	}
+	rep = rp.(*pb.CreateSubscriberReply)
+	return rep, nil
+}
+
+func (s *grpcServer) UpdateSubscriber(ctx context.Context, req *pb.UpdateSubscriberRequest) (rep *pb.UpdateSubscriberReply, err error) {
+	_, rp, err := s.updateSubscriber.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcEncodeError(err)
+	}
+	rep = rp.(*pb.UpdateSubscriberReply)
+	return rep, nil
+}
+
+func (s *grpcServer) DeleteSubscriber(ctx context.Context, req *pb.DeleteSubscriberRequest) (rep *pb.DeleteSubscriberReply, err error) {
+	_, rp, err := s.deleteSubscriber.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcEncodeError(err)
+	}
+	rep = rp.(*pb.DeleteSubscriberReply)
+	return rep, nil
+}
+
+func (s *grpcServer) ListSubscribers(ctx context.Context, req *pb.ListSubscribersRequest) (rep *pb.ListSubscribersReply, err error) {
+	_, rp, err := s.listSubscribers.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcEncodeError(err)
+	}
+	rep = rp.(*pb.ListSubscribersReply)
+	return rep, nil
+}
+
+func (s *grpcServer) ConfirmAuth(ctx context.Context, req *pb.ConfirmAuthRequest) (rep *pb.ConfirmAuthReply, err error) {
+	_, rp, err := s.confirmAuth.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcEncodeError(err)
+	}
+	rep = rp.(*pb.ConfirmAuthReply)
+	return rep, nil
+}
+
+func (s *grpcServer) GetUEHistory(ctx context.Context, req *pb.GetUEHistoryRequest) (rep *pb.GetUEHistoryReply, err error) {
+	_, rp, err := s.getUEHistory.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcEncodeError(err)
+	}
+	rep = rp.(*pb.GetUEHistoryReply)
+	return rep, nil
+}
+
+// MakeGRPCServer makes a set of endpoints available as a gRPC server.
+func MakeGRPCServer(endpoints endpoints.Endpoints, otTracer stdopentracing.Tracer, zipkinTracer *stdzipkin.Tracer, logger log.Logger) (req pb.UdmServer) {
+	zipkinServer := zipkin.GRPCServerTrace(zipkinTracer)
+
+	options := []grpctransport.ServerOption{
+		grpctransport.ServerErrorHandler(logging.ErrorHandler(logger)),
+		zipkinServer,
+		grpctransport.ServerBefore(plmn.GRPCToContext()),
+		grpctransport.ServerBefore(priority.GRPCToContext()),
+		grpctransport.ServerBefore(caller.GRPCToContext()),
+		grpctransport.ServerBefore(meta.GRPCToContext()),
+	}
+
+	return &grpcServer{
+		generateAuthData: grpctransport.NewServer(
+			endpoints.GenerateAuthDataEndpoint,
+			decodeGRPCGenerateAuthDataRequest,
+			encodeGRPCGenerateAuthDataResponse,
+			append(options, grpctransport.ServerBefore(opentracing.GRPCToContext(otTracer, "GenerateAuthData", logger)))...,
+		),
+		createSubscriber: grpctransport.NewServer(
+			endpoints.CreateSubscriberEndpoint,
+			decodeGRPCCreateSubscriberRequest,
+			encodeGRPCCreateSubscriberResponse,
+			append(options, grpctransport.ServerBefore(opentracing.GRPCToContext(otTracer, "CreateSubscriber", logger)))...,
+		),
+		updateSubscriber: grpctransport.NewServer(
+			endpoints.UpdateSubscriberEndpoint,
+			decodeGRPCUpdateSubscriberRequest,
+			encodeGRPCUpdateSubscriberResponse,
+			append(options, grpctransport.ServerBefore(opentracing.GRPCToContext(otTracer, "UpdateSubscriber", logger)))...,
+		),
+		deleteSubscriber: grpctransport.NewServer(
+			endpoints.DeleteSubscriberEndpoint,
+			decodeGRPCDeleteSubscriberRequest,
+			encodeGRPCDeleteSubscriberResponse,
+			append(options, grpctransport.ServerBefore(opentracing.GRPCToContext(otTracer, "DeleteSubscriber", logger)))...,
+		),
+		listSubscribers: grpctransport.NewServer(
+			endpoints.ListSubscribersEndpoint,
+			decodeGRPCListSubscribersRequest,
+			encodeGRPCListSubscribersResponse,
+			append(options, grpctransport.ServerBefore(opentracing.GRPCToContext(otTracer, "ListSubscribers", logger)))...,
+		),
+		confirmAuth: grpctransport.NewServer(
+			endpoints.ConfirmAuthEndpoint,
+			decodeGRPCConfirmAuthRequest,
+			encodeGRPCConfirmAuthResponse,
+			append(options, grpctransport.ServerBefore(opentracing.GRPCToContext(otTracer, "ConfirmAuth", logger)))...,
+		),
+		getUEHistory: grpctransport.NewServer(
+			endpoints.GetUEHistoryEndpoint,
+			decodeGRPCGetUEHistoryRequest,
+			encodeGRPCGetUEHistoryResponse,
+			append(options, grpctransport.ServerBefore(opentracing.GRPCToContext(otTracer, "GetUEHistory", logger)))...,
+		),
+	}
+}
+
+// decodeGRPCGenerateAuthDataRequest is a transport/grpc.DecodeRequestFunc that converts a
+// gRPC request to a user-domain request. Primarily useful in a server.
+func decodeGRPCGenerateAuthDataRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pb.GenerateAuthDataRequest)
+	r := endpoints.GenerateAuthDataRequest{SUPI: req.Supi, ServingNetworkName: req.ServingNetworkName}
+	if req.Resync != nil {
+		r.Resync = &endpoints.ResyncInfo{Rand: req.Resync.Rand, Auts: req.Resync.Auts}
+	}
+	return r, nil
+}
+
+// encodeGRPCGenerateAuthDataResponse is a transport/grpc.EncodeResponseFunc that converts a
+// user-domain response to a gRPC reply. Primarily useful in a server.
+func encodeGRPCGenerateAuthDataResponse(_ context.Context, grpcReply interface{}) (res interface{}, err error) {
+	reply := grpcReply.(endpoints.GenerateAuthDataResponse)
+	return &pb.GenerateAuthDataReply{Rand: reply.Rand, Autn: reply.Autn, XresStar: reply.XresStar, Kausf: reply.Kausf}, grpcEncodeError(reply.Err)
+}
+
+// decodeGRPCCreateSubscriberRequest is a transport/grpc.DecodeRequestFunc that converts a
+// gRPC request to a user-domain request. Primarily useful in a server.
+func decodeGRPCCreateSubscriberRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pb.CreateSubscriberRequest)
+	return endpoints.CreateSubscriberRequest{Subscriber: subscriberFromPB(req.Subscriber)}, nil
+}
+
+// encodeGRPCCreateSubscriberResponse is a transport/grpc.EncodeResponseFunc that converts a
+// user-domain response to a gRPC reply. Primarily useful in a server.
+func encodeGRPCCreateSubscriberResponse(_ context.Context, grpcReply interface{}) (res interface{}, err error) {
+	reply := grpcReply.(endpoints.CreateSubscriberResponse)
+	return &pb.CreateSubscriberReply{Subscriber: subscriberToPB(reply.Subscriber)}, grpcEncodeError(reply.Err)
+}
+
+// decodeGRPCUpdateSubscriberRequest is a transport/grpc.DecodeRequestFunc that converts a
+// gRPC request to a user-domain request. Primarily useful in a server.
+func decodeGRPCUpdateSubscriberRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pb.UpdateSubscriberRequest)
+	return endpoints.UpdateSubscriberRequest{Subscriber: subscriberFromPB(req.Subscriber)}, nil
+}
+
+// encodeGRPCUpdateSubscriberResponse is a transport/grpc.EncodeResponseFunc that converts a
+// user-domain response to a gRPC reply. Primarily useful in a server.
+func encodeGRPCUpdateSubscriberResponse(_ context.Context, grpcReply interface{}) (res interface{}, err error) {
+	reply := grpcReply.(endpoints.UpdateSubscriberResponse)
+	return &pb.UpdateSubscriberReply{Subscriber: subscriberToPB(reply.Subscriber)}, grpcEncodeError(reply.Err)
+}
+
+// decodeGRPCDeleteSubscriberRequest is a transport/grpc.DecodeRequestFunc that converts a
+// gRPC request to a user-domain request. Primarily useful in a server.
+func decodeGRPCDeleteSubscriberRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pb.DeleteSubscriberRequest)
+	return endpoints.DeleteSubscriberRequest{SUPI: req.Supi}, nil
+}
+
+// encodeGRPCDeleteSubscriberResponse is a transport/grpc.EncodeResponseFunc that converts a
+// user-domain response to a gRPC reply. Primarily useful in a server.
+func encodeGRPCDeleteSubscriberResponse(_ context.Context, grpcReply interface{}) (res interface{}, err error) {
+	reply := grpcReply.(endpoints.DeleteSubscriberResponse)
+	return &pb.DeleteSubscriberReply{}, grpcEncodeError(reply.Err)
+}
+
+// decodeGRPCListSubscribersRequest is a transport/grpc.DecodeRequestFunc that converts a
+// gRPC request to a user-domain request. Primarily useful in a server.
+func decodeGRPCListSubscribersRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pb.ListSubscribersRequest)
+	return endpoints.ListSubscribersRequest{PageSize: int(req.PageSize), PageToken: req.PageToken}, nil
+}
+
+// encodeGRPCListSubscribersResponse is a transport/grpc.EncodeResponseFunc that converts a
+// user-domain response to a gRPC reply. Primarily useful in a server.
+func encodeGRPCListSubscribersResponse(_ context.Context, grpcReply interface{}) (res interface{}, err error) {
+	reply := grpcReply.(endpoints.ListSubscribersResponse)
+	rep := &pb.ListSubscribersReply{NextPageToken: reply.NextPageToken}
+	for _, sub := range reply.Subscribers {
+		rep.Subscribers = append(rep.Subscribers, subscriberToPB(sub))
+	}
+	return rep, grpcEncodeError(reply.Err)
+}
+
+// decodeGRPCConfirmAuthRequest is a transport/grpc.DecodeRequestFunc that converts a
+// gRPC request to a user-domain request. Primarily useful in a server.
+func decodeGRPCConfirmAuthRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pb.ConfirmAuthRequest)
+	return endpoints.ConfirmAuthRequest{SUPI: req.Supi, ServingNetworkName: req.ServingNetworkName, Success: req.Success}, nil
+}
+
+// encodeGRPCConfirmAuthResponse is a transport/grpc.EncodeResponseFunc that converts a
+// user-domain response to a gRPC reply. Primarily useful in a server.
+func encodeGRPCConfirmAuthResponse(_ context.Context, grpcReply interface{}) (res interface{}, err error) {
+	reply := grpcReply.(endpoints.ConfirmAuthResponse)
+	return &pb.ConfirmAuthReply{}, grpcEncodeError(reply.Err)
+}
+
+// decodeGRPCGetUEHistoryRequest is a transport/grpc.DecodeRequestFunc that converts a
+// gRPC request to a user-domain request. Primarily useful in a server.
+func decodeGRPCGetUEHistoryRequest(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pb.GetUEHistoryRequest)
+	return endpoints.GetUEHistoryRequest{
+		SUPI:      req.Supi,
+		Since:     timeFromPB(req.Since),
+		Until:     timeFromPB(req.Until),
+		PageSize:  int(req.PageSize),
+		PageToken: req.PageToken,
+	}, nil
+}
+
+// encodeGRPCGetUEHistoryResponse is a transport/grpc.EncodeResponseFunc that converts a
+// user-domain response to a gRPC reply. Primarily useful in a server.
+func encodeGRPCGetUEHistoryResponse(_ context.Context, grpcReply interface{}) (res interface{}, err error) {
+	reply := grpcReply.(endpoints.GetUEHistoryResponse)
+	rep := &pb.GetUEHistoryReply{NextPageToken: reply.NextPageToken}
+	for _, e := range reply.Events {
+		rep.Events = append(rep.Events, eventToPB(e))
+	}
+	return rep, grpcEncodeError(reply.Err)
+}
+
+// NewGRPCClient returns an UdmService backed by a gRPC server at the other end
+// of the conn. The caller is responsible for constructing the conn, and
+// eventually closing the underlying transport. We bake-in certain middlewares,
+// implementing the client library pattern. The reads, ListSubscribers and
+// GetUEHistory, are hedged as set by hedging; hedge.MaxRatio(0) turns it off.
+func NewGRPCClient(conn *grpc.ClientConn, otTracer stdopentracing.Tracer, zipkinTracer *stdzipkin.Tracer, logger log.Logger, hedging ...hedge.Option) service.UdmService {
+	return NewGRPCPoolClient(grpcpool.FromConn(conn), otTracer, zipkinTracer, logger, hedging...)
+}
+
+// NewGRPCPoolClient is like NewGRPCClient, but spreads the calls over the
+// connections of the pool. The caller is responsible for closing the pool.
+func NewGRPCPoolClient(pool *grpcpool.Pool, otTracer stdopentracing.Tracer, zipkinTracer *stdzipkin.Tracer, logger log.Logger, hedging ...hedge.Option) service.UdmService {
+	limiter := ratelimit.NewErroringLimiter(rate.NewLimiter(rate.Every(time.Second), 100))
+	// every authentication vector is followed by the result of the
+	// authentication, which is not charged to the same budget
+	confirmLimiter := ratelimit.NewErroringLimiter(rate.NewLimiter(rate.Every(time.Second), 100))
+
+	zipkinClient := zipkin.GRPCClientTrace(zipkinTracer)
+
+	// global client middlewares
+	options := []grpctransport.ClientOption{
+		zipkinClient,
+		grpctransport.ClientBefore(plmn.ContextToGRPC()),
+		grpctransport.ClientBefore(priority.ContextToGRPC()),
+		grpctransport.ClientBefore(meta.ContextToGRPC()),
+	}
+
+	var generateAuthDataEndpoint endpoint.Endpoint
+	{
+		generateAuthDataEndpoint = pool.Endpoint(func(conn *grpc.ClientConn) endpoint.Endpoint {
+			return grpctransport.NewClient(
+				conn,
+				"pb.Udm",
+				"GenerateAuthData",
+				encodeGRPCGenerateAuthDataRequest,
+				decodeGRPCGenerateAuthDataResponse,
+				pb.GenerateAuthDataReply{},
+				append(options, grpctransport.ClientBefore(opentracing.ContextToGRPC(otTracer, logger)))...,
+			).Endpoint()
+		})
+		generateAuthDataEndpoint = opentracing.TraceClient(otTracer, "GenerateAuthData")(generateAuthDataEndpoint)
+		generateAuthDataEndpoint = limiter(generateAuthDataEndpoint)
+		generateAuthDataEndpoint = circuitbreaker.Gobreaker(breakers.New(gobreaker.Settings{
+			Name:    "GenerateAuthData",
+			Timeout: 30 * time.Second,
+		}))(generateAuthDataEndpoint)
+	}
+
+	var createSubscriberEndpoint endpoint.Endpoint
+	{
+		createSubscriberEndpoint = pool.Endpoint(func(conn *grpc.ClientConn) endpoint.Endpoint {
+			return grpctransport.NewClient(
+				conn,
+				"pb.Udm",
+				"CreateSubscriber",
+				encodeGRPCCreateSubscriberRequest,
+				decodeGRPCCreateSubscriberResponse,
+				pb.CreateSubscriberReply{},
+				append(options, grpctransport.ClientBefore(opentracing.ContextToGRPC(otTracer, logger)))...,
+			).Endpoint()
+		})
+		createSubscriberEndpoint = opentracing.TraceClient(otTracer, "CreateSubscriber")(createSubscriberEndpoint)
+		createSubscriberEndpoint = limiter(createSubscriberEndpoint)
+		createSubscriberEndpoint = circuitbreaker.Gobreaker(breakers.New(gobreaker.Settings{
+			Name:    "CreateSubscriber",
+			Timeout: 30 * time.Second,
+		}))(createSubscriberEndpoint)
+	}
+
+	var updateSubscriberEndpoint endpoint.Endpoint
+	{
+		updateSubscriberEndpoint = pool.Endpoint(func(conn *grpc.ClientConn) endpoint.Endpoint {
+			return grpctransport.NewClient(
+				conn,
+				"pb.Udm",
+				"UpdateSubscriber",
+				encodeGRPCUpdateSubscriberRequest,
+				decodeGRPCUpdateSubscriberResponse,
+				pb.UpdateSubscriberReply{},
+				append(options, grpctransport.ClientBefore(opentracing.ContextToGRPC(otTracer, logger)))...,
+			).Endpoint()
+		})
+		updateSubscriberEndpoint = opentracing.TraceClient(otTracer, "UpdateSubscriber")(updateSubscriberEndpoint)
+		updateSubscriberEndpoint = limiter(updateSubscriberEndpoint)
+		updateSubscriberEndpoint = circuitbreaker.Gobreaker(breakers.New(gobreaker.Settings{
+			Name:    "UpdateSubscriber",
+			Timeout: 30 * time.Second,
+		}))(updateSubscriberEndpoint)
+	}
+
+	var deleteSubscriberEndpoint endpoint.Endpoint
+	{
+		deleteSubscriberEndpoint = pool.Endpoint(func(conn *grpc.ClientConn) endpoint.Endpoint {
+			return grpctransport.NewClient(
+				conn,
+				"pb.Udm",
+				"DeleteSubscriber",
+				encodeGRPCDeleteSubscriberRequest,
+				decodeGRPCDeleteSubscriberResponse,
+				pb.DeleteSubscriberReply{},
+				append(options, grpctransport.ClientBefore(opentracing.ContextToGRPC(otTracer, logger)))...,
+			).Endpoint()
+		})
+		deleteSubscriberEndpoint = opentracing.TraceClient(otTracer, "DeleteSubscriber")(deleteSubscriberEndpoint)
+		deleteSubscriberEndpoint = limiter(deleteSubscriberEndpoint)
+		deleteSubscriberEndpoint = circuitbreaker.Gobreaker(breakers.New(gobreaker.Settings{
+			Name:    "DeleteSubscriber",
+			Timeout: 30 * time.Second,
+		}))(deleteSubscriberEndpoint)
+	}
+
+	var listSubscribersEndpoint endpoint.Endpoint
+	{
+		listSubscribersEndpoint = pool.Endpoint(func(conn *grpc.ClientConn) endpoint.Endpoint {
+			return grpctransport.NewClient(
+				conn,
+				"pb.Udm",
+				"ListSubscribers",
+				encodeGRPCListSubscribersRequest,
+				decodeGRPCListSubscribersResponse,
+				pb.ListSubscribersReply{},
+				append(options, grpctransport.ClientBefore(opentracing.ContextToGRPC(otTracer, logger)))...,
+			).Endpoint()
+		})
+		listSubscribersEndpoint = hedge.Middleware(hedging...)(listSubscribersEndpoint)
+		listSubscribersEndpoint = opentracing.TraceClient(otTracer, "ListSubscribers")(listSubscribersEndpoint)
+		listSubscribersEndpoint = limiter(listSubscribersEndpoint)
+		listSubscribersEndpoint = circuitbreaker.Gobreaker(breakers.New(gobreaker.Settings{
+			Name:    "ListSubscribers",
+			Timeout: 30 * time.Second,
+		}))(listSubscribersEndpoint)
+	}
+
+	var confirmAuthEndpoint endpoint.Endpoint
+	{
+		confirmAuthEndpoint = pool.Endpoint(func(conn *grpc.ClientConn) endpoint.Endpoint {
+			return grpctransport.NewClient(
+				conn,
+				"pb.Udm",
+				"ConfirmAuth",
+				encodeGRPCConfirmAuthRequest,
+				decodeGRPCConfirmAuthResponse,
+				pb.ConfirmAuthReply{},
+				append(options, grpctransport.ClientBefore(opentracing.ContextToGRPC(otTracer, logger)))...,
+			).Endpoint()
+		})
+		confirmAuthEndpoint = opentracing.TraceClient(otTracer, "ConfirmAuth")(confirmAuthEndpoint)
+		confirmAuthEndpoint = confirmLimiter(confirmAuthEndpoint)
+		confirmAuthEndpoint = circuitbreaker.Gobreaker(breakers.New(gobreaker.Settings{
+			Name:    "ConfirmAuth",
+			Timeout: 30 * time.Second,
+		}))(confirmAuthEndpoint)
+	}
+
+	var getUEHistoryEndpoint endpoint.Endpoint
+	{
+		getUEHistoryEndpoint = pool.Endpoint(func(conn *grpc.ClientConn) endpoint.Endpoint {
+			return grpctransport.NewClient(
+				conn,
+				"pb.Udm",
+				"GetUEHistory",
+				encodeGRPCGetUEHistoryRequest,
+				decodeGRPCGetUEHistoryResponse,
+				pb.GetUEHistoryReply{},
+				append(options, grpctransport.ClientBefore(opentracing.ContextToGRPC(otTracer, logger)))...,
+			).Endpoint()
+		})
+		getUEHistoryEndpoint = hedge.Middleware(hedging...)(getUEHistoryEndpoint)
+		getUEHistoryEndpoint = opentracing.TraceClient(otTracer, "GetUEHistory")(getUEHistoryEndpoint)
+		getUEHistoryEndpoint = limiter(getUEHistoryEndpoint)
+		getUEHistoryEndpoint = circuitbreaker.Gobreaker(breakers.New(gobreaker.Settings{
+			Name:    "GetUEHistory",
+			Timeout: 30 * time.Second,
+		}))(getUEHistoryEndpoint)
+	}
+
+	return endpoints.Endpoints{
+		GenerateAuthDataEndpoint: generateAuthDataEndpoint,
+		CreateSubscriberEndpoint: createSubscriberEndpoint,
+		UpdateSubscriberEndpoint: updateSubscriberEndpoint,
+		DeleteSubscriberEndpoint: deleteSubscriberEndpoint,
+		ListSubscribersEndpoint:  listSubscribersEndpoint,
+		ConfirmAuthEndpoint:      confirmAuthEndpoint,
+		GetUEHistoryEndpoint:     getUEHistoryEndpoint,
+	}
+}
+
+// encodeGRPCGenerateAuthDataRequest is a transport/grpc.EncodeRequestFunc that converts a
+// user-domain GenerateAuthData request to a gRPC GenerateAuthData request. Primarily useful in a client.
+func encodeGRPCGenerateAuthDataRequest(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(endpoints.GenerateAuthDataRequest)
+	r := &pb.GenerateAuthDataRequest{Supi: req.SUPI, ServingNetworkName: req.ServingNetworkName}
+	if req.Resync != nil {
+		r.Resync = &pb.ResynchronizationInfo{Rand: req.Resync.Rand, Auts: req.Resync.Auts}
+	}
+	return r, nil
+}
+
+// decodeGRPCGenerateAuthDataResponse is a transport/grpc.DecodeResponseFunc that converts a
+// gRPC GenerateAuthData reply to a user-domain GenerateAuthData response. Primarily useful in a client.
+func decodeGRPCGenerateAuthDataResponse(_ context.Context, grpcReply interface{}) (interface{}, error) {
+	reply := grpcReply.(*pb.GenerateAuthDataReply)
+	return endpoints.GenerateAuthDataResponse{Rand: reply.Rand, Autn: reply.Autn, XresStar: reply.XresStar, Kausf: reply.Kausf}, nil
+}
+
+// encodeGRPCCreateSubscriberRequest is a transport/grpc.EncodeRequestFunc that converts a
+// user-domain CreateSubscriber request to a gRPC CreateSubscriber request. Primarily useful in a client.
+func encodeGRPCCreateSubscriberRequest(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(endpoints.CreateSubscriberRequest)
+	return &pb.CreateSubscriberRequest{Subscriber: subscriberToPB(req.Subscriber)}, nil
+}
+
+// decodeGRPCCreateSubscriberResponse is a transport/grpc.DecodeResponseFunc that converts a
+// gRPC CreateSubscriber reply to a user-domain CreateSubscriber response. Primarily useful in a client.
+func decodeGRPCCreateSubscriberResponse(_ context.Context, grpcReply interface{}) (interface{}, error) {
+	reply := grpcReply.(*pb.CreateSubscriberReply)
+	return endpoints.CreateSubscriberResponse{Subscriber: subscriberFromPB(reply.Subscriber)}, nil
+}
+
+// encodeGRPCUpdateSubscriberRequest is a transport/grpc.EncodeRequestFunc that converts a
+// user-domain UpdateSubscriber request to a gRPC UpdateSubscriber request. Primarily useful in a client.
+func encodeGRPCUpdateSubscriberRequest(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(endpoints.UpdateSubscriberRequest)
+	return &pb.UpdateSubscriberRequest{Subscriber: subscriberToPB(req.Subscriber)}, nil
+}
+
+// decodeGRPCUpdateSubscriberResponse is a transport/grpc.DecodeResponseFunc that converts a
+// gRPC UpdateSubscriber reply to a user-domain UpdateSubscriber response. Primarily useful in a client.
+func decodeGRPCUpdateSubscriberResponse(_ context.Context, grpcReply interface{}) (interface{}, error) {
+	reply := grpcReply.(*pb.UpdateSubscriberReply)
+	return endpoints.UpdateSubscriberResponse{Subscriber: subscriberFromPB(reply.Subscriber)}, nil
+}
+
+// encodeGRPCDeleteSubscriberRequest is a transport/grpc.EncodeRequestFunc that converts a
+// user-domain DeleteSubscriber request to a gRPC DeleteSubscriber request. Primarily useful in a client.
+func encodeGRPCDeleteSubscriberRequest(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(endpoints.DeleteSubscriberRequest)
+	return &pb.DeleteSubscriberRequest{Supi: req.SUPI}, nil
+}
+
+// decodeGRPCDeleteSubscriberResponse is a transport/grpc.DecodeResponseFunc that converts a
+// gRPC DeleteSubscriber reply to a user-domain DeleteSubscriber response. Primarily useful in a client.
+func decodeGRPCDeleteSubscriberResponse(_ context.Context, grpcReply interface{}) (interface{}, error) {
+	return endpoints.DeleteSubscriberResponse{}, nil
+}
+
+// encodeGRPCListSubscribersRequest is a transport/grpc.EncodeRequestFunc that converts a
+// user-domain ListSubscribers request to a gRPC ListSubscribers request. Primarily useful in a client.
+func encodeGRPCListSubscribersRequest(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(endpoints.ListSubscribersRequest)
+	return &pb.ListSubscribersRequest{PageSize: int32(req.PageSize), PageToken: req.PageToken}, nil
+}
+
+// decodeGRPCListSubscribersResponse is a transport/grpc.DecodeResponseFunc that converts a
+// gRPC ListSubscribers reply to a user-domain ListSubscribers response. Primarily useful in a client.
+func decodeGRPCListSubscribersResponse(_ context.Context, grpcReply interface{}) (interface{}, error) {
+	reply := grpcReply.(*pb.ListSubscribersReply)
+	resp := endpoints.ListSubscribersResponse{NextPageToken: reply.NextPageToken}
+	for _, sub := range reply.Subscribers {
+		resp.Subscribers = append(resp.Subscribers, subscriberFromPB(sub))
+	}
+	return resp, nil
+}
+
+// encodeGRPCConfirmAuthRequest is a transport/grpc.EncodeRequestFunc that converts a
+// user-domain ConfirmAuth request to a gRPC ConfirmAuth request. Primarily useful in a client.
+func encodeGRPCConfirmAuthRequest(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(endpoints.ConfirmAuthRequest)
+	return &pb.ConfirmAuthRequest{Supi: req.SUPI, ServingNetworkName: req.ServingNetworkName, Success: req.Success}, nil
+}
+
+// decodeGRPCConfirmAuthResponse is a transport/grpc.DecodeResponseFunc that converts a
+// gRPC ConfirmAuth reply to a user-domain ConfirmAuth response. Primarily useful in a client.
+func decodeGRPCConfirmAuthResponse(_ context.Context, grpcReply interface{}) (interface{}, error) {
+	return endpoints.ConfirmAuthResponse{}, nil
+}
+
+// encodeGRPCGetUEHistoryRequest is a transport/grpc.EncodeRequestFunc that converts a
+// user-domain GetUEHistory request to a gRPC GetUEHistory request. Primarily useful in a client.
+func encodeGRPCGetUEHistoryRequest(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(endpoints.GetUEHistoryRequest)
+	return &pb.GetUEHistoryRequest{
+		Supi:      req.SUPI,
+		Since:     timeToPB(req.Since),
+		Until:     timeToPB(req.Until),
+		PageSize:  int32(req.PageSize),
+		PageToken: req.PageToken,
+	}, nil
+}
+
+// decodeGRPCGetUEHistoryResponse is a transport/grpc.DecodeResponseFunc that converts a
+// gRPC GetUEHistory reply to a user-domain GetUEHistory response. Primarily useful in a client.
+func decodeGRPCGetUEHistoryResponse(_ context.Context, grpcReply interface{}) (interface{}, error) {
+	reply := grpcReply.(*pb.GetUEHistoryReply)
+	resp := endpoints.GetUEHistoryResponse{NextPageToken: reply.NextPageToken}
+	for _, e := range reply.Events {
+		resp.Events = append(resp.Events, eventFromPB(e))
+	}
+	return resp, nil
+}
+
+func subscriberFromPB(s *pb.Subscriber) subscriber.Subscriber {
+	if s == nil {
+		return subscriber.Subscriber{}
+	}
+	sub := subscriber.Subscriber{SUPI: s.Supi, K: s.K, OP: s.Op, OPc: s.Opc, AMF: s.Amf, SQN: s.Sqn}
+	if s.DefaultSnssai != nil {
+		sub.DefaultSNSSAI = &subscriber.SNSSAI{SST: uint8(s.DefaultSnssai.Sst), SD: s.DefaultSnssai.Sd}
+	}
+	if s.Ambr != nil {
+		sub.AMBR = &subscriber.AMBR{Uplink: s.Ambr.Uplink, Downlink: s.Ambr.Downlink}
+	}
+	return sub
+}
+
+func subscriberToPB(sub subscriber.Subscriber) *pb.Subscriber {
+	s := &pb.Subscriber{Supi: sub.SUPI, K: sub.K, Op: sub.OP, Opc: sub.OPc, Amf: sub.AMF, Sqn: sub.SQN}
+	if sub.DefaultSNSSAI != nil {
+		s.DefaultSnssai = &pb.Snssai{Sst: uint32(sub.DefaultSNSSAI.SST), Sd: sub.DefaultSNSSAI.SD}
+	}
+	if sub.AMBR != nil {
+		s.Ambr = &pb.Ambr{Uplink: sub.AMBR.Uplink, Downlink: sub.AMBR.Downlink}
+	}
+	return s
+}
+
+func eventFromPB(e *pb.UEEvent) history.Event {
+	return history.Event{Time: timeFromPB(e.Time), Type: history.Type(e.Type), Source: e.Source, Outcome: e.Outcome, Detail: e.Detail}
+}
+
+func eventToPB(e history.Event) *pb.UEEvent {
+	return &pb.UEEvent{Time: timeToPB(e.Time), Type: string(e.Type), Source: e.Source, Outcome: e.Outcome, Detail: e.Detail}
+}
+
+// timeFromPB converts nanoseconds since the Unix epoch, zero for no time.
+func timeFromPB(ns int64) time.Time {
+	if ns == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, ns).UTC()
+}
+
+func timeToPB(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+func grpcEncodeError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	st, ok := status.FromError(err)
+	if ok {
+		return status.Error(st.Code(), st.Message())
+	}
+	switch err {
+	default:
+		return status.Error(codes.Internal, "internal server error")
+	}
+}
@@ -0,0 +1,654 @@
+package transports
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/circuitbreaker"
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/ratelimit"
+	"github.com/go-kit/kit/tracing/opentracing"
+	"github.com/go-kit/kit/tracing/zipkin"
+	grpctransport "github.com/go-kit/kit/transport/grpc"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	stdopentracing "github.com/opentracing/opentracing-go"
+	stdzipkin "github.com/openzipkin/zipkin-go"
+	"github.com/sony/gobreaker"
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/miki-tnt/sa5g-go-usvc-k8s/pb/udm"
+	pbv2 "github.com/miki-tnt/sa5g-go-usvc-k8s/pb/udm/v2"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/breakers"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/caller"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/grpcpool"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/hedge"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/history"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/logging"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/meta"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/plmn"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/priority"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/udm/endpoints"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/udm/service"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/udm/subscriber"
+)
+
+// retryV2 is how long a client keeps to version 1 after a server answered
+// that it does not implement version 2.
+const retryV2 = time.Minute
+
+type grpcServerV2 struct {
+	generateAuthData grpctransport.Handler
+	createSubscriber grpctransport.Handler
+	updateSubscriber grpctransport.Handler
+	deleteSubscriber grpctransport.Handler
+	listSubscribers  grpctransport.Handler
+	confirmAuth      grpctransport.Handler
+	getUEHistory     grpctransport.Handler
+}
+
+func (s *grpcServerV2) GenerateAuthData(ctx context.Context, req *pbv2.GenerateAuthDataRequest) (rep *pbv2.GenerateAuthDataReply, err error) {
+	_, rp, err := s.generateAuthData.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcEncodeError(err)
+	}
+	rep = rp.(*pbv2.GenerateAuthDataReply)
+	return rep, nil
+}
+
+func (s *grpcServerV2) CreateSubscriber(ctx context.Context, req *pbv2.CreateSubscriberRequest) (rep *pbv2.CreateSubscriberReply, err error) {
+	_, rp, err := s.createSubscriber.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcEncodeError(err)
+	}
+	rep = rp.(*pbv2.CreateSubscriberReply)
+	return rep, nil
+}
+
+func (s *grpcServerV2) UpdateSubscriber(ctx context.Context, req *pbv2.UpdateSubscriberRequest) (rep *pbv2.UpdateSubscriberReply, err error) {
+	_, rp, err := s.updateSubscriber.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcEncodeError(err)
+	}
+	rep = rp.(*pbv2.UpdateSubscriberReply)
+	return rep, nil
+}
+
+func (s *grpcServerV2) DeleteSubscriber(ctx context.Context, req *pbv2.DeleteSubscriberRequest) (rep *pbv2.DeleteSubscriberReply, err error) {
+	_, rp, err := s.deleteSubscriber.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcEncodeError(err)
+	}
+	rep = rp.(*pbv2.DeleteSubscriberReply)
+	return rep, nil
+}
+
+func (s *grpcServerV2) ListSubscribers(ctx context.Context, req *pbv2.ListSubscribersRequest) (rep *pbv2.ListSubscribersReply, err error) {
+	_, rp, err := s.listSubscribers.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcEncodeError(err)
+	}
+	rep = rp.(*pbv2.ListSubscribersReply)
+	return rep, nil
+}
+
+func (s *grpcServerV2) ConfirmAuth(ctx context.Context, req *pbv2.ConfirmAuthRequest) (rep *pbv2.ConfirmAuthReply, err error) {
+	_, rp, err := s.confirmAuth.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcEncodeError(err)
+	}
+	rep = rp.(*pbv2.ConfirmAuthReply)
+	return rep, nil
+}
+
+func (s *grpcServerV2) GetUEHistory(ctx context.Context, req *pbv2.GetUEHistoryRequest) (rep *pbv2.GetUEHistoryReply, err error) {
+	_, rp, err := s.getUEHistory.ServeGRPC(ctx, req)
+	if err != nil {
+		return nil, grpcEncodeError(err)
+	}
+	rep = rp.(*pbv2.GetUEHistoryReply)
+	return rep, nil
+}
+
+// MakeGRPCServerV2 makes a set of endpoints available as a gRPC server of
+// version 2 of the API. Register it next to the server of MakeGRPCServer,
+// which the clients of version 1 keep using.
+func MakeGRPCServerV2(endpoints endpoints.Endpoints, otTracer stdopentracing.Tracer, zipkinTracer *stdzipkin.Tracer, logger log.Logger) (req pbv2.UdmServer) {
+	zipkinServer := zipkin.GRPCServerTrace(zipkinTracer)
+
+	options := []grpctransport.ServerOption{
+		grpctransport.ServerErrorHandler(logging.ErrorHandler(logger)),
+		zipkinServer,
+		grpctransport.ServerBefore(plmn.GRPCToContext()),
+		grpctransport.ServerBefore(priority.GRPCToContext()),
+		grpctransport.ServerBefore(caller.GRPCToContext()),
+		grpctransport.ServerBefore(meta.GRPCToContext()),
+	}
+
+	return &grpcServerV2{
+		generateAuthData: grpctransport.NewServer(
+			endpoints.GenerateAuthDataEndpoint,
+			decodeGRPCGenerateAuthDataRequestV2,
+			encodeGRPCGenerateAuthDataResponseV2,
+			append(options, grpctransport.ServerBefore(opentracing.GRPCToContext(otTracer, "GenerateAuthData", logger)))...,
+		),
+		createSubscriber: grpctransport.NewServer(
+			endpoints.CreateSubscriberEndpoint,
+			decodeGRPCCreateSubscriberRequestV2,
+			encodeGRPCCreateSubscriberResponseV2,
+			append(options, grpctransport.ServerBefore(opentracing.GRPCToContext(otTracer, "CreateSubscriber", logger)))...,
+		),
+		updateSubscriber: grpctransport.NewServer(
+			endpoints.UpdateSubscriberEndpoint,
+			decodeGRPCUpdateSubscriberRequestV2,
+			encodeGRPCUpdateSubscriberResponseV2,
+			append(options, grpctransport.ServerBefore(opentracing.GRPCToContext(otTracer, "UpdateSubscriber", logger)))...,
+		),
+		deleteSubscriber: grpctransport.NewServer(
+			endpoints.DeleteSubscriberEndpoint,
+			decodeGRPCDeleteSubscriberRequestV2,
+			encodeGRPCDeleteSubscriberResponseV2,
+			append(options, grpctransport.ServerBefore(opentracing.GRPCToContext(otTracer, "DeleteSubscriber", logger)))...,
+		),
+		listSubscribers: grpctransport.NewServer(
+			endpoints.ListSubscribersEndpoint,
+			decodeGRPCListSubscribersRequestV2,
+			encodeGRPCListSubscribersResponseV2,
+			append(options, grpctransport.ServerBefore(opentracing.GRPCToContext(otTracer, "ListSubscribers", logger)))...,
+		),
+		confirmAuth: grpctransport.NewServer(
+			endpoints.ConfirmAuthEndpoint,
+			decodeGRPCConfirmAuthRequestV2,
+			encodeGRPCConfirmAuthResponseV2,
+			append(options, grpctransport.ServerBefore(opentracing.GRPCToContext(otTracer, "ConfirmAuth", logger)))...,
+		),
+		getUEHistory: grpctransport.NewServer(
+			endpoints.GetUEHistoryEndpoint,
+			decodeGRPCGetUEHistoryRequestV2,
+			encodeGRPCGetUEHistoryResponseV2,
+			append(options, grpctransport.ServerBefore(opentracing.GRPCToContext(otTracer, "GetUEHistory", logger)))...,
+		),
+	}
+}
+
+// decodeGRPCGenerateAuthDataRequestV2 is a transport/grpc.DecodeRequestFunc that converts a
+// gRPC v2 request to a user-domain request. Primarily useful in a server.
+func decodeGRPCGenerateAuthDataRequestV2(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pbv2.GenerateAuthDataRequest)
+	r := endpoints.GenerateAuthDataRequest{SUPI: req.Supi, ServingNetworkName: req.ServingNetworkName}
+	if req.Resync != nil {
+		r.Resync = &endpoints.ResyncInfo{Rand: req.Resync.Rand, Auts: req.Resync.Auts}
+	}
+	return r, nil
+}
+
+// encodeGRPCGenerateAuthDataResponseV2 is a transport/grpc.EncodeResponseFunc that converts a
+// user-domain response to a gRPC v2 reply. Primarily useful in a server.
+func encodeGRPCGenerateAuthDataResponseV2(_ context.Context, grpcReply interface{}) (res interface{}, err error) {
+	reply := grpcReply.(endpoints.GenerateAuthDataResponse)
+	return &pbv2.GenerateAuthDataReply{Rand: reply.Rand, Autn: reply.Autn, XresStar: reply.XresStar, Kausf: reply.Kausf}, grpcEncodeError(reply.Err)
+}
+
+// decodeGRPCCreateSubscriberRequestV2 is a transport/grpc.DecodeRequestFunc that converts a
+// gRPC v2 request to a user-domain request. Primarily useful in a server.
+func decodeGRPCCreateSubscriberRequestV2(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pbv2.CreateSubscriberRequest)
+	return endpoints.CreateSubscriberRequest{Subscriber: subscriberFromPBV2(req.Subscriber)}, nil
+}
+
+// encodeGRPCCreateSubscriberResponseV2 is a transport/grpc.EncodeResponseFunc that converts a
+// user-domain response to a gRPC v2 reply. Primarily useful in a server.
+func encodeGRPCCreateSubscriberResponseV2(_ context.Context, grpcReply interface{}) (res interface{}, err error) {
+	reply := grpcReply.(endpoints.CreateSubscriberResponse)
+	return &pbv2.CreateSubscriberReply{Subscriber: subscriberToPBV2(reply.Subscriber)}, grpcEncodeError(reply.Err)
+}
+
+// decodeGRPCUpdateSubscriberRequestV2 is a transport/grpc.DecodeRequestFunc that converts a
+// gRPC v2 request to a user-domain request. Primarily useful in a server.
+func decodeGRPCUpdateSubscriberRequestV2(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pbv2.UpdateSubscriberRequest)
+	return endpoints.UpdateSubscriberRequest{Subscriber: subscriberFromPBV2(req.Subscriber)}, nil
+}
+
+// encodeGRPCUpdateSubscriberResponseV2 is a transport/grpc.EncodeResponseFunc that converts a
+// user-domain response to a gRPC v2 reply. Primarily useful in a server.
+func encodeGRPCUpdateSubscriberResponseV2(_ context.Context, grpcReply interface{}) (res interface{}, err error) {
+	reply := grpcReply.(endpoints.UpdateSubscriberResponse)
+	return &pbv2.UpdateSubscriberReply{Subscriber: subscriberToPBV2(reply.Subscriber)}, grpcEncodeError(reply.Err)
+}
+
+// decodeGRPCDeleteSubscriberRequestV2 is a transport/grpc.DecodeRequestFunc that converts a
+// gRPC v2 request to a user-domain request. Primarily useful in a server.
+func decodeGRPCDeleteSubscriberRequestV2(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pbv2.DeleteSubscriberRequest)
+	return endpoints.DeleteSubscriberRequest{SUPI: req.Supi}, nil
+}
+
+// encodeGRPCDeleteSubscriberResponseV2 is a transport/grpc.EncodeResponseFunc that converts a
+// user-domain response to a gRPC v2 reply. Primarily useful in a server.
+func encodeGRPCDeleteSubscriberResponseV2(_ context.Context, grpcReply interface{}) (res interface{}, err error) {
+	reply := grpcReply.(endpoints.DeleteSubscriberResponse)
+	return &pbv2.DeleteSubscriberReply{}, grpcEncodeError(reply.Err)
+}
+
+// decodeGRPCListSubscribersRequestV2 is a transport/grpc.DecodeRequestFunc that converts a
+// gRPC v2 request to a user-domain request. Primarily useful in a server.
+func decodeGRPCListSubscribersRequestV2(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pbv2.ListSubscribersRequest)
+	return endpoints.ListSubscribersRequest{PageSize: int(req.PageSize), PageToken: req.PageToken}, nil
+}
+
+// encodeGRPCListSubscribersResponseV2 is a transport/grpc.EncodeResponseFunc that converts a
+// user-domain response to a gRPC v2 reply. Primarily useful in a server.
+func encodeGRPCListSubscribersResponseV2(_ context.Context, grpcReply interface{}) (res interface{}, err error) {
+	reply := grpcReply.(endpoints.ListSubscribersResponse)
+	rep := &pbv2.ListSubscribersReply{NextPageToken: reply.NextPageToken}
+	for _, sub := range reply.Subscribers {
+		rep.Subscribers = append(rep.Subscribers, subscriberToPBV2(sub))
+	}
+	return rep, grpcEncodeError(reply.Err)
+}
+
+// decodeGRPCConfirmAuthRequestV2 is a transport/grpc.DecodeRequestFunc that converts a
+// gRPC v2 request to a user-domain request. Primarily useful in a server.
+func decodeGRPCConfirmAuthRequestV2(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pbv2.ConfirmAuthRequest)
+	return endpoints.ConfirmAuthRequest{SUPI: req.Supi, ServingNetworkName: req.ServingNetworkName, Success: req.Success}, nil
+}
+
+// encodeGRPCConfirmAuthResponseV2 is a transport/grpc.EncodeResponseFunc that converts a
+// user-domain response to a gRPC v2 reply. Primarily useful in a server.
+func encodeGRPCConfirmAuthResponseV2(_ context.Context, grpcReply interface{}) (res interface{}, err error) {
+	reply := grpcReply.(endpoints.ConfirmAuthResponse)
+	return &pbv2.ConfirmAuthReply{}, grpcEncodeError(reply.Err)
+}
+
+// decodeGRPCGetUEHistoryRequestV2 reads the deprecated since and until of
+// the clients that do not set since_time and until_time yet.
+func decodeGRPCGetUEHistoryRequestV2(_ context.Context, grpcReq interface{}) (interface{}, error) {
+	req := grpcReq.(*pbv2.GetUEHistoryRequest)
+	r := endpoints.GetUEHistoryRequest{
+		SUPI:      req.Supi,
+		Since:     timeFromPB(req.Since),
+		Until:     timeFromPB(req.Until),
+		PageSize:  int(req.PageSize),
+		PageToken: req.PageToken,
+	}
+	var err error
+	if req.SinceTime != nil {
+		if r.Since, err = timestampFromPB(req.SinceTime); err != nil {
+			return nil, err
+		}
+	}
+	if req.UntilTime != nil {
+		if r.Until, err = timestampFromPB(req.UntilTime); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// encodeGRPCGetUEHistoryResponseV2 is a transport/grpc.EncodeResponseFunc that converts a
+// user-domain response to a gRPC v2 reply. Primarily useful in a server.
+func encodeGRPCGetUEHistoryResponseV2(_ context.Context, grpcReply interface{}) (res interface{}, err error) {
+	reply := grpcReply.(endpoints.GetUEHistoryResponse)
+	rep := &pbv2.GetUEHistoryReply{NextPageToken: reply.NextPageToken}
+	for _, e := range reply.Events {
+		rep.Events = append(rep.Events, eventToPBV2(e))
+	}
+	return rep, grpcEncodeError(reply.Err)
+}
+
+// NewGRPCPoolClientV2 is like NewGRPCPoolClient, but calls version 2 of the
+// API. A call answered Unimplemented, by a server not upgraded yet, is made
+// again with version 1, and the client keeps to version 1 for a minute.
+func NewGRPCPoolClientV2(pool *grpcpool.Pool, otTracer stdopentracing.Tracer, zipkinTracer *stdzipkin.Tracer, logger log.Logger, hedging ...hedge.Option) service.UdmService {
+	limiter := ratelimit.NewErroringLimiter(rate.NewLimiter(rate.Every(time.Second), 100))
+	// every authentication vector is followed by the result of the
+	// authentication, which is not charged to the same budget
+	confirmLimiter := ratelimit.NewErroringLimiter(rate.NewLimiter(rate.Every(time.Second), 100))
+
+	zipkinClient := zipkin.GRPCClientTrace(zipkinTracer)
+
+	// global client middlewares
+	options := []grpctransport.ClientOption{
+		zipkinClient,
+		grpctransport.ClientBefore(plmn.ContextToGRPC()),
+		grpctransport.ClientBefore(priority.ContextToGRPC()),
+		grpctransport.ClientBefore(meta.ContextToGRPC()),
+		grpctransport.ClientBefore(opentracing.ContextToGRPC(otTracer, logger)),
+	}
+	n := &negotiator{}
+
+	var generateAuthDataEndpoint endpoint.Endpoint
+	{
+		generateAuthDataEndpoint = n.endpoint(
+			poolEndpoint(pool, "udm.v2.Udm", "GenerateAuthData", encodeGRPCGenerateAuthDataRequestV2, decodeGRPCGenerateAuthDataResponseV2, pbv2.GenerateAuthDataReply{}, options),
+			poolEndpoint(pool, "pb.Udm", "GenerateAuthData", encodeGRPCGenerateAuthDataRequest, decodeGRPCGenerateAuthDataResponse, pb.GenerateAuthDataReply{}, options),
+		)
+		generateAuthDataEndpoint = opentracing.TraceClient(otTracer, "GenerateAuthData")(generateAuthDataEndpoint)
+		generateAuthDataEndpoint = limiter(generateAuthDataEndpoint)
+		generateAuthDataEndpoint = circuitbreaker.Gobreaker(breakers.New(gobreaker.Settings{
+			Name:    "GenerateAuthData",
+			Timeout: 30 * time.Second,
+		}))(generateAuthDataEndpoint)
+	}
+
+	var createSubscriberEndpoint endpoint.Endpoint
+	{
+		createSubscriberEndpoint = n.endpoint(
+			poolEndpoint(pool, "udm.v2.Udm", "CreateSubscriber", encodeGRPCCreateSubscriberRequestV2, decodeGRPCCreateSubscriberResponseV2, pbv2.CreateSubscriberReply{}, options),
+			poolEndpoint(pool, "pb.Udm", "CreateSubscriber", encodeGRPCCreateSubscriberRequest, decodeGRPCCreateSubscriberResponse, pb.CreateSubscriberReply{}, options),
+		)
+		createSubscriberEndpoint = opentracing.TraceClient(otTracer, "CreateSubscriber")(createSubscriberEndpoint)
+		createSubscriberEndpoint = limiter(createSubscriberEndpoint)
+		createSubscriberEndpoint = circuitbreaker.Gobreaker(breakers.New(gobreaker.Settings{
+			Name:    "CreateSubscriber",
+			Timeout: 30 * time.Second,
+		}))(createSubscriberEndpoint)
+	}
+
+	var updateSubscriberEndpoint endpoint.Endpoint
+	{
+		updateSubscriberEndpoint = n.endpoint(
+			poolEndpoint(pool, "udm.v2.Udm", "UpdateSubscriber", encodeGRPCUpdateSubscriberRequestV2, decodeGRPCUpdateSubscriberResponseV2, pbv2.UpdateSubscriberReply{}, options),
+			poolEndpoint(pool, "pb.Udm", "UpdateSubscriber", encodeGRPCUpdateSubscriberRequest, decodeGRPCUpdateSubscriberResponse, pb.UpdateSubscriberReply{}, options),
+		)
+		updateSubscriberEndpoint = opentracing.TraceClient(otTracer, "UpdateSubscriber")(updateSubscriberEndpoint)
+		updateSubscriberEndpoint = limiter(updateSubscriberEndpoint)
+		updateSubscriberEndpoint = circuitbreaker.Gobreaker(breakers.New(gobreaker.Settings{
+			Name:    "UpdateSubscriber",
+			Timeout: 30 * time.Second,
+		}))(updateSubscriberEndpoint)
+	}
+
+	var deleteSubscriberEndpoint endpoint.Endpoint
+	{
+		deleteSubscriberEndpoint = n.endpoint(
+			poolEndpoint(pool, "udm.v2.Udm", "DeleteSubscriber", encodeGRPCDeleteSubscriberRequestV2, decodeGRPCDeleteSubscriberResponseV2, pbv2.DeleteSubscriberReply{}, options),
+			poolEndpoint(pool, "pb.Udm", "DeleteSubscriber", encodeGRPCDeleteSubscriberRequest, decodeGRPCDeleteSubscriberResponse, pb.DeleteSubscriberReply{}, options),
+		)
+		deleteSubscriberEndpoint = opentracing.TraceClient(otTracer, "DeleteSubscriber")(deleteSubscriberEndpoint)
+		deleteSubscriberEndpoint = limiter(deleteSubscriberEndpoint)
+		deleteSubscriberEndpoint = circuitbreaker.Gobreaker(breakers.New(gobreaker.Settings{
+			Name:    "DeleteSubscriber",
+			Timeout: 30 * time.Second,
+		}))(deleteSubscriberEndpoint)
+	}
+
+	var listSubscribersEndpoint endpoint.Endpoint
+	{
+		listSubscribersEndpoint = n.endpoint(
+			poolEndpoint(pool, "udm.v2.Udm", "ListSubscribers", encodeGRPCListSubscribersRequestV2, decodeGRPCListSubscribersResponseV2, pbv2.ListSubscribersReply{}, options),
+			poolEndpoint(pool, "pb.Udm", "ListSubscribers", encodeGRPCListSubscribersRequest, decodeGRPCListSubscribersResponse, pb.ListSubscribersReply{}, options),
+		)
+		listSubscribersEndpoint = hedge.Middleware(hedging...)(listSubscribersEndpoint)
+		listSubscribersEndpoint = opentracing.TraceClient(otTracer, "ListSubscribers")(listSubscribersEndpoint)
+		listSubscribersEndpoint = limiter(listSubscribersEndpoint)
+		listSubscribersEndpoint = circuitbreaker.Gobreaker(breakers.New(gobreaker.Settings{
+			Name:    "ListSubscribers",
+			Timeout: 30 * time.Second,
+		}))(listSubscribersEndpoint)
+	}
+
+	var confirmAuthEndpoint endpoint.Endpoint
+	{
+		confirmAuthEndpoint = n.endpoint(
+			poolEndpoint(pool, "udm.v2.Udm", "ConfirmAuth", encodeGRPCConfirmAuthRequestV2, decodeGRPCConfirmAuthResponseV2, pbv2.ConfirmAuthReply{}, options),
+			poolEndpoint(pool, "pb.Udm", "ConfirmAuth", encodeGRPCConfirmAuthRequest, decodeGRPCConfirmAuthResponse, pb.ConfirmAuthReply{}, options),
+		)
+		confirmAuthEndpoint = opentracing.TraceClient(otTracer, "ConfirmAuth")(confirmAuthEndpoint)
+		confirmAuthEndpoint = confirmLimiter(confirmAuthEndpoint)
+		confirmAuthEndpoint = circuitbreaker.Gobreaker(breakers.New(gobreaker.Settings{
+			Name:    "ConfirmAuth",
+			Timeout: 30 * time.Second,
+		}))(confirmAuthEndpoint)
+	}
+
+	var getUEHistoryEndpoint endpoint.Endpoint
+	{
+		getUEHistoryEndpoint = n.endpoint(
+			poolEndpoint(pool, "udm.v2.Udm", "GetUEHistory", encodeGRPCGetUEHistoryRequestV2, decodeGRPCGetUEHistoryResponseV2, pbv2.GetUEHistoryReply{}, options),
+			poolEndpoint(pool, "pb.Udm", "GetUEHistory", encodeGRPCGetUEHistoryRequest, decodeGRPCGetUEHistoryResponse, pb.GetUEHistoryReply{}, options),
+		)
+		getUEHistoryEndpoint = hedge.Middleware(hedging...)(getUEHistoryEndpoint)
+		getUEHistoryEndpoint = opentracing.TraceClient(otTracer, "GetUEHistory")(getUEHistoryEndpoint)
+		getUEHistoryEndpoint = limiter(getUEHistoryEndpoint)
+		getUEHistoryEndpoint = circuitbreaker.Gobreaker(breakers.New(gobreaker.Settings{
+			Name:    "GetUEHistory",
+			Timeout: 30 * time.Second,
+		}))(getUEHistoryEndpoint)
+	}
+
+	return endpoints.Endpoints{
+		GenerateAuthDataEndpoint: generateAuthDataEndpoint,
+		CreateSubscriberEndpoint: createSubscriberEndpoint,
+		UpdateSubscriberEndpoint: updateSubscriberEndpoint,
+		DeleteSubscriberEndpoint: deleteSubscriberEndpoint,
+		ListSubscribersEndpoint:  listSubscribersEndpoint,
+		ConfirmAuthEndpoint:      confirmAuthEndpoint,
+		GetUEHistoryEndpoint:     getUEHistoryEndpoint,
+	}
+}
+
+// poolEndpoint returns the endpoint calling method of service over the
+// connections of pool.
+func poolEndpoint(pool *grpcpool.Pool, service, method string, enc grpctransport.EncodeRequestFunc, dec grpctransport.DecodeResponseFunc, reply interface{}, options []grpctransport.ClientOption) endpoint.Endpoint {
+	return pool.Endpoint(func(conn *grpc.ClientConn) endpoint.Endpoint {
+		return grpctransport.NewClient(conn, service, method, enc, dec, reply, options...).Endpoint()
+	})
+}
+
+// negotiator picks the version of the API the server implements.
+type negotiator struct {
+	mtx     sync.Mutex
+	v1Until time.Time
+}
+
+// endpoint returns an endpoint calling v2, or v1 when the server does not
+// implement v2.
+func (n *negotiator) endpoint(v2, v1 endpoint.Endpoint) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		if n.v1() {
+			return v1(ctx, request)
+		}
+		response, err := v2(ctx, request)
+		if status.Code(err) != codes.Unimplemented {
+			return response, err
+		}
+		n.mtx.Lock()
+		n.v1Until = time.Now().Add(retryV2)
+		n.mtx.Unlock()
+		return v1(ctx, request)
+	}
+}
+
+func (n *negotiator) v1() bool {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	return time.Now().Before(n.v1Until)
+}
+
+// encodeGRPCGenerateAuthDataRequestV2 is a transport/grpc.EncodeRequestFunc that converts a
+// user-domain GenerateAuthData request to a gRPC v2 GenerateAuthData request. Primarily useful in a client.
+func encodeGRPCGenerateAuthDataRequestV2(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(endpoints.GenerateAuthDataRequest)
+	r := &pbv2.GenerateAuthDataRequest{Supi: req.SUPI, ServingNetworkName: req.ServingNetworkName}
+	if req.Resync != nil {
+		r.Resync = &pbv2.ResynchronizationInfo{Rand: req.Resync.Rand, Auts: req.Resync.Auts}
+	}
+	return r, nil
+}
+
+// decodeGRPCGenerateAuthDataResponseV2 is a transport/grpc.DecodeResponseFunc that converts a
+// gRPC v2 GenerateAuthData reply to a user-domain GenerateAuthData response. Primarily useful in a client.
+func decodeGRPCGenerateAuthDataResponseV2(_ context.Context, grpcReply interface{}) (interface{}, error) {
+	reply := grpcReply.(*pbv2.GenerateAuthDataReply)
+	return endpoints.GenerateAuthDataResponse{Rand: reply.Rand, Autn: reply.Autn, XresStar: reply.XresStar, Kausf: reply.Kausf}, nil
+}
+
+// encodeGRPCCreateSubscriberRequestV2 is a transport/grpc.EncodeRequestFunc that converts a
+// user-domain CreateSubscriber request to a gRPC v2 CreateSubscriber request. Primarily useful in a client.
+func encodeGRPCCreateSubscriberRequestV2(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(endpoints.CreateSubscriberRequest)
+	return &pbv2.CreateSubscriberRequest{Subscriber: subscriberToPBV2(req.Subscriber)}, nil
+}
+
+// decodeGRPCCreateSubscriberResponseV2 is a transport/grpc.DecodeResponseFunc that converts a
+// gRPC v2 CreateSubscriber reply to a user-domain CreateSubscriber response. Primarily useful in a client.
+func decodeGRPCCreateSubscriberResponseV2(_ context.Context, grpcReply interface{}) (interface{}, error) {
+	reply := grpcReply.(*pbv2.CreateSubscriberReply)
+	return endpoints.CreateSubscriberResponse{Subscriber: subscriberFromPBV2(reply.Subscriber)}, nil
+}
+
+// encodeGRPCUpdateSubscriberRequestV2 is a transport/grpc.EncodeRequestFunc that converts a
+// user-domain UpdateSubscriber request to a gRPC v2 UpdateSubscriber request. Primarily useful in a client.
+func encodeGRPCUpdateSubscriberRequestV2(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(endpoints.UpdateSubscriberRequest)
+	return &pbv2.UpdateSubscriberRequest{Subscriber: subscriberToPBV2(req.Subscriber)}, nil
+}
+
+// decodeGRPCUpdateSubscriberResponseV2 is a transport/grpc.DecodeResponseFunc that converts a
+// gRPC v2 UpdateSubscriber reply to a user-domain UpdateSubscriber response. Primarily useful in a client.
+func decodeGRPCUpdateSubscriberResponseV2(_ context.Context, grpcReply interface{}) (interface{}, error) {
+	reply := grpcReply.(*pbv2.UpdateSubscriberReply)
+	return endpoints.UpdateSubscriberResponse{Subscriber: subscriberFromPBV2(reply.Subscriber)}, nil
+}
+
+// encodeGRPCDeleteSubscriberRequestV2 is a transport/grpc.EncodeRequestFunc that converts a
+// user-domain DeleteSubscriber request to a gRPC v2 DeleteSubscriber request. Primarily useful in a client.
+func encodeGRPCDeleteSubscriberRequestV2(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(endpoints.DeleteSubscriberRequest)
+	return &pbv2.DeleteSubscriberRequest{Supi: req.SUPI}, nil
+}
+
+// decodeGRPCDeleteSubscriberResponseV2 is a transport/grpc.DecodeResponseFunc that converts a
+// gRPC v2 DeleteSubscriber reply to a user-domain DeleteSubscriber response. Primarily useful in a client.
+func decodeGRPCDeleteSubscriberResponseV2(_ context.Context, grpcReply interface{}) (interface{}, error) {
+	return endpoints.DeleteSubscriberResponse{}, nil
+}
+
+// encodeGRPCListSubscribersRequestV2 is a transport/grpc.EncodeRequestFunc that converts a
+// user-domain ListSubscribers request to a gRPC v2 ListSubscribers request. Primarily useful in a client.
+func encodeGRPCListSubscribersRequestV2(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(endpoints.ListSubscribersRequest)
+	return &pbv2.ListSubscribersRequest{PageSize: int32(req.PageSize), PageToken: req.PageToken}, nil
+}
+
+// decodeGRPCListSubscribersResponseV2 is a transport/grpc.DecodeResponseFunc that converts a
+// gRPC v2 ListSubscribers reply to a user-domain ListSubscribers response. Primarily useful in a client.
+func decodeGRPCListSubscribersResponseV2(_ context.Context, grpcReply interface{}) (interface{}, error) {
+	reply := grpcReply.(*pbv2.ListSubscribersReply)
+	resp := endpoints.ListSubscribersResponse{NextPageToken: reply.NextPageToken}
+	for _, sub := range reply.Subscribers {
+		resp.Subscribers = append(resp.Subscribers, subscriberFromPBV2(sub))
+	}
+	return resp, nil
+}
+
+// encodeGRPCConfirmAuthRequestV2 is a transport/grpc.EncodeRequestFunc that converts a
+// user-domain ConfirmAuth request to a gRPC v2 ConfirmAuth request. Primarily useful in a client.
+func encodeGRPCConfirmAuthRequestV2(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(endpoints.ConfirmAuthRequest)
+	return &pbv2.ConfirmAuthRequest{Supi: req.SUPI, ServingNetworkName: req.ServingNetworkName, Success: req.Success}, nil
+}
+
+// decodeGRPCConfirmAuthResponseV2 is a transport/grpc.DecodeResponseFunc that converts a
+// gRPC v2 ConfirmAuth reply to a user-domain ConfirmAuth response. Primarily useful in a client.
+func decodeGRPCConfirmAuthResponseV2(_ context.Context, grpcReply interface{}) (interface{}, error) {
+	return endpoints.ConfirmAuthResponse{}, nil
+}
+
+// encodeGRPCGetUEHistoryRequestV2 is a transport/grpc.EncodeRequestFunc that converts a
+// user-domain GetUEHistory request to a gRPC v2 GetUEHistory request. Primarily useful in a client.
+func encodeGRPCGetUEHistoryRequestV2(_ context.Context, request interface{}) (interface{}, error) {
+	req := request.(endpoints.GetUEHistoryRequest)
+	r := &pbv2.GetUEHistoryRequest{Supi: req.SUPI, PageSize: int32(req.PageSize), PageToken: req.PageToken}
+	var err error
+	if !req.Since.IsZero() {
+		if r.SinceTime, err = ptypes.TimestampProto(req.Since); err != nil {
+			return nil, err
+		}
+	}
+	if !req.Until.IsZero() {
+		if r.UntilTime, err = ptypes.TimestampProto(req.Until); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// decodeGRPCGetUEHistoryResponseV2 is a transport/grpc.DecodeResponseFunc that converts a
+// gRPC v2 GetUEHistory reply to a user-domain GetUEHistory response. Primarily useful in a client.
+func decodeGRPCGetUEHistoryResponseV2(_ context.Context, grpcReply interface{}) (interface{}, error) {
+	reply := grpcReply.(*pbv2.GetUEHistoryReply)
+	resp := endpoints.GetUEHistoryResponse{NextPageToken: reply.NextPageToken}
+	for _, e := range reply.Events {
+		event, err := eventFromPBV2(e)
+		if err != nil {
+			return nil, err
+		}
+		resp.Events = append(resp.Events, event)
+	}
+	return resp, nil
+}
+
+// subscriberFromPBV2 still reads the deprecated op.
+func subscriberFromPBV2(s *pbv2.Subscriber) subscriber.Subscriber {
+	if s == nil {
+		return subscriber.Subscriber{}
+	}
+	sub := subscriber.Subscriber{SUPI: s.Supi, K: s.K, OP: s.Op, OPc: s.Opc, AMF: s.Amf, SQN: s.Sqn}
+	if s.DefaultSnssai != nil {
+		sub.DefaultSNSSAI = &subscriber.SNSSAI{SST: uint8(s.DefaultSnssai.Sst), SD: s.DefaultSnssai.Sd}
+	}
+	if s.Ambr != nil {
+		sub.AMBR = &subscriber.AMBR{Uplink: s.Ambr.Uplink, Downlink: s.Ambr.Downlink}
+	}
+	return sub
+}
+
+func subscriberToPBV2(sub subscriber.Subscriber) *pbv2.Subscriber {
+	s := &pbv2.Subscriber{Supi: sub.SUPI, K: sub.K, Op: sub.OP, Opc: sub.OPc, Amf: sub.AMF, Sqn: sub.SQN}
+	if sub.DefaultSNSSAI != nil {
+		s.DefaultSnssai = &pbv2.Snssai{Sst: uint32(sub.DefaultSNSSAI.SST), Sd: sub.DefaultSNSSAI.SD}
+	}
+	if sub.AMBR != nil {
+		s.Ambr = &pbv2.Ambr{Uplink: sub.AMBR.Uplink, Downlink: sub.AMBR.Downlink}
+	}
+	return s
+}
+
+// eventFromPBV2 falls back on the deprecated time of the servers that do not
+// set timestamp.
+func eventFromPBV2(e *pbv2.UEEvent) (history.Event, error) {
+	event := history.Event{Time: timeFromPB(e.Time), Type: history.Type(e.Type), Source: e.Source, Outcome: e.Outcome, Detail: e.Detail}
+	if e.Timestamp != nil {
+		t, err := timestampFromPB(e.Timestamp)
+		if err != nil {
+			return event, err
+		}
+		event.Time = t
+	}
+	return event, nil
+}
+
+// eventToPBV2 sets both times, for the clients still reading the deprecated
+// one.
+func eventToPBV2(e history.Event) *pbv2.UEEvent {
+	ts, _ := ptypes.TimestampProto(e.Time)
+	return &pbv2.UEEvent{Time: timeToPB(e.Time), Type: string(e.Type), Source: e.Source, Outcome: e.Outcome, Detail: e.Detail, Timestamp: ts}
+}
+
+func timestampFromPB(ts *timestamp.Timestamp) (time.Time, error) {
+	t, err := ptypes.Timestamp(ts)
+	if err != nil {
+		return t, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return t.UTC(), nil
+}
@@ -0,0 +1,498 @@
+package transports
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-kit/kit/circuitbreaker"
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/ratelimit"
+	"github.com/go-kit/kit/sd/lb"
+	"github.com/go-kit/kit/tracing/opentracing"
+	"github.com/go-kit/kit/tracing/zipkin"
+	httptransport "github.com/go-kit/kit/transport/http"
+	"github.com/golang/protobuf/proto"
+	stdopentracing "github.com/opentracing/opentracing-go"
+	stdzipkin "github.com/openzipkin/zipkin-go"
+	"github.com/sony/gobreaker"
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/miki-tnt/sa5g-go-usvc-k8s/pb/udm"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/apiversion"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/breakers"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/caller"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/codec"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/logging"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/meta"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/openapi"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/plmn"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/priority"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/udm/endpoints"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/udm/service"
+)
+
+type errorWrapper struct {
+	Error string `json:"error"`
+}
+
+func JSONErrorDecoder(r *http.Response) error {
+	contentType := r.Header.Get("Content-Type")
+	if !strings.Contains(contentType, "application/json") {
+		return fmt.Errorf("expected JSON formatted error, got Content-Type %s", contentType)
+	}
+	var w errorWrapper
+	if err := json.NewDecoder(r.Body).Decode(&w); err != nil {
+		return err
+	}
+	return errors.New(w.Error)
+}
+
+// unprefixedSince is when the paths without a version prefix were deprecated.
+var unprefixedSince = time.Date(2026, time.October, 17, 0, 0, 0, 0, time.UTC)
+
+// NewHTTPHandler returns a handler that makes a set of endpoints available on
+// predefined paths: version 1 of the API under /v1 and version 2 under /v2.
+// The paths without a prefix, of the clients predating the versions, still
+// serve version 1 but answer with a Deprecation header pointing to /v1.
+func NewHTTPHandler(endpoints endpoints.Endpoints, otTracer stdopentracing.Tracer, zipkinTracer *stdzipkin.Tracer, logger log.Logger) http.Handler {
+	v1 := newHTTPHandlerV1(endpoints, otTracer, zipkinTracer, logger)
+	return apiversion.Mux(
+		apiversion.Deprecate(v1, apiversion.Deprecation{Since: unprefixedSince, Successor: "/v1"}),
+		apiversion.Version{Prefix: "/v1", Handler: v1},
+		apiversion.Version{Prefix: "/v2", Handler: newHTTPHandlerV2(endpoints, otTracer, zipkinTracer, logger)},
+	)
+}
+
+// httpServerOptions returns the options of the HTTP servers of the methods,
+// whose bodies are serialized by the codecs of reg.
+func httpServerOptions(reg *codec.Registry, zipkinTracer *stdzipkin.Tracer, logger log.Logger) []httptransport.ServerOption {
+	return []httptransport.ServerOption{
+		httptransport.ServerErrorEncoder(httpEncodeError),
+		httptransport.ServerErrorHandler(logging.ErrorHandler(logger)),
+		zipkin.HTTPServerTrace(zipkinTracer),
+		httptransport.ServerBefore(plmn.HTTPToContext()),
+		httptransport.ServerBefore(priority.HTTPToContext()),
+		httptransport.ServerBefore(caller.HTTPToContext()),
+		httptransport.ServerBefore(meta.HTTPToContext()),
+		httptransport.ServerBefore(reg.HTTPToContext()),
+	}
+}
+
+// newHTTPHandlerV1 returns the handler of version 1 of the API, whose bodies
+// are the endpoints requests and responses.
+func newHTTPHandlerV1(endpoints endpoints.Endpoints, otTracer stdopentracing.Tracer, zipkinTracer *stdzipkin.Tracer, logger log.Logger) http.Handler {
+	options := httpServerOptions(codec.Default, zipkinTracer, logger)
+
+	m := http.NewServeMux()
+	m.Handle("/generateAuthData", httptransport.NewServer(
+		endpoints.GenerateAuthDataEndpoint,
+		codec.DecodeRequest(generateAuthDataBinding),
+		codec.EncodeResponse(generateAuthDataBinding),
+		append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "GenerateAuthData", logger)))...,
+	))
+	m.Handle("/createSubscriber", httptransport.NewServer(
+		endpoints.CreateSubscriberEndpoint,
+		codec.DecodeRequest(createSubscriberBinding),
+		codec.EncodeResponse(createSubscriberBinding),
+		append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "CreateSubscriber", logger)))...,
+	))
+	m.Handle("/updateSubscriber", httptransport.NewServer(
+		endpoints.UpdateSubscriberEndpoint,
+		codec.DecodeRequest(updateSubscriberBinding),
+		codec.EncodeResponse(updateSubscriberBinding),
+		append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "UpdateSubscriber", logger)))...,
+	))
+	m.Handle("/deleteSubscriber", httptransport.NewServer(
+		endpoints.DeleteSubscriberEndpoint,
+		codec.DecodeRequest(deleteSubscriberBinding),
+		codec.EncodeResponse(deleteSubscriberBinding),
+		append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "DeleteSubscriber", logger)))...,
+	))
+	m.Handle("/listSubscribers", httptransport.NewServer(
+		endpoints.ListSubscribersEndpoint,
+		codec.DecodeRequest(listSubscribersBinding),
+		codec.EncodeResponse(listSubscribersBinding),
+		append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "ListSubscribers", logger)))...,
+	))
+	m.Handle("/confirmAuth", httptransport.NewServer(
+		endpoints.ConfirmAuthEndpoint,
+		codec.DecodeRequest(confirmAuthBinding),
+		codec.EncodeResponse(confirmAuthBinding),
+		append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "ConfirmAuth", logger)))...,
+	))
+	m.Handle("/getUEHistory", httptransport.NewServer(
+		endpoints.GetUEHistoryEndpoint,
+		codec.DecodeRequest(getUEHistoryBinding),
+		codec.EncodeResponse(getUEHistoryBinding),
+		append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "GetUEHistory", logger)))...,
+	))
+	m.Handle(openapi.Path, openapi.Handler(OpenAPI()))
+	return m
+}
+
+var generateAuthDataBinding = codec.Binding{
+	Request:   func() interface{} { return &endpoints.GenerateAuthDataRequest{} },
+	PBRequest: func() proto.Message { return &pb.GenerateAuthDataRequest{} },
+	FromPB:    decodeGRPCGenerateAuthDataRequest,
+	ToPB:      encodeGRPCGenerateAuthDataResponse,
+}
+
+var createSubscriberBinding = codec.Binding{
+	Request:   func() interface{} { return &endpoints.CreateSubscriberRequest{} },
+	PBRequest: func() proto.Message { return &pb.CreateSubscriberRequest{} },
+	FromPB:    decodeGRPCCreateSubscriberRequest,
+	ToPB:      encodeGRPCCreateSubscriberResponse,
+}
+
+var updateSubscriberBinding = codec.Binding{
+	Request:   func() interface{} { return &endpoints.UpdateSubscriberRequest{} },
+	PBRequest: func() proto.Message { return &pb.UpdateSubscriberRequest{} },
+	FromPB:    decodeGRPCUpdateSubscriberRequest,
+	ToPB:      encodeGRPCUpdateSubscriberResponse,
+}
+
+var deleteSubscriberBinding = codec.Binding{
+	Request:   func() interface{} { return &endpoints.DeleteSubscriberRequest{} },
+	PBRequest: func() proto.Message { return &pb.DeleteSubscriberRequest{} },
+	FromPB:    decodeGRPCDeleteSubscriberRequest,
+	ToPB:      encodeGRPCDeleteSubscriberResponse,
+}
+
+var listSubscribersBinding = codec.Binding{
+	Request:   func() interface{} { return &endpoints.ListSubscribersRequest{} },
+	PBRequest: func() proto.Message { return &pb.ListSubscribersRequest{} },
+	FromPB:    decodeGRPCListSubscribersRequest,
+	ToPB:      encodeGRPCListSubscribersResponse,
+}
+
+var confirmAuthBinding = codec.Binding{
+	Request:   func() interface{} { return &endpoints.ConfirmAuthRequest{} },
+	PBRequest: func() proto.Message { return &pb.ConfirmAuthRequest{} },
+	FromPB:    decodeGRPCConfirmAuthRequest,
+	ToPB:      encodeGRPCConfirmAuthResponse,
+}
+
+var getUEHistoryBinding = codec.Binding{
+	Request:   func() interface{} { return &endpoints.GetUEHistoryRequest{} },
+	PBRequest: func() proto.Message { return &pb.GetUEHistoryRequest{} },
+	FromPB:    decodeGRPCGetUEHistoryRequest,
+	ToPB:      encodeGRPCGetUEHistoryResponse,
+}
+
+// NewHTTPClient returns an UdmService backed by an HTTP server living at the
+// remote instance. We expect instance to come from a service discovery system,
+// so likely of the form "host:port". We bake-in certain middlewares,
+// implementing the client library pattern.
+func NewHTTPClient(instance string, otTracer stdopentracing.Tracer, zipkinTracer *stdzipkin.Tracer, logger log.Logger) (service.UdmService, error) {
+	if !strings.HasPrefix(instance, "http") {
+		instance = "http://" + instance
+	}
+	u, err := url.Parse(instance)
+	if err != nil {
+		return nil, err
+	}
+
+	limiter := ratelimit.NewErroringLimiter(rate.NewLimiter(rate.Every(time.Second), 100))
+	// every authentication vector is followed by the result of the
+	// authentication, which is not charged to the same budget
+	confirmLimiter := ratelimit.NewErroringLimiter(rate.NewLimiter(rate.Every(time.Second), 100))
+
+	zipkinClient := zipkin.HTTPClientTrace(zipkinTracer)
+
+	// global client middlewares
+	options := []httptransport.ClientOption{
+		zipkinClient,
+		httptransport.ClientBefore(plmn.ContextToHTTP()),
+		httptransport.ClientBefore(priority.ContextToHTTP()),
+		httptransport.ClientBefore(meta.ContextToHTTP()),
+	}
+
+	e := endpoints.Endpoints{}
+
+	var generateAuthDataEndpoint endpoint.Endpoint
+	{
+		generateAuthDataEndpoint = httptransport.NewClient(
+			"POST",
+			copyURL(u, "/v1/generateAuthData"),
+			encodeHTTPRequest,
+			decodeHTTPGenerateAuthDataResponse,
+			append(options, httptransport.ClientBefore(opentracing.ContextToHTTP(otTracer, logger)))...,
+		).Endpoint()
+		generateAuthDataEndpoint = opentracing.TraceClient(otTracer, "GenerateAuthData")(generateAuthDataEndpoint)
+		generateAuthDataEndpoint = zipkin.TraceEndpoint(zipkinTracer, "GenerateAuthData")(generateAuthDataEndpoint)
+		generateAuthDataEndpoint = limiter(generateAuthDataEndpoint)
+		generateAuthDataEndpoint = circuitbreaker.Gobreaker(breakers.New(gobreaker.Settings{
+			Name:    "GenerateAuthData",
+			Timeout: 30 * time.Second,
+		}))(generateAuthDataEndpoint)
+		e.GenerateAuthDataEndpoint = generateAuthDataEndpoint
+	}
+
+	var createSubscriberEndpoint endpoint.Endpoint
+	{
+		createSubscriberEndpoint = httptransport.NewClient(
+			"POST",
+			copyURL(u, "/v1/createSubscriber"),
+			encodeHTTPRequest,
+			decodeHTTPCreateSubscriberResponse,
+			append(options, httptransport.ClientBefore(opentracing.ContextToHTTP(otTracer, logger)))...,
+		).Endpoint()
+		createSubscriberEndpoint = opentracing.TraceClient(otTracer, "CreateSubscriber")(createSubscriberEndpoint)
+		createSubscriberEndpoint = zipkin.TraceEndpoint(zipkinTracer, "CreateSubscriber")(createSubscriberEndpoint)
+		createSubscriberEndpoint = limiter(createSubscriberEndpoint)
+		createSubscriberEndpoint = circuitbreaker.Gobreaker(breakers.New(gobreaker.Settings{
+			Name:    "CreateSubscriber",
+			Timeout: 30 * time.Second,
+		}))(createSubscriberEndpoint)
+		e.CreateSubscriberEndpoint = createSubscriberEndpoint
+	}
+
+	var updateSubscriberEndpoint endpoint.Endpoint
+	{
+		updateSubscriberEndpoint = httptransport.NewClient(
+			"POST",
+			copyURL(u, "/v1/updateSubscriber"),
+			encodeHTTPRequest,
+			decodeHTTPUpdateSubscriberResponse,
+			append(options, httptransport.ClientBefore(opentracing.ContextToHTTP(otTracer, logger)))...,
+		).Endpoint()
+		updateSubscriberEndpoint = opentracing.TraceClient(otTracer, "UpdateSubscriber")(updateSubscriberEndpoint)
+		updateSubscriberEndpoint = zipkin.TraceEndpoint(zipkinTracer, "UpdateSubscriber")(updateSubscriberEndpoint)
+		updateSubscriberEndpoint = limiter(updateSubscriberEndpoint)
+		updateSubscriberEndpoint = circuitbreaker.Gobreaker(breakers.New(gobreaker.Settings{
+			Name:    "UpdateSubscriber",
+			Timeout: 30 * time.Second,
+		}))(updateSubscriberEndpoint)
+		e.UpdateSubscriberEndpoint = updateSubscriberEndpoint
+	}
+
+	var deleteSubscriberEndpoint endpoint.Endpoint
+	{
+		deleteSubscriberEndpoint = httptransport.NewClient(
+			"POST",
+			copyURL(u, "/v1/deleteSubscriber"),
+			encodeHTTPRequest,
+			decodeHTTPDeleteSubscriberResponse,
+			append(options, httptransport.ClientBefore(opentracing.ContextToHTTP(otTracer, logger)))...,
+		).Endpoint()
+		deleteSubscriberEndpoint = opentracing.TraceClient(otTracer, "DeleteSubscriber")(deleteSubscriberEndpoint)
+		deleteSubscriberEndpoint = zipkin.TraceEndpoint(zipkinTracer, "DeleteSubscriber")(deleteSubscriberEndpoint)
+		deleteSubscriberEndpoint = limiter(deleteSubscriberEndpoint)
+		deleteSubscriberEndpoint = circuitbreaker.Gobreaker(breakers.New(gobreaker.Settings{
+			Name:    "DeleteSubscriber",
+			Timeout: 30 * time.Second,
+		}))(deleteSubscriberEndpoint)
+		e.DeleteSubscriberEndpoint = deleteSubscriberEndpoint
+	}
+
+	var listSubscribersEndpoint endpoint.Endpoint
+	{
+		listSubscribersEndpoint = httptransport.NewClient(
+			"POST",
+			copyURL(u, "/v1/listSubscribers"),
+			encodeHTTPRequest,
+			decodeHTTPListSubscribersResponse,
+			append(options, httptransport.ClientBefore(opentracing.ContextToHTTP(otTracer, logger)))...,
+		).Endpoint()
+		listSubscribersEndpoint = opentracing.TraceClient(otTracer, "ListSubscribers")(listSubscribersEndpoint)
+		listSubscribersEndpoint = zipkin.TraceEndpoint(zipkinTracer, "ListSubscribers")(listSubscribersEndpoint)
+		listSubscribersEndpoint = limiter(listSubscribersEndpoint)
+		listSubscribersEndpoint = circuitbreaker.Gobreaker(breakers.New(gobreaker.Settings{
+			Name:    "ListSubscribers",
+			Timeout: 30 * time.Second,
+		}))(listSubscribersEndpoint)
+		e.ListSubscribersEndpoint = listSubscribersEndpoint
+	}
+
+	var confirmAuthEndpoint endpoint.Endpoint
+	{
+		confirmAuthEndpoint = httptransport.NewClient(
+			"POST",
+			copyURL(u, "/v1/confirmAuth"),
+			encodeHTTPRequest,
+			decodeHTTPConfirmAuthResponse,
+			append(options, httptransport.ClientBefore(opentracing.ContextToHTTP(otTracer, logger)))...,
+		).Endpoint()
+		confirmAuthEndpoint = opentracing.TraceClient(otTracer, "ConfirmAuth")(confirmAuthEndpoint)
+		confirmAuthEndpoint = zipkin.TraceEndpoint(zipkinTracer, "ConfirmAuth")(confirmAuthEndpoint)
+		confirmAuthEndpoint = confirmLimiter(confirmAuthEndpoint)
+		confirmAuthEndpoint = circuitbreaker.Gobreaker(breakers.New(gobreaker.Settings{
+			Name:    "ConfirmAuth",
+			Timeout: 30 * time.Second,
+		}))(confirmAuthEndpoint)
+		e.ConfirmAuthEndpoint = confirmAuthEndpoint
+	}
+
+	var getUEHistoryEndpoint endpoint.Endpoint
+	{
+		getUEHistoryEndpoint = httptransport.NewClient(
+			"POST",
+			copyURL(u, "/v1/getUEHistory"),
+			encodeHTTPRequest,
+			decodeHTTPGetUEHistoryResponse,
+			append(options, httptransport.ClientBefore(opentracing.ContextToHTTP(otTracer, logger)))...,
+		).Endpoint()
+		getUEHistoryEndpoint = opentracing.TraceClient(otTracer, "GetUEHistory")(getUEHistoryEndpoint)
+		getUEHistoryEndpoint = zipkin.TraceEndpoint(zipkinTracer, "GetUEHistory")(getUEHistoryEndpoint)
+		getUEHistoryEndpoint = limiter(getUEHistoryEndpoint)
+		getUEHistoryEndpoint = circuitbreaker.Gobreaker(breakers.New(gobreaker.Settings{
+			Name:    "GetUEHistory",
+			Timeout: 30 * time.Second,
+		}))(getUEHistoryEndpoint)
+		e.GetUEHistoryEndpoint = getUEHistoryEndpoint
+	}
+
+	return e, nil
+}
+
+func copyURL(base *url.URL, path string) *url.URL {
+	next := *base
+	next.Path = path
+	return &next
+}
+
+// encodeHTTPRequest is a transport/http.EncodeRequestFunc that
+// JSON-encodes any request to the request body. Primarily useful in a client.
+func encodeHTTPRequest(_ context.Context, r *http.Request, request interface{}) (err error) {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(request); err != nil {
+		return err
+	}
+	r.Body = ioutil.NopCloser(&buf)
+	return nil
+}
+
+// decodeHTTPGenerateAuthDataResponse is a transport/http.DecodeResponseFunc that decodes a
+// JSON-encoded generateAuthData response from the HTTP response body. If the response has a
+// non-200 status code, we will interpret that as an error and attempt to decode
+// the specific error message from the response body. Primarily useful in a client.
+func decodeHTTPGenerateAuthDataResponse(_ context.Context, r *http.Response) (interface{}, error) {
+	if r.StatusCode != http.StatusOK {
+		return nil, JSONErrorDecoder(r)
+	}
+	var resp endpoints.GenerateAuthDataResponse
+	err := json.NewDecoder(r.Body).Decode(&resp)
+	return resp, err
+}
+
+// decodeHTTPCreateSubscriberResponse is a transport/http.DecodeResponseFunc that decodes a
+// JSON-encoded createSubscriber response from the HTTP response body. If the response has a
+// non-200 status code, we will interpret that as an error and attempt to decode
+// the specific error message from the response body. Primarily useful in a client.
+func decodeHTTPCreateSubscriberResponse(_ context.Context, r *http.Response) (interface{}, error) {
+	if r.StatusCode != http.StatusOK {
+		return nil, JSONErrorDecoder(r)
+	}
+	var resp endpoints.CreateSubscriberResponse
+	err := json.NewDecoder(r.Body).Decode(&resp)
+	return resp, err
+}
+
+// decodeHTTPUpdateSubscriberResponse is a transport/http.DecodeResponseFunc that decodes a
+// JSON-encoded updateSubscriber response from the HTTP response body. If the response has a
+// non-200 status code, we will interpret that as an error and attempt to decode
+// the specific error message from the response body. Primarily useful in a client.
+func decodeHTTPUpdateSubscriberResponse(_ context.Context, r *http.Response) (interface{}, error) {
+	if r.StatusCode != http.StatusOK {
+		return nil, JSONErrorDecoder(r)
+	}
+	var resp endpoints.UpdateSubscriberResponse
+	err := json.NewDecoder(r.Body).Decode(&resp)
+	return resp, err
+}
+
+// decodeHTTPDeleteSubscriberResponse is a transport/http.DecodeResponseFunc that decodes a
+// JSON-encoded deleteSubscriber response from the HTTP response body. If the response has a
+// non-200 status code, we will interpret that as an error and attempt to decode
+// the specific error message from the response body. Primarily useful in a client.
+func decodeHTTPDeleteSubscriberResponse(_ context.Context, r *http.Response) (interface{}, error) {
+	if r.StatusCode != http.StatusOK {
+		return nil, JSONErrorDecoder(r)
+	}
+	var resp endpoints.DeleteSubscriberResponse
+	err := json.NewDecoder(r.Body).Decode(&resp)
+	return resp, err
+}
+
+// decodeHTTPListSubscribersResponse is a transport/http.DecodeResponseFunc that decodes a
+// JSON-encoded listSubscribers response from the HTTP response body. If the response has a
+// non-200 status code, we will interpret that as an error and attempt to decode
+// the specific error message from the response body. Primarily useful in a client.
+func decodeHTTPListSubscribersResponse(_ context.Context, r *http.Response) (interface{}, error) {
+	if r.StatusCode != http.StatusOK {
+		return nil, JSONErrorDecoder(r)
+	}
+	var resp endpoints.ListSubscribersResponse
+	err := json.NewDecoder(r.Body).Decode(&resp)
+	return resp, err
+}
+
+// decodeHTTPConfirmAuthResponse is a transport/http.DecodeResponseFunc that decodes a
+// JSON-encoded confirmAuth response from the HTTP response body. If the response has a
+// non-200 status code, we will interpret that as an error and attempt to decode
+// the specific error message from the response body. Primarily useful in a client.
+func decodeHTTPConfirmAuthResponse(_ context.Context, r *http.Response) (interface{}, error) {
+	if r.StatusCode != http.StatusOK {
+		return nil, JSONErrorDecoder(r)
+	}
+	var resp endpoints.ConfirmAuthResponse
+	err := json.NewDecoder(r.Body).Decode(&resp)
+	return resp, err
+}
+
+// decodeHTTPGetUEHistoryResponse is a transport/http.DecodeResponseFunc that decodes a
+// JSON-encoded getUEHistory response from the HTTP response body. If the response has a
+// non-200 status code, we will interpret that as an error and attempt to decode
+// the specific error message from the response body. Primarily useful in a client.
+func decodeHTTPGetUEHistoryResponse(_ context.Context, r *http.Response) (interface{}, error) {
+	if r.StatusCode != http.StatusOK {
+		return nil, JSONErrorDecoder(r)
+	}
+	var resp endpoints.GetUEHistoryResponse
+	err := json.NewDecoder(r.Body).Decode(&resp)
+	return resp, err
+}
+
+func httpEncodeError(_ context.Context, err error, w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if lberr, ok := err.(lb.RetryError); ok {
+		st, _ := status.FromError(lberr.Final)
+		w.WriteHeader(HTTPStatusFromCode(st.Code()))
+		json.NewEncoder(w).Encode(errorWrapper{Error: st.Message()})
+	} else {
+		st, ok := status.FromError(err)
+		if ok {
+			w.WriteHeader(HTTPStatusFromCode(st.Code()))
+			json.NewEncoder(w).Encode(errorWrapper{Error: st.Message()})
+		} else {
+			switch err {
+			case io.ErrUnexpectedEOF:
+				w.WriteHeader(http.StatusBadRequest)
+			case io.EOF:
+				w.WriteHeader(http.StatusBadRequest)
+			default:
+				switch err.(type) {
+				case *json.SyntaxError:
+					w.WriteHeader(http.StatusBadRequest)
+				case *json.UnmarshalTypeError:
+					w.WriteHeader(http.StatusBadRequest)
+				default:
+					w.WriteHeader(http.StatusInternalServerError)
+				}
+			}
+			json.NewEncoder(w).Encode(errorWrapper{Error: err.Error()})
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package transports
+
+import (
+	"net/http"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/tracing/opentracing"
+	httptransport "github.com/go-kit/kit/transport/http"
+	"github.com/golang/protobuf/proto"
+	stdopentracing "github.com/opentracing/opentracing-go"
+	stdzipkin "github.com/openzipkin/zipkin-go"
+
+	pbv2 "github.com/miki-tnt/sa5g-go-usvc-k8s/pb/udm/v2"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/codec"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/udm/endpoints"
+)
+
+// codecsV2 serialize the bodies of version 2 of the HTTP API, the messages of
+// version 2 of the gRPC API in JSON or protobuf.
+var codecsV2 = codec.NewRegistry(codec.ProtoJSON, codec.Protobuf)
+
+// newHTTPHandlerV2 returns the handler of version 2 of the API. Its bodies
+// are the messages of version 2 of the gRPC API, converted to the endpoints
+// requests and responses as by MakeGRPCServerV2, so that the JSON bodies of
+// HTTP and gRPC clients are the same.
+func newHTTPHandlerV2(endpoints endpoints.Endpoints, otTracer stdopentracing.Tracer, zipkinTracer *stdzipkin.Tracer, logger log.Logger) http.Handler {
+	options := httpServerOptions(codecsV2, zipkinTracer, logger)
+
+	m := http.NewServeMux()
+	m.Handle("/generateAuthData", httptransport.NewServer(
+		endpoints.GenerateAuthDataEndpoint,
+		codec.DecodeRequest(generateAuthDataBindingV2),
+		codec.EncodeResponse(generateAuthDataBindingV2),
+		append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "GenerateAuthData", logger)))...,
+	))
+	m.Handle("/createSubscriber", httptransport.NewServer(
+		endpoints.CreateSubscriberEndpoint,
+		codec.DecodeRequest(createSubscriberBindingV2),
+		codec.EncodeResponse(createSubscriberBindingV2),
+		append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "CreateSubscriber", logger)))...,
+	))
+	m.Handle("/updateSubscriber", httptransport.NewServer(
+		endpoints.UpdateSubscriberEndpoint,
+		codec.DecodeRequest(updateSubscriberBindingV2),
+		codec.EncodeResponse(updateSubscriberBindingV2),
+		append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "UpdateSubscriber", logger)))...,
+	))
+	m.Handle("/deleteSubscriber", httptransport.NewServer(
+		endpoints.DeleteSubscriberEndpoint,
+		codec.DecodeRequest(deleteSubscriberBindingV2),
+		codec.EncodeResponse(deleteSubscriberBindingV2),
+		append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "DeleteSubscriber", logger)))...,
+	))
+	m.Handle("/listSubscribers", httptransport.NewServer(
+		endpoints.ListSubscribersEndpoint,
+		codec.DecodeRequest(listSubscribersBindingV2),
+		codec.EncodeResponse(listSubscribersBindingV2),
+		append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "ListSubscribers", logger)))...,
+	))
+	m.Handle("/confirmAuth", httptransport.NewServer(
+		endpoints.ConfirmAuthEndpoint,
+		codec.DecodeRequest(confirmAuthBindingV2),
+		codec.EncodeResponse(confirmAuthBindingV2),
+		append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "ConfirmAuth", logger)))...,
+	))
+	m.Handle("/getUEHistory", httptransport.NewServer(
+		endpoints.GetUEHistoryEndpoint,
+		codec.DecodeRequest(getUEHistoryBindingV2),
+		codec.EncodeResponse(getUEHistoryBindingV2),
+		append(options, httptransport.ServerBefore(opentracing.HTTPToContext(otTracer, "GetUEHistory", logger)))...,
+	))
+	return m
+}
+
+// generateAuthDataBindingV2 binds the GenerateAuthData method to its requests and
+// replies of version 2.
+var generateAuthDataBindingV2 = codec.Binding{
+	Request:   func() interface{} { return &endpoints.GenerateAuthDataRequest{} },
+	PBRequest: func() proto.Message { return &pbv2.GenerateAuthDataRequest{} },
+	FromPB:    decodeGRPCGenerateAuthDataRequestV2,
+	ToPB:      encodeGRPCGenerateAuthDataResponseV2,
+}
+
+// createSubscriberBindingV2 binds the CreateSubscriber method to its requests and
+// replies of version 2.
+var createSubscriberBindingV2 = codec.Binding{
+	Request:   func() interface{} { return &endpoints.CreateSubscriberRequest{} },
+	PBRequest: func() proto.Message { return &pbv2.CreateSubscriberRequest{} },
+	FromPB:    decodeGRPCCreateSubscriberRequestV2,
+	ToPB:      encodeGRPCCreateSubscriberResponseV2,
+}
+
+// updateSubscriberBindingV2 binds the UpdateSubscriber method to its requests and
+// replies of version 2.
+var updateSubscriberBindingV2 = codec.Binding{
+	Request:   func() interface{} { return &endpoints.UpdateSubscriberRequest{} },
+	PBRequest: func() proto.Message { return &pbv2.UpdateSubscriberRequest{} },
+	FromPB:    decodeGRPCUpdateSubscriberRequestV2,
+	ToPB:      encodeGRPCUpdateSubscriberResponseV2,
+}
+
+// deleteSubscriberBindingV2 binds the DeleteSubscriber method to its requests and
+// replies of version 2.
+var deleteSubscriberBindingV2 = codec.Binding{
+	Request:   func() interface{} { return &endpoints.DeleteSubscriberRequest{} },
+	PBRequest: func() proto.Message { return &pbv2.DeleteSubscriberRequest{} },
+	FromPB:    decodeGRPCDeleteSubscriberRequestV2,
+	ToPB:      encodeGRPCDeleteSubscriberResponseV2,
+}
+
+// listSubscribersBindingV2 binds the ListSubscribers method to its requests and
+// replies of version 2.
+var listSubscribersBindingV2 = codec.Binding{
+	Request:   func() interface{} { return &endpoints.ListSubscribersRequest{} },
+	PBRequest: func() proto.Message { return &pbv2.ListSubscribersRequest{} },
+	FromPB:    decodeGRPCListSubscribersRequestV2,
+	ToPB:      encodeGRPCListSubscribersResponseV2,
+}
+
+// confirmAuthBindingV2 binds the ConfirmAuth method to its requests and
+// replies of version 2.
+var confirmAuthBindingV2 = codec.Binding{
+	Request:   func() interface{} { return &endpoints.ConfirmAuthRequest{} },
+	PBRequest: func() proto.Message { return &pbv2.ConfirmAuthRequest{} },
+	FromPB:    decodeGRPCConfirmAuthRequestV2,
+	ToPB:      encodeGRPCConfirmAuthResponseV2,
+}
+
+// getUEHistoryBindingV2 binds the GetUEHistory method to its requests and
+// replies of version 2.
+var getUEHistoryBindingV2 = codec.Binding{
+	Request:   func() interface{} { return &endpoints.GetUEHistoryRequest{} },
+	PBRequest: func() proto.Message { return &pbv2.GetUEHistoryRequest{} },
+	FromPB:    decodeGRPCGetUEHistoryRequestV2,
+	ToPB:      encodeGRPCGetUEHistoryResponseV2,
+}
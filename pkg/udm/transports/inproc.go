@@ -0,0 +1,61 @@
+package transports
+
+import (
+	"github.com/go-kit/kit/log"
+
+	pb "github.com/miki-tnt/sa5g-go-usvc-k8s/pb/udm"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/caller"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/kitx/inproc"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/logging"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/meta"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/plmn"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/priority"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/udm/endpoints"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/udm/service"
+)
+
+// MakeInprocServer makes a set of endpoints available to the in-process
+// clients of NewInprocClient, with the codecs and metadata of the gRPC
+// transport, version 1 of the API. The caller runs its Serve method and
+// eventually closes it.
+func MakeInprocServer(endpoints endpoints.Endpoints, logger log.Logger) *inproc.Server {
+	options := []inproc.ServerOption{
+		inproc.ServerErrorHandler(logging.ErrorHandler(logger)),
+		inproc.ServerErrorEncoder(grpcEncodeError),
+		inproc.ServerBefore(plmn.GRPCToContext()),
+		inproc.ServerBefore(priority.GRPCToContext()),
+		inproc.ServerBefore(caller.GRPCToContext()),
+		inproc.ServerBefore(meta.GRPCToContext()),
+	}
+
+	s := inproc.NewServer()
+	s.Handle("GenerateAuthData", endpoints.GenerateAuthDataEndpoint, decodeGRPCGenerateAuthDataRequest, encodeGRPCGenerateAuthDataResponse, pb.GenerateAuthDataRequest{}, options...)
+	s.Handle("CreateSubscriber", endpoints.CreateSubscriberEndpoint, decodeGRPCCreateSubscriberRequest, encodeGRPCCreateSubscriberResponse, pb.CreateSubscriberRequest{}, options...)
+	s.Handle("UpdateSubscriber", endpoints.UpdateSubscriberEndpoint, decodeGRPCUpdateSubscriberRequest, encodeGRPCUpdateSubscriberResponse, pb.UpdateSubscriberRequest{}, options...)
+	s.Handle("DeleteSubscriber", endpoints.DeleteSubscriberEndpoint, decodeGRPCDeleteSubscriberRequest, encodeGRPCDeleteSubscriberResponse, pb.DeleteSubscriberRequest{}, options...)
+	s.Handle("ListSubscribers", endpoints.ListSubscribersEndpoint, decodeGRPCListSubscribersRequest, encodeGRPCListSubscribersResponse, pb.ListSubscribersRequest{}, options...)
+	s.Handle("ConfirmAuth", endpoints.ConfirmAuthEndpoint, decodeGRPCConfirmAuthRequest, encodeGRPCConfirmAuthResponse, pb.ConfirmAuthRequest{}, options...)
+	s.Handle("GetUEHistory", endpoints.GetUEHistoryEndpoint, decodeGRPCGetUEHistoryRequest, encodeGRPCGetUEHistoryResponse, pb.GetUEHistoryRequest{}, options...)
+	return s
+}
+
+// NewInprocClient returns an UdmService calling the server of
+// MakeInprocServer. Unlike the gRPC client, it bakes in no rate limit,
+// circuit breaker or hedging, so that the tests using it are deterministic.
+func NewInprocClient(s *inproc.Server) service.UdmService {
+	options := []inproc.ClientOption{
+		inproc.ClientBefore(plmn.ContextToGRPC()),
+		inproc.ClientBefore(priority.ContextToGRPC()),
+		inproc.ClientBefore(meta.ContextToGRPC()),
+	}
+
+	return endpoints.Endpoints{
+		GenerateAuthDataEndpoint: inproc.NewClient(s, "GenerateAuthData", encodeGRPCGenerateAuthDataRequest, decodeGRPCGenerateAuthDataResponse, pb.GenerateAuthDataReply{}, options...).Endpoint(),
+		CreateSubscriberEndpoint: inproc.NewClient(s, "CreateSubscriber", encodeGRPCCreateSubscriberRequest, decodeGRPCCreateSubscriberResponse, pb.CreateSubscriberReply{}, options...).Endpoint(),
+		UpdateSubscriberEndpoint: inproc.NewClient(s, "UpdateSubscriber", encodeGRPCUpdateSubscriberRequest, decodeGRPCUpdateSubscriberResponse, pb.UpdateSubscriberReply{}, options...).Endpoint(),
+		DeleteSubscriberEndpoint: inproc.NewClient(s, "DeleteSubscriber", encodeGRPCDeleteSubscriberRequest, decodeGRPCDeleteSubscriberResponse, pb.DeleteSubscriberReply{}, options...).Endpoint(),
+		ListSubscribersEndpoint:  inproc.NewClient(s, "ListSubscribers", encodeGRPCListSubscribersRequest, decodeGRPCListSubscribersResponse, pb.ListSubscribersReply{}, options...).Endpoint(),
+		ConfirmAuthEndpoint:      inproc.NewClient(s, "ConfirmAuth", encodeGRPCConfirmAuthRequest, decodeGRPCConfirmAuthResponse, pb.ConfirmAuthReply{}, options...).Endpoint(),
+		GetUEHistoryEndpoint:     inproc.NewClient(s, "GetUEHistory", encodeGRPCGetUEHistoryRequest, decodeGRPCGetUEHistoryResponse, pb.GetUEHistoryReply{}, options...).Endpoint(),
+	}
+}
@@ -0,0 +1,62 @@
+package transports
+
+import (
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/openapi"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/udm/endpoints"
+)
+
+// OpenAPI returns the description of the HTTP transport, served by
+// NewHTTPHandler on openapi.Path.
+func OpenAPI() *openapi.Document {
+	return openapi.New("udm", "v1",
+		openapi.Operation{
+			Path:     "/v1/generateAuthData",
+			ID:       "GenerateAuthData",
+			Summary:  "GenerateAuthData returns a 5G-AKA home environment authentication vector.",
+			Request:  endpoints.GenerateAuthDataRequest{},
+			Response: endpoints.GenerateAuthDataResponse{},
+		},
+		openapi.Operation{
+			Path:     "/v1/createSubscriber",
+			ID:       "CreateSubscriber",
+			Summary:  "CreateSubscriber provisions a subscriber.",
+			Request:  endpoints.CreateSubscriberRequest{},
+			Response: endpoints.CreateSubscriberResponse{},
+		},
+		openapi.Operation{
+			Path:     "/v1/updateSubscriber",
+			ID:       "UpdateSubscriber",
+			Summary:  "UpdateSubscriber changes the non-empty fields of a subscriber.",
+			Request:  endpoints.UpdateSubscriberRequest{},
+			Response: endpoints.UpdateSubscriberResponse{},
+		},
+		openapi.Operation{
+			Path:     "/v1/deleteSubscriber",
+			ID:       "DeleteSubscriber",
+			Summary:  "DeleteSubscriber removes a subscriber.",
+			Request:  endpoints.DeleteSubscriberRequest{},
+			Response: endpoints.DeleteSubscriberResponse{},
+		},
+		openapi.Operation{
+			Path:     "/v1/listSubscribers",
+			ID:       "ListSubscribers",
+			Summary:  "ListSubscribers returns a page of subscribers, without K and OPc.",
+			Request:  endpoints.ListSubscribersRequest{},
+			Response: endpoints.ListSubscribersResponse{},
+		},
+		openapi.Operation{
+			Path:     "/v1/confirmAuth",
+			ID:       "ConfirmAuth",
+			Summary:  "ConfirmAuth records the result of an authentication in the history of the UE.",
+			Request:  endpoints.ConfirmAuthRequest{},
+			Response: endpoints.ConfirmAuthResponse{},
+		},
+		openapi.Operation{
+			Path:     "/v1/getUEHistory",
+			ID:       "GetUEHistory",
+			Summary:  "GetUEHistory returns a page of the events of a UE in a time range, oldest first.",
+			Request:  endpoints.GetUEHistoryRequest{},
+			Response: endpoints.GetUEHistoryResponse{},
+		},
+	)
+}
@@ -0,0 +1,86 @@
+package uetrace
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// ActivateRequest is the body of a PUT to Handler.
+type ActivateRequest struct {
+	UEID string `json:"ue_id"`
+	// TTL is a time.ParseDuration string, DefaultTTL when empty.
+	TTL string `json:"ttl,omitempty"`
+	// Reference is the trace reference, a random one when empty.
+	Reference string `json:"trace_reference,omitempty"`
+}
+
+type errorWrapper struct {
+	Error string `json:"error"`
+}
+
+// Handler serves the trace activations of l over HTTP:
+//
+//	GET    lists the activations in effect
+//	PUT    activates the trace of a UE from an ActivateRequest body
+//	DELETE deactivates the trace of the UE of the ?ue_id= query parameter
+func Handler(l *List) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.Method {
+		case http.MethodGet:
+		case http.MethodPut, http.MethodPost:
+			var ar ActivateRequest
+			if err := json.NewDecoder(req.Body).Decode(&ar); err != nil {
+				writeError(w, http.StatusBadRequest, err)
+				return
+			}
+			var ttl time.Duration
+			if ar.TTL != "" {
+				var err error
+				if ttl, err = time.ParseDuration(ar.TTL); err != nil {
+					writeError(w, http.StatusBadRequest, err)
+					return
+				}
+			}
+			if ar.UEID == "" {
+				writeError(w, http.StatusBadRequest, ErrNoUE)
+				return
+			}
+			if _, err := l.Activate(req.Context(), ar.UEID, ttl, ar.Reference); err != nil {
+				writeError(w, http.StatusInternalServerError, err)
+				return
+			}
+		case http.MethodDelete:
+			if req.URL.Query().Get("ue_id") == "" {
+				writeError(w, http.StatusBadRequest, ErrNoUE)
+				return
+			}
+			if err := l.Deactivate(req.Context(), req.URL.Query().Get("ue_id")); err != nil {
+				writeError(w, http.StatusInternalServerError, err)
+				return
+			}
+		default:
+			w.Header().Set("Allow", "GET, PUT, DELETE")
+			writeError(w, http.StatusMethodNotAllowed, nil)
+			return
+		}
+
+		activations, err := l.Activations(req.Context())
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(activations)
+	})
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	msg := http.StatusText(code)
+	if err != nil {
+		msg = err.Error()
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(errorWrapper{Error: msg})
+}
@@ -0,0 +1,268 @@
+// Package uetrace activates the tracing of single UEs, as the signalling
+// based trace activation of 3GPP (TS 32.422 4.1.2) does, rather than raising
+// the log level of a whole service: the services log every message of a UE
+// whose trace is active in full, and nothing more of the others.
+//
+// The activations are kept in a store under the SUPI, the SUCI or the
+// 5G-GUTI of their UE, for a time to live past which they deactivate by
+// themselves, so that NFs sharing a store, a Redis one, share them. Each
+// List reads them from the store at most once per refresh period, in the
+// background: the checks on the hot path only read its copy, which an
+// activation made elsewhere reaches within the period.
+//
+// The records of a trace carry its trace reference and no level, which the
+// log level filters let through. They dump the messages unredacted,
+// identities included, which is why a trace is activated per UE and on
+// purpose, and expires.
+package uetrace
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/clock"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/logging"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/meta"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/store"
+)
+
+// Path is where services mount Handler on their HTTP server.
+const Path = "/admin/uetrace"
+
+const keyPrefix = "uetrace/"
+
+// ErrNoUE is returned when the UE of an activation is missing.
+var ErrNoUE = errors.New("uetrace: no UE")
+
+// DefaultTTL is the lifetime of an activation made without an explicit ttl,
+// DefaultRefresh the period the activations are read from the store with.
+const (
+	DefaultTTL     = time.Hour
+	DefaultRefresh = 5 * time.Second
+)
+
+// Activation is the activation of the trace of a UE.
+type Activation struct {
+	// UEID is the SUPI, the SUCI or the 5G-GUTI of the UE.
+	UEID string `json:"ue_id"`
+	// Reference identifies the trace in its records.
+	Reference string    `json:"trace_reference"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Option sets an optional parameter of a List.
+type Option func(*List)
+
+// Logger sets the logger of the records of the traces, which should not be
+// sampled.
+func Logger(logger log.Logger) Option {
+	return func(l *List) { l.logger = logger }
+}
+
+// Refresh sets the period the activations are read from the store with,
+// DefaultRefresh by default.
+func Refresh(d time.Duration) Option {
+	return func(l *List) { l.refresh = d }
+}
+
+// Clock sets the clock the activations expire on, clock.Real by default.
+// The store keeps its own time for their keys.
+func Clock(c clock.Clock) Option {
+	return func(l *List) { l.clock = c }
+}
+
+// List is the list of the trace activations of a store. A nil List traces
+// no UE.
+type List struct {
+	st      store.Store
+	logger  log.Logger
+	refresh time.Duration
+	clock   clock.Clock
+
+	mtx        sync.Mutex
+	active     map[string]Activation
+	refreshed  time.Time
+	refreshing bool
+}
+
+// New returns the List of the activations kept in st.
+func New(st store.Store, options ...Option) *List {
+	l := &List{
+		st:      st,
+		logger:  log.NewNopLogger(),
+		refresh: DefaultRefresh,
+		clock:   clock.Real,
+		active:  make(map[string]Activation),
+	}
+	for _, option := range options {
+		option(l)
+	}
+	return l
+}
+
+// Activate activates the trace of the UE ueID for ttl, DefaultTTL when
+// zero, under reference, or under a random one when empty. It replaces the
+// activation of the UE, if any.
+func (l *List) Activate(ctx context.Context, ueID string, ttl time.Duration, reference string) (Activation, error) {
+	if ueID == "" {
+		return Activation{}, ErrNoUE
+	}
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	if reference == "" {
+		var b [3]byte
+		rand.Read(b[:])
+		reference = hex.EncodeToString(b[:])
+	}
+	a := Activation{UEID: ueID, Reference: reference, ExpiresAt: l.clock.Now().Add(ttl)}
+	b, err := json.Marshal(a)
+	if err != nil {
+		return Activation{}, err
+	}
+	if err := l.st.Set(ctx, keyPrefix+ueID, b, ttl); err != nil {
+		return Activation{}, err
+	}
+	l.mtx.Lock()
+	l.active[ueID] = a
+	l.mtx.Unlock()
+	level.Info(l.logger).Log("trace_reference", reference, "activated", ttl)
+	return a, nil
+}
+
+// Deactivate deactivates the trace of the UE ueID. Deactivating the trace of
+// a UE not traced is not an error.
+func (l *List) Deactivate(ctx context.Context, ueID string) error {
+	if err := l.st.Delete(ctx, keyPrefix+ueID); err != nil {
+		return err
+	}
+	l.mtx.Lock()
+	a, ok := l.active[ueID]
+	delete(l.active, ueID)
+	l.mtx.Unlock()
+	if ok {
+		level.Info(l.logger).Log("trace_reference", a.Reference, "deactivated", true)
+	}
+	return nil
+}
+
+// Activations returns the activations of the store, by UE. It walks the
+// keys of the store, and refreshes the copy of l with them.
+func (l *List) Activations(ctx context.Context) ([]Activation, error) {
+	keys, err := l.st.Keys(ctx, keyPrefix)
+	if err != nil {
+		return nil, err
+	}
+	now := l.clock.Now()
+	active := make(map[string]Activation, len(keys))
+	res := []Activation{}
+	for _, key := range keys {
+		b, err := l.st.Get(ctx, key)
+		if err == store.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		var a Activation
+		if err := json.Unmarshal(b, &a); err != nil || !now.Before(a.ExpiresAt) {
+			continue
+		}
+		active[a.UEID] = a
+		res = append(res, a)
+	}
+	l.mtx.Lock()
+	l.active, l.refreshed = active, now
+	l.mtx.Unlock()
+	return res, nil
+}
+
+// Active returns the activation of the first of ids, the identities of a UE,
+// whose trace is active. It reads the copy of l, refreshing it in the
+// background once older than the refresh period.
+func (l *List) Active(ids ...string) (Activation, bool) {
+	if l == nil {
+		return Activation{}, false
+	}
+	now := l.clock.Now()
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	if !l.refreshing && now.Sub(l.refreshed) >= l.refresh {
+		l.refreshing = true
+		go l.load()
+	}
+	for _, id := range ids {
+		if a, ok := l.active[id]; ok && id != "" && now.Before(a.ExpiresAt) {
+			return a, true
+		}
+	}
+	return Activation{}, false
+}
+
+func (l *List) load() {
+	ctx, cancel := context.WithTimeout(context.Background(), l.refresh)
+	defer cancel()
+	if _, err := l.Activations(ctx); err != nil {
+		level.Warn(l.logger).Log("refresh", "failed", "err", err)
+	}
+	l.mtx.Lock()
+	l.refreshing = false
+	l.refreshed = l.clock.Now()
+	l.mtx.Unlock()
+}
+
+// Log logs keyvals as a record of the trace a, along with the metadata of
+// ctx.
+func (l *List) Log(ctx context.Context, a Activation, keyvals ...interface{}) {
+	kv := append([]interface{}{"trace_reference", a.Reference}, logging.ContextKeyvals(ctx)...)
+	l.logger.Log(append(kv, keyvals...)...)
+}
+
+// Middleware returns an endpoint middleware that logs the requests of
+// method concerning a UE whose trace is active, the UE of the context or of
+// the request (see meta.UERequest), with their responses or errors and the
+// time they took. A nil List traces nothing.
+func Middleware(l *List, method string) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		if l == nil {
+			return next
+		}
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			ids := []string{meta.FromContext(ctx).UEID}
+			if r, ok := request.(meta.UERequest); ok {
+				ids = append(ids, r.UEID())
+			}
+			a, ok := l.Active(ids...)
+			if !ok {
+				return next(ctx, request)
+			}
+			begin := l.clock.Now()
+			response, err := next(ctx, request)
+			l.Log(ctx, a, "method", method, "request", Dump(request), "response", Dump(response), "err", err, "took", l.clock.Now().Sub(begin))
+			return response, err
+		}
+	}
+}
+
+// Dump returns the message m in full: its JSON, or its Go syntax when it
+// has none.
+func Dump(m interface{}) string {
+	if b, ok := m.([]byte); ok {
+		return string(b)
+	}
+	b, err := json.Marshal(m)
+	if err != nil {
+		return fmt.Sprintf("%#v", m)
+	}
+	return string(b)
+}
@@ -0,0 +1,178 @@
+package xds
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/go-kit/kit/log/level"
+	"github.com/golang/protobuf/ptypes"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/miki-tnt/sa5g-go-usvc-k8s/pb/xds"
+)
+
+// outliers is the outlier detection of a cluster, with the defaults of
+// Envoy for the fields not set.
+type outliers struct {
+	consecutive int
+	// enforcing is the percentage of the detections ejecting the endpoint,
+	// maxPercent that of the endpoints ejected at once.
+	enforcing  int
+	maxPercent int
+	interval   time.Duration
+	base, max  time.Duration
+}
+
+// outlierConfig returns the outlier detection of od, nil for none.
+func outlierConfig(od *pb.OutlierDetection) *outliers {
+	if od == nil {
+		return nil
+	}
+	o := &outliers{
+		consecutive: 5,
+		enforcing:   100,
+		maxPercent:  10,
+		interval:    10 * time.Second,
+		base:        30 * time.Second,
+		max:         300 * time.Second,
+	}
+	if v := od.GetConsecutive_5Xx(); v != nil {
+		o.consecutive = int(v.Value)
+	}
+	if v := od.GetEnforcingConsecutive_5Xx(); v != nil {
+		o.enforcing = int(v.Value)
+	}
+	if v := od.GetMaxEjectionPercent(); v != nil {
+		o.maxPercent = int(v.Value)
+	}
+	if d, err := ptypes.Duration(od.GetInterval()); err == nil && od.GetInterval() != nil {
+		o.interval = d
+	}
+	if d, err := ptypes.Duration(od.GetBaseEjectionTime()); err == nil && od.GetBaseEjectionTime() != nil {
+		o.base = d
+	}
+	if d, err := ptypes.Duration(od.GetMaxEjectionTime()); err == nil && od.GetMaxEjectionTime() != nil {
+		o.max = d
+	}
+	if o.max < o.base {
+		o.max = o.base
+	}
+	if o.consecutive <= 0 {
+		return nil
+	}
+	return o
+}
+
+// host is the outlier detection state of an endpoint.
+type host struct {
+	consecutive  int
+	ejections    int
+	ejectedUntil time.Time
+	// since is the time of the last change of ejections
+	since time.Time
+}
+
+func (h *host) ejected(now time.Time) bool {
+	return h != nil && now.Before(h.ejectedUntil)
+}
+
+// backendFailure tells whether err, returned by a call, is the failure of
+// the endpoint rather than of the request, as a 5xx status would be.
+func backendFailure(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.Internal, codes.Unknown:
+		return true
+	}
+	return false
+}
+
+// record counts the call to addr in the cluster name, which returned err,
+// and ejects the endpoint when its calls have failed consecutive times in a
+// row. An endpoint ejected again stays out longer each time, and gets an
+// ejection forgiven per interval without one.
+func (t *target) record(name, addr string, err error, now time.Time) {
+	failed := backendFailure(err)
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	cl := t.clusters[name]
+	if cl == nil || cl.outliers == nil {
+		return
+	}
+	o := cl.outliers
+	h, ok := cl.hosts[addr]
+	if !ok {
+		h = &host{}
+		cl.hosts[addr] = h
+	}
+	if !failed {
+		h.consecutive = 0
+		if h.ejections > 0 && !h.ejected(now) && now.Sub(h.since) >= o.interval {
+			h.ejections--
+			h.since = now
+		}
+		return
+	}
+	if h.consecutive++; h.consecutive < o.consecutive || h.ejected(now) {
+		return
+	}
+	h.consecutive = 0
+	if rand.Intn(100) >= o.enforcing {
+		return
+	}
+	ejected := 0
+	for _, other := range cl.hosts {
+		if other.ejected(now) {
+			ejected++
+		}
+	}
+	if ejected*100 >= o.maxPercent*len(cl.endpoints) {
+		return
+	}
+	d := o.base * time.Duration(h.ejections+1)
+	if d > o.max {
+		d = o.max
+	}
+	h.ejections++
+	h.ejectedUntil, h.since = now.Add(d), now
+	cl.ejections++
+	level.Warn(t.c.logger).Log("xds", t.name, "cluster", name, "eject", addr, "for", d)
+}
+
+// Lock and Unlock lock the target for the picks of the balancer of package
+// discovery, the target being the Source of the addresses of its endpoints.
+func (t *target) Lock()   { t.mtx.Lock() }
+func (t *target) Unlock() { t.mtx.Unlock() }
+
+// GroupWeight returns the weight of the cluster name in the route.
+func (t *target) GroupWeight(name string) uint32 {
+	if t.clusters[name] == nil {
+		return 0
+	}
+	return t.weights[name]
+}
+
+// Weight returns the weight of the endpoint addr of the cluster name.
+func (t *target) Weight(name, addr string) uint32 {
+	if cl := t.clusters[name]; cl != nil {
+		return cl.endpoints[addr]
+	}
+	return 0
+}
+
+// Ejected tells whether the endpoint addr of the cluster name is ejected.
+func (t *target) Ejected(name, addr string, now time.Time) bool {
+	if cl := t.clusters[name]; cl != nil {
+		return cl.hosts[addr].ejected(now)
+	}
+	return false
+}
+
+// Picked counts the pick of the endpoint addr of the cluster name, and
+// returns the function recording the result of its call.
+func (t *target) Picked(name, addr string) func(error) {
+	if cl := t.clusters[name]; cl != nil {
+		cl.picks++
+	}
+	return func(err error) { t.record(name, addr, err, time.Now()) }
+}
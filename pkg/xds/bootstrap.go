@@ -0,0 +1,108 @@
+package xds
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	pb "github.com/miki-tnt/sa5g-go-usvc-k8s/pb/xds"
+)
+
+// The variables giving the bootstrap of the xDS client, as for the xDS
+// clients of gRPC: the path of a file, or its content.
+const (
+	EnvBootstrap       = "GRPC_XDS_BOOTSTRAP"
+	EnvBootstrapConfig = "GRPC_XDS_BOOTSTRAP_CONFIG"
+)
+
+// ErrNoBootstrap fails the dials of the xds targets when no bootstrap is
+// given.
+var ErrNoBootstrap = errors.New("xds: no bootstrap, set " + EnvBootstrap)
+
+// Bootstrap is the gRPC xDS bootstrap, the control plane to stream the
+// resources from and the node the client stands for:
+//
+//	{
+//	  "xds_servers": [{"server_uri": "istiod.istio-system:15010", "channel_creds": [{"type": "insecure"}]}],
+//	  "node": {"id": "ausf-7d9f", "cluster": "ausf", "locality": {"zone": "eu-west-1a"}}
+//	}
+//
+// Only the first server is used, over plaintext.
+type Bootstrap struct {
+	XDSServers []Server `json:"xds_servers"`
+	Node       Node     `json:"node"`
+}
+
+// Server is an xDS control plane.
+type Server struct {
+	ServerURI    string `json:"server_uri"`
+	ChannelCreds []struct {
+		Type string `json:"type"`
+	} `json:"channel_creds"`
+}
+
+// Node is the node of the requests to the control plane.
+type Node struct {
+	ID       string `json:"id"`
+	Cluster  string `json:"cluster"`
+	Locality struct {
+		Region  string `json:"region"`
+		Zone    string `json:"zone"`
+		SubZone string `json:"sub_zone"`
+	} `json:"locality"`
+}
+
+// ReadBootstrap reads the bootstrap of EnvBootstrap, or else that of
+// EnvBootstrapConfig, nil when neither is set.
+func ReadBootstrap() (*Bootstrap, error) {
+	data := []byte(os.Getenv(EnvBootstrapConfig))
+	if path := os.Getenv(EnvBootstrap); path != "" {
+		var err error
+		if data, err = ioutil.ReadFile(path); err != nil {
+			return nil, fmt.Errorf("xds: bootstrap: %v", err)
+		}
+	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+	return ParseBootstrap(data)
+}
+
+// ParseBootstrap parses a bootstrap.
+func ParseBootstrap(data []byte) (*Bootstrap, error) {
+	var b Bootstrap
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("xds: bootstrap: %v", err)
+	}
+	if len(b.XDSServers) == 0 || b.XDSServers[0].ServerURI == "" {
+		return nil, errors.New("xds: bootstrap: no xds_servers")
+	}
+	if creds := b.XDSServers[0].ChannelCreds; len(creds) > 0 {
+		insecure := false
+		for _, c := range creds {
+			insecure = insecure || c.Type == "insecure"
+		}
+		if !insecure {
+			return nil, fmt.Errorf("xds: bootstrap: channel_creds %s not supported, only insecure", creds[0].Type)
+		}
+	}
+	return &b, nil
+}
+
+// node returns the node of the requests of the bootstrap.
+func (b *Bootstrap) node(userAgentVersion string) *pb.Node {
+	n := b.Node
+	return &pb.Node{
+		Id:      n.ID,
+		Cluster: n.Cluster,
+		Locality: &pb.Locality{
+			Region:  n.Locality.Region,
+			Zone:    n.Locality.Zone,
+			SubZone: n.Locality.SubZone,
+		},
+		UserAgentName:        "gRPC Go",
+		UserAgentVersionType: &pb.Node_UserAgentVersion{UserAgentVersion: userAgentVersion},
+	}
+}
@@ -0,0 +1,566 @@
+// Package xds resolves the gRPC targets of the xds scheme,
+//
+//	xds:///udm
+//
+// with the resources of an xDS control plane, such as Istio's istiod or a
+// go-control-plane server, read over the aggregated discovery service (ADS)
+// as the xDS clients of gRPC do, so that an external control plane steers
+// the calls of the NF clients without a sidecar proxy. The control plane is
+// given by the gRPC xDS bootstrap (see Bootstrap).
+//
+// The target names a listener (LDS), whose HTTP connection manager gives
+// the routes, inline or as a route configuration (RDS). The virtual host of
+// the target, and in it the first route matching every call, a prefix of ""
+// or "/", give the cluster, or the weighted clusters, the calls are split
+// between. Each cluster (CDS), of the EDS type, gives its endpoints (EDS)
+// and its outlier detection. The calls go to the clusters by their weights,
+// then to the endpoints of the lowest priority with healthy ones by their
+// weights, the endpoints whose calls fail in a row being ejected for a
+// while, as by the consecutive_5xx outlier detection of Envoy.
+//
+// The resources are subscribed to on one stream per client, shared by the
+// targets, and the resources that fail validation are rejected (NACK),
+// the last ones accepted staying in force. A broken stream is opened again
+// with backoff, the targets keeping their endpoints meanwhile.
+package xds
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	pb "github.com/miki-tnt/sa5g-go-usvc-k8s/pb/xds"
+)
+
+// Scheme is the scheme of the targets resolved.
+const Scheme = "xds"
+
+// The type URLs of the resources.
+const (
+	ListenerType = "type.googleapis.com/envoy.config.listener.v3.Listener"
+	RouteType    = "type.googleapis.com/envoy.config.route.v3.RouteConfiguration"
+	ClusterType  = "type.googleapis.com/envoy.config.cluster.v3.Cluster"
+	EndpointType = "type.googleapis.com/envoy.config.endpoint.v3.ClusterLoadAssignment"
+
+	managerType = "type.googleapis.com/envoy.extensions.filters.network.http_connection_manager.v3.HttpConnectionManager"
+)
+
+// The bounds of the time between two attempts to open a broken stream.
+const (
+	minBackoff = time.Second
+	maxBackoff = 30 * time.Second
+)
+
+// Option sets an optional parameter of a Client.
+type Option func(*Client)
+
+// Logger sets the logger of the broken streams and rejected resources.
+func Logger(logger log.Logger) Option {
+	return func(c *Client) { c.logger = logger }
+}
+
+// DialOptions sets the options of the connection to the control plane,
+// plaintext by default.
+func DialOptions(options ...grpc.DialOption) Option {
+	return func(c *Client) { c.dialOptions = options }
+}
+
+// Client is the xDS client of an NF, and the resolver.Builder of the xds
+// scheme, to be passed to grpc.WithResolvers. The stream to the control
+// plane is opened by the first dial of an xds target.
+type Client struct {
+	bootstrap   *Bootstrap
+	logger      log.Logger
+	dialOptions []grpc.DialOption
+
+	mtx       sync.Mutex
+	started   bool
+	subs      map[string]*subscription
+	dirty     map[string]bool
+	targets   map[string]*target
+	connected bool
+	err       error
+	acks      int64
+	nacks     int64
+	kick      chan struct{}
+
+	// the watchers are called one at a time, in the order of the updates
+	queueMtx sync.Mutex
+	queue    []func()
+	queued   chan struct{}
+}
+
+// NewClient returns the Client of the control plane of b. A nil b fails the
+// dials of the xds targets.
+func NewClient(b *Bootstrap, options ...Option) *Client {
+	c := &Client{
+		bootstrap:   b,
+		logger:      log.NewNopLogger(),
+		dialOptions: []grpc.DialOption{grpc.WithInsecure()},
+		subs:        map[string]*subscription{},
+		dirty:       map[string]bool{},
+		targets:     map[string]*target{},
+		kick:        make(chan struct{}, 1),
+		queued:      make(chan struct{}, 1),
+	}
+	for _, o := range options {
+		o(c)
+	}
+	return c
+}
+
+// subscription is the resources of a type subscribed to, by name.
+type subscription struct {
+	watches map[string][]*watch
+	// resources are the last resources accepted, nil for those the control
+	// plane does not know.
+	resources      map[string]proto.Message
+	version, nonce string
+}
+
+// watch is a watcher of a resource, called with the resource whenever it
+// changes, nil when it is removed.
+type watch struct {
+	fn      func(proto.Message)
+	stopped int32
+}
+
+// watch calls fn with the resource of typeURL named name, and its changes,
+// until the function returned is called.
+func (c *Client) watch(typeURL, name string, fn func(proto.Message)) func() {
+	w := &watch{fn: fn}
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.startLocked()
+	s, ok := c.subs[typeURL]
+	if !ok {
+		s = &subscription{watches: map[string][]*watch{}, resources: map[string]proto.Message{}}
+		c.subs[typeURL] = s
+	}
+	if len(s.watches[name]) == 0 {
+		c.changedLocked(typeURL)
+	}
+	s.watches[name] = append(s.watches[name], w)
+	if m, ok := s.resources[name]; ok {
+		c.enqueue(w, m)
+	}
+	return func() {
+		atomic.StoreInt32(&w.stopped, 1)
+		c.mtx.Lock()
+		defer c.mtx.Unlock()
+		ws := s.watches[name]
+		for i := range ws {
+			if ws[i] == w {
+				ws = append(ws[:i], ws[i+1:]...)
+				break
+			}
+		}
+		if len(ws) > 0 {
+			s.watches[name] = ws
+			return
+		}
+		delete(s.watches, name)
+		delete(s.resources, name)
+		c.changedLocked(typeURL)
+	}
+}
+
+// startLocked opens the stream to the control plane, on the first dial.
+func (c *Client) startLocked() {
+	if !c.started {
+		c.started = true
+		go c.run()
+		go c.deliver()
+	}
+}
+
+// changedLocked has the subscription of typeURL sent again.
+func (c *Client) changedLocked(typeURL string) {
+	c.dirty[typeURL] = true
+	select {
+	case c.kick <- struct{}{}:
+	default:
+	}
+}
+
+// enqueue has w called with m.
+func (c *Client) enqueue(w *watch, m proto.Message) {
+	c.do(func() {
+		if atomic.LoadInt32(&w.stopped) == 0 {
+			w.fn(m)
+		}
+	})
+}
+
+// do calls fn after the watchers of the updates before, and before those of
+// the updates after.
+func (c *Client) do(fn func()) {
+	c.queueMtx.Lock()
+	c.queue = append(c.queue, fn)
+	c.queueMtx.Unlock()
+	select {
+	case c.queued <- struct{}{}:
+	default:
+	}
+}
+
+// deliver calls the watchers of the resources.
+func (c *Client) deliver() {
+	for range c.queued {
+		for {
+			c.queueMtx.Lock()
+			if len(c.queue) == 0 {
+				c.queueMtx.Unlock()
+				break
+			}
+			fn := c.queue[0]
+			c.queue = c.queue[1:]
+			c.queueMtx.Unlock()
+			fn()
+		}
+	}
+}
+
+// run streams the resources from the control plane, opening the stream
+// again whenever it breaks.
+func (c *Client) run() {
+	uri := c.bootstrap.XDSServers[0].ServerURI
+	conn, err := grpc.Dial(uri, c.dialOptions...)
+	if err != nil {
+		// the dial does not block, only the options fail it
+		c.mtx.Lock()
+		c.err = err
+		c.mtx.Unlock()
+		level.Error(c.logger).Log("xds", uri, "err", err)
+		return
+	}
+	ads := pb.NewAggregatedDiscoveryServiceClient(conn)
+	backoff := minBackoff
+	for {
+		received, err := c.stream(ads)
+		c.mtx.Lock()
+		c.connected, c.err = false, err
+		c.mtx.Unlock()
+		if received {
+			backoff = minBackoff
+		}
+		level.Warn(c.logger).Log("xds", uri, "err", err, "retry", backoff)
+		time.Sleep(backoff)
+		if backoff *= 2; backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// stream subscribes to the resources watched on a new stream, and applies
+// the responses until the stream breaks. It tells whether a response was
+// received.
+func (c *Client) stream(ads pb.AggregatedDiscoveryServiceClient) (bool, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s, err := ads.StreamAggregatedResources(ctx, grpc.WaitForReady(true))
+	if err != nil {
+		return false, err
+	}
+	c.mtx.Lock()
+	for typeURL, sub := range c.subs {
+		// the versions are kept, the nonces are of the stream
+		sub.nonce = ""
+		if len(sub.watches) > 0 {
+			c.changedLocked(typeURL)
+		}
+	}
+	c.mtx.Unlock()
+
+	responses := make(chan *pb.DiscoveryResponse)
+	errc := make(chan error, 1)
+	go func() {
+		for {
+			r, err := s.Recv()
+			if err != nil {
+				errc <- err
+				return
+			}
+			select {
+			case responses <- r:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	node := c.bootstrap.node(grpc.Version)
+	received := false
+	for {
+		var requests []*pb.DiscoveryRequest
+		select {
+		case <-c.kick:
+			requests = c.subscriptions()
+		case r := <-responses:
+			if !received {
+				received = true
+				c.mtx.Lock()
+				c.connected, c.err = true, nil
+				c.mtx.Unlock()
+			}
+			requests = []*pb.DiscoveryRequest{c.apply(r)}
+		case err := <-errc:
+			return received, err
+		}
+		for _, req := range requests {
+			req.Node = node
+			if err := s.Send(req); err != nil {
+				return received, err
+			}
+		}
+	}
+}
+
+// subscriptions returns the requests of the subscriptions changed.
+func (c *Client) subscriptions() []*pb.DiscoveryRequest {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	requests := make([]*pb.DiscoveryRequest, 0, len(c.dirty))
+	for typeURL := range c.dirty {
+		s := c.subs[typeURL]
+		requests = append(requests, &pb.DiscoveryRequest{
+			TypeUrl:       typeURL,
+			VersionInfo:   s.version,
+			ResponseNonce: s.nonce,
+			ResourceNames: s.names(),
+		})
+	}
+	c.dirty = map[string]bool{}
+	return requests
+}
+
+func (s *subscription) names() []string {
+	names := make([]string, 0, len(s.watches))
+	for name := range s.watches {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// apply applies the response r, and returns the request acknowledging it:
+// the resources are accepted as a whole or rejected as a whole.
+func (c *Client) apply(r *pb.DiscoveryResponse) *pb.DiscoveryRequest {
+	resources, err := decode(r)
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	s, ok := c.subs[r.TypeUrl]
+	if !ok {
+		s = &subscription{watches: map[string][]*watch{}, resources: map[string]proto.Message{}}
+		c.subs[r.TypeUrl] = s
+	}
+	s.nonce = r.Nonce
+	if err != nil {
+		c.nacks++
+		level.Warn(c.logger).Log("xds", "nack", "type", r.TypeUrl, "version", r.VersionInfo, "err", err)
+		return &pb.DiscoveryRequest{
+			TypeUrl:       r.TypeUrl,
+			VersionInfo:   s.version,
+			ResponseNonce: r.Nonce,
+			ResourceNames: s.names(),
+			ErrorDetail:   &pb.Status{Code: int32(codes.InvalidArgument), Message: err.Error()},
+		}
+	}
+	c.acks++
+	s.version = r.VersionInfo
+	for name, ws := range s.watches {
+		m, ok := resources[name]
+		if !ok {
+			// every listener and cluster is in each response, the others
+			// only when they change
+			if r.TypeUrl != ListenerType && r.TypeUrl != ClusterType {
+				continue
+			}
+			if old, known := s.resources[name]; known && old == nil {
+				continue
+			}
+		} else if old := s.resources[name]; old != nil && proto.Equal(old, m) {
+			continue
+		}
+		s.resources[name] = m
+		for _, w := range ws {
+			c.enqueue(w, m)
+		}
+	}
+	return &pb.DiscoveryRequest{
+		TypeUrl:       r.TypeUrl,
+		VersionInfo:   r.VersionInfo,
+		ResponseNonce: r.Nonce,
+		ResourceNames: s.names(),
+	}
+}
+
+// decode returns the resources of r by name, or the error of the first one
+// invalid.
+func decode(r *pb.DiscoveryResponse) (map[string]proto.Message, error) {
+	resources := make(map[string]proto.Message, len(r.Resources))
+	for _, a := range r.Resources {
+		if a.TypeUrl != r.TypeUrl {
+			return nil, fmt.Errorf("resource of type %s in a response of %s", a.TypeUrl, r.TypeUrl)
+		}
+		var (
+			m    proto.Message
+			name string
+		)
+		switch r.TypeUrl {
+		case ListenerType:
+			l := &pb.Listener{}
+			if err := ptypes.UnmarshalAny(a, l); err != nil {
+				return nil, err
+			}
+			if _, err := manager(l); err != nil {
+				return nil, fmt.Errorf("listener %s: %v", l.Name, err)
+			}
+			m, name = l, l.Name
+		case RouteType:
+			rc := &pb.RouteConfiguration{}
+			if err := ptypes.UnmarshalAny(a, rc); err != nil {
+				return nil, err
+			}
+			m, name = rc, rc.Name
+		case ClusterType:
+			cl := &pb.Cluster{}
+			if err := ptypes.UnmarshalAny(a, cl); err != nil {
+				return nil, err
+			}
+			if cl.GetType() != pb.Cluster_EDS {
+				return nil, fmt.Errorf("cluster %s: type %s, not EDS", cl.Name, cl.GetType())
+			}
+			m, name = cl, cl.Name
+		case EndpointType:
+			cla := &pb.ClusterLoadAssignment{}
+			if err := ptypes.UnmarshalAny(a, cla); err != nil {
+				return nil, err
+			}
+			m, name = cla, cla.ClusterName
+		default:
+			return nil, fmt.Errorf("resource type %s not supported", r.TypeUrl)
+		}
+		resources[name] = m
+	}
+	return resources, nil
+}
+
+// manager returns the HTTP connection manager of the client listener l.
+func manager(l *pb.Listener) (*pb.HttpConnectionManager, error) {
+	a := l.GetApiListener().GetApiListener()
+	if a == nil {
+		return nil, errors.New("not an api_listener")
+	}
+	if a.TypeUrl != managerType {
+		return nil, fmt.Errorf("api_listener of type %s", a.TypeUrl)
+	}
+	hcm := &pb.HttpConnectionManager{}
+	if err := ptypes.UnmarshalAny(a, hcm); err != nil {
+		return nil, err
+	}
+	if hcm.GetRds() == nil && hcm.GetRouteConfig() == nil {
+		return nil, errors.New("neither rds nor route_config")
+	}
+	return hcm, nil
+}
+
+// Stats are the state of the stream of a Client, and the targets it
+// resolves.
+type Stats struct {
+	Server    string `json:"server"`
+	Node      string `json:"node"`
+	Connected bool   `json:"connected"`
+	Error     string `json:"error,omitempty"`
+	// Versions are the versions of the resources accepted, by type URL.
+	Versions map[string]string `json:"versions"`
+	ACKs     int64             `json:"acks"`
+	NACKs    int64             `json:"nacks"`
+	Targets  []TargetStats     `json:"targets"`
+}
+
+// TargetStats are the clusters of a target dialed, and the calls sent to
+// them.
+type TargetStats struct {
+	Target   string         `json:"target"`
+	Route    string         `json:"route,omitempty"`
+	Clusters []ClusterStats `json:"clusters"`
+	Updated  time.Time      `json:"updated"`
+	Error    string         `json:"error,omitempty"`
+}
+
+// ClusterStats are the endpoints of a cluster, by address, and the calls
+// sent to them.
+type ClusterStats struct {
+	Name      string            `json:"name"`
+	Weight    uint32            `json:"weight"`
+	Endpoints map[string]uint32 `json:"endpoints"`
+	// Ejected are the endpoints ejected, and until when.
+	Ejected   map[string]time.Time `json:"ejected,omitempty"`
+	Picks     int64                `json:"picks"`
+	Ejections int64                `json:"ejections"`
+	Error     string               `json:"error,omitempty"`
+}
+
+// Stats returns the stats of c.
+func (c *Client) Stats() Stats {
+	c.mtx.Lock()
+	s := Stats{Connected: c.connected, Versions: map[string]string{}, ACKs: c.acks, NACKs: c.nacks}
+	if c.bootstrap != nil {
+		s.Server, s.Node = c.bootstrap.XDSServers[0].ServerURI, c.bootstrap.Node.ID
+	}
+	if c.err != nil {
+		s.Error = c.err.Error()
+	}
+	for typeURL, sub := range c.subs {
+		if sub.version != "" {
+			s.Versions[typeURL] = sub.version
+		}
+	}
+	targets := make([]*target, 0, len(c.targets))
+	for _, t := range c.targets {
+		targets = append(targets, t)
+	}
+	c.mtx.Unlock()
+
+	now := time.Now()
+	s.Targets = make([]TargetStats, 0, len(targets))
+	for _, t := range targets {
+		t.mtx.Lock()
+		ts := TargetStats{Target: t.name, Clusters: make([]ClusterStats, 0, len(t.clusters)), Updated: t.updated}
+		if t.err != nil {
+			ts.Error = t.err.Error()
+		}
+		for name, cl := range t.clusters {
+			cs := ClusterStats{Name: name, Weight: t.weights[name], Endpoints: cl.endpoints, Picks: cl.picks, Ejections: cl.ejections}
+			for addr, h := range cl.hosts {
+				if h.ejected(now) {
+					if cs.Ejected == nil {
+						cs.Ejected = map[string]time.Time{}
+					}
+					cs.Ejected[addr] = h.ejectedUntil.UTC()
+				}
+			}
+			if cl.err != nil {
+				cs.Error = cl.err.Error()
+			}
+			ts.Clusters = append(ts.Clusters, cs)
+		}
+		t.mtx.Unlock()
+		sort.Slice(ts.Clusters, func(i, j int) bool { return ts.Clusters[i].Name < ts.Clusters[j].Name })
+		s.Targets = append(s.Targets, ts)
+	}
+	sort.Slice(s.Targets, func(i, j int) bool { return s.Targets[i].Target < s.Targets[j].Target })
+	return s
+}
@@ -0,0 +1,418 @@
+package xds
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/serviceconfig"
+
+	pb "github.com/miki-tnt/sa5g-go-usvc-k8s/pb/xds"
+	"github.com/miki-tnt/sa5g-go-usvc-k8s/pkg/discovery"
+)
+
+// Scheme returns the xds scheme.
+func (c *Client) Scheme() string {
+	return Scheme
+}
+
+// Build starts resolving the target t of cc, the name of a listener.
+func (c *Client) Build(t resolver.Target, cc resolver.ClientConn, _ resolver.BuildOptions) (resolver.Resolver, error) {
+	if c.bootstrap == nil {
+		return nil, ErrNoBootstrap
+	}
+	if t.Endpoint == "" {
+		return nil, errors.New("xds: no listener in the target")
+	}
+	r := &xdsResolver{cc: cc, config: cc.ParseServiceConfig(discovery.ServiceConfig(discovery.RoundRobin))}
+	c.mtx.Lock()
+	tg, ok := c.targets[t.Endpoint]
+	if !ok {
+		// the connections of a pool share the target, and its ejections
+		tg = &target{
+			c:         c,
+			name:      t.Endpoint,
+			resolvers: map[*xdsResolver]bool{},
+			clusters:  map[string]*cluster{},
+			addrs:     map[string]resolver.Address{},
+		}
+		c.targets[t.Endpoint] = tg
+	}
+	r.t = tg
+	c.startLocked()
+	c.mtx.Unlock()
+
+	tg.mtx.Lock()
+	tg.resolvers[r] = true
+	first := len(tg.resolvers) == 1
+	tg.mtx.Unlock()
+	c.do(func() {
+		if first {
+			tg.cancelListener = c.watch(ListenerType, tg.name, tg.onListener)
+		} else {
+			tg.update(r)
+		}
+	})
+	return r, nil
+}
+
+// xdsResolver resolves a target for a gRPC connection.
+type xdsResolver struct {
+	t      *target
+	cc     resolver.ClientConn
+	config *serviceconfig.ParseResult
+	once   sync.Once
+}
+
+func (*xdsResolver) ResolveNow(resolver.ResolveNowOptions) {}
+
+func (r *xdsResolver) Close() {
+	r.once.Do(func() {
+		t, c := r.t, r.t.c
+		c.mtx.Lock()
+		t.mtx.Lock()
+		delete(t.resolvers, r)
+		last := len(t.resolvers) == 0
+		if last {
+			delete(c.targets, t.name)
+		}
+		t.mtx.Unlock()
+		c.mtx.Unlock()
+		if last {
+			c.do(t.stop)
+		}
+	})
+}
+
+// target is a listener dialed, shared by the connections dialing it. Its
+// watches are only changed by the watchers, and the functions given to do,
+// which the Client calls one at a time; the mtx guards what the pickers and
+// the stats read.
+type target struct {
+	c              *Client
+	name           string
+	cancelListener func()
+	cancelRoute    func()
+	// route is the name of the route configuration watched, "" when the
+	// routes are inline.
+	route string
+
+	mtx       sync.Mutex
+	resolvers map[*xdsResolver]bool
+	// weights are those of the clusters of the route
+	weights  map[string]uint32
+	clusters map[string]*cluster
+	// addrs are the addresses of the endpoints by cluster and address, kept
+	// so that the balancers do not replace the connections to the endpoints
+	// that stay.
+	addrs   map[string]resolver.Address
+	updated time.Time
+	err     error
+}
+
+// cluster is a cluster of the route of a target.
+type cluster struct {
+	name           string
+	service        string
+	cancelCluster  func()
+	cancelEndpoint func()
+
+	// under the mtx of the target
+	outliers  *outliers
+	endpoints map[string]uint32
+	hosts     map[string]*host
+	picks     int64
+	ejections int64
+	err       error
+}
+
+func (t *target) onListener(m proto.Message) {
+	if m == nil {
+		t.fail(fmt.Errorf("xds: listener %s not found", t.name))
+		return
+	}
+	hcm, _ := manager(m.(*pb.Listener))
+	if rds := hcm.GetRds(); rds != nil {
+		if rds.RouteConfigName != t.route || t.cancelRoute == nil {
+			t.stopRoute()
+			t.route = rds.RouteConfigName
+			t.cancelRoute = t.c.watch(RouteType, t.route, t.onRoute)
+		}
+		return
+	}
+	t.stopRoute()
+	t.onRoute(hcm.GetRouteConfig())
+}
+
+func (t *target) stopRoute() {
+	if t.cancelRoute != nil {
+		t.cancelRoute()
+		t.cancelRoute, t.route = nil, ""
+	}
+}
+
+// onRoute splits the calls between the clusters of the route of the target
+// in rc.
+func (t *target) onRoute(m proto.Message) {
+	weights, err := t.clusterWeights(m.(*pb.RouteConfiguration))
+	if err != nil {
+		t.fail(err)
+		return
+	}
+	t.mtx.Lock()
+	t.weights, t.err = weights, nil
+	var added []*cluster
+	for name := range weights {
+		if _, ok := t.clusters[name]; !ok {
+			cl := &cluster{name: name, hosts: map[string]*host{}}
+			t.clusters[name] = cl
+			added = append(added, cl)
+		}
+	}
+	var removed []*cluster
+	for name, cl := range t.clusters {
+		if _, ok := weights[name]; !ok {
+			delete(t.clusters, name)
+			removed = append(removed, cl)
+		}
+	}
+	t.mtx.Unlock()
+	for _, cl := range removed {
+		cl.stop()
+	}
+	for _, cl := range added {
+		cl := cl
+		cl.cancelCluster = t.c.watch(ClusterType, cl.name, func(m proto.Message) { t.onCluster(cl, m) })
+	}
+	t.updateAll()
+}
+
+// clusterWeights returns the weights of the clusters of the route of the
+// target in rc.
+func (t *target) clusterWeights(rc *pb.RouteConfiguration) (map[string]uint32, error) {
+	vh := virtualHost(rc.VirtualHosts, t.name)
+	if vh == nil {
+		return nil, fmt.Errorf("xds: no virtual host of %s in route %s", t.name, rc.Name)
+	}
+	for _, r := range vh.Routes {
+		if m := r.GetMatch(); m.GetPath() != "" || (m.GetPrefix() != "" && m.GetPrefix() != "/") {
+			continue
+		}
+		a := r.GetRoute()
+		if a == nil {
+			continue
+		}
+		if name := a.GetCluster(); name != "" {
+			return map[string]uint32{name: 1}, nil
+		}
+		weights := map[string]uint32{}
+		for _, cw := range a.GetWeightedClusters().GetClusters() {
+			weights[cw.Name] += cw.GetWeight().GetValue()
+		}
+		if len(weights) > 0 {
+			return weights, nil
+		}
+	}
+	return nil, fmt.Errorf("xds: no default route of %s in route %s", t.name, rc.Name)
+}
+
+// virtualHost returns the virtual host of the domain host: that with the
+// domain, or else that with the longest wildcard matching it, "*.x",
+// "x.*" or "*".
+func virtualHost(vhs []*pb.VirtualHost, host string) *pb.VirtualHost {
+	var best *pb.VirtualHost
+	bestLen := -1
+	for _, vh := range vhs {
+		for _, d := range vh.Domains {
+			switch {
+			case d == host:
+				return vh
+			case d == "*":
+			case strings.HasPrefix(d, "*") && strings.HasSuffix(host, d[1:]):
+			case strings.HasSuffix(d, "*") && strings.HasPrefix(host, d[:len(d)-1]):
+			default:
+				continue
+			}
+			if len(d) > bestLen {
+				best, bestLen = vh, len(d)
+			}
+		}
+	}
+	return best
+}
+
+func (t *target) onCluster(cl *cluster, m proto.Message) {
+	if m == nil {
+		if cl.cancelEndpoint != nil {
+			cl.cancelEndpoint()
+			cl.cancelEndpoint = nil
+		}
+		t.mtx.Lock()
+		cl.endpoints, cl.err = nil, fmt.Errorf("xds: cluster %s not found", cl.name)
+		t.mtx.Unlock()
+		t.updateAll()
+		return
+	}
+	c := m.(*pb.Cluster)
+	service := c.GetEdsClusterConfig().GetServiceName()
+	if service == "" {
+		service = c.Name
+	}
+	t.mtx.Lock()
+	cl.outliers = outlierConfig(c.GetOutlierDetection())
+	t.mtx.Unlock()
+	if service != cl.service || cl.cancelEndpoint == nil {
+		if cl.cancelEndpoint != nil {
+			cl.cancelEndpoint()
+		}
+		cl.service = service
+		cl.cancelEndpoint = t.c.watch(EndpointType, service, func(m proto.Message) { t.onEndpoints(cl, m) })
+	}
+}
+
+// onEndpoints sets the endpoints of cl to those of the lowest priority with
+// healthy ones, weighted by their weight times that of their locality.
+func (t *target) onEndpoints(cl *cluster, m proto.Message) {
+	cla := m.(*pb.ClusterLoadAssignment)
+	byPriority := map[uint32]map[string]uint32{}
+	for _, lle := range cla.Endpoints {
+		lw := uint32(1)
+		if w := lle.GetLoadBalancingWeight(); w != nil {
+			lw = w.Value
+		}
+		for _, lbe := range lle.LbEndpoints {
+			if hs := lbe.HealthStatus; hs != pb.HealthStatus_UNKNOWN && hs != pb.HealthStatus_HEALTHY {
+				continue
+			}
+			sa := lbe.GetEndpoint().GetAddress().GetSocketAddress()
+			if sa == nil {
+				continue
+			}
+			w := uint32(1)
+			if lbw := lbe.GetLoadBalancingWeight(); lbw != nil {
+				w = lbw.Value
+			}
+			if w*lw == 0 {
+				continue
+			}
+			eps, ok := byPriority[lle.Priority]
+			if !ok {
+				eps = map[string]uint32{}
+				byPriority[lle.Priority] = eps
+			}
+			eps[net.JoinHostPort(sa.Address, strconv.Itoa(int(sa.GetPortValue())))] += w * lw
+		}
+	}
+	var (
+		endpoints map[string]uint32
+		priority  uint32
+	)
+	for p, eps := range byPriority {
+		if endpoints == nil || p < priority {
+			endpoints, priority = eps, p
+		}
+	}
+	t.mtx.Lock()
+	cl.endpoints, cl.err = endpoints, nil
+	if len(endpoints) == 0 {
+		cl.err = fmt.Errorf("xds: no healthy endpoint in cluster %s", cl.name)
+	}
+	for addr := range cl.hosts {
+		if _, ok := endpoints[addr]; !ok {
+			delete(cl.hosts, addr)
+		}
+	}
+	t.mtx.Unlock()
+	t.updateAll()
+}
+
+// fail reports err to the connections of the target, which keep their
+// endpoints.
+func (t *target) fail(err error) {
+	t.mtx.Lock()
+	t.err = err
+	resolvers := t.resolverList()
+	t.mtx.Unlock()
+	for _, r := range resolvers {
+		r.cc.ReportError(err)
+	}
+}
+
+func (t *target) resolverList() []*xdsResolver {
+	resolvers := make([]*xdsResolver, 0, len(t.resolvers))
+	for r := range t.resolvers {
+		resolvers = append(resolvers, r)
+	}
+	return resolvers
+}
+
+// updateAll updates the connections of the target with the endpoints of its
+// clusters.
+func (t *target) updateAll() {
+	t.mtx.Lock()
+	resolvers := t.resolverList()
+	t.mtx.Unlock()
+	for _, r := range resolvers {
+		t.update(r)
+	}
+}
+
+// update updates the connection of r with the endpoints of the clusters of
+// the target, those of the clusters without weight aside.
+func (t *target) update(r *xdsResolver) {
+	t.mtx.Lock()
+	if t.weights == nil {
+		// the route is not known yet
+		t.mtx.Unlock()
+		return
+	}
+	var addrs []resolver.Address
+	kept := map[string]resolver.Address{}
+	for name, w := range t.weights {
+		cl := t.clusters[name]
+		if w == 0 || cl == nil {
+			continue
+		}
+		for addr := range cl.endpoints {
+			key := name + "/" + addr
+			a, ok := t.addrs[key]
+			if !ok {
+				a = resolver.Address{Addr: addr, Attributes: discovery.Attributes(t, name)}
+			}
+			kept[key] = a
+			addrs = append(addrs, a)
+		}
+	}
+	t.addrs = kept
+	t.updated = time.Now().UTC()
+	t.mtx.Unlock()
+	sort.Slice(addrs, func(i, j int) bool { return addrs[i].Addr < addrs[j].Addr })
+	r.cc.UpdateState(resolver.State{Addresses: addrs, ServiceConfig: r.config})
+}
+
+// stop cancels the watches of the target, dialed no more.
+func (t *target) stop() {
+	t.cancelListener()
+	t.stopRoute()
+	t.mtx.Lock()
+	clusters := t.clusters
+	t.clusters = map[string]*cluster{}
+	t.mtx.Unlock()
+	for _, cl := range clusters {
+		cl.stop()
+	}
+}
+
+func (cl *cluster) stop() {
+	cl.cancelCluster()
+	if cl.cancelEndpoint != nil {
+		cl.cancelEndpoint()
+	}
+}